@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/repository/postgres"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+	"github.com/kingrain94/audit-log-api/internal/worker"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+func main() {
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: .env file not found")
+	}
+
+	// Initialize logger
+	appLogger := logger.NewLogger(os.Getenv("APP_ENV"))
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), config.DefaultTracingConfig("audit-log-anomaly-worker"))
+	if err != nil {
+		appLogger.Fatal("Failed to initialize tracing", err)
+	}
+
+	// Initialize PostgreSQL with database connections
+	dbConnections, err := config.NewDatabaseConnections()
+	if err != nil {
+		appLogger.Fatal("Failed to connect to PostgreSQL", err)
+	}
+	defer dbConnections.Close()
+
+	pgRepo := postgres.NewPostgresRepository(dbConnections)
+
+	// Create anomaly worker
+	anomalyConfig := config.DefaultAnomalyConfig()
+	if err := anomalyConfig.Validate(); err != nil {
+		appLogger.Fatal("Invalid anomaly configuration", err)
+	}
+	anomalyWorker := worker.NewAnomalyWorker(
+		service.NewAnomalyService(pgRepo, anomalyConfig),
+		pgRepo,
+		appLogger,
+		anomalyConfig.Window,
+	)
+
+	// Expose Prometheus metrics
+	metricsConfig := config.DefaultMetricsConfig(":9106")
+	metricsServer := metrics.NewServer(metricsConfig.Addr)
+	metricsServer.Start(func(err error) {
+		appLogger.Error("Metrics server failed", err)
+	})
+
+	// Setup graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	// Start worker
+	anomalyWorker.Start()
+
+	// Wait for shutdown signal
+	<-sigChan
+	appLogger.Info("Shutting down anomaly worker...")
+
+	// Stop worker
+	anomalyWorker.Stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+		appLogger.Error("Failed to shutdown metrics server", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		appLogger.Error("Failed to flush traces", err)
+	}
+	appLogger.Info("Anomaly worker stopped")
+}
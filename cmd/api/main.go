@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"syscall"
 	"time"
 
@@ -18,11 +19,20 @@ import (
 	"github.com/kingrain94/audit-log-api/docs"
 	"github.com/kingrain94/audit-log-api/internal/api"
 	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/ingest"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
 	"github.com/kingrain94/audit-log-api/internal/middleware"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/repository/clickhouse"
 	"github.com/kingrain94/audit-log-api/internal/repository/composite"
+	"github.com/kingrain94/audit-log-api/internal/repository/opensearch"
 	"github.com/kingrain94/audit-log-api/internal/service"
+	"github.com/kingrain94/audit-log-api/internal/service/cache"
 	"github.com/kingrain94/audit-log-api/internal/service/pubsub"
 	"github.com/kingrain94/audit-log-api/internal/service/queue"
+	"github.com/kingrain94/audit-log-api/internal/service/reputation"
+	"github.com/kingrain94/audit-log-api/internal/service/storage"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
 	"github.com/kingrain94/audit-log-api/pkg/logger"
 )
 
@@ -48,6 +58,12 @@ func main() {
 	// Initialize logger
 	appLogger := logger.NewLogger(os.Getenv("APP_ENV"))
 
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), config.DefaultTracingConfig("audit-log-api"))
+	if err != nil {
+		appLogger.Fatal("Failed to initialize tracing", err)
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		appLogger.Fatal("Failed to load config", err)
@@ -63,10 +79,28 @@ func main() {
 
 	// Initialize OpenSearch
 	osConfig := config.DefaultOpenSearchConfig()
-	osClient, err := osConfig.GetClient()
+	osClients, err := osConfig.GetClusterClients()
 	if err != nil {
 		appLogger.Fatal("Failed to connect to OpenSearch", err)
 	}
+	clusterHealth := opensearch.NewClusterHealthChecker(osClients, osConfig.HealthCheckInterval)
+	clusterHealth.Start()
+	defer clusterHealth.Stop()
+
+	// Initialize ClickHouse, which serves the stats when configured
+	chConfig := config.DefaultClickHouseConfig()
+	if err := chConfig.Validate(); err != nil {
+		appLogger.Fatal("Invalid ClickHouse configuration", err)
+	}
+	var analyticsRepo repository.AnalyticsRepository
+	if chConfig.Enabled() {
+		chConn, err := chConfig.Open()
+		if err != nil {
+			appLogger.Fatal("Failed to connect to ClickHouse", err)
+		}
+		defer chConn.Close()
+		analyticsRepo = clickhouse.NewAnalyticsRepository(chConn)
+	}
 
 	// Initialize Redis
 	redisConfig := config.DefaultRedisConfig()
@@ -76,47 +110,192 @@ func main() {
 	}
 	defer redisClient.Close()
 
-	// Initialize Redis pub/sub
-	redisPubSub := pubsub.NewRedisPubSub(redisClient, appLogger)
+	// Initialize Redis pub/sub or streams (per PUBSUB_BACKEND)
+	redisPubSub, err := pubsub.New(redisClient, config.DefaultPubSubConfig(), appLogger)
+	if err != nil {
+		appLogger.Fatal("Invalid pub/sub configuration", err)
+	}
+
+	// Initialize the message queue (SQS or Kafka, per QUEUE_BACKEND)
+	messageQueue, err := queue.New(config.DefaultQueueConfig())
+	if err != nil {
+		appLogger.Fatal("Failed to connect to message queue", err)
+	}
+	defer messageQueue.Close()
+
+	// Initialize S3 for export downloads and archive verification
+	s3Config := config.DefaultS3Config()
+	s3Client, err := s3Config.GetClient(context.Background())
+	if err != nil {
+		appLogger.Fatal("Failed to connect to S3", err)
+	}
+	exportURLSigner := storage.NewS3Presigner(s3Client, s3Config)
+
+	importConfig := config.DefaultImportConfig()
+	if err := importConfig.Validate(); err != nil {
+		appLogger.Fatal("Invalid import configuration", err)
+	}
 
-	// Initialize SQS
-	sqsConfig := config.DefaultSQSConfig()
-	sqsClient, err := sqsConfig.GetClient()
+	customShipperTemplates, err := config.DefaultShipperConfig().Templates()
 	if err != nil {
-		appLogger.Fatal("Failed to connect to SQS", err)
+		appLogger.Fatal("Failed to load shipper templates", err)
+	}
+	shipperTemplates, err := ingest.NewShipperTemplates(customShipperTemplates)
+	if err != nil {
+		appLogger.Fatal("Invalid shipper templates", err)
 	}
-	sqsService := queue.NewSQSService(sqsClient, sqsConfig)
 
-	repo := composite.NewCompositeRepository(dbConnections, osClient, osConfig)
+	repo := composite.NewCompositeRepository(dbConnections, osClients, osConfig)
 
 	// Initialize services
-	tenantService := service.NewTenantService(repo)
-	auditLogService := service.NewAuditLogService(repo, sqsService)
+	rateLimitCache := cache.NewRateLimitCache(redisClient, cfg.TenantRateLimitCacheTTL)
+	deletionConfig := config.DefaultTenantDeletionConfig()
+	if err := deletionConfig.Validate(); err != nil {
+		appLogger.Fatal("Invalid tenant deletion configuration", err)
+	}
+	tenantService := service.NewTenantService(repo, rateLimitCache, cache.NewTenantSettingsCache(redisClient, cfg.TenantSettingsCacheTTL), deletionConfig)
+	redactionService := service.NewRedactionService(repo, cache.NewRedactionRuleCache(redisClient, cfg.RedactionRuleCacheTTL))
+	taggingService := service.NewTaggingService(repo, cache.NewTaggingRuleCache(redisClient, cfg.TaggingRuleCacheTTL))
+	schemaService := service.NewSchemaService(repo, cache.NewResourceSchemaCache(redisClient, cfg.ResourceSchemaCacheTTL))
+	quotaConfig := config.DefaultQuotaConfig()
+	if err := quotaConfig.Validate(); err != nil {
+		appLogger.Fatal("Invalid quota configuration", err)
+	}
+	usageService := service.NewUsageService(cache.NewUsageCounter(redisClient), quotaConfig)
+	usageService.UseQuotaWarnings(service.NewQuotaWarningService(repo, tenantService, service.NewWebhookSender(quotaConfig.WebhookTimeout)))
+	auditLogService := service.NewAuditLogService(repo, messageQueue, exportURLSigner, redactionService, schemaService, usageService)
+	auditLogService.UseTagger(taggingService)
+	threatIntelConfig := config.DefaultThreatIntelConfig()
+	if err := threatIntelConfig.Validate(); err != nil {
+		appLogger.Fatal("Invalid threat intelligence configuration", err)
+	}
+	if threatIntelConfig.Enabled() {
+		var providers []service.IPReputationProvider
+		if slices.Contains(threatIntelConfig.Providers, config.ThreatIntelProviderBlocklist) {
+			blocklist := reputation.NewBlocklist(threatIntelConfig, appLogger)
+			blocklist.Start(threatIntelConfig.RefreshInterval)
+			defer blocklist.Stop()
+			providers = append(providers, blocklist)
+		}
+		if slices.Contains(threatIntelConfig.Providers, config.ThreatIntelProviderAbuseIPDB) {
+			providers = append(providers, reputation.NewAbuseIPDB(threatIntelConfig, cache.NewIPReputationCache(redisClient, threatIntelConfig.CacheTTL)))
+		}
+		auditLogService.UseEnricher(service.NewThreatIntelService(providers, threatIntelConfig))
+	}
+	ingestBufferConfig := config.DefaultIngestBufferConfig()
+	if err := ingestBufferConfig.Validate(); err != nil {
+		appLogger.Fatal("Invalid ingest buffer configuration", err)
+	}
+	if ingestBufferConfig.Enabled() {
+		auditLogService.StartIngestBuffer(ingestBufferConfig, appLogger)
+	}
+	if analyticsRepo != nil {
+		auditLogService.UseAnalytics(analyticsRepo)
+	}
+	auditLogService.UseWatermark(cache.NewIngestWatermark(redisClient))
+	if signingConfig := config.DefaultExportSigningConfig(); signingConfig.Enabled() {
+		key, err := signingConfig.PrivateKey()
+		if err != nil {
+			appLogger.Fatal("Failed to load export signing key", err)
+		}
+		auditLogService.UseExportSigner(service.NewExportSigner(key))
+	}
+	// Integrations' tokens are encrypted for the forwarding worker's key
+	var integrationTokens service.IntegrationTokenSealer
+	if tokenConfig := config.DefaultIntegrationTokenConfig(); tokenConfig.Enabled() {
+		key, err := tokenConfig.PublicKey()
+		if err != nil {
+			appLogger.Fatal("Failed to load integration token public key", err)
+		}
+		integrationTokens = service.NewIntegrationTokenSealer(key)
+	}
+	auditLogService.UseSampling(tenantService, cache.NewSampledLogCounter(redisClient))
+	logCacheConfig := config.DefaultLogCacheConfig()
+	if err := logCacheConfig.Validate(); err != nil {
+		appLogger.Fatal("Invalid log cache configuration", err)
+	}
+	if logCacheConfig.Enabled() {
+		auditLogService.UseReadCache(cache.NewLogReadCache(redisClient, logCacheConfig.TTL))
+	}
+	userService := service.NewUserService(repo)
+	tokenStore := cache.NewTokenStore(redisClient)
+	authService := service.NewAuthService(repo, tokenStore, cfg)
+	savedSearchService := service.NewSavedSearchService(repo)
+	policyService := service.NewPolicyService(repo, cache.NewPolicyCache(redisClient, cfg.PolicyCacheTTL), tenantService)
 
 	// Initialize middleware
-	authMiddleware := middleware.NewAuthMiddleware(cfg)
-	rateLimitMiddleware := middleware.NewRateLimitMiddleware(redisClient, cfg, appLogger)
+	authMiddleware, err := middleware.NewAuthMiddleware(cfg, config.DefaultOIDCConfig(), tokenStore)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize authentication", err)
+	}
+	authMiddleware.UseStreamTickets(authService)
+	tlsConfig := config.DefaultTLSConfig()
+	if err := tlsConfig.Validate(); err != nil {
+		appLogger.Fatal("Invalid TLS configuration", err)
+	}
+	if tlsConfig.VerifiesClientCerts() {
+		authMiddleware.UseClientCertificates(middleware.NewClientCertAuthenticator(tlsConfig))
+	}
+	policyMiddleware := middleware.NewPolicyMiddleware(policyService, appLogger)
+	rateLimitMiddleware := middleware.NewRateLimitMiddleware(redisClient, cfg, appLogger, tenantService)
 	validationMiddleware := middleware.NewValidationMiddleware(appLogger)
+	tenantSettingsMiddleware := middleware.NewTenantSettingsMiddleware(tenantService, appLogger)
+	metaAuditMiddleware := middleware.NewMetaAuditMiddleware(auditLogService, appLogger)
+	queryTimeoutConfig := config.DefaultQueryTimeoutConfig()
+	if err := queryTimeoutConfig.Validate(); err != nil {
+		appLogger.Fatal("Invalid query timeout configuration", err)
+	}
+	dbPools, err := dbConnections.Pools()
+	if err != nil {
+		appLogger.Fatal("Failed to get database pools", err)
+	}
+	dbPoolService := service.NewDBPoolService(dbPools, dbConnections.PoolConfig, appLogger)
+	if interval := dbConnections.PoolConfig.StatsLogInterval; interval > 0 {
+		dbPoolService.Start(interval)
+		defer dbPoolService.Stop()
+	}
 
 	// Initialize server
 	server := api.NewServer(
 		tenantService,
+		usageService,
 		auditLogService,
+		userService,
+		authService,
+		policyService,
+		redactionService,
+		taggingService,
+		schemaService,
+		savedSearchService,
+		service.NewIntegrationService(repo, integrationTokens),
+		config.DefaultLoader(),
+		service.NewIndexFailureService(repo, messageQueue),
+		service.NewSearchIndexService(repo, messageQueue),
+		service.NewJobService(repo),
+		service.NewArchiveService(repo, storage.NewS3ArchiveReader(s3Client, s3Config)),
+		service.NewImportService(repo, messageQueue, storage.NewS3ImportStore(s3Client, s3Config), redactionService, taggingService, usageService, importConfig),
+		dbPoolService,
 		authMiddleware,
+		policyMiddleware,
 		rateLimitMiddleware,
 		validationMiddleware,
+		tenantSettingsMiddleware,
+		metaAuditMiddleware,
+		queryTimeoutConfig,
 		appLogger,
 		redisPubSub,
+		shipperTemplates,
 	)
 
-	// Wire up WebSocket broadcaster
-	auditLogService.SetWebSocketBroadcaster(server.GetWebSocketHandler())
-
 	// Start WebSocket hub
 	server.StartWebSocketHub()
 
 	// Initialize router
 	router := gin.Default()
+	router.Use(middleware.Tracing())
+	router.Use(middleware.RequestID())
+	router.Use(middleware.CorrelationID())
+	router.Use(middleware.RequestMetrics())
 
 	// Swagger documentation endpoint
 	docs.SwaggerInfo.Title = "Audit Log API"
@@ -129,24 +308,47 @@ func main() {
 	// Swagger UI endpoint
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	// Health check endpoint
+	// Health check endpoint, with the last health status of each OpenSearch
+	// cluster. A cluster down only affects its tenants, so it isn't failed.
 	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+		c.JSON(http.StatusOK, gin.H{"status": "ok", "opensearch": clusterHealth.Status()})
 	})
 
+	// Prometheus metrics endpoint
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+
 	// Setup API routes
 	apiGroup := router.Group("/api/v1")
 	server.SetupRoutes(apiGroup)
+	server.SetupV2Routes(router.Group("/api/v2"))
+
+	// Bulk imports, whose uploads skip the API's size limit
+	server.SetupImportRoutes(router)
+
+	// OTLP/HTTP logs receiver
+	server.SetupOTLPRoutes(router)
 
 	// Start server
 	srv := &http.Server{
 		Addr:    fmt.Sprintf(":%d", cfg.ServerPort),
 		Handler: router,
 	}
+	if tlsConfig.Enabled() {
+		if srv.TLSConfig, err = tlsConfig.ServerConfig(); err != nil {
+			appLogger.Fatal("Failed to configure TLS", err)
+		}
+	}
 
 	// Graceful shutdown
 	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if srv.TLSConfig != nil {
+			// The certificate is already loaded into srv.TLSConfig
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			appLogger.Fatal("Failed to start server", err)
 		}
 	}()
@@ -160,9 +362,15 @@ func main() {
 	// Shutdown the HTTP server
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	if err := srv.Shutdown(ctx); err != nil {
+	err = srv.Shutdown(ctx)
+	// Store the buffered logs once no request can add to them
+	auditLogService.StopIngestBuffer()
+	if err != nil {
 		appLogger.Fatal("Server forced to shutdown", err)
 	}
+	if err := shutdownTracing(ctx); err != nil {
+		appLogger.Error("Failed to flush traces", err)
+	}
 
 	appLogger.Info("Server exiting")
 	appLogger.Sync()
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/repository/postgres"
+	"github.com/kingrain94/audit-log-api/internal/service/queue"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+	"github.com/kingrain94/audit-log-api/internal/worker"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+func main() {
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: .env file not found")
+	}
+
+	// Initialize logger
+	appLogger := logger.NewLogger(os.Getenv("APP_ENV"))
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), config.DefaultTracingConfig("audit-log-archive-scheduler"))
+	if err != nil {
+		appLogger.Fatal("Failed to initialize tracing", err)
+	}
+
+	// Initialize PostgreSQL, where tenants keep their retention settings and schedules
+	dbConnections, err := config.NewDatabaseConnections()
+	if err != nil {
+		appLogger.Fatal("Failed to connect to PostgreSQL", err)
+	}
+	defer dbConnections.Close()
+
+	pgRepo := postgres.NewPostgresRepository(dbConnections)
+
+	// Initialize the message queue (SQS or Kafka, per QUEUE_BACKEND)
+	messageQueue, err := queue.New(config.DefaultQueueConfig())
+	if err != nil {
+		appLogger.Fatal("Failed to connect to message queue", err)
+	}
+	defer messageQueue.Close()
+
+	// Create archive scheduler
+	scheduleConfig := config.DefaultArchiveScheduleConfig()
+	if err := scheduleConfig.Validate(); err != nil {
+		appLogger.Fatal("Invalid archive schedule configuration", err)
+	}
+	scheduler := worker.NewArchiveScheduler(
+		pgRepo,
+		messageQueue,
+		scheduleConfig,
+		appLogger,
+	)
+
+	// Expose Prometheus metrics
+	metricsConfig := config.DefaultMetricsConfig(":9113")
+	metricsServer := metrics.NewServer(metricsConfig.Addr)
+	metricsServer.Start(func(err error) {
+		appLogger.Error("Metrics server failed", err)
+	})
+
+	// Setup graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	// Start scheduler
+	scheduler.Start()
+
+	// Wait for shutdown signal
+	<-sigChan
+	appLogger.Info("Shutting down archive scheduler...")
+
+	// Stop scheduler
+	scheduler.Stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+		appLogger.Error("Failed to shutdown metrics server", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		appLogger.Error("Failed to flush traces", err)
+	}
+	appLogger.Info("Archive scheduler stopped")
+}
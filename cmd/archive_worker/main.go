@@ -11,8 +11,13 @@ import (
 	"github.com/joho/godotenv"
 
 	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
 	"github.com/kingrain94/audit-log-api/internal/repository/postgres"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	"github.com/kingrain94/audit-log-api/internal/service/cache"
 	"github.com/kingrain94/audit-log-api/internal/service/queue"
+	"github.com/kingrain94/audit-log-api/internal/service/storage"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
 	"github.com/kingrain94/audit-log-api/internal/worker"
 	"github.com/kingrain94/audit-log-api/pkg/logger"
 )
@@ -26,6 +31,12 @@ func main() {
 	// Initialize logger
 	appLogger := logger.NewLogger(os.Getenv("APP_ENV"))
 
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), config.DefaultTracingConfig("audit-log-archive-worker"))
+	if err != nil {
+		appLogger.Fatal("Failed to initialize tracing", err)
+	}
+
 	// Initialize PostgreSQL with database connections
 	dbConnections, err := config.NewDatabaseConnections()
 	if err != nil {
@@ -35,31 +46,75 @@ func main() {
 
 	pgRepo := postgres.NewPostgresRepository(dbConnections)
 
-	// Initialize SQS
-	sqsConfig := config.DefaultSQSConfig()
-	sqsClient, err := sqsConfig.GetClient()
+	// Initialize the message queue (SQS or Kafka, per QUEUE_BACKEND)
+	messageQueue, err := queue.New(config.DefaultQueueConfig())
+	if err != nil {
+		appLogger.Fatal("Failed to connect to message queue", err)
+	}
+	defer messageQueue.Close()
+
+	// Initialize Redis for the rule caches and usage counters of imports
+	redisClient, err := config.DefaultRedisConfig().GetClient()
 	if err != nil {
-		appLogger.Fatal("Failed to connect to SQS", err)
+		appLogger.Fatal("Failed to connect to Redis", err)
 	}
-	sqsService := queue.NewSQSService(sqsClient, sqsConfig)
+	defer redisClient.Close()
 
 	// Initialize S3
 	s3Config := config.DefaultS3Config()
+	if err := s3Config.Validate(); err != nil {
+		appLogger.Fatal("Invalid S3 configuration", err)
+	}
 	s3Client, err := s3Config.GetClient(context.Background())
 	if err != nil {
 		appLogger.Fatal("Failed to connect to S3", err)
 	}
 
+	importConfig := config.DefaultImportConfig()
+	if err := importConfig.Validate(); err != nil {
+		appLogger.Fatal("Invalid import configuration", err)
+	}
+	quotaConfig := config.DefaultQuotaConfig()
+	if err := quotaConfig.Validate(); err != nil {
+		appLogger.Fatal("Invalid quota configuration", err)
+	}
+	// Imported logs are tagged, redacted and metered like ingested ones
+	importService := service.NewImportService(
+		pgRepo,
+		messageQueue,
+		storage.NewS3ImportStore(s3Client, s3Config),
+		service.NewRedactionService(pgRepo, cache.NewRedactionRuleCache(redisClient, importConfig.RedactionRuleCacheTTL)),
+		service.NewTaggingService(pgRepo, cache.NewTaggingRuleCache(redisClient, importConfig.TaggingRuleCacheTTL)),
+		service.NewUsageService(cache.NewUsageCounter(redisClient), quotaConfig),
+		importConfig,
+	)
+
+	// Goroutines, poll interval and receive size unless overridden by WORKER_* settings
+	workerConfig := config.DefaultWorkerConfig(1, 5*time.Second, 10)
+	if err := workerConfig.Validate(); err != nil {
+		appLogger.Fatal("Invalid worker configuration", err)
+	}
+
 	// Create archive worker
 	archiveWorker := worker.NewArchiveWorker(
-		sqsService,
+		messageQueue,
 		pgRepo,
 		appLogger,
-		1,             // worker count
-		5*time.Second, // poll interval
-		s3Client,      // S3 client
-		s3Config,      // S3 configuration
+		workerConfig, // concurrency, pacing, drain timeout and visibility extension
+		s3Client,     // S3 client
+		s3Config,     // S3 configuration
+		importService,
 	)
+	if err := archiveWorker.VerifyObjectLock(context.Background()); err != nil {
+		appLogger.Fatal("Archive bucket can't lock archives", err)
+	}
+
+	// Expose Prometheus metrics
+	metricsConfig := config.DefaultMetricsConfig(":9102")
+	metricsServer := metrics.NewServer(metricsConfig.Addr)
+	metricsServer.Start(func(err error) {
+		appLogger.Error("Metrics server failed", err)
+	})
 
 	// Setup graceful shutdown
 	sigChan := make(chan os.Signal, 1)
@@ -77,5 +132,14 @@ func main() {
 
 	// Stop worker
 	archiveWorker.Stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+		appLogger.Error("Failed to shutdown metrics server", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		appLogger.Error("Failed to flush traces", err)
+	}
 	appLogger.Info("Archive worker stopped")
 }
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+)
+
+// apiClient calls the audit log API with the bearer token from --token
+type apiClient struct {
+	server     string
+	token      string
+	httpClient *http.Client
+}
+
+// connectionFlags registers --server and --token, defaulting to AUDITCTL_SERVER and AUDITCTL_TOKEN
+func connectionFlags(fs *flag.FlagSet) *apiClient {
+	c := &apiClient{httpClient: &http.Client{Timeout: time.Minute}}
+	fs.StringVar(&c.server, "server", envOrDefault("AUDITCTL_SERVER", "http://localhost:10000"), "API address")
+	fs.StringVar(&c.token, "token", os.Getenv("AUDITCTL_TOKEN"), "Bearer token (default $AUDITCTL_TOKEN)")
+	return c
+}
+
+func (c *apiClient) url(path string, query url.Values) string {
+	u := strings.TrimRight(c.server, "/") + "/api/v1" + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+func (c *apiClient) do(ctx context.Context, method, path string, query url.Values) (*http.Response, error) {
+	if c.token == "" {
+		return nil, fmt.Errorf("no token: pass --token or set AUDITCTL_TOKEN")
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.url(path, query), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if method != http.MethodGet {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		var apiErr dto.Error
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Message != "" {
+			return nil, fmt.Errorf("%s: %s (%s)", resp.Status, apiErr.Message, apiErr.Code)
+		}
+		return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
+
+// getJSON decodes the JSON response of a request into out
+func (c *apiClient) getJSON(ctx context.Context, method, path string, query url.Values, out any) error {
+	resp, err := c.do(ctx, method, path, query)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// logFilter holds the filter flags shared by query, stats, export and tail
+type logFilter struct {
+	userID, action, resourceType, severity string
+	sessionID, ipAddress, message          string
+	since                                  time.Duration
+	startTime, endTime, savedSearchID      string
+}
+
+func filterFlags(fs *flag.FlagSet, withTimeRange bool) *logFilter {
+	f := &logFilter{}
+	fs.StringVar(&f.userID, "user", "", "Filter by user ID")
+	fs.StringVar(&f.action, "action", "", "Filter by action")
+	fs.StringVar(&f.resourceType, "resource-type", "", "Filter by resource type")
+	fs.StringVar(&f.severity, "severity", "", "Filter by severity")
+	fs.StringVar(&f.sessionID, "session", "", "Filter by session ID")
+	fs.StringVar(&f.ipAddress, "ip", "", "Filter by IP address")
+	fs.StringVar(&f.message, "message", "", "Filter by message text")
+	if withTimeRange {
+		fs.DurationVar(&f.since, "since", 24*time.Hour, "Time range ending now, used when --start is not set")
+		fs.StringVar(&f.startTime, "start", "", "Start time (RFC3339 or YYYY-MM-DD)")
+		fs.StringVar(&f.endTime, "end", "", "End time (RFC3339 or YYYY-MM-DD, default now)")
+		fs.StringVar(&f.savedSearchID, "saved-search", "", "Saved search supplying the filters not given")
+	}
+	return f
+}
+
+func (f *logFilter) query() url.Values {
+	query := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	set("user_id", f.userID)
+	set("action", f.action)
+	set("resource_type", f.resourceType)
+	set("severity", f.severity)
+	set("session_id", f.sessionID)
+	set("ip_address", f.ipAddress)
+	set("message", f.message)
+	set("saved_search_id", f.savedSearchID)
+
+	// A saved search brings its own time range unless one is given explicitly
+	if f.startTime == "" && f.savedSearchID == "" {
+		now := time.Now().UTC()
+		set("start_time", now.Add(-f.since).Format(time.RFC3339))
+		set("end_time", now.Format(time.RFC3339))
+		return query
+	}
+	set("start_time", f.startTime)
+	set("end_time", f.endTime)
+	return query
+}
+
+// matches applies the filter to a streamed log, which the server does not filter
+func (f *logFilter) matches(log *dto.AuditLogResponse) bool {
+	return (f.userID == "" || log.UserID == f.userID) &&
+		(f.action == "" || strings.EqualFold(log.Action, f.action)) &&
+		(f.resourceType == "" || log.ResourceType == f.resourceType) &&
+		(f.severity == "" || strings.EqualFold(log.Severity, f.severity)) &&
+		(f.sessionID == "" || log.SessionID == f.sessionID) &&
+		(f.ipAddress == "" || log.IPAddress == f.ipAddress) &&
+		(f.message == "" || strings.Contains(strings.ToLower(log.Message), strings.ToLower(f.message)))
+}
+
+func envOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+func runExport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	client := connectionFlags(fs)
+	filter := filterFlags(fs, true)
+	format := fs.String("format", string(domain.ExportFormatCSV), "Export format: json, csv or xlsx")
+	outputFile := fs.String("out", "", "File to download the export to; prints the download URL when empty")
+	pollInterval := fs.Duration("poll", 2*time.Second, "How often to check the export job")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	query := filter.query()
+	query.Set("format", *format)
+
+	var job dto.ExportJobResponse
+	if err := client.getJSON(ctx, http.MethodPost, "/logs/export", query, &job); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Export job %s created, waiting for it to complete...\n", job.ID)
+
+	ticker := time.NewTicker(*pollInterval)
+	defer ticker.Stop()
+	for job.Status != string(domain.JobCompleted) {
+		if job.Status == string(domain.JobFailed) {
+			return fmt.Errorf("export job %s failed: %s", job.ID, job.Error)
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("stopped waiting for export job %s: %w", job.ID, ctx.Err())
+		case <-ticker.C:
+		}
+		if err := client.getJSON(ctx, http.MethodGet, "/logs/export/"+job.ID, nil, &job); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d logs\n", job.RowCount)
+
+	if *outputFile == "" {
+		fmt.Println(job.DownloadURL)
+		return nil
+	}
+	return download(ctx, job.DownloadURL, *outputFile)
+}
+
+// download saves the pre-signed S3 URL of an export, which needs no token
+func download(ctx context.Context, url, path string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download export: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download export: %s", resp.Status)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	fmt.Fprintf(os.Stderr, "Saved to %s\n", path)
+	return file.Close()
+}
@@ -0,0 +1,69 @@
+// Command auditctl queries, tails, summarizes and exports audit logs from the
+// command line and generates development tokens.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joho/godotenv"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, args []string) error
+}
+
+var commands = []command{
+	{"query", "List logs matching filters", runQuery},
+	{"tail", "Stream new logs over WebSocket", runTail},
+	{"stats", "Show log counts by action, severity and resource", runStats},
+	{"export", "Export logs to S3 and download the result", runExport},
+	{"token", "Generate a signed JWT for development", runToken},
+}
+
+func main() {
+	// .env supplies JWT_SECRET_KEY and AUDITCTL_* defaults when present
+	_ = godotenv.Load()
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for _, cmd := range commands {
+		if cmd.name != os.Args[1] {
+			continue
+		}
+		if err := cmd.run(ctx, os.Args[2:]); err != nil {
+			if err == flag.ErrHelp {
+				os.Exit(2)
+			}
+			fmt.Fprintf(os.Stderr, "auditctl %s: %v\n", cmd.name, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: auditctl <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", cmd.name, cmd.usage)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Run 'auditctl <command> -h' for the flags of a command.")
+}
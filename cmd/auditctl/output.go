@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputCSV   = "csv"
+)
+
+var csvHeader = []string{
+	"id", "timestamp", "tenant_id", "user_id", "session_id", "ip_address", "user_agent",
+	"action", "resource_type", "resource_id", "severity", "message", "metadata",
+}
+
+func validOutput(output string) error {
+	switch output {
+	case outputTable, outputJSON, outputCSV:
+		return nil
+	}
+	return fmt.Errorf("unknown output %q, want table, json or csv", output)
+}
+
+// logWriter prints logs one at a time, so tail can share it with query
+type logWriter struct {
+	output string
+	w      io.Writer
+	table  *tabwriter.Writer
+	csv    *csv.Writer
+	json   *json.Encoder
+}
+
+func newLogWriter(w io.Writer, output string) *logWriter {
+	lw := &logWriter{output: output, w: w}
+	switch output {
+	case outputCSV:
+		lw.csv = csv.NewWriter(w)
+		_ = lw.csv.Write(csvHeader)
+	case outputJSON:
+		lw.json = json.NewEncoder(w)
+	default:
+		lw.table = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(lw.table, "TIMESTAMP\tSEVERITY\tACTION\tRESOURCE\tUSER\tMESSAGE")
+	}
+	return lw
+}
+
+// Write prints a log; JSON output is one object per line
+func (lw *logWriter) Write(log *dto.AuditLogResponse) error {
+	switch lw.output {
+	case outputCSV:
+		return lw.csv.Write([]string{
+			log.ID, log.Timestamp.Format(time.RFC3339Nano), log.TenantID, log.UserID, log.SessionID,
+			log.IPAddress, log.UserAgent, log.Action, log.ResourceType, log.ResourceID,
+			log.Severity, log.Message, string(log.Metadata),
+		})
+	case outputJSON:
+		return lw.json.Encode(log)
+	}
+	_, err := fmt.Fprintf(lw.table, "%s\t%s\t%s\t%s/%s\t%s\t%s\n",
+		log.Timestamp.Local().Format(time.DateTime), log.Severity, log.Action,
+		log.ResourceType, log.ResourceID, log.UserID, log.Message)
+	return err
+}
+
+func (lw *logWriter) Flush() error {
+	switch lw.output {
+	case outputCSV:
+		lw.csv.Flush()
+		return lw.csv.Error()
+	case outputTable:
+		return lw.table.Flush()
+	}
+	return nil
+}
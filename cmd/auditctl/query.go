@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+)
+
+func runQuery(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("query", flag.ContinueOnError)
+	client := connectionFlags(fs)
+	filter := filterFlags(fs, true)
+	output := fs.String("o", outputTable, "Output format: table, json or csv")
+	page := fs.Int("page", 1, "Page number")
+	pageSize := fs.Int("page-size", 100, "Logs per page")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := validOutput(*output); err != nil {
+		return err
+	}
+
+	query := filter.query()
+	query.Set("page", strconv.Itoa(*page))
+	query.Set("page_size", strconv.Itoa(*pageSize))
+
+	var logs []dto.AuditLogResponse
+	if err := client.getJSON(ctx, http.MethodGet, "/logs", query, &logs); err != nil {
+		return err
+	}
+
+	w := newLogWriter(os.Stdout, *output)
+	for i := range logs {
+		if err := w.Write(&logs[i]); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+)
+
+func runStats(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	client := connectionFlags(fs)
+	filter := filterFlags(fs, true)
+	output := fs.String("o", outputTable, "Output format: table or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *output != outputTable && *output != outputJSON {
+		return fmt.Errorf("unknown output %q, want table or json", *output)
+	}
+
+	var stats dto.GetAuditLogStatsResponse
+	if err := client.getJSON(ctx, http.MethodGet, "/logs/stats", filter.query(), &stats); err != nil {
+		return err
+	}
+
+	if *output == outputJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Total logs\t%d\n", stats.TotalLogs)
+	printCounts(w, "ACTION", stats.ActionCounts)
+	printCounts(w, "SEVERITY", stats.SeverityCounts)
+	printCounts(w, "RESOURCE", stats.ResourceCounts)
+	if len(stats.Series) > 0 {
+		fmt.Fprintf(w, "\nINTERVAL START (%s)\tCOUNT\n", stats.Interval)
+		for _, bucket := range stats.Series {
+			fmt.Fprintf(w, "%s\t%d\n", bucket.Start.Local().Format(time.DateTime), bucket.Count)
+		}
+	}
+	return w.Flush()
+}
+
+// printCounts prints counts in descending order
+func printCounts(w *tabwriter.Writer, title string, counts map[string]int64) {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	fmt.Fprintf(w, "\n%s\tCOUNT\n", title)
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s\t%d\n", key, counts[key])
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+)
+
+func runTail(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("tail", flag.ContinueOnError)
+	client := connectionFlags(fs)
+	filter := filterFlags(fs, false)
+	output := fs.String("o", outputTable, "Output format: table, json or csv")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := validOutput(*output); err != nil {
+		return err
+	}
+	if client.token == "" {
+		return errors.New("no token: pass --token or set AUDITCTL_TOKEN")
+	}
+
+	streamURL := client.url("/logs/stream", nil)
+	streamURL = "ws" + strings.TrimPrefix(streamURL, "http")
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+client.token)
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, streamURL, header)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", streamURL, err)
+	}
+	defer conn.Close()
+	fmt.Fprintf(os.Stderr, "Streaming logs from %s, press Ctrl+C to stop\n", streamURL)
+
+	// Close the connection on interrupt to unblock ReadMessage
+	go func() {
+		<-ctx.Done()
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		conn.Close()
+	}()
+
+	w := newLogWriter(os.Stdout, *output)
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				return w.Flush()
+			}
+			return fmt.Errorf("stream closed: %w", err)
+		}
+
+		var log dto.AuditLogResponse
+		if err := json.Unmarshal(message, &log); err != nil {
+			continue
+		}
+		if !filter.matches(&log) {
+			continue
+		}
+		if err := w.Write(&log); err != nil {
+			return err
+		}
+		// Show each log as it arrives
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+}
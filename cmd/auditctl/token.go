@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// runToken signs an offline token with JWT_SECRET_KEY, the same way the API's
+// HMAC tokens are verified. Offline tokens carry no ID, so they cannot be
+// revoked and should only be used in development.
+func runToken(_ context.Context, args []string) error {
+	defaultHours, err := strconv.Atoi(os.Getenv("JWT_EXPIRATION_HOURS"))
+	if err != nil || defaultHours <= 0 {
+		defaultHours = 24
+	}
+
+	fs := flag.NewFlagSet("token", flag.ContinueOnError)
+	userID := fs.String("user", "", "User ID for the token")
+	tenantID := fs.String("tenant", "", "Tenant ID for the token")
+	roles := fs.String("roles", "", "Comma-separated list of roles")
+	expiration := fs.Duration("exp", time.Duration(defaultHours)*time.Hour, "Token lifetime (default $JWT_EXPIRATION_HOURS or 24h)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *userID == "" || *tenantID == "" {
+		return errors.New("--user and --tenant are required")
+	}
+
+	secret := os.Getenv("JWT_SECRET_KEY")
+	if secret == "" {
+		return errors.New("JWT_SECRET_KEY is not set")
+	}
+
+	rolesList := []string{}
+	if *roles != "" {
+		rolesList = strings.Split(*roles, ",")
+	}
+
+	now := time.Now()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id":   *userID,
+		"tenant_id": *tenantID,
+		"roles":     rolesList,
+		"exp":       now.Add(*expiration).Unix(),
+		"iat":       now.Unix(),
+	}).SignedString([]byte(secret))
+	if err != nil {
+		return fmt.Errorf("failed to sign token: %w", err)
+	}
+
+	fmt.Println(token)
+	return nil
+}
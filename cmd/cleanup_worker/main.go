@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
@@ -10,8 +11,12 @@ import (
 	"github.com/joho/godotenv"
 
 	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/repository/opensearch"
 	"github.com/kingrain94/audit-log-api/internal/repository/postgres"
+	"github.com/kingrain94/audit-log-api/internal/service/pubsub"
 	"github.com/kingrain94/audit-log-api/internal/service/queue"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
 	"github.com/kingrain94/audit-log-api/internal/worker"
 	"github.com/kingrain94/audit-log-api/pkg/logger"
 )
@@ -25,6 +30,12 @@ func main() {
 	// Initialize logger
 	appLogger := logger.NewLogger(os.Getenv("APP_ENV"))
 
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), config.DefaultTracingConfig("audit-log-cleanup-worker"))
+	if err != nil {
+		appLogger.Fatal("Failed to initialize tracing", err)
+	}
+
 	// Initialize PostgreSQL with database connections
 	dbConnections, err := config.NewDatabaseConnections()
 	if err != nil {
@@ -34,23 +45,57 @@ func main() {
 
 	pgRepo := postgres.NewPostgresRepository(dbConnections)
 
-	// Initialize SQS
-	sqsConfig := config.DefaultSQSConfig()
-	sqsClient, err := sqsConfig.GetClient()
+	// Initialize OpenSearch, purged along with PostgreSQL
+	osConfig := config.DefaultOpenSearchConfig()
+	osClients, err := osConfig.GetClusterClients()
+	if err != nil {
+		appLogger.Fatal("Failed to connect to OpenSearch", err)
+	}
+	osRepo := opensearch.NewClusterRepository(osClients, osConfig, opensearch.NewTenantSettings(pgRepo.Tenant(), osConfig.TenantSettingsCacheTTL))
+
+	// Initialize Redis, which carries the cleanup completion events
+	redisConfig := config.DefaultRedisConfig()
+	redisClient, err := redisConfig.GetClient()
+	if err != nil {
+		appLogger.Fatal("Failed to connect to Redis", err)
+	}
+	defer redisClient.Close()
+
+	// Initialize the message queue (SQS or Kafka, per QUEUE_BACKEND)
+	messageQueue, err := queue.New(config.DefaultQueueConfig())
 	if err != nil {
-		appLogger.Fatal("Failed to connect to SQS", err)
+		appLogger.Fatal("Failed to connect to message queue", err)
+	}
+	defer messageQueue.Close()
+
+	// Goroutines, poll interval and receive size unless overridden by WORKER_* settings
+	workerConfig := config.DefaultWorkerConfig(1, 5*time.Second, 10)
+	if err := workerConfig.Validate(); err != nil {
+		appLogger.Fatal("Invalid worker configuration", err)
+	}
+
+	broker, err := pubsub.New(redisClient, config.DefaultPubSubConfig(), appLogger)
+	if err != nil {
+		appLogger.Fatal("Invalid pub/sub configuration", err)
 	}
-	sqsService := queue.NewSQSService(sqsClient, sqsConfig)
 
 	// Create cleanup worker
 	cleanupWorker := worker.NewCleanupWorker(
-		sqsService,
+		messageQueue,
 		pgRepo,
+		osRepo,
+		broker,
 		appLogger,
-		1,             // worker count
-		5*time.Second, // poll interval
+		workerConfig, // concurrency, pacing, drain timeout and visibility extension
 	)
 
+	// Expose Prometheus metrics
+	metricsConfig := config.DefaultMetricsConfig(":9103")
+	metricsServer := metrics.NewServer(metricsConfig.Addr)
+	metricsServer.Start(func(err error) {
+		appLogger.Error("Metrics server failed", err)
+	})
+
 	// Setup graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -67,5 +112,14 @@ func main() {
 
 	// Stop worker
 	cleanupWorker.Stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+		appLogger.Error("Failed to shutdown metrics server", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		appLogger.Error("Failed to flush traces", err)
+	}
 	appLogger.Info("Cleanup worker stopped")
 }
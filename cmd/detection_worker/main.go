@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/repository/opensearch"
+	"github.com/kingrain94/audit-log-api/internal/repository/postgres"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	"github.com/kingrain94/audit-log-api/internal/service/cache"
+	"github.com/kingrain94/audit-log-api/internal/service/geoip"
+	"github.com/kingrain94/audit-log-api/internal/service/pubsub"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+	"github.com/kingrain94/audit-log-api/internal/worker"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+func main() {
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: .env file not found")
+	}
+
+	// Initialize logger
+	appLogger := logger.NewLogger(os.Getenv("APP_ENV"))
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), config.DefaultTracingConfig("audit-log-detection-worker"))
+	if err != nil {
+		appLogger.Fatal("Failed to initialize tracing", err)
+	}
+
+	detectionConfig := config.DefaultDetectionConfig()
+	if err := detectionConfig.Validate(); err != nil {
+		appLogger.Fatal("Invalid detection configuration", err)
+	}
+
+	// Load the GeoIP database impossible travel locates addresses with
+	var locator service.GeoLocator
+	if detectionConfig.ImpossibleTravelEnabled() {
+		database, err := geoip.Open(detectionConfig.GeoIPDatabase)
+		if err != nil {
+			appLogger.Fatal("Failed to load GeoIP database", err)
+		}
+		appLogger.Infof("Loaded %d GeoIP ranges", database.Len())
+		locator = database
+	}
+
+	// Initialize PostgreSQL with database connections
+	dbConnections, err := config.NewDatabaseConnections()
+	if err != nil {
+		appLogger.Fatal("Failed to connect to PostgreSQL", err)
+	}
+	defer dbConnections.Close()
+
+	pgRepo := postgres.NewPostgresRepository(dbConnections)
+
+	// Initialize Redis
+	redisConfig := config.DefaultRedisConfig()
+	redisClient, err := redisConfig.GetClient()
+	if err != nil {
+		appLogger.Fatal("Failed to connect to Redis", err)
+	}
+	defer redisClient.Close()
+
+	broker, err := pubsub.New(redisClient, config.DefaultPubSubConfig(), appLogger)
+	if err != nil {
+		appLogger.Fatal("Invalid pub/sub configuration", err)
+	}
+	defer broker.Close()
+
+	// Create detection worker
+	detector := service.NewDetectionService(
+		pgRepo,
+		opensearch.NewTenantSettings(pgRepo.Tenant(), config.DefaultOpenSearchConfig().TenantSettingsCacheTTL),
+		service.NewWebhookSender(detectionConfig.WebhookTimeout),
+		cache.NewAlertCooldown(redisClient),
+		locator,
+		detectionConfig,
+	)
+	detectionWorker := worker.NewDetectionWorker(detector, broker, appLogger, detectionConfig.QueueSize)
+
+	// Expose Prometheus metrics
+	metricsConfig := config.DefaultMetricsConfig(":9115")
+	metricsServer := metrics.NewServer(metricsConfig.Addr)
+	metricsServer.Start(func(err error) {
+		appLogger.Error("Metrics server failed", err)
+	})
+
+	// Setup graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	// Start worker
+	if err := detectionWorker.Start(); err != nil {
+		appLogger.Fatal("Failed to start detection worker", err)
+	}
+
+	// Wait for shutdown signal
+	<-sigChan
+	appLogger.Info("Shutting down detection worker...")
+
+	// Stop worker
+	detectionWorker.Stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+		appLogger.Error("Failed to shutdown metrics server", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		appLogger.Error("Failed to flush traces", err)
+	}
+	appLogger.Info("Detection worker stopped")
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/repository/opensearch"
+	"github.com/kingrain94/audit-log-api/internal/repository/postgres"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	"github.com/kingrain94/audit-log-api/internal/service/queue"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+	"github.com/kingrain94/audit-log-api/internal/worker"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+func main() {
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: .env file not found")
+	}
+
+	// Initialize logger
+	appLogger := logger.NewLogger(os.Getenv("APP_ENV"))
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), config.DefaultTracingConfig("audit-log-export-worker"))
+	if err != nil {
+		appLogger.Fatal("Failed to initialize tracing", err)
+	}
+
+	// Initialize PostgreSQL with database connections
+	dbConnections, err := config.NewDatabaseConnections()
+	if err != nil {
+		appLogger.Fatal("Failed to connect to PostgreSQL", err)
+	}
+	defer dbConnections.Close()
+
+	pgRepo := postgres.NewPostgresRepository(dbConnections)
+
+	// Initialize OpenSearch, which exports with a full-text query are scanned in
+	osConfig := config.DefaultOpenSearchConfig()
+	osClients, err := osConfig.GetClusterClients()
+	if err != nil {
+		appLogger.Fatal("Failed to connect to OpenSearch", err)
+	}
+	osRepo := opensearch.NewClusterRepository(osClients, osConfig, opensearch.NewTenantSettings(pgRepo.Tenant(), osConfig.TenantSettingsCacheTTL))
+
+	// Initialize the message queue (SQS or Kafka, per QUEUE_BACKEND)
+	messageQueue, err := queue.New(config.DefaultQueueConfig())
+	if err != nil {
+		appLogger.Fatal("Failed to connect to message queue", err)
+	}
+	defer messageQueue.Close()
+
+	// Initialize S3
+	s3Config := config.DefaultS3Config()
+	s3Client, err := s3Config.GetClient(context.Background())
+	if err != nil {
+		appLogger.Fatal("Failed to connect to S3", err)
+	}
+
+	// Load the key exports are signed with, if any
+	var signer *service.ExportSigner
+	if signingConfig := config.DefaultExportSigningConfig(); signingConfig.Enabled() {
+		key, err := signingConfig.PrivateKey()
+		if err != nil {
+			appLogger.Fatal("Failed to load export signing key", err)
+		}
+		signer = service.NewExportSigner(key)
+	}
+
+	// Goroutines, poll interval and receive size unless overridden by WORKER_* settings
+	workerConfig := config.DefaultWorkerConfig(1, 5*time.Second, 1)
+	if err := workerConfig.Validate(); err != nil {
+		appLogger.Fatal("Invalid worker configuration", err)
+	}
+
+	// Create export worker
+	exportWorker := worker.NewExportWorker(
+		messageQueue,
+		pgRepo,
+		osRepo,
+		appLogger,
+		workerConfig, // concurrency, pacing, drain timeout and visibility extension
+		s3Client,     // S3 client
+		s3Config,     // S3 configuration
+		signer,       // signs exports asking for a signature; nil without EXPORT_SIGNING_KEY_FILE
+	)
+
+	// Expose Prometheus metrics
+	metricsConfig := config.DefaultMetricsConfig(":9105")
+	metricsServer := metrics.NewServer(metricsConfig.Addr)
+	metricsServer.Start(func(err error) {
+		appLogger.Error("Metrics server failed", err)
+	})
+
+	// Setup graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	// Start worker
+	go func() {
+		appLogger.Info("Starting export worker...")
+		exportWorker.Start()
+	}()
+
+	// Wait for shutdown signal
+	<-sigChan
+	appLogger.Info("Shutting down export worker...")
+
+	// Stop worker
+	exportWorker.Stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+		appLogger.Error("Failed to shutdown metrics server", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		appLogger.Error("Failed to flush traces", err)
+	}
+	appLogger.Info("Export worker stopped")
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/repository/postgres"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+	"github.com/kingrain94/audit-log-api/internal/worker"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+func main() {
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: .env file not found")
+	}
+
+	// Initialize logger
+	appLogger := logger.NewLogger(os.Getenv("APP_ENV"))
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), config.DefaultTracingConfig("audit-log-forwarding-worker"))
+	if err != nil {
+		appLogger.Fatal("Failed to initialize tracing", err)
+	}
+
+	forwardingConfig := config.DefaultForwardingConfig()
+	if err := forwardingConfig.Validate(); err != nil {
+		appLogger.Fatal("Invalid forwarding configuration", err)
+	}
+	// Integrations' tokens are stored encrypted for this key
+	tokenKey, err := config.DefaultIntegrationTokenConfig().PrivateKey()
+	if err != nil {
+		appLogger.Fatal("Failed to load integration token private key", err)
+	}
+
+	// Initialize PostgreSQL with database connections
+	dbConnections, err := config.NewDatabaseConnections()
+	if err != nil {
+		appLogger.Fatal("Failed to connect to PostgreSQL", err)
+	}
+	defer dbConnections.Close()
+
+	// Create forwarding worker
+	forwarder := service.NewForwardingService(
+		postgres.NewPostgresRepository(dbConnections),
+		service.NewHTTPLogForwarder(forwardingConfig.RequestTimeout),
+		service.NewIntegrationTokenOpener(tokenKey),
+		forwardingConfig,
+	)
+	forwardingWorker := worker.NewForwardingWorker(forwarder, forwardingConfig, appLogger)
+
+	// Expose Prometheus metrics
+	metricsConfig := config.DefaultMetricsConfig(":9116")
+	metricsServer := metrics.NewServer(metricsConfig.Addr)
+	metricsServer.Start(func(err error) {
+		appLogger.Error("Metrics server failed", err)
+	})
+
+	// Setup graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	// Start worker
+	forwardingWorker.Start()
+
+	// Wait for shutdown signal
+	<-sigChan
+	appLogger.Info("Shutting down forwarding worker...")
+
+	// Stop worker
+	forwardingWorker.Stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+		appLogger.Error("Failed to shutdown metrics server", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		appLogger.Error("Failed to flush traces", err)
+	}
+	appLogger.Info("Forwarding worker stopped")
+}
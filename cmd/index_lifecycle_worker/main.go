@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/repository/opensearch"
+	"github.com/kingrain94/audit-log-api/internal/repository/postgres"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+	"github.com/kingrain94/audit-log-api/internal/worker"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+func main() {
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: .env file not found")
+	}
+
+	// Initialize logger
+	appLogger := logger.NewLogger(os.Getenv("APP_ENV"))
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), config.DefaultTracingConfig("audit-log-index-lifecycle-worker"))
+	if err != nil {
+		appLogger.Fatal("Failed to initialize tracing", err)
+	}
+
+	// Initialize PostgreSQL, where tenants keep their retention settings
+	dbConnections, err := config.NewDatabaseConnections()
+	if err != nil {
+		appLogger.Fatal("Failed to connect to PostgreSQL", err)
+	}
+	defer dbConnections.Close()
+
+	pgRepo := postgres.NewPostgresRepository(dbConnections)
+
+	// Initialize OpenSearch
+	osConfig := config.DefaultOpenSearchConfig()
+	osClients, err := osConfig.GetClusterClients()
+	if err != nil {
+		appLogger.Fatal("Failed to connect to OpenSearch", err)
+	}
+
+	// Create an index lifecycle worker per cluster. A tenant only writes to
+	// the cluster of its region, so each cluster's indices are managed alone.
+	lifecycleConfig := config.DefaultIndexLifecycleConfig()
+	if err := lifecycleConfig.Validate(); err != nil {
+		appLogger.Fatal("Invalid index lifecycle configuration", err)
+	}
+	var lifecycleWorkers []*worker.IndexLifecycleWorker
+	for _, osClient := range osClients {
+		lifecycleWorkers = append(lifecycleWorkers, worker.NewIndexLifecycleWorker(
+			opensearch.NewLifecycleManager(osClient, osConfig),
+			pgRepo.Tenant(),
+			lifecycleConfig,
+			appLogger,
+		))
+	}
+
+	// Expose Prometheus metrics
+	metricsConfig := config.DefaultMetricsConfig(":9108")
+	metricsServer := metrics.NewServer(metricsConfig.Addr)
+	metricsServer.Start(func(err error) {
+		appLogger.Error("Metrics server failed", err)
+	})
+
+	// Setup graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	// Start workers
+	for _, lifecycleWorker := range lifecycleWorkers {
+		lifecycleWorker.Start()
+	}
+
+	// Wait for shutdown signal
+	<-sigChan
+	appLogger.Info("Shutting down index lifecycle worker...")
+
+	// Stop workers
+	for _, lifecycleWorker := range lifecycleWorkers {
+		lifecycleWorker.Stop()
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+		appLogger.Error("Failed to shutdown metrics server", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		appLogger.Error("Failed to flush traces", err)
+	}
+	appLogger.Info("Index lifecycle worker stopped")
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
@@ -10,8 +11,13 @@ import (
 	"github.com/joho/godotenv"
 
 	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/repository/clickhouse"
 	"github.com/kingrain94/audit-log-api/internal/repository/opensearch"
+	"github.com/kingrain94/audit-log-api/internal/repository/postgres"
 	"github.com/kingrain94/audit-log-api/internal/service/queue"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
 	"github.com/kingrain94/audit-log-api/internal/worker"
 	"github.com/kingrain94/audit-log-api/pkg/logger"
 )
@@ -25,35 +31,89 @@ func main() {
 	// Initialize logger
 	appLogger := logger.NewLogger(os.Getenv("APP_ENV"))
 
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), config.DefaultTracingConfig("audit-log-index-worker"))
+	if err != nil {
+		appLogger.Fatal("Failed to initialize tracing", err)
+	}
+
 	// Initialize OpenSearch
 	osConfig := config.DefaultOpenSearchConfig()
-	osClient, err := osConfig.GetClient()
+	osClients, err := osConfig.GetClusterClients()
 	if err != nil {
 		appLogger.Fatal("Failed to connect to OpenSearch", err)
 	}
-	osRepo := opensearch.NewRepository(osClient, osConfig)
+
+	// Install the current index template before writing, so indices created
+	// by the first writes of a day get the current mapping version
+	for cluster, osClient := range osClients {
+		if err := opensearch.NewLifecycleManager(osClient, osConfig).PutIndexTemplate(context.Background()); err != nil {
+			appLogger.Errorf("Failed to put index template on cluster %s: %v", cluster, err)
+		}
+	}
 
 	appLogger.Info("OpenSearch connection established for index worker")
 
-	// Initialize SQS
-	sqsConfig := config.DefaultSQSConfig()
-	sqsClient, err := sqsConfig.GetClient()
+	// Initialize PostgreSQL, which keeps the logs OpenSearch rejected
+	dbConnections, err := config.NewDatabaseConnections()
+	if err != nil {
+		appLogger.Fatal("Failed to connect to PostgreSQL", err)
+	}
+	defer dbConnections.Close()
+
+	// Tenants' settings route their logs to the cluster of their region and
+	// list the metadata keys indexed under the indexed_keys mapping
+	osRepo := opensearch.NewClusterRepository(osClients, osConfig,
+		opensearch.NewTenantSettings(postgres.NewPostgresRepository(dbConnections).Tenant(), osConfig.TenantSettingsCacheTTL))
+
+	// Copy indexed logs to ClickHouse when it serves the stats
+	chConfig := config.DefaultClickHouseConfig()
+	if err := chConfig.Validate(); err != nil {
+		appLogger.Fatal("Invalid ClickHouse configuration", err)
+	}
+	var analyticsRepo repository.AnalyticsRepository
+	if chConfig.Enabled() {
+		chConn, err := chConfig.Open()
+		if err != nil {
+			appLogger.Fatal("Failed to connect to ClickHouse", err)
+		}
+		defer chConn.Close()
+		analyticsRepo = clickhouse.NewAnalyticsRepository(chConn)
+		appLogger.Info("ClickHouse connection established for index worker")
+	}
+
+	// Initialize the message queue (SQS or Kafka, per QUEUE_BACKEND)
+	messageQueue, err := queue.New(config.DefaultQueueConfig())
 	if err != nil {
-		appLogger.Fatal("Failed to connect to SQS", err)
+		appLogger.Fatal("Failed to connect to message queue", err)
 	}
-	sqsService := queue.NewSQSService(sqsClient, sqsConfig)
+	defer messageQueue.Close()
 
 	appLogger.Info("SQS connection established for index worker")
 
+	// Goroutines, poll interval and receive size unless overridden by WORKER_* settings
+	workerConfig := config.DefaultWorkerConfig(1, 5*time.Second, 10)
+	if err := workerConfig.Validate(); err != nil {
+		appLogger.Fatal("Invalid worker configuration", err)
+	}
+
 	// Initialize SQS worker
 	sqsWorker := worker.NewSQSWorker(
-		sqsService,
+		messageQueue,
 		osRepo,
+		postgres.NewIndexFailureRepository(dbConnections.Writer),
+		analyticsRepo,
 		appLogger,
-		1,             // 3 worker goroutines
-		5*time.Second, // Poll every 5 seconds
+		workerConfig, // concurrency, pacing, drain timeout and visibility extension
 	)
 
+	// Expose Prometheus metrics
+	metricsConfig := config.DefaultMetricsConfig(":9101")
+	metricsServer := metrics.NewServer(metricsConfig.Addr)
+	metricsServer.Start(func(err error) {
+		appLogger.Error("Metrics server failed", err)
+	})
+
 	// Start the worker
 	sqsWorker.Start()
 	appLogger.Info("SQS worker started")
@@ -66,6 +126,15 @@ func main() {
 	// Stop the worker
 	appLogger.Info("Shutting down worker...")
 	sqsWorker.Stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+		appLogger.Error("Failed to shutdown metrics server", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		appLogger.Error("Failed to flush traces", err)
+	}
 	appLogger.Info("Worker stopped")
 	appLogger.Sync()
 }
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/repository/postgres"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	"github.com/kingrain94/audit-log-api/internal/service/cache"
+	"github.com/kingrain94/audit-log-api/internal/service/queue"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+	"github.com/kingrain94/audit-log-api/internal/worker"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+func main() {
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: .env file not found")
+	}
+
+	// Initialize logger
+	appLogger := logger.NewLogger(os.Getenv("APP_ENV"))
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), config.DefaultTracingConfig("audit-log-ingest-worker"))
+	if err != nil {
+		appLogger.Fatal("Failed to initialize tracing", err)
+	}
+
+	// Initialize PostgreSQL with database connections
+	dbConnections, err := config.NewDatabaseConnections()
+	if err != nil {
+		appLogger.Fatal("Failed to connect to PostgreSQL", err)
+	}
+	defer dbConnections.Close()
+
+	pgRepo := postgres.NewPostgresRepository(dbConnections)
+
+	// Initialize the message queue (SQS or Kafka, per QUEUE_BACKEND)
+	messageQueue, err := queue.New(config.DefaultQueueConfig())
+	if err != nil {
+		appLogger.Fatal("Failed to connect to message queue", err)
+	}
+	defer messageQueue.Close()
+
+	// Goroutines, poll interval and receive size unless overridden by WORKER_* settings;
+	// poll right after each batch since receives long poll
+	workerConfig := config.DefaultWorkerConfig(3, 100*time.Millisecond, 10)
+	if err := workerConfig.Validate(); err != nil {
+		appLogger.Fatal("Invalid worker configuration", err)
+	}
+
+	// Advance the ingest watermarks the API derives ETags and cached stats from
+	redisClient, err := config.DefaultRedisConfig().GetClient()
+	if err != nil {
+		appLogger.Fatal("Failed to connect to Redis", err)
+	}
+	defer redisClient.Close()
+	ingestService := service.NewIngestService(pgRepo)
+	ingestService.UseWatermark(cache.NewIngestWatermark(redisClient))
+
+	// Create ingest worker
+	ingestWorker := worker.NewIngestWorker(
+		messageQueue,
+		ingestService,
+		appLogger,
+		workerConfig, // concurrency, pacing, drain timeout and visibility extension
+	)
+
+	// Expose Prometheus metrics
+	metricsConfig := config.DefaultMetricsConfig(":9110")
+	metricsServer := metrics.NewServer(metricsConfig.Addr)
+	metricsServer.Start(func(err error) {
+		appLogger.Error("Metrics server failed", err)
+	})
+
+	// Setup graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	// Start worker
+	ingestWorker.Start()
+
+	// Wait for shutdown signal
+	<-sigChan
+	appLogger.Info("Shutting down ingest worker...")
+
+	// Stop worker
+	ingestWorker.Stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+		appLogger.Error("Failed to shutdown metrics server", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		appLogger.Error("Failed to flush traces", err)
+	}
+	appLogger.Info("Ingest worker stopped")
+	appLogger.Sync()
+}
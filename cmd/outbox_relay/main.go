@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/repository/postgres"
+	"github.com/kingrain94/audit-log-api/internal/service/pubsub"
+	"github.com/kingrain94/audit-log-api/internal/service/queue"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+	"github.com/kingrain94/audit-log-api/internal/worker"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+func main() {
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: .env file not found")
+	}
+
+	// Initialize logger
+	appLogger := logger.NewLogger(os.Getenv("APP_ENV"))
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), config.DefaultTracingConfig("audit-log-outbox-relay"))
+	if err != nil {
+		appLogger.Fatal("Failed to initialize tracing", err)
+	}
+
+	// Initialize PostgreSQL with database connections
+	dbConnections, err := config.NewDatabaseConnections()
+	if err != nil {
+		appLogger.Fatal("Failed to connect to PostgreSQL", err)
+	}
+	defer dbConnections.Close()
+
+	pgRepo := postgres.NewPostgresRepository(dbConnections)
+
+	// Initialize Redis
+	redisConfig := config.DefaultRedisConfig()
+	redisClient, err := redisConfig.GetClient()
+	if err != nil {
+		appLogger.Fatal("Failed to connect to Redis", err)
+	}
+	defer redisClient.Close()
+
+	redisPubSub, err := pubsub.New(redisClient, config.DefaultPubSubConfig(), appLogger)
+	if err != nil {
+		appLogger.Fatal("Invalid pub/sub configuration", err)
+	}
+
+	// Initialize the message queue (SQS or Kafka, per QUEUE_BACKEND)
+	messageQueue, err := queue.New(config.DefaultQueueConfig())
+	if err != nil {
+		appLogger.Fatal("Failed to connect to message queue", err)
+	}
+	defer messageQueue.Close()
+
+	// Create outbox relay
+	outboxRelay := worker.NewOutboxRelay(
+		messageQueue,
+		redisPubSub,
+		pgRepo,
+		appLogger,
+		500*time.Millisecond, // poll interval
+		100,                  // events per batch
+	)
+
+	// Expose Prometheus metrics
+	metricsConfig := config.DefaultMetricsConfig(":9104")
+	metricsServer := metrics.NewServer(metricsConfig.Addr)
+	metricsServer.Start(func(err error) {
+		appLogger.Error("Metrics server failed", err)
+	})
+
+	// Setup graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	// Start relay
+	outboxRelay.Start()
+	appLogger.Info("Outbox relay started")
+
+	// Wait for shutdown signal
+	<-sigChan
+	appLogger.Info("Shutting down outbox relay...")
+
+	// Stop relay
+	outboxRelay.Stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+		appLogger.Error("Failed to shutdown metrics server", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		appLogger.Error("Failed to flush traces", err)
+	}
+	appLogger.Info("Outbox relay stopped")
+	appLogger.Sync()
+}
@@ -0,0 +1,133 @@
+// Command reindex backfills OpenSearch with a tenant's audit logs from
+// PostgreSQL, such as after an index was lost or its mapping changed:
+//
+//	reindex -tenant <id> -start 2025-01-01 -end 2025-06-30
+//
+// Logs are bulk indexed in batches and the reindex job is checkpointed after
+// each one. A job that fails or is interrupted is resumed where it stopped
+// with:
+//
+//	reindex -tenant <id> -job <job id>
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joho/godotenv"
+	"go.uber.org/zap"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/repository/composite"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+	"github.com/kingrain94/audit-log-api/pkg/utils"
+)
+
+func main() {
+	tenantID := flag.String("tenant", "", "ID of the tenant whose logs to reindex")
+	startFlag := flag.String("start", "", "start of the range to reindex, RFC3339 or YYYY-MM-DD")
+	endFlag := flag.String("end", "", "end of the range to reindex, RFC3339 or YYYY-MM-DD (inclusive)")
+	jobID := flag.String("job", "", "ID of a reindex job to resume instead of starting one")
+	flag.Parse()
+
+	if *tenantID == "" || (*jobID == "" && (*startFlag == "" || *endFlag == "")) {
+		fmt.Fprintln(os.Stderr, "usage: reindex -tenant <id> (-start <time> -end <time> | -job <job id>)")
+		os.Exit(2)
+	}
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: .env file not found")
+	}
+
+	// Initialize logger
+	appLogger := logger.NewLogger(os.Getenv("APP_ENV"))
+
+	// Initialize PostgreSQL, which the logs are read from and the job is tracked in
+	dbConnections, err := config.NewDatabaseConnections()
+	if err != nil {
+		appLogger.Fatal("Failed to connect to PostgreSQL", err)
+	}
+	defer dbConnections.Close()
+
+	// Initialize OpenSearch
+	osConfig := config.DefaultOpenSearchConfig()
+	osClients, err := osConfig.GetClusterClients()
+	if err != nil {
+		appLogger.Fatal("Failed to connect to OpenSearch", err)
+	}
+
+	// The command indexes the logs itself rather than queueing them, so it
+	// needs no publisher
+	indexService := service.NewSearchIndexService(composite.NewCompositeRepository(dbConnections, osClients, osConfig), nil)
+
+	// Stop at the current batch on SIGINT or SIGTERM; the job keeps its checkpoint
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var job *domain.ReindexJob
+	if *jobID != "" {
+		job, err = indexService.GetReindexJob(ctx, *tenantID, *jobID)
+		if err != nil {
+			appLogger.Fatal("Failed to load reindex job", err)
+		}
+		appLogger.Info("Resuming reindex job", zap.String("job_id", job.ID), zap.Int64("indexed", job.IndexedCount), zap.Int64("total", job.TotalCount))
+	} else {
+		start, err := utils.ParseUserTime(*startFlag, false)
+		if err != nil {
+			appLogger.Fatal("Invalid start time", err)
+		}
+		end, err := utils.ParseUserTime(*endFlag, true)
+		if err != nil {
+			appLogger.Fatal("Invalid end time", err)
+		}
+		job, err = indexService.CreateReindexJob(ctx, *tenantID, start, end)
+		if err != nil {
+			appLogger.Fatal("Failed to create reindex job", err)
+		}
+		appLogger.Info("Started reindex job", zap.String("job_id", job.ID), zap.Int64("total", job.TotalCount))
+	}
+
+	err = indexService.RunReindexJob(ctx, job, func(job *domain.ReindexJob) {
+		appLogger.Info("Reindex progress",
+			zap.String("job_id", job.ID),
+			zap.Int64("indexed", job.IndexedCount),
+			zap.Int64("failed", job.FailedCount),
+			zap.Int64("total", job.TotalCount),
+			zap.String("percent", percent(job)),
+			zap.Timep("cursor", job.CursorTimestamp))
+	})
+	if errors.Is(err, service.ErrReindexJobCompleted) {
+		appLogger.Info("Reindex job already completed", zap.String("job_id", job.ID))
+		return
+	}
+	if err != nil {
+		appLogger.Error("Reindex job stopped; resume it with -job "+job.ID, err)
+		os.Exit(1)
+	}
+
+	appLogger.Info("Reindex job completed",
+		zap.String("job_id", job.ID),
+		zap.Int64("indexed", job.IndexedCount),
+		zap.Int64("failed", job.FailedCount))
+	if job.FailedCount > 0 {
+		appLogger.Warnf("%d logs were rejected by OpenSearch; reprocess them with POST /admin/index-failures/reprocess", job.FailedCount)
+	}
+}
+
+// percent formats the share of the job's logs processed so far. Logs written
+// into the range after the job was created can take it past 100%.
+func percent(job *domain.ReindexJob) string {
+	if job.TotalCount == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.1f%%", float64(job.IndexedCount+job.FailedCount)*100/float64(job.TotalCount))
+}
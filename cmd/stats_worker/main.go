@@ -0,0 +1,134 @@
+// Command stats_worker keeps the audit_logs_hourly_stats table up to date,
+// rolling up the logs written since its last run every STATS_ROLLUP_INTERVAL.
+//
+// Hours whose stats are missing or wrong, such as after restoring a database
+// backup, are recomputed with a backfill, which exits once done:
+//
+//	stats_worker -backfill -start 2025-01-01 -end 2025-06-30 [-tenant <id>]
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+	"go.uber.org/zap"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/repository/postgres"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+	"github.com/kingrain94/audit-log-api/internal/worker"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+	"github.com/kingrain94/audit-log-api/pkg/utils"
+)
+
+func main() {
+	backfill := flag.Bool("backfill", false, "recompute the hourly stats of a range, then exit")
+	tenantID := flag.String("tenant", "", "ID of the tenant whose stats to backfill (default: every tenant)")
+	startFlag := flag.String("start", "", "start of the range to backfill, RFC3339 or YYYY-MM-DD")
+	endFlag := flag.String("end", "", "end of the range to backfill, RFC3339 or YYYY-MM-DD (inclusive)")
+	flag.Parse()
+
+	if *backfill && (*startFlag == "" || *endFlag == "") {
+		fmt.Fprintln(os.Stderr, "usage: stats_worker [-backfill -start <time> -end <time> [-tenant <id>]]")
+		os.Exit(2)
+	}
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: .env file not found")
+	}
+
+	// Initialize logger
+	appLogger := logger.NewLogger(os.Getenv("APP_ENV"))
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), config.DefaultTracingConfig("audit-log-stats-worker"))
+	if err != nil {
+		appLogger.Fatal("Failed to initialize tracing", err)
+	}
+
+	// Initialize PostgreSQL with database connections
+	dbConnections, err := config.NewDatabaseConnections()
+	if err != nil {
+		appLogger.Fatal("Failed to connect to PostgreSQL", err)
+	}
+	defer dbConnections.Close()
+
+	if dbConnections.StorageMode == config.StorageModeTimescale {
+		appLogger.Info("TimescaleDB maintains the hourly stats in timescale storage mode, nothing to do")
+		return
+	}
+
+	// Create stats worker
+	statsConfig := config.DefaultStatsRollupConfig()
+	if err := statsConfig.Validate(); err != nil {
+		appLogger.Fatal("Invalid stats rollup configuration", err)
+	}
+	statsWorker := worker.NewStatsWorker(
+		postgres.NewStatsRollup(dbConnections.Writer),
+		statsConfig,
+		appLogger,
+	)
+
+	if *backfill {
+		start, err := utils.ParseUserTime(*startFlag, false)
+		if err != nil {
+			appLogger.Fatal("Invalid start time", err)
+		}
+		end, err := utils.ParseUserTime(*endFlag, true)
+		if err != nil {
+			appLogger.Fatal("Invalid end time", err)
+		}
+
+		// Stop after the current day on SIGINT or SIGTERM; days done stay done
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		buckets, err := statsWorker.Backfill(ctx, *tenantID, start, end)
+		if err != nil {
+			appLogger.Error("Hourly stats backfill stopped", err)
+			os.Exit(1)
+		}
+		appLogger.Info("Hourly stats backfill completed", zap.Int64("buckets", buckets))
+		return
+	}
+
+	// Expose Prometheus metrics
+	metricsConfig := config.DefaultMetricsConfig(":9114")
+	metricsServer := metrics.NewServer(metricsConfig.Addr)
+	metricsServer.Start(func(err error) {
+		appLogger.Error("Metrics server failed", err)
+	})
+
+	// Setup graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	// Start worker
+	statsWorker.Start()
+
+	// Wait for shutdown signal
+	<-sigChan
+	appLogger.Info("Shutting down stats worker...")
+
+	// Stop worker
+	statsWorker.Stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+		appLogger.Error("Failed to shutdown metrics server", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		appLogger.Error("Failed to flush traces", err)
+	}
+	appLogger.Info("Stats worker stopped")
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/ingest"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/repository/composite"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	"github.com/kingrain94/audit-log-api/internal/service/cache"
+	"github.com/kingrain94/audit-log-api/internal/service/queue"
+	"github.com/kingrain94/audit-log-api/internal/service/reputation"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+func main() {
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: .env file not found")
+	}
+
+	// Initialize logger
+	appLogger := logger.NewLogger(os.Getenv("APP_ENV"))
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), config.DefaultTracingConfig("audit-log-syslog-ingest"))
+	if err != nil {
+		appLogger.Fatal("Failed to initialize tracing", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		appLogger.Fatal("Failed to load config", err)
+	}
+
+	dbConnections, err := config.NewDatabaseConnections()
+	if err != nil {
+		appLogger.Fatal("Failed to connect to database", err)
+	}
+	defer dbConnections.Close()
+
+	// Initialize OpenSearch
+	osConfig := config.DefaultOpenSearchConfig()
+	osClients, err := osConfig.GetClusterClients()
+	if err != nil {
+		appLogger.Fatal("Failed to connect to OpenSearch", err)
+	}
+
+	// Initialize Redis for the redaction rule cache and usage counters
+	redisConfig := config.DefaultRedisConfig()
+	redisClient, err := redisConfig.GetClient()
+	if err != nil {
+		appLogger.Fatal("Failed to connect to Redis", err)
+	}
+	defer redisClient.Close()
+
+	// Initialize the message queue (SQS or Kafka, per QUEUE_BACKEND)
+	messageQueue, err := queue.New(config.DefaultQueueConfig())
+	if err != nil {
+		appLogger.Fatal("Failed to connect to message queue", err)
+	}
+	defer messageQueue.Close()
+
+	repo := composite.NewCompositeRepository(dbConnections, osClients, osConfig)
+
+	quotaConfig := config.DefaultQuotaConfig()
+	if err := quotaConfig.Validate(); err != nil {
+		appLogger.Fatal("Invalid quota configuration", err)
+	}
+
+	// Syslog messages go through the same enrichment, tagging, redaction, quota and outbox pipeline as the API
+	redactionService := service.NewRedactionService(repo, cache.NewRedactionRuleCache(redisClient, cfg.RedactionRuleCacheTTL))
+	schemaService := service.NewSchemaService(repo, cache.NewResourceSchemaCache(redisClient, cfg.ResourceSchemaCacheTTL))
+	usageService := service.NewUsageService(cache.NewUsageCounter(redisClient), quotaConfig)
+	auditLogService := service.NewAuditLogService(repo, messageQueue, nil, redactionService, schemaService, usageService)
+	auditLogService.UseTagger(service.NewTaggingService(repo, cache.NewTaggingRuleCache(redisClient, cfg.TaggingRuleCacheTTL)))
+	threatIntelConfig := config.DefaultThreatIntelConfig()
+	if err := threatIntelConfig.Validate(); err != nil {
+		appLogger.Fatal("Invalid threat intelligence configuration", err)
+	}
+	if threatIntelConfig.Enabled() {
+		var providers []service.IPReputationProvider
+		if slices.Contains(threatIntelConfig.Providers, config.ThreatIntelProviderBlocklist) {
+			blocklist := reputation.NewBlocklist(threatIntelConfig, appLogger)
+			blocklist.Start(threatIntelConfig.RefreshInterval)
+			defer blocklist.Stop()
+			providers = append(providers, blocklist)
+		}
+		if slices.Contains(threatIntelConfig.Providers, config.ThreatIntelProviderAbuseIPDB) {
+			providers = append(providers, reputation.NewAbuseIPDB(threatIntelConfig, cache.NewIPReputationCache(redisClient, threatIntelConfig.CacheTTL)))
+		}
+		auditLogService.UseEnricher(service.NewThreatIntelService(providers, threatIntelConfig))
+	}
+	// Tenant sampling rules and quota warnings apply to syslog messages as well
+	tenantService := service.NewTenantService(repo, cache.NewRateLimitCache(redisClient, cfg.TenantRateLimitCacheTTL), cache.NewTenantSettingsCache(redisClient, cfg.TenantSettingsCacheTTL), config.DefaultTenantDeletionConfig())
+	auditLogService.UseSampling(tenantService, cache.NewSampledLogCounter(redisClient))
+	usageService.UseQuotaWarnings(service.NewQuotaWarningService(repo, tenantService, service.NewWebhookSender(quotaConfig.WebhookTimeout)))
+
+	syslogConfig := config.DefaultSyslogConfig()
+	if err := syslogConfig.Validate(); err != nil {
+		appLogger.Fatal("Invalid syslog configuration", err)
+	}
+	if len(syslogConfig.SourceTokens) == 0 {
+		appLogger.Warn("SYSLOG_SOURCE_TOKENS is empty, every message will be rejected")
+	}
+	syslogServer := ingest.NewSyslogServer(auditLogService, syslogConfig, appLogger)
+
+	// Expose Prometheus metrics
+	metricsConfig := config.DefaultMetricsConfig(":9107")
+	metricsServer := metrics.NewServer(metricsConfig.Addr)
+	metricsServer.Start(func(err error) {
+		appLogger.Error("Metrics server failed", err)
+	})
+
+	// Setup graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	// Start listener
+	if err := syslogServer.Start(); err != nil {
+		appLogger.Fatal("Failed to start syslog server", err)
+	}
+
+	// Wait for shutdown signal
+	<-sigChan
+	appLogger.Info("Shutting down syslog ingest...")
+
+	// Stop listener, flushing pending logs
+	syslogServer.Stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+		appLogger.Error("Failed to shutdown metrics server", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		appLogger.Error("Failed to flush traces", err)
+	}
+	appLogger.Info("Syslog ingest stopped")
+}
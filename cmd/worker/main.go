@@ -0,0 +1,302 @@
+// Command worker runs the index, archive and cleanup queue workers in one
+// process, selected with --mode, so a deployment can ship a single binary
+// instead of one per worker.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/repository/clickhouse"
+	"github.com/kingrain94/audit-log-api/internal/repository/opensearch"
+	"github.com/kingrain94/audit-log-api/internal/repository/postgres"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	"github.com/kingrain94/audit-log-api/internal/service/cache"
+	"github.com/kingrain94/audit-log-api/internal/service/pubsub"
+	"github.com/kingrain94/audit-log-api/internal/service/queue"
+	"github.com/kingrain94/audit-log-api/internal/service/storage"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+	"github.com/kingrain94/audit-log-api/internal/worker"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+const (
+	modeIndex   = "index"
+	modeArchive = "archive"
+	modeCleanup = "cleanup"
+	modeAll     = "all"
+)
+
+var modes = []string{modeIndex, modeArchive, modeCleanup}
+
+// queueWorker is a worker started once and stopped on shutdown
+type queueWorker interface {
+	Start()
+	Stop()
+}
+
+func main() {
+	mode := flag.String("mode", modeAll, "Comma-separated workers to run: index, archive, cleanup, or all")
+	flag.Parse()
+
+	selected, err := parseModes(*mode)
+	if err != nil {
+		log.Fatalf("Invalid --mode: %v", err)
+	}
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: .env file not found")
+	}
+
+	// Initialize logger
+	appLogger := logger.NewLogger(os.Getenv("APP_ENV"))
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), config.DefaultTracingConfig("audit-log-worker"))
+	if err != nil {
+		appLogger.Fatal("Failed to initialize tracing", err)
+	}
+
+	// Initialize PostgreSQL, which every mode uses
+	dbConnections, err := config.NewDatabaseConnections()
+	if err != nil {
+		appLogger.Fatal("Failed to connect to PostgreSQL", err)
+	}
+	defer dbConnections.Close()
+
+	pgRepo := postgres.NewPostgresRepository(dbConnections)
+
+	// Initialize the message queue (SQS or Kafka, per QUEUE_BACKEND)
+	messageQueue, err := queue.New(config.DefaultQueueConfig())
+	if err != nil {
+		appLogger.Fatal("Failed to connect to message queue", err)
+	}
+	defer messageQueue.Close()
+
+	// Goroutines, poll interval and receive size of each worker unless
+	// overridden by WORKER_* settings
+	workerConfig := config.DefaultWorkerConfig(1, 5*time.Second, 10)
+	if err := workerConfig.Validate(); err != nil {
+		appLogger.Fatal("Invalid worker configuration", err)
+	}
+
+	// Initialize OpenSearch for the index and cleanup workers
+	var osRepo opensearch.Repository
+	if selected[modeIndex] || selected[modeCleanup] {
+		osConfig := config.DefaultOpenSearchConfig()
+		osClients, err := osConfig.GetClusterClients()
+		if err != nil {
+			appLogger.Fatal("Failed to connect to OpenSearch", err)
+		}
+		osRepo = opensearch.NewClusterRepository(osClients, osConfig, opensearch.NewTenantSettings(pgRepo.Tenant(), osConfig.TenantSettingsCacheTTL))
+
+		// Install the current index template before writing, so indices created
+		// by the first writes of a day get the current mapping version
+		for cluster, osClient := range osClients {
+			if err := opensearch.NewLifecycleManager(osClient, osConfig).PutIndexTemplate(context.Background()); err != nil {
+				appLogger.Errorf("Failed to put index template on cluster %s: %v", cluster, err)
+			}
+		}
+	}
+
+	// Initialize Redis, which carries the cleanup completion events and keeps
+	// the rule caches and usage counters of imports
+	var redisClient *redis.Client
+	if selected[modeArchive] || selected[modeCleanup] {
+		redisClient, err = config.DefaultRedisConfig().GetClient()
+		if err != nil {
+			appLogger.Fatal("Failed to connect to Redis", err)
+		}
+		defer redisClient.Close()
+	}
+
+	var workers []queueWorker
+	if selected[modeIndex] {
+		// Copy indexed logs to ClickHouse when it serves the stats
+		chConfig := config.DefaultClickHouseConfig()
+		if err := chConfig.Validate(); err != nil {
+			appLogger.Fatal("Invalid ClickHouse configuration", err)
+		}
+		var analyticsRepo repository.AnalyticsRepository
+		if chConfig.Enabled() {
+			chConn, err := chConfig.Open()
+			if err != nil {
+				appLogger.Fatal("Failed to connect to ClickHouse", err)
+			}
+			defer chConn.Close()
+			analyticsRepo = clickhouse.NewAnalyticsRepository(chConn)
+		}
+
+		workers = append(workers, worker.NewSQSWorker(
+			messageQueue,
+			osRepo,
+			postgres.NewIndexFailureRepository(dbConnections.Writer),
+			analyticsRepo,
+			appLogger,
+			workerConfig,
+		))
+	}
+
+	if selected[modeArchive] {
+		s3Config := config.DefaultS3Config()
+		if err := s3Config.Validate(); err != nil {
+			appLogger.Fatal("Invalid S3 configuration", err)
+		}
+		s3Client, err := s3Config.GetClient(context.Background())
+		if err != nil {
+			appLogger.Fatal("Failed to connect to S3", err)
+		}
+
+		importConfig := config.DefaultImportConfig()
+		if err := importConfig.Validate(); err != nil {
+			appLogger.Fatal("Invalid import configuration", err)
+		}
+		quotaConfig := config.DefaultQuotaConfig()
+		if err := quotaConfig.Validate(); err != nil {
+			appLogger.Fatal("Invalid quota configuration", err)
+		}
+		// Imported logs are tagged, redacted and metered like ingested ones
+		importService := service.NewImportService(
+			pgRepo,
+			messageQueue,
+			storage.NewS3ImportStore(s3Client, s3Config),
+			service.NewRedactionService(pgRepo, cache.NewRedactionRuleCache(redisClient, importConfig.RedactionRuleCacheTTL)),
+			service.NewTaggingService(pgRepo, cache.NewTaggingRuleCache(redisClient, importConfig.TaggingRuleCacheTTL)),
+			service.NewUsageService(cache.NewUsageCounter(redisClient), quotaConfig),
+			importConfig,
+		)
+
+		archiveWorker := worker.NewArchiveWorker(
+			messageQueue,
+			pgRepo,
+			appLogger,
+			workerConfig,
+			s3Client,
+			s3Config,
+			importService,
+		)
+		if err := archiveWorker.VerifyObjectLock(context.Background()); err != nil {
+			appLogger.Fatal("Archive bucket can't lock archives", err)
+		}
+		workers = append(workers, archiveWorker)
+	}
+
+	if selected[modeCleanup] {
+		broker, err := pubsub.New(redisClient, config.DefaultPubSubConfig(), appLogger)
+		if err != nil {
+			appLogger.Fatal("Invalid pub/sub configuration", err)
+		}
+
+		workers = append(workers, worker.NewCleanupWorker(
+			messageQueue,
+			pgRepo,
+			osRepo,
+			broker,
+			appLogger,
+			workerConfig,
+		))
+	}
+
+	// Expose Prometheus metrics and health checks. The process is ready while
+	// its workers run and PostgreSQL answers.
+	var running atomic.Bool
+	metricsConfig := config.DefaultMetricsConfig(":9112")
+	metricsServer := metrics.NewServer(metricsConfig.Addr)
+	metricsServer.HandleHealth(func(ctx context.Context) error {
+		if !running.Load() {
+			return errors.New("workers are not running")
+		}
+		sqlDB, err := dbConnections.Writer.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	})
+	metricsServer.Start(func(err error) {
+		appLogger.Error("Metrics server failed", err)
+	})
+
+	// Start the workers
+	for _, w := range workers {
+		w.Start()
+	}
+	running.Store(true)
+	appLogger.Infof("Workers started: %s", strings.Join(selectedModes(selected), ", "))
+
+	// Wait for interrupt signal to gracefully shutdown the workers
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	// Stop the workers together, so shutdown takes one drain timeout at most
+	appLogger.Info("Shutting down workers...")
+	running.Store(false)
+	var stopped sync.WaitGroup
+	for _, w := range workers {
+		stopped.Add(1)
+		go func() {
+			defer stopped.Done()
+			w.Stop()
+		}()
+	}
+	stopped.Wait()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+		appLogger.Error("Failed to shutdown metrics server", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		appLogger.Error("Failed to flush traces", err)
+	}
+	appLogger.Info("Workers stopped")
+	appLogger.Sync()
+}
+
+// parseModes parses the comma-separated --mode value into the set of workers to run
+func parseModes(value string) (map[string]bool, error) {
+	selected := make(map[string]bool)
+	for _, mode := range strings.Split(value, ",") {
+		mode = strings.ToLower(strings.TrimSpace(mode))
+		switch {
+		case mode == modeAll:
+			for _, m := range modes {
+				selected[m] = true
+			}
+		case slices.Contains(modes, mode):
+			selected[mode] = true
+		default:
+			return nil, fmt.Errorf("unknown mode %q, expected %s or %s", mode, strings.Join(modes, ", "), modeAll)
+		}
+	}
+	return selected, nil
+}
+
+// selectedModes lists the selected workers in a stable order
+func selectedModes(selected map[string]bool) []string {
+	var names []string
+	for _, m := range modes {
+		if selected[m] {
+			names = append(names, m)
+		}
+	}
+	return names
+}
@@ -0,0 +1,296 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
+)
+
+//go:generate mockery --name ConfigService --output ../mocks
+type ConfigService interface {
+	Effective() map[string]any
+}
+
+//go:generate mockery --name IndexFailureService --output ../mocks
+type IndexFailureService interface {
+	List(ctx context.Context, tenantID string) ([]dto.IndexFailureResponse, error)
+	Reprocess(ctx context.Context, tenantID string, ids []string) (*dto.ReprocessIndexFailuresResponse, error)
+}
+
+//go:generate mockery --name SearchIndexService --output ../mocks
+type SearchIndexService interface {
+	List(ctx context.Context, tenantID string) ([]dto.SearchIndexResponse, error)
+	Ensure(ctx context.Context, tenantID string, start, end time.Time) (*dto.EnsureIndicesResponse, error)
+	Reindex(ctx context.Context, tenantID string, start, end time.Time) (*dto.ReindexResponse, error)
+	Delete(ctx context.Context, tenantID string, day time.Time) error
+}
+
+//go:generate mockery --name DBPoolService --output ../mocks
+type DBPoolService interface {
+	List() []dto.DBPoolResponse
+	Update(name string, req *dto.UpdateDBPoolRequest) (*dto.DBPoolResponse, error)
+}
+
+type AdminHandler struct {
+	*BaseHandler
+	config   ConfigService
+	failures IndexFailureService
+	indices  SearchIndexService
+	dbPools  DBPoolService
+}
+
+func NewAdminHandler(config ConfigService, failures IndexFailureService, indices SearchIndexService, dbPools DBPoolService) *AdminHandler {
+	return &AdminHandler{config: config, failures: failures, indices: indices, dbPools: dbPools}
+}
+
+// GetConfig godoc
+// @Summary Get the effective configuration
+// @Description Get every setting the API instance resolved from its environment, config file and defaults. Passwords and secret keys are masked.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Router /admin/config [get]
+func (h *AdminHandler) GetConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, h.config.Effective())
+}
+
+// ListIndexFailures godoc
+// @Summary List index failures
+// @Description List the tenant's 500 most recent logs OpenSearch rejected, such as for a mapping conflict or after retries for load ran out. The logs are stored, only missing from search.
+// @Tags admin
+// @Produce json
+// @Success 200 {array} dto.IndexFailureResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /admin/index-failures [get]
+func (h *AdminHandler) ListIndexFailures(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	failures, err := h.failures.List(h.RequestCtx(c), tenantID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, failures)
+}
+
+// ReprocessIndexFailures godoc
+// @Summary Reprocess index failures
+// @Description Queue the logs of the given index failures, or of the 500 most recent ones without a body, for indexing again once the cause is fixed. Logs rejected again show up as new failures.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param body body dto.ReprocessIndexFailuresRequest false "Index failures to reprocess"
+// @Success 200 {object} dto.ReprocessIndexFailuresResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /admin/index-failures/reprocess [post]
+func (h *AdminHandler) ReprocessIndexFailures(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	// The body is optional
+	var req dto.ReprocessIndexFailuresRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			bindError(c, err)
+			return
+		}
+	}
+
+	result, err := h.failures.Reprocess(h.RequestCtx(c), tenantID, req.IDs)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListIndices godoc
+// @Summary List search indices
+// @Description List the tenant's daily OpenSearch indices, oldest first, with their document counts, sizes and health.
+// @Tags admin
+// @Produce json
+// @Success 200 {array} dto.SearchIndexResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /admin/indices [get]
+func (h *AdminHandler) ListIndices(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	indices, err := h.indices.List(h.RequestCtx(c), tenantID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, indices)
+}
+
+// EnsureIndices godoc
+// @Summary Create missing search indices
+// @Description Create the tenant's missing daily indices, with the audit log mapping, for the UTC days from start_time to end_time, at most 366 days. Existing indices are left as they are.
+// @Tags admin
+// @Produce json
+// @Param start_time query string true "First day (RFC3339 or YYYY-MM-DD)"
+// @Param end_time query string true "Last day (RFC3339 or YYYY-MM-DD)"
+// @Success 200 {object} dto.EnsureIndicesResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /admin/indices [post]
+func (h *AdminHandler) EnsureIndices(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	start, end, ok := bindTimeRange(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.indices.Ensure(h.RequestCtx(c), tenantID, start, end)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ReindexLogs godoc
+// @Summary Reindex logs from PostgreSQL
+// @Description Queue the tenant's logs from start_time to end_time, at most 31 days, for the index worker to index from PostgreSQL into OpenSearch again, such as after losing an index. Documents already indexed are overwritten.
+// @Tags admin
+// @Produce json
+// @Param start_time query string true "Reindex logs from this time (RFC3339 or YYYY-MM-DD)"
+// @Param end_time query string true "Reindex logs up to this time (RFC3339 or YYYY-MM-DD)"
+// @Success 202 {object} dto.ReindexResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /admin/indices/reindex [post]
+func (h *AdminHandler) ReindexLogs(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	start, end, ok := bindTimeRange(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.indices.Reindex(h.RequestCtx(c), tenantID, start, end)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, result)
+}
+
+// DeleteIndex godoc
+// @Summary Delete a search index
+// @Description Delete the tenant's daily index for a UTC day. Its logs stay in PostgreSQL but are no longer searchable until reindexed.
+// @Tags admin
+// @Param day path string true "UTC day of the index (YYYY-MM-DD)"
+// @Success 204
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /admin/indices/{day} [delete]
+func (h *AdminHandler) DeleteIndex(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	day, err := time.Parse(time.DateOnly, c.Param("day"))
+	if err != nil {
+		respondError(c, errValidation("day must be a date in YYYY-MM-DD format"))
+		return
+	}
+
+	if err := h.indices.Delete(h.RequestCtx(c), tenantID, day); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListDBPools godoc
+// @Summary List database connection pools
+// @Description List the connection pools of the API instance's PostgreSQL writer and readers with their limits, open, in-use and idle connections and the waits for a free connection since startup. Platform administrators only.
+// @Tags admin
+// @Produce json
+// @Success 200 {array} dto.DBPoolResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Router /admin/db-pools [get]
+func (h *AdminHandler) ListDBPools(c *gin.Context) {
+	c.JSON(http.StatusOK, h.dbPools.List())
+}
+
+// UpdateDBPool godoc
+// @Summary Resize a database connection pool
+// @Description Set the maximum open and idle connections and the connection lifetime of a connection pool of the API instance that answers, without a restart. The new limits last until the instance restarts; other instances keep theirs. Platform administrators only.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param pool path string true "Pool name, writer or reader_<n>"
+// @Param body body dto.UpdateDBPoolRequest true "Pool limits"
+// @Success 200 {object} dto.DBPoolResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Router /admin/db-pools/{pool} [put]
+func (h *AdminHandler) UpdateDBPool(c *gin.Context) {
+	var req dto.UpdateDBPoolRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	pool, err := h.dbPools.Update(c.Param("pool"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pool)
+}
@@ -0,0 +1,418 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
+)
+
+type AdminHandlerTestSuite struct {
+	suite.Suite
+	router       *gin.Engine
+	mockService  *MockConfigService
+	mockFailures *MockIndexFailureService
+	mockIndices  *MockSearchIndexService
+	mockDBPools  *MockDBPoolService
+	handler      *AdminHandler
+}
+
+type MockConfigService struct {
+	mock.Mock
+}
+
+func (m *MockConfigService) Effective() map[string]any {
+	args := m.Called()
+	return args.Get(0).(map[string]any)
+}
+
+type MockIndexFailureService struct {
+	mock.Mock
+}
+
+func (m *MockIndexFailureService) List(ctx context.Context, tenantID string) ([]dto.IndexFailureResponse, error) {
+	args := m.Called(ctx, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.IndexFailureResponse), args.Error(1)
+}
+
+func (m *MockIndexFailureService) Reprocess(ctx context.Context, tenantID string, ids []string) (*dto.ReprocessIndexFailuresResponse, error) {
+	args := m.Called(ctx, tenantID, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ReprocessIndexFailuresResponse), args.Error(1)
+}
+
+type MockSearchIndexService struct {
+	mock.Mock
+}
+
+func (m *MockSearchIndexService) List(ctx context.Context, tenantID string) ([]dto.SearchIndexResponse, error) {
+	args := m.Called(ctx, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.SearchIndexResponse), args.Error(1)
+}
+
+func (m *MockSearchIndexService) Ensure(ctx context.Context, tenantID string, start, end time.Time) (*dto.EnsureIndicesResponse, error) {
+	args := m.Called(ctx, tenantID, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.EnsureIndicesResponse), args.Error(1)
+}
+
+func (m *MockSearchIndexService) Reindex(ctx context.Context, tenantID string, start, end time.Time) (*dto.ReindexResponse, error) {
+	args := m.Called(ctx, tenantID, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ReindexResponse), args.Error(1)
+}
+
+func (m *MockSearchIndexService) Delete(ctx context.Context, tenantID string, day time.Time) error {
+	args := m.Called(ctx, tenantID, day)
+	return args.Error(0)
+}
+
+type MockDBPoolService struct {
+	mock.Mock
+}
+
+func (m *MockDBPoolService) List() []dto.DBPoolResponse {
+	args := m.Called()
+	return args.Get(0).([]dto.DBPoolResponse)
+}
+
+func (m *MockDBPoolService) Update(name string, req *dto.UpdateDBPoolRequest) (*dto.DBPoolResponse, error) {
+	args := m.Called(name, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.DBPoolResponse), args.Error(1)
+}
+
+func (s *AdminHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.mockService = new(MockConfigService)
+	s.mockFailures = new(MockIndexFailureService)
+	s.mockIndices = new(MockSearchIndexService)
+	s.mockDBPools = new(MockDBPoolService)
+	s.handler = NewAdminHandler(s.mockService, s.mockFailures, s.mockIndices, s.mockDBPools)
+
+	withTenant := func(c *gin.Context) {
+		c.Set(string(contextutils.TenantIDKey), "tenant1")
+	}
+	s.router.GET("/admin/config", s.handler.GetConfig)
+	s.router.GET("/admin/index-failures", withTenant, s.handler.ListIndexFailures)
+	s.router.POST("/admin/index-failures/reprocess", withTenant, s.handler.ReprocessIndexFailures)
+	s.router.GET("/admin/indices", withTenant, s.handler.ListIndices)
+	s.router.POST("/admin/indices", withTenant, s.handler.EnsureIndices)
+	s.router.POST("/admin/indices/reindex", withTenant, s.handler.ReindexLogs)
+	s.router.DELETE("/admin/indices/:day", withTenant, s.handler.DeleteIndex)
+	s.router.GET("/admin/db-pools", s.handler.ListDBPools)
+	s.router.PUT("/admin/db-pools/:pool", s.handler.UpdateDBPool)
+}
+
+func TestAdminHandler(t *testing.T) {
+	suite.Run(t, new(AdminHandlerTestSuite))
+}
+
+func (s *AdminHandlerTestSuite) TestGetConfig_Success() {
+	// Arrange
+	s.mockService.On("Effective").Return(map[string]any{
+		"server": map[string]any{"port": 10000},
+		"jwt":    map[string]any{"secret_key": "********", "access_token_ttl": "15m0s"},
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/admin/config", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response map[string]map[string]any
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal(float64(10000), response["server"]["port"])
+	s.Equal("********", response["jwt"]["secret_key"])
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AdminHandlerTestSuite) TestListIndexFailures_Success() {
+	// Arrange
+	s.mockFailures.On("List", mock.Anything, "tenant1").Return([]dto.IndexFailureResponse{
+		{ID: "failure1", LogID: "log1", Status: 400, ErrorType: "mapper_parsing_exception"},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/admin/index-failures", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response []dto.IndexFailureResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Len(response, 1)
+	s.Equal("mapper_parsing_exception", response[0].ErrorType)
+	s.mockFailures.AssertExpectations(s.T())
+}
+
+func (s *AdminHandlerTestSuite) TestReprocessIndexFailures_ByIDs() {
+	// Arrange
+	ids := []string{"550e8400-e29b-41d4-a716-446655440000"}
+	s.mockFailures.On("Reprocess", mock.Anything, "tenant1", ids).
+		Return(&dto.ReprocessIndexFailuresResponse{Reprocessed: 1}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/admin/index-failures/reprocess", strings.NewReader(`{"ids":["550e8400-e29b-41d4-a716-446655440000"]}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.JSONEq(`{"reprocessed":1}`, w.Body.String())
+	s.mockFailures.AssertExpectations(s.T())
+}
+
+func (s *AdminHandlerTestSuite) TestReprocessIndexFailures_NoBody() {
+	// Arrange
+	s.mockFailures.On("Reprocess", mock.Anything, "tenant1", []string(nil)).
+		Return(&dto.ReprocessIndexFailuresResponse{Reprocessed: 12}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/admin/index-failures/reprocess", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.JSONEq(`{"reprocessed":12}`, w.Body.String())
+	s.mockFailures.AssertExpectations(s.T())
+}
+
+func (s *AdminHandlerTestSuite) TestReprocessIndexFailures_InvalidID() {
+	// Arrange
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/admin/index-failures/reprocess", strings.NewReader(`{"ids":["not-a-uuid"]}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockFailures.AssertNotCalled(s.T(), "Reprocess", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *AdminHandlerTestSuite) TestReprocessIndexFailures_ServiceError() {
+	// Arrange
+	s.mockFailures.On("Reprocess", mock.Anything, "tenant1", []string(nil)).
+		Return(nil, errors.New("queue unavailable"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/admin/index-failures/reprocess", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusInternalServerError, w.Code)
+}
+
+func (s *AdminHandlerTestSuite) TestListIndices_Success() {
+	// Arrange
+	s.mockIndices.On("List", mock.Anything, "tenant1").Return([]dto.SearchIndexResponse{
+		{Name: "audit_logs_tenant1_2025_07_17", Day: "2025-07-17", Docs: 48000, SizeBytes: 10485760, Health: "green"},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/admin/indices", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.JSONEq(`[{"name":"audit_logs_tenant1_2025_07_17","day":"2025-07-17","docs":48000,"size_bytes":10485760,"health":"green"}]`, w.Body.String())
+}
+
+func (s *AdminHandlerTestSuite) TestEnsureIndices_Success() {
+	// Arrange
+	start := time.Date(2025, 7, 17, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 7, 18, 23, 59, 59, 0, time.UTC)
+	s.mockIndices.On("Ensure", mock.Anything, "tenant1", start, end).
+		Return(&dto.EnsureIndicesResponse{Created: []string{"2025-07-18"}, Existing: 1}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/admin/indices?start_time=2025-07-17&end_time=2025-07-18", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.JSONEq(`{"created":["2025-07-18"],"existing":1}`, w.Body.String())
+	s.mockIndices.AssertExpectations(s.T())
+}
+
+func (s *AdminHandlerTestSuite) TestEnsureIndices_MissingRange() {
+	// Arrange
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/admin/indices?start_time=2025-07-17", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockIndices.AssertNotCalled(s.T(), "Ensure", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *AdminHandlerTestSuite) TestReindexLogs_Accepted() {
+	// Arrange
+	start := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 7, 2, 0, 0, 0, 0, time.UTC)
+	s.mockIndices.On("Reindex", mock.Anything, "tenant1", start, end).Return(&dto.ReindexResponse{Queued: 48000}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/admin/indices/reindex?start_time=2025-07-01T00:00:00Z&end_time=2025-07-02T00:00:00Z", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusAccepted, w.Code)
+	s.JSONEq(`{"queued":48000}`, w.Body.String())
+}
+
+func (s *AdminHandlerTestSuite) TestReindexLogs_RangeTooLong() {
+	// Arrange
+	s.mockIndices.On("Reindex", mock.Anything, "tenant1", mock.Anything, mock.Anything).Return(nil, service.ErrInvalidReindexRange)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/admin/indices/reindex?start_time=2025-01-01&end_time=2025-07-01", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *AdminHandlerTestSuite) TestDeleteIndex_Success() {
+	// Arrange
+	s.mockIndices.On("Delete", mock.Anything, "tenant1", time.Date(2025, 7, 17, 0, 0, 0, 0, time.UTC)).Return(nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodDelete, "/admin/indices/2025-07-17", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusNoContent, w.Code)
+	s.mockIndices.AssertExpectations(s.T())
+}
+
+func (s *AdminHandlerTestSuite) TestDeleteIndex_NotFound() {
+	// Arrange
+	s.mockIndices.On("Delete", mock.Anything, "tenant1", mock.Anything).Return(service.ErrSearchIndexNotFound)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodDelete, "/admin/indices/2025-07-17", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *AdminHandlerTestSuite) TestDeleteIndex_InvalidDay() {
+	// Arrange
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodDelete, "/admin/indices/audit_logs_other_2025_07_17", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockIndices.AssertNotCalled(s.T(), "Delete", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *AdminHandlerTestSuite) TestUpdateDBPool_Success() {
+	// Arrange
+	maxOpen := 100
+	s.mockDBPools.On("Update", "writer", &dto.UpdateDBPoolRequest{MaxOpenConns: &maxOpen, ConnMaxLifetime: "30m"}).
+		Return(&dto.DBPoolResponse{Name: "writer", MaxOpenConns: 100, MaxIdleConns: 10, ConnMaxLifetime: "30m0s"}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/admin/db-pools/writer", strings.NewReader(`{"max_open_conns":100,"conn_max_lifetime":"30m"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response dto.DBPoolResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal(100, response.MaxOpenConns)
+	s.Equal("30m0s", response.ConnMaxLifetime)
+	s.mockDBPools.AssertExpectations(s.T())
+}
+
+func (s *AdminHandlerTestSuite) TestUpdateDBPool_ZeroMaxOpenConns_BadRequest() {
+	// Arrange
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/admin/db-pools/writer", strings.NewReader(`{"max_open_conns":0}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockDBPools.AssertNotCalled(s.T(), "Update", mock.Anything, mock.Anything)
+}
+
+func (s *AdminHandlerTestSuite) TestUpdateDBPool_UnknownPool_NotFound() {
+	// Arrange
+	s.mockDBPools.On("Update", "reader_9", mock.Anything).Return(nil, service.ErrDBPoolNotFound)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/admin/db-pools/reader_9", strings.NewReader(`{"max_idle_conns":5}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+	s.mockDBPools.AssertExpectations(s.T())
+}
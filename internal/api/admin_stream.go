@@ -0,0 +1,259 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+)
+
+// adminStreamMaxRate is the most logs per second an admin stream receives of
+// one tenant, so a noisy tenant can't drown the others on a dashboard
+const adminStreamMaxRate = 100
+
+// TenantLister lists the tenants whose names label admin stream logs
+type TenantLister interface {
+	List(ctx context.Context) ([]dto.CreateTenantResponse, error)
+}
+
+// adminStream is the subscription of a platform admin's client to the logs
+// of several tenants, or of all of them
+type adminStream struct {
+	// tenants restricts the stream to these tenants; nil streams every tenant
+	tenants map[string]struct{}
+	// names labels logs with the name of their tenant
+	names map[string]string
+
+	actions       map[string]struct{}
+	severities    map[string]struct{}
+	resourceTypes map[string]struct{}
+
+	// rate caps the logs per second streamed of each tenant
+	rate    int
+	mu      sync.Mutex
+	windows map[string]rateWindow
+}
+
+// rateWindow counts the logs of a tenant streamed in the current second
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// adminStreamMessage labels a log with its tenant
+type adminStreamMessage struct {
+	TenantID   string          `json:"tenant_id"`
+	TenantName string          `json:"tenant_name,omitempty"`
+	Log        json.RawMessage `json:"log"`
+}
+
+// newAdminStream reads the tenants, filters and rate cap of an admin stream
+// request and resolves the names of the tenants
+func (h *WebSocketHandler) newAdminStream(c *gin.Context) (*adminStream, error) {
+	stream := &adminStream{
+		tenants:       queryValues(c, "tenant_id", false),
+		actions:       queryValues(c, "action", true),
+		severities:    queryValues(c, "severity", true),
+		resourceTypes: queryValues(c, "resource_type", false),
+		rate:          adminStreamMaxRate,
+		windows:       make(map[string]rateWindow),
+	}
+	for tenantID := range stream.tenants {
+		if _, err := uuid.Parse(tenantID); err != nil {
+			return nil, errValidation(fmt.Sprintf("tenant_id %q is not a tenant ID", tenantID))
+		}
+	}
+	if value := c.Query("rate"); value != "" {
+		rate, err := strconv.Atoi(value)
+		if err != nil || rate < 1 || rate > adminStreamMaxRate {
+			return nil, errValidation(fmt.Sprintf("rate must be between 1 and %d logs per second", adminStreamMaxRate))
+		}
+		stream.rate = rate
+	}
+
+	tenants, err := h.tenants.List(c.Request.Context())
+	if err != nil {
+		return nil, err
+	}
+	stream.names = make(map[string]string, len(tenants))
+	for _, tenant := range tenants {
+		stream.names[tenant.ID] = tenant.Name
+	}
+	return stream, nil
+}
+
+// queryValues collects the comma-separated values of a query parameter, which
+// may be repeated; nil means the parameter wasn't given
+func queryValues(c *gin.Context, key string, upper bool) map[string]struct{} {
+	var values map[string]struct{}
+	for _, param := range c.QueryArray(key) {
+		for _, value := range strings.Split(param, ",") {
+			if value = strings.TrimSpace(value); value == "" {
+				continue
+			}
+			if upper {
+				value = strings.ToUpper(value)
+			}
+			if values == nil {
+				values = make(map[string]struct{})
+			}
+			values[value] = struct{}{}
+		}
+	}
+	return values
+}
+
+// accepts reports whether a log passes the stream's tenants, filters and rate cap
+func (a *adminStream) accepts(log *dto.AuditLogResponse) bool {
+	if !contains(a.tenants, log.TenantID) ||
+		!contains(a.actions, strings.ToUpper(log.Action)) ||
+		!contains(a.severities, strings.ToUpper(log.Severity)) ||
+		!contains(a.resourceTypes, log.ResourceType) {
+		return false
+	}
+	return a.allow(log.TenantID)
+}
+
+// contains reports whether value is in set; a nil set holds every value
+func contains(set map[string]struct{}, value string) bool {
+	if set == nil {
+		return true
+	}
+	_, ok := set[value]
+	return ok
+}
+
+// allow counts a log of the tenant against the rate cap, dropping it once
+// the tenant's logs of the current second reach the cap
+func (a *adminStream) allow(tenantID string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	window := a.windows[tenantID]
+	if now.Sub(window.start) >= time.Second {
+		window = rateWindow{start: now}
+	}
+	if window.count >= a.rate {
+		metrics.StreamMessagesRateLimitedTotal.Inc()
+		return false
+	}
+	window.count++
+	a.windows[tenantID] = window
+	return true
+}
+
+// label wraps a log with its tenant's ID and name
+func (a *adminStream) label(log *dto.AuditLogResponse, payload []byte) ([]byte, error) {
+	return json.Marshal(adminStreamMessage{
+		TenantID:   log.TenantID,
+		TenantName: a.names[log.TenantID],
+		Log:        payload,
+	})
+}
+
+// handleFirehoseMessage queues a log of any tenant for the admin clients
+// accepting it, labelled with its tenant
+func (h *WebSocketHandler) handleFirehoseMessage(log *dto.AuditLogResponse) {
+	payload, err := json.Marshal(log)
+	if err != nil {
+		h.logger.Errorf("Error marshaling log: %v", err)
+		return
+	}
+
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	for client := range h.clients {
+		if client.admin == nil || !client.admin.accepts(log) {
+			continue
+		}
+		labelled, err := client.admin.label(log, payload)
+		if err != nil {
+			continue
+		}
+		client.enqueue(streamMessage{id: log.ID, payload: labelled})
+	}
+}
+
+// HandleAdminWebSocket streams the logs of several tenants over a WebSocket
+// @Summary Stream audit logs of several tenants (WebSocket)
+// @Description Upgrade to a WebSocket streaming new audit logs of the given tenants, or of every tenant, for platform administrators (admins of the system tenant). Each message is {"tenant_id","tenant_name","log"}. Logs are filtered server-side and capped per tenant per second.
+// @Tags    admin
+// @Param   tenant_id query []string false "Tenants to stream; every tenant if omitted" collectionFormat(csv)
+// @Param   action query []string false "Actions to stream" collectionFormat(csv)
+// @Param   severity query []string false "Severities to stream" collectionFormat(csv)
+// @Param   resource_type query []string false "Resource types to stream" collectionFormat(csv)
+// @Param   rate query int false "Logs per second streamed of each tenant at most (default and maximum 100)"
+// @Param   ticket query string false "One-time ticket from POST /logs/stream/ticket, in place of the Authorization header"
+// @Success 101 {string} string "Switching Protocols"
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Router  /admin/logs/stream [get]
+func (h *WebSocketHandler) HandleAdminWebSocket(c *gin.Context) {
+	stream, err := h.newAdminStream(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader already responded with the reason
+		_ = c.Error(fmt.Errorf("failed to upgrade connection: %w", err))
+		return
+	}
+
+	client := &Client{
+		conn:  conn,
+		admin: stream,
+		send:  make(chan streamMessage, websocketSendChannelBufferSize),
+	}
+	h.register <- client
+
+	go h.writePump(client, nil)
+	go h.readPump(client)
+}
+
+// HandleAdminSSE streams the logs of several tenants as Server-Sent Events
+// @Summary Stream audit logs of several tenants (SSE)
+// @Description Stream new audit logs of the given tenants, or of every tenant, as Server-Sent Events, for platform administrators (admins of the system tenant). Each event is {"tenant_id","tenant_name","log"}. Logs are filtered server-side and capped per tenant per second.
+// @Tags    admin
+// @Produce text/event-stream
+// @Param   tenant_id query []string false "Tenants to stream; every tenant if omitted" collectionFormat(csv)
+// @Param   action query []string false "Actions to stream" collectionFormat(csv)
+// @Param   severity query []string false "Severities to stream" collectionFormat(csv)
+// @Param   resource_type query []string false "Resource types to stream" collectionFormat(csv)
+// @Param   rate query int false "Logs per second streamed of each tenant at most (default and maximum 100)"
+// @Param   ticket query string false "One-time ticket from POST /logs/stream/ticket, in place of the Authorization header"
+// @Success 200 {string} string "event stream"
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Router  /admin/logs/sse [get]
+func (h *WebSocketHandler) HandleAdminSSE(c *gin.Context) {
+	stream, err := h.newAdminStream(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	client := &Client{
+		admin: stream,
+		send:  make(chan streamMessage, websocketSendChannelBufferSize),
+	}
+	h.register <- client
+	defer h.unregisterClient(client)
+
+	h.serveSSE(c, client, nil)
+}
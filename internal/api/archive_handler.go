@@ -0,0 +1,166 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
+	"github.com/kingrain94/audit-log-api/pkg/utils"
+)
+
+//go:generate mockery --name ArchiveService --output ../mocks
+type ArchiveService interface {
+	List(ctx context.Context, filter *domain.ArchiveFilter) ([]dto.ArchiveResponse, error)
+	Verify(ctx context.Context, tenantID, id string) (*dto.ArchiveVerificationResponse, error)
+	Search(ctx context.Context, filter *domain.ArchiveSearchFilter) (*dto.ArchiveSearchResponse, error)
+}
+
+type ArchiveHandler struct {
+	*BaseHandler
+	service ArchiveService
+}
+
+func NewArchiveHandler(service ArchiveService) *ArchiveHandler {
+	return &ArchiveHandler{service: service}
+}
+
+// ListArchives godoc
+// @Summary List archives
+// @Description List the archives of the authenticated tenant in S3 with the SHA-256 checksums of their manifest and parts and their last verification, most recent first
+// @Tags archives
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(10)
+// @Success 200 {array} dto.ArchiveResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /archives [get]
+func (h *ArchiveHandler) ListArchives(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	filter := &domain.ArchiveFilter{TenantID: tenantID}
+	if page, err := strconv.Atoi(c.Query("page")); err == nil {
+		filter.Page = page
+	}
+	if pageSize, err := strconv.Atoi(c.Query("page_size")); err == nil {
+		filter.PageSize = pageSize
+	}
+
+	archives, err := h.service.List(h.RequestCtx(c), filter)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, archives)
+}
+
+// VerifyArchive godoc
+// @Summary Verify an archive
+// @Description Re-hash the manifest and parts of an archive of the authenticated tenant in S3 and compare them with the SHA-256 checksums recorded when it was written. The archive is verified when every object matches, corrupted when any is missing or altered, and unavailable when parts in Glacier have to be restored first.
+// @Tags archives
+// @Produce json
+// @Param id path string true "Archive ID"
+// @Success 200 {object} dto.ArchiveVerificationResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /archives/{id}/verify [post]
+func (h *ArchiveHandler) VerifyArchive(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	result, err := h.service.Verify(h.RequestCtx(c), tenantID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// SearchArchivedLogs godoc
+// @Summary Search archived logs
+// @Description Query the authenticated tenant's archived logs in S3 in place with S3 Select, for occasional access to cold data without a restore. Only the archive parts whose timestamps overlap the range are queried, newest first, until the limit is reached; parts in Glacier are skipped and listed in unavailable_parts. Own-scoped callers only find their own logs.
+// @Tags audit_logs
+// @Produce json
+// @Param start_time query string true "Start of the range (RFC3339 or YYYY-MM-DD)"
+// @Param end_time query string true "End of the range (RFC3339 or YYYY-MM-DD), at most 366 days after start_time"
+// @Param user_id query string false "Filter by user ID"
+// @Param action query string false "Filter by action"
+// @Param resource_type query string false "Filter by resource type"
+// @Param resource_id query string false "Filter by resource ID"
+// @Param severity query string false "Filter by severity" Enums(INFO, WARNING, ERROR, CRITICAL)
+// @Param limit query int false "Maximum number of logs, at most 1000" default(100)
+// @Success 200 {object} dto.ArchiveSearchResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /logs/archive/search [get]
+func (h *ArchiveHandler) SearchArchivedLogs(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	filter := &domain.ArchiveSearchFilter{
+		TenantID:     tenantID,
+		UserID:       c.Query("user_id"),
+		Action:       c.Query("action"),
+		ResourceType: c.Query("resource_type"),
+		ResourceID:   c.Query("resource_id"),
+	}
+
+	var err error
+	if filter.StartTime, err = utils.ParseUserTime(c.Query("start_time"), false); err != nil {
+		respondError(c, errValidation("invalid start_time: "+err.Error()))
+		return
+	}
+	if filter.EndTime, err = utils.ParseUserTime(c.Query("end_time"), true); err != nil {
+		respondError(c, errValidation("invalid end_time: "+err.Error()))
+		return
+	}
+	if severity := c.Query("severity"); severity != "" {
+		if !domain.IsSeverityLevel(severity) {
+			respondError(c, errValidation("invalid severity: "+severity))
+			return
+		}
+		filter.Severity = strings.ToUpper(severity)
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil {
+			respondError(c, errValidation("invalid limit: "+limit))
+			return
+		}
+	}
+
+	// Own-scoped callers only find their own logs, whatever user_id they ask for
+	if userID := ownScopeUserID(c); userID != "" {
+		filter.UserID = userID
+	}
+
+	resp, err := h.service.Search(h.RequestCtx(c), filter)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,198 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ArchiveHandlerTestSuite struct {
+	suite.Suite
+	router      *gin.Engine
+	mockService *MockArchiveService
+	handler     *ArchiveHandler
+}
+
+type MockArchiveService struct {
+	mock.Mock
+}
+
+func (m *MockArchiveService) List(ctx context.Context, filter *domain.ArchiveFilter) ([]dto.ArchiveResponse, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]dto.ArchiveResponse), args.Error(1)
+}
+
+func (m *MockArchiveService) Verify(ctx context.Context, tenantID, id string) (*dto.ArchiveVerificationResponse, error) {
+	args := m.Called(ctx, tenantID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ArchiveVerificationResponse), args.Error(1)
+}
+
+func (m *MockArchiveService) Search(ctx context.Context, filter *domain.ArchiveSearchFilter) (*dto.ArchiveSearchResponse, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ArchiveSearchResponse), args.Error(1)
+}
+
+func (s *ArchiveHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.mockService = new(MockArchiveService)
+	s.handler = NewArchiveHandler(s.mockService)
+
+	// Setup routes with the tenant the JWT middleware would set
+	archives := s.router.Group("/archives", func(c *gin.Context) {
+		c.Set(string(contextutils.TenantIDKey), "tenant1")
+	})
+	archives.GET("", s.handler.ListArchives)
+	archives.POST("/:id/verify", s.handler.VerifyArchive)
+	s.router.GET("/logs/archive/search", func(c *gin.Context) {
+		c.Set(string(contextutils.TenantIDKey), "tenant1")
+		c.Set(string(contextutils.UserIDKey), "user1")
+		c.Set(string(contextutils.PolicyScopeKey), c.Query("scope"))
+	}, s.handler.SearchArchivedLogs)
+}
+
+func TestArchiveHandler(t *testing.T) {
+	suite.Run(t, new(ArchiveHandlerTestSuite))
+}
+
+func (s *ArchiveHandlerTestSuite) TestListArchives_Paginates() {
+	// Arrange
+	expectedFilter := &domain.ArchiveFilter{TenantID: "tenant1", Page: 2, PageSize: 5}
+	s.mockService.On("List", mock.Anything, expectedFilter).
+		Return([]dto.ArchiveResponse{{ID: "archive1", LogCount: 42}}, nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodGet, "/archives?page=2&page_size=5", nil)
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response []dto.ArchiveResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Len(response, 1)
+	s.Equal(int64(42), response[0].LogCount)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *ArchiveHandlerTestSuite) TestVerifyArchive_Success() {
+	// Arrange
+	s.mockService.On("Verify", mock.Anything, "tenant1", "archive1").Return(&dto.ArchiveVerificationResponse{
+		ArchiveID: "archive1",
+		Status:    "corrupted",
+		Objects:   []dto.ArchiveObjectIntegrityResult{{Key: "part-00001.ndjson.gz", Status: "mismatch"}},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/archives/archive1/verify", nil)
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response dto.ArchiveVerificationResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal("corrupted", response.Status)
+	s.Equal("mismatch", response.Objects[0].Status)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *ArchiveHandlerTestSuite) TestVerifyArchive_NotFound() {
+	// Arrange
+	s.mockService.On("Verify", mock.Anything, "tenant1", "missing").Return(nil, service.ErrArchiveNotFound)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/archives/missing/verify", nil)
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *ArchiveHandlerTestSuite) TestSearchArchivedLogs_Filters() {
+	// Arrange
+	s.mockService.On("Search", mock.Anything, mock.MatchedBy(func(f *domain.ArchiveSearchFilter) bool {
+		return f.TenantID == "tenant1" && f.Action == "DELETE" && f.Severity == "ERROR" && f.Limit == 50 &&
+			f.UserID == "user9" && f.EndTime.Equal(time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC))
+	})).Return(&dto.ArchiveSearchResponse{Logs: []dto.AuditLogResponse{{ID: "log1"}}, PartsScanned: 2}, nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodGet,
+		"/logs/archive/search?start_time=2025-01-01&end_time=2025-01-31&action=DELETE&severity=error&user_id=user9&limit=50", nil)
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response dto.ArchiveSearchResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Len(response.Logs, 1)
+	s.Equal(2, response.PartsScanned)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *ArchiveHandlerTestSuite) TestSearchArchivedLogs_OwnScopeOnlyFindsOwnLogs() {
+	// Arrange
+	s.mockService.On("Search", mock.Anything, mock.MatchedBy(func(f *domain.ArchiveSearchFilter) bool {
+		return f.UserID == "user1"
+	})).Return(&dto.ArchiveSearchResponse{}, nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodGet,
+		"/logs/archive/search?start_time=2025-01-01&end_time=2025-01-31&user_id=user9&scope=own", nil)
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *ArchiveHandlerTestSuite) TestSearchArchivedLogs_RequiresRange() {
+	// Arrange
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodGet, "/logs/archive/search?start_time=2025-01-01", nil)
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "Search", mock.Anything, mock.Anything)
+}
+
+func (s *ArchiveHandlerTestSuite) TestSearchArchivedLogs_InvalidSeverity() {
+	// Arrange
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodGet, "/logs/archive/search?start_time=2025-01-01&end_time=2025-01-31&severity=LOUD", nil)
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "Search", mock.Anything, mock.Anything)
+}
@@ -2,13 +2,17 @@ package api
 
 import (
 	"context"
-	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 
 	"github.com/kingrain94/audit-log-api/internal/api/dto"
 	"github.com/kingrain94/audit-log-api/internal/domain"
@@ -20,43 +24,111 @@ import (
 type AuditLogService interface {
 	Create(ctx context.Context, req dto.CreateAuditLogRequest) error
 	BulkCreate(ctx context.Context, reqs []dto.CreateAuditLogRequest) error
+	CreateAsync(ctx context.Context, req dto.CreateAuditLogRequest) (string, error)
+	BulkCreateAsync(ctx context.Context, reqs []dto.CreateAuditLogRequest) ([]string, error)
 	GetByID(ctx context.Context, id string) (*dto.AuditLogResponse, error)
-	List(ctx context.Context, filter *domain.AuditLogFilter, usePagination bool) ([]dto.AuditLogResponse, error)
+	BatchGet(ctx context.Context, ids []string, userID string) (*dto.BatchGetLogsResponse, error)
+	GetDiff(ctx context.Context, id, userID string, unified bool) (*dto.AuditLogDiffResponse, error)
+	GetByCorrelationID(ctx context.Context, tenantID, correlationID, userID string) ([]dto.AuditLogResponse, error)
+	List(ctx context.Context, filter *domain.AuditLogFilter, usePagination bool) ([]dto.AuditLogResponse, string, bool, error)
 	GetStats(ctx context.Context, filter *domain.AuditLogFilter) (*dto.GetAuditLogStatsResponse, error)
 	GetStatsV2(ctx context.Context, filter *domain.AuditLogFilter) (*dto.GetAuditLogStatsResponse, error)
-	ScheduleArchive(ctx context.Context, tenantID string, beforeDate time.Time) error
+	GetTopStats(ctx context.Context, filter *domain.AuditLogFilter, limit int) (*dto.TopStatsResponse, error)
+	ETag(ctx context.Context, name string, filter *domain.AuditLogFilter) string
+	Export(ctx context.Context, filter *domain.AuditLogFilter, format domain.ExportFormat, out io.Writer) (int64, error)
+	Report(ctx context.Context, filter *domain.AuditLogFilter) ([]byte, error)
+	ScheduleArchive(ctx context.Context, tenantID string, beforeDate time.Time) (string, error)
+	CreateExportJob(ctx context.Context, filter *domain.AuditLogFilter, format domain.ExportFormat, signWith domain.ExportSigningKey) (*dto.ExportJobResponse, error)
+	GetExportJob(ctx context.Context, tenantID, jobID string) (*dto.ExportJobResponse, error)
+	GetExportSignature(ctx context.Context, tenantID, jobID string) (*dto.ExportSignatureResponse, error)
+	ExportSigningKeys(ctx context.Context, tenantID string) ([]dto.SigningKeyResponse, error)
+	CreateRestoreJob(ctx context.Context, tenantID string, startTime, endTime time.Time) (*dto.RestoreJobResponse, error)
+	GetRestoreJob(ctx context.Context, tenantID, jobID string) (*dto.RestoreJobResponse, error)
 }
 
+// SavedSearchLookup resolves the saved_search_id query parameter of log queries
+//
+//go:generate mockery --name SavedSearchLookup --output ../mocks
+type SavedSearchLookup interface {
+	GetFilter(ctx context.Context, tenantID, userID, id string) (*domain.SavedSearchFilter, error)
+}
+
+// apiVersion selects how a version of the API shapes the responses of the
+// handlers the versions share, so they are all served by the same service
+// layer. Version 1 is frozen: its responses keep their shape.
+type apiVersion struct {
+	// envelope wraps results in a data object, with the pagination of log
+	// listings in the body rather than in headers
+	envelope bool
+}
+
+var (
+	apiV1 = apiVersion{}
+	apiV2 = apiVersion{envelope: true}
+)
+
+// Values ranked per field by GET /logs/stats/top
+const (
+	topStatsDefaultLimit = 10
+	topStatsMaxLimit     = 100
+)
+
 type AuditLogHandler struct {
 	*BaseHandler
-	service AuditLogService
+	service       AuditLogService
+	savedSearches SavedSearchLookup
 }
 
-func NewAuditLogHandler(service AuditLogService) *AuditLogHandler {
-	return &AuditLogHandler{service: service}
+func NewAuditLogHandler(service AuditLogService, savedSearches SavedSearchLookup) *AuditLogHandler {
+	return &AuditLogHandler{
+		service:       service,
+		savedSearches: savedSearches,
+	}
 }
 
 // CreateLog Create a new audit log entry
 // @Summary Create audit log
-// @Description Create a new audit log entry
+// @Description Create a new audit log entry. With async=true the log is checked and queued, and stored in the background under the returned ID.
 // @Tags    audit_logs
 // @Accept  json
 // @Produce json
+// @Param   async query bool false "Return 202 once the log is queued instead of stored"
 // @Param   body body dto.CreateAuditLogRequest true "Audit log object"
 // @Success 201
+// @Success 202 {object} dto.AcceptedLogsResponse
 // @Failure 400 {object} dto.Error
 // @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error "Monthly quota exceeded"
+// @Failure 429 {object} dto.Error "Daily quota exceeded"
 // @Failure 500 {object} dto.Error
 // @Router  /logs [post]
 func (h *AuditLogHandler) CreateLog(c *gin.Context) {
+	async, err := asyncMode(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
 	var log dto.CreateAuditLogRequest
 	if err := c.ShouldBindJSON(&log); err != nil {
-		c.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+		bindError(c, err)
+		return
+	}
+	upconvertLog(c, &log)
+	fillRequestID(c, &log)
+
+	if async {
+		id, err := h.service.CreateAsync(h.RequestCtx(c), log)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusAccepted, dto.AcceptedLogsResponse{Message: "Log accepted", IDs: []string{id}})
 		return
 	}
 
 	if err := h.service.Create(h.RequestCtx(c), log); err != nil {
-		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -65,25 +137,59 @@ func (h *AuditLogHandler) CreateLog(c *gin.Context) {
 
 // BulkCreateLogs Create multiple audit log entries
 // @Summary Bulk create audit logs
-// @Description Create multiple audit log entries in a single request
+// @Description Create multiple audit log entries in a single request. The body may be compressed with Content-Encoding gzip or deflate; the 10MB size limit applies to the decompressed body. With async=true the logs are checked and queued, and stored in the background under the returned IDs.
 // @Tags    audit_logs
 // @Accept  json
 // @Produce json
+// @Param   Content-Encoding header string false "gzip or deflate for a compressed body"
+// @Param   async query bool false "Return 202 once the logs are queued instead of stored"
 // @Param   body body []dto.CreateAuditLogRequest true "Array of audit log objects"
 // @Success 201
+// @Success 202 {object} dto.AcceptedLogsResponse
 // @Failure 400 {object} dto.Error
 // @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error "Monthly quota exceeded"
+// @Failure 413 {object} dto.Error
+// @Failure 415 {object} dto.Error "Unsupported Content-Encoding"
+// @Failure 429 {object} dto.Error "Daily quota exceeded"
 // @Failure 500 {object} dto.Error
 // @Router  /logs/bulk [post]
 func (h *AuditLogHandler) BulkCreateLogs(c *gin.Context) {
-	var logs []dto.CreateAuditLogRequest
-	if err := c.ShouldBindJSON(&logs); err != nil {
-		c.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+	async, err := asyncMode(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	var bulk dto.BulkCreateAuditLogsRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&bulk.Logs); err != nil {
+		// Compressed bodies are only found too large once decompressed, which
+		// bindError responds to with 413
+		bindError(c, err)
+		return
+	}
+	if err := binding.Validator.ValidateStruct(&bulk); err != nil {
+		bindError(c, err)
+		return
+	}
+	logs := bulk.Logs
+	for i := range logs {
+		upconvertLog(c, &logs[i])
+		fillRequestID(c, &logs[i])
+	}
+
+	if async {
+		ids, err := h.service.BulkCreateAsync(h.RequestCtx(c), logs)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusAccepted, dto.AcceptedLogsResponse{Message: "Logs accepted", IDs: ids})
 		return
 	}
 
 	if err := h.service.BulkCreate(h.RequestCtx(c), logs); err != nil {
-		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -106,17 +212,107 @@ func (h *AuditLogHandler) GetLog(c *gin.Context) {
 
 	log, err := h.service.GetByID(h.RequestCtx(c), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		respondError(c, err)
 		return
 	}
-	if log == nil {
-		c.JSON(http.StatusNotFound, dto.Error{Error: "Log not found"})
+	if log == nil || (ownScopeUserID(c) != "" && log.UserID != ownScopeUserID(c)) {
+		respondError(c, errNotFound("Log not found"))
 		return
 	}
 
 	c.JSON(http.StatusOK, log)
 }
 
+// BatchGetLogs Get several audit logs by ID
+// @Summary Batch get audit logs
+// @Description Fetch up to 100 logs by ID in one round trip, for hydrating lists of references. Returns the logs found, in request order, and the IDs that were not; with exists_only only the IDs are returned.
+// @Tags    audit_logs
+// @Accept  json
+// @Produce json
+// @Param   body body dto.BatchGetLogsRequest true "Log IDs"
+// @Success 200 {object} dto.BatchGetLogsResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /logs/batch-get [post]
+func (h *AuditLogHandler) BatchGetLogs(c *gin.Context) {
+	var req dto.BatchGetLogsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	resp, err := h.service.BatchGet(h.RequestCtx(c), req.IDs, ownScopeUserID(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if req.ExistsOnly {
+		resp.Logs = nil
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetLogDiff Compare the before and after state of an audit log
+// @Summary Get audit log state diff
+// @Description Lists the paths added, removed or changed between a log's before_state and after_state, optionally with a unified text diff for display
+// @Tags    audit_logs
+// @Produce json
+// @Param   id path string true "Log ID"
+// @Param   unified query bool false "Also return a unified text diff of the indented states"
+// @Success 200 {object} dto.AuditLogDiffResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /logs/{id}/diff [get]
+func (h *AuditLogHandler) GetLogDiff(c *gin.Context) {
+	unified := false
+	if value := c.Query("unified"); value != "" {
+		var err error
+		if unified, err = strconv.ParseBool(value); err != nil {
+			respondError(c, errValidation("unified must be a boolean"))
+			return
+		}
+	}
+
+	diff, err := h.service.GetDiff(h.RequestCtx(c), c.Param("id"), ownScopeUserID(c), unified)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// GetCorrelatedLogs Get the audit logs of a request chain
+// @Summary Get audit logs by correlation ID
+// @Description Returns every log sharing a correlation ID, ordered by time, so the steps of a request chain can be followed across services
+// @Tags    audit_logs
+// @Produce json
+// @Param   correlation_id path string true "Correlation ID"
+// @Success 200 {array} dto.AuditLogResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /logs/correlation/{correlation_id} [get]
+func (h *AuditLogHandler) GetCorrelatedLogs(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errValidation("tenant_id is required"))
+		return
+	}
+
+	logs, err := h.service.GetByCorrelationID(h.RequestCtx(c), tenantID, c.Param("correlation_id"), ownScopeUserID(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, logs)
+}
+
 // ListLogs Get a list of audit logs with filtering
 // @Summary List audit logs
 // @Description Get a list of audit logs with filtering options
@@ -124,176 +320,583 @@ func (h *AuditLogHandler) GetLog(c *gin.Context) {
 // @Produce json
 // @Param   page query int false "Page number"
 // @Param   page_size query int false "Page size"
+// @Param   q query string false "Full-text query across message, metadata, user agent and resource ID; supports \"phrases\", +, |, - and prefix*"
 // @Param   user_id query string false "Filter by user ID"
-// @Param   action query string false "Filter by action"
-// @Param   resource_type query string false "Filter by resource type"
-// @Param   severity query string false "Filter by severity"
+// @Param   action query string false "Filter by actions, comma-separated; prefix a value with ! or use action!= to exclude it"
+// @Param   resource_type query string false "Filter by resource types, comma-separated; prefix a value with ! or use resource_type!= to exclude it"
+// @Param   severity query string false "Filter by severities, comma-separated; prefix a value with ! or use severity!= to exclude it"
+// @Param   tags query string false "Filter by tags, comma-separated, matching logs with any of them; prefix a value with ! or use tags!= to exclude logs with it"
+// @Param   min_threat_score query int false "Filter by the threat score of the log's IP address, 0 to 100, matching logs scoring at least this"
 // @Param   start_time query string true "Filter by start time (RFC3339 or YYYY-MM-DD)" example:"2024-03-20T00:00:00Z"
 // @Param   end_time query string true "Filter by end time (RFC3339 or YYYY-MM-DD)" example:"2024-03-20T23:59:59Z"
+// @Param   saved_search_id query string false "Saved search supplying the filters and time range not given in the query"
+// @Param   fields query string false "Comma-separated fields to return, such as id,action,timestamp,message; all fields when omitted"
+// @Param   sort query string false "Comma-separated sort keys timestamp, severity or action, each optionally suffixed with :asc or :desc, such as severity:desc,timestamp; newest first when omitted"
+// @Param   cursor query string false "X-Next-Cursor of the previous page, to page through searches beyond 10,000 logs; page is then ignored"
+// @Param   If-None-Match header string false "ETag of a previous response; 304 is returned if the logs are unchanged"
 // @Success 200 {array} dto.AuditLogResponse
+// @Header  200 {string} X-Next-Cursor "Cursor of the next page of a search, when it may hold more logs"
+// @Header  200 {string} X-Degraded-Mode "true when OpenSearch failed and the search was served by PostgreSQL, without highlights"
+// @Success 304 "Logs unchanged since the response tagged If-None-Match"
 // @Failure 401 {object} dto.Error
 // @Failure 500 {object} dto.Error
 // @Router  /logs [get]
 func (h *AuditLogHandler) ListLogs(c *gin.Context) {
-	filter, err := getFilterFromQuery(c)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+	h.listLogs(c, apiV1)
+}
+
+// ListLogsV2 Get a page of audit logs in an envelope
+// @Summary List audit logs (v2)
+// @Description Get a page of audit logs with filtering options, enveloped with its pagination. Pages are fetched by passing the next_cursor of the previous page as cursor, whether the logs are searched or listed. Served at /api/v2/logs.
+// @Tags    audit_logs
+// @Produce json
+// @Param   page_size query int false "Page size"
+// @Param   cursor query string false "next_cursor of the previous page; the first page when omitted"
+// @Param   q query string false "Full-text query across message, metadata, user agent and resource ID; supports \"phrases\", +, |, - and prefix*"
+// @Param   user_id query string false "Filter by user ID"
+// @Param   action query string false "Filter by actions, comma-separated; prefix a value with ! or use action!= to exclude it"
+// @Param   resource_type query string false "Filter by resource types, comma-separated; prefix a value with ! or use resource_type!= to exclude it"
+// @Param   severity query string false "Filter by severities, comma-separated; prefix a value with ! or use severity!= to exclude it"
+// @Param   tags query string false "Filter by tags, comma-separated, matching logs with any of them; prefix a value with ! or use tags!= to exclude logs with it"
+// @Param   min_threat_score query int false "Filter by the threat score of the log's IP address, 0 to 100, matching logs scoring at least this"
+// @Param   start_time query string true "Filter by start time (RFC3339 or YYYY-MM-DD)" example:"2024-03-20T00:00:00Z"
+// @Param   end_time query string true "Filter by end time (RFC3339 or YYYY-MM-DD)" example:"2024-03-20T23:59:59Z"
+// @Param   saved_search_id query string false "Saved search supplying the filters and time range not given in the query"
+// @Param   fields query string false "Comma-separated fields to return, such as id,action,timestamp,message; all fields when omitted"
+// @Param   sort query string false "Comma-separated sort keys timestamp, severity or action, each optionally suffixed with :asc or :desc, such as severity:desc,timestamp; newest first when omitted"
+// @Param   If-None-Match header string false "ETag of a previous response; 304 is returned if the logs are unchanged"
+// @Success 200 {object} dto.LogPageResponse
+// @Success 304 "Logs unchanged since the response tagged If-None-Match"
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /v2/logs [get]
+func (h *AuditLogHandler) ListLogsV2(c *gin.Context) {
+	h.listLogs(c, apiV2)
+}
+
+// listLogs lists a page of logs in the response shape of version
+func (h *AuditLogHandler) listLogs(c *gin.Context, version apiVersion) {
+	filter, ok := h.bindFilter(c)
+	if !ok {
+		return
+	}
+	if notModified(c, h.service.ETag(h.RequestCtx(c), "logs", filter)) {
 		return
 	}
 
-	logs, err := h.service.List(h.RequestCtx(c), filter, true)
+	logs, nextCursor, degraded, err := h.service.List(h.RequestCtx(c), filter, true)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, logs)
+	var data any = logs
+	if len(filter.Fields) > 0 {
+		data = dto.SelectAuditLogFields(logs, filter.Fields)
+	}
+
+	if !version.envelope {
+		if nextCursor != "" {
+			c.Header("X-Next-Cursor", nextCursor)
+		}
+		if degraded {
+			c.Header("X-Degraded-Mode", "true")
+		}
+		c.JSON(http.StatusOK, data)
+		return
+	}
+
+	// Full pages of PostgreSQL, which come without a search cursor, continue
+	// at the next page
+	if nextCursor == "" && len(logs) > 0 && len(logs) == filter.PageSize {
+		nextCursor = domain.EncodePageCursor(max(filter.Page, 1) + 1)
+	}
+	c.JSON(http.StatusOK, dto.LogPageResponse{
+		Data: data,
+		Pagination: dto.PaginationResponse{
+			PageSize:   filter.PageSize,
+			NextCursor: nextCursor,
+			HasMore:    nextCursor != "",
+		},
+		Degraded: degraded,
+	})
 }
 
-// ExportLogs Export audit logs in JSON or CSV format
+// ExportLogs Export audit logs in JSON, CSV or Excel format
 // @Summary Export audit logs
-// @Description Export audit logs with filtering options in JSON or CSV format
+// @Description Export audit logs with filtering options in JSON, CSV or Excel format, oldest first.
+// @Description Excel workbooks have a frozen header row, sized columns and severities color coded from WARNING up.
+// @Description The logs are streamed with chunked transfer encoding as they are read, so exports of any size use bounded memory.
 // @Tags    audit_logs
-// @Produce json,text/csv
-// @Param   format query string false "Export format (json or csv)" default(json)
+// @Produce json,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param   format query string false "Export format (json, csv or xlsx)" default(json)
+// @Param   q query string false "Full-text query across message, metadata, user agent and resource ID; supports \"phrases\", +, |, - and prefix*"
 // @Param   user_id query string false "Filter by user ID"
-// @Param   action query string false "Filter by action"
-// @Param   resource_type query string false "Filter by resource type"
-// @Param   severity query string false "Filter by severity"
+// @Param   action query string false "Filter by actions, comma-separated; prefix a value with ! or use action!= to exclude it"
+// @Param   resource_type query string false "Filter by resource types, comma-separated; prefix a value with ! or use resource_type!= to exclude it"
+// @Param   severity query string false "Filter by severities, comma-separated; prefix a value with ! or use severity!= to exclude it"
+// @Param   tags query string false "Filter by tags, comma-separated, matching logs with any of them; prefix a value with ! or use tags!= to exclude logs with it"
+// @Param   min_threat_score query int false "Filter by the threat score of the log's IP address, 0 to 100, matching logs scoring at least this"
 // @Param   start_time query string true "Filter by start time (RFC3339 or YYYY-MM-DD)" example:"2024-03-20T00:00:00Z"
 // @Param   end_time query string true "Filter by end time (RFC3339 or YYYY-MM-DD)" example:"2024-03-20T23:59:59Z"
+// @Param   saved_search_id query string false "Saved search supplying the filters and time range not given in the query"
+// @Param   fields query string false "Comma-separated fields to return, such as id,action,timestamp,message; all fields when omitted"
 // @Success 200 {file} file
 // @Failure 400 {object} dto.Error
 // @Failure 401 {object} dto.Error
 // @Failure 500 {object} dto.Error
 // @Router  /logs/export [get]
 func (h *AuditLogHandler) ExportLogs(c *gin.Context) {
-	format := c.DefaultQuery("format", "json")
-	if format != "json" && format != "csv" {
-		c.JSON(http.StatusBadRequest, dto.Error{Error: "Invalid format. Must be 'json' or 'csv'"})
+	format, ok := bindExportFormat(c)
+	if !ok {
+		return
+	}
+
+	filter, ok := h.bindFilter(c)
+	if !ok {
 		return
 	}
 
-	filter, err := getFilterFromQuery(c)
+	c.Header("Content-Disposition", "attachment; filename=audit_logs."+string(format))
+	c.Header("Content-Type", format.ContentType())
+
+	// The status and headers are sent with the first rows, so an error before
+	// them still gets an error response; a later one truncates the export
+	if _, err := h.service.Export(h.RequestCtx(c), filter, format, c.Writer); err != nil {
+		if c.Writer.Written() {
+			_ = c.Error(err)
+			return
+		}
+		c.Writer.Header().Del("Content-Disposition")
+		c.Writer.Header().Del("Content-Type")
+		respondError(c, err)
+	}
+}
+
+// GetReport Generate a compliance report on audit logs
+// @Summary Get compliance report
+// @Description Generate a PDF summary of the audit logs of a time range for compliance audits: stats tables, severity and activity charts, the top actions and resource types, sample entries and an integrity verification.
+// @Description The verification checks the logs read against the stats and gives the SHA-256 digest of the JSON export of the same filters.
+// @Tags    audit_logs
+// @Produce application/pdf
+// @Param   format query string false "Report format (pdf)" default(pdf)
+// @Param   start_time query string true "Filter by start time (RFC3339 or YYYY-MM-DD)" example:"2024-03-20T00:00:00Z"
+// @Param   end_time query string true "Filter by end time (RFC3339 or YYYY-MM-DD)" example:"2024-03-20T23:59:59Z"
+// @Param   saved_search_id query string false "Saved search supplying the filters and time range not given in the query"
+// @Param   q query string false "Full-text query across message, metadata, user agent and resource ID; supports \"phrases\", +, |, - and prefix*"
+// @Param   user_id query string false "Filter by user ID"
+// @Param   action query string false "Filter by actions, comma-separated; prefix a value with ! or use action!= to exclude it"
+// @Param   resource_type query string false "Filter by resource types, comma-separated; prefix a value with ! or use resource_type!= to exclude it"
+// @Param   severity query string false "Filter by severities, comma-separated; prefix a value with ! or use severity!= to exclude it"
+// @Param   tags query string false "Filter by tags, comma-separated, matching logs with any of them; prefix a value with ! or use tags!= to exclude logs with it"
+// @Param   min_threat_score query int false "Filter by the threat score of the log's IP address, 0 to 100, matching logs scoring at least this"
+// @Success 200 {file} file
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /logs/report [get]
+func (h *AuditLogHandler) GetReport(c *gin.Context) {
+	if format := c.DefaultQuery("format", "pdf"); format != "pdf" {
+		respondError(c, errValidation("Invalid format. Must be 'pdf'"))
+		return
+	}
+
+	filter, ok := h.bindFilter(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.Report(h.RequestCtx(c), filter)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=audit_report.pdf")
+	c.Data(http.StatusOK, "application/pdf", report)
+}
+
+// CreateExportJob Start an asynchronous export of audit logs
+// @Summary Create export job
+// @Description Enqueue an export job that streams matching audit logs to S3 in JSON, CSV or Excel format. Poll the job for a download URL.
+// @Description With sign, the file is signed once uploaded with the server's key or the tenant's own; the signature and the public key verifying it are served by GET /logs/export/{job_id}/signature.
+// @Tags    audit_logs
+// @Produce json
+// @Param   format query string false "Export format (json, csv or xlsx)" default(json)
+// @Param   sign query string false "Sign the export with the server's key or the tenant's own" Enums(server, tenant)
+// @Param   q query string false "Full-text query across message, metadata, user agent and resource ID; supports \"phrases\", +, |, - and prefix*"
+// @Param   user_id query string false "Filter by user ID"
+// @Param   action query string false "Filter by actions, comma-separated; prefix a value with ! or use action!= to exclude it"
+// @Param   resource_type query string false "Filter by resource types, comma-separated; prefix a value with ! or use resource_type!= to exclude it"
+// @Param   severity query string false "Filter by severities, comma-separated; prefix a value with ! or use severity!= to exclude it"
+// @Param   tags query string false "Filter by tags, comma-separated, matching logs with any of them; prefix a value with ! or use tags!= to exclude logs with it"
+// @Param   min_threat_score query int false "Filter by the threat score of the log's IP address, 0 to 100, matching logs scoring at least this"
+// @Param   start_time query string true "Filter by start time (RFC3339 or YYYY-MM-DD)" example:"2024-03-20T00:00:00Z"
+// @Param   end_time query string true "Filter by end time (RFC3339 or YYYY-MM-DD)" example:"2024-03-20T23:59:59Z"
+// @Param   saved_search_id query string false "Saved search supplying the filters and time range not given in the query"
+// @Param   fields query string false "Comma-separated fields to return, such as id,action,timestamp,message; all fields when omitted"
+// @Success 202 {object} dto.ExportJobResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /logs/export [post]
+func (h *AuditLogHandler) CreateExportJob(c *gin.Context) {
+	format, ok := bindExportFormat(c)
+	if !ok {
+		return
+	}
+
+	signWith := domain.ExportSigningKey(c.Query("sign"))
+	if signWith != "" && !slices.Contains(domain.ExportSigningKeys, signWith) {
+		respondError(c, errValidation("Invalid sign. Must be 'server' or 'tenant'"))
+		return
+	}
+
+	filter, ok := h.bindFilter(c)
+	if !ok {
 		return
 	}
 
-	logs, err := h.service.List(h.RequestCtx(c), filter, false)
+	job, err := h.service.CreateExportJob(h.RequestCtx(c), filter, format, signWith)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	switch format {
-	case "json":
-		c.Header("Content-Disposition", "attachment; filename=audit_logs.json")
-		c.JSON(http.StatusOK, logs)
-	case "csv":
-		c.Header("Content-Disposition", "attachment; filename=audit_logs.csv")
-		c.Header("Content-Type", "text/csv")
+	c.JSON(http.StatusAccepted, job)
+}
 
-		writer := csv.NewWriter(c.Writer)
-		defer writer.Flush()
+// bindExportFormat returns the export format of the query, json by default,
+// writing an error response and returning false if it isn't one of
+// domain.LogExportFormats
+func bindExportFormat(c *gin.Context) (domain.ExportFormat, bool) {
+	format := domain.ExportFormat(c.DefaultQuery("format", string(domain.ExportFormatJSON)))
+	if !slices.Contains(domain.LogExportFormats, format) {
+		respondError(c, errValidation("Invalid format. Must be 'json', 'csv' or 'xlsx'"))
+		return "", false
+	}
+	return format, true
+}
 
-		// Write CSV header
-		header := []string{
-			"ID", "TenantID", "UserID", "SessionID", "Action",
-			"ResourceType", "ResourceID", "IPAddress", "UserAgent",
-			"Severity", "Message", "BeforeState", "AfterState",
-			"Metadata", "Timestamp",
-		}
-		if err := writer.Write(header); err != nil {
-			c.JSON(http.StatusInternalServerError, dto.Error{Error: "Failed to write CSV header"})
-			return
-		}
+// GetExportJob Get the status of an export job
+// @Summary Get export job
+// @Description Get the status of an export job, including a pre-signed download URL once it has completed
+// @Tags    audit_logs
+// @Produce json
+// @Param   job_id path string true "Export job ID"
+// @Success 200 {object} dto.ExportJobResponse
+// @Failure 401 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /logs/export/{job_id} [get]
+func (h *AuditLogHandler) GetExportJob(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
 
-		// Write each log entry as CSV
-		for _, log := range logs {
-			// Convert JSON fields to strings
-			beforeState := ""
-			if log.BeforeState != nil {
-				beforeState = string(log.BeforeState)
-			}
-			afterState := ""
-			if log.AfterState != nil {
-				afterState = string(log.AfterState)
-			}
-			metadata := ""
-			if log.Metadata != nil {
-				metadata = string(log.Metadata)
-			}
+	job, err := h.service.GetExportJob(h.RequestCtx(c), tenantID, c.Param("job_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
 
-			record := []string{
-				log.ID,
-				log.TenantID,
-				log.UserID,
-				log.SessionID,
-				log.Action,
-				log.ResourceType,
-				log.ResourceID,
-				log.IPAddress,
-				log.UserAgent,
-				log.Severity,
-				log.Message,
-				beforeState,
-				afterState,
-				metadata,
-				log.Timestamp.Format(time.RFC3339),
-			}
+	c.JSON(http.StatusOK, job)
+}
 
-			if err := writer.Write(record); err != nil {
-				c.JSON(http.StatusInternalServerError, dto.Error{Error: "Failed to write CSV record"})
-				return
-			}
-		}
+// GetExportSignature Get the signature of an export
+// @Summary Get export signature
+// @Description Get the detached signature of a signed export job's file, for recipients to verify the download wasn't altered: the Ed25519 signature, base64-encoded, of the raw SHA-256 digest of the file as downloaded, with the PEM-encoded public key verifying it
+// @Tags    audit_logs
+// @Produce json
+// @Param   job_id path string true "Export job ID"
+// @Success 200 {object} dto.ExportSignatureResponse
+// @Failure 401 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /logs/export/{job_id}/signature [get]
+func (h *AuditLogHandler) GetExportSignature(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
 	}
+
+	signature, err := h.service.GetExportSignature(h.RequestCtx(c), tenantID, c.Param("job_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, signature)
+}
+
+// GetExportSigningKeys List the keys exports are signed with
+// @Summary List export signing keys
+// @Description List the public keys the authenticated tenant's exports are signed with, the server's and the tenant's own, to hand to recipients verifying exports; empty when signing isn't configured
+// @Tags    audit_logs
+// @Produce json
+// @Success 200 {array} dto.SigningKeyResponse
+// @Failure 401 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /logs/export/signing-keys [get]
+func (h *AuditLogHandler) GetExportSigningKeys(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	keys, err := h.service.ExportSigningKeys(h.RequestCtx(c), tenantID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
 }
 
 // GetStats Get audit log statistics
 // @Summary Get log statistics
-// @Description Get statistics about audit logs including counts by action, severity, and resource
+// @Description Get statistics about audit logs including counts by action, severity, and resource.
+// @Description When search filters are given, stats are aggregated in OpenSearch and include a time-bucketed series.
 // @Tags    audit_logs
 // @Produce json
 // @Param   start_time query string true "Filter by start time (RFC3339 or YYYY-MM-DD)" example:"2024-03-20T00:00:00Z"
 // @Param   end_time query string true "Filter by end time (RFC3339 or YYYY-MM-DD)" example:"2024-03-20T23:59:59Z"
+// @Param   saved_search_id query string false "Saved search supplying the filters and time range not given in the query"
+// @Param   q query string false "Full-text query across message, metadata, user agent and resource ID; supports \"phrases\", +, |, - and prefix*"
+// @Param   user_id query string false "Filter by user ID"
+// @Param   action query string false "Filter by actions, comma-separated; prefix a value with ! or use action!= to exclude it"
+// @Param   resource_type query string false "Filter by resource types, comma-separated; prefix a value with ! or use resource_type!= to exclude it"
+// @Param   severity query string false "Filter by severities, comma-separated; prefix a value with ! or use severity!= to exclude it"
+// @Param   tags query string false "Filter by tags, comma-separated, matching logs with any of them; prefix a value with ! or use tags!= to exclude logs with it"
+// @Param   min_threat_score query int false "Filter by the threat score of the log's IP address, 0 to 100, matching logs scoring at least this"
+// @Param   If-None-Match header string false "ETag of a previous response; 304 is returned if the stats are unchanged"
 // @Success 200 {object} dto.GetAuditLogStatsResponse
+// @Success 304 "Stats unchanged since the response tagged If-None-Match"
 // @Failure 401 {object} dto.Error
 // @Failure 500 {object} dto.Error
 // @Router  /logs/stats [get]
 func (h *AuditLogHandler) GetStats(c *gin.Context) {
-	filter, err := getFilterFromQuery(c)
+	h.getStats(c, apiV1)
+}
+
+// GetStatsV2 Get audit log statistics in an envelope
+// @Summary Get audit log statistics (v2)
+// @Description Get counts of audit logs by action, severity and resource type for a time range, enveloped in a data object. Served at /api/v2/logs/stats.
+// @Tags    audit_logs
+// @Produce json
+// @Param   start_time query string true "Filter by start time (RFC3339 or YYYY-MM-DD)" example:"2024-03-20T00:00:00Z"
+// @Param   end_time query string true "Filter by end time (RFC3339 or YYYY-MM-DD)" example:"2024-03-20T23:59:59Z"
+// @Param   saved_search_id query string false "Saved search supplying the filters and time range not given in the query"
+// @Param   q query string false "Full-text query across message, metadata, user agent and resource ID; supports \"phrases\", +, |, - and prefix*"
+// @Param   user_id query string false "Filter by user ID"
+// @Param   action query string false "Filter by actions, comma-separated; prefix a value with ! or use action!= to exclude it"
+// @Param   resource_type query string false "Filter by resource types, comma-separated; prefix a value with ! or use resource_type!= to exclude it"
+// @Param   severity query string false "Filter by severities, comma-separated; prefix a value with ! or use severity!= to exclude it"
+// @Param   tags query string false "Filter by tags, comma-separated, matching logs with any of them; prefix a value with ! or use tags!= to exclude logs with it"
+// @Param   min_threat_score query int false "Filter by the threat score of the log's IP address, 0 to 100, matching logs scoring at least this"
+// @Param   If-None-Match header string false "ETag of a previous response; 304 is returned if the stats are unchanged"
+// @Success 200 {object} dto.StatsEnvelopeResponse
+// @Success 304 "Stats unchanged since the response tagged If-None-Match"
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /v2/logs/stats [get]
+func (h *AuditLogHandler) GetStatsV2(c *gin.Context) {
+	h.getStats(c, apiV2)
+}
+
+// GetTopStats Get the top values and unique counts of audit logs
+// @Summary Get top users, IPs, resources and sessions
+// @Description Rank the users, IP addresses, resources and sessions of the logs in a time range by log count, with each one's share of the logs, and count distinct users and IP addresses over the range and per UTC day.
+// @Description Aggregated in OpenSearch; unique counts are approximate.
+// @Tags    audit_logs
+// @Produce json
+// @Param   start_time query string true "Filter by start time (RFC3339 or YYYY-MM-DD)" example:"2024-03-20T00:00:00Z"
+// @Param   end_time query string true "Filter by end time (RFC3339 or YYYY-MM-DD)" example:"2024-03-20T23:59:59Z"
+// @Param   limit query int false "Values ranked per field, 1 to 100" default(10)
+// @Param   saved_search_id query string false "Saved search supplying the filters and time range not given in the query"
+// @Param   q query string false "Full-text query across message, metadata, user agent and resource ID; supports \"phrases\", +, |, - and prefix*"
+// @Param   user_id query string false "Filter by user ID"
+// @Param   action query string false "Filter by actions, comma-separated; prefix a value with ! or use action!= to exclude it"
+// @Param   resource_type query string false "Filter by resource types, comma-separated; prefix a value with ! or use resource_type!= to exclude it"
+// @Param   severity query string false "Filter by severities, comma-separated; prefix a value with ! or use severity!= to exclude it"
+// @Param   tags query string false "Filter by tags, comma-separated, matching logs with any of them; prefix a value with ! or use tags!= to exclude logs with it"
+// @Param   min_threat_score query int false "Filter by the threat score of the log's IP address, 0 to 100, matching logs scoring at least this"
+// @Success 200 {object} dto.TopStatsResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /logs/stats/top [get]
+func (h *AuditLogHandler) GetTopStats(c *gin.Context) {
+	filter, ok := h.bindFilter(c)
+	if !ok {
+		return
+	}
+	if filter.StartTime.IsZero() || filter.EndTime.IsZero() {
+		respondError(c, errValidation("start_time and end_time parameters are required"))
+		return
+	}
+
+	limit := topStatsDefaultLimit
+	if value := c.Query("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 || limit > topStatsMaxLimit {
+			respondError(c, errValidation(fmt.Sprintf("limit must be between 1 and %d", topStatsMaxLimit)))
+			return
+		}
+	}
+
+	stats, err := h.service.GetTopStats(h.RequestCtx(c), filter, limit)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// getStats computes log stats in the response shape of version
+func (h *AuditLogHandler) getStats(c *gin.Context, version apiVersion) {
+	filter, ok := h.bindFilter(c)
+	if !ok {
+		return
+	}
+	if notModified(c, h.service.ETag(h.RequestCtx(c), "stats", filter)) {
 		return
 	}
 
 	stats, err := h.service.GetStatsV2(h.RequestCtx(c), filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		respondError(c, err)
 		return
 	}
 
+	if version.envelope {
+		c.JSON(http.StatusOK, dto.StatsEnvelopeResponse{Data: stats})
+		return
+	}
 	c.JSON(http.StatusOK, stats)
 }
 
-func getFilterFromQuery(c *gin.Context) (*domain.AuditLogFilter, error) {
+// notModified sets the response's ETag and, if the request's If-None-Match
+// matches it, writes 304 and returns true. Tags are compared weakly, as
+// RFC 9110 requires for If-None-Match.
+func notModified(c *gin.Context, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	c.Header("ETag", etag)
+
+	for _, tag := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// bindFilter builds the log filter of a query, writing an error response and
+// returning false if it is invalid or names a saved search the caller can't see
+func (h *AuditLogHandler) bindFilter(c *gin.Context) (*domain.AuditLogFilter, bool) {
+	var saved *domain.SavedSearchFilter
+	if id := c.Query("saved_search_id"); id != "" {
+		var err error
+		saved, err = h.savedSearches.GetFilter(h.RequestCtx(c), c.GetString(string(contextutils.TenantIDKey)), c.GetString(string(contextutils.UserIDKey)), id)
+		if err != nil {
+			respondError(c, err)
+			return nil, false
+		}
+	}
+
+	filter, err := getFilterFromQuery(c, saved)
+	if err != nil {
+		bindError(c, err)
+		return nil, false
+	}
+	return filter, true
+}
+
+// bindTimeRange reads the required start_time and end_time query
+// parameters, writing an error response and returning false if they are
+// missing or invalid
+func bindTimeRange(c *gin.Context) (start, end time.Time, ok bool) {
+	startStr, endStr := c.Query("start_time"), c.Query("end_time")
+	if startStr == "" || endStr == "" {
+		respondError(c, errValidation("start_time and end_time parameters are required"))
+		return time.Time{}, time.Time{}, false
+	}
+
+	start, err := utils.ParseUserTime(startStr, false)
+	if err != nil {
+		respondError(c, errValidation("Invalid start_time format: "+err.Error()))
+		return time.Time{}, time.Time{}, false
+	}
+	end, err = utils.ParseUserTime(endStr, true)
+	if err != nil {
+		respondError(c, errValidation("Invalid end_time format: "+err.Error()))
+		return time.Time{}, time.Time{}, false
+	}
+	if start.After(end) {
+		respondError(c, errValidation("start_time must be before end_time"))
+		return time.Time{}, time.Time{}, false
+	}
+	return start, end, true
+}
+
+// secondsUntilMidnightUTC returns when daily quotas reset, rounded up
+func secondsUntilMidnightUTC() int {
+	now := time.Now().UTC()
+	midnight := now.Truncate(24 * time.Hour).Add(24 * time.Hour)
+	return int(midnight.Sub(now).Seconds()) + 1
+}
+
+// getFilterFromQuery builds a log filter from query parameters. Criteria and
+// time bounds missing from the query are taken from saved, if given.
+func getFilterFromQuery(c *gin.Context, saved *domain.SavedSearchFilter) (*domain.AuditLogFilter, error) {
 	tenantID := c.GetString(string(contextutils.TenantIDKey))
 	if tenantID == "" {
 		return nil, fmt.Errorf("tenant_id is required")
 	}
 
 	filter := &domain.AuditLogFilter{
-		TenantID:     tenantID,
-		UserID:       c.Query("user_id"),
-		Action:       c.Query("action"),
-		ResourceType: c.Query("resource_type"),
-		Severity:     c.Query("severity"),
-		SessionID:    c.Query("session_id"),
-		IPAddress:    c.Query("ip_address"),
-		UserAgent:    c.Query("user_agent"),
-		Message:      c.Query("message"),
+		TenantID:      tenantID,
+		UserID:        c.Query("user_id"),
+		Action:        getValueFilterFromQuery(c, "action"),
+		ResourceType:  getValueFilterFromQuery(c, "resource_type"),
+		Severity:      getValueFilterFromQuery(c, "severity"),
+		Tags:          getValueFilterFromQuery(c, "tags"),
+		SessionID:     c.Query("session_id"),
+		IPAddress:     c.Query("ip_address"),
+		UserAgent:     c.Query("user_agent"),
+		Message:       c.Query("message"),
+		Query:         c.Query("q"),
+		CorrelationID: c.Query("correlation_id"),
+	}
+
+	fields, err := getFieldsFromQuery(c)
+	if err != nil {
+		return nil, err
+	}
+	filter.Fields = fields
+
+	sort, err := domain.ParseSort(c.Query("sort"))
+	if err != nil {
+		return nil, err
+	}
+	filter.Sort = sort
+
+	if minScore := c.Query("min_threat_score"); minScore != "" {
+		score, err := strconv.Atoi(minScore)
+		if err != nil || score < 0 || score > 100 {
+			return nil, fmt.Errorf("min_threat_score must be a number from 0 to 100")
+		}
+		filter.MinThreatScore = score
 	}
 
 	// Parse pagination
@@ -308,6 +911,20 @@ func getFilterFromQuery(c *gin.Context) (*domain.AuditLogFilter, error) {
 		}
 	}
 
+	// A cursor continues the search after the previous page, however deep,
+	// or a listing of PostgreSQL at its next page
+	if cursor := c.Query("cursor"); cursor != "" {
+		if page, ok := domain.ParsePageCursor(cursor); ok {
+			filter.Page = page
+		} else {
+			searchAfter, err := domain.ParseSearchCursor(cursor)
+			if err != nil {
+				return nil, err
+			}
+			filter.SearchAfter = searchAfter
+		}
+	}
+
 	// Parse time filters
 	if startTime := c.Query("start_time"); startTime != "" {
 		t, err := utils.ParseUserTime(startTime, false)
@@ -315,8 +932,6 @@ func getFilterFromQuery(c *gin.Context) (*domain.AuditLogFilter, error) {
 			return nil, err
 		}
 		filter.StartTime = t
-	} else {
-		return nil, fmt.Errorf("start_time is required")
 	}
 	if endTime := c.Query("end_time"); endTime != "" {
 		t, err := utils.ParseUserTime(endTime, true)
@@ -324,19 +939,59 @@ func getFilterFromQuery(c *gin.Context) (*domain.AuditLogFilter, error) {
 			return nil, err
 		}
 		filter.EndTime = t
-	} else {
+	}
+
+	if saved != nil {
+		saved.ApplyDefaults(filter, time.Now())
+	}
+	if filter.StartTime.IsZero() {
+		return nil, fmt.Errorf("start_time is required")
+	}
+	if filter.EndTime.IsZero() {
 		return nil, fmt.Errorf("end_time is required")
 	}
 	if filter.StartTime.After(filter.EndTime) {
 		return nil, fmt.Errorf("start_time must be before end_time")
 	}
 
+	// Own-scoped callers only see their own logs, whatever user_id they ask
+	// for; the repositories enforce VisibleUserID on every read
+	if userID := ownScopeUserID(c); userID != "" {
+		filter.UserID = userID
+		filter.VisibleUserID = userID
+	}
+
 	return filter, nil
 }
 
+// getValueFilterFromQuery reads a multi-value filter: name=A,B or repeated name
+// parameters match any of the values, and name!=C excludes values
+func getValueFilterFromQuery(c *gin.Context, name string) domain.ValueFilter {
+	filter := domain.ParseValueFilter(strings.Join(c.QueryArray(name), ","))
+	excluded := domain.ParseValueFilter(strings.Join(c.QueryArray(name+"!"), ","))
+	filter.NotIn = append(filter.NotIn, excluded.In...)
+	return filter
+}
+
+// getFieldsFromQuery reads the comma-separated fields to return, nil for all
+func getFieldsFromQuery(c *gin.Context) ([]string, error) {
+	var fields []string
+	for _, field := range strings.Split(c.Query("fields"), ",") {
+		field = strings.TrimSpace(field)
+		if field == "" || slices.Contains(fields, field) {
+			continue
+		}
+		if !slices.Contains(domain.AuditLogFields, field) {
+			return nil, fmt.Errorf("unknown field %q", field)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
 // Cleanup Schedule cleanup operation for audit logs
 // @Summary Schedule cleanup operation
-// @Description Enqueues an archive job message to SQS for logs before the specified date
+// @Description Enqueues an archive job message to SQS for logs before the specified date. The returned job_id tracks the archival and deletion at GET /jobs/{id}.
 // @Tags audit-logs
 // @Accept json
 // @Produce json
@@ -350,38 +1005,116 @@ func getFilterFromQuery(c *gin.Context) (*domain.AuditLogFilter, error) {
 func (h *AuditLogHandler) Cleanup(c *gin.Context) {
 	tenantID := c.GetString(string(contextutils.TenantIDKey))
 	if tenantID == "" {
-		c.JSON(http.StatusUnauthorized, dto.Error{Error: "No tenant ID found"})
+		respondError(c, errNoTenant)
 		return
 	}
 
 	// Parse before_date from query parameter
 	beforeDateStr := c.Query("before_date")
 	if beforeDateStr == "" {
-		c.JSON(http.StatusBadRequest, dto.Error{Error: "before_date parameter is required"})
+		respondError(c, errValidation("before_date parameter is required"))
 		return
 	}
 
 	beforeDate, err := utils.ParseUserTime(beforeDateStr, true)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.Error{Error: "Invalid before_date format: " + err.Error()})
+		respondError(c, errValidation("Invalid before_date format: "+err.Error()))
 		return
 	}
 
 	// Validate that the date is not in the future
 	if beforeDate.After(time.Now()) {
-		c.JSON(http.StatusBadRequest, dto.Error{Error: "before_date cannot be in the future"})
+		respondError(c, errValidation("before_date cannot be in the future"))
 		return
 	}
 
 	// Enqueue archive message to SQS
-	if err := h.service.ScheduleArchive(c.Request.Context(), tenantID, beforeDate); err != nil {
-		c.JSON(http.StatusInternalServerError, dto.Error{Error: "Failed to schedule cleanup: " + err.Error()})
+	jobID, err := h.service.ScheduleArchive(c.Request.Context(), tenantID, beforeDate)
+	if err != nil {
+		respondError(c, fmt.Errorf("failed to schedule cleanup: %w", err))
 		return
 	}
 
 	c.JSON(http.StatusAccepted, gin.H{
 		"message":     "Cleanup operation scheduled successfully",
+		"job_id":      jobID,
 		"tenant_id":   tenantID,
 		"before_date": beforeDate.Format(time.RFC3339),
 	})
 }
+
+// RestoreLogs Restore archived audit logs
+// @Summary Restore archived logs
+// @Description Enqueues a restore job that re-imports archived logs in the time range from S3 into PostgreSQL and re-indexes them in OpenSearch
+// @Tags audit-logs
+// @Produce json
+// @Param start_time query string true "Restore logs from this time (RFC3339 or YYYY-MM-DD)"
+// @Param end_time query string true "Restore logs up to this time (RFC3339 or YYYY-MM-DD)"
+// @Success 202 {object} dto.RestoreJobResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Security ApiKeyAuth
+// @Router /api/v1/logs/restore [post]
+func (h *AuditLogHandler) RestoreLogs(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	startTime, endTime, ok := bindTimeRange(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.service.CreateRestoreJob(h.RequestCtx(c), tenantID, startTime, endTime)
+	if err != nil {
+		respondError(c, fmt.Errorf("failed to schedule restore: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetRestoreJob Get the status of a restore job
+// @Summary Get restore job
+// @Description Get the progress of an archive restore job
+// @Tags audit-logs
+// @Produce json
+// @Param job_id path string true "Restore job ID"
+// @Success 200 {object} dto.RestoreJobResponse
+// @Failure 401 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Security ApiKeyAuth
+// @Router /api/v1/logs/restore/{job_id} [get]
+func (h *AuditLogHandler) GetRestoreJob(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	job, err := h.service.GetRestoreJob(h.RequestCtx(c), tenantID, c.Param("job_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// asyncMode reports whether ?async=true asks for logs to be queued and stored
+// in the background
+func asyncMode(c *gin.Context) (bool, error) {
+	value := c.Query("async")
+	if value == "" {
+		return false, nil
+	}
+	async, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errValidation("async must be a boolean")
+	}
+	return async, nil
+}
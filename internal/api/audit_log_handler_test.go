@@ -4,14 +4,20 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/kingrain94/audit-log-api/internal/api/dto"
 	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/service"
 	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
@@ -19,9 +25,10 @@ import (
 
 type AuditLogHandlerTestSuite struct {
 	suite.Suite
-	router      *gin.Engine
-	mockService *MockAuditLogService
-	handler     *AuditLogHandler
+	router            *gin.Engine
+	mockService       *MockAuditLogService
+	mockSavedSearches *MockSavedSearchService
+	handler           *AuditLogHandler
 }
 
 type MockAuditLogService struct {
@@ -38,6 +45,19 @@ func (m *MockAuditLogService) BulkCreate(ctx context.Context, reqs []dto.CreateA
 	return args.Error(0)
 }
 
+func (m *MockAuditLogService) CreateAsync(ctx context.Context, req dto.CreateAuditLogRequest) (string, error) {
+	args := m.Called(ctx, req)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAuditLogService) BulkCreateAsync(ctx context.Context, reqs []dto.CreateAuditLogRequest) ([]string, error) {
+	args := m.Called(ctx, reqs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
 func (m *MockAuditLogService) GetByID(ctx context.Context, id string) (*dto.AuditLogResponse, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -46,11 +66,35 @@ func (m *MockAuditLogService) GetByID(ctx context.Context, id string) (*dto.Audi
 	return args.Get(0).(*dto.AuditLogResponse), args.Error(1)
 }
 
-func (m *MockAuditLogService) List(ctx context.Context, filter *domain.AuditLogFilter, usePagination bool) ([]dto.AuditLogResponse, error) {
-	args := m.Called(ctx, filter, usePagination)
+func (m *MockAuditLogService) GetDiff(ctx context.Context, id, userID string, unified bool) (*dto.AuditLogDiffResponse, error) {
+	args := m.Called(ctx, id, userID, unified)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.AuditLogDiffResponse), args.Error(1)
+}
+
+func (m *MockAuditLogService) BatchGet(ctx context.Context, ids []string, userID string) (*dto.BatchGetLogsResponse, error) {
+	args := m.Called(ctx, ids, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.BatchGetLogsResponse), args.Error(1)
+}
+
+func (m *MockAuditLogService) GetByCorrelationID(ctx context.Context, tenantID, correlationID, userID string) ([]dto.AuditLogResponse, error) {
+	args := m.Called(ctx, tenantID, correlationID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
 	return args.Get(0).([]dto.AuditLogResponse), args.Error(1)
 }
 
+func (m *MockAuditLogService) List(ctx context.Context, filter *domain.AuditLogFilter, usePagination bool) ([]dto.AuditLogResponse, string, bool, error) {
+	args := m.Called(ctx, filter, usePagination)
+	return args.Get(0).([]dto.AuditLogResponse), args.String(1), args.Bool(2), args.Error(3)
+}
+
 func (m *MockAuditLogService) GetStats(ctx context.Context, filter *domain.AuditLogFilter) (*dto.GetAuditLogStatsResponse, error) {
 	args := m.Called(ctx, filter)
 	return args.Get(0).(*dto.GetAuditLogStatsResponse), args.Error(1)
@@ -61,16 +105,91 @@ func (m *MockAuditLogService) GetStatsV2(ctx context.Context, filter *domain.Aud
 	return args.Get(0).(*dto.GetAuditLogStatsResponse), args.Error(1)
 }
 
-func (m *MockAuditLogService) ScheduleArchive(ctx context.Context, tenantID string, beforeDate time.Time) error {
+func (m *MockAuditLogService) GetTopStats(ctx context.Context, filter *domain.AuditLogFilter, limit int) (*dto.TopStatsResponse, error) {
+	args := m.Called(ctx, filter, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.TopStatsResponse), args.Error(1)
+}
+
+func (m *MockAuditLogService) ETag(ctx context.Context, name string, filter *domain.AuditLogFilter) string {
+	args := m.Called(ctx, name, filter)
+	return args.String(0)
+}
+
+func (m *MockAuditLogService) Export(ctx context.Context, filter *domain.AuditLogFilter, format domain.ExportFormat, out io.Writer) (int64, error) {
+	args := m.Called(ctx, filter, format, out)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAuditLogService) Report(ctx context.Context, filter *domain.AuditLogFilter) ([]byte, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockAuditLogService) ScheduleArchive(ctx context.Context, tenantID string, beforeDate time.Time) (string, error) {
 	args := m.Called(ctx, tenantID, beforeDate)
-	return args.Error(0)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAuditLogService) CreateExportJob(ctx context.Context, filter *domain.AuditLogFilter, format domain.ExportFormat, signWith domain.ExportSigningKey) (*dto.ExportJobResponse, error) {
+	args := m.Called(ctx, filter, format, signWith)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ExportJobResponse), args.Error(1)
+}
+
+func (m *MockAuditLogService) GetExportSignature(ctx context.Context, tenantID, jobID string) (*dto.ExportSignatureResponse, error) {
+	args := m.Called(ctx, tenantID, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ExportSignatureResponse), args.Error(1)
+}
+
+func (m *MockAuditLogService) ExportSigningKeys(ctx context.Context, tenantID string) ([]dto.SigningKeyResponse, error) {
+	args := m.Called(ctx, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.SigningKeyResponse), args.Error(1)
+}
+
+func (m *MockAuditLogService) GetExportJob(ctx context.Context, tenantID, jobID string) (*dto.ExportJobResponse, error) {
+	args := m.Called(ctx, tenantID, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ExportJobResponse), args.Error(1)
+}
+
+func (m *MockAuditLogService) CreateRestoreJob(ctx context.Context, tenantID string, startTime, endTime time.Time) (*dto.RestoreJobResponse, error) {
+	args := m.Called(ctx, tenantID, startTime, endTime)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.RestoreJobResponse), args.Error(1)
+}
+
+func (m *MockAuditLogService) GetRestoreJob(ctx context.Context, tenantID, jobID string) (*dto.RestoreJobResponse, error) {
+	args := m.Called(ctx, tenantID, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.RestoreJobResponse), args.Error(1)
 }
 
 func (s *AuditLogHandlerTestSuite) SetupTest() {
 	gin.SetMode(gin.TestMode)
 	s.router = gin.New()
 	s.mockService = new(MockAuditLogService)
-	s.handler = NewAuditLogHandler(s.mockService)
+	s.mockSavedSearches = new(MockSavedSearchService)
+	s.handler = NewAuditLogHandler(s.mockService, s.mockSavedSearches)
 
 	// Setup routes
 	s.router.POST("/logs", s.handler.CreateLog)
@@ -213,44 +332,1313 @@ func (s *AuditLogHandlerTestSuite) TestGetLog_Success() {
 	s.mockService.AssertExpectations(s.T())
 }
 
-func (s *AuditLogHandlerTestSuite) TestListLogs_Success() {
+func (s *AuditLogHandlerTestSuite) TestGetLogDiff_Unified() {
 	// Arrange
-	expectedLogs := []dto.AuditLogResponse{
-		{
-			ID:        "log1",
-			TenantID:  "tenant1",
-			UserID:    "user1",
-			Action:    "create",
-			Message:   "Test message 1",
-			Timestamp: time.Now(),
-		},
-		{
-			ID:        "log2",
-			TenantID:  "tenant1",
-			UserID:    "user2",
-			Action:    "update",
-			Message:   "Test message 2",
-			Timestamp: time.Now(),
-		},
+	diff := &dto.AuditLogDiffResponse{
+		ID:      "log1",
+		Changes: []dto.StateChange{{Path: "name", Op: "changed", Before: "old", After: "new"}},
+		Unified: "--- before_state\n+++ after_state\n",
 	}
-
-	s.mockService.On("List", mock.Anything, mock.AnythingOfType("*domain.AuditLogFilter"), true).Return(expectedLogs, nil)
+	s.mockService.On("GetDiff", mock.Anything, "log1", "", true).Return(diff, nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
-	c.Request, _ = http.NewRequest(http.MethodGet, "/logs?page=1&page_size=10&start_time=2024-01-01T00:00:00Z&end_time=2024-12-31T23:59:59Z", nil)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs/log1/diff?unified=true", nil)
+	c.Params = []gin.Param{{Key: "id", Value: "log1"}}
 	c.Set(string(contextutils.TenantIDKey), "tenant1")
 
 	// Act
-	s.handler.ListLogs(c)
+	s.handler.GetLogDiff(c)
 
 	// Assert
 	s.Equal(http.StatusOK, w.Code)
-	var response []dto.AuditLogResponse
-	err := json.Unmarshal(w.Body.Bytes(), &response)
-	s.NoError(err)
-	s.Len(response, 2)
-	s.Equal(expectedLogs[0].ID, response[0].ID)
-	s.Equal(expectedLogs[1].ID, response[1].ID)
+	var response dto.AuditLogDiffResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal(diff.Unified, response.Unified)
+	s.Len(response.Changes, 1)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestGetLogDiff_NotFound() {
+	// Arrange
+	s.mockService.On("GetDiff", mock.Anything, "missing", "", false).Return(nil, service.ErrLogNotFound)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs/missing/diff", nil)
+	c.Params = []gin.Param{{Key: "id", Value: "missing"}}
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.GetLogDiff(c)
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestBulkCreateLogs_BodyTooLarge() {
+	// Arrange
+	body, _ := json.Marshal([]dto.CreateAuditLogRequest{{TenantID: "tenant1", Action: "create", Message: "Test message"}})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/logs/bulk", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	// As bounded by DecompressRequest once decompressed
+	c.Request.Body = http.MaxBytesReader(w, c.Request.Body, 16)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.BulkCreateLogs(c)
+
+	// Assert
+	s.Equal(http.StatusRequestEntityTooLarge, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "BulkCreate", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogHandlerTestSuite) TestCreateLog_InvalidFields() {
+	// Arrange
+	req := dto.CreateAuditLogRequest{
+		TenantID:     "tenant1",
+		UserID:       "user1",
+		Action:       "create",
+		ResourceType: "user",
+		ResourceID:   "resource1",
+		Message:      "Test message",
+		Severity:     "LOUD",
+		IPAddress:    "not-an-ip",
+		Timestamp:    time.Now(),
+	}
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/logs", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.CreateLog(c)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	var resp struct {
+		Details []dto.FieldError `json:"details"`
+	}
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	s.ElementsMatch([]dto.FieldError{
+		{Field: "ip_address", Rule: "ip"},
+		{Field: "severity", Rule: "severity"},
+	}, resp.Details)
+	s.mockService.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogHandlerTestSuite) TestBulkCreateLogs_InvalidLogIndexed() {
+	// Arrange
+	valid := dto.CreateAuditLogRequest{
+		TenantID:     "tenant1",
+		UserID:       "user1",
+		Action:       "create",
+		ResourceType: "user",
+		ResourceID:   "resource1",
+		Message:      "Test message",
+		Severity:     "info",
+		Timestamp:    time.Now(),
+	}
+	oversized := valid
+	oversized.Metadata = json.RawMessage(`{"blob":"` + strings.Repeat("x", 65536) + `"}`)
+	body, _ := json.Marshal([]dto.CreateAuditLogRequest{valid, oversized})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/logs/bulk", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.BulkCreateLogs(c)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	var resp struct {
+		Details []dto.FieldError `json:"details"`
+	}
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	s.Equal([]dto.FieldError{{Field: "logs[1].metadata", Rule: "max", Param: "65536"}}, resp.Details)
+	s.mockService.AssertNotCalled(s.T(), "BulkCreate", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogHandlerTestSuite) TestBulkCreateLogs_PathologicalPayloads_Rejected() {
+	// Arrange
+	valid := dto.CreateAuditLogRequest{
+		TenantID:     "tenant1",
+		UserID:       "user1",
+		Action:       "create",
+		ResourceType: "user",
+		ResourceID:   "resource1",
+		Message:      "Test message",
+		Severity:     "info",
+		Timestamp:    time.Now(),
+		AfterState:   json.RawMessage(`{"name":"new name","roles":[{"id":1,"scopes":["read"]}]}`),
+	}
+	deep := valid
+	deep.BeforeState = json.RawMessage(strings.Repeat(`{"a":`, 11) + `1` + strings.Repeat(`}`, 11))
+	wide := valid
+	fields := make([]string, 257)
+	for i := range fields {
+		fields[i] = fmt.Sprintf(`"k%d":%d`, i, i)
+	}
+	wide.Metadata = json.RawMessage(`{` + strings.Join(fields, ",") + `}`)
+	body, _ := json.Marshal([]dto.CreateAuditLogRequest{valid, deep, wide})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/logs/bulk", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.BulkCreateLogs(c)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	var resp struct {
+		Details []dto.FieldError `json:"details"`
+	}
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	s.Equal([]dto.FieldError{
+		{Field: "logs[1].before_state", Rule: "json_depth", Param: "10"},
+		{Field: "logs[2].metadata", Rule: "json_keys", Param: "256"},
+	}, resp.Details)
+	s.mockService.AssertNotCalled(s.T(), "BulkCreate", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogHandlerTestSuite) TestBulkCreateLogs_DailyQuotaExceeded() {
+	// Arrange
+	reqs := []dto.CreateAuditLogRequest{{
+		TenantID:     "tenant1",
+		Action:       "create",
+		ResourceType: "user",
+		ResourceID:   "resource1",
+		Message:      "Test message",
+		Severity:     "info",
+		Timestamp:    time.Now(),
+	}}
+	s.mockService.On("BulkCreate", mock.Anything, mock.Anything).Return(service.ErrDailyQuotaExceeded)
+
+	body, _ := json.Marshal(reqs)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/logs/bulk", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.BulkCreateLogs(c)
+
+	// Assert
+	s.Equal(http.StatusTooManyRequests, w.Code)
+	s.NotEmpty(w.Header().Get("Retry-After"))
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestCreateLog_Version1_UpconvertedWithCorrelationID() {
+	// Arrange
+	req := dto.CreateAuditLogRequest{
+		TenantID:     "tenant1",
+		UserID:       "user1",
+		Action:       "create",
+		ResourceType: "user",
+		ResourceID:   "resource1",
+		Message:      "Test message",
+		Severity:     "info",
+		Timestamp:    time.Now(),
+	}
+	s.mockService.On("Create", mock.Anything, mock.MatchedBy(func(r dto.CreateAuditLogRequest) bool {
+		return r.CorrelationID == "req-1" && r.SchemaVersion == domain.LogSchemaVersion
+	})).Return(nil)
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/logs", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+	c.Set(string(contextutils.CorrelationIDKey), "req-1")
+
+	// Act
+	s.handler.CreateLog(c)
+
+	// Assert
+	s.Equal(http.StatusCreated, w.Code)
 	s.mockService.AssertExpectations(s.T())
 }
+
+func (s *AuditLogHandlerTestSuite) TestCreateLog_Version2WithoutCorrelationID_Rejected() {
+	// Arrange
+	req := dto.CreateAuditLogRequest{
+		TenantID:      "tenant1",
+		SchemaVersion: 2,
+		Action:        "create",
+		ResourceType:  "user",
+		ResourceID:    "resource1",
+		Message:       "Test message",
+		Severity:      "info",
+		Timestamp:     time.Now(),
+	}
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/logs", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+	c.Set(string(contextutils.CorrelationIDKey), "req-1")
+
+	// Act
+	s.handler.CreateLog(c)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Contains(w.Body.String(), "correlation_id")
+	s.mockService.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogHandlerTestSuite) TestCreateLog_RecordsRequestIDInMetadata() {
+	// Arrange
+	req := dto.CreateAuditLogRequest{
+		TenantID:     "tenant1",
+		UserID:       "user1",
+		Action:       "create",
+		ResourceType: "user",
+		ResourceID:   "resource1",
+		Message:      "Test message",
+		Severity:     "info",
+		Metadata:     json.RawMessage(`{"team":"security"}`),
+		Timestamp:    time.Now(),
+	}
+	s.mockService.On("Create", mock.Anything, mock.MatchedBy(func(r dto.CreateAuditLogRequest) bool {
+		var metadata map[string]string
+		return json.Unmarshal(r.Metadata, &metadata) == nil &&
+			metadata["request_id"] == "call-1" && metadata["team"] == "security"
+	})).Return(nil)
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/logs", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+	c.Set(string(contextutils.RequestIDKey), "call-1")
+
+	// Act
+	s.handler.CreateLog(c)
+
+	// Assert
+	s.Equal(http.StatusCreated, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestBulkCreateLogs_KeepsClientRequestID() {
+	// Arrange
+	req := []dto.CreateAuditLogRequest{
+		{
+			TenantID:     "tenant1",
+			UserID:       "user1",
+			Action:       "create",
+			ResourceType: "user",
+			ResourceID:   "resource1",
+			Message:      "Test message",
+			Severity:     "info",
+			Metadata:     json.RawMessage(`{"request_id":"upstream-7"}`),
+			Timestamp:    time.Now(),
+		},
+		{
+			TenantID:     "tenant1",
+			UserID:       "user1",
+			Action:       "update",
+			ResourceType: "user",
+			ResourceID:   "resource1",
+			Message:      "Test message",
+			Severity:     "info",
+			Timestamp:    time.Now(),
+		},
+	}
+	s.mockService.On("BulkCreate", mock.Anything, mock.MatchedBy(func(logs []dto.CreateAuditLogRequest) bool {
+		return len(logs) == 2 &&
+			string(logs[0].Metadata) == `{"request_id":"upstream-7"}` &&
+			string(logs[1].Metadata) == `{"request_id":"call-1"}`
+	})).Return(nil)
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/logs/bulk", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+	c.Set(string(contextutils.RequestIDKey), "call-1")
+
+	// Act
+	s.handler.BulkCreateLogs(c)
+
+	// Assert
+	s.Equal(http.StatusCreated, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestCreateLog_Async_Accepted() {
+	// Arrange
+	req := dto.CreateAuditLogRequest{
+		TenantID:     "tenant1",
+		UserID:       "user1",
+		Action:       "create",
+		ResourceType: "user",
+		ResourceID:   "resource1",
+		Message:      "Test message",
+		Severity:     "info",
+		Timestamp:    time.Now(),
+	}
+	s.mockService.On("CreateAsync", mock.Anything, mock.AnythingOfType("dto.CreateAuditLogRequest")).Return("log1", nil)
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/logs?async=true", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.CreateLog(c)
+
+	// Assert
+	s.Equal(http.StatusAccepted, w.Code)
+	var response dto.AcceptedLogsResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal([]string{"log1"}, response.IDs)
+	s.mockService.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogHandlerTestSuite) TestBulkCreateLogs_Async_Accepted() {
+	// Arrange
+	req := []dto.CreateAuditLogRequest{
+		{TenantID: "tenant1", UserID: "user1", Action: "create", ResourceType: "user", ResourceID: "resource1", Message: "first", Severity: "info", Timestamp: time.Now()},
+		{TenantID: "tenant1", UserID: "user1", Action: "update", ResourceType: "user", ResourceID: "resource1", Message: "second", Severity: "info", Timestamp: time.Now()},
+	}
+	s.mockService.On("BulkCreateAsync", mock.Anything, mock.MatchedBy(func(logs []dto.CreateAuditLogRequest) bool {
+		return len(logs) == 2
+	})).Return([]string{"log1", "log2"}, nil)
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/logs/bulk?async=1", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.BulkCreateLogs(c)
+
+	// Assert
+	s.Equal(http.StatusAccepted, w.Code)
+	var response dto.AcceptedLogsResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal([]string{"log1", "log2"}, response.IDs)
+	s.mockService.AssertNotCalled(s.T(), "BulkCreate", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogHandlerTestSuite) TestCreateLog_InvalidAsync() {
+	// Arrange
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/logs?async=maybe", bytes.NewBufferString(`{}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.CreateLog(c)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "CreateAsync", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogHandlerTestSuite) TestGetCorrelatedLogs_Success() {
+	// Arrange
+	expectedLogs := []dto.AuditLogResponse{
+		{ID: "log1", TenantID: "tenant1", CorrelationID: "req-1"},
+		{ID: "log2", TenantID: "tenant1", CorrelationID: "req-1"},
+	}
+	s.mockService.On("GetByCorrelationID", mock.Anything, "tenant1", "req-1", "").Return(expectedLogs, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs/correlation/req-1", nil)
+	c.Params = []gin.Param{{Key: "correlation_id", Value: "req-1"}}
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.GetCorrelatedLogs(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response []dto.AuditLogResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Len(response, 2)
+	s.Equal("log1", response[0].ID)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestListLogs_Success() {
+	// Arrange
+	expectedLogs := []dto.AuditLogResponse{
+		{
+			ID:        "log1",
+			TenantID:  "tenant1",
+			UserID:    "user1",
+			Action:    "create",
+			Message:   "Test message 1",
+			Timestamp: time.Now(),
+		},
+		{
+			ID:        "log2",
+			TenantID:  "tenant1",
+			UserID:    "user2",
+			Action:    "update",
+			Message:   "Test message 2",
+			Timestamp: time.Now(),
+		},
+	}
+
+	s.mockService.On("ETag", mock.Anything, "logs", mock.Anything).Return("")
+	s.mockService.On("List", mock.Anything, mock.AnythingOfType("*domain.AuditLogFilter"), true).Return(expectedLogs, "", false, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs?page=1&page_size=10&start_time=2024-01-01T00:00:00Z&end_time=2024-12-31T23:59:59Z", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ListLogs(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response []dto.AuditLogResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	s.NoError(err)
+	s.Len(response, 2)
+	s.Equal(expectedLogs[0].ID, response[0].ID)
+	s.Equal(expectedLogs[1].ID, response[1].ID)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestListLogs_WithCursor_ReturnsNextCursor() {
+	// Arrange
+	cursor := domain.EncodeSearchCursor(json.RawMessage(`[1704103100000,"log2"]`))
+	s.mockService.On("ETag", mock.Anything, "logs", mock.Anything).Return("")
+	s.mockService.On("List", mock.Anything, mock.MatchedBy(func(f *domain.AuditLogFilter) bool {
+		return string(f.SearchAfter) == `[1704103100000,"log2"]`
+	}), true).Return([]dto.AuditLogResponse{{ID: "log3"}}, "next", false, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs?q=login&cursor="+cursor+"&start_time=2024-01-01&end_time=2024-12-31", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ListLogs(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("next", w.Header().Get("X-Next-Cursor"))
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestListLogs_Degraded_SetsHeader() {
+	// Arrange
+	s.mockService.On("ETag", mock.Anything, "logs", mock.Anything).Return("")
+	s.mockService.On("List", mock.Anything, mock.AnythingOfType("*domain.AuditLogFilter"), true).Return([]dto.AuditLogResponse{{ID: "log1"}}, "", true, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs?q=login&start_time=2024-01-01&end_time=2024-12-31", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ListLogs(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("true", w.Header().Get("X-Degraded-Mode"))
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestListLogs_InvalidCursor() {
+	// Arrange
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs?cursor=not-a-cursor&start_time=2024-01-01&end_time=2024-12-31", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ListLogs(c)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "List", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogHandlerTestSuite) TestListLogsV2_FullPage_ReturnsPageCursor() {
+	// Arrange
+	s.mockService.On("ETag", mock.Anything, "logs", mock.Anything).Return("")
+	s.mockService.On("List", mock.Anything, mock.AnythingOfType("*domain.AuditLogFilter"), true).
+		Return([]dto.AuditLogResponse{{ID: "log1"}, {ID: "log2"}}, "", false, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs?page_size=2&fields=id&start_time=2024-01-01&end_time=2024-12-31", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ListLogsV2(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.Empty(w.Header().Get("X-Next-Cursor"))
+	var response struct {
+		Data       []map[string]any       `json:"data"`
+		Pagination dto.PaginationResponse `json:"pagination"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal([]map[string]any{{"id": "log1"}, {"id": "log2"}}, response.Data)
+	s.Equal(2, response.Pagination.PageSize)
+	s.Equal(domain.EncodePageCursor(2), response.Pagination.NextCursor)
+	s.True(response.Pagination.HasMore)
+}
+
+func (s *AuditLogHandlerTestSuite) TestListLogsV2_PageCursor_ListsThatPage() {
+	// Arrange
+	s.mockService.On("ETag", mock.Anything, "logs", mock.Anything).Return("")
+	s.mockService.On("List", mock.Anything, mock.MatchedBy(func(f *domain.AuditLogFilter) bool {
+		return f.Page == 3 && len(f.SearchAfter) == 0
+	}), true).Return([]dto.AuditLogResponse{{ID: "log1"}}, "", false, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs?page_size=2&cursor="+domain.EncodePageCursor(3)+"&start_time=2024-01-01&end_time=2024-12-31", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ListLogsV2(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response dto.LogPageResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Empty(response.Pagination.NextCursor)
+	s.False(response.Pagination.HasMore)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestListLogs_SetsETag() {
+	// Arrange
+	s.mockService.On("ETag", mock.Anything, "logs", mock.AnythingOfType("*domain.AuditLogFilter")).Return(`W/"abc"`)
+	s.mockService.On("List", mock.Anything, mock.AnythingOfType("*domain.AuditLogFilter"), true).Return([]dto.AuditLogResponse{}, "", false, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs?start_time=2024-01-01&end_time=2024-12-31", nil)
+	c.Request.Header.Set("If-None-Match", `W/"old"`)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ListLogs(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal(`W/"abc"`, w.Header().Get("ETag"))
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestListLogs_NotModified() {
+	// Arrange
+	s.mockService.On("ETag", mock.Anything, "logs", mock.AnythingOfType("*domain.AuditLogFilter")).Return(`W/"abc"`)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs?start_time=2024-01-01&end_time=2024-12-31", nil)
+	c.Request.Header.Set("If-None-Match", `W/"old", "abc"`)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ListLogs(c)
+	c.Writer.WriteHeaderNow()
+
+	// Assert
+	s.Equal(http.StatusNotModified, w.Code)
+	s.Equal(`W/"abc"`, w.Header().Get("ETag"))
+	s.Empty(w.Body.Bytes())
+	s.mockService.AssertNotCalled(s.T(), "List", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogHandlerTestSuite) TestGetStats_NotModified() {
+	// Arrange
+	s.mockService.On("ETag", mock.Anything, "stats", mock.AnythingOfType("*domain.AuditLogFilter")).Return(`W/"abc"`)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs/stats?start_time=2024-01-01&end_time=2024-12-31", nil)
+	c.Request.Header.Set("If-None-Match", `W/"abc"`)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.GetStats(c)
+	c.Writer.WriteHeaderNow()
+
+	// Assert
+	s.Equal(http.StatusNotModified, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "GetStatsV2", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogHandlerTestSuite) TestGetStatsV2_WrapsStatsInEnvelope() {
+	// Arrange
+	s.mockService.On("ETag", mock.Anything, "stats", mock.Anything).Return("")
+	s.mockService.On("GetStatsV2", mock.Anything, mock.AnythingOfType("*domain.AuditLogFilter")).
+		Return(&dto.GetAuditLogStatsResponse{TotalLogs: 7}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs/stats?start_time=2024-01-01&end_time=2024-12-31", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.GetStatsV2(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response dto.StatsEnvelopeResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Require().NotNil(response.Data)
+	s.Equal(int64(7), response.Data.TotalLogs)
+}
+
+func (s *AuditLogHandlerTestSuite) TestGetTopStats_Success() {
+	// Arrange
+	s.mockService.On("GetTopStats", mock.Anything, mock.AnythingOfType("*domain.AuditLogFilter"), 5).
+		Return(&dto.TopStatsResponse{
+			TotalLogs: 4,
+			Users:     []dto.TopValueResponse{{Value: "user1", Count: 3, Percentage: 75}},
+		}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs/stats/top?start_time=2024-01-01&end_time=2024-12-31&limit=5", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.GetTopStats(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response dto.TopStatsResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal(int64(4), response.TotalLogs)
+	s.Equal(75.0, response.Users[0].Percentage)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestGetTopStats_DefaultLimit() {
+	// Arrange
+	s.mockService.On("GetTopStats", mock.Anything, mock.AnythingOfType("*domain.AuditLogFilter"), topStatsDefaultLimit).
+		Return(&dto.TopStatsResponse{}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs/stats/top?start_time=2024-01-01&end_time=2024-12-31", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.GetTopStats(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestGetTopStats_InvalidLimit() {
+	// Arrange
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs/stats/top?start_time=2024-01-01&end_time=2024-12-31&limit=500", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.GetTopStats(c)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "GetTopStats", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogHandlerTestSuite) TestGetTopStats_MissingTimeRange() {
+	// Arrange
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs/stats/top?start_time=2024-01-01", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.GetTopStats(c)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "GetTopStats", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogHandlerTestSuite) TestListLogs_SelectsFields() {
+	// Arrange
+	logs := []dto.AuditLogResponse{{
+		ID:          "log1",
+		Action:      "create",
+		Message:     "Test message",
+		BeforeState: json.RawMessage(`{"name":"old"}`),
+		AfterState:  json.RawMessage(`{"name":"new"}`),
+		Timestamp:   time.Now(),
+	}}
+	s.mockService.On("ETag", mock.Anything, "logs", mock.Anything).Return("")
+	s.mockService.On("List", mock.Anything, mock.MatchedBy(func(f *domain.AuditLogFilter) bool {
+		return slices.Equal(f.Fields, []string{"id", "action"})
+	}), true).Return(logs, "", false, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs?fields=id,action,id&start_time=2024-01-01&end_time=2024-12-31", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ListLogs(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response []map[string]any
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal([]map[string]any{{"id": "log1", "action": "create"}}, response)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestListLogs_UnknownField() {
+	// Arrange
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs?fields=id,password&start_time=2024-01-01&end_time=2024-12-31", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ListLogs(c)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Contains(w.Body.String(), "password")
+	s.mockService.AssertNotCalled(s.T(), "List", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogHandlerTestSuite) TestListLogs_Sort() {
+	// Arrange
+	s.mockService.On("ETag", mock.Anything, "logs", mock.Anything).Return("")
+	s.mockService.On("List", mock.Anything, mock.MatchedBy(func(f *domain.AuditLogFilter) bool {
+		return slices.Equal(f.Sort, []domain.SortField{{Field: "severity", Desc: true}, {Field: "action"}})
+	}), true).Return([]dto.AuditLogResponse{}, "", false, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs?sort=severity:desc,action&start_time=2024-01-01&end_time=2024-12-31", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ListLogs(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestListLogs_InvalidSort() {
+	// Arrange
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs?sort=message&start_time=2024-01-01&end_time=2024-12-31", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ListLogs(c)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Contains(w.Body.String(), "message")
+	s.mockService.AssertNotCalled(s.T(), "List", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogHandlerTestSuite) TestListLogs_OwnScopeForcesUserID() {
+	// Arrange
+	s.mockService.On("ETag", mock.Anything, "logs", mock.Anything).Return("")
+	s.mockService.On("List", mock.Anything, mock.MatchedBy(func(f *domain.AuditLogFilter) bool {
+		return f.UserID == "user1" && f.VisibleUserID == "user1"
+	}), true).Return([]dto.AuditLogResponse{}, "", false, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs?user_id=user2&start_time=2024-01-01T00:00:00Z&end_time=2024-12-31T23:59:59Z", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+	c.Set(string(contextutils.UserIDKey), "user1")
+	c.Set(string(contextutils.PolicyScopeKey), string(domain.PolicyScopeOwn))
+
+	// Act
+	s.handler.ListLogs(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestListLogs_MultiValueFilters() {
+	// Arrange
+	s.mockService.On("ETag", mock.Anything, "logs", mock.Anything).Return("")
+	s.mockService.On("List", mock.Anything, mock.MatchedBy(func(f *domain.AuditLogFilter) bool {
+		return slices.Equal(f.Severity.In, []string{"ERROR", "CRITICAL"}) &&
+			slices.Equal(f.Action.NotIn, []string{"LOGIN", "VIEW"}) && len(f.Action.In) == 0 &&
+			slices.Equal(f.ResourceType.In, []string{"user", "order"})
+	}), true).Return([]dto.AuditLogResponse{}, "", false, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet,
+		"/logs?severity=ERROR,CRITICAL&action!=VIEW&action=!LOGIN&resource_type=user&resource_type=order"+
+			"&start_time=2024-03-20&end_time=2024-03-21", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ListLogs(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestListLogs_TagFilter() {
+	// Arrange
+	s.mockService.On("ETag", mock.Anything, "logs", mock.Anything).Return("")
+	s.mockService.On("List", mock.Anything, mock.MatchedBy(func(f *domain.AuditLogFilter) bool {
+		return slices.Equal(f.Tags.In, []string{"authentication", "privilege-change"}) &&
+			slices.Equal(f.Tags.NotIn, []string{"service-account"})
+	}), true).Return([]dto.AuditLogResponse{{ID: "log1", Tags: []string{"authentication"}}}, "", false, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet,
+		"/logs?tags=authentication,privilege-change&tags!=service-account&start_time=2024-03-20&end_time=2024-03-21", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ListLogs(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.Contains(w.Body.String(), `"tags":["authentication"]`)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestListLogs_MinThreatScoreFilter() {
+	// Arrange
+	s.mockService.On("ETag", mock.Anything, "logs", mock.Anything).Return("")
+	s.mockService.On("List", mock.Anything, mock.MatchedBy(func(f *domain.AuditLogFilter) bool {
+		return f.MinThreatScore == 75
+	}), true).Return([]dto.AuditLogResponse{{ID: "log1"}}, "", false, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs?min_threat_score=75&start_time=2024-03-20&end_time=2024-03-21", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ListLogs(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestListLogs_InvalidMinThreatScore() {
+	// Arrange
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs?min_threat_score=101&start_time=2024-03-20&end_time=2024-03-21", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ListLogs(c)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Contains(w.Body.String(), "min_threat_score")
+	s.mockService.AssertNotCalled(s.T(), "List", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogHandlerTestSuite) TestListLogs_SavedSearch() {
+	// Arrange
+	s.mockSavedSearches.On("GetFilter", mock.Anything, "tenant1", "user1", "search1").
+		Return(&domain.SavedSearchFilter{Action: "login", Severity: "ERROR", Lookback: "24h"}, nil)
+	s.mockService.On("ETag", mock.Anything, "logs", mock.Anything).Return("")
+	s.mockService.On("List", mock.Anything, mock.MatchedBy(func(f *domain.AuditLogFilter) bool {
+		return slices.Equal(f.Action.In, []string{"login"}) && slices.Equal(f.Severity.In, []string{"WARNING"}) &&
+			f.EndTime.Sub(f.StartTime) == 24*time.Hour
+	}), true).Return([]dto.AuditLogResponse{}, "", false, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs?saved_search_id=search1&severity=WARNING", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+	c.Set(string(contextutils.UserIDKey), "user1")
+
+	// Act
+	s.handler.ListLogs(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.mockService.AssertExpectations(s.T())
+	s.mockSavedSearches.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestListLogs_SavedSearchNotFound() {
+	// Arrange
+	s.mockSavedSearches.On("GetFilter", mock.Anything, "tenant1", "user1", "missing").
+		Return(nil, service.ErrSavedSearchNotFound)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs?saved_search_id=missing", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+	c.Set(string(contextutils.UserIDKey), "user1")
+
+	// Act
+	s.handler.ListLogs(c)
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "List", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogHandlerTestSuite) TestCreateExportJob_Accepted() {
+	// Arrange
+	expectedJob := &dto.ExportJobResponse{
+		ID:     "job1",
+		Status: string(domain.JobPending),
+		Format: string(domain.ExportFormatCSV),
+	}
+
+	s.mockService.On("CreateExportJob", mock.Anything, mock.AnythingOfType("*domain.AuditLogFilter"), domain.ExportFormatCSV, domain.ExportSigningKey("")).Return(expectedJob, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/logs/export?format=csv&start_time=2024-01-01&end_time=2024-12-31", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.CreateExportJob(c)
+
+	// Assert
+	s.Equal(http.StatusAccepted, w.Code)
+	var response dto.ExportJobResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	s.NoError(err)
+	s.Equal("job1", response.ID)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestExportLogs_StreamsCSV() {
+	// Arrange
+	s.mockService.On("Export", mock.Anything, mock.AnythingOfType("*domain.AuditLogFilter"), domain.ExportFormatCSV, mock.Anything).
+		Run(func(args mock.Arguments) {
+			_, _ = io.WriteString(args.Get(3).(io.Writer), "ID,Action\nlog1,create\n")
+		}).
+		Return(int64(1), nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs/export?format=csv&fields=id,action&start_time=2024-01-01&end_time=2024-12-31", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ExportLogs(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("text/csv", w.Header().Get("Content-Type"))
+	s.Equal("attachment; filename=audit_logs.csv", w.Header().Get("Content-Disposition"))
+	s.Equal("ID,Action\nlog1,create\n", w.Body.String())
+	s.mockService.AssertNotCalled(s.T(), "List", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogHandlerTestSuite) TestExportLogs_XLSX() {
+	// Arrange
+	s.mockService.On("Export", mock.Anything, mock.AnythingOfType("*domain.AuditLogFilter"), domain.ExportFormatXLSX, mock.Anything).
+		Return(int64(0), nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs/export?format=xlsx&start_time=2024-01-01&end_time=2024-12-31", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ExportLogs(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", w.Header().Get("Content-Type"))
+	s.Equal("attachment; filename=audit_logs.xlsx", w.Header().Get("Content-Disposition"))
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestExportLogs_InvalidFormat() {
+	// Arrange
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs/export?format=pdf&start_time=2024-01-01&end_time=2024-12-31", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ExportLogs(c)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Contains(w.Body.String(), "Must be 'json', 'csv' or 'xlsx'")
+	s.mockService.AssertNotCalled(s.T(), "Export", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogHandlerTestSuite) TestExportLogs_ErrorBeforeRows_RespondsWithError() {
+	// Arrange
+	s.mockService.On("Export", mock.Anything, mock.AnythingOfType("*domain.AuditLogFilter"), domain.ExportFormatJSON, mock.Anything).
+		Return(int64(0), errors.New("database unavailable"))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs/export?start_time=2024-01-01&end_time=2024-12-31", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ExportLogs(c)
+
+	// Assert
+	s.Equal(http.StatusInternalServerError, w.Code)
+	s.Empty(w.Header().Get("Content-Disposition"))
+	s.Contains(w.Header().Get("Content-Type"), "application/json")
+}
+
+func (s *AuditLogHandlerTestSuite) TestGetReport_ReturnsPDF() {
+	// Arrange
+	s.mockService.On("Report", mock.Anything, mock.MatchedBy(func(f *domain.AuditLogFilter) bool {
+		return f.TenantID == "tenant1" && f.Severity.In[0] == "ERROR"
+	})).Return([]byte("%PDF-1.4\n"), nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs/report?format=pdf&severity=ERROR&start_time=2024-01-01&end_time=2024-03-31", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.GetReport(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("application/pdf", w.Header().Get("Content-Type"))
+	s.Equal("attachment; filename=audit_report.pdf", w.Header().Get("Content-Disposition"))
+	s.Equal("%PDF-1.4\n", w.Body.String())
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestGetReport_InvalidFormat() {
+	// Arrange
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs/report?format=docx&start_time=2024-01-01&end_time=2024-03-31", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.GetReport(c)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "Report", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogHandlerTestSuite) TestGetReport_MissingTimeRange() {
+	// Arrange
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs/report", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.GetReport(c)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Contains(w.Body.String(), "start_time is required")
+	s.mockService.AssertNotCalled(s.T(), "Report", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogHandlerTestSuite) TestCreateExportJob_InvalidFormat() {
+	// Arrange
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/logs/export?format=xml&start_time=2024-01-01&end_time=2024-12-31", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.CreateExportJob(c)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "CreateExportJob", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogHandlerTestSuite) TestCreateExportJob_InvalidSign() {
+	// Arrange
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/logs/export?format=csv&sign=customer&start_time=2024-01-01&end_time=2024-12-31", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.CreateExportJob(c)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "CreateExportJob", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogHandlerTestSuite) TestGetExportSignature_Success() {
+	// Arrange
+	expected := &dto.ExportSignatureResponse{
+		JobID:              "job1",
+		SigningKeyResponse: dto.SigningKeyResponse{KeyID: "3f9a1c7e2b8d4f60", Key: "tenant", Algorithm: "Ed25519"},
+		SHA256:             "ab12",
+		Signature:          "c2ln",
+	}
+	s.mockService.On("GetExportSignature", mock.Anything, "tenant1", "job1").Return(expected, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs/export/job1/signature", nil)
+	c.Params = []gin.Param{{Key: "job_id", Value: "job1"}}
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.GetExportSignature(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response dto.ExportSignatureResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal("3f9a1c7e2b8d4f60", response.KeyID)
+	s.Equal("c2ln", response.Signature)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestGetExportJob_NotFound() {
+	// Arrange
+	s.mockService.On("GetExportJob", mock.Anything, "tenant1", "missing").Return(nil, service.ErrExportJobNotFound)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs/export/missing", nil)
+	c.Params = []gin.Param{{Key: "job_id", Value: "missing"}}
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.GetExportJob(c)
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestRestoreLogs_Accepted() {
+	// Arrange
+	expectedJob := &dto.RestoreJobResponse{ID: "job1", Status: string(domain.JobPending)}
+
+	s.mockService.On("CreateRestoreJob", mock.Anything, "tenant1", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(expectedJob, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/logs/restore?start_time=2024-01-01&end_time=2024-03-31", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.RestoreLogs(c)
+
+	// Assert
+	s.Equal(http.StatusAccepted, w.Code)
+	var response dto.RestoreJobResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	s.NoError(err)
+	s.Equal("job1", response.ID)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestRestoreLogs_InvalidRange() {
+	// Arrange
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/logs/restore?start_time=2024-03-31&end_time=2024-01-01", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.RestoreLogs(c)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "CreateRestoreJob", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogHandlerTestSuite) TestBatchGetLogs_ExistsOnly() {
+	// Arrange
+	ids := []string{"6f1c2f4e-8d0a-4b5e-9a57-1f1d2e3c4b5a", "0b9e7c1d-2a3f-4e5d-8c6b-7a8f9e0d1c2b"}
+	resp := &dto.BatchGetLogsResponse{
+		Logs:    []dto.AuditLogResponse{{ID: ids[0]}},
+		Found:   []string{ids[0]},
+		Missing: []string{ids[1]},
+	}
+	s.mockService.On("BatchGet", mock.Anything, ids, "").Return(resp, nil)
+
+	body, _ := json.Marshal(dto.BatchGetLogsRequest{IDs: ids, ExistsOnly: true})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/logs/batch-get", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.BatchGetLogs(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response dto.BatchGetLogsResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Empty(response.Logs)
+	s.Equal([]string{ids[0]}, response.Found)
+	s.Equal([]string{ids[1]}, response.Missing)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestBatchGetLogs_InvalidIDs() {
+	// Arrange
+	body, _ := json.Marshal(dto.BatchGetLogsRequest{IDs: []string{"not-a-uuid"}})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/logs/batch-get", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.BatchGetLogs(c)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "BatchGet", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
+)
+
+//go:generate mockery --name AuthService --output ../mocks
+type AuthService interface {
+	IssueToken(ctx context.Context, req dto.TokenRequest) (*dto.TokenResponse, error)
+	Refresh(ctx context.Context, refreshToken string) (*dto.TokenResponse, error)
+	Revoke(ctx context.Context, userID, tokenID string, expiresAt time.Time, refreshToken string) error
+	IssueStreamTicket(ctx context.Context, claims jwt.MapClaims) (*dto.StreamTicketResponse, error)
+}
+
+type AuthHandler struct {
+	*BaseHandler
+	service AuthService
+}
+
+func NewAuthHandler(service AuthService) *AuthHandler {
+	return &AuthHandler{service: service}
+}
+
+// IssueToken godoc
+// @Summary Issue an access token
+// @Description Exchange user credentials for a short-lived access token and a refresh token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body dto.TokenRequest true "Credentials"
+// @Success 200 {object} dto.TokenResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /auth/token [post]
+func (h *AuthHandler) IssueToken(c *gin.Context) {
+	var req dto.TokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	token, err := h.service.IssueToken(h.RequestCtx(c), req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+// RefreshToken godoc
+// @Summary Refresh an access token
+// @Description Exchange a refresh token for a new token pair. The refresh token is rotated and must not be reused.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body dto.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} dto.TokenResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /auth/refresh [post]
+func (h *AuthHandler) RefreshToken(c *gin.Context) {
+	var req dto.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	token, err := h.service.Refresh(h.RequestCtx(c), req.RefreshToken)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+// RevokeToken godoc
+// @Summary Revoke tokens
+// @Description Revoke the calling access token and, if given, every refresh token issued from the same login
+// @Tags auth
+// @Accept json
+// @Param body body dto.RevokeTokenRequest false "Refresh token to revoke"
+// @Success 204
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /auth/revoke [post]
+func (h *AuthHandler) RevokeToken(c *gin.Context) {
+	// The body is optional; an empty one only revokes the access token
+	var req dto.RevokeTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		bindError(c, err)
+		return
+	}
+
+	value, _ := c.Get(string(contextutils.ClaimsKey))
+	claims, ok := value.(jwt.MapClaims)
+	if !ok {
+		respondError(c, errUnauthorized("No authentication found"))
+		return
+	}
+
+	userID, _ := claims["user_id"].(string)
+	tokenID, _ := claims["jti"].(string)
+	var expiresAt time.Time
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		expiresAt = exp.Time
+	}
+
+	err := h.service.Revoke(h.RequestCtx(c), userID, tokenID, expiresAt, req.RefreshToken)
+	if errors.Is(err, service.ErrInvalidRefreshToken) {
+		// The caller is authenticated, only the refresh token in the body is invalid
+		respondError(c, errValidation(err.Error()))
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// IssueStreamTicket godoc
+// @Summary Issue a log stream ticket
+// @Description Issue a short-lived one-time ticket for opening /logs/stream or /logs/sse with ?ticket=, for browsers that can't send an Authorization header with a WebSocket upgrade or an EventSource. The ticket acts for the caller's tenant, user and roles.
+// @Tags audit_logs
+// @Produce json
+// @Success 201 {object} dto.StreamTicketResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /logs/stream/ticket [post]
+func (h *AuthHandler) IssueStreamTicket(c *gin.Context) {
+	value, _ := c.Get(string(contextutils.ClaimsKey))
+	claims, ok := value.(jwt.MapClaims)
+	if !ok {
+		respondError(c, errUnauthorized("No authentication found"))
+		return
+	}
+
+	ticket, err := h.service.IssueStreamTicket(h.RequestCtx(c), claims)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, ticket)
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuthHandlerTestSuite struct {
+	suite.Suite
+	router      *gin.Engine
+	mockService *MockAuthService
+	handler     *AuthHandler
+}
+
+type MockAuthService struct {
+	mock.Mock
+}
+
+func (m *MockAuthService) IssueToken(ctx context.Context, req dto.TokenRequest) (*dto.TokenResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.TokenResponse), args.Error(1)
+}
+
+func (m *MockAuthService) Refresh(ctx context.Context, refreshToken string) (*dto.TokenResponse, error) {
+	args := m.Called(ctx, refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.TokenResponse), args.Error(1)
+}
+
+func (m *MockAuthService) Revoke(ctx context.Context, userID, tokenID string, expiresAt time.Time, refreshToken string) error {
+	args := m.Called(ctx, userID, tokenID, expiresAt, refreshToken)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) IssueStreamTicket(ctx context.Context, claims jwt.MapClaims) (*dto.StreamTicketResponse, error) {
+	args := m.Called(ctx, claims)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.StreamTicketResponse), args.Error(1)
+}
+
+func (s *AuthHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.mockService = new(MockAuthService)
+	s.handler = NewAuthHandler(s.mockService)
+
+	// Setup routes
+	s.router.POST("/auth/token", s.handler.IssueToken)
+	s.router.POST("/auth/refresh", s.handler.RefreshToken)
+}
+
+func TestAuthHandler(t *testing.T) {
+	suite.Run(t, new(AuthHandlerTestSuite))
+}
+
+func (s *AuthHandlerTestSuite) TestIssueToken_Success() {
+	// Arrange
+	req := dto.TokenRequest{Email: "jane@example.com", Password: "correct-horse"}
+	s.mockService.On("IssueToken", mock.Anything, req).
+		Return(&dto.TokenResponse{AccessToken: "access", RefreshToken: "refresh", TokenType: "Bearer", ExpiresIn: 900}, nil)
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/auth/token", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response dto.TokenResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal("access", response.AccessToken)
+	s.Equal("refresh", response.RefreshToken)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuthHandlerTestSuite) TestIssueToken_InvalidCredentials() {
+	// Arrange
+	req := dto.TokenRequest{Email: "jane@example.com", Password: "wrong"}
+	s.mockService.On("IssueToken", mock.Anything, req).Return(nil, service.ErrInvalidCredentials)
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/auth/token", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusUnauthorized, w.Code)
+}
+
+func (s *AuthHandlerTestSuite) TestRefreshToken_Invalid() {
+	// Arrange
+	s.mockService.On("Refresh", mock.Anything, "reused").Return(nil, service.ErrInvalidRefreshToken)
+
+	body, _ := json.Marshal(dto.RefreshTokenRequest{RefreshToken: "reused"})
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusUnauthorized, w.Code)
+}
+
+func (s *AuthHandlerTestSuite) TestRevokeToken_Success() {
+	// Arrange
+	expiresAt := time.Now().Add(10 * time.Minute).Truncate(time.Second)
+	s.mockService.On("Revoke", mock.Anything, "user1", "jti1", expiresAt, "refresh").Return(nil)
+
+	body, _ := json.Marshal(dto.RevokeTokenRequest{RefreshToken: "refresh"})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/auth/revoke", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(string(contextutils.ClaimsKey), jwt.MapClaims{
+		"user_id": "user1",
+		"jti":     "jti1",
+		"exp":     float64(expiresAt.Unix()),
+	})
+
+	// Act
+	s.handler.RevokeToken(c)
+
+	// Assert
+	s.Equal(http.StatusNoContent, c.Writer.Status())
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuthHandlerTestSuite) TestIssueStreamTicket_Success() {
+	// Arrange
+	claims := jwt.MapClaims{"user_id": "user1", "tenant_id": "tenant1", "roles": []any{"auditor"}}
+	s.mockService.On("IssueStreamTicket", mock.Anything, claims).
+		Return(&dto.StreamTicketResponse{Ticket: "ticket", ExpiresIn: 30}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/logs/stream/ticket", nil)
+	c.Set(string(contextutils.ClaimsKey), claims)
+
+	// Act
+	s.handler.IssueStreamTicket(c)
+
+	// Assert
+	s.Equal(http.StatusCreated, w.Code)
+	var resp dto.StreamTicketResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	s.Equal("ticket", resp.Ticket)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuthHandlerTestSuite) TestIssueStreamTicket_NoTenant() {
+	// Arrange
+	claims := jwt.MapClaims{"user_id": "user1"}
+	s.mockService.On("IssueStreamTicket", mock.Anything, claims).Return(nil, service.ErrNoTenantInToken)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/logs/stream/ticket", nil)
+	c.Set(string(contextutils.ClaimsKey), claims)
+
+	// Act
+	s.handler.IssueStreamTicket(c)
+
+	// Assert
+	s.Equal(http.StatusUnauthorized, w.Code)
+}
@@ -2,8 +2,11 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
 	"github.com/kingrain94/audit-log-api/internal/utils"
 )
 
@@ -18,3 +21,40 @@ func (h *BaseHandler) RequestCtx(ginCtx *gin.Context) context.Context {
 	}
 	return ctx
 }
+
+// ownScopeUserID returns the caller's user ID when an own-scoped policy limits
+// the request to the caller's logs, or "" when every log of the tenant is visible
+func ownScopeUserID(ginCtx *gin.Context) string {
+	if ginCtx.GetString(string(utils.PolicyScopeKey)) != string(domain.PolicyScopeOwn) {
+		return ""
+	}
+	return ginCtx.GetString(string(utils.UserIDKey))
+}
+
+// upconvertLog brings a log of an older schema version up to the current
+// one, defaulting what that version didn't require from the request
+func upconvertLog(ginCtx *gin.Context, log *dto.CreateAuditLogRequest) {
+	log.Upconvert(ginCtx.GetString(string(utils.CorrelationIDKey)))
+}
+
+// fillRequestID records the ID of the creating request as request_id in a
+// log's metadata, unless the metadata has one already or isn't a JSON object
+func fillRequestID(ginCtx *gin.Context, log *dto.CreateAuditLogRequest) {
+	id := ginCtx.GetString(string(utils.RequestIDKey))
+	if id == "" {
+		return
+	}
+
+	metadata := map[string]json.RawMessage{}
+	if len(log.Metadata) > 0 && string(log.Metadata) != "null" {
+		if err := json.Unmarshal(log.Metadata, &metadata); err != nil {
+			return
+		}
+	}
+	if _, ok := metadata["request_id"]; ok {
+		return
+	}
+
+	metadata["request_id"], _ = json.Marshal(id)
+	log.Metadata, _ = json.Marshal(metadata)
+}
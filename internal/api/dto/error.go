@@ -1,6 +1,36 @@
 package dto
 
-// Error represents a standard error response
+// Error codes of error responses. Clients branch on these; messages are for
+// people and may change.
+const (
+	CodeValidationFailed    = "VALIDATION_FAILED"
+	CodeUnauthorized        = "UNAUTHORIZED"
+	CodeForbidden           = "FORBIDDEN"
+	CodeNotFound            = "NOT_FOUND"
+	CodeConflict            = "CONFLICT"
+	CodeGone                = "GONE"
+	CodePayloadTooLarge     = "PAYLOAD_TOO_LARGE"
+	CodeUnsupportedMedia    = "UNSUPPORTED_MEDIA_TYPE"
+	CodeRateLimited         = "RATE_LIMITED"
+	CodeTenantQuotaExceeded = "TENANT_QUOTA_EXCEEDED"
+	CodeInternal            = "INTERNAL_ERROR"
+	CodeServiceUnavailable  = "SERVICE_UNAVAILABLE"
+	CodeQueryTimeout        = "QUERY_TIMEOUT"
+)
+
+// Error is the body of every error response. Details holds structured
+// context, such as the fields failing validation.
 type Error struct {
-	Error string `json:"error" example:"error message"`
+	Code      string `json:"code" example:"NOT_FOUND"`
+	Message   string `json:"message" example:"log not found"`
+	Details   any    `json:"details,omitempty" swaggertype:"object"`
+	RequestID string `json:"request_id,omitempty" example:"9f1c2b4e-6a7d-4e0f-8b3a-2c5d7e9f1a3b"`
+}
+
+// FieldError describes a request field failing validation. Param is the
+// parameter of the rule, or for the schema rule what the value violates.
+type FieldError struct {
+	Field string `json:"field" example:"ids[0]"`
+	Rule  string `json:"rule" example:"uuid"`
+	Param string `json:"param,omitempty" example:""`
 }
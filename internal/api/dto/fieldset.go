@@ -0,0 +1,100 @@
+package dto
+
+// auditLogCSVColumns maps the selectable fields of a log to their column in
+// AuditLogCSVHeader and CSVRecord
+var auditLogCSVColumns = map[string]int{
+	"id": 0, "tenant_id": 1, "user_id": 2, "session_id": 3, "action": 4,
+	"resource_type": 5, "resource_id": 6, "ip_address": 7, "user_agent": 8,
+	"severity": 9, "message": 10, "before_state": 11, "after_state": 12,
+	"metadata": 13, "timestamp": 14, "correlation_id": 15,
+}
+
+// SelectAuditLogFields returns the logs with only the given fields, for
+// sparse fieldset responses
+func SelectAuditLogFields(logs []AuditLogResponse, fields []string) []map[string]any {
+	selected := make([]map[string]any, len(logs))
+	for i := range logs {
+		selected[i] = logs[i].SelectFields(fields)
+	}
+	return selected
+}
+
+// SelectFields returns the given fields of the log keyed by their JSON name.
+// Highlights of a full-text query are kept, as they only come with one.
+func (r *AuditLogResponse) SelectFields(fields []string) map[string]any {
+	selected := make(map[string]any, len(fields)+1)
+	for _, field := range fields {
+		switch field {
+		case "id":
+			selected[field] = r.ID
+		case "tenant_id":
+			selected[field] = r.TenantID
+		case "user_id":
+			selected[field] = r.UserID
+		case "session_id":
+			selected[field] = r.SessionID
+		case "correlation_id":
+			selected[field] = r.CorrelationID
+		case "ip_address":
+			selected[field] = r.IPAddress
+		case "user_agent":
+			selected[field] = r.UserAgent
+		case "action":
+			selected[field] = r.Action
+		case "resource_type":
+			selected[field] = r.ResourceType
+		case "resource_id":
+			selected[field] = r.ResourceID
+		case "severity":
+			selected[field] = r.Severity
+		case "message":
+			selected[field] = r.Message
+		case "before_state":
+			selected[field] = r.BeforeState
+		case "after_state":
+			selected[field] = r.AfterState
+		case "metadata":
+			selected[field] = r.Metadata
+		case "schema_errors":
+			selected[field] = r.SchemaErrors
+		case "schema_version":
+			selected[field] = r.SchemaVersion
+		case "tags":
+			selected[field] = r.Tags
+		case "timestamp":
+			selected[field] = r.Timestamp
+		}
+	}
+	if len(r.Highlights) > 0 {
+		selected["highlights"] = r.Highlights
+	}
+	return selected
+}
+
+// AuditLogCSVHeaderOf returns the CSV header row for the given fields, or the
+// full AuditLogCSVHeader when fields is empty
+func AuditLogCSVHeaderOf(fields []string) []string {
+	if len(fields) == 0 {
+		return AuditLogCSVHeader
+	}
+	return selectCSVColumns(AuditLogCSVHeader, fields)
+}
+
+// CSVRecordOf converts the given fields of the log to a CSV row matching
+// AuditLogCSVHeaderOf, or all of them when fields is empty
+func (r *AuditLogResponse) CSVRecordOf(fields []string) []string {
+	if len(fields) == 0 {
+		return r.CSVRecord()
+	}
+	return selectCSVColumns(r.CSVRecord(), fields)
+}
+
+func selectCSVColumns(row []string, fields []string) []string {
+	selected := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if i, ok := auditLogCSVColumns[field]; ok {
+			selected = append(selected, row[i])
+		}
+	}
+	return selected
+}
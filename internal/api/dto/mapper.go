@@ -1,48 +1,83 @@
 package dto
 
 import (
+	"math"
+	"time"
+
 	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/pkg/jsondiff"
 )
 
-// ToAuditLog converts a CreateAuditLogRequest DTO to an AuditLog domain model
+// AuditLogCSVHeader is the header row of CSV exports, matching AuditLogResponse.CSVRecord
+var AuditLogCSVHeader = []string{
+	"ID", "TenantID", "UserID", "SessionID", "Action",
+	"ResourceType", "ResourceID", "IPAddress", "UserAgent",
+	"Severity", "Message", "BeforeState", "AfterState",
+	"Metadata", "Timestamp", "CorrelationID",
+}
+
+// ToAuditLog converts a CreateAuditLogRequest DTO to an AuditLog domain model.
+// The request is expected to be upconverted already, so the log is recorded
+// as of the current schema version.
 func (r *CreateAuditLogRequest) ToAuditLog() *domain.AuditLog {
 	return &domain.AuditLog{
-		TenantID:     r.TenantID,
-		UserID:       r.UserID,
-		SessionID:    r.SessionID,
-		IPAddress:    r.IPAddress,
-		UserAgent:    r.UserAgent,
-		Action:       r.Action,
-		ResourceType: r.ResourceType,
-		ResourceID:   r.ResourceID,
-		Severity:     r.Severity,
-		Message:      r.Message,
-		BeforeState:  r.BeforeState,
-		AfterState:   r.AfterState,
-		Metadata:     r.Metadata,
-		Timestamp:    r.Timestamp,
+		SchemaVersion: domain.LogSchemaVersion,
+		TenantID:      r.TenantID,
+		UserID:        r.UserID,
+		SessionID:     r.SessionID,
+		CorrelationID: r.CorrelationID,
+		IPAddress:     r.IPAddress,
+		UserAgent:     r.UserAgent,
+		Action:        r.Action,
+		ResourceType:  r.ResourceType,
+		ResourceID:    r.ResourceID,
+		Severity:      r.Severity,
+		Message:       r.Message,
+		BeforeState:   r.BeforeState,
+		AfterState:    r.AfterState,
+		Metadata:      r.Metadata,
+		Timestamp:     r.Timestamp,
 	}
 }
 
 // FromAuditLog converts an AuditLog domain model to an AuditLogResponse DTO
 func FromAuditLog(log *domain.AuditLog) *AuditLogResponse {
 	return &AuditLogResponse{
-		ID:           log.ID,
-		TenantID:     log.TenantID,
-		UserID:       log.UserID,
-		SessionID:    log.SessionID,
-		IPAddress:    log.IPAddress,
-		UserAgent:    log.UserAgent,
-		Action:       log.Action,
-		ResourceType: log.ResourceType,
-		ResourceID:   log.ResourceID,
-		Severity:     log.Severity,
-		Message:      log.Message,
-		BeforeState:  log.BeforeState,
-		AfterState:   log.AfterState,
-		Metadata:     log.Metadata,
-		Timestamp:    log.Timestamp,
+		ID:            log.ID,
+		TenantID:      log.TenantID,
+		UserID:        log.UserID,
+		SessionID:     log.SessionID,
+		CorrelationID: log.CorrelationID,
+		IPAddress:     log.IPAddress,
+		UserAgent:     log.UserAgent,
+		Action:        log.Action,
+		ResourceType:  log.ResourceType,
+		ResourceID:    log.ResourceID,
+		Severity:      log.Severity,
+		Message:       log.Message,
+		BeforeState:   log.BeforeState,
+		AfterState:    log.AfterState,
+		Metadata:      log.Metadata,
+		SchemaErrors:  log.SchemaErrors,
+		SchemaVersion: log.SchemaVersion,
+		Tags:          log.Tags,
+		Timestamp:     log.Timestamp,
+		Highlights:    log.Highlights,
+	}
+}
+
+// FromStateChanges converts the differences between two states to StateChange DTOs
+func FromStateChanges(changes []jsondiff.Change) []StateChange {
+	responses := make([]StateChange, len(changes))
+	for i, change := range changes {
+		responses[i] = StateChange{
+			Path:   change.Path,
+			Op:     string(change.Op),
+			Before: change.Before,
+			After:  change.After,
+		}
 	}
+	return responses
 }
 
 func FromAuditLogs(logs []domain.AuditLog) []AuditLogResponse {
@@ -52,3 +87,564 @@ func FromAuditLogs(logs []domain.AuditLog) []AuditLogResponse {
 	}
 	return responses
 }
+
+// CSVRecord converts an AuditLogResponse to a CSV row; JSON fields are written as raw JSON
+func (r *AuditLogResponse) CSVRecord() []string {
+	return []string{
+		r.ID,
+		r.TenantID,
+		r.UserID,
+		r.SessionID,
+		r.Action,
+		r.ResourceType,
+		r.ResourceID,
+		r.IPAddress,
+		r.UserAgent,
+		r.Severity,
+		r.Message,
+		string(r.BeforeState),
+		string(r.AfterState),
+		string(r.Metadata),
+		r.Timestamp.Format(time.RFC3339),
+		r.CorrelationID,
+	}
+}
+
+// FromUser converts a User domain model to a UserResponse DTO
+func FromUser(user *domain.User) *UserResponse {
+	return &UserResponse{
+		ID:        user.ID,
+		TenantID:  user.TenantID,
+		Email:     user.Email,
+		Name:      user.Name,
+		Roles:     user.Roles,
+		Active:    user.Active,
+		Metadata:  user.Metadata,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+}
+
+func FromUsers(users []domain.User) []UserResponse {
+	responses := make([]UserResponse, len(users))
+	for i := range users {
+		responses[i] = *FromUser(&users[i])
+	}
+	return responses
+}
+
+// FromPolicy converts a Policy domain model to a PolicyResponse DTO
+func FromPolicy(policy *domain.Policy) *PolicyResponse {
+	return &PolicyResponse{
+		ID:        policy.ID,
+		TenantID:  policy.TenantID,
+		Role:      policy.Role,
+		Resource:  string(policy.Resource),
+		Action:    string(policy.Action),
+		Effect:    string(policy.Effect),
+		Scope:     string(policy.Scope),
+		CreatedAt: policy.CreatedAt,
+		UpdatedAt: policy.UpdatedAt,
+	}
+}
+
+func FromPolicies(policies []domain.Policy) []PolicyResponse {
+	responses := make([]PolicyResponse, len(policies))
+	for i := range policies {
+		responses[i] = *FromPolicy(&policies[i])
+	}
+	return responses
+}
+
+// FromRedactionRule converts a RedactionRule domain model to a RedactionRuleResponse DTO
+func FromRedactionRule(rule *domain.RedactionRule) *RedactionRuleResponse {
+	return &RedactionRuleResponse{
+		ID:        rule.ID,
+		TenantID:  rule.TenantID,
+		Target:    string(rule.Target),
+		Path:      rule.Path,
+		Mask:      string(rule.Mask),
+		CreatedAt: rule.CreatedAt,
+		UpdatedAt: rule.UpdatedAt,
+	}
+}
+
+func FromRedactionRules(rules []domain.RedactionRule) []RedactionRuleResponse {
+	responses := make([]RedactionRuleResponse, len(rules))
+	for i := range rules {
+		responses[i] = *FromRedactionRule(&rules[i])
+	}
+	return responses
+}
+
+// FromTaggingRule converts a TaggingRule domain model to a TaggingRuleResponse DTO
+func FromTaggingRule(rule *domain.TaggingRule) *TaggingRuleResponse {
+	return &TaggingRuleResponse{
+		ID:             rule.ID,
+		TenantID:       rule.TenantID,
+		Tag:            rule.Tag,
+		Actions:        rule.Actions,
+		ResourceTypes:  rule.ResourceTypes,
+		Severities:     rule.Severities,
+		MessagePattern: rule.MessagePattern,
+		CreatedAt:      rule.CreatedAt,
+		UpdatedAt:      rule.UpdatedAt,
+	}
+}
+
+func FromTaggingRules(rules []domain.TaggingRule) []TaggingRuleResponse {
+	responses := make([]TaggingRuleResponse, len(rules))
+	for i := range rules {
+		responses[i] = *FromTaggingRule(&rules[i])
+	}
+	return responses
+}
+
+func FromIndexFailure(failure *domain.IndexFailure) *IndexFailureResponse {
+	return &IndexFailureResponse{
+		ID:        failure.ID,
+		LogID:     failure.LogID,
+		IndexName: failure.IndexName,
+		Status:    failure.Status,
+		ErrorType: failure.ErrorType,
+		Reason:    failure.Reason,
+		FailedAt:  failure.FailedAt,
+	}
+}
+
+func FromIndexFailures(failures []domain.IndexFailure) []IndexFailureResponse {
+	responses := make([]IndexFailureResponse, len(failures))
+	for i := range failures {
+		responses[i] = *FromIndexFailure(&failures[i])
+	}
+	return responses
+}
+
+func FromSearchIndices(indices []domain.SearchIndex) []SearchIndexResponse {
+	responses := make([]SearchIndexResponse, len(indices))
+	for i, index := range indices {
+		responses[i] = SearchIndexResponse{
+			Name:      index.Name,
+			Day:       index.Day.Format(time.DateOnly),
+			Docs:      index.Docs,
+			SizeBytes: index.SizeBytes,
+			Health:    index.Health,
+		}
+	}
+	return responses
+}
+
+func FromResourceSchema(schema *domain.ResourceSchema) *ResourceSchemaResponse {
+	return &ResourceSchemaResponse{
+		ID:             schema.ID,
+		TenantID:       schema.TenantID,
+		ResourceType:   schema.ResourceType,
+		MetadataSchema: schema.MetadataSchema,
+		StateSchema:    schema.StateSchema,
+		Mode:           string(schema.Mode),
+		CreatedAt:      schema.CreatedAt,
+		UpdatedAt:      schema.UpdatedAt,
+	}
+}
+
+func FromResourceSchemas(schemas []domain.ResourceSchema) []ResourceSchemaResponse {
+	responses := make([]ResourceSchemaResponse, len(schemas))
+	for i := range schemas {
+		responses[i] = *FromResourceSchema(&schemas[i])
+	}
+	return responses
+}
+
+// FromAuditLogStats converts AuditLogStats to a GetAuditLogStatsResponse DTO
+func FromAuditLogStats(stats *domain.AuditLogStats) *GetAuditLogStatsResponse {
+	response := &GetAuditLogStatsResponse{
+		TotalLogs:      stats.TotalLogs,
+		ActionCounts:   make(map[string]int64, len(stats.ActionCounts)),
+		SeverityCounts: make(map[string]int64, len(stats.SeverityCounts)),
+		ResourceCounts: make(map[string]int64, len(stats.ResourceCounts)),
+	}
+
+	for action, count := range stats.ActionCounts {
+		response.ActionCounts[string(action)] = count
+	}
+	for severity, count := range stats.SeverityCounts {
+		response.SeverityCounts[string(severity)] = count
+	}
+	for resourceType, count := range stats.ResourceCounts {
+		response.ResourceCounts[resourceType] = count
+	}
+
+	if stats.Series != nil {
+		response.Interval = stats.Interval.String()
+		response.Series = make([]StatsBucketResponse, len(stats.Series))
+		for i, bucket := range stats.Series {
+			response.Series[i] = StatsBucketResponse{Start: bucket.Start, Count: bucket.Count}
+		}
+	}
+
+	if sampled := stats.SampledOut; sampled != nil && sampled.TotalLogs > 0 {
+		response.SampledOut = &SampledLogsResponse{
+			TotalLogs:      sampled.TotalLogs,
+			ActionCounts:   sampled.ActionCounts,
+			SeverityCounts: sampled.SeverityCounts,
+		}
+		response.EstimatedTotalLogs = stats.TotalLogs + sampled.TotalLogs
+	}
+
+	return response
+}
+
+// FromTopStats converts TopStats to a TopStatsResponse, with the share of the
+// logs each value holds
+func FromTopStats(stats *domain.TopStats) *TopStatsResponse {
+	response := &TopStatsResponse{
+		TotalLogs:         stats.TotalLogs,
+		Users:             fromTopValues(stats.Users, stats.TotalLogs),
+		IPAddresses:       fromTopValues(stats.IPAddresses, stats.TotalLogs),
+		Resources:         fromTopValues(stats.Resources, stats.TotalLogs),
+		Sessions:          fromTopValues(stats.Sessions, stats.TotalLogs),
+		UniqueUsers:       stats.UniqueUsers,
+		UniqueIPAddresses: stats.UniqueIPAddresses,
+		Daily:             make([]DailyUniqueCountsResponse, len(stats.Daily)),
+	}
+	for i, day := range stats.Daily {
+		response.Daily[i] = DailyUniqueCountsResponse{
+			Day:               day.Day.Format(time.DateOnly),
+			UniqueUsers:       day.Users,
+			UniqueIPAddresses: day.IPAddresses,
+		}
+	}
+	return response
+}
+
+func fromTopValues(values []domain.TopValue, total int64) []TopValueResponse {
+	response := make([]TopValueResponse, len(values))
+	for i, value := range values {
+		response[i] = TopValueResponse{
+			Value:        value.Value,
+			ResourceType: value.ResourceType,
+			Count:        value.Count,
+		}
+		if total > 0 {
+			response[i].Percentage = math.Round(float64(value.Count)*10000/float64(total)) / 100
+		}
+	}
+	return response
+}
+
+// FromSavedSearch converts a SavedSearch domain model to a SavedSearchResponse DTO
+func FromSavedSearch(search *domain.SavedSearch) *SavedSearchResponse {
+	return &SavedSearchResponse{
+		ID:          search.ID,
+		TenantID:    search.TenantID,
+		OwnerID:     search.OwnerID,
+		Name:        search.Name,
+		Description: search.Description,
+		Filter:      SavedSearchFilter(search.Filter),
+		Shared:      search.Shared,
+		CreatedAt:   search.CreatedAt,
+		UpdatedAt:   search.UpdatedAt,
+	}
+}
+
+// ToSavedSearchFilter converts a SavedSearchFilter DTO to the domain model
+func (f SavedSearchFilter) ToSavedSearchFilter() domain.SavedSearchFilter {
+	return domain.SavedSearchFilter(f)
+}
+
+func FromSavedSearches(searches []domain.SavedSearch) []SavedSearchResponse {
+	responses := make([]SavedSearchResponse, len(searches))
+	for i := range searches {
+		responses[i] = *FromSavedSearch(&searches[i])
+	}
+	return responses
+}
+
+func FromIntegration(integration *domain.Integration) *IntegrationResponse {
+	return &IntegrationResponse{
+		ID:               integration.ID,
+		TenantID:         integration.TenantID,
+		Name:             integration.Name,
+		Type:             string(integration.Type),
+		Enabled:          integration.Enabled,
+		Token:            "********" + integration.TokenHint,
+		Settings:         IntegrationSettings(integration.Settings),
+		Filter:           IntegrationFilter(integration.Filter),
+		ForwardedCount:   integration.ForwardedCount,
+		ForwardedThrough: integration.CursorCreatedAt,
+		LastForwardedAt:  integration.LastForwardedAt,
+		Failures:         integration.Failures,
+		LastError:        integration.LastError,
+		NextAttemptAt:    integration.NextAttemptAt,
+		CreatedAt:        integration.CreatedAt,
+		UpdatedAt:        integration.UpdatedAt,
+	}
+}
+
+func FromIntegrations(integrations []domain.Integration) []IntegrationResponse {
+	responses := make([]IntegrationResponse, len(integrations))
+	for i := range integrations {
+		responses[i] = *FromIntegration(&integrations[i])
+	}
+	return responses
+}
+
+// FromTenantDeletion converts a deleted Tenant domain model to a TenantDeletionResponse DTO
+func FromTenantDeletion(tenant *domain.Tenant) *TenantDeletionResponse {
+	resp := &TenantDeletionResponse{
+		ID:   tenant.ID,
+		Name: tenant.Name,
+	}
+	if tenant.DeletedAt != nil {
+		resp.DeletedAt = *tenant.DeletedAt
+	}
+	if tenant.PurgeAfter != nil {
+		resp.PurgeAfter = *tenant.PurgeAfter
+	}
+	return resp
+}
+
+// FromTenantRateLimit converts a TenantRateLimit domain model to a TenantRateLimitResponse DTO
+func FromTenantRateLimit(limit *domain.TenantRateLimit) *TenantRateLimitResponse {
+	return &TenantRateLimitResponse{
+		TenantID:  limit.TenantID,
+		RateLimit: limit.Limit,
+		Burst:     limit.Burst,
+	}
+}
+
+// FromArchiveSchedule converts an ArchiveSchedule domain model to an ArchiveScheduleResponse DTO
+func FromArchiveSchedule(schedule *domain.ArchiveSchedule) *ArchiveScheduleResponse {
+	return &ArchiveScheduleResponse{
+		TenantID:      schedule.TenantID,
+		Enabled:       schedule.Enabled,
+		IntervalHours: schedule.IntervalHours,
+		RetentionDays: schedule.RetentionDays,
+		LastRunAt:     schedule.LastRunAt,
+		LastJobID:     schedule.LastJobID,
+	}
+}
+
+// FromTenantSettings converts a Tenant domain model to a TenantSettingsResponse DTO
+func FromTenantSettings(tenant *domain.Tenant) *TenantSettingsResponse {
+	settings := tenant.Settings
+
+	allowedActions := settings.AllowedActions
+	if allowedActions == nil {
+		allowedActions = []string{}
+	}
+	customActions := settings.CustomActions
+	if customActions == nil {
+		customActions = []string{}
+	}
+	secrets := make([]string, len(settings.WebhookSecrets))
+	for i, secret := range settings.WebhookSecrets {
+		secrets[i] = maskSecret(secret)
+	}
+	logVisibility := settings.LogVisibility
+	if logVisibility == "" {
+		logVisibility = domain.PolicyScopeAll
+	}
+	samplingRules := make([]SamplingRuleResponse, len(settings.SamplingRules))
+	for i, rule := range settings.SamplingRules {
+		samplingRules[i] = SamplingRuleResponse{
+			Actions:    nonNil(rule.Actions),
+			Severities: nonNil(rule.Severities),
+			Rate:       rule.Rate,
+		}
+	}
+	userRateLimits := settings.UserRateLimits
+	if userRateLimits == nil {
+		userRateLimits = map[string]int{}
+	}
+
+	return &TenantSettingsResponse{
+		TenantID:            tenant.ID,
+		RetentionDays:       settings.RetentionDays,
+		RateLimit:           tenant.RateLimit,
+		RateLimitBurst:      tenant.RateLimitBurst,
+		AllowedActions:      allowedActions,
+		CustomActions:       customActions,
+		WebhookURL:          settings.WebhookURL,
+		WebhookSecrets:      secrets,
+		DataResidencyRegion: settings.DataResidencyRegion,
+		LogVisibility:       string(logVisibility),
+		SamplingRules:       samplingRules,
+		UserRateLimits:      userRateLimits,
+		IndexedMetadataKeys: nonNil(settings.IndexedMetadataKeys),
+		ArchiveStorage: ArchiveStorageResponse{
+			StorageClass:     settings.ArchiveStorage.StorageClass,
+			GlacierAfterDays: settings.ArchiveStorage.GlacierAfterDays,
+			ObjectLockMode:   settings.ArchiveStorage.ObjectLockMode,
+			ObjectLockDays:   settings.ArchiveStorage.ObjectLockDays,
+		},
+		Encryption: EncryptionResponse{
+			KMSKeyARN:       settings.Encryption.KMSKeyARN,
+			ExportPublicKey: settings.Encryption.ExportPublicKey,
+		},
+		UpdatedAt: tenant.UpdatedAt,
+	}
+}
+
+// nonNil returns values, or an empty list so it isn't encoded as null
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// maskSecret hides all but the last four characters of a secret, and its length
+func maskSecret(secret string) string {
+	const mask = "********"
+	if len(secret) <= 4 {
+		return mask
+	}
+	return mask + secret[len(secret)-4:]
+}
+
+// FromExportJob converts an ExportJob domain model to an ExportJobResponse DTO
+func FromExportJob(job *domain.ExportJob) *ExportJobResponse {
+	resp := &ExportJobResponse{
+		ID:          job.ID,
+		Status:      string(job.Status),
+		Scope:       string(job.Scope),
+		Format:      string(job.Format),
+		RowCount:    job.RowCount,
+		Error:       job.Error,
+		Encrypted:   job.Encrypted,
+		CreatedAt:   job.CreatedAt,
+		CompletedAt: job.CompletedAt,
+	}
+	if job.Signature != nil {
+		resp.Signature = FromExportSignature(job.ID, job.Signature)
+	}
+	return resp
+}
+
+// FromSigningKey converts a SigningKey domain model to a SigningKeyResponse DTO
+func FromSigningKey(key domain.SigningKey) SigningKeyResponse {
+	return SigningKeyResponse{
+		KeyID:     key.ID,
+		Key:       string(key.Key),
+		Algorithm: key.Algorithm,
+		PublicKey: key.PublicKey,
+	}
+}
+
+// FromExportSignature converts the signature of an export job to an
+// ExportSignatureResponse DTO
+func FromExportSignature(jobID string, signature *domain.ExportSignature) *ExportSignatureResponse {
+	return &ExportSignatureResponse{
+		JobID:              jobID,
+		SigningKeyResponse: FromSigningKey(signature.SigningKey),
+		SHA256:             signature.SHA256,
+		Signature:          signature.Signature,
+		SignedAt:           signature.SignedAt,
+	}
+}
+
+// FromRestoreJob converts a RestoreJob domain model to a RestoreJobResponse DTO
+func FromRestoreJob(job *domain.RestoreJob) *RestoreJobResponse {
+	return &RestoreJobResponse{
+		ID:               job.ID,
+		Status:           string(job.Status),
+		StartTime:        job.StartTime,
+		EndTime:          job.EndTime,
+		ObjectsProcessed: job.ObjectsProcessed,
+		RestoredCount:    job.RestoredCount,
+		Error:            job.Error,
+		CreatedAt:        job.CreatedAt,
+		CompletedAt:      job.CompletedAt,
+	}
+}
+
+// FromImportJob converts an ImportJob domain model to an ImportJobResponse DTO
+func FromImportJob(job *domain.ImportJob) *ImportJobResponse {
+	resp := &ImportJobResponse{
+		ID:            job.ID,
+		Status:        string(job.Status),
+		SourceURI:     job.SourceURI,
+		SizeBytes:     job.SizeBytes,
+		BytesRead:     job.BytesRead,
+		LinesRead:     job.LinesRead,
+		ImportedCount: job.ImportedCount,
+		SkippedCount:  job.SkippedCount,
+		InvalidCount:  job.InvalidCount,
+		Error:         job.Error,
+		CreatedAt:     job.CreatedAt,
+		CompletedAt:   job.CompletedAt,
+	}
+	switch {
+	case job.Status == domain.JobCompleted:
+		resp.Progress = 100
+	case job.SizeBytes > 0:
+		resp.Progress = math.Round(float64(job.BytesRead)*1000/float64(job.SizeBytes)) / 10
+	}
+	for _, lineErr := range job.LineErrors {
+		resp.LineErrors = append(resp.LineErrors, ImportLineErrorResponse{Line: lineErr.Line, Error: lineErr.Error})
+	}
+	return resp
+}
+
+// FromJob converts a Job domain model to a JobResponse DTO
+func FromJob(job *domain.Job) *JobResponse {
+	return &JobResponse{
+		ID:          job.ID,
+		Type:        string(job.Type),
+		Status:      string(job.Status),
+		Counts:      job.Counts,
+		Error:       job.Error,
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+		CompletedAt: job.CompletedAt,
+	}
+}
+
+// FromJobs converts a slice of Job domain models to JobResponse DTOs
+func FromJobs(jobs []domain.Job) []JobResponse {
+	responses := make([]JobResponse, len(jobs))
+	for i := range jobs {
+		responses[i] = *FromJob(&jobs[i])
+	}
+	return responses
+}
+
+// FromArchive converts an Archive domain model to an ArchiveResponse DTO
+func FromArchive(archive *domain.Archive) *ArchiveResponse {
+	parts := make([]ArchivePartResponse, len(archive.Parts))
+	for i, part := range archive.Parts {
+		parts[i] = ArchivePartResponse{
+			Key:            part.Key,
+			LogCount:       part.LogCount,
+			FirstTimestamp: part.FirstTimestamp,
+			LastTimestamp:  part.LastTimestamp,
+			Size:           part.Size,
+			SHA256:         part.SHA256,
+		}
+	}
+	return &ArchiveResponse{
+		ID:                 archive.ID,
+		BeforeDate:         archive.BeforeDate,
+		ManifestKey:        archive.ManifestKey,
+		ManifestSHA256:     archive.ManifestSHA256,
+		LogCount:           archive.LogCount,
+		Size:               archive.Size,
+		Parts:              parts,
+		StorageClass:       archive.StorageClass,
+		RetainUntil:        archive.RetainUntil,
+		KMSKeyID:           archive.KMSKeyID,
+		ArchivedAt:         archive.ArchivedAt,
+		VerificationStatus: string(archive.VerificationStatus),
+		LastVerifiedAt:     archive.LastVerifiedAt,
+	}
+}
+
+// FromArchives converts a slice of Archive domain models to ArchiveResponse DTOs
+func FromArchives(archives []domain.Archive) []ArchiveResponse {
+	responses := make([]ArchiveResponse, len(archives))
+	for i := range archives {
+		responses[i] = *FromArchive(&archives[i])
+	}
+	return responses
+}
@@ -9,19 +9,271 @@ type CreateTenantRequest struct {
 	Name string `json:"name" binding:"required"`
 }
 
+type UpdateTenantRateLimitRequest struct {
+	RateLimit int `json:"rate_limit" binding:"required,min=1" example:"1000"`
+	Burst     int `json:"burst" binding:"min=0" example:"200"`
+}
+
+// UpdateTenantSettingsRequest changes the settings that are given. Lists and
+// maps replace the current ones; an empty one clears them. User rate limits
+// cap the requests per minute of each user on ingest or query routes, 0
+// leaving the route class uncapped. Indexed metadata keys are the top-level
+// metadata keys searchable under the indexed_keys metadata mapping.
+type UpdateTenantSettingsRequest struct {
+	RetentionDays       *int                  `json:"retention_days" binding:"omitempty,min=0,max=3650" example:"90"`
+	RateLimit           *int                  `json:"rate_limit" binding:"omitempty,min=1" example:"1000"`
+	RateLimitBurst      *int                  `json:"rate_limit_burst" binding:"omitempty,min=0" example:"200"`
+	AllowedActions      []string              `json:"allowed_actions" binding:"omitempty,max=100,dive,required,max=64" example:"CREATE,UPDATE,DELETE"`
+	CustomActions       []string              `json:"custom_actions" binding:"omitempty,max=100,dive,required,max=64" example:"LOGIN,EXPORT"`
+	WebhookURL          *string               `json:"webhook_url" binding:"omitempty,max=2048,url|len=0,startswith=https://|len=0" example:"https://hooks.example.com/audit"`
+	WebhookSecrets      []string              `json:"webhook_secrets" binding:"omitempty,max=5,dive,min=16,max=256" example:"whsec_3f9a1c7e2b8d4f60"`
+	DataResidencyRegion *string               `json:"data_residency_region" binding:"omitempty,max=64" example:"eu-west-1"`
+	LogVisibility       *string               `json:"log_visibility" binding:"omitempty,oneof=all own" example:"own"`
+	SamplingRules       []SamplingRuleRequest `json:"sampling_rules" binding:"omitempty,max=20,dive"`
+	UserRateLimits      map[string]int        `json:"user_rate_limits" binding:"omitempty,dive,keys,oneof=ingest query,endkeys,min=0"`
+	IndexedMetadataKeys []string              `json:"indexed_metadata_keys" binding:"omitempty,max=50,dive,required,max=64,excludesall=.*" example:"order_id,region"`
+	// ArchiveStorage changes how the tenant's archives are stored in S3
+	ArchiveStorage *ArchiveStorageRequest `json:"archive_storage"`
+	// Encryption changes the keys the tenant's archives and exports are encrypted with
+	Encryption *EncryptionRequest `json:"encryption"`
+}
+
+// EncryptionRequest changes the keys the tenant's data is encrypted with in
+// S3. Empty strings restore the defaults. kms_key_arn is the customer-managed
+// KMS key archives and exports written afterwards are encrypted with; the
+// service's role must be allowed to use it. export_public_key is a PEM-encoded
+// RSA public key of at least 2048 bits log exports are encrypted for before
+// upload.
+type EncryptionRequest struct {
+	KMSKeyARN       *string `json:"kms_key_arn" binding:"omitempty,max=2048,startswith=arn:|len=0" example:"arn:aws:kms:eu-west-1:111122223333:key/1234abcd-12ab-34cd-56ef-1234567890ab"`
+	ExportPublicKey *string `json:"export_public_key" binding:"omitempty,max=8192" example:"-----BEGIN PUBLIC KEY-----\nMIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8AMIIBCgKCAQEA...\n-----END PUBLIC KEY-----\n"`
+}
+
+// ArchiveStorageRequest changes how the tenant's archives are stored in S3.
+// Empty strings and 0 restore the defaults. Object lock mode and days only
+// apply to archives written afterwards, which can't be deleted or overwritten
+// until their retention date passes; COMPLIANCE retention can't be shortened.
+type ArchiveStorageRequest struct {
+	StorageClass     *string `json:"storage_class" binding:"omitempty,oneof=STANDARD STANDARD_IA ONEZONE_IA INTELLIGENT_TIERING GLACIER_IR GLACIER DEEP_ARCHIVE" example:"STANDARD_IA"`
+	GlacierAfterDays *int    `json:"glacier_after_days" binding:"omitempty,min=0,max=36500" example:"90"`
+	ObjectLockMode   *string `json:"object_lock_mode" binding:"omitempty,oneof=GOVERNANCE COMPLIANCE" example:"COMPLIANCE"`
+	ObjectLockDays   *int    `json:"object_lock_days" binding:"omitempty,min=0,max=36500" example:"2555"`
+}
+
+// SamplingRuleRequest keeps rate, between 0 and 1, of the logs with one of
+// actions and one of severities at ingest. An empty list matches every action
+// or severity; the first matching rule applies.
+type SamplingRuleRequest struct {
+	Actions    []string `json:"actions" binding:"omitempty,max=100,dive,required,max=64" example:"VIEW"`
+	Severities []string `json:"severities" binding:"omitempty,max=10,dive,severity" example:"INFO"`
+	Rate       *float64 `json:"rate" binding:"required,min=0,max=1" example:"0.01"`
+}
+
+// UpdateArchiveScheduleRequest changes the archive schedule overrides that are
+// given. Zero interval_hours or retention_days restores the default.
+type UpdateArchiveScheduleRequest struct {
+	Enabled       *bool `json:"enabled" example:"true"`
+	IntervalHours *int  `json:"interval_hours" binding:"omitempty,min=0,max=8760" example:"24"`
+	RetentionDays *int  `json:"retention_days" binding:"omitempty,min=0,max=3650" example:"90"`
+}
+
+type CreateUserRequest struct {
+	Email    string          `json:"email" binding:"required,email" example:"jane@example.com"`
+	Name     string          `json:"name" binding:"required" example:"Jane Doe"`
+	Password string          `json:"password" binding:"required,min=8,max=72" example:"correct-horse-battery"`
+	Roles    []string        `json:"roles" binding:"omitempty,dive,oneof=admin user auditor" example:"user,auditor"`
+	Metadata json.RawMessage `json:"metadata" swaggertype:"string" example:"{\\"team\\":\\"security\\"}"`
+}
+
+type UpdateUserRolesRequest struct {
+	Roles []string `json:"roles" binding:"required,min=1,dive,oneof=admin user auditor" example:"admin"`
+}
+
+// PolicyRequest defines a permission for a role. Admin permissions are fixed and cannot be changed.
+type PolicyRequest struct {
+	Role     string `json:"role" binding:"required,oneof=user auditor" example:"user"`
+	Resource string `json:"resource" binding:"required,oneof=logs users tenants policies redaction_rules tagging_rules schemas saved_searches config index_failures indices jobs archives integrations *" example:"logs"`
+	Action   string `json:"action" binding:"required,oneof=read create update delete export restore import *" example:"read"`
+	Effect   string `json:"effect" binding:"omitempty,oneof=allow deny" example:"allow"`
+	Scope    string `json:"scope" binding:"omitempty,oneof=all own" example:"own"`
+}
+
+// RedactionRuleRequest masks values at a JSON path of ingested logs. Path is dotted,
+// "*" matches any key or array element, and an empty path covers the whole field.
+type RedactionRuleRequest struct {
+	Target string `json:"target" binding:"required,oneof=before_state after_state metadata" example:"after_state"`
+	Path   string `json:"path" binding:"max=255" example:"customer.email"`
+	Mask   string `json:"mask" binding:"required,oneof=full email ssn card_number" example:"email"`
+}
+
+// TaggingRuleRequest tags the logs ingested from now on that match every
+// criterion it sets: one of actions, resource_types and severities, and
+// message_pattern, a regular expression searched for in the message. At least
+// one criterion is required.
+type TaggingRuleRequest struct {
+	Tag            string   `json:"tag" binding:"required,tag" example:"privilege-change"`
+	Actions        []string `json:"actions" binding:"omitempty,max=100,dive,required,max=64" example:"UPDATE"`
+	ResourceTypes  []string `json:"resource_types" binding:"omitempty,max=100,dive,required,max=255" example:"role"`
+	Severities     []string `json:"severities" binding:"omitempty,max=10,dive,severity" example:"WARNING"`
+	MessagePattern string   `json:"message_pattern" binding:"max=1024" example:"(?i)granted .*admin"`
+}
+
+// ResourceSchemaRequest registers JSON Schemas for the logs of a resource type.
+// metadata_schema applies to metadata and state_schema to both before_state and
+// after_state. Logs that don't conform are rejected in reject mode and stored
+// with their violations in flag mode.
+type ResourceSchemaRequest struct {
+	ResourceType   string          `json:"resource_type" binding:"required,max=255" example:"user"`
+	MetadataSchema json.RawMessage `json:"metadata_schema" binding:"max=65536" swaggertype:"object"`
+	StateSchema    json.RawMessage `json:"state_schema" binding:"max=65536" swaggertype:"object"`
+	Mode           string          `json:"mode" binding:"required,oneof=reject flag" example:"reject"`
+}
+
+// SavedSearchRequest names a log filter. Shared searches are visible to the whole tenant.
+type SavedSearchRequest struct {
+	Name        string            `json:"name" binding:"required,max=100" example:"Failed logins"`
+	Description string            `json:"description" binding:"max=500" example:"Login failures in the last day"`
+	Filter      SavedSearchFilter `json:"filter"`
+	Shared      bool              `json:"shared" example:"true"`
+}
+
+// SavedSearchFilter holds the log filter criteria of a saved search. Use either
+// an absolute start_time/end_time or a lookback relative to when the search runs.
+// Action, resource_type, severity and tags take comma-separated values; values
+// prefixed with ! are excluded.
+type SavedSearchFilter struct {
+	UserID       string     `json:"user_id,omitempty" example:"user123"`
+	SessionID    string     `json:"session_id,omitempty"`
+	IPAddress    string     `json:"ip_address,omitempty"`
+	UserAgent    string     `json:"user_agent,omitempty"`
+	Action       string     `json:"action,omitempty" example:"LOGIN"`
+	ResourceType string     `json:"resource_type,omitempty"`
+	Message      string     `json:"message,omitempty"`
+	Severity     string     `json:"severity,omitempty" example:"ERROR,CRITICAL"`
+	Tags         string     `json:"tags,omitempty" example:"authentication"`
+	StartTime    *time.Time `json:"start_time,omitempty"`
+	EndTime      *time.Time `json:"end_time,omitempty"`
+	Lookback     string     `json:"lookback,omitempty" example:"24h"`
+	// MinThreatScore is 0 to 100, 0 not filtering by threat score
+	MinThreatScore int `json:"min_threat_score,omitempty" binding:"min=0,max=100" example:"50"`
+}
+
+// IntegrationRequest configures an integration forwarding the tenant's logs
+// to Splunk (HTTP Event Collector) or Datadog (Logs API). The token is the HEC
+// token or Datadog API key; updates without one keep the current token.
+type IntegrationRequest struct {
+	Name     string              `json:"name" binding:"required,max=100" example:"Splunk SIEM"`
+	Type     string              `json:"type" binding:"required,oneof=splunk datadog" example:"splunk"`
+	Enabled  *bool               `json:"enabled" example:"true"`
+	Token    string              `json:"token" binding:"max=512" example:"5f1c2e9a-7b3d-4c8e-9a1f-2d6b8e4c0a73"`
+	Settings IntegrationSettings `json:"settings"`
+	Filter   IntegrationFilter   `json:"filter"`
+}
+
+// IntegrationSettings configures where logs are forwarded. url is required
+// for Splunk, the https base URL of the HTTP Event Collector, and replaces
+// the intake of site for Datadog. index and sourcetype apply to Splunk, site,
+// service and tags to Datadog.
+type IntegrationSettings struct {
+	URL        string   `json:"url,omitempty" binding:"omitempty,max=2048,url,startswith=https://" example:"https://splunk.example.com:8088"`
+	Index      string   `json:"index,omitempty" binding:"max=255" example:"audit"`
+	SourceType string   `json:"sourcetype,omitempty" binding:"max=255" example:"audit_log"`
+	Site       string   `json:"site,omitempty" binding:"omitempty,oneof=datadoghq.com us3.datadoghq.com us5.datadoghq.com datadoghq.eu ap1.datadoghq.com ap2.datadoghq.com ddog-gov.com" example:"datadoghq.com"`
+	Service    string   `json:"service,omitempty" binding:"max=255" example:"audit-log-api"`
+	Tags       []string `json:"tags,omitempty" binding:"max=50,dive,max=200" example:"env:prod"`
+	Source     string   `json:"source,omitempty" binding:"max=255" example:"audit-log-api"`
+}
+
+// IntegrationFilter selects the logs forwarded; empty criteria forward every
+// log. Action, resource_type, severity and tags take comma-separated values;
+// values prefixed with ! are excluded.
+type IntegrationFilter struct {
+	Action       string `json:"action,omitempty" example:"LOGIN,LOGOUT"`
+	ResourceType string `json:"resource_type,omitempty"`
+	Severity     string `json:"severity,omitempty" example:"!INFO"`
+	Tags         string `json:"tags,omitempty"`
+	// MinThreatScore is 0 to 100, 0 not filtering by threat score
+	MinThreatScore int `json:"min_threat_score,omitempty" binding:"min=0,max=100" example:"50"`
+}
+
+type TokenRequest struct {
+	Email    string `json:"email" binding:"required,email" example:"jane@example.com"`
+	Password string `json:"password" binding:"required" example:"correct-horse-battery"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// RevokeTokenRequest optionally names a refresh token to revoke along with the calling access token
+type RevokeTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// CreateAuditLogRequest is a log to store, written to schema_version of the
+// log schema; logs without one are version 1 and are upconverted to
+// domain.LogSchemaVersion. Version 2 requires correlation_id, which version 1
+// defaults to the request's X-Correlation-ID. Severity is one of the domain.SeverityLevels,
+// the message is bounded to 4KB and each JSON payload to 64KB, 10 levels of
+// nesting and 256 keys; the action is checked against the tenant's known
+// actions before binding.
 type CreateAuditLogRequest struct {
-	TenantID     string          `json:"tenant_id" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"`
-	UserID       string          `json:"user_id" example:"123456"`
-	SessionID    string          `json:"session_id" example:"sess_123456"`
-	IPAddress    string          `json:"ip_address" example:"192.168.1.1"`
-	UserAgent    string          `json:"user_agent" example:"Mozilla/5.0"`
-	Action       string          `json:"action" binding:"required" example:"CREATE"`
-	ResourceType string          `json:"resource_type" binding:"required" example:"user"`
-	ResourceID   string          `json:"resource_id" binding:"required" example:"user123"`
-	Severity     string          `json:"severity" binding:"required" example:"INFO"`
-	Message      string          `json:"message" binding:"required" example:"User created successfully"`
-	BeforeState  json.RawMessage `json:"before_state" swaggertype:"string" example:"{\\"name\\":\\"old name\\"}"`
-	AfterState   json.RawMessage `json:"after_state" swaggertype:"string" example:"{\\"name\\":\\"new name\\"}"`
-	Metadata     json.RawMessage `json:"metadata" swaggertype:"string" example:"{\\"key\\":\\"value\\"}"`
-	Timestamp    time.Time       `json:"timestamp" binding:"required" example:"2025-07-17T21:20:48Z"`
+	TenantID      string          `json:"tenant_id" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"`
+	UserID        string          `json:"user_id" example:"123456"`
+	SessionID     string          `json:"session_id" example:"sess_123456"`
+	SchemaVersion int             `json:"schema_version" binding:"omitempty,min=1,max=2" example:"2"`
+	CorrelationID string          `json:"correlation_id" binding:"required_if=SchemaVersion 2,max=128" example:"req-7f3c2a"`
+	IPAddress     string          `json:"ip_address" binding:"omitempty,ip" example:"192.168.1.1"`
+	UserAgent     string          `json:"user_agent" binding:"max=512" example:"Mozilla/5.0"`
+	Action        string          `json:"action" binding:"required,max=64" example:"CREATE"`
+	ResourceType  string          `json:"resource_type" binding:"required" example:"user"`
+	ResourceID    string          `json:"resource_id" binding:"required" example:"user123"`
+	Severity      string          `json:"severity" binding:"required,severity" example:"INFO"`
+	Message       string          `json:"message" binding:"required,max=4096" example:"User created successfully"`
+	BeforeState   json.RawMessage `json:"before_state" binding:"max=65536,json_depth=10,json_keys=256" swaggertype:"string" example:"{\\"name\\":\\"old name\\"}"`
+	AfterState    json.RawMessage `json:"after_state" binding:"max=65536,json_depth=10,json_keys=256" swaggertype:"string" example:"{\\"name\\":\\"new name\\"}"`
+	Metadata      json.RawMessage `json:"metadata" binding:"max=65536,json_depth=10,json_keys=256" swaggertype:"string" example:"{\\"key\\":\\"value\\"}"`
+	Timestamp     time.Time       `json:"timestamp" binding:"required" example:"2025-07-17T21:20:48Z"`
+}
+
+// BulkCreateAuditLogsRequest holds the array body of a bulk ingest, so that
+// validation errors name the index of the failing log, e.g. logs[3].severity
+type BulkCreateAuditLogsRequest struct {
+	Logs []CreateAuditLogRequest `json:"logs" binding:"dive"`
+}
+
+// ImportLogsRequest starts the import of a gzip-compressed NDJSON file of
+// historical logs already in S3
+type ImportLogsRequest struct {
+	SourceURI string `json:"source_uri" binding:"required,startswith=s3://" example:"s3://legacy-audit-exports/550e8400-e29b-41d4-a716-446655440000/2019.ndjson.gz"`
+}
+
+// ImportAuditLogRequest is a line of an import file: a log as it is ingested,
+// with the ID it had in the system it is migrated from. Its tenant is the
+// import's, whatever the line holds.
+type ImportAuditLogRequest struct {
+	ID string `json:"id" binding:"omitempty,uuid" example:"550e8400-e29b-41d4-a716-446655440000"`
+	CreateAuditLogRequest
+}
+
+// BatchGetLogsRequest names up to 100 logs to fetch in one round trip. With
+// exists_only, only the IDs found and missing are returned.
+type BatchGetLogsRequest struct {
+	IDs        []string `json:"ids" binding:"required,min=1,max=100,dive,uuid" example:"550e8400-e29b-41d4-a716-446655440000"`
+	ExistsOnly bool     `json:"exists_only" example:"false"`
+}
+
+// ReprocessIndexFailuresRequest names the index failures to reindex; the
+// tenant's most recent ones are reindexed when IDs is empty
+type ReprocessIndexFailuresRequest struct {
+	IDs []string `json:"ids" binding:"max=500,dive,uuid" example:"550e8400-e29b-41d4-a716-446655440000"`
+}
+
+// UpdateDBPoolRequest resizes a database connection pool of the API instance;
+// settings left out keep their value
+type UpdateDBPoolRequest struct {
+	MaxOpenConns *int `json:"max_open_conns" binding:"omitempty,min=1" example:"100"`
+	MaxIdleConns *int `json:"max_idle_conns" binding:"omitempty,min=0" example:"20"`
+	// ConnMaxLifetime is a duration such as 30m; 0 keeps connections forever
+	ConnMaxLifetime string `json:"conn_max_lifetime,omitempty" example:"30m"`
 }
@@ -13,23 +13,454 @@ type CreateTenantResponse struct {
 	UpdatedAt time.Time `json:"updated_at" example:"2025-07-17T21:20:48Z"`
 }
 
+// TenantDeletionResponse represents a deleted tenant, which can be restored until purge_after
+type TenantDeletionResponse struct {
+	ID         string    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name       string    `json:"name" example:"My Tenant"`
+	DeletedAt  time.Time `json:"deleted_at" example:"2025-07-17T21:20:48Z"`
+	PurgeAfter time.Time `json:"purge_after" example:"2025-08-16T21:20:48Z"`
+}
+
+// TenantRateLimitResponse represents a tenant's per-minute rate limit
+type TenantRateLimitResponse struct {
+	TenantID  string `json:"tenant_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	RateLimit int    `json:"rate_limit" example:"1000"`
+	Burst     int    `json:"burst" example:"200"`
+}
+
+// TenantSettingsResponse represents a tenant's self-service settings. Webhook
+// secrets are masked down to their last four characters.
+type TenantSettingsResponse struct {
+	TenantID            string                 `json:"tenant_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	RetentionDays       int                    `json:"retention_days" example:"90"`
+	RateLimit           int                    `json:"rate_limit" example:"1000"`
+	RateLimitBurst      int                    `json:"rate_limit_burst" example:"200"`
+	AllowedActions      []string               `json:"allowed_actions" example:"CREATE,UPDATE,DELETE"`
+	CustomActions       []string               `json:"custom_actions" example:"LOGIN,EXPORT"`
+	WebhookURL          string                 `json:"webhook_url" example:"https://hooks.example.com/audit"`
+	WebhookSecrets      []string               `json:"webhook_secrets" example:"********4f60"`
+	DataResidencyRegion string                 `json:"data_residency_region" example:"eu-west-1"`
+	LogVisibility       string                 `json:"log_visibility" example:"own"`
+	SamplingRules       []SamplingRuleResponse `json:"sampling_rules"`
+	UserRateLimits      map[string]int         `json:"user_rate_limits"`
+	IndexedMetadataKeys []string               `json:"indexed_metadata_keys" example:"order_id,region"`
+	ArchiveStorage      ArchiveStorageResponse `json:"archive_storage"`
+	Encryption          EncryptionResponse     `json:"encryption"`
+	UpdatedAt           time.Time              `json:"updated_at" example:"2025-07-17T21:20:48Z"`
+}
+
+// ArchiveStorageResponse represents the tenant's archive storage overrides;
+// empty strings and 0 use the defaults
+type ArchiveStorageResponse struct {
+	StorageClass     string `json:"storage_class" example:"STANDARD_IA"`
+	GlacierAfterDays int    `json:"glacier_after_days" example:"90"`
+	ObjectLockMode   string `json:"object_lock_mode" example:"COMPLIANCE"`
+	ObjectLockDays   int    `json:"object_lock_days" example:"2555"`
+}
+
+// EncryptionResponse represents the keys the tenant's data is encrypted with
+// in S3; an empty kms_key_arn uses the default key
+type EncryptionResponse struct {
+	KMSKeyARN       string `json:"kms_key_arn" example:"arn:aws:kms:eu-west-1:111122223333:key/1234abcd-12ab-34cd-56ef-1234567890ab"`
+	ExportPublicKey string `json:"export_public_key" example:"-----BEGIN PUBLIC KEY-----\nMIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8AMIIBCgKCAQEA...\n-----END PUBLIC KEY-----\n"`
+}
+
+// SamplingRuleResponse represents a rule keeping a share of a tenant's logs
+type SamplingRuleResponse struct {
+	Actions    []string `json:"actions" example:"VIEW"`
+	Severities []string `json:"severities" example:"INFO"`
+	Rate       float64  `json:"rate" example:"0.01"`
+}
+
+// ArchiveScheduleResponse represents a tenant's scheduled archival. Zero
+// interval_hours uses the scheduler's interval and zero retention_days the
+// tenant's retention setting.
+type ArchiveScheduleResponse struct {
+	TenantID      string     `json:"tenant_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Enabled       bool       `json:"enabled" example:"true"`
+	IntervalHours int        `json:"interval_hours" example:"24"`
+	RetentionDays int        `json:"retention_days" example:"90"`
+	LastRunAt     *time.Time `json:"last_run_at,omitempty" example:"2025-07-17T02:00:00Z"`
+	LastJobID     *string    `json:"last_job_id,omitempty" example:"6ba7b810-9dad-11d1-80b4-00c04fd430c8"`
+}
+
+// UsagePeriodResponse is the ingestion volume of a day, e.g. "2025-07-17", or of a month, e.g. "2025-07"
+type UsagePeriodResponse struct {
+	Period string `json:"period" example:"2025-07-17"`
+	Logs   int64  `json:"logs" example:"12000"`
+	Bytes  int64  `json:"bytes" example:"7340032"`
+}
+
+// TenantQuotaResponse represents the ingestion quotas of a tenant; 0 is unlimited
+type TenantQuotaResponse struct {
+	DailyLogs    int64 `json:"daily_logs" example:"100000"`
+	MonthlyLogs  int64 `json:"monthly_logs" example:"2000000"`
+	MonthlyBytes int64 `json:"monthly_bytes" example:"10737418240"`
+}
+
+// TenantUsageResponse represents a tenant's ingestion volume by UTC day and month
+type TenantUsageResponse struct {
+	TenantID string                `json:"tenant_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	From     string                `json:"from" example:"2025-06-01"`
+	To       string                `json:"to" example:"2025-07-17"`
+	Daily    []UsagePeriodResponse `json:"daily"`
+	Monthly  []UsagePeriodResponse `json:"monthly"`
+	Quota    TenantQuotaResponse   `json:"quota"`
+}
+
+// PolicyResponse represents a tenant policy
+type PolicyResponse struct {
+	ID        string    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TenantID  string    `json:"tenant_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Role      string    `json:"role" example:"user"`
+	Resource  string    `json:"resource" example:"logs"`
+	Action    string    `json:"action" example:"read"`
+	Effect    string    `json:"effect" example:"allow"`
+	Scope     string    `json:"scope" example:"own"`
+	CreatedAt time.Time `json:"created_at" example:"2025-07-17T21:20:48Z"`
+	UpdatedAt time.Time `json:"updated_at" example:"2025-07-17T21:20:48Z"`
+}
+
+// RedactionRuleResponse represents a tenant redaction rule
+type RedactionRuleResponse struct {
+	ID        string    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TenantID  string    `json:"tenant_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Target    string    `json:"target" example:"after_state"`
+	Path      string    `json:"path" example:"customer.email"`
+	Mask      string    `json:"mask" example:"email"`
+	CreatedAt time.Time `json:"created_at" example:"2025-07-17T21:20:48Z"`
+	UpdatedAt time.Time `json:"updated_at" example:"2025-07-17T21:20:48Z"`
+}
+
+// TaggingRuleResponse represents a tenant tagging rule
+type TaggingRuleResponse struct {
+	ID             string    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TenantID       string    `json:"tenant_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Tag            string    `json:"tag" example:"privilege-change"`
+	Actions        []string  `json:"actions,omitempty" example:"UPDATE"`
+	ResourceTypes  []string  `json:"resource_types,omitempty" example:"role"`
+	Severities     []string  `json:"severities,omitempty" example:"WARNING"`
+	MessagePattern string    `json:"message_pattern,omitempty" example:"(?i)granted .*admin"`
+	CreatedAt      time.Time `json:"created_at" example:"2025-07-17T21:20:48Z"`
+	UpdatedAt      time.Time `json:"updated_at" example:"2025-07-17T21:20:48Z"`
+}
+
+// ResourceSchemaResponse represents the JSON Schemas of a tenant's logs of a resource type
+type ResourceSchemaResponse struct {
+	ID             string          `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TenantID       string          `json:"tenant_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	ResourceType   string          `json:"resource_type" example:"user"`
+	MetadataSchema json.RawMessage `json:"metadata_schema,omitempty" swaggertype:"object"`
+	StateSchema    json.RawMessage `json:"state_schema,omitempty" swaggertype:"object"`
+	Mode           string          `json:"mode" example:"reject"`
+	CreatedAt      time.Time       `json:"created_at" example:"2025-07-17T21:20:48Z"`
+	UpdatedAt      time.Time       `json:"updated_at" example:"2025-07-17T21:20:48Z"`
+}
+
+// SavedSearchResponse represents a saved search
+type SavedSearchResponse struct {
+	ID          string            `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TenantID    string            `json:"tenant_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	OwnerID     string            `json:"owner_id" example:"user123"`
+	Name        string            `json:"name" example:"Failed logins"`
+	Description string            `json:"description" example:"Login failures in the last day"`
+	Filter      SavedSearchFilter `json:"filter"`
+	Shared      bool              `json:"shared" example:"true"`
+	CreatedAt   time.Time         `json:"created_at" example:"2025-07-17T21:20:48Z"`
+	UpdatedAt   time.Time         `json:"updated_at" example:"2025-07-17T21:20:48Z"`
+}
+
+// IntegrationResponse represents a log forwarding integration with its
+// delivery status. The token is masked down to its last four characters.
+// forwarded_through is when the last log forwarded was stored; failures
+// counts the consecutive failed deliveries, retried at next_attempt_at.
+type IntegrationResponse struct {
+	ID               string              `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TenantID         string              `json:"tenant_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name             string              `json:"name" example:"Splunk SIEM"`
+	Type             string              `json:"type" example:"splunk"`
+	Enabled          bool                `json:"enabled" example:"true"`
+	Token            string              `json:"token" example:"********0a73"`
+	Settings         IntegrationSettings `json:"settings"`
+	Filter           IntegrationFilter   `json:"filter"`
+	ForwardedCount   int64               `json:"forwarded_count" example:"125000"`
+	ForwardedThrough time.Time           `json:"forwarded_through" example:"2025-07-17T21:20:48Z"`
+	LastForwardedAt  *time.Time          `json:"last_forwarded_at,omitempty" example:"2025-07-17T21:20:48Z"`
+	Failures         int                 `json:"failures" example:"0"`
+	LastError        string              `json:"last_error,omitempty" example:"splunk responded with status 503"`
+	NextAttemptAt    *time.Time          `json:"next_attempt_at,omitempty"`
+	CreatedAt        time.Time           `json:"created_at" example:"2025-07-17T21:20:48Z"`
+	UpdatedAt        time.Time           `json:"updated_at" example:"2025-07-17T21:20:48Z"`
+}
+
+// TokenResponse holds a newly issued access and refresh token pair
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type" example:"Bearer"`
+	ExpiresIn    int64  `json:"expires_in" example:"900"`
+}
+
+// StreamTicketResponse holds a one-time ticket for opening a log stream
+type StreamTicketResponse struct {
+	Ticket    string `json:"ticket"`
+	ExpiresIn int64  `json:"expires_in" example:"30"`
+}
+
+// UserResponse represents a tenant user
+type UserResponse struct {
+	ID        string          `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TenantID  string          `json:"tenant_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Email     string          `json:"email" example:"jane@example.com"`
+	Name      string          `json:"name" example:"Jane Doe"`
+	Roles     []string        `json:"roles" example:"user,auditor"`
+	Active    bool            `json:"active" example:"true"`
+	Metadata  json.RawMessage `json:"metadata,omitempty" swaggertype:"string" example:"{\\"team\\":\\"security\\"}"`
+	CreatedAt time.Time       `json:"created_at" example:"2025-07-17T21:20:48Z"`
+	UpdatedAt time.Time       `json:"updated_at" example:"2025-07-17T21:20:48Z"`
+}
+
 // AuditLogResponse represents a single audit log entry in the response
 type AuditLogResponse struct {
-	ID           string          `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	TenantID     string          `json:"tenant_id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	UserID       string          `json:"user_id" example:"123456"`
-	SessionID    string          `json:"session_id" example:"sess_123456"`
-	IPAddress    string          `json:"ip_address" example:"192.168.1.1"`
-	UserAgent    string          `json:"user_agent" example:"Mozilla/5.0"`
-	Action       string          `json:"action" example:"CREATE"`
-	ResourceType string          `json:"resource_type" example:"user"`
-	ResourceID   string          `json:"resource_id" example:"user123"`
-	Severity     string          `json:"severity" example:"INFO"`
-	Message      string          `json:"message" example:"User created successfully"`
-	BeforeState  json.RawMessage `json:"before_state,omitempty" swaggertype:"string" example:"{\\"name\\":\\"old name\\"}"`
-	AfterState   json.RawMessage `json:"after_state,omitempty" swaggertype:"string" example:"{\\"name\\":\\"new name\\"}"`
-	Metadata     json.RawMessage `json:"metadata,omitempty" swaggertype:"string" example:"{\\"key\\":\\"value\\"}"`
-	Timestamp    time.Time       `json:"timestamp" example:"2025-07-17T21:20:48Z"`
+	ID            string          `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TenantID      string          `json:"tenant_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	UserID        string          `json:"user_id" example:"123456"`
+	SessionID     string          `json:"session_id" example:"sess_123456"`
+	CorrelationID string          `json:"correlation_id,omitempty" example:"req-7f3c2a"`
+	IPAddress     string          `json:"ip_address" example:"192.168.1.1"`
+	UserAgent     string          `json:"user_agent" example:"Mozilla/5.0"`
+	Action        string          `json:"action" example:"CREATE"`
+	ResourceType  string          `json:"resource_type" example:"user"`
+	ResourceID    string          `json:"resource_id" example:"user123"`
+	Severity      string          `json:"severity" example:"INFO"`
+	Message       string          `json:"message" example:"User created successfully"`
+	BeforeState   json.RawMessage `json:"before_state,omitempty" swaggertype:"string" example:"{\\"name\\":\\"old name\\"}"`
+	AfterState    json.RawMessage `json:"after_state,omitempty" swaggertype:"string" example:"{\\"name\\":\\"new name\\"}"`
+	Metadata      json.RawMessage `json:"metadata,omitempty" swaggertype:"string" example:"{\\"key\\":\\"value\\"}"`
+	SchemaErrors  []string        `json:"schema_errors,omitempty" example:"metadata.region: value must be one of 'eu', 'us'"`
+	SchemaVersion int             `json:"schema_version" example:"2"`
+	Tags          []string        `json:"tags,omitempty" example:"authentication"`
+	Timestamp     time.Time       `json:"timestamp" example:"2025-07-17T21:20:48Z"`
+	// Highlights holds the fragments matching the q full-text query, keyed by field
+	Highlights map[string][]string `json:"highlights,omitempty"`
+}
+
+// ExportJobResponse represents the state of an asynchronous export job.
+// Encrypted downloads are encrypted for the tenant's export public key; the
+// signature of a signed job's download is given once it has completed.
+type ExportJobResponse struct {
+	ID          string                   `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Status      string                   `json:"status" example:"COMPLETED"`
+	Scope       string                   `json:"scope" example:"logs"`
+	Format      string                   `json:"format" example:"csv"`
+	RowCount    int64                    `json:"row_count" example:"125000"`
+	Error       string                   `json:"error,omitempty" example:""`
+	DownloadURL string                   `json:"download_url,omitempty" example:"https://audit-log-exports.s3.amazonaws.com/exports/..."`
+	Encrypted   bool                     `json:"encrypted" example:"false"`
+	CreatedAt   time.Time                `json:"created_at" example:"2025-07-17T21:20:48Z"`
+	CompletedAt *time.Time               `json:"completed_at,omitempty" example:"2025-07-17T21:25:13Z"`
+	Signature   *ExportSignatureResponse `json:"signature,omitempty"`
+}
+
+// SigningKeyResponse represents a public key export files are signed with,
+// PEM-encoded; key is server for the key shared by every tenant or tenant for
+// the tenant's own
+type SigningKeyResponse struct {
+	KeyID     string `json:"key_id" example:"3f9a1c7e2b8d4f60"`
+	Key       string `json:"key" example:"tenant"`
+	Algorithm string `json:"algorithm" example:"Ed25519"`
+	PublicKey string `json:"public_key" example:"-----BEGIN PUBLIC KEY-----\nMCowBQYDK2VwAyEAGb9ECWmEzf6FQbrBZ9w7lshQhqowtrbLDFw4rXAxZuE=\n-----END PUBLIC KEY-----\n"`
+}
+
+// ExportSignatureResponse represents the detached signature of an export file:
+// the Ed25519 signature, base64-encoded, of the raw SHA-256 digest of the file
+// as downloaded, with the public key verifying it
+type ExportSignatureResponse struct {
+	JobID string `json:"job_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	SigningKeyResponse
+	SHA256    string    `json:"sha256" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	Signature string    `json:"signature" example:"p8cS3bD0...=="`
+	SignedAt  time.Time `json:"signed_at" example:"2025-07-17T21:25:13Z"`
+}
+
+// IndexFailureResponse represents a log OpenSearch rejected; the log itself is
+// served by GET /logs/{id}
+type IndexFailureResponse struct {
+	ID        string    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	LogID     string    `json:"log_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	IndexName string    `json:"index_name" example:"audit_logs_550e8400-e29b-41d4-a716-446655440000_2025_07_17"`
+	Status    int       `json:"status" example:"400"`
+	ErrorType string    `json:"error_type,omitempty" example:"mapper_parsing_exception"`
+	Reason    string    `json:"reason,omitempty" example:"failed to parse field [metadata.amount] of type [long]"`
+	FailedAt  time.Time `json:"failed_at" example:"2025-07-17T21:20:48Z"`
+}
+
+// ReprocessIndexFailuresResponse counts the failed logs queued for reindexing
+type ReprocessIndexFailuresResponse struct {
+	Reprocessed int `json:"reprocessed" example:"12"`
+}
+
+// SearchIndexResponse describes one of a tenant's daily OpenSearch indices
+type SearchIndexResponse struct {
+	Name      string `json:"name" example:"audit_logs_550e8400-e29b-41d4-a716-446655440000_2025_07_17"`
+	Day       string `json:"day" example:"2025-07-17"`
+	Docs      int64  `json:"docs" example:"48000"`
+	SizeBytes int64  `json:"size_bytes" example:"10485760"`
+	Health    string `json:"health" example:"green"`
+}
+
+// DBPoolResponse describes a database connection pool of the API instance:
+// its limits and the statistics of its connections
+type DBPoolResponse struct {
+	Name            string `json:"name" example:"writer"`
+	MaxOpenConns    int    `json:"max_open_conns" example:"50"`
+	MaxIdleConns    int    `json:"max_idle_conns" example:"10"`
+	ConnMaxLifetime string `json:"conn_max_lifetime" example:"1h0m0s"`
+	OpenConnections int    `json:"open_connections" example:"12"`
+	InUse           int    `json:"in_use" example:"9"`
+	Idle            int    `json:"idle" example:"3"`
+	// WaitCount and WaitDuration are the waits for a free connection since startup
+	WaitCount         int64  `json:"wait_count" example:"42"`
+	WaitDuration      string `json:"wait_duration" example:"1.2s"`
+	MaxIdleClosed     int64  `json:"max_idle_closed" example:"7"`
+	MaxLifetimeClosed int64  `json:"max_lifetime_closed" example:"3"`
+}
+
+// EnsureIndicesResponse lists the days indices were created for and counts
+// the days that already had one
+type EnsureIndicesResponse struct {
+	Created  []string `json:"created" example:"2025-07-18"`
+	Existing int      `json:"existing" example:"1"`
+}
+
+// ReindexResponse counts the logs queued for indexing
+type ReindexResponse struct {
+	Queued int64 `json:"queued" example:"48000"`
+}
+
+// RestoreJobResponse represents the state of an archive restore job
+type RestoreJobResponse struct {
+	ID               string     `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Status           string     `json:"status" example:"RUNNING"`
+	StartTime        time.Time  `json:"start_time" example:"2024-01-01T00:00:00Z"`
+	EndTime          time.Time  `json:"end_time" example:"2024-03-31T23:59:59Z"`
+	ObjectsProcessed int        `json:"objects_processed" example:"3"`
+	RestoredCount    int64      `json:"restored_count" example:"48000"`
+	Error            string     `json:"error,omitempty" example:""`
+	CreatedAt        time.Time  `json:"created_at" example:"2025-07-17T21:20:48Z"`
+	CompletedAt      *time.Time `json:"completed_at,omitempty" example:"2025-07-17T21:25:13Z"`
+}
+
+// ImportJobResponse represents the state of a bulk import job. Progress is
+// the share of the compressed file read so far, in percent; imported logs
+// were stored, skipped ones already existed and invalid lines were rejected
+// for the reasons in line_errors, of which the first 100 are kept.
+type ImportJobResponse struct {
+	ID            string                    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Status        string                    `json:"status" example:"RUNNING"`
+	SourceURI     string                    `json:"source_uri" example:"s3://legacy-audit-exports/550e8400-e29b-41d4-a716-446655440000/2019.ndjson.gz"`
+	SizeBytes     int64                     `json:"size_bytes" example:"734003200"`
+	BytesRead     int64                     `json:"bytes_read" example:"367001600"`
+	Progress      float64                   `json:"progress" example:"50"`
+	LinesRead     int64                     `json:"lines_read" example:"2500000"`
+	ImportedCount int64                     `json:"imported_count" example:"2499800"`
+	SkippedCount  int64                     `json:"skipped_count" example:"150"`
+	InvalidCount  int64                     `json:"invalid_count" example:"50"`
+	LineErrors    []ImportLineErrorResponse `json:"line_errors,omitempty"`
+	Error         string                    `json:"error,omitempty" example:""`
+	CreatedAt     time.Time                 `json:"created_at" example:"2025-07-17T21:20:48Z"`
+	CompletedAt   *time.Time                `json:"completed_at,omitempty" example:"2025-07-17T21:45:13Z"`
+}
+
+// ImportLineErrorResponse is why a line of an import file was rejected
+type ImportLineErrorResponse struct {
+	Line  int64  `json:"line" example:"1042"`
+	Error string `json:"error" example:"severity: failed on the 'severity' tag"`
+}
+
+// JobResponse represents the state of a background export, restore or cleanup job
+type JobResponse struct {
+	ID          string           `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Type        string           `json:"type" example:"cleanup"`
+	Status      string           `json:"status" example:"COMPLETED"`
+	Counts      map[string]int64 `json:"counts"`
+	Error       string           `json:"error,omitempty" example:""`
+	CreatedAt   time.Time        `json:"created_at" example:"2025-07-17T21:20:48Z"`
+	UpdatedAt   time.Time        `json:"updated_at" example:"2025-07-17T21:25:13Z"`
+	CompletedAt *time.Time       `json:"completed_at,omitempty" example:"2025-07-17T21:25:13Z"`
+}
+
+// LogPageResponse is the /api/v2 envelope of a page of logs. Data holds
+// only the selected fields of each log when fields are selected.
+type LogPageResponse struct {
+	Data       any                `json:"data" swaggertype:"array,object"`
+	Pagination PaginationResponse `json:"pagination"`
+	// Degraded is true when OpenSearch failed and the search was served by
+	// PostgreSQL, without highlights
+	Degraded bool `json:"degraded,omitempty" example:"false"`
+}
+
+// PaginationResponse tells how to fetch the page after the current one
+type PaginationResponse struct {
+	PageSize int `json:"page_size" example:"10"`
+	// NextCursor is passed as cursor to fetch the next page; it is empty on
+	// the last page
+	NextCursor string `json:"next_cursor,omitempty" example:"eyJwYWdlIjoyfQ"`
+	HasMore    bool   `json:"has_more" example:"true"`
+}
+
+// StatsEnvelopeResponse is the /api/v2 envelope of log stats
+type StatsEnvelopeResponse struct {
+	Data *GetAuditLogStatsResponse `json:"data"`
+}
+
+// AcceptedLogsResponse lists the IDs logs accepted with ?async=true are stored
+// under, in request order
+type AcceptedLogsResponse struct {
+	Message string   `json:"message" example:"Logs accepted"`
+	IDs     []string `json:"ids" example:"550e8400-e29b-41d4-a716-446655440000"`
+}
+
+// ShipperIngestResponse counts the records of a log shipper's batch that
+// were stored and those that were dropped, with the reasons of the first
+// ones dropped
+type ShipperIngestResponse struct {
+	Accepted int                  `json:"accepted" example:"98"`
+	Rejected int                  `json:"rejected" example:"2"`
+	Errors   []ShipperRecordError `json:"errors,omitempty"`
+}
+
+// ShipperRecordError is why a record, by its index in the batch, was dropped:
+// a timestamp in no known format or fields of its log failing validation
+type ShipperRecordError struct {
+	Record int          `json:"record" example:"3"`
+	Error  string       `json:"error,omitempty" example:"Log validation failed"`
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// BatchGetLogsResponse holds the requested logs that were found, in request
+// order, and the IDs of those that weren't
+type BatchGetLogsResponse struct {
+	Logs    []AuditLogResponse `json:"logs,omitempty"`
+	Found   []string           `json:"found" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Missing []string           `json:"missing" example:"6ba7b810-9dad-11d1-80b4-00c04fd430c8"`
+}
+
+// AuditLogDiffResponse lists the differences between a log's before_state and after_state
+type AuditLogDiffResponse struct {
+	ID      string        `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Changes []StateChange `json:"changes"`
+	// Unified is a unified text diff of the indented states, only returned on request
+	Unified string `json:"unified,omitempty" example:"--- before_state\n+++ after_state\n@@ -1,3 +1,3 @@\n {\n-  \"name\": \"old name\"\n+  \"name\": \"new name\"\n }\n"`
+}
+
+// StateChange is a value added, removed or changed at a dot-separated path, with
+// array elements indexed as in "items[0].price"
+type StateChange struct {
+	Path   string `json:"path" example:"name"`
+	Op     string `json:"op" enums:"added,removed,changed" example:"changed"`
+	Before any    `json:"before" swaggertype:"string" example:"old name"`
+	After  any    `json:"after" swaggertype:"string" example:"new name"`
 }
 
 // GetAuditLogStatsResponse represents statistics about audit logs
@@ -38,4 +469,120 @@ type GetAuditLogStatsResponse struct {
 	ActionCounts   map[string]int64 `json:"action_counts" example:"CREATE:50,UPDATE:30,DELETE:20"`
 	SeverityCounts map[string]int64 `json:"severity_counts" example:"INFO:80,WARNING:15,ERROR:5"`
 	ResourceCounts map[string]int64 `json:"resource_counts" example:"user:60,order:40"`
+	// Interval and Series are present when the stats are served by OpenSearch
+	Interval string                `json:"interval,omitempty" example:"1h0m0s"`
+	Series   []StatsBucketResponse `json:"series,omitempty"`
+	// SampledOut and EstimatedTotalLogs are present when the tenant's sampling
+	// rules dropped logs on the UTC days of the range
+	SampledOut         *SampledLogsResponse `json:"sampled_out,omitempty"`
+	EstimatedTotalLogs int64                `json:"estimated_total_logs,omitempty" example:"1900"`
+}
+
+// SampledLogsResponse counts the logs dropped at ingest by sampling rules,
+// which the stats don't include
+type SampledLogsResponse struct {
+	TotalLogs      int64            `json:"total_logs" example:"1800"`
+	ActionCounts   map[string]int64 `json:"action_counts" example:"VIEW:1800"`
+	SeverityCounts map[string]int64 `json:"severity_counts" example:"INFO:1800"`
+}
+
+// TopStatsResponse ranks the most frequent users, IP addresses, resources and
+// sessions of the logs in a range, and counts distinct users and IP addresses.
+// Unique counts are approximate.
+type TopStatsResponse struct {
+	TotalLogs         int64                       `json:"total_logs" example:"1000"`
+	Users             []TopValueResponse          `json:"users"`
+	IPAddresses       []TopValueResponse          `json:"ip_addresses"`
+	Resources         []TopValueResponse          `json:"resources"`
+	Sessions          []TopValueResponse          `json:"sessions"`
+	UniqueUsers       int64                       `json:"unique_users" example:"42"`
+	UniqueIPAddresses int64                       `json:"unique_ip_addresses" example:"57"`
+	Daily             []DailyUniqueCountsResponse `json:"daily"`
+}
+
+// TopValueResponse is a value and the number and share of the logs holding it
+type TopValueResponse struct {
+	Value string `json:"value" example:"user123"`
+	// ResourceType is set for resources
+	ResourceType string `json:"resource_type,omitempty" example:"order"`
+	Count        int64  `json:"count" example:"250"`
+	// Percentage is the share of the range's logs, out of 100
+	Percentage float64 `json:"percentage" example:"25"`
+}
+
+// DailyUniqueCountsResponse counts the distinct users and IP addresses of a
+// UTC day
+type DailyUniqueCountsResponse struct {
+	Day               string `json:"day" example:"2025-07-17"`
+	UniqueUsers       int64  `json:"unique_users" example:"12"`
+	UniqueIPAddresses int64  `json:"unique_ip_addresses" example:"15"`
+}
+
+// StatsBucketResponse counts the logs in the interval starting at Start
+type StatsBucketResponse struct {
+	Start time.Time `json:"start" example:"2025-07-17T21:00:00Z"`
+	Count int64     `json:"count" example:"42"`
+}
+
+// ArchiveResponse describes an archive in S3 with the SHA-256 checksums it was
+// written with
+type ArchiveResponse struct {
+	ID             string    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	BeforeDate     time.Time `json:"before_date" example:"2025-06-01T00:00:00Z"`
+	ManifestKey    string    `json:"manifest_key" example:"audit-logs/550e8400-e29b-41d4-a716-446655440000/before_2025-06-01_00-00-00/manifest.json"`
+	ManifestSHA256 string    `json:"manifest_sha256" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	LogCount       int64     `json:"log_count" example:"250000"`
+	// Size is the total size of the compressed parts in bytes
+	Size         int64                 `json:"size" example:"31457280"`
+	Parts        []ArchivePartResponse `json:"parts"`
+	StorageClass string                `json:"storage_class,omitempty" example:"STANDARD_IA"`
+	RetainUntil  *time.Time            `json:"retain_until,omitempty" example:"2032-06-01T00:00:00Z"`
+	KMSKeyID     string                `json:"kms_key_id,omitempty" example:"arn:aws:kms:eu-west-1:111122223333:key/1234abcd-12ab-34cd-56ef-1234567890ab"`
+	ArchivedAt   time.Time             `json:"archived_at" example:"2025-06-01T02:00:00Z"`
+	// VerificationStatus is the outcome of the last verification, if any
+	VerificationStatus string     `json:"verification_status,omitempty" example:"verified"`
+	LastVerifiedAt     *time.Time `json:"last_verified_at,omitempty" example:"2025-07-17T21:20:48Z"`
+}
+
+// ArchivePartResponse describes one compressed NDJSON object of an archive
+type ArchivePartResponse struct {
+	Key            string    `json:"key" example:"audit-logs/550e8400-e29b-41d4-a716-446655440000/before_2025-06-01_00-00-00/part-00001.ndjson.gz"`
+	LogCount       int64     `json:"log_count" example:"250000"`
+	FirstTimestamp time.Time `json:"first_timestamp" example:"2025-05-01T00:00:03Z"`
+	LastTimestamp  time.Time `json:"last_timestamp" example:"2025-05-31T23:59:58Z"`
+	Size           int64     `json:"size" example:"31457280"`
+	SHA256         string    `json:"sha256" example:"60303ae22b998861bce3b28f33eec1be758a213c86c93c076dbe9f558c11c752"`
+}
+
+// ArchiveVerificationResponse reports the integrity of an archive's objects,
+// re-hashed from S3
+type ArchiveVerificationResponse struct {
+	ArchiveID string `json:"archive_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// Status is verified when every object matches its checksum, corrupted
+	// when any is missing or doesn't match, and unavailable when objects in
+	// Glacier couldn't be read
+	Status     string                         `json:"status" example:"verified"`
+	VerifiedAt time.Time                      `json:"verified_at" example:"2025-07-17T21:20:48Z"`
+	Objects    []ArchiveObjectIntegrityResult `json:"objects"`
+}
+
+// ArchiveObjectIntegrityResult is the verification of one archive object
+type ArchiveObjectIntegrityResult struct {
+	Key string `json:"key" example:"audit-logs/550e8400-e29b-41d4-a716-446655440000/before_2025-06-01_00-00-00/part-00001.ndjson.gz"`
+	// Status is ok, mismatch, missing or unavailable
+	Status         string `json:"status" example:"ok"`
+	ExpectedSHA256 string `json:"expected_sha256" example:"60303ae22b998861bce3b28f33eec1be758a213c86c93c076dbe9f558c11c752"`
+	ActualSHA256   string `json:"actual_sha256,omitempty" example:"60303ae22b998861bce3b28f33eec1be758a213c86c93c076dbe9f558c11c752"`
+}
+
+// ArchiveSearchResponse holds the archived logs an archive search found in S3
+type ArchiveSearchResponse struct {
+	Logs []AuditLogResponse `json:"logs"`
+	// PartsScanned is the number of archive parts queried
+	PartsScanned int `json:"parts_scanned" example:"3"`
+	// UnavailableParts are the keys of parts that are missing or in Glacier,
+	// which weren't searched
+	UnavailableParts []string `json:"unavailable_parts"`
+	// Truncated is true when the limit was reached, so more logs may match
+	Truncated bool `json:"truncated" example:"false"`
 }
@@ -0,0 +1,30 @@
+package dto
+
+import "github.com/kingrain94/audit-log-api/internal/domain"
+
+// logUpconverters bring a log from each older schema version to the next: the
+// one at index i turns version i+1 into version i+2. Add one with each bump
+// of domain.LogSchemaVersion, so producers of older versions keep working.
+var logUpconverters = []func(r *CreateAuditLogRequest, correlationID string){
+	upconvertV1,
+}
+
+// upconvertV1 defaults the correlation_id, which version 2 requires, to the
+// request's chain ID
+func upconvertV1(r *CreateAuditLogRequest, correlationID string) {
+	if r.CorrelationID == "" {
+		r.CorrelationID = correlationID
+	}
+}
+
+// Upconvert brings a log of an older schema version up to
+// domain.LogSchemaVersion, one version at a time. correlationID is the chain
+// ID of the request that sent the log, which versions before 2 default to.
+func (r *CreateAuditLogRequest) Upconvert(correlationID string) {
+	if r.SchemaVersion == 0 {
+		r.SchemaVersion = 1
+	}
+	for ; r.SchemaVersion < domain.LogSchemaVersion; r.SchemaVersion++ {
+		logUpconverters[r.SchemaVersion-1](r, correlationID)
+	}
+}
@@ -0,0 +1,89 @@
+package dto
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+// RegisterValidators adds the custom binding rules of the request DTOs to v
+// and makes validation errors name fields by their JSON name
+func RegisterValidators(v *validator.Validate) error {
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	return errors.Join(
+		v.RegisterValidation("severity", func(fl validator.FieldLevel) bool {
+			return domain.IsSeverityLevel(fl.Field().String())
+		}),
+		v.RegisterValidation("tag", func(fl validator.FieldLevel) bool {
+			return domain.IsTag(fl.Field().String())
+		}),
+		// json_depth and json_keys bound the nesting depth and object keys of
+		// a JSON document, since every key of a stored payload becomes a field
+		// of the search index mapping
+		v.RegisterValidation("json_depth", func(fl validator.FieldLevel) bool {
+			depth, _ := jsonShape(fl.Field().Bytes())
+			return depth <= paramInt(fl)
+		}),
+		v.RegisterValidation("json_keys", func(fl validator.FieldLevel) bool {
+			_, keys := jsonShape(fl.Field().Bytes())
+			return keys <= paramInt(fl)
+		}),
+	)
+}
+
+func paramInt(fl validator.FieldLevel) int {
+	n, err := strconv.Atoi(fl.Param())
+	if err != nil {
+		panic("dto: invalid validation param " + fl.Param())
+	}
+	return n
+}
+
+// jsonShape returns the nesting depth of the objects and arrays of a JSON
+// document and the number of keys of all its objects. Syntax errors are left
+// to the JSON decoding of the request, so the shape up to the error is returned.
+func jsonShape(doc []byte) (depth, keys int) {
+	type container struct {
+		object bool
+		tokens int
+	}
+	var stack []container
+
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return depth, keys
+		}
+		delim, isDelim := token.(json.Delim)
+		if isDelim && (delim == '}' || delim == ']') {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		// Keys and values of an object alternate, so keys are its even tokens
+		if n := len(stack); n > 0 && stack[n-1].object {
+			if stack[n-1].tokens%2 == 0 {
+				keys++
+			}
+			stack[n-1].tokens++
+		}
+		if isDelim {
+			stack = append(stack, container{object: delim == '{'})
+			depth = max(depth, len(stack))
+		}
+	}
+}
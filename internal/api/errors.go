@@ -0,0 +1,251 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/middleware"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	"github.com/kingrain94/audit-log-api/internal/utils"
+	"github.com/kingrain94/audit-log-api/pkg/breaker"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+func init() {
+	// Handlers bind with gin's validator, which needs the rules of the DTOs
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		if err := dto.RegisterValidators(v); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// apiError is an error with the status and code of its response
+type apiError struct {
+	status  int
+	code    string
+	message string
+	details any
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+func errValidation(message string) *apiError {
+	return &apiError{status: http.StatusBadRequest, code: dto.CodeValidationFailed, message: message}
+}
+
+func errUnauthorized(message string) *apiError {
+	return &apiError{status: http.StatusUnauthorized, code: dto.CodeUnauthorized, message: message}
+}
+
+func errForbidden(message string) *apiError {
+	return &apiError{status: http.StatusForbidden, code: dto.CodeForbidden, message: message}
+}
+
+func errNotFound(message string) *apiError {
+	return &apiError{status: http.StatusNotFound, code: dto.CodeNotFound, message: message}
+}
+
+// errNoTenant is returned when authentication didn't establish a tenant
+var errNoTenant = errUnauthorized("No tenant ID found")
+
+// serviceErrors maps the errors of the service layer to their responses
+var serviceErrors = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{service.ErrTenantNotFound, http.StatusNotFound, dto.CodeNotFound},
+	{service.ErrTenantExists, http.StatusConflict, dto.CodeConflict},
+	{service.ErrTenantNotDeleted, http.StatusConflict, dto.CodeConflict},
+	{service.ErrTenantPurged, http.StatusGone, dto.CodeGone},
+	{service.ErrSystemTenant, http.StatusForbidden, dto.CodeForbidden},
+	{service.ErrInvalidExportPublicKey, http.StatusBadRequest, dto.CodeValidationFailed},
+	{service.ErrInvalidWebhookURL, http.StatusBadRequest, dto.CodeValidationFailed},
+	{service.ErrLogNotFound, http.StatusNotFound, dto.CodeNotFound},
+	{service.ErrDailyQuotaExceeded, http.StatusTooManyRequests, dto.CodeTenantQuotaExceeded},
+	{service.ErrMonthlyQuotaExceeded, http.StatusForbidden, dto.CodeTenantQuotaExceeded},
+	{service.ErrInvalidUsageRange, http.StatusBadRequest, dto.CodeValidationFailed},
+	{service.ErrExportJobNotFound, http.StatusNotFound, dto.CodeNotFound},
+	{service.ErrExportNotSigned, http.StatusNotFound, dto.CodeNotFound},
+	{service.ErrExportSigningDisabled, http.StatusBadRequest, dto.CodeValidationFailed},
+	{service.ErrRestoreJobNotFound, http.StatusNotFound, dto.CodeNotFound},
+	{service.ErrImportJobNotFound, http.StatusNotFound, dto.CodeNotFound},
+	{service.ErrInvalidImportSource, http.StatusBadRequest, dto.CodeValidationFailed},
+	{service.ErrImportSourceNotAllowed, http.StatusForbidden, dto.CodeForbidden},
+	{service.ErrImportKeyNotAllowed, http.StatusForbidden, dto.CodeForbidden},
+	{service.ErrImportNotGzip, http.StatusBadRequest, dto.CodeValidationFailed},
+	{service.ErrImportTooLarge, http.StatusRequestEntityTooLarge, dto.CodePayloadTooLarge},
+	{service.ErrJobNotFound, http.StatusNotFound, dto.CodeNotFound},
+	{service.ErrArchiveNotFound, http.StatusNotFound, dto.CodeNotFound},
+	{service.ErrInvalidArchiveSearchRange, http.StatusBadRequest, dto.CodeValidationFailed},
+	{service.ErrSearchIndexNotFound, http.StatusNotFound, dto.CodeNotFound},
+	{service.ErrInvalidIndexRange, http.StatusBadRequest, dto.CodeValidationFailed},
+	{service.ErrInvalidReindexRange, http.StatusBadRequest, dto.CodeValidationFailed},
+	{service.ErrUserNotFound, http.StatusNotFound, dto.CodeNotFound},
+	{service.ErrEmailAlreadyExists, http.StatusConflict, dto.CodeConflict},
+	{service.ErrPolicyNotFound, http.StatusNotFound, dto.CodeNotFound},
+	{service.ErrPolicyExists, http.StatusConflict, dto.CodeConflict},
+	{service.ErrInvalidPolicyScope, http.StatusBadRequest, dto.CodeValidationFailed},
+	{service.ErrRedactionRuleNotFound, http.StatusNotFound, dto.CodeNotFound},
+	{service.ErrRedactionRuleExists, http.StatusConflict, dto.CodeConflict},
+	{service.ErrInvalidRedactionPath, http.StatusBadRequest, dto.CodeValidationFailed},
+	{service.ErrTaggingRuleNotFound, http.StatusNotFound, dto.CodeNotFound},
+	{service.ErrTaggingRuleExists, http.StatusConflict, dto.CodeConflict},
+	{service.ErrEmptyTaggingRule, http.StatusBadRequest, dto.CodeValidationFailed},
+	{service.ErrInvalidMessagePattern, http.StatusBadRequest, dto.CodeValidationFailed},
+	{service.ErrResourceSchemaNotFound, http.StatusNotFound, dto.CodeNotFound},
+	{service.ErrResourceSchemaExists, http.StatusConflict, dto.CodeConflict},
+	{service.ErrInvalidResourceSchema, http.StatusBadRequest, dto.CodeValidationFailed},
+	{service.ErrSavedSearchNotFound, http.StatusNotFound, dto.CodeNotFound},
+	{service.ErrSavedSearchExists, http.StatusConflict, dto.CodeConflict},
+	{service.ErrSavedSearchNotOwner, http.StatusForbidden, dto.CodeForbidden},
+	{service.ErrInvalidSavedSearch, http.StatusBadRequest, dto.CodeValidationFailed},
+	{service.ErrIntegrationNotFound, http.StatusNotFound, dto.CodeNotFound},
+	{service.ErrIntegrationExists, http.StatusConflict, dto.CodeConflict},
+	{service.ErrInvalidIntegration, http.StatusBadRequest, dto.CodeValidationFailed},
+	{service.ErrIntegrationTokensDisabled, http.StatusBadRequest, dto.CodeValidationFailed},
+	{service.ErrInvalidCredentials, http.StatusUnauthorized, dto.CodeUnauthorized},
+	{service.ErrUserInactive, http.StatusForbidden, dto.CodeForbidden},
+	{service.ErrInvalidRefreshToken, http.StatusUnauthorized, dto.CodeUnauthorized},
+	{service.ErrNoTenantInToken, http.StatusUnauthorized, dto.CodeUnauthorized},
+	{service.ErrDBPoolNotFound, http.StatusNotFound, dto.CodeNotFound},
+	{service.ErrInvalidDBPoolSettings, http.StatusBadRequest, dto.CodeValidationFailed},
+	{service.ErrQueryTimeout, http.StatusGatewayTimeout, dto.CodeQueryTimeout},
+	{gorm.ErrRecordNotFound, http.StatusNotFound, dto.CodeNotFound},
+}
+
+// mapError returns the response of err. Errors that aren't known to be safe
+// to show, such as raw database or OpenSearch errors, become a generic
+// INTERNAL_ERROR.
+func mapError(err error) *apiError {
+	var known *apiError
+	if errors.As(err, &known) {
+		return known
+	}
+
+	for _, mapping := range serviceErrors {
+		if errors.Is(err, mapping.err) {
+			return &apiError{status: mapping.status, code: mapping.code, message: mapping.err.Error()}
+		}
+	}
+
+	var violations *service.SchemaViolationError
+	if errors.As(err, &violations) {
+		details := make([]dto.FieldError, len(violations.Violations))
+		for i, violation := range violations.Violations {
+			field := violation.Field
+			if violations.Batch {
+				field = fmt.Sprintf("logs[%d].%s", violation.Log, field)
+			}
+			details[i] = dto.FieldError{Field: field, Rule: "schema", Param: violation.Message}
+		}
+		return &apiError{status: http.StatusBadRequest, code: dto.CodeValidationFailed, message: "Log does not conform to the schema of its resource type", details: details}
+	}
+
+	var (
+		open      *breaker.OpenError
+		tooLarge  *http.MaxBytesError
+		fieldErrs validator.ValidationErrors
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &open):
+		// A dependency is down; tell clients which rather than a bare 500
+		return &apiError{status: http.StatusServiceUnavailable, code: dto.CodeServiceUnavailable, message: "Service temporarily unavailable: " + open.Error()}
+	case errors.Is(err, context.DeadlineExceeded):
+		// A search or other call ran past the request's timeout
+		return &apiError{status: http.StatusGatewayTimeout, code: dto.CodeQueryTimeout, message: service.ErrQueryTimeout.Error()}
+	case errors.As(err, &tooLarge):
+		return &apiError{
+			status:  http.StatusRequestEntityTooLarge,
+			code:    dto.CodePayloadTooLarge,
+			message: fmt.Sprintf("Request body exceeds %d bytes", tooLarge.Limit),
+		}
+	case errors.As(err, &fieldErrs):
+		return &apiError{status: http.StatusBadRequest, code: dto.CodeValidationFailed, message: "Request validation failed", details: fieldErrorDetails(fieldErrs)}
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return errValidation("Invalid request body: " + err.Error())
+	}
+
+	return &apiError{status: http.StatusInternalServerError, code: dto.CodeInternal, message: "Internal server error"}
+}
+
+// fieldErrorDetails lists the fields failing validation by their JSON path
+func fieldErrorDetails(fieldErrs validator.ValidationErrors) []dto.FieldError {
+	details := make([]dto.FieldError, len(fieldErrs))
+	for i, fieldErr := range fieldErrs {
+		// Drop the name of the request type, leaving the JSON path of the field
+		_, field, _ := strings.Cut(fieldErr.Namespace(), ".")
+		details[i] = dto.FieldError{Field: field, Rule: fieldErr.Tag(), Param: fieldErr.Param()}
+	}
+	return details
+}
+
+// respondError records err on the request and writes its response. The
+// ErrorHandler logs what was recorded.
+func respondError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	writeError(c, err)
+}
+
+func writeError(c *gin.Context, err error) {
+	resp := mapError(err)
+	if errors.Is(err, service.ErrDailyQuotaExceeded) {
+		// The daily quota resets at midnight UTC; a monthly one needs a higher quota
+		c.Header("Retry-After", strconv.Itoa(secondsUntilMidnightUTC()))
+	}
+	var open *breaker.OpenError
+	if errors.As(err, &open) {
+		c.Header("Retry-After", strconv.Itoa(max(int(math.Ceil(open.RetryAfter.Seconds())), 1)))
+	}
+	middleware.WriteError(c, resp.status, dto.Error{Code: resp.code, Message: resp.message, Details: resp.details})
+}
+
+// bindError responds to a request that failed to bind, with the fields
+// failing validation as details
+func bindError(c *gin.Context, err error) {
+	if resp := mapError(err); resp.status == http.StatusInternalServerError {
+		err = errValidation(err.Error())
+	}
+	respondError(c, err)
+}
+
+// ErrorHandler is the central error middleware of the API. It writes the
+// response of errors handlers recorded with c.Error without responding, and
+// logs the errors behind 5xx responses, whose details aren't shown to clients.
+func ErrorHandler(logger *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		if !c.Writer.Written() {
+			writeError(c, err)
+		}
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			logger.With(zap.String("request_id", c.GetString(string(utils.RequestIDKey)))).
+				Errorf("%s %s failed: %v", c.Request.Method, c.FullPath(), err)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	"github.com/kingrain94/audit-log-api/internal/utils"
+	"github.com/kingrain94/audit-log-api/pkg/breaker"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+type ErrorsTestSuite struct {
+	suite.Suite
+}
+
+func (s *ErrorsTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestErrors(t *testing.T) {
+	suite.Run(t, new(ErrorsTestSuite))
+}
+
+func (s *ErrorsTestSuite) decode(w *httptest.ResponseRecorder) dto.Error {
+	var body dto.Error
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &body))
+	return body
+}
+
+func (s *ErrorsTestSuite) TestRespondError_MapsServiceErrors() {
+	// Arrange
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs/log1/diff", nil)
+	c.Set(string(utils.RequestIDKey), "req-1")
+
+	// Act
+	respondError(c, fmt.Errorf("failed to get log: %w", service.ErrLogNotFound))
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+	body := s.decode(w)
+	s.Equal(dto.CodeNotFound, body.Code)
+	s.Equal(service.ErrLogNotFound.Error(), body.Message)
+	s.Equal("req-1", body.RequestID)
+}
+
+func (s *ErrorsTestSuite) TestRespondError_QuotaExceeded() {
+	// Arrange
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/logs", nil)
+
+	// Act
+	respondError(c, service.ErrDailyQuotaExceeded)
+
+	// Assert
+	s.Equal(http.StatusTooManyRequests, w.Code)
+	s.NotEmpty(w.Header().Get("Retry-After"))
+	s.Equal(dto.CodeTenantQuotaExceeded, s.decode(w).Code)
+}
+
+func (s *ErrorsTestSuite) TestRespondError_DependencyUnavailable() {
+	// Arrange
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs", nil)
+	err := fmt.Errorf("failed to search logs: %w", &breaker.OpenError{Name: "opensearch", RetryAfter: 12500 * time.Millisecond})
+
+	// Act
+	respondError(c, err)
+
+	// Assert
+	s.Equal(http.StatusServiceUnavailable, w.Code)
+	s.Equal("13", w.Header().Get("Retry-After"))
+	body := s.decode(w)
+	s.Equal(dto.CodeServiceUnavailable, body.Code)
+	s.Equal("Service temporarily unavailable: opensearch is unavailable: circuit breaker is open", body.Message)
+}
+
+func (s *ErrorsTestSuite) TestRespondError_QueryTimeout() {
+	// Arrange
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs/stats", nil)
+	err := fmt.Errorf("failed to get counts: %w", fmt.Errorf("%w: %w", service.ErrQueryTimeout, context.DeadlineExceeded))
+
+	// Act
+	respondError(c, err)
+
+	// Assert
+	s.Equal(http.StatusGatewayTimeout, w.Code)
+	body := s.decode(w)
+	s.Equal(dto.CodeQueryTimeout, body.Code)
+	s.Equal(service.ErrQueryTimeout.Error(), body.Message)
+}
+
+func (s *ErrorsTestSuite) TestRespondError_SearchDeadlineExceeded() {
+	// Arrange
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs", nil)
+
+	// Act
+	respondError(c, fmt.Errorf("failed to search logs: %w", context.DeadlineExceeded))
+
+	// Assert
+	s.Equal(http.StatusGatewayTimeout, w.Code)
+	s.Equal(dto.CodeQueryTimeout, s.decode(w).Code)
+}
+
+func (s *ErrorsTestSuite) TestRespondError_SchemaViolations() {
+	// Arrange
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/logs/bulk", nil)
+	err := &service.SchemaViolationError{Batch: true, Violations: []service.SchemaViolation{
+		{Log: 1, Field: "metadata.region", Message: "value must be one of 'eu', 'us'"},
+	}}
+
+	// Act
+	respondError(c, err)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	var body struct {
+		Code    string           `json:"code"`
+		Details []dto.FieldError `json:"details"`
+	}
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &body))
+	s.Equal(dto.CodeValidationFailed, body.Code)
+	s.Require().Len(body.Details, 1)
+	s.Equal("logs[1].metadata.region", body.Details[0].Field)
+	s.Equal("schema", body.Details[0].Rule)
+}
+
+func (s *ErrorsTestSuite) TestRespondError_HidesInternalErrors() {
+	// Arrange
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs", nil)
+
+	// Act
+	respondError(c, errors.New(`pq: relation "audit_logs" does not exist`))
+
+	// Assert
+	s.Equal(http.StatusInternalServerError, w.Code)
+	body := s.decode(w)
+	s.Equal(dto.CodeInternal, body.Code)
+	s.NotContains(body.Message, "audit_logs")
+}
+
+func (s *ErrorsTestSuite) TestBindError_ListsInvalidFields() {
+	// Arrange
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/logs/batch-get", strings.NewReader(`{"ids":["not-a-uuid"]}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	var req dto.BatchGetLogsRequest
+
+	// Act
+	bindError(c, c.ShouldBindJSON(&req))
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	var body struct {
+		Code    string           `json:"code"`
+		Details []dto.FieldError `json:"details"`
+	}
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &body))
+	s.Equal(dto.CodeValidationFailed, body.Code)
+	s.Require().Len(body.Details, 1)
+	s.Equal("uuid", body.Details[0].Rule)
+}
+
+func (s *ErrorsTestSuite) TestErrorHandler_WritesRecordedErrors() {
+	// Arrange
+	router := gin.New()
+	router.Use(ErrorHandler(logger.NewLogger("test")))
+	router.GET("/tenants/:id", func(c *gin.Context) {
+		_ = c.Error(service.ErrTenantNotFound)
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/tenants/t1", nil)
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+	s.Equal(dto.CodeNotFound, s.decode(w).Code)
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
+)
+
+// Content types of import uploads, gzip-compressed NDJSON
+const (
+	ndjsonContentType = "application/x-ndjson"
+	gzipContentType   = "application/gzip"
+)
+
+//go:generate mockery --name ImportService --output ../mocks
+type ImportService interface {
+	Upload(ctx context.Context, tenantID string, body io.Reader, size int64) (*dto.ImportJobResponse, error)
+	ImportFromS3(ctx context.Context, tenantID, uri string) (*dto.ImportJobResponse, error)
+	Get(ctx context.Context, tenantID, jobID string) (*dto.ImportJobResponse, error)
+}
+
+type ImportHandler struct {
+	*BaseHandler
+	service ImportService
+}
+
+func NewImportHandler(service ImportService) *ImportHandler {
+	return &ImportHandler{service: service}
+}
+
+// ImportLogs godoc
+// @Summary Import historical logs
+// @Description Enqueue an import of historical logs from a legacy system: a gzip-compressed NDJSON file of one log per line, in the format of POST /logs with an optional id. The file is either the body, as application/gzip or as application/x-ndjson with Content-Encoding gzip and a Content-Length, or read from S3 with a JSON body naming its s3:// URI in one of the buckets of IMPORT_ALLOWED_BUCKETS, under the tenant's <tenant_id>/ prefix. Logs keep their IDs, or get IDs derived from the file and line, and timestamps, so importing a file again skips the logs already imported. They are tagged and redacted by the tenant's rules and count towards its quotas, but aren't sampled or enriched. Invalid lines are counted and reported on the job without failing it.
+// @Tags audit_logs
+// @Accept json
+// @Accept application/gzip
+// @Accept application/x-ndjson
+// @Produce json
+// @Param request body dto.ImportLogsRequest false "S3 source, for JSON bodies"
+// @Success 202 {object} dto.ImportJobResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 411 {object} dto.Error "Upload without a Content-Length"
+// @Failure 413 {object} dto.Error
+// @Failure 415 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /logs/import [post]
+func (h *ImportHandler) ImportLogs(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	var (
+		job *dto.ImportJobResponse
+		err error
+	)
+	contentType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	switch contentType {
+	case ndjsonContentType, gzipContentType:
+		if contentType == ndjsonContentType && c.GetHeader("Content-Encoding") != "gzip" {
+			respondError(c, &apiError{status: http.StatusUnsupportedMediaType, code: dto.CodeUnsupportedMedia, message: "NDJSON uploads must be sent with Content-Encoding gzip"})
+			return
+		}
+		// The file is streamed to S3, which needs its size up front
+		if c.Request.ContentLength < 0 {
+			respondError(c, &apiError{status: http.StatusLengthRequired, code: dto.CodeValidationFailed, message: "Content-Length header is required for uploads"})
+			return
+		}
+		job, err = h.service.Upload(h.RequestCtx(c), tenantID, c.Request.Body, c.Request.ContentLength)
+	default:
+		// Only uploads may exceed the size limit of the other API requests
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxRequestSize)
+		var req dto.ImportLogsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			bindError(c, err)
+			return
+		}
+		job, err = h.service.ImportFromS3(h.RequestCtx(c), tenantID, req.SourceURI)
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetImportJob godoc
+// @Summary Get import job
+// @Description Get the progress of an import job of the authenticated tenant: the bytes and lines read, the logs imported, the lines skipped because their logs already existed, and the invalid lines with the reasons of the first 100
+// @Tags audit_logs
+// @Produce json
+// @Param job_id path string true "Import job ID"
+// @Success 200 {object} dto.ImportJobResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /logs/import/{job_id} [get]
+func (h *ImportHandler) GetImportJob(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	job, err := h.service.Get(h.RequestCtx(c), tenantID, c.Param("job_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ImportHandlerTestSuite struct {
+	suite.Suite
+	router      *gin.Engine
+	mockService *MockImportService
+	handler     *ImportHandler
+}
+
+type MockImportService struct {
+	mock.Mock
+}
+
+func (m *MockImportService) Upload(ctx context.Context, tenantID string, body io.Reader, size int64) (*dto.ImportJobResponse, error) {
+	args := m.Called(ctx, tenantID, body, size)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ImportJobResponse), args.Error(1)
+}
+
+func (m *MockImportService) ImportFromS3(ctx context.Context, tenantID, uri string) (*dto.ImportJobResponse, error) {
+	args := m.Called(ctx, tenantID, uri)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ImportJobResponse), args.Error(1)
+}
+
+func (m *MockImportService) Get(ctx context.Context, tenantID, jobID string) (*dto.ImportJobResponse, error) {
+	args := m.Called(ctx, tenantID, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ImportJobResponse), args.Error(1)
+}
+
+func (s *ImportHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.mockService = new(MockImportService)
+	s.handler = NewImportHandler(s.mockService)
+
+	// Setup routes with the tenant the JWT middleware would set
+	logs := s.router.Group("/logs", func(c *gin.Context) {
+		c.Set(string(contextutils.TenantIDKey), "tenant1")
+	})
+	logs.POST("/import", s.handler.ImportLogs)
+	logs.GET("/import/:job_id", s.handler.GetImportJob)
+}
+
+func TestImportHandler(t *testing.T) {
+	suite.Run(t, new(ImportHandlerTestSuite))
+}
+
+func (s *ImportHandlerTestSuite) TestImportLogs_UploadsGzipBody() {
+	// Arrange
+	body := []byte{0x1f, 0x8b, 0x08, 0x00}
+	s.mockService.On("Upload", mock.Anything, "tenant1", mock.Anything, int64(len(body))).
+		Return(&dto.ImportJobResponse{ID: "job1", Status: "PENDING"}, nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/logs/import", bytes.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/x-ndjson")
+	httpReq.Header.Set("Content-Encoding", "gzip")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusAccepted, w.Code)
+	var response dto.ImportJobResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal("job1", response.ID)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *ImportHandlerTestSuite) TestImportLogs_RejectsUncompressedNDJSON() {
+	// Arrange
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/logs/import", strings.NewReader(`{"action":"CREATE"}`))
+	httpReq.Header.Set("Content-Type", "application/x-ndjson")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusUnsupportedMediaType, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "Upload", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *ImportHandlerTestSuite) TestImportLogs_SourceNotAllowed() {
+	// Arrange
+	uri := "s3://audit-archives/audit-logs/tenant2/part-00001.ndjson.gz"
+	s.mockService.On("ImportFromS3", mock.Anything, "tenant1", uri).Return(nil, service.ErrImportSourceNotAllowed)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/logs/import", strings.NewReader(`{"source_uri":"`+uri+`"}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusForbidden, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *ImportHandlerTestSuite) TestGetImportJob_NotFound() {
+	// Arrange
+	s.mockService.On("Get", mock.Anything, "tenant1", "job1").Return(nil, service.ErrImportJobNotFound)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodGet, "/logs/import/job1", nil)
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
+)
+
+//go:generate mockery --name IntegrationService --output ../mocks
+type IntegrationService interface {
+	Create(ctx context.Context, tenantID string, req dto.IntegrationRequest) (*dto.IntegrationResponse, error)
+	List(ctx context.Context, tenantID string) ([]dto.IntegrationResponse, error)
+	Get(ctx context.Context, tenantID, id string) (*dto.IntegrationResponse, error)
+	Update(ctx context.Context, tenantID, id string, req dto.IntegrationRequest) (*dto.IntegrationResponse, error)
+	Delete(ctx context.Context, tenantID, id string) error
+}
+
+type IntegrationHandler struct {
+	*BaseHandler
+	service IntegrationService
+}
+
+func NewIntegrationHandler(service IntegrationService) *IntegrationHandler {
+	return &IntegrationHandler{service: service}
+}
+
+// CreateIntegration godoc
+// @Summary Create a log forwarding integration
+// @Description Forward the authenticated tenant's logs matching the filter to a Splunk HTTP Event Collector or the Datadog Logs API, from the logs stored from now on. Splunk integrations need the collector's url and a HEC token, Datadog integrations an API key. Logs are delivered at least once, in the order they were stored, by the forwarding worker.
+// @Tags integrations
+// @Accept json
+// @Produce json
+// @Param body body dto.IntegrationRequest true "Integration"
+// @Success 201 {object} dto.IntegrationResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 409 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /integrations [post]
+func (h *IntegrationHandler) CreateIntegration(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	var req dto.IntegrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	integration, err := h.service.Create(h.RequestCtx(c), tenantID, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, integration)
+}
+
+// ListIntegrations godoc
+// @Summary List log forwarding integrations
+// @Description List the log forwarding integrations of the authenticated tenant with their delivery status. Tokens are masked.
+// @Tags integrations
+// @Produce json
+// @Success 200 {array} dto.IntegrationResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /integrations [get]
+func (h *IntegrationHandler) ListIntegrations(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	integrations, err := h.service.List(h.RequestCtx(c), tenantID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, integrations)
+}
+
+// GetIntegration godoc
+// @Summary Get a log forwarding integration
+// @Description Get a log forwarding integration of the authenticated tenant with its delivery status: the logs forwarded, the time of the last log forwarded and the last error of consecutive failed deliveries
+// @Tags integrations
+// @Produce json
+// @Param id path string true "Integration ID"
+// @Success 200 {object} dto.IntegrationResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /integrations/{id} [get]
+func (h *IntegrationHandler) GetIntegration(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	integration, err := h.service.Get(h.RequestCtx(c), tenantID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, integration)
+}
+
+// UpdateIntegration godoc
+// @Summary Update a log forwarding integration
+// @Description Replace a log forwarding integration of the authenticated tenant. The token is kept when omitted and the type is unchanged. Forwarding resumes after the last log delivered, at once if it was backing off from failed deliveries.
+// @Tags integrations
+// @Accept json
+// @Produce json
+// @Param id path string true "Integration ID"
+// @Param body body dto.IntegrationRequest true "Integration"
+// @Success 200 {object} dto.IntegrationResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 409 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /integrations/{id} [put]
+func (h *IntegrationHandler) UpdateIntegration(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	var req dto.IntegrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	integration, err := h.service.Update(h.RequestCtx(c), tenantID, c.Param("id"), req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, integration)
+}
+
+// DeleteIntegration godoc
+// @Summary Delete a log forwarding integration
+// @Description Delete a log forwarding integration of the authenticated tenant, stopping its forwarding
+// @Tags integrations
+// @Param id path string true "Integration ID"
+// @Success 204
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /integrations/{id} [delete]
+func (h *IntegrationHandler) DeleteIntegration(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	if err := h.service.Delete(h.RequestCtx(c), tenantID, c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
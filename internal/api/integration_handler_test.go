@@ -0,0 +1,165 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type IntegrationHandlerTestSuite struct {
+	suite.Suite
+	router      *gin.Engine
+	mockService *MockIntegrationService
+	handler     *IntegrationHandler
+}
+
+type MockIntegrationService struct {
+	mock.Mock
+}
+
+func (m *MockIntegrationService) Create(ctx context.Context, tenantID string, req dto.IntegrationRequest) (*dto.IntegrationResponse, error) {
+	args := m.Called(ctx, tenantID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.IntegrationResponse), args.Error(1)
+}
+
+func (m *MockIntegrationService) List(ctx context.Context, tenantID string) ([]dto.IntegrationResponse, error) {
+	args := m.Called(ctx, tenantID)
+	return args.Get(0).([]dto.IntegrationResponse), args.Error(1)
+}
+
+func (m *MockIntegrationService) Get(ctx context.Context, tenantID, id string) (*dto.IntegrationResponse, error) {
+	args := m.Called(ctx, tenantID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.IntegrationResponse), args.Error(1)
+}
+
+func (m *MockIntegrationService) Update(ctx context.Context, tenantID, id string, req dto.IntegrationRequest) (*dto.IntegrationResponse, error) {
+	args := m.Called(ctx, tenantID, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.IntegrationResponse), args.Error(1)
+}
+
+func (m *MockIntegrationService) Delete(ctx context.Context, tenantID, id string) error {
+	args := m.Called(ctx, tenantID, id)
+	return args.Error(0)
+}
+
+func (s *IntegrationHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.mockService = new(MockIntegrationService)
+	s.handler = NewIntegrationHandler(s.mockService)
+
+	// Setup routes with the tenant the JWT middleware would set
+	integrations := s.router.Group("/integrations", func(c *gin.Context) {
+		c.Set(string(contextutils.TenantIDKey), "tenant1")
+	})
+	integrations.POST("", s.handler.CreateIntegration)
+	integrations.GET("", s.handler.ListIntegrations)
+	integrations.GET("/:id", s.handler.GetIntegration)
+	integrations.PUT("/:id", s.handler.UpdateIntegration)
+	integrations.DELETE("/:id", s.handler.DeleteIntegration)
+}
+
+func TestIntegrationHandler(t *testing.T) {
+	suite.Run(t, new(IntegrationHandlerTestSuite))
+}
+
+func (s *IntegrationHandlerTestSuite) TestCreateIntegration_Success() {
+	// Arrange
+	s.mockService.On("Create", mock.Anything, "tenant1", mock.MatchedBy(func(req dto.IntegrationRequest) bool {
+		return req.Type == "splunk" && req.Settings.URL == "https://splunk.example.com:8088" && req.Filter.Severity == "ERROR,CRITICAL"
+	})).Return(&dto.IntegrationResponse{ID: "integration1", TenantID: "tenant1", Name: "siem", Type: "splunk"}, nil)
+
+	body := []byte(`{"name":"siem","type":"splunk","token":"hec-token","settings":{"url":"https://splunk.example.com:8088"},"filter":{"severity":"ERROR,CRITICAL"}}`)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/integrations", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusCreated, w.Code)
+	var response dto.IntegrationResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal("integration1", response.ID)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *IntegrationHandlerTestSuite) TestCreateIntegration_UnknownType() {
+	// Arrange
+	body := []byte(`{"name":"siem","type":"sumo","token":"token"}`)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/integrations", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *IntegrationHandlerTestSuite) TestCreateIntegration_Invalid() {
+	// Arrange
+	s.mockService.On("Create", mock.Anything, "tenant1", mock.Anything).Return(nil, service.ErrInvalidIntegration)
+
+	body := []byte(`{"name":"siem","type":"splunk","token":"hec-token"}`)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/integrations", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *IntegrationHandlerTestSuite) TestGetIntegration_NotFound() {
+	// Arrange
+	s.mockService.On("Get", mock.Anything, "tenant1", "missing").Return(nil, service.ErrIntegrationNotFound)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodGet, "/integrations/missing", nil)
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *IntegrationHandlerTestSuite) TestDeleteIntegration_Success() {
+	// Arrange
+	s.mockService.On("Delete", mock.Anything, "tenant1", "integration1").Return(nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodDelete, "/integrations/integration1", nil)
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusNoContent, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
+)
+
+//go:generate mockery --name JobService --output ../mocks
+type JobService interface {
+	List(ctx context.Context, filter *domain.JobFilter) ([]dto.JobResponse, error)
+	Get(ctx context.Context, tenantID, id string) (*dto.JobResponse, error)
+}
+
+type JobHandler struct {
+	*BaseHandler
+	service JobService
+}
+
+func NewJobHandler(service JobService) *JobHandler {
+	return &JobHandler{service: service}
+}
+
+// ListJobs godoc
+// @Summary List background jobs
+// @Description List the export, restore, cleanup, reindex and import jobs of the authenticated tenant, newest first
+// @Tags jobs
+// @Produce json
+// @Param type query string false "Filter by job type" Enums(export, tenant_export, restore, cleanup, reindex, import)
+// @Param status query string false "Filter by status" Enums(PENDING, RUNNING, COMPLETED, FAILED)
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(10)
+// @Success 200 {array} dto.JobResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /jobs [get]
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	filter, err := getJobFilterFromQuery(c)
+	if err != nil {
+		bindError(c, err)
+		return
+	}
+
+	jobs, err := h.service.List(h.RequestCtx(c), filter)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, jobs)
+}
+
+// GetJob godoc
+// @Summary Get a background job
+// @Description Get the status, counts, error and timing of a job of the authenticated tenant
+// @Tags jobs
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} dto.JobResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /jobs/{id} [get]
+func (h *JobHandler) GetJob(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	job, err := h.service.Get(h.RequestCtx(c), tenantID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+func getJobFilterFromQuery(c *gin.Context) (*domain.JobFilter, error) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant_id is required")
+	}
+
+	filter := &domain.JobFilter{TenantID: tenantID}
+
+	if jobType := c.Query("type"); jobType != "" {
+		if !domain.IsValidJobType(jobType) {
+			return nil, fmt.Errorf("invalid job type: %s", jobType)
+		}
+		filter.Type = domain.JobType(jobType)
+	}
+
+	if status := c.Query("status"); status != "" {
+		status = strings.ToUpper(status)
+		if !domain.IsValidJobStatus(status) {
+			return nil, fmt.Errorf("invalid job status: %s", status)
+		}
+		filter.Status = domain.JobStatus(status)
+	}
+
+	// Parse pagination
+	if page := c.Query("page"); page != "" {
+		if pageNum, err := strconv.Atoi(page); err == nil {
+			filter.Page = pageNum
+		}
+	}
+	if pageSize := c.Query("page_size"); pageSize != "" {
+		if size, err := strconv.Atoi(pageSize); err == nil {
+			filter.PageSize = size
+		}
+	}
+
+	return filter, nil
+}
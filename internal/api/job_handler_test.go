@@ -0,0 +1,144 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type JobHandlerTestSuite struct {
+	suite.Suite
+	router      *gin.Engine
+	mockService *MockJobService
+	handler     *JobHandler
+}
+
+type MockJobService struct {
+	mock.Mock
+}
+
+func (m *MockJobService) List(ctx context.Context, filter *domain.JobFilter) ([]dto.JobResponse, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]dto.JobResponse), args.Error(1)
+}
+
+func (m *MockJobService) Get(ctx context.Context, tenantID, id string) (*dto.JobResponse, error) {
+	args := m.Called(ctx, tenantID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.JobResponse), args.Error(1)
+}
+
+func (s *JobHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.mockService = new(MockJobService)
+	s.handler = NewJobHandler(s.mockService)
+
+	// Setup routes with the tenant the JWT middleware would set
+	jobs := s.router.Group("/jobs", func(c *gin.Context) {
+		c.Set(string(contextutils.TenantIDKey), "tenant1")
+	})
+	jobs.GET("", s.handler.ListJobs)
+	jobs.GET("/:id", s.handler.GetJob)
+}
+
+func TestJobHandler(t *testing.T) {
+	suite.Run(t, new(JobHandlerTestSuite))
+}
+
+func (s *JobHandlerTestSuite) TestListJobs_Filters() {
+	// Arrange
+	expectedFilter := &domain.JobFilter{
+		TenantID: "tenant1",
+		Type:     domain.JobTypeCleanup,
+		Status:   domain.JobFailed,
+		Page:     2,
+		PageSize: 5,
+	}
+	s.mockService.On("List", mock.Anything, expectedFilter).
+		Return([]dto.JobResponse{{ID: "job1", Type: "cleanup", Status: "FAILED"}}, nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodGet, "/jobs?type=cleanup&status=failed&page=2&page_size=5", nil)
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response []dto.JobResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Len(response, 1)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *JobHandlerTestSuite) TestListJobs_InvalidType() {
+	// Arrange
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodGet, "/jobs?type=purge", nil)
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "List", mock.Anything, mock.Anything)
+}
+
+func (s *JobHandlerTestSuite) TestListJobs_InvalidStatus() {
+	// Arrange
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodGet, "/jobs?status=stuck", nil)
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "List", mock.Anything, mock.Anything)
+}
+
+func (s *JobHandlerTestSuite) TestGetJob_Success() {
+	// Arrange
+	s.mockService.On("Get", mock.Anything, "tenant1", "job1").
+		Return(&dto.JobResponse{ID: "job1", Type: "cleanup", Status: "COMPLETED", Counts: map[string]int64{"deleted": 42}}, nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodGet, "/jobs/job1", nil)
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response dto.JobResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal(int64(42), response.Counts["deleted"])
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *JobHandlerTestSuite) TestGetJob_NotFound() {
+	// Arrange
+	s.mockService.On("Get", mock.Anything, "tenant1", "missing").Return(nil, service.ErrJobNotFound)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodGet, "/jobs/missing", nil)
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+}
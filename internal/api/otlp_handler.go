@@ -0,0 +1,130 @@
+package api
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/ingest"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
+)
+
+// maxOTLPRequestSize caps OTLP request bodies after decompression
+const maxOTLPRequestSize = 10 * 1024 * 1024
+
+//go:generate mockery --name OTLPLogService --output ../mocks
+type OTLPLogService interface {
+	BulkCreate(ctx context.Context, reqs []dto.CreateAuditLogRequest) error
+}
+
+type OTLPHandler struct {
+	*BaseHandler
+	service OTLPLogService
+}
+
+func NewOTLPHandler(service OTLPLogService) *OTLPHandler {
+	return &OTLPHandler{service: service}
+}
+
+// ExportLogs receives OpenTelemetry logs over OTLP/HTTP with protobuf
+// encoding, optionally gzip compressed, and stores each log record as an
+// audit log of the authenticated tenant. It is mounted at /v1/logs, outside
+// the API base path, where OTLP exporters send logs by default. Responses
+// are an ExportLogsServiceResponse, or a google.rpc.Status on failure.
+func (h *OTLPHandler) ExportLogs(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		writeOTLPStatus(c, http.StatusUnauthorized, codes.Unauthenticated, "No tenant ID found")
+		return
+	}
+
+	body, err := readOTLPBody(c.Request)
+	if err != nil {
+		writeOTLPStatus(c, http.StatusBadRequest, codes.InvalidArgument, err.Error())
+		return
+	}
+
+	var req collogspb.ExportLogsServiceRequest
+	if err := proto.Unmarshal(body, &req); err != nil {
+		writeOTLPStatus(c, http.StatusBadRequest, codes.InvalidArgument, "failed to decode ExportLogsServiceRequest: "+err.Error())
+		return
+	}
+
+	logs, rejected := ingest.ConvertOTLPLogs(&req, tenantID)
+	if len(logs) > 0 {
+		err := h.service.BulkCreate(h.RequestCtx(c), logs)
+		switch {
+		case errors.Is(err, service.ErrDailyQuotaExceeded):
+			// 429 is retryable; exporters honour Retry-After
+			c.Header("Retry-After", strconv.Itoa(secondsUntilMidnightUTC()))
+			writeOTLPStatus(c, http.StatusTooManyRequests, codes.ResourceExhausted, err.Error())
+			return
+		case errors.Is(err, service.ErrMonthlyQuotaExceeded):
+			writeOTLPStatus(c, http.StatusForbidden, codes.PermissionDenied, err.Error())
+			return
+		case err != nil:
+			// 503 tells exporters to retry the batch
+			writeOTLPStatus(c, http.StatusServiceUnavailable, codes.Unavailable, err.Error())
+			return
+		}
+	}
+
+	resp := &collogspb.ExportLogsServiceResponse{}
+	if rejected > 0 {
+		resp.PartialSuccess = &collogspb.ExportLogsPartialSuccess{
+			RejectedLogRecords: rejected,
+			ErrorMessage:       "tenant.id resource attribute does not match the authenticated tenant",
+		}
+	}
+	writeOTLPMessage(c, http.StatusOK, resp)
+}
+
+func readOTLPBody(r *http.Request) ([]byte, error) {
+	var reader io.Reader = r.Body
+	switch r.Header.Get("Content-Encoding") {
+	case "", "identity":
+	case "gzip":
+		gzipReader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	default:
+		return nil, fmt.Errorf("unsupported Content-Encoding %q", r.Header.Get("Content-Encoding"))
+	}
+
+	body, err := io.ReadAll(io.LimitReader(reader, maxOTLPRequestSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+	if len(body) > maxOTLPRequestSize {
+		return nil, fmt.Errorf("body exceeds %d bytes", maxOTLPRequestSize)
+	}
+	return body, nil
+}
+
+func writeOTLPStatus(c *gin.Context, httpStatus int, code codes.Code, message string) {
+	writeOTLPMessage(c, httpStatus, &status.Status{Code: int32(code), Message: message})
+}
+
+func writeOTLPMessage(c *gin.Context, httpStatus int, msg proto.Message) {
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Data(httpStatus, ingest.OTLPProtobufContentType, data)
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
+)
+
+type OTLPHandlerTestSuite struct {
+	suite.Suite
+	router      *gin.Engine
+	mockService *MockAuditLogService
+	handler     *OTLPHandler
+}
+
+func (s *OTLPHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.mockService = new(MockAuditLogService)
+	s.handler = NewOTLPHandler(s.mockService)
+
+	// Setup routes with the tenant the JWT middleware would set
+	s.router.POST("/v1/logs", func(c *gin.Context) {
+		c.Set(string(contextutils.TenantIDKey), "tenant1")
+	}, s.handler.ExportLogs)
+}
+
+func TestOTLPHandler(t *testing.T) {
+	suite.Run(t, new(OTLPHandlerTestSuite))
+}
+
+func stringAttr(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}}}
+}
+
+func otlpRequest(tenantID string, records ...*logspb.LogRecord) *collogspb.ExportLogsServiceRequest {
+	attrs := []*commonpb.KeyValue{stringAttr("service.name", "billing")}
+	if tenantID != "" {
+		attrs = append(attrs, stringAttr("tenant.id", tenantID))
+	}
+	return &collogspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{{
+			Resource:  &resourcepb.Resource{Attributes: attrs},
+			ScopeLogs: []*logspb.ScopeLogs{{LogRecords: records}},
+		}},
+	}
+}
+
+func (s *OTLPHandlerTestSuite) post(req *collogspb.ExportLogsServiceRequest, gzipped bool) *httptest.ResponseRecorder {
+	body, err := proto.Marshal(req)
+	s.Require().NoError(err)
+	if gzipped {
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		_, _ = gw.Write(body)
+		_ = gw.Close()
+		body = buf.Bytes()
+	}
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/v1/logs", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	if gzipped {
+		httpReq.Header.Set("Content-Encoding", "gzip")
+	}
+	s.router.ServeHTTP(w, httpReq)
+	return w
+}
+
+func (s *OTLPHandlerTestSuite) TestExportLogs_Success() {
+	// Arrange
+	timestamp := time.Date(2025, 7, 17, 21, 20, 48, 0, time.UTC)
+	record := &logspb.LogRecord{
+		TimeUnixNano:   uint64(timestamp.UnixNano()),
+		SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_WARN,
+		Body:           &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "Invoice voided"}},
+		Attributes: []*commonpb.KeyValue{
+			stringAttr("enduser.id", "user1"),
+			stringAttr("audit.action", "VOID"),
+			stringAttr("audit.resource_id", "inv-42"),
+		},
+	}
+
+	var stored []dto.CreateAuditLogRequest
+	s.mockService.On("BulkCreate", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { stored = args.Get(1).([]dto.CreateAuditLogRequest) }).
+		Return(nil)
+
+	// Act
+	w := s.post(otlpRequest("tenant1", record), true)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response collogspb.ExportLogsServiceResponse
+	s.NoError(proto.Unmarshal(w.Body.Bytes(), &response))
+	s.Nil(response.PartialSuccess)
+
+	s.Require().Len(stored, 1)
+	s.Equal("tenant1", stored[0].TenantID)
+	s.Equal("user1", stored[0].UserID)
+	s.Equal("VOID", stored[0].Action)
+	s.Equal("billing", stored[0].ResourceType)
+	s.Equal("inv-42", stored[0].ResourceID)
+	s.Equal("WARNING", stored[0].Severity)
+	s.Equal("Invoice voided", stored[0].Message)
+	s.True(timestamp.Equal(stored[0].Timestamp))
+	s.Contains(string(stored[0].Metadata), `"service.name":"billing"`)
+}
+
+func (s *OTLPHandlerTestSuite) TestExportLogs_OtherTenantRejected() {
+	// Arrange
+	record := &logspb.LogRecord{Body: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "hello"}}}
+
+	// Act
+	w := s.post(otlpRequest("tenant2", record, record), false)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response collogspb.ExportLogsServiceResponse
+	s.NoError(proto.Unmarshal(w.Body.Bytes(), &response))
+	s.Require().NotNil(response.PartialSuccess)
+	s.Equal(int64(2), response.PartialSuccess.RejectedLogRecords)
+	s.mockService.AssertNotCalled(s.T(), "BulkCreate", mock.Anything, mock.Anything)
+}
+
+func (s *OTLPHandlerTestSuite) TestExportLogs_InvalidBody() {
+	// Arrange
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/v1/logs", bytes.NewBufferString("not protobuf"))
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	var response status.Status
+	s.NoError(proto.Unmarshal(w.Body.Bytes(), &response))
+	s.NotEmpty(response.Message)
+}
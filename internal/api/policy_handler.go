@@ -0,0 +1,155 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
+)
+
+//go:generate mockery --name PolicyService --output ../mocks
+type PolicyService interface {
+	Create(ctx context.Context, tenantID string, req dto.PolicyRequest) (*dto.PolicyResponse, error)
+	List(ctx context.Context, tenantID string) ([]dto.PolicyResponse, error)
+	Update(ctx context.Context, tenantID, id string, req dto.PolicyRequest) (*dto.PolicyResponse, error)
+	Delete(ctx context.Context, tenantID, id string) error
+}
+
+type PolicyHandler struct {
+	*BaseHandler
+	service PolicyService
+}
+
+func NewPolicyHandler(service PolicyService) *PolicyHandler {
+	return &PolicyHandler{service: service}
+}
+
+// CreatePolicy godoc
+// @Summary Create a policy
+// @Description Grant or deny a role an action on a resource. The first policy for a role replaces its built-in defaults.
+// @Tags policies
+// @Accept json
+// @Produce json
+// @Param body body dto.PolicyRequest true "Policy"
+// @Success 201 {object} dto.PolicyResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 409 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /policies [post]
+func (h *PolicyHandler) CreatePolicy(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	var req dto.PolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	policy, err := h.service.Create(h.RequestCtx(c), tenantID, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, policy)
+}
+
+// ListPolicies godoc
+// @Summary List policies
+// @Description List the policies of the authenticated tenant. Roles without policies use the built-in defaults.
+// @Tags policies
+// @Produce json
+// @Success 200 {array} dto.PolicyResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /policies [get]
+func (h *PolicyHandler) ListPolicies(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	policies, err := h.service.List(h.RequestCtx(c), tenantID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, policies)
+}
+
+// UpdatePolicy godoc
+// @Summary Update a policy
+// @Description Replace a policy of the authenticated tenant
+// @Tags policies
+// @Accept json
+// @Produce json
+// @Param id path string true "Policy ID"
+// @Param body body dto.PolicyRequest true "Policy"
+// @Success 200 {object} dto.PolicyResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 409 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /policies/{id} [put]
+func (h *PolicyHandler) UpdatePolicy(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	var req dto.PolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	policy, err := h.service.Update(h.RequestCtx(c), tenantID, c.Param("id"), req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// DeletePolicy godoc
+// @Summary Delete a policy
+// @Description Delete a policy of the authenticated tenant. Deleting the last policy of a role restores its defaults.
+// @Tags policies
+// @Param id path string true "Policy ID"
+// @Success 204
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /policies/{id} [delete]
+func (h *PolicyHandler) DeletePolicy(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	err := h.service.Delete(h.RequestCtx(c), tenantID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type PolicyHandlerTestSuite struct {
+	suite.Suite
+	router      *gin.Engine
+	mockService *MockPolicyService
+	handler     *PolicyHandler
+}
+
+type MockPolicyService struct {
+	mock.Mock
+}
+
+func (m *MockPolicyService) Create(ctx context.Context, tenantID string, req dto.PolicyRequest) (*dto.PolicyResponse, error) {
+	args := m.Called(ctx, tenantID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.PolicyResponse), args.Error(1)
+}
+
+func (m *MockPolicyService) List(ctx context.Context, tenantID string) ([]dto.PolicyResponse, error) {
+	args := m.Called(ctx, tenantID)
+	return args.Get(0).([]dto.PolicyResponse), args.Error(1)
+}
+
+func (m *MockPolicyService) Update(ctx context.Context, tenantID, id string, req dto.PolicyRequest) (*dto.PolicyResponse, error) {
+	args := m.Called(ctx, tenantID, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.PolicyResponse), args.Error(1)
+}
+
+func (m *MockPolicyService) Delete(ctx context.Context, tenantID, id string) error {
+	args := m.Called(ctx, tenantID, id)
+	return args.Error(0)
+}
+
+func (s *PolicyHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.mockService = new(MockPolicyService)
+	s.handler = NewPolicyHandler(s.mockService)
+
+	// Setup routes with the tenant the JWT middleware would set
+	policies := s.router.Group("/policies", func(c *gin.Context) {
+		c.Set(string(contextutils.TenantIDKey), "tenant1")
+	})
+	policies.POST("", s.handler.CreatePolicy)
+	policies.GET("", s.handler.ListPolicies)
+	policies.PUT("/:id", s.handler.UpdatePolicy)
+	policies.DELETE("/:id", s.handler.DeletePolicy)
+}
+
+func TestPolicyHandler(t *testing.T) {
+	suite.Run(t, new(PolicyHandlerTestSuite))
+}
+
+func (s *PolicyHandlerTestSuite) TestCreatePolicy_Success() {
+	// Arrange
+	req := dto.PolicyRequest{Role: "auditor", Resource: "logs", Action: "create", Effect: "allow"}
+	s.mockService.On("Create", mock.Anything, "tenant1", req).
+		Return(&dto.PolicyResponse{ID: "policy1", TenantID: "tenant1", Role: "auditor", Resource: "logs", Action: "create", Effect: "allow", Scope: "all"}, nil)
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/policies", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusCreated, w.Code)
+	var response dto.PolicyResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal("policy1", response.ID)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *PolicyHandlerTestSuite) TestCreatePolicy_AdminRoleRejected() {
+	// Arrange
+	body, _ := json.Marshal(dto.PolicyRequest{Role: "admin", Resource: "logs", Action: "read", Effect: "deny"})
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/policies", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *PolicyHandlerTestSuite) TestCreatePolicy_Exists() {
+	// Arrange
+	req := dto.PolicyRequest{Role: "user", Resource: "logs", Action: "read"}
+	s.mockService.On("Create", mock.Anything, "tenant1", req).Return(nil, service.ErrPolicyExists)
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/policies", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusConflict, w.Code)
+}
+
+func (s *PolicyHandlerTestSuite) TestUpdatePolicy_InvalidScope() {
+	// Arrange
+	req := dto.PolicyRequest{Role: "auditor", Resource: "logs", Action: "delete", Scope: "own"}
+	s.mockService.On("Update", mock.Anything, "tenant1", "policy1", req).Return(nil, service.ErrInvalidPolicyScope)
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPut, "/policies/policy1", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *PolicyHandlerTestSuite) TestDeletePolicy_NotFound() {
+	// Arrange
+	s.mockService.On("Delete", mock.Anything, "tenant1", "missing").Return(service.ErrPolicyNotFound)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodDelete, "/policies/missing", nil)
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+}
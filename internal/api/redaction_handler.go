@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
+)
+
+//go:generate mockery --name RedactionService --output ../mocks
+type RedactionService interface {
+	Create(ctx context.Context, tenantID string, req dto.RedactionRuleRequest) (*dto.RedactionRuleResponse, error)
+	List(ctx context.Context, tenantID string) ([]dto.RedactionRuleResponse, error)
+	Delete(ctx context.Context, tenantID, id string) error
+}
+
+type RedactionHandler struct {
+	*BaseHandler
+	service RedactionService
+}
+
+func NewRedactionHandler(service RedactionService) *RedactionHandler {
+	return &RedactionHandler{service: service}
+}
+
+// CreateRedactionRule godoc
+// @Summary Create a redaction rule
+// @Description Mask values at a JSON path of before_state, after_state or metadata in every log the tenant ingests from now on
+// @Tags redaction
+// @Accept json
+// @Produce json
+// @Param body body dto.RedactionRuleRequest true "Redaction rule"
+// @Success 201 {object} dto.RedactionRuleResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 409 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /redaction-rules [post]
+func (h *RedactionHandler) CreateRedactionRule(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	var req dto.RedactionRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	rule, err := h.service.Create(h.RequestCtx(c), tenantID, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// ListRedactionRules godoc
+// @Summary List redaction rules
+// @Description List the redaction rules of the authenticated tenant
+// @Tags redaction
+// @Produce json
+// @Success 200 {array} dto.RedactionRuleResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /redaction-rules [get]
+func (h *RedactionHandler) ListRedactionRules(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	rules, err := h.service.List(h.RequestCtx(c), tenantID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// DeleteRedactionRule godoc
+// @Summary Delete a redaction rule
+// @Description Delete a redaction rule of the authenticated tenant. Logs already stored stay redacted.
+// @Tags redaction
+// @Param id path string true "Redaction rule ID"
+// @Success 204
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /redaction-rules/{id} [delete]
+func (h *RedactionHandler) DeleteRedactionRule(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	err := h.service.Delete(h.RequestCtx(c), tenantID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
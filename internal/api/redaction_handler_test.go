@@ -0,0 +1,116 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type RedactionHandlerTestSuite struct {
+	suite.Suite
+	router      *gin.Engine
+	mockService *MockRedactionService
+	handler     *RedactionHandler
+}
+
+type MockRedactionService struct {
+	mock.Mock
+}
+
+func (m *MockRedactionService) Create(ctx context.Context, tenantID string, req dto.RedactionRuleRequest) (*dto.RedactionRuleResponse, error) {
+	args := m.Called(ctx, tenantID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.RedactionRuleResponse), args.Error(1)
+}
+
+func (m *MockRedactionService) List(ctx context.Context, tenantID string) ([]dto.RedactionRuleResponse, error) {
+	args := m.Called(ctx, tenantID)
+	return args.Get(0).([]dto.RedactionRuleResponse), args.Error(1)
+}
+
+func (m *MockRedactionService) Delete(ctx context.Context, tenantID, id string) error {
+	args := m.Called(ctx, tenantID, id)
+	return args.Error(0)
+}
+
+func (s *RedactionHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.mockService = new(MockRedactionService)
+	s.handler = NewRedactionHandler(s.mockService)
+
+	// Setup routes with the tenant the JWT middleware would set
+	rules := s.router.Group("/redaction-rules", func(c *gin.Context) {
+		c.Set(string(contextutils.TenantIDKey), "tenant1")
+	})
+	rules.POST("", s.handler.CreateRedactionRule)
+	rules.GET("", s.handler.ListRedactionRules)
+	rules.DELETE("/:id", s.handler.DeleteRedactionRule)
+}
+
+func TestRedactionHandler(t *testing.T) {
+	suite.Run(t, new(RedactionHandlerTestSuite))
+}
+
+func (s *RedactionHandlerTestSuite) TestCreateRedactionRule_Success() {
+	// Arrange
+	req := dto.RedactionRuleRequest{Target: "after_state", Path: "customer.email", Mask: "email"}
+	s.mockService.On("Create", mock.Anything, "tenant1", req).
+		Return(&dto.RedactionRuleResponse{ID: "rule1", TenantID: "tenant1", Target: req.Target, Path: req.Path, Mask: req.Mask}, nil)
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/redaction-rules", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusCreated, w.Code)
+	var response dto.RedactionRuleResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal("rule1", response.ID)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *RedactionHandlerTestSuite) TestCreateRedactionRule_InvalidMask() {
+	// Arrange
+	body, _ := json.Marshal(dto.RedactionRuleRequest{Target: "metadata", Path: "phone", Mask: "phone"})
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/redaction-rules", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *RedactionHandlerTestSuite) TestDeleteRedactionRule_NotFound() {
+	// Arrange
+	s.mockService.On("Delete", mock.Anything, "tenant1", "missing").Return(service.ErrRedactionRuleNotFound)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodDelete, "/redaction-rules/missing", nil)
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
+)
+
+//go:generate mockery --name SavedSearchService --output ../mocks
+type SavedSearchService interface {
+	SavedSearchLookup
+	Create(ctx context.Context, tenantID, ownerID string, req dto.SavedSearchRequest) (*dto.SavedSearchResponse, error)
+	List(ctx context.Context, tenantID, userID string) ([]dto.SavedSearchResponse, error)
+	Update(ctx context.Context, tenantID, userID, id string, req dto.SavedSearchRequest) (*dto.SavedSearchResponse, error)
+	Delete(ctx context.Context, tenantID, userID, id string) error
+}
+
+type SavedSearchHandler struct {
+	*BaseHandler
+	service SavedSearchService
+}
+
+func NewSavedSearchHandler(service SavedSearchService) *SavedSearchHandler {
+	return &SavedSearchHandler{service: service}
+}
+
+// caller returns the tenant and user of the request, writing 401 if either is missing
+func (h *SavedSearchHandler) caller(c *gin.Context) (tenantID, userID string, ok bool) {
+	tenantID = c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return "", "", false
+	}
+	userID = c.GetString(string(contextutils.UserIDKey))
+	if userID == "" {
+		respondError(c, errUnauthorized("No user ID found"))
+		return "", "", false
+	}
+	return tenantID, userID, true
+}
+
+// CreateSavedSearch godoc
+// @Summary Create a saved search
+// @Description Save a named log filter. Run it with GET /logs?saved_search_id={id}; shared searches can be run by the whole tenant.
+// @Tags saved_searches
+// @Accept json
+// @Produce json
+// @Param body body dto.SavedSearchRequest true "Saved search"
+// @Success 201 {object} dto.SavedSearchResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 409 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /saved-searches [post]
+func (h *SavedSearchHandler) CreateSavedSearch(c *gin.Context) {
+	tenantID, userID, ok := h.caller(c)
+	if !ok {
+		return
+	}
+
+	var req dto.SavedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	search, err := h.service.Create(h.RequestCtx(c), tenantID, userID, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, search)
+}
+
+// ListSavedSearches godoc
+// @Summary List saved searches
+// @Description List the caller's saved searches and those shared within the tenant
+// @Tags saved_searches
+// @Produce json
+// @Success 200 {array} dto.SavedSearchResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /saved-searches [get]
+func (h *SavedSearchHandler) ListSavedSearches(c *gin.Context) {
+	tenantID, userID, ok := h.caller(c)
+	if !ok {
+		return
+	}
+
+	searches, err := h.service.List(h.RequestCtx(c), tenantID, userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, searches)
+}
+
+// UpdateSavedSearch godoc
+// @Summary Update a saved search
+// @Description Replace a saved search. Only its owner can change it.
+// @Tags saved_searches
+// @Accept json
+// @Produce json
+// @Param id path string true "Saved search ID"
+// @Param body body dto.SavedSearchRequest true "Saved search"
+// @Success 200 {object} dto.SavedSearchResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 409 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /saved-searches/{id} [put]
+func (h *SavedSearchHandler) UpdateSavedSearch(c *gin.Context) {
+	tenantID, userID, ok := h.caller(c)
+	if !ok {
+		return
+	}
+
+	var req dto.SavedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	search, err := h.service.Update(h.RequestCtx(c), tenantID, userID, c.Param("id"), req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, search)
+}
+
+// DeleteSavedSearch godoc
+// @Summary Delete a saved search
+// @Description Delete a saved search. Only its owner can delete it.
+// @Tags saved_searches
+// @Param id path string true "Saved search ID"
+// @Success 204
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /saved-searches/{id} [delete]
+func (h *SavedSearchHandler) DeleteSavedSearch(c *gin.Context) {
+	tenantID, userID, ok := h.caller(c)
+	if !ok {
+		return
+	}
+
+	err := h.service.Delete(h.RequestCtx(c), tenantID, userID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type SavedSearchHandlerTestSuite struct {
+	suite.Suite
+	router      *gin.Engine
+	mockService *MockSavedSearchService
+	handler     *SavedSearchHandler
+}
+
+type MockSavedSearchService struct {
+	mock.Mock
+}
+
+func (m *MockSavedSearchService) Create(ctx context.Context, tenantID, ownerID string, req dto.SavedSearchRequest) (*dto.SavedSearchResponse, error) {
+	args := m.Called(ctx, tenantID, ownerID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.SavedSearchResponse), args.Error(1)
+}
+
+func (m *MockSavedSearchService) List(ctx context.Context, tenantID, userID string) ([]dto.SavedSearchResponse, error) {
+	args := m.Called(ctx, tenantID, userID)
+	return args.Get(0).([]dto.SavedSearchResponse), args.Error(1)
+}
+
+func (m *MockSavedSearchService) GetFilter(ctx context.Context, tenantID, userID, id string) (*domain.SavedSearchFilter, error) {
+	args := m.Called(ctx, tenantID, userID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SavedSearchFilter), args.Error(1)
+}
+
+func (m *MockSavedSearchService) Update(ctx context.Context, tenantID, userID, id string, req dto.SavedSearchRequest) (*dto.SavedSearchResponse, error) {
+	args := m.Called(ctx, tenantID, userID, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.SavedSearchResponse), args.Error(1)
+}
+
+func (m *MockSavedSearchService) Delete(ctx context.Context, tenantID, userID, id string) error {
+	args := m.Called(ctx, tenantID, userID, id)
+	return args.Error(0)
+}
+
+func (s *SavedSearchHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.mockService = new(MockSavedSearchService)
+	s.handler = NewSavedSearchHandler(s.mockService)
+
+	// Setup routes with the tenant and user the JWT middleware would set
+	searches := s.router.Group("/saved-searches", func(c *gin.Context) {
+		c.Set(string(contextutils.TenantIDKey), "tenant1")
+		c.Set(string(contextutils.UserIDKey), "user1")
+	})
+	searches.POST("", s.handler.CreateSavedSearch)
+	searches.GET("", s.handler.ListSavedSearches)
+	searches.PUT("/:id", s.handler.UpdateSavedSearch)
+	searches.DELETE("/:id", s.handler.DeleteSavedSearch)
+}
+
+func TestSavedSearchHandler(t *testing.T) {
+	suite.Run(t, new(SavedSearchHandlerTestSuite))
+}
+
+func (s *SavedSearchHandlerTestSuite) TestCreateSavedSearch_Success() {
+	// Arrange
+	req := dto.SavedSearchRequest{Name: "failed logins", Filter: dto.SavedSearchFilter{Action: "login", Severity: "ERROR", Lookback: "24h"}, Shared: true}
+	s.mockService.On("Create", mock.Anything, "tenant1", "user1", req).
+		Return(&dto.SavedSearchResponse{ID: "search1", TenantID: "tenant1", OwnerID: "user1", Name: req.Name, Filter: req.Filter, Shared: true}, nil)
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/saved-searches", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusCreated, w.Code)
+	var response dto.SavedSearchResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal("search1", response.ID)
+	s.Equal("24h", response.Filter.Lookback)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *SavedSearchHandlerTestSuite) TestCreateSavedSearch_Invalid() {
+	// Arrange
+	req := dto.SavedSearchRequest{Name: "bad", Filter: dto.SavedSearchFilter{Lookback: "-1h"}}
+	s.mockService.On("Create", mock.Anything, "tenant1", "user1", req).Return(nil, service.ErrInvalidSavedSearch)
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/saved-searches", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *SavedSearchHandlerTestSuite) TestCreateSavedSearch_Exists() {
+	// Arrange
+	req := dto.SavedSearchRequest{Name: "failed logins", Filter: dto.SavedSearchFilter{Lookback: "1h"}}
+	s.mockService.On("Create", mock.Anything, "tenant1", "user1", req).Return(nil, service.ErrSavedSearchExists)
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/saved-searches", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusConflict, w.Code)
+}
+
+func (s *SavedSearchHandlerTestSuite) TestUpdateSavedSearch_NotOwner() {
+	// Arrange
+	req := dto.SavedSearchRequest{Name: "failed logins", Filter: dto.SavedSearchFilter{Lookback: "1h"}}
+	s.mockService.On("Update", mock.Anything, "tenant1", "user1", "search1", req).Return(nil, service.ErrSavedSearchNotOwner)
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPut, "/saved-searches/search1", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusForbidden, w.Code)
+}
+
+func (s *SavedSearchHandlerTestSuite) TestDeleteSavedSearch_NotFound() {
+	// Arrange
+	s.mockService.On("Delete", mock.Anything, "tenant1", "user1", "missing").Return(service.ErrSavedSearchNotFound)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodDelete, "/saved-searches/missing", nil)
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+}
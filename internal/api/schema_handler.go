@@ -0,0 +1,183 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
+)
+
+//go:generate mockery --name SchemaService --output ../mocks
+type SchemaService interface {
+	Create(ctx context.Context, tenantID string, req dto.ResourceSchemaRequest) (*dto.ResourceSchemaResponse, error)
+	List(ctx context.Context, tenantID string) ([]dto.ResourceSchemaResponse, error)
+	Get(ctx context.Context, tenantID, id string) (*dto.ResourceSchemaResponse, error)
+	Update(ctx context.Context, tenantID, id string, req dto.ResourceSchemaRequest) (*dto.ResourceSchemaResponse, error)
+	Delete(ctx context.Context, tenantID, id string) error
+}
+
+type SchemaHandler struct {
+	*BaseHandler
+	service SchemaService
+}
+
+func NewSchemaHandler(service SchemaService) *SchemaHandler {
+	return &SchemaHandler{service: service}
+}
+
+// CreateSchema godoc
+// @Summary Register a resource schema
+// @Description Register JSON Schemas (draft 2020-12 by default) that the metadata, before_state and after_state of the tenant's logs of a resource type must conform to from now on. Logs that don't are rejected in reject mode and stored with their violations in schema_errors in flag mode. Metadata of logs ingested through the API carries a request_id.
+// @Tags schemas
+// @Accept json
+// @Produce json
+// @Param body body dto.ResourceSchemaRequest true "Resource schema"
+// @Success 201 {object} dto.ResourceSchemaResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 409 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /schemas [post]
+func (h *SchemaHandler) CreateSchema(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	var req dto.ResourceSchemaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	schema, err := h.service.Create(h.RequestCtx(c), tenantID, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, schema)
+}
+
+// ListSchemas godoc
+// @Summary List resource schemas
+// @Description List the resource schemas of the authenticated tenant
+// @Tags schemas
+// @Produce json
+// @Success 200 {array} dto.ResourceSchemaResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /schemas [get]
+func (h *SchemaHandler) ListSchemas(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	schemas, err := h.service.List(h.RequestCtx(c), tenantID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas)
+}
+
+// GetSchema godoc
+// @Summary Get a resource schema
+// @Description Get a resource schema of the authenticated tenant
+// @Tags schemas
+// @Produce json
+// @Param id path string true "Resource schema ID"
+// @Success 200 {object} dto.ResourceSchemaResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /schemas/{id} [get]
+func (h *SchemaHandler) GetSchema(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	schema, err := h.service.Get(h.RequestCtx(c), tenantID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schema)
+}
+
+// UpdateSchema godoc
+// @Summary Update a resource schema
+// @Description Replace a resource schema of the authenticated tenant. Logs already stored are not checked again.
+// @Tags schemas
+// @Accept json
+// @Produce json
+// @Param id path string true "Resource schema ID"
+// @Param body body dto.ResourceSchemaRequest true "Resource schema"
+// @Success 200 {object} dto.ResourceSchemaResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 409 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /schemas/{id} [put]
+func (h *SchemaHandler) UpdateSchema(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	var req dto.ResourceSchemaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	schema, err := h.service.Update(h.RequestCtx(c), tenantID, c.Param("id"), req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schema)
+}
+
+// DeleteSchema godoc
+// @Summary Delete a resource schema
+// @Description Delete a resource schema of the authenticated tenant, so its logs are no longer checked
+// @Tags schemas
+// @Param id path string true "Resource schema ID"
+// @Success 204
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /schemas/{id} [delete]
+func (h *SchemaHandler) DeleteSchema(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	if err := h.service.Delete(h.RequestCtx(c), tenantID, c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type SchemaHandlerTestSuite struct {
+	suite.Suite
+	router      *gin.Engine
+	mockService *MockSchemaService
+	handler     *SchemaHandler
+}
+
+type MockSchemaService struct {
+	mock.Mock
+}
+
+func (m *MockSchemaService) Create(ctx context.Context, tenantID string, req dto.ResourceSchemaRequest) (*dto.ResourceSchemaResponse, error) {
+	args := m.Called(ctx, tenantID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ResourceSchemaResponse), args.Error(1)
+}
+
+func (m *MockSchemaService) List(ctx context.Context, tenantID string) ([]dto.ResourceSchemaResponse, error) {
+	args := m.Called(ctx, tenantID)
+	return args.Get(0).([]dto.ResourceSchemaResponse), args.Error(1)
+}
+
+func (m *MockSchemaService) Get(ctx context.Context, tenantID, id string) (*dto.ResourceSchemaResponse, error) {
+	args := m.Called(ctx, tenantID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ResourceSchemaResponse), args.Error(1)
+}
+
+func (m *MockSchemaService) Update(ctx context.Context, tenantID, id string, req dto.ResourceSchemaRequest) (*dto.ResourceSchemaResponse, error) {
+	args := m.Called(ctx, tenantID, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ResourceSchemaResponse), args.Error(1)
+}
+
+func (m *MockSchemaService) Delete(ctx context.Context, tenantID, id string) error {
+	args := m.Called(ctx, tenantID, id)
+	return args.Error(0)
+}
+
+func (s *SchemaHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.mockService = new(MockSchemaService)
+	s.handler = NewSchemaHandler(s.mockService)
+
+	// Setup routes with the tenant the JWT middleware would set
+	schemas := s.router.Group("/schemas", func(c *gin.Context) {
+		c.Set(string(contextutils.TenantIDKey), "tenant1")
+	})
+	schemas.POST("", s.handler.CreateSchema)
+	schemas.GET("", s.handler.ListSchemas)
+	schemas.GET("/:id", s.handler.GetSchema)
+	schemas.PUT("/:id", s.handler.UpdateSchema)
+	schemas.DELETE("/:id", s.handler.DeleteSchema)
+}
+
+func TestSchemaHandler(t *testing.T) {
+	suite.Run(t, new(SchemaHandlerTestSuite))
+}
+
+func (s *SchemaHandlerTestSuite) TestCreateSchema_Success() {
+	// Arrange
+	s.mockService.On("Create", mock.Anything, "tenant1", mock.MatchedBy(func(req dto.ResourceSchemaRequest) bool {
+		return req.ResourceType == "user" && req.Mode == "reject"
+	})).Return(&dto.ResourceSchemaResponse{ID: "schema1", TenantID: "tenant1", ResourceType: "user", Mode: "reject"}, nil)
+
+	body := []byte(`{"resource_type":"user","metadata_schema":{"type":"object"},"mode":"reject"}`)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/schemas", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusCreated, w.Code)
+	var response dto.ResourceSchemaResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal("schema1", response.ID)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *SchemaHandlerTestSuite) TestCreateSchema_InvalidMode() {
+	// Arrange
+	body := []byte(`{"resource_type":"user","metadata_schema":{"type":"object"},"mode":"warn"}`)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/schemas", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *SchemaHandlerTestSuite) TestCreateSchema_Conflict() {
+	// Arrange
+	s.mockService.On("Create", mock.Anything, "tenant1", mock.Anything).Return(nil, service.ErrResourceSchemaExists)
+
+	body := []byte(`{"resource_type":"user","state_schema":{"type":"object"},"mode":"flag"}`)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/schemas", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusConflict, w.Code)
+}
+
+func (s *SchemaHandlerTestSuite) TestGetSchema_NotFound() {
+	// Arrange
+	s.mockService.On("Get", mock.Anything, "tenant1", "missing").Return(nil, service.ErrResourceSchemaNotFound)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodGet, "/schemas/missing", nil)
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *SchemaHandlerTestSuite) TestDeleteSchema_Success() {
+	// Arrange
+	s.mockService.On("Delete", mock.Anything, "tenant1", "schema1").Return(nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodDelete, "/schemas/schema1", nil)
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusNoContent, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
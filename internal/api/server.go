@@ -1,8 +1,15 @@
 package api
 
 import (
+	"net/http"
+	"strings"
+	"time"
+
 	"github.com/gin-gonic/gin"
 
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/ingest"
 	"github.com/kingrain94/audit-log-api/internal/middleware"
 	"github.com/kingrain94/audit-log-api/internal/service"
 	"github.com/kingrain94/audit-log-api/internal/service/pubsub"
@@ -10,70 +17,360 @@ import (
 )
 
 type Server struct {
-	tenant     *TenantHandler
-	auditLog   *AuditLogHandler
-	websocket  *WebSocketHandler
-	auth       *middleware.AuthMiddleware
-	rateLimit  *middleware.RateLimitMiddleware
-	validation *middleware.ValidationMiddleware
+	tenant      *TenantHandler
+	auditLog    *AuditLogHandler
+	user        *UserHandler
+	authn       *AuthHandler
+	policy      *PolicyHandler
+	redaction   *RedactionHandler
+	tagging     *TaggingHandler
+	schema      *SchemaHandler
+	savedSearch *SavedSearchHandler
+	integration *IntegrationHandler
+	otlp        *OTLPHandler
+	shipper     *ShipperHandler
+	admin       *AdminHandler
+	job         *JobHandler
+	archive     *ArchiveHandler
+	imports     *ImportHandler
+	websocket   *WebSocketHandler
+	auth        *middleware.AuthMiddleware
+	policies    *middleware.PolicyMiddleware
+	rateLimit   *middleware.RateLimitMiddleware
+	validation  *middleware.ValidationMiddleware
+	settings    *middleware.TenantSettingsMiddleware
+	metaAudit   *middleware.MetaAuditMiddleware
+	timeouts    *config.QueryTimeoutConfig
+	logger      *logger.Logger
 }
 
 func NewServer(
 	tenantService *service.TenantService,
+	usageService *service.UsageService,
 	auditLogService *service.AuditLogService,
+	userService *service.UserService,
+	authService *service.AuthService,
+	policyService *service.PolicyService,
+	redactionService *service.RedactionService,
+	taggingService *service.TaggingService,
+	schemaService *service.SchemaService,
+	savedSearchService *service.SavedSearchService,
+	integrationService *service.IntegrationService,
+	configService ConfigService,
+	indexFailureService *service.IndexFailureService,
+	searchIndexService *service.SearchIndexService,
+	jobService *service.JobService,
+	archiveService *service.ArchiveService,
+	importService *service.ImportService,
+	dbPoolService *service.DBPoolService,
 	auth *middleware.AuthMiddleware,
+	policies *middleware.PolicyMiddleware,
 	rateLimit *middleware.RateLimitMiddleware,
 	validation *middleware.ValidationMiddleware,
+	settings *middleware.TenantSettingsMiddleware,
+	metaAudit *middleware.MetaAuditMiddleware,
+	timeouts *config.QueryTimeoutConfig,
 	logger *logger.Logger,
-	pubsub *pubsub.RedisPubSub,
+	pubsub pubsub.Broker,
+	shipperTemplates map[string]*ingest.ShipperTemplate,
 ) *Server {
 	return &Server{
-		tenant:     NewTenantHandler(tenantService),
-		auditLog:   NewAuditLogHandler(auditLogService),
-		websocket:  NewWebSocketHandler(auditLogService, logger, pubsub),
-		auth:       auth,
-		rateLimit:  rateLimit,
-		validation: validation,
+		tenant:      NewTenantHandler(tenantService, usageService, auditLogService),
+		auditLog:    NewAuditLogHandler(auditLogService, savedSearchService),
+		user:        NewUserHandler(userService),
+		authn:       NewAuthHandler(authService),
+		policy:      NewPolicyHandler(policyService),
+		redaction:   NewRedactionHandler(redactionService),
+		tagging:     NewTaggingHandler(taggingService),
+		schema:      NewSchemaHandler(schemaService),
+		savedSearch: NewSavedSearchHandler(savedSearchService),
+		integration: NewIntegrationHandler(integrationService),
+		otlp:        NewOTLPHandler(auditLogService),
+		shipper:     NewShipperHandler(auditLogService, shipperTemplates),
+		admin:       NewAdminHandler(configService, indexFailureService, searchIndexService, dbPoolService),
+		job:         NewJobHandler(jobService),
+		archive:     NewArchiveHandler(archiveService),
+		imports:     NewImportHandler(importService),
+		websocket:   NewWebSocketHandler(auditLogService, tenantService, logger, pubsub),
+		auth:        auth,
+		policies:    policies,
+		rateLimit:   rateLimit,
+		validation:  validation,
+		settings:    settings,
+		metaAudit:   metaAudit,
+		timeouts:    timeouts,
+		logger:      logger,
 	}
 }
 
-func (s *Server) SetupRoutes(api *gin.RouterGroup) {
+// maxRequestSize bounds API request bodies, after decompression for
+// compressed ones
+const maxRequestSize = 10 * 1024 * 1024
+
+// useCommonMiddleware applies the error handling, input validation and
+// global rate limiting every version of the API shares
+func (s *Server) useCommonMiddleware(api *gin.RouterGroup) {
+	// Outermost, so it sees the errors of every middleware and handler
+	api.Use(ErrorHandler(s.logger))
+
 	// Apply security middleware first
 	api.Use(s.validation.BlockSuspiciousPatterns())
 	api.Use(s.validation.SanitizeInput())
-	api.Use(s.validation.ValidateRequestSize(10 * 1024 * 1024)) // 10MB max
+	api.Use(s.validation.ValidateRequestSize(maxRequestSize))
 	api.Use(s.validation.ValidateContentType("application/json", "text/plain"))
 
 	// Apply global rate limiting
-	api.Use(s.rateLimit.GlobalRateLimit(10000)) // 10k requests per minute per IP
+	api.Use(s.rateLimit.GlobalRateLimit(rateLimitRoute))
+
+	api.Use(middleware.Timeout(s.requestTimeout))
+}
+
+// rateLimitRoute picks the rate limit route class of a request from its
+// matched route, before authentication: log ingestion or anything else
+func rateLimitRoute(c *gin.Context) middleware.RateLimitRoute {
+	if c.Request.Method == http.MethodPost {
+		if route := c.FullPath(); strings.HasSuffix(route, "/logs") || strings.HasSuffix(route, "/logs/bulk") || strings.HasSuffix(route, "/logs/ingest/:template") {
+			return middleware.RateLimitIngest
+		}
+	}
+	return middleware.RateLimitQuery
+}
+
+// requestTimeout picks the timeout of a request by its endpoint class:
+// ingestion, exports, reports and archive verifications and searches, or any
+// other query. Streams have none.
+func (s *Server) requestTimeout(c *gin.Context) time.Duration {
+	route := c.FullPath()
+	switch {
+	case strings.HasSuffix(route, "/stream") || strings.HasSuffix(route, "/sse"):
+		return 0
+	case rateLimitRoute(c) == middleware.RateLimitIngest:
+		return s.timeouts.Ingest
+	case strings.Contains(route, "/logs/export") || strings.HasSuffix(route, "/logs/report") || strings.HasSuffix(route, "/verify") || strings.HasSuffix(route, "/archive/search"):
+		return s.timeouts.Export
+	}
+	return s.timeouts.Query
+}
+
+// SetupRoutes mounts the routes of /api/v1, whose responses are frozen
+func (s *Server) SetupRoutes(api *gin.RouterGroup) {
+	s.useCommonMiddleware(api)
 
 	{
-		tenants := api.Group("/tenants", s.auth.JWTAuth(), s.rateLimit.TenantRateLimit(), s.auth.RequireRole("admin"))
+		ingest := s.rateLimit.TenantRateLimit(middleware.RateLimitIngest)
+		query := s.rateLimit.TenantRateLimit(middleware.RateLimitQuery)
+		// Queries and administrative calls are meta-audited, log ingestion isn't
+		audit := s.metaAudit.Record()
+
+		authGroup := api.Group("/auth")
+		{
+			authGroup.POST("/token", s.authn.IssueToken)
+			authGroup.POST("/refresh", s.authn.RefreshToken)
+			authGroup.POST("/revoke", s.auth.JWTAuth(), s.authn.RevokeToken)
+		}
+
+		allow := s.policies.Authorize
+
+		tenants := api.Group("/tenants", s.auth.JWTAuth(), query, audit)
+		{
+			tenants.POST("", allow(domain.PolicyResourceTenants, domain.PolicyActionCreate), s.tenant.CreateTenant)
+			tenants.GET("", allow(domain.PolicyResourceTenants, domain.PolicyActionRead), s.tenant.ListTenants)
+			tenants.DELETE("/:id", s.auth.RequirePlatformAdmin(), allow(domain.PolicyResourceTenants, domain.PolicyActionDelete), s.tenant.DeleteTenant)
+			tenants.POST("/:id/restore", s.auth.RequirePlatformAdmin(), allow(domain.PolicyResourceTenants, domain.PolicyActionRestore), s.tenant.RestoreTenant)
+			tenants.GET("/:id/rate-limit", s.auth.RequirePlatformAdmin(), allow(domain.PolicyResourceTenants, domain.PolicyActionRead), s.tenant.GetTenantRateLimit)
+			tenants.PUT("/:id/rate-limit", s.auth.RequirePlatformAdmin(), allow(domain.PolicyResourceTenants, domain.PolicyActionUpdate), s.tenant.UpdateTenantRateLimit)
+			tenants.GET("/:id/settings", allow(domain.PolicyResourceTenants, domain.PolicyActionRead), s.tenant.GetTenantSettings)
+			tenants.PUT("/:id/settings", allow(domain.PolicyResourceTenants, domain.PolicyActionUpdate), s.tenant.UpdateTenantSettings)
+			tenants.GET("/:id/archive-schedule", allow(domain.PolicyResourceTenants, domain.PolicyActionRead), s.tenant.GetTenantArchiveSchedule)
+			tenants.PUT("/:id/archive-schedule", allow(domain.PolicyResourceTenants, domain.PolicyActionUpdate), s.tenant.UpdateTenantArchiveSchedule)
+			tenants.POST("/:id/export", allow(domain.PolicyResourceTenants, domain.PolicyActionExport), s.tenant.ExportTenant)
+			tenants.GET("/:id/export/:job_id", allow(domain.PolicyResourceTenants, domain.PolicyActionExport), s.tenant.GetTenantExport)
+			tenants.GET("/:id/usage", allow(domain.PolicyResourceTenants, domain.PolicyActionRead), s.tenant.GetTenantUsage)
+		}
+
+		users := api.Group("/users", s.auth.JWTAuth(), query, audit)
+		{
+			users.POST("", allow(domain.PolicyResourceUsers, domain.PolicyActionCreate), s.user.CreateUser)
+			users.GET("", allow(domain.PolicyResourceUsers, domain.PolicyActionRead), s.user.ListUsers)
+			users.PUT("/:id/roles", allow(domain.PolicyResourceUsers, domain.PolicyActionUpdate), s.user.UpdateUserRoles)
+			users.POST("/:id/deactivate", allow(domain.PolicyResourceUsers, domain.PolicyActionUpdate), s.user.DeactivateUser)
+		}
+
+		policies := api.Group("/policies", s.auth.JWTAuth(), query, audit)
+		{
+			policies.POST("", allow(domain.PolicyResourcePolicies, domain.PolicyActionCreate), s.policy.CreatePolicy)
+			policies.GET("", allow(domain.PolicyResourcePolicies, domain.PolicyActionRead), s.policy.ListPolicies)
+			policies.PUT("/:id", allow(domain.PolicyResourcePolicies, domain.PolicyActionUpdate), s.policy.UpdatePolicy)
+			policies.DELETE("/:id", allow(domain.PolicyResourcePolicies, domain.PolicyActionDelete), s.policy.DeletePolicy)
+		}
+
+		redactionRules := api.Group("/redaction-rules", s.auth.JWTAuth(), query, audit)
+		{
+			redactionRules.POST("", allow(domain.PolicyResourceRedactionRules, domain.PolicyActionCreate), s.redaction.CreateRedactionRule)
+			redactionRules.GET("", allow(domain.PolicyResourceRedactionRules, domain.PolicyActionRead), s.redaction.ListRedactionRules)
+			redactionRules.DELETE("/:id", allow(domain.PolicyResourceRedactionRules, domain.PolicyActionDelete), s.redaction.DeleteRedactionRule)
+		}
+
+		taggingRules := api.Group("/tagging-rules", s.auth.JWTAuth(), query, audit)
+		{
+			taggingRules.POST("", allow(domain.PolicyResourceTaggingRules, domain.PolicyActionCreate), s.tagging.CreateTaggingRule)
+			taggingRules.GET("", allow(domain.PolicyResourceTaggingRules, domain.PolicyActionRead), s.tagging.ListTaggingRules)
+			taggingRules.DELETE("/:id", allow(domain.PolicyResourceTaggingRules, domain.PolicyActionDelete), s.tagging.DeleteTaggingRule)
+		}
+
+		schemas := api.Group("/schemas", s.auth.JWTAuth(), query, audit)
+		{
+			schemas.POST("", allow(domain.PolicyResourceSchemas, domain.PolicyActionCreate), s.schema.CreateSchema)
+			schemas.GET("", allow(domain.PolicyResourceSchemas, domain.PolicyActionRead), s.schema.ListSchemas)
+			schemas.GET("/:id", allow(domain.PolicyResourceSchemas, domain.PolicyActionRead), s.schema.GetSchema)
+			schemas.PUT("/:id", allow(domain.PolicyResourceSchemas, domain.PolicyActionUpdate), s.schema.UpdateSchema)
+			schemas.DELETE("/:id", allow(domain.PolicyResourceSchemas, domain.PolicyActionDelete), s.schema.DeleteSchema)
+		}
+
+		integrations := api.Group("/integrations", s.auth.JWTAuth(), query, audit)
+		{
+			integrations.POST("", allow(domain.PolicyResourceIntegrations, domain.PolicyActionCreate), s.integration.CreateIntegration)
+			integrations.GET("", allow(domain.PolicyResourceIntegrations, domain.PolicyActionRead), s.integration.ListIntegrations)
+			integrations.GET("/:id", allow(domain.PolicyResourceIntegrations, domain.PolicyActionRead), s.integration.GetIntegration)
+			integrations.PUT("/:id", allow(domain.PolicyResourceIntegrations, domain.PolicyActionUpdate), s.integration.UpdateIntegration)
+			integrations.DELETE("/:id", allow(domain.PolicyResourceIntegrations, domain.PolicyActionDelete), s.integration.DeleteIntegration)
+		}
+
+		savedSearches := api.Group("/saved-searches", s.auth.JWTAuth(), query, audit)
+		{
+			savedSearches.POST("", allow(domain.PolicyResourceSavedSearches, domain.PolicyActionCreate), s.savedSearch.CreateSavedSearch)
+			savedSearches.GET("", allow(domain.PolicyResourceSavedSearches, domain.PolicyActionRead), s.savedSearch.ListSavedSearches)
+			savedSearches.PUT("/:id", allow(domain.PolicyResourceSavedSearches, domain.PolicyActionUpdate), s.savedSearch.UpdateSavedSearch)
+			savedSearches.DELETE("/:id", allow(domain.PolicyResourceSavedSearches, domain.PolicyActionDelete), s.savedSearch.DeleteSavedSearch)
+		}
+
+		admin := api.Group("/admin", s.auth.JWTAuth(), query, audit)
+		{
+			admin.GET("/config", allow(domain.PolicyResourceConfig, domain.PolicyActionRead), s.admin.GetConfig)
+			admin.GET("/index-failures", allow(domain.PolicyResourceIndexFailures, domain.PolicyActionRead), s.admin.ListIndexFailures)
+			admin.POST("/index-failures/reprocess", allow(domain.PolicyResourceIndexFailures, domain.PolicyActionUpdate), s.admin.ReprocessIndexFailures)
+			admin.GET("/indices", allow(domain.PolicyResourceIndices, domain.PolicyActionRead), s.admin.ListIndices)
+			admin.POST("/indices", allow(domain.PolicyResourceIndices, domain.PolicyActionCreate), s.admin.EnsureIndices)
+			admin.POST("/indices/reindex", allow(domain.PolicyResourceIndices, domain.PolicyActionUpdate), s.admin.ReindexLogs)
+			admin.DELETE("/indices/:day", allow(domain.PolicyResourceIndices, domain.PolicyActionDelete), s.admin.DeleteIndex)
+
+			// The pools are the instance's, shared by every tenant
+			dbPools := admin.Group("/db-pools", s.auth.RequirePlatformAdmin())
+			dbPools.GET("", s.admin.ListDBPools)
+			dbPools.PUT("/:pool", s.admin.UpdateDBPool)
+		}
+
+		jobs := api.Group("/jobs", s.auth.JWTAuth(), query, audit)
+		{
+			jobs.GET("", allow(domain.PolicyResourceJobs, domain.PolicyActionRead), s.job.ListJobs)
+			jobs.GET("/:id", allow(domain.PolicyResourceJobs, domain.PolicyActionRead), s.job.GetJob)
+		}
+
+		archives := api.Group("/archives", s.auth.JWTAuth(), query, audit)
 		{
-			tenants.POST("", s.tenant.CreateTenant)
-			tenants.GET("", s.tenant.ListTenants)
+			archives.GET("", allow(domain.PolicyResourceArchives, domain.PolicyActionRead), s.archive.ListArchives)
+			archives.POST("/:id/verify", allow(domain.PolicyResourceArchives, domain.PolicyActionUpdate), s.archive.VerifyArchive)
 		}
 
-		logs := api.Group("/logs", s.auth.JWTAuth(), s.rateLimit.TenantRateLimit(), s.auth.RequireRole("user"))
+		logs := api.Group("/logs", s.auth.JWTAuth())
 		{
-			logs.POST("", s.auditLog.CreateLog)
-			logs.GET("", s.auditLog.ListLogs)
-			logs.GET("/:id", s.auditLog.GetLog)
-			logs.GET("/export", s.auditLog.ExportLogs)
-			logs.GET("/stats", s.auditLog.GetStats)
-			logs.POST("/bulk", s.auditLog.BulkCreateLogs)
-			logs.DELETE("/cleanup", s.auth.RequireRole("auditor"), s.auditLog.Cleanup)
-			logs.GET("/stream", s.websocket.HandleWebSocket)
+			read := allow(domain.PolicyResourceLogs, domain.PolicyActionRead)
+			export := allow(domain.PolicyResourceLogs, domain.PolicyActionExport)
+			restore := allow(domain.PolicyResourceLogs, domain.PolicyActionRestore)
+			validateActions := s.settings.ValidateActions()
+
+			logs.POST("", ingest, allow(domain.PolicyResourceLogs, domain.PolicyActionCreate), validateActions, s.auditLog.CreateLog)
+			logs.GET("", query, audit, read, middleware.Compress(), s.auditLog.ListLogs)
+			logs.GET("/:id", query, audit, read, s.auditLog.GetLog)
+			logs.GET("/:id/diff", query, audit, read, s.auditLog.GetLogDiff)
+			logs.POST("/batch-get", query, audit, read, s.auditLog.BatchGetLogs)
+			logs.GET("/correlation/:correlation_id", query, audit, read, s.auditLog.GetCorrelatedLogs)
+			logs.GET("/export", query, audit, export, middleware.Compress(), s.auditLog.ExportLogs)
+			logs.POST("/export", query, audit, export, s.auditLog.CreateExportJob)
+			logs.GET("/export/signing-keys", query, audit, export, s.auditLog.GetExportSigningKeys)
+			logs.GET("/export/:job_id", query, audit, export, s.auditLog.GetExportJob)
+			logs.GET("/export/:job_id/signature", query, audit, export, s.auditLog.GetExportSignature)
+			logs.GET("/stats", query, audit, read, s.auditLog.GetStats)
+			logs.GET("/stats/top", query, audit, read, s.auditLog.GetTopStats)
+			logs.GET("/report", query, audit, export, s.auditLog.GetReport)
+			logs.POST("/bulk", middleware.DecompressRequest(maxRequestSize), ingest, allow(domain.PolicyResourceLogs, domain.PolicyActionCreate), validateActions, s.auditLog.BulkCreateLogs)
+			logs.POST("/ingest/:template", middleware.DecompressRequest(maxRequestSize), ingest, allow(domain.PolicyResourceLogs, domain.PolicyActionCreate), s.shipper.IngestShipperLogs)
+			logs.DELETE("/cleanup", query, audit, allow(domain.PolicyResourceLogs, domain.PolicyActionDelete), s.auditLog.Cleanup)
+			logs.POST("/restore", query, audit, restore, s.auditLog.RestoreLogs)
+			logs.GET("/restore/:job_id", query, audit, restore, s.auditLog.GetRestoreJob)
+			logs.GET("/archive/search", query, audit, read, s.archive.SearchArchivedLogs)
+			logs.GET("/import/:job_id", query, audit, allow(domain.PolicyResourceLogs, domain.PolicyActionImport), s.imports.GetImportJob)
+			logs.POST("/stream/ticket", query, audit, read, s.authn.IssueStreamTicket)
+
+			// Streams also take a one-time ?ticket= in place of the Authorization header
+			streams := api.Group("/logs", s.auth.StreamAuth(), query, audit, read)
+			streams.GET("/stream", s.websocket.HandleWebSocket)
+			streams.GET("/sse", s.websocket.HandleSSE)
+		}
+
+		// Streams of several tenants for the platform's SOC dashboards
+		adminStreams := api.Group("/admin/logs", s.auth.StreamAuth(), query, audit, s.auth.RequirePlatformAdmin())
+		{
+			adminStreams.GET("/stream", s.websocket.HandleAdminWebSocket)
+			adminStreams.GET("/sse", s.websocket.HandleAdminSSE)
 		}
 	}
 }
 
+// SetupV2Routes mounts the routes of /api/v2, which lists logs and stats in
+// envelopes with cursor pagination in the body. They share their handlers and
+// the service layer with /api/v1, which serves every other route.
+func (s *Server) SetupV2Routes(api *gin.RouterGroup) {
+	s.useCommonMiddleware(api)
+
+	query := s.rateLimit.TenantRateLimit(middleware.RateLimitQuery)
+	logs := api.Group("/logs", s.auth.JWTAuth(), query, s.metaAudit.Record(),
+		s.policies.Authorize(domain.PolicyResourceLogs, domain.PolicyActionRead))
+	{
+		logs.GET("", middleware.Compress(), s.auditLog.ListLogsV2)
+		logs.GET("/stats", s.auditLog.GetStatsV2)
+	}
+}
+
+// SetupOTLPRoutes mounts the OTLP/HTTP logs receiver at /v1/logs, the path
+// OpenTelemetry exporters post to by default. It takes protobuf bodies, so it
+// skips the JSON input validation of the API routes.
+func (s *Server) SetupOTLPRoutes(router gin.IRouter) {
+	otlp := router.Group("/v1",
+		ErrorHandler(s.logger),
+		s.validation.ValidateRequestSize(maxOTLPRequestSize),
+		s.validation.ValidateContentType(ingest.OTLPProtobufContentType),
+		s.rateLimit.GlobalRateLimit(rateLimitRoute),
+		middleware.Timeout(func(*gin.Context) time.Duration { return s.timeouts.Ingest }),
+		s.auth.JWTAuth(),
+		s.rateLimit.TenantRateLimit(middleware.RateLimitIngest),
+	)
+	otlp.POST("/logs", s.policies.Authorize(domain.PolicyResourceLogs, domain.PolicyActionCreate), s.otlp.ExportLogs)
+}
+
+// maxImportUploadSize bounds import uploads, which IMPORT_MAX_UPLOAD_SIZE
+// lowers, at the largest object a single S3 PUT accepts
+const maxImportUploadSize = 5 * 1024 * 1024 * 1024
+
+// SetupImportRoutes mounts POST /api/v1/logs/import outside the /api/v1
+// group, since uploads are streamed to S3: they skip the JSON input
+// validation, size limit and timeout of the API routes.
+func (s *Server) SetupImportRoutes(router gin.IRouter) {
+	imports := router.Group("/api/v1/logs",
+		ErrorHandler(s.logger),
+		s.validation.BlockSuspiciousPatterns(),
+		s.validation.SanitizeInput(),
+		s.validation.ValidateRequestSize(maxImportUploadSize),
+		s.validation.ValidateContentType("application/json", ndjsonContentType, gzipContentType),
+		s.rateLimit.GlobalRateLimit(rateLimitRoute),
+		s.auth.JWTAuth(),
+		s.rateLimit.TenantRateLimit(middleware.RateLimitQuery),
+		s.metaAudit.Record(),
+	)
+	imports.POST("/import", s.policies.Authorize(domain.PolicyResourceLogs, domain.PolicyActionImport), s.imports.ImportLogs)
+}
+
 // StartWebSocketHub starts the WebSocket hub for broadcasting logs
 func (s *Server) StartWebSocketHub() {
 	go s.websocket.Start()
 }
-
-// GetWebSocketHandler returns the WebSocket handler for wiring up broadcasting
-func (s *Server) GetWebSocketHandler() *WebSocketHandler {
-	return s.websocket
-}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/ingest"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
+)
+
+// maxShipperRecordErrors bounds the dropped records a response explains
+const maxShipperRecordErrors = 100
+
+//go:generate mockery --name ShipperLogService --output ../mocks
+type ShipperLogService interface {
+	BulkCreate(ctx context.Context, reqs []dto.CreateAuditLogRequest) error
+}
+
+type ShipperHandler struct {
+	*BaseHandler
+	service   ShipperLogService
+	templates map[string]*ingest.ShipperTemplate
+}
+
+func NewShipperHandler(service ShipperLogService, templates map[string]*ingest.ShipperTemplate) *ShipperHandler {
+	return &ShipperHandler{service: service, templates: templates}
+}
+
+// IngestShipperLogs godoc
+// @Summary Ingest logs from a log shipper
+// @Description Store the records Fluent Bit's and Logstash's http outputs send as audit logs of the authenticated tenant, mapping their fields with a template: the built-in fluentbit or logstash, or one of SHIPPER_TEMPLATES_FILE. The body is a JSON array of records (Fluent Bit's json format, Logstash's json_batch), a single record (Logstash's json) or NDJSON (Fluent Bit's json_lines), optionally compressed with Content-Encoding gzip or deflate; the 10MB size limit applies to the decompressed body. Records are kept in the logs' metadata. Records whose timestamp is in no known format or whose log fails validation are dropped and counted in rejected, so shippers don't retry them.
+// @Tags audit_logs
+// @Accept json
+// @Produce json
+// @Param template path string true "Template mapping the records' fields, e.g. fluentbit or logstash"
+// @Param Content-Encoding header string false "gzip or deflate for a compressed body"
+// @Success 200 {object} dto.ShipperIngestResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error "Unknown template"
+// @Failure 413 {object} dto.Error
+// @Failure 429 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /logs/ingest/{template} [post]
+func (h *ShipperHandler) IngestShipperLogs(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	template, ok := h.templates[strings.ToLower(c.Param("template"))]
+	if !ok {
+		respondError(c, errNotFound("Unknown shipper template "+c.Param("template")))
+		return
+	}
+
+	records, err := ingest.ParseShipperBatch(c.Request.Body)
+	if err != nil {
+		bindError(c, err)
+		return
+	}
+
+	resp := dto.ShipperIngestResponse{}
+	logs := make([]dto.CreateAuditLogRequest, 0, len(records))
+	for i, record := range records {
+		log, err := template.Convert(record, tenantID)
+		if err == nil {
+			err = binding.Validator.ValidateStruct(&log)
+		}
+		if err != nil {
+			resp.Rejected++
+			if len(resp.Errors) < maxShipperRecordErrors {
+				resp.Errors = append(resp.Errors, shipperRecordError(i, err))
+			}
+			continue
+		}
+
+		upconvertLog(c, &log)
+		fillRequestID(c, &log)
+		logs = append(logs, log)
+	}
+
+	if len(logs) > 0 {
+		if err := h.service.BulkCreate(h.RequestCtx(c), logs); err != nil {
+			respondError(c, err)
+			return
+		}
+	}
+	resp.Accepted = len(logs)
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// shipperRecordError explains why the record at index was dropped, listing
+// the fields of its log that failed validation
+func shipperRecordError(index int, err error) dto.ShipperRecordError {
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return dto.ShipperRecordError{Record: index, Error: err.Error()}
+	}
+	return dto.ShipperRecordError{Record: index, Error: "Log validation failed", Fields: fieldErrorDetails(fieldErrs)}
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/ingest"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ShipperHandlerTestSuite struct {
+	suite.Suite
+	router      *gin.Engine
+	mockService *MockShipperLogService
+	handler     *ShipperHandler
+}
+
+type MockShipperLogService struct {
+	mock.Mock
+}
+
+func (m *MockShipperLogService) BulkCreate(ctx context.Context, reqs []dto.CreateAuditLogRequest) error {
+	args := m.Called(ctx, reqs)
+	return args.Error(0)
+}
+
+func (s *ShipperHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.mockService = new(MockShipperLogService)
+
+	templates, err := ingest.NewShipperTemplates(map[string]map[string][]string{
+		"deploys": {"action": {"=DEPLOY"}, "message": {"text"}, "resource_id": {"service"}, "timestamp": {"at"}},
+	})
+	s.Require().NoError(err)
+	s.handler = NewShipperHandler(s.mockService, templates)
+
+	// Setup routes with the tenant the JWT middleware would set
+	logs := s.router.Group("/logs", func(c *gin.Context) {
+		c.Set(string(contextutils.TenantIDKey), "tenant1")
+	})
+	logs.POST("/ingest/:template", s.handler.IngestShipperLogs)
+}
+
+func TestShipperHandler(t *testing.T) {
+	suite.Run(t, new(ShipperHandlerTestSuite))
+}
+
+func (s *ShipperHandlerTestSuite) post(path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	s.router.ServeHTTP(w, httpReq)
+	return w
+}
+
+func (s *ShipperHandlerTestSuite) TestIngestShipperLogs_MapsFluentBitBatch() {
+	// Arrange
+	var stored []dto.CreateAuditLogRequest
+	s.mockService.On("BulkCreate", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).([]dto.CreateAuditLogRequest)
+	}).Return(nil)
+	body := `[{"date":1700000000.5,"log":"payment failed","level":"error","kubernetes":{"pod_name":"billing-7d9f","labels":{"app":"billing"}}}]`
+
+	// Act
+	w := s.post("/logs/ingest/fluentbit", body)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response dto.ShipperIngestResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal(1, response.Accepted)
+	s.Equal(0, response.Rejected)
+
+	s.Require().Len(stored, 1)
+	s.Equal("tenant1", stored[0].TenantID)
+	s.Equal("payment failed", stored[0].Message)
+	s.Equal("ERROR", stored[0].Severity)
+	s.Equal("LOG", stored[0].Action)
+	s.Equal("billing", stored[0].ResourceType)
+	s.Equal("billing-7d9f", stored[0].ResourceID)
+	s.Equal(int64(1700000000), stored[0].Timestamp.Unix())
+	s.Equal(500, stored[0].Timestamp.Nanosecond()/1e6)
+	s.Contains(string(stored[0].Metadata), `"template":"fluentbit"`)
+}
+
+func (s *ShipperHandlerTestSuite) TestIngestShipperLogs_RejectsInvalidRecords() {
+	// Arrange
+	s.mockService.On("BulkCreate", mock.Anything, mock.MatchedBy(func(reqs []dto.CreateAuditLogRequest) bool {
+		return len(reqs) == 1 && reqs[0].Message == "deployed"
+	})).Return(nil)
+	body := `{"text":"deployed","service":"billing"}
+{"service":"billing"}
+{"text":"rolled back","at":"yesterday"}`
+
+	// Act
+	w := s.post("/logs/ingest/deploys", body)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response dto.ShipperIngestResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal(1, response.Accepted)
+	s.Equal(2, response.Rejected)
+	s.Require().Len(response.Errors, 2)
+	s.Equal(1, response.Errors[0].Record)
+	s.Require().Len(response.Errors[0].Fields, 1)
+	s.Equal("message", response.Errors[0].Fields[0].Field)
+	s.Equal(2, response.Errors[1].Record)
+	s.Contains(response.Errors[1].Error, "no known format")
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *ShipperHandlerTestSuite) TestIngestShipperLogs_LogstashRecord() {
+	// Arrange
+	s.mockService.On("BulkCreate", mock.Anything, mock.MatchedBy(func(reqs []dto.CreateAuditLogRequest) bool {
+		return len(reqs) == 1 && reqs[0].ResourceID == "web-1" && reqs[0].Timestamp.Year() == 2024
+	})).Return(nil)
+	body := `{"@timestamp":"2024-05-01T10:00:00.000Z","message":"user logged in","host":{"name":"web-1"},"log":{"level":"info"}}`
+
+	// Act
+	w := s.post("/logs/ingest/Logstash", body)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *ShipperHandlerTestSuite) TestIngestShipperLogs_UnknownTemplate() {
+	// Act
+	w := s.post("/logs/ingest/vector", `[{"message":"hello"}]`)
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "BulkCreate", mock.Anything, mock.Anything)
+}
+
+func (s *ShipperHandlerTestSuite) TestIngestShipperLogs_InvalidBody() {
+	// Act
+	w := s.post("/logs/ingest/fluentbit", `["not a record"]`)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "BulkCreate", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kingrain94/audit-log-api/internal/utils"
+)
+
+const (
+	sseHeartbeatInterval = 15 * time.Second
+	sseRetryMillis       = 5000
+)
+
+// HandleSSE streams the tenant's real-time logs as Server-Sent Events
+// @Summary Stream audit logs (SSE)
+// @Description Stream new audit logs of the tenant as Server-Sent Events. Each event id is the log ID; reconnect with Last-Event-ID, or since if the last log ID is unknown, to replay logs missed while disconnected.
+// @Tags    audit_logs
+// @Produce text/event-stream
+// @Param   Last-Event-ID header string false "ID of the last log received"
+// @Param   since query string false "Replay logs stored at or after this time (RFC 3339)"
+// @Param   ticket query string false "One-time ticket from POST /logs/stream/ticket, in place of the Authorization header"
+// @Success 200 {string} string "event stream"
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Router  /logs/sse [get]
+func (h *WebSocketHandler) HandleSSE(c *gin.Context) {
+	// Get tenant ID from context (set by auth middleware). tenant scope is required
+	value, exists := c.Get(string(utils.TenantIDKey))
+	tenantID, ok := value.(string)
+	if !exists || !ok {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	resume, err := parseResumePoint(lastEventID, c.Query("since"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	// Register before replaying so no log published meanwhile is lost
+	client := &Client{
+		tenantID: tenantID,
+		userID:   ownScopeUserID(c),
+		send:     make(chan streamMessage, websocketSendChannelBufferSize),
+	}
+	h.register <- client
+	defer h.unregisterClient(client)
+
+	h.serveSSE(c, client, h.replay(c.Request.Context(), client, resume))
+}
+
+// serveSSE writes the replayed logs, then the client's queued logs until it
+// disconnects. The client must be registered.
+func (h *WebSocketHandler) serveSSE(c *gin.Context, client *Client, replay []streamMessage) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Disable proxy buffering (nginx)
+	c.Status(http.StatusOK)
+
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", sseRetryMillis)
+	c.Writer.Flush()
+
+	replayed := make(map[string]struct{})
+	for _, message := range replay {
+		writeSSEEvent(c, message)
+		replayed[message.id] = struct{}{}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case message, ok := <-client.send:
+			if !ok {
+				// Closed once unregistered; the client reconnects with Last-Event-ID
+				return
+			}
+			// Logs queued meanwhile are written with it and flushed once
+			for _, message := range client.appendQueued([]streamMessage{message}) {
+				if _, dup := replayed[message.id]; !dup {
+					writeSSEEvent(c, message)
+				}
+			}
+			c.Writer.Flush()
+
+		case <-heartbeat.C:
+			// Comment lines keep proxies from closing idle connections
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+func writeSSEEvent(c *gin.Context, message streamMessage) {
+	fmt.Fprintf(c.Writer, "id: %s\nevent: audit_log\ndata: %s\n\n", message.id, message.payload)
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
+)
+
+//go:generate mockery --name TaggingService --output ../mocks
+type TaggingService interface {
+	Create(ctx context.Context, tenantID string, req dto.TaggingRuleRequest) (*dto.TaggingRuleResponse, error)
+	List(ctx context.Context, tenantID string) ([]dto.TaggingRuleResponse, error)
+	Delete(ctx context.Context, tenantID, id string) error
+}
+
+type TaggingHandler struct {
+	*BaseHandler
+	service TaggingService
+}
+
+func NewTaggingHandler(service TaggingService) *TaggingHandler {
+	return &TaggingHandler{service: service}
+}
+
+// CreateTaggingRule godoc
+// @Summary Create a tagging rule
+// @Description Tag every log the tenant ingests from now on that matches the rule's actions, resource types, severities and message pattern, such as authentication or privilege-change events; logs can then be filtered by tag
+// @Tags tagging
+// @Accept json
+// @Produce json
+// @Param body body dto.TaggingRuleRequest true "Tagging rule"
+// @Success 201 {object} dto.TaggingRuleResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 409 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /tagging-rules [post]
+func (h *TaggingHandler) CreateTaggingRule(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	var req dto.TaggingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	rule, err := h.service.Create(h.RequestCtx(c), tenantID, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// ListTaggingRules godoc
+// @Summary List tagging rules
+// @Description List the tagging rules of the authenticated tenant
+// @Tags tagging
+// @Produce json
+// @Success 200 {array} dto.TaggingRuleResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /tagging-rules [get]
+func (h *TaggingHandler) ListTaggingRules(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	rules, err := h.service.List(h.RequestCtx(c), tenantID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// DeleteTaggingRule godoc
+// @Summary Delete a tagging rule
+// @Description Delete a tagging rule of the authenticated tenant. Logs already stored keep their tags.
+// @Tags tagging
+// @Param id path string true "Tagging rule ID"
+// @Success 204
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /tagging-rules/{id} [delete]
+func (h *TaggingHandler) DeleteTaggingRule(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	err := h.service.Delete(h.RequestCtx(c), tenantID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
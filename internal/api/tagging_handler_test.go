@@ -0,0 +1,131 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type TaggingHandlerTestSuite struct {
+	suite.Suite
+	router      *gin.Engine
+	mockService *MockTaggingService
+	handler     *TaggingHandler
+}
+
+type MockTaggingService struct {
+	mock.Mock
+}
+
+func (m *MockTaggingService) Create(ctx context.Context, tenantID string, req dto.TaggingRuleRequest) (*dto.TaggingRuleResponse, error) {
+	args := m.Called(ctx, tenantID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.TaggingRuleResponse), args.Error(1)
+}
+
+func (m *MockTaggingService) List(ctx context.Context, tenantID string) ([]dto.TaggingRuleResponse, error) {
+	args := m.Called(ctx, tenantID)
+	return args.Get(0).([]dto.TaggingRuleResponse), args.Error(1)
+}
+
+func (m *MockTaggingService) Delete(ctx context.Context, tenantID, id string) error {
+	args := m.Called(ctx, tenantID, id)
+	return args.Error(0)
+}
+
+func (s *TaggingHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.mockService = new(MockTaggingService)
+	s.handler = NewTaggingHandler(s.mockService)
+
+	// Setup routes with the tenant the JWT middleware would set
+	rules := s.router.Group("/tagging-rules", func(c *gin.Context) {
+		c.Set(string(contextutils.TenantIDKey), "tenant1")
+	})
+	rules.POST("", s.handler.CreateTaggingRule)
+	rules.GET("", s.handler.ListTaggingRules)
+	rules.DELETE("/:id", s.handler.DeleteTaggingRule)
+}
+
+func TestTaggingHandler(t *testing.T) {
+	suite.Run(t, new(TaggingHandlerTestSuite))
+}
+
+func (s *TaggingHandlerTestSuite) TestCreateTaggingRule_Success() {
+	// Arrange
+	req := dto.TaggingRuleRequest{Tag: "authentication", Actions: []string{"LOGIN", "LOGOUT"}, Severities: []string{"WARNING"}}
+	s.mockService.On("Create", mock.Anything, "tenant1", req).
+		Return(&dto.TaggingRuleResponse{ID: "rule1", TenantID: "tenant1", Tag: req.Tag, Actions: req.Actions, Severities: req.Severities}, nil)
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/tagging-rules", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusCreated, w.Code)
+	var response dto.TaggingRuleResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal("rule1", response.ID)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *TaggingHandlerTestSuite) TestCreateTaggingRule_InvalidTag() {
+	// Arrange
+	body, _ := json.Marshal(dto.TaggingRuleRequest{Tag: "Privilege Change", Actions: []string{"UPDATE"}})
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/tagging-rules", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *TaggingHandlerTestSuite) TestCreateTaggingRule_InvalidSeverity() {
+	// Arrange
+	body, _ := json.Marshal(dto.TaggingRuleRequest{Tag: "security", Severities: []string{"FATAL"}})
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/tagging-rules", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *TaggingHandlerTestSuite) TestDeleteTaggingRule_NotFound() {
+	// Arrange
+	s.mockService.On("Delete", mock.Anything, "tenant1", "missing").Return(service.ErrTaggingRuleNotFound)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodDelete, "/tagging-rules/missing", nil)
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+}
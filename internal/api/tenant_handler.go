@@ -3,11 +3,13 @@ package api
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/kingrain94/audit-log-api/internal/api/dto"
 	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/utils"
 )
 
 //go:generate mockery --name TenantService --output ../mocks
@@ -15,17 +17,41 @@ type TenantService interface {
 	Create(ctx context.Context, req dto.CreateTenantRequest) (dto.CreateTenantResponse, error)
 	GetByID(ctx context.Context, id string) (*domain.Tenant, error)
 	Update(ctx context.Context, tenant *domain.Tenant) error
-	Delete(ctx context.Context, id string) error
+	Delete(ctx context.Context, id string) (*domain.Tenant, error)
+	Restore(ctx context.Context, id string) (*domain.Tenant, error)
 	List(ctx context.Context) ([]dto.CreateTenantResponse, error)
+	GetRateLimit(ctx context.Context, tenantID string) (*domain.TenantRateLimit, error)
+	UpdateRateLimit(ctx context.Context, tenantID string, req dto.UpdateTenantRateLimitRequest) (*domain.TenantRateLimit, error)
+	GetSettings(ctx context.Context, tenantID string) (*domain.Tenant, error)
+	UpdateSettings(ctx context.Context, tenantID string, req dto.UpdateTenantSettingsRequest) (*domain.Tenant, error)
+	GetArchiveSchedule(ctx context.Context, tenantID string) (*domain.ArchiveSchedule, error)
+	UpdateArchiveSchedule(ctx context.Context, tenantID string, req dto.UpdateArchiveScheduleRequest) (*domain.ArchiveSchedule, error)
+}
+
+// TenantUsageService reports the ingestion volume of tenants
+//
+//go:generate mockery --name TenantUsageService --output ../mocks
+type TenantUsageService interface {
+	GetUsage(ctx context.Context, tenantID string, from, to time.Time) (*dto.TenantUsageResponse, error)
+}
+
+// TenantExportService dumps all of a tenant's data to S3
+//
+//go:generate mockery --name TenantExportService --output ../mocks
+type TenantExportService interface {
+	CreateTenantExportJob(ctx context.Context, tenantID string) (*dto.ExportJobResponse, error)
+	GetExportJob(ctx context.Context, tenantID, jobID string) (*dto.ExportJobResponse, error)
 }
 
 type TenantHandler struct {
 	*BaseHandler
 	service TenantService
+	usage   TenantUsageService
+	exports TenantExportService
 }
 
-func NewTenantHandler(service TenantService) *TenantHandler {
-	return &TenantHandler{service: service}
+func NewTenantHandler(service TenantService, usage TenantUsageService, exports TenantExportService) *TenantHandler {
+	return &TenantHandler{service: service, usage: usage, exports: exports}
 }
 
 // CreateTenant godoc
@@ -43,13 +69,13 @@ func NewTenantHandler(service TenantService) *TenantHandler {
 func (h *TenantHandler) CreateTenant(c *gin.Context) {
 	var req dto.CreateTenantRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+		bindError(c, err)
 		return
 	}
 
 	tenant, err := h.service.Create(h.RequestCtx(c), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -68,9 +94,340 @@ func (h *TenantHandler) CreateTenant(c *gin.Context) {
 func (h *TenantHandler) ListTenants(c *gin.Context) {
 	tenants, err := h.service.List(h.RequestCtx(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, tenants)
 }
+
+// DeleteTenant godoc
+// @Summary Delete a tenant
+// @Description Soft delete a tenant. Its logs are kept until purge_after, until which the tenant can be restored; they are then archived and removed. Platform administrators only.
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Tenant ID"
+// @Success 200 {object} dto.TenantDeletionResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /tenants/{id} [delete]
+func (h *TenantHandler) DeleteTenant(c *gin.Context) {
+	tenant, err := h.service.Delete(h.RequestCtx(c), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromTenantDeletion(tenant))
+}
+
+// RestoreTenant godoc
+// @Summary Restore a deleted tenant
+// @Description Undo the deletion of a tenant during its grace period. Platform administrators only.
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Tenant ID"
+// @Success 200 {object} dto.CreateTenantResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 409 {object} dto.Error
+// @Failure 410 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /tenants/{id}/restore [post]
+func (h *TenantHandler) RestoreTenant(c *gin.Context) {
+	tenant, err := h.service.Restore(h.RequestCtx(c), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.CreateTenantResponse{
+		ID:        tenant.ID,
+		Name:      tenant.Name,
+		CreatedAt: tenant.CreatedAt,
+		UpdatedAt: tenant.UpdatedAt,
+	})
+}
+
+// GetTenantRateLimit godoc
+// @Summary Get tenant rate limit
+// @Description Get the per-minute request limit and burst allowance of a tenant. Platform administrators only.
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Tenant ID"
+// @Success 200 {object} dto.TenantRateLimitResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /tenants/{id}/rate-limit [get]
+func (h *TenantHandler) GetTenantRateLimit(c *gin.Context) {
+	limit, err := h.service.GetRateLimit(h.RequestCtx(c), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromTenantRateLimit(limit))
+}
+
+// UpdateTenantRateLimit godoc
+// @Summary Update tenant rate limit
+// @Description Set the per-minute request limit and burst allowance of a tenant. Platform administrators only.
+// @Tags tenants
+// @Accept json
+// @Produce json
+// @Param id path string true "Tenant ID"
+// @Param body body dto.UpdateTenantRateLimitRequest true "Rate limit"
+// @Success 200 {object} dto.TenantRateLimitResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /tenants/{id}/rate-limit [put]
+func (h *TenantHandler) UpdateTenantRateLimit(c *gin.Context) {
+	var req dto.UpdateTenantRateLimitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	limit, err := h.service.UpdateRateLimit(h.RequestCtx(c), c.Param("id"), req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromTenantRateLimit(limit))
+}
+
+// GetTenantSettings godoc
+// @Summary Get tenant settings
+// @Description Get the retention, rate limit, allowed actions, webhook secrets and data residency region of the caller's tenant. Webhook secrets are masked.
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Tenant ID"
+// @Success 200 {object} dto.TenantSettingsResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /tenants/{id}/settings [get]
+func (h *TenantHandler) GetTenantSettings(c *gin.Context) {
+	if !ownTenant(c) {
+		respondError(c, errForbidden("Tenants can only manage their own settings"))
+		return
+	}
+
+	tenant, err := h.service.GetSettings(h.RequestCtx(c), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromTenantSettings(tenant))
+}
+
+// UpdateTenantSettings godoc
+// @Summary Update tenant settings
+// @Description Change the settings of the caller's tenant. Settings left out of the body are kept; lists replace the current ones.
+// @Tags tenants
+// @Accept json
+// @Produce json
+// @Param id path string true "Tenant ID"
+// @Param body body dto.UpdateTenantSettingsRequest true "Settings"
+// @Success 200 {object} dto.TenantSettingsResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /tenants/{id}/settings [put]
+func (h *TenantHandler) UpdateTenantSettings(c *gin.Context) {
+	if !ownTenant(c) {
+		respondError(c, errForbidden("Tenants can only manage their own settings"))
+		return
+	}
+
+	var req dto.UpdateTenantSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	tenant, err := h.service.UpdateSettings(h.RequestCtx(c), c.Param("id"), req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromTenantSettings(tenant))
+}
+
+// GetTenantArchiveSchedule godoc
+// @Summary Get tenant archive schedule
+// @Description Get how often the logs of the caller's tenant are archived to S3 and deleted, how old they must be, and the last scheduled run
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Tenant ID"
+// @Success 200 {object} dto.ArchiveScheduleResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /tenants/{id}/archive-schedule [get]
+func (h *TenantHandler) GetTenantArchiveSchedule(c *gin.Context) {
+	if !ownTenant(c) {
+		respondError(c, errForbidden("Tenants can only manage their own archive schedule"))
+		return
+	}
+
+	schedule, err := h.service.GetArchiveSchedule(h.RequestCtx(c), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromArchiveSchedule(schedule))
+}
+
+// UpdateTenantArchiveSchedule godoc
+// @Summary Update tenant archive schedule
+// @Description Override the scheduled archival of the caller's tenant: disable it, or change its interval or the age of the logs archived. Overrides left out of the body are kept.
+// @Tags tenants
+// @Accept json
+// @Produce json
+// @Param id path string true "Tenant ID"
+// @Param body body dto.UpdateArchiveScheduleRequest true "Schedule overrides"
+// @Success 200 {object} dto.ArchiveScheduleResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /tenants/{id}/archive-schedule [put]
+func (h *TenantHandler) UpdateTenantArchiveSchedule(c *gin.Context) {
+	if !ownTenant(c) {
+		respondError(c, errForbidden("Tenants can only manage their own archive schedule"))
+		return
+	}
+
+	var req dto.UpdateArchiveScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	schedule, err := h.service.UpdateArchiveSchedule(h.RequestCtx(c), c.Param("id"), req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromArchiveSchedule(schedule))
+}
+
+// GetTenantUsage godoc
+// @Summary Get tenant usage
+// @Description Get the logs and bytes the caller's tenant ingested per UTC day and calendar month, with its quotas
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Tenant ID"
+// @Param from query string false "First day, YYYY-MM-DD (default: first day of last month)"
+// @Param to query string false "Last day, YYYY-MM-DD (default: today)"
+// @Success 200 {object} dto.TenantUsageResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /tenants/{id}/usage [get]
+func (h *TenantHandler) GetTenantUsage(c *gin.Context) {
+	if !ownTenant(c) {
+		respondError(c, errForbidden("Tenants can only view their own usage"))
+		return
+	}
+
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	from := time.Date(to.Year(), to.Month()-1, 1, 0, 0, 0, 0, time.UTC)
+	for name, day := range map[string]*time.Time{"from": &from, "to": &to} {
+		if value := c.Query(name); value != "" {
+			parsed, err := time.Parse(time.DateOnly, value)
+			if err != nil {
+				respondError(c, errValidation(name+" must be a date in YYYY-MM-DD format"))
+				return
+			}
+			*day = parsed
+		}
+	}
+
+	usage, err := h.usage.GetUsage(h.RequestCtx(c), c.Param("id"), from, to)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, usage)
+}
+
+// ExportTenant godoc
+// @Summary Export all tenant data
+// @Description Start an asynchronous dump of all of the caller's tenant data (audit logs, users, retention policies and settings) to S3 as gzip-compressed NDJSON files plus a manifest, for data portability and off-boarding. Poll the job for a download URL of the manifest.
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Tenant ID"
+// @Success 202 {object} dto.ExportJobResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /tenants/{id}/export [post]
+func (h *TenantHandler) ExportTenant(c *gin.Context) {
+	if !ownTenant(c) {
+		respondError(c, errForbidden("Tenants can only export their own data"))
+		return
+	}
+
+	job, err := h.exports.CreateTenantExportJob(h.RequestCtx(c), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetTenantExport godoc
+// @Summary Get tenant export job
+// @Description Get the status of a tenant data export, including a pre-signed download URL of its manifest once it has completed
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Tenant ID"
+// @Param job_id path string true "Export job ID"
+// @Success 200 {object} dto.ExportJobResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /tenants/{id}/export/{job_id} [get]
+func (h *TenantHandler) GetTenantExport(c *gin.Context) {
+	if !ownTenant(c) {
+		respondError(c, errForbidden("Tenants can only export their own data"))
+		return
+	}
+
+	job, err := h.exports.GetExportJob(h.RequestCtx(c), c.Param("id"), c.Param("job_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// ownTenant reports whether the tenant in the path is the caller's
+func ownTenant(c *gin.Context) bool {
+	return c.Param("id") == c.GetString(string(utils.TenantIDKey))
+}
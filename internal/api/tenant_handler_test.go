@@ -10,8 +10,12 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/kingrain94/audit-log-api/internal/api/dto"
 	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/middleware"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
@@ -20,6 +24,8 @@ type TenantHandlerTestSuite struct {
 	suite.Suite
 	router      *gin.Engine
 	mockService *MockTenantService
+	mockUsage   *MockTenantUsageService
+	mockExports *MockTenantExportService
 	handler     *TenantHandler
 }
 
@@ -45,9 +51,20 @@ func (m *MockTenantService) Update(ctx context.Context, tenant *domain.Tenant) e
 	return args.Error(0)
 }
 
-func (m *MockTenantService) Delete(ctx context.Context, id string) error {
+func (m *MockTenantService) Delete(ctx context.Context, id string) (*domain.Tenant, error) {
 	args := m.Called(ctx, id)
-	return args.Error(0)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Tenant), args.Error(1)
+}
+
+func (m *MockTenantService) Restore(ctx context.Context, id string) (*domain.Tenant, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Tenant), args.Error(1)
 }
 
 func (m *MockTenantService) List(ctx context.Context) ([]dto.CreateTenantResponse, error) {
@@ -55,15 +72,100 @@ func (m *MockTenantService) List(ctx context.Context) ([]dto.CreateTenantRespons
 	return args.Get(0).([]dto.CreateTenantResponse), args.Error(1)
 }
 
+func (m *MockTenantService) GetRateLimit(ctx context.Context, tenantID string) (*domain.TenantRateLimit, error) {
+	args := m.Called(ctx, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TenantRateLimit), args.Error(1)
+}
+
+func (m *MockTenantService) UpdateRateLimit(ctx context.Context, tenantID string, req dto.UpdateTenantRateLimitRequest) (*domain.TenantRateLimit, error) {
+	args := m.Called(ctx, tenantID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TenantRateLimit), args.Error(1)
+}
+
+func (m *MockTenantService) GetSettings(ctx context.Context, tenantID string) (*domain.Tenant, error) {
+	args := m.Called(ctx, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Tenant), args.Error(1)
+}
+
+func (m *MockTenantService) UpdateSettings(ctx context.Context, tenantID string, req dto.UpdateTenantSettingsRequest) (*domain.Tenant, error) {
+	args := m.Called(ctx, tenantID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Tenant), args.Error(1)
+}
+
+func (m *MockTenantService) GetArchiveSchedule(ctx context.Context, tenantID string) (*domain.ArchiveSchedule, error) {
+	args := m.Called(ctx, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ArchiveSchedule), args.Error(1)
+}
+
+func (m *MockTenantService) UpdateArchiveSchedule(ctx context.Context, tenantID string, req dto.UpdateArchiveScheduleRequest) (*domain.ArchiveSchedule, error) {
+	args := m.Called(ctx, tenantID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ArchiveSchedule), args.Error(1)
+}
+
+type MockTenantExportService struct {
+	mock.Mock
+}
+
+func (m *MockTenantExportService) CreateTenantExportJob(ctx context.Context, tenantID string) (*dto.ExportJobResponse, error) {
+	args := m.Called(ctx, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ExportJobResponse), args.Error(1)
+}
+
+func (m *MockTenantExportService) GetExportJob(ctx context.Context, tenantID, jobID string) (*dto.ExportJobResponse, error) {
+	args := m.Called(ctx, tenantID, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ExportJobResponse), args.Error(1)
+}
+
+type MockTenantUsageService struct {
+	mock.Mock
+}
+
+func (m *MockTenantUsageService) GetUsage(ctx context.Context, tenantID string, from, to time.Time) (*dto.TenantUsageResponse, error) {
+	args := m.Called(ctx, tenantID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.TenantUsageResponse), args.Error(1)
+}
+
 func (s *TenantHandlerTestSuite) SetupTest() {
 	gin.SetMode(gin.TestMode)
 	s.router = gin.New()
 	s.mockService = new(MockTenantService)
-	s.handler = NewTenantHandler(s.mockService)
+	s.mockUsage = new(MockTenantUsageService)
+	s.mockExports = new(MockTenantExportService)
+	s.handler = NewTenantHandler(s.mockService, s.mockUsage, s.mockExports)
 
 	// Setup routes
 	s.router.POST("/tenants", s.handler.CreateTenant)
 	s.router.GET("/tenants", s.handler.ListTenants)
+	s.router.PUT("/tenants/:id/rate-limit", s.handler.UpdateTenantRateLimit)
+	s.router.DELETE("/tenants/:id", s.handler.DeleteTenant)
+	s.router.POST("/tenants/:id/restore", s.handler.RestoreTenant)
 }
 
 func TestTenantHandler(t *testing.T) {
@@ -144,3 +246,423 @@ func (s *TenantHandlerTestSuite) TestListTenants_Success() {
 	s.Equal(expectedTenants[1].Name, response[1].Name)
 	s.mockService.AssertExpectations(s.T())
 }
+
+func (s *TenantHandlerTestSuite) TestUpdateTenantRateLimit_Success() {
+	// Arrange
+	req := dto.UpdateTenantRateLimitRequest{RateLimit: 5000, Burst: 500}
+	s.mockService.On("UpdateRateLimit", mock.Anything, "tenant1", req).
+		Return(&domain.TenantRateLimit{TenantID: "tenant1", Limit: 5000, Burst: 500}, nil)
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPut, "/tenants/tenant1/rate-limit", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response dto.TenantRateLimitResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal("tenant1", response.TenantID)
+	s.Equal(5000, response.RateLimit)
+	s.Equal(500, response.Burst)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *TenantHandlerTestSuite) TestTenantRateLimit_TenantAdminForbidden() {
+	// Arrange
+	router := s.platformAdminRouter(jwt.MapClaims{"tenant_id": "tenant1", "roles": []interface{}{"admin"}})
+
+	for _, method := range []string{http.MethodGet, http.MethodPut} {
+		body, _ := json.Marshal(dto.UpdateTenantRateLimitRequest{RateLimit: 100000})
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest(method, "/tenants/tenant1/rate-limit", bytes.NewBuffer(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		// Act
+		router.ServeHTTP(w, httpReq)
+
+		// Assert
+		s.Equal(http.StatusForbidden, w.Code, method)
+	}
+	s.mockService.AssertNotCalled(s.T(), "GetRateLimit", mock.Anything, mock.Anything)
+	s.mockService.AssertNotCalled(s.T(), "UpdateRateLimit", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *TenantHandlerTestSuite) TestUpdateTenantRateLimit_InvalidLimit() {
+	// Arrange
+	body, _ := json.Marshal(dto.UpdateTenantRateLimitRequest{RateLimit: 0})
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPut, "/tenants/tenant1/rate-limit", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "UpdateRateLimit", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *TenantHandlerTestSuite) TestUpdateTenantRateLimit_NotFound() {
+	// Arrange
+	req := dto.UpdateTenantRateLimitRequest{RateLimit: 100}
+	s.mockService.On("UpdateRateLimit", mock.Anything, "missing", req).Return(nil, service.ErrTenantNotFound)
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPut, "/tenants/missing/rate-limit", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *TenantHandlerTestSuite) TestGetTenantSettings_MasksWebhookSecrets() {
+	// Arrange
+	tenant := &domain.Tenant{
+		ID:        "tenant1",
+		RateLimit: 1000,
+		Settings: domain.TenantSettings{
+			RetentionDays:  90,
+			WebhookSecrets: []string{"whsec_3f9a1c7e2b8d4f60"},
+		},
+	}
+	s.mockService.On("GetSettings", mock.Anything, "tenant1").Return(tenant, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/tenants/tenant1/settings", nil)
+	c.Params = []gin.Param{{Key: "id", Value: "tenant1"}}
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.GetTenantSettings(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response dto.TenantSettingsResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal(90, response.RetentionDays)
+	s.Equal(1000, response.RateLimit)
+	s.Equal([]string{"********4f60"}, response.WebhookSecrets)
+	s.Empty(response.AllowedActions)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *TenantHandlerTestSuite) TestUpdateTenantSettings_OtherTenant_Forbidden() {
+	// Arrange
+	body, _ := json.Marshal(dto.UpdateTenantSettingsRequest{AllowedActions: []string{"CREATE"}})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPut, "/tenants/tenant2/settings", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = []gin.Param{{Key: "id", Value: "tenant2"}}
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.UpdateTenantSettings(c)
+
+	// Assert
+	s.Equal(http.StatusForbidden, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "UpdateSettings", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *TenantHandlerTestSuite) TestUpdateTenantSettings_UnknownUserRateLimitRoute_BadRequest() {
+	// Arrange
+	body := []byte(`{"user_rate_limits":{"ingest":100,"export":10}}`)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPut, "/tenants/tenant1/settings", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = []gin.Param{{Key: "id", Value: "tenant1"}}
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.UpdateTenantSettings(c)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "UpdateSettings", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *TenantHandlerTestSuite) TestUpdateTenantSettings_NestedIndexedMetadataKey_BadRequest() {
+	// Arrange
+	body := []byte(`{"indexed_metadata_keys":["order_id","request.path"]}`)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPut, "/tenants/tenant1/settings", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = []gin.Param{{Key: "id", Value: "tenant1"}}
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.UpdateTenantSettings(c)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "UpdateSettings", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *TenantHandlerTestSuite) TestUpdateTenantSettings_WebhookURL() {
+	// Arrange
+	empty := ""
+	cleared := dto.UpdateTenantSettingsRequest{WebhookURL: &empty}
+	tenant := &domain.Tenant{ID: "tenant1"}
+	s.mockService.On("UpdateSettings", mock.Anything, "tenant1", cleared).Return(tenant, nil)
+
+	update := func(body string) int {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodPut, "/tenants/tenant1/settings", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = []gin.Param{{Key: "id", Value: "tenant1"}}
+		c.Set(string(contextutils.TenantIDKey), "tenant1")
+		s.handler.UpdateTenantSettings(c)
+		return w.Code
+	}
+
+	// Act
+	invalid := update(`{"webhook_url":"not a url"}`)
+	insecure := update(`{"webhook_url":"http://hooks.example.com/audit"}`)
+	clear := update(`{"webhook_url":""}`)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, invalid)
+	s.Equal(http.StatusBadRequest, insecure)
+	s.Equal(http.StatusOK, clear)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *TenantHandlerTestSuite) TestUpdateTenantArchiveSchedule_Success() {
+	// Arrange
+	retentionDays := 30
+	req := dto.UpdateArchiveScheduleRequest{RetentionDays: &retentionDays}
+	body, _ := json.Marshal(req)
+	schedule := &domain.ArchiveSchedule{TenantID: "tenant1", Enabled: true, RetentionDays: 30}
+	s.mockService.On("UpdateArchiveSchedule", mock.Anything, "tenant1", req).Return(schedule, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPut, "/tenants/tenant1/archive-schedule", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = []gin.Param{{Key: "id", Value: "tenant1"}}
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.UpdateTenantArchiveSchedule(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response dto.ArchiveScheduleResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.True(response.Enabled)
+	s.Equal(30, response.RetentionDays)
+	s.Nil(response.LastRunAt)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *TenantHandlerTestSuite) TestUpdateTenantArchiveSchedule_InvalidInterval() {
+	// Arrange
+	body := []byte(`{"interval_hours": -1}`)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPut, "/tenants/tenant1/archive-schedule", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = []gin.Param{{Key: "id", Value: "tenant1"}}
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.UpdateTenantArchiveSchedule(c)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "UpdateArchiveSchedule", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *TenantHandlerTestSuite) TestGetTenantUsage_ParsesRange() {
+	// Arrange
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 7, 17, 0, 0, 0, 0, time.UTC)
+	usage := &dto.TenantUsageResponse{
+		TenantID: "tenant1",
+		Monthly:  []dto.UsagePeriodResponse{{Period: "2025-06", Logs: 100}, {Period: "2025-07", Logs: 50}},
+	}
+	s.mockUsage.On("GetUsage", mock.Anything, "tenant1", from, to).Return(usage, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/tenants/tenant1/usage?from=2025-06-01&to=2025-07-17", nil)
+	c.Params = []gin.Param{{Key: "id", Value: "tenant1"}}
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.GetTenantUsage(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response dto.TenantUsageResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Len(response.Monthly, 2)
+	s.mockUsage.AssertExpectations(s.T())
+}
+
+func (s *TenantHandlerTestSuite) TestGetTenantUsage_InvalidDate() {
+	// Arrange
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/tenants/tenant1/usage?from=July", nil)
+	c.Params = []gin.Param{{Key: "id", Value: "tenant1"}}
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.GetTenantUsage(c)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockUsage.AssertNotCalled(s.T(), "GetUsage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *TenantHandlerTestSuite) TestDeleteTenant_ReturnsPurgeAfter() {
+	// Arrange
+	deletedAt := time.Date(2025, 7, 17, 0, 0, 0, 0, time.UTC)
+	purgeAfter := deletedAt.Add(30 * 24 * time.Hour)
+	tenant := &domain.Tenant{ID: "tenant1", Name: "Tenant 1", DeletedAt: &deletedAt, PurgeAfter: &purgeAfter}
+	s.mockService.On("Delete", mock.Anything, "tenant1").Return(tenant, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodDelete, "/tenants/tenant1", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response dto.TenantDeletionResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.True(purgeAfter.Equal(response.PurgeAfter))
+	s.mockService.AssertExpectations(s.T())
+}
+
+// platformAdminRouter routes the tenant lifecycle endpoints behind
+// RequirePlatformAdmin, as the server does, for a caller with claims.
+func (s *TenantHandlerTestSuite) platformAdminRouter(claims jwt.MapClaims) *gin.Engine {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(string(contextutils.ClaimsKey), claims)
+		c.Set(string(contextutils.TenantIDKey), claims["tenant_id"])
+		c.Next()
+	})
+	admin := new(middleware.AuthMiddleware).RequirePlatformAdmin()
+	router.DELETE("/tenants/:id", admin, s.handler.DeleteTenant)
+	router.POST("/tenants/:id/restore", admin, s.handler.RestoreTenant)
+	router.GET("/tenants/:id/rate-limit", admin, s.handler.GetTenantRateLimit)
+	router.PUT("/tenants/:id/rate-limit", admin, s.handler.UpdateTenantRateLimit)
+	return router
+}
+
+func (s *TenantHandlerTestSuite) TestDeleteTenant_TenantAdminForbidden() {
+	// Arrange
+	router := s.platformAdminRouter(jwt.MapClaims{"tenant_id": "tenant1", "roles": []interface{}{"admin"}})
+
+	for _, req := range []struct{ method, path string }{
+		{http.MethodDelete, "/tenants/tenant2"},
+		{http.MethodPost, "/tenants/tenant2/restore"},
+	} {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest(req.method, req.path, nil)
+
+		// Act
+		router.ServeHTTP(w, httpReq)
+
+		// Assert
+		s.Equal(http.StatusForbidden, w.Code, req.path)
+	}
+	s.mockService.AssertNotCalled(s.T(), "Delete", mock.Anything, mock.Anything)
+	s.mockService.AssertNotCalled(s.T(), "Restore", mock.Anything, mock.Anything)
+}
+
+func (s *TenantHandlerTestSuite) TestDeleteTenant_PlatformAdmin() {
+	// Arrange
+	router := s.platformAdminRouter(jwt.MapClaims{"tenant_id": domain.SystemTenantID, "roles": []interface{}{"admin"}})
+	s.mockService.On("Delete", mock.Anything, "tenant2").Return(&domain.Tenant{ID: "tenant2"}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodDelete, "/tenants/tenant2", nil)
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *TenantHandlerTestSuite) TestRestoreTenant_Errors() {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{service.ErrTenantNotFound, http.StatusNotFound},
+		{service.ErrTenantNotDeleted, http.StatusConflict},
+		{service.ErrTenantPurged, http.StatusGone},
+	}
+
+	for _, tt := range tests {
+		// Arrange
+		s.SetupTest()
+		s.mockService.On("Restore", mock.Anything, "tenant1").Return(nil, tt.err)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/tenants/tenant1/restore", nil)
+
+		// Act
+		s.router.ServeHTTP(w, req)
+
+		// Assert
+		s.Equal(tt.status, w.Code, tt.err.Error())
+	}
+}
+
+func (s *TenantHandlerTestSuite) TestExportTenant_Accepted() {
+	// Arrange
+	job := &dto.ExportJobResponse{ID: "job1", Status: string(domain.JobPending), Scope: string(domain.ExportScopeTenant)}
+	s.mockExports.On("CreateTenantExportJob", mock.Anything, "tenant1").Return(job, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/tenants/tenant1/export", nil)
+	c.Params = []gin.Param{{Key: "id", Value: "tenant1"}}
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ExportTenant(c)
+
+	// Assert
+	s.Equal(http.StatusAccepted, w.Code)
+	var response dto.ExportJobResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal("job1", response.ID)
+	s.mockExports.AssertExpectations(s.T())
+}
+
+func (s *TenantHandlerTestSuite) TestExportTenant_OtherTenantForbidden() {
+	// Arrange
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/tenants/tenant2/export", nil)
+	c.Params = []gin.Param{{Key: "id", Value: "tenant2"}}
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ExportTenant(c)
+
+	// Assert
+	s.Equal(http.StatusForbidden, w.Code)
+	s.mockExports.AssertNotCalled(s.T(), "CreateTenantExportJob", mock.Anything, mock.Anything)
+}
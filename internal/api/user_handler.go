@@ -0,0 +1,207 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
+)
+
+//go:generate mockery --name UserService --output ../mocks
+type UserService interface {
+	Create(ctx context.Context, tenantID string, req dto.CreateUserRequest) (*dto.UserResponse, error)
+	List(ctx context.Context, filter *domain.UserFilter) ([]dto.UserResponse, error)
+	UpdateRoles(ctx context.Context, tenantID, id string, roles []string) (*dto.UserResponse, error)
+	Deactivate(ctx context.Context, tenantID, id string) (*dto.UserResponse, error)
+}
+
+type UserHandler struct {
+	*BaseHandler
+	service UserService
+}
+
+func NewUserHandler(service UserService) *UserHandler {
+	return &UserHandler{service: service}
+}
+
+// CreateUser godoc
+// @Summary Create a user
+// @Description Create a user in the authenticated tenant. Users without roles get the user role.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param body body dto.CreateUserRequest true "User object"
+// @Success 201 {object} dto.UserResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 409 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /users [post]
+func (h *UserHandler) CreateUser(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	var req dto.CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	user, err := h.service.Create(h.RequestCtx(c), tenantID, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
+
+// ListUsers godoc
+// @Summary List users
+// @Description List the users of the authenticated tenant
+// @Tags users
+// @Produce json
+// @Param email query string false "Filter by email (substring match)"
+// @Param name query string false "Filter by name (substring match)"
+// @Param roles query string false "Comma-separated roles; matches users holding any of them" example:"admin,auditor"
+// @Param active query bool false "Filter by active status"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(10)
+// @Success 200 {array} dto.UserResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /users [get]
+func (h *UserHandler) ListUsers(c *gin.Context) {
+	filter, err := getUserFilterFromQuery(c)
+	if err != nil {
+		bindError(c, err)
+		return
+	}
+
+	users, err := h.service.List(h.RequestCtx(c), filter)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// UpdateUserRoles godoc
+// @Summary Update user roles
+// @Description Replace the roles of a user in the authenticated tenant
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param body body dto.UpdateUserRolesRequest true "Roles"
+// @Success 200 {object} dto.UserResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /users/{id}/roles [put]
+func (h *UserHandler) UpdateUserRoles(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	var req dto.UpdateUserRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	user, err := h.service.UpdateRoles(h.RequestCtx(c), tenantID, c.Param("id"), req.Roles)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// DeactivateUser godoc
+// @Summary Deactivate a user
+// @Description Mark a user in the authenticated tenant as inactive
+// @Tags users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} dto.UserResponse
+// @Failure 401 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /users/{id}/deactivate [post]
+func (h *UserHandler) DeactivateUser(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		respondError(c, errNoTenant)
+		return
+	}
+
+	user, err := h.service.Deactivate(h.RequestCtx(c), tenantID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func getUserFilterFromQuery(c *gin.Context) (*domain.UserFilter, error) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant_id is required")
+	}
+
+	filter := &domain.UserFilter{
+		TenantID: tenantID,
+		Email:    c.Query("email"),
+		Name:     c.Query("name"),
+	}
+
+	if roles := c.Query("roles"); roles != "" {
+		for _, role := range strings.Split(roles, ",") {
+			role = strings.TrimSpace(role)
+			if !domain.IsValidRole(role) {
+				return nil, fmt.Errorf("invalid role: %s", role)
+			}
+			filter.Roles = append(filter.Roles, role)
+		}
+	}
+
+	if active := c.Query("active"); active != "" {
+		value, err := strconv.ParseBool(active)
+		if err != nil {
+			return nil, fmt.Errorf("invalid active value: %s", active)
+		}
+		filter.Active = &value
+	}
+
+	// Parse pagination
+	if page := c.Query("page"); page != "" {
+		if pageNum, err := strconv.Atoi(page); err == nil {
+			filter.Page = pageNum
+		}
+	}
+	if pageSize := c.Query("page_size"); pageSize != "" {
+		if size, err := strconv.Atoi(pageSize); err == nil {
+			filter.PageSize = size
+		}
+	}
+
+	return filter, nil
+}
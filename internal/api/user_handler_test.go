@@ -0,0 +1,210 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	contextutils "github.com/kingrain94/audit-log-api/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type UserHandlerTestSuite struct {
+	suite.Suite
+	router      *gin.Engine
+	mockService *MockUserService
+	handler     *UserHandler
+}
+
+type MockUserService struct {
+	mock.Mock
+}
+
+func (m *MockUserService) Create(ctx context.Context, tenantID string, req dto.CreateUserRequest) (*dto.UserResponse, error) {
+	args := m.Called(ctx, tenantID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.UserResponse), args.Error(1)
+}
+
+func (m *MockUserService) List(ctx context.Context, filter *domain.UserFilter) ([]dto.UserResponse, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]dto.UserResponse), args.Error(1)
+}
+
+func (m *MockUserService) UpdateRoles(ctx context.Context, tenantID, id string, roles []string) (*dto.UserResponse, error) {
+	args := m.Called(ctx, tenantID, id, roles)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.UserResponse), args.Error(1)
+}
+
+func (m *MockUserService) Deactivate(ctx context.Context, tenantID, id string) (*dto.UserResponse, error) {
+	args := m.Called(ctx, tenantID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.UserResponse), args.Error(1)
+}
+
+func (s *UserHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.mockService = new(MockUserService)
+	s.handler = NewUserHandler(s.mockService)
+
+	// Setup routes with the tenant the JWT middleware would set
+	users := s.router.Group("/users", func(c *gin.Context) {
+		c.Set(string(contextutils.TenantIDKey), "tenant1")
+	})
+	users.POST("", s.handler.CreateUser)
+	users.GET("", s.handler.ListUsers)
+	users.PUT("/:id/roles", s.handler.UpdateUserRoles)
+	users.POST("/:id/deactivate", s.handler.DeactivateUser)
+}
+
+func TestUserHandler(t *testing.T) {
+	suite.Run(t, new(UserHandlerTestSuite))
+}
+
+func (s *UserHandlerTestSuite) TestCreateUser_Success() {
+	// Arrange
+	req := dto.CreateUserRequest{Email: "jane@example.com", Name: "Jane", Password: "correct-horse", Roles: []string{"auditor"}}
+	s.mockService.On("Create", mock.Anything, "tenant1", mock.MatchedBy(func(r dto.CreateUserRequest) bool { return r.Email == req.Email })).
+		Return(&dto.UserResponse{ID: "user1", TenantID: "tenant1", Email: req.Email, Name: req.Name, Roles: req.Roles, Active: true}, nil)
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/users", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusCreated, w.Code)
+	var response dto.UserResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal("user1", response.ID)
+	s.Equal([]string{"auditor"}, response.Roles)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *UserHandlerTestSuite) TestCreateUser_InvalidRole() {
+	// Arrange
+	body, _ := json.Marshal(dto.CreateUserRequest{Email: "jane@example.com", Name: "Jane", Password: "correct-horse", Roles: []string{"root"}})
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/users", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *UserHandlerTestSuite) TestCreateUser_EmailExists() {
+	// Arrange
+	req := dto.CreateUserRequest{Email: "jane@example.com", Name: "Jane", Password: "correct-horse"}
+	s.mockService.On("Create", mock.Anything, "tenant1", mock.MatchedBy(func(r dto.CreateUserRequest) bool { return r.Email == req.Email })).Return(nil, service.ErrEmailAlreadyExists)
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/users", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusConflict, w.Code)
+}
+
+func (s *UserHandlerTestSuite) TestListUsers_Filters() {
+	// Arrange
+	active := true
+	expectedFilter := &domain.UserFilter{
+		TenantID: "tenant1",
+		Email:    "example.com",
+		Roles:    []string{"admin", "auditor"},
+		Active:   &active,
+		Page:     2,
+		PageSize: 5,
+	}
+	s.mockService.On("List", mock.Anything, expectedFilter).
+		Return([]dto.UserResponse{{ID: "user1"}}, nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodGet, "/users?email=example.com&roles=admin,auditor&active=true&page=2&page_size=5", nil)
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response []dto.UserResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Len(response, 1)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *UserHandlerTestSuite) TestListUsers_InvalidRole() {
+	// Arrange
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodGet, "/users?roles=root", nil)
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "List", mock.Anything, mock.Anything)
+}
+
+func (s *UserHandlerTestSuite) TestUpdateUserRoles_NotFound() {
+	// Arrange
+	roles := []string{"admin"}
+	s.mockService.On("UpdateRoles", mock.Anything, "tenant1", "missing", roles).Return(nil, service.ErrUserNotFound)
+
+	body, _ := json.Marshal(dto.UpdateUserRolesRequest{Roles: roles})
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPut, "/users/missing/roles", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *UserHandlerTestSuite) TestDeactivateUser_Success() {
+	// Arrange
+	s.mockService.On("Deactivate", mock.Anything, "tenant1", "user1").
+		Return(&dto.UserResponse{ID: "user1", Active: false}, nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/users/user1/deactivate", nil)
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response dto.UserResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.False(response.Active)
+	s.mockService.AssertExpectations(s.T())
+}
@@ -3,13 +3,17 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 
 	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
 	"github.com/kingrain94/audit-log-api/internal/service"
 	"github.com/kingrain94/audit-log-api/internal/service/pubsub"
 	"github.com/kingrain94/audit-log-api/internal/utils"
@@ -20,6 +24,11 @@ const (
 	websocketReadBufferSize        = 1024
 	websocketWriteBufferSize       = 1024
 	websocketSendChannelBufferSize = 256
+	// websocketWriteWait bounds how long a batch of logs may take to write;
+	// a client that doesn't read within it is disconnected
+	websocketWriteWait = 10 * time.Second
+	// streamBatchSize caps the queued logs written to a client in one batch
+	streamBatchSize = 64
 )
 
 var upgrader = websocket.Upgrader{
@@ -30,29 +39,125 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
+// Client is a real-time subscriber of a tenant's logs. conn is nil for
+// Server-Sent Events clients, which are drained by HandleSSE instead of writePump.
+// send is the client's queue; only the hub closes it, on unregister.
 type Client struct {
 	conn     *websocket.Conn
 	tenantID string
-	send     chan []byte
+	// userID restricts the client to one user's logs when set by an own-scoped policy
+	userID string
+	// admin is set for a platform admin's client of several tenants, which is
+	// fed by the subscription to every tenant instead of tenantID's
+	admin *adminStream
+	send  chan streamMessage
+}
+
+// accepts reports whether a log of the client's tenant subscription may be streamed to it
+func (c *Client) accepts(log *dto.AuditLogResponse) bool {
+	return c.admin == nil && c.tenantID == log.TenantID && (c.userID == "" || c.userID == log.UserID)
+}
+
+// enqueue queues a log for the client without blocking the broadcast. When
+// the queue is full its oldest log is dropped, so a slow client falls behind
+// instead of holding up the others; it can replay what it missed on reconnect.
+func (c *Client) enqueue(message streamMessage) {
+	for {
+		select {
+		case c.send <- message:
+			return
+		default:
+		}
+
+		select {
+		case <-c.send:
+			metrics.StreamMessagesDroppedTotal.Inc()
+		default:
+			// Drained by the client meanwhile; try again
+		}
+	}
+}
+
+// nextBatch waits for the next queued log and returns it with the logs queued
+// behind it, up to streamBatchSize, so they are written together. It returns
+// false once the hub closed the queue.
+func (c *Client) nextBatch() ([]streamMessage, bool) {
+	message, ok := <-c.send
+	if !ok {
+		return nil, false
+	}
+	return c.appendQueued([]streamMessage{message}), true
+}
+
+// appendQueued appends the logs already queued to batch, up to streamBatchSize
+func (c *Client) appendQueued(batch []streamMessage) []streamMessage {
+	for len(batch) < streamBatchSize {
+		select {
+		case message, ok := <-c.send:
+			if !ok {
+				// Closed; the next receive reports it
+				return batch
+			}
+			batch = append(batch, message)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+// streamMessage is a log ready to be written to a client
+type streamMessage struct {
+	id      string
+	payload []byte
+}
+
+// streamResumePoint is where a reconnecting stream client left off: the ID
+// of the last log it received or, if it doesn't know it, when it disconnected
+type streamResumePoint struct {
+	lastEventID string
+	since       time.Time
+}
+
+// parseResumePoint validates the resume parameters of a stream request. The
+// last log ID is preferred since it resumes exactly where the client left off.
+func parseResumePoint(lastEventID, since string) (streamResumePoint, error) {
+	if lastEventID != "" {
+		if _, err := uuid.Parse(lastEventID); err != nil {
+			return streamResumePoint{}, errValidation("Last-Event-ID must be a log ID")
+		}
+		return streamResumePoint{lastEventID: lastEventID}, nil
+	}
+	if since == "" {
+		return streamResumePoint{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, since)
+	if err != nil {
+		return streamResumePoint{}, errValidation("since must be an RFC 3339 timestamp")
+	}
+	return streamResumePoint{since: t}, nil
 }
 
 type WebSocketHandler struct {
 	auditLogService *service.AuditLogService
+	tenants         TenantLister
 	clients         map[*Client]bool
 	register        chan *Client
 	unregister      chan *Client
 	mutex           sync.RWMutex
 	logger          *logger.Logger
-	pubsub          *pubsub.RedisPubSub
+	pubsub          pubsub.Broker
 	ctx             context.Context
 	cancel          context.CancelFunc
 	tenantClients   map[string]int // Count of clients per tenant
+	adminClients    int            // Count of clients of several tenants
 }
 
-func NewWebSocketHandler(auditLogService *service.AuditLogService, logger *logger.Logger, pubsub *pubsub.RedisPubSub) *WebSocketHandler {
+func NewWebSocketHandler(auditLogService *service.AuditLogService, tenants TenantLister, logger *logger.Logger, pubsub pubsub.Broker) *WebSocketHandler {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &WebSocketHandler{
 		auditLogService: auditLogService,
+		tenants:         tenants,
 		clients:         make(map[*Client]bool),
 		register:        make(chan *Client),
 		unregister:      make(chan *Client),
@@ -64,18 +169,36 @@ func NewWebSocketHandler(auditLogService *service.AuditLogService, logger *logge
 	}
 }
 
+// HandleWebSocket streams the tenant's real-time logs over a WebSocket
+// @Summary Stream audit logs (WebSocket)
+// @Description Upgrade to a WebSocket streaming new audit logs of the tenant. Reconnect with last_event_id, or since if the last log ID is unknown, to first replay logs missed while disconnected, oldest first, before live logs.
+// @Tags    audit_logs
+// @Param   last_event_id query string false "ID of the last log received"
+// @Param   since query string false "Replay logs stored at or after this time (RFC 3339)"
+// @Param   ticket query string false "One-time ticket from POST /logs/stream/ticket, in place of the Authorization header"
+// @Success 101 {string} string "Switching Protocols"
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Router  /logs/stream [get]
 func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 	// Get tenant ID from context (set by auth middleware). tenant scope is required
 	tenantID, exists := c.Get(string(utils.TenantIDKey))
 	if !exists {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "No tenant ID found"})
+		respondError(c, errNoTenant)
+		return
+	}
+
+	resume, err := parseResumePoint(c.Query("last_event_id"), c.Query("since"))
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
 	// Upgrade HTTP connection to WebSocket
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upgrade connection"})
+		// The upgrader already responded with the reason
+		_ = c.Error(fmt.Errorf("failed to upgrade connection: %w", err))
 		return
 	}
 
@@ -83,23 +206,72 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 	client := &Client{
 		conn:     conn,
 		tenantID: tenantID.(string),
-		send:     make(chan []byte, websocketSendChannelBufferSize),
+		userID:   ownScopeUserID(c),
+		send:     make(chan streamMessage, websocketSendChannelBufferSize),
 	}
+	// Register before replaying so no log published meanwhile is lost; live
+	// logs queue up in the send buffer until the replay is written
 	h.register <- client
+	replayed := h.replay(c.Request.Context(), client, resume)
 
-	go h.writePump(client)
+	go h.writePump(client, replayed)
 	go h.readPump(client)
 }
 
+// replay returns the logs a resuming client missed, oldest first. A failed
+// replay is logged and the client gets live logs only.
+func (h *WebSocketHandler) replay(ctx context.Context, client *Client, resume streamResumePoint) []streamMessage {
+	var logs []dto.AuditLogResponse
+	var err error
+	switch {
+	case resume.lastEventID != "":
+		logs, err = h.auditLogService.ListAfter(ctx, client.tenantID, resume.lastEventID)
+	case !resume.since.IsZero():
+		logs, err = h.auditLogService.ListSince(ctx, client.tenantID, resume.since)
+	default:
+		return nil
+	}
+	if err != nil {
+		h.logger.Errorf("Failed to replay logs for tenant %s: %v", client.tenantID, err)
+		return nil
+	}
+
+	messages := make([]streamMessage, 0, len(logs))
+	for i := range logs {
+		if !client.accepts(&logs[i]) {
+			continue
+		}
+		payload, err := json.Marshal(&logs[i])
+		if err != nil {
+			continue
+		}
+		messages = append(messages, streamMessage{id: logs[i].ID, payload: payload})
+	}
+	return messages
+}
+
 func (h *WebSocketHandler) Start() {
 	for {
 		select {
 		case client := <-h.register:
 			h.mutex.Lock()
 			h.clients[client] = true
-			h.tenantClients[client.tenantID]++
+			metrics.WebSocketClients.Inc()
+
+			if client.admin != nil {
+				// Subscribe to every tenant's logs if this is the first admin client
+				h.adminClients++
+				if h.adminClients == 1 {
+					if err := h.pubsub.SubscribeAll(h.ctx, h.handleFirehoseMessage); err != nil {
+						h.logger.Errorf("Failed to subscribe to all tenants: %v", err)
+					}
+				}
+				h.mutex.Unlock()
+				continue
+			}
 
 			// Subscribe to tenant's channel if this is the first client
+			h.tenantClients[client.tenantID]++
 			if h.tenantClients[client.tenantID] == 1 {
 				if err := h.pubsub.Subscribe(h.ctx, client.tenantID, h.handlePubSubMessage); err != nil {
 					h.logger.Errorf("Failed to subscribe to tenant %s: %v", client.tenantID, err)
@@ -112,14 +284,22 @@ func (h *WebSocketHandler) Start() {
 			if _, ok := h.clients[client]; ok {
 				delete(h.clients, client)
 				close(client.send)
+				metrics.WebSocketClients.Dec()
 
-				// Decrement tenant client count
-				h.tenantClients[client.tenantID]--
+				if client.admin != nil {
+					h.adminClients--
+					if h.adminClients == 0 {
+						h.pubsub.UnsubscribeAll()
+					}
+				} else {
+					// Decrement tenant client count
+					h.tenantClients[client.tenantID]--
 
-				// Unsubscribe if no more clients for this tenant
-				if h.tenantClients[client.tenantID] == 0 {
-					h.pubsub.Unsubscribe(client.tenantID)
-					delete(h.tenantClients, client.tenantID)
+					// Unsubscribe if no more clients for this tenant
+					if h.tenantClients[client.tenantID] == 0 {
+						h.pubsub.Unsubscribe(client.tenantID)
+						delete(h.tenantClients, client.tenantID)
+					}
 				}
 			}
 			h.mutex.Unlock()
@@ -130,54 +310,64 @@ func (h *WebSocketHandler) Start() {
 	}
 }
 
+// unregisterClient hands a client to the hub for removal. Once the hub is
+// stopped nothing serves the channel, so it gives up instead of blocking.
+func (h *WebSocketHandler) unregisterClient(client *Client) {
+	select {
+	case h.unregister <- client:
+	case <-h.ctx.Done():
+	}
+}
+
 func (h *WebSocketHandler) Stop() {
 	h.cancel()
 	h.pubsub.Close()
 }
 
-// handlePubSubMessage handles messages received from Redis pub/sub
+// handlePubSubMessage queues a log received from the broker for the clients
+// accepting it. The broadcast only reads the client set; clients leave it
+// through the unregister channel, which the hub serves under the write lock.
 func (h *WebSocketHandler) handlePubSubMessage(log *dto.AuditLogResponse) {
-	message, err := json.Marshal(log)
+	payload, err := json.Marshal(log)
 	if err != nil {
 		h.logger.Errorf("Error marshaling log: %v", err)
 		return
 	}
+	message := streamMessage{id: log.ID, payload: payload}
 
 	h.mutex.RLock()
 	defer h.mutex.RUnlock()
 
 	for client := range h.clients {
-		if client.tenantID == log.TenantID {
-			select {
-			case client.send <- message:
-			default: // If the channel is full, close the channel and remove the client
-				close(client.send)
-				delete(h.clients, client)
-				h.tenantClients[client.tenantID]--
-
-				// Unsubscribe if no more clients for this tenant
-				if h.tenantClients[client.tenantID] == 0 {
-					h.pubsub.Unsubscribe(client.tenantID)
-					delete(h.tenantClients, client.tenantID)
-				}
-			}
+		if client.accepts(log) {
+			client.enqueue(message)
 		}
 	}
 }
 
-func (h *WebSocketHandler) writePump(client *Client) {
+// writePump writes the replayed logs, then live logs in batches as they are
+// queued. Live logs already replayed are skipped, so each log is written once,
+// in order. A client not reading within websocketWriteWait is disconnected;
+// closing the connection ends readPump, which unregisters the client.
+func (h *WebSocketHandler) writePump(client *Client, replayed []streamMessage) {
 	defer func() {
 		client.conn.Close()
 	}()
 
-	for message := range client.send {
-		w, err := client.conn.NextWriter(websocket.TextMessage)
-		if err != nil {
-			return
-		}
-		w.Write(message)
+	seen := make(map[string]struct{}, len(replayed))
+	for _, message := range replayed {
+		seen[message.id] = struct{}{}
+	}
+	if err := writeWebSocketBatch(client.conn, replayed, nil); err != nil {
+		return
+	}
 
-		if err := w.Close(); err != nil {
+	for {
+		batch, ok := client.nextBatch()
+		if !ok {
+			break
+		}
+		if err := writeWebSocketBatch(client.conn, batch, seen); err != nil {
 			return
 		}
 	}
@@ -186,9 +376,26 @@ func (h *WebSocketHandler) writePump(client *Client) {
 	client.conn.WriteMessage(websocket.CloseMessage, []byte{})
 }
 
+// writeWebSocketBatch writes a batch of logs, one message each, under a
+// single write deadline, skipping logs in skip
+func writeWebSocketBatch(conn *websocket.Conn, batch []streamMessage, skip map[string]struct{}) error {
+	if err := conn.SetWriteDeadline(time.Now().Add(websocketWriteWait)); err != nil {
+		return err
+	}
+	for _, message := range batch {
+		if _, dup := skip[message.id]; dup {
+			continue
+		}
+		if err := conn.WriteMessage(websocket.TextMessage, message.payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (h *WebSocketHandler) readPump(client *Client) {
 	defer func() {
-		h.unregister <- client
+		h.unregisterClient(client)
 		client.conn.Close()
 	}()
 
@@ -209,10 +416,3 @@ func (h *WebSocketHandler) readPump(client *Client) {
 		}
 	}
 }
-
-// BroadcastLog sends a log to all connected clients of the same tenant
-func (h *WebSocketHandler) BroadcastLog(log *dto.AuditLogResponse) {
-	if err := h.pubsub.Publish(h.ctx, log); err != nil {
-		h.logger.Errorf("Failed to publish log: %v", err)
-	}
-}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+)
+
+type StreamClientTestSuite struct {
+	suite.Suite
+}
+
+func TestStreamClient(t *testing.T) {
+	suite.Run(t, new(StreamClientTestSuite))
+}
+
+func (s *StreamClientTestSuite) TestEnqueue_FullQueueDropsOldest() {
+	// Arrange
+	client := &Client{send: make(chan streamMessage, 2)}
+
+	// Act
+	for i := 1; i <= 3; i++ {
+		client.enqueue(streamMessage{id: fmt.Sprint(i)})
+	}
+
+	// Assert
+	batch, ok := client.nextBatch()
+	s.True(ok)
+	s.Require().Len(batch, 2)
+	s.Equal("2", batch[0].id)
+	s.Equal("3", batch[1].id)
+}
+
+func (s *StreamClientTestSuite) TestNextBatch_CapsBatchSize() {
+	// Arrange
+	client := &Client{send: make(chan streamMessage, streamBatchSize+1)}
+	for i := 0; i <= streamBatchSize; i++ {
+		client.enqueue(streamMessage{id: fmt.Sprint(i)})
+	}
+
+	// Act
+	first, _ := client.nextBatch()
+	second, _ := client.nextBatch()
+
+	// Assert
+	s.Len(first, streamBatchSize)
+	s.Len(second, 1)
+	s.Equal(fmt.Sprint(streamBatchSize), second[0].id)
+}
+
+func (s *StreamClientTestSuite) TestNextBatch_ClosedQueue() {
+	// Arrange
+	client := &Client{send: make(chan streamMessage, 1)}
+	close(client.send)
+
+	// Act
+	batch, ok := client.nextBatch()
+
+	// Assert
+	s.False(ok)
+	s.Empty(batch)
+}
+
+func (s *StreamClientTestSuite) adminStreamContext(query string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest(http.MethodGet, "/admin/logs/stream?"+query, nil)
+	return c
+}
+
+func (s *StreamClientTestSuite) TestNewAdminStream_FiltersAndLabels() {
+	// Arrange
+	tenants := new(MockTenantService)
+	tenants.On("List", mock.Anything).Return([]dto.CreateTenantResponse{
+		{ID: "123e4567-e89b-12d3-a456-426614174000", Name: "Acme"},
+	}, nil)
+	handler := &WebSocketHandler{tenants: tenants}
+	c := s.adminStreamContext("tenant_id=123e4567-e89b-12d3-a456-426614174000&severity=error,critical&rate=2")
+
+	// Act
+	stream, err := handler.newAdminStream(c)
+
+	// Assert
+	s.Require().NoError(err)
+	log := &dto.AuditLogResponse{ID: "1", TenantID: "123e4567-e89b-12d3-a456-426614174000", Severity: "ERROR"}
+	s.True(stream.accepts(log))
+	s.False(stream.accepts(&dto.AuditLogResponse{TenantID: log.TenantID, Severity: "INFO"}))
+	s.False(stream.accepts(&dto.AuditLogResponse{TenantID: "223e4567-e89b-12d3-a456-426614174000", Severity: "ERROR"}))
+
+	labelled, err := stream.label(log, []byte(`{"id":"1"}`))
+	s.Require().NoError(err)
+	var message adminStreamMessage
+	s.Require().NoError(json.Unmarshal(labelled, &message))
+	s.Equal("Acme", message.TenantName)
+	s.JSONEq(`{"id":"1"}`, string(message.Log))
+}
+
+func (s *StreamClientTestSuite) TestNewAdminStream_InvalidRate() {
+	// Arrange
+	handler := &WebSocketHandler{tenants: new(MockTenantService)}
+	c := s.adminStreamContext(fmt.Sprintf("rate=%d", adminStreamMaxRate+1))
+
+	// Act
+	stream, err := handler.newAdminStream(c)
+
+	// Assert
+	s.Error(err)
+	s.Nil(stream)
+}
+
+func (s *StreamClientTestSuite) TestAdminStream_RateCapPerTenant() {
+	// Arrange
+	stream := &adminStream{rate: 2, windows: make(map[string]rateWindow)}
+
+	// Act
+	first := stream.accepts(&dto.AuditLogResponse{TenantID: "tenant1"})
+	second := stream.accepts(&dto.AuditLogResponse{TenantID: "tenant1"})
+	third := stream.accepts(&dto.AuditLogResponse{TenantID: "tenant1"})
+	other := stream.accepts(&dto.AuditLogResponse{TenantID: "tenant2"})
+
+	// Assert
+	s.True(first)
+	s.True(second)
+	s.False(third)
+	s.True(other)
+}
+
+func (s *StreamClientTestSuite) TestAccepts_AdminClientSkipsTenantBroadcast() {
+	// Arrange
+	client := &Client{tenantID: "tenant1", admin: &adminStream{}}
+
+	// Act
+	accepted := client.accepts(&dto.AuditLogResponse{TenantID: "tenant1"})
+
+	// Assert
+	s.False(accepted)
+}
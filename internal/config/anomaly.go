@@ -0,0 +1,35 @@
+package config
+
+import "time"
+
+type AnomalyConfig struct {
+	// Window is both how often the worker runs and the span of recent activity it checks
+	Window time.Duration `validate:"gt=0"`
+	// BaselinePeriod is the history before the window that activity is compared against
+	BaselinePeriod time.Duration `validate:"gtfield=Window"`
+	// MinLogs skips rate and failure checks for windows with fewer logs
+	MinLogs int64 `validate:"min=0"`
+	// RateMultiplier flags windows logging more than this multiple of the baseline rate
+	RateMultiplier float64 `validate:"gt=1"`
+	// FailureRatioDelta flags windows whose failure ratio exceeds the baseline ratio by this much
+	FailureRatioDelta float64 `validate:"gt=0,lte=1"`
+	// NewIPThreshold flags users acting from at least this many IP addresses unseen in the baseline
+	NewIPThreshold int `validate:"min=1"`
+}
+
+// DefaultAnomalyConfig loads anomaly detection thresholds from ANOMALY_*
+// environment variables
+func DefaultAnomalyConfig() *AnomalyConfig {
+	return &AnomalyConfig{
+		Window:            getDuration("anomaly.window", 15*time.Minute),
+		BaselinePeriod:    getDuration("anomaly.baseline_period", 7*24*time.Hour),
+		MinLogs:           int64(getInt("anomaly.min_logs", 100)),
+		RateMultiplier:    getFloat("anomaly.rate_multiplier", 3),
+		FailureRatioDelta: getFloat("anomaly.failure_ratio_delta", 0.2),
+		NewIPThreshold:    getInt("anomaly.new_ip_threshold", 1),
+	}
+}
+
+func (c *AnomalyConfig) Validate() error {
+	return validateStruct(c)
+}
@@ -0,0 +1,29 @@
+package config
+
+import "time"
+
+// ArchiveScheduleConfig controls the archive scheduler, which archives and
+// then deletes each tenant's logs older than its retention
+type ArchiveScheduleConfig struct {
+	// CheckInterval is how often the scheduler looks for tenants due a run
+	CheckInterval time.Duration `validate:"gt=0"`
+	// Interval is how often a tenant is archived unless its schedule overrides it
+	Interval time.Duration `validate:"gt=0"`
+	// Retention applies to tenants without a retention setting; zero leaves
+	// their logs in place
+	Retention time.Duration `validate:"gte=0"`
+}
+
+// DefaultArchiveScheduleConfig loads the scheduler settings from
+// ARCHIVE_SCHEDULE_* environment variables
+func DefaultArchiveScheduleConfig() *ArchiveScheduleConfig {
+	return &ArchiveScheduleConfig{
+		CheckInterval: getDuration("archive_schedule.check_interval", 5*time.Minute),
+		Interval:      getDuration("archive_schedule.interval", 24*time.Hour),
+		Retention:     getDuration("archive_schedule.retention", 0),
+	}
+}
+
+func (c *ArchiveScheduleConfig) Validate() error {
+	return validateStruct(c)
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"time"
+
+	"github.com/kingrain94/audit-log-api/pkg/breaker"
+)
+
+// BreakerConfig controls the circuit breaker of a dependency, which fails
+// calls at once while the dependency is down instead of letting each wait on
+// a timeout
+type BreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that opens the
+	// breaker; zero disables it
+	FailureThreshold int `validate:"gte=0"`
+	// OpenTimeout is how long the breaker fails calls before probing the
+	// dependency again
+	OpenTimeout time.Duration `validate:"gt=0"`
+	// HalfOpenRequests is the number of probe calls that must succeed to
+	// close the breaker again
+	HalfOpenRequests int `validate:"min=1"`
+}
+
+// DefaultBreakerConfig loads the breaker settings of a dependency from
+// <DEPENDENCY>_BREAKER_* environment variables, such as
+// OPENSEARCH_BREAKER_FAILURE_THRESHOLD for the dependency "opensearch"
+func DefaultBreakerConfig(dependency string) *BreakerConfig {
+	return &BreakerConfig{
+		FailureThreshold: getInt(dependency+".breaker.failure_threshold", 5),
+		OpenTimeout:      getDuration(dependency+".breaker.open_timeout", 30*time.Second),
+		HalfOpenRequests: getInt(dependency+".breaker.half_open_requests", 1),
+	}
+}
+
+func (c *BreakerConfig) Validate() error {
+	return validateStruct(c)
+}
+
+// Enabled reports whether the dependency is guarded by a breaker
+func (c *BreakerConfig) Enabled() bool {
+	return c != nil && c.FailureThreshold > 0
+}
+
+// NewBreaker returns the breaker of the named dependency, nil when disabled,
+// which lets every call through. isFailure and onStateChange are optional.
+func (c *BreakerConfig) NewBreaker(name string, isFailure func(error) bool, onStateChange func(name string, from, to breaker.State)) *breaker.Breaker {
+	if !c.Enabled() {
+		return nil
+	}
+	return breaker.New(breaker.Settings{
+		Name:             name,
+		FailureThreshold: c.FailureThreshold,
+		OpenTimeout:      c.OpenTimeout,
+		HalfOpenRequests: c.HalfOpenRequests,
+		IsFailure:        isFailure,
+		OnStateChange:    onStateChange,
+	})
+}
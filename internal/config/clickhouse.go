@@ -0,0 +1,59 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+// ClickHouseConfig locates the optional ClickHouse analytics store. Stats are
+// aggregated there instead of in OpenSearch and PostgreSQL once Addr is set.
+type ClickHouseConfig struct {
+	// Addr is a comma separated list of host:port native protocol addresses
+	Addr        string
+	Database    string `validate:"required_with=Addr"`
+	Username    string `validate:"required_with=Addr"`
+	Password    string
+	DialTimeout time.Duration `validate:"gt=0"`
+}
+
+func DefaultClickHouseConfig() *ClickHouseConfig {
+	return &ClickHouseConfig{
+		Addr:        getString("clickhouse.addr", ""),
+		Database:    getString("clickhouse.database", "audit_log"),
+		Username:    getString("clickhouse.username", "default"),
+		Password:    getString("clickhouse.password", ""),
+		DialTimeout: getDuration("clickhouse.dial_timeout", 5*time.Second),
+	}
+}
+
+func (c *ClickHouseConfig) Validate() error {
+	return validateStruct(c)
+}
+
+// Enabled reports whether a ClickHouse analytics store is configured
+func (c *ClickHouseConfig) Enabled() bool {
+	return c.Addr != ""
+}
+
+// Open connects to ClickHouse over the native protocol
+func (c *ClickHouseConfig) Open() (driver.Conn, error) {
+	var addrs []string
+	for _, addr := range strings.Split(c.Addr, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+
+	return clickhouse.Open(&clickhouse.Options{
+		Addr: addrs,
+		Auth: clickhouse.Auth{
+			Database: c.Database,
+			Username: c.Username,
+			Password: c.Password,
+		},
+		DialTimeout: c.DialTimeout,
+	})
+}
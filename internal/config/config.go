@@ -1,44 +1,93 @@
 package config
 
 import (
-	"os"
-	"strconv"
+	"errors"
+	"time"
 )
 
 type Config struct {
-	ServerPort         int    `json:"server_port"`
+	ServerPort         int    `json:"server_port" validate:"min=1,max=65535"`
 	JWTSecretKey       string `json:"jwt_secret_key"`
-	JWTExpirationHours int    `json:"jwt_expiration_hours"`
-	DefaultRateLimit   int    `json:"default_rate_limit"`
-	GlobalRateLimit    int    `json:"global_rate_limit"`
+	JWTExpirationHours int    `json:"jwt_expiration_hours" validate:"min=1"`
+
+	// Lifetimes of tokens issued by /auth/token. Access tokens are short-lived since
+	// role changes and deactivation only take effect on the next refresh.
+	AccessTokenTTL  time.Duration `json:"access_token_ttl" validate:"gt=0"`
+	RefreshTokenTTL time.Duration `json:"refresh_token_ttl" validate:"gtfield=AccessTokenTTL"`
+	// StreamTicketTTL is how long a one-time ticket for opening a log stream stays redeemable
+	StreamTicketTTL  time.Duration `json:"stream_ticket_ttl" validate:"gt=0"`
+	DefaultRateLimit int           `json:"default_rate_limit" validate:"min=1"`
+	GlobalRateLimit  int           `json:"global_rate_limit" validate:"min=1"`
+
+	// Rate limit algorithms ("sliding_window" or "token_bucket") for ingest and query routes
+	IngestRateLimitAlgorithm string `json:"ingest_rate_limit_algorithm" validate:"oneof=sliding_window token_bucket"`
+	QueryRateLimitAlgorithm  string `json:"query_rate_limit_algorithm" validate:"oneof=sliding_window token_bucket"`
+
+	// Callers holding one of RateLimitExemptRoles, or whose token subject is
+	// one of RateLimitExemptSubjects, skip tenant rate limiting
+	RateLimitExemptRoles    []string `json:"rate_limit_exempt_roles"`
+	RateLimitExemptSubjects []string `json:"rate_limit_exempt_subjects"`
+
+	// TenantRateLimitCacheTTL bounds how long a changed tenant limit can take to reach every API instance
+	TenantRateLimitCacheTTL time.Duration `json:"tenant_rate_limit_cache_ttl"`
+
+	// PolicyCacheTTL bounds how long a changed policy can take to reach every API instance
+	PolicyCacheTTL time.Duration `json:"policy_cache_ttl"`
+
+	// RedactionRuleCacheTTL bounds how long a changed redaction rule can take to reach every API instance
+	RedactionRuleCacheTTL time.Duration `json:"redaction_rule_cache_ttl"`
+
+	// TaggingRuleCacheTTL bounds how long a changed tagging rule can take to reach every API instance
+	TaggingRuleCacheTTL time.Duration `json:"tagging_rule_cache_ttl"`
+
+	// ResourceSchemaCacheTTL bounds how long a changed resource schema can take to reach every API instance
+	ResourceSchemaCacheTTL time.Duration `json:"resource_schema_cache_ttl"`
+
+	// TenantSettingsCacheTTL bounds how long changed tenant settings can take to reach every API instance
+	TenantSettingsCacheTTL time.Duration `json:"tenant_settings_cache_ttl"`
 }
 
+// Load resolves the API configuration and validates it, failing on missing
+// or malformed settings instead of starting with unusable defaults
 func Load() (*Config, error) {
-	serverPort, _ := strconv.Atoi(os.Getenv("SERVER_PORT"))
-	if serverPort == 0 {
-		serverPort = 10000
-	}
+	cfg := &Config{
+		ServerPort:         getInt("server.port", 10000),
+		JWTSecretKey:       getString("jwt.secret_key", ""),
+		JWTExpirationHours: getInt("jwt.expiration_hours", 24),
 
-	jwtExpirationHours, _ := strconv.Atoi(os.Getenv("JWT_EXPIRATION_HOURS"))
-	if jwtExpirationHours == 0 {
-		jwtExpirationHours = 24
-	}
+		AccessTokenTTL:   getDuration("jwt.access_token_ttl", 15*time.Minute),
+		RefreshTokenTTL:  getDuration("jwt.refresh_token_ttl", 7*24*time.Hour),
+		StreamTicketTTL:  getDuration("jwt.stream_ticket_ttl", 30*time.Second),
+		DefaultRateLimit: getInt("default_rate_limit", 1000), // 1000 requests per minute per tenant
+		GlobalRateLimit:  getInt("global_rate_limit", 10000), // 10000 requests per minute globally per IP
 
-	defaultRateLimit, _ := strconv.Atoi(os.Getenv("DEFAULT_RATE_LIMIT"))
-	if defaultRateLimit == 0 {
-		defaultRateLimit = 1000 // 1000 requests per minute per tenant
+		IngestRateLimitAlgorithm: getString("ingest_rate_limit_algorithm", "token_bucket"),
+		QueryRateLimitAlgorithm:  getString("query_rate_limit_algorithm", "sliding_window"),
+		RateLimitExemptRoles:     parseList(getString("rate_limit_exempt_roles", "")),
+		RateLimitExemptSubjects:  parseList(getString("rate_limit_exempt_subjects", "")),
+
+		TenantRateLimitCacheTTL: getDuration("tenant_rate_limit_cache_ttl", 5*time.Minute),
+		PolicyCacheTTL:          getDuration("policy_cache_ttl", time.Minute),
+		RedactionRuleCacheTTL:   getDuration("redaction_rule_cache_ttl", time.Minute),
+		TaggingRuleCacheTTL:     getDuration("tagging_rule_cache_ttl", time.Minute),
+		ResourceSchemaCacheTTL:  getDuration("resource_schema_cache_ttl", time.Minute),
+		TenantSettingsCacheTTL:  getDuration("tenant_settings_cache_ttl", time.Minute),
 	}
 
-	globalRateLimit, _ := strconv.Atoi(os.Getenv("GLOBAL_RATE_LIMIT"))
-	if globalRateLimit == 0 {
-		globalRateLimit = 10000 // 10000 requests per minute globally per IP
+	if err := cfg.Validate(); err != nil {
+		return nil, err
 	}
+	return cfg, nil
+}
 
-	return &Config{
-		ServerPort:         serverPort,
-		JWTSecretKey:       os.Getenv("JWT_SECRET_KEY"),
-		JWTExpirationHours: jwtExpirationHours,
-		DefaultRateLimit:   defaultRateLimit,
-		GlobalRateLimit:    globalRateLimit,
-	}, nil
+// Validate checks the API settings together with the authentication mode
+// they depend on
+func (c *Config) Validate() error {
+	oidcConfig := DefaultOIDCConfig()
+	errs := fieldErrors(c, oidcConfig)
+	// Tokens are signed with the shared secret unless an identity provider issues them all
+	if c.JWTSecretKey == "" && oidcConfig.AcceptsHMAC() {
+		errs = append(errs, errors.New("JWT_SECRET_KEY is required unless AUTH_MODE is oidc"))
+	}
+	return invalidConfig(errs)
 }
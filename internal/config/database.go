@@ -1,29 +1,51 @@
 package config
 
 import (
+	"database/sql"
 	"fmt"
-	"os"
-	"strconv"
 	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+
+	"github.com/kingrain94/audit-log-api/internal/metrics"
 )
 
 type DatabaseConfig struct {
-	Host     string
-	Port     string
-	User     string
+	Host     string `validate:"required"`
+	Port     string `validate:"required,numeric"`
+	User     string `validate:"required"`
 	Password string
-	DBName   string
-	SSLMode  string
+	DBName   string `validate:"required"`
+	SSLMode  string `validate:"oneof=disable allow prefer require verify-ca verify-full"`
 }
 
+// Storage modes of the audit_logs table
+const (
+	// StorageModePartitioned keeps audit_logs natively partitioned by month
+	StorageModePartitioned = "partitioned"
+	// StorageModeTimescale keeps audit_logs as a TimescaleDB hypertable with
+	// compressed chunks and a continuous aggregate for hourly stats, set up by
+	// the migrations in scripts/migrations/timescale
+	StorageModeTimescale = "timescale"
+)
+
+// Bulk insert modes of audit_logs
+const (
+	// BulkInsertCopy stores batches of logs with COPY FROM
+	BulkInsertCopy = "copy"
+	// BulkInsertInsert stores batches of logs with multi-row INSERTs
+	BulkInsertInsert = "insert"
+)
+
 type ConnectionPoolConfig struct {
-	MaxOpenConns    int
-	MaxIdleConns    int
-	ConnMaxLifetime time.Duration
+	MaxOpenConns    int           `validate:"min=1"`
+	MaxIdleConns    int           `validate:"min=0,ltefield=MaxOpenConns"`
+	ConnMaxLifetime time.Duration `validate:"gte=0"`
+	// StatsLogInterval is how often the API logs the statistics of its
+	// connection pools; 0 disables the logs
+	StatsLogInterval time.Duration `validate:"gte=0"`
 }
 
 func DefaultConnectionPoolConfig() *ConnectionPoolConfig {
@@ -34,62 +56,37 @@ func DefaultConnectionPoolConfig() *ConnectionPoolConfig {
 	}
 }
 
-func getEnvWithDefault(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
-func getEnvIntWithDefault(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intValue, err := strconv.Atoi(value); err == nil {
-			return intValue
-		}
-	}
-	return defaultValue
-}
-
-// getEnvDurationWithDefault returns environment variable as duration or default if not set
-func getEnvDurationWithDefault(key string, defaultValue time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
-		if duration, err := time.ParseDuration(value); err == nil {
-			return duration
-		}
-	}
-	return defaultValue
-}
-
-// getWriterConfig loads writer database configuration from environment variables
+// getWriterConfig loads writer database configuration
 func getWriterConfig() *DatabaseConfig {
 	return &DatabaseConfig{
-		Host:     getEnvWithDefault("POSTGRES_WRITER_HOST", "localhost"),
-		Port:     getEnvWithDefault("POSTGRES_WRITER_PORT", "5432"),
-		User:     getEnvWithDefault("POSTGRES_WRITER_USER", "postgres"),
-		Password: getEnvWithDefault("POSTGRES_WRITER_PASSWORD", ""),
-		DBName:   getEnvWithDefault("POSTGRES_WRITER_DB_NAME", "audit_log"),
-		SSLMode:  getEnvWithDefault("POSTGRES_WRITER_SSL_MODE", "disable"),
+		Host:     getString("postgres.writer.host", "localhost"),
+		Port:     getString("postgres.writer.port", "5432"),
+		User:     getString("postgres.writer.user", "postgres"),
+		Password: getString("postgres.writer.password", ""),
+		DBName:   getString("postgres.writer.db_name", "audit_log"),
+		SSLMode:  getString("postgres.writer.ssl_mode", "disable"),
 	}
 }
 
-// getReaderConfig loads reader database configuration from environment variables
+// getReaderConfig loads reader database configuration
 func getReaderConfig() *DatabaseConfig {
 	return &DatabaseConfig{
-		Host:     getEnvWithDefault("POSTGRES_READER_HOST", "localhost"),
-		Port:     getEnvWithDefault("POSTGRES_READER_PORT", "5432"),
-		User:     getEnvWithDefault("POSTGRES_READER_USER", "postgres"),
-		Password: getEnvWithDefault("POSTGRES_READER_PASSWORD", ""),
-		DBName:   getEnvWithDefault("POSTGRES_READER_DB_NAME", "audit_log"),
-		SSLMode:  getEnvWithDefault("POSTGRES_READER_SSL_MODE", "disable"),
+		Host:     getString("postgres.reader.host", "localhost"),
+		Port:     getString("postgres.reader.port", "5432"),
+		User:     getString("postgres.reader.user", "postgres"),
+		Password: getString("postgres.reader.password", ""),
+		DBName:   getString("postgres.reader.db_name", "audit_log"),
+		SSLMode:  getString("postgres.reader.ssl_mode", "disable"),
 	}
 }
 
-// getConnectionPoolConfig loads connection pool configuration from environment variables
+// getConnectionPoolConfig loads connection pool configuration
 func getConnectionPoolConfig() *ConnectionPoolConfig {
 	return &ConnectionPoolConfig{
-		MaxOpenConns:    getEnvIntWithDefault("DB_MAX_OPEN_CONNS", 50),
-		MaxIdleConns:    getEnvIntWithDefault("DB_MAX_IDLE_CONNS", 10),
-		ConnMaxLifetime: getEnvDurationWithDefault("DB_CONN_MAX_LIFETIME", 1*time.Hour),
+		MaxOpenConns:     getInt("db.max_open_conns", 50),
+		MaxIdleConns:     getInt("db.max_idle_conns", 10),
+		ConnMaxLifetime:  getDuration("db.conn_max_lifetime", 1*time.Hour),
+		StatsLogInterval: getDuration("db.stats_log_interval", time.Minute),
 	}
 }
 
@@ -116,8 +113,15 @@ func configureConnectionPool(gormDB *gorm.DB, poolConfig *ConnectionPoolConfig)
 
 // createDatabaseConnection creates a GORM database connection with connection pool tuning
 func createDatabaseConnection(config *DatabaseConfig, poolConfig *ConnectionPoolConfig) (*gorm.DB, error) {
-	dsn := config.buildDSN()
+	if err := validateStruct(config, poolConfig); err != nil {
+		return nil, err
+	}
+
+	return openDatabase(config.buildDSN(), poolConfig)
+}
 
+// openDatabase opens a GORM connection to dsn with connection pool tuning
+func openDatabase(dsn string, poolConfig *ConnectionPoolConfig) (*gorm.DB, error) {
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
 	})
@@ -140,35 +144,120 @@ func NewWriterDatabase() (*gorm.DB, error) {
 	return createDatabaseConnection(config, poolConfig)
 }
 
-// NewReaderDatabase creates a database connection optimized for read operations
-func NewReaderDatabase() (*gorm.DB, error) {
-	config := getReaderConfig()
-	poolConfig := getConnectionPoolConfig()
-	return createDatabaseConnection(config, poolConfig)
+// NewReaderDatabase creates a database connection optimized for read
+// operations, spread over the readers of poolConfig and falling back to writer
+func NewReaderDatabase(writer *gorm.DB, poolConfig *ReaderPoolConfig) (*gorm.DB, *ReaderPool, error) {
+	if err := validateStruct(poolConfig); err != nil {
+		return nil, nil, err
+	}
+	writerDB, err := writer.DB()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get sql.DB from gorm.DB: %w", err)
+	}
+
+	connPoolConfig := getConnectionPoolConfig()
+	dsns := poolConfig.DSNs
+	if len(dsns) == 0 {
+		config := getReaderConfig()
+		if err := validateStruct(config, connPoolConfig); err != nil {
+			return nil, nil, err
+		}
+		dsns = []string{config.buildDSN()}
+	}
+
+	readers := make([]*sql.DB, 0, len(dsns))
+	for i, dsn := range dsns {
+		db, err := openDatabase(dsn, connPoolConfig)
+		if err == nil {
+			var sqlDB *sql.DB
+			if sqlDB, err = db.DB(); err == nil {
+				readers = append(readers, sqlDB)
+				continue
+			}
+		}
+		for _, reader := range readers {
+			reader.Close()
+		}
+		return nil, nil, fmt.Errorf("failed to connect to reader %d: %w", i, err)
+	}
+
+	pool := NewReaderPool(readers, writerDB, poolConfig)
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: pool}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Info),
+	})
+	if err != nil {
+		pool.Close()
+		return nil, nil, fmt.Errorf("failed to open reader pool: %w", err)
+	}
+	return db, pool, nil
 }
 
 // DatabaseConnections holds both writer and reader database connections
 type DatabaseConnections struct {
 	Writer *gorm.DB
-	Reader *gorm.DB
+	// Reader spreads reads over ReaderPool's read replicas
+	Reader     *gorm.DB
+	ReaderPool *ReaderPool
+	// StorageMode is how the audit_logs table is stored, one of the StorageMode constants
+	StorageMode string `validate:"oneof=partitioned timescale"`
+	// BulkInsertMode is how batches of logs are stored, one of the BulkInsert constants
+	BulkInsertMode string `validate:"oneof=copy insert"`
+	// PoolConfig is the configuration the connection pools were opened with
+	PoolConfig *ConnectionPoolConfig
 }
 
 // NewDatabaseConnections creates both writer and reader database connections
 func NewDatabaseConnections() (*DatabaseConnections, error) {
+	storageMode := getString("postgres.storage_mode", StorageModePartitioned)
+	bulkInsertMode := getString("postgres.bulk_insert_mode", BulkInsertCopy)
+	if err := validateStruct(&DatabaseConnections{StorageMode: storageMode, BulkInsertMode: bulkInsertMode}); err != nil {
+		return nil, err
+	}
+
 	writer, err := NewWriterDatabase()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create writer database connection: %w", err)
 	}
 
-	reader, err := NewReaderDatabase()
+	reader, readerPool, err := NewReaderDatabase(writer, DefaultReaderPoolConfig())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create reader database connection: %w", err)
 	}
 
-	return &DatabaseConnections{
-		Writer: writer,
-		Reader: reader,
-	}, nil
+	connections := &DatabaseConnections{
+		Writer:         writer,
+		Reader:         reader,
+		ReaderPool:     readerPool,
+		StorageMode:    storageMode,
+		BulkInsertMode: bulkInsertMode,
+		PoolConfig:     getConnectionPoolConfig(),
+	}
+	pools, err := connections.Pools()
+	if err != nil {
+		connections.Close()
+		return nil, err
+	}
+	for name, db := range pools {
+		metrics.ObserveDBPool(name, db)
+	}
+	return connections, nil
+}
+
+// Pools returns the connection pools of the writer, named writer, and of
+// each reader
+func (dc *DatabaseConnections) Pools() (map[string]*sql.DB, error) {
+	writer, err := dc.Writer.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sql.DB from gorm.DB: %w", err)
+	}
+
+	pools := map[string]*sql.DB{"writer": writer}
+	if dc.ReaderPool != nil {
+		for name, db := range dc.ReaderPool.Readers() {
+			pools[name] = db
+		}
+	}
+	return pools, nil
 }
 
 // Close closes both writer and reader database connections
@@ -181,10 +270,8 @@ func (dc *DatabaseConnections) Close() error {
 		}
 	}
 
-	if dc.Reader != nil {
-		if sqlDB, err := dc.Reader.DB(); err == nil {
-			readerErr = sqlDB.Close()
-		}
+	if dc.ReaderPool != nil {
+		readerErr = dc.ReaderPool.Close()
 	}
 
 	if writerErr != nil {
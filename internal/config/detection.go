@@ -0,0 +1,70 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+// DetectionConfig controls the detection worker, which evaluates the
+// built-in detection rules on the live stream of logs
+type DetectionConfig struct {
+	// BruteForceThreshold alerts on an IP address failing this many actions
+	// within BruteForceWindow; 0 disables the rule
+	BruteForceThreshold int           `validate:"min=0"`
+	BruteForceWindow    time.Duration `validate:"gt=0"`
+	// BruteForceActions restricts the failed actions counted; empty counts all
+	BruteForceActions []string
+	// GeoIPDatabase is the path of the IP to city database impossible travel
+	// locates addresses with; empty disables the rule
+	GeoIPDatabase string
+	// TravelMaxSpeedKmh alerts on users appearing from two places farther
+	// apart than they could travel at this speed
+	TravelMaxSpeedKmh float64 `validate:"gt=0"`
+	// TravelMinDistanceKm ignores places closer than this, which IP
+	// geolocation can't tell apart
+	TravelMinDistanceKm float64 `validate:"gte=0"`
+	// TravelMaxGap forgets where a user was after this long
+	TravelMaxGap time.Duration `validate:"gt=0"`
+	// AlertCooldown suppresses repeated alerts of a rule for the same IP
+	// address or user, across workers
+	AlertCooldown time.Duration `validate:"gte=0"`
+	// QueueSize bounds the logs waiting to be evaluated; more are dropped
+	QueueSize      int           `validate:"min=1"`
+	WebhookTimeout time.Duration `validate:"gt=0"`
+}
+
+// DefaultDetectionConfig loads the detection rule settings from DETECTION_*
+// environment variables. DETECTION_BRUTE_FORCE_ACTIONS is a comma separated
+// list.
+func DefaultDetectionConfig() *DetectionConfig {
+	return &DetectionConfig{
+		BruteForceThreshold: getInt("detection.brute_force_threshold", 10),
+		BruteForceWindow:    getDuration("detection.brute_force_window", 5*time.Minute),
+		BruteForceActions:   parseList(getString("detection.brute_force_actions", "")),
+		GeoIPDatabase:       getString("detection.geoip_database", ""),
+		TravelMaxSpeedKmh:   getFloat("detection.travel_max_speed_kmh", 1000),
+		TravelMinDistanceKm: getFloat("detection.travel_min_distance_km", 500),
+		TravelMaxGap:        getDuration("detection.travel_max_gap", 24*time.Hour),
+		AlertCooldown:       getDuration("detection.alert_cooldown", 15*time.Minute),
+		QueueSize:           getInt("detection.queue_size", 10000),
+		WebhookTimeout:      getDuration("detection.webhook_timeout", 5*time.Second),
+	}
+}
+
+func (c *DetectionConfig) Validate() error {
+	errs := fieldErrors(c)
+	if !c.BruteForceEnabled() && !c.ImpossibleTravelEnabled() {
+		errs = append(errs, errors.New("DETECTION_BRUTE_FORCE_THRESHOLD is 0 and DETECTION_GEOIP_DATABASE is empty, no rule is enabled"))
+	}
+	return invalidConfig(errs)
+}
+
+// BruteForceEnabled reports whether failed actions are counted per IP address
+func (c *DetectionConfig) BruteForceEnabled() bool {
+	return c.BruteForceThreshold > 0
+}
+
+// ImpossibleTravelEnabled reports whether users' locations are compared
+func (c *DetectionConfig) ImpossibleTravelEnabled() bool {
+	return c.GeoIPDatabase != ""
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ExportSigningConfig holds the key export files are signed with
+type ExportSigningConfig struct {
+	// KeyFile is a PEM-encoded PKCS #8 Ed25519 private key, such as one
+	// generated by openssl genpkey -algorithm ed25519; exports can't be
+	// signed without it
+	KeyFile string
+}
+
+// DefaultExportSigningConfig loads the export signing settings from
+// EXPORT_SIGNING_* environment variables
+func DefaultExportSigningConfig() *ExportSigningConfig {
+	return &ExportSigningConfig{
+		KeyFile: getString("export_signing.key_file", ""),
+	}
+}
+
+// Enabled reports whether exports can be signed
+func (c *ExportSigningConfig) Enabled() bool {
+	return c.KeyFile != ""
+}
+
+// PrivateKey reads the signing key from KeyFile
+func (c *ExportSigningConfig) PrivateKey() (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found in export signing key %s", c.KeyFile)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse export signing key: %w", err)
+	}
+	ed25519Key, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("export signing key must be an Ed25519 key")
+	}
+	return ed25519Key, nil
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+// ForwardingConfig controls the forwarding worker, which delivers the logs
+// of tenants' integrations to Splunk and Datadog
+type ForwardingConfig struct {
+	// PollInterval is how often integrations are checked for new logs
+	PollInterval time.Duration `validate:"gt=0"`
+	// BatchSize caps the logs of one delivery; Datadog accepts at most 1000
+	BatchSize int `validate:"min=1,max=1000"`
+	// MaxBatchBytes caps the encoded size of one delivery; Datadog accepts
+	// at most 5MB
+	MaxBatchBytes int `validate:"min=65536,max=5000000"`
+	// MaxBatches caps the deliveries of an integration each time it is
+	// claimed, so one busy integration doesn't hold a worker
+	MaxBatches int `validate:"min=1"`
+	// Concurrency is how many integrations a worker forwards at a time
+	Concurrency int `validate:"min=1"`
+	// SettleDelay holds logs back for this long after they are stored, so
+	// logs of transactions committing out of order aren't skipped
+	SettleDelay    time.Duration `validate:"gte=0"`
+	RequestTimeout time.Duration `validate:"gt=0"`
+	// MaxRetries is how many times a failed delivery is retried at once,
+	// RetryBackoff apart and doubling, before the integration backs off
+	MaxRetries   int           `validate:"min=0"`
+	RetryBackoff time.Duration `validate:"gt=0"`
+	// MaxBackoff caps how long an integration waits after consecutive
+	// failed deliveries
+	MaxBackoff time.Duration `validate:"gt=0"`
+	// Lease is how long a worker owns an integration it claimed; another
+	// worker takes it over once the lease expires
+	Lease time.Duration `validate:"gt=0"`
+}
+
+// DefaultForwardingConfig loads the forwarding worker settings from
+// FORWARDING_* environment variables
+func DefaultForwardingConfig() *ForwardingConfig {
+	return &ForwardingConfig{
+		PollInterval:   getDuration("forwarding.poll_interval", 5*time.Second),
+		BatchSize:      getInt("forwarding.batch_size", 500),
+		MaxBatchBytes:  getInt("forwarding.max_batch_bytes", 4*1024*1024),
+		MaxBatches:     getInt("forwarding.max_batches", 10),
+		Concurrency:    getInt("forwarding.concurrency", 4),
+		SettleDelay:    getDuration("forwarding.settle_delay", 5*time.Second),
+		RequestTimeout: getDuration("forwarding.request_timeout", 10*time.Second),
+		MaxRetries:     getInt("forwarding.max_retries", 3),
+		RetryBackoff:   getDuration("forwarding.retry_backoff", time.Second),
+		MaxBackoff:     getDuration("forwarding.max_backoff", 15*time.Minute),
+		Lease:          getDuration("forwarding.lease", 10*time.Minute),
+	}
+}
+
+func (c *ForwardingConfig) Validate() error {
+	errs := fieldErrors(c)
+	if c.Lease <= c.attemptTimeout()*time.Duration(c.MaxBatches) {
+		errs = append(errs, errors.New("FORWARDING_LEASE must exceed the time FORWARDING_MAX_BATCHES deliveries may take with their retries"))
+	}
+	return invalidConfig(errs)
+}
+
+// attemptTimeout is the longest a delivery may take with its retries
+func (c *ForwardingConfig) attemptTimeout() time.Duration {
+	backoff := time.Duration(0)
+	for i := range c.MaxRetries {
+		backoff += c.RetryBackoff << i
+	}
+	return c.RequestTimeout*time.Duration(c.MaxRetries+1) + backoff
+}
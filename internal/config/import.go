@@ -0,0 +1,37 @@
+package config
+
+import "time"
+
+// maxS3PutSize is the largest object S3 takes in a single upload
+const maxS3PutSize = 5 << 30
+
+// ImportConfig controls bulk imports of historical logs
+type ImportConfig struct {
+	// AllowedBuckets lists the buckets imports may read s3:// URIs from;
+	// without any, only files uploaded through the API can be imported
+	AllowedBuckets []string
+	// MaxUploadSize caps the compressed size of a file uploaded through the
+	// API, which is staged in the export bucket in a single upload
+	MaxUploadSize int64 `validate:"min=1,max=5368709120"`
+	// RedactionRuleCacheTTL and TaggingRuleCacheTTL bound how long changed
+	// rules can take to apply to imported logs, as they do on the API
+	RedactionRuleCacheTTL time.Duration `validate:"gt=0"`
+	TaggingRuleCacheTTL   time.Duration `validate:"gt=0"`
+}
+
+// DefaultImportConfig loads the import settings from IMPORT_* environment
+// variables. IMPORT_ALLOWED_BUCKETS is a comma separated list of bucket names.
+// The rule caches share the API's REDACTION_RULE_CACHE_TTL and
+// TAGGING_RULE_CACHE_TTL.
+func DefaultImportConfig() *ImportConfig {
+	return &ImportConfig{
+		AllowedBuckets:        parseList(getString("import.allowed_buckets", "")),
+		MaxUploadSize:         int64(getInt("import.max_upload_size", maxS3PutSize)),
+		RedactionRuleCacheTTL: getDuration("redaction_rule_cache_ttl", time.Minute),
+		TaggingRuleCacheTTL:   getDuration("tagging_rule_cache_ttl", time.Minute),
+	}
+}
+
+func (c *ImportConfig) Validate() error {
+	return validateStruct(c)
+}
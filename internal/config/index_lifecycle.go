@@ -0,0 +1,67 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+// IndexLifecycleConfig controls how the daily per-tenant OpenSearch indices
+// age: they are force merged once warm and deleted after the retention window
+type IndexLifecycleConfig struct {
+	// Interval is how often the lifecycle is applied
+	Interval time.Duration `validate:"gt=0"`
+	// WarmAfter is the age at which an index is force merged and its replicas
+	// reduced to WarmReplicas; zero disables the warm phase
+	WarmAfter    time.Duration `validate:"gte=0"`
+	WarmReplicas int           `validate:"min=0"`
+	// Retention is the age at which an index is deleted; zero keeps indices forever
+	Retention time.Duration `validate:"gte=0"`
+	// RetentionOverrides replaces Retention for individual tenants
+	RetentionOverrides map[string]time.Duration
+	// MaxMigrations bounds the indices migrated to a new mapping version per
+	// run, so a mapping change is rolled out over several runs; zero disables
+	// migrations
+	MaxMigrations int `validate:"min=0"`
+}
+
+// DefaultIndexLifecycleConfig loads the lifecycle settings from
+// OPENSEARCH_LIFECYCLE_* environment variables.
+// OPENSEARCH_LIFECYCLE_RETENTION_OVERRIDES is a comma separated list of
+// tenant_id=duration pairs.
+func DefaultIndexLifecycleConfig() *IndexLifecycleConfig {
+	return &IndexLifecycleConfig{
+		Interval:           getDuration("opensearch.lifecycle.interval", time.Hour),
+		WarmAfter:          getDuration("opensearch.lifecycle.warm_after", 7*24*time.Hour),
+		WarmReplicas:       getInt("opensearch.lifecycle.warm_replicas", 1),
+		Retention:          getDuration("opensearch.lifecycle.retention", 90*24*time.Hour),
+		RetentionOverrides: parseRetentionOverrides(getString("opensearch.lifecycle.retention_overrides", "")),
+		MaxMigrations:      getInt("opensearch.lifecycle.max_migrations", 5),
+	}
+}
+
+func (c *IndexLifecycleConfig) Validate() error {
+	return validateStruct(c)
+}
+
+// RetentionFor returns the retention window of a tenant's indices
+func (c *IndexLifecycleConfig) RetentionFor(tenantID string) time.Duration {
+	if retention, ok := c.RetentionOverrides[tenantID]; ok {
+		return retention
+	}
+	return c.Retention
+}
+
+// parseRetentionOverrides parses "tenant_id=duration,..." pairs, skipping malformed ones
+func parseRetentionOverrides(value string) map[string]time.Duration {
+	overrides := make(map[string]time.Duration)
+	for _, pair := range strings.Split(value, ",") {
+		tenantID, retention, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || tenantID == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(strings.TrimSpace(retention)); err == nil && d >= 0 {
+			overrides[strings.TrimSpace(tenantID)] = d
+		}
+	}
+	return overrides
+}
@@ -0,0 +1,33 @@
+package config
+
+import "time"
+
+// IngestBufferConfig controls the write-behind buffer that collects single
+// log creations and stores them in batches
+type IngestBufferConfig struct {
+	// BatchSize is how many logs of a tenant are stored together; 0 disables buffering
+	BatchSize int `validate:"min=0"`
+	// FlushInterval bounds how long a log waits in the buffer
+	FlushInterval time.Duration `validate:"gt=0"`
+	// DrainTimeout bounds how long shutdown waits for the buffer to be flushed
+	DrainTimeout time.Duration `validate:"gt=0"`
+}
+
+// DefaultIngestBufferConfig loads the write-behind buffer settings from
+// INGEST_BUFFER_* environment variables
+func DefaultIngestBufferConfig() *IngestBufferConfig {
+	return &IngestBufferConfig{
+		BatchSize:     getInt("ingest_buffer.batch_size", 0),
+		FlushInterval: getDuration("ingest_buffer.flush_interval", 100*time.Millisecond),
+		DrainTimeout:  getDuration("ingest_buffer.drain_timeout", 10*time.Second),
+	}
+}
+
+func (c *IngestBufferConfig) Validate() error {
+	return validateStruct(c)
+}
+
+// Enabled reports whether single log creations are buffered
+func (c *IngestBufferConfig) Enabled() bool {
+	return c.BatchSize > 0
+}
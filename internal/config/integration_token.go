@@ -0,0 +1,57 @@
+package config
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/kingrain94/audit-log-api/pkg/envelope"
+)
+
+// IntegrationTokenConfig holds the RSA key pair the tokens of log forwarding
+// integrations are encrypted with at rest. The API only needs the public key,
+// so only the forwarding worker can read tokens back.
+type IntegrationTokenConfig struct {
+	// PublicKeyFile is a PEM-encoded RSA public key of at least 2048 bits;
+	// integrations can't be created without it
+	PublicKeyFile string
+	// PrivateKeyFile is the PEM-encoded RSA private key of PublicKeyFile,
+	// PKCS #8 or PKCS #1, which the forwarding worker requires
+	PrivateKeyFile string
+}
+
+// DefaultIntegrationTokenConfig loads the token encryption settings from
+// INTEGRATION_TOKEN_* environment variables
+func DefaultIntegrationTokenConfig() *IntegrationTokenConfig {
+	return &IntegrationTokenConfig{
+		PublicKeyFile:  getString("integration_token.public_key_file", ""),
+		PrivateKeyFile: getString("integration_token.private_key_file", ""),
+	}
+}
+
+// Enabled reports whether tokens can be encrypted
+func (c *IntegrationTokenConfig) Enabled() bool {
+	return c.PublicKeyFile != ""
+}
+
+// PublicKey reads the key tokens are encrypted with from PublicKeyFile
+func (c *IntegrationTokenConfig) PublicKey() (*rsa.PublicKey, error) {
+	data, err := os.ReadFile(c.PublicKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read integration token public key: %w", err)
+	}
+	return envelope.ParsePublicKey(string(data))
+}
+
+// PrivateKey reads the key tokens are decrypted with from PrivateKeyFile
+func (c *IntegrationTokenConfig) PrivateKey() (*rsa.PrivateKey, error) {
+	if c.PrivateKeyFile == "" {
+		return nil, errors.New("INTEGRATION_TOKEN_PRIVATE_KEY_FILE is required")
+	}
+	data, err := os.ReadFile(c.PrivateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read integration token private key: %w", err)
+	}
+	return envelope.ParsePrivateKey(string(data))
+}
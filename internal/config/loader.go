@@ -0,0 +1,214 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+// maskedValue replaces secrets in the effective configuration
+const maskedValue = "********"
+
+// secretKeys are the settings whose values are never dumped
+var secretKeys = map[string]bool{
+	"password":          true,
+	"secret_key":        true,
+	"access_key_id":     true,
+	"secret_access_key": true,
+	"source_tokens":     true,
+}
+
+var validate = validator.New(validator.WithRequiredStructEnabled())
+
+// Loader resolves settings from, in order of precedence, environment
+// variables, the YAML file named by CONFIG_FILE and the defaults given by the
+// code reading them. Setting keys are dotted paths into the YAML file; the
+// matching environment variable is the upper-cased key with dots replaced by
+// underscores, so postgres.writer.host is read from POSTGRES_WRITER_HOST.
+type Loader struct {
+	mu       sync.Mutex
+	once     sync.Once
+	settings *viper.Viper
+	// errs holds file and parse errors by setting key until validation reports them
+	errs map[string]error
+}
+
+var defaultLoader = &Loader{errs: make(map[string]error)}
+
+// DefaultLoader returns the loader behind every Default*Config function
+func DefaultLoader() *Loader {
+	return defaultLoader
+}
+
+// values returns the layered settings, reading CONFIG_FILE on first use so
+// that variables loaded from .env are taken into account. The caller must
+// hold l.mu.
+func (l *Loader) values() *viper.Viper {
+	l.once.Do(func() {
+		l.settings = viper.New()
+		l.settings.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+		l.settings.AutomaticEnv()
+
+		if path := os.Getenv("CONFIG_FILE"); path != "" {
+			l.settings.SetConfigFile(path)
+			if err := l.settings.ReadInConfig(); err != nil {
+				l.errs["CONFIG_FILE"] = fmt.Errorf("failed to read config file %s: %w", path, err)
+			}
+		}
+	})
+	return l.settings
+}
+
+// Effective returns every setting read by this process with its effective
+// value. Secrets are masked so the result can be shown to operators.
+func (l *Loader) Effective() map[string]any {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return maskSecrets(l.values().AllSettings())
+}
+
+// err joins the file and parse errors met so far
+func (l *Loader) err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	keys := make([]string, 0, len(l.errs))
+	for key := range l.errs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	errs := make([]error, 0, len(keys))
+	for _, key := range keys {
+		errs = append(errs, l.errs[key])
+	}
+	return errors.Join(errs...)
+}
+
+// lookup resolves key, registering defaultValue so that the setting shows up
+// in Effective. Empty values fall back to the default; values that cannot be
+// parsed do too, and are reported by the next validation.
+func lookup[T any](key string, defaultValue T, parse func(any) (T, error)) T {
+	l := defaultLoader
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	settings := l.values()
+	settings.SetDefault(key, defaultValue)
+	raw := settings.Get(key)
+	if s, ok := raw.(string); ok && s == "" {
+		return defaultValue
+	}
+
+	value, err := parse(raw)
+	if err != nil {
+		l.errs[key] = fmt.Errorf("%s: %w", envName(key), err)
+		return defaultValue
+	}
+	return value
+}
+
+func getString(key, defaultValue string) string {
+	return lookup(key, defaultValue, cast.ToStringE)
+}
+
+// getStringAllowEmpty is like getString but keeps a setting explicitly set to empty
+func getStringAllowEmpty(key, defaultValue string) string {
+	value := getString(key, defaultValue)
+	if env, ok := os.LookupEnv(envName(key)); ok {
+		return env
+	}
+	return value
+}
+
+func getInt(key string, defaultValue int) int {
+	return lookup(key, defaultValue, cast.ToIntE)
+}
+
+func getFloat(key string, defaultValue float64) float64 {
+	return lookup(key, defaultValue, cast.ToFloat64E)
+}
+
+// getDuration parses Go duration strings such as "30s" or "1h30m"
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	return lookup(key, defaultValue, cast.ToDurationE)
+}
+
+// envName returns the environment variable a setting key is read from
+func envName(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// validateStruct checks the validate tags of config structs
+func validateStruct(structs ...any) error {
+	return invalidConfig(fieldErrors(structs...))
+}
+
+// invalidConfig joins errs with the settings that failed to load
+func invalidConfig(errs []error) error {
+	errs = append(errs, defaultLoader.err())
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	return nil
+}
+
+func fieldErrors(structs ...any) []error {
+	var errs []error
+	for _, s := range structs {
+		var fieldErrs validator.ValidationErrors
+		if err := validate.Struct(s); errors.As(err, &fieldErrs) {
+			for _, fieldErr := range fieldErrs {
+				errs = append(errs, fieldError(fieldErr))
+			}
+		} else if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func fieldError(err validator.FieldError) error {
+	switch err.Tag() {
+	case "required", "required_unless":
+		return fmt.Errorf("%s is required", err.Namespace())
+	case "oneof":
+		return fmt.Errorf("%s must be one of %s, got %v", err.Namespace(), err.Param(), err.Value())
+	case "min", "gte":
+		return fmt.Errorf("%s must be at least %s, got %v", err.Namespace(), err.Param(), err.Value())
+	case "max", "lte", "ltefield":
+		return fmt.Errorf("%s must be at most %s, got %v", err.Namespace(), err.Param(), err.Value())
+	case "gt", "gtfield":
+		return fmt.Errorf("%s must be greater than %s, got %v", err.Namespace(), err.Param(), err.Value())
+	}
+	return fmt.Errorf("%s is not a valid %s: %v", err.Namespace(), err.Tag(), err.Value())
+}
+
+// maskSecrets copies settings, masking the non-empty values of secret keys
+// and printing durations in their string form
+func maskSecrets(settings map[string]any) map[string]any {
+	masked := make(map[string]any, len(settings))
+	for key, value := range settings {
+		switch v := value.(type) {
+		case map[string]any:
+			masked[key] = maskSecrets(v)
+		case time.Duration:
+			masked[key] = v.String()
+		default:
+			if secretKeys[key] && cast.ToString(value) != "" {
+				masked[key] = maskedValue
+			} else {
+				masked[key] = value
+			}
+		}
+	}
+	return masked
+}
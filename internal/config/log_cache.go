@@ -0,0 +1,26 @@
+package config
+
+import "time"
+
+// LogCacheConfig controls the Redis cache of the logs and stats read by
+// polling dashboards
+type LogCacheConfig struct {
+	// TTL bounds how long a cached log or stats can be served; zero disables the cache
+	TTL time.Duration `validate:"gte=0"`
+}
+
+// DefaultLogCacheConfig loads the cache settings from LOG_CACHE_* environment variables
+func DefaultLogCacheConfig() *LogCacheConfig {
+	return &LogCacheConfig{
+		TTL: getDuration("log_cache.ttl", 10*time.Second),
+	}
+}
+
+func (c *LogCacheConfig) Validate() error {
+	return validateStruct(c)
+}
+
+// Enabled reports whether logs and stats are cached
+func (c *LogCacheConfig) Enabled() bool {
+	return c.TTL > 0
+}
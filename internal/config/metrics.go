@@ -0,0 +1,13 @@
+package config
+
+type MetricsConfig struct {
+	Addr string
+}
+
+// DefaultMetricsConfig returns the metrics listen address from METRICS_ADDR,
+// falling back to a per-process default so workers on one host don't collide
+func DefaultMetricsConfig(defaultAddr string) *MetricsConfig {
+	return &MetricsConfig{
+		Addr: getString("metrics.addr", defaultAddr),
+	}
+}
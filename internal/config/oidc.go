@@ -0,0 +1,76 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+// Authentication modes accepted by AUTH_MODE
+const (
+	// AuthModeHMAC accepts only HS256 tokens signed with JWT_SECRET_KEY
+	AuthModeHMAC = "hmac"
+	// AuthModeOIDC accepts only RS256 tokens issued by the configured identity provider
+	AuthModeOIDC = "oidc"
+	// AuthModeHybrid accepts both, easing migration to an identity provider
+	AuthModeHybrid = "hybrid"
+)
+
+type OIDCConfig struct {
+	Mode string `validate:"oneof=hmac oidc hybrid"`
+
+	// Issuer must match the iss claim; its discovery document locates the JWKS when JWKSURL is unset
+	Issuer   string `validate:"required_unless=Mode hmac,omitempty,url"`
+	JWKSURL  string `validate:"omitempty,url"`
+	Audience string
+
+	// TenantClaim and RolesClaim name the claims carrying the tenant and roles.
+	// Dotted paths reach into nested claims, e.g. realm_access.roles for Keycloak.
+	TenantClaim string
+	RolesClaim  string
+
+	// RoleMapping translates identity provider roles or groups to API roles.
+	// When set, roles without a mapping are dropped.
+	RoleMapping map[string]string
+
+	// JWKSRefreshInterval bounds how long signing keys are cached
+	JWKSRefreshInterval time.Duration `validate:"gt=0"`
+	HTTPTimeout         time.Duration `validate:"gt=0"`
+}
+
+// DefaultOIDCConfig returns OIDC configuration from environment variables
+func DefaultOIDCConfig() *OIDCConfig {
+	return &OIDCConfig{
+		Mode:                getString("auth.mode", AuthModeHMAC),
+		Issuer:              strings.TrimSuffix(getString("oidc.issuer", ""), "/"),
+		JWKSURL:             getString("oidc.jwks_url", ""),
+		Audience:            getString("oidc.audience", ""),
+		TenantClaim:         getString("oidc.tenant_claim", "tenant_id"),
+		RolesClaim:          getString("oidc.roles_claim", "roles"),
+		RoleMapping:         parseRoleMapping(getString("oidc.role_mapping", "")),
+		JWKSRefreshInterval: getDuration("oidc.jwks_refresh_interval", time.Hour),
+		HTTPTimeout:         getDuration("oidc.http_timeout", 5*time.Second),
+	}
+}
+
+// AcceptsHMAC reports whether tokens signed with the shared secret are accepted
+func (c *OIDCConfig) AcceptsHMAC() bool {
+	return c.Mode != AuthModeOIDC
+}
+
+// AcceptsOIDC reports whether identity provider tokens are accepted
+func (c *OIDCConfig) AcceptsOIDC() bool {
+	return c.Mode == AuthModeOIDC || c.Mode == AuthModeHybrid
+}
+
+// parseRoleMapping parses "idp-role=api-role,..." pairs
+func parseRoleMapping(value string) map[string]string {
+	mapping := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		from, to, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || from == "" || to == "" {
+			continue
+		}
+		mapping[strings.TrimSpace(from)] = strings.TrimSpace(to)
+	}
+	return mapping
+}
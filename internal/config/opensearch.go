@@ -4,7 +4,7 @@ import (
 	"crypto/tls"
 	"fmt"
 	"net/http"
-	"os"
+	"strings"
 	"time"
 
 	"github.com/opensearch-project/opensearch-go/v2"
@@ -15,27 +15,106 @@ type OpenSearchConfig struct {
 	Port     string
 	Username string
 	Password string
+	// BulkMaxRetries is how many times bulk items rejected for load, with a
+	// 429 or 5xx status, are retried before they count as failed
+	BulkMaxRetries int
+	// BulkRetryBackoff is the wait before the first retry, doubled for each
+	// retry after it
+	BulkRetryBackoff time.Duration
+	// Breaker guards the API's calls to OpenSearch
+	Breaker *BreakerConfig
+	// MetadataMapping is how the metadata, before_state and after_state of
+	// logs are mapped in indices created from now on
+	MetadataMapping MetadataMapping `validate:"oneof=dynamic flat_object indexed_keys"`
+	// TenantSettingsCacheTTL is how long the settings routing a tenant's logs,
+	// its data residency region and indexed metadata keys, are cached
+	TenantSettingsCacheTTL time.Duration `validate:"gt=0"`
+	// Clusters are the addresses of the clusters serving the tenants of data
+	// residency regions, by region. Tenants of any other region, or none, are
+	// served by the cluster at Host and Port.
+	Clusters map[string]string `validate:"dive,keys,ne=default,endkeys,url"`
+	// HealthCheckInterval is how often the API checks the health of each cluster
+	HealthCheckInterval time.Duration `validate:"gt=0"`
 }
 
+// DefaultOpenSearchCluster names the cluster at Host and Port
+const DefaultOpenSearchCluster = "default"
+
+// MetadataMapping is a strategy mapping the free-form JSON of logs
+type MetadataMapping string
+
+const (
+	// MetadataMappingDynamic maps every key as a field of its own. Tenants
+	// sharing a cluster can run into the total fields limit or into a key
+	// mapped with conflicting types.
+	MetadataMappingDynamic MetadataMapping = "dynamic"
+	// MetadataMappingFlatObject maps each payload as a single flat_object
+	// field, searchable by key and value but typed as keywords
+	MetadataMappingFlatObject MetadataMapping = "flat_object"
+	// MetadataMappingIndexedKeys leaves the payloads unindexed in _source and
+	// indexes, as keywords, only the top-level metadata keys each tenant lists
+	// in its indexed_metadata_keys setting
+	MetadataMappingIndexedKeys MetadataMapping = "indexed_keys"
+)
+
 func DefaultOpenSearchConfig() *OpenSearchConfig {
 	return &OpenSearchConfig{
-		Host:     getEnvOrDefault("OPENSEARCH_HOST", "localhost"),
-		Port:     getEnvOrDefault("OPENSEARCH_PORT", "9200"),
-		Username: getEnvOrDefault("OPENSEARCH_USERNAME", ""),
-		Password: getEnvOrDefault("OPENSEARCH_PASSWORD", ""),
+		Host:     getString("opensearch.host", "localhost"),
+		Port:     getString("opensearch.port", "9200"),
+		Username: getString("opensearch.username", ""),
+		Password: getString("opensearch.password", ""),
+
+		BulkMaxRetries:   getInt("opensearch.bulk_max_retries", 3),
+		BulkRetryBackoff: getDuration("opensearch.bulk_retry_backoff", 500*time.Millisecond),
+
+		Breaker: DefaultBreakerConfig("opensearch"),
+
+		MetadataMapping:        MetadataMapping(getString("opensearch.metadata_mapping", string(MetadataMappingDynamic))),
+		TenantSettingsCacheTTL: getDuration("opensearch.tenant_settings_cache_ttl", time.Minute),
+		Clusters:               parseClusters(getString("opensearch.clusters", "")),
+		HealthCheckInterval:    getDuration("opensearch.health_check_interval", 30*time.Second),
 	}
 }
 
+func (c *OpenSearchConfig) Validate() error {
+	return validateStruct(c)
+}
+
+// GetClient returns a client of the default cluster
 func (c *OpenSearchConfig) GetClient() (*opensearch.Client, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c.newClient(fmt.Sprintf("http://%s:%s", c.Host, c.Port))
+}
+
+// GetClusterClients returns a client of every cluster by region, the default
+// cluster under DefaultOpenSearchCluster. Every cluster shares the credentials.
+func (c *OpenSearchConfig) GetClusterClients() (map[string]*opensearch.Client, error) {
+	defaultClient, err := c.GetClient()
+	if err != nil {
+		return nil, err
+	}
+
+	clients := map[string]*opensearch.Client{DefaultOpenSearchCluster: defaultClient}
+	for region, address := range c.Clusters {
+		client, err := c.newClient(address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create client of OpenSearch cluster %s: %w", region, err)
+		}
+		clients[region] = client
+	}
+	return clients, nil
+}
+
+func (c *OpenSearchConfig) newClient(address string) (*opensearch.Client, error) {
 	config := opensearch.Config{
 		Transport: &http.Transport{
 			TLSClientConfig: &tls.Config{
 				InsecureSkipVerify: true,
 			},
 		},
-		Addresses: []string{
-			fmt.Sprintf("http://%s:%s", c.Host, c.Port),
-		},
+		Addresses: []string{address},
 	}
 
 	if c.Username != "" && c.Password != "" {
@@ -46,21 +125,61 @@ func (c *OpenSearchConfig) GetClient() (*opensearch.Client, error) {
 	return opensearch.NewClient(config)
 }
 
+// parseClusters parses "region=address,..." pairs, skipping malformed ones
+func parseClusters(value string) map[string]string {
+	clusters := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		region, address, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		region, address = strings.TrimSpace(region), strings.TrimSpace(address)
+		if !ok || region == "" || address == "" {
+			continue
+		}
+		clusters[region] = address
+	}
+	return clusters
+}
+
+const (
+	indexPrefix     = "audit_logs_"
+	indexDateLayout = "2006_01_02"
+)
+
 // GetIndexName returns the index name for a given tenant and time
 // Format: audit_logs_<tenant_id>_YYYY_MM_DD
 func (c *OpenSearchConfig) GetIndexName(tenantID string, t time.Time) string {
-	return fmt.Sprintf("audit_logs_%s_%s", tenantID, t.Format("2006_01_02"))
+	return fmt.Sprintf("%s%s_%s", indexPrefix, tenantID, t.Format(indexDateLayout))
+}
+
+// ParseIndexName splits an index name built by GetIndexName into its tenant
+// and day. ok is false for any other index.
+func (c *OpenSearchConfig) ParseIndexName(name string) (tenantID string, day time.Time, ok bool) {
+	rest, found := strings.CutPrefix(name, indexPrefix)
+	if !found || len(rest) < len(indexDateLayout)+2 {
+		return "", time.Time{}, false
+	}
+
+	split := len(rest) - len(indexDateLayout)
+	day, err := time.Parse(indexDateLayout, rest[split:])
+	if err != nil || rest[split-1] != '_' {
+		return "", time.Time{}, false
+	}
+	return rest[:split-1], day, true
+}
+
+// GetWriteAlias returns the alias pointing at a tenant's current daily index
+// Format: audit_logs_write_<tenant_id>
+func (c *OpenSearchConfig) GetWriteAlias(tenantID string) string {
+	return fmt.Sprintf("audit_logs_write_%s", tenantID)
 }
 
 // GetIndexPattern returns a pattern matching all indices for a tenant
 // Format: audit_logs_<tenant_id>_*
 func (c *OpenSearchConfig) GetIndexPattern(tenantID string) string {
-	return fmt.Sprintf("audit_logs_%s_*", tenantID)
+	return fmt.Sprintf("%s%s_*", indexPrefix, tenantID)
 }
 
-func getEnvOrDefault(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
+// GetAllIndicesPattern returns a pattern matching the indices of every tenant
+// Format: audit_logs_*
+func (c *OpenSearchConfig) GetAllIndicesPattern() string {
+	return indexPrefix + "*"
 }
@@ -0,0 +1,34 @@
+package config
+
+import "time"
+
+// PartitionConfig controls the monthly partitions of the audit_logs table:
+// partitions are created ahead of the months they hold and dropped once the
+// whole month is past retention
+type PartitionConfig struct {
+	// Interval is how often partitions are maintained
+	Interval time.Duration `validate:"gt=0"`
+	// MonthsAhead is how many months after the current one get a partition
+	MonthsAhead int `validate:"min=1"`
+	// TenantHashPartitions sub-partitions new months by tenant hash into that
+	// many partitions; zero keeps one partition per month
+	TenantHashPartitions int `validate:"min=0"`
+	// Retention is the age past which a month is dropped for every tenant,
+	// measured from the end of the month; zero keeps partitions forever
+	Retention time.Duration `validate:"gte=0"`
+}
+
+// DefaultPartitionConfig loads the partition settings from
+// POSTGRES_PARTITIONS_* environment variables
+func DefaultPartitionConfig() *PartitionConfig {
+	return &PartitionConfig{
+		Interval:             getDuration("postgres.partitions.interval", time.Hour),
+		MonthsAhead:          getInt("postgres.partitions.months_ahead", 3),
+		TenantHashPartitions: getInt("postgres.partitions.tenant_hash_partitions", 0),
+		Retention:            getDuration("postgres.partitions.retention", 0),
+	}
+}
+
+func (c *PartitionConfig) Validate() error {
+	return validateStruct(c)
+}
@@ -0,0 +1,36 @@
+package config
+
+import "os"
+
+const (
+	PubSubBackendPubSub  = "pubsub"
+	PubSubBackendStreams = "streams"
+)
+
+// PubSubConfig selects how stored logs are fanned out to the WebSocket and
+// SSE clients of the API instances. Publishers and the API must agree on it.
+type PubSubConfig struct {
+	// Backend is PubSubBackendPubSub (default), which drops logs published
+	// while an instance is reconnecting, or PubSubBackendStreams, which keeps
+	// them in a Redis Stream per tenant until each instance acknowledges them
+	Backend string `validate:"oneof=pubsub streams"`
+	// StreamMaxLen approximately caps the logs kept per tenant stream
+	StreamMaxLen int64 `validate:"min=1"`
+	// ConsumerGroup holds the offsets of one API instance in each tenant
+	// stream, so it must differ between instances; a name stable across
+	// restarts, such as the pod name, lets a restarted instance reuse its groups
+	ConsumerGroup string `validate:"required"`
+}
+
+func DefaultPubSubConfig() *PubSubConfig {
+	hostname, _ := os.Hostname()
+	return &PubSubConfig{
+		Backend:       getString("pubsub.backend", PubSubBackendPubSub),
+		StreamMaxLen:  int64(getInt("pubsub.stream_max_len", 10000)),
+		ConsumerGroup: getString("pubsub.consumer_group", "api-"+hostname),
+	}
+}
+
+func (c *PubSubConfig) Validate() error {
+	return validateStruct(c)
+}
@@ -0,0 +1,30 @@
+package config
+
+import "time"
+
+// QueryTimeoutConfig bounds the time the API spends on a request, and so the
+// database queries it runs, per class of endpoint. Postgres cancels a query
+// still running at the deadline through statement_timeout. A timeout of 0
+// leaves the class unbounded.
+type QueryTimeoutConfig struct {
+	// Ingest bounds the requests writing logs
+	Ingest time.Duration `validate:"min=0"`
+	// Query bounds the requests reading logs, stats included
+	Query time.Duration `validate:"min=0"`
+	// Export bounds the requests streaming exports and reports
+	Export time.Duration `validate:"min=0"`
+}
+
+// DefaultQueryTimeoutConfig loads the timeouts from QUERY_TIMEOUT_*
+// environment variables
+func DefaultQueryTimeoutConfig() *QueryTimeoutConfig {
+	return &QueryTimeoutConfig{
+		Ingest: getDuration("query_timeout.ingest", 10*time.Second),
+		Query:  getDuration("query_timeout.query", 30*time.Second),
+		Export: getDuration("query_timeout.export", 10*time.Minute),
+	}
+}
+
+func (c *QueryTimeoutConfig) Validate() error {
+	return validateStruct(c)
+}
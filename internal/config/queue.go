@@ -0,0 +1,62 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	QueueBackendSQS   = "sqs"
+	QueueBackendKafka = "kafka"
+)
+
+// QueueConfig selects the message queue behind the index, archive, cleanup,
+// export and ingest pipeline
+type QueueConfig struct {
+	// Backend is QueueBackendSQS (default) or QueueBackendKafka
+	Backend string       `validate:"oneof=sqs kafka"`
+	SQS     *SQSConfig   `validate:"-"`
+	Kafka   *KafkaConfig `validate:"-"`
+}
+
+func DefaultQueueConfig() *QueueConfig {
+	return &QueueConfig{
+		Backend: getString("queue.backend", QueueBackendSQS),
+		SQS:     DefaultSQSConfig(),
+		Kafka:   DefaultKafkaConfig(),
+	}
+}
+
+// Validate checks the backend and the settings of the selected backend only
+func (c *QueueConfig) Validate() error {
+	if c.Backend == QueueBackendKafka {
+		return validateStruct(c, c.Kafka)
+	}
+	return validateStruct(c, c.SQS)
+}
+
+type KafkaConfig struct {
+	Brokers []string `validate:"min=1,dive,hostname_port"`
+	// ConsumerGroup is shared by every worker; each topic is consumed by one worker type
+	ConsumerGroup string `validate:"required"`
+	IndexTopic    string `validate:"required"`
+	ArchiveTopic  string `validate:"required"`
+	CleanupTopic  string `validate:"required"`
+	ExportTopic   string `validate:"required"`
+	IngestTopic   string `validate:"required"`
+	// VisibilityTimeout mirrors SQS: messages not acknowledged within it are delivered again
+	VisibilityTimeout time.Duration `validate:"gt=0"`
+}
+
+func DefaultKafkaConfig() *KafkaConfig {
+	return &KafkaConfig{
+		Brokers:           strings.Split(getString("kafka.brokers", "localhost:9092"), ","),
+		ConsumerGroup:     getString("kafka.consumer_group", "audit-log-workers"),
+		IndexTopic:        getString("kafka.index_topic", "audit-log-index"),
+		ArchiveTopic:      getString("kafka.archive_topic", "audit-log-archive"),
+		CleanupTopic:      getString("kafka.cleanup_topic", "audit-log-cleanup"),
+		ExportTopic:       getString("kafka.export_topic", "audit-log-export"),
+		IngestTopic:       getString("kafka.ingest_topic", "audit-log-ingest"),
+		VisibilityTimeout: getDuration("kafka.visibility_timeout", 5*time.Minute),
+	}
+}
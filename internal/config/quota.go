@@ -0,0 +1,38 @@
+package config
+
+import "time"
+
+type QuotaConfig struct {
+	// DailyLogs caps the logs a tenant may ingest per UTC day
+	DailyLogs int64 `validate:"min=0"`
+	// MonthlyLogs caps the logs a tenant may ingest per UTC calendar month
+	MonthlyLogs int64 `validate:"min=0"`
+	// MonthlyBytes caps the bytes of logs a tenant may ingest per UTC calendar month
+	MonthlyBytes int64 `validate:"min=0"`
+	// WarningRatio is the share of a quota at which the tenant is warned, once
+	// per quota period, with a WARNING audit log and a webhook. 0 disables warnings.
+	WarningRatio float64 `validate:"min=0,max=1"`
+	// WebhookTimeout bounds the delivery of a warning to the tenant's webhook
+	WebhookTimeout time.Duration `validate:"min=0"`
+}
+
+// DefaultQuotaConfig loads the per-tenant ingestion quotas from QUOTA_*
+// environment variables. A quota of 0 is unlimited.
+func DefaultQuotaConfig() *QuotaConfig {
+	return &QuotaConfig{
+		DailyLogs:      int64(getInt("quota.daily_logs", 0)),
+		MonthlyLogs:    int64(getInt("quota.monthly_logs", 0)),
+		MonthlyBytes:   int64(getInt("quota.monthly_bytes", 0)),
+		WarningRatio:   getFloat("quota.warning_ratio", 0.8),
+		WebhookTimeout: getDuration("quota.webhook_timeout", 5*time.Second),
+	}
+}
+
+func (c *QuotaConfig) Validate() error {
+	return validateStruct(c)
+}
+
+// Enabled reports whether any quota is set
+func (c *QuotaConfig) Enabled() bool {
+	return c.DailyLogs > 0 || c.MonthlyLogs > 0 || c.MonthlyBytes > 0
+}
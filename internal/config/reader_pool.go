@@ -0,0 +1,192 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+)
+
+// Reader selection strategies
+const (
+	// ReaderSelectionRoundRobin spreads reads evenly over the healthy readers
+	ReaderSelectionRoundRobin = "round_robin"
+	// ReaderSelectionLeastLatency sends reads to the healthy reader that
+	// answered its last health check fastest
+	ReaderSelectionLeastLatency = "least_latency"
+)
+
+// ReaderPoolConfig configures the read replicas reads are spread over
+type ReaderPoolConfig struct {
+	// DSNs are the connection strings of the readers. Without any, the single
+	// reader of the postgres.reader settings is used.
+	DSNs                []string
+	Selection           string        `validate:"oneof=round_robin least_latency"`
+	HealthCheckInterval time.Duration `validate:"gt=0"`
+}
+
+func DefaultReaderPoolConfig() *ReaderPoolConfig {
+	return &ReaderPoolConfig{
+		DSNs:                parseList(getString("postgres.reader.dsns", "")),
+		Selection:           getString("postgres.reader.selection", ReaderSelectionRoundRobin),
+		HealthCheckInterval: getDuration("postgres.reader.health_check_interval", 5*time.Second),
+	}
+}
+
+// replica is a reader and the outcome of its last health check
+type replica struct {
+	name    string
+	db      *sql.DB
+	healthy atomic.Bool
+	latency atomic.Int64
+}
+
+// ReaderPool is the connection pool of the reader gorm.DB. Each query goes to
+// a healthy reader, picked per the selection strategy; while every reader is
+// down, reads go to the writer. Readers are health checked in the background
+// until Close.
+type ReaderPool struct {
+	readers   []*replica
+	writer    *sql.DB
+	selection string
+	next      atomic.Uint64
+
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+// NewReaderPool checks the health of every reader once, then every interval
+func NewReaderPool(readers []*sql.DB, writer *sql.DB, config *ReaderPoolConfig) *ReaderPool {
+	p := &ReaderPool{
+		writer:    writer,
+		selection: config.Selection,
+		interval:  config.HealthCheckInterval,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	for i, db := range readers {
+		p.readers = append(p.readers, &replica{name: "reader_" + strconv.Itoa(i), db: db})
+	}
+
+	p.check()
+	go p.run()
+	return p
+}
+
+func (p *ReaderPool) run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.check()
+		}
+	}
+}
+
+// check pings every reader, waiting no longer than the check interval
+func (p *ReaderPool) check() {
+	for _, r := range p.readers {
+		ctx, cancel := context.WithTimeout(context.Background(), p.interval)
+		start := time.Now()
+		err := r.db.PingContext(ctx)
+		cancel()
+
+		r.latency.Store(int64(time.Since(start)))
+		r.healthy.Store(err == nil)
+
+		up := 0.0
+		if err == nil {
+			up = 1
+		}
+		metrics.DBReaderUp.WithLabelValues(r.name).Set(up)
+	}
+}
+
+// pick returns the database the next read goes to
+func (p *ReaderPool) pick() *sql.DB {
+	var healthy []*replica
+	for _, r := range p.readers {
+		if r.healthy.Load() {
+			healthy = append(healthy, r)
+		}
+	}
+	if len(healthy) == 0 {
+		metrics.DBReaderFallbacksTotal.Inc()
+		return p.writer
+	}
+
+	if p.selection == ReaderSelectionLeastLatency {
+		fastest := healthy[0]
+		for _, r := range healthy[1:] {
+			if r.latency.Load() < fastest.latency.Load() {
+				fastest = r
+			}
+		}
+		return fastest.db
+	}
+	return healthy[p.next.Add(1)%uint64(len(healthy))].db
+}
+
+func (p *ReaderPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return p.pick().PrepareContext(ctx, query)
+}
+
+func (p *ReaderPool) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return p.pick().ExecContext(ctx, query, args...)
+}
+
+func (p *ReaderPool) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return p.pick().QueryContext(ctx, query, args...)
+}
+
+func (p *ReaderPool) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return p.pick().QueryRowContext(ctx, query, args...)
+}
+
+// BeginTx runs a read transaction on one database, picked as a query is
+func (p *ReaderPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return p.pick().BeginTx(ctx, opts)
+}
+
+// Ping succeeds while a reader, or else the writer, answers
+func (p *ReaderPool) Ping() error {
+	return p.pick().Ping()
+}
+
+// Readers returns the database of every reader, by name
+func (p *ReaderPool) Readers() map[string]*sql.DB {
+	readers := make(map[string]*sql.DB, len(p.readers))
+	for _, r := range p.readers {
+		readers[r.name] = r.db
+	}
+	return readers
+}
+
+// Close stops the health checks and closes the readers, not the writer
+func (p *ReaderPool) Close() error {
+	var errs []error
+	p.once.Do(func() {
+		close(p.stop)
+		<-p.done
+		for _, r := range p.readers {
+			if err := r.db.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to close %s: %w", r.name, err))
+			}
+		}
+	})
+	return errors.Join(errs...)
+}
@@ -2,9 +2,13 @@ package config
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/redis/go-redis/v9"
+
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/pkg/breaker"
 )
 
 type RedisConfig struct {
@@ -12,14 +16,17 @@ type RedisConfig struct {
 	Port     string
 	Password string
 	DB       int
+	// Breaker guards the commands sent to Redis
+	Breaker *BreakerConfig
 }
 
 func DefaultRedisConfig() *RedisConfig {
 	return &RedisConfig{
-		Host:     getEnvOrDefault("REDIS_HOST", "localhost"),
-		Port:     getEnvOrDefault("REDIS_PORT", "6379"),
-		Password: getEnvOrDefault("REDIS_PASSWORD", ""),
+		Host:     getString("redis.host", "localhost"),
+		Port:     getString("redis.port", "6379"),
+		Password: getString("redis.password", ""),
 		DB:       0,
+		Breaker:  DefaultBreakerConfig("redis"),
 	}
 }
 
@@ -35,5 +42,41 @@ func (c *RedisConfig) GetClient() (*redis.Client, error) {
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
+	if c.Breaker.Enabled() {
+		client.AddHook(breakerHook{breaker: c.Breaker.NewBreaker("redis", isRedisFailure, metrics.ObserveBreakerStateChange)})
+	}
+
 	return client, nil
 }
+
+// breakerHook sends the commands of a Redis client through a circuit breaker
+type breakerHook struct {
+	breaker *breaker.Breaker
+}
+
+func (h breakerHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h breakerHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		return h.breaker.Execute(func() error {
+			return next(ctx, cmd)
+		})
+	}
+}
+
+func (h breakerHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		return h.breaker.Execute(func() error {
+			return next(ctx, cmds)
+		})
+	}
+}
+
+// isRedisFailure tells failures to reach Redis from errors Redis replied
+// with, such as redis.Nil for a missing key, which show it's up
+func isRedisFailure(err error) bool {
+	var reply redis.Error
+	return !errors.As(err, &reply) && !errors.Is(err, context.Canceled)
+}
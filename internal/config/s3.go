@@ -2,6 +2,8 @@ package config
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
@@ -11,21 +13,57 @@ import (
 
 type S3Config struct {
 	BucketName      string
+	ExportBucket    string
+	ExportURLExpiry time.Duration
 	Region          string
 	Endpoint        string
 	AccessKeyID     string
 	SecretAccessKey string
+	// ArchiveStorageClass is the storage class archive parts are written in
+	ArchiveStorageClass string `validate:"oneof=STANDARD STANDARD_IA ONEZONE_IA INTELLIGENT_TIERING GLACIER_IR GLACIER DEEP_ARCHIVE"`
+	// ArchiveGlacierAfterDays moves archive parts to ArchiveGlacierClass this
+	// many days after they're written, through a bucket lifecycle rule per
+	// tenant; 0 disables the transition
+	ArchiveGlacierAfterDays int    `validate:"min=0"`
+	ArchiveGlacierClass     string `validate:"oneof=GLACIER_IR GLACIER DEEP_ARCHIVE"`
+	// ArchiveObjectLockMode locks archive objects against deletion and
+	// overwrite for ArchiveObjectLockDays with S3 Object Lock, GOVERNANCE or
+	// COMPLIANCE; empty disables it. The bucket must have Object Lock enabled.
+	ArchiveObjectLockMode string `validate:"omitempty,oneof=GOVERNANCE COMPLIANCE"`
+	ArchiveObjectLockDays int    `validate:"min=0"`
+	// KMSKeyID is the KMS key archives and exports are encrypted with (SSE-KMS)
+	// for tenants without their own; empty leaves the bucket's default
+	// encryption
+	KMSKeyID string
 }
 
 // DefaultS3Config returns default S3 configuration from environment variables
 func DefaultS3Config() *S3Config {
 	return &S3Config{
-		BucketName:      getEnvWithDefault("S3_ARCHIVE_BUCKET", "audit-log-archives"),
-		Region:          getEnvWithDefault("AWS_REGION", "us-east-1"),
-		Endpoint:        getEnvWithDefault("AWS_ENDPOINT_URL", ""),
-		AccessKeyID:     getEnvWithDefault("AWS_ACCESS_KEY_ID", "dummy"),
-		SecretAccessKey: getEnvWithDefault("AWS_SECRET_ACCESS_KEY", "dummy"),
+		BucketName:      getString("s3.archive_bucket", "audit-log-archives"),
+		ExportBucket:    getString("s3.export_bucket", "audit-log-exports"),
+		ExportURLExpiry: getDuration("s3.export_url_expiry", 15*time.Minute),
+		Region:          getString("aws.region", "us-east-1"),
+		Endpoint:        getString("aws.endpoint_url", ""),
+		AccessKeyID:     getString("aws.access_key_id", "dummy"),
+		SecretAccessKey: getString("aws.secret_access_key", "dummy"),
+
+		ArchiveStorageClass:     getString("s3.archive_storage_class", "STANDARD"),
+		ArchiveGlacierAfterDays: getInt("s3.archive_glacier_after_days", 0),
+		ArchiveGlacierClass:     getString("s3.archive_glacier_class", "GLACIER"),
+		ArchiveObjectLockMode:   getString("s3.archive_object_lock_mode", ""),
+		ArchiveObjectLockDays:   getInt("s3.archive_object_lock_days", 0),
+		KMSKeyID:                getString("s3.kms_key_id", ""),
+	}
+}
+
+// Validate checks the archive storage settings
+func (c *S3Config) Validate() error {
+	errs := fieldErrors(c)
+	if c.ArchiveObjectLockMode != "" && c.ArchiveObjectLockDays == 0 {
+		errs = append(errs, errors.New("S3_ARCHIVE_OBJECT_LOCK_DAYS must be set with S3_ARCHIVE_OBJECT_LOCK_MODE"))
 	}
+	return invalidConfig(errs)
 }
 
 // GetClient creates and returns an S3 client
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+// ShipperConfig controls the ingestion of log shippers such as Fluent Bit
+// and Logstash
+type ShipperConfig struct {
+	// TemplatesFile is a YAML or JSON file of field mapping templates by
+	// name, each mapping audit log fields to the record fields they are read
+	// from; they add to or replace the built-in fluentbit and logstash ones
+	TemplatesFile string
+}
+
+// DefaultShipperConfig loads the shipper settings from SHIPPER_*
+// environment variables
+func DefaultShipperConfig() *ShipperConfig {
+	return &ShipperConfig{
+		TemplatesFile: getString("shipper.templates_file", ""),
+	}
+}
+
+// Templates reads the templates of TemplatesFile; there are none without it
+func (c *ShipperConfig) Templates() (map[string]map[string][]string, error) {
+	if c.TemplatesFile == "" {
+		return nil, nil
+	}
+
+	file := viper.New()
+	file.SetConfigFile(c.TemplatesFile)
+	if err := file.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read shipper templates %s: %w", c.TemplatesFile, err)
+	}
+	var templates map[string]map[string][]string
+	if err := file.Unmarshal(&templates); err != nil {
+		return nil, fmt.Errorf("failed to parse shipper templates %s: %w", c.TemplatesFile, err)
+	}
+	return templates, nil
+}
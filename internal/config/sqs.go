@@ -11,24 +11,31 @@ import (
 )
 
 type SQSConfig struct {
-	Region          string `mapstructure:"region"`
-	Endpoint        string `mapstructure:"endpoint"`
+	Region          string `mapstructure:"region" validate:"required"`
+	Endpoint        string `mapstructure:"endpoint" validate:"omitempty,url"`
 	AccessKeyID     string `mapstructure:"access_key_id"`
 	SecretAccessKey string `mapstructure:"secret_access_key"`
-	IndexQueueURL   string `mapstructure:"index_queue_url"`
-	ArchiveQueueURL string `mapstructure:"archive_queue_url"`
-	CleanupQueueURL string `mapstructure:"cleanup_queue_url"`
+	IndexQueueURL   string `mapstructure:"index_queue_url" validate:"required,url"`
+	ArchiveQueueURL string `mapstructure:"archive_queue_url" validate:"required,url"`
+	CleanupQueueURL string `mapstructure:"cleanup_queue_url" validate:"required,url"`
+	ExportQueueURL  string `mapstructure:"export_queue_url" validate:"required,url"`
+	IngestQueueURL  string `mapstructure:"ingest_queue_url" validate:"required,url"`
+	// Breaker guards the calls to SQS
+	Breaker *BreakerConfig `mapstructure:"-"`
 }
 
 func DefaultSQSConfig() *SQSConfig {
 	return &SQSConfig{
-		Region:          getEnvOrDefault("AWS_REGION", "us-east-1"),
-		Endpoint:        getEnvOrDefault("AWS_SQS_ENDPOINT", "http://localhost:4566"),
-		AccessKeyID:     getEnvOrDefault("AWS_ACCESS_KEY_ID", "dummy"),
-		SecretAccessKey: getEnvOrDefault("AWS_SECRET_ACCESS_KEY", "dummy"),
-		IndexQueueURL:   getEnvOrDefault("AWS_SQS_INDEX_QUEUE_URL", "http://localhost:4566/000000000000/audit-log-index-queue"),
-		ArchiveQueueURL: getEnvOrDefault("AWS_SQS_ARCHIVE_QUEUE_URL", "http://localhost:4566/000000000000/audit-log-archive-queue"),
-		CleanupQueueURL: getEnvOrDefault("AWS_SQS_CLEANUP_QUEUE_URL", "http://localhost:4566/000000000000/audit-log-cleanup-queue"),
+		Region:          getString("aws.region", "us-east-1"),
+		Endpoint:        getString("aws.sqs.endpoint", "http://localhost:4566"),
+		AccessKeyID:     getString("aws.access_key_id", "dummy"),
+		SecretAccessKey: getString("aws.secret_access_key", "dummy"),
+		IndexQueueURL:   getString("aws.sqs.index_queue_url", "http://localhost:4566/000000000000/audit-log-index-queue"),
+		ArchiveQueueURL: getString("aws.sqs.archive_queue_url", "http://localhost:4566/000000000000/audit-log-archive-queue"),
+		CleanupQueueURL: getString("aws.sqs.cleanup_queue_url", "http://localhost:4566/000000000000/audit-log-cleanup-queue"),
+		ExportQueueURL:  getString("aws.sqs.export_queue_url", "http://localhost:4566/000000000000/audit-log-export-queue"),
+		IngestQueueURL:  getString("aws.sqs.ingest_queue_url", "http://localhost:4566/000000000000/audit-log-ingest-queue"),
+		Breaker:         DefaultBreakerConfig("sqs"),
 	}
 }
 
@@ -0,0 +1,30 @@
+package config
+
+import "time"
+
+// StatsRollupConfig controls the stats worker, which rolls the logs up into
+// the audit_logs_hourly_stats table
+type StatsRollupConfig struct {
+	// Interval is how often logs created since the last rollup are rolled up
+	Interval time.Duration `validate:"gt=0"`
+	// Delay holds back the logs created within it, so that logs of
+	// transactions still in flight aren't passed over
+	Delay time.Duration `validate:"gte=0"`
+	// MaxWindow caps the span of creation times one rollup covers, so a
+	// worker catching up commits in steps
+	MaxWindow time.Duration `validate:"gt=0"`
+}
+
+// DefaultStatsRollupConfig loads the stats worker settings from
+// STATS_ROLLUP_* environment variables
+func DefaultStatsRollupConfig() *StatsRollupConfig {
+	return &StatsRollupConfig{
+		Interval:  getDuration("stats_rollup.interval", time.Minute),
+		Delay:     getDuration("stats_rollup.delay", 30*time.Second),
+		MaxWindow: getDuration("stats_rollup.max_window", time.Hour),
+	}
+}
+
+func (c *StatsRollupConfig) Validate() error {
+	return validateStruct(c)
+}
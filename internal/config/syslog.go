@@ -0,0 +1,54 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+type SyslogConfig struct {
+	// UDPAddr and TCPAddr are the listen addresses; setting one to empty disables it
+	UDPAddr string
+	TCPAddr string
+	// SourceTokens maps the token a source sends in its [auth token="..."]
+	// structured data element to the tenant its messages are logged under
+	SourceTokens map[string]string
+	// BatchSize and FlushInterval bound how long messages wait before being written
+	BatchSize     int           `validate:"min=1"`
+	FlushInterval time.Duration `validate:"gt=0"`
+	// MaxMessageSize caps a single message; longer TCP frames close the connection
+	MaxMessageSize int `validate:"min=480"`
+}
+
+// DefaultSyslogConfig loads the syslog listener settings from SYSLOG_*
+// environment variables. SYSLOG_SOURCE_TOKENS is a comma separated list of
+// token=tenant_id pairs.
+func DefaultSyslogConfig() *SyslogConfig {
+	return &SyslogConfig{
+		UDPAddr:        getStringAllowEmpty("syslog.udp_addr", ":5514"),
+		TCPAddr:        getStringAllowEmpty("syslog.tcp_addr", ":5514"),
+		SourceTokens:   parseSourceTokens(getString("syslog.source_tokens", "")),
+		BatchSize:      getInt("syslog.batch_size", 100),
+		FlushInterval:  getDuration("syslog.flush_interval", time.Second),
+		MaxMessageSize: getInt("syslog.max_message_size", 64*1024),
+	}
+}
+
+func (c *SyslogConfig) Validate() error {
+	errs := fieldErrors(c)
+	if c.UDPAddr == "" && c.TCPAddr == "" {
+		errs = append(errs, errors.New("SYSLOG_UDP_ADDR and SYSLOG_TCP_ADDR are both disabled"))
+	}
+	return invalidConfig(errs)
+}
+
+func parseSourceTokens(value string) map[string]string {
+	tokens := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		token, tenantID, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if ok && token != "" && tenantID != "" {
+			tokens[token] = tenantID
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,25 @@
+package config
+
+import "time"
+
+// TenantDeletionConfig controls how long deleted tenants can be restored and
+// how often the purge worker looks for tenants past that grace period
+type TenantDeletionConfig struct {
+	// GracePeriod is how long a deleted tenant can be restored before its data is purged
+	GracePeriod time.Duration `validate:"gt=0"`
+	// PurgeInterval is how often the purge worker runs
+	PurgeInterval time.Duration `validate:"gt=0"`
+}
+
+// DefaultTenantDeletionConfig loads the tenant deletion settings from
+// TENANT_DELETION_* environment variables
+func DefaultTenantDeletionConfig() *TenantDeletionConfig {
+	return &TenantDeletionConfig{
+		GracePeriod:   getDuration("tenant_deletion.grace_period", 30*24*time.Hour),
+		PurgeInterval: getDuration("tenant_deletion.purge_interval", time.Hour),
+	}
+}
+
+func (c *TenantDeletionConfig) Validate() error {
+	return validateStruct(c)
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+// IP reputation providers
+const (
+	// ThreatIntelProviderBlocklist scores the addresses on downloaded
+	// blocklists, such as FireHOL, Spamhaus DROP or OTX exports
+	ThreatIntelProviderBlocklist = "blocklist"
+	// ThreatIntelProviderAbuseIPDB scores addresses with the abuse confidence
+	// score of the AbuseIPDB check API
+	ThreatIntelProviderAbuseIPDB = "abuseipdb"
+)
+
+// ThreatIntelConfig controls the enrichment of ingested logs with the threat
+// score of their IP address
+type ThreatIntelConfig struct {
+	// Providers are the IP reputation providers asked, the highest score
+	// winning; none disables enrichment
+	Providers []string `validate:"dive,oneof=blocklist abuseipdb"`
+	// BlocklistURLs are the http(s) URLs or file paths of the blocklists, each
+	// listing an IP address or CIDR range per line
+	BlocklistURLs []string
+	// BlocklistScore is the threat score of the addresses on a blocklist
+	BlocklistScore int `validate:"min=1,max=100"`
+	// RefreshInterval is how often the blocklists are downloaded again
+	RefreshInterval time.Duration `validate:"gt=0"`
+	AbuseIPDBURL    string        `validate:"url"`
+	AbuseIPDBAPIKey string
+	// AbuseIPDBMaxAgeDays is how many days back AbuseIPDB reports count
+	AbuseIPDBMaxAgeDays int `validate:"min=1,max=365"`
+	// CacheTTL is how long the AbuseIPDB score of an address is reused
+	CacheTTL time.Duration `validate:"gt=0"`
+	// Timeout bounds a lookup or a blocklist download
+	Timeout time.Duration `validate:"gt=0"`
+	// MinScore is the lowest score recorded as a log's threat_score
+	MinScore int `validate:"min=1,max=100"`
+	// RaiseSeverityScore raises logs scoring at least this to RaisedSeverity;
+	// 0 leaves severities as they are
+	RaiseSeverityScore int    `validate:"min=0,max=100"`
+	RaisedSeverity     string `validate:"oneof=WARNING ERROR CRITICAL"`
+}
+
+// DefaultThreatIntelConfig loads the IP reputation settings from
+// THREAT_INTEL_* environment variables. THREAT_INTEL_PROVIDERS and
+// THREAT_INTEL_BLOCKLIST_URLS are comma separated lists.
+func DefaultThreatIntelConfig() *ThreatIntelConfig {
+	return &ThreatIntelConfig{
+		Providers:           parseList(getString("threat_intel.providers", "")),
+		BlocklistURLs:       parseList(getString("threat_intel.blocklist_urls", "")),
+		BlocklistScore:      getInt("threat_intel.blocklist_score", 100),
+		RefreshInterval:     getDuration("threat_intel.refresh_interval", time.Hour),
+		AbuseIPDBURL:        getString("threat_intel.abuseipdb_url", "https://api.abuseipdb.com/api/v2/check"),
+		AbuseIPDBAPIKey:     getString("threat_intel.abuseipdb_api_key", ""),
+		AbuseIPDBMaxAgeDays: getInt("threat_intel.abuseipdb_max_age_days", 90),
+		CacheTTL:            getDuration("threat_intel.cache_ttl", 6*time.Hour),
+		Timeout:             getDuration("threat_intel.timeout", 2*time.Second),
+		MinScore:            getInt("threat_intel.min_score", 25),
+		RaiseSeverityScore:  getInt("threat_intel.raise_severity_score", 0),
+		RaisedSeverity:      getString("threat_intel.raised_severity", "WARNING"),
+	}
+}
+
+func (c *ThreatIntelConfig) Validate() error {
+	errs := fieldErrors(c)
+	if slices.Contains(c.Providers, ThreatIntelProviderBlocklist) && len(c.BlocklistURLs) == 0 {
+		errs = append(errs, errors.New("THREAT_INTEL_BLOCKLIST_URLS is required by the blocklist provider"))
+	}
+	if slices.Contains(c.Providers, ThreatIntelProviderAbuseIPDB) && c.AbuseIPDBAPIKey == "" {
+		errs = append(errs, errors.New("THREAT_INTEL_ABUSEIPDB_API_KEY is required by the abuseipdb provider"))
+	}
+	if c.RaiseSeverityScore > 0 && c.RaiseSeverityScore < c.MinScore {
+		errs = append(errs, errors.New("THREAT_INTEL_RAISE_SEVERITY_SCORE must not be below THREAT_INTEL_MIN_SCORE"))
+	}
+	return invalidConfig(errs)
+}
+
+// Enabled reports whether logs are enriched with threat scores
+func (c *ThreatIntelConfig) Enabled() bool {
+	return len(c.Providers) > 0
+}
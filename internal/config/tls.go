@@ -0,0 +1,146 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Client certificate modes accepted by TLS_CLIENT_AUTH
+const (
+	// ClientAuthNone doesn't ask for client certificates
+	ClientAuthNone = "none"
+	// ClientAuthOptional verifies client certificates that are presented, so
+	// callers may authenticate with either a certificate or a bearer token
+	ClientAuthOptional = "optional"
+	// ClientAuthRequire rejects connections without a valid client certificate
+	ClientAuthRequire = "require"
+)
+
+// TLSConfig controls TLS termination in the API server and the verification
+// of client certificates
+type TLSConfig struct {
+	// CertFile and KeyFile hold the server certificate; TLS is off without them
+	CertFile string `validate:"required_with=KeyFile"`
+	KeyFile  string `validate:"required_with=CertFile"`
+
+	ClientAuth string `validate:"oneof=none optional require"`
+	// ClientCAFile is the PEM bundle of the CAs client certificates must chain to
+	ClientCAFile string `validate:"required_unless=ClientAuth none"`
+
+	// ClientCertTenants maps client certificates to the tenant they act for,
+	// by the hex SHA-256 fingerprint of the certificate or its subject common
+	// name. Verified certificates without a mapping aren't authenticated.
+	ClientCertTenants map[string]string
+	// ClientCertRoles are the roles of callers authenticated by a certificate
+	ClientCertRoles []string `validate:"required_unless=ClientAuth none"`
+}
+
+// DefaultTLSConfig loads the TLS settings from TLS_* environment variables
+func DefaultTLSConfig() *TLSConfig {
+	return &TLSConfig{
+		CertFile:          getString("tls.cert_file", ""),
+		KeyFile:           getString("tls.key_file", ""),
+		ClientAuth:        getString("tls.client_auth", ClientAuthNone),
+		ClientCAFile:      getString("tls.client_ca_file", ""),
+		ClientCertTenants: parseClientCertTenants(getString("tls.client_cert_tenants", "")),
+		ClientCertRoles:   parseList(getString("tls.client_cert_roles", "user")),
+	}
+}
+
+func (c *TLSConfig) Validate() error {
+	if err := validateStruct(c); err != nil {
+		return err
+	}
+	if !c.Enabled() && c.ClientAuth != ClientAuthNone {
+		return errors.New("client certificates need TLS_CERT_FILE and TLS_KEY_FILE")
+	}
+	return nil
+}
+
+// Enabled reports whether the API server terminates TLS
+func (c *TLSConfig) Enabled() bool {
+	return c.CertFile != ""
+}
+
+// VerifiesClientCerts reports whether callers may authenticate with a client certificate
+func (c *TLSConfig) VerifiesClientCerts() bool {
+	return c.Enabled() && c.ClientAuth != ClientAuthNone
+}
+
+// ServerConfig returns the TLS configuration of the API server, verifying
+// client certificates against the CA bundle when they are asked for
+func (c *TLSConfig) ServerConfig() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server certificate: %w", err)
+	}
+	serverConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if !c.VerifiesClientCerts() {
+		return serverConfig, nil
+	}
+
+	bundle, err := os.ReadFile(c.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA bundle: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bundle) {
+		return nil, fmt.Errorf("no certificates found in client CA bundle %s", c.ClientCAFile)
+	}
+	serverConfig.ClientCAs = pool
+	serverConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	if c.ClientAuth == ClientAuthRequire {
+		serverConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return serverConfig, nil
+}
+
+// parseClientCertTenants parses "fingerprint-or-common-name=tenant-id,..."
+// pairs. Fingerprints may be written with colons and in either case.
+// Malformed pairs are skipped, leaving their certificates unauthenticated.
+func parseClientCertTenants(value string) map[string]string {
+	tenants := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		cert, tenantID, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		cert, tenantID = strings.TrimSpace(cert), strings.TrimSpace(tenantID)
+		if !ok || cert == "" || tenantID == "" || strings.Contains(tenantID, "=") {
+			continue
+		}
+		if fingerprint := strings.ToLower(strings.ReplaceAll(cert, ":", "")); isSHA256Hex(fingerprint) {
+			cert = fingerprint
+		}
+		tenants[cert] = tenantID
+	}
+	return tenants
+}
+
+// parseList parses comma-separated values, dropping empty ones
+func parseList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// isSHA256Hex reports whether s is a lowercase hex SHA-256 digest
+func isSHA256Hex(s string) bool {
+	if len(s) != 64 {
+		return false
+	}
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TLSConfigTestSuite struct {
+	suite.Suite
+}
+
+func TestTLSConfig(t *testing.T) {
+	suite.Run(t, new(TLSConfigTestSuite))
+}
+
+func (s *TLSConfigTestSuite) TestParseClientCertTenants_NormalizesFingerprints() {
+	// Arrange
+	fingerprint := strings.Repeat("AB", 32)
+	colons := strings.TrimSuffix(strings.Repeat("AB:", 32), ":")
+
+	// Act
+	tenants := parseClientCertTenants(colons + "=tenant1, billing-service = tenant2")
+
+	// Assert
+	s.Equal(map[string]string{
+		strings.ToLower(fingerprint): "tenant1",
+		"billing-service":            "tenant2",
+	}, tenants)
+}
+
+func (s *TLSConfigTestSuite) TestParseClientCertTenants_SkipsMalformedEntries() {
+	// Arrange
+	value := "billing-service, =tenant1, reporting=, export=tenant2=tenant3, ingest-service=tenant4"
+
+	// Act
+	tenants := parseClientCertTenants(value)
+
+	// Assert
+	s.Equal(map[string]string{"ingest-service": "tenant4"}, tenants)
+}
+
+func (s *TLSConfigTestSuite) TestParseClientCertTenants_Empty() {
+	// Act
+	tenants := parseClientCertTenants("")
+
+	// Assert
+	s.Empty(tenants)
+}
@@ -0,0 +1,22 @@
+package config
+
+type TracingConfig struct {
+	// Endpoint is the OTLP/HTTP collector URL; tracing is disabled when empty
+	Endpoint    string
+	ServiceName string
+	SampleRatio float64
+}
+
+// DefaultTracingConfig loads OpenTelemetry settings from the standard OTEL_*
+// environment variables, using serviceName when OTEL_SERVICE_NAME is unset
+func DefaultTracingConfig(serviceName string) *TracingConfig {
+	return &TracingConfig{
+		Endpoint:    getString("otel.exporter_otlp_endpoint", ""),
+		ServiceName: getString("otel.service_name", serviceName),
+		SampleRatio: getFloat("otel.traces_sampler_arg", 1.0),
+	}
+}
+
+func (c *TracingConfig) Enabled() bool {
+	return c.Endpoint != ""
+}
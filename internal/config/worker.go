@@ -0,0 +1,45 @@
+package config
+
+import "time"
+
+// WorkerConfig controls how many messages the queue workers process, how fast,
+// and how they hold and hand back messages
+type WorkerConfig struct {
+	// Count is the number of goroutines receiving and processing messages
+	Count int `validate:"min=1"`
+	// PollInterval is the pause between two receives of a goroutine
+	PollInterval time.Duration `validate:"gt=0"`
+	// MaxMessages is the most messages a receive returns, at most 10 for SQS
+	MaxMessages int `validate:"min=1,max=10"`
+	// RateLimit caps the messages a goroutine processes per second; 0 disables it
+	RateLimit float64 `validate:"gte=0"`
+	// BackoffMin and BackoffMax bound the exponential backoff of a goroutine
+	// while OpenSearch or PostgreSQL report pressure (429s and timeouts)
+	BackoffMin time.Duration `validate:"gt=0"`
+	BackoffMax time.Duration `validate:"gtefield=BackoffMin"`
+	// DrainTimeout bounds how long Stop waits for received messages to be
+	// processed; messages not started by then are released for redelivery
+	DrainTimeout time.Duration `validate:"gt=0"`
+	// VisibilityExtension is how far a message's visibility timeout is pushed
+	// out, every half of it, while the message is being processed
+	VisibilityExtension time.Duration `validate:"gte=2s"`
+}
+
+// DefaultWorkerConfig reads the worker settings, falling back to the given
+// per-process count, poll interval and receive size
+func DefaultWorkerConfig(count int, pollInterval time.Duration, maxMessages int) *WorkerConfig {
+	return &WorkerConfig{
+		Count:               getInt("worker.count", count),
+		PollInterval:        getDuration("worker.poll_interval", pollInterval),
+		MaxMessages:         getInt("worker.max_messages", maxMessages),
+		RateLimit:           getFloat("worker.rate_limit", 0),
+		BackoffMin:          getDuration("worker.backoff_min", time.Second),
+		BackoffMax:          getDuration("worker.backoff_max", 30*time.Second),
+		DrainTimeout:        getDuration("worker.drain_timeout", 30*time.Second),
+		VisibilityExtension: getDuration("worker.visibility_extension", 30*time.Second),
+	}
+}
+
+func (c *WorkerConfig) Validate() error {
+	return validateStruct(c)
+}
@@ -0,0 +1,56 @@
+package domain
+
+import "time"
+
+type AnomalyKind string
+
+const (
+	// AnomalyLogRate flags a tenant logging far faster than its baseline
+	AnomalyLogRate AnomalyKind = "log_rate"
+	// AnomalyFailureRatio flags a jump in the share of ERROR and CRITICAL logs
+	AnomalyFailureRatio AnomalyKind = "failure_ratio"
+	// AnomalyNewIPAddress flags a known user acting from IP addresses unseen in the baseline
+	AnomalyNewIPAddress AnomalyKind = "new_ip_address"
+)
+
+const (
+	// ActionAnomaly is the action of the synthetic logs the anomaly worker emits
+	ActionAnomaly ActionType = "ANOMALY"
+	// AnomalyResourceType is the resource type of the synthetic logs the anomaly worker emits
+	AnomalyResourceType = "anomaly"
+)
+
+// ActivityCounts summarises a tenant's logs over a time window
+type ActivityCounts struct {
+	Total int64
+	// Failed counts ERROR and CRITICAL logs
+	Failed int64
+}
+
+// FailureRatio returns the share of failed logs, or 0 without logs
+func (c ActivityCounts) FailureRatio() float64 {
+	if c.Total == 0 {
+		return 0
+	}
+	return float64(c.Failed) / float64(c.Total)
+}
+
+// UserIPAddress is an IP address a user acted from
+type UserIPAddress struct {
+	UserID    string
+	IPAddress string
+}
+
+// Anomaly is activity that deviated from the tenant's baseline during
+// [WindowStart, WindowEnd)
+type Anomaly struct {
+	TenantID    string
+	Kind        AnomalyKind
+	UserID      string
+	IPAddresses []string
+	Observed    float64
+	Baseline    float64
+	Threshold   float64
+	WindowStart time.Time
+	WindowEnd   time.Time
+}
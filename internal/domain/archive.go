@@ -0,0 +1,79 @@
+package domain
+
+import "time"
+
+// ArchiveVerificationStatus is the outcome of re-hashing an archive in S3
+type ArchiveVerificationStatus string
+
+const (
+	// ArchiveVerified means every object matched its recorded checksum
+	ArchiveVerified ArchiveVerificationStatus = "verified"
+	// ArchiveCorrupted means an object is missing or doesn't match its checksum
+	ArchiveCorrupted ArchiveVerificationStatus = "corrupted"
+	// ArchiveUnavailable means no object was found corrupted, but some are in
+	// Glacier and couldn't be read
+	ArchiveUnavailable ArchiveVerificationStatus = "unavailable"
+)
+
+// Archive records an archive written to S3 with the SHA-256 checksums of its
+// manifest and parts, so its integrity can be verified later
+type Archive struct {
+	ID                 string                    `gorm:"primaryKey;type:uuid" json:"id"`
+	TenantID           string                    `gorm:"type:uuid;not null" json:"tenant_id"`
+	BeforeDate         time.Time                 `gorm:"type:timestamp with time zone;not null" json:"before_date"`
+	ManifestKey        string                    `gorm:"type:text;not null" json:"manifest_key"`
+	ManifestSHA256     string                    `gorm:"column:manifest_sha256;type:text;not null" json:"manifest_sha256"`
+	LogCount           int64                     `gorm:"not null" json:"log_count"`
+	Size               int64                     `gorm:"not null" json:"size"`
+	Parts              []ArchivePart             `gorm:"type:jsonb;serializer:json;not null" json:"parts"`
+	StorageClass       string                    `gorm:"type:text" json:"storage_class,omitempty"`
+	RetainUntil        *time.Time                `gorm:"type:timestamp with time zone" json:"retain_until,omitempty"`
+	KMSKeyID           string                    `gorm:"column:kms_key_id;type:text" json:"kms_key_id,omitempty"`
+	ArchivedAt         time.Time                 `gorm:"type:timestamp with time zone;not null" json:"archived_at"`
+	VerificationStatus ArchiveVerificationStatus `gorm:"type:text" json:"verification_status,omitempty"`
+	LastVerifiedAt     *time.Time                `gorm:"type:timestamp with time zone" json:"last_verified_at,omitempty"`
+	CreatedAt          time.Time                 `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt          time.Time                 `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+func (Archive) TableName() string {
+	return "archives"
+}
+
+// ArchivePart is one compressed NDJSON object of an archive
+type ArchivePart struct {
+	Key            string    `json:"key"`
+	LogCount       int64     `json:"log_count"`
+	FirstTimestamp time.Time `json:"first_timestamp"`
+	LastTimestamp  time.Time `json:"last_timestamp"`
+	Size           int64     `json:"size"`
+	SHA256         string    `json:"sha256"`
+}
+
+// Overlaps reports whether the part may hold logs in [start, end]
+func (p ArchivePart) Overlaps(start, end time.Time) bool {
+	return !p.LastTimestamp.Before(start) && !p.FirstTimestamp.After(end)
+}
+
+// ArchiveFilter selects a page of a tenant's archives
+type ArchiveFilter struct {
+	TenantID string `json:"tenant_id"`
+	Page     int    `json:"page"`
+	PageSize int    `json:"page_size"`
+	Limit    int    `json:"limit"`
+	Offset   int    `json:"offset"`
+}
+
+// ArchiveSearchFilter selects archived logs of a tenant in a time range,
+// optionally by user, action, resource and severity
+type ArchiveSearchFilter struct {
+	TenantID     string    `json:"tenant_id"`
+	StartTime    time.Time `json:"start_time"`
+	EndTime      time.Time `json:"end_time"`
+	UserID       string    `json:"user_id"`
+	Action       string    `json:"action"`
+	ResourceType string    `json:"resource_type"`
+	ResourceID   string    `json:"resource_id"`
+	Severity     string    `json:"severity"`
+	Limit        int       `json:"limit"`
+}
@@ -0,0 +1,47 @@
+package domain
+
+import "time"
+
+// ArchiveSchedule is a tenant's override of the scheduled archival and the
+// record of its last run. Zero IntervalHours and RetentionDays fall back to
+// the scheduler's interval and the tenant's retention setting. Tenants without
+// a schedule are archived with the defaults.
+type ArchiveSchedule struct {
+	TenantID      string     `gorm:"primaryKey;type:uuid" json:"tenant_id"`
+	Enabled       bool       `gorm:"not null" json:"enabled"`
+	IntervalHours int        `gorm:"not null" json:"interval_hours"`
+	RetentionDays int        `gorm:"not null" json:"retention_days"`
+	LastRunAt     *time.Time `gorm:"type:timestamp with time zone" json:"last_run_at,omitempty"`
+	LastJobID     *string    `gorm:"type:uuid" json:"last_job_id,omitempty"`
+	CreatedAt     time.Time  `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+func (ArchiveSchedule) TableName() string {
+	return "archive_schedules"
+}
+
+// DefaultArchiveSchedule is the schedule of a tenant without overrides
+func DefaultArchiveSchedule(tenantID string) *ArchiveSchedule {
+	return &ArchiveSchedule{TenantID: tenantID, Enabled: true}
+}
+
+// Interval returns how often the tenant's logs are archived, or fallback
+// when the tenant doesn't override it
+func (s *ArchiveSchedule) Interval(fallback time.Duration) time.Duration {
+	if s.IntervalHours > 0 {
+		return time.Duration(s.IntervalHours) * time.Hour
+	}
+	return fallback
+}
+
+// Retention returns the age of the logs archived, or 0 when the tenant's
+// retention setting applies
+func (s *ArchiveSchedule) Retention() time.Duration {
+	return time.Duration(s.RetentionDays) * 24 * time.Hour
+}
+
+// Due reports whether the next run is due by now
+func (s *ArchiveSchedule) Due(now time.Time, interval time.Duration) bool {
+	return s.Enabled && (s.LastRunAt == nil || !now.Before(s.LastRunAt.Add(interval)))
+}
@@ -1,7 +1,11 @@
 package domain
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -23,26 +27,61 @@ const (
 	ActionView   ActionType = "VIEW"
 )
 
+// SeverityLevels are the severities logs may be ingested with
+var SeverityLevels = []SeverityLevel{SeverityInfo, SeverityWarning, SeverityError, SeverityCritical}
+
+// ActionTypes are the built-in actions every tenant may log. Tenants add
+// their own with TenantSettings.CustomActions.
+var ActionTypes = []ActionType{ActionCreate, ActionUpdate, ActionDelete, ActionView}
+
+// IsSeverityLevel reports whether severity is one of SeverityLevels, ignoring case
+func IsSeverityLevel(severity string) bool {
+	return slices.ContainsFunc(SeverityLevels, func(level SeverityLevel) bool {
+		return strings.EqualFold(string(level), severity)
+	})
+}
+
+// IsActionType reports whether action is one of the built-in ActionTypes, ignoring case
+func IsActionType(action string) bool {
+	return slices.ContainsFunc(ActionTypes, func(actionType ActionType) bool {
+		return strings.EqualFold(string(actionType), action)
+	})
+}
+
+// LogSchemaVersion is the version of the log schema producers write. Logs of
+// older versions are upconverted at ingest, and each stored log records the
+// version it conforms to; logs stored before versioning are version 1.
+const LogSchemaVersion = 2
+
 type AuditLog struct {
-	ID           string          `gorm:"primaryKey;type:uuid" json:"id"`
-	TenantID     string          `gorm:"type:uuid;not null" json:"tenant_id"`
-	UserID       string          `gorm:"type:uuid" json:"user_id"`
-	SessionID    string          `gorm:"type:text" json:"session_id"`
-	IPAddress    string          `gorm:"type:text" json:"ip_address"`
-	UserAgent    string          `gorm:"type:text" json:"user_agent"`
-	Action       string          `gorm:"type:text;not null" json:"action"`
-	ResourceType string          `gorm:"type:text" json:"resource_type"`
-	ResourceID   string          `gorm:"type:text" json:"resource_id"`
-	Message      string          `gorm:"type:text" json:"message"`
-	Severity     string          `gorm:"type:text;not null;default:'INFO'" json:"severity"`
-	BeforeState  json.RawMessage `gorm:"type:jsonb" json:"before_state,omitempty"`
-	AfterState   json.RawMessage `gorm:"type:jsonb" json:"after_state,omitempty"`
-	Metadata     json.RawMessage `gorm:"type:jsonb" json:"metadata,omitempty"`
-	Timestamp    time.Time       `gorm:"type:timestamp with time zone;not null;default:CURRENT_TIMESTAMP" json:"timestamp"`
-	CreatedAt    time.Time       `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
-	UpdatedAt    time.Time       `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
-	Tenant       *Tenant         `gorm:"foreignKey:TenantID" json:"-"`
-	User         *User           `gorm:"foreignKey:UserID" json:"-"`
+	ID            string          `gorm:"primaryKey;type:uuid" json:"id"`
+	TenantID      string          `gorm:"type:uuid;not null" json:"tenant_id"`
+	UserID        string          `gorm:"type:uuid" json:"user_id"`
+	SessionID     string          `gorm:"type:text" json:"session_id"`
+	CorrelationID string          `gorm:"type:text;default:null" json:"correlation_id,omitempty"`
+	IPAddress     string          `gorm:"type:text" json:"ip_address"`
+	UserAgent     string          `gorm:"type:text" json:"user_agent"`
+	Action        string          `gorm:"type:text;not null" json:"action"`
+	ResourceType  string          `gorm:"type:text" json:"resource_type"`
+	ResourceID    string          `gorm:"type:text" json:"resource_id"`
+	Message       string          `gorm:"type:text" json:"message"`
+	Severity      string          `gorm:"type:text;not null;default:'INFO'" json:"severity"`
+	BeforeState   json.RawMessage `gorm:"type:jsonb" json:"before_state,omitempty"`
+	AfterState    json.RawMessage `gorm:"type:jsonb" json:"after_state,omitempty"`
+	Metadata      json.RawMessage `gorm:"type:jsonb" json:"metadata,omitempty"`
+	SchemaErrors  []string        `gorm:"type:jsonb;serializer:json" json:"schema_errors,omitempty"`
+	SchemaVersion int             `gorm:"type:smallint;not null;default:1" json:"schema_version,omitempty"`
+	Tags          Tags            `gorm:"type:text[];not null;default:'{}'" json:"tags,omitempty"`
+	Timestamp     time.Time       `gorm:"type:timestamp with time zone;not null;default:CURRENT_TIMESTAMP" json:"timestamp"`
+	CreatedAt     time.Time       `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt     time.Time       `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	Tenant        *Tenant         `gorm:"foreignKey:TenantID" json:"-"`
+	User          *User           `gorm:"foreignKey:UserID" json:"-"`
+	// Highlights holds the fragments of each field that matched a full-text query
+	Highlights map[string][]string `gorm:"-" json:"-"`
+	// SortValues holds the sort values of a search hit, which a search
+	// continues after through AuditLogFilter.SearchAfter
+	SortValues json.RawMessage `gorm:"-" json:"-"`
 }
 
 func (AuditLog) TableName() string {
@@ -50,22 +89,211 @@ func (AuditLog) TableName() string {
 }
 
 type AuditLogFilter struct {
-	TenantID     string    `json:"tenant_id"`
-	UserID       string    `json:"user_id"`
-	SessionID    string    `json:"session_id"`
-	IPAddress    string    `json:"ip_address"`
-	UserAgent    string    `json:"user_agent"`
-	Action       string    `json:"action"`
-	ResourceType string    `json:"resource_type"`
-	ResourceID   string    `json:"resource_id"`
-	Message      string    `json:"message"`
-	Severity     string    `json:"severity"`
-	StartTime    time.Time `json:"start_time"`
-	EndTime      time.Time `json:"end_time"`
-	Page         int       `json:"page"`
-	PageSize     int       `json:"page_size"`
-	Limit        int       `json:"limit"`
-	Offset       int       `json:"offset"`
+	TenantID string `json:"tenant_id"`
+	UserID   string `json:"user_id"`
+	// VisibleUserID restricts the logs to the user's, whatever UserID asks
+	// for; it is set for callers that may only see their own logs
+	VisibleUserID string      `json:"visible_user_id,omitempty"`
+	SessionID     string      `json:"session_id"`
+	IPAddress     string      `json:"ip_address"`
+	UserAgent     string      `json:"user_agent"`
+	Action        ValueFilter `json:"action"`
+	ResourceType  ValueFilter `json:"resource_type"`
+	ResourceID    string      `json:"resource_id"`
+	Message       string      `json:"message"`
+	Severity      ValueFilter `json:"severity"`
+	Tags          ValueFilter `json:"tags"`
+	StartTime     time.Time   `json:"start_time"`
+	EndTime       time.Time   `json:"end_time"`
+	Page          int         `json:"page"`
+	PageSize      int         `json:"page_size"`
+	Limit         int         `json:"limit"`
+	Offset        int         `json:"offset"`
+	// Query is a full-text query across the message, metadata, user agent and
+	// resource ID; results are ranked by relevance
+	Query         string `json:"query,omitempty"`
+	CorrelationID string `json:"correlation_id,omitempty"`
+	// MinThreatScore restricts the logs to those whose metadata records a
+	// threat score of at least this; 0 doesn't filter
+	MinThreatScore int `json:"min_threat_score,omitempty"`
+	// Fields restricts the logs read to these of AuditLogFields; empty reads
+	// every field
+	Fields []string `json:"fields,omitempty"`
+	// Sort orders listed logs; empty lists the newest first
+	Sort []SortField `json:"sort,omitempty"`
+	// SearchAfter continues a search after the log with these sort values
+	// rather than skipping Offset logs, which OpenSearch caps at 10,000
+	SearchAfter json.RawMessage `json:"search_after,omitempty"`
+}
+
+// AuditLogFields are the fields of a log that can be selected, named alike in
+// responses, audit_logs columns and search documents
+var AuditLogFields = []string{
+	"id", "tenant_id", "user_id", "session_id", "correlation_id",
+	"ip_address", "user_agent", "action", "resource_type", "resource_id",
+	"severity", "message", "before_state", "after_state", "metadata", "schema_errors",
+	"schema_version", "tags", "timestamp",
+}
+
+// SelectedFields returns the fields to read for the filter, nil for all of
+// them. The id and timestamp are always read, as sorting and keyset
+// pagination need them.
+func (f AuditLogFilter) SelectedFields() []string {
+	if len(f.Fields) == 0 {
+		return nil
+	}
+
+	fields := []string{"id", "timestamp"}
+	for _, field := range f.Fields {
+		if !slices.Contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// ValueFilter matches a field against sets of values: a value matches if it
+// is one of In, or In is empty, and none of NotIn
+type ValueFilter struct {
+	In    []string `json:"in,omitempty"`
+	NotIn []string `json:"not_in,omitempty"`
+}
+
+// ParseValueFilter parses a comma-separated list of values, such as
+// "ERROR,CRITICAL"; values prefixed with ! are excluded, as in "!VIEW"
+func ParseValueFilter(s string) ValueFilter {
+	var f ValueFilter
+	for _, value := range strings.Split(s, ",") {
+		value = strings.TrimSpace(value)
+		if excluded, ok := strings.CutPrefix(value, "!"); ok {
+			if excluded = strings.TrimSpace(excluded); excluded != "" {
+				f.NotIn = append(f.NotIn, excluded)
+			}
+		} else if value != "" {
+			f.In = append(f.In, value)
+		}
+	}
+	return f
+}
+
+// IsEmpty reports whether the filter matches every value
+func (f ValueFilter) IsEmpty() bool {
+	return len(f.In) == 0 && len(f.NotIn) == 0
+}
+
+// Matches reports whether value passes the filter
+func (f ValueFilter) Matches(value string) bool {
+	return (len(f.In) == 0 || slices.Contains(f.In, value)) && !slices.Contains(f.NotIn, value)
+}
+
+// UnmarshalJSON also accepts the single value string filters were stored as
+// before they took several values
+func (f *ValueFilter) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err == nil {
+		*f = ParseValueFilter(value)
+		return nil
+	}
+
+	type valueFilter ValueFilter
+	return json.Unmarshal(data, (*valueFilter)(f))
+}
+
+// SortableFields are the fields logs can be listed in order of. Severities
+// sort by level, from INFO up to CRITICAL, rather than alphabetically.
+var SortableFields = []string{"timestamp", "severity", "action"}
+
+// SortField orders logs by one of SortableFields, ascending unless Desc
+type SortField struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc,omitempty"`
+}
+
+// ParseSort parses comma-separated sort keys such as "severity:desc,timestamp".
+// A key sorts ascending unless suffixed with ":desc"; each field may be used once.
+func ParseSort(s string) ([]SortField, error) {
+	var sort []SortField
+	for _, key := range strings.Split(s, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+
+		field, direction, _ := strings.Cut(key, ":")
+		field = strings.TrimSpace(field)
+		if !slices.Contains(SortableFields, field) {
+			return nil, fmt.Errorf("cannot sort by %q, sortable fields are %s", field, strings.Join(SortableFields, ", "))
+		}
+		if slices.ContainsFunc(sort, func(f SortField) bool { return f.Field == field }) {
+			return nil, fmt.Errorf("cannot sort by %q more than once", field)
+		}
+
+		var desc bool
+		switch strings.ToLower(strings.TrimSpace(direction)) {
+		case "", "asc":
+		case "desc":
+			desc = true
+		default:
+			return nil, fmt.Errorf("invalid sort direction %q for %s, must be asc or desc", direction, field)
+		}
+		sort = append(sort, SortField{Field: field, Desc: desc})
+	}
+	return sort, nil
+}
+
+// EncodeSearchCursor returns the opaque cursor of a page whose last log has
+// the given sort values
+func EncodeSearchCursor(sortValues json.RawMessage) string {
+	return base64.RawURLEncoding.EncodeToString(sortValues)
+}
+
+// ParseSearchCursor returns the sort values a cursor of EncodeSearchCursor
+// continues after
+func ParseSearchCursor(cursor string) (json.RawMessage, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	var values []json.RawMessage
+	if err != nil || json.Unmarshal(data, &values) != nil || len(values) == 0 {
+		return nil, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return data, nil
+}
+
+// pageCursor is the content of a cursor of EncodePageCursor
+type pageCursor struct {
+	Page int `json:"page"`
+}
+
+// EncodePageCursor returns the opaque cursor of a listing that isn't
+// searched, which continues at page
+func EncodePageCursor(page int) string {
+	data, _ := json.Marshal(pageCursor{Page: page})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParsePageCursor returns the page a cursor of EncodePageCursor continues at,
+// and false for any other cursor
+func ParsePageCursor(cursor string) (int, bool) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	var parsed pageCursor
+	if err != nil || json.Unmarshal(data, &parsed) != nil || parsed.Page < 1 {
+		return 0, false
+	}
+	return parsed.Page, true
+}
+
+// SortOrder returns the keys to list the filter's logs by. The newest logs
+// come first among logs equal in every key.
+func (f AuditLogFilter) SortOrder() []SortField {
+	if slices.ContainsFunc(f.Sort, func(s SortField) bool { return s.Field == "timestamp" }) {
+		return f.Sort
+	}
+	return append(slices.Clone(f.Sort), SortField{Field: "timestamp", Desc: true})
+}
+
+// AuditLogCursor marks the last log of a batch for keyset pagination
+type AuditLogCursor struct {
+	Timestamp time.Time
+	ID        string
 }
 
 type AuditLogStats struct {
@@ -73,4 +301,71 @@ type AuditLogStats struct {
 	ActionCounts   map[ActionType]int64    `json:"action_counts"`
 	SeverityCounts map[SeverityLevel]int64 `json:"severity_counts"`
 	ResourceCounts map[string]int64        `json:"resource_counts"`
+	// Interval and Series hold log counts over time; they are only filled by sources that can bucket cheaply
+	Interval time.Duration         `json:"interval,omitempty"`
+	Series   []AuditLogStatsBucket `json:"series,omitempty"`
+	// SampledOut counts the logs the tenant's sampling rules dropped on the
+	// UTC days of the range, so the counts above can be extrapolated
+	SampledOut *SampledLogCounts `json:"sampled_out,omitempty"`
+}
+
+// SampledLogCounts counts the logs of a tenant dropped at ingest by its
+// sampling rules
+type SampledLogCounts struct {
+	TotalLogs      int64            `json:"total_logs"`
+	ActionCounts   map[string]int64 `json:"action_counts"`
+	SeverityCounts map[string]int64 `json:"severity_counts"`
+}
+
+// TopStats ranks the users, IP addresses, resources and sessions of the logs
+// matching a filter by log count, and counts their distinct users and IP
+// addresses, over the whole range and per UTC day. Distinct counts are
+// approximate.
+type TopStats struct {
+	TotalLogs         int64
+	Users             []TopValue
+	IPAddresses       []TopValue
+	Resources         []TopValue
+	Sessions          []TopValue
+	UniqueUsers       int64
+	UniqueIPAddresses int64
+	Daily             []DailyUniqueCounts
+}
+
+// TopValue is a value of a log field and the number of logs holding it
+type TopValue struct {
+	Value string
+	// ResourceType is the type of a resource ID, the most frequent one if
+	// the ID is used by several types
+	ResourceType string
+	Count        int64
+}
+
+// DailyUniqueCounts counts the distinct users and IP addresses of the logs of
+// the UTC day starting at Day
+type DailyUniqueCounts struct {
+	Day         time.Time
+	Users       int64
+	IPAddresses int64
+}
+
+// AuditLogStatsBucket counts the logs in the interval starting at Start
+type AuditLogStatsBucket struct {
+	Start time.Time `json:"start"`
+	Count int64     `json:"count"`
+}
+
+// StatsInterval picks a bucket width that keeps a time series between start
+// and end to at most a few hundred points
+func StatsInterval(start, end time.Time) time.Duration {
+	switch span := end.Sub(start); {
+	case span <= 6*time.Hour:
+		return 5 * time.Minute
+	case span <= 3*24*time.Hour:
+		return time.Hour
+	case span <= 90*24*time.Hour:
+		return 24 * time.Hour
+	default:
+		return 7 * 24 * time.Hour
+	}
 }
@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// CleanupJob records an archival and deletion of a tenant's logs before a
+// date, with how many logs were archived and removed from each store. Jobs of
+// cleanups scheduled through the API are created pending and updated by the
+// archive worker, then the cleanup worker; the others are created by the
+// cleanup worker.
+type CleanupJob struct {
+	ID            string     `gorm:"primaryKey;type:uuid" json:"id"`
+	TenantID      string     `gorm:"type:uuid;not null" json:"tenant_id"`
+	Status        JobStatus  `gorm:"type:text;not null" json:"status"`
+	BeforeDate    time.Time  `gorm:"type:timestamp with time zone;not null" json:"before_date"`
+	ArchivedCount int64      `gorm:"not null;default:0" json:"archived_count"`
+	DeletedCount  int64      `gorm:"not null;default:0" json:"deleted_count"`
+	IndexedCount  int64      `gorm:"not null;default:0" json:"indexed_count"`
+	Error         string     `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt     time.Time  `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	CompletedAt   *time.Time `gorm:"type:timestamp with time zone" json:"completed_at,omitempty"`
+}
+
+func (CleanupJob) TableName() string {
+	return "cleanup_jobs"
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// DetectionRule names a built-in rule the detection worker evaluates on the
+// live stream of logs
+type DetectionRule string
+
+const (
+	// DetectionBruteForce flags an IP address failing many actions in a short time
+	DetectionBruteForce DetectionRule = "brute_force"
+	// DetectionImpossibleTravel flags a user appearing from two places too far
+	// apart to travel between in the time between their logs
+	DetectionImpossibleTravel DetectionRule = "impossible_travel"
+)
+
+const (
+	// ActionSecurityAlert is the action of the synthetic logs the detection worker emits
+	ActionSecurityAlert ActionType = "SECURITY_ALERT"
+	// SecurityAlertResourceType is the resource type of the synthetic logs the detection worker emits
+	SecurityAlertResourceType = "security_alert"
+)
+
+// earthRadiusKm is the mean radius of the Earth
+const earthRadiusKm = 6371.0
+
+// GeoLocation is where an IP address is located
+type GeoLocation struct {
+	Country   string  `json:"country"`
+	City      string  `json:"city,omitempty"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// DistanceKm returns the great-circle distance to other
+func (l GeoLocation) DistanceKm(other GeoLocation) float64 {
+	lat1, lat2 := l.Latitude*math.Pi/180, other.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (other.Longitude - l.Longitude) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// Sighting is a user seen at a location through a log
+type Sighting struct {
+	LogID     string      `json:"log_id"`
+	IPAddress string      `json:"ip_address"`
+	Location  GeoLocation `json:"location"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// SecurityAlert is what a detection rule found on the live stream
+type SecurityAlert struct {
+	TenantID  string
+	Rule      DetectionRule
+	UserID    string
+	IPAddress string
+	// UserIDs are the users of the failed actions of a brute force alert
+	UserIDs []string
+	// Count is the number of failed actions of a brute force alert within Window
+	Count  int
+	Window time.Duration
+	// From and To are the sightings of an impossible travel alert
+	From       *Sighting
+	To         *Sighting
+	DistanceKm float64
+	SpeedKmh   float64
+	At         time.Time
+}
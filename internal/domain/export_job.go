@@ -0,0 +1,102 @@
+package domain
+
+import "time"
+
+// ExportFormat is the file format an export is written in
+type ExportFormat string
+
+const (
+	ExportFormatJSON ExportFormat = "json"
+	ExportFormatCSV  ExportFormat = "csv"
+	ExportFormatXLSX ExportFormat = "xlsx"
+	// ExportFormatNDJSON is gzip-compressed NDJSON, used by tenant dumps
+	ExportFormatNDJSON ExportFormat = "ndjson"
+)
+
+// LogExportFormats are the formats logs can be exported in
+var LogExportFormats = []ExportFormat{ExportFormatJSON, ExportFormatCSV, ExportFormatXLSX}
+
+// ContentType returns the media type of a log export in the format
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportFormatCSV:
+		return "text/csv"
+	case ExportFormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "application/json"
+	}
+}
+
+// ExportScope is what an export job exports
+type ExportScope string
+
+const (
+	// ExportScopeLogs exports the logs matching the job filter to a single file
+	ExportScopeLogs ExportScope = "logs"
+	// ExportScopeTenant dumps all of the tenant's logs, users, retention
+	// policies and settings to a directory of files described by a manifest
+	ExportScopeTenant ExportScope = "tenant"
+)
+
+// ExportSigningKey is the key an export is signed with
+type ExportSigningKey string
+
+const (
+	// ExportSigningServer signs with the server's key, shared by every tenant
+	ExportSigningServer ExportSigningKey = "server"
+	// ExportSigningTenant signs with the tenant's own key, derived from the
+	// server's key
+	ExportSigningTenant ExportSigningKey = "tenant"
+)
+
+// ExportSigningAlgorithm is the algorithm exports are signed with
+const ExportSigningAlgorithm = "Ed25519"
+
+// ExportSigningKeys are the keys an export can be signed with
+var ExportSigningKeys = []ExportSigningKey{ExportSigningServer, ExportSigningTenant}
+
+// SigningKey is the public half of a key exports are signed with, PEM-encoded
+// and identified by the first 16 hex digits of the SHA-256 of the raw key
+type SigningKey struct {
+	ID        string           `json:"key_id"`
+	Key       ExportSigningKey `json:"key"`
+	Algorithm string           `json:"algorithm"`
+	PublicKey string           `json:"public_key"`
+}
+
+// ExportSignature is the detached signature of an export file: the Ed25519
+// signature, base64-encoded, of the raw SHA-256 digest of the file as
+// downloaded, with the key that verifies it
+type ExportSignature struct {
+	SigningKey
+	SHA256    string    `json:"sha256"`
+	Signature string    `json:"signature"`
+	SignedAt  time.Time `json:"signed_at"`
+}
+
+// ExportJob is an export request processed by the export worker, which
+// streams the matching logs to S3 and records the resulting object key.
+// Encrypted exports were encrypted for the tenant's export public key; jobs
+// with SignWith set are signed with that key once uploaded.
+type ExportJob struct {
+	ID          string           `gorm:"primaryKey;type:uuid" json:"id"`
+	TenantID    string           `gorm:"type:uuid;not null" json:"tenant_id"`
+	Status      JobStatus        `gorm:"type:text;not null" json:"status"`
+	Scope       ExportScope      `gorm:"type:text;not null;default:logs" json:"scope"`
+	Format      ExportFormat     `gorm:"type:text;not null" json:"format"`
+	Filter      AuditLogFilter   `gorm:"type:jsonb;serializer:json;not null" json:"filter"`
+	S3Key       string           `gorm:"column:s3_key;type:text" json:"s3_key,omitempty"`
+	RowCount    int64            `gorm:"not null;default:0" json:"row_count"`
+	Error       string           `gorm:"type:text" json:"error,omitempty"`
+	Encrypted   bool             `gorm:"not null;default:false" json:"encrypted"`
+	SignWith    ExportSigningKey `gorm:"type:text" json:"sign_with,omitempty"`
+	Signature   *ExportSignature `gorm:"type:jsonb;serializer:json" json:"signature,omitempty"`
+	CreatedAt   time.Time        `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt   time.Time        `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	CompletedAt *time.Time       `gorm:"type:timestamp with time zone" json:"completed_at,omitempty"`
+}
+
+func (ExportJob) TableName() string {
+	return "export_jobs"
+}
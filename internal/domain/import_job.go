@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// MaxImportLineErrors bounds the line errors an import job keeps
+const MaxImportLineErrors = 100
+
+// ImportJob loads a tenant's historical logs from a gzip-compressed NDJSON
+// file in S3, uploaded through the API or read from an allowed bucket. Logs
+// keep their IDs and timestamps, so importing the same file twice is a no-op.
+// The job records the lines it has stored, so an interrupted import resumes
+// after them.
+type ImportJob struct {
+	ID            string            `gorm:"primaryKey;type:uuid" json:"id"`
+	TenantID      string            `gorm:"type:uuid;not null" json:"tenant_id"`
+	Status        JobStatus         `gorm:"type:text;not null" json:"status"`
+	SourceURI     string            `gorm:"type:text;not null" json:"source_uri"`
+	Uploaded      bool              `gorm:"not null;default:false" json:"uploaded"`
+	SizeBytes     int64             `gorm:"not null;default:0" json:"size_bytes"`
+	BytesRead     int64             `gorm:"not null;default:0" json:"bytes_read"`
+	LinesRead     int64             `gorm:"not null;default:0" json:"lines_read"`
+	ImportedCount int64             `gorm:"not null;default:0" json:"imported_count"`
+	SkippedCount  int64             `gorm:"not null;default:0" json:"skipped_count"`
+	InvalidCount  int64             `gorm:"not null;default:0" json:"invalid_count"`
+	LineErrors    []ImportLineError `gorm:"type:jsonb;serializer:json" json:"line_errors,omitempty"`
+	Error         string            `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt     time.Time         `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt     time.Time         `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	CompletedAt   *time.Time        `gorm:"type:timestamp with time zone" json:"completed_at,omitempty"`
+}
+
+func (ImportJob) TableName() string {
+	return "import_jobs"
+}
+
+// ImportLineError is why a line of an import file was rejected
+type ImportLineError struct {
+	Line  int64  `json:"line"`
+	Error string `json:"error"`
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// IndexFailure is an audit log OpenSearch rejected for good, such as for a
+// mapping conflict, kept with its document so it can be reindexed once the
+// cause is fixed
+type IndexFailure struct {
+	ID        string    `gorm:"primaryKey;type:uuid" json:"id"`
+	TenantID  string    `gorm:"type:uuid;not null" json:"tenant_id"`
+	LogID     string    `gorm:"type:uuid;not null" json:"log_id"`
+	IndexName string    `gorm:"type:text;not null" json:"index_name"`
+	Status    int       `gorm:"not null" json:"status"`
+	ErrorType string    `gorm:"type:text" json:"error_type,omitempty"`
+	Reason    string    `gorm:"type:text" json:"reason,omitempty"`
+	Document  AuditLog  `gorm:"type:jsonb;serializer:json;not null" json:"document"`
+	FailedAt  time.Time `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"failed_at"`
+}
+
+func (IndexFailure) TableName() string {
+	return "index_failures"
+}
@@ -0,0 +1,99 @@
+package domain
+
+import "time"
+
+// IntegrationType is the log platform an integration forwards to
+type IntegrationType string
+
+const (
+	// IntegrationSplunk posts events to a Splunk HTTP Event Collector
+	IntegrationSplunk IntegrationType = "splunk"
+	// IntegrationDatadog posts logs to the Datadog Logs API
+	IntegrationDatadog IntegrationType = "datadog"
+)
+
+// IntegrationSettings configures the destination of an integration. Index
+// and SourceType apply to Splunk, Site, Service and Tags to Datadog, and URL
+// and Source to both.
+type IntegrationSettings struct {
+	// URL is the base URL of the Splunk HTTP Event Collector, e.g.
+	// https://splunk.example.com:8088, or replaces the Datadog intake of Site,
+	// e.g. with a proxy
+	URL        string `json:"url,omitempty"`
+	Index      string `json:"index,omitempty"`
+	SourceType string `json:"sourcetype,omitempty"`
+	// Site is the Datadog site, e.g. datadoghq.com or datadoghq.eu
+	Site    string   `json:"site,omitempty"`
+	Service string   `json:"service,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+	Source  string   `json:"source,omitempty"`
+}
+
+// IntegrationFilter selects the logs an integration forwards; empty criteria
+// forward every log. Action, ResourceType, Severity and Tags take the
+// ParseValueFilter syntax.
+type IntegrationFilter struct {
+	Action       string `json:"action,omitempty"`
+	ResourceType string `json:"resource_type,omitempty"`
+	Severity     string `json:"severity,omitempty"`
+	Tags         string `json:"tags,omitempty"`
+	// MinThreatScore is 0 to 100, 0 not filtering by threat score
+	MinThreatScore int `json:"min_threat_score,omitempty"`
+}
+
+// AuditLogFilter returns the filter of the tenant's logs the integration forwards
+func (f *IntegrationFilter) AuditLogFilter(tenantID string) AuditLogFilter {
+	return AuditLogFilter{
+		TenantID:       tenantID,
+		Action:         ParseValueFilter(f.Action),
+		ResourceType:   ParseValueFilter(f.ResourceType),
+		Severity:       ParseValueFilter(f.Severity),
+		Tags:           ParseValueFilter(f.Tags),
+		MinThreatScore: f.MinThreatScore,
+	}
+}
+
+// Integration forwards a tenant's logs matching its filter to an external
+// log platform, in the order they were stored. The forwarding worker resumes
+// after the last log delivered, CursorCreatedAt and CursorID, so logs are
+// delivered at least once; failed deliveries are retried at NextAttemptAt,
+// backing off with every consecutive failure, while one that can't succeed
+// until the integration is updated, such as with a revoked token, disables it.
+type Integration struct {
+	ID       string              `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	TenantID string              `gorm:"type:uuid;not null" json:"tenant_id"`
+	Name     string              `gorm:"type:text;not null" json:"name"`
+	Type     IntegrationType     `gorm:"type:text;not null" json:"type"`
+	Enabled  bool                `gorm:"not null" json:"enabled"`
+	Settings IntegrationSettings `gorm:"type:jsonb;serializer:json;not null" json:"settings"`
+	// Token is the HEC token or Datadog API key, which is stored encrypted as
+	// SealedToken and only decrypted by the forwarding worker. TokenHint is
+	// its last four characters.
+	Token       string            `gorm:"-" json:"-"`
+	SealedToken []byte            `gorm:"type:bytea;not null" json:"-"`
+	TokenHint   string            `gorm:"type:text;not null" json:"-"`
+	Filter      IntegrationFilter `gorm:"type:jsonb;serializer:json;not null" json:"filter"`
+
+	CursorCreatedAt time.Time  `gorm:"type:timestamp with time zone;not null" json:"cursor_created_at"`
+	CursorID        string     `gorm:"type:uuid;not null" json:"cursor_id"`
+	ForwardedCount  int64      `gorm:"not null;default:0" json:"forwarded_count"`
+	LastForwardedAt *time.Time `gorm:"type:timestamp with time zone" json:"last_forwarded_at,omitempty"`
+	Failures        int        `gorm:"not null;default:0" json:"failures"`
+	LastError       string     `gorm:"type:text" json:"last_error,omitempty"`
+	NextAttemptAt   *time.Time `gorm:"type:timestamp with time zone" json:"next_attempt_at,omitempty"`
+	// LockedUntil is when the lease of the worker forwarding the integration
+	// ends, or when the last one ended
+	LockedUntil *time.Time `gorm:"type:timestamp with time zone" json:"locked_until,omitempty"`
+
+	CreatedAt time.Time `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+func (Integration) TableName() string {
+	return "integrations"
+}
+
+// Cursor returns the position of the last log delivered
+func (i *Integration) Cursor() *AuditLogCursor {
+	return &AuditLogCursor{Timestamp: i.CursorCreatedAt, ID: i.CursorID}
+}
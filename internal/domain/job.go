@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// JobStatus tracks the lifecycle of an asynchronous job processed by a worker
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "PENDING"
+	JobRunning   JobStatus = "RUNNING"
+	JobCompleted JobStatus = "COMPLETED"
+	JobFailed    JobStatus = "FAILED"
+)
+
+// JobStatuses lists the job statuses
+var JobStatuses = []JobStatus{JobPending, JobRunning, JobCompleted, JobFailed}
+
+// Done reports whether the job has reached a final state
+func (s JobStatus) Done() bool {
+	return s == JobCompleted || s == JobFailed
+}
+
+// JobType is the kind of background operation a Job is
+type JobType string
+
+const (
+	JobTypeExport       JobType = "export"
+	JobTypeTenantExport JobType = "tenant_export"
+	JobTypeRestore      JobType = "restore"
+	JobTypeCleanup      JobType = "cleanup"
+	JobTypeReindex      JobType = "reindex"
+	JobTypeImport       JobType = "import"
+)
+
+// JobTypes lists the job types
+var JobTypes = []JobType{JobTypeExport, JobTypeTenantExport, JobTypeRestore, JobTypeCleanup, JobTypeReindex, JobTypeImport}
+
+// IsValidJobType checks if a job type is valid
+func IsValidJobType(jobType string) bool {
+	return slices.Contains(JobTypes, JobType(jobType))
+}
+
+// IsValidJobStatus checks if a job status is valid
+func IsValidJobStatus(status string) bool {
+	return slices.Contains(JobStatuses, JobStatus(status))
+}
+
+// Job is the common view of the export, restore, cleanup, reindex and import jobs,
+// read from the jobs view over their tables. Counts holds the job type's counters, such
+// as rows for exports or archived, deleted and indexed for cleanups.
+type Job struct {
+	ID          string           `gorm:"primaryKey;type:uuid" json:"id"`
+	TenantID    string           `gorm:"type:uuid" json:"tenant_id"`
+	Type        JobType          `gorm:"type:text" json:"type"`
+	Status      JobStatus        `gorm:"type:text" json:"status"`
+	Counts      map[string]int64 `gorm:"type:jsonb;serializer:json" json:"counts"`
+	Error       string           `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt   time.Time        `gorm:"type:timestamp with time zone" json:"created_at"`
+	UpdatedAt   time.Time        `gorm:"type:timestamp with time zone" json:"updated_at"`
+	CompletedAt *time.Time       `gorm:"type:timestamp with time zone" json:"completed_at,omitempty"`
+}
+
+func (Job) TableName() string {
+	return "jobs"
+}
+
+// JobFilter selects a tenant's jobs, optionally of one type and status
+type JobFilter struct {
+	TenantID string    `json:"tenant_id"`
+	Type     JobType   `json:"type"`
+	Status   JobStatus `json:"status"`
+	Page     int       `json:"page"`
+	PageSize int       `json:"page_size"`
+	Limit    int       `json:"limit"`
+	Offset   int       `json:"offset"`
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxEventType identifies the side effect an outbox event triggers
+type OutboxEventType string
+
+const (
+	// OutboxEventIndex indexes and broadcasts a single audit log
+	OutboxEventIndex OutboxEventType = "INDEX"
+
+	// OutboxEventBulkIndex indexes and broadcasts a batch of audit logs
+	OutboxEventBulkIndex OutboxEventType = "BULK_INDEX"
+)
+
+// OutboxEvent is a side effect recorded in the same transaction as the audit
+// logs it refers to, and published later by the outbox relay
+type OutboxEvent struct {
+	ID             string            `gorm:"primaryKey;type:uuid" json:"id"`
+	TenantID       string            `gorm:"type:uuid;not null" json:"tenant_id"`
+	EventType      OutboxEventType   `gorm:"type:text;not null" json:"event_type"`
+	Payload        json.RawMessage   `gorm:"type:jsonb;not null" json:"payload"`
+	TraceContext   map[string]string `gorm:"type:jsonb;serializer:json" json:"trace_context,omitempty"`
+	RequestID      string            `gorm:"type:text" json:"request_id,omitempty"`
+	Attempts       int               `gorm:"not null;default:0" json:"attempts"`
+	LastError      string            `gorm:"type:text" json:"last_error,omitempty"`
+	LockedUntil    *time.Time        `gorm:"type:timestamp with time zone" json:"locked_until,omitempty"`
+	ProcessedAt    *time.Time        `gorm:"type:timestamp with time zone" json:"processed_at,omitempty"`
+	DeadLetteredAt *time.Time        `gorm:"type:timestamp with time zone" json:"dead_lettered_at,omitempty"`
+	CreatedAt      time.Time         `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// PolicyResource is an API resource that permissions are granted on
+type PolicyResource string
+
+const (
+	PolicyResourceLogs           PolicyResource = "logs"
+	PolicyResourceUsers          PolicyResource = "users"
+	PolicyResourceTenants        PolicyResource = "tenants"
+	PolicyResourcePolicies       PolicyResource = "policies"
+	PolicyResourceRedactionRules PolicyResource = "redaction_rules"
+	PolicyResourceTaggingRules   PolicyResource = "tagging_rules"
+	PolicyResourceSchemas        PolicyResource = "schemas"
+	PolicyResourceSavedSearches  PolicyResource = "saved_searches"
+	PolicyResourceConfig         PolicyResource = "config"
+	PolicyResourceIndexFailures  PolicyResource = "index_failures"
+	PolicyResourceIndices        PolicyResource = "indices"
+	PolicyResourceJobs           PolicyResource = "jobs"
+	PolicyResourceArchives       PolicyResource = "archives"
+	PolicyResourceIntegrations   PolicyResource = "integrations"
+	PolicyResourceAny            PolicyResource = "*"
+)
+
+// PolicyAction is an operation on a resource
+type PolicyAction string
+
+const (
+	PolicyActionRead    PolicyAction = "read"
+	PolicyActionCreate  PolicyAction = "create"
+	PolicyActionUpdate  PolicyAction = "update"
+	PolicyActionDelete  PolicyAction = "delete"
+	PolicyActionExport  PolicyAction = "export"
+	PolicyActionRestore PolicyAction = "restore"
+	PolicyActionImport  PolicyAction = "import"
+	PolicyActionAny     PolicyAction = "*"
+)
+
+type PolicyEffect string
+
+const (
+	PolicyAllow PolicyEffect = "allow"
+	PolicyDeny  PolicyEffect = "deny"
+)
+
+// PolicyScope restricts which records an allowed action applies to
+type PolicyScope string
+
+const (
+	// PolicyScopeAll applies to every record of the tenant
+	PolicyScopeAll PolicyScope = "all"
+	// PolicyScopeOwn applies only to logs whose user_id is the caller's
+	PolicyScopeOwn PolicyScope = "own"
+)
+
+// Policy grants or denies a role an action on a resource within a tenant
+type Policy struct {
+	ID        string         `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	TenantID  string         `gorm:"type:uuid;not null" json:"tenant_id"`
+	Role      string         `gorm:"type:text;not null" json:"role"`
+	Resource  PolicyResource `gorm:"type:text;not null" json:"resource"`
+	Action    PolicyAction   `gorm:"type:text;not null" json:"action"`
+	Effect    PolicyEffect   `gorm:"type:text;not null;default:'allow'" json:"effect"`
+	Scope     PolicyScope    `gorm:"type:text;not null;default:'all'" json:"scope"`
+	CreatedAt time.Time      `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+func (Policy) TableName() string {
+	return "policies"
+}
+
+func (p *Policy) matches(resource PolicyResource, action PolicyAction) bool {
+	return (p.Resource == PolicyResourceAny || p.Resource == resource) &&
+		(p.Action == PolicyActionAny || p.Action == action)
+}
+
+// DefaultPolicies apply to every role a tenant has not defined policies for
+var DefaultPolicies = []Policy{
+	{Role: string(RoleAdmin), Resource: PolicyResourceAny, Action: PolicyActionAny, Effect: PolicyAllow, Scope: PolicyScopeAll},
+
+	{Role: string(RoleUser), Resource: PolicyResourceLogs, Action: PolicyActionRead, Effect: PolicyAllow, Scope: PolicyScopeAll},
+	{Role: string(RoleUser), Resource: PolicyResourceLogs, Action: PolicyActionCreate, Effect: PolicyAllow, Scope: PolicyScopeAll},
+	{Role: string(RoleUser), Resource: PolicyResourceLogs, Action: PolicyActionExport, Effect: PolicyAllow, Scope: PolicyScopeAll},
+	{Role: string(RoleUser), Resource: PolicyResourceSavedSearches, Action: PolicyActionAny, Effect: PolicyAllow, Scope: PolicyScopeAll},
+
+	{Role: string(RoleAuditor), Resource: PolicyResourceLogs, Action: PolicyActionRead, Effect: PolicyAllow, Scope: PolicyScopeAll},
+	{Role: string(RoleAuditor), Resource: PolicyResourceLogs, Action: PolicyActionExport, Effect: PolicyAllow, Scope: PolicyScopeAll},
+	{Role: string(RoleAuditor), Resource: PolicyResourceLogs, Action: PolicyActionDelete, Effect: PolicyAllow, Scope: PolicyScopeAll},
+	{Role: string(RoleAuditor), Resource: PolicyResourceLogs, Action: PolicyActionRestore, Effect: PolicyAllow, Scope: PolicyScopeAll},
+	{Role: string(RoleAuditor), Resource: PolicyResourceSavedSearches, Action: PolicyActionAny, Effect: PolicyAllow, Scope: PolicyScopeAll},
+	{Role: string(RoleAuditor), Resource: PolicyResourceJobs, Action: PolicyActionRead, Effect: PolicyAllow, Scope: PolicyScopeAll},
+	{Role: string(RoleAuditor), Resource: PolicyResourceArchives, Action: PolicyActionRead, Effect: PolicyAllow, Scope: PolicyScopeAll},
+	{Role: string(RoleAuditor), Resource: PolicyResourceArchives, Action: PolicyActionUpdate, Effect: PolicyAllow, Scope: PolicyScopeAll},
+}
+
+// PolicyDecision is the outcome of evaluating policies for a request
+type PolicyDecision struct {
+	Allowed bool
+	Scope   PolicyScope
+}
+
+// EvaluatePolicies decides whether a caller holding roles may perform action on
+// resource. A tenant's policies for a role replace the defaults for that role.
+// Any matching deny wins; otherwise the broadest matching allow applies.
+func EvaluatePolicies(tenantPolicies []Policy, roles []string, resource PolicyResource, action PolicyAction) PolicyDecision {
+	customRoles := make(map[string]bool)
+	for _, p := range tenantPolicies {
+		customRoles[p.Role] = true
+	}
+
+	candidates := make([]Policy, 0, len(tenantPolicies))
+	for _, p := range DefaultPolicies {
+		if !customRoles[p.Role] {
+			candidates = append(candidates, p)
+		}
+	}
+	candidates = append(candidates, tenantPolicies...)
+
+	decision := PolicyDecision{}
+	for i := range candidates {
+		p := &candidates[i]
+		if !slices.Contains(roles, p.Role) || !p.matches(resource, action) {
+			continue
+		}
+		if p.Effect == PolicyDeny {
+			return PolicyDecision{}
+		}
+		if !decision.Allowed || p.Scope == PolicyScopeAll {
+			decision = PolicyDecision{Allowed: true, Scope: p.Scope}
+		}
+	}
+	return decision
+}
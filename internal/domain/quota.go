@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+// Quota names a per-tenant ingestion quota
+type Quota string
+
+const (
+	QuotaDailyLogs    Quota = "daily_logs"
+	QuotaMonthlyLogs  Quota = "monthly_logs"
+	QuotaMonthlyBytes Quota = "monthly_bytes"
+)
+
+const (
+	// ActionQuotaWarning is the action of the synthetic logs warning a tenant
+	// about its usage nearing a quota
+	ActionQuotaWarning ActionType = "QUOTA_WARNING"
+	// QuotaResourceType is the resource type of quota warning logs
+	QuotaResourceType = "quota"
+)
+
+// QuotaWarning reports a tenant's usage of a quota during Period, a UTC day
+// (2006-01-02) or month (2006-01), reaching the warning share of Limit
+type QuotaWarning struct {
+	TenantID string
+	Quota    Quota
+	Period   string
+	Used     int64
+	Limit    int64
+	At       time.Time
+}
+
+// Ratio returns the share of the quota used
+func (w QuotaWarning) Ratio() float64 {
+	return float64(w.Used) / float64(w.Limit)
+}
@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+// RedactionTarget is the audit log JSON field a redaction rule applies to
+type RedactionTarget string
+
+const (
+	RedactionTargetBeforeState RedactionTarget = "before_state"
+	RedactionTargetAfterState  RedactionTarget = "after_state"
+	RedactionTargetMetadata    RedactionTarget = "metadata"
+)
+
+// RedactionMask decides how matched values are masked
+type RedactionMask string
+
+const (
+	// RedactionMaskFull replaces the whole matched value
+	RedactionMaskFull RedactionMask = "full"
+	// RedactionMaskEmail masks the local part of email addresses in matched strings
+	RedactionMaskEmail RedactionMask = "email"
+	// RedactionMaskSSN masks US social security numbers in matched strings, keeping the last four digits
+	RedactionMaskSSN RedactionMask = "ssn"
+	// RedactionMaskCardNumber masks payment card numbers in matched strings, keeping the last four digits
+	RedactionMaskCardNumber RedactionMask = "card_number"
+)
+
+// RedactionRule masks values at Path within Target before a tenant's logs are
+// persisted. Path is a dotted path through nested objects where "*" matches
+// any key or array element; an empty path matches the whole document.
+type RedactionRule struct {
+	ID        string          `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	TenantID  string          `gorm:"type:uuid;not null" json:"tenant_id"`
+	Target    RedactionTarget `gorm:"type:text;not null" json:"target"`
+	Path      string          `gorm:"type:text;not null;default:''" json:"path"`
+	Mask      RedactionMask   `gorm:"type:text;not null" json:"mask"`
+	CreatedAt time.Time       `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time       `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+func (RedactionRule) TableName() string {
+	return "redaction_rules"
+}
@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+// ReindexJob backfills OpenSearch with a tenant's logs from PostgreSQL for a
+// time range, such as after an index was lost or its mapping changed. Logs
+// are indexed in (timestamp, id) order and the job records the last one
+// indexed, so an interrupted job resumes where it stopped.
+type ReindexJob struct {
+	ID              string     `gorm:"primaryKey;type:uuid" json:"id"`
+	TenantID        string     `gorm:"type:uuid;not null" json:"tenant_id"`
+	Status          JobStatus  `gorm:"type:text;not null" json:"status"`
+	StartTime       time.Time  `gorm:"type:timestamp with time zone;not null" json:"start_time"`
+	EndTime         time.Time  `gorm:"type:timestamp with time zone;not null" json:"end_time"`
+	TotalCount      int64      `gorm:"not null;default:0" json:"total_count"`
+	IndexedCount    int64      `gorm:"not null;default:0" json:"indexed_count"`
+	FailedCount     int64      `gorm:"not null;default:0" json:"failed_count"`
+	CursorTimestamp *time.Time `gorm:"type:timestamp with time zone" json:"cursor_timestamp,omitempty"`
+	CursorID        string     `gorm:"type:text" json:"cursor_id,omitempty"`
+	Error           string     `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt       time.Time  `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	CompletedAt     *time.Time `gorm:"type:timestamp with time zone" json:"completed_at,omitempty"`
+}
+
+func (ReindexJob) TableName() string {
+	return "reindex_jobs"
+}
+
+// Cursor returns the position after the last log the job processed, nil
+// before the first batch
+func (j *ReindexJob) Cursor() *AuditLogCursor {
+	if j.CursorTimestamp == nil {
+		return nil
+	}
+	return &AuditLogCursor{Timestamp: *j.CursorTimestamp, ID: j.CursorID}
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// RestoreJob re-imports archived logs from S3 for a tenant and time range.
+// Restored logs are written back to audit_logs and re-indexed in OpenSearch.
+type RestoreJob struct {
+	ID               string     `gorm:"primaryKey;type:uuid" json:"id"`
+	TenantID         string     `gorm:"type:uuid;not null" json:"tenant_id"`
+	Status           JobStatus  `gorm:"type:text;not null" json:"status"`
+	StartTime        time.Time  `gorm:"type:timestamp with time zone;not null" json:"start_time"`
+	EndTime          time.Time  `gorm:"type:timestamp with time zone;not null" json:"end_time"`
+	ObjectsProcessed int        `gorm:"not null;default:0" json:"objects_processed"`
+	RestoredCount    int64      `gorm:"not null;default:0" json:"restored_count"`
+	Error            string     `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt        time.Time  `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt        time.Time  `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	CompletedAt      *time.Time `gorm:"type:timestamp with time zone" json:"completed_at,omitempty"`
+}
+
+func (RestoreJob) TableName() string {
+	return "restore_jobs"
+}
@@ -11,7 +11,7 @@ type RetentionPolicy struct {
 	TenantID    string          `gorm:"type:uuid;not null" json:"tenant_id"`
 	Name        string          `gorm:"type:text;not null" json:"name"`
 	Description string          `gorm:"type:text" json:"description"`
-	Rules       []RetentionRule `gorm:"type:jsonb" json:"rules"`
+	Rules       []RetentionRule `gorm:"type:jsonb;serializer:json" json:"rules"`
 	Enabled     bool            `gorm:"not null;default:true" json:"enabled"`
 	CreatedAt   time.Time       `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt   time.Time       `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
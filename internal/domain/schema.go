@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SchemaMode decides what happens to logs that don't conform to a schema
+type SchemaMode string
+
+const (
+	// SchemaModeReject fails the ingest request of nonconforming logs
+	SchemaModeReject SchemaMode = "reject"
+	// SchemaModeFlag stores nonconforming logs with their violations in schema_errors
+	SchemaModeFlag SchemaMode = "flag"
+)
+
+// ResourceSchema constrains the JSON fields of a tenant's logs of one
+// resource type, keeping the fields searched across them consistent.
+// MetadataSchema applies to metadata and StateSchema to both before_state and
+// after_state; either may be empty to leave its fields unchecked.
+type ResourceSchema struct {
+	ID             string          `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	TenantID       string          `gorm:"type:uuid;not null" json:"tenant_id"`
+	ResourceType   string          `gorm:"type:text;not null" json:"resource_type"`
+	MetadataSchema json.RawMessage `gorm:"type:jsonb" json:"metadata_schema,omitempty"`
+	StateSchema    json.RawMessage `gorm:"type:jsonb" json:"state_schema,omitempty"`
+	Mode           SchemaMode      `gorm:"type:text;not null" json:"mode"`
+	CreatedAt      time.Time       `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt      time.Time       `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+func (ResourceSchema) TableName() string {
+	return "resource_schemas"
+}
@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// SearchIndex is one of a tenant's daily OpenSearch indices
+type SearchIndex struct {
+	Name string
+	// Day is the UTC day the index holds logs for
+	Day       time.Time
+	Docs      int64
+	SizeBytes int64
+	// Health is the index's cluster health: green, yellow or red
+	Health string
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// tagPattern is the form of tags: lowercase words joined by "-", "_", ":" or "."
+var tagPattern = regexp.MustCompile(`^[a-z0-9]+(?:[-_:.][a-z0-9]+)*$`)
+
+// IsTag reports whether tag is a valid tag of at most 64 characters
+func IsTag(tag string) bool {
+	return len(tag) <= 64 && tagPattern.MatchString(tag)
+}
+
+// TaggingRule labels the logs a tenant ingests with Tag, such as
+// "authentication" or "privilege-change", when they match every criterion the
+// rule sets: one of its actions, resource types and severities, and its
+// MessagePattern, a regular expression searched for in the message.
+type TaggingRule struct {
+	ID             string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	TenantID       string    `gorm:"type:uuid;not null" json:"tenant_id"`
+	Tag            string    `gorm:"type:text;not null" json:"tag"`
+	Actions        []string  `gorm:"type:jsonb;serializer:json" json:"actions,omitempty"`
+	ResourceTypes  []string  `gorm:"type:jsonb;serializer:json" json:"resource_types,omitempty"`
+	Severities     []string  `gorm:"type:jsonb;serializer:json" json:"severities,omitempty"`
+	MessagePattern string    `gorm:"type:text;not null;default:''" json:"message_pattern,omitempty"`
+	CreatedAt      time.Time `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+func (TaggingRule) TableName() string {
+	return "tagging_rules"
+}
+
+// MatchesFields reports whether the rule's actions, resource types and
+// severities admit the log, severities ignoring case; its message pattern is
+// left to the caller
+func (r *TaggingRule) MatchesFields(log *AuditLog) bool {
+	return (len(r.Actions) == 0 || slices.Contains(r.Actions, log.Action)) &&
+		(len(r.ResourceTypes) == 0 || slices.Contains(r.ResourceTypes, log.ResourceType)) &&
+		(len(r.Severities) == 0 || slices.ContainsFunc(r.Severities, func(severity string) bool {
+			return strings.EqualFold(severity, log.Severity)
+		}))
+}
+
+// Tags are the labels of a log, stored in a text[] column
+type Tags []string
+
+// Add adds tag unless the tags have it already
+func (t *Tags) Add(tag string) {
+	if !slices.Contains(*t, tag) {
+		*t = append(*t, tag)
+	}
+}
+
+// Value encodes the tags as a PostgreSQL array literal
+func (t Tags) Value() (driver.Value, error) {
+	elements := make([]string, len(t))
+	for i, tag := range t {
+		elements[i] = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(tag) + `"`
+	}
+	return "{" + strings.Join(elements, ",") + "}", nil
+}
+
+// Scan decodes a PostgreSQL array literal of text
+func (t *Tags) Scan(src any) error {
+	var literal string
+	switch v := src.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case string:
+		literal = v
+	case []byte:
+		literal = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Tags", src)
+	}
+	if len(literal) < 2 || literal[0] != '{' || literal[len(literal)-1] != '}' {
+		return fmt.Errorf("invalid array literal %q", literal)
+	}
+
+	tags := Tags{}
+	var element strings.Builder
+	quoted, escaped, inQuotes := false, false, false
+	for _, r := range literal[1 : len(literal)-1] {
+		switch {
+		case escaped:
+			element.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			inQuotes = !inQuotes
+			quoted = true
+		case r == ',' && !inQuotes:
+			tags = append(tags, element.String())
+			element.Reset()
+			quoted = false
+		default:
+			element.WriteRune(r)
+		}
+	}
+	if element.Len() > 0 || quoted {
+		tags = append(tags, element.String())
+	}
+	*t = tags
+	return nil
+}
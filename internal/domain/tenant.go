@@ -1,17 +1,160 @@
 package domain
 
 import (
+	"slices"
 	"time"
 )
 
+// SystemTenantID is the reserved tenant that holds the meta-audit logs: the
+// queries and administrative actions made against this API
+const SystemTenantID = "00000000-0000-0000-0000-000000000000"
+
+// Tenant is an organization whose logs are kept apart from the others. A
+// deleted tenant keeps its row, with DeletedAt set, until PurgeAfter; the purge
+// worker then archives and removes its logs, recording PurgeStartedAt.
 type Tenant struct {
-	ID        string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
-	Name      string    `gorm:"type:text;not null" json:"name"`
-	RateLimit int       `gorm:"not null;default:1000" json:"rate_limit"`
-	CreatedAt time.Time `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
-	UpdatedAt time.Time `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	ID             string         `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	Name           string         `gorm:"type:text;not null" json:"name"`
+	RateLimit      int            `gorm:"not null;default:1000" json:"rate_limit"`
+	RateLimitBurst int            `gorm:"not null;default:0" json:"rate_limit_burst"`
+	Settings       TenantSettings `gorm:"type:jsonb;serializer:json;not null;default:'{}'" json:"-"`
+	CreatedAt      time.Time      `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt      time.Time      `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	DeletedAt      *time.Time     `gorm:"type:timestamp with time zone" json:"deleted_at,omitempty"`
+	PurgeAfter     *time.Time     `gorm:"type:timestamp with time zone" json:"purge_after,omitempty"`
+	PurgeStartedAt *time.Time     `gorm:"type:timestamp with time zone" json:"-"`
 }
 
 func (Tenant) TableName() string {
 	return "tenants"
 }
+
+// Restorable reports whether a deleted tenant is still within its grace period
+func (t *Tenant) Restorable(now time.Time) bool {
+	return t.DeletedAt != nil && t.PurgeStartedAt == nil && t.PurgeAfter != nil && now.Before(*t.PurgeAfter)
+}
+
+// TenantRateLimit is the per-minute request allowance of a tenant. Burst is
+// the number of extra requests tolerated on top of Limit within a window.
+type TenantRateLimit struct {
+	TenantID string `json:"tenant_id"`
+	Limit    int    `json:"limit"`
+	Burst    int    `json:"burst"`
+}
+
+// Allowance returns the total number of requests allowed per window
+func (l *TenantRateLimit) Allowance() int {
+	return l.Limit + l.Burst
+}
+
+// TenantSettings is the configuration a tenant manages itself. Zero values
+// fall back to the global defaults. The rate limit is kept in the tenant's
+// own columns. CustomActions extend the built-in ActionTypes the tenant may
+// log, while AllowedActions restrict them.
+type TenantSettings struct {
+	RetentionDays  int      `json:"retention_days,omitempty"`
+	AllowedActions []string `json:"allowed_actions,omitempty"`
+	CustomActions  []string `json:"custom_actions,omitempty"`
+	// WebhookURL receives notifications such as quota warnings and security
+	// alerts, signed with the first of WebhookSecrets
+	WebhookURL          string   `json:"webhook_url,omitempty"`
+	WebhookSecrets      []string `json:"webhook_secrets,omitempty"`
+	DataResidencyRegion string   `json:"data_residency_region,omitempty"`
+	// LogVisibility is the scope of the logs callers other than admins and
+	// auditors may read and export: all the tenant's logs by default, or only
+	// their own with PolicyScopeOwn
+	LogVisibility PolicyScope `json:"log_visibility,omitempty"`
+	// SamplingRules keep only a share of some logs at ingest. The first rule
+	// matching a log applies; logs matching none are all kept.
+	SamplingRules []SamplingRule `json:"sampling_rules,omitempty"`
+	// UserRateLimits cap the requests per minute each user of the tenant may
+	// make on a rate limit route class (ingest or query), within the tenant's
+	// own limit. Classes without a cap only have the tenant's limit.
+	UserRateLimits map[string]int `json:"user_rate_limits,omitempty"`
+	// IndexedMetadataKeys are the top-level metadata keys of the tenant's logs
+	// that are searchable when OpenSearch maps metadata with the indexed_keys
+	// strategy. Logs indexed before a key is added aren't searchable by it.
+	IndexedMetadataKeys []string `json:"indexed_metadata_keys,omitempty"`
+	// ArchiveStorage overrides how the tenant's archives are stored in S3
+	ArchiveStorage ArchiveStorage `json:"archive_storage"`
+	// Encryption sets the keys the tenant's archives and exports are encrypted with
+	Encryption Encryption `json:"encryption"`
+}
+
+// Encryption is how a tenant's data is encrypted in S3. KMSKeyARN is the
+// customer-managed KMS key archives and exports are encrypted with (SSE-KMS),
+// the global default key when empty; it applies to objects written afterwards.
+// ExportPublicKey is a PEM-encoded RSA public key log exports are additionally
+// encrypted for before upload, so only the holder of the private key can read
+// the downloads.
+type Encryption struct {
+	KMSKeyARN       string `json:"kms_key_arn,omitempty"`
+	ExportPublicKey string `json:"export_public_key,omitempty"`
+}
+
+// ArchiveStorage is how a tenant's archives are stored in S3: the storage
+// class parts are written in, the days after which they move to Glacier, and
+// the S3 Object Lock mode and days retaining each archive. Zero values fall
+// back to the global defaults; changes apply to archives written afterwards,
+// except the Glacier transition, which applies to every archive of the tenant.
+type ArchiveStorage struct {
+	StorageClass     string `json:"storage_class,omitempty"`
+	GlacierAfterDays int    `json:"glacier_after_days,omitempty"`
+	ObjectLockMode   string `json:"object_lock_mode,omitempty"`
+	ObjectLockDays   int    `json:"object_lock_days,omitempty"`
+}
+
+// SamplingRule keeps Rate, between 0 and 1, of the logs with one of Actions
+// and one of Severities. An empty list matches every action or severity.
+type SamplingRule struct {
+	Actions    []string `json:"actions,omitempty"`
+	Severities []string `json:"severities,omitempty"`
+	Rate       float64  `json:"rate"`
+}
+
+// Matches reports whether the rule applies to logs with action and severity
+func (r *SamplingRule) Matches(action, severity string) bool {
+	return (len(r.Actions) == 0 || slices.Contains(r.Actions, action)) &&
+		(len(r.Severities) == 0 || slices.Contains(r.Severities, severity))
+}
+
+// AllowsAction reports whether logs with the action may be ingested. Every
+// action is allowed when the tenant doesn't restrict them.
+func (s *TenantSettings) AllowsAction(action string) bool {
+	return len(s.AllowedActions) == 0 || slices.Contains(s.AllowedActions, action)
+}
+
+// KnowsAction reports whether the action is a built-in ActionType or one of
+// the tenant's custom actions
+func (s *TenantSettings) KnowsAction(action string) bool {
+	return IsActionType(action) || slices.Contains(s.CustomActions, action)
+}
+
+// LimitsToOwnLogs reports whether a caller holding roles may only read and
+// export its own logs. Admins and auditors always see every log of the tenant.
+func (s *TenantSettings) LimitsToOwnLogs(roles []string) bool {
+	return s.LogVisibility == PolicyScopeOwn &&
+		!slices.Contains(roles, string(RoleAdmin)) &&
+		!slices.Contains(roles, string(RoleAuditor))
+}
+
+// SampleRate returns the share of logs with action and severity that are kept
+func (s *TenantSettings) SampleRate(action, severity string) float64 {
+	for i := range s.SamplingRules {
+		if s.SamplingRules[i].Matches(action, severity) {
+			return s.SamplingRules[i].Rate
+		}
+	}
+	return 1
+}
+
+// UserRateLimit returns the per-minute limit of each user on a route class, or
+// 0 when the tenant doesn't cap its users
+func (s *TenantSettings) UserRateLimit(route string) int {
+	return s.UserRateLimits[route]
+}
+
+// Retention returns how long the tenant's logs are kept, or 0 for the default
+func (s *TenantSettings) Retention() time.Duration {
+	return time.Duration(s.RetentionDays) * 24 * time.Hour
+}
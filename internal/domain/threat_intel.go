@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+)
+
+// ThreatScoreKey is the metadata key the threat score of a log's IP address
+// is recorded under, from 1 to 100
+const ThreatScoreKey = "threat_score"
+
+// ThreatScore returns the threat score recorded in the log's metadata, 0 if
+// none is or it isn't a number
+func (l *AuditLog) ThreatScore() int {
+	if len(l.Metadata) == 0 {
+		return 0
+	}
+	var metadata map[string]json.RawMessage
+	if err := json.Unmarshal(l.Metadata, &metadata); err != nil {
+		return 0
+	}
+	var score float64
+	if err := json.Unmarshal(metadata[ThreatScoreKey], &score); err != nil {
+		return 0
+	}
+	return int(score)
+}
+
+// SetThreatScore records score in the log's metadata, which is created if
+// the log has none. It reports false for metadata that isn't a JSON object,
+// which is left as it is.
+func (l *AuditLog) SetThreatScore(score int) bool {
+	metadata := map[string]json.RawMessage{}
+	if len(l.Metadata) > 0 && string(l.Metadata) != "null" {
+		if err := json.Unmarshal(l.Metadata, &metadata); err != nil {
+			return false
+		}
+	}
+	metadata[ThreatScoreKey], _ = json.Marshal(score)
+
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return false
+	}
+	l.Metadata = encoded
+	return true
+}
+
+// RaiseSeverity sets the log's severity to level if it is lower, in the order
+// of SeverityLevels, and reports whether it did
+func (l *AuditLog) RaiseSeverity(level SeverityLevel) bool {
+	current := slices.Index(SeverityLevels, SeverityLevel(strings.ToUpper(l.Severity)))
+	if current >= slices.Index(SeverityLevels, level) {
+		return false
+	}
+	l.Severity = string(level)
+	return true
+}
@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// RefreshToken is the server-side record of an issued refresh token. Tokens
+// are stored by hash; every token rotated from the same login shares a
+// FamilyID so reuse of a rotated token can revoke the whole chain.
+type RefreshToken struct {
+	TokenHash string    `json:"token_hash"`
+	FamilyID  string    `json:"family_id"`
+	UserID    string    `json:"user_id"`
+	TenantID  string    `json:"tenant_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// StreamTicket is a one-time credential for opening a log stream from a
+// browser, which can't send an Authorization header with a WebSocket upgrade.
+// It is stored by hash and carries the identity of the token it was issued for.
+type StreamTicket struct {
+	TicketHash string    `json:"ticket_hash"`
+	TenantID   string    `json:"tenant_id"`
+	UserID     string    `json:"user_id,omitempty"`
+	Roles      []string  `json:"roles"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"database/sql/driver"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// StringArray maps a PostgreSQL text[] column. The pgx driver encodes []string
+// natively on write but returns arrays as their text representation on read.
+type StringArray []string
+
+func (a *StringArray) Scan(src any) error {
+	return pgtype.NewMap().SQLScanner((*[]string)(a)).Scan(src)
+}
+
+func (a StringArray) Value() (driver.Value, error) {
+	if a == nil {
+		return []string{}, nil
+	}
+	return []string(a), nil
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// TenantUsage is the ingestion volume of a tenant over a period
+type TenantUsage struct {
+	// Day is the UTC day the usage was recorded on
+	Day   time.Time `json:"day"`
+	Logs  int64     `json:"logs"`
+	Bytes int64     `json:"bytes"`
+}
@@ -0,0 +1,201 @@
+package ingest
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"strconv"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+// OTLPProtobufContentType is the content type of OTLP/HTTP protobuf requests and responses
+const OTLPProtobufContentType = "application/x-protobuf"
+
+// Attributes mapped to audit log fields. Log record attributes take
+// precedence over resource attributes of the same name.
+const (
+	otlpTenantIDAttr     = "tenant.id"
+	otlpUserIDAttr       = "enduser.id"
+	otlpSessionIDAttr    = "session.id"
+	otlpClientAddrAttr   = "client.address"
+	otlpUserAgentAttr    = "user_agent.original"
+	otlpActionAttr       = "audit.action"
+	otlpResourceTypeAttr = "audit.resource_type"
+	otlpResourceIDAttr   = "audit.resource_id"
+	otlpServiceNameAttr  = "service.name"
+	otlpServiceInstAttr  = "service.instance.id"
+
+	otlpDefaultAction       = "LOG"
+	otlpDefaultResourceType = "otel"
+)
+
+// ConvertOTLPLogs maps the log records of an export request to audit logs of
+// tenantID, the authenticated tenant. Resources whose tenant.id attribute
+// names another tenant are skipped; the number of their records is returned
+// as rejected.
+//
+// The action is the audit.action attribute or the record's event name, the
+// resource type audit.resource_type or service.name and the resource ID
+// audit.resource_id, service.instance.id or service.name. enduser.id,
+// session.id, client.address and user_agent.original fill the user fields.
+// The body becomes the message; attributes, resource attributes, the
+// instrumentation scope and trace context are kept in metadata.
+func ConvertOTLPLogs(req *collogspb.ExportLogsServiceRequest, tenantID string) (logs []dto.CreateAuditLogRequest, rejected int64) {
+	for _, resourceLogs := range req.GetResourceLogs() {
+		resourceAttrs := otlpAttributes(resourceLogs.GetResource().GetAttributes())
+		if resourceTenant, ok := resourceAttrs[otlpTenantIDAttr].(string); ok && resourceTenant != tenantID {
+			for _, scopeLogs := range resourceLogs.GetScopeLogs() {
+				rejected += int64(len(scopeLogs.GetLogRecords()))
+			}
+			continue
+		}
+
+		for _, scopeLogs := range resourceLogs.GetScopeLogs() {
+			scope := map[string]string{
+				"name":    scopeLogs.GetScope().GetName(),
+				"version": scopeLogs.GetScope().GetVersion(),
+			}
+			for _, record := range scopeLogs.GetLogRecords() {
+				logs = append(logs, convertOTLPLogRecord(record, resourceAttrs, scope, tenantID))
+			}
+		}
+	}
+	return logs, rejected
+}
+
+func convertOTLPLogRecord(record *logspb.LogRecord, resourceAttrs map[string]any, scope map[string]string, tenantID string) dto.CreateAuditLogRequest {
+	attrs := otlpAttributes(record.GetAttributes())
+	lookup := func(keys ...string) string {
+		for _, key := range keys {
+			if value, ok := attrs[key].(string); ok && value != "" {
+				return value
+			}
+			if value, ok := resourceAttrs[key].(string); ok && value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	metadata := map[string]any{
+		"source":              "otlp",
+		"attributes":          attrs,
+		"resource_attributes": resourceAttrs,
+		"scope":               scope,
+		"severity_text":       record.GetSeverityText(),
+	}
+	if len(record.GetTraceId()) > 0 {
+		metadata["trace_id"] = hex.EncodeToString(record.GetTraceId())
+	}
+	if len(record.GetSpanId()) > 0 {
+		metadata["span_id"] = hex.EncodeToString(record.GetSpanId())
+	}
+	// otlpValue only yields JSON-safe values, so marshaling cannot fail
+	metadataJSON, _ := json.Marshal(metadata)
+
+	log := dto.CreateAuditLogRequest{
+		TenantID:     tenantID,
+		UserID:       lookup(otlpUserIDAttr),
+		SessionID:    lookup(otlpSessionIDAttr),
+		IPAddress:    lookup(otlpClientAddrAttr),
+		UserAgent:    lookup(otlpUserAgentAttr),
+		Action:       lookup(otlpActionAttr),
+		ResourceType: lookup(otlpResourceTypeAttr, otlpServiceNameAttr),
+		ResourceID:   lookup(otlpResourceIDAttr, otlpServiceInstAttr, otlpServiceNameAttr),
+		Severity:     string(otlpSeverity(record.GetSeverityNumber())),
+		Message:      otlpBody(record.GetBody()),
+		Metadata:     metadataJSON,
+		Timestamp:    otlpTimestamp(record),
+	}
+	if log.Action == "" {
+		log.Action = record.GetEventName()
+	}
+	if log.Action == "" {
+		log.Action = otlpDefaultAction
+	}
+	if log.ResourceType == "" {
+		log.ResourceType = otlpDefaultResourceType
+	}
+	return log
+}
+
+// otlpSeverity maps OTel severity numbers: TRACE, DEBUG and INFO are INFO,
+// WARN is WARNING, ERROR is ERROR and FATAL is CRITICAL
+func otlpSeverity(number logspb.SeverityNumber) domain.SeverityLevel {
+	switch {
+	case number >= logspb.SeverityNumber_SEVERITY_NUMBER_FATAL:
+		return domain.SeverityCritical
+	case number >= logspb.SeverityNumber_SEVERITY_NUMBER_ERROR:
+		return domain.SeverityError
+	case number >= logspb.SeverityNumber_SEVERITY_NUMBER_WARN:
+		return domain.SeverityWarning
+	}
+	return domain.SeverityInfo
+}
+
+// otlpTimestamp prefers the event time, then the time the record was observed
+func otlpTimestamp(record *logspb.LogRecord) time.Time {
+	if nanos := record.GetTimeUnixNano(); nanos > 0 {
+		return time.Unix(0, int64(nanos)).UTC()
+	}
+	if nanos := record.GetObservedTimeUnixNano(); nanos > 0 {
+		return time.Unix(0, int64(nanos)).UTC()
+	}
+	return time.Now().UTC()
+}
+
+// otlpBody returns a string body as is and other bodies as JSON
+func otlpBody(body *commonpb.AnyValue) string {
+	value := otlpValue(body)
+	if s, ok := value.(string); ok {
+		return s
+	}
+	if value == nil {
+		return ""
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+func otlpAttributes(attrs []*commonpb.KeyValue) map[string]any {
+	values := make(map[string]any, len(attrs))
+	for _, attr := range attrs {
+		values[attr.GetKey()] = otlpValue(attr.GetValue())
+	}
+	return values
+}
+
+func otlpValue(value *commonpb.AnyValue) any {
+	switch v := value.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return v.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return v.BoolValue
+	case *commonpb.AnyValue_IntValue:
+		return v.IntValue
+	case *commonpb.AnyValue_DoubleValue:
+		// JSON has no NaN or infinity
+		if math.IsNaN(v.DoubleValue) || math.IsInf(v.DoubleValue, 0) {
+			return strconv.FormatFloat(v.DoubleValue, 'g', -1, 64)
+		}
+		return v.DoubleValue
+	case *commonpb.AnyValue_BytesValue:
+		return v.BytesValue
+	case *commonpb.AnyValue_ArrayValue:
+		values := make([]any, len(v.ArrayValue.GetValues()))
+		for i, item := range v.ArrayValue.GetValues() {
+			values[i] = otlpValue(item)
+		}
+		return values
+	case *commonpb.AnyValue_KvlistValue:
+		return otlpAttributes(v.KvlistValue.GetValues())
+	}
+	return nil
+}
@@ -0,0 +1,306 @@
+package ingest
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+const (
+	transportUDP = "udp"
+	transportTCP = "tcp"
+)
+
+// LogWriter stores ingested logs, e.g. the AuditLogService
+type LogWriter interface {
+	BulkCreate(ctx context.Context, req []dto.CreateAuditLogRequest) error
+}
+
+// SyslogServer receives RFC 5424 messages over UDP and TCP and writes them
+// as audit logs of the tenant their source token belongs to. Messages are
+// batched per tenant and flushed at the batch size or flush interval.
+type SyslogServer struct {
+	writer       LogWriter
+	config       *config.SyslogConfig
+	logger       *logger.Logger
+	udpConn      net.PacketConn
+	tcpListener  net.Listener
+	mu           sync.Mutex
+	conns        map[net.Conn]struct{}
+	batches      map[string][]dto.CreateAuditLogRequest
+	shutdownChan chan struct{}
+	waitGroup    sync.WaitGroup
+}
+
+func NewSyslogServer(writer LogWriter, config *config.SyslogConfig, logger *logger.Logger) *SyslogServer {
+	return &SyslogServer{
+		writer:       writer,
+		config:       config,
+		logger:       logger,
+		conns:        make(map[net.Conn]struct{}),
+		batches:      make(map[string][]dto.CreateAuditLogRequest),
+		shutdownChan: make(chan struct{}),
+	}
+}
+
+func (s *SyslogServer) Start() error {
+	s.logger.Info("Starting Syslog server...")
+
+	if s.config.UDPAddr != "" {
+		conn, err := net.ListenPacket("udp", s.config.UDPAddr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on UDP %s: %w", s.config.UDPAddr, err)
+		}
+		s.udpConn = conn
+		s.logger.Infof("Syslog server listening on UDP %s", conn.LocalAddr())
+	}
+	if s.config.TCPAddr != "" {
+		listener, err := net.Listen("tcp", s.config.TCPAddr)
+		if err != nil {
+			if s.udpConn != nil {
+				s.udpConn.Close()
+			}
+			return fmt.Errorf("failed to listen on TCP %s: %w", s.config.TCPAddr, err)
+		}
+		s.tcpListener = listener
+		s.logger.Infof("Syslog server listening on TCP %s", listener.Addr())
+	}
+
+	if s.udpConn != nil {
+		s.waitGroup.Add(1)
+		go s.serveUDP()
+	}
+	if s.tcpListener != nil {
+		s.waitGroup.Add(1)
+		go s.serveTCP()
+	}
+	s.waitGroup.Add(1)
+	go s.flushLoop()
+
+	return nil
+}
+
+// Stop closes the listeners and open connections, then flushes the pending batches
+func (s *SyslogServer) Stop() {
+	s.logger.Info("Stopping Syslog server...")
+	close(s.shutdownChan)
+
+	if s.udpConn != nil {
+		s.udpConn.Close()
+	}
+	if s.tcpListener != nil {
+		s.tcpListener.Close()
+	}
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.waitGroup.Wait()
+	s.flushAll(context.Background())
+	s.logger.Info("Syslog server stopped")
+}
+
+func (s *SyslogServer) serveUDP() {
+	defer s.waitGroup.Done()
+
+	buf := make([]byte, s.config.MaxMessageSize)
+	for {
+		n, addr, err := s.udpConn.ReadFrom(buf)
+		if err != nil {
+			if s.shuttingDown() {
+				return
+			}
+			s.logger.Errorf("Syslog server failed to read UDP packet: %v", err)
+			continue
+		}
+		s.handleMessage(buf[:n], addr, transportUDP)
+	}
+}
+
+func (s *SyslogServer) serveTCP() {
+	defer s.waitGroup.Done()
+
+	for {
+		conn, err := s.tcpListener.Accept()
+		if err != nil {
+			if s.shuttingDown() {
+				return
+			}
+			s.logger.Errorf("Syslog server failed to accept TCP connection: %v", err)
+			continue
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.waitGroup.Add(1)
+		go s.serveConn(conn)
+	}
+}
+
+// serveConn reads the messages of a TCP connection, framed per RFC 6587 by
+// either an octet count prefix or a trailing newline
+func (s *SyslogServer) serveConn(conn net.Conn) {
+	defer s.waitGroup.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	reader := bufio.NewReaderSize(conn, s.config.MaxMessageSize+1)
+	for {
+		frame, err := s.readFrame(reader)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !s.shuttingDown() {
+				s.logger.Warnf("Syslog server closing connection from %s: %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+		if len(frame) > 0 {
+			s.handleMessage(frame, conn.RemoteAddr(), transportTCP)
+		}
+	}
+}
+
+func (s *SyslogServer) readFrame(reader *bufio.Reader) ([]byte, error) {
+	first, err := reader.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+
+	// Octet counting: "<length> <message>"
+	if first[0] >= '1' && first[0] <= '9' {
+		prefix, err := reader.ReadSlice(' ')
+		if err != nil {
+			return nil, fmt.Errorf("bad octet count: %w", err)
+		}
+		length, err := strconv.Atoi(string(prefix[:len(prefix)-1]))
+		if err != nil {
+			return nil, fmt.Errorf("bad octet count: %w", err)
+		}
+		if length > s.config.MaxMessageSize {
+			return nil, fmt.Errorf("message of %d bytes exceeds the maximum size", length)
+		}
+		frame := make([]byte, length)
+		if _, err := io.ReadFull(reader, frame); err != nil {
+			return nil, err
+		}
+		return frame, nil
+	}
+
+	// Non-transparent framing: messages end with a newline
+	line, err := reader.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		return nil, errors.New("message exceeds the maximum size")
+	}
+	if err != nil && (!errors.Is(err, io.EOF) || len(line) == 0) {
+		return nil, err
+	}
+	frame := make([]byte, len(line))
+	copy(frame, line)
+	return frame, nil
+}
+
+// handleMessage parses and authenticates a message and adds it to its tenant's batch
+func (s *SyslogServer) handleMessage(data []byte, addr net.Addr, transport string) {
+	msg, err := ParseSyslog(data)
+	if err != nil {
+		metrics.SyslogMessagesReceivedTotal.WithLabelValues(transport, "invalid").Inc()
+		s.logger.Warnf("Syslog server dropped message from %s: %v", addr, err)
+		return
+	}
+
+	tenantID, ok := s.config.SourceTokens[msg.SourceToken()]
+	if !ok {
+		metrics.SyslogMessagesReceivedTotal.WithLabelValues(transport, "unauthorized").Inc()
+		s.logger.Warnf("Syslog server dropped message from %s: unknown source token", addr)
+		return
+	}
+
+	req, err := msg.ToCreateAuditLogRequest(tenantID, sourceIP(addr))
+	if err != nil {
+		metrics.SyslogMessagesReceivedTotal.WithLabelValues(transport, "invalid").Inc()
+		s.logger.Warnf("Syslog server dropped message from %s: %v", addr, err)
+		return
+	}
+	metrics.SyslogMessagesReceivedTotal.WithLabelValues(transport, "accepted").Inc()
+
+	s.mu.Lock()
+	s.batches[tenantID] = append(s.batches[tenantID], req)
+	var full []dto.CreateAuditLogRequest
+	if len(s.batches[tenantID]) >= s.config.BatchSize {
+		full = s.batches[tenantID]
+		delete(s.batches, tenantID)
+	}
+	s.mu.Unlock()
+
+	if full != nil {
+		s.flush(context.Background(), tenantID, full)
+	}
+}
+
+func (s *SyslogServer) flushLoop() {
+	defer s.waitGroup.Done()
+
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.shutdownChan:
+			return
+		case <-ticker.C:
+			s.flushAll(context.Background())
+		}
+	}
+}
+
+func (s *SyslogServer) flushAll(ctx context.Context) {
+	s.mu.Lock()
+	batches := s.batches
+	s.batches = make(map[string][]dto.CreateAuditLogRequest)
+	s.mu.Unlock()
+
+	for tenantID, batch := range batches {
+		s.flush(ctx, tenantID, batch)
+	}
+}
+
+func (s *SyslogServer) flush(ctx context.Context, tenantID string, batch []dto.CreateAuditLogRequest) {
+	if err := s.writer.BulkCreate(ctx, batch); err != nil {
+		s.logger.Errorf("Syslog server failed to write %d logs of tenant %s: %v", len(batch), tenantID, err)
+	}
+}
+
+func (s *SyslogServer) shuttingDown() bool {
+	select {
+	case <-s.shutdownChan:
+		return true
+	default:
+		return false
+	}
+}
+
+func sourceIP(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
@@ -0,0 +1,285 @@
+package ingest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+// Audit log fields a shipper template fills
+const (
+	shipperUserID        = "user_id"
+	shipperSessionID     = "session_id"
+	shipperCorrelationID = "correlation_id"
+	shipperIPAddress     = "ip_address"
+	shipperUserAgent     = "user_agent"
+	shipperAction        = "action"
+	shipperResourceType  = "resource_type"
+	shipperResourceID    = "resource_id"
+	shipperSeverity      = "severity"
+	shipperMessage       = "message"
+	shipperTimestamp     = "timestamp"
+
+	shipperDefaultAction = "LOG"
+)
+
+var shipperFields = []string{
+	shipperUserID, shipperSessionID, shipperCorrelationID, shipperIPAddress, shipperUserAgent,
+	shipperAction, shipperResourceType, shipperResourceID, shipperSeverity, shipperMessage, shipperTimestamp,
+}
+
+// shipperTimeLayouts are the string timestamps of Fluent Bit (iso8601 and
+// java_sql_timestamp) and Logstash (@timestamp)
+var shipperTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+}
+
+var ErrInvalidShipperBatch = errors.New("body must be a JSON array of records, a JSON record or NDJSON records")
+
+// builtinShipperTemplates map the records of Fluent Bit's and Logstash's http
+// outputs, including their Kubernetes and ECS fields
+var builtinShipperTemplates = map[string]map[string][]string{
+	"fluentbit": {
+		shipperUserID:        {"user_id", "user.id"},
+		shipperSessionID:     {"session_id"},
+		shipperCorrelationID: {"correlation_id", "trace_id"},
+		shipperIPAddress:     {"ip_address", "client_ip", "source.ip"},
+		shipperUserAgent:     {"user_agent", "user_agent.original"},
+		shipperAction:        {"action", "event.action"},
+		shipperResourceType:  {"resource_type", "kubernetes.labels.app", "kubernetes.container_name"},
+		shipperResourceID:    {"resource_id", "kubernetes.pod_name", "hostname", "host"},
+		shipperSeverity:      {"severity", "level", "log.level"},
+		shipperMessage:       {"message", "log", "msg"},
+		shipperTimestamp:     {"date", "time", "@timestamp", "timestamp"},
+	},
+	"logstash": {
+		shipperUserID:        {"user_id", "user.id", "user.name"},
+		shipperSessionID:     {"session_id"},
+		shipperCorrelationID: {"correlation_id", "trace.id"},
+		shipperIPAddress:     {"ip_address", "client.ip", "source.ip"},
+		shipperUserAgent:     {"user_agent", "user_agent.original"},
+		shipperAction:        {"action", "event.action"},
+		shipperResourceType:  {"resource_type", "service.name", "type"},
+		shipperResourceID:    {"resource_id", "host.name", "host.hostname", "host"},
+		shipperSeverity:      {"severity", "log.level", "level"},
+		shipperMessage:       {"message"},
+		shipperTimestamp:     {"@timestamp", "timestamp"},
+	},
+}
+
+// ShipperTemplate maps the JSON records log shippers send to audit logs.
+// Each audit log field has sources tried in order until one is set: a path
+// into the record, with dots separating the keys of nested objects, or a
+// constant prefixed with =. The action defaults to LOG and the resource type
+// and ID to the template's name; the timestamp to the time of ingestion.
+type ShipperTemplate struct {
+	Name   string
+	Fields map[string][]string
+}
+
+// NewShipperTemplates returns the built-in fluentbit and logstash templates
+// with custom, templates by name mapping fields to their sources, added or
+// replacing the built-ins of the same name
+func NewShipperTemplates(custom map[string]map[string][]string) (map[string]*ShipperTemplate, error) {
+	templates := make(map[string]*ShipperTemplate, len(builtinShipperTemplates)+len(custom))
+	for name, fields := range builtinShipperTemplates {
+		templates[name] = &ShipperTemplate{Name: name, Fields: fields}
+	}
+
+	var errs []error
+	for name, fields := range custom {
+		for field, sources := range fields {
+			if !slices.Contains(shipperFields, field) {
+				errs = append(errs, fmt.Errorf("shipper template %s: unknown field %q, expected one of %s", name, field, strings.Join(shipperFields, ", ")))
+			}
+			if len(sources) == 0 {
+				errs = append(errs, fmt.Errorf("shipper template %s: field %s has no sources", name, field))
+			}
+		}
+		templates[strings.ToLower(name)] = &ShipperTemplate{Name: strings.ToLower(name), Fields: fields}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return templates, nil
+}
+
+// ParseShipperBatch decodes the records of a shipper's request: a JSON array
+// of records (Fluent Bit's json format, Logstash's json_batch), a single
+// record (Logstash's json) or a stream of records (Fluent Bit's json_lines
+// and json_stream). Numbers are kept as json.Number.
+func ParseShipperBatch(body io.Reader) ([]map[string]any, error) {
+	decoder := json.NewDecoder(body)
+	decoder.UseNumber()
+
+	var records []map[string]any
+	for {
+		var value any
+		if err := decoder.Decode(&value); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidShipperBatch, err)
+		}
+
+		batch, ok := value.([]any)
+		if !ok {
+			batch = []any{value}
+		}
+		for _, item := range batch {
+			record, ok := item.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%w: record %d is not a JSON object", ErrInvalidShipperBatch, len(records))
+			}
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+// Convert maps a record to an audit log of tenantID, the authenticated
+// tenant, keeping the record in metadata. It fails for timestamps in no
+// known format.
+func (t *ShipperTemplate) Convert(record map[string]any, tenantID string) (dto.CreateAuditLogRequest, error) {
+	timestamp := time.Now().UTC()
+	if value, ok := t.lookup(record, shipperTimestamp); ok {
+		var err error
+		if timestamp, err = shipperTime(value); err != nil {
+			return dto.CreateAuditLogRequest{}, err
+		}
+	}
+
+	metadata := map[string]any{
+		"source":   "shipper",
+		"template": t.Name,
+		"record":   record,
+	}
+	// Records are decoded JSON, so marshaling cannot fail
+	metadataJSON, _ := json.Marshal(metadata)
+
+	log := dto.CreateAuditLogRequest{
+		TenantID:      tenantID,
+		UserID:        t.lookupString(record, shipperUserID),
+		SessionID:     t.lookupString(record, shipperSessionID),
+		CorrelationID: t.lookupString(record, shipperCorrelationID),
+		IPAddress:     t.lookupString(record, shipperIPAddress),
+		UserAgent:     t.lookupString(record, shipperUserAgent),
+		Action:        t.lookupString(record, shipperAction),
+		ResourceType:  t.lookupString(record, shipperResourceType),
+		ResourceID:    t.lookupString(record, shipperResourceID),
+		Severity:      string(shipperLevel(t.lookupString(record, shipperSeverity))),
+		Message:       t.lookupString(record, shipperMessage),
+		Metadata:      metadataJSON,
+		Timestamp:     timestamp,
+	}
+	if log.Action == "" {
+		log.Action = shipperDefaultAction
+	}
+	if log.ResourceType == "" {
+		log.ResourceType = t.Name
+	}
+	if log.ResourceID == "" {
+		log.ResourceID = t.Name
+	}
+	return log, nil
+}
+
+// lookup returns the first of the field's sources set in the record
+func (t *ShipperTemplate) lookup(record map[string]any, field string) (any, bool) {
+	for _, source := range t.Fields[field] {
+		if constant, ok := strings.CutPrefix(source, "="); ok {
+			return constant, true
+		}
+		if value, ok := recordValue(record, source); ok && value != nil && value != "" {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// lookupString returns the field's value as a string, JSON-encoding objects and arrays
+func (t *ShipperTemplate) lookupString(record map[string]any, field string) string {
+	value, ok := t.lookup(record, field)
+	if !ok {
+		return ""
+	}
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// recordValue resolves a dotted path, preferring keys that contain dots
+// themselves, such as Fluent Bit's log.level, over nested objects
+func recordValue(record map[string]any, path string) (any, bool) {
+	if value, ok := record[path]; ok {
+		return value, true
+	}
+	for i := range len(path) {
+		if path[i] != '.' {
+			continue
+		}
+		if nested, ok := record[path[:i]].(map[string]any); ok {
+			if value, ok := recordValue(nested, path[i+1:]); ok {
+				return value, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// shipperTime parses epoch seconds, with a fraction as Fluent Bit's double
+// dates have, epoch milliseconds and the string formats of shipperTimeLayouts
+func shipperTime(value any) (time.Time, error) {
+	switch v := value.(type) {
+	case json.Number:
+		seconds, err := v.Float64()
+		if err != nil || math.IsInf(seconds, 0) {
+			break
+		}
+		// Dates past 5138 in seconds are milliseconds
+		if seconds > 1e11 {
+			seconds /= 1000
+		}
+		whole, fraction := math.Modf(seconds)
+		return time.Unix(int64(whole), int64(fraction*1e9)).UTC(), nil
+	case string:
+		for _, layout := range shipperTimeLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t.UTC(), nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("timestamp %v is in no known format", value)
+}
+
+// shipperLevel maps the level names of common loggers: fatal, panic,
+// emergency, alert and critical are CRITICAL, error is ERROR, warning is
+// WARNING and anything else INFO
+func shipperLevel(level string) domain.SeverityLevel {
+	switch strings.ToLower(level) {
+	case "fatal", "panic", "emerg", "emergency", "alert", "crit", "critical":
+		return domain.SeverityCritical
+	case "err", "error":
+		return domain.SeverityError
+	case "warn", "warning":
+		return domain.SeverityWarning
+	}
+	return domain.SeverityInfo
+}
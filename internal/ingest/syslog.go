@@ -0,0 +1,303 @@
+package ingest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+const (
+	// syslogNilValue marks an absent header field or structured data
+	syslogNilValue = "-"
+	// syslogAuthSDName is the structured data element carrying the source token,
+	// e.g. [auth@32473 token="..."]
+	syslogAuthSDName = "auth"
+	// syslogDefaultAction and syslogDefaultResourceType stand in for a missing MSGID and APP-NAME
+	syslogDefaultAction       = "SYSLOG"
+	syslogDefaultResourceType = "syslog"
+)
+
+var ErrInvalidSyslog = errors.New("invalid RFC 5424 syslog message")
+
+var syslogFacilities = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// SyslogMessage is a parsed RFC 5424 message
+type SyslogMessage struct {
+	Facility  int
+	Severity  int
+	Timestamp time.Time
+	Hostname  string
+	AppName   string
+	ProcID    string
+	MsgID     string
+	// StructuredData maps SD-IDs to their parameters
+	StructuredData map[string]map[string]string
+	Message        string
+}
+
+// ParseSyslog parses an RFC 5424 message. Header fields set to the NILVALUE
+// are left empty.
+func ParseSyslog(data []byte) (*SyslogMessage, error) {
+	p := &syslogParser{data: data}
+	msg := &SyslogMessage{StructuredData: make(map[string]map[string]string)}
+
+	pri, err := p.priority()
+	if err != nil {
+		return nil, err
+	}
+	msg.Facility, msg.Severity = pri/8, pri%8
+
+	if version, err := p.field(2); err != nil || version != "1" {
+		return nil, fmt.Errorf("%w: unsupported version", ErrInvalidSyslog)
+	}
+
+	timestamp, err := p.headerField(64)
+	if err != nil {
+		return nil, err
+	}
+	if timestamp != "" {
+		if msg.Timestamp, err = time.Parse(time.RFC3339Nano, timestamp); err != nil {
+			return nil, fmt.Errorf("%w: bad timestamp", ErrInvalidSyslog)
+		}
+	}
+
+	for _, header := range []struct {
+		dst    *string
+		maxLen int
+	}{
+		{&msg.Hostname, 255},
+		{&msg.AppName, 48},
+		{&msg.ProcID, 128},
+		{&msg.MsgID, 32},
+	} {
+		if *header.dst, err = p.headerField(header.maxLen); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.structuredData(msg.StructuredData); err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.data) {
+		if p.data[p.pos] != ' ' {
+			return nil, fmt.Errorf("%w: expected space before MSG", ErrInvalidSyslog)
+		}
+		body := bytes.TrimPrefix(p.data[p.pos+1:], []byte("\xEF\xBB\xBF"))
+		msg.Message = strings.TrimRight(string(body), "\r\n")
+	}
+
+	return msg, nil
+}
+
+// SourceToken returns the token parameter of the auth structured data element
+func (m *SyslogMessage) SourceToken() string {
+	for id, params := range m.StructuredData {
+		if name, _, _ := strings.Cut(id, "@"); name == syslogAuthSDName {
+			return params["token"]
+		}
+	}
+	return ""
+}
+
+// SeverityLevel maps syslog severities to audit log severities: emergency,
+// alert and critical are CRITICAL, error is ERROR, warning is WARNING and
+// notice, informational and debug are INFO
+func (m *SyslogMessage) SeverityLevel() domain.SeverityLevel {
+	switch {
+	case m.Severity <= 2:
+		return domain.SeverityCritical
+	case m.Severity == 3:
+		return domain.SeverityError
+	case m.Severity == 4:
+		return domain.SeverityWarning
+	}
+	return domain.SeverityInfo
+}
+
+// ToCreateAuditLogRequest maps the message to a log of tenantID. MSGID becomes
+// the action, APP-NAME the resource type and HOSTNAME, or else the source IP,
+// the resource ID; the remaining header fields and the structured data, minus
+// the source token, are kept in metadata.
+func (m *SyslogMessage) ToCreateAuditLogRequest(tenantID, sourceIP string) (dto.CreateAuditLogRequest, error) {
+	structuredData := make(map[string]map[string]string, len(m.StructuredData))
+	for id, params := range m.StructuredData {
+		if name, _, _ := strings.Cut(id, "@"); name != syslogAuthSDName {
+			structuredData[id] = params
+		}
+	}
+
+	metadata, err := json.Marshal(map[string]any{
+		"source":          "syslog",
+		"facility":        m.facilityName(),
+		"hostname":        m.Hostname,
+		"app_name":        m.AppName,
+		"proc_id":         m.ProcID,
+		"msg_id":          m.MsgID,
+		"structured_data": structuredData,
+	})
+	if err != nil {
+		return dto.CreateAuditLogRequest{}, fmt.Errorf("failed to marshal syslog metadata: %w", err)
+	}
+
+	req := dto.CreateAuditLogRequest{
+		TenantID:     tenantID,
+		IPAddress:    sourceIP,
+		Action:       m.MsgID,
+		ResourceType: m.AppName,
+		ResourceID:   m.Hostname,
+		Severity:     string(m.SeverityLevel()),
+		Message:      m.Message,
+		Metadata:     metadata,
+		Timestamp:    m.Timestamp,
+	}
+	if req.Action == "" {
+		req.Action = syslogDefaultAction
+	}
+	if req.ResourceType == "" {
+		req.ResourceType = syslogDefaultResourceType
+	}
+	if req.ResourceID == "" {
+		req.ResourceID = sourceIP
+	}
+	if req.Timestamp.IsZero() {
+		req.Timestamp = time.Now().UTC()
+	}
+	return req, nil
+}
+
+func (m *SyslogMessage) facilityName() string {
+	if m.Facility < len(syslogFacilities) {
+		return syslogFacilities[m.Facility]
+	}
+	return strconv.Itoa(m.Facility)
+}
+
+type syslogParser struct {
+	data []byte
+	pos  int
+}
+
+func (p *syslogParser) priority() (int, error) {
+	end := bytes.IndexByte(p.data, '>')
+	if len(p.data) < 3 || p.data[0] != '<' || end < 2 || end > 4 {
+		return 0, fmt.Errorf("%w: bad PRI", ErrInvalidSyslog)
+	}
+	pri, err := strconv.Atoi(string(p.data[1:end]))
+	if err != nil || pri > 191 {
+		return 0, fmt.Errorf("%w: bad PRI", ErrInvalidSyslog)
+	}
+	p.pos = end + 1
+	return pri, nil
+}
+
+// field reads up to the next space, consuming it
+func (p *syslogParser) field(maxLen int) (string, error) {
+	end := bytes.IndexByte(p.data[p.pos:], ' ')
+	if end <= 0 || end > maxLen {
+		return "", fmt.Errorf("%w: truncated header", ErrInvalidSyslog)
+	}
+	value := string(p.data[p.pos : p.pos+end])
+	p.pos += end + 1
+	return value, nil
+}
+
+// headerField reads a header field, returning "" for the NILVALUE
+func (p *syslogParser) headerField(maxLen int) (string, error) {
+	value, err := p.field(maxLen)
+	if err != nil || value == syslogNilValue {
+		return "", err
+	}
+	return value, nil
+}
+
+func (p *syslogParser) structuredData(elements map[string]map[string]string) error {
+	if p.pos >= len(p.data) {
+		return fmt.Errorf("%w: missing structured data", ErrInvalidSyslog)
+	}
+	if p.data[p.pos] == '-' {
+		p.pos++
+		return nil
+	}
+
+	for p.pos < len(p.data) && p.data[p.pos] == '[' {
+		p.pos++
+		id, err := p.name("]")
+		if err != nil {
+			return err
+		}
+		params := make(map[string]string)
+		for p.pos < len(p.data) && p.data[p.pos] == ' ' {
+			p.pos++
+			name, err := p.name("=")
+			if err != nil {
+				return err
+			}
+			if p.pos+1 >= len(p.data) || p.data[p.pos] != '=' || p.data[p.pos+1] != '"' {
+				return fmt.Errorf("%w: bad SD-PARAM", ErrInvalidSyslog)
+			}
+			p.pos += 2
+			value, err := p.paramValue()
+			if err != nil {
+				return err
+			}
+			params[name] = value
+		}
+		if p.pos >= len(p.data) || p.data[p.pos] != ']' {
+			return fmt.Errorf("%w: unterminated SD-ELEMENT", ErrInvalidSyslog)
+		}
+		p.pos++
+		elements[id] = params
+	}
+
+	if len(elements) == 0 {
+		return fmt.Errorf("%w: bad structured data", ErrInvalidSyslog)
+	}
+	return nil
+}
+
+// name reads an SD-NAME, which ends at a space or one of the terminators
+func (p *syslogParser) name(terminators string) (string, error) {
+	start := p.pos
+	for p.pos < len(p.data) && p.data[p.pos] != ' ' && !strings.ContainsRune(terminators, rune(p.data[p.pos])) {
+		if p.data[p.pos] == '"' || p.data[p.pos] < 33 || p.data[p.pos] > 126 {
+			return "", fmt.Errorf("%w: bad SD-NAME", ErrInvalidSyslog)
+		}
+		p.pos++
+	}
+	if p.pos == start || p.pos-start > 32 {
+		return "", fmt.Errorf("%w: bad SD-NAME", ErrInvalidSyslog)
+	}
+	return string(p.data[start:p.pos]), nil
+}
+
+// paramValue reads a quoted PARAM-VALUE after its opening quote, unescaping \", \\ and \]
+func (p *syslogParser) paramValue() (string, error) {
+	var value strings.Builder
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return value.String(), nil
+		case c == '\\' && p.pos+1 < len(p.data) && strings.IndexByte(`"\]`, p.data[p.pos+1]) >= 0:
+			value.WriteByte(p.data[p.pos+1])
+			p.pos += 2
+		default:
+			value.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", fmt.Errorf("%w: unterminated PARAM-VALUE", ErrInvalidSyslog)
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"database/sql"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	dbPoolMaxOpenConnections = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "db_pool", "max_open_connections"),
+		"Maximum number of open connections of a database connection pool",
+		[]string{"pool"}, nil)
+	dbPoolOpenConnections = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "db_pool", "open_connections"),
+		"Number of connections open in a database connection pool, in use or idle",
+		[]string{"pool"}, nil)
+	dbPoolInUseConnections = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "db_pool", "in_use_connections"),
+		"Number of connections of a database connection pool in use",
+		[]string{"pool"}, nil)
+	dbPoolIdleConnections = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "db_pool", "idle_connections"),
+		"Number of idle connections of a database connection pool",
+		[]string{"pool"}, nil)
+	dbPoolWaitsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "db_pool", "waits_total"),
+		"Total number of times a query waited for a connection of a database connection pool",
+		[]string{"pool"}, nil)
+	dbPoolWaitSecondsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "db_pool", "wait_seconds_total"),
+		"Total time queries waited for a connection of a database connection pool",
+		[]string{"pool"}, nil)
+)
+
+// dbPoolCollector reports the statistics of database connection pools, read
+// at each scrape
+type dbPoolCollector struct {
+	mu    sync.Mutex
+	pools map[string]*sql.DB
+}
+
+var dbPools = &dbPoolCollector{pools: make(map[string]*sql.DB)}
+
+func init() {
+	prometheus.MustRegister(dbPools)
+}
+
+// ObserveDBPool exports the statistics of the connection pool of db, labelled
+// with its name
+func ObserveDBPool(name string, db *sql.DB) {
+	dbPools.mu.Lock()
+	defer dbPools.mu.Unlock()
+	dbPools.pools[name] = db
+}
+
+func (c *dbPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- dbPoolMaxOpenConnections
+	ch <- dbPoolOpenConnections
+	ch <- dbPoolInUseConnections
+	ch <- dbPoolIdleConnections
+	ch <- dbPoolWaitsTotal
+	ch <- dbPoolWaitSecondsTotal
+}
+
+func (c *dbPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name, db := range c.pools {
+		stats := db.Stats()
+		ch <- prometheus.MustNewConstMetric(dbPoolMaxOpenConnections, prometheus.GaugeValue, float64(stats.MaxOpenConnections), name)
+		ch <- prometheus.MustNewConstMetric(dbPoolOpenConnections, prometheus.GaugeValue, float64(stats.OpenConnections), name)
+		ch <- prometheus.MustNewConstMetric(dbPoolInUseConnections, prometheus.GaugeValue, float64(stats.InUse), name)
+		ch <- prometheus.MustNewConstMetric(dbPoolIdleConnections, prometheus.GaugeValue, float64(stats.Idle), name)
+		ch <- prometheus.MustNewConstMetric(dbPoolWaitsTotal, prometheus.CounterValue, float64(stats.WaitCount), name)
+		ch <- prometheus.MustNewConstMetric(dbPoolWaitSecondsTotal, prometheus.CounterValue, stats.WaitDuration.Seconds(), name)
+	}
+}
@@ -0,0 +1,549 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/kingrain94/audit-log-api/pkg/breaker"
+)
+
+const namespace = "audit_log"
+
+var (
+	// HTTPRequestDuration tracks API latency per route and status code
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency in seconds",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// LogsIngestedTotal counts audit logs persisted per tenant
+	LogsIngestedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logs_ingested_total",
+		Help:      "Number of audit logs ingested",
+	}, []string{"tenant_id"})
+
+	// RateLimitRejectionsTotal counts requests rejected by rate limiting
+	RateLimitRejectionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_rejections_total",
+		Help:      "Number of requests rejected by rate limiting",
+	}, []string{"scope"})
+
+	// WebSocketClients tracks connected WebSocket clients
+	WebSocketClients = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "websocket_clients",
+		Help:      "Number of connected WebSocket clients",
+	})
+
+	// StreamMessagesDroppedTotal counts logs dropped from the queue of a slow
+	// WebSocket or SSE client to make room for newer ones
+	StreamMessagesDroppedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "stream_messages_dropped_total",
+		Help:      "Total number of logs dropped from the queues of slow stream clients",
+	})
+
+	// StreamMessagesRateLimitedTotal counts logs not streamed to admin clients
+	// of several tenants because their tenant exceeded the per-tenant rate cap
+	StreamMessagesRateLimitedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "stream_messages_rate_limited_total",
+		Help:      "Total number of logs not streamed to admin clients over the per-tenant rate cap",
+	})
+
+	// QueueMessagesSentTotal counts messages sent to SQS
+	QueueMessagesSentTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "queue_messages_sent_total",
+		Help:      "Number of messages sent to SQS",
+	}, []string{"queue", "type", "status"})
+
+	// QueueProcessingLag tracks the delay between sending and receiving a message
+	QueueProcessingLag = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "queue_processing_lag_seconds",
+		Help:      "Delay between a message being sent to SQS and received by a worker",
+		Buckets:   []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 300, 900},
+	}, []string{"queue"})
+
+	// WorkerMessagesProcessedTotal counts messages handled by background workers
+	WorkerMessagesProcessedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "worker_messages_processed_total",
+		Help:      "Number of messages processed by background workers",
+	}, []string{"worker", "status"})
+
+	// WorkerProcessingDuration tracks how long workers take per message
+	WorkerProcessingDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "worker_processing_duration_seconds",
+		Help:      "Time spent processing a single message",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"worker"})
+
+	// WorkerBackoffsTotal counts the times a worker backed off because a store reported pressure
+	WorkerBackoffsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "worker_backoffs_total",
+		Help:      "Number of times a worker backed off on OpenSearch or PostgreSQL pressure",
+	}, []string{"worker"})
+
+	// OutboxBroadcastFailuresTotal counts the indexed outbox events whose logs couldn't be broadcast
+	OutboxBroadcastFailuresTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "outbox_broadcast_failures_total",
+		Help:      "Number of outbox events processed without their logs reaching Redis",
+	})
+
+	// OutboxEventsDeadLetteredTotal counts the outbox events given up on after too many attempts
+	OutboxEventsDeadLetteredTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "outbox_events_dead_lettered_total",
+		Help:      "Number of outbox events dead-lettered after failing every attempt",
+	})
+
+	// OpenSearchIndexFailuresTotal counts failed OpenSearch index operations
+	OpenSearchIndexFailuresTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "opensearch_index_failures_total",
+		Help:      "Number of failed OpenSearch index operations",
+	}, []string{"operation"})
+
+	// IndexFailuresSavedTotal counts logs OpenSearch rejected that were stored for reprocessing
+	IndexFailuresSavedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "index_failures_saved_total",
+		Help:      "Number of logs rejected by OpenSearch and stored in index_failures",
+	})
+
+	// AnalyticsInsertFailuresTotal counts failed inserts into the ClickHouse analytics store
+	AnalyticsInsertFailuresTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "analytics_insert_failures_total",
+		Help:      "Number of failed inserts into the analytics store",
+	})
+
+	// SearchFallbacksTotal counts log searches served by PostgreSQL because
+	// OpenSearch failed
+	SearchFallbacksTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "search_fallbacks_total",
+		Help:      "Number of log searches served by PostgreSQL because OpenSearch failed",
+	})
+
+	// AnomaliesDetectedTotal counts anomalies reported by the anomaly worker
+	AnomaliesDetectedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "anomalies_detected_total",
+		Help:      "Number of anomalies detected in tenant activity",
+	}, []string{"tenant_id", "kind"})
+
+	// SyslogMessagesReceivedTotal counts syslog messages by transport and outcome
+	SyslogMessagesReceivedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "syslog_messages_received_total",
+		Help:      "Number of syslog messages received by the ingest listener",
+	}, []string{"transport", "status"})
+
+	// IndexLifecycleActionsTotal counts the OpenSearch index lifecycle actions applied
+	IndexLifecycleActionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "index_lifecycle_actions_total",
+		Help:      "Number of OpenSearch index lifecycle actions applied",
+	}, []string{"action", "status"})
+
+	// PartitionActionsTotal counts the audit_logs partition maintenance actions applied
+	PartitionActionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "partition_actions_total",
+		Help:      "Number of audit_logs partition maintenance actions applied",
+	}, []string{"action", "status"})
+
+	// ScheduledArchivesTotal counts the archive runs the archive scheduler started
+	ScheduledArchivesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "scheduled_archives_total",
+		Help:      "Number of archive runs started by the archive scheduler",
+	}, []string{"status"})
+
+	// ArchiveVerificationsTotal counts the archive verifications by outcome
+	ArchiveVerificationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "archive_verifications_total",
+		Help:      "Number of archive integrity verifications by status",
+	}, []string{"status"})
+
+	// TenantPurgeActionsTotal counts the steps taken to purge deleted tenants
+	TenantPurgeActionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tenant_purge_actions_total",
+		Help:      "Number of deleted tenant purge steps taken",
+	}, []string{"action", "status"})
+
+	// RateLimitExemptionsTotal counts requests that skipped tenant rate limiting as exempt callers
+	RateLimitExemptionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_exemptions_total",
+		Help:      "Number of requests that skipped tenant rate limiting as exempt callers",
+	}, []string{"route"})
+
+	// QuotaRejectionsTotal counts ingest requests rejected for exceeding a tenant quota
+	QuotaRejectionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "quota_rejections_total",
+		Help:      "Number of ingest requests rejected for exceeding a tenant quota",
+	}, []string{"tenant_id", "quota"})
+
+	// QuotaWarningsTotal counts tenants warned about nearing a quota, and warnings that couldn't be recorded
+	QuotaWarningsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "quota_warnings_total",
+		Help:      "Number of warnings about a tenant nearing a quota",
+	}, []string{"tenant_id", "quota", "status"})
+
+	// WebhookDeliveriesTotal counts notifications posted to tenant webhooks
+	WebhookDeliveriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhook_deliveries_total",
+		Help:      "Number of notifications posted to tenant webhooks",
+	}, []string{"event", "status"})
+
+	// LogsSampledOutTotal counts audit logs dropped at ingest by tenant sampling rules
+	LogsSampledOutTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logs_sampled_out_total",
+		Help:      "Number of audit logs dropped at ingest by tenant sampling rules",
+	}, []string{"tenant_id"})
+
+	// MetaAuditFailuresTotal counts meta-audit logs of API calls that couldn't be enqueued
+	MetaAuditFailuresTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "meta_audit_failures_total",
+		Help:      "Number of meta-audit logs of API calls that couldn't be enqueued",
+	})
+
+	// IngestBufferFlushesTotal counts write-behind buffer flushes by what triggered them
+	IngestBufferFlushesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ingest_buffer_flushes_total",
+		Help:      "Number of write-behind buffer flushes",
+	}, []string{"trigger", "status"})
+
+	// IngestBufferFlushSize tracks how many logs a write-behind buffer flush stores
+	IngestBufferFlushSize = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "ingest_buffer_flush_size",
+		Help:      "Number of logs stored per write-behind buffer flush",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+	})
+
+	// IngestBufferFlushDuration tracks how long write-behind buffer flushes take
+	IngestBufferFlushDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "ingest_buffer_flush_duration_seconds",
+		Help:      "Duration of write-behind buffer flushes",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	// IngestBufferPendingLogs tracks the logs waiting in the write-behind buffer
+	IngestBufferPendingLogs = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ingest_buffer_pending_logs",
+		Help:      "Number of logs waiting in the write-behind buffer",
+	})
+
+	// CircuitBreakerState tracks the state of each dependency's circuit breaker:
+	// 0 closed, 1 half-open, 2 open
+	CircuitBreakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "circuit_breaker_state",
+		Help:      "State of the circuit breaker of a dependency: 0 closed, 1 half-open, 2 open",
+	}, []string{"dependency"})
+
+	// DBReaderUp tracks whether each PostgreSQL read replica answered its
+	// last health check
+	DBReaderUp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "db_reader_up",
+		Help:      "Whether a PostgreSQL read replica answered its last health check",
+	}, []string{"reader"})
+
+	// DBReaderFallbacksTotal counts the reads sent to the writer because no
+	// read replica was healthy
+	DBReaderFallbacksTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_reader_fallbacks_total",
+		Help:      "Number of reads sent to the writer because no read replica was healthy",
+	})
+
+	// OpenSearchClusterUp tracks whether each OpenSearch cluster is healthy
+	// enough to serve: 1 when green or yellow, 0 when red or unreachable
+	OpenSearchClusterUp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "opensearch_cluster_up",
+		Help:      "Whether an OpenSearch cluster is green or yellow (1) or red or unreachable (0)",
+	}, []string{"cluster"})
+
+	// CircuitBreakerTransitionsTotal counts the state changes of the circuit breakers
+	CircuitBreakerTransitionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "circuit_breaker_transitions_total",
+		Help:      "Number of state changes of the circuit breaker of a dependency",
+	}, []string{"dependency", "state"})
+
+	// StatsRollupLagSeconds tracks how far the hourly stats trail the logs:
+	// the age of the creation time up to which logs are rolled up
+	StatsRollupLagSeconds = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "stats_rollup_lag_seconds",
+		Help:      "Age of the creation time up to which logs are counted in the hourly stats",
+	})
+
+	// StatsRollupsTotal counts the rollups of the hourly stats by outcome
+	StatsRollupsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "stats_rollups_total",
+		Help:      "Number of rollups of the hourly stats",
+	}, []string{"status"})
+
+	// StatsRollupBucketsTotal counts the tenant hours of hourly stats recomputed
+	StatsRollupBucketsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "stats_rollup_buckets_total",
+		Help:      "Number of tenant hours of hourly stats recomputed",
+	})
+
+	// IPReputationLookupsTotal counts the IP reputation lookups by provider and
+	// result: listed, clean or error
+	IPReputationLookupsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ip_reputation_lookups_total",
+		Help:      "Number of IP reputation lookups of ingested logs' addresses",
+	}, []string{"provider", "result"})
+
+	// ThreatScoredLogsTotal counts the logs enriched with a threat score
+	ThreatScoredLogsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "threat_scored_logs_total",
+		Help:      "Number of ingested logs from IP addresses with a threat score",
+	}, []string{"tenant_id"})
+
+	// BlocklistEntries tracks the addresses and ranges loaded from each blocklist
+	BlocklistEntries = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "blocklist_entries",
+		Help:      "Number of IP addresses and CIDR ranges loaded from a blocklist",
+	}, []string{"source"})
+
+	// BlocklistRefreshesTotal counts the blocklist downloads by outcome
+	BlocklistRefreshesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "blocklist_refreshes_total",
+		Help:      "Number of blocklist downloads",
+	}, []string{"source", "status"})
+
+	// SecurityAlertsTotal counts the alerts raised by the detection rules
+	SecurityAlertsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "security_alerts_total",
+		Help:      "Number of security alerts raised by the detection rules",
+	}, []string{"tenant_id", "rule"})
+
+	// DetectionLogsDroppedTotal counts the broadcast logs the detection worker had no room to evaluate
+	DetectionLogsDroppedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "detection_logs_dropped_total",
+		Help:      "Number of logs dropped unevaluated by the detection worker",
+	})
+
+	// IntegrationDeliveriesTotal counts the batches posted to integrations by outcome
+	IntegrationDeliveriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "integration_deliveries_total",
+		Help:      "Number of log batches posted to Splunk and Datadog integrations",
+	}, []string{"type", "status"})
+
+	// IntegrationDeliveryDuration tracks how long integrations take to accept a batch
+	IntegrationDeliveryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "integration_delivery_duration_seconds",
+		Help:      "Time taken to post a log batch to an integration",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"type"})
+
+	// IntegrationLogsForwardedTotal counts the logs delivered to each tenant's integrations
+	IntegrationLogsForwardedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "integration_logs_forwarded_total",
+		Help:      "Number of logs delivered to Splunk and Datadog integrations",
+	}, []string{"tenant_id", "type"})
+
+	// IntegrationForwardingLag tracks how long ago the last log forwarded by
+	// each integration was stored, while it has more to forward
+	IntegrationForwardingLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "integration_forwarding_lag_seconds",
+		Help:      "Age of the last log an integration forwarded while more are waiting, 0 once caught up",
+	}, []string{"tenant_id", "integration_id"})
+
+	// IntegrationsDisabledTotal counts the integrations disabled after
+	// deliveries that can't succeed until they are updated
+	IntegrationsDisabledTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "integrations_disabled_total",
+		Help:      "Number of integrations disabled after a delivery failing with a non-retryable error",
+	}, []string{"tenant_id", "type"})
+)
+
+// ObserveWorkerMessage records the outcome and duration of a processed message
+func ObserveWorkerMessage(worker string, start time.Time, err error) {
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	WorkerMessagesProcessedTotal.WithLabelValues(worker, status).Inc()
+	WorkerProcessingDuration.WithLabelValues(worker).Observe(time.Since(start).Seconds())
+}
+
+// ObserveIntegrationDelivery records the outcome and duration of a batch posted to an integration
+func ObserveIntegrationDelivery(integrationType string, start time.Time, err error) {
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	IntegrationDeliveriesTotal.WithLabelValues(integrationType, status).Inc()
+	IntegrationDeliveryDuration.WithLabelValues(integrationType).Observe(time.Since(start).Seconds())
+}
+
+// ObserveIndexLifecycleAction records the outcome of an index lifecycle action
+func ObserveIndexLifecycleAction(action string, err error) {
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	IndexLifecycleActionsTotal.WithLabelValues(action, status).Inc()
+}
+
+// ObservePartitionAction records the outcome of a partition maintenance action
+func ObservePartitionAction(action string, err error) {
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	PartitionActionsTotal.WithLabelValues(action, status).Inc()
+}
+
+// ObserveScheduledArchive records the outcome of starting a scheduled archive run
+func ObserveScheduledArchive(err error) {
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	ScheduledArchivesTotal.WithLabelValues(status).Inc()
+}
+
+// ObserveBlocklistRefresh records the outcome of downloading a blocklist and,
+// on success, the number of its entries
+func ObserveBlocklistRefresh(source string, entries int, err error) {
+	status := "success"
+	if err != nil {
+		status = "error"
+	} else {
+		BlocklistEntries.WithLabelValues(source).Set(float64(entries))
+	}
+	BlocklistRefreshesTotal.WithLabelValues(source, status).Inc()
+}
+
+// ObserveTenantPurgeAction records the outcome of a tenant purge step
+func ObserveTenantPurgeAction(action string, err error) {
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	TenantPurgeActionsTotal.WithLabelValues(action, status).Inc()
+}
+
+// ObserveStatsRollup records the outcome of a rollup of the hourly stats and
+// the lag of its watermark
+func ObserveStatsRollup(buckets int64, watermark time.Time, err error) {
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	StatsRollupsTotal.WithLabelValues(status).Inc()
+	StatsRollupBucketsTotal.Add(float64(buckets))
+	if !watermark.IsZero() {
+		StatsRollupLagSeconds.Set(time.Since(watermark).Seconds())
+	}
+}
+
+// ObserveBreakerStateChange records a state change of a dependency's circuit breaker
+func ObserveBreakerStateChange(dependency string, _, to breaker.State) {
+	CircuitBreakerState.WithLabelValues(dependency).Set(float64(to))
+	CircuitBreakerTransitionsTotal.WithLabelValues(dependency, to.String()).Inc()
+}
+
+// Handler returns the HTTP handler exposing all registered metrics
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Server exposes /metrics for processes without an HTTP API (workers)
+type Server struct {
+	srv *http.Server
+	mux *http.ServeMux
+}
+
+func NewServer(addr string) *Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+
+	return &Server{
+		srv: &http.Server{
+			Addr:              addr,
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+		mux: mux,
+	}
+}
+
+// HandleHealth adds /healthz, which answers as long as the process serves, and
+// /readyz, which answers 503 with the error while ready returns one. Call it
+// before Start.
+func (s *Server) HandleHealth(ready func(ctx context.Context) error) {
+	s.mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	})
+	s.mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if err := ready(r.Context()); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	})
+}
+
+// Start serves metrics in the background; errors are reported through onError
+func (s *Server) Start(onError func(error)) {
+	go func() {
+		if err := s.srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			onError(err)
+		}
+	}()
+}
+
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
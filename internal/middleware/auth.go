@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
@@ -8,73 +10,223 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
 	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
 	"github.com/kingrain94/audit-log-api/internal/utils"
 )
 
+// TokenRevocationList reports whether an access token was revoked before it expired
+type TokenRevocationList interface {
+	IsAccessTokenRevoked(ctx context.Context, tokenID string) (bool, error)
+}
+
+// StreamTicketRedeemer consumes one-time stream tickets, returning the claims
+// a ticket authenticates or nil if it is unknown, expired or already used
+type StreamTicketRedeemer interface {
+	RedeemStreamTicket(ctx context.Context, ticket string) (jwt.MapClaims, error)
+}
+
 type AuthMiddleware struct {
-	config *config.Config
+	config      *config.Config
+	revocations TokenRevocationList
+	acceptHMAC  bool
+	oidc        *OIDCVerifier
+	clientCerts *ClientCertAuthenticator
+	tickets     StreamTicketRedeemer
 }
 
-func NewAuthMiddleware(config *config.Config) *AuthMiddleware {
-	return &AuthMiddleware{
-		config: config,
+// NewAuthMiddleware creates the middleware for the configured AUTH_MODE. The
+// OIDC verifier is only set up when identity provider tokens are accepted.
+func NewAuthMiddleware(config *config.Config, oidcConfig *config.OIDCConfig, revocations TokenRevocationList) (*AuthMiddleware, error) {
+	m := &AuthMiddleware{
+		config:      config,
+		revocations: revocations,
+		acceptHMAC:  oidcConfig.AcceptsHMAC(),
 	}
+
+	if oidcConfig.AcceptsOIDC() {
+		verifier, err := NewOIDCVerifier(oidcConfig)
+		if err != nil {
+			return nil, err
+		}
+		m.oidc = verifier
+	}
+
+	return m, nil
 }
 
+// UseClientCertificates lets callers without a bearer token authenticate with
+// a verified client certificate mapped to their tenant
+func (m *AuthMiddleware) UseClientCertificates(clientCerts *ClientCertAuthenticator) {
+	m.clientCerts = clientCerts
+}
+
+// UseStreamTickets lets StreamAuth accept one-time tickets issued by
+// POST /logs/stream/ticket
+func (m *AuthMiddleware) UseStreamTickets(tickets StreamTicketRedeemer) {
+	m.tickets = tickets
+}
+
+// StreamAuth authenticates the opening of a log stream. Browsers can't send an
+// Authorization header with a WebSocket upgrade or an EventSource request, so
+// a one-time ?ticket= is accepted in its place; otherwise it is JWTAuth. The
+// ticket acts for the tenant, user and roles of the token it was issued for.
+func (m *AuthMiddleware) StreamAuth() gin.HandlerFunc {
+	jwtAuth := m.JWTAuth()
+	return func(c *gin.Context) {
+		ticket := c.Query("ticket")
+		if ticket == "" || m.tickets == nil || c.GetHeader("Authorization") != "" {
+			jwtAuth(c)
+			return
+		}
+
+		claims, err := m.tickets.RedeemStreamTicket(c.Request.Context(), ticket)
+		if err != nil {
+			abortWithError(c, http.StatusServiceUnavailable, dto.CodeServiceUnavailable, "Unable to verify stream ticket")
+			return
+		}
+		if claims == nil {
+			abortWithError(c, http.StatusUnauthorized, dto.CodeUnauthorized, "Invalid or expired stream ticket")
+			return
+		}
+
+		c.Set(string(utils.TenantIDKey), claims["tenant_id"])
+		if userID, ok := claims["user_id"].(string); ok {
+			c.Set(string(utils.UserIDKey), userID)
+		}
+		c.Set(string(utils.ClaimsKey), claims)
+		c.Next()
+	}
+}
+
+// JWTAuth authenticates the caller by its bearer token or, when client
+// certificates are accepted and no token is sent, by its client certificate
 func (m *AuthMiddleware) JWTAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" && m.clientCerts != nil {
+			if claims, ok := m.clientCerts.claims(c.Request.TLS); ok {
+				c.Set(string(utils.TenantIDKey), claims["tenant_id"])
+				c.Set(string(utils.ClaimsKey), claims)
+				c.Next()
+				return
+			}
+		}
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
-			c.Abort()
+			abortWithError(c, http.StatusUnauthorized, dto.CodeUnauthorized, "Authorization header is required")
 			return
 		}
 
 		bearerToken := strings.Split(authHeader, " ")
 		if len(bearerToken) != 2 || strings.ToLower(bearerToken[0]) != "bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
-			c.Abort()
+			abortWithError(c, http.StatusUnauthorized, dto.CodeUnauthorized, "Invalid authorization header format")
 			return
 		}
 
-		token := bearerToken[1]
-		claims := jwt.MapClaims{}
-
-		_, err := jwt.ParseWithClaims(token, &claims, func(token *jwt.Token) (any, error) {
-			return []byte(m.config.JWTSecretKey), nil
-		})
-
+		claims, err := m.parseToken(c.Request.Context(), bearerToken[1])
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
-			c.Abort()
+			abortWithError(c, http.StatusUnauthorized, dto.CodeUnauthorized, "Invalid or expired token")
 			return
 		}
 
+		// Tokens issued by /auth/token carry an ID that can be revoked; offline
+		// tokens without one are only bounded by their expiry
+		if jti, ok := claims["jti"].(string); ok && jti != "" {
+			revoked, err := m.revocations.IsAccessTokenRevoked(c.Request.Context(), jti)
+			if err != nil {
+				// Fail closed: a revoked token must not be accepted while Redis is unavailable
+				abortWithError(c, http.StatusServiceUnavailable, dto.CodeServiceUnavailable, "Unable to verify token")
+				return
+			}
+			if revoked {
+				abortWithError(c, http.StatusUnauthorized, dto.CodeUnauthorized, "Token has been revoked")
+				return
+			}
+		}
+
 		// Set claims in context
 		c.Set(string(utils.TenantIDKey), claims["tenant_id"])
+		if userID, ok := claims["user_id"].(string); ok {
+			c.Set(string(utils.UserIDKey), userID)
+		}
 		c.Set(string(utils.ClaimsKey), claims)
 		c.Next()
 	}
 }
 
+// parseToken verifies a token with the shared secret or the identity provider's
+// keys, depending on its signing algorithm and the accepted modes
+func (m *AuthMiddleware) parseToken(ctx context.Context, tokenString string) (jwt.MapClaims, error) {
+	// The algorithm only selects the verifier; the signature is checked below
+	unverified, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	switch unverified.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if !m.acceptHMAC {
+			break
+		}
+		_, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (any, error) {
+			return []byte(m.config.JWTSecretKey), nil
+		}, jwt.WithValidMethods([]string{"HS256", "HS384", "HS512"}))
+		return claims, err
+	case *jwt.SigningMethodRSA:
+		if m.oidc == nil {
+			break
+		}
+		_, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (any, error) {
+			return m.oidc.keyFunc(ctx, token)
+		}, m.oidc.parserOptions()...)
+		if err != nil {
+			return nil, err
+		}
+		return claims, m.oidc.mapClaims(claims)
+	}
+
+	return nil, fmt.Errorf("unsupported signing method %s", unverified.Method.Alg())
+}
+
+// RequirePlatformAdmin lets through admins of the system tenant only, who
+// operate the platform across tenants. Must run after JWTAuth or StreamAuth.
+func (m *AuthMiddleware) RequirePlatformAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := c.Value(string(utils.ClaimsKey)).(jwt.MapClaims)
+		if !ok {
+			abortWithError(c, http.StatusUnauthorized, dto.CodeUnauthorized, "No authentication found")
+			return
+		}
+
+		tenantID, _ := claims["tenant_id"].(string)
+		if tenantID != domain.SystemTenantID || !hasRole(claims, string(domain.RoleAdmin)) {
+			abortWithError(c, http.StatusForbidden, dto.CodeForbidden, "Platform administrator role required")
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // RequireRole middleware checks if the user has the required role
 func (m *AuthMiddleware) RequireRole(role string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		claims, exists := c.Get(string(utils.ClaimsKey))
 		if !exists {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "No authentication found"})
+			abortWithError(c, http.StatusUnauthorized, dto.CodeUnauthorized, "No authentication found")
 			return
 		}
 
 		claimsMap, ok := claims.(jwt.MapClaims)
 		if !ok {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Invalid claims type"})
+			abortWithError(c, http.StatusInternalServerError, dto.CodeInternal, "Invalid claims type")
 			return
 		}
 
 		if !hasRole(claimsMap, role) {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			abortWithError(c, http.StatusForbidden, dto.CodeForbidden, "Insufficient permissions")
 			return
 		}
 
@@ -97,20 +249,26 @@ func (m *AuthMiddleware) GenerateToken(userID, tenantID string, roles []string)
 
 // hasRole checks if the user has the required role
 func hasRole(claims jwt.MapClaims, requiredRole string) bool {
-	rolesInterface, exists := claims["roles"]
-	if !exists {
-		return false
+	for _, role := range claimRoles(claims) {
+		if role == requiredRole {
+			return true
+		}
 	}
+	return false
+}
 
-	roles, ok := rolesInterface.([]any)
+// claimRoles returns the roles claim as strings
+func claimRoles(claims jwt.MapClaims) []string {
+	rolesInterface, ok := claims["roles"].([]any)
 	if !ok {
-		return false
+		return nil
 	}
 
-	for _, role := range roles {
-		if roleStr, ok := role.(string); ok && roleStr == requiredRole {
-			return true
+	roles := make([]string, 0, len(rolesInterface))
+	for _, role := range rolesInterface {
+		if roleStr, ok := role.(string); ok {
+			roles = append(roles, roleStr)
 		}
 	}
-	return false
+	return roles
 }
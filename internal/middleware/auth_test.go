@@ -0,0 +1,280 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+const testIssuer = "https://idp.example.com"
+
+type AuthMiddlewareTestSuite struct {
+	suite.Suite
+	key        *rsa.PrivateKey
+	jwks       *httptest.Server
+	middleware *AuthMiddleware
+}
+
+func (s *AuthMiddlewareTestSuite) SetupSuite() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+	s.key = key
+}
+
+func (s *AuthMiddlewareTestSuite) SetupTest() {
+	s.jwks = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "key1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(s.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.key.E)).Bytes()),
+		}}})
+	}))
+
+	m, err := NewAuthMiddleware(&config.Config{JWTSecretKey: "secret"}, &config.OIDCConfig{
+		Mode:                config.AuthModeOIDC,
+		Issuer:              testIssuer,
+		JWKSURL:             s.jwks.URL,
+		TenantClaim:         "org.tenant",
+		RolesClaim:          "realm_access.roles",
+		RoleMapping:         map[string]string{"audit-admins": "admin"},
+		JWKSRefreshInterval: time.Hour,
+		HTTPTimeout:         time.Second,
+	}, nil)
+	s.Require().NoError(err)
+	s.middleware = m
+}
+
+func (s *AuthMiddlewareTestSuite) TearDownTest() {
+	s.jwks.Close()
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	suite.Run(t, new(AuthMiddlewareTestSuite))
+}
+
+// idpClaims returns the claims of a valid identity provider token
+func idpClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":          testIssuer,
+		"sub":          "user1",
+		"exp":          time.Now().Add(time.Hour).Unix(),
+		"org":          map[string]any{"tenant": "tenant1"},
+		"realm_access": map[string]any{"roles": []any{"audit-admins", "offline_access"}},
+	}
+}
+
+// signRS256 signs claims with the suite's key under kid
+func (s *AuthMiddlewareTestSuite) signRS256(kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(s.key)
+	s.Require().NoError(err)
+	return signed
+}
+
+func (s *AuthMiddlewareTestSuite) TestParseToken_OIDC_MapsNestedClaims() {
+	// Arrange
+	token := s.signRS256("key1", idpClaims())
+
+	// Act
+	claims, err := s.middleware.parseToken(context.Background(), token)
+
+	// Assert
+	s.Require().NoError(err)
+	s.Equal("tenant1", claims["tenant_id"])
+	s.Equal([]any{"admin"}, claims["roles"])
+	s.Equal("user1", claims["user_id"])
+}
+
+func (s *AuthMiddlewareTestSuite) TestParseToken_OIDC_MissingTenantClaim() {
+	// Arrange
+	claims := idpClaims()
+	claims["org"] = map[string]any{"name": "tenant1"}
+	token := s.signRS256("key1", claims)
+
+	// Act
+	_, err := s.middleware.parseToken(context.Background(), token)
+
+	// Assert
+	s.ErrorContains(err, "org.tenant")
+}
+
+func (s *AuthMiddlewareTestSuite) TestParseToken_OIDC_UnknownKeyID() {
+	// Arrange
+	token := s.signRS256("key2", idpClaims())
+
+	// Act
+	_, err := s.middleware.parseToken(context.Background(), token)
+
+	// Assert
+	s.ErrorIs(err, errUnknownSigningKey)
+}
+
+func (s *AuthMiddlewareTestSuite) TestParseToken_OIDC_OtherSigningKey() {
+	// Arrange
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, idpClaims())
+	token.Header["kid"] = "key1"
+	signed, err := token.SignedString(other)
+	s.Require().NoError(err)
+
+	// Act
+	_, err = s.middleware.parseToken(context.Background(), signed)
+
+	// Assert
+	s.ErrorIs(err, jwt.ErrTokenSignatureInvalid)
+}
+
+func (s *AuthMiddlewareTestSuite) TestParseToken_OIDC_RejectsHMACTokens() {
+	// Signing with the identity provider's public key is the classic
+	// algorithm confusion attack; the shared secret isn't accepted either
+	publicKey, err := x509.MarshalPKIXPublicKey(&s.key.PublicKey)
+	s.Require().NoError(err)
+
+	for _, secret := range [][]byte{publicKey, []byte("secret")} {
+		// Arrange
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, idpClaims())
+		token.Header["kid"] = "key1"
+		signed, err := token.SignedString(secret)
+		s.Require().NoError(err)
+
+		// Act
+		claims, err := s.middleware.parseToken(context.Background(), signed)
+
+		// Assert
+		s.ErrorContains(err, "unsupported signing method HS256")
+		s.Nil(claims)
+	}
+}
+
+func (s *AuthMiddlewareTestSuite) TestParseToken_OIDC_WrongIssuer() {
+	// Arrange
+	claims := idpClaims()
+	claims["iss"] = "https://evil.example.com"
+	token := s.signRS256("key1", claims)
+
+	// Act
+	_, err := s.middleware.parseToken(context.Background(), token)
+
+	// Assert
+	s.ErrorIs(err, jwt.ErrTokenInvalidIssuer)
+}
+
+func (s *AuthMiddlewareTestSuite) TestParseToken_OIDC_Expired() {
+	// Arrange
+	claims := idpClaims()
+	claims["exp"] = time.Now().Add(-time.Minute).Unix()
+	token := s.signRS256("key1", claims)
+
+	// Act
+	_, err := s.middleware.parseToken(context.Background(), token)
+
+	// Assert
+	s.ErrorIs(err, jwt.ErrTokenExpired)
+}
+
+func (s *AuthMiddlewareTestSuite) TestParseToken_OIDC_RequiresExpiry() {
+	// Arrange
+	claims := idpClaims()
+	delete(claims, "exp")
+	token := s.signRS256("key1", claims)
+
+	// Act
+	_, err := s.middleware.parseToken(context.Background(), token)
+
+	// Assert
+	s.ErrorIs(err, jwt.ErrTokenRequiredClaimMissing)
+}
+
+// serve runs JWTAuth for a request with the bearer token and returns the
+// response and the tenant it authenticated
+func (s *AuthMiddlewareTestSuite) serve(token string) (*httptest.ResponseRecorder, any) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	var tenantID any
+	router.GET("/logs", s.middleware.JWTAuth(), func(c *gin.Context) {
+		tenantID, _ = c.Get(string(utils.TenantIDKey))
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/logs", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	router.ServeHTTP(w, req)
+	return w, tenantID
+}
+
+func (s *AuthMiddlewareTestSuite) TestJWTAuth_OIDC_SetsMappedTenant() {
+	// Arrange
+	token := s.signRS256("key1", idpClaims())
+
+	// Act
+	w, tenantID := s.serve(token)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("tenant1", tenantID)
+}
+
+func (s *AuthMiddlewareTestSuite) TestJWTAuth_OIDC_HMACTokenUnauthorized() {
+	// Arrange
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"tenant_id": "tenant1",
+		"exp":       time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("secret"))
+	s.Require().NoError(err)
+
+	// Act
+	w, tenantID := s.serve(token)
+
+	// Assert
+	s.Equal(http.StatusUnauthorized, w.Code)
+	s.Nil(tenantID)
+}
+
+func (s *AuthMiddlewareTestSuite) TestParseToken_HMAC_Expired() {
+	// Arrange
+	m, err := NewAuthMiddleware(&config.Config{JWTSecretKey: "secret"}, &config.OIDCConfig{Mode: config.AuthModeHMAC}, nil)
+	s.Require().NoError(err)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"tenant_id": "tenant1",
+		"exp":       time.Now().Add(-time.Minute).Unix(),
+	}).SignedString([]byte("secret"))
+	s.Require().NoError(err)
+
+	// Act
+	_, err = m.parseToken(context.Background(), token)
+
+	// Assert
+	s.ErrorIs(err, jwt.ErrTokenExpired)
+}
+
+func (s *AuthMiddlewareTestSuite) TestParseToken_HMAC_RejectsRS256Tokens() {
+	// Arrange
+	m, err := NewAuthMiddleware(&config.Config{JWTSecretKey: "secret"}, &config.OIDCConfig{Mode: config.AuthModeHMAC}, nil)
+	s.Require().NoError(err)
+	token := s.signRS256("key1", idpClaims())
+
+	// Act
+	_, err = m.parseToken(context.Background(), token)
+
+	// Assert
+	s.ErrorContains(err, "unsupported signing method RS256")
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+)
+
+// ClientCertAuthenticator authenticates callers by the client certificate
+// they presented, acting for the tenant the certificate is mapped to
+type ClientCertAuthenticator struct {
+	tenants map[string]string
+	roles   []any
+}
+
+func NewClientCertAuthenticator(tlsConfig *config.TLSConfig) *ClientCertAuthenticator {
+	roles := make([]any, len(tlsConfig.ClientCertRoles))
+	for i, role := range tlsConfig.ClientCertRoles {
+		roles[i] = role
+	}
+	return &ClientCertAuthenticator{
+		tenants: tlsConfig.ClientCertTenants,
+		roles:   roles,
+	}
+}
+
+// claims returns the claims of the caller behind a verified client
+// certificate, as a token for its tenant would carry them. The certificate's
+// fingerprint is looked up before its common name, so one certificate can be
+// mapped apart from others sharing its name.
+func (a *ClientCertAuthenticator) claims(state *tls.ConnectionState) (jwt.MapClaims, bool) {
+	// Only chains verified against the client CA bundle are trusted
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+	cert := state.VerifiedChains[0][0]
+
+	fingerprint := sha256.Sum256(cert.Raw)
+	tenantID, ok := a.tenants[hex.EncodeToString(fingerprint[:])]
+	if !ok && cert.Subject.CommonName != "" {
+		tenantID, ok = a.tenants[cert.Subject.CommonName]
+	}
+	if !ok {
+		return nil, false
+	}
+
+	return jwt.MapClaims{
+		"sub":       "cert:" + cert.Subject.CommonName,
+		"tenant_id": tenantID,
+		"roles":     a.roles,
+	}, true
+}
@@ -0,0 +1,194 @@
+package middleware
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+type ClientCertTestSuite struct {
+	suite.Suite
+	middleware *AuthMiddleware
+	router     *gin.Engine
+	// tenantID is the tenant the last request was authenticated for
+	tenantID any
+}
+
+func (s *ClientCertTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	m, err := NewAuthMiddleware(&config.Config{JWTSecretKey: "secret"}, &config.OIDCConfig{Mode: config.AuthModeHMAC}, nil)
+	s.Require().NoError(err)
+	s.middleware = m
+	s.useTenants(map[string]string{"billing-service": "tenant1"})
+
+	s.tenantID = nil
+	s.router = gin.New()
+	record := func(c *gin.Context) {
+		s.tenantID, _ = c.Get(string(utils.TenantIDKey))
+		c.Status(http.StatusOK)
+	}
+	s.router.GET("/logs", m.JWTAuth(), record)
+	s.router.DELETE("/tenants/:id", m.JWTAuth(), m.RequirePlatformAdmin(), record)
+}
+
+func TestClientCert(t *testing.T) {
+	suite.Run(t, new(ClientCertTestSuite))
+}
+
+func (s *ClientCertTestSuite) useTenants(tenants map[string]string) {
+	s.middleware.UseClientCertificates(NewClientCertAuthenticator(&config.TLSConfig{
+		ClientCertTenants: tenants,
+		ClientCertRoles:   []string{"user"},
+	}))
+}
+
+// certificate creates a self-signed client certificate for commonName
+func (s *ClientCertTestSuite) certificate(commonName string) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.Require().NoError(err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	s.Require().NoError(err)
+	cert, err := x509.ParseCertificate(der)
+	s.Require().NoError(err)
+	return cert
+}
+
+// serve sends a request over a connection with state and no bearer token
+func (s *ClientCertTestSuite) serve(method, path string, state *tls.ConnectionState) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, nil)
+	req.TLS = state
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func verified(cert *x509.Certificate) *tls.ConnectionState {
+	return &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{cert},
+		VerifiedChains:   [][]*x509.Certificate{{cert}},
+	}
+}
+
+func (s *ClientCertTestSuite) TestJWTAuth_MappedCertificate() {
+	// Arrange
+	cert := s.certificate("billing-service")
+
+	// Act
+	w := s.serve(http.MethodGet, "/logs", verified(cert))
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("tenant1", s.tenantID)
+}
+
+func (s *ClientCertTestSuite) TestJWTAuth_FingerprintTakesPrecedence() {
+	// Arrange
+	cert := s.certificate("billing-service")
+	fingerprint := sha256.Sum256(cert.Raw)
+	s.useTenants(map[string]string{
+		"billing-service":                  "tenant1",
+		hex.EncodeToString(fingerprint[:]): "tenant2",
+	})
+
+	// Act
+	w := s.serve(http.MethodGet, "/logs", verified(cert))
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("tenant2", s.tenantID)
+}
+
+func (s *ClientCertTestSuite) TestJWTAuth_UnmappedCertificateUnauthorized() {
+	// Arrange
+	cert := s.certificate("unknown-service")
+
+	// Act
+	w := s.serve(http.MethodGet, "/logs", verified(cert))
+
+	// Assert
+	s.Equal(http.StatusUnauthorized, w.Code)
+	s.Nil(s.tenantID)
+}
+
+func (s *ClientCertTestSuite) TestJWTAuth_CertificateWithoutCommonNameUnauthorized() {
+	// Arrange
+	s.useTenants(map[string]string{"": "tenant1"})
+	cert := s.certificate("")
+
+	// Act
+	w := s.serve(http.MethodGet, "/logs", verified(cert))
+
+	// Assert
+	s.Equal(http.StatusUnauthorized, w.Code)
+	s.Nil(s.tenantID)
+}
+
+func (s *ClientCertTestSuite) TestJWTAuth_UnverifiedCertificateUnauthorized() {
+	// Arrange
+	cert := s.certificate("billing-service")
+	state := &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+
+	// Act
+	w := s.serve(http.MethodGet, "/logs", state)
+
+	// Assert
+	s.Equal(http.StatusUnauthorized, w.Code)
+	s.Nil(s.tenantID)
+}
+
+func (s *ClientCertTestSuite) TestJWTAuth_CertificateActsForItsOwnTenantOnly() {
+	// Arrange
+	cert := s.certificate("billing-service")
+
+	// Act
+	w := s.serve(http.MethodDelete, "/tenants/tenant2", verified(cert))
+
+	// Assert
+	s.Equal(http.StatusForbidden, w.Code)
+	s.Nil(s.tenantID)
+}
+
+func (s *ClientCertTestSuite) TestJWTAuth_BearerTokenTakesPrecedence() {
+	// Arrange
+	cert := s.certificate("billing-service")
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"tenant_id": "tenant2",
+		"exp":       time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("secret"))
+	s.Require().NoError(err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/logs", nil)
+	req.TLS = verified(cert)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("tenant2", s.tenantID)
+}
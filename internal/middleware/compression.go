@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+)
+
+// Compress compresses responses with gzip or deflate, whichever the client
+// prefers in Accept-Encoding. It suits routes returning large bodies, such as
+// log listings and exports, and must not wrap streaming routes.
+func Compress() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		encoding := acceptedEncoding(c.GetHeader("Accept-Encoding"))
+		c.Header("Vary", "Accept-Encoding")
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		var compressor io.WriteCloser
+		if encoding == "gzip" {
+			compressor, _ = gzip.NewWriterLevel(c.Writer, gzip.DefaultCompression)
+		} else {
+			compressor, _ = zlib.NewWriterLevel(c.Writer, flate.DefaultCompression)
+		}
+
+		c.Header("Content-Encoding", encoding)
+		c.Writer = &compressWriter{ResponseWriter: c.Writer, compressor: compressor}
+		defer compressor.Close()
+
+		c.Next()
+	}
+}
+
+// acceptedEncoding returns the supported encoding the Accept-Encoding header
+// ranks highest, gzip on a tie, or "" if it accepts neither
+func acceptedEncoding(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "gzip" && name != "deflate" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > bestQ || (q == bestQ && name == "gzip") {
+			best, bestQ = name, q
+		}
+	}
+	return best
+}
+
+// compressWriter compresses the body written by handlers
+type compressWriter struct {
+	gin.ResponseWriter
+	compressor io.WriteCloser
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	// The compressed length isn't known up front
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	w.Header().Del("Content-Length")
+	return w.compressor.Write(data)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush sends what was compressed so far, for handlers writing in batches
+func (w *compressWriter) Flush() {
+	if flusher, ok := w.compressor.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// DecompressRequest accepts request bodies compressed with Content-Encoding
+// gzip or deflate, so agents can send compressed batches. maxSize bounds the
+// decompressed body, as the size check of ValidateRequestSize only sees the
+// compressed one.
+func DecompressRequest(maxSize int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			body io.ReadCloser
+			err  error
+		)
+		switch encoding := strings.ToLower(strings.TrimSpace(c.GetHeader("Content-Encoding"))); encoding {
+		case "", "identity":
+			c.Next()
+			return
+		case "gzip":
+			body, err = gzip.NewReader(c.Request.Body)
+		case "deflate":
+			body, err = zlib.NewReader(c.Request.Body)
+		default:
+			abortWithError(c, http.StatusUnsupportedMediaType, dto.CodeUnsupportedMedia, "Unsupported Content-Encoding "+strconv.Quote(encoding))
+			return
+		}
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, dto.CodeValidationFailed, "Invalid compressed request body")
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, body, maxSize)
+		c.Request.Header.Del("Content-Encoding")
+		c.Request.ContentLength = -1
+
+		c.Next()
+	}
+}
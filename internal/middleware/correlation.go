@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kingrain94/audit-log-api/internal/utils"
+)
+
+// CorrelationIDHeader carries the ID shared by the requests of one chain
+const CorrelationIDHeader = "X-Correlation-ID"
+
+// maxClientIDLength bounds correlation and request IDs accepted from clients
+const maxClientIDLength = 128
+
+// CorrelationID reads the request chain ID from X-Correlation-ID, starting a
+// new chain when the header is missing or invalid. The ID is echoed in the
+// response so callers can pass it on, and fills the correlation_id of logs
+// created by the request.
+func CorrelationID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(CorrelationIDHeader)
+		if !validClientID(id) {
+			id = uuid.NewString()
+		}
+
+		c.Set(string(utils.CorrelationIDKey), id)
+		c.Header(CorrelationIDHeader, id)
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("correlation_id", id))
+
+		c.Next()
+	}
+}
+
+// validClientID accepts up to 128 letters, digits and the separators - _ . :
+func validClientID(id string) bool {
+	if id == "" || len(id) > maxClientIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-' || r == '_' || r == '.' || r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/utils"
+)
+
+// WriteError aborts the request with the error response body, tagged with the
+// request's ID so callers can quote it when reporting problems
+func WriteError(c *gin.Context, status int, body dto.Error) {
+	body.RequestID = c.GetString(string(utils.RequestIDKey))
+	c.AbortWithStatusJSON(status, body)
+}
+
+// abortWithError aborts the request with an error response of code
+func abortWithError(c *gin.Context, status int, code, message string) {
+	WriteError(c, status, dto.Error{Code: code, Message: message})
+}
@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// jwksMinRefreshInterval throttles refreshes triggered by unknown key IDs, so
+// tokens with made-up kids can't make every request hit the identity provider
+const jwksMinRefreshInterval = time.Minute
+
+var errUnknownSigningKey = errors.New("unknown signing key")
+
+// jwksCache caches the RSA signing keys of an identity provider by key ID.
+// Keys are refetched after refreshInterval, or earlier when a token names an
+// unknown key after a rotation. If a refresh fails, cached keys keep being
+// served so an identity provider outage doesn't reject valid tokens.
+type jwksCache struct {
+	client          *http.Client
+	issuer          string
+	jwksURL         string
+	refreshInterval time.Duration
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+func newJWKSCache(client *http.Client, issuer, jwksURL string, refreshInterval time.Duration) *jwksCache {
+	return &jwksCache{
+		client:          client,
+		issuer:          issuer,
+		jwksURL:         jwksURL,
+		refreshInterval: refreshInterval,
+		keys:            make(map[string]*rsa.PublicKey),
+	}
+}
+
+// Key returns the public key with the given ID
+func (c *jwksCache) Key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key, ok := c.keys[kid]
+	age := time.Since(c.fetchedAt)
+	if ok && age < c.refreshInterval {
+		return key, nil
+	}
+	if !ok && !c.fetchedAt.IsZero() && age < jwksMinRefreshInterval {
+		return nil, errUnknownSigningKey
+	}
+
+	if err := c.refresh(ctx); err != nil {
+		if ok {
+			return key, nil
+		}
+		return nil, err
+	}
+
+	if key, ok = c.keys[kid]; !ok {
+		return nil, errUnknownSigningKey
+	}
+	return key, nil
+}
+
+func (c *jwksCache) refresh(ctx context.Context) error {
+	if c.jwksURL == "" {
+		jwksURL, err := c.discover(ctx)
+		if err != nil {
+			return err
+		}
+		c.jwksURL = jwksURL
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := c.getJSON(ctx, c.jwksURL, &set); err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		key, err := rsaPublicKey(jwk.N, jwk.E)
+		if err != nil {
+			return fmt.Errorf("invalid JWKS key %s: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+
+	c.keys = keys
+	c.fetchedAt = time.Now()
+	return nil
+}
+
+// discover reads the JWKS URL from the issuer's OpenID Connect discovery document
+func (c *jwksCache) discover(ctx context.Context) (string, error) {
+	var doc struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := c.getJSON(ctx, c.issuer+"/.well-known/openid-configuration", &doc); err != nil {
+		return "", fmt.Errorf("failed to fetch OIDC discovery document: %w", err)
+	}
+	if doc.JWKSURI == "" {
+		return "", errors.New("OIDC discovery document has no jwks_uri")
+	}
+	return doc.JWKSURI, nil
+}
+
+func (c *jwksCache) getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// rsaPublicKey builds a key from the base64url-encoded modulus and exponent of a JWK
+func rsaPublicKey(n, e string) (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %w", err)
+	}
+
+	exponent := new(big.Int).SetBytes(eBytes)
+	if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("exponent too large")
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(exponent.Int64()),
+	}, nil
+}
@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/utils"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+// MetaAuditRecorder enqueues a log for the ingest worker to store
+type MetaAuditRecorder interface {
+	CreateAsync(ctx context.Context, req dto.CreateAuditLogRequest) (string, error)
+}
+
+// metaAuditResourceType is the resource type of meta-audit logs, whose
+// resource ID is the route called
+const metaAuditResourceType = "api_route"
+
+type MetaAuditMiddleware struct {
+	recorder MetaAuditRecorder
+	logger   *logger.Logger
+}
+
+func NewMetaAuditMiddleware(recorder MetaAuditRecorder, logger *logger.Logger) *MetaAuditMiddleware {
+	return &MetaAuditMiddleware{
+		recorder: recorder,
+		logger:   logger,
+	}
+}
+
+// metaAuditMetadata describes the call a meta-audit log records
+type metaAuditMetadata struct {
+	TenantID   string `json:"tenant_id"`
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	Query      string `json:"query,omitempty"`
+	Status     int    `json:"status"`
+	DurationMS int64  `json:"duration_ms"`
+	RequestID  string `json:"request_id,omitempty"`
+}
+
+// Record logs the call, once handled, in the system tenant: who called which
+// route on behalf of which tenant and how it was answered, including calls
+// denied by the policies. Must run after JWTAuth; unauthenticated calls have
+// no caller to record. Logs are enqueued like async ingests, and a log that
+// can't be enqueued is reported without failing the call.
+func (m *MetaAuditMiddleware) Record() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		tenantID := c.GetString(string(utils.TenantIDKey))
+		if tenantID == "" {
+			return
+		}
+		status := c.Writer.Status()
+		userID := c.GetString(string(utils.UserIDKey))
+
+		metadata, err := json.Marshal(metaAuditMetadata{
+			TenantID:   tenantID,
+			Method:     c.Request.Method,
+			Path:       c.Request.URL.Path,
+			Query:      c.Request.URL.RawQuery,
+			Status:     status,
+			DurationMS: time.Since(start).Milliseconds(),
+			RequestID:  utils.RequestIDFromContext(c.Request.Context()),
+		})
+		if err != nil {
+			m.logger.Errorf("Failed to encode meta-audit log: %v", err)
+			return
+		}
+
+		caller := "user " + userID
+		if userID == "" {
+			caller = "a service token"
+		}
+		req := dto.CreateAuditLogRequest{
+			TenantID:      domain.SystemTenantID,
+			UserID:        userID,
+			CorrelationID: c.GetString(string(utils.CorrelationIDKey)),
+			IPAddress:     c.ClientIP(),
+			UserAgent:     truncate(c.Request.UserAgent(), 512),
+			Action:        string(metaAuditAction(c.Request.Method)),
+			ResourceType:  metaAuditResourceType,
+			ResourceID:    c.FullPath(),
+			Severity:      string(metaAuditSeverity(status)),
+			Message:       fmt.Sprintf("%s %s by %s of tenant %s returned %d", c.Request.Method, c.FullPath(), caller, tenantID, status),
+			Metadata:      metadata,
+			Timestamp:     start.UTC(),
+		}
+
+		// The call is answered already; a client going away must not lose its log
+		if _, err := m.recorder.CreateAsync(context.WithoutCancel(c.Request.Context()), req); err != nil {
+			metrics.MetaAuditFailuresTotal.Inc()
+			m.logger.Errorf("Failed to record meta-audit log of %s %s for tenant %s: %v", c.Request.Method, c.FullPath(), tenantID, err)
+		}
+	}
+}
+
+// metaAuditAction maps the method of a call to the action it performs
+func metaAuditAction(method string) domain.ActionType {
+	switch method {
+	case http.MethodPost:
+		return domain.ActionCreate
+	case http.MethodPut, http.MethodPatch:
+		return domain.ActionUpdate
+	case http.MethodDelete:
+		return domain.ActionDelete
+	default:
+		return domain.ActionView
+	}
+}
+
+// metaAuditSeverity flags denied calls as warnings and failed ones as errors
+func metaAuditSeverity(status int) domain.SeverityLevel {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return domain.SeverityError
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return domain.SeverityWarning
+	default:
+		return domain.SeverityInfo
+	}
+}
+
+// truncate cuts s to at most n bytes without splitting a character
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return strings.ToValidUTF8(s[:n], "")
+	}
+	return s
+}
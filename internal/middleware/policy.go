@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/utils"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+// PolicyEvaluator decides whether a caller may perform an action on a resource
+type PolicyEvaluator interface {
+	Evaluate(ctx context.Context, tenantID string, roles []string, resource domain.PolicyResource, action domain.PolicyAction) (domain.PolicyDecision, error)
+}
+
+type PolicyMiddleware struct {
+	policies PolicyEvaluator
+	logger   *logger.Logger
+}
+
+func NewPolicyMiddleware(policies PolicyEvaluator, logger *logger.Logger) *PolicyMiddleware {
+	return &PolicyMiddleware{
+		policies: policies,
+		logger:   logger,
+	}
+}
+
+// Authorize allows the request only if the tenant's policies grant one of the
+// caller's roles the action on the resource. The granted scope is stored in
+// the context so handlers can restrict own-scoped callers to their own logs.
+// Must run after JWTAuth.
+func (m *PolicyMiddleware) Authorize(resource domain.PolicyResource, action domain.PolicyAction) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get(string(utils.ClaimsKey))
+		claims, ok := value.(jwt.MapClaims)
+		if !ok {
+			abortWithError(c, http.StatusUnauthorized, dto.CodeUnauthorized, "No authentication found")
+			return
+		}
+
+		tenantID := c.GetString(string(utils.TenantIDKey))
+		decision, err := m.policies.Evaluate(c.Request.Context(), tenantID, claimRoles(claims), resource, action)
+		if err != nil {
+			m.logger.Errorf("Failed to evaluate policies for tenant %s: %v", tenantID, err)
+			abortWithError(c, http.StatusInternalServerError, dto.CodeInternal, "Failed to evaluate permissions")
+			return
+		}
+
+		// An own-scoped grant is meaningless without a caller identity
+		if !decision.Allowed || (decision.Scope == domain.PolicyScopeOwn && c.GetString(string(utils.UserIDKey)) == "") {
+			abortWithError(c, http.StatusForbidden, dto.CodeForbidden, "Insufficient permissions")
+			return
+		}
+
+		c.Set(string(utils.PolicyScopeKey), string(decision.Scope))
+		c.Next()
+	}
+}
@@ -1,155 +1,275 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/redis/go-redis/v9"
 
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
 	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
 	"github.com/kingrain94/audit-log-api/internal/utils"
 	"github.com/kingrain94/audit-log-api/pkg/logger"
 )
 
+// TenantRateLimitProvider resolves the configured rate limit of a tenant, and
+// its settings holding the limits of its users
+type TenantRateLimitProvider interface {
+	GetRateLimit(ctx context.Context, tenantID string) (*domain.TenantRateLimit, error)
+	ResolveSettings(ctx context.Context, tenantID string) (*domain.TenantSettings, error)
+}
+
 type RateLimitMiddleware struct {
 	redis  *redis.Client
 	config *config.Config
 	logger *logger.Logger
+	limits TenantRateLimitProvider
+
+	// exemptRoles and exemptSubjects skip tenant rate limiting
+	exemptRoles    map[string]struct{}
+	exemptSubjects map[string]struct{}
 }
 
-func NewRateLimitMiddleware(redis *redis.Client, config *config.Config, logger *logger.Logger) *RateLimitMiddleware {
+func NewRateLimitMiddleware(redis *redis.Client, config *config.Config, logger *logger.Logger, limits TenantRateLimitProvider) *RateLimitMiddleware {
 	return &RateLimitMiddleware{
-		redis:  redis,
-		config: config,
-		logger: logger,
+		redis:          redis,
+		config:         config,
+		logger:         logger,
+		limits:         limits,
+		exemptRoles:    stringSet(config.RateLimitExemptRoles),
+		exemptSubjects: stringSet(config.RateLimitExemptSubjects),
 	}
 }
 
-// TenantRateLimit implements per-tenant rate limiting
-func (m *RateLimitMiddleware) TenantRateLimit() gin.HandlerFunc {
+func stringSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		set[value] = struct{}{}
+	}
+	return set
+}
+
+// RateLimitRoute identifies a class of routes, or lane, with its own counters
+// and algorithm, so a burst of queries can't exhaust the budgets of ingestion
+// and the other way round
+type RateLimitRoute string
+
+const (
+	RateLimitIngest RateLimitRoute = "ingest"
+	RateLimitQuery  RateLimitRoute = "query"
+)
+
+// rateLimitWindow is the period tenant and global limits are expressed in
+const rateLimitWindow = time.Minute
+
+// TenantRateLimit implements per-tenant rate limiting for a route class, and
+// per-user rate limiting within the tenant when its settings cap its users, so
+// one noisy account can't exhaust the tenant's budget. Users are checked first
+// so their rejected requests don't count against the tenant. Callers holding
+// an exempt role or authenticated as an exempt subject skip both.
+func (m *RateLimitMiddleware) TenantRateLimit(route RateLimitRoute) gin.HandlerFunc {
+	algorithm := m.routeAlgorithm(route)
+
 	return func(c *gin.Context) {
 		tenantID, err := utils.GetTenantIDFromContext(c.Request.Context())
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Tenant ID required for rate limiting"})
-			c.Abort()
+			abortWithError(c, http.StatusUnauthorized, dto.CodeUnauthorized, "Tenant ID required for rate limiting")
+			return
+		}
+		if m.exempt(c) {
+			metrics.RateLimitExemptionsTotal.WithLabelValues(string(route)).Inc()
+			c.Next()
+			return
+		}
+		if !m.allowUser(c, algorithm, tenantID, route) {
 			return
 		}
 
-		// Get tenant-specific rate limit (default: 1000 requests per minute)
-		limit := m.getTenantRateLimit(tenantID)
+		// Get tenant-specific rate limit and burst allowance
+		limit, burst := m.getTenantRateLimit(c.Request.Context(), tenantID)
 
-		// Create Redis key for this tenant
-		key := fmt.Sprintf("rate_limit:tenant:%s", tenantID)
+		// Create Redis key for this tenant and route class
+		key := fmt.Sprintf("rate_limit:tenant:%s:%s", tenantID, route)
 
-		// Check current request count
-		current, err := m.redis.Get(c.Request.Context(), key).Int()
-		if err != nil && err != redis.Nil {
+		result, err := m.allow(c.Request.Context(), algorithm, key, limit, limit+burst, rateLimitWindow)
+		if err != nil {
 			m.logger.Error("Redis error in rate limiting", err)
 			// Allow request to continue on Redis error (fail open)
 			c.Next()
 			return
 		}
 
-		if current >= limit {
-			c.Header("X-RateLimit-Limit", strconv.Itoa(limit))
-			c.Header("X-RateLimit-Remaining", "0")
-			c.Header("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10))
+		setRateLimitHeaders(c, result)
 
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error": "Rate limit exceeded",
-				"limit": limit,
-				"reset": time.Now().Add(time.Minute).Unix(),
+		if !result.Allowed {
+			metrics.RateLimitRejectionsTotal.WithLabelValues("tenant").Inc()
+			WriteError(c, http.StatusTooManyRequests, dto.Error{
+				Code:    dto.CodeRateLimited,
+				Message: "Rate limit exceeded",
+				Details: gin.H{"limit": result.Limit, "reset": result.Reset.Unix()},
 			})
-			c.Abort()
 			return
 		}
 
-		// Increment counter
-		pipe := m.redis.Pipeline()
-		pipe.Incr(c.Request.Context(), key)
-		pipe.Expire(c.Request.Context(), key, time.Minute)
-		_, err = pipe.Exec(c.Request.Context())
-
-		if err != nil {
-			m.logger.Error("Redis pipeline error in rate limiting", err)
-		}
+		c.Next()
+	}
+}
 
-		// Add rate limit headers
-		remaining := limit - (current + 1)
-		if remaining < 0 {
-			remaining = 0
-		}
+// allowUser counts the request against the caller's limit on the route class
+// when the tenant caps its users, writing an error response and returning
+// false once it's exceeded. Like the tenant limit, it fails open.
+func (m *RateLimitMiddleware) allowUser(c *gin.Context, algorithm RateLimitAlgorithm, tenantID string, route RateLimitRoute) bool {
+	userID := rateLimitUser(c)
+	if userID == "" {
+		return true
+	}
+	settings, err := m.limits.ResolveSettings(c.Request.Context(), tenantID)
+	if err != nil {
+		m.logger.Errorf("Failed to resolve settings of tenant %s: %v", tenantID, err)
+		return true
+	}
+	limit := settings.UserRateLimit(string(route))
+	if limit <= 0 {
+		return true
+	}
 
-		c.Header("X-RateLimit-Limit", strconv.Itoa(limit))
-		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
-		c.Header("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10))
+	key := fmt.Sprintf("rate_limit:tenant:%s:user:%s:%s", tenantID, userID, route)
+	result, err := m.allow(c.Request.Context(), algorithm, key, limit, limit, rateLimitWindow)
+	if err != nil {
+		m.logger.Error("Redis error in user rate limiting", err)
+		return true
+	}
+	if !result.Allowed {
+		setRateLimitHeaders(c, result)
+		metrics.RateLimitRejectionsTotal.WithLabelValues("user").Inc()
+		WriteError(c, http.StatusTooManyRequests, dto.Error{
+			Code:    dto.CodeRateLimited,
+			Message: "User rate limit exceeded",
+			Details: gin.H{"limit": result.Limit, "reset": result.Reset.Unix()},
+		})
+		return false
+	}
+	return true
+}
 
-		c.Next()
+// rateLimitUser returns the user the caller authenticated as: the user_id of
+// its token or, for client certificates, the certificate's subject
+func rateLimitUser(c *gin.Context) string {
+	claims, ok := c.Value(string(utils.ClaimsKey)).(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+	if userID, _ := claims["user_id"].(string); userID != "" {
+		return userID
 	}
+	subject, _ := claims["sub"].(string)
+	return subject
 }
 
-// GlobalRateLimit implements global rate limiting based on IP
-func (m *RateLimitMiddleware) GlobalRateLimit(limit int) gin.HandlerFunc {
+// GlobalRateLimit implements global rate limiting based on IP. Each route
+// class, as picked by classify from the matched route, has its own budget.
+// It runs before authentication, so it can't rely on the tenant.
+func (m *RateLimitMiddleware) GlobalRateLimit(classify func(c *gin.Context) RateLimitRoute) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		clientIP := c.ClientIP()
-		key := fmt.Sprintf("rate_limit:global:%s", clientIP)
+		route := classify(c)
+		key := fmt.Sprintf("rate_limit:global:%s:%s", route, c.ClientIP())
+		limit := m.config.GlobalRateLimit
 
-		// Check current request count
-		current, err := m.redis.Get(c.Request.Context(), key).Int()
-		if err != nil && err != redis.Nil {
+		result, err := m.allow(c.Request.Context(), SlidingWindow, key, limit, limit, rateLimitWindow)
+		if err != nil {
 			m.logger.Error("Redis error in global rate limiting", err)
 			c.Next()
 			return
 		}
 
-		if current >= limit {
-			c.Header("X-RateLimit-Limit", strconv.Itoa(limit))
-			c.Header("X-RateLimit-Remaining", "0")
-			c.Header("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10))
+		setRateLimitHeaders(c, result)
 
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error": "Global rate limit exceeded",
-				"limit": limit,
-				"reset": time.Now().Add(time.Minute).Unix(),
+		if !result.Allowed {
+			metrics.RateLimitRejectionsTotal.WithLabelValues("global").Inc()
+			WriteError(c, http.StatusTooManyRequests, dto.Error{
+				Code:    dto.CodeRateLimited,
+				Message: "Global rate limit exceeded",
+				Details: gin.H{"limit": result.Limit, "reset": result.Reset.Unix()},
 			})
-			c.Abort()
 			return
 		}
 
-		// Increment counter
-		pipe := m.redis.Pipeline()
-		pipe.Incr(c.Request.Context(), key)
-		pipe.Expire(c.Request.Context(), key, time.Minute)
-		_, err = pipe.Exec(c.Request.Context())
+		c.Next()
+	}
+}
 
-		if err != nil {
-			m.logger.Error("Redis pipeline error in global rate limiting", err)
+// exempt reports whether the caller holds a role or is authenticated as a
+// subject exempt from tenant rate limiting, such as an ingest gateway's
+// client certificate (cert:<common name>)
+func (m *RateLimitMiddleware) exempt(c *gin.Context) bool {
+	if len(m.exemptRoles) == 0 && len(m.exemptSubjects) == 0 {
+		return false
+	}
+	claims, ok := c.Value(string(utils.ClaimsKey)).(jwt.MapClaims)
+	if !ok {
+		return false
+	}
+	if subject, _ := claims["sub"].(string); subject != "" {
+		if _, ok := m.exemptSubjects[subject]; ok {
+			return true
 		}
-
-		// Add rate limit headers
-		remaining := limit - (current + 1)
-		if remaining < 0 {
-			remaining = 0
+	}
+	for _, role := range claimRoles(claims) {
+		if _, ok := m.exemptRoles[role]; ok {
+			return true
 		}
+	}
+	return false
+}
+
+// setRateLimitHeaders reports the limiter state; X-RateLimit-Reset is when the
+// full allowance is available again, while Retry-After tells a rejected
+// client how many seconds to wait before its next request can be admitted
+func setRateLimitHeaders(c *gin.Context, result *rateLimitResult) {
+	c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("X-RateLimit-Reset", strconv.FormatInt(result.Reset.Unix(), 10))
+	if !result.Allowed {
+		c.Header("Retry-After", strconv.Itoa(max(int(math.Ceil(result.RetryAfter.Seconds())), 1)))
+	}
+}
 
-		c.Header("X-RateLimit-Limit", strconv.Itoa(limit))
-		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
-		c.Header("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10))
+// routeAlgorithm returns the configured algorithm for a route class
+func (m *RateLimitMiddleware) routeAlgorithm(route RateLimitRoute) RateLimitAlgorithm {
+	algorithm := m.config.QueryRateLimitAlgorithm
+	if route == RateLimitIngest {
+		algorithm = m.config.IngestRateLimitAlgorithm
+	}
 
-		c.Next()
+	switch RateLimitAlgorithm(algorithm) {
+	case SlidingWindow, TokenBucket:
+		return RateLimitAlgorithm(algorithm)
+	default:
+		m.logger.Errorf("Unknown rate limit algorithm %q for %s routes, using %s", algorithm, route, SlidingWindow)
+		return SlidingWindow
 	}
 }
 
-// getTenantRateLimit retrieves the rate limit for a specific tenant
-// In a real implementation, this would query the database
-func (m *RateLimitMiddleware) getTenantRateLimit(tenantID string) int {
-	// TODO: Query tenant table for custom rate limit
-	// For now, return default from config
+// getTenantRateLimit retrieves the per-minute limit and burst for a specific tenant,
+// falling back to the configured default when the tenant limit is unavailable
+func (m *RateLimitMiddleware) getTenantRateLimit(ctx context.Context, tenantID string) (int, int) {
+	limit, err := m.limits.GetRateLimit(ctx, tenantID)
+	if err != nil {
+		m.logger.Errorf("Failed to load rate limit for tenant %s: %v", tenantID, err)
+	} else if limit.Limit > 0 {
+		return limit.Limit, limit.Burst
+	}
+
 	if m.config.DefaultRateLimit > 0 {
-		return m.config.DefaultRateLimit
+		return m.config.DefaultRateLimit, 0
 	}
-	return 1000 // Default: 1000 requests per minute
+	return 1000, 0 // Default: 1000 requests per minute
 }
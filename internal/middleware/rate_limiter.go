@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// RateLimitAlgorithm selects how requests are counted against a limit
+type RateLimitAlgorithm string
+
+const (
+	// SlidingWindow allows at most limit requests in any rolling window,
+	// so there is no burst at fixed window boundaries
+	SlidingWindow RateLimitAlgorithm = "sliding_window"
+
+	// TokenBucket refills limit tokens per window up to a capacity, smoothing
+	// traffic while tolerating short bursts
+	TokenBucket RateLimitAlgorithm = "token_bucket"
+)
+
+// slidingWindowScript keeps one sorted-set member per accepted request, scored
+// by its timestamp in milliseconds. Returns {allowed, remaining, reset_ms}.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', key, window)
+
+local reset = now + window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window
+end
+
+return {allowed, limit - count, reset}
+`)
+
+// tokenBucketScript stores the token count and last refill time in a hash.
+// Returns {allowed, remaining, reset_ms} where reset is when the bucket is full again.
+var tokenBucketScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local capacity = tonumber(ARGV[2])
+local rate = tonumber(ARGV[3])
+
+local state = redis.call('HMGET', key, 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', key, 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', key, math.ceil(capacity / rate))
+
+return {allowed, math.floor(tokens), now + math.ceil((capacity - tokens) / rate)}
+`)
+
+// rateLimitResult is the outcome of a single limiter check
+type rateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	Reset     time.Time
+	// RetryAfter is how long a rejected request should wait for the next one
+	// to be admitted
+	RetryAfter time.Duration
+}
+
+// allow atomically checks and records one request against key.
+// For a token bucket, limit is the refill per window and capacity the bucket size;
+// a sliding window admits capacity requests per window.
+func (m *RateLimitMiddleware) allow(ctx context.Context, algorithm RateLimitAlgorithm, key string, limit, capacity int, window time.Duration) (*rateLimitResult, error) {
+	now := time.Now().UnixMilli()
+
+	var (
+		res []int64
+		err error
+	)
+	switch algorithm {
+	case TokenBucket:
+		rate := float64(limit) / float64(window.Milliseconds())
+		res, err = tokenBucketScript.Run(ctx, m.redis, []string{key}, now, capacity, rate).Int64Slice()
+	case SlidingWindow:
+		res, err = slidingWindowScript.Run(ctx, m.redis, []string{key}, now, window.Milliseconds(), capacity, uuid.New().String()).Int64Slice()
+	default:
+		return nil, fmt.Errorf("unknown rate limit algorithm: %s", algorithm)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to run rate limit script: %w", err)
+	}
+	if len(res) != 3 {
+		return nil, fmt.Errorf("unexpected rate limit script result: %v", res)
+	}
+
+	result := &rateLimitResult{
+		Allowed:   res[0] == 1,
+		Limit:     capacity,
+		Remaining: int(max(res[1], 0)),
+		Reset:     time.UnixMilli(res[2]),
+	}
+	if !result.Allowed {
+		// A sliding window admits a request once its oldest one leaves the
+		// window, which is the reset; a bucket once a single token refilled
+		result.RetryAfter = time.UnixMilli(res[2]).Sub(time.UnixMilli(now))
+		if algorithm == TokenBucket {
+			result.RetryAfter = window / time.Duration(max(limit, 1))
+		}
+	}
+	return result, nil
+}
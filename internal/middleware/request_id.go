@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kingrain94/audit-log-api/internal/utils"
+)
+
+// RequestIDHeader carries the ID of a single API call
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID identifies every API call by the X-Request-ID it was sent with,
+// or a new ID when the header is missing or invalid. The ID is echoed in the
+// response, tags error responses and log lines, travels with the queue
+// messages the call sends and is stored in the metadata of logs it creates.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validClientID(id) {
+			id = uuid.NewString()
+		}
+
+		c.Set(string(utils.RequestIDKey), id)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), utils.RequestIDKey, id))
+		c.Header(RequestIDHeader, id)
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("request_id", id))
+
+		c.Next()
+	}
+}
@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/utils"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+// TenantSettingsProvider resolves the self-service settings of a tenant
+type TenantSettingsProvider interface {
+	ResolveSettings(ctx context.Context, tenantID string) (*domain.TenantSettings, error)
+}
+
+type TenantSettingsMiddleware struct {
+	settings TenantSettingsProvider
+	logger   *logger.Logger
+}
+
+func NewTenantSettingsMiddleware(settings TenantSettingsProvider, logger *logger.Logger) *TenantSettingsMiddleware {
+	return &TenantSettingsMiddleware{
+		settings: settings,
+		logger:   logger,
+	}
+}
+
+// ValidateActions rejects ingest requests carrying a log whose action is
+// neither a built-in domain.ActionType nor a custom action of the tenant,
+// listing each unknown action as a field error, or whose action the tenant
+// doesn't allow. The body, a single log or an array of logs, is restored for
+// the handler. Bodies that don't parse are left for the handler to reject.
+func (m *TenantSettingsMiddleware) ValidateActions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID, err := utils.GetTenantIDFromContext(c.Request.Context())
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		settings, err := m.settings.ResolveSettings(c.Request.Context(), tenantID)
+		if err != nil {
+			m.logger.Errorf("Failed to resolve settings of tenant %s: %v", tenantID, err)
+			// Fail open like the rate limiter, the tenant's defaults allow every action
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			WriteError(c, http.StatusRequestEntityTooLarge, dto.Error{
+				Code:    dto.CodePayloadTooLarge,
+				Message: "Request body too large",
+				Details: gin.H{"max_size": tooLarge.Limit},
+			})
+			return
+		}
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, dto.CodeValidationFailed, "Failed to read request body")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		actions, batch := logActions(body)
+		var unknown []dto.FieldError
+		for i, action := range actions {
+			if action == "" || settings.KnowsAction(action) {
+				// Missing actions fail the handler's binding
+				continue
+			}
+			field := "action"
+			if batch {
+				field = fmt.Sprintf("logs[%d].action", i)
+			}
+			unknown = append(unknown, dto.FieldError{Field: field, Rule: "action"})
+		}
+		if len(unknown) > 0 {
+			WriteError(c, http.StatusBadRequest, dto.Error{
+				Code:    dto.CodeValidationFailed,
+				Message: "Unknown action; use a built-in action or one of the tenant's custom actions",
+				Details: unknown,
+			})
+			return
+		}
+
+		for _, action := range actions {
+			if !settings.AllowsAction(action) {
+				abortWithError(c, http.StatusForbidden, dto.CodeForbidden, fmt.Sprintf("Action %q is not allowed for this tenant", action))
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// logActions returns the actions of the logs in an ingest body, and whether
+// it is an array of logs
+func logActions(body []byte) ([]string, bool) {
+	type actionOnly struct {
+		Action string `json:"action"`
+	}
+
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var logs []actionOnly
+		if err := json.Unmarshal(trimmed, &logs); err != nil {
+			return nil, true
+		}
+		actions := make([]string, len(logs))
+		for i, log := range logs {
+			actions[i] = log.Action
+		}
+		return actions, true
+	}
+
+	var log actionOnly
+	if err := json.Unmarshal(trimmed, &log); err != nil {
+		return nil, false
+	}
+	return []string{log.Action}, false
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Timeout bounds each request by the duration timeout picks for it, setting
+// the deadline of its context so the database and search queries it runs are
+// cancelled once it passes. A duration of 0 leaves the request unbounded, as
+// streams must be.
+func Timeout(timeout func(c *gin.Context) time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		d := timeout(c)
+		if d <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
 	"github.com/kingrain94/audit-log-api/pkg/logger"
 )
 
@@ -69,8 +70,7 @@ func (m *ValidationMiddleware) ValidateContentType(allowedTypes ...string) gin.H
 
 		contentType := c.GetHeader("Content-Type")
 		if contentType == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Content-Type header is required"})
-			c.Abort()
+			abortWithError(c, http.StatusBadRequest, dto.CodeValidationFailed, "Content-Type header is required")
 			return
 		}
 
@@ -87,11 +87,11 @@ func (m *ValidationMiddleware) ValidateContentType(allowedTypes ...string) gin.H
 		}
 
 		if !allowed {
-			c.JSON(http.StatusUnsupportedMediaType, gin.H{
-				"error":         "Unsupported Content-Type",
-				"allowed_types": allowedTypes,
+			WriteError(c, http.StatusUnsupportedMediaType, dto.Error{
+				Code:    dto.CodeUnsupportedMedia,
+				Message: "Unsupported Content-Type",
+				Details: gin.H{"allowed_types": allowedTypes},
 			})
-			c.Abort()
 			return
 		}
 
@@ -103,12 +103,11 @@ func (m *ValidationMiddleware) ValidateContentType(allowedTypes ...string) gin.H
 func (m *ValidationMiddleware) ValidateRequestSize(maxSize int64) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if c.Request.ContentLength > maxSize {
-			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
-				"error":         "Request body too large",
-				"max_size":      maxSize,
-				"received_size": c.Request.ContentLength,
+			WriteError(c, http.StatusRequestEntityTooLarge, dto.Error{
+				Code:    dto.CodePayloadTooLarge,
+				Message: "Request body too large",
+				Details: gin.H{"max_size": maxSize, "received_size": c.Request.ContentLength},
 			})
-			c.Abort()
 			return
 		}
 
@@ -167,8 +166,7 @@ func (m *ValidationMiddleware) BlockSuspiciousPatterns() gin.HandlerFunc {
 			m.logger.Warn("Blocked suspicious request",
 				zap.String("path", c.Request.URL.Path),
 				zap.String("ip", c.ClientIP()))
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
-			c.Abort()
+			abortWithError(c, http.StatusBadRequest, dto.CodeValidationFailed, "Invalid request")
 			return
 		}
 
@@ -180,8 +178,7 @@ func (m *ValidationMiddleware) BlockSuspiciousPatterns() gin.HandlerFunc {
 						zap.String("key", key),
 						zap.String("value", value),
 						zap.String("ip", c.ClientIP()))
-					c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
-					c.Abort()
+					abortWithError(c, http.StatusBadRequest, dto.CodeValidationFailed, "Invalid request")
 					return
 				}
 			}
@@ -198,8 +195,7 @@ func (m *ValidationMiddleware) BlockSuspiciousPatterns() gin.HandlerFunc {
 						zap.String("key", key),
 						zap.String("value", value),
 						zap.String("ip", c.ClientIP()))
-					c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
-					c.Abort()
+					abortWithError(c, http.StatusBadRequest, dto.CodeValidationFailed, "Invalid request")
 					return
 				}
 			}
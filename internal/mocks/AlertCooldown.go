@@ -0,0 +1,58 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// AlertCooldown is an autogenerated mock type for the AlertCooldown type
+type AlertCooldown struct {
+	mock.Mock
+}
+
+// Acquire provides a mock function with given fields: ctx, key, ttl
+func (_m *AlertCooldown) Acquire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	ret := _m.Called(ctx, key, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Acquire")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) (bool, error)); ok {
+		return rf(ctx, key, ttl)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) bool); ok {
+		r0 = rf(ctx, key, ttl)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Duration) error); ok {
+		r1 = rf(ctx, key, ttl)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewAlertCooldown creates a new instance of AlertCooldown. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAlertCooldown(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AlertCooldown {
+	mock := &AlertCooldown{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
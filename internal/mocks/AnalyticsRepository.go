@@ -0,0 +1,77 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AnalyticsRepository is an autogenerated mock type for the AnalyticsRepository type
+type AnalyticsRepository struct {
+	mock.Mock
+}
+
+// Insert provides a mock function with given fields: ctx, logs
+func (_m *AnalyticsRepository) Insert(ctx context.Context, logs []domain.AuditLog) error {
+	ret := _m.Called(ctx, logs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Insert")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.AuditLog) error); ok {
+		r0 = rf(ctx, logs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Stats provides a mock function with given fields: ctx, filter
+func (_m *AnalyticsRepository) Stats(ctx context.Context, filter *domain.AuditLogFilter) (*domain.AuditLogStats, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Stats")
+	}
+
+	var r0 *domain.AuditLogStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLogFilter) (*domain.AuditLogStats, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLogFilter) *domain.AuditLogStats); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.AuditLogStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.AuditLogFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewAnalyticsRepository creates a new instance of AnalyticsRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAnalyticsRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AnalyticsRepository {
+	mock := &AnalyticsRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,91 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	io "io"
+
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ArchiveReader is an autogenerated mock type for the ArchiveReader type
+type ArchiveReader struct {
+	mock.Mock
+}
+
+// Open provides a mock function with given fields: ctx, key
+func (_m *ArchiveReader) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Open")
+	}
+
+	var r0 io.ReadCloser
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (io.ReadCloser, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) io.ReadCloser); ok {
+		r0 = rf(ctx, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.ReadCloser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Select provides a mock function with given fields: ctx, key, expression
+func (_m *ArchiveReader) Select(ctx context.Context, key string, expression string) ([]domain.AuditLog, error) {
+	ret := _m.Called(ctx, key, expression)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Select")
+	}
+
+	var r0 []domain.AuditLog
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]domain.AuditLog, error)); ok {
+		return rf(ctx, key, expression)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []domain.AuditLog); ok {
+		r0 = rf(ctx, key, expression)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.AuditLog)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, key, expression)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewArchiveReader creates a new instance of ArchiveReader. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewArchiveReader(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ArchiveReader {
+	mock := &ArchiveReader{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
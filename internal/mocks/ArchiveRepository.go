@@ -0,0 +1,157 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// ArchiveRepository is an autogenerated mock type for the ArchiveRepository type
+type ArchiveRepository struct {
+	mock.Mock
+}
+
+// GetByID provides a mock function with given fields: ctx, tenantID, id
+func (_m *ArchiveRepository) GetByID(ctx context.Context, tenantID string, id string) (*domain.Archive, error) {
+	ret := _m.Called(ctx, tenantID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *domain.Archive
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*domain.Archive, error)); ok {
+		return rf(ctx, tenantID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *domain.Archive); ok {
+		r0 = rf(ctx, tenantID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Archive)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, tenantID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: ctx, filter
+func (_m *ArchiveRepository) List(ctx context.Context, filter domain.ArchiveFilter) ([]domain.Archive, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []domain.Archive
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ArchiveFilter) ([]domain.Archive, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ArchiveFilter) []domain.Archive); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Archive)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.ArchiveFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListSince provides a mock function with given fields: ctx, tenantID, since
+func (_m *ArchiveRepository) ListSince(ctx context.Context, tenantID string, since time.Time) ([]domain.Archive, error) {
+	ret := _m.Called(ctx, tenantID, since)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSince")
+	}
+
+	var r0 []domain.Archive
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) ([]domain.Archive, error)); ok {
+		return rf(ctx, tenantID, since)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) []domain.Archive); ok {
+		r0 = rf(ctx, tenantID, since)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Archive)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, tenantID, since)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Save provides a mock function with given fields: ctx, archive
+func (_m *ArchiveRepository) Save(ctx context.Context, archive *domain.Archive) error {
+	ret := _m.Called(ctx, archive)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Archive) error); ok {
+		r0 = rf(ctx, archive)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateVerification provides a mock function with given fields: ctx, archive
+func (_m *ArchiveRepository) UpdateVerification(ctx context.Context, archive *domain.Archive) error {
+	ret := _m.Called(ctx, archive)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateVerification")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Archive) error); ok {
+		r0 = rf(ctx, archive)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewArchiveRepository creates a new instance of ArchiveRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewArchiveRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ArchiveRepository {
+	mock := &ArchiveRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,137 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// ArchiveScheduleRepository is an autogenerated mock type for the ArchiveScheduleRepository type
+type ArchiveScheduleRepository struct {
+	mock.Mock
+}
+
+// Claim provides a mock function with given fields: ctx, tenantID, lastRunAt, runAt, jobID
+func (_m *ArchiveScheduleRepository) Claim(ctx context.Context, tenantID string, lastRunAt *time.Time, runAt time.Time, jobID string) (bool, error) {
+	ret := _m.Called(ctx, tenantID, lastRunAt, runAt, jobID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Claim")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *time.Time, time.Time, string) (bool, error)); ok {
+		return rf(ctx, tenantID, lastRunAt, runAt, jobID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *time.Time, time.Time, string) bool); ok {
+		r0 = rf(ctx, tenantID, lastRunAt, runAt, jobID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *time.Time, time.Time, string) error); ok {
+		r1 = rf(ctx, tenantID, lastRunAt, runAt, jobID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByTenant provides a mock function with given fields: ctx, tenantID
+func (_m *ArchiveScheduleRepository) GetByTenant(ctx context.Context, tenantID string) (*domain.ArchiveSchedule, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByTenant")
+	}
+
+	var r0 *domain.ArchiveSchedule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.ArchiveSchedule, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.ArchiveSchedule); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.ArchiveSchedule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: ctx
+func (_m *ArchiveScheduleRepository) List(ctx context.Context) ([]domain.ArchiveSchedule, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []domain.ArchiveSchedule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]domain.ArchiveSchedule, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []domain.ArchiveSchedule); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ArchiveSchedule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Save provides a mock function with given fields: ctx, schedule
+func (_m *ArchiveScheduleRepository) Save(ctx context.Context, schedule *domain.ArchiveSchedule) error {
+	ret := _m.Called(ctx, schedule)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.ArchiveSchedule) error); ok {
+		r0 = rf(ctx, schedule)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewArchiveScheduleRepository creates a new instance of ArchiveScheduleRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewArchiveScheduleRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ArchiveScheduleRepository {
+	mock := &ArchiveScheduleRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
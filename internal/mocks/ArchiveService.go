@@ -0,0 +1,121 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/kingrain94/audit-log-api/internal/api/dto"
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ArchiveService is an autogenerated mock type for the ArchiveService type
+type ArchiveService struct {
+	mock.Mock
+}
+
+// List provides a mock function with given fields: ctx, filter
+func (_m *ArchiveService) List(ctx context.Context, filter *domain.ArchiveFilter) ([]dto.ArchiveResponse, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []dto.ArchiveResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.ArchiveFilter) ([]dto.ArchiveResponse, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.ArchiveFilter) []dto.ArchiveResponse); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.ArchiveResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.ArchiveFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Search provides a mock function with given fields: ctx, filter
+func (_m *ArchiveService) Search(ctx context.Context, filter *domain.ArchiveSearchFilter) (*dto.ArchiveSearchResponse, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Search")
+	}
+
+	var r0 *dto.ArchiveSearchResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.ArchiveSearchFilter) (*dto.ArchiveSearchResponse, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.ArchiveSearchFilter) *dto.ArchiveSearchResponse); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.ArchiveSearchResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.ArchiveSearchFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Verify provides a mock function with given fields: ctx, tenantID, id
+func (_m *ArchiveService) Verify(ctx context.Context, tenantID string, id string) (*dto.ArchiveVerificationResponse, error) {
+	ret := _m.Called(ctx, tenantID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Verify")
+	}
+
+	var r0 *dto.ArchiveVerificationResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*dto.ArchiveVerificationResponse, error)); ok {
+		return rf(ctx, tenantID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *dto.ArchiveVerificationResponse); ok {
+		r0 = rf(ctx, tenantID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.ArchiveVerificationResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, tenantID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewArchiveService creates a new instance of ArchiveService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewArchiveService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ArchiveService {
+	mock := &ArchiveService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
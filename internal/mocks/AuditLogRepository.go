@@ -80,6 +80,36 @@ func (_m *AuditLogRepository) DeleteBeforeDate(ctx context.Context, tenantID str
 	return r0, r1
 }
 
+// GetActivity provides a mock function with given fields: ctx, tenantID, start, end
+func (_m *AuditLogRepository) GetActivity(ctx context.Context, tenantID string, start time.Time, end time.Time) (*domain.ActivityCounts, error) {
+	ret := _m.Called(ctx, tenantID, start, end)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetActivity")
+	}
+
+	var r0 *domain.ActivityCounts
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) (*domain.ActivityCounts, error)); ok {
+		return rf(ctx, tenantID, start, end)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) *domain.ActivityCounts); ok {
+		r0 = rf(ctx, tenantID, start, end)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.ActivityCounts)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, tenantID, start, end)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByID provides a mock function with given fields: ctx, id
 func (_m *AuditLogRepository) GetByID(ctx context.Context, id string) (*domain.AuditLog, error) {
 	ret := _m.Called(ctx, id)
@@ -110,9 +140,69 @@ func (_m *AuditLogRepository) GetByID(ctx context.Context, id string) (*domain.A
 	return r0, r1
 }
 
-// GetRecentLogs provides a mock function with given fields: ctx, tenantID, since
-func (_m *AuditLogRepository) GetRecentLogs(ctx context.Context, tenantID string, since time.Time) ([]domain.AuditLog, error) {
-	ret := _m.Called(ctx, tenantID, since)
+// GetByIDs provides a mock function with given fields: ctx, ids
+func (_m *AuditLogRepository) GetByIDs(ctx context.Context, ids []string) ([]domain.AuditLog, error) {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByIDs")
+	}
+
+	var r0 []domain.AuditLog
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]domain.AuditLog, error)); ok {
+		return rf(ctx, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []domain.AuditLog); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.AuditLog)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetHourlyActivity provides a mock function with given fields: ctx, tenantID, start, end
+func (_m *AuditLogRepository) GetHourlyActivity(ctx context.Context, tenantID string, start time.Time, end time.Time) (*domain.ActivityCounts, error) {
+	ret := _m.Called(ctx, tenantID, start, end)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetHourlyActivity")
+	}
+
+	var r0 *domain.ActivityCounts
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) (*domain.ActivityCounts, error)); ok {
+		return rf(ctx, tenantID, start, end)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) *domain.ActivityCounts); ok {
+		r0 = rf(ctx, tenantID, start, end)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.ActivityCounts)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, tenantID, start, end)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRecentLogs provides a mock function with given fields: ctx, tenantID, since, limit
+func (_m *AuditLogRepository) GetRecentLogs(ctx context.Context, tenantID string, since time.Time, limit int) ([]domain.AuditLog, error) {
+	ret := _m.Called(ctx, tenantID, since, limit)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetRecentLogs")
@@ -120,19 +210,19 @@ func (_m *AuditLogRepository) GetRecentLogs(ctx context.Context, tenantID string
 
 	var r0 []domain.AuditLog
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) ([]domain.AuditLog, error)); ok {
-		return rf(ctx, tenantID, since)
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, int) ([]domain.AuditLog, error)); ok {
+		return rf(ctx, tenantID, since, limit)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) []domain.AuditLog); ok {
-		r0 = rf(ctx, tenantID, since)
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, int) []domain.AuditLog); ok {
+		r0 = rf(ctx, tenantID, since, limit)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]domain.AuditLog)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
-		r1 = rf(ctx, tenantID, since)
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, int) error); ok {
+		r1 = rf(ctx, tenantID, since, limit)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -200,6 +290,154 @@ func (_m *AuditLogRepository) List(ctx context.Context, filter domain.AuditLogFi
 	return r0, r1
 }
 
+// ListAfter provides a mock function with given fields: ctx, tenantID, afterID, limit
+func (_m *AuditLogRepository) ListAfter(ctx context.Context, tenantID string, afterID string, limit int) ([]domain.AuditLog, error) {
+	ret := _m.Called(ctx, tenantID, afterID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAfter")
+	}
+
+	var r0 []domain.AuditLog
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) ([]domain.AuditLog, error)); ok {
+		return rf(ctx, tenantID, afterID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) []domain.AuditLog); ok {
+		r0 = rf(ctx, tenantID, afterID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.AuditLog)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int) error); ok {
+		r1 = rf(ctx, tenantID, afterID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListBatch provides a mock function with given fields: ctx, filter, cursor, limit
+func (_m *AuditLogRepository) ListBatch(ctx context.Context, filter domain.AuditLogFilter, cursor *domain.AuditLogCursor, limit int) ([]domain.AuditLog, error) {
+	ret := _m.Called(ctx, filter, cursor, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListBatch")
+	}
+
+	var r0 []domain.AuditLog
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.AuditLogFilter, *domain.AuditLogCursor, int) ([]domain.AuditLog, error)); ok {
+		return rf(ctx, filter, cursor, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.AuditLogFilter, *domain.AuditLogCursor, int) []domain.AuditLog); ok {
+		r0 = rf(ctx, filter, cursor, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.AuditLog)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.AuditLogFilter, *domain.AuditLogCursor, int) error); ok {
+		r1 = rf(ctx, filter, cursor, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListNewIPAddresses provides a mock function with given fields: ctx, tenantID, baselineStart, since, until
+func (_m *AuditLogRepository) ListNewIPAddresses(ctx context.Context, tenantID string, baselineStart time.Time, since time.Time, until time.Time) ([]domain.UserIPAddress, error) {
+	ret := _m.Called(ctx, tenantID, baselineStart, since, until)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListNewIPAddresses")
+	}
+
+	var r0 []domain.UserIPAddress
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time, time.Time) ([]domain.UserIPAddress, error)); ok {
+		return rf(ctx, tenantID, baselineStart, since, until)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time, time.Time) []domain.UserIPAddress); ok {
+		r0 = rf(ctx, tenantID, baselineStart, since, until)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.UserIPAddress)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, tenantID, baselineStart, since, until)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListStoredAfter provides a mock function with given fields: ctx, filter, cursor, until, limit
+func (_m *AuditLogRepository) ListStoredAfter(ctx context.Context, filter domain.AuditLogFilter, cursor *domain.AuditLogCursor, until time.Time, limit int) ([]domain.AuditLog, error) {
+	ret := _m.Called(ctx, filter, cursor, until, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListStoredAfter")
+	}
+
+	var r0 []domain.AuditLog
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.AuditLogFilter, *domain.AuditLogCursor, time.Time, int) ([]domain.AuditLog, error)); ok {
+		return rf(ctx, filter, cursor, until, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.AuditLogFilter, *domain.AuditLogCursor, time.Time, int) []domain.AuditLog); ok {
+		r0 = rf(ctx, filter, cursor, until, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.AuditLog)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.AuditLogFilter, *domain.AuditLogCursor, time.Time, int) error); ok {
+		r1 = rf(ctx, filter, cursor, until, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Restore provides a mock function with given fields: ctx, logs
+func (_m *AuditLogRepository) Restore(ctx context.Context, logs []domain.AuditLog) (int64, error) {
+	ret := _m.Called(ctx, logs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Restore")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.AuditLog) (int64, error)); ok {
+		return rf(ctx, logs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.AuditLog) int64); ok {
+		r0 = rf(ctx, logs)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []domain.AuditLog) error); ok {
+		r1 = rf(ctx, logs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewAuditLogRepository creates a new instance of AuditLogRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewAuditLogRepository(t interface {
@@ -8,6 +8,8 @@ import (
 	dto "github.com/kingrain94/audit-log-api/internal/api/dto"
 	domain "github.com/kingrain94/audit-log-api/internal/domain"
 
+	io "io"
+
 	mock "github.com/stretchr/testify/mock"
 
 	time "time"
@@ -18,6 +20,36 @@ type AuditLogService struct {
 	mock.Mock
 }
 
+// BatchGet provides a mock function with given fields: ctx, ids, userID
+func (_m *AuditLogService) BatchGet(ctx context.Context, ids []string, userID string) (*dto.BatchGetLogsResponse, error) {
+	ret := _m.Called(ctx, ids, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BatchGet")
+	}
+
+	var r0 *dto.BatchGetLogsResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string, string) (*dto.BatchGetLogsResponse, error)); ok {
+		return rf(ctx, ids, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string, string) *dto.BatchGetLogsResponse); ok {
+		r0 = rf(ctx, ids, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.BatchGetLogsResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string, string) error); ok {
+		r1 = rf(ctx, ids, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // BulkCreate provides a mock function with given fields: ctx, reqs
 func (_m *AuditLogService) BulkCreate(ctx context.Context, reqs []dto.CreateAuditLogRequest) error {
 	ret := _m.Called(ctx, reqs)
@@ -36,6 +68,36 @@ func (_m *AuditLogService) BulkCreate(ctx context.Context, reqs []dto.CreateAudi
 	return r0
 }
 
+// BulkCreateAsync provides a mock function with given fields: ctx, reqs
+func (_m *AuditLogService) BulkCreateAsync(ctx context.Context, reqs []dto.CreateAuditLogRequest) ([]string, error) {
+	ret := _m.Called(ctx, reqs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BulkCreateAsync")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []dto.CreateAuditLogRequest) ([]string, error)); ok {
+		return rf(ctx, reqs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []dto.CreateAuditLogRequest) []string); ok {
+		r0 = rf(ctx, reqs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []dto.CreateAuditLogRequest) error); ok {
+		r1 = rf(ctx, reqs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Create provides a mock function with given fields: ctx, req
 func (_m *AuditLogService) Create(ctx context.Context, req dto.CreateAuditLogRequest) error {
 	ret := _m.Called(ctx, req)
@@ -54,6 +116,200 @@ func (_m *AuditLogService) Create(ctx context.Context, req dto.CreateAuditLogReq
 	return r0
 }
 
+// CreateAsync provides a mock function with given fields: ctx, req
+func (_m *AuditLogService) CreateAsync(ctx context.Context, req dto.CreateAuditLogRequest) (string, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAsync")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, dto.CreateAuditLogRequest) (string, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, dto.CreateAuditLogRequest) string); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, dto.CreateAuditLogRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateExportJob provides a mock function with given fields: ctx, filter, format, signWith
+func (_m *AuditLogService) CreateExportJob(ctx context.Context, filter *domain.AuditLogFilter, format domain.ExportFormat, signWith domain.ExportSigningKey) (*dto.ExportJobResponse, error) {
+	ret := _m.Called(ctx, filter, format, signWith)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateExportJob")
+	}
+
+	var r0 *dto.ExportJobResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLogFilter, domain.ExportFormat, domain.ExportSigningKey) (*dto.ExportJobResponse, error)); ok {
+		return rf(ctx, filter, format, signWith)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLogFilter, domain.ExportFormat, domain.ExportSigningKey) *dto.ExportJobResponse); ok {
+		r0 = rf(ctx, filter, format, signWith)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.ExportJobResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.AuditLogFilter, domain.ExportFormat, domain.ExportSigningKey) error); ok {
+		r1 = rf(ctx, filter, format, signWith)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateRestoreJob provides a mock function with given fields: ctx, tenantID, startTime, endTime
+func (_m *AuditLogService) CreateRestoreJob(ctx context.Context, tenantID string, startTime time.Time, endTime time.Time) (*dto.RestoreJobResponse, error) {
+	ret := _m.Called(ctx, tenantID, startTime, endTime)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateRestoreJob")
+	}
+
+	var r0 *dto.RestoreJobResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) (*dto.RestoreJobResponse, error)); ok {
+		return rf(ctx, tenantID, startTime, endTime)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) *dto.RestoreJobResponse); ok {
+		r0 = rf(ctx, tenantID, startTime, endTime)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.RestoreJobResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, tenantID, startTime, endTime)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ETag provides a mock function with given fields: ctx, name, filter
+func (_m *AuditLogService) ETag(ctx context.Context, name string, filter *domain.AuditLogFilter) string {
+	ret := _m.Called(ctx, name, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ETag")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context, string, *domain.AuditLogFilter) string); ok {
+		r0 = rf(ctx, name, filter)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// Export provides a mock function with given fields: ctx, filter, format, out
+func (_m *AuditLogService) Export(ctx context.Context, filter *domain.AuditLogFilter, format domain.ExportFormat, out io.Writer) (int64, error) {
+	ret := _m.Called(ctx, filter, format, out)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Export")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLogFilter, domain.ExportFormat, io.Writer) (int64, error)); ok {
+		return rf(ctx, filter, format, out)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLogFilter, domain.ExportFormat, io.Writer) int64); ok {
+		r0 = rf(ctx, filter, format, out)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.AuditLogFilter, domain.ExportFormat, io.Writer) error); ok {
+		r1 = rf(ctx, filter, format, out)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExportSigningKeys provides a mock function with given fields: ctx, tenantID
+func (_m *AuditLogService) ExportSigningKeys(ctx context.Context, tenantID string) ([]dto.SigningKeyResponse, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportSigningKeys")
+	}
+
+	var r0 []dto.SigningKeyResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]dto.SigningKeyResponse, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []dto.SigningKeyResponse); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.SigningKeyResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByCorrelationID provides a mock function with given fields: ctx, tenantID, correlationID, userID
+func (_m *AuditLogService) GetByCorrelationID(ctx context.Context, tenantID string, correlationID string, userID string) ([]dto.AuditLogResponse, error) {
+	ret := _m.Called(ctx, tenantID, correlationID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByCorrelationID")
+	}
+
+	var r0 []dto.AuditLogResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) ([]dto.AuditLogResponse, error)); ok {
+		return rf(ctx, tenantID, correlationID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) []dto.AuditLogResponse); ok {
+		r0 = rf(ctx, tenantID, correlationID, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.AuditLogResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, tenantID, correlationID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByID provides a mock function with given fields: ctx, id
 func (_m *AuditLogService) GetByID(ctx context.Context, id string) (*dto.AuditLogResponse, error) {
 	ret := _m.Called(ctx, id)
@@ -84,6 +340,126 @@ func (_m *AuditLogService) GetByID(ctx context.Context, id string) (*dto.AuditLo
 	return r0, r1
 }
 
+// GetDiff provides a mock function with given fields: ctx, id, userID, unified
+func (_m *AuditLogService) GetDiff(ctx context.Context, id string, userID string, unified bool) (*dto.AuditLogDiffResponse, error) {
+	ret := _m.Called(ctx, id, userID, unified)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDiff")
+	}
+
+	var r0 *dto.AuditLogDiffResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, bool) (*dto.AuditLogDiffResponse, error)); ok {
+		return rf(ctx, id, userID, unified)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, bool) *dto.AuditLogDiffResponse); ok {
+		r0 = rf(ctx, id, userID, unified)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.AuditLogDiffResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, bool) error); ok {
+		r1 = rf(ctx, id, userID, unified)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetExportJob provides a mock function with given fields: ctx, tenantID, jobID
+func (_m *AuditLogService) GetExportJob(ctx context.Context, tenantID string, jobID string) (*dto.ExportJobResponse, error) {
+	ret := _m.Called(ctx, tenantID, jobID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetExportJob")
+	}
+
+	var r0 *dto.ExportJobResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*dto.ExportJobResponse, error)); ok {
+		return rf(ctx, tenantID, jobID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *dto.ExportJobResponse); ok {
+		r0 = rf(ctx, tenantID, jobID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.ExportJobResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, tenantID, jobID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetExportSignature provides a mock function with given fields: ctx, tenantID, jobID
+func (_m *AuditLogService) GetExportSignature(ctx context.Context, tenantID string, jobID string) (*dto.ExportSignatureResponse, error) {
+	ret := _m.Called(ctx, tenantID, jobID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetExportSignature")
+	}
+
+	var r0 *dto.ExportSignatureResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*dto.ExportSignatureResponse, error)); ok {
+		return rf(ctx, tenantID, jobID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *dto.ExportSignatureResponse); ok {
+		r0 = rf(ctx, tenantID, jobID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.ExportSignatureResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, tenantID, jobID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRestoreJob provides a mock function with given fields: ctx, tenantID, jobID
+func (_m *AuditLogService) GetRestoreJob(ctx context.Context, tenantID string, jobID string) (*dto.RestoreJobResponse, error) {
+	ret := _m.Called(ctx, tenantID, jobID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRestoreJob")
+	}
+
+	var r0 *dto.RestoreJobResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*dto.RestoreJobResponse, error)); ok {
+		return rf(ctx, tenantID, jobID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *dto.RestoreJobResponse); ok {
+		r0 = rf(ctx, tenantID, jobID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.RestoreJobResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, tenantID, jobID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetStats provides a mock function with given fields: ctx, filter
 func (_m *AuditLogService) GetStats(ctx context.Context, filter *domain.AuditLogFilter) (*dto.GetAuditLogStatsResponse, error) {
 	ret := _m.Called(ctx, filter)
@@ -144,8 +520,38 @@ func (_m *AuditLogService) GetStatsV2(ctx context.Context, filter *domain.AuditL
 	return r0, r1
 }
 
+// GetTopStats provides a mock function with given fields: ctx, filter, limit
+func (_m *AuditLogService) GetTopStats(ctx context.Context, filter *domain.AuditLogFilter, limit int) (*dto.TopStatsResponse, error) {
+	ret := _m.Called(ctx, filter, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTopStats")
+	}
+
+	var r0 *dto.TopStatsResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLogFilter, int) (*dto.TopStatsResponse, error)); ok {
+		return rf(ctx, filter, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLogFilter, int) *dto.TopStatsResponse); ok {
+		r0 = rf(ctx, filter, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.TopStatsResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.AuditLogFilter, int) error); ok {
+		r1 = rf(ctx, filter, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // List provides a mock function with given fields: ctx, filter, usePagination
-func (_m *AuditLogService) List(ctx context.Context, filter *domain.AuditLogFilter, usePagination bool) ([]dto.AuditLogResponse, error) {
+func (_m *AuditLogService) List(ctx context.Context, filter *domain.AuditLogFilter, usePagination bool) ([]dto.AuditLogResponse, string, bool, error) {
 	ret := _m.Called(ctx, filter, usePagination)
 
 	if len(ret) == 0 {
@@ -153,8 +559,10 @@ func (_m *AuditLogService) List(ctx context.Context, filter *domain.AuditLogFilt
 	}
 
 	var r0 []dto.AuditLogResponse
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLogFilter, bool) ([]dto.AuditLogResponse, error)); ok {
+	var r1 string
+	var r2 bool
+	var r3 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLogFilter, bool) ([]dto.AuditLogResponse, string, bool, error)); ok {
 		return rf(ctx, filter, usePagination)
 	}
 	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLogFilter, bool) []dto.AuditLogResponse); ok {
@@ -165,8 +573,50 @@ func (_m *AuditLogService) List(ctx context.Context, filter *domain.AuditLogFilt
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *domain.AuditLogFilter, bool) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.AuditLogFilter, bool) string); ok {
 		r1 = rf(ctx, filter, usePagination)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *domain.AuditLogFilter, bool) bool); ok {
+		r2 = rf(ctx, filter, usePagination)
+	} else {
+		r2 = ret.Get(2).(bool)
+	}
+
+	if rf, ok := ret.Get(3).(func(context.Context, *domain.AuditLogFilter, bool) error); ok {
+		r3 = rf(ctx, filter, usePagination)
+	} else {
+		r3 = ret.Error(3)
+	}
+
+	return r0, r1, r2, r3
+}
+
+// Report provides a mock function with given fields: ctx, filter
+func (_m *AuditLogService) Report(ctx context.Context, filter *domain.AuditLogFilter) ([]byte, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Report")
+	}
+
+	var r0 []byte
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLogFilter) ([]byte, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLogFilter) []byte); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.AuditLogFilter) error); ok {
+		r1 = rf(ctx, filter)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -175,21 +625,31 @@ func (_m *AuditLogService) List(ctx context.Context, filter *domain.AuditLogFilt
 }
 
 // ScheduleArchive provides a mock function with given fields: ctx, tenantID, beforeDate
-func (_m *AuditLogService) ScheduleArchive(ctx context.Context, tenantID string, beforeDate time.Time) error {
+func (_m *AuditLogService) ScheduleArchive(ctx context.Context, tenantID string, beforeDate time.Time) (string, error) {
 	ret := _m.Called(ctx, tenantID, beforeDate)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ScheduleArchive")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) (string, error)); ok {
+		return rf(ctx, tenantID, beforeDate)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) string); ok {
 		r0 = rf(ctx, tenantID, beforeDate)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(string)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, tenantID, beforeDate)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // NewAuditLogService creates a new instance of AuditLogService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
@@ -0,0 +1,141 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	dto "github.com/kingrain94/audit-log-api/internal/api/dto"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// AuthService is an autogenerated mock type for the AuthService type
+type AuthService struct {
+	mock.Mock
+}
+
+// IssueStreamTicket provides a mock function with given fields: ctx, claims
+func (_m *AuthService) IssueStreamTicket(ctx context.Context, claims jwt.MapClaims) (*dto.StreamTicketResponse, error) {
+	ret := _m.Called(ctx, claims)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IssueStreamTicket")
+	}
+
+	var r0 *dto.StreamTicketResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, jwt.MapClaims) (*dto.StreamTicketResponse, error)); ok {
+		return rf(ctx, claims)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, jwt.MapClaims) *dto.StreamTicketResponse); ok {
+		r0 = rf(ctx, claims)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.StreamTicketResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, jwt.MapClaims) error); ok {
+		r1 = rf(ctx, claims)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IssueToken provides a mock function with given fields: ctx, req
+func (_m *AuthService) IssueToken(ctx context.Context, req dto.TokenRequest) (*dto.TokenResponse, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IssueToken")
+	}
+
+	var r0 *dto.TokenResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, dto.TokenRequest) (*dto.TokenResponse, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, dto.TokenRequest) *dto.TokenResponse); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.TokenResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, dto.TokenRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Refresh provides a mock function with given fields: ctx, refreshToken
+func (_m *AuthService) Refresh(ctx context.Context, refreshToken string) (*dto.TokenResponse, error) {
+	ret := _m.Called(ctx, refreshToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Refresh")
+	}
+
+	var r0 *dto.TokenResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*dto.TokenResponse, error)); ok {
+		return rf(ctx, refreshToken)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *dto.TokenResponse); ok {
+		r0 = rf(ctx, refreshToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.TokenResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, refreshToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Revoke provides a mock function with given fields: ctx, userID, tokenID, expiresAt, refreshToken
+func (_m *AuthService) Revoke(ctx context.Context, userID string, tokenID string, expiresAt time.Time, refreshToken string) error {
+	ret := _m.Called(ctx, userID, tokenID, expiresAt, refreshToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Revoke")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time, string) error); ok {
+		r0 = rf(ctx, userID, tokenID, expiresAt, refreshToken)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewAuthService creates a new instance of AuthService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAuthService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AuthService {
+	mock := &AuthService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,95 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CleanupJobRepository is an autogenerated mock type for the CleanupJobRepository type
+type CleanupJobRepository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, job
+func (_m *CleanupJobRepository) Create(ctx context.Context, job *domain.CleanupJob) error {
+	ret := _m.Called(ctx, job)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.CleanupJob) error); ok {
+		r0 = rf(ctx, job)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByID provides a mock function with given fields: ctx, tenantID, id
+func (_m *CleanupJobRepository) GetByID(ctx context.Context, tenantID string, id string) (*domain.CleanupJob, error) {
+	ret := _m.Called(ctx, tenantID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *domain.CleanupJob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*domain.CleanupJob, error)); ok {
+		return rf(ctx, tenantID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *domain.CleanupJob); ok {
+		r0 = rf(ctx, tenantID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.CleanupJob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, tenantID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, job
+func (_m *CleanupJobRepository) Update(ctx context.Context, job *domain.CleanupJob) error {
+	ret := _m.Called(ctx, job)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.CleanupJob) error); ok {
+		r0 = rf(ctx, job)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewCleanupJobRepository creates a new instance of CleanupJobRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCleanupJobRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CleanupJobRepository {
+	mock := &CleanupJobRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,44 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+
+// ConfigService is an autogenerated mock type for the ConfigService type
+type ConfigService struct {
+	mock.Mock
+}
+
+// Effective provides a mock function with no fields
+func (_m *ConfigService) Effective() map[string]interface{} {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Effective")
+	}
+
+	var r0 map[string]interface{}
+	if rf, ok := ret.Get(0).(func() map[string]interface{}); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]interface{})
+		}
+	}
+
+	return r0
+}
+
+// NewConfigService creates a new instance of ConfigService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewConfigService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ConfigService {
+	mock := &ConfigService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
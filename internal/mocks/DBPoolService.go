@@ -0,0 +1,77 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	dto "github.com/kingrain94/audit-log-api/internal/api/dto"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// DBPoolService is an autogenerated mock type for the DBPoolService type
+type DBPoolService struct {
+	mock.Mock
+}
+
+// List provides a mock function with no fields
+func (_m *DBPoolService) List() []dto.DBPoolResponse {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []dto.DBPoolResponse
+	if rf, ok := ret.Get(0).(func() []dto.DBPoolResponse); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.DBPoolResponse)
+		}
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: name, req
+func (_m *DBPoolService) Update(name string, req *dto.UpdateDBPoolRequest) (*dto.DBPoolResponse, error) {
+	ret := _m.Called(name, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 *dto.DBPoolResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, *dto.UpdateDBPoolRequest) (*dto.DBPoolResponse, error)); ok {
+		return rf(name, req)
+	}
+	if rf, ok := ret.Get(0).(func(string, *dto.UpdateDBPoolRequest) *dto.DBPoolResponse); ok {
+		r0 = rf(name, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.DBPoolResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, *dto.UpdateDBPoolRequest) error); ok {
+		r1 = rf(name, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewDBPoolService creates a new instance of DBPoolService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewDBPoolService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *DBPoolService {
+	mock := &DBPoolService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,95 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ExportJobRepository is an autogenerated mock type for the ExportJobRepository type
+type ExportJobRepository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, job
+func (_m *ExportJobRepository) Create(ctx context.Context, job *domain.ExportJob) error {
+	ret := _m.Called(ctx, job)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.ExportJob) error); ok {
+		r0 = rf(ctx, job)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByID provides a mock function with given fields: ctx, tenantID, id
+func (_m *ExportJobRepository) GetByID(ctx context.Context, tenantID string, id string) (*domain.ExportJob, error) {
+	ret := _m.Called(ctx, tenantID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *domain.ExportJob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*domain.ExportJob, error)); ok {
+		return rf(ctx, tenantID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *domain.ExportJob); ok {
+		r0 = rf(ctx, tenantID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.ExportJob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, tenantID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, job
+func (_m *ExportJobRepository) Update(ctx context.Context, job *domain.ExportJob) error {
+	ret := _m.Called(ctx, job)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.ExportJob) error); ok {
+		r0 = rf(ctx, job)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewExportJobRepository creates a new instance of ExportJobRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewExportJobRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ExportJobRepository {
+	mock := &ExportJobRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
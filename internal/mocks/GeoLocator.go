@@ -0,0 +1,57 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	netip "net/netip"
+)
+
+// GeoLocator is an autogenerated mock type for the GeoLocator type
+type GeoLocator struct {
+	mock.Mock
+}
+
+// Locate provides a mock function with given fields: ip
+func (_m *GeoLocator) Locate(ip netip.Addr) (domain.GeoLocation, bool) {
+	ret := _m.Called(ip)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Locate")
+	}
+
+	var r0 domain.GeoLocation
+	var r1 bool
+	if rf, ok := ret.Get(0).(func(netip.Addr) (domain.GeoLocation, bool)); ok {
+		return rf(ip)
+	}
+	if rf, ok := ret.Get(0).(func(netip.Addr) domain.GeoLocation); ok {
+		r0 = rf(ip)
+	} else {
+		r0 = ret.Get(0).(domain.GeoLocation)
+	}
+
+	if rf, ok := ret.Get(1).(func(netip.Addr) bool); ok {
+		r1 = rf(ip)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
+// NewGeoLocator creates a new instance of GeoLocator. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewGeoLocator(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *GeoLocator {
+	mock := &GeoLocator{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,75 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	netip "net/netip"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IPReputationProvider is an autogenerated mock type for the IPReputationProvider type
+type IPReputationProvider struct {
+	mock.Mock
+}
+
+// Name provides a mock function with no fields
+func (_m *IPReputationProvider) Name() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Name")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// Score provides a mock function with given fields: ctx, ip
+func (_m *IPReputationProvider) Score(ctx context.Context, ip netip.Addr) (int, error) {
+	ret := _m.Called(ctx, ip)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Score")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, netip.Addr) (int, error)); ok {
+		return rf(ctx, ip)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, netip.Addr) int); ok {
+		r0 = rf(ctx, ip)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, netip.Addr) error); ok {
+		r1 = rf(ctx, ip)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewIPReputationProvider creates a new instance of IPReputationProvider. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIPReputationProvider(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IPReputationProvider {
+	mock := &IPReputationProvider{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
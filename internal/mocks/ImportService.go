@@ -0,0 +1,121 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	io "io"
+
+	dto "github.com/kingrain94/audit-log-api/internal/api/dto"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ImportService is an autogenerated mock type for the ImportService type
+type ImportService struct {
+	mock.Mock
+}
+
+// Get provides a mock function with given fields: ctx, tenantID, jobID
+func (_m *ImportService) Get(ctx context.Context, tenantID string, jobID string) (*dto.ImportJobResponse, error) {
+	ret := _m.Called(ctx, tenantID, jobID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *dto.ImportJobResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*dto.ImportJobResponse, error)); ok {
+		return rf(ctx, tenantID, jobID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *dto.ImportJobResponse); ok {
+		r0 = rf(ctx, tenantID, jobID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.ImportJobResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, tenantID, jobID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ImportFromS3 provides a mock function with given fields: ctx, tenantID, uri
+func (_m *ImportService) ImportFromS3(ctx context.Context, tenantID string, uri string) (*dto.ImportJobResponse, error) {
+	ret := _m.Called(ctx, tenantID, uri)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportFromS3")
+	}
+
+	var r0 *dto.ImportJobResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*dto.ImportJobResponse, error)); ok {
+		return rf(ctx, tenantID, uri)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *dto.ImportJobResponse); ok {
+		r0 = rf(ctx, tenantID, uri)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.ImportJobResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, tenantID, uri)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Upload provides a mock function with given fields: ctx, tenantID, body, size
+func (_m *ImportService) Upload(ctx context.Context, tenantID string, body io.Reader, size int64) (*dto.ImportJobResponse, error) {
+	ret := _m.Called(ctx, tenantID, body, size)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Upload")
+	}
+
+	var r0 *dto.ImportJobResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, io.Reader, int64) (*dto.ImportJobResponse, error)); ok {
+		return rf(ctx, tenantID, body, size)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, io.Reader, int64) *dto.ImportJobResponse); ok {
+		r0 = rf(ctx, tenantID, body, size)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.ImportJobResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, io.Reader, int64) error); ok {
+		r1 = rf(ctx, tenantID, body, size)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewImportService creates a new instance of ImportService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewImportService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ImportService {
+	mock := &ImportService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
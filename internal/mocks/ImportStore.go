@@ -0,0 +1,112 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	io "io"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ImportStore is an autogenerated mock type for the ImportStore type
+type ImportStore struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: ctx, uri
+func (_m *ImportStore) Delete(ctx context.Context, uri string) error {
+	ret := _m.Called(ctx, uri)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, uri)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Open provides a mock function with given fields: ctx, uri
+func (_m *ImportStore) Open(ctx context.Context, uri string) (io.ReadCloser, int64, error) {
+	ret := _m.Called(ctx, uri)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Open")
+	}
+
+	var r0 io.ReadCloser
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (io.ReadCloser, int64, error)); ok {
+		return rf(ctx, uri)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) io.ReadCloser); ok {
+		r0 = rf(ctx, uri)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.ReadCloser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) int64); ok {
+		r1 = rf(ctx, uri)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = rf(ctx, uri)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Put provides a mock function with given fields: ctx, key, body, size
+func (_m *ImportStore) Put(ctx context.Context, key string, body io.Reader, size int64) (string, error) {
+	ret := _m.Called(ctx, key, body, size)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Put")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, io.Reader, int64) (string, error)); ok {
+		return rf(ctx, key, body, size)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, io.Reader, int64) string); ok {
+		r0 = rf(ctx, key, body, size)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, io.Reader, int64) error); ok {
+		r1 = rf(ctx, key, body, size)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewImportStore creates a new instance of ImportStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewImportStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ImportStore {
+	mock := &ImportStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
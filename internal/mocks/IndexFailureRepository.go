@@ -0,0 +1,125 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IndexFailureRepository is an autogenerated mock type for the IndexFailureRepository type
+type IndexFailureRepository struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: ctx, tenantID, ids
+func (_m *IndexFailureRepository) Delete(ctx context.Context, tenantID string, ids []string) error {
+	ret := _m.Called(ctx, tenantID, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) error); ok {
+		r0 = rf(ctx, tenantID, ids)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByIDs provides a mock function with given fields: ctx, tenantID, ids
+func (_m *IndexFailureRepository) GetByIDs(ctx context.Context, tenantID string, ids []string) ([]domain.IndexFailure, error) {
+	ret := _m.Called(ctx, tenantID, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByIDs")
+	}
+
+	var r0 []domain.IndexFailure
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) ([]domain.IndexFailure, error)); ok {
+		return rf(ctx, tenantID, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) []domain.IndexFailure); ok {
+		r0 = rf(ctx, tenantID, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.IndexFailure)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
+		r1 = rf(ctx, tenantID, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListByTenant provides a mock function with given fields: ctx, tenantID, limit
+func (_m *IndexFailureRepository) ListByTenant(ctx context.Context, tenantID string, limit int) ([]domain.IndexFailure, error) {
+	ret := _m.Called(ctx, tenantID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListByTenant")
+	}
+
+	var r0 []domain.IndexFailure
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]domain.IndexFailure, error)); ok {
+		return rf(ctx, tenantID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []domain.IndexFailure); ok {
+		r0 = rf(ctx, tenantID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.IndexFailure)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, tenantID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Save provides a mock function with given fields: ctx, failures
+func (_m *IndexFailureRepository) Save(ctx context.Context, failures []domain.IndexFailure) error {
+	ret := _m.Called(ctx, failures)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.IndexFailure) error); ok {
+		r0 = rf(ctx, failures)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewIndexFailureRepository creates a new instance of IndexFailureRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIndexFailureRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IndexFailureRepository {
+	mock := &IndexFailureRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,89 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/kingrain94/audit-log-api/internal/api/dto"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IndexFailureService is an autogenerated mock type for the IndexFailureService type
+type IndexFailureService struct {
+	mock.Mock
+}
+
+// List provides a mock function with given fields: ctx, tenantID
+func (_m *IndexFailureService) List(ctx context.Context, tenantID string) ([]dto.IndexFailureResponse, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []dto.IndexFailureResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]dto.IndexFailureResponse, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []dto.IndexFailureResponse); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.IndexFailureResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Reprocess provides a mock function with given fields: ctx, tenantID, ids
+func (_m *IndexFailureService) Reprocess(ctx context.Context, tenantID string, ids []string) (*dto.ReprocessIndexFailuresResponse, error) {
+	ret := _m.Called(ctx, tenantID, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reprocess")
+	}
+
+	var r0 *dto.ReprocessIndexFailuresResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) (*dto.ReprocessIndexFailuresResponse, error)); ok {
+		return rf(ctx, tenantID, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) *dto.ReprocessIndexFailuresResponse); ok {
+		r0 = rf(ctx, tenantID, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.ReprocessIndexFailuresResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
+		r1 = rf(ctx, tenantID, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewIndexFailureService creates a new instance of IndexFailureService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIndexFailureService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IndexFailureService {
+	mock := &IndexFailureService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,211 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// IntegrationRepository is an autogenerated mock type for the IntegrationRepository type
+type IntegrationRepository struct {
+	mock.Mock
+}
+
+// ClaimDue provides a mock function with given fields: ctx, limit, lease
+func (_m *IntegrationRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.Integration, error) {
+	ret := _m.Called(ctx, limit, lease)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimDue")
+	}
+
+	var r0 []domain.Integration
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Duration) ([]domain.Integration, error)); ok {
+		return rf(ctx, limit, lease)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Duration) []domain.Integration); ok {
+		r0 = rf(ctx, limit, lease)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Integration)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, time.Duration) error); ok {
+		r1 = rf(ctx, limit, lease)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: ctx, integration
+func (_m *IntegrationRepository) Create(ctx context.Context, integration *domain.Integration) error {
+	ret := _m.Called(ctx, integration)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Integration) error); ok {
+		r0 = rf(ctx, integration)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: ctx, tenantID, id
+func (_m *IntegrationRepository) Delete(ctx context.Context, tenantID string, id string) error {
+	ret := _m.Called(ctx, tenantID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, tenantID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Disable provides a mock function with given fields: ctx, integration
+func (_m *IntegrationRepository) Disable(ctx context.Context, integration *domain.Integration) error {
+	ret := _m.Called(ctx, integration)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Disable")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Integration) error); ok {
+		r0 = rf(ctx, integration)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByID provides a mock function with given fields: ctx, tenantID, id
+func (_m *IntegrationRepository) GetByID(ctx context.Context, tenantID string, id string) (*domain.Integration, error) {
+	ret := _m.Called(ctx, tenantID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *domain.Integration
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*domain.Integration, error)); ok {
+		return rf(ctx, tenantID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *domain.Integration); ok {
+		r0 = rf(ctx, tenantID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Integration)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, tenantID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListByTenant provides a mock function with given fields: ctx, tenantID
+func (_m *IntegrationRepository) ListByTenant(ctx context.Context, tenantID string) ([]domain.Integration, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListByTenant")
+	}
+
+	var r0 []domain.Integration
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.Integration, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.Integration); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Integration)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveProgress provides a mock function with given fields: ctx, integration
+func (_m *IntegrationRepository) SaveProgress(ctx context.Context, integration *domain.Integration) error {
+	ret := _m.Called(ctx, integration)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveProgress")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Integration) error); ok {
+		r0 = rf(ctx, integration)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, integration
+func (_m *IntegrationRepository) Update(ctx context.Context, integration *domain.Integration) error {
+	ret := _m.Called(ctx, integration)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Integration) error); ok {
+		r0 = rf(ctx, integration)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewIntegrationRepository creates a new instance of IntegrationRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIntegrationRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IntegrationRepository {
+	mock := &IntegrationRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,52 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+
+// IntegrationTokenOpener is an autogenerated mock type for the IntegrationTokenOpener type
+type IntegrationTokenOpener struct {
+	mock.Mock
+}
+
+// Open provides a mock function with given fields: sealed
+func (_m *IntegrationTokenOpener) Open(sealed []byte) (string, error) {
+	ret := _m.Called(sealed)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Open")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]byte) (string, error)); ok {
+		return rf(sealed)
+	}
+	if rf, ok := ret.Get(0).(func([]byte) string); ok {
+		r0 = rf(sealed)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func([]byte) error); ok {
+		r1 = rf(sealed)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewIntegrationTokenOpener creates a new instance of IntegrationTokenOpener. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIntegrationTokenOpener(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IntegrationTokenOpener {
+	mock := &IntegrationTokenOpener{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
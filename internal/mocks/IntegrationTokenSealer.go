@@ -0,0 +1,54 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+
+// IntegrationTokenSealer is an autogenerated mock type for the IntegrationTokenSealer type
+type IntegrationTokenSealer struct {
+	mock.Mock
+}
+
+// Seal provides a mock function with given fields: token
+func (_m *IntegrationTokenSealer) Seal(token string) ([]byte, error) {
+	ret := _m.Called(token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Seal")
+	}
+
+	var r0 []byte
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]byte, error)); ok {
+		return rf(token)
+	}
+	if rf, ok := ret.Get(0).(func(string) []byte); ok {
+		r0 = rf(token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewIntegrationTokenSealer creates a new instance of IntegrationTokenSealer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIntegrationTokenSealer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IntegrationTokenSealer {
+	mock := &IntegrationTokenSealer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,91 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/kingrain94/audit-log-api/internal/api/dto"
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// JobService is an autogenerated mock type for the JobService type
+type JobService struct {
+	mock.Mock
+}
+
+// Get provides a mock function with given fields: ctx, tenantID, id
+func (_m *JobService) Get(ctx context.Context, tenantID string, id string) (*dto.JobResponse, error) {
+	ret := _m.Called(ctx, tenantID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *dto.JobResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*dto.JobResponse, error)); ok {
+		return rf(ctx, tenantID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *dto.JobResponse); ok {
+		r0 = rf(ctx, tenantID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.JobResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, tenantID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: ctx, filter
+func (_m *JobService) List(ctx context.Context, filter *domain.JobFilter) ([]dto.JobResponse, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []dto.JobResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.JobFilter) ([]dto.JobResponse, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.JobFilter) []dto.JobResponse); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.JobResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.JobFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewJobService creates a new instance of JobService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewJobService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *JobService {
+	mock := &JobService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,34 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// LogEnricher is an autogenerated mock type for the LogEnricher type
+type LogEnricher struct {
+	mock.Mock
+}
+
+// Enrich provides a mock function with given fields: ctx, logs
+func (_m *LogEnricher) Enrich(ctx context.Context, logs []domain.AuditLog) {
+	_m.Called(ctx, logs)
+}
+
+// NewLogEnricher creates a new instance of LogEnricher. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLogEnricher(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LogEnricher {
+	mock := &LogEnricher{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,77 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// LogForwarder is an autogenerated mock type for the LogForwarder type
+type LogForwarder struct {
+	mock.Mock
+}
+
+// Encode provides a mock function with given fields: integration, log
+func (_m *LogForwarder) Encode(integration *domain.Integration, log *domain.AuditLog) ([]byte, error) {
+	ret := _m.Called(integration, log)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Encode")
+	}
+
+	var r0 []byte
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*domain.Integration, *domain.AuditLog) ([]byte, error)); ok {
+		return rf(integration, log)
+	}
+	if rf, ok := ret.Get(0).(func(*domain.Integration, *domain.AuditLog) []byte); ok {
+		r0 = rf(integration, log)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*domain.Integration, *domain.AuditLog) error); ok {
+		r1 = rf(integration, log)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Send provides a mock function with given fields: ctx, integration, events
+func (_m *LogForwarder) Send(ctx context.Context, integration *domain.Integration, events [][]byte) error {
+	ret := _m.Called(ctx, integration, events)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Send")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Integration, [][]byte) error); ok {
+		r0 = rf(ctx, integration, events)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewLogForwarder creates a new instance of LogForwarder. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLogForwarder(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LogForwarder {
+	mock := &LogForwarder{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
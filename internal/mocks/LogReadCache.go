@@ -0,0 +1,143 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// LogReadCache is an autogenerated mock type for the LogReadCache type
+type LogReadCache struct {
+	mock.Mock
+}
+
+// GetLog provides a mock function with given fields: ctx, tenantID, id
+func (_m *LogReadCache) GetLog(ctx context.Context, tenantID string, id string) (*domain.AuditLog, error) {
+	ret := _m.Called(ctx, tenantID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLog")
+	}
+
+	var r0 *domain.AuditLog
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*domain.AuditLog, error)); ok {
+		return rf(ctx, tenantID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *domain.AuditLog); ok {
+		r0 = rf(ctx, tenantID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.AuditLog)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, tenantID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetStats provides a mock function with given fields: ctx, key
+func (_m *LogReadCache) GetStats(ctx context.Context, key string) (*domain.AuditLogStats, error) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetStats")
+	}
+
+	var r0 *domain.AuditLogStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.AuditLogStats, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.AuditLogStats); ok {
+		r0 = rf(ctx, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.AuditLogStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetLog provides a mock function with given fields: ctx, log
+func (_m *LogReadCache) SetLog(ctx context.Context, log *domain.AuditLog) error {
+	ret := _m.Called(ctx, log)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetLog")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLog) error); ok {
+		r0 = rf(ctx, log)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetStats provides a mock function with given fields: ctx, key, stats
+func (_m *LogReadCache) SetStats(ctx context.Context, key string, stats *domain.AuditLogStats) error {
+	ret := _m.Called(ctx, key, stats)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetStats")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *domain.AuditLogStats) error); ok {
+		r0 = rf(ctx, key, stats)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StatsKey provides a mock function with given fields: filter, watermark
+func (_m *LogReadCache) StatsKey(filter *domain.AuditLogFilter, watermark int64) string {
+	ret := _m.Called(filter, watermark)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StatsKey")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(*domain.AuditLogFilter, int64) string); ok {
+		r0 = rf(filter, watermark)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// NewLogReadCache creates a new instance of LogReadCache. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLogReadCache(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LogReadCache {
+	mock := &LogReadCache{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,47 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// LogRedactor is an autogenerated mock type for the LogRedactor type
+type LogRedactor struct {
+	mock.Mock
+}
+
+// Redact provides a mock function with given fields: ctx, logs
+func (_m *LogRedactor) Redact(ctx context.Context, logs []domain.AuditLog) error {
+	ret := _m.Called(ctx, logs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Redact")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.AuditLog) error); ok {
+		r0 = rf(ctx, logs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewLogRedactor creates a new instance of LogRedactor. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLogRedactor(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LogRedactor {
+	mock := &LogRedactor{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,47 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// LogTagger is an autogenerated mock type for the LogTagger type
+type LogTagger struct {
+	mock.Mock
+}
+
+// Tag provides a mock function with given fields: ctx, logs
+func (_m *LogTagger) Tag(ctx context.Context, logs []domain.AuditLog) error {
+	ret := _m.Called(ctx, logs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Tag")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.AuditLog) error); ok {
+		r0 = rf(ctx, logs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewLogTagger creates a new instance of LogTagger. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLogTagger(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LogTagger {
+	mock := &LogTagger{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,175 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// MessagePublisher is an autogenerated mock type for the MessagePublisher type
+type MessagePublisher struct {
+	mock.Mock
+}
+
+// SendArchiveMessage provides a mock function with given fields: ctx, tenantID, jobID, beforeDate
+func (_m *MessagePublisher) SendArchiveMessage(ctx context.Context, tenantID string, jobID string, beforeDate time.Time) error {
+	ret := _m.Called(ctx, tenantID, jobID, beforeDate)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendArchiveMessage")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time) error); ok {
+		r0 = rf(ctx, tenantID, jobID, beforeDate)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SendBulkIndexMessage provides a mock function with given fields: ctx, logs
+func (_m *MessagePublisher) SendBulkIndexMessage(ctx context.Context, logs []domain.AuditLog) error {
+	ret := _m.Called(ctx, logs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendBulkIndexMessage")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.AuditLog) error); ok {
+		r0 = rf(ctx, logs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SendCleanupMessage provides a mock function with given fields: ctx, tenantID, jobID, beforeDate
+func (_m *MessagePublisher) SendCleanupMessage(ctx context.Context, tenantID string, jobID string, beforeDate time.Time) error {
+	ret := _m.Called(ctx, tenantID, jobID, beforeDate)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendCleanupMessage")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time) error); ok {
+		r0 = rf(ctx, tenantID, jobID, beforeDate)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SendExportMessage provides a mock function with given fields: ctx, tenantID, jobID
+func (_m *MessagePublisher) SendExportMessage(ctx context.Context, tenantID string, jobID string) error {
+	ret := _m.Called(ctx, tenantID, jobID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendExportMessage")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, tenantID, jobID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SendImportMessage provides a mock function with given fields: ctx, tenantID, jobID
+func (_m *MessagePublisher) SendImportMessage(ctx context.Context, tenantID string, jobID string) error {
+	ret := _m.Called(ctx, tenantID, jobID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendImportMessage")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, tenantID, jobID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SendIndexMessage provides a mock function with given fields: ctx, log
+func (_m *MessagePublisher) SendIndexMessage(ctx context.Context, log *domain.AuditLog) error {
+	ret := _m.Called(ctx, log)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendIndexMessage")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLog) error); ok {
+		r0 = rf(ctx, log)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SendIngestMessage provides a mock function with given fields: ctx, logs
+func (_m *MessagePublisher) SendIngestMessage(ctx context.Context, logs []domain.AuditLog) error {
+	ret := _m.Called(ctx, logs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendIngestMessage")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.AuditLog) error); ok {
+		r0 = rf(ctx, logs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SendRestoreMessage provides a mock function with given fields: ctx, tenantID, jobID
+func (_m *MessagePublisher) SendRestoreMessage(ctx context.Context, tenantID string, jobID string) error {
+	ret := _m.Called(ctx, tenantID, jobID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendRestoreMessage")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, tenantID, jobID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewMessagePublisher creates a new instance of MessagePublisher. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMessagePublisher(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MessagePublisher {
+	mock := &MessagePublisher{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,47 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/kingrain94/audit-log-api/internal/api/dto"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// OTLPLogService is an autogenerated mock type for the OTLPLogService type
+type OTLPLogService struct {
+	mock.Mock
+}
+
+// BulkCreate provides a mock function with given fields: ctx, reqs
+func (_m *OTLPLogService) BulkCreate(ctx context.Context, reqs []dto.CreateAuditLogRequest) error {
+	ret := _m.Called(ctx, reqs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BulkCreate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []dto.CreateAuditLogRequest) error); ok {
+		r0 = rf(ctx, reqs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewOTLPLogService creates a new instance of OTLPLogService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOTLPLogService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OTLPLogService {
+	mock := &OTLPLogService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
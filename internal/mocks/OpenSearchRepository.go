@@ -52,6 +52,24 @@ func (_m *OpenSearchRepository) CreateIndex(ctx context.Context, tenantID string
 	return r0
 }
 
+// DeleteDailyIndex provides a mock function with given fields: ctx, tenantID, day
+func (_m *OpenSearchRepository) DeleteDailyIndex(ctx context.Context, tenantID string, day time.Time) error {
+	ret := _m.Called(ctx, tenantID, day)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteDailyIndex")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, tenantID, day)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteIndex provides a mock function with given fields: ctx, tenantID
 func (_m *OpenSearchRepository) DeleteIndex(ctx context.Context, tenantID string) error {
 	ret := _m.Called(ctx, tenantID)
@@ -70,6 +88,36 @@ func (_m *OpenSearchRepository) DeleteIndex(ctx context.Context, tenantID string
 	return r0
 }
 
+// GetByIDs provides a mock function with given fields: ctx, ids
+func (_m *OpenSearchRepository) GetByIDs(ctx context.Context, ids []string) ([]domain.AuditLog, error) {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByIDs")
+	}
+
+	var r0 []domain.AuditLog
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]domain.AuditLog, error)); ok {
+		return rf(ctx, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []domain.AuditLog); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.AuditLog)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Index provides a mock function with given fields: ctx, log
 func (_m *OpenSearchRepository) Index(ctx context.Context, log *domain.AuditLog) error {
 	ret := _m.Called(ctx, log)
@@ -88,6 +136,54 @@ func (_m *OpenSearchRepository) Index(ctx context.Context, log *domain.AuditLog)
 	return r0
 }
 
+// ListIndices provides a mock function with given fields: ctx, tenantID
+func (_m *OpenSearchRepository) ListIndices(ctx context.Context, tenantID string) ([]domain.SearchIndex, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListIndices")
+	}
+
+	var r0 []domain.SearchIndex
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.SearchIndex, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.SearchIndex); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.SearchIndex)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Scan provides a mock function with given fields: ctx, filter, size, fn
+func (_m *OpenSearchRepository) Scan(ctx context.Context, filter *domain.AuditLogFilter, size int, fn func([]domain.AuditLog) error) error {
+	ret := _m.Called(ctx, filter, size, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Scan")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLogFilter, int, func([]domain.AuditLog) error) error); ok {
+		r0 = rf(ctx, filter, size, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Search provides a mock function with given fields: ctx, filter
 func (_m *OpenSearchRepository) Search(ctx context.Context, filter *domain.AuditLogFilter) ([]domain.AuditLog, error) {
 	ret := _m.Called(ctx, filter)
@@ -118,6 +214,66 @@ func (_m *OpenSearchRepository) Search(ctx context.Context, filter *domain.Audit
 	return r0, r1
 }
 
+// Stats provides a mock function with given fields: ctx, filter
+func (_m *OpenSearchRepository) Stats(ctx context.Context, filter *domain.AuditLogFilter) (*domain.AuditLogStats, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Stats")
+	}
+
+	var r0 *domain.AuditLogStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLogFilter) (*domain.AuditLogStats, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLogFilter) *domain.AuditLogStats); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.AuditLogStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.AuditLogFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// TopStats provides a mock function with given fields: ctx, filter, limit
+func (_m *OpenSearchRepository) TopStats(ctx context.Context, filter *domain.AuditLogFilter, limit int) (*domain.TopStats, error) {
+	ret := _m.Called(ctx, filter, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TopStats")
+	}
+
+	var r0 *domain.TopStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLogFilter, int) (*domain.TopStats, error)); ok {
+		return rf(ctx, filter, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLogFilter, int) *domain.TopStats); ok {
+		r0 = rf(ctx, filter, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TopStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.AuditLogFilter, int) error); ok {
+		r1 = rf(ctx, filter, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewOpenSearchRepository creates a new instance of OpenSearchRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewOpenSearchRepository(t interface {
@@ -0,0 +1,161 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// OutboxRepository is an autogenerated mock type for the OutboxRepository type
+type OutboxRepository struct {
+	mock.Mock
+}
+
+// ClaimPending provides a mock function with given fields: ctx, limit, lease
+func (_m *OutboxRepository) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxEvent, error) {
+	ret := _m.Called(ctx, limit, lease)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimPending")
+	}
+
+	var r0 []domain.OutboxEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Duration) ([]domain.OutboxEvent, error)); ok {
+		return rf(ctx, limit, lease)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Duration) []domain.OutboxEvent); ok {
+		r0 = rf(ctx, limit, lease)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.OutboxEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, time.Duration) error); ok {
+		r1 = rf(ctx, limit, lease)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: ctx, event
+func (_m *OutboxRepository) Create(ctx context.Context, event *domain.OutboxEvent) error {
+	ret := _m.Called(ctx, event)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.OutboxEvent) error); ok {
+		r0 = rf(ctx, event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteProcessedBefore provides a mock function with given fields: ctx, before
+func (_m *OutboxRepository) DeleteProcessedBefore(ctx context.Context, before time.Time) (int64, error) {
+	ret := _m.Called(ctx, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteProcessedBefore")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int64, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkDeadLettered provides a mock function with given fields: ctx, id, reason
+func (_m *OutboxRepository) MarkDeadLettered(ctx context.Context, id string, reason string) error {
+	ret := _m.Called(ctx, id, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkDeadLettered")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, id, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MarkFailed provides a mock function with given fields: ctx, id, reason
+func (_m *OutboxRepository) MarkFailed(ctx context.Context, id string, reason string) error {
+	ret := _m.Called(ctx, id, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkFailed")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, id, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MarkProcessed provides a mock function with given fields: ctx, ids
+func (_m *OutboxRepository) MarkProcessed(ctx context.Context, ids []string) error {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkProcessed")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) error); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewOutboxRepository creates a new instance of OutboxRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOutboxRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OutboxRepository {
+	mock := &OutboxRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,95 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PolicyCache is an autogenerated mock type for the PolicyCache type
+type PolicyCache struct {
+	mock.Mock
+}
+
+// Get provides a mock function with given fields: ctx, tenantID
+func (_m *PolicyCache) Get(ctx context.Context, tenantID string) ([]domain.Policy, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 []domain.Policy
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.Policy, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.Policy); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Policy)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Invalidate provides a mock function with given fields: ctx, tenantID
+func (_m *PolicyCache) Invalidate(ctx context.Context, tenantID string) error {
+	ret := _m.Called(ctx, tenantID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Invalidate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Set provides a mock function with given fields: ctx, tenantID, policies
+func (_m *PolicyCache) Set(ctx context.Context, tenantID string, policies []domain.Policy) error {
+	ret := _m.Called(ctx, tenantID, policies)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Set")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []domain.Policy) error); ok {
+		r0 = rf(ctx, tenantID, policies)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewPolicyCache creates a new instance of PolicyCache. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPolicyCache(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PolicyCache {
+	mock := &PolicyCache{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,137 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/kingrain94/audit-log-api/internal/api/dto"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PolicyService is an autogenerated mock type for the PolicyService type
+type PolicyService struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, tenantID, req
+func (_m *PolicyService) Create(ctx context.Context, tenantID string, req dto.PolicyRequest) (*dto.PolicyResponse, error) {
+	ret := _m.Called(ctx, tenantID, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *dto.PolicyResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, dto.PolicyRequest) (*dto.PolicyResponse, error)); ok {
+		return rf(ctx, tenantID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, dto.PolicyRequest) *dto.PolicyResponse); ok {
+		r0 = rf(ctx, tenantID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.PolicyResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, dto.PolicyRequest) error); ok {
+		r1 = rf(ctx, tenantID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: ctx, tenantID, id
+func (_m *PolicyService) Delete(ctx context.Context, tenantID string, id string) error {
+	ret := _m.Called(ctx, tenantID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, tenantID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// List provides a mock function with given fields: ctx, tenantID
+func (_m *PolicyService) List(ctx context.Context, tenantID string) ([]dto.PolicyResponse, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []dto.PolicyResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]dto.PolicyResponse, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []dto.PolicyResponse); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.PolicyResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, tenantID, id, req
+func (_m *PolicyService) Update(ctx context.Context, tenantID string, id string, req dto.PolicyRequest) (*dto.PolicyResponse, error) {
+	ret := _m.Called(ctx, tenantID, id, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 *dto.PolicyResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, dto.PolicyRequest) (*dto.PolicyResponse, error)); ok {
+		return rf(ctx, tenantID, id, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, dto.PolicyRequest) *dto.PolicyResponse); ok {
+		r0 = rf(ctx, tenantID, id, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.PolicyResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, dto.PolicyRequest) error); ok {
+		r1 = rf(ctx, tenantID, id, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewPolicyService creates a new instance of PolicyService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPolicyService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PolicyService {
+	mock := &PolicyService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
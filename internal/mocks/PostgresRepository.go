@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	repository "github.com/kingrain94/audit-log-api/internal/repository"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -12,6 +14,46 @@ type PostgresRepository struct {
 	mock.Mock
 }
 
+// Archive provides a mock function with no fields
+func (_m *PostgresRepository) Archive() repository.ArchiveRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Archive")
+	}
+
+	var r0 repository.ArchiveRepository
+	if rf, ok := ret.Get(0).(func() repository.ArchiveRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repository.ArchiveRepository)
+		}
+	}
+
+	return r0
+}
+
+// ArchiveSchedule provides a mock function with no fields
+func (_m *PostgresRepository) ArchiveSchedule() repository.ArchiveScheduleRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ArchiveSchedule")
+	}
+
+	var r0 repository.ArchiveScheduleRepository
+	if rf, ok := ret.Get(0).(func() repository.ArchiveScheduleRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repository.ArchiveScheduleRepository)
+		}
+	}
+
+	return r0
+}
+
 // AuditLog provides a mock function with no fields
 func (_m *PostgresRepository) AuditLog() repository.AuditLogRepository {
 	ret := _m.Called()
@@ -32,6 +74,306 @@ func (_m *PostgresRepository) AuditLog() repository.AuditLogRepository {
 	return r0
 }
 
+// CleanupJob provides a mock function with no fields
+func (_m *PostgresRepository) CleanupJob() repository.CleanupJobRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for CleanupJob")
+	}
+
+	var r0 repository.CleanupJobRepository
+	if rf, ok := ret.Get(0).(func() repository.CleanupJobRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repository.CleanupJobRepository)
+		}
+	}
+
+	return r0
+}
+
+// ExportJob provides a mock function with no fields
+func (_m *PostgresRepository) ExportJob() repository.ExportJobRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportJob")
+	}
+
+	var r0 repository.ExportJobRepository
+	if rf, ok := ret.Get(0).(func() repository.ExportJobRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repository.ExportJobRepository)
+		}
+	}
+
+	return r0
+}
+
+// ImportJob provides a mock function with no fields
+func (_m *PostgresRepository) ImportJob() repository.ImportJobRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportJob")
+	}
+
+	var r0 repository.ImportJobRepository
+	if rf, ok := ret.Get(0).(func() repository.ImportJobRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repository.ImportJobRepository)
+		}
+	}
+
+	return r0
+}
+
+// IndexFailure provides a mock function with no fields
+func (_m *PostgresRepository) IndexFailure() repository.IndexFailureRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for IndexFailure")
+	}
+
+	var r0 repository.IndexFailureRepository
+	if rf, ok := ret.Get(0).(func() repository.IndexFailureRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repository.IndexFailureRepository)
+		}
+	}
+
+	return r0
+}
+
+// Integration provides a mock function with no fields
+func (_m *PostgresRepository) Integration() repository.IntegrationRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Integration")
+	}
+
+	var r0 repository.IntegrationRepository
+	if rf, ok := ret.Get(0).(func() repository.IntegrationRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repository.IntegrationRepository)
+		}
+	}
+
+	return r0
+}
+
+// Job provides a mock function with no fields
+func (_m *PostgresRepository) Job() repository.JobRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Job")
+	}
+
+	var r0 repository.JobRepository
+	if rf, ok := ret.Get(0).(func() repository.JobRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repository.JobRepository)
+		}
+	}
+
+	return r0
+}
+
+// Outbox provides a mock function with no fields
+func (_m *PostgresRepository) Outbox() repository.OutboxRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Outbox")
+	}
+
+	var r0 repository.OutboxRepository
+	if rf, ok := ret.Get(0).(func() repository.OutboxRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repository.OutboxRepository)
+		}
+	}
+
+	return r0
+}
+
+// Policy provides a mock function with no fields
+func (_m *PostgresRepository) Policy() repository.PolicyRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Policy")
+	}
+
+	var r0 repository.PolicyRepository
+	if rf, ok := ret.Get(0).(func() repository.PolicyRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repository.PolicyRepository)
+		}
+	}
+
+	return r0
+}
+
+// RedactionRule provides a mock function with no fields
+func (_m *PostgresRepository) RedactionRule() repository.RedactionRuleRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for RedactionRule")
+	}
+
+	var r0 repository.RedactionRuleRepository
+	if rf, ok := ret.Get(0).(func() repository.RedactionRuleRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repository.RedactionRuleRepository)
+		}
+	}
+
+	return r0
+}
+
+// ReindexJob provides a mock function with no fields
+func (_m *PostgresRepository) ReindexJob() repository.ReindexJobRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReindexJob")
+	}
+
+	var r0 repository.ReindexJobRepository
+	if rf, ok := ret.Get(0).(func() repository.ReindexJobRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repository.ReindexJobRepository)
+		}
+	}
+
+	return r0
+}
+
+// ResourceSchema provides a mock function with no fields
+func (_m *PostgresRepository) ResourceSchema() repository.ResourceSchemaRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResourceSchema")
+	}
+
+	var r0 repository.ResourceSchemaRepository
+	if rf, ok := ret.Get(0).(func() repository.ResourceSchemaRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repository.ResourceSchemaRepository)
+		}
+	}
+
+	return r0
+}
+
+// RestoreJob provides a mock function with no fields
+func (_m *PostgresRepository) RestoreJob() repository.RestoreJobRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreJob")
+	}
+
+	var r0 repository.RestoreJobRepository
+	if rf, ok := ret.Get(0).(func() repository.RestoreJobRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repository.RestoreJobRepository)
+		}
+	}
+
+	return r0
+}
+
+// RetentionPolicy provides a mock function with no fields
+func (_m *PostgresRepository) RetentionPolicy() repository.RetentionPolicyRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetentionPolicy")
+	}
+
+	var r0 repository.RetentionPolicyRepository
+	if rf, ok := ret.Get(0).(func() repository.RetentionPolicyRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repository.RetentionPolicyRepository)
+		}
+	}
+
+	return r0
+}
+
+// SavedSearch provides a mock function with no fields
+func (_m *PostgresRepository) SavedSearch() repository.SavedSearchRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for SavedSearch")
+	}
+
+	var r0 repository.SavedSearchRepository
+	if rf, ok := ret.Get(0).(func() repository.SavedSearchRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repository.SavedSearchRepository)
+		}
+	}
+
+	return r0
+}
+
+// TaggingRule provides a mock function with no fields
+func (_m *PostgresRepository) TaggingRule() repository.TaggingRuleRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for TaggingRule")
+	}
+
+	var r0 repository.TaggingRuleRepository
+	if rf, ok := ret.Get(0).(func() repository.TaggingRuleRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repository.TaggingRuleRepository)
+		}
+	}
+
+	return r0
+}
+
 // Tenant provides a mock function with no fields
 func (_m *PostgresRepository) Tenant() repository.TenantRepository {
 	ret := _m.Called()
@@ -52,6 +394,44 @@ func (_m *PostgresRepository) Tenant() repository.TenantRepository {
 	return r0
 }
 
+// Transaction provides a mock function with given fields: ctx, fn
+func (_m *PostgresRepository) Transaction(ctx context.Context, fn func(repository.PostgresRepository) error) error {
+	ret := _m.Called(ctx, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Transaction")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, func(repository.PostgresRepository) error) error); ok {
+		r0 = rf(ctx, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// User provides a mock function with no fields
+func (_m *PostgresRepository) User() repository.UserRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for User")
+	}
+
+	var r0 repository.UserRepository
+	if rf, ok := ret.Get(0).(func() repository.UserRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repository.UserRepository)
+		}
+	}
+
+	return r0
+}
+
 // NewPostgresRepository creates a new instance of PostgresRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewPostgresRepository(t interface {
@@ -0,0 +1,47 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// QuotaNotifier is an autogenerated mock type for the QuotaNotifier type
+type QuotaNotifier struct {
+	mock.Mock
+}
+
+// NotifyQuotaWarning provides a mock function with given fields: ctx, warning
+func (_m *QuotaNotifier) NotifyQuotaWarning(ctx context.Context, warning domain.QuotaWarning) error {
+	ret := _m.Called(ctx, warning)
+
+	if len(ret) == 0 {
+		panic("no return value specified for NotifyQuotaWarning")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.QuotaWarning) error); ok {
+		r0 = rf(ctx, warning)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewQuotaNotifier creates a new instance of QuotaNotifier. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewQuotaNotifier(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *QuotaNotifier {
+	mock := &QuotaNotifier{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,107 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/kingrain94/audit-log-api/internal/api/dto"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// RedactionService is an autogenerated mock type for the RedactionService type
+type RedactionService struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, tenantID, req
+func (_m *RedactionService) Create(ctx context.Context, tenantID string, req dto.RedactionRuleRequest) (*dto.RedactionRuleResponse, error) {
+	ret := _m.Called(ctx, tenantID, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *dto.RedactionRuleResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, dto.RedactionRuleRequest) (*dto.RedactionRuleResponse, error)); ok {
+		return rf(ctx, tenantID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, dto.RedactionRuleRequest) *dto.RedactionRuleResponse); ok {
+		r0 = rf(ctx, tenantID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.RedactionRuleResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, dto.RedactionRuleRequest) error); ok {
+		r1 = rf(ctx, tenantID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: ctx, tenantID, id
+func (_m *RedactionService) Delete(ctx context.Context, tenantID string, id string) error {
+	ret := _m.Called(ctx, tenantID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, tenantID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// List provides a mock function with given fields: ctx, tenantID
+func (_m *RedactionService) List(ctx context.Context, tenantID string) ([]dto.RedactionRuleResponse, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []dto.RedactionRuleResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]dto.RedactionRuleResponse, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []dto.RedactionRuleResponse); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.RedactionRuleResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewRedactionService creates a new instance of RedactionService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRedactionService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *RedactionService {
+	mock := &RedactionService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,143 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ResourceSchemaRepository is an autogenerated mock type for the ResourceSchemaRepository type
+type ResourceSchemaRepository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, schema
+func (_m *ResourceSchemaRepository) Create(ctx context.Context, schema *domain.ResourceSchema) error {
+	ret := _m.Called(ctx, schema)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.ResourceSchema) error); ok {
+		r0 = rf(ctx, schema)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: ctx, tenantID, id
+func (_m *ResourceSchemaRepository) Delete(ctx context.Context, tenantID string, id string) error {
+	ret := _m.Called(ctx, tenantID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, tenantID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByID provides a mock function with given fields: ctx, tenantID, id
+func (_m *ResourceSchemaRepository) GetByID(ctx context.Context, tenantID string, id string) (*domain.ResourceSchema, error) {
+	ret := _m.Called(ctx, tenantID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *domain.ResourceSchema
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*domain.ResourceSchema, error)); ok {
+		return rf(ctx, tenantID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *domain.ResourceSchema); ok {
+		r0 = rf(ctx, tenantID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.ResourceSchema)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, tenantID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListByTenant provides a mock function with given fields: ctx, tenantID
+func (_m *ResourceSchemaRepository) ListByTenant(ctx context.Context, tenantID string) ([]domain.ResourceSchema, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListByTenant")
+	}
+
+	var r0 []domain.ResourceSchema
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.ResourceSchema, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.ResourceSchema); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ResourceSchema)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, schema
+func (_m *ResourceSchemaRepository) Update(ctx context.Context, schema *domain.ResourceSchema) error {
+	ret := _m.Called(ctx, schema)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.ResourceSchema) error); ok {
+		r0 = rf(ctx, schema)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewResourceSchemaRepository creates a new instance of ResourceSchemaRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewResourceSchemaRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ResourceSchemaRepository {
+	mock := &ResourceSchemaRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,59 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// RetentionPolicyRepository is an autogenerated mock type for the RetentionPolicyRepository type
+type RetentionPolicyRepository struct {
+	mock.Mock
+}
+
+// ListByTenant provides a mock function with given fields: ctx, tenantID
+func (_m *RetentionPolicyRepository) ListByTenant(ctx context.Context, tenantID string) ([]domain.RetentionPolicy, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListByTenant")
+	}
+
+	var r0 []domain.RetentionPolicy
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.RetentionPolicy, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.RetentionPolicy); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.RetentionPolicy)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewRetentionPolicyRepository creates a new instance of RetentionPolicyRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRetentionPolicyRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *RetentionPolicyRepository {
+	mock := &RetentionPolicyRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,79 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// SampledLogCounter is an autogenerated mock type for the SampledLogCounter type
+type SampledLogCounter struct {
+	mock.Mock
+}
+
+// Add provides a mock function with given fields: ctx, tenantID, day, logs
+func (_m *SampledLogCounter) Add(ctx context.Context, tenantID string, day time.Time, logs []domain.AuditLog) error {
+	ret := _m.Called(ctx, tenantID, day, logs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Add")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, []domain.AuditLog) error); ok {
+		r0 = rf(ctx, tenantID, day, logs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Sum provides a mock function with given fields: ctx, tenantID, from, to
+func (_m *SampledLogCounter) Sum(ctx context.Context, tenantID string, from time.Time, to time.Time) (*domain.SampledLogCounts, error) {
+	ret := _m.Called(ctx, tenantID, from, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Sum")
+	}
+
+	var r0 *domain.SampledLogCounts
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) (*domain.SampledLogCounts, error)); ok {
+		return rf(ctx, tenantID, from, to)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) *domain.SampledLogCounts); ok {
+		r0 = rf(ctx, tenantID, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.SampledLogCounts)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, tenantID, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewSampledLogCounter creates a new instance of SampledLogCounter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSampledLogCounter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SampledLogCounter {
+	mock := &SampledLogCounter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
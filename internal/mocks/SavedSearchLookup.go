@@ -0,0 +1,59 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SavedSearchLookup is an autogenerated mock type for the SavedSearchLookup type
+type SavedSearchLookup struct {
+	mock.Mock
+}
+
+// GetFilter provides a mock function with given fields: ctx, tenantID, userID, id
+func (_m *SavedSearchLookup) GetFilter(ctx context.Context, tenantID string, userID string, id string) (*domain.SavedSearchFilter, error) {
+	ret := _m.Called(ctx, tenantID, userID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFilter")
+	}
+
+	var r0 *domain.SavedSearchFilter
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (*domain.SavedSearchFilter, error)); ok {
+		return rf(ctx, tenantID, userID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *domain.SavedSearchFilter); ok {
+		r0 = rf(ctx, tenantID, userID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.SavedSearchFilter)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, tenantID, userID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewSavedSearchLookup creates a new instance of SavedSearchLookup. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSavedSearchLookup(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SavedSearchLookup {
+	mock := &SavedSearchLookup{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,143 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SavedSearchRepository is an autogenerated mock type for the SavedSearchRepository type
+type SavedSearchRepository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, search
+func (_m *SavedSearchRepository) Create(ctx context.Context, search *domain.SavedSearch) error {
+	ret := _m.Called(ctx, search)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.SavedSearch) error); ok {
+		r0 = rf(ctx, search)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: ctx, tenantID, id
+func (_m *SavedSearchRepository) Delete(ctx context.Context, tenantID string, id string) error {
+	ret := _m.Called(ctx, tenantID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, tenantID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByID provides a mock function with given fields: ctx, tenantID, id
+func (_m *SavedSearchRepository) GetByID(ctx context.Context, tenantID string, id string) (*domain.SavedSearch, error) {
+	ret := _m.Called(ctx, tenantID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *domain.SavedSearch
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*domain.SavedSearch, error)); ok {
+		return rf(ctx, tenantID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *domain.SavedSearch); ok {
+		r0 = rf(ctx, tenantID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.SavedSearch)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, tenantID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListVisible provides a mock function with given fields: ctx, tenantID, userID
+func (_m *SavedSearchRepository) ListVisible(ctx context.Context, tenantID string, userID string) ([]domain.SavedSearch, error) {
+	ret := _m.Called(ctx, tenantID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListVisible")
+	}
+
+	var r0 []domain.SavedSearch
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]domain.SavedSearch, error)); ok {
+		return rf(ctx, tenantID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []domain.SavedSearch); ok {
+		r0 = rf(ctx, tenantID, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.SavedSearch)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, tenantID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, search
+func (_m *SavedSearchRepository) Update(ctx context.Context, search *domain.SavedSearch) error {
+	ret := _m.Called(ctx, search)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.SavedSearch) error); ok {
+		r0 = rf(ctx, search)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewSavedSearchRepository creates a new instance of SavedSearchRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSavedSearchRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SavedSearchRepository {
+	mock := &SavedSearchRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,169 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/kingrain94/audit-log-api/internal/api/dto"
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SavedSearchService is an autogenerated mock type for the SavedSearchService type
+type SavedSearchService struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, tenantID, ownerID, req
+func (_m *SavedSearchService) Create(ctx context.Context, tenantID string, ownerID string, req dto.SavedSearchRequest) (*dto.SavedSearchResponse, error) {
+	ret := _m.Called(ctx, tenantID, ownerID, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *dto.SavedSearchResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, dto.SavedSearchRequest) (*dto.SavedSearchResponse, error)); ok {
+		return rf(ctx, tenantID, ownerID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, dto.SavedSearchRequest) *dto.SavedSearchResponse); ok {
+		r0 = rf(ctx, tenantID, ownerID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.SavedSearchResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, dto.SavedSearchRequest) error); ok {
+		r1 = rf(ctx, tenantID, ownerID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: ctx, tenantID, userID, id
+func (_m *SavedSearchService) Delete(ctx context.Context, tenantID string, userID string, id string) error {
+	ret := _m.Called(ctx, tenantID, userID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, tenantID, userID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetFilter provides a mock function with given fields: ctx, tenantID, userID, id
+func (_m *SavedSearchService) GetFilter(ctx context.Context, tenantID string, userID string, id string) (*domain.SavedSearchFilter, error) {
+	ret := _m.Called(ctx, tenantID, userID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFilter")
+	}
+
+	var r0 *domain.SavedSearchFilter
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (*domain.SavedSearchFilter, error)); ok {
+		return rf(ctx, tenantID, userID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *domain.SavedSearchFilter); ok {
+		r0 = rf(ctx, tenantID, userID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.SavedSearchFilter)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, tenantID, userID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: ctx, tenantID, userID
+func (_m *SavedSearchService) List(ctx context.Context, tenantID string, userID string) ([]dto.SavedSearchResponse, error) {
+	ret := _m.Called(ctx, tenantID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []dto.SavedSearchResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]dto.SavedSearchResponse, error)); ok {
+		return rf(ctx, tenantID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []dto.SavedSearchResponse); ok {
+		r0 = rf(ctx, tenantID, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.SavedSearchResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, tenantID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, tenantID, userID, id, req
+func (_m *SavedSearchService) Update(ctx context.Context, tenantID string, userID string, id string, req dto.SavedSearchRequest) (*dto.SavedSearchResponse, error) {
+	ret := _m.Called(ctx, tenantID, userID, id, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 *dto.SavedSearchResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, dto.SavedSearchRequest) (*dto.SavedSearchResponse, error)); ok {
+		return rf(ctx, tenantID, userID, id, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, dto.SavedSearchRequest) *dto.SavedSearchResponse); ok {
+		r0 = rf(ctx, tenantID, userID, id, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.SavedSearchResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, dto.SavedSearchRequest) error); ok {
+		r1 = rf(ctx, tenantID, userID, id, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewSavedSearchService creates a new instance of SavedSearchService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSavedSearchService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SavedSearchService {
+	mock := &SavedSearchService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,139 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/kingrain94/audit-log-api/internal/api/dto"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// SearchIndexService is an autogenerated mock type for the SearchIndexService type
+type SearchIndexService struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: ctx, tenantID, day
+func (_m *SearchIndexService) Delete(ctx context.Context, tenantID string, day time.Time) error {
+	ret := _m.Called(ctx, tenantID, day)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, tenantID, day)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Ensure provides a mock function with given fields: ctx, tenantID, start, end
+func (_m *SearchIndexService) Ensure(ctx context.Context, tenantID string, start time.Time, end time.Time) (*dto.EnsureIndicesResponse, error) {
+	ret := _m.Called(ctx, tenantID, start, end)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Ensure")
+	}
+
+	var r0 *dto.EnsureIndicesResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) (*dto.EnsureIndicesResponse, error)); ok {
+		return rf(ctx, tenantID, start, end)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) *dto.EnsureIndicesResponse); ok {
+		r0 = rf(ctx, tenantID, start, end)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.EnsureIndicesResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, tenantID, start, end)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: ctx, tenantID
+func (_m *SearchIndexService) List(ctx context.Context, tenantID string) ([]dto.SearchIndexResponse, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []dto.SearchIndexResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]dto.SearchIndexResponse, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []dto.SearchIndexResponse); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.SearchIndexResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Reindex provides a mock function with given fields: ctx, tenantID, start, end
+func (_m *SearchIndexService) Reindex(ctx context.Context, tenantID string, start time.Time, end time.Time) (*dto.ReindexResponse, error) {
+	ret := _m.Called(ctx, tenantID, start, end)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reindex")
+	}
+
+	var r0 *dto.ReindexResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) (*dto.ReindexResponse, error)); ok {
+		return rf(ctx, tenantID, start, end)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) *dto.ReindexResponse); ok {
+		r0 = rf(ctx, tenantID, start, end)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.ReindexResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, tenantID, start, end)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewSearchIndexService creates a new instance of SearchIndexService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSearchIndexService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SearchIndexService {
+	mock := &SearchIndexService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,95 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TaggingRuleCache is an autogenerated mock type for the TaggingRuleCache type
+type TaggingRuleCache struct {
+	mock.Mock
+}
+
+// Get provides a mock function with given fields: ctx, tenantID
+func (_m *TaggingRuleCache) Get(ctx context.Context, tenantID string) ([]domain.TaggingRule, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 []domain.TaggingRule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.TaggingRule, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.TaggingRule); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.TaggingRule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Invalidate provides a mock function with given fields: ctx, tenantID
+func (_m *TaggingRuleCache) Invalidate(ctx context.Context, tenantID string) error {
+	ret := _m.Called(ctx, tenantID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Invalidate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Set provides a mock function with given fields: ctx, tenantID, rules
+func (_m *TaggingRuleCache) Set(ctx context.Context, tenantID string, rules []domain.TaggingRule) error {
+	ret := _m.Called(ctx, tenantID, rules)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Set")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []domain.TaggingRule) error); ok {
+		r0 = rf(ctx, tenantID, rules)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewTaggingRuleCache creates a new instance of TaggingRuleCache. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTaggingRuleCache(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TaggingRuleCache {
+	mock := &TaggingRuleCache{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
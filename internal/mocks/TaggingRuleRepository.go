@@ -0,0 +1,95 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TaggingRuleRepository is an autogenerated mock type for the TaggingRuleRepository type
+type TaggingRuleRepository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, rule
+func (_m *TaggingRuleRepository) Create(ctx context.Context, rule *domain.TaggingRule) error {
+	ret := _m.Called(ctx, rule)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.TaggingRule) error); ok {
+		r0 = rf(ctx, rule)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: ctx, tenantID, id
+func (_m *TaggingRuleRepository) Delete(ctx context.Context, tenantID string, id string) error {
+	ret := _m.Called(ctx, tenantID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, tenantID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ListByTenant provides a mock function with given fields: ctx, tenantID
+func (_m *TaggingRuleRepository) ListByTenant(ctx context.Context, tenantID string) ([]domain.TaggingRule, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListByTenant")
+	}
+
+	var r0 []domain.TaggingRule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.TaggingRule, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.TaggingRule); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.TaggingRule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewTaggingRuleRepository creates a new instance of TaggingRuleRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTaggingRuleRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TaggingRuleRepository {
+	mock := &TaggingRuleRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,89 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/kingrain94/audit-log-api/internal/api/dto"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TenantExportService is an autogenerated mock type for the TenantExportService type
+type TenantExportService struct {
+	mock.Mock
+}
+
+// CreateTenantExportJob provides a mock function with given fields: ctx, tenantID
+func (_m *TenantExportService) CreateTenantExportJob(ctx context.Context, tenantID string) (*dto.ExportJobResponse, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateTenantExportJob")
+	}
+
+	var r0 *dto.ExportJobResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*dto.ExportJobResponse, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *dto.ExportJobResponse); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.ExportJobResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetExportJob provides a mock function with given fields: ctx, tenantID, jobID
+func (_m *TenantExportService) GetExportJob(ctx context.Context, tenantID string, jobID string) (*dto.ExportJobResponse, error) {
+	ret := _m.Called(ctx, tenantID, jobID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetExportJob")
+	}
+
+	var r0 *dto.ExportJobResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*dto.ExportJobResponse, error)); ok {
+		return rf(ctx, tenantID, jobID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *dto.ExportJobResponse); ok {
+		r0 = rf(ctx, tenantID, jobID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.ExportJobResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, tenantID, jobID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewTenantExportService creates a new instance of TenantExportService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTenantExportService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TenantExportService {
+	mock := &TenantExportService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -7,6 +7,8 @@ import (
 
 	domain "github.com/kingrain94/audit-log-api/internal/domain"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // TenantRepository is an autogenerated mock type for the TenantRepository type
@@ -44,17 +46,17 @@ func (_m *TenantRepository) Create(ctx context.Context, tenant *domain.Tenant) (
 	return r0, r1
 }
 
-// Delete provides a mock function with given fields: ctx, id
-func (_m *TenantRepository) Delete(ctx context.Context, id string) error {
-	ret := _m.Called(ctx, id)
+// Delete provides a mock function with given fields: ctx, id, purgeAfter
+func (_m *TenantRepository) Delete(ctx context.Context, id string, purgeAfter time.Time) error {
+	ret := _m.Called(ctx, id, purgeAfter)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Delete")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, id, purgeAfter)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -92,6 +94,36 @@ func (_m *TenantRepository) GetByID(ctx context.Context, id string) (*domain.Ten
 	return r0, r1
 }
 
+// GetDeleted provides a mock function with given fields: ctx, id
+func (_m *TenantRepository) GetDeleted(ctx context.Context, id string) (*domain.Tenant, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeleted")
+	}
+
+	var r0 *domain.Tenant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.Tenant, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.Tenant); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Tenant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // List provides a mock function with given fields: ctx
 func (_m *TenantRepository) List(ctx context.Context) ([]domain.Tenant, error) {
 	ret := _m.Called(ctx)
@@ -122,6 +154,90 @@ func (_m *TenantRepository) List(ctx context.Context) ([]domain.Tenant, error) {
 	return r0, r1
 }
 
+// ListPurgeable provides a mock function with given fields: ctx, now
+func (_m *TenantRepository) ListPurgeable(ctx context.Context, now time.Time) ([]domain.Tenant, error) {
+	ret := _m.Called(ctx, now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListPurgeable")
+	}
+
+	var r0 []domain.Tenant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]domain.Tenant, error)); ok {
+		return rf(ctx, now)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []domain.Tenant); ok {
+		r0 = rf(ctx, now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Tenant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkPurgeStarted provides a mock function with given fields: ctx, id, startedAt
+func (_m *TenantRepository) MarkPurgeStarted(ctx context.Context, id string, startedAt time.Time) error {
+	ret := _m.Called(ctx, id, startedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkPurgeStarted")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, id, startedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Purge provides a mock function with given fields: ctx, id
+func (_m *TenantRepository) Purge(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Purge")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Restore provides a mock function with given fields: ctx, id
+func (_m *TenantRepository) Restore(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Restore")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: ctx, tenant
 func (_m *TenantRepository) Update(ctx context.Context, tenant *domain.Tenant) error {
 	ret := _m.Called(ctx, tenant)
@@ -45,21 +45,63 @@ func (_m *TenantService) Create(ctx context.Context, req dto.CreateTenantRequest
 }
 
 // Delete provides a mock function with given fields: ctx, id
-func (_m *TenantService) Delete(ctx context.Context, id string) error {
+func (_m *TenantService) Delete(ctx context.Context, id string) (*domain.Tenant, error) {
 	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Delete")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+	var r0 *domain.Tenant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.Tenant, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.Tenant); ok {
 		r0 = rf(ctx, id)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Tenant)
+		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetArchiveSchedule provides a mock function with given fields: ctx, tenantID
+func (_m *TenantService) GetArchiveSchedule(ctx context.Context, tenantID string) (*domain.ArchiveSchedule, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetArchiveSchedule")
+	}
+
+	var r0 *domain.ArchiveSchedule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.ArchiveSchedule, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.ArchiveSchedule); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.ArchiveSchedule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // GetByID provides a mock function with given fields: ctx, id
@@ -92,6 +134,66 @@ func (_m *TenantService) GetByID(ctx context.Context, id string) (*domain.Tenant
 	return r0, r1
 }
 
+// GetRateLimit provides a mock function with given fields: ctx, tenantID
+func (_m *TenantService) GetRateLimit(ctx context.Context, tenantID string) (*domain.TenantRateLimit, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRateLimit")
+	}
+
+	var r0 *domain.TenantRateLimit
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.TenantRateLimit, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.TenantRateLimit); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TenantRateLimit)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSettings provides a mock function with given fields: ctx, tenantID
+func (_m *TenantService) GetSettings(ctx context.Context, tenantID string) (*domain.Tenant, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSettings")
+	}
+
+	var r0 *domain.Tenant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.Tenant, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.Tenant); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Tenant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // List provides a mock function with given fields: ctx
 func (_m *TenantService) List(ctx context.Context) ([]dto.CreateTenantResponse, error) {
 	ret := _m.Called(ctx)
@@ -122,6 +224,36 @@ func (_m *TenantService) List(ctx context.Context) ([]dto.CreateTenantResponse,
 	return r0, r1
 }
 
+// Restore provides a mock function with given fields: ctx, id
+func (_m *TenantService) Restore(ctx context.Context, id string) (*domain.Tenant, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Restore")
+	}
+
+	var r0 *domain.Tenant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.Tenant, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.Tenant); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Tenant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: ctx, tenant
 func (_m *TenantService) Update(ctx context.Context, tenant *domain.Tenant) error {
 	ret := _m.Called(ctx, tenant)
@@ -140,6 +272,96 @@ func (_m *TenantService) Update(ctx context.Context, tenant *domain.Tenant) erro
 	return r0
 }
 
+// UpdateArchiveSchedule provides a mock function with given fields: ctx, tenantID, req
+func (_m *TenantService) UpdateArchiveSchedule(ctx context.Context, tenantID string, req dto.UpdateArchiveScheduleRequest) (*domain.ArchiveSchedule, error) {
+	ret := _m.Called(ctx, tenantID, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateArchiveSchedule")
+	}
+
+	var r0 *domain.ArchiveSchedule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, dto.UpdateArchiveScheduleRequest) (*domain.ArchiveSchedule, error)); ok {
+		return rf(ctx, tenantID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, dto.UpdateArchiveScheduleRequest) *domain.ArchiveSchedule); ok {
+		r0 = rf(ctx, tenantID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.ArchiveSchedule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, dto.UpdateArchiveScheduleRequest) error); ok {
+		r1 = rf(ctx, tenantID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateRateLimit provides a mock function with given fields: ctx, tenantID, req
+func (_m *TenantService) UpdateRateLimit(ctx context.Context, tenantID string, req dto.UpdateTenantRateLimitRequest) (*domain.TenantRateLimit, error) {
+	ret := _m.Called(ctx, tenantID, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateRateLimit")
+	}
+
+	var r0 *domain.TenantRateLimit
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, dto.UpdateTenantRateLimitRequest) (*domain.TenantRateLimit, error)); ok {
+		return rf(ctx, tenantID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, dto.UpdateTenantRateLimitRequest) *domain.TenantRateLimit); ok {
+		r0 = rf(ctx, tenantID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TenantRateLimit)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, dto.UpdateTenantRateLimitRequest) error); ok {
+		r1 = rf(ctx, tenantID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateSettings provides a mock function with given fields: ctx, tenantID, req
+func (_m *TenantService) UpdateSettings(ctx context.Context, tenantID string, req dto.UpdateTenantSettingsRequest) (*domain.Tenant, error) {
+	ret := _m.Called(ctx, tenantID, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateSettings")
+	}
+
+	var r0 *domain.Tenant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, dto.UpdateTenantSettingsRequest) (*domain.Tenant, error)); ok {
+		return rf(ctx, tenantID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, dto.UpdateTenantSettingsRequest) *domain.Tenant); ok {
+		r0 = rf(ctx, tenantID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Tenant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, dto.UpdateTenantSettingsRequest) error); ok {
+		r1 = rf(ctx, tenantID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewTenantService creates a new instance of TenantService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewTenantService(t interface {
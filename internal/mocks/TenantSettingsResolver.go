@@ -0,0 +1,59 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TenantSettingsResolver is an autogenerated mock type for the TenantSettingsResolver type
+type TenantSettingsResolver struct {
+	mock.Mock
+}
+
+// ResolveSettings provides a mock function with given fields: ctx, tenantID
+func (_m *TenantSettingsResolver) ResolveSettings(ctx context.Context, tenantID string) (*domain.TenantSettings, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResolveSettings")
+	}
+
+	var r0 *domain.TenantSettings
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.TenantSettings, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.TenantSettings); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TenantSettings)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewTenantSettingsResolver creates a new instance of TenantSettingsResolver. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTenantSettingsResolver(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TenantSettingsResolver {
+	mock := &TenantSettingsResolver{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,61 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/kingrain94/audit-log-api/internal/api/dto"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// TenantUsageService is an autogenerated mock type for the TenantUsageService type
+type TenantUsageService struct {
+	mock.Mock
+}
+
+// GetUsage provides a mock function with given fields: ctx, tenantID, from, to
+func (_m *TenantUsageService) GetUsage(ctx context.Context, tenantID string, from time.Time, to time.Time) (*dto.TenantUsageResponse, error) {
+	ret := _m.Called(ctx, tenantID, from, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUsage")
+	}
+
+	var r0 *dto.TenantUsageResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) (*dto.TenantUsageResponse, error)); ok {
+		return rf(ctx, tenantID, from, to)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) *dto.TenantUsageResponse); ok {
+		r0 = rf(ctx, tenantID, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.TenantUsageResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, tenantID, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewTenantUsageService creates a new instance of TenantUsageService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTenantUsageService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TenantUsageService {
+	mock := &TenantUsageService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,191 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// TokenStore is an autogenerated mock type for the TokenStore type
+type TokenStore struct {
+	mock.Mock
+}
+
+// ConsumeStreamTicket provides a mock function with given fields: ctx, ticketHash
+func (_m *TokenStore) ConsumeStreamTicket(ctx context.Context, ticketHash string) (*domain.StreamTicket, error) {
+	ret := _m.Called(ctx, ticketHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConsumeStreamTicket")
+	}
+
+	var r0 *domain.StreamTicket
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.StreamTicket, error)); ok {
+		return rf(ctx, ticketHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.StreamTicket); ok {
+		r0 = rf(ctx, ticketHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.StreamTicket)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, ticketHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRefreshToken provides a mock function with given fields: ctx, tokenHash
+func (_m *TokenStore) GetRefreshToken(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	ret := _m.Called(ctx, tokenHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRefreshToken")
+	}
+
+	var r0 *domain.RefreshToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.RefreshToken, error)); ok {
+		return rf(ctx, tokenHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.RefreshToken); ok {
+		r0 = rf(ctx, tokenHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.RefreshToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tokenHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeAccessToken provides a mock function with given fields: ctx, tokenID, expiresAt
+func (_m *TokenStore) RevokeAccessToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	ret := _m.Called(ctx, tokenID, expiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAccessToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, tokenID, expiresAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeRefreshFamily provides a mock function with given fields: ctx, familyID
+func (_m *TokenStore) RevokeRefreshFamily(ctx context.Context, familyID string) error {
+	ret := _m.Called(ctx, familyID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeRefreshFamily")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, familyID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RotateRefreshToken provides a mock function with given fields: ctx, current, next
+func (_m *TokenStore) RotateRefreshToken(ctx context.Context, current *domain.RefreshToken, next *domain.RefreshToken) (bool, error) {
+	ret := _m.Called(ctx, current, next)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RotateRefreshToken")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.RefreshToken, *domain.RefreshToken) (bool, error)); ok {
+		return rf(ctx, current, next)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.RefreshToken, *domain.RefreshToken) bool); ok {
+		r0 = rf(ctx, current, next)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.RefreshToken, *domain.RefreshToken) error); ok {
+		r1 = rf(ctx, current, next)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveRefreshToken provides a mock function with given fields: ctx, token
+func (_m *TokenStore) SaveRefreshToken(ctx context.Context, token *domain.RefreshToken) error {
+	ret := _m.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveRefreshToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.RefreshToken) error); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveStreamTicket provides a mock function with given fields: ctx, ticket
+func (_m *TokenStore) SaveStreamTicket(ctx context.Context, ticket *domain.StreamTicket) error {
+	ret := _m.Called(ctx, ticket)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveStreamTicket")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.StreamTicket) error); ok {
+		r0 = rf(ctx, ticket)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewTokenStore creates a new instance of TokenStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTokenStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TokenStore {
+	mock := &TokenStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,125 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// UsageStore is an autogenerated mock type for the UsageStore type
+type UsageStore struct {
+	mock.Mock
+}
+
+// Add provides a mock function with given fields: ctx, tenantID, day, logs, bytes
+func (_m *UsageStore) Add(ctx context.Context, tenantID string, day time.Time, logs int64, bytes int64) error {
+	ret := _m.Called(ctx, tenantID, day, logs, bytes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Add")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, int64, int64) error); ok {
+		r0 = rf(ctx, tenantID, day, logs, bytes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Days provides a mock function with given fields: ctx, tenantID, from, to
+func (_m *UsageStore) Days(ctx context.Context, tenantID string, from time.Time, to time.Time) ([]domain.TenantUsage, error) {
+	ret := _m.Called(ctx, tenantID, from, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Days")
+	}
+
+	var r0 []domain.TenantUsage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) ([]domain.TenantUsage, error)); ok {
+		return rf(ctx, tenantID, from, to)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) []domain.TenantUsage); ok {
+		r0 = rf(ctx, tenantID, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.TenantUsage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, tenantID, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkQuotaWarned provides a mock function with given fields: ctx, tenantID, quota, period
+func (_m *UsageStore) MarkQuotaWarned(ctx context.Context, tenantID string, quota domain.Quota, period string) (bool, error) {
+	ret := _m.Called(ctx, tenantID, quota, period)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkQuotaWarned")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.Quota, string) (bool, error)); ok {
+		return rf(ctx, tenantID, quota, period)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.Quota, string) bool); ok {
+		r0 = rf(ctx, tenantID, quota, period)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, domain.Quota, string) error); ok {
+		r1 = rf(ctx, tenantID, quota, period)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UnmarkQuotaWarned provides a mock function with given fields: ctx, tenantID, quota, period
+func (_m *UsageStore) UnmarkQuotaWarned(ctx context.Context, tenantID string, quota domain.Quota, period string) error {
+	ret := _m.Called(ctx, tenantID, quota, period)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UnmarkQuotaWarned")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.Quota, string) error); ok {
+		r0 = rf(ctx, tenantID, quota, period)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewUsageStore creates a new instance of UsageStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUsageStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UsageStore {
+	mock := &UsageStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,64 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// UsageTracker is an autogenerated mock type for the UsageTracker type
+type UsageTracker struct {
+	mock.Mock
+}
+
+// CheckQuota provides a mock function with given fields: ctx, tenantID, logs
+func (_m *UsageTracker) CheckQuota(ctx context.Context, tenantID string, logs int64) error {
+	ret := _m.Called(ctx, tenantID, logs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckQuota")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) error); ok {
+		r0 = rf(ctx, tenantID, logs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Record provides a mock function with given fields: ctx, tenantID, logs, bytes
+func (_m *UsageTracker) Record(ctx context.Context, tenantID string, logs int64, bytes int64) error {
+	ret := _m.Called(ctx, tenantID, logs, bytes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Record")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, int64) error); ok {
+		r0 = rf(ctx, tenantID, logs, bytes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewUsageTracker creates a new instance of UsageTracker. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUsageTracker(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UsageTracker {
+	mock := &UsageTracker{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
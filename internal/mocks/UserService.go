@@ -0,0 +1,151 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/kingrain94/audit-log-api/internal/api/dto"
+	domain "github.com/kingrain94/audit-log-api/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// UserService is an autogenerated mock type for the UserService type
+type UserService struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, tenantID, req
+func (_m *UserService) Create(ctx context.Context, tenantID string, req dto.CreateUserRequest) (*dto.UserResponse, error) {
+	ret := _m.Called(ctx, tenantID, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *dto.UserResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, dto.CreateUserRequest) (*dto.UserResponse, error)); ok {
+		return rf(ctx, tenantID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, dto.CreateUserRequest) *dto.UserResponse); ok {
+		r0 = rf(ctx, tenantID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.UserResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, dto.CreateUserRequest) error); ok {
+		r1 = rf(ctx, tenantID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Deactivate provides a mock function with given fields: ctx, tenantID, id
+func (_m *UserService) Deactivate(ctx context.Context, tenantID string, id string) (*dto.UserResponse, error) {
+	ret := _m.Called(ctx, tenantID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Deactivate")
+	}
+
+	var r0 *dto.UserResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*dto.UserResponse, error)); ok {
+		return rf(ctx, tenantID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *dto.UserResponse); ok {
+		r0 = rf(ctx, tenantID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.UserResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, tenantID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: ctx, filter
+func (_m *UserService) List(ctx context.Context, filter *domain.UserFilter) ([]dto.UserResponse, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []dto.UserResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.UserFilter) ([]dto.UserResponse, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.UserFilter) []dto.UserResponse); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.UserResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.UserFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateRoles provides a mock function with given fields: ctx, tenantID, id, roles
+func (_m *UserService) UpdateRoles(ctx context.Context, tenantID string, id string, roles []string) (*dto.UserResponse, error) {
+	ret := _m.Called(ctx, tenantID, id, roles)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateRoles")
+	}
+
+	var r0 *dto.UserResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []string) (*dto.UserResponse, error)); ok {
+		return rf(ctx, tenantID, id, roles)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []string) *dto.UserResponse); ok {
+		r0 = rf(ctx, tenantID, id, roles)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.UserResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, []string) error); ok {
+		r1 = rf(ctx, tenantID, id, roles)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewUserService creates a new instance of UserService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUserService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UserService {
+	mock := &UserService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
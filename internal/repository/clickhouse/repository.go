@@ -0,0 +1,240 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/google/uuid"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/utils"
+)
+
+// table is the ReplacingMergeTree created by scripts/clickhouse/001_audit_logs.sql.
+// Duplicate rows are merged away in the background, so queries read it with
+// FINAL to count each log once in the meantime.
+const table = "audit_logs"
+
+type analyticsRepository struct {
+	conn driver.Conn
+}
+
+func NewAnalyticsRepository(conn driver.Conn) repository.AnalyticsRepository {
+	return &tracedRepository{
+		next: &analyticsRepository{conn: conn},
+	}
+}
+
+func (r *analyticsRepository) Insert(ctx context.Context, logs []domain.AuditLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	batch, err := r.conn.PrepareBatch(ctx, "INSERT INTO "+table+
+		" (id, tenant_id, user_id, session_id, correlation_id, ip_address, user_agent,"+
+		" action, resource_type, resource_id, severity, message, tags, threat_score, timestamp)")
+	if err != nil {
+		return fmt.Errorf("failed to prepare batch: %w", err)
+	}
+	defer batch.Abort()
+
+	for _, log := range logs {
+		id, err := uuid.Parse(log.ID)
+		if err != nil {
+			return fmt.Errorf("invalid log ID %q: %w", log.ID, err)
+		}
+		if err := batch.Append(
+			id, log.TenantID, log.UserID, log.SessionID, log.CorrelationID, log.IPAddress, log.UserAgent,
+			log.Action, log.ResourceType, log.ResourceID, log.Severity, log.Message, []string(log.Tags),
+			uint8(min(max(log.ThreatScore(), 0), 100)), log.Timestamp.UTC(),
+		); err != nil {
+			return fmt.Errorf("failed to append log %s: %w", log.ID, err)
+		}
+	}
+
+	if err := batch.Send(); err != nil {
+		return fmt.Errorf("failed to insert logs: %w", err)
+	}
+	return nil
+}
+
+func (r *analyticsRepository) Stats(ctx context.Context, filter *domain.AuditLogFilter) (*domain.AuditLogStats, error) {
+	tenantID := filter.TenantID
+	if tenantID == "" {
+		var err error
+		if tenantID, err = utils.GetTenantIDFromContext(ctx); err != nil {
+			return nil, fmt.Errorf("failed to get tenant ID from context: %w", err)
+		}
+	}
+
+	interval := domain.StatsInterval(filter.StartTime, filter.EndTime)
+	where, args := buildWhere(tenantID, filter)
+
+	// One pass counts every combination; the counts per field and per bucket
+	// are summed up from it
+	query := fmt.Sprintf(`
+		SELECT action, severity, resource_type,
+			toStartOfInterval(timestamp, INTERVAL %d SECOND) AS bucket,
+			count() AS count
+		FROM %s FINAL
+		WHERE %s
+		GROUP BY action, severity, resource_type, bucket`,
+		int64(interval/time.Second), table, where)
+
+	rows, err := r.conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := &domain.AuditLogStats{
+		ActionCounts:   make(map[domain.ActionType]int64),
+		SeverityCounts: make(map[domain.SeverityLevel]int64),
+		ResourceCounts: make(map[string]int64),
+		Interval:       interval,
+	}
+	buckets := make(map[int64]int64)
+	for rows.Next() {
+		var (
+			action, severity, resourceType string
+			bucket                         time.Time
+			count                          uint64
+		)
+		if err := rows.Scan(&action, &severity, &resourceType, &bucket, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan stats: %w", err)
+		}
+		n := int64(count)
+		stats.TotalLogs += n
+		stats.ActionCounts[domain.ActionType(action)] += n
+		stats.SeverityCounts[domain.SeverityLevel(severity)] += n
+		if resourceType != "" {
+			stats.ResourceCounts[resourceType] += n
+		}
+		buckets[bucket.Unix()] += n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stats: %w", err)
+	}
+
+	stats.Series = series(buckets, filter.StartTime, filter.EndTime, interval)
+	return stats, nil
+}
+
+// buildWhere returns the conditions matching the filter with their arguments.
+// Text filters match case-insensitive substrings; the full-text query is left
+// to OpenSearch.
+func buildWhere(tenantID string, filter *domain.AuditLogFilter) (string, []any) {
+	conditions := []string{"tenant_id = ?"}
+	args := []any{tenantID}
+
+	exactMatches := []struct {
+		column string
+		value  string
+	}{
+		{"user_id", filter.UserID},
+		{"session_id", filter.SessionID},
+		{"correlation_id", filter.CorrelationID},
+		{"ip_address", filter.IPAddress},
+		{"resource_id", filter.ResourceID},
+	}
+	for _, match := range exactMatches {
+		if match.value != "" {
+			conditions = append(conditions, match.column+" = ?")
+			args = append(args, match.value)
+		}
+	}
+	if filter.VisibleUserID != "" {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, filter.VisibleUserID)
+	}
+
+	valueMatches := []struct {
+		column string
+		values domain.ValueFilter
+	}{
+		{"action", filter.Action},
+		{"resource_type", filter.ResourceType},
+		{"severity", filter.Severity},
+	}
+	for _, match := range valueMatches {
+		if len(match.values.In) > 0 {
+			conditions = append(conditions, "has(?, "+match.column+")")
+			args = append(args, match.values.In)
+		}
+		if len(match.values.NotIn) > 0 {
+			conditions = append(conditions, "NOT has(?, "+match.column+")")
+			args = append(args, match.values.NotIn)
+		}
+	}
+	if len(filter.Tags.In) > 0 {
+		conditions = append(conditions, "hasAny(tags, ?)")
+		args = append(args, filter.Tags.In)
+	}
+	if len(filter.Tags.NotIn) > 0 {
+		conditions = append(conditions, "NOT hasAny(tags, ?)")
+		args = append(args, filter.Tags.NotIn)
+	}
+	if filter.MinThreatScore > 0 {
+		conditions = append(conditions, "threat_score >= ?")
+		args = append(args, filter.MinThreatScore)
+	}
+
+	textMatches := []struct {
+		column string
+		value  string
+	}{
+		{"user_agent", filter.UserAgent},
+		{"message", filter.Message},
+	}
+	for _, match := range textMatches {
+		if match.value != "" {
+			conditions = append(conditions, "positionCaseInsensitiveUTF8("+match.column+", ?) > 0")
+			args = append(args, match.value)
+		}
+	}
+
+	if !filter.StartTime.IsZero() {
+		conditions = append(conditions, "timestamp >= ?")
+		args = append(args, filter.StartTime.UTC())
+	}
+	if !filter.EndTime.IsZero() {
+		conditions = append(conditions, "timestamp <= ?")
+		args = append(args, filter.EndTime.UTC())
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// series lists the bucket counts in time order. With both ends of the range
+// set, empty buckets between them are included, as in OpenSearch's histogram.
+func series(buckets map[int64]int64, start, end time.Time, interval time.Duration) []domain.AuditLogStatsBucket {
+	step := int64(interval / time.Second)
+	if !start.IsZero() && !end.IsZero() {
+		// Buckets are aligned to the Unix epoch
+		for t := start.Unix() / step * step; t <= end.Unix(); t += step {
+			if _, ok := buckets[t]; !ok {
+				buckets[t] = 0
+			}
+		}
+	}
+
+	starts := make([]int64, 0, len(buckets))
+	for t := range buckets {
+		starts = append(starts, t)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i] < starts[j] })
+
+	result := make([]domain.AuditLogStatsBucket, 0, len(starts))
+	for _, t := range starts {
+		result = append(result, domain.AuditLogStatsBucket{
+			Start: time.Unix(t, 0).UTC(),
+			Count: buckets[t],
+		})
+	}
+	return result
+}
@@ -0,0 +1,43 @@
+package clickhouse
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+)
+
+// tracedRepository wraps an AnalyticsRepository with one client span per operation
+type tracedRepository struct {
+	next repository.AnalyticsRepository
+}
+
+func startSpan(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, attribute.String("db.system", "clickhouse"))
+	return tracing.Start(ctx, "clickhouse."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+func (r *tracedRepository) Insert(ctx context.Context, logs []domain.AuditLog) error {
+	attrs := []attribute.KeyValue{attribute.Int("audit_log.count", len(logs))}
+	if len(logs) > 0 {
+		attrs = append(attrs, tracing.TenantAttr(logs[0].TenantID))
+	}
+	ctx, span := startSpan(ctx, "Insert", attrs...)
+	err := r.next.Insert(ctx, logs)
+	tracing.End(span, err)
+	return err
+}
+
+func (r *tracedRepository) Stats(ctx context.Context, filter *domain.AuditLogFilter) (*domain.AuditLogStats, error) {
+	ctx, span := startSpan(ctx, "Stats")
+	stats, err := r.next.Stats(ctx, filter)
+	tracing.End(span, err)
+	return stats, err
+}
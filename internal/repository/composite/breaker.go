@@ -0,0 +1,119 @@
+package composite
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/repository/opensearch"
+	"github.com/kingrain94/audit-log-api/pkg/breaker"
+)
+
+// breakerOpenSearch guards the repository of an OpenSearch cluster with a
+// circuit breaker, so its calls fail at once with a *breaker.OpenError while
+// the cluster is down
+type breakerOpenSearch struct {
+	next    opensearch.Repository
+	breaker *breaker.Breaker
+}
+
+// isOpenSearchFailure tells failures of OpenSearch from errors of the call:
+// documents a bulk request rejected, such as for a mapping conflict, don't
+// mean the cluster is down
+func isOpenSearchFailure(err error) bool {
+	var bulkErr *opensearch.BulkIndexError
+	return !errors.As(err, &bulkErr) && !errors.Is(err, context.Canceled)
+}
+
+func (r *breakerOpenSearch) Index(ctx context.Context, log *domain.AuditLog) error {
+	return r.breaker.Execute(func() error {
+		return r.next.Index(ctx, log)
+	})
+}
+
+func (r *breakerOpenSearch) BulkIndex(ctx context.Context, logs []domain.AuditLog) error {
+	return r.breaker.Execute(func() error {
+		return r.next.BulkIndex(ctx, logs)
+	})
+}
+
+func (r *breakerOpenSearch) Search(ctx context.Context, filter *domain.AuditLogFilter) ([]domain.AuditLog, error) {
+	return breaker.Call(r.breaker, func() ([]domain.AuditLog, error) {
+		return r.next.Search(ctx, filter)
+	})
+}
+
+// Scan counts as one call however many batches it reads. Errors of fn are
+// returned without counting as failures of OpenSearch.
+func (r *breakerOpenSearch) Scan(ctx context.Context, filter *domain.AuditLogFilter, size int, fn func([]domain.AuditLog) error) error {
+	var fnErr error
+	err := r.breaker.Execute(func() error {
+		err := r.next.Scan(ctx, filter, size, func(logs []domain.AuditLog) error {
+			fnErr = fn(logs)
+			return fnErr
+		})
+		if fnErr != nil {
+			return nil
+		}
+		return err
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	return err
+}
+
+func (r *breakerOpenSearch) GetByIDs(ctx context.Context, ids []string) ([]domain.AuditLog, error) {
+	return breaker.Call(r.breaker, func() ([]domain.AuditLog, error) {
+		return r.next.GetByIDs(ctx, ids)
+	})
+}
+
+func (r *breakerOpenSearch) Stats(ctx context.Context, filter *domain.AuditLogFilter) (*domain.AuditLogStats, error) {
+	return breaker.Call(r.breaker, func() (*domain.AuditLogStats, error) {
+		return r.next.Stats(ctx, filter)
+	})
+}
+
+func (r *breakerOpenSearch) TopStats(ctx context.Context, filter *domain.AuditLogFilter, limit int) (*domain.TopStats, error) {
+	return breaker.Call(r.breaker, func() (*domain.TopStats, error) {
+		return r.next.TopStats(ctx, filter, limit)
+	})
+}
+
+func (r *breakerOpenSearch) CreateIndex(ctx context.Context, tenantID string, t time.Time) error {
+	return r.breaker.Execute(func() error {
+		return r.next.CreateIndex(ctx, tenantID, t)
+	})
+}
+
+func (r *breakerOpenSearch) DeleteIndex(ctx context.Context, tenantID string) error {
+	return r.breaker.Execute(func() error {
+		return r.next.DeleteIndex(ctx, tenantID)
+	})
+}
+
+func (r *breakerOpenSearch) ListIndices(ctx context.Context, tenantID string) ([]domain.SearchIndex, error) {
+	return breaker.Call(r.breaker, func() ([]domain.SearchIndex, error) {
+		return r.next.ListIndices(ctx, tenantID)
+	})
+}
+
+func (r *breakerOpenSearch) DeleteDailyIndex(ctx context.Context, tenantID string, day time.Time) error {
+	return r.breaker.Execute(func() error {
+		return r.next.DeleteDailyIndex(ctx, tenantID, day)
+	})
+}
+
+func (r *breakerOpenSearch) Delete(ctx context.Context, tenantID, logID string) error {
+	return r.breaker.Execute(func() error {
+		return r.next.Delete(ctx, tenantID, logID)
+	})
+}
+
+func (r *breakerOpenSearch) DeleteRange(ctx context.Context, tenantID string, start, end time.Time) (int64, error) {
+	return breaker.Call(r.breaker, func() (int64, error) {
+		return r.next.DeleteRange(ctx, tenantID, start, end)
+	})
+}
@@ -1,7 +1,10 @@
 package composite
 
 import (
+	"context"
+
 	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
 	"github.com/kingrain94/audit-log-api/internal/repository"
 	"github.com/kingrain94/audit-log-api/internal/repository/opensearch"
 	"github.com/kingrain94/audit-log-api/internal/repository/postgres"
@@ -13,10 +16,33 @@ type compositeRepository struct {
 	osRepo       repository.OpenSearchRepository
 }
 
-func NewCompositeRepository(dbConnections *config.DatabaseConnections, osClient *opensearchclient.Client, osConfig *config.OpenSearchConfig) repository.Repository {
+// NewCompositeRepository combines PostgreSQL and the OpenSearch clusters of
+// osClients, by region. Each tenant's logs go to the cluster of its data
+// residency region. Calls to each cluster go through a circuit breaker of
+// osConfig.Breaker, so they fail fast while the cluster is down.
+func NewCompositeRepository(dbConnections *config.DatabaseConnections, osClients map[string]*opensearchclient.Client, osConfig *config.OpenSearchConfig) repository.Repository {
+	postgresRepo := postgres.NewPostgresRepository(dbConnections)
+	settings := opensearch.NewTenantSettings(postgresRepo.Tenant(), osConfig.TenantSettingsCacheTTL)
+
+	clusters := make(map[string]opensearch.Repository, len(osClients))
+	for cluster, client := range osClients {
+		var osRepo opensearch.Repository = opensearch.NewRepository(client, osConfig, settings)
+		if osConfig.Breaker.Enabled() {
+			name := "opensearch"
+			if cluster != config.DefaultOpenSearchCluster {
+				name += "_" + cluster
+			}
+			osRepo = &breakerOpenSearch{
+				next:    osRepo,
+				breaker: osConfig.Breaker.NewBreaker(name, isOpenSearchFailure, metrics.ObserveBreakerStateChange),
+			}
+		}
+		clusters[cluster] = osRepo
+	}
+
 	return &compositeRepository{
-		postgresRepo: postgres.NewPostgresRepository(dbConnections),
-		osRepo:       opensearch.NewRepository(osClient, osConfig),
+		postgresRepo: postgresRepo,
+		osRepo:       opensearch.NewRoutedRepository(clusters, settings),
 	}
 }
 
@@ -28,6 +54,82 @@ func (r *compositeRepository) Tenant() repository.TenantRepository {
 	return r.postgresRepo.Tenant()
 }
 
+func (r *compositeRepository) User() repository.UserRepository {
+	return r.postgresRepo.User()
+}
+
+func (r *compositeRepository) Policy() repository.PolicyRepository {
+	return r.postgresRepo.Policy()
+}
+
+func (r *compositeRepository) RedactionRule() repository.RedactionRuleRepository {
+	return r.postgresRepo.RedactionRule()
+}
+
+func (r *compositeRepository) TaggingRule() repository.TaggingRuleRepository {
+	return r.postgresRepo.TaggingRule()
+}
+
+func (r *compositeRepository) ResourceSchema() repository.ResourceSchemaRepository {
+	return r.postgresRepo.ResourceSchema()
+}
+
+func (r *compositeRepository) SavedSearch() repository.SavedSearchRepository {
+	return r.postgresRepo.SavedSearch()
+}
+
+func (r *compositeRepository) Outbox() repository.OutboxRepository {
+	return r.postgresRepo.Outbox()
+}
+
+func (r *compositeRepository) ExportJob() repository.ExportJobRepository {
+	return r.postgresRepo.ExportJob()
+}
+
+func (r *compositeRepository) RestoreJob() repository.RestoreJobRepository {
+	return r.postgresRepo.RestoreJob()
+}
+
+func (r *compositeRepository) ImportJob() repository.ImportJobRepository {
+	return r.postgresRepo.ImportJob()
+}
+
+func (r *compositeRepository) CleanupJob() repository.CleanupJobRepository {
+	return r.postgresRepo.CleanupJob()
+}
+
+func (r *compositeRepository) ReindexJob() repository.ReindexJobRepository {
+	return r.postgresRepo.ReindexJob()
+}
+
+func (r *compositeRepository) Job() repository.JobRepository {
+	return r.postgresRepo.Job()
+}
+
+func (r *compositeRepository) RetentionPolicy() repository.RetentionPolicyRepository {
+	return r.postgresRepo.RetentionPolicy()
+}
+
+func (r *compositeRepository) IndexFailure() repository.IndexFailureRepository {
+	return r.postgresRepo.IndexFailure()
+}
+
+func (r *compositeRepository) ArchiveSchedule() repository.ArchiveScheduleRepository {
+	return r.postgresRepo.ArchiveSchedule()
+}
+
+func (r *compositeRepository) Archive() repository.ArchiveRepository {
+	return r.postgresRepo.Archive()
+}
+
+func (r *compositeRepository) Integration() repository.IntegrationRepository {
+	return r.postgresRepo.Integration()
+}
+
+func (r *compositeRepository) Transaction(ctx context.Context, fn func(tx repository.PostgresRepository) error) error {
+	return r.postgresRepo.Transaction(ctx, fn)
+}
+
 func (r *compositeRepository) OpenSearch() repository.OpenSearchRepository {
 	return r.osRepo
 }
@@ -0,0 +1,65 @@
+package opensearch
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/opensearch-project/opensearch-go/v2/opensearchapi"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+// ErrTooManyRequests is wrapped by the errors of requests OpenSearch rejected
+// with a 429, so callers can back off
+var ErrTooManyRequests = errors.New("opensearch rejected the request for load")
+
+// responseError describes an error response, wrapping ErrTooManyRequests for 429s
+func responseError(msg string, res *opensearchapi.Response) error {
+	if res.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("%s: %w: %s", msg, ErrTooManyRequests, res.String())
+	}
+	return fmt.Errorf("%s: %s", msg, res.String())
+}
+
+// BulkItemFailure is a log OpenSearch rejected within a bulk request
+type BulkItemFailure struct {
+	Log    domain.AuditLog
+	Index  string
+	Status int
+	// Type and Reason describe the rejection, such as a mapper_parsing_exception
+	Type   string
+	Reason string
+}
+
+// Retryable reports whether the item was rejected for load, so it may be
+// indexed if sent again
+func (f BulkItemFailure) Retryable() bool {
+	return f.Status == http.StatusTooManyRequests || f.Status >= http.StatusInternalServerError
+}
+
+// BulkIndexError lists the logs of a bulk index that failed; the other logs
+// of the batch were indexed
+type BulkIndexError struct {
+	Failures []BulkItemFailure
+}
+
+func (e *BulkIndexError) Error() string {
+	first := e.Failures[0]
+	return fmt.Sprintf("%d documents failed to index, first %s with status %d: %s: %s",
+		len(e.Failures), first.Log.ID, first.Status, first.Type, first.Reason)
+}
+
+// bulkResponse is the part of a bulk response needed to find rejected items
+type bulkResponse struct {
+	Errors bool                          `json:"errors"`
+	Items  []map[string]bulkResponseItem `json:"items"`
+}
+
+type bulkResponseItem struct {
+	Status int `json:"status"`
+	Error  *struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error"`
+}
@@ -0,0 +1,109 @@
+package opensearch
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/opensearch-project/opensearch-go/v2"
+	"github.com/opensearch-project/opensearch-go/v2/opensearchapi"
+
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+)
+
+// ClusterStatusUnreachable is the status of a cluster that didn't answer its
+// health check. Clusters that answered have their health status: green,
+// yellow or red.
+const ClusterStatusUnreachable = "unreachable"
+
+// ClusterHealthChecker periodically checks the health of every cluster,
+// recording it in the opensearch_cluster_up metric
+type ClusterHealthChecker struct {
+	clients  map[string]*opensearch.Client
+	interval time.Duration
+
+	mu     sync.RWMutex
+	status map[string]string
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewClusterHealthChecker(clients map[string]*opensearch.Client, interval time.Duration) *ClusterHealthChecker {
+	return &ClusterHealthChecker{
+		clients:  clients,
+		interval: interval,
+		status:   make(map[string]string, len(clients)),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start checks the clusters at once, then every interval until Stop
+func (c *ClusterHealthChecker) Start() {
+	go func() {
+		defer close(c.done)
+
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			c.check()
+			select {
+			case <-c.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (c *ClusterHealthChecker) Stop() {
+	close(c.stop)
+	<-c.done
+}
+
+// Status returns the last status of every cluster checked, by region
+func (c *ClusterHealthChecker) Status() map[string]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return maps.Clone(c.status)
+}
+
+func (c *ClusterHealthChecker) check() {
+	for cluster, client := range c.clients {
+		status := clusterHealth(client, c.interval)
+
+		up := 0.0
+		if status == "green" || status == "yellow" {
+			up = 1
+		}
+		metrics.OpenSearchClusterUp.WithLabelValues(cluster).Set(up)
+
+		c.mu.Lock()
+		c.status[cluster] = status
+		c.mu.Unlock()
+	}
+}
+
+// clusterHealth returns the health status of a cluster, waiting for its
+// answer no longer than timeout
+func clusterHealth(client *opensearch.Client, timeout time.Duration) string {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req := opensearchapi.ClusterHealthRequest{}
+	res, err := req.Do(ctx, client)
+	if err := checkResponse(res, err, "check cluster health"); err != nil {
+		return ClusterStatusUnreachable
+	}
+
+	var health struct {
+		Status string `json:"status"`
+	}
+	if err := decodeResponse(res, &health); err != nil || health.Status == "" {
+		return ClusterStatusUnreachable
+	}
+	return health.Status
+}
@@ -0,0 +1,310 @@
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opensearch-project/opensearch-go/v2"
+	"github.com/opensearch-project/opensearch-go/v2/opensearchapi"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+)
+
+const (
+	// indexTemplateName is the index template holding the audit log mapping
+	indexTemplateName = "audit_logs"
+	// deleteBatchSize bounds the number of indices named in one delete request
+	deleteBatchSize = 50
+)
+
+// LifecyclePhaseWarm marks indices that were force merged and had their
+// replicas reduced. Indices without a phase are hot.
+const LifecyclePhaseWarm = "warm"
+
+// IndexInfo describes a daily audit log index
+type IndexInfo struct {
+	Name     string
+	TenantID string
+	// Day is the UTC day the index holds logs for
+	Day      time.Time
+	Replicas int
+	Phase    string
+	// MappingVersion is the version of the mapping the index was created with
+	MappingVersion int
+	// Outdated reports whether the index was created with an older mapping or
+	// with another metadata mapping strategy than the configured one
+	Outdated bool
+}
+
+// LifecycleManager applies the lifecycle of the daily per-tenant indices
+type LifecycleManager interface {
+	// PutIndexTemplate installs the index template so that indices created
+	// implicitly, by a write to a missing index, get the audit log mapping.
+	// The template is versioned with MappingVersion.
+	PutIndexTemplate(ctx context.Context) error
+	// ListIndices returns every daily audit log index
+	ListIndices(ctx context.Context) ([]IndexInfo, error)
+	// RolloverWriteAlias points the tenant's write alias at the index for now,
+	// creating it and the next day's index first. It reports whether the alias moved.
+	RolloverWriteAlias(ctx context.Context, tenantID string, now time.Time) (bool, error)
+	// Warm reduces an index to the given replicas and force merges it to one segment
+	Warm(ctx context.Context, index string, replicas int) error
+	// DeleteIndices deletes the named indices
+	DeleteIndices(ctx context.Context, indices []string) error
+	// MigrateIndex recreates an index with the current mapping and metadata
+	// mapping strategy, keeping its logs. Migrating an index again completes an interrupted migration.
+	MigrateIndex(ctx context.Context, index string) error
+	// InterruptedMigrations returns the indices whose migration didn't complete
+	InterruptedMigrations(ctx context.Context) ([]string, error)
+}
+
+type lifecycleManager struct {
+	client *opensearch.Client
+	config *config.OpenSearchConfig
+}
+
+func NewLifecycleManager(client *opensearch.Client, config *config.OpenSearchConfig) LifecycleManager {
+	return &lifecycleManager{
+		client: client,
+		config: config,
+	}
+}
+
+func (m *lifecycleManager) PutIndexTemplate(ctx context.Context) error {
+	var template map[string]any
+	if err := json.Unmarshal([]byte(getIndexMapping(m.config.MetadataMapping)), &template); err != nil {
+		return fmt.Errorf("failed to parse index mapping: %w", err)
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"index_patterns": []string{m.config.GetAllIndicesPattern()},
+		"template":       template,
+		"version":        MappingVersion,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal index template: %w", err)
+	}
+
+	req := opensearchapi.IndicesPutIndexTemplateRequest{
+		Name: indexTemplateName,
+		Body: strings.NewReader(string(body)),
+	}
+	res, err := req.Do(ctx, m.client)
+	if err := checkResponse(res, err, "put index template"); err != nil {
+		return err
+	}
+	res.Body.Close()
+
+	return nil
+}
+
+func (m *lifecycleManager) ListIndices(ctx context.Context) ([]IndexInfo, error) {
+	pattern := m.config.GetAllIndicesPattern()
+
+	cat := opensearchapi.CatIndicesRequest{
+		Index:  []string{pattern},
+		Format: "json",
+		H:      []string{"index", "rep"},
+	}
+	res, err := cat.Do(ctx, m.client)
+	if err := checkResponse(res, err, "list indices"); err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		Index string `json:"index"`
+		Rep   string `json:"rep"`
+	}
+	if err := decodeResponse(res, &rows); err != nil {
+		return nil, err
+	}
+
+	metas, err := getIndexMeta(ctx, m.client, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	indices := make([]IndexInfo, 0, len(rows))
+	for _, row := range rows {
+		tenantID, day, ok := m.config.ParseIndexName(row.Index)
+		if !ok {
+			continue
+		}
+		replicas, _ := strconv.Atoi(row.Rep)
+		indices = append(indices, IndexInfo{
+			Name:           row.Index,
+			TenantID:       tenantID,
+			Day:            day,
+			Replicas:       replicas,
+			Phase:          metas[row.Index].LifecyclePhase,
+			MappingVersion: metas[row.Index].MappingVersion,
+			Outdated:       metas[row.Index].outdated(m.config.MetadataMapping),
+		})
+	}
+
+	return indices, nil
+}
+
+func (m *lifecycleManager) RolloverWriteAlias(ctx context.Context, tenantID string, now time.Time) (bool, error) {
+	// Creating tomorrow's index ahead of time spares the first writes after
+	// midnight the index creation
+	for _, day := range []time.Time{now, now.Add(24 * time.Hour)} {
+		if err := createIndex(ctx, m.client, m.config.GetIndexName(tenantID, day), m.config.MetadataMapping); err != nil {
+			return false, err
+		}
+	}
+
+	alias := m.config.GetWriteAlias(tenantID)
+	target := m.config.GetIndexName(tenantID, now)
+
+	current, err := m.aliasIndices(ctx, alias)
+	if err != nil {
+		return false, err
+	}
+	if len(current) == 1 && current[0] == target {
+		return false, nil
+	}
+
+	actions := make([]map[string]any, 0, len(current)+1)
+	for _, index := range current {
+		if index != target {
+			actions = append(actions, map[string]any{
+				"remove": map[string]any{"index": index, "alias": alias},
+			})
+		}
+	}
+	actions = append(actions, map[string]any{
+		"add": map[string]any{"index": target, "alias": alias, "is_write_index": true},
+	})
+
+	body, err := json.Marshal(map[string]any{"actions": actions})
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal alias actions: %w", err)
+	}
+
+	// Removing and adding in one request moves the alias atomically
+	req := opensearchapi.IndicesUpdateAliasesRequest{
+		Body: strings.NewReader(string(body)),
+	}
+	res, err := req.Do(ctx, m.client)
+	if err := checkResponse(res, err, "update aliases"); err != nil {
+		return false, err
+	}
+	res.Body.Close()
+
+	return true, nil
+}
+
+// aliasIndices returns the indices an alias points at, none if it doesn't exist
+func (m *lifecycleManager) aliasIndices(ctx context.Context, alias string) ([]string, error) {
+	req := opensearchapi.IndicesGetAliasRequest{
+		Name: []string{alias},
+	}
+	res, err := req.Do(ctx, m.client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alias: %w", err)
+	}
+	if res.StatusCode == 404 {
+		res.Body.Close()
+		return nil, nil
+	}
+	if err := checkResponse(res, nil, "get alias"); err != nil {
+		return nil, err
+	}
+
+	var aliases map[string]any
+	if err := decodeResponse(res, &aliases); err != nil {
+		return nil, err
+	}
+
+	indices := make([]string, 0, len(aliases))
+	for index := range aliases {
+		indices = append(indices, index)
+	}
+	return indices, nil
+}
+
+func (m *lifecycleManager) Warm(ctx context.Context, index string, replicas int) error {
+	settings := opensearchapi.IndicesPutSettingsRequest{
+		Index: []string{index},
+		Body:  strings.NewReader(fmt.Sprintf(`{"index":{"number_of_replicas":%d}}`, replicas)),
+	}
+	res, err := settings.Do(ctx, m.client)
+	if err := checkResponse(res, err, "update index settings"); err != nil {
+		return err
+	}
+	res.Body.Close()
+
+	// Indices have a single primary shard, so there is nothing to shrink;
+	// merging down to one segment is what saves space and heap
+	maxSegments := 1
+	merge := opensearchapi.IndicesForcemergeRequest{
+		Index:          []string{index},
+		MaxNumSegments: &maxSegments,
+	}
+	res, err = merge.Do(ctx, m.client)
+	if err := checkResponse(res, err, "force merge index"); err != nil {
+		return err
+	}
+	res.Body.Close()
+
+	// The phase is recorded last, so an index is warmed again next run if any step failed
+	return updateIndexMeta(ctx, m.client, index, map[string]any{"lifecycle_phase": LifecyclePhaseWarm})
+}
+
+func (m *lifecycleManager) DeleteIndices(ctx context.Context, indices []string) error {
+	return deleteIndices(ctx, m.client, indices)
+}
+
+func (m *lifecycleManager) MigrateIndex(ctx context.Context, index string) error {
+	return migrateIndex(ctx, m.client, index, m.config.MetadataMapping)
+}
+
+func (m *lifecycleManager) InterruptedMigrations(ctx context.Context) ([]string, error) {
+	return interruptedMigrations(ctx, m.client, m.config.GetAllIndicesPattern())
+}
+
+// deleteIndices deletes the named indices in batches
+func deleteIndices(ctx context.Context, client *opensearch.Client, indices []string) error {
+	for start := 0; start < len(indices); start += deleteBatchSize {
+		end := min(start+deleteBatchSize, len(indices))
+
+		req := opensearchapi.IndicesDeleteRequest{
+			Index: indices[start:end],
+		}
+		res, err := req.Do(ctx, client)
+		if err := checkResponse(res, err, "delete indices"); err != nil {
+			return err
+		}
+		res.Body.Close()
+	}
+
+	return nil
+}
+
+// checkResponse turns a failed request or an error response into an error,
+// closing the response body in that case
+func checkResponse(res *opensearchapi.Response, err error, action string) error {
+	if err != nil {
+		return fmt.Errorf("failed to %s: %w", action, err)
+	}
+	if res.IsError() {
+		defer res.Body.Close()
+		return fmt.Errorf("error trying to %s: %s", action, res.String())
+	}
+	return nil
+}
+
+// decodeResponse decodes a successful response body into v and closes it
+func decodeResponse(res *opensearchapi.Response, v any) error {
+	defer res.Body.Close()
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil && err != io.EOF {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
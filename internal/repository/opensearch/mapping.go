@@ -0,0 +1,337 @@
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/opensearch-project/opensearch-go/v2"
+	"github.com/opensearch-project/opensearch-go/v2/opensearchapi"
+)
+
+// MappingVersion is the version of the audit log mapping, recorded in the
+// _meta of every index created with it. Bump it with any change to
+// getIndexMapping, so the index lifecycle worker migrates the indices created
+// with an older mapping. Indices that predate versioning are version 1.
+const MappingVersion = 5
+
+// migrationIndexPrefix names the index a daily index is copied to while it is
+// migrated to the current mapping. It keeps the copy out of the tenant's
+// index pattern, so searches don't see its logs twice.
+const migrationIndexPrefix = "migrating_"
+
+// getIndexMapping returns the mapping for audit log index with optimized
+// settings, the free-form payloads mapped per the metadata mapping strategy
+func getIndexMapping(strategy config.MetadataMapping) string {
+	return fmt.Sprintf(`{
+		"mappings": {
+			"_meta": {
+				"mapping_version": %d,
+				"metadata_mapping": %q
+			},
+			"dynamic_templates": [
+				{
+					"metadata_keys": {
+						"path_match": "metadata_keys.*",
+						"mapping": { "type": "keyword", "ignore_above": 256 }
+					}
+				}
+			],
+			"properties": {
+				"id": { "type": "keyword" },
+				"tenant_id": { "type": "keyword" },
+				"user_id": { "type": "keyword" },
+				"session_id": { "type": "keyword" },
+				"correlation_id": { "type": "keyword" },
+				"action": { "type": "keyword" },
+				"resource_type": { "type": "keyword" },
+				"resource_id": { "type": "keyword" },
+				"message": { "type": "text" },
+				"metadata": %[3]s,
+				"before_state": %[3]s,
+				"after_state": %[3]s,
+				"metadata_keys": { "type": "object" },
+				"schema_errors": { "type": "keyword" },
+				"schema_version": { "type": "short" },
+				"severity": { "type": "keyword" },
+				"tags": { "type": "keyword" },
+				"threat_score": { "type": "short" },
+				"timestamp": { "type": "date" },
+				"ip_address": { "type": "ip" },
+				"user_agent": { "type": "text" }
+			}
+		},
+		"settings": {
+			"index": {
+				"number_of_shards": 1,
+				"number_of_replicas": 1,
+				"refresh_interval": "1s",
+				"mapping": {
+					"total_fields": {
+						"limit": 2000
+					}
+				}
+			}
+		}
+	}`, MappingVersion, strategy, payloadMapping(strategy))
+}
+
+// payloadMapping maps the metadata, before_state and after_state of logs
+func payloadMapping(strategy config.MetadataMapping) string {
+	switch strategy {
+	case config.MetadataMappingFlatObject:
+		return `{ "type": "flat_object" }`
+	case config.MetadataMappingIndexedKeys:
+		// Kept in _source, so logs read back whole, but not indexed
+		return `{ "type": "object", "enabled": false }`
+	default:
+		return `{ "type": "object", "dynamic": true }`
+	}
+}
+
+// indexMeta is the _meta of an index's mapping: its mapping version,
+// metadata mapping strategy and lifecycle phase
+type indexMeta struct {
+	MappingVersion  int                    `json:"mapping_version"`
+	MetadataMapping config.MetadataMapping `json:"metadata_mapping"`
+	LifecyclePhase  string                 `json:"lifecycle_phase"`
+}
+
+// outdated reports whether the index was created with an older mapping or
+// with another metadata mapping strategy than strategy
+func (m indexMeta) outdated(strategy config.MetadataMapping) bool {
+	return m.MappingVersion < MappingVersion || m.MetadataMapping != strategy
+}
+
+// getIndexMeta reads the _meta of the indices matching pattern. Indices that
+// predate mapping versioning are reported with version 1, and those that
+// predate metadata mapping strategies with the dynamic one.
+func getIndexMeta(ctx context.Context, client *opensearch.Client, pattern string) (map[string]indexMeta, error) {
+	req := opensearchapi.IndicesGetMappingRequest{
+		Index:      []string{pattern},
+		FilterPath: []string{"*.mappings._meta"},
+	}
+	res, err := req.Do(ctx, client)
+	if err := checkResponse(res, err, "get index mappings"); err != nil {
+		return nil, err
+	}
+
+	var mappings map[string]struct {
+		Mappings struct {
+			Meta indexMeta `json:"_meta"`
+		} `json:"mappings"`
+	}
+	if err := decodeResponse(res, &mappings); err != nil {
+		return nil, err
+	}
+
+	metas := make(map[string]indexMeta, len(mappings))
+	for index, mapping := range mappings {
+		meta := mapping.Mappings.Meta
+		if meta.MappingVersion == 0 {
+			meta.MappingVersion = 1
+		}
+		if meta.MetadataMapping == "" {
+			meta.MetadataMapping = config.MetadataMappingDynamic
+		}
+		metas[index] = meta
+	}
+	return metas, nil
+}
+
+// updateIndexMeta sets keys of an index's _meta. A mapping update replaces
+// the whole _meta, so the keys are merged into the current one.
+func updateIndexMeta(ctx context.Context, client *opensearch.Client, index string, update map[string]any) error {
+	req := opensearchapi.IndicesGetMappingRequest{
+		Index:      []string{index},
+		FilterPath: []string{"*.mappings._meta"},
+	}
+	res, err := req.Do(ctx, client)
+	if err := checkResponse(res, err, "get index mapping"); err != nil {
+		return err
+	}
+	var mappings map[string]struct {
+		Mappings struct {
+			Meta map[string]any `json:"_meta"`
+		} `json:"mappings"`
+	}
+	if err := decodeResponse(res, &mappings); err != nil {
+		return err
+	}
+
+	meta := mappings[index].Mappings.Meta
+	if meta == nil {
+		meta = make(map[string]any, len(update))
+	}
+	for key, value := range update {
+		meta[key] = value
+	}
+
+	body, err := json.Marshal(map[string]any{"_meta": meta})
+	if err != nil {
+		return fmt.Errorf("failed to marshal index meta: %w", err)
+	}
+	put := opensearchapi.IndicesPutMappingRequest{
+		Index: []string{index},
+		Body:  strings.NewReader(string(body)),
+	}
+	res, err = put.Do(ctx, client)
+	if err := checkResponse(res, err, "update index meta"); err != nil {
+		return err
+	}
+	res.Body.Close()
+
+	return nil
+}
+
+// migrateIndex moves a daily index to the current mapping, with the metadata
+// mapping strategy. Its logs are
+// copied to a migration index created with the mapping, the index is
+// recreated and the logs are copied back. Writes to the index are blocked
+// while it's copied, and its logs are missing from search from its deletion
+// until they're copied back.
+//
+// Each step can be repeated, so an interrupted migration is completed by
+// migrating the index again: while the index still has its old mapping the
+// copy is started over, otherwise the migration index holds every log and
+// only the copy back is repeated.
+func migrateIndex(ctx context.Context, client *opensearch.Client, index string, strategy config.MetadataMapping) error {
+	migration := migrationIndexPrefix + index
+
+	copied, err := indexExists(ctx, client, migration)
+	if err != nil {
+		return err
+	}
+	if copied {
+		exists, err := indexExists(ctx, client, index)
+		if err != nil {
+			return err
+		}
+		if exists {
+			metas, err := getIndexMeta(ctx, client, index)
+			if err != nil {
+				return err
+			}
+			if metas[index].outdated(strategy) {
+				// The copy may be incomplete; start it over
+				if err := deleteIndices(ctx, client, []string{migration}); err != nil {
+					return err
+				}
+				copied = false
+			}
+		}
+	}
+
+	if !copied {
+		if err := blockWrites(ctx, client, index); err != nil {
+			return err
+		}
+		if err := createIndex(ctx, client, migration, strategy); err != nil {
+			return err
+		}
+		if err := reindex(ctx, client, index, migration); err != nil {
+			return err
+		}
+		if err := deleteIndices(ctx, client, []string{index}); err != nil {
+			return err
+		}
+	}
+
+	if err := createIndex(ctx, client, index, strategy); err != nil {
+		return err
+	}
+	if err := reindex(ctx, client, migration, index); err != nil {
+		return err
+	}
+	return deleteIndices(ctx, client, []string{migration})
+}
+
+// interruptedMigrations returns the indices whose migration index is left
+// over from an interrupted migration
+func interruptedMigrations(ctx context.Context, client *opensearch.Client, pattern string) ([]string, error) {
+	cat := opensearchapi.CatIndicesRequest{
+		Index:  []string{migrationIndexPrefix + pattern},
+		Format: "json",
+		H:      []string{"index"},
+	}
+	res, err := cat.Do(ctx, client)
+	if err := checkResponse(res, err, "list migration indices"); err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		Index string `json:"index"`
+	}
+	if err := decodeResponse(res, &rows); err != nil {
+		return nil, err
+	}
+
+	indices := make([]string, len(rows))
+	for i, row := range rows {
+		indices[i] = strings.TrimPrefix(row.Index, migrationIndexPrefix)
+	}
+	return indices, nil
+}
+
+func indexExists(ctx context.Context, client *opensearch.Client, index string) (bool, error) {
+	req := opensearchapi.IndicesExistsRequest{
+		Index: []string{index},
+	}
+	res, err := req.Do(ctx, client)
+	if err != nil {
+		return false, fmt.Errorf("failed to check index existence: %w", err)
+	}
+	res.Body.Close()
+	return res.StatusCode == 200, nil
+}
+
+// blockWrites makes an index read-only, so no log written during its copy is lost
+func blockWrites(ctx context.Context, client *opensearch.Client, index string) error {
+	req := opensearchapi.IndicesPutSettingsRequest{
+		Index: []string{index},
+		Body:  strings.NewReader(`{"index":{"blocks":{"write":true}}}`),
+	}
+	res, err := req.Do(ctx, client)
+	if err := checkResponse(res, err, "block index writes"); err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+// reindex copies every document of source to dest, waiting for the copy to
+// complete. Documents already in dest are overwritten, so a copy can be
+// repeated.
+func reindex(ctx context.Context, client *opensearch.Client, source, dest string) error {
+	body, err := json.Marshal(map[string]any{
+		"source": map[string]any{"index": source},
+		"dest":   map[string]any{"index": dest},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal reindex request: %w", err)
+	}
+
+	wait, refresh := true, true
+	req := opensearchapi.ReindexRequest{
+		Body:              strings.NewReader(string(body)),
+		WaitForCompletion: &wait,
+		Refresh:           &refresh,
+	}
+	res, err := req.Do(ctx, client)
+	if err := checkResponse(res, err, "reindex "+source); err != nil {
+		return err
+	}
+
+	var result struct {
+		Failures []json.RawMessage `json:"failures"`
+	}
+	if err := decodeResponse(res, &result); err != nil {
+		return err
+	}
+	if len(result.Failures) > 0 {
+		return fmt.Errorf("%d documents failed to copy from %s to %s, first: %s", len(result.Failures), source, dest, result.Failures[0])
+	}
+	return nil
+}
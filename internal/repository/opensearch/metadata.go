@@ -0,0 +1,76 @@
+package opensearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+// document is a log as it's indexed. Under the indexed_keys strategy it
+// carries the tenant's indexed metadata keys, which searches never read back.
+// The threat score of the metadata is copied to a numeric field under every
+// strategy, so logs can be filtered by it.
+type document struct {
+	*domain.AuditLog
+	MetadataKeys map[string]string `json:"metadata_keys,omitempty"`
+	ThreatScore  int               `json:"threat_score,omitempty"`
+}
+
+// documents returns the logs of a tenant as they're indexed
+func (r *repository) documents(ctx context.Context, tenantID string, logs []domain.AuditLog) ([]document, error) {
+	var keys []string
+	if r.config.MetadataMapping == config.MetadataMappingIndexedKeys && r.settings != nil {
+		settings, err := r.settings.ResolveSettings(ctx, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		keys = settings.IndexedMetadataKeys
+	}
+
+	docs := make([]document, len(logs))
+	for i := range logs {
+		docs[i] = document{
+			AuditLog:     &logs[i],
+			MetadataKeys: indexedMetadata(logs[i].Metadata, keys),
+			ThreatScore:  logs[i].ThreatScore(),
+		}
+	}
+	return docs, nil
+}
+
+// indexedMetadata returns the values of the listed top-level metadata keys as
+// strings: string values as they are, any other value as its JSON
+func indexedMetadata(metadata json.RawMessage, keys []string) map[string]string {
+	if len(keys) == 0 || len(metadata) == 0 {
+		return nil
+	}
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(metadata, &values); err != nil {
+		return nil
+	}
+
+	indexed := make(map[string]string, len(keys))
+	for _, key := range keys {
+		raw, ok := values[key]
+		if !ok || string(raw) == "null" {
+			continue
+		}
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			indexed[key] = s
+			continue
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, raw); err != nil {
+			continue
+		}
+		indexed[key] = compact.String()
+	}
+	if len(indexed) == 0 {
+		return nil
+	}
+	return indexed
+}
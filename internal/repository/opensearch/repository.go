@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,27 +20,52 @@ import (
 type Repository interface {
 	// Index indexes a single audit log
 	Index(ctx context.Context, log *domain.AuditLog) error
-	// BulkIndex indexes multiple audit logs
+	// BulkIndex indexes multiple audit logs, retrying items rejected for load.
+	// Logs that still fail are returned in a *BulkIndexError; the others are indexed.
 	BulkIndex(ctx context.Context, logs []domain.AuditLog) error
 	// Search searches audit logs with the given filter
 	Search(ctx context.Context, filter *domain.AuditLogFilter) ([]domain.AuditLog, error)
+	// Scan calls fn with every log matching the filter, in batches of size
+	Scan(ctx context.Context, filter *domain.AuditLogFilter, size int, fn func([]domain.AuditLog) error) error
+	// GetByIDs fetches the logs with the given IDs in one request
+	GetByIDs(ctx context.Context, ids []string) ([]domain.AuditLog, error)
+	// Stats aggregates counts and a time series of the logs matching the filter
+	Stats(ctx context.Context, filter *domain.AuditLogFilter) (*domain.AuditLogStats, error)
+	// TopStats ranks the users, IP addresses, resources and sessions of the
+	// logs matching the filter by log count, limit of each, and counts the
+	// distinct users and IP addresses over the range and per UTC day
+	TopStats(ctx context.Context, filter *domain.AuditLogFilter, limit int) (*domain.TopStats, error)
 	// CreateIndex creates an index for a tenant if it doesn't exist
 	CreateIndex(ctx context.Context, tenantID string, t time.Time) error
-	// DeleteIndex deletes an index for a tenant
+	// DeleteIndex deletes every daily index of a tenant
 	DeleteIndex(ctx context.Context, tenantID string) error
-	// Delete deletes a single audit log by ID
+	// ListIndices returns the tenant's daily indices, oldest first
+	ListIndices(ctx context.Context, tenantID string) ([]domain.SearchIndex, error)
+	// DeleteDailyIndex deletes the tenant's index for the UTC day of day
+	DeleteDailyIndex(ctx context.Context, tenantID string, day time.Time) error
+	// Delete deletes a single audit log by ID from whichever of the tenant's indices holds it
 	Delete(ctx context.Context, tenantID, logID string) error
+	// DeleteRange deletes the tenant's logs with a timestamp in [start, end),
+	// from the beginning when start is zero, and returns how many were deleted
+	DeleteRange(ctx context.Context, tenantID string, start, end time.Time) (int64, error)
 }
 
 type repository struct {
-	client *opensearch.Client
-	config *config.OpenSearchConfig
+	client   *opensearch.Client
+	config   *config.OpenSearchConfig
+	settings TenantSettingsResolver
 }
 
-func NewRepository(client *opensearch.Client, config *config.OpenSearchConfig) Repository {
-	return &repository{
-		client: client,
-		config: config,
+// NewRepository returns the repository of one cluster. settings resolves the
+// metadata keys indexed under the indexed_keys metadata mapping strategy; it
+// may be nil for a repository that doesn't index logs.
+func NewRepository(client *opensearch.Client, config *config.OpenSearchConfig, settings TenantSettingsResolver) Repository {
+	return &tracedRepository{
+		next: &repository{
+			client:   client,
+			config:   config,
+			settings: settings,
+		},
 	}
 }
 
@@ -56,7 +83,11 @@ func (r *repository) Index(ctx context.Context, log *domain.AuditLog) error {
 	}
 
 	// Convert log to JSON
-	data, err := json.Marshal(log)
+	docs, err := r.documents(ctx, log.TenantID, []domain.AuditLog{*log})
+	if err != nil {
+		return fmt.Errorf("failed to resolve indexed metadata keys: %w", err)
+	}
+	data, err := json.Marshal(docs[0])
 	if err != nil {
 		return fmt.Errorf("failed to marshal log: %w", err)
 	}
@@ -75,7 +106,7 @@ func (r *repository) Index(ctx context.Context, log *domain.AuditLog) error {
 	defer res.Body.Close()
 
 	if res.IsError() {
-		return fmt.Errorf("error indexing document: %s", res.String())
+		return responseError("error indexing document", res)
 	}
 
 	return nil
@@ -98,16 +129,25 @@ func (r *repository) BulkIndex(ctx context.Context, logs []domain.AuditLog) erro
 	}
 
 	// Process each group separately
+	var failures []BulkItemFailure
 	for indexName, groupLogs := range logGroups {
-		if err := r.bulkIndexGroup(ctx, indexName, groupLogs); err != nil {
+		groupFailures, err := r.bulkIndexGroup(ctx, indexName, groupLogs)
+		if err != nil {
 			return fmt.Errorf("failed to bulk index group for index %s: %w", indexName, err)
 		}
+		failures = append(failures, groupFailures...)
 	}
 
+	if len(failures) > 0 {
+		return &BulkIndexError{Failures: failures}
+	}
 	return nil
 }
 
-func (r *repository) bulkIndexGroup(ctx context.Context, indexName string, logs []domain.AuditLog) error {
+// bulkIndexGroup indexes logs into one index. Items rejected for load are
+// retried with backoff; it returns those that still failed and those that
+// failed for good.
+func (r *repository) bulkIndexGroup(ctx context.Context, indexName string, logs []domain.AuditLog) ([]BulkItemFailure, error) {
 	// Ensure index exists (using first log's tenant and timestamp)
 	if len(logs) > 0 {
 		indexTime := time.Now()
@@ -115,30 +155,67 @@ func (r *repository) bulkIndexGroup(ctx context.Context, indexName string, logs
 			indexTime = logs[0].Timestamp
 		}
 		if err := r.CreateIndex(ctx, logs[0].TenantID, indexTime); err != nil {
-			return fmt.Errorf("failed to ensure index exists: %w", err)
+			return nil, fmt.Errorf("failed to ensure index exists: %w", err)
 		}
 	}
 
+	var failed []BulkItemFailure
+	backoff := r.config.BulkRetryBackoff
+	for attempt := 0; ; attempt++ {
+		itemFailures, err := r.bulkRequest(ctx, indexName, logs)
+		if err != nil {
+			return nil, err
+		}
+
+		logs = logs[:0:0]
+		for _, f := range itemFailures {
+			if f.Retryable() && attempt < r.config.BulkMaxRetries {
+				logs = append(logs, f.Log)
+			} else {
+				failed = append(failed, f)
+			}
+		}
+		if len(logs) == 0 {
+			return failed, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// bulkRequest sends one bulk request and returns the items it rejected
+func (r *repository) bulkRequest(ctx context.Context, indexName string, logs []domain.AuditLog) ([]BulkItemFailure, error) {
+	// Every log of an index has the same tenant
+	docs, err := r.documents(ctx, logs[0].TenantID, logs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve indexed metadata keys: %w", err)
+	}
+
 	// Build bulk request body
 	var bulkBody strings.Builder
-	for _, log := range logs {
+	for _, doc := range docs {
 		action := map[string]any{
 			"index": map[string]any{
 				"_index": indexName,
-				"_id":    log.ID,
+				"_id":    doc.ID,
 			},
 		}
 		actionLine, err := json.Marshal(action)
 		if err != nil {
-			return fmt.Errorf("failed to marshal action: %w", err)
+			return nil, fmt.Errorf("failed to marshal action: %w", err)
 		}
 		bulkBody.Write(actionLine)
 		bulkBody.WriteString("\n")
 
 		// Add document line
-		docLine, err := json.Marshal(log)
+		docLine, err := json.Marshal(doc)
 		if err != nil {
-			return fmt.Errorf("failed to marshal document: %w", err)
+			return nil, fmt.Errorf("failed to marshal document: %w", err)
 		}
 		bulkBody.Write(docLine)
 		bulkBody.WriteString("\n")
@@ -151,15 +228,41 @@ func (r *repository) bulkIndexGroup(ctx context.Context, indexName string, logs
 
 	res, err := req.Do(ctx, r.client)
 	if err != nil {
-		return fmt.Errorf("failed to execute bulk request: %w", err)
+		return nil, fmt.Errorf("failed to execute bulk request: %w", err)
 	}
 	defer res.Body.Close()
 
 	if res.IsError() {
-		return fmt.Errorf("bulk request failed: %s", res.String())
+		return nil, responseError("bulk request failed", res)
 	}
 
-	return nil
+	// A successful request can still have rejected some of its items, which
+	// are listed in request order
+	var response bulkResponse
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode bulk response: %w", err)
+	}
+	if !response.Errors {
+		return nil, nil
+	}
+	if len(response.Items) != len(logs) {
+		return nil, fmt.Errorf("bulk response has %d items for %d documents", len(response.Items), len(logs))
+	}
+
+	var failures []BulkItemFailure
+	for i, item := range response.Items {
+		result := item["index"]
+		if result.Status < 300 {
+			continue
+		}
+		failure := BulkItemFailure{Log: logs[i], Index: indexName, Status: result.Status}
+		if result.Error != nil {
+			failure.Type = result.Error.Type
+			failure.Reason = result.Error.Reason
+		}
+		failures = append(failures, failure)
+	}
+	return failures, nil
 }
 
 func (r *repository) Search(ctx context.Context, filter *domain.AuditLogFilter) ([]domain.AuditLog, error) {
@@ -198,45 +301,420 @@ func (r *repository) Search(ctx context.Context, filter *domain.AuditLogFilter)
 		return nil, fmt.Errorf("search request failed: %s", res.String())
 	}
 
-	// Parse response
+	logs, _, err := decodeSearchHits(res)
+	return logs, err
+}
+
+// decodeSearchHits returns the logs of a search response with their
+// highlights and sort values, and the point in time it searched, if any
+func decodeSearchHits(res *opensearchapi.Response) ([]domain.AuditLog, string, error) {
 	var searchResult struct {
-		Hits struct {
+		PitID string `json:"pit_id"`
+		Hits  struct {
 			Hits []struct {
-				Source domain.AuditLog `json:"_source"`
+				Source    domain.AuditLog     `json:"_source"`
+				Highlight map[string][]string `json:"highlight"`
+				Sort      json.RawMessage     `json:"sort"`
 			} `json:"hits"`
 		} `json:"hits"`
 	}
 
 	if err := json.NewDecoder(res.Body).Decode(&searchResult); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return nil, "", fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	// Extract logs from response
 	var logs []domain.AuditLog
 	for _, hit := range searchResult.Hits.Hits {
+		hit.Source.Highlights = hit.Highlight
+		hit.Source.SortValues = hit.Sort
 		logs = append(logs, hit.Source)
 	}
 
+	return logs, searchResult.PitID, nil
+}
+
+// pitKeepAlive is how long a point in time is kept between the batches of a scan
+const pitKeepAlive = 2 * time.Minute
+
+// Scan pages through the logs matching the filter with search_after over a
+// point in time, so they are read consistently whatever their number, unlike
+// from/size pages, which OpenSearch caps at 10,000 hits. The logs are sorted
+// by the filter, ties broken by ID, and fn gets each batch before the next
+// is read; the point in time is released once the scan ends.
+func (r *repository) Scan(ctx context.Context, filter *domain.AuditLogFilter, size int, fn func([]domain.AuditLog) error) error {
+	tenantID := filter.TenantID
+	if tenantID == "" {
+		var err error
+		if tenantID, err = utils.GetTenantIDFromContext(ctx); err != nil {
+			return fmt.Errorf("failed to get tenant ID from context: %w", err)
+		}
+	}
+
+	res, pit, err := opensearchapi.PointInTimeCreateRequest{
+		Index:     []string{r.config.GetIndexPattern(tenantID)},
+		KeepAlive: pitKeepAlive,
+	}.Do(ctx, r.client)
+	if err != nil {
+		return fmt.Errorf("failed to create point in time: %w", err)
+	}
+	res.Body.Close()
+	if res.IsError() || pit == nil || pit.PitID == "" {
+		if res.StatusCode == 404 {
+			return nil
+		}
+		return fmt.Errorf("point in time request failed: %s", res.String())
+	}
+
+	pitID := pit.PitID
+	defer func() {
+		// Release the point in time even if the scan was canceled; it
+		// expires after pitKeepAlive otherwise
+		if res, _, err := (opensearchapi.PointInTimeDeleteRequest{PitID: []string{pitID}}).Do(context.WithoutCancel(ctx), r.client); err == nil {
+			res.Body.Close()
+		}
+	}()
+
+	query := map[string]any{
+		"size":  size,
+		"query": r.buildFilterQuery(filter),
+		"sort":  buildSort(filter),
+	}
+	if fields := filter.SelectedFields(); fields != nil {
+		query["_source"] = fields
+	}
+
+	for {
+		query["pit"] = map[string]any{"id": pitID, "keep_alive": fmt.Sprintf("%ds", int(pitKeepAlive.Seconds()))}
+
+		queryJSON, err := json.Marshal(query)
+		if err != nil {
+			return fmt.Errorf("failed to marshal query: %w", err)
+		}
+
+		// Searches over a point in time name no index
+		res, err := opensearchapi.SearchRequest{Body: strings.NewReader(string(queryJSON))}.Do(ctx, r.client)
+		if err != nil {
+			return fmt.Errorf("failed to execute search: %w", err)
+		}
+		if res.IsError() {
+			res.Body.Close()
+			return fmt.Errorf("search request failed: %s", res.String())
+		}
+		logs, nextPitID, err := decodeSearchHits(res)
+		res.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		if len(logs) == 0 {
+			return nil
+		}
+		if err := fn(logs); err != nil {
+			return err
+		}
+		if len(logs) < size {
+			return nil
+		}
+
+		if nextPitID != "" {
+			pitID = nextPitID
+		}
+		query["search_after"] = logs[len(logs)-1].SortValues
+	}
+}
+
+// GetByIDs fetches logs by ID across the tenant's daily indices. A log's
+// index depends on its timestamp, which callers don't know, so this runs an
+// ids query over the tenant's index pattern rather than an _mget, which needs
+// the concrete index of every document; it is still a single round trip.
+func (r *repository) GetByIDs(ctx context.Context, ids []string) ([]domain.AuditLog, error) {
+	tenantID, err := utils.GetTenantIDFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant ID from context: %w", err)
+	}
+
+	queryJSON, err := json.Marshal(map[string]any{
+		"size": len(ids),
+		"query": map[string]any{
+			"ids": map[string]any{"values": ids},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal query: %w", err)
+	}
+
+	req := opensearchapi.SearchRequest{
+		Index: []string{r.config.GetIndexPattern(tenantID)},
+		Body:  strings.NewReader(string(queryJSON)),
+	}
+
+	res, err := req.Do(ctx, r.client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute search: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		if res.StatusCode == 404 {
+			return []domain.AuditLog{}, nil
+		}
+		return nil, fmt.Errorf("search request failed: %s", res.String())
+	}
+
+	var searchResult struct {
+		Hits struct {
+			Hits []struct {
+				Source domain.AuditLog `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&searchResult); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	logs := make([]domain.AuditLog, 0, len(searchResult.Hits.Hits))
+	for _, hit := range searchResult.Hits.Hits {
+		logs = append(logs, hit.Source)
+	}
 	return logs, nil
 }
 
+// statsTermsSize caps the number of distinct values counted per field
+const statsTermsSize = 100
+
+type termsAggregation struct {
+	Buckets []struct {
+		Key      string `json:"key"`
+		DocCount int64  `json:"doc_count"`
+	} `json:"buckets"`
+}
+
+func (r *repository) Stats(ctx context.Context, filter *domain.AuditLogFilter) (*domain.AuditLogStats, error) {
+	tenantID := filter.TenantID
+	if tenantID == "" {
+		var err error
+		if tenantID, err = utils.GetTenantIDFromContext(ctx); err != nil {
+			return nil, fmt.Errorf("failed to get tenant ID from context: %w", err)
+		}
+	}
+
+	interval := domain.StatsInterval(filter.StartTime, filter.EndTime)
+	queryJSON, err := json.Marshal(r.buildStatsQuery(filter, interval))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal query: %w", err)
+	}
+
+	req := opensearchapi.SearchRequest{
+		Index: []string{r.config.GetIndexPattern(tenantID)},
+		Body:  strings.NewReader(string(queryJSON)),
+	}
+
+	res, err := req.Do(ctx, r.client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute stats search: %w", err)
+	}
+	defer res.Body.Close()
+
+	stats := &domain.AuditLogStats{
+		ActionCounts:   make(map[domain.ActionType]int64),
+		SeverityCounts: make(map[domain.SeverityLevel]int64),
+		ResourceCounts: make(map[string]int64),
+		Interval:       interval,
+		Series:         []domain.AuditLogStatsBucket{},
+	}
+
+	if res.IsError() {
+		if res.StatusCode == 404 {
+			return stats, nil
+		}
+		return nil, fmt.Errorf("stats search request failed: %s", res.String())
+	}
+
+	var result struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+		} `json:"hits"`
+		Aggregations struct {
+			Actions    termsAggregation `json:"actions"`
+			Severities termsAggregation `json:"severities"`
+			Resources  termsAggregation `json:"resources"`
+			Timeline   struct {
+				Buckets []struct {
+					Key      int64 `json:"key"`
+					DocCount int64 `json:"doc_count"`
+				} `json:"buckets"`
+			} `json:"timeline"`
+		} `json:"aggregations"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	stats.TotalLogs = result.Hits.Total.Value
+	for _, b := range result.Aggregations.Actions.Buckets {
+		stats.ActionCounts[domain.ActionType(b.Key)] = b.DocCount
+	}
+	for _, b := range result.Aggregations.Severities.Buckets {
+		stats.SeverityCounts[domain.SeverityLevel(b.Key)] = b.DocCount
+	}
+	for _, b := range result.Aggregations.Resources.Buckets {
+		if b.Key != "" {
+			stats.ResourceCounts[b.Key] = b.DocCount
+		}
+	}
+	for _, b := range result.Aggregations.Timeline.Buckets {
+		stats.Series = append(stats.Series, domain.AuditLogStatsBucket{
+			Start: time.UnixMilli(b.Key).UTC(),
+			Count: b.DocCount,
+		})
+	}
+
+	return stats, nil
+}
+
+// buildStatsQuery counts the logs matching the filter by action, severity and
+// resource type, and over time in buckets of interval
+func (r *repository) buildStatsQuery(filter *domain.AuditLogFilter, interval time.Duration) map[string]any {
+	timeline := map[string]any{
+		"field":          "timestamp",
+		"fixed_interval": fixedInterval(interval),
+		"min_doc_count":  0,
+	}
+	if !filter.StartTime.IsZero() && !filter.EndTime.IsZero() {
+		timeline["extended_bounds"] = map[string]any{
+			"min": filter.StartTime.UnixMilli(),
+			"max": filter.EndTime.UnixMilli(),
+		}
+	}
+
+	return map[string]any{
+		"size":             0,
+		"track_total_hits": true,
+		"query":            r.buildFilterQuery(filter),
+		"aggs": map[string]any{
+			"actions":    createTermsAggregation("action"),
+			"severities": createTermsAggregation("severity"),
+			"resources":  createTermsAggregation("resource_type"),
+			"timeline":   map[string]any{"date_histogram": timeline},
+		},
+	}
+}
+
 // buildSearchQuery constructs the OpenSearch query based on the filter
 func (r *repository) buildSearchQuery(filter *domain.AuditLogFilter) map[string]any {
+	query := map[string]any{
+		"query": r.buildFilterQuery(filter),
+	}
+
+	// Add pagination, continuing after a previous page's last hit when given
+	if filter.Page > 0 && filter.PageSize > 0 {
+		query["size"] = filter.PageSize
+		if len(filter.SearchAfter) > 0 {
+			query["search_after"] = filter.SearchAfter
+		} else {
+			query["from"] = (filter.Page - 1) * filter.PageSize
+		}
+	}
+
+	query["sort"] = buildSort(filter)
+
+	// Only return the selected fields of each document
+	if fields := filter.SelectedFields(); fields != nil {
+		query["_source"] = fields
+	}
+
+	// Full-text queries rank by relevance, unless sorted explicitly, and
+	// highlight what matched
+	if filter.Query != "" {
+		if len(filter.Sort) == 0 {
+			query["sort"] = append([]any{"_score"}, query["sort"].([]any)...)
+		}
+		fields := r.fullTextFields()
+		highlightFields := make(map[string]any, len(fields))
+		for _, field := range fields {
+			// flat_object fields can't be highlighted
+			if field != "metadata" {
+				highlightFields[field] = map[string]any{}
+			}
+		}
+		query["highlight"] = map[string]any{"fields": highlightFields}
+	}
+
+	return query
+}
+
+// buildSort orders hits by the filter's sort keys, ranking severities by level
+// through a script since the keyword field would sort them alphabetically.
+// The log ID breaks ties, so search_after neither skips nor repeats logs.
+func buildSort(filter *domain.AuditLogFilter) []any {
+	var sort []any
+	for _, key := range filter.SortOrder() {
+		order := "asc"
+		if key.Desc {
+			order = "desc"
+		}
+
+		if key.Field != "severity" {
+			sort = append(sort, map[string]any{key.Field: map[string]any{"order": order}})
+			continue
+		}
+
+		ranks := make(map[string]int, len(domain.SeverityLevels))
+		for i, level := range domain.SeverityLevels {
+			ranks[string(level)] = i
+		}
+		sort = append(sort, map[string]any{
+			"_script": map[string]any{
+				"type": "number",
+				"script": map[string]any{
+					"source": "doc['severity'].size() == 0 ? -1 : params.ranks.getOrDefault(doc['severity'].value, -1)",
+					"params": map[string]any{"ranks": ranks},
+				},
+				"order": order,
+			},
+		})
+	}
+	sort = append(sort, map[string]any{"id": map[string]any{"order": "asc"}})
+	return sort
+}
+
+// buildFilterQuery constructs the bool query matching the filter's criteria
+func (r *repository) buildFilterQuery(filter *domain.AuditLogFilter) map[string]any {
 	must := make([]map[string]any, 0)
+	mustNot := make([]map[string]any, 0)
 
 	// Add exact match filters (keyword fields)
 	exactMatches := map[string]string{
-		"user_id":       filter.UserID,
-		"action":        filter.Action,
-		"resource_type": filter.ResourceType,
-		"severity":      filter.Severity,
-		"session_id":    filter.SessionID,
+		"user_id":        filter.UserID,
+		"session_id":     filter.SessionID,
+		"correlation_id": filter.CorrelationID,
 	}
 	for field, value := range exactMatches {
 		if value != "" {
 			must = append(must, createTermQuery(field, value))
 		}
 	}
+	if filter.VisibleUserID != "" {
+		must = append(must, createTermQuery("user_id", filter.VisibleUserID))
+	}
+
+	// Add multi-value filters (keyword fields)
+	valueMatches := map[string]domain.ValueFilter{
+		"action":        filter.Action,
+		"resource_type": filter.ResourceType,
+		"severity":      filter.Severity,
+		"tags":          filter.Tags,
+	}
+	for field, values := range valueMatches {
+		if len(values.In) > 0 {
+			must = append(must, createTermsQuery(field, values.In))
+		}
+		if len(values.NotIn) > 0 {
+			mustNot = append(mustNot, createTermsQuery(field, values.NotIn))
+		}
+	}
 
 	// Add full-text search filters (text fields)
 	textMatches := map[string]string{
@@ -249,6 +727,19 @@ func (r *repository) buildSearchQuery(filter *domain.AuditLogFilter) map[string]
 		}
 	}
 
+	// Add full-text query across fields
+	if filter.Query != "" {
+		must = append(must, createFullTextQuery(filter.Query, r.fullTextFields()))
+	}
+
+	if filter.MinThreatScore > 0 {
+		must = append(must, map[string]any{
+			"range": map[string]any{
+				"threat_score": map[string]any{"gte": filter.MinThreatScore},
+			},
+		})
+	}
+
 	// Add IP address filter (special handling for IP type)
 	if filter.IPAddress != "" {
 		must = append(must, createTermQuery("ip_address", filter.IPAddress))
@@ -259,38 +750,53 @@ func (r *repository) buildSearchQuery(filter *domain.AuditLogFilter) map[string]
 		must = append(must, createTimeRangeQuery(filter.StartTime, filter.EndTime))
 	}
 
-	// Construct the final query
-	query := map[string]any{
-		"query": map[string]any{
-			"bool": map[string]any{
-				"must": must,
-			},
+	return map[string]any{
+		"bool": map[string]any{
+			"must":     must,
+			"must_not": mustNot,
 		},
 	}
+}
 
-	// Add pagination
-	if filter.Page > 0 && filter.PageSize > 0 {
-		query["from"] = (filter.Page - 1) * filter.PageSize
-		query["size"] = filter.PageSize
+// Helper functions to create specific query types
+func createTermQuery(field, value string) map[string]any {
+	return map[string]any{
+		"term": map[string]any{
+			field: value,
+		},
 	}
+}
 
-	// Add sorting (most recent first)
-	query["sort"] = []map[string]any{
-		{
-			"timestamp": map[string]any{
-				"order": "desc",
-			},
+func createTermsQuery(field string, values []string) map[string]any {
+	return map[string]any{
+		"terms": map[string]any{
+			field: values,
 		},
 	}
+}
 
-	return query
+// fullTextFields are the fields searched by a filter's full-text query. The
+// metadata fields searched depend on how the metadata is mapped.
+func (r *repository) fullTextFields() []string {
+	metadata := "metadata.*"
+	switch r.config.MetadataMapping {
+	case config.MetadataMappingFlatObject:
+		metadata = "metadata"
+	case config.MetadataMappingIndexedKeys:
+		metadata = "metadata_keys.*"
+	}
+	return []string{"message", metadata, "user_agent", "resource_id"}
 }
 
-// Helper functions to create specific query types
-func createTermQuery(field, value string) map[string]any {
+// createFullTextQuery uses simple_query_string, which supports quoted phrases,
+// +, | and - operators and prefix* terms, and never fails on bad syntax
+func createFullTextQuery(query string, fields []string) map[string]any {
 	return map[string]any{
-		"term": map[string]any{
-			field: value,
+		"simple_query_string": map[string]any{
+			"query":            query,
+			"fields":           fields,
+			"default_operator": "and",
+			"lenient":          true,
 		},
 	}
 }
@@ -303,6 +809,27 @@ func createMatchQuery(field, value string) map[string]any {
 	}
 }
 
+func createTermsAggregation(field string) map[string]any {
+	return map[string]any{
+		"terms": map[string]any{
+			"field": field,
+			"size":  statsTermsSize,
+		},
+	}
+}
+
+// fixedInterval formats a duration as an OpenSearch fixed_interval such as 5m, 1h or 7d
+func fixedInterval(d time.Duration) string {
+	switch {
+	case d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	default:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+}
+
 func createTimeRangeQuery(startTime, endTime time.Time) map[string]any {
 	timeRange := make(map[string]any)
 	if !startTime.IsZero() {
@@ -318,60 +845,18 @@ func createTimeRangeQuery(startTime, endTime time.Time) map[string]any {
 	}
 }
 
-// getIndexMapping returns the mapping for audit log index with optimized settings
-func (r *repository) getIndexMapping() string {
-	return `{
-		"mappings": {
-			"properties": {
-				"id": { "type": "keyword" },
-				"tenant_id": { "type": "keyword" },
-				"user_id": { "type": "keyword" },
-				"session_id": { "type": "keyword" },
-				"action": { "type": "keyword" },
-				"resource_type": { "type": "keyword" },
-				"resource_id": { "type": "keyword" },
-				"message": { "type": "text" },
-				"metadata": { 
-					"type": "object",
-					"dynamic": true
-				},
-				"before_state": {
-					"type": "object",
-					"dynamic": true
-				},
-				"after_state": {
-					"type": "object",
-					"dynamic": true
-				},
-				"severity": { "type": "keyword" },
-				"timestamp": { "type": "date" },
-				"ip_address": { "type": "ip" },
-				"user_agent": { "type": "text" }
-			}
-		},
-		"settings": {
-			"index": {
-				"number_of_shards": 1,
-				"number_of_replicas": 1,
-				"refresh_interval": "1s",
-				"mapping": {
-					"total_fields": {
-						"limit": 2000
-					}
-				}
-			}
-		}
-	}`
-}
-
 func (r *repository) CreateIndex(ctx context.Context, tenantID string, t time.Time) error {
-	indexName := r.config.GetIndexName(tenantID, t)
+	return createIndex(ctx, r.client, r.config.GetIndexName(tenantID, t), r.config.MetadataMapping)
+}
 
+// createIndex creates the named index with the audit log mapping, for the
+// metadata mapping strategy, unless it exists
+func createIndex(ctx context.Context, client *opensearch.Client, indexName string, strategy config.MetadataMapping) error {
 	// Check if index exists
 	exists := opensearchapi.IndicesExistsRequest{
 		Index: []string{indexName},
 	}
-	res, err := exists.Do(ctx, r.client)
+	res, err := exists.Do(ctx, client)
 	if err != nil {
 		return fmt.Errorf("failed to check index existence: %w", err)
 	}
@@ -384,10 +869,10 @@ func (r *repository) CreateIndex(ctx context.Context, tenantID string, t time.Ti
 	// Create index with mapping and settings
 	create := opensearchapi.IndicesCreateRequest{
 		Index: indexName,
-		Body:  strings.NewReader(r.getIndexMapping()),
+		Body:  strings.NewReader(getIndexMapping(strategy)),
 	}
 
-	res, err = create.Do(ctx, r.client)
+	res, err = create.Do(ctx, client)
 	if err != nil {
 		return fmt.Errorf("failed to create index: %w", err)
 	}
@@ -401,42 +886,147 @@ func (r *repository) CreateIndex(ctx context.Context, tenantID string, t time.Ti
 }
 
 func (r *repository) DeleteIndex(ctx context.Context, tenantID string) error {
-	indexName := r.config.GetIndexName(tenantID, time.Now()) // Assuming current time for deletion
+	indices, err := r.tenantIndices(ctx, tenantID)
+	if err != nil {
+		return err
+	}
 
-	delete := opensearchapi.IndicesDeleteRequest{
-		Index: []string{indexName},
+	names := make([]string, len(indices))
+	for i, index := range indices {
+		names[i] = index.Name
 	}
+	return deleteIndices(ctx, r.client, names)
+}
 
-	res, err := delete.Do(ctx, r.client)
+func (r *repository) ListIndices(ctx context.Context, tenantID string) ([]domain.SearchIndex, error) {
+	indices, err := r.tenantIndices(ctx, tenantID)
 	if err != nil {
-		return fmt.Errorf("failed to delete index: %w", err)
+		return nil, err
 	}
-	defer res.Body.Close()
+	slices.SortFunc(indices, func(a, b domain.SearchIndex) int {
+		return a.Day.Compare(b.Day)
+	})
+	return indices, nil
+}
 
-	if res.IsError() {
-		return fmt.Errorf("error deleting index: %s", res.String())
+func (r *repository) DeleteDailyIndex(ctx context.Context, tenantID string, day time.Time) error {
+	return deleteIndices(ctx, r.client, []string{r.config.GetIndexName(tenantID, day.UTC())})
+}
+
+func (r *repository) Delete(ctx context.Context, tenantID, logID string) error {
+	_, err := r.deleteByQuery(ctx, []string{r.config.GetIndexPattern(tenantID)}, map[string]any{
+		"ids": map[string]any{"values": []string{logID}},
+	})
+	return err
+}
+
+// DeleteRange drops the indices whose whole day is in the range, so their
+// space is freed at once, and deletes the matching logs from the others
+func (r *repository) DeleteRange(ctx context.Context, tenantID string, start, end time.Time) (int64, error) {
+	indices, err := r.tenantIndices(ctx, tenantID)
+	if err != nil {
+		return 0, err
 	}
 
-	return nil
+	var whole, partial []string
+	var deleted int64
+	for _, index := range indices {
+		dayEnd := index.Day.Add(24 * time.Hour)
+		switch {
+		case !index.Day.Before(start) && !dayEnd.After(end):
+			whole = append(whole, index.Name)
+			deleted += index.Docs
+		case index.Day.Before(end) && dayEnd.After(start):
+			partial = append(partial, index.Name)
+		}
+	}
+
+	if err := deleteIndices(ctx, r.client, whole); err != nil {
+		return 0, err
+	}
+	if len(partial) == 0 {
+		return deleted, nil
+	}
+
+	timestamp := map[string]any{"lt": end.Format(time.RFC3339Nano)}
+	if !start.IsZero() {
+		timestamp["gte"] = start.Format(time.RFC3339Nano)
+	}
+	n, err := r.deleteByQuery(ctx, partial, map[string]any{
+		"range": map[string]any{"timestamp": timestamp},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted + n, nil
 }
 
-func (r *repository) Delete(ctx context.Context, tenantID, logID string) error {
-	indexName := r.config.GetIndexName(tenantID, time.Now()) // Assuming current time for deletion
+// tenantIndices lists the tenant's daily indices with their document counts
+// and sizes
+func (r *repository) tenantIndices(ctx context.Context, tenantID string) ([]domain.SearchIndex, error) {
+	cat := opensearchapi.CatIndicesRequest{
+		Index:  []string{r.config.GetIndexPattern(tenantID)},
+		Format: "json",
+		Bytes:  "b",
+		H:      []string{"index", "docs.count", "store.size", "health"},
+	}
+	res, err := cat.Do(ctx, r.client)
+	if err := checkResponse(res, err, "list tenant indices"); err != nil {
+		return nil, err
+	}
 
-	req := opensearchapi.DeleteRequest{
-		Index:      indexName,
-		DocumentID: logID,
+	var rows []struct {
+		Index  string `json:"index"`
+		Docs   string `json:"docs.count"`
+		Size   string `json:"store.size"`
+		Health string `json:"health"`
+	}
+	if err := decodeResponse(res, &rows); err != nil {
+		return nil, err
 	}
 
-	res, err := req.Do(ctx, r.client)
+	indices := make([]domain.SearchIndex, 0, len(rows))
+	for _, row := range rows {
+		indexTenant, day, ok := r.config.ParseIndexName(row.Index)
+		if !ok || indexTenant != tenantID {
+			continue
+		}
+		docs, _ := strconv.ParseInt(row.Docs, 10, 64)
+		size, _ := strconv.ParseInt(row.Size, 10, 64)
+		indices = append(indices, domain.SearchIndex{Name: row.Index, Day: day, Docs: docs, SizeBytes: size, Health: row.Health})
+	}
+	return indices, nil
+}
+
+// deleteByQuery deletes the documents of the indices that match query and
+// returns how many were deleted. Documents changed meanwhile are still deleted.
+func (r *repository) deleteByQuery(ctx context.Context, indices []string, query map[string]any) (int64, error) {
+	body, err := json.Marshal(map[string]any{"query": query})
 	if err != nil {
-		return fmt.Errorf("failed to delete document: %w", err)
+		return 0, fmt.Errorf("failed to marshal query: %w", err)
 	}
-	defer res.Body.Close()
 
-	if res.IsError() {
-		return fmt.Errorf("error deleting document: %s", res.String())
+	allowNoIndices := true
+	req := opensearchapi.DeleteByQueryRequest{
+		Index:          indices,
+		Body:           strings.NewReader(string(body)),
+		Conflicts:      "proceed",
+		AllowNoIndices: &allowNoIndices,
+	}
+	res, err := req.Do(ctx, r.client)
+	if err := checkResponse(res, err, "delete by query"); err != nil {
+		return 0, err
 	}
 
-	return nil
+	var result struct {
+		Deleted  int64             `json:"deleted"`
+		Failures []json.RawMessage `json:"failures"`
+	}
+	if err := decodeResponse(res, &result); err != nil {
+		return 0, err
+	}
+	if len(result.Failures) > 0 {
+		return result.Deleted, fmt.Errorf("delete by query failed for %d documents: %s", len(result.Failures), result.Failures[0])
+	}
+	return result.Deleted, nil
 }
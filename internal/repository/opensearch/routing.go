@@ -0,0 +1,246 @@
+package opensearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opensearch-project/opensearch-go/v2"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+// TenantSettingsResolver returns the settings of a tenant
+type TenantSettingsResolver interface {
+	ResolveSettings(ctx context.Context, tenantID string) (*domain.TenantSettings, error)
+}
+
+// TenantGetter fetches a tenant, as the tenant repository does
+type TenantGetter interface {
+	GetByID(ctx context.Context, id string) (*domain.Tenant, error)
+}
+
+type tenantSettingsEntry struct {
+	settings  *domain.TenantSettings
+	fetchedAt time.Time
+}
+
+// tenantSettings reads the settings of tenants, caching them in memory for
+// ttl so that routing and bulk writes don't each query the tenant
+type tenantSettings struct {
+	tenants TenantGetter
+	ttl     time.Duration
+
+	mu      sync.Mutex
+	entries map[string]tenantSettingsEntry
+}
+
+func NewTenantSettings(tenants TenantGetter, ttl time.Duration) TenantSettingsResolver {
+	return &tenantSettings{
+		tenants: tenants,
+		ttl:     ttl,
+		entries: make(map[string]tenantSettingsEntry),
+	}
+}
+
+func (r *tenantSettings) ResolveSettings(ctx context.Context, tenantID string) (*domain.TenantSettings, error) {
+	r.mu.Lock()
+	entry, ok := r.entries[tenantID]
+	r.mu.Unlock()
+	if ok && time.Since(entry.fetchedAt) < r.ttl {
+		return entry.settings, nil
+	}
+
+	tenant, err := r.tenants.GetByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.entries[tenantID] = tenantSettingsEntry{settings: &tenant.Settings, fetchedAt: time.Now()}
+	r.mu.Unlock()
+	return &tenant.Settings, nil
+}
+
+// routedRepository sends the calls for a tenant to the cluster serving its
+// data residency region, or to the default cluster when no cluster does
+type routedRepository struct {
+	clusters map[string]Repository
+	settings TenantSettingsResolver
+}
+
+// NewRoutedRepository routes each tenant's calls to one of clusters, by
+// region, which must hold the default cluster under
+// config.DefaultOpenSearchCluster. With the default cluster alone, its
+// repository is returned as is.
+func NewRoutedRepository(clusters map[string]Repository, settings TenantSettingsResolver) Repository {
+	if len(clusters) == 1 {
+		return clusters[config.DefaultOpenSearchCluster]
+	}
+	return &routedRepository{
+		clusters: clusters,
+		settings: settings,
+	}
+}
+
+// NewClusterRepository returns the repository of every cluster of clients,
+// by region, routing each tenant's calls to its cluster
+func NewClusterRepository(clients map[string]*opensearch.Client, config *config.OpenSearchConfig, settings TenantSettingsResolver) Repository {
+	clusters := make(map[string]Repository, len(clients))
+	for cluster, client := range clients {
+		clusters[cluster] = NewRepository(client, config, settings)
+	}
+	return NewRoutedRepository(clusters, settings)
+}
+
+// route returns the repository of the cluster serving the tenant. Calls
+// without a tenant go to the default cluster.
+func (r *routedRepository) route(ctx context.Context, tenantID string) (Repository, error) {
+	if tenantID == "" || tenantID == domain.SystemTenantID {
+		return r.clusters[config.DefaultOpenSearchCluster], nil
+	}
+	settings, err := r.settings.ResolveSettings(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve the cluster of tenant %s: %w", tenantID, err)
+	}
+	if repo, ok := r.clusters[settings.DataResidencyRegion]; ok {
+		return repo, nil
+	}
+	return r.clusters[config.DefaultOpenSearchCluster], nil
+}
+
+func (r *routedRepository) Index(ctx context.Context, log *domain.AuditLog) error {
+	repo, err := r.route(ctx, log.TenantID)
+	if err != nil {
+		return err
+	}
+	return repo.Index(ctx, log)
+}
+
+// BulkIndex splits the logs by cluster. Logs a cluster rejected are returned
+// together in one *BulkIndexError.
+func (r *routedRepository) BulkIndex(ctx context.Context, logs []domain.AuditLog) error {
+	groups := make(map[Repository][]domain.AuditLog)
+	for _, log := range logs {
+		repo, err := r.route(ctx, log.TenantID)
+		if err != nil {
+			return err
+		}
+		groups[repo] = append(groups[repo], log)
+	}
+
+	var failures []BulkItemFailure
+	for repo, groupLogs := range groups {
+		err := repo.BulkIndex(ctx, groupLogs)
+		var bulkErr *BulkIndexError
+		if errors.As(err, &bulkErr) {
+			failures = append(failures, bulkErr.Failures...)
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if len(failures) > 0 {
+		return &BulkIndexError{Failures: failures}
+	}
+	return nil
+}
+
+func (r *routedRepository) Search(ctx context.Context, filter *domain.AuditLogFilter) ([]domain.AuditLog, error) {
+	repo, err := r.route(ctx, filter.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.Search(ctx, filter)
+}
+
+func (r *routedRepository) Scan(ctx context.Context, filter *domain.AuditLogFilter, size int, fn func([]domain.AuditLog) error) error {
+	repo, err := r.route(ctx, filter.TenantID)
+	if err != nil {
+		return err
+	}
+	return repo.Scan(ctx, filter, size, fn)
+}
+
+// GetByIDs asks every cluster, since the IDs don't tell the tenant
+func (r *routedRepository) GetByIDs(ctx context.Context, ids []string) ([]domain.AuditLog, error) {
+	var logs []domain.AuditLog
+	for _, repo := range r.clusters {
+		found, err := repo.GetByIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, found...)
+	}
+	return logs, nil
+}
+
+func (r *routedRepository) Stats(ctx context.Context, filter *domain.AuditLogFilter) (*domain.AuditLogStats, error) {
+	repo, err := r.route(ctx, filter.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.Stats(ctx, filter)
+}
+
+func (r *routedRepository) TopStats(ctx context.Context, filter *domain.AuditLogFilter, limit int) (*domain.TopStats, error) {
+	repo, err := r.route(ctx, filter.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.TopStats(ctx, filter, limit)
+}
+
+func (r *routedRepository) CreateIndex(ctx context.Context, tenantID string, t time.Time) error {
+	repo, err := r.route(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	return repo.CreateIndex(ctx, tenantID, t)
+}
+
+// DeleteIndex deletes the tenant's indices from every cluster, so none are
+// left behind on a cluster the tenant moved away from
+func (r *routedRepository) DeleteIndex(ctx context.Context, tenantID string) error {
+	for _, repo := range r.clusters {
+		if err := repo.DeleteIndex(ctx, tenantID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *routedRepository) ListIndices(ctx context.Context, tenantID string) ([]domain.SearchIndex, error) {
+	repo, err := r.route(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListIndices(ctx, tenantID)
+}
+
+func (r *routedRepository) DeleteDailyIndex(ctx context.Context, tenantID string, day time.Time) error {
+	repo, err := r.route(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	return repo.DeleteDailyIndex(ctx, tenantID, day)
+}
+
+func (r *routedRepository) Delete(ctx context.Context, tenantID, logID string) error {
+	repo, err := r.route(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	return repo.Delete(ctx, tenantID, logID)
+}
+
+func (r *routedRepository) DeleteRange(ctx context.Context, tenantID string, start, end time.Time) (int64, error) {
+	repo, err := r.route(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	return repo.DeleteRange(ctx, tenantID, start, end)
+}
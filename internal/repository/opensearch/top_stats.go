@@ -0,0 +1,183 @@
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opensearch-project/opensearch-go/v2/opensearchapi"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/utils"
+)
+
+// cardinalityAggregation is the result of a cardinality aggregation, an
+// approximate distinct count
+type cardinalityAggregation struct {
+	Value int64 `json:"value"`
+}
+
+func (r *repository) TopStats(ctx context.Context, filter *domain.AuditLogFilter, limit int) (*domain.TopStats, error) {
+	tenantID := filter.TenantID
+	if tenantID == "" {
+		var err error
+		if tenantID, err = utils.GetTenantIDFromContext(ctx); err != nil {
+			return nil, fmt.Errorf("failed to get tenant ID from context: %w", err)
+		}
+	}
+
+	queryJSON, err := json.Marshal(r.buildTopStatsQuery(filter, limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal query: %w", err)
+	}
+
+	req := opensearchapi.SearchRequest{
+		Index: []string{r.config.GetIndexPattern(tenantID)},
+		Body:  strings.NewReader(string(queryJSON)),
+	}
+
+	res, err := req.Do(ctx, r.client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute top stats search: %w", err)
+	}
+	defer res.Body.Close()
+
+	stats := &domain.TopStats{
+		Users:       []domain.TopValue{},
+		IPAddresses: []domain.TopValue{},
+		Resources:   []domain.TopValue{},
+		Sessions:    []domain.TopValue{},
+		Daily:       []domain.DailyUniqueCounts{},
+	}
+
+	if res.IsError() {
+		if res.StatusCode == 404 {
+			return stats, nil
+		}
+		return nil, fmt.Errorf("top stats search request failed: %s", res.String())
+	}
+
+	var result struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+		} `json:"hits"`
+		Aggregations struct {
+			Users       termsAggregation `json:"users"`
+			IPAddresses termsAggregation `json:"ip_addresses"`
+			Sessions    termsAggregation `json:"sessions"`
+			Resources   struct {
+				Buckets []struct {
+					Key      string           `json:"key"`
+					DocCount int64            `json:"doc_count"`
+					Type     termsAggregation `json:"type"`
+				} `json:"buckets"`
+			} `json:"resources"`
+			UniqueUsers       cardinalityAggregation `json:"unique_users"`
+			UniqueIPAddresses cardinalityAggregation `json:"unique_ip_addresses"`
+			Daily             struct {
+				Buckets []struct {
+					Key               int64                  `json:"key"`
+					UniqueUsers       cardinalityAggregation `json:"unique_users"`
+					UniqueIPAddresses cardinalityAggregation `json:"unique_ip_addresses"`
+				} `json:"buckets"`
+			} `json:"daily"`
+		} `json:"aggregations"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	aggs := result.Aggregations
+	stats.TotalLogs = result.Hits.Total.Value
+	stats.Users = topValues(aggs.Users)
+	stats.IPAddresses = topValues(aggs.IPAddresses)
+	stats.Sessions = topValues(aggs.Sessions)
+	for _, b := range aggs.Resources.Buckets {
+		value := domain.TopValue{Value: b.Key, Count: b.DocCount}
+		if len(b.Type.Buckets) > 0 {
+			value.ResourceType = b.Type.Buckets[0].Key
+		}
+		stats.Resources = append(stats.Resources, value)
+	}
+	stats.UniqueUsers = aggs.UniqueUsers.Value
+	stats.UniqueIPAddresses = aggs.UniqueIPAddresses.Value
+	for _, b := range aggs.Daily.Buckets {
+		stats.Daily = append(stats.Daily, domain.DailyUniqueCounts{
+			Day:         time.UnixMilli(b.Key).UTC(),
+			Users:       b.UniqueUsers.Value,
+			IPAddresses: b.UniqueIPAddresses.Value,
+		})
+	}
+
+	return stats, nil
+}
+
+// buildTopStatsQuery ranks the values of the logs matching the filter with
+// terms aggregations, limit of each, and counts distinct users and IP
+// addresses with cardinality aggregations, over the range and per UTC day
+func (r *repository) buildTopStatsQuery(filter *domain.AuditLogFilter, limit int) map[string]any {
+	unique := func() map[string]any {
+		return map[string]any{
+			"unique_users":        map[string]any{"cardinality": map[string]any{"field": "user_id"}},
+			"unique_ip_addresses": map[string]any{"cardinality": map[string]any{"field": "ip_address"}},
+		}
+	}
+
+	daily := map[string]any{
+		"field":          "timestamp",
+		"fixed_interval": "1d",
+		"min_doc_count":  0,
+	}
+	if !filter.StartTime.IsZero() && !filter.EndTime.IsZero() {
+		daily["extended_bounds"] = map[string]any{
+			"min": filter.StartTime.UnixMilli(),
+			"max": filter.EndTime.UnixMilli(),
+		}
+	}
+
+	resources := topTermsAggregation("resource_id", limit)
+	resources["aggs"] = map[string]any{
+		"type": topTermsAggregation("resource_type", 1),
+	}
+
+	aggs := unique()
+	aggs["users"] = topTermsAggregation("user_id", limit)
+	aggs["ip_addresses"] = topTermsAggregation("ip_address", limit)
+	aggs["resources"] = resources
+	aggs["sessions"] = topTermsAggregation("session_id", limit)
+	aggs["daily"] = map[string]any{
+		"date_histogram": daily,
+		"aggs":           unique(),
+	}
+
+	return map[string]any{
+		"size":             0,
+		"track_total_hits": true,
+		"query":            r.buildFilterQuery(filter),
+		"aggs":             aggs,
+	}
+}
+
+// topTermsAggregation ranks the size most frequent values of field
+func topTermsAggregation(field string, size int) map[string]any {
+	return map[string]any{
+		"terms": map[string]any{
+			"field": field,
+			"size":  size,
+		},
+	}
+}
+
+func topValues(agg termsAggregation) []domain.TopValue {
+	values := make([]domain.TopValue, 0, len(agg.Buckets))
+	for _, b := range agg.Buckets {
+		if b.Key != "" {
+			values = append(values, domain.TopValue{Value: b.Key, Count: b.DocCount})
+		}
+	}
+	return values
+}
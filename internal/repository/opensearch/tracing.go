@@ -0,0 +1,127 @@
+package opensearch
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+)
+
+// tracedRepository wraps a Repository with one client span per operation
+type tracedRepository struct {
+	next Repository
+}
+
+func startSpan(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, attribute.String("db.system", "opensearch"))
+	return tracing.Start(ctx, "opensearch."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+func (r *tracedRepository) Index(ctx context.Context, log *domain.AuditLog) error {
+	ctx, span := startSpan(ctx, "Index", tracing.TenantAttr(log.TenantID), attribute.String("audit_log.id", log.ID))
+	err := r.next.Index(ctx, log)
+	tracing.End(span, err)
+	return err
+}
+
+func (r *tracedRepository) BulkIndex(ctx context.Context, logs []domain.AuditLog) error {
+	attrs := []attribute.KeyValue{attribute.Int("audit_log.count", len(logs))}
+	if len(logs) > 0 {
+		attrs = append(attrs, tracing.TenantAttr(logs[0].TenantID))
+	}
+	ctx, span := startSpan(ctx, "BulkIndex", attrs...)
+	err := r.next.BulkIndex(ctx, logs)
+	tracing.End(span, err)
+	return err
+}
+
+func (r *tracedRepository) Search(ctx context.Context, filter *domain.AuditLogFilter) ([]domain.AuditLog, error) {
+	ctx, span := startSpan(ctx, "Search")
+	logs, err := r.next.Search(ctx, filter)
+	span.SetAttributes(attribute.Int("audit_log.count", len(logs)))
+	tracing.End(span, err)
+	return logs, err
+}
+
+func (r *tracedRepository) Scan(ctx context.Context, filter *domain.AuditLogFilter, size int, fn func([]domain.AuditLog) error) error {
+	ctx, span := startSpan(ctx, "Scan", tracing.TenantAttr(filter.TenantID))
+	var count int
+	err := r.next.Scan(ctx, filter, size, func(logs []domain.AuditLog) error {
+		count += len(logs)
+		return fn(logs)
+	})
+	span.SetAttributes(attribute.Int("audit_log.count", count))
+	tracing.End(span, err)
+	return err
+}
+
+func (r *tracedRepository) GetByIDs(ctx context.Context, ids []string) ([]domain.AuditLog, error) {
+	ctx, span := startSpan(ctx, "GetByIDs", attribute.Int("audit_log.requested", len(ids)))
+	logs, err := r.next.GetByIDs(ctx, ids)
+	span.SetAttributes(attribute.Int("audit_log.count", len(logs)))
+	tracing.End(span, err)
+	return logs, err
+}
+
+func (r *tracedRepository) Stats(ctx context.Context, filter *domain.AuditLogFilter) (*domain.AuditLogStats, error) {
+	ctx, span := startSpan(ctx, "Stats", tracing.TenantAttr(filter.TenantID))
+	stats, err := r.next.Stats(ctx, filter)
+	tracing.End(span, err)
+	return stats, err
+}
+
+func (r *tracedRepository) TopStats(ctx context.Context, filter *domain.AuditLogFilter, limit int) (*domain.TopStats, error) {
+	ctx, span := startSpan(ctx, "TopStats", tracing.TenantAttr(filter.TenantID))
+	stats, err := r.next.TopStats(ctx, filter, limit)
+	tracing.End(span, err)
+	return stats, err
+}
+
+func (r *tracedRepository) CreateIndex(ctx context.Context, tenantID string, t time.Time) error {
+	ctx, span := startSpan(ctx, "CreateIndex", tracing.TenantAttr(tenantID))
+	err := r.next.CreateIndex(ctx, tenantID, t)
+	tracing.End(span, err)
+	return err
+}
+
+func (r *tracedRepository) DeleteIndex(ctx context.Context, tenantID string) error {
+	ctx, span := startSpan(ctx, "DeleteIndex", tracing.TenantAttr(tenantID))
+	err := r.next.DeleteIndex(ctx, tenantID)
+	tracing.End(span, err)
+	return err
+}
+
+func (r *tracedRepository) ListIndices(ctx context.Context, tenantID string) ([]domain.SearchIndex, error) {
+	ctx, span := startSpan(ctx, "ListIndices", tracing.TenantAttr(tenantID))
+	indices, err := r.next.ListIndices(ctx, tenantID)
+	tracing.End(span, err)
+	return indices, err
+}
+
+func (r *tracedRepository) DeleteDailyIndex(ctx context.Context, tenantID string, day time.Time) error {
+	ctx, span := startSpan(ctx, "DeleteDailyIndex", tracing.TenantAttr(tenantID))
+	err := r.next.DeleteDailyIndex(ctx, tenantID, day)
+	tracing.End(span, err)
+	return err
+}
+
+func (r *tracedRepository) Delete(ctx context.Context, tenantID, logID string) error {
+	ctx, span := startSpan(ctx, "Delete", tracing.TenantAttr(tenantID), attribute.String("audit_log.id", logID))
+	err := r.next.Delete(ctx, tenantID, logID)
+	tracing.End(span, err)
+	return err
+}
+
+func (r *tracedRepository) DeleteRange(ctx context.Context, tenantID string, start, end time.Time) (int64, error) {
+	ctx, span := startSpan(ctx, "DeleteRange", tracing.TenantAttr(tenantID))
+	deleted, err := r.next.DeleteRange(ctx, tenantID, start, end)
+	tracing.End(span, err)
+	return deleted, err
+}
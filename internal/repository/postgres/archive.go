@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+type ArchiveRepository struct {
+	writerDB *gorm.DB
+}
+
+func NewArchiveRepository(writerDB *gorm.DB) *ArchiveRepository {
+	return &ArchiveRepository{
+		writerDB: writerDB,
+	}
+}
+
+// Save records an archive, replacing the record of an archive rewritten to
+// the same manifest key
+func (r *ArchiveRepository) Save(ctx context.Context, archive *domain.Archive) error {
+	if archive.ID == "" {
+		archive.ID = uuid.New().String()
+	}
+
+	return r.writerDB.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "tenant_id"}, {Name: "manifest_key"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"before_date", "manifest_sha256", "log_count", "size", "parts", "storage_class",
+				"kms_key_id", "retain_until", "archived_at", "verification_status", "last_verified_at", "updated_at",
+			}),
+		}).
+		Create(archive).Error
+}
+
+func (r *ArchiveRepository) List(ctx context.Context, filter domain.ArchiveFilter) ([]domain.Archive, error) {
+	var archives []domain.Archive
+
+	db := r.writerDB.WithContext(ctx).Where("tenant_id = ?", filter.TenantID)
+
+	// Apply pagination
+	if filter.Limit > 0 {
+		db = db.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		db = db.Offset(filter.Offset)
+	}
+
+	if err := db.Order("before_date DESC").Find(&archives).Error; err != nil {
+		return nil, err
+	}
+	return archives, nil
+}
+
+func (r *ArchiveRepository) GetByID(ctx context.Context, tenantID, id string) (*domain.Archive, error) {
+	var archive domain.Archive
+
+	if err := r.writerDB.WithContext(ctx).First(&archive, "id = ? AND tenant_id = ?", id, tenantID).Error; err != nil {
+		return nil, err
+	}
+	return &archive, nil
+}
+
+func (r *ArchiveRepository) ListSince(ctx context.Context, tenantID string, since time.Time) ([]domain.Archive, error) {
+	var archives []domain.Archive
+
+	err := r.writerDB.WithContext(ctx).
+		Where("tenant_id = ? AND before_date > ?", tenantID, since).
+		Order("before_date DESC").
+		Find(&archives).Error
+	if err != nil {
+		return nil, err
+	}
+	return archives, nil
+}
+
+// UpdateVerification records the outcome of the archive's last verification
+func (r *ArchiveRepository) UpdateVerification(ctx context.Context, archive *domain.Archive) error {
+	return r.writerDB.WithContext(ctx).Model(archive).Updates(map[string]any{
+		"verification_status": archive.VerificationStatus,
+		"last_verified_at":    archive.LastVerifiedAt,
+	}).Error
+}
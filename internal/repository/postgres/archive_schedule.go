@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+type ArchiveScheduleRepository struct {
+	writerDB *gorm.DB
+}
+
+func NewArchiveScheduleRepository(writerDB *gorm.DB) *ArchiveScheduleRepository {
+	return &ArchiveScheduleRepository{writerDB: writerDB}
+}
+
+func (r *ArchiveScheduleRepository) GetByTenant(ctx context.Context, tenantID string) (*domain.ArchiveSchedule, error) {
+	var schedule domain.ArchiveSchedule
+	if err := r.writerDB.WithContext(ctx).First(&schedule, "tenant_id = ?", tenantID).Error; err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (r *ArchiveScheduleRepository) List(ctx context.Context) ([]domain.ArchiveSchedule, error) {
+	var schedules []domain.ArchiveSchedule
+	if err := r.writerDB.WithContext(ctx).Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// Save stores the schedule's overrides, keeping the record of its last run
+func (r *ArchiveScheduleRepository) Save(ctx context.Context, schedule *domain.ArchiveSchedule) error {
+	return r.writerDB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "interval_hours", "retention_days", "updated_at"}),
+	}).Omit("last_run_at", "last_job_id").Create(schedule).Error
+}
+
+// Claim records a run of the tenant's schedule at runAt with job jobID,
+// creating the default schedule if the tenant has none. It returns false when
+// the last run is no longer lastRunAt, i.e. another scheduler claimed the run.
+func (r *ArchiveScheduleRepository) Claim(ctx context.Context, tenantID string, lastRunAt *time.Time, runAt time.Time, jobID string) (bool, error) {
+	var claimed bool
+	err := r.writerDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(domain.DefaultArchiveSchedule(tenantID)).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&domain.ArchiveSchedule{}).
+			Where("tenant_id = ? AND last_run_at IS NOT DISTINCT FROM ?", tenantID, lastRunAt).
+			Updates(map[string]any{
+				"last_run_at": runAt,
+				"last_job_id": jobID,
+				"updated_at":  runAt,
+			})
+		claimed = result.RowsAffected == 1
+		return result.Error
+	})
+	return claimed, err
+}
@@ -2,25 +2,46 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
+	"github.com/kingrain94/audit-log-api/internal/config"
 	"github.com/kingrain94/audit-log-api/internal/domain"
 	"github.com/kingrain94/audit-log-api/internal/utils"
 )
 
+// failedSeverities are the severities counted as failed actions
+var failedSeverities = []string{string(domain.SeverityError), string(domain.SeverityCritical)}
+
+// threatScoreExpr reads the threat score of a log's metadata, NULL unless it
+// is a number
+const threatScoreExpr = "(CASE WHEN jsonb_typeof(metadata->'threat_score') = 'number' THEN (metadata->>'threat_score')::numeric END)"
+
 type AuditLogRepository struct {
 	writerDB *gorm.DB
 	readerDB *gorm.DB
+	// timescale is set when audit_logs is a TimescaleDB hypertable, whose
+	// hourly stats are a continuous aggregate rather than a view over the logs
+	timescale bool
+	// copy stores batches of logs with COPY FROM rather than INSERTs
+	copy bool
+	// conn is the connection of the transaction writerDB runs in, if any,
+	// which COPY runs on
+	conn *sql.Conn
 }
 
-func NewAuditLogRepository(writerDB, readerDB *gorm.DB) *AuditLogRepository {
+func NewAuditLogRepository(writerDB, readerDB *gorm.DB, storageMode, bulkInsertMode string) *AuditLogRepository {
 	return &AuditLogRepository{
-		writerDB: writerDB,
-		readerDB: readerDB,
+		writerDB:  writerDB,
+		readerDB:  readerDB,
+		timescale: storageMode == config.StorageModeTimescale,
+		copy:      bulkInsertMode == config.BulkInsertCopy,
 	}
 }
 
@@ -30,7 +51,9 @@ func (r *AuditLogRepository) Create(ctx context.Context, log *domain.AuditLog) e
 	}
 
 	// Use writer database for create operations
-	return r.writerDB.WithContext(ctx).Create(log).Error
+	return withStatementTimeout(ctx, r.writerDB, func(tx *gorm.DB) error {
+		return tx.Create(log).Error
+	})
 }
 
 func (r *AuditLogRepository) GetByID(ctx context.Context, id string) (*domain.AuditLog, error) {
@@ -48,6 +71,22 @@ func (r *AuditLogRepository) GetByID(ctx context.Context, id string) (*domain.Au
 	return &log, nil
 }
 
+// GetByIDs returns the logs of the tenant with the given IDs in a single query;
+// IDs that don't exist are left out
+func (r *AuditLogRepository) GetByIDs(ctx context.Context, ids []string) ([]domain.AuditLog, error) {
+	var logs []domain.AuditLog
+
+	db, err := getTenantScope(r.readerDB, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Where("id IN ?", ids).Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
 func (r *AuditLogRepository) List(ctx context.Context, filter domain.AuditLogFilter) ([]domain.AuditLog, error) {
 	var logs []domain.AuditLog
 
@@ -60,27 +99,8 @@ func (r *AuditLogRepository) List(ctx context.Context, filter domain.AuditLogFil
 	}
 
 	// Apply additional filters
-	if filter.UserID != "" {
-		db = db.Where("user_id = ?", filter.UserID)
-	}
-	if filter.Action != "" {
-		db = db.Where("action = ?", filter.Action)
-	}
-	if filter.ResourceType != "" {
-		db = db.Where("resource_type = ?", filter.ResourceType)
-	}
-	if filter.ResourceID != "" {
-		db = db.Where("resource_id = ?", filter.ResourceID)
-	}
-	if filter.Severity != "" {
-		db = db.Where("severity = ?", filter.Severity)
-	}
-	if !filter.StartTime.IsZero() {
-		db = db.Where("timestamp >= ?", filter.StartTime)
-	}
-	if !filter.EndTime.IsZero() {
-		db = db.Where("timestamp <= ?", filter.EndTime)
-	}
+	db = applyFilter(db, filter)
+	db = applySelect(db, filter)
 
 	// Apply pagination
 	if filter.Limit > 0 {
@@ -90,10 +110,11 @@ func (r *AuditLogRepository) List(ctx context.Context, filter domain.AuditLogFil
 		db = db.Offset(filter.Offset)
 	}
 
-	// Apply sorting
-	db = db.Order("timestamp DESC")
+	db = applySort(db, filter)
 
-	if err := db.Find(&logs).Error; err != nil {
+	if err := withStatementTimeout(ctx, db, func(tx *gorm.DB) error {
+		return tx.Find(&logs).Error
+	}); err != nil {
 		return nil, err
 	}
 
@@ -104,14 +125,83 @@ func (r *AuditLogRepository) DeleteBeforeDate(ctx context.Context, tenantID stri
 	// Use writer database for delete operations
 	db := r.writerDB.WithContext(ctx)
 
+	var dropped int64
+	if !r.timescale {
+		var err error
+		if dropped, err = r.dropTenantPartitions(db, tenantID, beforeDate); err != nil {
+			return 0, err
+		}
+	}
+
 	result := db.Where("tenant_id = ? AND timestamp < ?", tenantID, beforeDate).
 		Delete(&domain.AuditLog{})
 
 	if result.Error != nil {
-		return 0, result.Error
+		return dropped, result.Error
+	}
+
+	// The hourly stats of the deleted logs go with them, while the hour
+	// holding beforeDate keeps the logs after it
+	if !r.timescale {
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			_, err := rollupRange(tx, tenantID, time.Time{}, beforeDate)
+			return err
+		}); err != nil {
+			return dropped + result.RowsAffected, err
+		}
 	}
 
-	return result.RowsAffected, nil
+	return dropped + result.RowsAffected, nil
+}
+
+// dropTenantPartitions drops the monthly partitions that ended by beforeDate
+// and hold logs of the tenant only, which is far cheaper than deleting their
+// rows. It returns the number of logs dropped.
+func (r *AuditLogRepository) dropTenantPartitions(db *gorm.DB, tenantID string, beforeDate time.Time) (int64, error) {
+	partitions, err := listPartitions(db)
+	if err != nil {
+		return 0, err
+	}
+
+	var dropped int64
+	for _, partition := range partitions {
+		if partition.End().After(beforeDate) {
+			break
+		}
+
+		var count int64
+		err := db.Transaction(func(tx *gorm.DB) error {
+			// Keep other tenants from writing to the month until it's dropped
+			if err := tx.Exec("LOCK TABLE " + partition.Name + " IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+				return fmt.Errorf("failed to lock partition %s: %w", partition.Name, err)
+			}
+
+			var shared bool
+			if err := tx.Raw("SELECT EXISTS (SELECT 1 FROM "+partition.Name+" WHERE tenant_id <> ?)", tenantID).
+				Scan(&shared).Error; err != nil {
+				return fmt.Errorf("failed to check partition %s: %w", partition.Name, err)
+			}
+			if shared {
+				return nil
+			}
+
+			if err := tx.Raw("SELECT COUNT(*) FROM " + partition.Name).Scan(&count).Error; err != nil {
+				return fmt.Errorf("failed to count partition %s: %w", partition.Name, err)
+			}
+			// Empty months are left to the partition worker's retention
+			if count == 0 {
+				return nil
+			}
+
+			return dropPartition(tx, partition)
+		})
+		if err != nil {
+			return dropped, err
+		}
+		dropped += count
+	}
+
+	return dropped, nil
 }
 
 func (r *AuditLogRepository) BulkCreate(ctx context.Context, logs []domain.AuditLog) error {
@@ -129,7 +219,73 @@ func (r *AuditLogRepository) BulkCreate(ctx context.Context, logs []domain.Audit
 	}
 
 	// Use writer database for create operations
-	return r.writerDB.WithContext(ctx).CreateInBatches(logs, 100).Error
+	if r.copy {
+		return r.copyLogs(ctx, logs)
+	}
+	return withStatementTimeout(ctx, r.writerDB, func(tx *gorm.DB) error {
+		return tx.CreateInBatches(logs, 100).Error
+	})
+}
+
+func (r *AuditLogRepository) Restore(ctx context.Context, logs []domain.AuditLog) (int64, error) {
+	if len(logs) == 0 {
+		return 0, nil
+	}
+
+	// Logs keep their original IDs, so restoring the same archive twice is a no-op
+	var restored int64
+	var err error
+	if r.copy {
+		restored, err = r.restoreLogs(ctx, logs)
+	} else {
+		result := r.writerDB.WithContext(ctx).
+			Clauses(clause.OnConflict{DoNothing: true}).
+			CreateInBatches(logs, 100)
+		restored, err = result.RowsAffected, result.Error
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to restore logs: %w", err)
+	}
+
+	if err := r.rollupRestored(ctx, logs); err != nil {
+		return restored, err
+	}
+
+	return restored, nil
+}
+
+// rollupRestored recomputes the hourly stats of the hours the restored logs
+// fall in. The logs keep their original creation time, which the stats
+// worker's watermark is already past.
+func (r *AuditLogRepository) rollupRestored(ctx context.Context, logs []domain.AuditLog) error {
+	if r.timescale {
+		return nil
+	}
+
+	type span struct{ start, end time.Time }
+	spans := make(map[string]*span)
+	for _, log := range logs {
+		s, ok := spans[log.TenantID]
+		if !ok {
+			spans[log.TenantID] = &span{start: log.Timestamp, end: log.Timestamp}
+			continue
+		}
+		if log.Timestamp.Before(s.start) {
+			s.start = log.Timestamp
+		}
+		if log.Timestamp.After(s.end) {
+			s.end = log.Timestamp
+		}
+	}
+
+	return r.writerDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for tenantID, s := range spans {
+			if _, err := rollupRange(tx, tenantID, s.start, s.end); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 func (r *AuditLogRepository) GetStats(ctx context.Context, filter domain.AuditLogFilter) (*domain.AuditLogStats, error) {
@@ -150,14 +306,28 @@ func (r *AuditLogRepository) GetStats(ctx context.Context, filter domain.AuditLo
 		return nil, err
 	}
 
+	var stats *domain.AuditLogStats
+	err = withStatementTimeout(ctx, db, func(tx *gorm.DB) error {
+		stats, err = r.getStats(tx, filter)
+		return err
+	})
+	return stats, err
+}
+
+// getStats counts the logs of filter by action, severity and resource type
+// from the hourly stats or the logs themselves
+func (r *AuditLogRepository) getStats(db *gorm.DB, filter domain.AuditLogFilter) (*domain.AuditLogStats, error) {
 	stats := &domain.AuditLogStats{
 		ActionCounts:   make(map[domain.ActionType]int64),
 		SeverityCounts: make(map[domain.SeverityLevel]int64),
 		ResourceCounts: make(map[string]int64),
 	}
 
-	// Calculate time range duration
-	duration := filter.EndTime.Sub(filter.StartTime)
+	// The continuous aggregate is kept up to date by TimescaleDB and is read for
+	// any range. Otherwise the hourly stats table, which the stats worker rolls
+	// up shortly after logs are written, is read for short ranges, where its
+	// lag matters least against the cost of counting the logs.
+	hourly := r.timescale || filter.EndTime.Sub(filter.StartTime) <= 24*time.Hour
 
 	type countResult struct {
 		Category string
@@ -168,8 +338,7 @@ func (r *AuditLogRepository) GetStats(ctx context.Context, filter domain.AuditLo
 
 	// Choose the appropriate source based on time range
 	var query string
-	if duration <= 24*time.Hour {
-		// For last 24 hours, use hourly stats
+	if hourly {
 		query = `
 			SELECT category, key, SUM(count) as count FROM (
 				SELECT 'action' as category, action as key, count
@@ -238,12 +407,12 @@ func (r *AuditLogRepository) GetStats(ctx context.Context, filter domain.AuditLo
 	}
 
 	// Get total count using the same strategy
-	if duration <= 24*time.Hour {
+	if hourly {
 		if err := db.Raw(`
-			SELECT COUNT(*) FROM audit_logs_hourly_stats
+			SELECT COALESCE(SUM(count), 0) FROM audit_logs_hourly_stats
 			WHERE tenant_id = ? AND bucket >= ? AND bucket < ?`,
 			filter.TenantID, filter.StartTime, filter.EndTime).
-			Count(&stats.TotalLogs).Error; err != nil {
+			Scan(&stats.TotalLogs).Error; err != nil {
 			return nil, fmt.Errorf("failed to get total count: %w", err)
 		}
 	} else {
@@ -259,14 +428,82 @@ func (r *AuditLogRepository) GetStats(ctx context.Context, filter domain.AuditLo
 	return stats, nil
 }
 
-func (r *AuditLogRepository) GetRecentLogs(ctx context.Context, tenantID string, since time.Time) ([]domain.AuditLog, error) {
-	var logs []domain.AuditLog
+func (r *AuditLogRepository) GetActivity(ctx context.Context, tenantID string, start, end time.Time) (*domain.ActivityCounts, error) {
+	var counts domain.ActivityCounts
+
+	// Use reader database for read operations
+	err := r.readerDB.WithContext(ctx).Raw(`
+		SELECT COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE severity IN ?) AS failed
+		FROM audit_logs
+		WHERE tenant_id = ? AND timestamp >= ? AND timestamp < ?`,
+		failedSeverities, tenantID, start, end).
+		Scan(&counts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get activity: %w", err)
+	}
+
+	return &counts, nil
+}
+
+func (r *AuditLogRepository) GetHourlyActivity(ctx context.Context, tenantID string, start, end time.Time) (*domain.ActivityCounts, error) {
+	var counts domain.ActivityCounts
 
 	// Use reader database for read operations
+	err := r.readerDB.WithContext(ctx).Raw(`
+		SELECT COALESCE(SUM(count), 0) AS total,
+			COALESCE(SUM(count) FILTER (WHERE severity IN ?), 0) AS failed
+		FROM audit_logs_hourly_stats
+		WHERE tenant_id = ? AND bucket >= ? AND bucket < ?`,
+		failedSeverities, tenantID, start, end).
+		Scan(&counts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hourly activity: %w", err)
+	}
+
+	return &counts, nil
+}
+
+func (r *AuditLogRepository) ListNewIPAddresses(ctx context.Context, tenantID string, baselineStart, since, until time.Time) ([]domain.UserIPAddress, error) {
+	var addresses []domain.UserIPAddress
+
+	// Use reader database for read operations
+	err := r.readerDB.WithContext(ctx).Raw(`
+		SELECT DISTINCT w.user_id, w.ip_address
+		FROM audit_logs w
+		WHERE w.tenant_id = ? AND w.timestamp >= ? AND w.timestamp < ?
+			AND w.user_id <> '' AND w.ip_address <> ''
+			AND EXISTS (
+				SELECT 1 FROM audit_logs b
+				WHERE b.tenant_id = w.tenant_id AND b.user_id = w.user_id
+					AND b.timestamp >= ? AND b.timestamp < ?
+			)
+			AND NOT EXISTS (
+				SELECT 1 FROM audit_logs b
+				WHERE b.tenant_id = w.tenant_id AND b.user_id = w.user_id AND b.ip_address = w.ip_address
+					AND b.timestamp >= ? AND b.timestamp < ?
+			)
+		ORDER BY w.user_id, w.ip_address`,
+		tenantID, since, until,
+		baselineStart, since,
+		baselineStart, since).
+		Scan(&addresses).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list new IP addresses: %w", err)
+	}
+
+	return addresses, nil
+}
+
+func (r *AuditLogRepository) GetRecentLogs(ctx context.Context, tenantID string, since time.Time, limit int) ([]domain.AuditLog, error) {
+	var logs []domain.AuditLog
+
+	// Use reader database for read operations; created_at reflects ingest order,
+	// the order logs are published to streams in
 	err := r.readerDB.WithContext(ctx).
-		Where("tenant_id = ? AND timestamp >= ?", tenantID, since).
-		Order("timestamp DESC").
-		Limit(100). // Limit to prevent too many logs from being sent
+		Where("tenant_id = ? AND created_at >= ?", tenantID, since).
+		Order("created_at ASC, id ASC").
+		Limit(limit).
 		Find(&logs).Error
 
 	if err != nil {
@@ -275,3 +512,179 @@ func (r *AuditLogRepository) GetRecentLogs(ctx context.Context, tenantID string,
 
 	return logs, nil
 }
+
+func (r *AuditLogRepository) ListAfter(ctx context.Context, tenantID, afterID string, limit int) ([]domain.AuditLog, error) {
+	var logs []domain.AuditLog
+
+	// Use reader database for read operations; created_at reflects ingest order
+	err := r.readerDB.WithContext(ctx).
+		Where("tenant_id = ? AND created_at > (SELECT created_at FROM audit_logs WHERE id = ? AND tenant_id = ?)", tenantID, afterID, tenantID).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&logs).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list logs after %s: %w", afterID, err)
+	}
+
+	return logs, nil
+}
+
+// ListBatch returns up to limit logs matching filter in (timestamp, id) order,
+// starting after cursor. Keyset pagination keeps deep pages as cheap as the first.
+func (r *AuditLogRepository) ListBatch(ctx context.Context, filter domain.AuditLogFilter, cursor *domain.AuditLogCursor, limit int) ([]domain.AuditLog, error) {
+	if filter.TenantID == "" {
+		return nil, fmt.Errorf("tenant_id is required")
+	}
+
+	var logs []domain.AuditLog
+
+	// Use reader database for read operations
+	db := applyFilter(r.readerDB.WithContext(ctx).Where("tenant_id = ?", filter.TenantID), filter)
+	db = applySelect(db, filter)
+	if cursor != nil {
+		db = db.Where("(timestamp, id) > (?, ?)", cursor.Timestamp, cursor.ID)
+	}
+
+	err := withStatementTimeout(ctx, db, func(tx *gorm.DB) error {
+		return tx.Order("timestamp ASC, id ASC").
+			Limit(limit).
+			Find(&logs).Error
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list log batch: %w", err)
+	}
+
+	return logs, nil
+}
+
+// ListStoredAfter returns up to limit logs matching filter in the order they
+// were stored, (created_at, id), after cursor and no later than until. It
+// reads the writer, so logs committed since aren't missed on a lagging replica.
+func (r *AuditLogRepository) ListStoredAfter(ctx context.Context, filter domain.AuditLogFilter, cursor *domain.AuditLogCursor, until time.Time, limit int) ([]domain.AuditLog, error) {
+	if filter.TenantID == "" {
+		return nil, fmt.Errorf("tenant_id is required")
+	}
+
+	var logs []domain.AuditLog
+
+	db := applyFilter(r.writerDB.WithContext(ctx).Where("tenant_id = ? AND created_at <= ?", filter.TenantID, until), filter)
+	if cursor != nil {
+		db = db.Where("(created_at, id) > (?, ?)", cursor.Timestamp, cursor.ID)
+	}
+
+	err := withStatementTimeout(ctx, db, func(tx *gorm.DB) error {
+		return tx.Order("created_at ASC, id ASC").
+			Limit(limit).
+			Find(&logs).Error
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stored logs: %w", err)
+	}
+
+	return logs, nil
+}
+
+// applySelect reads only the filter's selected fields, so large JSONB columns
+// aren't loaded when the caller doesn't need them
+func applySelect(db *gorm.DB, filter domain.AuditLogFilter) *gorm.DB {
+	if fields := filter.SelectedFields(); fields != nil {
+		db = db.Select(fields)
+	}
+	return db
+}
+
+// applySort orders the logs by the filter's sort keys. Severities are ordered
+// by their position in domain.SeverityLevels.
+func applySort(db *gorm.DB, filter domain.AuditLogFilter) *gorm.DB {
+	for _, key := range filter.SortOrder() {
+		column := key.Field
+		if key.Field == "severity" {
+			column = severityRank
+		}
+		if key.Desc {
+			column += " DESC"
+		}
+		db = db.Order(column)
+	}
+	return db
+}
+
+// severityRank is a SQL expression ranking a log's severity by level
+var severityRank = func() string {
+	levels := make([]string, len(domain.SeverityLevels))
+	for i, level := range domain.SeverityLevels {
+		levels[i] = "'" + string(level) + "'"
+	}
+	return "array_position(ARRAY[" + strings.Join(levels, ", ") + "]::text[], severity)"
+}()
+
+// applyFilter adds the optional filter conditions shared by list queries
+func applyFilter(db *gorm.DB, filter domain.AuditLogFilter) *gorm.DB {
+	if filter.UserID != "" {
+		db = db.Where("user_id = ?", filter.UserID)
+	}
+	if filter.VisibleUserID != "" {
+		db = db.Where("user_id = ?", filter.VisibleUserID)
+	}
+	db = applyValueFilter(db, "action", filter.Action)
+	db = applyValueFilter(db, "resource_type", filter.ResourceType)
+	if filter.ResourceID != "" {
+		db = db.Where("resource_id = ?", filter.ResourceID)
+	}
+	db = applyValueFilter(db, "severity", filter.Severity)
+	// A log matches the included tags with any of them, which the GIN index on tags serves
+	if len(filter.Tags.In) > 0 {
+		db = db.Where("tags && ?", domain.Tags(filter.Tags.In))
+	}
+	if len(filter.Tags.NotIn) > 0 {
+		db = db.Where("NOT tags && ?", domain.Tags(filter.Tags.NotIn))
+	}
+	// The expression and the predicate match idx_audit_logs_threat_score
+	if filter.MinThreatScore > 0 {
+		db = db.Where("jsonb_typeof(metadata->'threat_score') = 'number' AND "+threatScoreExpr+" >= ?", filter.MinThreatScore)
+	}
+	if filter.SessionID != "" {
+		db = db.Where("session_id = ?", filter.SessionID)
+	}
+	if filter.CorrelationID != "" {
+		db = db.Where("correlation_id = ?", filter.CorrelationID)
+	}
+	if filter.IPAddress != "" {
+		db = db.Where("ip_address = ?", filter.IPAddress)
+	}
+	if filter.UserAgent != "" {
+		db = db.Where("user_agent ILIKE ?", "%"+filter.UserAgent+"%")
+	}
+	if filter.Message != "" {
+		db = db.Where("message ILIKE ?", "%"+filter.Message+"%")
+	}
+	// Without OpenSearch a full-text query is a substring match on the same fields
+	if filter.Query != "" {
+		pattern := "%" + filter.Query + "%"
+		db = db.Where("(message ILIKE ? OR metadata::text ILIKE ? OR user_agent ILIKE ? OR resource_id ILIKE ?)",
+			pattern, pattern, pattern, pattern)
+	}
+	if !filter.StartTime.IsZero() {
+		db = db.Where("timestamp >= ?", filter.StartTime)
+	}
+	if !filter.EndTime.IsZero() {
+		db = db.Where("timestamp <= ?", filter.EndTime)
+	}
+
+	return db
+}
+
+// applyValueFilter restricts column to the filter's included values and away
+// from its excluded ones
+func applyValueFilter(db *gorm.DB, column string, filter domain.ValueFilter) *gorm.DB {
+	if len(filter.In) > 0 {
+		db = db.Where(column+" IN ?", filter.In)
+	}
+	if len(filter.NotIn) > 0 {
+		db = db.Where(column+" NOT IN ?", filter.NotIn)
+	}
+	return db
+}
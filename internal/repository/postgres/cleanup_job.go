@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+type CleanupJobRepository struct {
+	writerDB *gorm.DB
+}
+
+func NewCleanupJobRepository(writerDB *gorm.DB) *CleanupJobRepository {
+	return &CleanupJobRepository{
+		writerDB: writerDB,
+	}
+}
+
+func (r *CleanupJobRepository) Create(ctx context.Context, job *domain.CleanupJob) error {
+	if job.ID == "" {
+		job.ID = uuid.New().String()
+	}
+
+	return r.writerDB.WithContext(ctx).Create(job).Error
+}
+
+// GetByID reads from the writer so the workers see each other's updates immediately
+func (r *CleanupJobRepository) GetByID(ctx context.Context, tenantID, id string) (*domain.CleanupJob, error) {
+	var job domain.CleanupJob
+
+	if err := r.writerDB.WithContext(ctx).First(&job, "id = ? AND tenant_id = ?", id, tenantID).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *CleanupJobRepository) Update(ctx context.Context, job *domain.CleanupJob) error {
+	return r.writerDB.WithContext(ctx).Save(job).Error
+}
@@ -0,0 +1,206 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+// copyColumns are the audit_logs columns COPY writes, in the order of copyRow
+var copyColumns = []string{
+	"id", "tenant_id", "user_id", "session_id", "correlation_id", "ip_address",
+	"user_agent", "action", "resource_type", "resource_id", "message", "severity",
+	"before_state", "after_state", "metadata", "schema_errors", "schema_version",
+	"tags", "timestamp", "created_at", "updated_at",
+}
+
+// copyStagingTable holds the logs Restore copies before inserting those that
+// don't exist yet, as COPY can't skip conflicting rows
+const copyStagingTable = "audit_logs_copy"
+
+// pgxExecutor is a pgx connection or transaction
+type pgxExecutor interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+// withPgx runs fn on the pgx connection under the writer. Inside a
+// transaction of the repository it runs on the transaction's connection;
+// otherwise in a transaction of its own. Either way the statements of fn are
+// bounded by a statement_timeout of ctx's deadline, if any.
+func (r *AuditLogRepository) withPgx(ctx context.Context, fn func(pgxExecutor) error) error {
+	conn := r.conn
+	if conn == nil {
+		sqlDB, err := r.writerDB.DB()
+		if err != nil {
+			return err
+		}
+		if conn, err = sqlDB.Conn(ctx); err != nil {
+			return err
+		}
+		defer conn.Close()
+	}
+
+	err := conn.Raw(func(driverConn any) error {
+		stdlibConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("COPY needs a pgx connection, got %T", driverConn)
+		}
+		run := func(db pgxExecutor) error {
+			if deadline, ok := ctx.Deadline(); ok {
+				timeout := max(time.Until(deadline).Milliseconds(), 1)
+				if _, err := db.Exec(ctx, fmt.Sprintf("SET LOCAL statement_timeout = %d", timeout)); err != nil {
+					return err
+				}
+			}
+			return fn(db)
+		}
+
+		if r.conn != nil {
+			return run(stdlibConn.Conn())
+		}
+		return pgx.BeginFunc(ctx, stdlibConn.Conn(), func(tx pgx.Tx) error {
+			return run(tx)
+		})
+	})
+	return queryError(err)
+}
+
+// copyLogs stores logs with COPY FROM, which PostgreSQL ingests far faster
+// than multi-row INSERTs of the same logs
+func (r *AuditLogRepository) copyLogs(ctx context.Context, logs []domain.AuditLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	rows, err := copyRows(logs)
+	if err != nil {
+		return err
+	}
+	return r.withPgx(ctx, func(db pgxExecutor) error {
+		_, err := db.CopyFrom(ctx, pgx.Identifier{domain.AuditLog{}.TableName()}, copyColumns, pgx.CopyFromRows(rows))
+		return err
+	})
+}
+
+// restoreLogs copies logs into a staging table, then inserts those that don't
+// exist yet, and returns the number inserted
+func (r *AuditLogRepository) restoreLogs(ctx context.Context, logs []domain.AuditLog) (int64, error) {
+	rows, err := copyRows(logs)
+	if err != nil {
+		return 0, err
+	}
+
+	var restored int64
+	err = r.withPgx(ctx, func(db pgxExecutor) error {
+		if _, err := db.Exec(ctx, fmt.Sprintf(
+			"CREATE TEMP TABLE %s (LIKE %s INCLUDING DEFAULTS)",
+			copyStagingTable, domain.AuditLog{}.TableName())); err != nil {
+			return err
+		}
+		if _, err := db.CopyFrom(ctx, pgx.Identifier{copyStagingTable}, copyColumns, pgx.CopyFromRows(rows)); err != nil {
+			return err
+		}
+
+		tag, err := db.Exec(ctx, fmt.Sprintf(
+			"INSERT INTO %s SELECT * FROM %s ON CONFLICT DO NOTHING",
+			domain.AuditLog{}.TableName(), copyStagingTable))
+		if err != nil {
+			return err
+		}
+		restored = tag.RowsAffected()
+
+		// A later restore in the same transaction creates it again; a failed
+		// one rolls back its creation
+		_, err = db.Exec(ctx, "DROP TABLE "+copyStagingTable)
+		return err
+	})
+	return restored, err
+}
+
+// copyRows returns the values of logs in the order of copyColumns, filling in
+// the defaults GORM leaves to the database and the times it sets on create
+func copyRows(logs []domain.AuditLog) ([][]any, error) {
+	now := time.Now()
+	rows := make([][]any, len(logs))
+	for i := range logs {
+		log := &logs[i]
+		if log.CreatedAt.IsZero() {
+			log.CreatedAt = now
+		}
+		if log.UpdatedAt.IsZero() {
+			log.UpdatedAt = now
+		}
+
+		id, err := copyUUID(log.ID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id of log %d: %w", i, err)
+		}
+		tenantID, err := copyUUID(log.TenantID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tenant_id of log %d: %w", i, err)
+		}
+		var schemaErrors []byte
+		if len(log.SchemaErrors) > 0 {
+			if schemaErrors, err = json.Marshal(log.SchemaErrors); err != nil {
+				return nil, err
+			}
+		}
+
+		rows[i] = []any{
+			id, tenantID, log.UserID, log.SessionID, copyNullString(log.CorrelationID), log.IPAddress,
+			log.UserAgent, log.Action, log.ResourceType, log.ResourceID, log.Message, copyDefault(log.Severity, string(domain.SeverityInfo)),
+			copyJSON(log.BeforeState), copyJSON(log.AfterState), copyJSON(log.Metadata), copyJSON(schemaErrors), int16(copyDefault(log.SchemaVersion, 1)),
+			copyTags(log.Tags), copyDefault(log.Timestamp, now), log.CreatedAt, log.UpdatedAt,
+		}
+	}
+	return rows, nil
+}
+
+// copyUUID encodes a UUID column, which pgx doesn't encode from strings
+func copyUUID(s string) (pgtype.UUID, error) {
+	var id pgtype.UUID
+	err := id.Scan(s)
+	return id, err
+}
+
+// copyNullString stores an empty string as NULL, as GORM does for columns
+// defaulting to null
+func copyNullString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// copyJSON stores an absent JSON payload as NULL
+func copyJSON(raw []byte) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	return raw
+}
+
+// copyTags stores absent tags as an empty array, the column's default
+func copyTags(tags domain.Tags) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+// copyDefault returns value, or def when value is zero
+func copyDefault[T comparable](value, def T) T {
+	var zero T
+	if value == zero {
+		return def
+	}
+	return value
+}
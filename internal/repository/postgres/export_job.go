@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+type ExportJobRepository struct {
+	writerDB *gorm.DB
+}
+
+func NewExportJobRepository(writerDB *gorm.DB) *ExportJobRepository {
+	return &ExportJobRepository{
+		writerDB: writerDB,
+	}
+}
+
+func (r *ExportJobRepository) Create(ctx context.Context, job *domain.ExportJob) error {
+	if job.ID == "" {
+		job.ID = uuid.New().String()
+	}
+
+	return r.writerDB.WithContext(ctx).Create(job).Error
+}
+
+// GetByID reads from the writer so status polls see updates made by the export worker immediately
+func (r *ExportJobRepository) GetByID(ctx context.Context, tenantID, id string) (*domain.ExportJob, error) {
+	var job domain.ExportJob
+
+	if err := r.writerDB.WithContext(ctx).First(&job, "id = ? AND tenant_id = ?", id, tenantID).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *ExportJobRepository) Update(ctx context.Context, job *domain.ExportJob) error {
+	return r.writerDB.WithContext(ctx).Save(job).Error
+}
@@ -2,9 +2,15 @@ package postgres
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
-	"github.com/kingrain94/audit-log-api/internal/utils"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/utils"
 )
 
 // getTenantScope returns a scoped database instance with tenant isolation
@@ -16,3 +22,38 @@ func getTenantScope(db *gorm.DB, ctx context.Context) (*gorm.DB, error) {
 
 	return db.WithContext(ctx).Where("tenant_id = ?", tenantID), nil
 }
+
+// withStatementTimeout runs fn on db. When ctx has a deadline, fn runs in a
+// transaction whose statement_timeout is the time left, so Postgres itself
+// cancels a query its caller gave up on rather than holding the connection
+// until it completes. A query cancelled either way returns
+// repository.ErrQueryTimeout.
+func withStatementTimeout(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return queryError(fn(db.WithContext(ctx)))
+	}
+
+	// A statement_timeout of 0 disables it, so allow at least a millisecond
+	timeout := max(time.Until(deadline).Milliseconds(), 1)
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(fmt.Sprintf("SET LOCAL statement_timeout = %d", timeout)).Error; err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+	return queryError(err)
+}
+
+// queryError marks errors of queries cancelled for their timeout with
+// repository.ErrQueryTimeout
+func queryError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var pgErr *pgconn.PgError
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &pgErr) && pgErr.Code == "57014") {
+		return fmt.Errorf("%w: %w", repository.ErrQueryTimeout, err)
+	}
+	return err
+}
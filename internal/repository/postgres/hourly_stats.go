@@ -0,0 +1,167 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// hourlyStatsRollup names the watermark of audit_logs_hourly_stats in
+// stats_rollup_state
+const hourlyStatsRollup = "audit_logs_hourly_stats"
+
+// RollupResult is the outcome of a rollup of the hourly stats
+type RollupResult struct {
+	// Buckets is the number of tenant hours recomputed
+	Buckets int64
+	// Watermark is the creation time up to which logs are now counted
+	Watermark time.Time
+}
+
+// StatsRollup maintains audit_logs_hourly_stats, the log counts per tenant,
+// hour, action, severity and resource type that short ranges of stats are
+// read from
+type StatsRollup interface {
+	// Watermark returns the creation time up to which logs are counted
+	Watermark(ctx context.Context) (time.Time, error)
+	// Rollup recomputes the hours holding logs created after the watermark
+	// and up to until, then moves the watermark to until
+	Rollup(ctx context.Context, until time.Time) (*RollupResult, error)
+	// Backfill recomputes the hours from the one holding start through the
+	// one holding end, of the tenant or of every tenant when tenantID is
+	// empty. It returns the number of tenant hours holding logs.
+	Backfill(ctx context.Context, tenantID string, start, end time.Time) (int64, error)
+}
+
+type statsRollup struct {
+	db *gorm.DB
+}
+
+func NewStatsRollup(db *gorm.DB) StatsRollup {
+	return &statsRollup{db: db}
+}
+
+func (s *statsRollup) Watermark(ctx context.Context) (time.Time, error) {
+	var watermark time.Time
+	err := s.db.WithContext(ctx).Raw("SELECT watermark FROM stats_rollup_state WHERE name = ?", hourlyStatsRollup).
+		Row().Scan(&watermark)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get the hourly stats watermark: %w", err)
+	}
+	return watermark, nil
+}
+
+func (s *statsRollup) Rollup(ctx context.Context, until time.Time) (*RollupResult, error) {
+	result := &RollupResult{}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Locking the watermark keeps concurrent workers from rolling up the
+		// same logs
+		var watermark time.Time
+		if err := tx.Raw("SELECT watermark FROM stats_rollup_state WHERE name = ? FOR UPDATE", hourlyStatsRollup).
+			Row().Scan(&watermark); err != nil {
+			return fmt.Errorf("failed to lock the hourly stats watermark: %w", err)
+		}
+		result.Watermark = watermark
+		if !until.After(watermark) {
+			return nil
+		}
+
+		if err := tx.Exec(`
+			CREATE TEMPORARY TABLE stats_rollup_buckets ON COMMIT DROP AS
+			SELECT DISTINCT tenant_id, date_trunc('hour', timestamp) AS bucket
+			FROM audit_logs
+			WHERE created_at > ? AND created_at <= ?`,
+			watermark, until).Error; err != nil {
+			return fmt.Errorf("failed to find the hours to roll up: %w", err)
+		}
+		buckets, err := rollupBuckets(tx)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Exec("UPDATE stats_rollup_state SET watermark = ?, updated_at = NOW() WHERE name = ?",
+			until, hourlyStatsRollup).Error; err != nil {
+			return fmt.Errorf("failed to move the hourly stats watermark: %w", err)
+		}
+		result.Buckets = buckets
+		result.Watermark = until
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *statsRollup) Backfill(ctx context.Context, tenantID string, start, end time.Time) (int64, error) {
+	var buckets int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		buckets, err = rollupRange(tx, tenantID, start, end)
+		return err
+	})
+	return buckets, err
+}
+
+// rollupRange recomputes the hours of the tenant, or of every tenant when
+// tenantID is empty, from the one holding start through the one holding end.
+// Hours left without logs lose their stats. It must run in a transaction.
+func rollupRange(tx *gorm.DB, tenantID string, start, end time.Time) (int64, error) {
+	tenant := ""
+	if tenantID != "" {
+		tenant = " AND tenant_id = @tenant"
+	}
+	args := map[string]any{"start": start, "end": end, "tenant": tenantID}
+
+	// Hours whose logs are all gone are left with no buckets to recompute
+	if err := tx.Exec(`
+		DELETE FROM audit_logs_hourly_stats
+		WHERE bucket >= date_trunc('hour', @start::timestamptz)
+		AND bucket <= date_trunc('hour', @end::timestamptz)`+tenant, args).Error; err != nil {
+		return 0, fmt.Errorf("failed to clear hourly stats: %w", err)
+	}
+	if err := tx.Exec(`
+		CREATE TEMPORARY TABLE stats_rollup_buckets ON COMMIT DROP AS
+		SELECT DISTINCT tenant_id, date_trunc('hour', timestamp) AS bucket
+		FROM audit_logs
+		WHERE timestamp >= date_trunc('hour', @start::timestamptz)
+		AND timestamp < date_trunc('hour', @end::timestamptz) + INTERVAL '1 hour'`+tenant, args).Error; err != nil {
+		return 0, fmt.Errorf("failed to find the hours to roll up: %w", err)
+	}
+	return rollupBuckets(tx)
+}
+
+// rollupBuckets recomputes the tenant hours listed in the
+// stats_rollup_buckets temporary table, which it drops
+func rollupBuckets(tx *gorm.DB) (int64, error) {
+	var buckets int64
+	if err := tx.Raw("SELECT COUNT(*) FROM stats_rollup_buckets").Scan(&buckets).Error; err != nil {
+		return 0, fmt.Errorf("failed to count the hours to roll up: %w", err)
+	}
+
+	if buckets > 0 {
+		if err := tx.Exec(`
+			DELETE FROM audit_logs_hourly_stats s
+			USING stats_rollup_buckets b
+			WHERE s.tenant_id = b.tenant_id AND s.bucket = b.bucket`).Error; err != nil {
+			return 0, fmt.Errorf("failed to clear hourly stats: %w", err)
+		}
+		if err := tx.Exec(`
+			INSERT INTO audit_logs_hourly_stats (bucket, tenant_id, action, severity, resource_type, count)
+			SELECT b.bucket, l.tenant_id, l.action, l.severity, COALESCE(l.resource_type, ''), COUNT(*)
+			FROM stats_rollup_buckets b
+			JOIN audit_logs l ON l.tenant_id = b.tenant_id
+				AND l.timestamp >= b.bucket AND l.timestamp < b.bucket + INTERVAL '1 hour'
+			GROUP BY 1, 2, 3, 4, 5`).Error; err != nil {
+			return 0, fmt.Errorf("failed to roll up hourly stats: %w", err)
+		}
+	}
+
+	// A transaction may roll up more than once
+	if err := tx.Exec("DROP TABLE stats_rollup_buckets").Error; err != nil {
+		return 0, fmt.Errorf("failed to drop the hours rolled up: %w", err)
+	}
+	return buckets, nil
+}
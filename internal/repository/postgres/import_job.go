@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+type ImportJobRepository struct {
+	writerDB *gorm.DB
+}
+
+func NewImportJobRepository(writerDB *gorm.DB) *ImportJobRepository {
+	return &ImportJobRepository{
+		writerDB: writerDB,
+	}
+}
+
+func (r *ImportJobRepository) Create(ctx context.Context, job *domain.ImportJob) error {
+	if job.ID == "" {
+		job.ID = uuid.New().String()
+	}
+
+	return r.writerDB.WithContext(ctx).Create(job).Error
+}
+
+// GetByID reads from the writer so status polls see the progress the archive worker saves immediately
+func (r *ImportJobRepository) GetByID(ctx context.Context, tenantID, id string) (*domain.ImportJob, error) {
+	var job domain.ImportJob
+
+	if err := r.writerDB.WithContext(ctx).First(&job, "id = ? AND tenant_id = ?", id, tenantID).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *ImportJobRepository) Update(ctx context.Context, job *domain.ImportJob) error {
+	return r.writerDB.WithContext(ctx).Save(job).Error
+}
@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+type IndexFailureRepository struct {
+	writerDB *gorm.DB
+}
+
+// NewIndexFailureRepository reads from the writer too, so a reprocess right
+// after a failure was stored finds it
+func NewIndexFailureRepository(writerDB *gorm.DB) *IndexFailureRepository {
+	return &IndexFailureRepository{
+		writerDB: writerDB,
+	}
+}
+
+func (r *IndexFailureRepository) Save(ctx context.Context, failures []domain.IndexFailure) error {
+	if len(failures) == 0 {
+		return nil
+	}
+	for i := range failures {
+		if failures[i].ID == "" {
+			failures[i].ID = uuid.New().String()
+		}
+	}
+
+	return r.writerDB.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "log_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"index_name", "status", "error_type", "reason", "document", "failed_at"}),
+		}).
+		Create(&failures).Error
+}
+
+func (r *IndexFailureRepository) ListByTenant(ctx context.Context, tenantID string, limit int) ([]domain.IndexFailure, error) {
+	var failures []domain.IndexFailure
+
+	err := r.writerDB.WithContext(ctx).
+		Where("tenant_id = ?", tenantID).
+		Order("failed_at DESC").
+		Limit(limit).
+		Find(&failures).Error
+	return failures, err
+}
+
+func (r *IndexFailureRepository) GetByIDs(ctx context.Context, tenantID string, ids []string) ([]domain.IndexFailure, error) {
+	var failures []domain.IndexFailure
+
+	err := r.writerDB.WithContext(ctx).
+		Where("tenant_id = ? AND id IN ?", tenantID, ids).
+		Find(&failures).Error
+	return failures, err
+}
+
+func (r *IndexFailureRepository) Delete(ctx context.Context, tenantID string, ids []string) error {
+	return r.writerDB.WithContext(ctx).
+		Where("tenant_id = ? AND id IN ?", tenantID, ids).
+		Delete(&domain.IndexFailure{}).Error
+}
@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+type IntegrationRepository struct {
+	writerDB *gorm.DB
+	readerDB *gorm.DB
+}
+
+func NewIntegrationRepository(writerDB, readerDB *gorm.DB) *IntegrationRepository {
+	return &IntegrationRepository{
+		writerDB: writerDB,
+		readerDB: readerDB,
+	}
+}
+
+func (r *IntegrationRepository) Create(ctx context.Context, integration *domain.Integration) error {
+	return r.writerDB.WithContext(ctx).Create(integration).Error
+}
+
+func (r *IntegrationRepository) GetByID(ctx context.Context, tenantID, id string) (*domain.Integration, error) {
+	var integration domain.Integration
+	if err := r.readerDB.WithContext(ctx).First(&integration, "id = ? AND tenant_id = ?", id, tenantID).Error; err != nil {
+		return nil, err
+	}
+	return &integration, nil
+}
+
+func (r *IntegrationRepository) ListByTenant(ctx context.Context, tenantID string) ([]domain.Integration, error) {
+	var integrations []domain.Integration
+	if err := r.readerDB.WithContext(ctx).
+		Where("tenant_id = ?", tenantID).
+		Order("name ASC").
+		Find(&integrations).Error; err != nil {
+		return nil, err
+	}
+	return integrations, nil
+}
+
+// Update stores the integration's settings and its next attempt, keeping the
+// forwarding progress the worker records
+func (r *IntegrationRepository) Update(ctx context.Context, integration *domain.Integration) error {
+	return r.writerDB.WithContext(ctx).
+		Select("name", "enabled", "settings", "sealed_token", "token_hint", "filter", "next_attempt_at", "updated_at").
+		Where("tenant_id = ?", integration.TenantID).
+		Updates(integration).Error
+}
+
+// Delete removes an integration, returning gorm.ErrRecordNotFound if the tenant has no such integration
+func (r *IntegrationRepository) Delete(ctx context.Context, tenantID, id string) error {
+	result := r.writerDB.WithContext(ctx).Delete(&domain.Integration{}, "id = ? AND tenant_id = ?", id, tenantID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ClaimDue locks up to limit enabled integrations whose next attempt is due
+// for the lease duration, those that ran least recently first
+func (r *IntegrationRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.Integration, error) {
+	var integrations []domain.Integration
+
+	err := r.writerDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("enabled AND (next_attempt_at IS NULL OR next_attempt_at <= ?) AND (locked_until IS NULL OR locked_until < ?)", now, now).
+			Order("locked_until ASC NULLS FIRST").
+			Limit(limit).
+			Find(&integrations).Error; err != nil {
+			return err
+		}
+
+		if len(integrations) == 0 {
+			return nil
+		}
+
+		ids := make([]string, len(integrations))
+		for i := range integrations {
+			ids[i] = integrations[i].ID
+		}
+
+		return tx.Model(&domain.Integration{}).
+			Where("id IN ?", ids).
+			Update("locked_until", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return integrations, nil
+}
+
+// SaveProgress stores the forwarding progress of an integration and releases
+// its lease, leaving the settings as they may have been updated since. The
+// lease ends now, recording when the integration last ran.
+func (r *IntegrationRepository) SaveProgress(ctx context.Context, integration *domain.Integration) error {
+	now := time.Now()
+	integration.LockedUntil = &now
+	return r.writerDB.WithContext(ctx).
+		Select("cursor_created_at", "cursor_id", "forwarded_count", "last_forwarded_at", "failures", "last_error", "next_attempt_at", "locked_until").
+		Updates(integration).Error
+}
+
+// Disable turns off an integration, unless its settings were updated since it
+// was read, since the update may have fixed what its deliveries failed on
+func (r *IntegrationRepository) Disable(ctx context.Context, integration *domain.Integration) error {
+	return r.writerDB.WithContext(ctx).
+		Model(&domain.Integration{}).
+		Where("id = ? AND updated_at = ?", integration.ID, integration.UpdatedAt).
+		Update("enabled", false).Error
+}
@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+type JobRepository struct {
+	writerDB *gorm.DB
+}
+
+// NewJobRepository reads from the writer so status polls see updates made by
+// the workers immediately
+func NewJobRepository(writerDB *gorm.DB) *JobRepository {
+	return &JobRepository{
+		writerDB: writerDB,
+	}
+}
+
+func (r *JobRepository) List(ctx context.Context, filter domain.JobFilter) ([]domain.Job, error) {
+	var jobs []domain.Job
+
+	db := r.writerDB.WithContext(ctx).Where("tenant_id = ?", filter.TenantID)
+	if filter.Type != "" {
+		db = db.Where("type = ?", filter.Type)
+	}
+	if filter.Status != "" {
+		db = db.Where("status = ?", filter.Status)
+	}
+
+	// Apply pagination
+	if filter.Limit > 0 {
+		db = db.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		db = db.Offset(filter.Offset)
+	}
+
+	if err := db.Order("created_at DESC").Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (r *JobRepository) GetByID(ctx context.Context, tenantID, id string) (*domain.Job, error) {
+	var job domain.Job
+
+	if err := r.writerDB.WithContext(ctx).First(&job, "id = ? AND tenant_id = ?", id, tenantID).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
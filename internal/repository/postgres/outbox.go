@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+type OutboxRepository struct {
+	writerDB *gorm.DB
+}
+
+func NewOutboxRepository(writerDB *gorm.DB) *OutboxRepository {
+	return &OutboxRepository{
+		writerDB: writerDB,
+	}
+}
+
+func (r *OutboxRepository) Create(ctx context.Context, event *domain.OutboxEvent) error {
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+
+	return r.writerDB.WithContext(ctx).Create(event).Error
+}
+
+// ClaimPending locks up to limit unprocessed events that aren't dead-lettered
+// for the lease duration so concurrent relays never publish the same event at
+// the same time
+func (r *OutboxRepository) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxEvent, error) {
+	var events []domain.OutboxEvent
+
+	err := r.writerDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("processed_at IS NULL AND dead_lettered_at IS NULL AND (locked_until IS NULL OR locked_until < ?)", now).
+			Order("created_at ASC").
+			Limit(limit).
+			Find(&events).Error; err != nil {
+			return err
+		}
+
+		if len(events) == 0 {
+			return nil
+		}
+
+		ids := make([]string, len(events))
+		for i := range events {
+			ids[i] = events[i].ID
+		}
+
+		lockedUntil := now.Add(lease)
+		return tx.Model(&domain.OutboxEvent{}).
+			Where("id IN ?", ids).
+			Updates(map[string]any{
+				"locked_until": lockedUntil,
+				"attempts":     gorm.Expr("attempts + 1"),
+			}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (r *OutboxRepository) MarkProcessed(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return r.writerDB.WithContext(ctx).
+		Model(&domain.OutboxEvent{}).
+		Where("id IN ?", ids).
+		Updates(map[string]any{
+			"processed_at": time.Now(),
+			"locked_until": nil,
+			"last_error":   "",
+		}).Error
+}
+
+// MarkFailed records the failure reason; the event becomes claimable again once its lease expires
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id string, reason string) error {
+	return r.writerDB.WithContext(ctx).
+		Model(&domain.OutboxEvent{}).
+		Where("id = ?", id).
+		Update("last_error", reason).Error
+}
+
+// MarkDeadLettered records the failure reason and stops the event from being claimed again
+func (r *OutboxRepository) MarkDeadLettered(ctx context.Context, id string, reason string) error {
+	return r.writerDB.WithContext(ctx).
+		Model(&domain.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"dead_lettered_at": time.Now(),
+			"locked_until":     nil,
+			"last_error":       reason,
+		}).Error
+}
+
+func (r *OutboxRepository) DeleteProcessedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.writerDB.WithContext(ctx).
+		Where("processed_at IS NOT NULL AND processed_at < ?", before).
+		Delete(&domain.OutboxEvent{})
+
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
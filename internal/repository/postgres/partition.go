@@ -0,0 +1,180 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+)
+
+const (
+	// partitionPrefix starts the name of every monthly partition of audit_logs,
+	// followed by the month in partitionMonthLayout, e.g. audit_logs_y2026m10
+	partitionPrefix      = "audit_logs_"
+	partitionMonthLayout = "y2006m01"
+	// defaultPartition holds the logs outside every monthly partition
+	defaultPartition = "audit_logs_default"
+)
+
+// PartitionInfo describes a monthly partition of the audit_logs table
+type PartitionInfo struct {
+	Name string
+	// Month is the first instant of the month the partition holds, in UTC
+	Month time.Time
+}
+
+// End returns the first instant after the partition's month
+func (p PartitionInfo) End() time.Time {
+	return p.Month.AddDate(0, 1, 0)
+}
+
+// PartitionManager creates and drops the monthly partitions of audit_logs
+type PartitionManager interface {
+	// ListPartitions returns the monthly partitions, oldest first
+	ListPartitions(ctx context.Context) ([]PartitionInfo, error)
+	// CreatePartition creates the partition of the month starting at month,
+	// moving the month's logs out of the default partition. It reports
+	// whether the partition was created rather than already there.
+	CreatePartition(ctx context.Context, month time.Time) (bool, error)
+	// DropPartition detaches the partition and drops it with its logs and
+	// their hourly stats
+	DropPartition(ctx context.Context, partition PartitionInfo) error
+}
+
+type partitionManager struct {
+	db     *gorm.DB
+	config *config.PartitionConfig
+}
+
+func NewPartitionManager(db *gorm.DB, config *config.PartitionConfig) PartitionManager {
+	return &partitionManager{
+		db:     db,
+		config: config,
+	}
+}
+
+func (m *partitionManager) ListPartitions(ctx context.Context) ([]PartitionInfo, error) {
+	return listPartitions(m.db.WithContext(ctx))
+}
+
+func (m *partitionManager) CreatePartition(ctx context.Context, month time.Time) (bool, error) {
+	month = time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	partition := PartitionInfo{Name: partitionName(month), Month: month}
+
+	created := false
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var exists bool
+		if err := tx.Raw("SELECT to_regclass(?) IS NOT NULL", partition.Name).Scan(&exists).Error; err != nil {
+			return fmt.Errorf("failed to check partition %s: %w", partition.Name, err)
+		}
+		if exists {
+			return nil
+		}
+
+		// A new partition can't overlap rows of the default partition, so the
+		// month's logs are set aside and written back once it exists
+		var hasDefault bool
+		if err := tx.Raw("SELECT to_regclass(?) IS NOT NULL", defaultPartition).Scan(&hasDefault).Error; err != nil {
+			return fmt.Errorf("failed to check default partition: %w", err)
+		}
+		if hasDefault {
+			if err := tx.Exec("CREATE TEMP TABLE audit_logs_moved (LIKE audit_logs) ON COMMIT DROP").Error; err != nil {
+				return fmt.Errorf("failed to create staging table: %w", err)
+			}
+			if err := tx.Exec(`
+				WITH moved AS (
+					DELETE FROM `+defaultPartition+` WHERE timestamp >= ? AND timestamp < ? RETURNING *
+				)
+				INSERT INTO audit_logs_moved SELECT * FROM moved`,
+				partition.Month, partition.End()).Error; err != nil {
+				return fmt.Errorf("failed to move logs out of the default partition: %w", err)
+			}
+		}
+
+		// DDL takes no bind parameters; the name and bounds are generated here
+		ddl := fmt.Sprintf("CREATE TABLE %s PARTITION OF audit_logs FOR VALUES FROM ('%s') TO ('%s')",
+			partition.Name, partition.Month.Format(time.RFC3339), partition.End().Format(time.RFC3339))
+		if m.config.TenantHashPartitions > 0 {
+			ddl += " PARTITION BY HASH (tenant_id)"
+		}
+		if err := tx.Exec(ddl).Error; err != nil {
+			return fmt.Errorf("failed to create partition %s: %w", partition.Name, err)
+		}
+		for i := 0; i < m.config.TenantHashPartitions; i++ {
+			if err := tx.Exec(fmt.Sprintf("CREATE TABLE %s_h%d PARTITION OF %s FOR VALUES WITH (MODULUS %d, REMAINDER %d)",
+				partition.Name, i, partition.Name, m.config.TenantHashPartitions, i)).Error; err != nil {
+				return fmt.Errorf("failed to create tenant partition %d of %s: %w", i, partition.Name, err)
+			}
+		}
+
+		if hasDefault {
+			if err := tx.Exec("INSERT INTO audit_logs SELECT * FROM audit_logs_moved").Error; err != nil {
+				return fmt.Errorf("failed to move logs into partition %s: %w", partition.Name, err)
+			}
+		}
+
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+func (m *partitionManager) DropPartition(ctx context.Context, partition PartitionInfo) error {
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return dropPartition(tx, partition)
+	})
+}
+
+// partitionName returns the name of the partition holding the given month
+func partitionName(month time.Time) string {
+	return partitionPrefix + month.UTC().Format(partitionMonthLayout)
+}
+
+// listPartitions returns the monthly partitions of audit_logs, oldest first.
+// Other children, such as the default partition, are left out.
+func listPartitions(db *gorm.DB) ([]PartitionInfo, error) {
+	var names []string
+	if err := db.Raw(`
+		SELECT c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = 'audit_logs'::regclass
+		ORDER BY c.relname`).
+		Scan(&names).Error; err != nil {
+		return nil, fmt.Errorf("failed to list partitions: %w", err)
+	}
+
+	var partitions []PartitionInfo
+	for _, name := range names {
+		if !strings.HasPrefix(name, partitionPrefix) {
+			continue
+		}
+		month, err := time.Parse(partitionMonthLayout, strings.TrimPrefix(name, partitionPrefix))
+		if err != nil {
+			continue
+		}
+		partitions = append(partitions, PartitionInfo{Name: name, Month: month})
+	}
+	return partitions, nil
+}
+
+// dropPartition detaches a partition and drops it, along with any tenant hash
+// partitions under it and the hourly stats of its month
+func dropPartition(db *gorm.DB, partition PartitionInfo) error {
+	name := partition.Name
+	if err := db.Exec(fmt.Sprintf("ALTER TABLE audit_logs DETACH PARTITION %s", name)).Error; err != nil {
+		return fmt.Errorf("failed to detach partition %s: %w", name, err)
+	}
+	if err := db.Exec(fmt.Sprintf("DROP TABLE %s", name)).Error; err != nil {
+		return fmt.Errorf("failed to drop partition %s: %w", name, err)
+	}
+	if err := db.Exec("DELETE FROM audit_logs_hourly_stats WHERE bucket >= ? AND bucket < ?",
+		partition.Month, partition.End()).Error; err != nil {
+		return fmt.Errorf("failed to delete the hourly stats of partition %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+type PolicyRepository struct {
+	writerDB *gorm.DB
+	readerDB *gorm.DB
+}
+
+func NewPolicyRepository(writerDB, readerDB *gorm.DB) *PolicyRepository {
+	return &PolicyRepository{
+		writerDB: writerDB,
+		readerDB: readerDB,
+	}
+}
+
+func (r *PolicyRepository) Create(ctx context.Context, policy *domain.Policy) error {
+	return r.writerDB.WithContext(ctx).Create(policy).Error
+}
+
+func (r *PolicyRepository) GetByID(ctx context.Context, tenantID, id string) (*domain.Policy, error) {
+	var policy domain.Policy
+	if err := r.readerDB.WithContext(ctx).First(&policy, "id = ? AND tenant_id = ?", id, tenantID).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *PolicyRepository) ListByTenant(ctx context.Context, tenantID string) ([]domain.Policy, error) {
+	var policies []domain.Policy
+	if err := r.readerDB.WithContext(ctx).
+		Where("tenant_id = ?", tenantID).
+		Order("role ASC, resource ASC, action ASC").
+		Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+func (r *PolicyRepository) Update(ctx context.Context, policy *domain.Policy) error {
+	return r.writerDB.WithContext(ctx).Save(policy).Error
+}
+
+// Delete removes a policy, returning gorm.ErrRecordNotFound if the tenant has no such policy
+func (r *PolicyRepository) Delete(ctx context.Context, tenantID, id string) error {
+	result := r.writerDB.WithContext(ctx).Delete(&domain.Policy{}, "id = ? AND tenant_id = ?", id, tenantID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -1,6 +1,10 @@
 package postgres
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
+
 	"gorm.io/gorm"
 
 	"github.com/kingrain94/audit-log-api/internal/config"
@@ -8,18 +12,72 @@ import (
 )
 
 type postgresRepository struct {
-	writerDB     *gorm.DB
-	readerDB     *gorm.DB
+	writerDB    *gorm.DB
+	readerDB    *gorm.DB
+	storageMode string
+	bulkInsert  string
+	// conn is the connection a transaction is pinned to, nil outside one
+	conn *sql.Conn
+
 	auditLogRepo repository.AuditLogRepository
 	tenantRepo   repository.TenantRepository
+	userRepo     repository.UserRepository
+	policyRepo   repository.PolicyRepository
+	redactRepo   repository.RedactionRuleRepository
+	tagRepo      repository.TaggingRuleRepository
+	schemaRepo   repository.ResourceSchemaRepository
+	searchRepo   repository.SavedSearchRepository
+	outboxRepo   repository.OutboxRepository
+	exportRepo   repository.ExportJobRepository
+	restoreRepo  repository.RestoreJobRepository
+	importRepo   repository.ImportJobRepository
+	cleanupRepo  repository.CleanupJobRepository
+	reindexRepo  repository.ReindexJobRepository
+	jobRepo      repository.JobRepository
+	retainRepo   repository.RetentionPolicyRepository
+	failureRepo  repository.IndexFailureRepository
+	scheduleRepo repository.ArchiveScheduleRepository
+	archiveRepo  repository.ArchiveRepository
+	integRepo    repository.IntegrationRepository
 }
 
 func NewPostgresRepository(dbConnections *config.DatabaseConnections) repository.PostgresRepository {
+	instrument(dbConnections.Writer)
+	instrument(dbConnections.Reader)
+
+	return newPostgresRepository(dbConnections.Writer, dbConnections.Reader, dbConnections.StorageMode, dbConnections.BulkInsertMode, nil)
+}
+
+func newPostgresRepository(writerDB, readerDB *gorm.DB, storageMode, bulkInsert string, conn *sql.Conn) *postgresRepository {
+	auditLogRepo := NewAuditLogRepository(writerDB, readerDB, storageMode, bulkInsert)
+	auditLogRepo.conn = conn
+
 	return &postgresRepository{
-		writerDB:     dbConnections.Writer,
-		readerDB:     dbConnections.Reader,
-		auditLogRepo: NewAuditLogRepository(dbConnections.Writer, dbConnections.Reader),
-		tenantRepo:   NewTenantRepository(dbConnections.Writer, dbConnections.Reader),
+		writerDB:     writerDB,
+		readerDB:     readerDB,
+		storageMode:  storageMode,
+		bulkInsert:   bulkInsert,
+		conn:         conn,
+		auditLogRepo: auditLogRepo,
+		tenantRepo:   NewTenantRepository(writerDB, readerDB),
+		userRepo:     NewUserRepository(writerDB, readerDB),
+		policyRepo:   NewPolicyRepository(writerDB, readerDB),
+		redactRepo:   NewRedactionRuleRepository(writerDB, readerDB),
+		tagRepo:      NewTaggingRuleRepository(writerDB, readerDB),
+		schemaRepo:   NewResourceSchemaRepository(writerDB, readerDB),
+		searchRepo:   NewSavedSearchRepository(writerDB, readerDB),
+		outboxRepo:   NewOutboxRepository(writerDB),
+		exportRepo:   NewExportJobRepository(writerDB),
+		restoreRepo:  NewRestoreJobRepository(writerDB),
+		importRepo:   NewImportJobRepository(writerDB),
+		cleanupRepo:  NewCleanupJobRepository(writerDB),
+		reindexRepo:  NewReindexJobRepository(writerDB),
+		jobRepo:      NewJobRepository(writerDB),
+		retainRepo:   NewRetentionPolicyRepository(readerDB),
+		failureRepo:  NewIndexFailureRepository(writerDB),
+		scheduleRepo: NewArchiveScheduleRepository(writerDB),
+		archiveRepo:  NewArchiveRepository(writerDB),
+		integRepo:    NewIntegrationRepository(writerDB, readerDB),
 	}
 }
 
@@ -30,3 +88,103 @@ func (r *postgresRepository) AuditLog() repository.AuditLogRepository {
 func (r *postgresRepository) Tenant() repository.TenantRepository {
 	return r.tenantRepo
 }
+
+func (r *postgresRepository) User() repository.UserRepository {
+	return r.userRepo
+}
+
+func (r *postgresRepository) Policy() repository.PolicyRepository {
+	return r.policyRepo
+}
+
+func (r *postgresRepository) RedactionRule() repository.RedactionRuleRepository {
+	return r.redactRepo
+}
+
+func (r *postgresRepository) TaggingRule() repository.TaggingRuleRepository {
+	return r.tagRepo
+}
+
+func (r *postgresRepository) ResourceSchema() repository.ResourceSchemaRepository {
+	return r.schemaRepo
+}
+
+func (r *postgresRepository) SavedSearch() repository.SavedSearchRepository {
+	return r.searchRepo
+}
+
+func (r *postgresRepository) Outbox() repository.OutboxRepository {
+	return r.outboxRepo
+}
+
+func (r *postgresRepository) ExportJob() repository.ExportJobRepository {
+	return r.exportRepo
+}
+
+func (r *postgresRepository) RestoreJob() repository.RestoreJobRepository {
+	return r.restoreRepo
+}
+
+func (r *postgresRepository) ImportJob() repository.ImportJobRepository {
+	return r.importRepo
+}
+
+func (r *postgresRepository) CleanupJob() repository.CleanupJobRepository {
+	return r.cleanupRepo
+}
+
+func (r *postgresRepository) ReindexJob() repository.ReindexJobRepository {
+	return r.reindexRepo
+}
+
+func (r *postgresRepository) Job() repository.JobRepository {
+	return r.jobRepo
+}
+
+func (r *postgresRepository) RetentionPolicy() repository.RetentionPolicyRepository {
+	return r.retainRepo
+}
+
+func (r *postgresRepository) IndexFailure() repository.IndexFailureRepository {
+	return r.failureRepo
+}
+
+func (r *postgresRepository) ArchiveSchedule() repository.ArchiveScheduleRepository {
+	return r.scheduleRepo
+}
+
+func (r *postgresRepository) Archive() repository.ArchiveRepository {
+	return r.archiveRepo
+}
+
+func (r *postgresRepository) Integration() repository.IntegrationRepository {
+	return r.integRepo
+}
+
+// Transaction binds both writer and reader to the same transaction so reads
+// inside fn see its writes. The transaction is pinned to one connection, which
+// COPY, out of reach of database/sql, runs on directly.
+func (r *postgresRepository) Transaction(ctx context.Context, fn func(tx repository.PostgresRepository) error) error {
+	if r.conn != nil {
+		// Nested in a transaction, which a savepoint stands in for
+		return r.writerDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(newPostgresRepository(tx, tx, r.storageMode, r.bulkInsert, r.conn))
+		})
+	}
+
+	sqlDB, err := r.writerDB.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get a database connection: %w", err)
+	}
+	defer conn.Close()
+
+	db := r.writerDB.WithContext(ctx)
+	db.Statement.ConnPool = conn
+	return db.Transaction(func(tx *gorm.DB) error {
+		return fn(newPostgresRepository(tx, tx, r.storageMode, r.bulkInsert, conn))
+	})
+}
@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+type RedactionRuleRepository struct {
+	writerDB *gorm.DB
+	readerDB *gorm.DB
+}
+
+func NewRedactionRuleRepository(writerDB, readerDB *gorm.DB) *RedactionRuleRepository {
+	return &RedactionRuleRepository{
+		writerDB: writerDB,
+		readerDB: readerDB,
+	}
+}
+
+func (r *RedactionRuleRepository) Create(ctx context.Context, rule *domain.RedactionRule) error {
+	return r.writerDB.WithContext(ctx).Create(rule).Error
+}
+
+func (r *RedactionRuleRepository) ListByTenant(ctx context.Context, tenantID string) ([]domain.RedactionRule, error) {
+	var rules []domain.RedactionRule
+	if err := r.readerDB.WithContext(ctx).
+		Where("tenant_id = ?", tenantID).
+		Order("target ASC, path ASC").
+		Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// Delete removes a rule, returning gorm.ErrRecordNotFound if the tenant has no such rule
+func (r *RedactionRuleRepository) Delete(ctx context.Context, tenantID, id string) error {
+	result := r.writerDB.WithContext(ctx).Delete(&domain.RedactionRule{}, "id = ? AND tenant_id = ?", id, tenantID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
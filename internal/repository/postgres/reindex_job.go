@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+type ReindexJobRepository struct {
+	writerDB *gorm.DB
+}
+
+func NewReindexJobRepository(writerDB *gorm.DB) *ReindexJobRepository {
+	return &ReindexJobRepository{
+		writerDB: writerDB,
+	}
+}
+
+func (r *ReindexJobRepository) Create(ctx context.Context, job *domain.ReindexJob) error {
+	if job.ID == "" {
+		job.ID = uuid.New().String()
+	}
+
+	return r.writerDB.WithContext(ctx).Create(job).Error
+}
+
+// GetByID reads from the writer so a resumed job starts from its latest checkpoint
+func (r *ReindexJobRepository) GetByID(ctx context.Context, tenantID, id string) (*domain.ReindexJob, error) {
+	var job domain.ReindexJob
+
+	if err := r.writerDB.WithContext(ctx).First(&job, "id = ? AND tenant_id = ?", id, tenantID).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *ReindexJobRepository) Update(ctx context.Context, job *domain.ReindexJob) error {
+	return r.writerDB.WithContext(ctx).Save(job).Error
+}
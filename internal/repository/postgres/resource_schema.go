@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+type ResourceSchemaRepository struct {
+	writerDB *gorm.DB
+	readerDB *gorm.DB
+}
+
+func NewResourceSchemaRepository(writerDB, readerDB *gorm.DB) *ResourceSchemaRepository {
+	return &ResourceSchemaRepository{
+		writerDB: writerDB,
+		readerDB: readerDB,
+	}
+}
+
+func (r *ResourceSchemaRepository) Create(ctx context.Context, schema *domain.ResourceSchema) error {
+	return r.writerDB.WithContext(ctx).Create(schema).Error
+}
+
+func (r *ResourceSchemaRepository) GetByID(ctx context.Context, tenantID, id string) (*domain.ResourceSchema, error) {
+	var schema domain.ResourceSchema
+	if err := r.readerDB.WithContext(ctx).First(&schema, "id = ? AND tenant_id = ?", id, tenantID).Error; err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+func (r *ResourceSchemaRepository) ListByTenant(ctx context.Context, tenantID string) ([]domain.ResourceSchema, error) {
+	var schemas []domain.ResourceSchema
+	if err := r.readerDB.WithContext(ctx).
+		Where("tenant_id = ?", tenantID).
+		Order("resource_type ASC").
+		Find(&schemas).Error; err != nil {
+		return nil, err
+	}
+	return schemas, nil
+}
+
+func (r *ResourceSchemaRepository) Update(ctx context.Context, schema *domain.ResourceSchema) error {
+	return r.writerDB.WithContext(ctx).Save(schema).Error
+}
+
+// Delete removes a schema, returning gorm.ErrRecordNotFound if the tenant has no such schema
+func (r *ResourceSchemaRepository) Delete(ctx context.Context, tenantID, id string) error {
+	result := r.writerDB.WithContext(ctx).Delete(&domain.ResourceSchema{}, "id = ? AND tenant_id = ?", id, tenantID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
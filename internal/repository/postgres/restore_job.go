@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+type RestoreJobRepository struct {
+	writerDB *gorm.DB
+}
+
+func NewRestoreJobRepository(writerDB *gorm.DB) *RestoreJobRepository {
+	return &RestoreJobRepository{
+		writerDB: writerDB,
+	}
+}
+
+func (r *RestoreJobRepository) Create(ctx context.Context, job *domain.RestoreJob) error {
+	if job.ID == "" {
+		job.ID = uuid.New().String()
+	}
+
+	return r.writerDB.WithContext(ctx).Create(job).Error
+}
+
+// GetByID reads from the writer so status polls see updates made by the archive worker immediately
+func (r *RestoreJobRepository) GetByID(ctx context.Context, tenantID, id string) (*domain.RestoreJob, error) {
+	var job domain.RestoreJob
+
+	if err := r.writerDB.WithContext(ctx).First(&job, "id = ? AND tenant_id = ?", id, tenantID).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *RestoreJobRepository) Update(ctx context.Context, job *domain.RestoreJob) error {
+	return r.writerDB.WithContext(ctx).Save(job).Error
+}
@@ -0,0 +1,28 @@
+package postgres
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+type RetentionPolicyRepository struct {
+	readerDB *gorm.DB
+}
+
+func NewRetentionPolicyRepository(readerDB *gorm.DB) *RetentionPolicyRepository {
+	return &RetentionPolicyRepository{readerDB: readerDB}
+}
+
+func (r *RetentionPolicyRepository) ListByTenant(ctx context.Context, tenantID string) ([]domain.RetentionPolicy, error) {
+	var policies []domain.RetentionPolicy
+	if err := r.readerDB.WithContext(ctx).
+		Where("tenant_id = ?", tenantID).
+		Order("name ASC").
+		Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
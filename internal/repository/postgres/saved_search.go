@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+type SavedSearchRepository struct {
+	writerDB *gorm.DB
+	readerDB *gorm.DB
+}
+
+func NewSavedSearchRepository(writerDB, readerDB *gorm.DB) *SavedSearchRepository {
+	return &SavedSearchRepository{
+		writerDB: writerDB,
+		readerDB: readerDB,
+	}
+}
+
+func (r *SavedSearchRepository) Create(ctx context.Context, search *domain.SavedSearch) error {
+	return r.writerDB.WithContext(ctx).Create(search).Error
+}
+
+func (r *SavedSearchRepository) GetByID(ctx context.Context, tenantID, id string) (*domain.SavedSearch, error) {
+	var search domain.SavedSearch
+	if err := r.readerDB.WithContext(ctx).First(&search, "id = ? AND tenant_id = ?", id, tenantID).Error; err != nil {
+		return nil, err
+	}
+	return &search, nil
+}
+
+func (r *SavedSearchRepository) ListVisible(ctx context.Context, tenantID, userID string) ([]domain.SavedSearch, error) {
+	var searches []domain.SavedSearch
+	if err := r.readerDB.WithContext(ctx).
+		Where("tenant_id = ? AND (owner_id = ? OR shared)", tenantID, userID).
+		Order("name ASC").
+		Find(&searches).Error; err != nil {
+		return nil, err
+	}
+	return searches, nil
+}
+
+func (r *SavedSearchRepository) Update(ctx context.Context, search *domain.SavedSearch) error {
+	return r.writerDB.WithContext(ctx).Save(search).Error
+}
+
+// Delete removes a saved search, returning gorm.ErrRecordNotFound if the tenant has no such search
+func (r *SavedSearchRepository) Delete(ctx context.Context, tenantID, id string) error {
+	result := r.writerDB.WithContext(ctx).Delete(&domain.SavedSearch{}, "id = ? AND tenant_id = ?", id, tenantID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
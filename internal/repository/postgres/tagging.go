@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+type TaggingRuleRepository struct {
+	writerDB *gorm.DB
+	readerDB *gorm.DB
+}
+
+func NewTaggingRuleRepository(writerDB, readerDB *gorm.DB) *TaggingRuleRepository {
+	return &TaggingRuleRepository{
+		writerDB: writerDB,
+		readerDB: readerDB,
+	}
+}
+
+func (r *TaggingRuleRepository) Create(ctx context.Context, rule *domain.TaggingRule) error {
+	return r.writerDB.WithContext(ctx).Create(rule).Error
+}
+
+func (r *TaggingRuleRepository) ListByTenant(ctx context.Context, tenantID string) ([]domain.TaggingRule, error) {
+	var rules []domain.TaggingRule
+	if err := r.readerDB.WithContext(ctx).
+		Where("tenant_id = ?", tenantID).
+		Order("tag ASC, created_at ASC").
+		Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// Delete removes a rule, returning gorm.ErrRecordNotFound if the tenant has no such rule
+func (r *TaggingRuleRepository) Delete(ctx context.Context, tenantID, id string) error {
+	result := r.writerDB.WithContext(ctx).Delete(&domain.TaggingRule{}, "id = ? AND tenant_id = ?", id, tenantID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -29,7 +30,7 @@ func (r *TenantRepository) Create(ctx context.Context, tenant *domain.Tenant) (*
 
 func (r *TenantRepository) GetByID(ctx context.Context, id string) (*domain.Tenant, error) {
 	var tenant domain.Tenant
-	if err := r.readerDB.WithContext(ctx).First(&tenant, "id = ?", id).Error; err != nil {
+	if err := r.readerDB.WithContext(ctx).First(&tenant, "id = ? AND deleted_at IS NULL", id).Error; err != nil {
 		return nil, err
 	}
 	return &tenant, nil
@@ -39,14 +40,84 @@ func (r *TenantRepository) Update(ctx context.Context, tenant *domain.Tenant) er
 	return r.writerDB.WithContext(ctx).Save(tenant).Error
 }
 
-func (r *TenantRepository) Delete(ctx context.Context, id string) error {
-	return r.writerDB.WithContext(ctx).Delete(&domain.Tenant{}, "id = ?", id).Error
+// Delete marks the tenant deleted, keeping its row and logs until purgeAfter
+func (r *TenantRepository) Delete(ctx context.Context, id string, purgeAfter time.Time) error {
+	result := r.writerDB.WithContext(ctx).
+		Model(&domain.Tenant{}).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Updates(map[string]any{
+			"deleted_at":  gorm.Expr("CURRENT_TIMESTAMP"),
+			"purge_after": purgeAfter,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
 
 func (r *TenantRepository) List(ctx context.Context) ([]domain.Tenant, error) {
 	var tenants []domain.Tenant
-	if err := r.readerDB.WithContext(ctx).Find(&tenants).Error; err != nil {
+	if err := r.readerDB.WithContext(ctx).Where("deleted_at IS NULL").Find(&tenants).Error; err != nil {
+		return nil, err
+	}
+	return tenants, nil
+}
+
+// GetDeleted returns a deleted tenant that is not purged yet
+func (r *TenantRepository) GetDeleted(ctx context.Context, id string) (*domain.Tenant, error) {
+	var tenant domain.Tenant
+	// Read from the writer so a restore right after the delete sees it
+	if err := r.writerDB.WithContext(ctx).First(&tenant, "id = ? AND deleted_at IS NOT NULL", id).Error; err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+// Restore clears the deletion of a tenant whose purge hasn't started
+func (r *TenantRepository) Restore(ctx context.Context, id string) error {
+	result := r.writerDB.WithContext(ctx).
+		Model(&domain.Tenant{}).
+		Where("id = ? AND deleted_at IS NOT NULL AND purge_started_at IS NULL", id).
+		Updates(map[string]any{
+			"deleted_at":  nil,
+			"purge_after": nil,
+			"updated_at":  gorm.Expr("CURRENT_TIMESTAMP"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListPurgeable returns the deleted tenants whose grace period ended by now
+func (r *TenantRepository) ListPurgeable(ctx context.Context, now time.Time) ([]domain.Tenant, error) {
+	var tenants []domain.Tenant
+	err := r.writerDB.WithContext(ctx).
+		Where("deleted_at IS NOT NULL AND purge_after <= ?", now).
+		Order("purge_after ASC").
+		Find(&tenants).Error
+	if err != nil {
 		return nil, err
 	}
 	return tenants, nil
 }
+
+// MarkPurgeStarted records that the tenant's data is being purged, after
+// which it can no longer be restored
+func (r *TenantRepository) MarkPurgeStarted(ctx context.Context, id string, startedAt time.Time) error {
+	return r.writerDB.WithContext(ctx).
+		Model(&domain.Tenant{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("purge_started_at", startedAt).Error
+}
+
+// Purge removes a deleted tenant's row, cascading to the rows that reference it
+func (r *TenantRepository) Purge(ctx context.Context, id string) error {
+	return r.writerDB.WithContext(ctx).Delete(&domain.Tenant{}, "id = ? AND deleted_at IS NOT NULL", id).Error
+}
@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"errors"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+)
+
+const (
+	tracingPluginName = "otel:tracing"
+	tracingSpanKey    = "otel:span"
+)
+
+// tracingPlugin wraps every GORM statement in a client span, so repository
+// calls show up as children of the service span that issued them
+type tracingPlugin struct{}
+
+func (tracingPlugin) Name() string {
+	return tracingPluginName
+}
+
+func (p tracingPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+
+	if err := cb.Create().Before("gorm:create").Register("otel:before_create", p.before("INSERT")); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("otel:after_create", p.after); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("otel:before_query", p.before("SELECT")); err != nil {
+		return err
+	}
+	if err := cb.Query().After("gorm:query").Register("otel:after_query", p.after); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("otel:before_update", p.before("UPDATE")); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("otel:after_update", p.after); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("otel:before_delete", p.before("DELETE")); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("otel:after_delete", p.after); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("otel:before_row", p.before("ROW")); err != nil {
+		return err
+	}
+	if err := cb.Row().After("gorm:row").Register("otel:after_row", p.after); err != nil {
+		return err
+	}
+	if err := cb.Raw().Before("gorm:raw").Register("otel:before_raw", p.before("RAW")); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("otel:after_raw", p.after)
+}
+
+func (tracingPlugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx, span := tracing.Start(db.Statement.Context, "postgres."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemPostgreSQL,
+				semconv.DBOperationName(operation),
+			),
+		)
+		db.Statement.Context = ctx
+		db.InstanceSet(tracingSpanKey, span)
+	}
+}
+
+func (tracingPlugin) after(db *gorm.DB) {
+	value, ok := db.InstanceGet(tracingSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+
+	// SQL holds placeholders only, so no audit log values leak into traces
+	span.SetAttributes(
+		semconv.DBCollectionName(db.Statement.Table),
+		semconv.DBQueryText(db.Statement.SQL.String()),
+	)
+
+	err := db.Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	tracing.End(span, err)
+}
+
+// instrument registers the tracing plugin on a connection. Connections are
+// shared between repositories, so an already registered plugin is not an error.
+func instrument(db *gorm.DB) {
+	if _, ok := db.Config.Plugins[tracingPluginName]; ok {
+		return
+	}
+	_ = db.Use(tracingPlugin{})
+}
@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+type UserRepository struct {
+	writerDB *gorm.DB
+	readerDB *gorm.DB
+}
+
+func NewUserRepository(writerDB, readerDB *gorm.DB) *UserRepository {
+	return &UserRepository{
+		writerDB: writerDB,
+		readerDB: readerDB,
+	}
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	return r.writerDB.WithContext(ctx).Create(user).Error
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, tenantID, id string) (*domain.User, error) {
+	var user domain.User
+	if err := r.readerDB.WithContext(ctx).First(&user, "id = ? AND tenant_id = ?", id, tenantID).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetByEmail looks up a user across all tenants, since emails are globally unique
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	var user domain.User
+	if err := r.readerDB.WithContext(ctx).First(&user, "email = ?", email).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) List(ctx context.Context, filter domain.UserFilter) ([]domain.User, error) {
+	var users []domain.User
+
+	db := r.readerDB.WithContext(ctx).Where("tenant_id = ?", filter.TenantID)
+	if filter.Email != "" {
+		db = db.Where("email ILIKE ?", "%"+filter.Email+"%")
+	}
+	if filter.Name != "" {
+		db = db.Where("name ILIKE ?", "%"+filter.Name+"%")
+	}
+	if len(filter.Roles) > 0 {
+		// Match users holding any of the requested roles
+		db = db.Where("roles && ?", domain.StringArray(filter.Roles))
+	}
+	if filter.Active != nil {
+		db = db.Where("active = ?", *filter.Active)
+	}
+
+	// Apply pagination
+	if filter.Limit > 0 {
+		db = db.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		db = db.Offset(filter.Offset)
+	}
+
+	if err := db.Order("created_at ASC").Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
+	return r.writerDB.WithContext(ctx).Save(user).Error
+}
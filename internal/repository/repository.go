@@ -2,20 +2,44 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/kingrain94/audit-log-api/internal/domain"
 )
 
+// ErrQueryTimeout is returned by queries cancelled for running past the
+// deadline of their context or the database's statement timeout
+var ErrQueryTimeout = errors.New("query timed out")
+
 //go:generate mockery --name AuditLogRepository --output ../mocks
 type AuditLogRepository interface {
 	Create(ctx context.Context, log *domain.AuditLog) error
 	GetByID(ctx context.Context, id string) (*domain.AuditLog, error)
+	// GetByIDs returns the logs with the given IDs, leaving out IDs that don't exist
+	GetByIDs(ctx context.Context, ids []string) ([]domain.AuditLog, error)
 	List(ctx context.Context, filter domain.AuditLogFilter) ([]domain.AuditLog, error)
 	DeleteBeforeDate(ctx context.Context, tenantID string, beforeDate time.Time) (int64, error)
 	BulkCreate(ctx context.Context, logs []domain.AuditLog) error
-	GetRecentLogs(ctx context.Context, tenantID string, since time.Time) ([]domain.AuditLog, error)
+	// Restore inserts logs with their original IDs, skipping logs that already exist, and returns the number inserted
+	Restore(ctx context.Context, logs []domain.AuditLog) (int64, error)
+	// GetRecentLogs returns up to limit logs stored at or after since, oldest first
+	GetRecentLogs(ctx context.Context, tenantID string, since time.Time, limit int) ([]domain.AuditLog, error)
+	// ListAfter returns up to limit logs stored after the log with afterID, oldest first
+	ListAfter(ctx context.Context, tenantID, afterID string, limit int) ([]domain.AuditLog, error)
+	// ListBatch returns up to limit logs matching filter after cursor, in (timestamp, id) order
+	ListBatch(ctx context.Context, filter domain.AuditLogFilter, cursor *domain.AuditLogCursor, limit int) ([]domain.AuditLog, error)
+	// ListStoredAfter returns up to limit logs matching filter stored after
+	// cursor and no later than until, in (created_at, id) order
+	ListStoredAfter(ctx context.Context, filter domain.AuditLogFilter, cursor *domain.AuditLogCursor, until time.Time, limit int) ([]domain.AuditLog, error)
 	GetStats(ctx context.Context, filter domain.AuditLogFilter) (*domain.AuditLogStats, error)
+	// GetActivity counts a tenant's logs in [start, end) from the logs table
+	GetActivity(ctx context.Context, tenantID string, start, end time.Time) (*domain.ActivityCounts, error)
+	// GetHourlyActivity counts a tenant's logs in [start, end) from the hourly stats aggregate
+	GetHourlyActivity(ctx context.Context, tenantID string, start, end time.Time) (*domain.ActivityCounts, error)
+	// ListNewIPAddresses returns the IP addresses users acted from in [since, until)
+	// that they never used in [baselineStart, since), for users active in the baseline
+	ListNewIPAddresses(ctx context.Context, tenantID string, baselineStart, since, until time.Time) ([]domain.UserIPAddress, error)
 }
 
 //go:generate mockery --name OpenSearchRepository --output ../mocks
@@ -23,8 +47,33 @@ type OpenSearchRepository interface {
 	Index(ctx context.Context, log *domain.AuditLog) error
 	BulkIndex(ctx context.Context, logs []domain.AuditLog) error
 	Search(ctx context.Context, filter *domain.AuditLogFilter) ([]domain.AuditLog, error)
+	// Scan calls fn with every log matching filter in batches of size,
+	// however many match
+	Scan(ctx context.Context, filter *domain.AuditLogFilter, size int, fn func([]domain.AuditLog) error) error
+	// GetByIDs returns the indexed logs with the given IDs, leaving out IDs that aren't indexed
+	GetByIDs(ctx context.Context, ids []string) ([]domain.AuditLog, error)
+	Stats(ctx context.Context, filter *domain.AuditLogFilter) (*domain.AuditLogStats, error)
+	// TopStats ranks the most frequent values of the logs matching filter,
+	// limit of each, and counts their distinct users and IP addresses
+	TopStats(ctx context.Context, filter *domain.AuditLogFilter, limit int) (*domain.TopStats, error)
 	CreateIndex(ctx context.Context, tenantID string, t time.Time) error
 	DeleteIndex(ctx context.Context, tenantID string) error
+	// ListIndices returns the tenant's daily indices, oldest first
+	ListIndices(ctx context.Context, tenantID string) ([]domain.SearchIndex, error)
+	// DeleteDailyIndex deletes the tenant's index for the UTC day of day
+	DeleteDailyIndex(ctx context.Context, tenantID string, day time.Time) error
+}
+
+// AnalyticsRepository is a columnar copy of the logs that aggregation queries
+// run against instead of the OLTP databases
+//
+//go:generate mockery --name AnalyticsRepository --output ../mocks
+type AnalyticsRepository interface {
+	// Insert stores logs; a log inserted twice is counted once
+	Insert(ctx context.Context, logs []domain.AuditLog) error
+	// Stats aggregates counts and a time series of the logs matching the
+	// filter, except for its full-text query
+	Stats(ctx context.Context, filter *domain.AuditLogFilter) (*domain.AuditLogStats, error)
 }
 
 //go:generate mockery --name TenantRepository --output ../mocks
@@ -32,14 +81,215 @@ type TenantRepository interface {
 	Create(ctx context.Context, tenant *domain.Tenant) (*domain.Tenant, error)
 	GetByID(ctx context.Context, id string) (*domain.Tenant, error)
 	Update(ctx context.Context, tenant *domain.Tenant) error
-	Delete(ctx context.Context, id string) error
+	// Delete marks the tenant deleted; it is purged once purgeAfter passes
+	Delete(ctx context.Context, id string, purgeAfter time.Time) error
 	List(ctx context.Context) ([]domain.Tenant, error)
+	GetDeleted(ctx context.Context, id string) (*domain.Tenant, error)
+	// Restore clears the deletion of a tenant whose purge hasn't started
+	Restore(ctx context.Context, id string) error
+	// ListPurgeable returns the deleted tenants whose grace period ended by now
+	ListPurgeable(ctx context.Context, now time.Time) ([]domain.Tenant, error)
+	MarkPurgeStarted(ctx context.Context, id string, startedAt time.Time) error
+	// Purge removes the tenant's row and, by cascade, every row referencing it
+	Purge(ctx context.Context, id string) error
+}
+
+//go:generate mockery --name UserRepository --output ../mocks
+type UserRepository interface {
+	Create(ctx context.Context, user *domain.User) error
+	GetByID(ctx context.Context, tenantID, id string) (*domain.User, error)
+	GetByEmail(ctx context.Context, email string) (*domain.User, error)
+	List(ctx context.Context, filter domain.UserFilter) ([]domain.User, error)
+	Update(ctx context.Context, user *domain.User) error
+}
+
+//go:generate mockery --name PolicyRepository --output ../mocks
+type PolicyRepository interface {
+	Create(ctx context.Context, policy *domain.Policy) error
+	GetByID(ctx context.Context, tenantID, id string) (*domain.Policy, error)
+	ListByTenant(ctx context.Context, tenantID string) ([]domain.Policy, error)
+	Update(ctx context.Context, policy *domain.Policy) error
+	Delete(ctx context.Context, tenantID, id string) error
+}
+
+//go:generate mockery --name RedactionRuleRepository --output ../mocks
+type RedactionRuleRepository interface {
+	Create(ctx context.Context, rule *domain.RedactionRule) error
+	ListByTenant(ctx context.Context, tenantID string) ([]domain.RedactionRule, error)
+	Delete(ctx context.Context, tenantID, id string) error
+}
+
+//go:generate mockery --name TaggingRuleRepository --output ../mocks
+type TaggingRuleRepository interface {
+	Create(ctx context.Context, rule *domain.TaggingRule) error
+	ListByTenant(ctx context.Context, tenantID string) ([]domain.TaggingRule, error)
+	Delete(ctx context.Context, tenantID, id string) error
+}
+
+//go:generate mockery --name ResourceSchemaRepository --output ../mocks
+type ResourceSchemaRepository interface {
+	Create(ctx context.Context, schema *domain.ResourceSchema) error
+	GetByID(ctx context.Context, tenantID, id string) (*domain.ResourceSchema, error)
+	ListByTenant(ctx context.Context, tenantID string) ([]domain.ResourceSchema, error)
+	Update(ctx context.Context, schema *domain.ResourceSchema) error
+	Delete(ctx context.Context, tenantID, id string) error
+}
+
+//go:generate mockery --name SavedSearchRepository --output ../mocks
+type SavedSearchRepository interface {
+	Create(ctx context.Context, search *domain.SavedSearch) error
+	GetByID(ctx context.Context, tenantID, id string) (*domain.SavedSearch, error)
+	// ListVisible returns the searches owned by userID and those shared within the tenant
+	ListVisible(ctx context.Context, tenantID, userID string) ([]domain.SavedSearch, error)
+	Update(ctx context.Context, search *domain.SavedSearch) error
+	Delete(ctx context.Context, tenantID, id string) error
+}
+
+//go:generate mockery --name OutboxRepository --output ../mocks
+type OutboxRepository interface {
+	Create(ctx context.Context, event *domain.OutboxEvent) error
+	ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxEvent, error)
+	MarkProcessed(ctx context.Context, ids []string) error
+	MarkFailed(ctx context.Context, id string, reason string) error
+	MarkDeadLettered(ctx context.Context, id string, reason string) error
+	DeleteProcessedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+//go:generate mockery --name ExportJobRepository --output ../mocks
+type ExportJobRepository interface {
+	Create(ctx context.Context, job *domain.ExportJob) error
+	GetByID(ctx context.Context, tenantID, id string) (*domain.ExportJob, error)
+	Update(ctx context.Context, job *domain.ExportJob) error
+}
+
+//go:generate mockery --name RestoreJobRepository --output ../mocks
+type RestoreJobRepository interface {
+	Create(ctx context.Context, job *domain.RestoreJob) error
+	GetByID(ctx context.Context, tenantID, id string) (*domain.RestoreJob, error)
+	Update(ctx context.Context, job *domain.RestoreJob) error
+}
+
+//go:generate mockery --name ImportJobRepository --output ../mocks
+type ImportJobRepository interface {
+	Create(ctx context.Context, job *domain.ImportJob) error
+	GetByID(ctx context.Context, tenantID, id string) (*domain.ImportJob, error)
+	Update(ctx context.Context, job *domain.ImportJob) error
+}
+
+//go:generate mockery --name CleanupJobRepository --output ../mocks
+type CleanupJobRepository interface {
+	Create(ctx context.Context, job *domain.CleanupJob) error
+	GetByID(ctx context.Context, tenantID, id string) (*domain.CleanupJob, error)
+	Update(ctx context.Context, job *domain.CleanupJob) error
+}
+
+//go:generate mockery --name ReindexJobRepository --output ../mocks
+type ReindexJobRepository interface {
+	Create(ctx context.Context, job *domain.ReindexJob) error
+	GetByID(ctx context.Context, tenantID, id string) (*domain.ReindexJob, error)
+	Update(ctx context.Context, job *domain.ReindexJob) error
+}
+
+// JobRepository reads the export, restore, cleanup and reindex jobs together
+//
+//go:generate mockery --name JobRepository --output ../mocks
+type JobRepository interface {
+	// List returns the jobs matching filter, most recent first
+	List(ctx context.Context, filter domain.JobFilter) ([]domain.Job, error)
+	GetByID(ctx context.Context, tenantID, id string) (*domain.Job, error)
+}
+
+// ArchiveScheduleRepository keeps the tenants' scheduled archival overrides and last runs
+//
+//go:generate mockery --name ArchiveScheduleRepository --output ../mocks
+type ArchiveScheduleRepository interface {
+	GetByTenant(ctx context.Context, tenantID string) (*domain.ArchiveSchedule, error)
+	List(ctx context.Context) ([]domain.ArchiveSchedule, error)
+	// Save stores the schedule's overrides, keeping the record of its last run
+	Save(ctx context.Context, schedule *domain.ArchiveSchedule) error
+	// Claim records a run at runAt unless the last run is no longer lastRunAt
+	Claim(ctx context.Context, tenantID string, lastRunAt *time.Time, runAt time.Time, jobID string) (bool, error)
+}
+
+// ArchiveRepository records the archives written to S3 with their checksums
+//
+//go:generate mockery --name ArchiveRepository --output ../mocks
+type ArchiveRepository interface {
+	// Save records an archive, replacing the record of an archive rewritten to the same manifest key
+	Save(ctx context.Context, archive *domain.Archive) error
+	// List returns the archives matching filter, most recent before date first
+	List(ctx context.Context, filter domain.ArchiveFilter) ([]domain.Archive, error)
+	GetByID(ctx context.Context, tenantID, id string) (*domain.Archive, error)
+	// ListSince returns the tenant's archives with a before date after since,
+	// the only ones that may hold logs from since on, most recent first
+	ListSince(ctx context.Context, tenantID string, since time.Time) ([]domain.Archive, error)
+	// UpdateVerification records the outcome of the archive's last verification
+	UpdateVerification(ctx context.Context, archive *domain.Archive) error
+}
+
+//go:generate mockery --name RetentionPolicyRepository --output ../mocks
+type RetentionPolicyRepository interface {
+	ListByTenant(ctx context.Context, tenantID string) ([]domain.RetentionPolicy, error)
+}
+
+// IndexFailureRepository keeps the logs OpenSearch rejected until they are reindexed
+//
+//go:generate mockery --name IndexFailureRepository --output ../mocks
+type IndexFailureRepository interface {
+	// Save stores failures, replacing any earlier failure of the same log
+	Save(ctx context.Context, failures []domain.IndexFailure) error
+	// ListByTenant returns up to limit of a tenant's failures, most recent first
+	ListByTenant(ctx context.Context, tenantID string, limit int) ([]domain.IndexFailure, error)
+	// GetByIDs returns the tenant's failures with the given IDs, leaving out IDs that don't exist
+	GetByIDs(ctx context.Context, tenantID string, ids []string) ([]domain.IndexFailure, error)
+	Delete(ctx context.Context, tenantID string, ids []string) error
+}
+
+// IntegrationRepository keeps the tenants' log forwarding integrations and
+// their forwarding progress
+//
+//go:generate mockery --name IntegrationRepository --output ../mocks
+type IntegrationRepository interface {
+	Create(ctx context.Context, integration *domain.Integration) error
+	GetByID(ctx context.Context, tenantID, id string) (*domain.Integration, error)
+	ListByTenant(ctx context.Context, tenantID string) ([]domain.Integration, error)
+	// Update stores the integration's settings, keeping its forwarding progress
+	Update(ctx context.Context, integration *domain.Integration) error
+	Delete(ctx context.Context, tenantID, id string) error
+	// ClaimDue locks up to limit enabled integrations due for forwarding for
+	// the lease duration, so concurrent workers never forward the same one
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.Integration, error)
+	// SaveProgress stores the integration's forwarding progress and releases it
+	SaveProgress(ctx context.Context, integration *domain.Integration) error
+	// Disable turns off an integration whose deliveries can't succeed, unless
+	// it was updated since it was claimed
+	Disable(ctx context.Context, integration *domain.Integration) error
 }
 
 //go:generate mockery --name PostgresRepository --output ../mocks
 type PostgresRepository interface {
 	AuditLog() AuditLogRepository
 	Tenant() TenantRepository
+	User() UserRepository
+	Policy() PolicyRepository
+	RedactionRule() RedactionRuleRepository
+	TaggingRule() TaggingRuleRepository
+	ResourceSchema() ResourceSchemaRepository
+	SavedSearch() SavedSearchRepository
+	Outbox() OutboxRepository
+	ExportJob() ExportJobRepository
+	RestoreJob() RestoreJobRepository
+	ImportJob() ImportJobRepository
+	CleanupJob() CleanupJobRepository
+	ReindexJob() ReindexJobRepository
+	Job() JobRepository
+	RetentionPolicy() RetentionPolicyRepository
+	IndexFailure() IndexFailureRepository
+	ArchiveSchedule() ArchiveScheduleRepository
+	Archive() ArchiveRepository
+	Integration() IntegrationRepository
+	// Transaction runs fn against repositories bound to a single writer transaction
+	Transaction(ctx context.Context, fn func(tx PostgresRepository) error) error
 }
 
 //go:generate mockery --name Repository --output ../mocks
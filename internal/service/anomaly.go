@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+)
+
+// AnomalyService compares a tenant's recent activity with its baseline from
+// the hourly stats aggregate and records deviations as CRITICAL audit logs
+type AnomalyService struct {
+	repo   repository.PostgresRepository
+	config *config.AnomalyConfig
+}
+
+func NewAnomalyService(repo repository.PostgresRepository, config *config.AnomalyConfig) *AnomalyService {
+	return &AnomalyService{
+		repo:   repo,
+		config: config,
+	}
+}
+
+// Detect checks the window ending at now against the baseline period before it
+func (s *AnomalyService) Detect(ctx context.Context, tenantID string, now time.Time) (_ []domain.Anomaly, err error) {
+	ctx, span := tracing.Start(ctx, "AnomalyService.Detect", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	windowStart := now.Add(-s.config.Window)
+	baselineStart := windowStart.Add(-s.config.BaselinePeriod)
+	newAnomaly := func(kind domain.AnomalyKind, observed, baseline, threshold float64) domain.Anomaly {
+		return domain.Anomaly{
+			TenantID:    tenantID,
+			Kind:        kind,
+			Observed:    observed,
+			Baseline:    baseline,
+			Threshold:   threshold,
+			WindowStart: windowStart,
+			WindowEnd:   now,
+		}
+	}
+
+	current, err := s.repo.AuditLog().GetActivity(ctx, tenantID, windowStart, now)
+	if err != nil {
+		return nil, err
+	}
+	baseline, err := s.repo.AuditLog().GetHourlyActivity(ctx, tenantID, baselineStart, windowStart)
+	if err != nil {
+		return nil, err
+	}
+
+	var anomalies []domain.Anomaly
+
+	// Tenants without history or with quiet windows have no meaningful rates to compare
+	if current.Total >= s.config.MinLogs && baseline.Total > 0 {
+		currentRate := float64(current.Total) / s.config.Window.Minutes()
+		baselineRate := float64(baseline.Total) / s.config.BaselinePeriod.Minutes()
+		if threshold := baselineRate * s.config.RateMultiplier; currentRate > threshold {
+			anomalies = append(anomalies, newAnomaly(domain.AnomalyLogRate, currentRate, baselineRate, threshold))
+		}
+
+		currentRatio, baselineRatio := current.FailureRatio(), baseline.FailureRatio()
+		if threshold := baselineRatio + s.config.FailureRatioDelta; currentRatio > threshold {
+			anomalies = append(anomalies, newAnomaly(domain.AnomalyFailureRatio, currentRatio, baselineRatio, threshold))
+		}
+	}
+
+	addresses, err := s.repo.AuditLog().ListNewIPAddresses(ctx, tenantID, baselineStart, windowStart, now)
+	if err != nil {
+		return nil, err
+	}
+	var userIDs []string
+	addressesByUser := make(map[string][]string)
+	for _, address := range addresses {
+		if _, ok := addressesByUser[address.UserID]; !ok {
+			userIDs = append(userIDs, address.UserID)
+		}
+		addressesByUser[address.UserID] = append(addressesByUser[address.UserID], address.IPAddress)
+	}
+	for _, userID := range userIDs {
+		ips := addressesByUser[userID]
+		if len(ips) < s.config.NewIPThreshold {
+			continue
+		}
+		anomaly := newAnomaly(domain.AnomalyNewIPAddress, float64(len(ips)), 0, float64(s.config.NewIPThreshold))
+		anomaly.UserID = userID
+		anomaly.IPAddresses = ips
+		anomalies = append(anomalies, anomaly)
+	}
+
+	span.SetAttributes(attribute.Int("anomaly.count", len(anomalies)))
+	return anomalies, nil
+}
+
+// Report stores anomalies as CRITICAL audit logs, indexed and broadcast through
+// the outbox like any other log
+func (s *AnomalyService) Report(ctx context.Context, anomalies []domain.Anomaly) (err error) {
+	if len(anomalies) == 0 {
+		return nil
+	}
+
+	ctx, span := tracing.Start(ctx, "AnomalyService.Report", trace.WithAttributes(
+		tracing.TenantAttr(anomalies[0].TenantID),
+		attribute.Int("anomaly.count", len(anomalies)),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	auditLogs := make([]domain.AuditLog, len(anomalies))
+	for i, anomaly := range anomalies {
+		log, err := newAnomalyLog(anomaly)
+		if err != nil {
+			return err
+		}
+		auditLogs[i] = *log
+	}
+
+	if err := storeIndexedLogs(ctx, s.repo, auditLogs); err != nil {
+		return fmt.Errorf("failed to store anomaly logs: %w", err)
+	}
+
+	for _, anomaly := range anomalies {
+		metrics.AnomaliesDetectedTotal.WithLabelValues(anomaly.TenantID, string(anomaly.Kind)).Inc()
+	}
+	return nil
+}
+
+// storeIndexedLogs stores logs the service generates itself, with the outbox
+// events indexing and broadcasting them, in one transaction
+func storeIndexedLogs(ctx context.Context, repo repository.PostgresRepository, logs []domain.AuditLog) error {
+	return repo.Transaction(ctx, func(tx repository.PostgresRepository) error {
+		if err := tx.AuditLog().BulkCreate(ctx, logs); err != nil {
+			return err
+		}
+
+		_, err := createBulkOutboxEvents(ctx, tx.Outbox(), logs)
+		return err
+	})
+}
+
+func newAnomalyLog(anomaly domain.Anomaly) (*domain.AuditLog, error) {
+	metadata, err := json.Marshal(map[string]any{
+		"kind":         anomaly.Kind,
+		"observed":     anomaly.Observed,
+		"baseline":     anomaly.Baseline,
+		"threshold":    anomaly.Threshold,
+		"window_start": anomaly.WindowStart,
+		"window_end":   anomaly.WindowEnd,
+		"ip_addresses": anomaly.IPAddresses,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal anomaly metadata: %w", err)
+	}
+
+	log := &domain.AuditLog{
+		TenantID:     anomaly.TenantID,
+		UserID:       anomaly.UserID,
+		Action:       string(domain.ActionAnomaly),
+		ResourceType: domain.AnomalyResourceType,
+		ResourceID:   string(anomaly.Kind),
+		Severity:     string(domain.SeverityCritical),
+		Metadata:     metadata,
+		Timestamp:    anomaly.WindowEnd,
+	}
+
+	switch anomaly.Kind {
+	case domain.AnomalyLogRate:
+		log.Message = fmt.Sprintf("Log rate of %.1f/min exceeds %.1f/min (baseline %.1f/min)",
+			anomaly.Observed, anomaly.Threshold, anomaly.Baseline)
+	case domain.AnomalyFailureRatio:
+		log.Message = fmt.Sprintf("Failed action ratio of %.0f%% exceeds %.0f%% (baseline %.0f%%)",
+			anomaly.Observed*100, anomaly.Threshold*100, anomaly.Baseline*100)
+	case domain.AnomalyNewIPAddress:
+		log.Message = fmt.Sprintf("User %s acted from %d new IP address(es): %s",
+			anomaly.UserID, len(anomaly.IPAddresses), strings.Join(anomaly.IPAddresses, ", "))
+		if len(anomaly.IPAddresses) > 0 {
+			log.IPAddress = anomaly.IPAddresses[0]
+		}
+	}
+
+	return log, nil
+}
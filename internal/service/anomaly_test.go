@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/mocks"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AnomalyServiceTestSuite struct {
+	suite.Suite
+	mockRepo     *mocks.PostgresRepository
+	mockAuditLog *mocks.AuditLogRepository
+	mockOutbox   *mocks.OutboxRepository
+	service      *AnomalyService
+	now          time.Time
+}
+
+func (s *AnomalyServiceTestSuite) SetupTest() {
+	s.mockRepo = new(mocks.PostgresRepository)
+	s.mockAuditLog = new(mocks.AuditLogRepository)
+	s.mockOutbox = new(mocks.OutboxRepository)
+
+	s.mockRepo.On("AuditLog").Return(s.mockAuditLog)
+	s.mockRepo.On("Outbox").Return(s.mockOutbox)
+	s.mockRepo.On("Transaction", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, fn func(tx repository.PostgresRepository) error) error {
+			return fn(s.mockRepo)
+		})
+
+	s.service = NewAnomalyService(s.mockRepo, &config.AnomalyConfig{
+		Window:            10 * time.Minute,
+		BaselinePeriod:    100 * time.Minute,
+		MinLogs:           100,
+		RateMultiplier:    3,
+		FailureRatioDelta: 0.2,
+		NewIPThreshold:    1,
+	})
+	s.now = time.Date(2025, 7, 17, 12, 0, 0, 0, time.UTC)
+}
+
+func TestAnomalyService(t *testing.T) {
+	suite.Run(t, new(AnomalyServiceTestSuite))
+}
+
+func (s *AnomalyServiceTestSuite) TestDetect_NormalActivity() {
+	// Arrange
+	ctx := context.Background()
+	s.mockAuditLog.On("GetActivity", mock.Anything, "tenant1", s.now.Add(-10*time.Minute), s.now).
+		Return(&domain.ActivityCounts{Total: 200, Failed: 20}, nil)
+	s.mockAuditLog.On("GetHourlyActivity", mock.Anything, "tenant1", s.now.Add(-110*time.Minute), s.now.Add(-10*time.Minute)).
+		Return(&domain.ActivityCounts{Total: 1000, Failed: 100}, nil)
+	s.mockAuditLog.On("ListNewIPAddresses", mock.Anything, "tenant1", mock.Anything, mock.Anything, mock.Anything).
+		Return([]domain.UserIPAddress{}, nil)
+
+	// Act
+	anomalies, err := s.service.Detect(ctx, "tenant1", s.now)
+
+	// Assert
+	s.NoError(err)
+	s.Empty(anomalies)
+}
+
+func (s *AnomalyServiceTestSuite) TestDetect_RateFailuresAndNewIPs() {
+	// Arrange
+	ctx := context.Background()
+	s.mockAuditLog.On("GetActivity", mock.Anything, "tenant1", mock.Anything, mock.Anything).
+		Return(&domain.ActivityCounts{Total: 500, Failed: 250}, nil)
+	s.mockAuditLog.On("GetHourlyActivity", mock.Anything, "tenant1", mock.Anything, mock.Anything).
+		Return(&domain.ActivityCounts{Total: 1000, Failed: 100}, nil)
+	s.mockAuditLog.On("ListNewIPAddresses", mock.Anything, "tenant1", mock.Anything, mock.Anything, mock.Anything).
+		Return([]domain.UserIPAddress{
+			{UserID: "user1", IPAddress: "203.0.113.7"},
+			{UserID: "user1", IPAddress: "203.0.113.8"},
+		}, nil)
+
+	// Act
+	anomalies, err := s.service.Detect(ctx, "tenant1", s.now)
+
+	// Assert
+	s.NoError(err)
+	s.Len(anomalies, 3)
+	s.Equal(domain.AnomalyLogRate, anomalies[0].Kind)
+	s.InDelta(50.0, anomalies[0].Observed, 0.001)
+	s.InDelta(30.0, anomalies[0].Threshold, 0.001)
+	s.Equal(domain.AnomalyFailureRatio, anomalies[1].Kind)
+	s.InDelta(0.5, anomalies[1].Observed, 0.001)
+	s.Equal(domain.AnomalyNewIPAddress, anomalies[2].Kind)
+	s.Equal("user1", anomalies[2].UserID)
+	s.Equal([]string{"203.0.113.7", "203.0.113.8"}, anomalies[2].IPAddresses)
+}
+
+func (s *AnomalyServiceTestSuite) TestDetect_SkipsQuietWindow() {
+	// Arrange
+	ctx := context.Background()
+	s.mockAuditLog.On("GetActivity", mock.Anything, "tenant1", mock.Anything, mock.Anything).
+		Return(&domain.ActivityCounts{Total: 50, Failed: 50}, nil)
+	s.mockAuditLog.On("GetHourlyActivity", mock.Anything, "tenant1", mock.Anything, mock.Anything).
+		Return(&domain.ActivityCounts{Total: 10, Failed: 0}, nil)
+	s.mockAuditLog.On("ListNewIPAddresses", mock.Anything, "tenant1", mock.Anything, mock.Anything, mock.Anything).
+		Return([]domain.UserIPAddress{}, nil)
+
+	// Act
+	anomalies, err := s.service.Detect(ctx, "tenant1", s.now)
+
+	// Assert
+	s.NoError(err)
+	s.Empty(anomalies)
+}
+
+func (s *AnomalyServiceTestSuite) TestReport_StoresCriticalLogs() {
+	// Arrange
+	ctx := context.Background()
+	anomalies := []domain.Anomaly{{
+		TenantID:    "tenant1",
+		Kind:        domain.AnomalyNewIPAddress,
+		UserID:      "user1",
+		IPAddresses: []string{"203.0.113.7"},
+		Observed:    1,
+		Threshold:   1,
+		WindowStart: s.now.Add(-10 * time.Minute),
+		WindowEnd:   s.now,
+	}}
+	s.mockAuditLog.On("BulkCreate", mock.Anything, mock.MatchedBy(func(logs []domain.AuditLog) bool {
+		return len(logs) == 1 &&
+			logs[0].Severity == string(domain.SeverityCritical) &&
+			logs[0].Action == string(domain.ActionAnomaly) &&
+			logs[0].UserID == "user1" &&
+			logs[0].IPAddress == "203.0.113.7"
+	})).Return(nil)
+	s.mockOutbox.On("Create", mock.Anything, mock.MatchedBy(func(event *domain.OutboxEvent) bool {
+		return event.TenantID == "tenant1" && event.EventType == domain.OutboxEventBulkIndex
+	})).Return(nil)
+
+	// Act
+	err := s.service.Report(ctx, anomalies)
+
+	// Assert
+	s.NoError(err)
+	s.mockAuditLog.AssertExpectations(s.T())
+	s.mockOutbox.AssertExpectations(s.T())
+}
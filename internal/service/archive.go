@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/service/storage"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+)
+
+// Statuses of the objects of a verified archive
+const (
+	archiveObjectOK          = "ok"
+	archiveObjectMismatch    = "mismatch"
+	archiveObjectMissing     = "missing"
+	archiveObjectUnavailable = "unavailable"
+)
+
+const (
+	defaultArchiveSearchLimit = 100
+	maxArchiveSearchLimit     = 1000
+	maxArchiveSearchRange     = 366 * 24 * time.Hour
+)
+
+// ArchiveReader streams and queries objects in the archive bucket. Both
+// return storage.ErrObjectNotFound for objects that don't exist and
+// storage.ErrObjectInGlacier for objects that have to be restored first.
+//
+//go:generate mockery --name ArchiveReader --output ../mocks
+type ArchiveReader interface {
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Select returns the logs an S3 Select SQL expression selects from an archive part
+	Select(ctx context.Context, key, expression string) ([]domain.AuditLog, error)
+}
+
+// ArchiveService lists a tenant's archives in S3, verifies their integrity
+// against the SHA-256 checksums recorded when they were written and searches
+// them in place
+type ArchiveService struct {
+	repo   repository.Repository
+	reader ArchiveReader
+}
+
+func NewArchiveService(repo repository.Repository, reader ArchiveReader) *ArchiveService {
+	return &ArchiveService{repo: repo, reader: reader}
+}
+
+// List returns a page of the tenant's archives, most recent before date first
+func (s *ArchiveService) List(ctx context.Context, filter *domain.ArchiveFilter) (_ []dto.ArchiveResponse, err error) {
+	ctx, span := tracing.Start(ctx, "ArchiveService.List", trace.WithAttributes(tracing.TenantAttr(filter.TenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	// Set default values for pagination
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = 10
+	}
+
+	// Convert page and page size to limit and offset
+	filter.Limit = filter.PageSize
+	filter.Offset = (filter.Page - 1) * filter.PageSize
+
+	archives, err := s.repo.Archive().List(ctx, *filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archives: %w", err)
+	}
+	return dto.FromArchives(archives), nil
+}
+
+// Verify re-hashes the archive's manifest and parts in S3 and compares them
+// with the checksums recorded when it was written, recording the outcome on
+// the archive. Objects in Glacier are reported unavailable; they have to be
+// restored before they can be verified.
+func (s *ArchiveService) Verify(ctx context.Context, tenantID, id string) (_ *dto.ArchiveVerificationResponse, err error) {
+	ctx, span := tracing.Start(ctx, "ArchiveService.Verify", trace.WithAttributes(tracing.TenantAttr(tenantID), attribute.String("archive.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	archive, err := s.repo.Archive().GetByID(ctx, tenantID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrArchiveNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get archive: %w", err)
+	}
+
+	objects := make([]dto.ArchiveObjectIntegrityResult, 0, len(archive.Parts)+1)
+	result, err := s.verifyObject(ctx, archive.ManifestKey, archive.ManifestSHA256)
+	if err != nil {
+		return nil, err
+	}
+	objects = append(objects, result)
+	for _, part := range archive.Parts {
+		result, err := s.verifyObject(ctx, part.Key, part.SHA256)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, result)
+	}
+
+	status := domain.ArchiveVerified
+	for _, object := range objects {
+		switch object.Status {
+		case archiveObjectMismatch, archiveObjectMissing:
+			status = domain.ArchiveCorrupted
+		case archiveObjectUnavailable:
+			if status == domain.ArchiveVerified {
+				status = domain.ArchiveUnavailable
+			}
+		}
+	}
+	metrics.ArchiveVerificationsTotal.WithLabelValues(string(status)).Inc()
+
+	verifiedAt := time.Now().UTC()
+	archive.VerificationStatus = status
+	archive.LastVerifiedAt = &verifiedAt
+	if err := s.repo.Archive().UpdateVerification(ctx, archive); err != nil {
+		return nil, fmt.Errorf("failed to record verification of archive %s: %w", archive.ID, err)
+	}
+
+	return &dto.ArchiveVerificationResponse{
+		ArchiveID:  archive.ID,
+		Status:     string(status),
+		VerifiedAt: verifiedAt,
+		Objects:    objects,
+	}, nil
+}
+
+// Search queries the tenant's archived logs in S3 with S3 Select, without
+// restoring them. Only the parts of recorded archives whose timestamps overlap
+// the range are queried, newest first, until the limit is reached; parts that
+// are missing or in Glacier are skipped and reported. Logs are returned newest
+// first.
+func (s *ArchiveService) Search(ctx context.Context, filter *domain.ArchiveSearchFilter) (_ *dto.ArchiveSearchResponse, err error) {
+	ctx, span := tracing.Start(ctx, "ArchiveService.Search", trace.WithAttributes(tracing.TenantAttr(filter.TenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	if !filter.EndTime.After(filter.StartTime) || filter.EndTime.Sub(filter.StartTime) > maxArchiveSearchRange {
+		return nil, ErrInvalidArchiveSearchRange
+	}
+	if filter.Limit < 1 {
+		filter.Limit = defaultArchiveSearchLimit
+	}
+	filter.Limit = min(filter.Limit, maxArchiveSearchLimit)
+
+	archives, err := s.repo.Archive().ListSince(ctx, filter.TenantID, filter.StartTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archives: %w", err)
+	}
+
+	resp := &dto.ArchiveSearchResponse{UnavailableParts: []string{}}
+	var logs []domain.AuditLog
+search:
+	for _, archive := range archives {
+		// Parts are written oldest first
+		for _, part := range slices.Backward(archive.Parts) {
+			if !part.Overlaps(filter.StartTime, filter.EndTime) {
+				continue
+			}
+			if len(logs) >= filter.Limit {
+				break search
+			}
+
+			selected, err := s.reader.Select(ctx, part.Key, archiveSelectExpression(filter, filter.Limit-len(logs)))
+			if errors.Is(err, storage.ErrObjectNotFound) || errors.Is(err, storage.ErrObjectInGlacier) {
+				resp.UnavailableParts = append(resp.UnavailableParts, part.Key)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			resp.PartsScanned++
+			logs = append(logs, selected...)
+		}
+	}
+
+	slices.SortStableFunc(logs, func(a, b domain.AuditLog) int {
+		return b.Timestamp.Compare(a.Timestamp)
+	})
+	resp.Logs = dto.FromAuditLogs(logs)
+	resp.Truncated = len(logs) >= filter.Limit
+	return resp, nil
+}
+
+// archiveSelectExpression builds the S3 Select SQL selecting up to limit of
+// the logs matching filter from an archive part
+func archiveSelectExpression(filter *domain.ArchiveSearchFilter, limit int) string {
+	conditions := []string{
+		fmt.Sprintf(`CAST(s."timestamp" AS TIMESTAMP) >= CAST('%s' AS TIMESTAMP)`, filter.StartTime.UTC().Format(time.RFC3339Nano)),
+		fmt.Sprintf(`CAST(s."timestamp" AS TIMESTAMP) <= CAST('%s' AS TIMESTAMP)`, filter.EndTime.UTC().Format(time.RFC3339Nano)),
+	}
+	for _, field := range []struct{ name, value string }{
+		{"user_id", filter.UserID},
+		{"action", filter.Action},
+		{"resource_type", filter.ResourceType},
+		{"resource_id", filter.ResourceID},
+		{"severity", filter.Severity},
+	} {
+		if field.value != "" {
+			conditions = append(conditions, fmt.Sprintf(`s."%s" = '%s'`, field.name, strings.ReplaceAll(field.value, "'", "''")))
+		}
+	}
+	return fmt.Sprintf("SELECT * FROM S3Object s WHERE %s LIMIT %d", strings.Join(conditions, " AND "), limit)
+}
+
+// verifyObject hashes the archive object at key and compares it with its
+// recorded checksum. Only failures to read an existing object are errors.
+func (s *ArchiveService) verifyObject(ctx context.Context, key, expected string) (dto.ArchiveObjectIntegrityResult, error) {
+	result := dto.ArchiveObjectIntegrityResult{Key: key, ExpectedSHA256: expected}
+
+	body, err := s.reader.Open(ctx, key)
+	switch {
+	case errors.Is(err, storage.ErrObjectNotFound):
+		result.Status = archiveObjectMissing
+		return result, nil
+	case errors.Is(err, storage.ErrObjectInGlacier):
+		result.Status = archiveObjectUnavailable
+		return result, nil
+	case err != nil:
+		return result, err
+	}
+	defer body.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, body); err != nil {
+		return result, fmt.Errorf("failed to read archive object %s: %w", key, err)
+	}
+
+	result.ActualSHA256 = hex.EncodeToString(hash.Sum(nil))
+	result.Status = archiveObjectOK
+	if result.ActualSHA256 != expected {
+		result.Status = archiveObjectMismatch
+	}
+	return result, nil
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/mocks"
+	"github.com/kingrain94/audit-log-api/internal/service/storage"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type ArchiveServiceTestSuite struct {
+	suite.Suite
+	mockRepo    *mocks.Repository
+	mockArchive *mocks.ArchiveRepository
+	mockReader  *mocks.ArchiveReader
+	service     *ArchiveService
+}
+
+func (s *ArchiveServiceTestSuite) SetupTest() {
+	s.mockRepo = new(mocks.Repository)
+	s.mockArchive = new(mocks.ArchiveRepository)
+	s.mockReader = new(mocks.ArchiveReader)
+
+	s.mockRepo.On("Archive").Return(s.mockArchive)
+
+	s.service = NewArchiveService(s.mockRepo, s.mockReader)
+}
+
+func TestArchiveService(t *testing.T) {
+	suite.Run(t, new(ArchiveServiceTestSuite))
+}
+
+func sha256Hex(content string) string {
+	digest := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(digest[:])
+}
+
+// testArchive returns an archive of a manifest and two parts whose recorded
+// checksums are those of their keys
+func testArchive() *domain.Archive {
+	return &domain.Archive{
+		ID:             "archive1",
+		TenantID:       "tenant1",
+		ManifestKey:    "manifest.json",
+		ManifestSHA256: sha256Hex("manifest.json"),
+		Parts: []domain.ArchivePart{
+			{Key: "part-00001.ndjson.gz", SHA256: sha256Hex("part-00001.ndjson.gz")},
+			{Key: "part-00002.ndjson.gz", SHA256: sha256Hex("part-00002.ndjson.gz")},
+		},
+	}
+}
+
+// serveObject makes the reader return the content for key
+func (s *ArchiveServiceTestSuite) serveObject(key, content string) {
+	s.mockReader.On("Open", mock.Anything, key).Return(io.NopCloser(strings.NewReader(content)), nil)
+}
+
+func (s *ArchiveServiceTestSuite) TestList_AppliesPaginationDefaults() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.ArchiveFilter{TenantID: "tenant1", Page: 2}
+
+	s.mockArchive.On("List", mock.Anything, mock.MatchedBy(func(f domain.ArchiveFilter) bool {
+		return f.TenantID == "tenant1" && f.Limit == 10 && f.Offset == 10
+	})).Return([]domain.Archive{*testArchive()}, nil)
+
+	// Act
+	resp, err := s.service.List(ctx, filter)
+
+	// Assert
+	s.NoError(err)
+	s.Len(resp, 1)
+	s.Len(resp[0].Parts, 2)
+	s.Equal(sha256Hex("manifest.json"), resp[0].ManifestSHA256)
+	s.mockArchive.AssertExpectations(s.T())
+}
+
+func (s *ArchiveServiceTestSuite) TestVerify_AllObjectsMatch() {
+	// Arrange
+	ctx := context.Background()
+	s.mockArchive.On("GetByID", mock.Anything, "tenant1", "archive1").Return(testArchive(), nil)
+	s.serveObject("manifest.json", "manifest.json")
+	s.serveObject("part-00001.ndjson.gz", "part-00001.ndjson.gz")
+	s.serveObject("part-00002.ndjson.gz", "part-00002.ndjson.gz")
+	s.mockArchive.On("UpdateVerification", mock.Anything, mock.MatchedBy(func(a *domain.Archive) bool {
+		return a.VerificationStatus == domain.ArchiveVerified && a.LastVerifiedAt != nil
+	})).Return(nil)
+
+	// Act
+	resp, err := s.service.Verify(ctx, "tenant1", "archive1")
+
+	// Assert
+	s.NoError(err)
+	s.Equal("verified", resp.Status)
+	s.Len(resp.Objects, 3)
+	for _, object := range resp.Objects {
+		s.Equal("ok", object.Status)
+		s.Equal(object.ExpectedSHA256, object.ActualSHA256)
+	}
+	s.mockArchive.AssertExpectations(s.T())
+}
+
+func (s *ArchiveServiceTestSuite) TestVerify_AlteredAndMissingObjectsAreCorrupted() {
+	// Arrange
+	ctx := context.Background()
+	s.mockArchive.On("GetByID", mock.Anything, "tenant1", "archive1").Return(testArchive(), nil)
+	s.serveObject("manifest.json", "manifest.json")
+	s.serveObject("part-00001.ndjson.gz", "tampered")
+	s.mockReader.On("Open", mock.Anything, "part-00002.ndjson.gz").
+		Return(nil, fmt.Errorf("%w: part-00002.ndjson.gz", storage.ErrObjectNotFound))
+	s.mockArchive.On("UpdateVerification", mock.Anything, mock.MatchedBy(func(a *domain.Archive) bool {
+		return a.VerificationStatus == domain.ArchiveCorrupted
+	})).Return(nil)
+
+	// Act
+	resp, err := s.service.Verify(ctx, "tenant1", "archive1")
+
+	// Assert
+	s.NoError(err)
+	s.Equal("corrupted", resp.Status)
+	s.Equal("ok", resp.Objects[0].Status)
+	s.Equal("mismatch", resp.Objects[1].Status)
+	s.Equal(sha256Hex("tampered"), resp.Objects[1].ActualSHA256)
+	s.Equal("missing", resp.Objects[2].Status)
+	s.Empty(resp.Objects[2].ActualSHA256)
+}
+
+func (s *ArchiveServiceTestSuite) TestVerify_GlacierPartsAreUnavailable() {
+	// Arrange
+	ctx := context.Background()
+	s.mockArchive.On("GetByID", mock.Anything, "tenant1", "archive1").Return(testArchive(), nil)
+	s.serveObject("manifest.json", "manifest.json")
+	s.mockReader.On("Open", mock.Anything, mock.MatchedBy(func(key string) bool {
+		return strings.HasPrefix(key, "part-")
+	})).Return(nil, storage.ErrObjectInGlacier)
+	s.mockArchive.On("UpdateVerification", mock.Anything, mock.Anything).Return(nil)
+
+	// Act
+	resp, err := s.service.Verify(ctx, "tenant1", "archive1")
+
+	// Assert
+	s.NoError(err)
+	s.Equal("unavailable", resp.Status)
+	s.Equal("unavailable", resp.Objects[1].Status)
+	s.Equal("unavailable", resp.Objects[2].Status)
+}
+
+func (s *ArchiveServiceTestSuite) TestVerify_ReadErrorFailsWithoutRecording() {
+	// Arrange
+	ctx := context.Background()
+	s.mockArchive.On("GetByID", mock.Anything, "tenant1", "archive1").Return(testArchive(), nil)
+	s.mockReader.On("Open", mock.Anything, "manifest.json").Return(nil, errors.New("connection reset"))
+
+	// Act
+	resp, err := s.service.Verify(ctx, "tenant1", "archive1")
+
+	// Assert
+	s.Error(err)
+	s.Nil(resp)
+	s.mockArchive.AssertNotCalled(s.T(), "UpdateVerification", mock.Anything, mock.Anything)
+}
+
+func (s *ArchiveServiceTestSuite) TestVerify_NotFound() {
+	// Arrange
+	ctx := context.Background()
+	s.mockArchive.On("GetByID", mock.Anything, "tenant1", "missing").Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	resp, err := s.service.Verify(ctx, "tenant1", "missing")
+
+	// Assert
+	s.ErrorIs(err, ErrArchiveNotFound)
+	s.Nil(resp)
+	s.mockReader.AssertNotCalled(s.T(), "Open", mock.Anything, mock.Anything)
+}
+
+func (s *ArchiveServiceTestSuite) TestSearch_QueriesOverlappingPartsNewestFirst() {
+	// Arrange
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+	filter := &domain.ArchiveSearchFilter{TenantID: "tenant1", StartTime: day(10), EndTime: day(20), Action: "O'Brien"}
+
+	s.mockArchive.On("ListSince", mock.Anything, "tenant1", day(10)).Return([]domain.Archive{{
+		Parts: []domain.ArchivePart{
+			{Key: "old", FirstTimestamp: day(1), LastTimestamp: day(5)},
+			{Key: "first", FirstTimestamp: day(6), LastTimestamp: day(12)},
+			{Key: "second", FirstTimestamp: day(12), LastTimestamp: day(25)},
+		},
+	}}, nil)
+	var queried, expressions []string
+	s.mockReader.On("Select", mock.Anything, mock.Anything, mock.MatchedBy(func(expression string) bool {
+		return strings.Contains(expression, `s."action" = 'O''Brien'`)
+	})).Run(func(args mock.Arguments) {
+		queried = append(queried, args.String(1))
+		expressions = append(expressions, args.String(2))
+	}).Return(func(_ context.Context, key, _ string) []domain.AuditLog {
+		if key == "first" {
+			return []domain.AuditLog{{ID: "log1", Timestamp: day(11)}}
+		}
+		return []domain.AuditLog{{ID: "log2", Timestamp: day(15)}}
+	}, nil)
+
+	// Act
+	resp, err := s.service.Search(ctx, filter)
+
+	// Assert
+	s.NoError(err)
+	s.Equal([]string{"second", "first"}, queried)
+	s.True(strings.HasSuffix(expressions[0], "LIMIT 100"))
+	s.True(strings.HasSuffix(expressions[1], "LIMIT 99"))
+	s.Equal(2, resp.PartsScanned)
+	s.Equal("log2", resp.Logs[0].ID)
+	s.Equal("log1", resp.Logs[1].ID)
+	s.False(resp.Truncated)
+}
+
+func (s *ArchiveServiceTestSuite) TestSearch_SkipsGlacierPartsAndStopsAtLimit() {
+	// Arrange
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+	filter := &domain.ArchiveSearchFilter{TenantID: "tenant1", StartTime: day(1), EndTime: day(31), Limit: 1}
+
+	s.mockArchive.On("ListSince", mock.Anything, "tenant1", day(1)).Return([]domain.Archive{{
+		Parts: []domain.ArchivePart{
+			{Key: "oldest", FirstTimestamp: day(1), LastTimestamp: day(9)},
+			{Key: "older", FirstTimestamp: day(10), LastTimestamp: day(19)},
+			{Key: "glacier", FirstTimestamp: day(20), LastTimestamp: day(29)},
+		},
+	}}, nil)
+	s.mockReader.On("Select", mock.Anything, "glacier", mock.Anything).Return(nil, storage.ErrObjectInGlacier)
+	s.mockReader.On("Select", mock.Anything, "older", mock.Anything).Return([]domain.AuditLog{{ID: "log1"}}, nil)
+
+	// Act
+	resp, err := s.service.Search(ctx, filter)
+
+	// Assert
+	s.NoError(err)
+	s.Equal([]string{"glacier"}, resp.UnavailableParts)
+	s.Len(resp.Logs, 1)
+	s.True(resp.Truncated)
+	s.mockReader.AssertNotCalled(s.T(), "Select", mock.Anything, "oldest", mock.Anything)
+}
+
+func (s *ArchiveServiceTestSuite) TestSearch_RejectsLongRange() {
+	// Arrange
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	filter := &domain.ArchiveSearchFilter{TenantID: "tenant1", StartTime: start, EndTime: start.AddDate(2, 0, 0)}
+
+	// Act
+	resp, err := s.service.Search(ctx, filter)
+
+	// Assert
+	s.ErrorIs(err, ErrInvalidArchiveSearchRange)
+	s.Nil(resp)
+	s.mockArchive.AssertNotCalled(s.T(), "ListSince", mock.Anything, mock.Anything, mock.Anything)
+}
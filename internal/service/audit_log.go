@@ -2,101 +2,674 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand/v2"
 	"time"
 
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
 	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/config"
 	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
 	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+	"github.com/kingrain94/audit-log-api/internal/utils"
+	"github.com/kingrain94/audit-log-api/pkg/jsondiff"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
 )
 
-//go:generate mockery --name WebSocketBroadcaster --output ../mocks
-type WebSocketBroadcaster interface {
-	BroadcastLog(log *dto.AuditLogResponse)
-}
-
-//go:generate mockery --name SQSService --output ../mocks
-type SQSService interface {
+// MessagePublisher enqueues pipeline messages on the configured queue backend
+//
+//go:generate mockery --name MessagePublisher --output ../mocks
+type MessagePublisher interface {
 	SendIndexMessage(ctx context.Context, log *domain.AuditLog) error
 	SendBulkIndexMessage(ctx context.Context, logs []domain.AuditLog) error
-	SendArchiveMessage(ctx context.Context, tenantID string, beforeDate time.Time) error
-	SendCleanupMessage(ctx context.Context, tenantID string, beforeDate time.Time) error
+	SendArchiveMessage(ctx context.Context, tenantID, jobID string, beforeDate time.Time) error
+	SendCleanupMessage(ctx context.Context, tenantID, jobID string, beforeDate time.Time) error
+	SendExportMessage(ctx context.Context, tenantID, jobID string) error
+	SendRestoreMessage(ctx context.Context, tenantID, jobID string) error
+	SendImportMessage(ctx context.Context, tenantID, jobID string) error
+	SendIngestMessage(ctx context.Context, logs []domain.AuditLog) error
+}
+
+//go:generate mockery --name ExportURLSigner --output ../mocks
+type ExportURLSigner interface {
+	PresignGet(ctx context.Context, key string) (string, error)
+}
+
+//go:generate mockery --name LogRedactor --output ../mocks
+type LogRedactor interface {
+	Redact(ctx context.Context, logs []domain.AuditLog) error
+}
+
+// LogTagger labels logs per their tenants' tagging rules
+//
+//go:generate mockery --name LogTagger --output ../mocks
+type LogTagger interface {
+	Tag(ctx context.Context, logs []domain.AuditLog) error
+}
+
+// LogEnricher adds what is known of logs' sources to them, such as the threat
+// score of their IP address
+//
+//go:generate mockery --name LogEnricher --output ../mocks
+type LogEnricher interface {
+	Enrich(ctx context.Context, logs []domain.AuditLog)
+}
+
+// LogSchemaValidator checks logs against the JSON Schemas of their resource type
+//
+//go:generate mockery --name LogSchemaValidator --output ../mocks
+type LogSchemaValidator interface {
+	Validate(ctx context.Context, logs []domain.AuditLog) error
+}
+
+// UsageTracker meters ingestion against the tenants' quotas
+//
+//go:generate mockery --name UsageTracker --output ../mocks
+type UsageTracker interface {
+	CheckQuota(ctx context.Context, tenantID string, logs int64) error
+	Record(ctx context.Context, tenantID string, logs, bytes int64) error
+}
+
+// LogReadCache keeps the logs and stats read by polling dashboards for a short
+// time. Stats are keyed by filter and the tenant's ingest watermark.
+//
+//go:generate mockery --name LogReadCache --output ../mocks
+type LogReadCache interface {
+	GetLog(ctx context.Context, tenantID, id string) (*domain.AuditLog, error)
+	SetLog(ctx context.Context, log *domain.AuditLog) error
+	StatsKey(filter *domain.AuditLogFilter, watermark int64) string
+	GetStats(ctx context.Context, key string) (*domain.AuditLogStats, error)
+	SetStats(ctx context.Context, key string, stats *domain.AuditLogStats) error
+}
+
+// IngestWatermark tracks per tenant when logs were last stored, so responses
+// derived from them can be tagged and cached until the next store
+//
+//go:generate mockery --name IngestWatermark --output ../mocks
+type IngestWatermark interface {
+	Get(ctx context.Context, tenantID string) (int64, error)
+	Advance(ctx context.Context, tenantIDs ...string) error
 }
 
+// streamReplayLimit caps how many missed logs are replayed to a resuming stream client
+const streamReplayLimit = 1000
+
+// correlationLogLimit caps how many logs of one request chain are returned
+const correlationLogLimit = 1000
+
+// etagWindow bounds how long an ETag outlives the watermark it was derived
+// from. Logs reach OpenSearch and the analytics store only after they are
+// stored, so a response computed meanwhile could otherwise be served as
+// unchanged until the tenant's next store.
+const etagWindow = time.Minute
+
+// maxIngestMessageSize keeps ingest messages below the 256KB SQS message limit
+const maxIngestMessageSize = 240 * 1024
+
 type AuditLogService struct {
-	repo        repository.Repository
-	sqsSvc      SQSService
-	broadcaster WebSocketBroadcaster
+	repo      repository.Repository
+	publisher MessagePublisher
+	urlSigner ExportURLSigner
+	redactor  LogRedactor
+	tagger    LogTagger
+	enricher  LogEnricher
+	schemas   LogSchemaValidator
+	usage     UsageTracker
+	buffer    *ingestBuffer
+	analytics repository.AnalyticsRepository
+	readCache LogReadCache
+	watermark IngestWatermark
+	signer    *ExportSigner
+	now       func() time.Time
+
+	samplingSettings TenantSettingsResolver
+	sampled          SampledLogCounter
+	// random returns a number in [0, 1) that logs are sampled by
+	random func() float64
 }
 
-func NewAuditLogService(repo repository.Repository, sqsSvc SQSService) *AuditLogService {
+func NewAuditLogService(repo repository.Repository, publisher MessagePublisher, urlSigner ExportURLSigner, redactor LogRedactor, schemas LogSchemaValidator, usage UsageTracker) *AuditLogService {
 	return &AuditLogService{
-		repo:   repo,
-		sqsSvc: sqsSvc,
+		repo:      repo,
+		publisher: publisher,
+		urlSigner: urlSigner,
+		redactor:  redactor,
+		schemas:   schemas,
+		usage:     usage,
+		now:       time.Now,
+		random:    rand.Float64,
+	}
+}
+
+// StartIngestBuffer makes Create buffer logs and store them per tenant in
+// batches of cfg.BatchSize, or after cfg.FlushInterval, instead of one by one
+func (s *AuditLogService) StartIngestBuffer(cfg *config.IngestBufferConfig, logger *logger.Logger) {
+	s.buffer = newIngestBuffer(s.storeBatch, cfg, logger)
+	s.buffer.start()
+}
+
+// StopIngestBuffer stores the buffered logs; logs created afterwards are
+// stored right away
+func (s *AuditLogService) StopIngestBuffer() {
+	if s.buffer != nil {
+		s.buffer.stop()
 	}
 }
 
-// SetWebSocketBroadcaster sets the WebSocket broadcaster
-func (s *AuditLogService) SetWebSocketBroadcaster(broadcaster WebSocketBroadcaster) {
-	s.broadcaster = broadcaster
+// UseAnalytics makes GetStatsV2 aggregate in the analytics store, which the
+// index worker copies logs to, rather than in OpenSearch or PostgreSQL
+func (s *AuditLogService) UseAnalytics(analytics repository.AnalyticsRepository) {
+	s.analytics = analytics
 }
 
-func (s *AuditLogService) Create(ctx context.Context, req dto.CreateAuditLogRequest) error {
-	auditLog := req.ToAuditLog()
+// UseReadCache makes GetByID read through the cache, and GetStatsV2 as well
+// when an ingest watermark is used
+func (s *AuditLogService) UseReadCache(cache LogReadCache) {
+	s.readCache = cache
+}
+
+// UseWatermark makes storing logs advance their tenants' watermarks, which
+// ETag and the cached stats are derived from
+func (s *AuditLogService) UseWatermark(watermark IngestWatermark) {
+	s.watermark = watermark
+}
+
+// UseTagger makes ingestion tag logs per their tenants' tagging rules before
+// they are redacted
+func (s *AuditLogService) UseTagger(tagger LogTagger) {
+	s.tagger = tagger
+}
+
+// UseExportSigner lets export jobs be signed, and their signing keys be
+// published, with the signer's keys
+func (s *AuditLogService) UseExportSigner(signer *ExportSigner) {
+	s.signer = signer
+}
 
-	// Store in PostgreSQL
-	if err := s.repo.AuditLog().Create(ctx, auditLog); err != nil {
-		return fmt.Errorf("failed to store log in PostgreSQL: %w", err)
+// UseEnricher makes ingestion enrich logs before they are sampled, so the
+// severity enrichment raises decides their sample rate
+func (s *AuditLogService) UseEnricher(enricher LogEnricher) {
+	s.enricher = enricher
+}
+
+// enrich enriches logs when an enricher is used
+func (s *AuditLogService) enrich(ctx context.Context, logs []domain.AuditLog) {
+	if s.enricher != nil {
+		s.enricher.Enrich(ctx, logs)
+	}
+}
+
+// tag tags logs when a tagger is used
+func (s *AuditLogService) tag(ctx context.Context, logs []domain.AuditLog) error {
+	if s.tagger == nil {
+		return nil
 	}
+	if err := s.tagger.Tag(ctx, logs); err != nil {
+		return fmt.Errorf("failed to tag logs: %w", err)
+	}
+	return nil
+}
+
+// Create checks the log against its resource schema, enriches, tags and
+// redacts it, and stores it together with an outbox event in a single
+// transaction. Indexing and broadcasting are performed by the outbox relay,
+// so a crash after commit can no longer lose the index message. While the
+// ingest buffer runs, the log is acknowledged once buffered and stored with
+// its batch. A log dropped by the tenant's sampling rules is acknowledged
+// without being stored.
+func (s *AuditLogService) Create(ctx context.Context, req dto.CreateAuditLogRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "AuditLogService.Create", trace.WithAttributes(tracing.TenantAttr(req.TenantID)))
+	defer func() { tracing.End(span, err) }()
 
-	// Send message to SQS for asynchronous indexing
-	if err := s.sqsSvc.SendIndexMessage(ctx, auditLog); err != nil {
-		fmt.Printf("failed to send index message to SQS: %v\n", err)
+	if err := s.usage.CheckQuota(ctx, req.TenantID, 1); err != nil {
+		return err
 	}
 
-	// Broadcast to WebSocket clients if broadcaster is available
-	if s.broadcaster != nil {
-		s.broadcaster.BroadcastLog(dto.FromAuditLog(auditLog))
+	auditLogs := []domain.AuditLog{*req.ToAuditLog()}
+	// Schemas see the values as sent, before redaction masks them
+	if err := s.schemas.Validate(ctx, auditLogs); err != nil {
+		return err
 	}
+	s.enrich(ctx, auditLogs)
+	if auditLogs = s.sample(ctx, auditLogs); len(auditLogs) == 0 {
+		return nil
+	}
+	if err := s.tag(ctx, auditLogs); err != nil {
+		return err
+	}
+	if err := s.redactor.Redact(ctx, auditLogs); err != nil {
+		return fmt.Errorf("failed to redact log: %w", err)
+	}
+	if s.buffer != nil {
+		if buffered, err := s.buffer.add(ctx, auditLogs[0]); buffered {
+			return err
+		}
+	}
+	auditLog := &auditLogs[0]
 
+	var payloadSize int
+	err = s.repo.Transaction(ctx, func(tx repository.PostgresRepository) error {
+		// Store in PostgreSQL
+		if err := tx.AuditLog().Create(ctx, auditLog); err != nil {
+			return fmt.Errorf("failed to store log in PostgreSQL: %w", err)
+		}
+
+		// Record the index/broadcast side effect in the outbox
+		event, err := newOutboxEvent(ctx, domain.OutboxEventIndex, []domain.AuditLog{*auditLog})
+		if err != nil {
+			return err
+		}
+		payloadSize = len(event.Payload)
+		if err := tx.Outbox().Create(ctx, event); err != nil {
+			return fmt.Errorf("failed to store outbox event: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	metrics.LogsIngestedTotal.WithLabelValues(auditLog.TenantID).Inc()
+	// Usage is metered on a best-effort basis once the log is stored
+	_ = s.usage.Record(ctx, auditLog.TenantID, 1, int64(payloadSize))
+	s.advanceWatermark(ctx, auditLog.TenantID)
 	return nil
 }
 
-func (s *AuditLogService) BulkCreate(ctx context.Context, req []dto.CreateAuditLogRequest) error {
+func (s *AuditLogService) BulkCreate(ctx context.Context, req []dto.CreateAuditLogRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "AuditLogService.BulkCreate", trace.WithAttributes(attribute.Int("audit_log.count", len(req))))
+	defer func() { tracing.End(span, err) }()
+
 	auditLogs := make([]domain.AuditLog, len(req))
 	for i := range req {
 		auditLogs[i] = *req[i].ToAuditLog()
 	}
-
-	// Store in PostgreSQL
-	if err := s.repo.AuditLog().BulkCreate(ctx, auditLogs); err != nil {
-		return fmt.Errorf("failed to bulk store logs in PostgreSQL: %w", err)
+	for tenantID, count := range tenantLogCounts(auditLogs) {
+		if err := s.usage.CheckQuota(ctx, tenantID, count); err != nil {
+			return err
+		}
 	}
 
-	// Send message to SQS for asynchronous bulk indexing
-	if err := s.sqsSvc.SendBulkIndexMessage(ctx, auditLogs); err != nil {
-		fmt.Printf("failed to send bulk index message to SQS: %v\n", err)
+	if err := s.schemas.Validate(ctx, auditLogs); err != nil {
+		var violations *SchemaViolationError
+		if errors.As(err, &violations) {
+			violations.Batch = true
+		}
+		return err
 	}
+	s.enrich(ctx, auditLogs)
+	if auditLogs = s.sample(ctx, auditLogs); len(auditLogs) == 0 {
+		return nil
+	}
+	if err := s.tag(ctx, auditLogs); err != nil {
+		return err
+	}
+	if err := s.redactor.Redact(ctx, auditLogs); err != nil {
+		return fmt.Errorf("failed to redact logs: %w", err)
+	}
+
+	return s.storeBatch(ctx, auditLogs)
+}
 
-	// Broadcast each log to WebSocket clients if broadcaster is available
-	if s.broadcaster != nil {
-		for _, log := range auditLogs {
-			s.broadcaster.BroadcastLog(dto.FromAuditLog(&log))
+// storeBatch stores logs together with their bulk outbox events in a single
+// transaction and meters them
+func (s *AuditLogService) storeBatch(ctx context.Context, auditLogs []domain.AuditLog) error {
+	var payloadSize int
+	err := s.repo.Transaction(ctx, func(tx repository.PostgresRepository) error {
+		// Store in PostgreSQL
+		if err := tx.AuditLog().BulkCreate(ctx, auditLogs); err != nil {
+			return fmt.Errorf("failed to bulk store logs in PostgreSQL: %w", err)
 		}
+
+		// Record the bulk index/broadcast side effect in the outbox
+		var err error
+		payloadSize, err = createBulkOutboxEvents(ctx, tx.Outbox(), auditLogs)
+		return err
+	})
+	if err != nil {
+		return err
 	}
 
+	if len(auditLogs) > 0 {
+		metrics.LogsIngestedTotal.WithLabelValues(auditLogs[0].TenantID).Add(float64(len(auditLogs)))
+	}
+	// The payload size is shared out by log count among the batch's tenants
+	counts := tenantLogCounts(auditLogs)
+	tenantIDs := make([]string, 0, len(counts))
+	for tenantID, count := range counts {
+		_ = s.usage.Record(ctx, tenantID, count, int64(payloadSize)*count/int64(len(auditLogs)))
+		tenantIDs = append(tenantIDs, tenantID)
+	}
+	s.advanceWatermark(ctx, tenantIDs...)
 	return nil
 }
 
-func (s *AuditLogService) GetByID(ctx context.Context, id string) (*dto.AuditLogResponse, error) {
+// advanceWatermark moves the watermarks of tenants whose logs were stored, on
+// a best-effort basis; ETags and cached stats expire shortly anyway
+func (s *AuditLogService) advanceWatermark(ctx context.Context, tenantIDs ...string) {
+	if s.watermark != nil && len(tenantIDs) > 0 {
+		_ = s.watermark.Advance(ctx, tenantIDs...)
+	}
+}
+
+// ETag returns a weak entity tag of the named response (such as "logs" or
+// "stats") to filter, derived from the filter and the tenant's ingest
+// watermark. It changes when the tenant's logs are stored and at the latest
+// after etagWindow. It is "" when no watermark is used or it can't be read.
+func (s *AuditLogService) ETag(ctx context.Context, name string, filter *domain.AuditLogFilter) string {
+	if s.watermark == nil {
+		return ""
+	}
+	watermark, err := s.watermark.Get(ctx, filter.TenantID)
+	if err != nil {
+		return ""
+	}
+
+	data, err := json.Marshal(filter)
+	if err != nil {
+		return ""
+	}
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s:%s:%d:%d:", name, filter.TenantID, watermark, s.now().Truncate(etagWindow).Unix())
+	hash.Write(data)
+
+	return `W/"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
+
+// CreateAsync runs the checks of Create and enqueues the log for the ingest
+// worker to store instead of storing it, returning the ID it will be stored with
+func (s *AuditLogService) CreateAsync(ctx context.Context, req dto.CreateAuditLogRequest) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "AuditLogService.CreateAsync", trace.WithAttributes(tracing.TenantAttr(req.TenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	ids, err := s.accept(ctx, []dto.CreateAuditLogRequest{req}, false)
+	if err != nil {
+		return "", err
+	}
+	return ids[0], nil
+}
+
+// BulkCreateAsync runs the checks of BulkCreate and enqueues the logs for the
+// ingest worker to store instead of storing them, returning their IDs in order
+func (s *AuditLogService) BulkCreateAsync(ctx context.Context, req []dto.CreateAuditLogRequest) (_ []string, err error) {
+	ctx, span := tracing.Start(ctx, "AuditLogService.BulkCreateAsync", trace.WithAttributes(attribute.Int("audit_log.count", len(req))))
+	defer func() { tracing.End(span, err) }()
+
+	return s.accept(ctx, req, true)
+}
+
+// accept checks, samples, tags, redacts and enqueues logs. IDs are assigned here, so they can
+// be returned before the logs are stored and a redelivered message is only
+// stored once. If enqueueing fails part way, the chunks already enqueued are
+// still stored.
+func (s *AuditLogService) accept(ctx context.Context, req []dto.CreateAuditLogRequest, batch bool) ([]string, error) {
+	auditLogs := make([]domain.AuditLog, len(req))
+	ids := make([]string, len(req))
+	for i := range req {
+		auditLogs[i] = *req[i].ToAuditLog()
+		auditLogs[i].ID = uuid.New().String()
+		ids[i] = auditLogs[i].ID
+	}
+	counts := tenantLogCounts(auditLogs)
+	for tenantID, count := range counts {
+		if err := s.usage.CheckQuota(ctx, tenantID, count); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.schemas.Validate(ctx, auditLogs); err != nil {
+		var violations *SchemaViolationError
+		if errors.As(err, &violations) {
+			violations.Batch = batch
+		}
+		return nil, err
+	}
+	s.enrich(ctx, auditLogs)
+	// Dropped logs keep their IDs in the response but are never stored
+	if auditLogs = s.sample(ctx, auditLogs); len(auditLogs) == 0 {
+		return ids, nil
+	}
+	counts = tenantLogCounts(auditLogs)
+	if err := s.tag(ctx, auditLogs); err != nil {
+		return nil, err
+	}
+	if err := s.redactor.Redact(ctx, auditLogs); err != nil {
+		return nil, fmt.Errorf("failed to redact logs: %w", err)
+	}
+
+	chunks, payloadSize, err := IngestChunks(auditLogs)
+	if err != nil {
+		return nil, err
+	}
+	for _, chunk := range chunks {
+		if err := s.publisher.SendIngestMessage(ctx, chunk); err != nil {
+			return nil, fmt.Errorf("failed to enqueue logs: %w", err)
+		}
+	}
+
+	for tenantID, count := range counts {
+		_ = s.usage.Record(ctx, tenantID, count, int64(payloadSize)*count/int64(len(auditLogs)))
+	}
+	return ids, nil
+}
+
+// IngestChunks splits logs into chunks that each fit in one ingest or index
+// message and returns their total JSON size
+func IngestChunks(logs []domain.AuditLog) ([][]domain.AuditLog, int, error) {
+	var chunks [][]domain.AuditLog
+	start, chunkSize, total := 0, 0, 0
+	for i := range logs {
+		encoded, err := json.Marshal(&logs[i])
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to marshal log: %w", err)
+		}
+		if i > start && chunkSize+len(encoded) > maxIngestMessageSize {
+			chunks = append(chunks, logs[start:i])
+			start, chunkSize = i, 0
+		}
+		chunkSize += len(encoded) + 1
+		total += len(encoded)
+	}
+	if start < len(logs) {
+		chunks = append(chunks, logs[start:])
+	}
+	return chunks, total, nil
+}
+
+// tenantLogCounts counts the logs of each tenant in a batch
+func tenantLogCounts(logs []domain.AuditLog) map[string]int64 {
+	counts := make(map[string]int64)
+	for i := range logs {
+		counts[logs[i].TenantID]++
+	}
+	return counts
+}
+
+// createBulkOutboxEvents stores a bulk outbox event per chunk of logs that
+// fits in one index message and returns the size of their payloads
+func createBulkOutboxEvents(ctx context.Context, outbox repository.OutboxRepository, logs []domain.AuditLog) (int, error) {
+	chunks, _, err := IngestChunks(logs)
+	if err != nil {
+		return 0, err
+	}
+
+	var payloadSize int
+	for _, chunk := range chunks {
+		event, err := newOutboxEvent(ctx, domain.OutboxEventBulkIndex, chunk)
+		if err != nil {
+			return 0, err
+		}
+		payloadSize += len(event.Payload)
+		if err := outbox.Create(ctx, event); err != nil {
+			return 0, fmt.Errorf("failed to store outbox event: %w", err)
+		}
+	}
+	return payloadSize, nil
+}
+
+// newOutboxEvent builds an outbox event carrying the persisted logs as payload
+// and the current trace context and request ID, so the relay continues the
+// ingest trace and tags its queue messages with the request
+func newOutboxEvent(ctx context.Context, eventType domain.OutboxEventType, logs []domain.AuditLog) (*domain.OutboxEvent, error) {
+	payload, err := json.Marshal(logs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal outbox payload: %w", err)
+	}
+
+	var tenantID string
+	if len(logs) > 0 {
+		tenantID = logs[0].TenantID
+	}
+
+	return &domain.OutboxEvent{
+		TenantID:     tenantID,
+		EventType:    eventType,
+		Payload:      payload,
+		TraceContext: tracing.Inject(ctx),
+		RequestID:    utils.RequestIDFromContext(ctx),
+	}, nil
+}
+
+func (s *AuditLogService) GetByID(ctx context.Context, id string) (_ *dto.AuditLogResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AuditLogService.GetByID", trace.WithAttributes(attribute.String("audit_log.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	// Cache errors are not fatal: the log is then read from the database
+	tenantID, _ := utils.GetTenantIDFromContext(ctx)
+	if s.readCache != nil && tenantID != "" {
+		if log, err := s.readCache.GetLog(ctx, tenantID, id); err == nil && log != nil {
+			span.SetAttributes(attribute.Bool("cache.hit", true))
+			return dto.FromAuditLog(log), nil
+		}
+	}
+
 	log, err := s.repo.AuditLog().GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	if s.readCache != nil && tenantID != "" {
+		_ = s.readCache.SetLog(ctx, log)
+	}
 	return dto.FromAuditLog(log), nil
 }
 
-func (s *AuditLogService) List(ctx context.Context, filter *domain.AuditLogFilter, usePagination bool) ([]dto.AuditLogResponse, error) {
+// BatchGet returns the logs with the given IDs, in request order, and the IDs
+// not found. IDs missing from PostgreSQL, such as logs cleaned up after
+// archival, are looked up in OpenSearch. A non-empty userID restricts the
+// lookup to that user's logs.
+func (s *AuditLogService) BatchGet(ctx context.Context, ids []string, userID string) (_ *dto.BatchGetLogsResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AuditLogService.BatchGet", trace.WithAttributes(attribute.Int("audit_log.requested", len(ids))))
+	defer func() { tracing.End(span, err) }()
+
+	found := make(map[string]*domain.AuditLog, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := found[id]; !ok {
+			found[id] = nil
+			unique = append(unique, id)
+		}
+	}
+	ids = unique
+
+	logs, err := s.repo.AuditLog().GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get logs: %w", err)
+	}
+	for i := range logs {
+		found[logs[i].ID] = &logs[i]
+	}
+
+	var missing []string
+	for _, id := range ids {
+		if found[id] == nil {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		// The search index only fills gaps, so it being unavailable isn't fatal
+		if indexed, err := s.repo.OpenSearch().GetByIDs(ctx, missing); err == nil {
+			for i := range indexed {
+				found[indexed[i].ID] = &indexed[i]
+			}
+		}
+	}
+
+	resp := &dto.BatchGetLogsResponse{
+		Logs:    []dto.AuditLogResponse{},
+		Found:   []string{},
+		Missing: []string{},
+	}
+	for _, id := range ids {
+		log := found[id]
+		if log == nil || (userID != "" && log.UserID != userID) {
+			resp.Missing = append(resp.Missing, id)
+			continue
+		}
+		resp.Logs = append(resp.Logs, *dto.FromAuditLog(log))
+		resp.Found = append(resp.Found, id)
+	}
+
+	return resp, nil
+}
+
+// GetDiff compares the before and after state of a log. A non-empty userID
+// restricts the lookup to that user's logs.
+func (s *AuditLogService) GetDiff(ctx context.Context, id, userID string, unified bool) (_ *dto.AuditLogDiffResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AuditLogService.GetDiff", trace.WithAttributes(attribute.String("audit_log.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	log, err := s.repo.AuditLog().GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && userID != "" && log.UserID != userID) {
+		return nil, ErrLogNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := jsondiff.Diff(log.BeforeState, log.AfterState)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff log states: %w", err)
+	}
+	diff := &dto.AuditLogDiffResponse{
+		ID:      log.ID,
+		Changes: dto.FromStateChanges(changes),
+	}
+
+	if unified {
+		diff.Unified, err = jsondiff.Unified(log.BeforeState, log.AfterState, "before_state", "after_state")
+		if err != nil {
+			return nil, fmt.Errorf("failed to diff log states: %w", err)
+		}
+	}
+
+	return diff, nil
+}
+
+// List returns a page of the logs matching filter. Pages searched in
+// OpenSearch, which a filter with search criteria or SearchAfter is, come
+// with the cursor of the next page when it may hold more logs; pages of
+// PostgreSQL have none, as offsets there aren't capped.
+//
+// When the OpenSearch search fails, such as while its circuit breaker is
+// open, the page is read from PostgreSQL instead and degraded is true: a
+// full-text query is then a substring match and the logs aren't highlighted.
+// A search continued after a cursor can't fall back, as PostgreSQL has no
+// equivalent of its sort values.
+func (s *AuditLogService) List(ctx context.Context, filter *domain.AuditLogFilter, usePagination bool) (_ []dto.AuditLogResponse, nextCursor string, degraded bool, err error) {
+	ctx, span := tracing.Start(ctx, "AuditLogService.List")
+	defer func() { tracing.End(span, err) }()
+
 	// Set default values for pagination
 	if filter.Page < 1 {
 		filter.Page = 1
@@ -109,26 +682,41 @@ func (s *AuditLogService) List(ctx context.Context, filter *domain.AuditLogFilte
 	filter.Limit = filter.PageSize
 	filter.Offset = (filter.Page - 1) * filter.PageSize
 
-	// Use OpenSearch for searching if there are search criteria benefit from it
-	if s.hasSearchCriteria(filter) {
+	// Use OpenSearch for searching if there are search criteria benefit from
+	// it, or to continue a search after a cursor
+	if s.hasSearchCriteria(filter) || len(filter.SearchAfter) > 0 {
+		span.SetAttributes(attribute.String("audit_log.source", "opensearch"))
 		logs, err := s.repo.OpenSearch().Search(ctx, filter)
-		if err != nil {
-			return nil, err
+		if err == nil {
+			if len(logs) == filter.PageSize && len(logs[len(logs)-1].SortValues) > 0 {
+				nextCursor = domain.EncodeSearchCursor(logs[len(logs)-1].SortValues)
+			}
+			return dto.FromAuditLogs(logs), nextCursor, false, nil
 		}
-		return dto.FromAuditLogs(logs), nil
+		if len(filter.SearchAfter) > 0 || ctx.Err() != nil {
+			return nil, "", false, err
+		}
+		metrics.SearchFallbacksTotal.Inc()
+		span.RecordError(err)
+		span.SetAttributes(attribute.Bool("audit_log.degraded", true))
+		degraded = true
 	}
+	span.SetAttributes(attribute.String("audit_log.source", "postgres"))
 
 	// Otherwise, use PostgreSQL for simple listing if there are no search criteria benefit from it
 	logs, err := s.repo.AuditLog().List(ctx, *filter)
 	if err != nil {
-		return nil, err
+		return nil, "", false, err
 	}
-	return dto.FromAuditLogs(logs), nil
+	return dto.FromAuditLogs(logs), "", degraded, nil
 }
 
-func (s *AuditLogService) GetStats(ctx context.Context, filter *domain.AuditLogFilter) (*dto.GetAuditLogStatsResponse, error) {
+func (s *AuditLogService) GetStats(ctx context.Context, filter *domain.AuditLogFilter) (_ *dto.GetAuditLogStatsResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AuditLogService.GetStats")
+	defer func() { tracing.End(span, err) }()
+
 	// Use OpenSearch for aggregations if available, otherwise fall back to PostgreSQL
-	logs, err := s.List(ctx, filter, false)
+	logs, _, _, err := s.List(ctx, filter, false)
 	if err != nil {
 		return nil, err
 	}
@@ -151,51 +739,316 @@ func (s *AuditLogService) GetStats(ctx context.Context, filter *domain.AuditLogF
 	return stats, nil
 }
 
-func (s *AuditLogService) GetStatsV2(ctx context.Context, filter *domain.AuditLogFilter) (*dto.GetAuditLogStatsResponse, error) {
-	stats, err := s.repo.AuditLog().GetStats(ctx, *filter)
+// GetStatsV2 aggregates stats in the analytics store when one is used, unless
+// the filter has a full-text query. Otherwise OpenSearch aggregates them when
+// the filter has search criteria, which the pre-aggregated PostgreSQL stats
+// can't apply, and returns a time series along with the counts. PostgreSQL
+// serves the remaining stats.
+func (s *AuditLogService) GetStatsV2(ctx context.Context, filter *domain.AuditLogFilter) (_ *dto.GetAuditLogStatsResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AuditLogService.GetStatsV2")
+	defer func() { tracing.End(span, err) }()
+
+	// Cache errors are not fatal: the stats are then computed again
+	var cacheKey string
+	if s.readCache != nil && s.watermark != nil {
+		if watermark, err := s.watermark.Get(ctx, filter.TenantID); err == nil {
+			cacheKey = s.readCache.StatsKey(filter, watermark)
+		}
+	}
+	if cacheKey != "" {
+		if stats, err := s.readCache.GetStats(ctx, cacheKey); err == nil && stats != nil {
+			span.SetAttributes(attribute.Bool("cache.hit", true))
+			return dto.FromAuditLogStats(stats), nil
+		}
+	}
+
+	var stats *domain.AuditLogStats
+	if s.analytics != nil && filter.Query == "" {
+		span.SetAttributes(attribute.String("audit_log.source", "clickhouse"))
+		stats, err = s.analytics.Stats(ctx, filter)
+	} else if s.hasSearchCriteria(filter) {
+		span.SetAttributes(attribute.String("audit_log.source", "opensearch"))
+		stats, err = s.repo.OpenSearch().Stats(ctx, filter)
+	} else {
+		span.SetAttributes(attribute.String("audit_log.source", "postgres"))
+		stats, err = s.repo.AuditLog().GetStats(ctx, *filter)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get audit log stats: %w", err)
 	}
-
-	// Convert domain stats to DTO
-	response := &dto.GetAuditLogStatsResponse{
-		TotalLogs:      stats.TotalLogs,
-		ActionCounts:   make(map[string]int64, len(stats.ActionCounts)),
-		SeverityCounts: make(map[string]int64, len(stats.SeverityCounts)),
-		ResourceCounts: make(map[string]int64, len(stats.ResourceCounts)),
+	// Sampled logs are only counted by tenant and day, so they can't be
+	// extrapolated for narrower filters
+	if s.sampled != nil && !s.hasSearchCriteria(filter) {
+		if stats.SampledOut, err = s.sampled.Sum(ctx, filter.TenantID, filter.StartTime, filter.EndTime); err != nil {
+			return nil, fmt.Errorf("failed to get sampled log counts: %w", err)
+		}
+	}
+	if cacheKey != "" {
+		_ = s.readCache.SetStats(ctx, cacheKey, stats)
 	}
 
-	// Convert ActionType to string
-	for action, count := range stats.ActionCounts {
-		response.ActionCounts[string(action)] = count
+	return dto.FromAuditLogStats(stats), nil
+}
+
+// GetTopStats ranks the most frequent users, IP addresses, resources and
+// sessions of the logs matching filter, limit of each, and counts their
+// distinct users and IP addresses. Only OpenSearch aggregates them.
+func (s *AuditLogService) GetTopStats(ctx context.Context, filter *domain.AuditLogFilter, limit int) (_ *dto.TopStatsResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AuditLogService.GetTopStats", trace.WithAttributes(
+		attribute.Int("top_stats.limit", limit)))
+	defer func() { tracing.End(span, err) }()
+
+	stats, err := s.repo.OpenSearch().TopStats(ctx, filter, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top stats: %w", err)
 	}
+	return dto.FromTopStats(stats), nil
+}
+
+// GetByCorrelationID returns the logs of a request chain in time order, at
+// most correlationLogLimit of them. A non-empty userID restricts them to that
+// user's logs.
+func (s *AuditLogService) GetByCorrelationID(ctx context.Context, tenantID, correlationID, userID string) (_ []dto.AuditLogResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AuditLogService.GetByCorrelationID", trace.WithAttributes(
+		tracing.TenantAttr(tenantID),
+		attribute.String("audit_log.correlation_id", correlationID),
+	))
+	defer func() { tracing.End(span, err) }()
 
-	// Convert SeverityLevel to string
-	for severity, count := range stats.SeverityCounts {
-		response.SeverityCounts[string(severity)] = count
+	filter := domain.AuditLogFilter{
+		TenantID:      tenantID,
+		UserID:        userID,
+		CorrelationID: correlationID,
+	}
+	logs, err := s.repo.AuditLog().ListBatch(ctx, filter, nil, correlationLogLimit)
+	if err != nil {
+		return nil, err
 	}
+	return dto.FromAuditLogs(logs), nil
+}
+
+// ListAfter returns logs ingested after lastEventID so streaming clients can
+// resume without gaps. At most streamReplayLimit logs are replayed.
+func (s *AuditLogService) ListAfter(ctx context.Context, tenantID, lastEventID string) (_ []dto.AuditLogResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AuditLogService.ListAfter", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
 
-	// Copy resource counts as is
-	for resourceType, count := range stats.ResourceCounts {
-		response.ResourceCounts[resourceType] = count
+	logs, err := s.repo.AuditLog().ListAfter(ctx, tenantID, lastEventID, streamReplayLimit)
+	if err != nil {
+		return nil, err
 	}
+	return dto.FromAuditLogs(logs), nil
+}
+
+// ListSince returns logs ingested at or after since, oldest first, so streaming
+// clients that only know when they disconnected can resume. At most
+// streamReplayLimit logs are replayed.
+func (s *AuditLogService) ListSince(ctx context.Context, tenantID string, since time.Time) (_ []dto.AuditLogResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AuditLogService.ListSince", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
 
-	return response, nil
+	logs, err := s.repo.AuditLog().GetRecentLogs(ctx, tenantID, since, streamReplayLimit)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromAuditLogs(logs), nil
 }
 
 // hasSearchCriteria checks if the filter contains search criteria that would benefit from OpenSearch
 func (s *AuditLogService) hasSearchCriteria(filter *domain.AuditLogFilter) bool {
 	return filter.UserID != "" ||
-		filter.Action != "" ||
-		filter.ResourceType != "" ||
-		filter.Severity != "" ||
+		filter.VisibleUserID != "" ||
+		!filter.Action.IsEmpty() ||
+		!filter.ResourceType.IsEmpty() ||
+		!filter.Severity.IsEmpty() ||
+		!filter.Tags.IsEmpty() ||
+		filter.MinThreatScore > 0 ||
 		filter.IPAddress != "" ||
 		filter.UserAgent != "" ||
 		filter.Message != "" ||
+		filter.Query != "" ||
 		filter.SessionID != ""
 }
 
-// ScheduleArchive schedules an archive operation by sending a message to SQS
-func (s *AuditLogService) ScheduleArchive(ctx context.Context, tenantID string, beforeDate time.Time) error {
-	return s.sqsSvc.SendArchiveMessage(ctx, tenantID, beforeDate)
+// ScheduleArchive records a cleanup job and enqueues the archival of the
+// tenant's logs before beforeDate, which the cleanup follows. It returns the
+// job ID, whose status GET /jobs/{id} reports.
+func (s *AuditLogService) ScheduleArchive(ctx context.Context, tenantID string, beforeDate time.Time) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "AuditLogService.ScheduleArchive", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	job := &domain.CleanupJob{
+		TenantID:   tenantID,
+		Status:     domain.JobPending,
+		BeforeDate: beforeDate,
+	}
+	if err := s.repo.CleanupJob().Create(ctx, job); err != nil {
+		return "", fmt.Errorf("failed to create cleanup job: %w", err)
+	}
+
+	if err := s.publisher.SendArchiveMessage(ctx, tenantID, job.ID, beforeDate); err != nil {
+		// Best effort: don't leave the job pending forever when it never reached the queue
+		job.Status = domain.JobFailed
+		job.Error = "failed to enqueue archive message"
+		_ = s.repo.CleanupJob().Update(ctx, job)
+		return "", fmt.Errorf("failed to enqueue archive message: %w", err)
+	}
+
+	return job.ID, nil
+}
+
+// CreateExportJob records an export job and enqueues it for the export worker.
+// Pagination in the filter is ignored; the job exports every matching log.
+// The export is signed with signWith once uploaded, unless it is empty.
+func (s *AuditLogService) CreateExportJob(ctx context.Context, filter *domain.AuditLogFilter, format domain.ExportFormat, signWith domain.ExportSigningKey) (_ *dto.ExportJobResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AuditLogService.CreateExportJob", trace.WithAttributes(tracing.TenantAttr(filter.TenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	if signWith != "" && s.signer == nil {
+		return nil, ErrExportSigningDisabled
+	}
+
+	jobFilter := *filter
+	jobFilter.Page, jobFilter.PageSize, jobFilter.Limit, jobFilter.Offset = 0, 0, 0, 0
+
+	return s.startExportJob(ctx, &domain.ExportJob{
+		TenantID: filter.TenantID,
+		Status:   domain.JobPending,
+		Scope:    domain.ExportScopeLogs,
+		Format:   format,
+		Filter:   jobFilter,
+		SignWith: signWith,
+	})
+}
+
+// CreateTenantExportJob records a job dumping all of the tenant's logs, users,
+// retention policies and settings to S3, for data portability and
+// off-boarding, and enqueues it for the export worker
+func (s *AuditLogService) CreateTenantExportJob(ctx context.Context, tenantID string) (_ *dto.ExportJobResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AuditLogService.CreateTenantExportJob", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	return s.startExportJob(ctx, &domain.ExportJob{
+		TenantID: tenantID,
+		Status:   domain.JobPending,
+		Scope:    domain.ExportScopeTenant,
+		Format:   domain.ExportFormatNDJSON,
+		Filter:   domain.AuditLogFilter{TenantID: tenantID},
+	})
+}
+
+func (s *AuditLogService) startExportJob(ctx context.Context, job *domain.ExportJob) (*dto.ExportJobResponse, error) {
+	if err := s.repo.ExportJob().Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create export job: %w", err)
+	}
+
+	if err := s.publisher.SendExportMessage(ctx, job.TenantID, job.ID); err != nil {
+		// Best effort: don't leave the job pending forever when it never reached the queue
+		job.Status = domain.JobFailed
+		job.Error = "failed to enqueue export job"
+		_ = s.repo.ExportJob().Update(ctx, job)
+		return nil, fmt.Errorf("failed to enqueue export job: %w", err)
+	}
+
+	return dto.FromExportJob(job), nil
+}
+
+// GetExportJob returns the job status, with a pre-signed download URL once it has completed
+func (s *AuditLogService) GetExportJob(ctx context.Context, tenantID, jobID string) (_ *dto.ExportJobResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AuditLogService.GetExportJob", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	job, err := s.repo.ExportJob().GetByID(ctx, tenantID, jobID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrExportJobNotFound
+		}
+		return nil, fmt.Errorf("failed to get export job: %w", err)
+	}
+
+	resp := dto.FromExportJob(job)
+	if job.Status == domain.JobCompleted && job.S3Key != "" {
+		url, err := s.urlSigner.PresignGet(ctx, job.S3Key)
+		if err != nil {
+			return nil, err
+		}
+		resp.DownloadURL = url
+	}
+
+	return resp, nil
+}
+
+// GetExportSignature returns the detached signature of a signed export job's
+// file with the public key verifying it
+func (s *AuditLogService) GetExportSignature(ctx context.Context, tenantID, jobID string) (_ *dto.ExportSignatureResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AuditLogService.GetExportSignature", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	job, err := s.repo.ExportJob().GetByID(ctx, tenantID, jobID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrExportJobNotFound
+		}
+		return nil, fmt.Errorf("failed to get export job: %w", err)
+	}
+	if job.Signature == nil {
+		return nil, ErrExportNotSigned
+	}
+
+	return dto.FromExportSignature(job.ID, job.Signature), nil
+}
+
+// ExportSigningKeys returns the public keys the tenant's exports are signed
+// with, so recipients can verify them; none when signing isn't configured
+func (s *AuditLogService) ExportSigningKeys(ctx context.Context, tenantID string) ([]dto.SigningKeyResponse, error) {
+	if s.signer == nil {
+		return []dto.SigningKeyResponse{}, nil
+	}
+
+	keys := make([]dto.SigningKeyResponse, len(domain.ExportSigningKeys))
+	for i, key := range domain.ExportSigningKeys {
+		keys[i] = dto.FromSigningKey(s.signer.PublicKey(tenantID, key))
+	}
+	return keys, nil
+}
+
+// CreateRestoreJob records a restore job for archived logs in [startTime, endTime] and enqueues it
+func (s *AuditLogService) CreateRestoreJob(ctx context.Context, tenantID string, startTime, endTime time.Time) (_ *dto.RestoreJobResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AuditLogService.CreateRestoreJob", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	job := &domain.RestoreJob{
+		TenantID:  tenantID,
+		Status:    domain.JobPending,
+		StartTime: startTime,
+		EndTime:   endTime,
+	}
+	if err := s.repo.RestoreJob().Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create restore job: %w", err)
+	}
+
+	if err := s.publisher.SendRestoreMessage(ctx, tenantID, job.ID); err != nil {
+		// Best effort: don't leave the job pending forever when it never reached the queue
+		job.Status = domain.JobFailed
+		job.Error = "failed to enqueue restore job"
+		_ = s.repo.RestoreJob().Update(ctx, job)
+		return nil, fmt.Errorf("failed to enqueue restore job: %w", err)
+	}
+
+	return dto.FromRestoreJob(job), nil
+}
+
+func (s *AuditLogService) GetRestoreJob(ctx context.Context, tenantID, jobID string) (_ *dto.RestoreJobResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AuditLogService.GetRestoreJob", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	job, err := s.repo.RestoreJob().GetByID(ctx, tenantID, jobID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRestoreJobNotFound
+		}
+		return nil, fmt.Errorf("failed to get restore job: %w", err)
+	}
+
+	return dto.FromRestoreJob(job), nil
 }
@@ -1,39 +1,88 @@
 package service
 
 import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
 	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/config"
 	"github.com/kingrain94/audit-log-api/internal/domain"
 	"github.com/kingrain94/audit-log-api/internal/mocks"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/utils"
+	"github.com/kingrain94/audit-log-api/pkg/breaker"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
 )
 
 type AuditLogServiceTestSuite struct {
 	suite.Suite
-	mockRepo        *mocks.Repository
-	mockAuditLog    *mocks.AuditLogRepository
-	mockOpenSearch  *mocks.OpenSearchRepository
-	mockSQS         *mocks.SQSService
-	mockBroadcaster *mocks.WebSocketBroadcaster
-	service         *AuditLogService
+	mockRepo       *mocks.Repository
+	mockAuditLog   *mocks.AuditLogRepository
+	mockOpenSearch *mocks.OpenSearchRepository
+	mockOutbox     *mocks.OutboxRepository
+	mockPublisher  *mocks.MessagePublisher
+	mockExportJob  *mocks.ExportJobRepository
+	mockURLSigner  *mocks.ExportURLSigner
+	mockRestoreJob *mocks.RestoreJobRepository
+	mockRedactor   *mocks.LogRedactor
+	mockSchemas    *mocks.LogSchemaValidator
+	mockUsage      *mocks.UsageTracker
+	service        *AuditLogService
 }
 
 func (s *AuditLogServiceTestSuite) SetupTest() {
 	s.mockRepo = new(mocks.Repository)
 	s.mockAuditLog = new(mocks.AuditLogRepository)
 	s.mockOpenSearch = new(mocks.OpenSearchRepository)
-	s.mockSQS = new(mocks.SQSService)
-	s.mockBroadcaster = new(mocks.WebSocketBroadcaster)
+	s.mockOutbox = new(mocks.OutboxRepository)
+	s.mockPublisher = new(mocks.MessagePublisher)
+	s.mockExportJob = new(mocks.ExportJobRepository)
+	s.mockURLSigner = new(mocks.ExportURLSigner)
+	s.mockRestoreJob = new(mocks.RestoreJobRepository)
+	s.mockRedactor = new(mocks.LogRedactor)
+	s.mockSchemas = new(mocks.LogSchemaValidator)
+	s.mockUsage = new(mocks.UsageTracker)
 
 	s.mockRepo.On("AuditLog").Return(s.mockAuditLog)
 	s.mockRepo.On("OpenSearch").Return(s.mockOpenSearch)
+	s.mockRepo.On("Outbox").Return(s.mockOutbox)
+	s.mockRepo.On("ExportJob").Return(s.mockExportJob)
+	s.mockRepo.On("RestoreJob").Return(s.mockRestoreJob)
+	s.mockRepo.On("Transaction", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, fn func(tx repository.PostgresRepository) error) error {
+			return fn(s.mockRepo)
+		})
+
+	s.mockUsage.On("CheckQuota", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	s.mockUsage.On("Record", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	s.mockSchemas.On("Validate", mock.Anything, mock.Anything).Return(nil).Maybe()
 
-	s.service = NewAuditLogService(s.mockRepo, s.mockSQS)
-	s.service.SetWebSocketBroadcaster(s.mockBroadcaster)
+	s.service = NewAuditLogService(s.mockRepo, s.mockPublisher, s.mockURLSigner, s.mockRedactor, s.mockSchemas, s.mockUsage)
 }
 
 func TestAuditLogService(t *testing.T) {
@@ -57,9 +106,162 @@ func (s *AuditLogServiceTestSuite) TestCreate_Success() {
 		Timestamp:    time.Now(),
 	}
 
-	s.mockAuditLog.On("Create", ctx, mock.AnythingOfType("*domain.AuditLog")).Return(nil)
-	s.mockSQS.On("SendIndexMessage", ctx, mock.AnythingOfType("*domain.AuditLog")).Return(nil)
-	s.mockBroadcaster.On("BroadcastLog", mock.AnythingOfType("*dto.AuditLogResponse")).Return()
+	s.mockRedactor.On("Redact", mock.Anything, mock.Anything).Return(nil)
+	s.mockAuditLog.On("Create", mock.Anything, mock.AnythingOfType("*domain.AuditLog")).Return(nil)
+	s.mockOutbox.On("Create", mock.Anything, mock.MatchedBy(func(e *domain.OutboxEvent) bool {
+		return e.EventType == domain.OutboxEventIndex && e.TenantID == "tenant1"
+	})).Return(nil)
+
+	// Act
+	err := s.service.Create(ctx, req)
+
+	// Assert
+	s.NoError(err)
+	s.mockAuditLog.AssertExpectations(s.T())
+	s.mockOutbox.AssertExpectations(s.T())
+	s.mockPublisher.AssertNotCalled(s.T(), "SendIndexMessage", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogServiceTestSuite) TestBulkCreate_AdvancesWatermarkOfEachTenant() {
+	// Arrange
+	ctx := context.Background()
+	watermark := new(mocks.IngestWatermark)
+	watermark.On("Advance", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	s.service.UseWatermark(watermark)
+	reqs := []dto.CreateAuditLogRequest{
+		{TenantID: "tenant1", Action: "create", Severity: "info", Timestamp: time.Now()},
+		{TenantID: "tenant2", Action: "update", Severity: "info", Timestamp: time.Now()},
+	}
+	s.mockRedactor.On("Redact", mock.Anything, mock.Anything).Return(nil)
+	s.mockAuditLog.On("BulkCreate", mock.Anything, mock.Anything).Return(nil)
+	s.mockOutbox.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	// Act
+	err := s.service.BulkCreate(ctx, reqs)
+
+	// Assert
+	s.NoError(err)
+	watermark.AssertCalled(s.T(), "Advance", mock.Anything, mock.MatchedBy(func(id string) bool { return id == "tenant1" || id == "tenant2" }), mock.MatchedBy(func(id string) bool { return id == "tenant1" || id == "tenant2" }))
+}
+
+func (s *AuditLogServiceTestSuite) TestBulkCreate_QuotaExceeded_StoresNothing() {
+	// Arrange
+	ctx := context.Background()
+	usage := new(mocks.UsageTracker)
+	usage.On("CheckQuota", mock.Anything, "tenant1", int64(2)).Return(ErrDailyQuotaExceeded)
+	service := NewAuditLogService(s.mockRepo, s.mockPublisher, s.mockURLSigner, s.mockRedactor, s.mockSchemas, usage)
+	reqs := []dto.CreateAuditLogRequest{
+		{TenantID: "tenant1", Action: "create", Severity: "info", Timestamp: time.Now()},
+		{TenantID: "tenant1", Action: "update", Severity: "info", Timestamp: time.Now()},
+	}
+
+	// Act
+	err := service.BulkCreate(ctx, reqs)
+
+	// Assert
+	s.ErrorIs(err, ErrDailyQuotaExceeded)
+	s.mockAuditLog.AssertNotCalled(s.T(), "BulkCreate", mock.Anything, mock.Anything)
+	usage.AssertNotCalled(s.T(), "Record", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogServiceTestSuite) TestCreate_OutboxFailure_ReturnsError() {
+	// Arrange
+	ctx := context.Background()
+	req := dto.CreateAuditLogRequest{
+		TenantID:  "tenant1",
+		Action:    "create",
+		Severity:  "info",
+		Timestamp: time.Now(),
+	}
+
+	s.mockRedactor.On("Redact", mock.Anything, mock.Anything).Return(nil)
+	s.mockAuditLog.On("Create", mock.Anything, mock.AnythingOfType("*domain.AuditLog")).Return(nil)
+	s.mockOutbox.On("Create", mock.Anything, mock.AnythingOfType("*domain.OutboxEvent")).Return(errors.New("db down"))
+
+	// Act
+	err := s.service.Create(ctx, req)
+
+	// Assert
+	s.Error(err)
+	s.Contains(err.Error(), "failed to store outbox event")
+}
+
+func (s *AuditLogServiceTestSuite) TestCreate_PropagatesTraceContextToOutbox() {
+	// Arrange
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+	req := dto.CreateAuditLogRequest{
+		TenantID:  "tenant1",
+		Action:    "create",
+		Severity:  "info",
+		Timestamp: time.Now(),
+	}
+
+	s.mockRedactor.On("Redact", mock.Anything, mock.Anything).Return(nil)
+	s.mockAuditLog.On("Create", mock.Anything, mock.AnythingOfType("*domain.AuditLog")).Return(nil)
+	s.mockOutbox.On("Create", mock.Anything, mock.MatchedBy(func(e *domain.OutboxEvent) bool {
+		return strings.Contains(e.TraceContext["traceparent"], traceID.String())
+	})).Return(nil)
+
+	// Act
+	err := s.service.Create(ctx, req)
+
+	// Assert
+	s.NoError(err)
+	s.mockOutbox.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestCreate_RecordsRequestIDOnOutbox() {
+	// Arrange
+	ctx := context.WithValue(context.Background(), utils.RequestIDKey, "call-1")
+	req := dto.CreateAuditLogRequest{
+		TenantID:  "tenant1",
+		Action:    "create",
+		Severity:  "info",
+		Timestamp: time.Now(),
+	}
+
+	s.mockRedactor.On("Redact", mock.Anything, mock.Anything).Return(nil)
+	s.mockAuditLog.On("Create", mock.Anything, mock.AnythingOfType("*domain.AuditLog")).Return(nil)
+	s.mockOutbox.On("Create", mock.Anything, mock.MatchedBy(func(e *domain.OutboxEvent) bool {
+		return e.RequestID == "call-1"
+	})).Return(nil)
+
+	// Act
+	err := s.service.Create(ctx, req)
+
+	// Assert
+	s.NoError(err)
+	s.mockOutbox.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestCreate_StoresRedactedLog() {
+	// Arrange
+	ctx := context.Background()
+	req := dto.CreateAuditLogRequest{
+		TenantID:  "tenant1",
+		Action:    "create",
+		Severity:  "info",
+		Metadata:  json.RawMessage(`{"email":"jane@example.com"}`),
+		Timestamp: time.Now(),
+	}
+	redacted := json.RawMessage(`{"email":"***@example.com"}`)
+
+	s.mockRedactor.On("Redact", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).([]domain.AuditLog)[0].Metadata = redacted
+	}).Return(nil)
+	s.mockAuditLog.On("Create", mock.Anything, mock.MatchedBy(func(l *domain.AuditLog) bool {
+		return string(l.Metadata) == string(redacted)
+	})).Return(nil)
+	s.mockOutbox.On("Create", mock.Anything, mock.MatchedBy(func(e *domain.OutboxEvent) bool {
+		return !strings.Contains(string(e.Payload), "jane@example.com")
+	})).Return(nil)
 
 	// Act
 	err := s.service.Create(ctx, req)
@@ -67,8 +269,28 @@ func (s *AuditLogServiceTestSuite) TestCreate_Success() {
 	// Assert
 	s.NoError(err)
 	s.mockAuditLog.AssertExpectations(s.T())
-	s.mockSQS.AssertExpectations(s.T())
-	s.mockBroadcaster.AssertExpectations(s.T())
+	s.mockOutbox.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestCreate_RedactionFailure_DoesNotStore() {
+	// Arrange
+	ctx := context.Background()
+	req := dto.CreateAuditLogRequest{
+		TenantID:  "tenant1",
+		Action:    "create",
+		Severity:  "info",
+		Timestamp: time.Now(),
+	}
+
+	s.mockRedactor.On("Redact", mock.Anything, mock.Anything).Return(errors.New("db down"))
+
+	// Act
+	err := s.service.Create(ctx, req)
+
+	// Assert
+	s.Error(err)
+	s.mockAuditLog.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+	s.mockOutbox.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
 }
 
 func (s *AuditLogServiceTestSuite) TestBulkCreate_Success() {
@@ -97,18 +319,355 @@ func (s *AuditLogServiceTestSuite) TestBulkCreate_Success() {
 		},
 	}
 
-	s.mockAuditLog.On("BulkCreate", ctx, mock.AnythingOfType("[]domain.AuditLog")).Return(nil)
-	s.mockSQS.On("SendBulkIndexMessage", ctx, mock.AnythingOfType("[]domain.AuditLog")).Return(nil)
-	s.mockBroadcaster.On("BroadcastLog", mock.AnythingOfType("*dto.AuditLogResponse")).Return().Times(2)
+	s.mockRedactor.On("Redact", mock.Anything, mock.Anything).Return(nil)
+	s.mockAuditLog.On("BulkCreate", mock.Anything, mock.AnythingOfType("[]domain.AuditLog")).Return(nil)
+	s.mockOutbox.On("Create", mock.Anything, mock.MatchedBy(func(e *domain.OutboxEvent) bool {
+		return e.EventType == domain.OutboxEventBulkIndex
+	})).Return(nil)
+
+	// Act
+	err := s.service.BulkCreate(ctx, reqs)
+
+	// Assert
+	s.NoError(err)
+	s.mockAuditLog.AssertExpectations(s.T())
+	s.mockOutbox.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestBulkCreate_SplitsOutboxEventsToFitTheQueue() {
+	// Arrange
+	ctx := context.Background()
+	reqs := make([]dto.CreateAuditLogRequest, 6)
+	for i := range reqs {
+		reqs[i] = dto.CreateAuditLogRequest{TenantID: "tenant1", Action: "create", Severity: "info", ResourceID: strings.Repeat("x", 100*1024), Timestamp: time.Now()}
+	}
+
+	var stored []domain.AuditLog
+	s.mockRedactor.On("Redact", mock.Anything, mock.Anything).Return(nil)
+	s.mockAuditLog.On("BulkCreate", mock.Anything, mock.AnythingOfType("[]domain.AuditLog")).Return(nil).Once()
+	s.mockOutbox.On("Create", mock.Anything, mock.MatchedBy(func(e *domain.OutboxEvent) bool {
+		return e.EventType == domain.OutboxEventBulkIndex && len(e.Payload) < maxIngestMessageSize
+	})).Run(func(args mock.Arguments) {
+		var logs []domain.AuditLog
+		s.NoError(json.Unmarshal(args.Get(1).(*domain.OutboxEvent).Payload, &logs))
+		stored = append(stored, logs...)
+	}).Return(nil).Times(3)
+
+	// Act
+	err := s.service.BulkCreate(ctx, reqs)
+
+	// Assert
+	s.NoError(err)
+	s.Len(stored, len(reqs))
+	s.mockAuditLog.AssertExpectations(s.T())
+	s.mockOutbox.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestBulkCreate_SamplesPerTenantRules() {
+	// Arrange
+	ctx := context.Background()
+	reqs := []dto.CreateAuditLogRequest{
+		{TenantID: "tenant1", Action: "VIEW", Severity: "INFO", Timestamp: time.Now()},
+		{TenantID: "tenant1", Action: "VIEW", Severity: "ERROR", Timestamp: time.Now()},
+	}
+	settings := new(mocks.TenantSettingsResolver)
+	settings.On("ResolveSettings", mock.Anything, "tenant1").Return(&domain.TenantSettings{
+		SamplingRules: []domain.SamplingRule{{Severities: []string{"INFO"}, Rate: 0.5}},
+	}, nil).Once()
+	counter := new(mocks.SampledLogCounter)
+	counter.On("Add", mock.Anything, "tenant1", mock.Anything, mock.MatchedBy(func(logs []domain.AuditLog) bool {
+		return len(logs) == 1 && logs[0].Severity == "INFO"
+	})).Return(nil)
+	s.service.UseSampling(settings, counter)
+	s.service.random = func() float64 { return 0.7 }
+
+	var stored []domain.AuditLog
+	s.mockRedactor.On("Redact", mock.Anything, mock.Anything).Return(nil)
+	s.mockAuditLog.On("BulkCreate", mock.Anything, mock.AnythingOfType("[]domain.AuditLog")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).([]domain.AuditLog) }).
+		Return(nil)
+	s.mockOutbox.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	// Act
+	err := s.service.BulkCreate(ctx, reqs)
+
+	// Assert
+	s.NoError(err)
+	s.Require().Len(stored, 1)
+	s.Equal("ERROR", stored[0].Severity)
+	settings.AssertExpectations(s.T())
+	counter.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestCreate_SampledOut_AcknowledgedWithoutStoring() {
+	// Arrange
+	ctx := context.Background()
+	settings := new(mocks.TenantSettingsResolver)
+	settings.On("ResolveSettings", mock.Anything, "tenant1").Return(&domain.TenantSettings{
+		SamplingRules: []domain.SamplingRule{{Actions: []string{"VIEW"}, Rate: 0}},
+	}, nil)
+	counter := new(mocks.SampledLogCounter)
+	counter.On("Add", mock.Anything, "tenant1", mock.Anything, mock.Anything).Return(nil)
+	s.service.UseSampling(settings, counter)
+
+	// Act
+	err := s.service.Create(ctx, dto.CreateAuditLogRequest{TenantID: "tenant1", Action: "VIEW", Severity: "INFO", Timestamp: time.Now()})
+
+	// Assert
+	s.NoError(err)
+	counter.AssertExpectations(s.T())
+	s.mockRedactor.AssertNotCalled(s.T(), "Redact", mock.Anything, mock.Anything)
+	s.mockRepo.AssertNotCalled(s.T(), "Transaction", mock.Anything, mock.Anything)
+	s.mockUsage.AssertNotCalled(s.T(), "Record", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogServiceTestSuite) TestCreate_SettingsError_KeepsLog() {
+	// Arrange
+	ctx := context.Background()
+	settings := new(mocks.TenantSettingsResolver)
+	settings.On("ResolveSettings", mock.Anything, "tenant1").Return(nil, errors.New("redis down"))
+	s.service.UseSampling(settings, new(mocks.SampledLogCounter))
+	s.mockRedactor.On("Redact", mock.Anything, mock.Anything).Return(nil)
+	s.mockAuditLog.On("Create", mock.Anything, mock.AnythingOfType("*domain.AuditLog")).Return(nil)
+	s.mockOutbox.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	// Act
+	err := s.service.Create(ctx, dto.CreateAuditLogRequest{TenantID: "tenant1", Action: "VIEW", Severity: "INFO", Timestamp: time.Now()})
+
+	// Assert
+	s.NoError(err)
+	s.mockAuditLog.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestCreate_IngestBuffer_StoresFullBatch() {
+	// Arrange
+	ctx := context.WithValue(context.Background(), utils.ClaimsKey, jwt.MapClaims{"tenant_id": "tenant1"})
+	s.service.StartIngestBuffer(&config.IngestBufferConfig{BatchSize: 2, FlushInterval: time.Hour, DrainTimeout: time.Second}, logger.NewLogger("test"))
+	defer s.service.StopIngestBuffer()
+
+	s.mockRedactor.On("Redact", mock.Anything, mock.Anything).Return(nil)
+	s.mockAuditLog.On("BulkCreate", mock.MatchedBy(func(ctx context.Context) bool {
+		tenantID, err := utils.GetTenantIDFromContext(ctx)
+		return err == nil && tenantID == "tenant1"
+	}), mock.MatchedBy(func(logs []domain.AuditLog) bool {
+		return len(logs) == 2 && logs[0].Message == "first" && logs[1].Message == "second"
+	})).Return(nil).Once()
+	s.mockOutbox.On("Create", mock.Anything, mock.MatchedBy(func(e *domain.OutboxEvent) bool {
+		return e.EventType == domain.OutboxEventBulkIndex
+	})).Return(nil).Once()
+
+	// Act
+	firstErr := s.service.Create(ctx, dto.CreateAuditLogRequest{TenantID: "tenant1", Action: "create", Severity: "info", Message: "first", Timestamp: time.Now()})
+	s.mockAuditLog.AssertNotCalled(s.T(), "BulkCreate", mock.Anything, mock.Anything)
+	secondErr := s.service.Create(ctx, dto.CreateAuditLogRequest{TenantID: "tenant1", Action: "create", Severity: "info", Message: "second", Timestamp: time.Now()})
+
+	// Assert
+	s.NoError(firstErr)
+	s.NoError(secondErr)
+	s.mockAuditLog.AssertExpectations(s.T())
+	s.mockAuditLog.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+	s.mockOutbox.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestStopIngestBuffer_DrainsPendingLogs() {
+	// Arrange
+	ctx := context.Background()
+	s.service.StartIngestBuffer(&config.IngestBufferConfig{BatchSize: 100, FlushInterval: time.Hour, DrainTimeout: time.Second}, logger.NewLogger("test"))
+
+	s.mockRedactor.On("Redact", mock.Anything, mock.Anything).Return(nil)
+	s.mockAuditLog.On("BulkCreate", mock.Anything, mock.MatchedBy(func(logs []domain.AuditLog) bool {
+		return len(logs) == 1 && logs[0].TenantID == "tenant1"
+	})).Return(nil).Once()
+	s.mockAuditLog.On("BulkCreate", mock.Anything, mock.MatchedBy(func(logs []domain.AuditLog) bool {
+		return len(logs) == 1 && logs[0].TenantID == "tenant2"
+	})).Return(nil).Once()
+	s.mockOutbox.On("Create", mock.Anything, mock.Anything).Return(nil).Twice()
+	s.NoError(s.service.Create(ctx, dto.CreateAuditLogRequest{TenantID: "tenant1", Action: "create", Severity: "info", Timestamp: time.Now()}))
+	s.NoError(s.service.Create(ctx, dto.CreateAuditLogRequest{TenantID: "tenant2", Action: "create", Severity: "info", Timestamp: time.Now()}))
+
+	// Act
+	s.service.StopIngestBuffer()
+
+	// Assert
+	s.mockAuditLog.AssertExpectations(s.T())
+	s.mockOutbox.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestCreate_IngestBufferStopped_StoresRightAway() {
+	// Arrange
+	ctx := context.Background()
+	s.service.StartIngestBuffer(&config.IngestBufferConfig{BatchSize: 100, FlushInterval: time.Hour, DrainTimeout: time.Second}, logger.NewLogger("test"))
+	s.service.StopIngestBuffer()
+
+	s.mockRedactor.On("Redact", mock.Anything, mock.Anything).Return(nil)
+	s.mockAuditLog.On("Create", mock.Anything, mock.AnythingOfType("*domain.AuditLog")).Return(nil)
+	s.mockOutbox.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	// Act
+	err := s.service.Create(ctx, dto.CreateAuditLogRequest{TenantID: "tenant1", Action: "create", Severity: "info", Timestamp: time.Now()})
+
+	// Assert
+	s.NoError(err)
+	s.mockAuditLog.AssertExpectations(s.T())
+	s.mockAuditLog.AssertNotCalled(s.T(), "BulkCreate", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogServiceTestSuite) TestBulkCreateAsync_EnqueuesRedactedLogsWithIDs() {
+	// Arrange
+	ctx := context.Background()
+	reqs := []dto.CreateAuditLogRequest{
+		{TenantID: "tenant1", Action: "create", Severity: "info", Timestamp: time.Now()},
+		{TenantID: "tenant1", Action: "update", Severity: "info", Timestamp: time.Now()},
+	}
+	var enqueued []domain.AuditLog
+	s.mockRedactor.On("Redact", mock.Anything, mock.Anything).Return(nil)
+	s.mockPublisher.On("SendIngestMessage", mock.Anything, mock.AnythingOfType("[]domain.AuditLog")).
+		Run(func(args mock.Arguments) { enqueued = args.Get(1).([]domain.AuditLog) }).
+		Return(nil).Once()
+
+	// Act
+	ids, err := s.service.BulkCreateAsync(ctx, reqs)
+
+	// Assert
+	s.NoError(err)
+	s.Require().Len(ids, 2)
+	s.Require().Len(enqueued, 2)
+	s.Equal(ids[0], enqueued[0].ID)
+	s.Equal(ids[1], enqueued[1].ID)
+	s.mockRedactor.AssertExpectations(s.T())
+	s.mockAuditLog.AssertNotCalled(s.T(), "BulkCreate", mock.Anything, mock.Anything)
+	s.mockRepo.AssertNotCalled(s.T(), "Transaction", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogServiceTestSuite) TestBulkCreate_StoresTagsBeforeRedacting() {
+	// Arrange
+	ctx := context.Background()
+	reqs := []dto.CreateAuditLogRequest{
+		{TenantID: "tenant1", Action: "LOGIN", Severity: "INFO", Timestamp: time.Now()},
+		{TenantID: "tenant1", Action: "VIEW", Severity: "INFO", Timestamp: time.Now()},
+	}
+	tagger := new(mocks.LogTagger)
+	tagger.On("Tag", mock.Anything, mock.AnythingOfType("[]domain.AuditLog")).
+		Run(func(args mock.Arguments) {
+			logs := args.Get(1).([]domain.AuditLog)
+			logs[0].Tags.Add("authentication")
+		}).
+		Return(nil).Once()
+	s.service.UseTagger(tagger)
+	s.mockRedactor.On("Redact", mock.Anything, mock.MatchedBy(func(logs []domain.AuditLog) bool {
+		return slices.Equal(logs[0].Tags, domain.Tags{"authentication"})
+	})).Return(nil).Once()
+	s.mockAuditLog.On("BulkCreate", mock.Anything, mock.MatchedBy(func(logs []domain.AuditLog) bool {
+		return len(logs) == 2 && slices.Equal(logs[0].Tags, domain.Tags{"authentication"}) && len(logs[1].Tags) == 0
+	})).Return(nil).Once()
+	s.mockOutbox.On("Create", mock.Anything, mock.Anything).Return(nil)
 
 	// Act
 	err := s.service.BulkCreate(ctx, reqs)
 
 	// Assert
 	s.NoError(err)
+	tagger.AssertExpectations(s.T())
+	s.mockRedactor.AssertExpectations(s.T())
 	s.mockAuditLog.AssertExpectations(s.T())
-	s.mockSQS.AssertExpectations(s.T())
-	s.mockBroadcaster.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestCreate_TaggingFails_StoresNothing() {
+	// Arrange
+	ctx := context.Background()
+	tagger := new(mocks.LogTagger)
+	tagger.On("Tag", mock.Anything, mock.Anything).Return(errors.New("db down"))
+	s.service.UseTagger(tagger)
+
+	// Act
+	err := s.service.Create(ctx, dto.CreateAuditLogRequest{TenantID: "tenant1", Action: "LOGIN", Severity: "INFO", Timestamp: time.Now()})
+
+	// Assert
+	s.Error(err)
+	s.mockRedactor.AssertNotCalled(s.T(), "Redact", mock.Anything, mock.Anything)
+	s.mockAuditLog.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogServiceTestSuite) TestBulkCreate_EnrichesBeforeSampling() {
+	// Arrange
+	ctx := context.Background()
+	reqs := []dto.CreateAuditLogRequest{
+		{TenantID: "tenant1", Action: "LOGIN", Severity: "INFO", IPAddress: "203.0.113.7", Timestamp: time.Now()},
+		{TenantID: "tenant1", Action: "LOGIN", Severity: "INFO", IPAddress: "198.51.100.20", Timestamp: time.Now()},
+	}
+	enricher := new(mocks.LogEnricher)
+	enricher.On("Enrich", mock.Anything, mock.AnythingOfType("[]domain.AuditLog")).
+		Run(func(args mock.Arguments) {
+			logs := args.Get(1).([]domain.AuditLog)
+			logs[0].SetThreatScore(100)
+			logs[0].RaiseSeverity(domain.SeverityError)
+		}).Once()
+	s.service.UseEnricher(enricher)
+	settings := new(mocks.TenantSettingsResolver)
+	settings.On("ResolveSettings", mock.Anything, "tenant1").Return(&domain.TenantSettings{
+		SamplingRules: []domain.SamplingRule{{Severities: []string{"INFO"}, Rate: 0}},
+	}, nil)
+	counter := new(mocks.SampledLogCounter)
+	counter.On("Add", mock.Anything, "tenant1", mock.Anything, mock.Anything).Return(nil)
+	s.service.UseSampling(settings, counter)
+
+	var stored []domain.AuditLog
+	s.mockRedactor.On("Redact", mock.Anything, mock.Anything).Return(nil)
+	s.mockAuditLog.On("BulkCreate", mock.Anything, mock.AnythingOfType("[]domain.AuditLog")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).([]domain.AuditLog) }).
+		Return(nil)
+	s.mockOutbox.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	// Act
+	err := s.service.BulkCreate(ctx, reqs)
+
+	// Assert
+	s.NoError(err)
+	s.Require().Len(stored, 1)
+	s.Equal("203.0.113.7", stored[0].IPAddress)
+	s.Equal("ERROR", stored[0].Severity)
+	s.Equal(100, stored[0].ThreatScore())
+	enricher.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestBulkCreateAsync_SplitsLargeBatches() {
+	// Arrange
+	ctx := context.Background()
+	metadata := json.RawMessage(`{"blob":"` + strings.Repeat("x", 100*1024) + `"}`)
+	reqs := make([]dto.CreateAuditLogRequest, 3)
+	for i := range reqs {
+		reqs[i] = dto.CreateAuditLogRequest{TenantID: "tenant1", Action: "create", Severity: "info", Metadata: metadata, Timestamp: time.Now()}
+	}
+	s.mockRedactor.On("Redact", mock.Anything, mock.Anything).Return(nil)
+	s.mockPublisher.On("SendIngestMessage", mock.Anything, mock.MatchedBy(func(logs []domain.AuditLog) bool {
+		return len(logs) == 2
+	})).Return(nil).Once()
+	s.mockPublisher.On("SendIngestMessage", mock.Anything, mock.MatchedBy(func(logs []domain.AuditLog) bool {
+		return len(logs) == 1
+	})).Return(nil).Once()
+
+	// Act
+	ids, err := s.service.BulkCreateAsync(ctx, reqs)
+
+	// Assert
+	s.NoError(err)
+	s.Len(ids, 3)
+	s.mockPublisher.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestCreateAsync_EnqueueFailure_ReturnsError() {
+	// Arrange
+	ctx := context.Background()
+	s.mockRedactor.On("Redact", mock.Anything, mock.Anything).Return(nil)
+	s.mockPublisher.On("SendIngestMessage", mock.Anything, mock.Anything).Return(errors.New("queue unavailable"))
+
+	// Act
+	id, err := s.service.CreateAsync(ctx, dto.CreateAuditLogRequest{TenantID: "tenant1", Action: "create", Severity: "info", Timestamp: time.Now()})
+
+	// Assert
+	s.Error(err)
+	s.Empty(id)
+	s.mockUsage.AssertNotCalled(s.T(), "Record", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 }
 
 func (s *AuditLogServiceTestSuite) TestList_WithSearchCriteria_UsesOpenSearch() {
@@ -116,7 +675,7 @@ func (s *AuditLogServiceTestSuite) TestList_WithSearchCriteria_UsesOpenSearch()
 	ctx := context.Background()
 	filter := &domain.AuditLogFilter{
 		UserID:   "user1",
-		Action:   "create",
+		Action:   domain.ValueFilter{In: []string{"create"}},
 		Page:     1,
 		PageSize: 10,
 	}
@@ -132,10 +691,10 @@ func (s *AuditLogServiceTestSuite) TestList_WithSearchCriteria_UsesOpenSearch()
 		},
 	}
 
-	s.mockOpenSearch.On("Search", ctx, filter).Return(expectedLogs, nil)
+	s.mockOpenSearch.On("Search", mock.Anything, filter).Return(expectedLogs, nil)
 
 	// Act
-	result, err := s.service.List(ctx, filter, true)
+	result, _, _, err := s.service.List(ctx, filter, true)
 
 	// Assert
 	s.NoError(err)
@@ -145,34 +704,1073 @@ func (s *AuditLogServiceTestSuite) TestList_WithSearchCriteria_UsesOpenSearch()
 	s.mockOpenSearch.AssertExpectations(s.T())
 }
 
-func (s *AuditLogServiceTestSuite) TestList_WithoutSearchCriteria_UsesPostgres() {
+func (s *AuditLogServiceTestSuite) TestList_FullSearchPage_ReturnsNextCursor() {
 	// Arrange
 	ctx := context.Background()
-	filter := &domain.AuditLogFilter{
-		Page:     1,
-		PageSize: 10,
+	filter := &domain.AuditLogFilter{TenantID: "tenant1", UserID: "user1", PageSize: 2}
+	logs := []domain.AuditLog{
+		{ID: "1", TenantID: "tenant1", SortValues: json.RawMessage(`[1704103200000,"1"]`)},
+		{ID: "2", TenantID: "tenant1", SortValues: json.RawMessage(`[1704103100000,"2"]`)},
 	}
+	s.mockOpenSearch.On("Search", mock.Anything, filter).Return(logs, nil)
 
-	expectedLogs := []domain.AuditLog{
-		{
-			ID:        "1",
-			TenantID:  "tenant1",
-			UserID:    "user1",
-			Action:    "create",
-			Message:   "Test message",
-			CreatedAt: time.Now(),
-		},
-	}
+	// Act
+	result, nextCursor, _, err := s.service.List(ctx, filter, true)
+
+	// Assert
+	s.NoError(err)
+	s.Len(result, 2)
+	searchAfter, err := domain.ParseSearchCursor(nextCursor)
+	s.NoError(err)
+	s.JSONEq(`[1704103100000,"2"]`, string(searchAfter))
+}
 
-	s.mockAuditLog.On("List", ctx, mock.AnythingOfType("domain.AuditLogFilter")).Return(expectedLogs, nil)
+func (s *AuditLogServiceTestSuite) TestList_LastSearchPage_ReturnsNoCursor() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{TenantID: "tenant1", UserID: "user1", PageSize: 2}
+	s.mockOpenSearch.On("Search", mock.Anything, filter).
+		Return([]domain.AuditLog{{ID: "1", TenantID: "tenant1", SortValues: json.RawMessage(`[1704103200000,"1"]`)}}, nil)
 
 	// Act
-	result, err := s.service.List(ctx, filter, true)
+	_, nextCursor, _, err := s.service.List(ctx, filter, true)
 
 	// Assert
 	s.NoError(err)
-	s.Len(result, 1)
-	s.Equal(expectedLogs[0].ID, result[0].ID)
-	s.Equal(expectedLogs[0].UserID, result[0].UserID)
-	s.mockAuditLog.AssertExpectations(s.T())
+	s.Empty(nextCursor)
+}
+
+func (s *AuditLogServiceTestSuite) TestList_WithSearchAfter_SearchesOpenSearch() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{TenantID: "tenant1", SearchAfter: json.RawMessage(`[1704103100000,"2"]`)}
+	s.mockOpenSearch.On("Search", mock.Anything, filter).Return([]domain.AuditLog{}, nil)
+
+	// Act
+	_, _, _, err := s.service.List(ctx, filter, true)
+
+	// Assert
+	s.NoError(err)
+	s.mockOpenSearch.AssertExpectations(s.T())
+	s.mockAuditLog.AssertNotCalled(s.T(), "List", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogServiceTestSuite) TestList_SearchFails_FallsBackToPostgres() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{TenantID: "tenant1", Query: "failed login", PageSize: 10}
+	s.mockOpenSearch.On("Search", mock.Anything, filter).Return(nil, &breaker.OpenError{Name: "opensearch", RetryAfter: time.Second})
+	s.mockAuditLog.On("List", mock.Anything, mock.MatchedBy(func(f domain.AuditLogFilter) bool {
+		return f.Query == "failed login" && f.Limit == 10
+	})).Return([]domain.AuditLog{{ID: "1", TenantID: "tenant1"}}, nil)
+
+	// Act
+	result, nextCursor, degraded, err := s.service.List(ctx, filter, true)
+
+	// Assert
+	s.NoError(err)
+	s.True(degraded)
+	s.Empty(nextCursor)
+	s.Len(result, 1)
+	s.mockAuditLog.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestList_SearchAfterFails_ReturnsError() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{TenantID: "tenant1", SearchAfter: json.RawMessage(`[1704103100000,"2"]`)}
+	s.mockOpenSearch.On("Search", mock.Anything, filter).Return(nil, errors.New("opensearch unavailable"))
+
+	// Act
+	_, _, degraded, err := s.service.List(ctx, filter, true)
+
+	// Assert
+	s.Error(err)
+	s.False(degraded)
+	s.mockAuditLog.AssertNotCalled(s.T(), "List", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogServiceTestSuite) TestList_WithQuery_ReturnsHighlights() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{
+		TenantID: "tenant1",
+		Query:    "failed login",
+	}
+
+	expectedLogs := []domain.AuditLog{
+		{
+			ID:         "1",
+			TenantID:   "tenant1",
+			Message:    "User login failed",
+			Highlights: map[string][]string{"message": {"User <em>login</em> <em>failed</em>"}},
+		},
+	}
+
+	s.mockOpenSearch.On("Search", mock.Anything, filter).Return(expectedLogs, nil)
+
+	// Act
+	result, _, _, err := s.service.List(ctx, filter, true)
+
+	// Assert
+	s.NoError(err)
+	s.Len(result, 1)
+	s.Equal(expectedLogs[0].Highlights, result[0].Highlights)
+	s.mockOpenSearch.AssertExpectations(s.T())
+	s.mockAuditLog.AssertNotCalled(s.T(), "List", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogServiceTestSuite) TestList_WithoutSearchCriteria_UsesPostgres() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{
+		Page:     1,
+		PageSize: 10,
+	}
+
+	expectedLogs := []domain.AuditLog{
+		{
+			ID:        "1",
+			TenantID:  "tenant1",
+			UserID:    "user1",
+			Action:    "create",
+			Message:   "Test message",
+			CreatedAt: time.Now(),
+		},
+	}
+
+	s.mockAuditLog.On("List", mock.Anything, mock.AnythingOfType("domain.AuditLogFilter")).Return(expectedLogs, nil)
+
+	// Act
+	result, _, _, err := s.service.List(ctx, filter, true)
+
+	// Assert
+	s.NoError(err)
+	s.Len(result, 1)
+	s.Equal(expectedLogs[0].ID, result[0].ID)
+	s.Equal(expectedLogs[0].UserID, result[0].UserID)
+	s.mockAuditLog.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestGetStatsV2_WithSearchCriteria_UsesOpenSearch() {
+	// Arrange
+	ctx := context.Background()
+	start := time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC)
+	filter := &domain.AuditLogFilter{
+		TenantID:  "tenant1",
+		UserID:    "user1",
+		StartTime: start,
+		EndTime:   start.Add(24 * time.Hour),
+	}
+
+	s.mockOpenSearch.On("Stats", mock.Anything, filter).Return(&domain.AuditLogStats{
+		TotalLogs:      3,
+		ActionCounts:   map[domain.ActionType]int64{domain.ActionCreate: 3},
+		SeverityCounts: map[domain.SeverityLevel]int64{domain.SeverityInfo: 3},
+		ResourceCounts: map[string]int64{"user": 3},
+		Interval:       time.Hour,
+		Series: []domain.AuditLogStatsBucket{
+			{Start: start, Count: 1},
+			{Start: start.Add(time.Hour), Count: 2},
+		},
+	}, nil)
+
+	// Act
+	stats, err := s.service.GetStatsV2(ctx, filter)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(int64(3), stats.TotalLogs)
+	s.Equal(int64(3), stats.ActionCounts[string(domain.ActionCreate)])
+	s.Equal("1h0m0s", stats.Interval)
+	s.Len(stats.Series, 2)
+	s.Equal(int64(2), stats.Series[1].Count)
+	s.mockAuditLog.AssertNotCalled(s.T(), "GetStats", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogServiceTestSuite) TestGetTopStats_ComputesShares() {
+	// Arrange
+	ctx := context.Background()
+	start := time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC)
+	filter := &domain.AuditLogFilter{TenantID: "tenant1", StartTime: start, EndTime: start.Add(48 * time.Hour)}
+
+	s.mockOpenSearch.On("TopStats", mock.Anything, filter, 5).Return(&domain.TopStats{
+		TotalLogs:         8,
+		Users:             []domain.TopValue{{Value: "user1", Count: 6}, {Value: "user2", Count: 1}},
+		IPAddresses:       []domain.TopValue{{Value: "10.0.0.1", Count: 8}},
+		Resources:         []domain.TopValue{{Value: "order-1", ResourceType: "order", Count: 3}},
+		Sessions:          []domain.TopValue{},
+		UniqueUsers:       2,
+		UniqueIPAddresses: 1,
+		Daily: []domain.DailyUniqueCounts{
+			{Day: start, Users: 2, IPAddresses: 1},
+			{Day: start.Add(24 * time.Hour), Users: 1, IPAddresses: 1},
+		},
+	}, nil)
+
+	// Act
+	stats, err := s.service.GetTopStats(ctx, filter, 5)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(int64(8), stats.TotalLogs)
+	s.Equal(75.0, stats.Users[0].Percentage)
+	s.Equal(12.5, stats.Users[1].Percentage)
+	s.Equal(100.0, stats.IPAddresses[0].Percentage)
+	s.Equal("order", stats.Resources[0].ResourceType)
+	s.Equal(37.5, stats.Resources[0].Percentage)
+	s.Empty(stats.Sessions)
+	s.Equal(int64(2), stats.UniqueUsers)
+	s.Require().Len(stats.Daily, 2)
+	s.Equal("2024-03-21", stats.Daily[1].Day)
+}
+
+func (s *AuditLogServiceTestSuite) TestGetTopStats_NoLogs_ZeroShares() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{TenantID: "tenant1"}
+
+	s.mockOpenSearch.On("TopStats", mock.Anything, filter, 10).Return(&domain.TopStats{}, nil)
+
+	// Act
+	stats, err := s.service.GetTopStats(ctx, filter, 10)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(int64(0), stats.TotalLogs)
+	s.Empty(stats.Users)
+	s.Empty(stats.Daily)
+}
+
+func (s *AuditLogServiceTestSuite) TestGetStatsV2_WithoutSearchCriteria_UsesPostgres() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{
+		TenantID:  "tenant1",
+		StartTime: time.Now().Add(-time.Hour),
+		EndTime:   time.Now(),
+	}
+
+	s.mockAuditLog.On("GetStats", mock.Anything, *filter).Return(&domain.AuditLogStats{
+		TotalLogs:      5,
+		ActionCounts:   map[domain.ActionType]int64{domain.ActionUpdate: 5},
+		SeverityCounts: map[domain.SeverityLevel]int64{},
+		ResourceCounts: map[string]int64{},
+	}, nil)
+
+	// Act
+	stats, err := s.service.GetStatsV2(ctx, filter)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(int64(5), stats.TotalLogs)
+	s.Empty(stats.Series)
+	s.mockOpenSearch.AssertNotCalled(s.T(), "Stats", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogServiceTestSuite) TestGetStatsV2_WithSampling_EstimatesTotal() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{
+		TenantID:  "tenant1",
+		StartTime: time.Now().Add(-time.Hour),
+		EndTime:   time.Now(),
+	}
+	s.mockAuditLog.On("GetStats", mock.Anything, *filter).Return(&domain.AuditLogStats{
+		TotalLogs:      5,
+		ActionCounts:   map[domain.ActionType]int64{domain.ActionView: 5},
+		SeverityCounts: map[domain.SeverityLevel]int64{},
+		ResourceCounts: map[string]int64{},
+	}, nil)
+	counter := new(mocks.SampledLogCounter)
+	counter.On("Sum", mock.Anything, "tenant1", filter.StartTime, filter.EndTime).Return(&domain.SampledLogCounts{
+		TotalLogs:      495,
+		ActionCounts:   map[string]int64{"VIEW": 495},
+		SeverityCounts: map[string]int64{"INFO": 495},
+	}, nil)
+	s.service.UseSampling(new(mocks.TenantSettingsResolver), counter)
+
+	// Act
+	stats, err := s.service.GetStatsV2(ctx, filter)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(int64(5), stats.TotalLogs)
+	s.Require().NotNil(stats.SampledOut)
+	s.Equal(int64(495), stats.SampledOut.ActionCounts["VIEW"])
+	s.Equal(int64(500), stats.EstimatedTotalLogs)
+}
+
+func (s *AuditLogServiceTestSuite) TestGetStatsV2_CacheHit_SkipsDatabase() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{TenantID: "tenant1", StartTime: time.Now().Add(-time.Hour), EndTime: time.Now()}
+	watermark := new(mocks.IngestWatermark)
+	watermark.On("Get", mock.Anything, "tenant1").Return(int64(3), nil)
+	readCache := new(mocks.LogReadCache)
+	readCache.On("StatsKey", filter, int64(3)).Return("log_stats:tenant1:3:abc")
+	readCache.On("GetStats", mock.Anything, "log_stats:tenant1:3:abc").Return(&domain.AuditLogStats{TotalLogs: 7}, nil)
+	s.service.UseReadCache(readCache)
+	s.service.UseWatermark(watermark)
+
+	// Act
+	stats, err := s.service.GetStatsV2(ctx, filter)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(int64(7), stats.TotalLogs)
+	s.mockAuditLog.AssertNotCalled(s.T(), "GetStats", mock.Anything, mock.Anything)
+	readCache.AssertNotCalled(s.T(), "SetStats", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogServiceTestSuite) TestGetStatsV2_CacheMiss_CachesUnderKeyTakenFirst() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{TenantID: "tenant1", StartTime: time.Now().Add(-time.Hour), EndTime: time.Now()}
+	stats := &domain.AuditLogStats{TotalLogs: 5}
+	watermark := new(mocks.IngestWatermark)
+	watermark.On("Get", mock.Anything, "tenant1").Return(int64(3), nil).Once()
+	readCache := new(mocks.LogReadCache)
+	readCache.On("StatsKey", filter, int64(3)).Return("log_stats:tenant1:3:abc").Once()
+	readCache.On("GetStats", mock.Anything, "log_stats:tenant1:3:abc").Return(nil, nil)
+	readCache.On("SetStats", mock.Anything, "log_stats:tenant1:3:abc", stats).Return(nil)
+	s.mockAuditLog.On("GetStats", mock.Anything, *filter).Return(stats, nil)
+	s.service.UseReadCache(readCache)
+	s.service.UseWatermark(watermark)
+
+	// Act
+	resp, err := s.service.GetStatsV2(ctx, filter)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(int64(5), resp.TotalLogs)
+	readCache.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestGetStatsV2_CacheError_FallsBackToDatabase() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{TenantID: "tenant1", StartTime: time.Now().Add(-time.Hour), EndTime: time.Now()}
+	watermark := new(mocks.IngestWatermark)
+	watermark.On("Get", mock.Anything, "tenant1").Return(int64(0), errors.New("redis down"))
+	readCache := new(mocks.LogReadCache)
+	s.mockAuditLog.On("GetStats", mock.Anything, *filter).Return(&domain.AuditLogStats{TotalLogs: 2}, nil)
+	s.service.UseReadCache(readCache)
+	s.service.UseWatermark(watermark)
+
+	// Act
+	stats, err := s.service.GetStatsV2(ctx, filter)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(int64(2), stats.TotalLogs)
+	readCache.AssertNotCalled(s.T(), "SetStats", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogServiceTestSuite) TestETag_ChangesWithWatermarkAndFilter() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{TenantID: "tenant1", StartTime: time.Now().Add(-time.Hour), EndTime: time.Now()}
+	other := *filter
+	other.Page = 2
+	s.service.now = func() time.Time { return time.Date(2025, 7, 17, 12, 0, 30, 0, time.UTC) }
+	watermark := new(mocks.IngestWatermark)
+	watermark.On("Get", mock.Anything, "tenant1").Return(int64(3), nil).Twice()
+	watermark.On("Get", mock.Anything, "tenant1").Return(int64(4), nil)
+	s.service.UseWatermark(watermark)
+
+	// Act
+	etag := s.service.ETag(ctx, "stats", filter)
+	same := s.service.ETag(ctx, "stats", filter)
+	advanced := s.service.ETag(ctx, "stats", filter)
+	otherFilter := s.service.ETag(ctx, "stats", &other)
+	otherName := s.service.ETag(ctx, "logs", filter)
+	s.service.now = func() time.Time { return time.Date(2025, 7, 17, 12, 1, 0, 0, time.UTC) }
+	nextWindow := s.service.ETag(ctx, "logs", filter)
+
+	// Assert
+	s.Regexp(`^W/"[0-9a-f]{32}"$`, etag)
+	s.Equal(etag, same)
+	s.NotEqual(etag, advanced)
+	s.NotEqual(advanced, otherFilter)
+	s.NotEqual(advanced, otherName)
+	s.NotEqual(otherName, nextWindow)
+}
+
+func (s *AuditLogServiceTestSuite) TestETag_WatermarkError_ReturnsEmpty() {
+	// Arrange
+	watermark := new(mocks.IngestWatermark)
+	watermark.On("Get", mock.Anything, "tenant1").Return(int64(0), errors.New("redis down"))
+	s.service.UseWatermark(watermark)
+
+	// Act
+	etag := s.service.ETag(context.Background(), "logs", &domain.AuditLogFilter{TenantID: "tenant1"})
+
+	// Assert
+	s.Empty(etag)
+}
+
+func (s *AuditLogServiceTestSuite) TestGetByID_CacheMiss_CachesLog() {
+	// Arrange
+	ctx := context.WithValue(context.Background(), utils.ClaimsKey, jwt.MapClaims{"tenant_id": "tenant1"})
+	log := &domain.AuditLog{ID: "log1", TenantID: "tenant1", Action: "CREATE"}
+	readCache := new(mocks.LogReadCache)
+	readCache.On("GetLog", mock.Anything, "tenant1", "log1").Return(nil, nil)
+	readCache.On("SetLog", mock.Anything, log).Return(nil)
+	s.mockAuditLog.On("GetByID", mock.Anything, "log1").Return(log, nil)
+	s.service.UseReadCache(readCache)
+
+	// Act
+	resp, err := s.service.GetByID(ctx, "log1")
+
+	// Assert
+	s.NoError(err)
+	s.Equal("log1", resp.ID)
+	readCache.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestGetByID_CacheHit_SkipsDatabase() {
+	// Arrange
+	ctx := context.WithValue(context.Background(), utils.ClaimsKey, jwt.MapClaims{"tenant_id": "tenant1"})
+	readCache := new(mocks.LogReadCache)
+	readCache.On("GetLog", mock.Anything, "tenant1", "log1").Return(&domain.AuditLog{ID: "log1", TenantID: "tenant1"}, nil)
+	s.service.UseReadCache(readCache)
+
+	// Act
+	resp, err := s.service.GetByID(ctx, "log1")
+
+	// Assert
+	s.NoError(err)
+	s.Equal("log1", resp.ID)
+	s.mockAuditLog.AssertNotCalled(s.T(), "GetByID", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogServiceTestSuite) TestGetStatsV2_WithAnalytics_UsesAnalytics() {
+	// Arrange
+	ctx := context.Background()
+	start := time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC)
+	filter := &domain.AuditLogFilter{
+		TenantID:  "tenant1",
+		UserID:    "user1",
+		StartTime: start,
+		EndTime:   start.Add(24 * time.Hour),
+	}
+
+	analytics := new(mocks.AnalyticsRepository)
+	analytics.On("Stats", mock.Anything, filter).Return(&domain.AuditLogStats{
+		TotalLogs:      4,
+		ActionCounts:   map[domain.ActionType]int64{domain.ActionDelete: 4},
+		SeverityCounts: map[domain.SeverityLevel]int64{domain.SeverityInfo: 4},
+		ResourceCounts: map[string]int64{},
+		Interval:       time.Hour,
+		Series:         []domain.AuditLogStatsBucket{{Start: start, Count: 4}},
+	}, nil)
+	s.service.UseAnalytics(analytics)
+
+	// Act
+	stats, err := s.service.GetStatsV2(ctx, filter)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(int64(4), stats.TotalLogs)
+	s.Equal(int64(4), stats.ActionCounts[string(domain.ActionDelete)])
+	s.Len(stats.Series, 1)
+	s.mockOpenSearch.AssertNotCalled(s.T(), "Stats", mock.Anything, mock.Anything)
+	s.mockAuditLog.AssertNotCalled(s.T(), "GetStats", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogServiceTestSuite) TestGetStatsV2_WithAnalytics_FullTextQueryUsesOpenSearch() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{
+		TenantID:  "tenant1",
+		Query:     "password reset",
+		StartTime: time.Now().Add(-time.Hour),
+		EndTime:   time.Now(),
+	}
+
+	analytics := new(mocks.AnalyticsRepository)
+	s.service.UseAnalytics(analytics)
+	s.mockOpenSearch.On("Stats", mock.Anything, filter).Return(&domain.AuditLogStats{
+		TotalLogs:      2,
+		ActionCounts:   map[domain.ActionType]int64{},
+		SeverityCounts: map[domain.SeverityLevel]int64{},
+		ResourceCounts: map[string]int64{},
+	}, nil)
+
+	// Act
+	stats, err := s.service.GetStatsV2(ctx, filter)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(int64(2), stats.TotalLogs)
+	analytics.AssertNotCalled(s.T(), "Stats", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogServiceTestSuite) TestListAfter_ReplaysMissedLogs() {
+	// Arrange
+	ctx := context.Background()
+	missedLogs := []domain.AuditLog{
+		{ID: "2", TenantID: "tenant1", Action: "create"},
+		{ID: "3", TenantID: "tenant1", Action: "update"},
+	}
+
+	s.mockAuditLog.On("ListAfter", mock.Anything, "tenant1", "1", streamReplayLimit).Return(missedLogs, nil)
+
+	// Act
+	result, err := s.service.ListAfter(ctx, "tenant1", "1")
+
+	// Assert
+	s.NoError(err)
+	s.Len(result, 2)
+	s.Equal("2", result[0].ID)
+	s.Equal("3", result[1].ID)
+	s.mockAuditLog.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestListSince_ReplaysMissedLogs() {
+	// Arrange
+	ctx := context.Background()
+	since := time.Now().Add(-time.Minute)
+	missedLogs := []domain.AuditLog{
+		{ID: "2", TenantID: "tenant1", Action: "create"},
+		{ID: "3", TenantID: "tenant1", Action: "update"},
+	}
+
+	s.mockAuditLog.On("GetRecentLogs", mock.Anything, "tenant1", since, streamReplayLimit).Return(missedLogs, nil)
+
+	// Act
+	result, err := s.service.ListSince(ctx, "tenant1", since)
+
+	// Assert
+	s.NoError(err)
+	s.Len(result, 2)
+	s.Equal("2", result[0].ID)
+	s.Equal("3", result[1].ID)
+	s.mockAuditLog.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestCreateExportJob_EnqueuesJob() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{
+		TenantID: "tenant1",
+		Action:   domain.ValueFilter{In: []string{"create"}},
+		Page:     3,
+		PageSize: 50,
+	}
+
+	s.mockExportJob.On("Create", mock.Anything, mock.MatchedBy(func(j *domain.ExportJob) bool {
+		return j.TenantID == "tenant1" && j.Status == domain.JobPending &&
+			j.Format == domain.ExportFormatCSV && slices.Equal(j.Filter.Action.In, []string{"create"}) && j.Filter.PageSize == 0
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.ExportJob).ID = "job1"
+	}).Return(nil)
+	s.mockPublisher.On("SendExportMessage", mock.Anything, "tenant1", "job1").Return(nil)
+
+	// Act
+	result, err := s.service.CreateExportJob(ctx, filter, domain.ExportFormatCSV, "")
+
+	// Assert
+	s.NoError(err)
+	s.Equal("job1", result.ID)
+	s.Equal(string(domain.JobPending), result.Status)
+	s.mockExportJob.AssertExpectations(s.T())
+	s.mockPublisher.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestCreateTenantExportJob_EnqueuesTenantDump() {
+	// Arrange
+	ctx := context.Background()
+
+	s.mockExportJob.On("Create", mock.Anything, mock.MatchedBy(func(j *domain.ExportJob) bool {
+		return j.TenantID == "tenant1" && j.Scope == domain.ExportScopeTenant &&
+			j.Format == domain.ExportFormatNDJSON && j.Filter.TenantID == "tenant1"
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.ExportJob).ID = "job1"
+	}).Return(nil)
+	s.mockPublisher.On("SendExportMessage", mock.Anything, "tenant1", "job1").Return(nil)
+
+	// Act
+	result, err := s.service.CreateTenantExportJob(ctx, "tenant1")
+
+	// Assert
+	s.NoError(err)
+	s.Equal("job1", result.ID)
+	s.Equal(string(domain.ExportScopeTenant), result.Scope)
+	s.mockExportJob.AssertExpectations(s.T())
+	s.mockPublisher.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestCreateExportJob_EnqueueFailure_MarksJobFailed() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{TenantID: "tenant1"}
+
+	s.mockExportJob.On("Create", mock.Anything, mock.AnythingOfType("*domain.ExportJob")).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.ExportJob).ID = "job1"
+	}).Return(nil)
+	s.mockPublisher.On("SendExportMessage", mock.Anything, "tenant1", "job1").Return(errors.New("queue unavailable"))
+	s.mockExportJob.On("Update", mock.Anything, mock.MatchedBy(func(j *domain.ExportJob) bool {
+		return j.ID == "job1" && j.Status == domain.JobFailed
+	})).Return(nil)
+
+	// Act
+	result, err := s.service.CreateExportJob(ctx, filter, domain.ExportFormatJSON, "")
+
+	// Assert
+	s.Error(err)
+	s.Nil(result)
+	s.mockExportJob.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestGetExportJob_Completed_ReturnsDownloadURL() {
+	// Arrange
+	ctx := context.Background()
+	job := &domain.ExportJob{
+		ID:       "job1",
+		TenantID: "tenant1",
+		Status:   domain.JobCompleted,
+		Format:   domain.ExportFormatJSON,
+		S3Key:    "exports/tenant1/job1.json",
+		RowCount: 42,
+	}
+
+	s.mockExportJob.On("GetByID", mock.Anything, "tenant1", "job1").Return(job, nil)
+	s.mockURLSigner.On("PresignGet", mock.Anything, "exports/tenant1/job1.json").Return("https://example.com/job1.json", nil)
+
+	// Act
+	result, err := s.service.GetExportJob(ctx, "tenant1", "job1")
+
+	// Assert
+	s.NoError(err)
+	s.Equal("https://example.com/job1.json", result.DownloadURL)
+	s.Equal(int64(42), result.RowCount)
+	s.mockURLSigner.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestGetExportJob_Pending_OmitsDownloadURL() {
+	// Arrange
+	ctx := context.Background()
+	job := &domain.ExportJob{ID: "job1", TenantID: "tenant1", Status: domain.JobRunning}
+
+	s.mockExportJob.On("GetByID", mock.Anything, "tenant1", "job1").Return(job, nil)
+
+	// Act
+	result, err := s.service.GetExportJob(ctx, "tenant1", "job1")
+
+	// Assert
+	s.NoError(err)
+	s.Empty(result.DownloadURL)
+	s.mockURLSigner.AssertNotCalled(s.T(), "PresignGet", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogServiceTestSuite) TestGetExportJob_NotFound() {
+	// Arrange
+	ctx := context.Background()
+	s.mockExportJob.On("GetByID", mock.Anything, "tenant1", "missing").Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	result, err := s.service.GetExportJob(ctx, "tenant1", "missing")
+
+	// Assert
+	s.ErrorIs(err, ErrExportJobNotFound)
+	s.Nil(result)
+}
+
+func (s *AuditLogServiceTestSuite) TestCreateExportJob_SigningDisabled() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{TenantID: "tenant1"}
+
+	// Act
+	result, err := s.service.CreateExportJob(ctx, filter, domain.ExportFormatJSON, domain.ExportSigningServer)
+
+	// Assert
+	s.ErrorIs(err, ErrExportSigningDisabled)
+	s.Nil(result)
+	s.mockExportJob.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogServiceTestSuite) TestGetExportSignature_NotSigned() {
+	// Arrange
+	ctx := context.Background()
+	job := &domain.ExportJob{ID: "job1", TenantID: "tenant1", Status: domain.JobCompleted}
+	s.mockExportJob.On("GetByID", mock.Anything, "tenant1", "job1").Return(job, nil)
+
+	// Act
+	result, err := s.service.GetExportSignature(ctx, "tenant1", "job1")
+
+	// Assert
+	s.ErrorIs(err, ErrExportNotSigned)
+	s.Nil(result)
+}
+
+func (s *AuditLogServiceTestSuite) TestExportSigningKeys_VerifySignatures() {
+	// Arrange
+	ctx := context.Background()
+	_, key, err := ed25519.GenerateKey(nil)
+	s.Require().NoError(err)
+	signer := NewExportSigner(key)
+	s.service.UseExportSigner(signer)
+	digest := sha256.Sum256([]byte("export"))
+
+	// Act
+	keys, err := s.service.ExportSigningKeys(ctx, "tenant1")
+
+	// Assert
+	s.NoError(err)
+	s.Require().Len(keys, 2)
+	s.NotEqual(keys[0].KeyID, keys[1].KeyID)
+	for _, k := range keys {
+		sig := signer.Sign("tenant1", domain.ExportSigningKey(k.Key), digest[:])
+		s.Equal(k.KeyID, sig.ID)
+
+		block, _ := pem.Decode([]byte(k.PublicKey))
+		s.Require().NotNil(block)
+		public, err := x509.ParsePKIXPublicKey(block.Bytes)
+		s.Require().NoError(err)
+		raw, err := base64.StdEncoding.DecodeString(sig.Signature)
+		s.Require().NoError(err)
+		s.True(ed25519.Verify(public.(ed25519.PublicKey), digest[:], raw))
+	}
+
+	other, err := s.service.ExportSigningKeys(ctx, "tenant2")
+	s.NoError(err)
+	s.Equal(keys[0].KeyID, other[0].KeyID)
+	s.NotEqual(keys[1].KeyID, other[1].KeyID)
+}
+
+func (s *AuditLogServiceTestSuite) TestGetDiff_ListsChangedPaths() {
+	// Arrange
+	ctx := context.Background()
+	log := &domain.AuditLog{
+		ID:          "log1",
+		UserID:      "user1",
+		BeforeState: json.RawMessage(`{"name":"old","address":{"city":"Hanoi"},"tags":["a","b"]}`),
+		AfterState:  json.RawMessage(`{"name":"new","address":{"city":"Hanoi"},"tags":["a"],"email":"a@b.c"}`),
+	}
+	s.mockAuditLog.On("GetByID", mock.Anything, "log1").Return(log, nil)
+
+	// Act
+	result, err := s.service.GetDiff(ctx, "log1", "", true)
+
+	// Assert
+	s.NoError(err)
+	s.Equal([]dto.StateChange{
+		{Path: "email", Op: "added", After: "a@b.c"},
+		{Path: "name", Op: "changed", Before: "old", After: "new"},
+		{Path: "tags[1]", Op: "removed", Before: "b"},
+	}, result.Changes)
+	s.Contains(result.Unified, "\n-  \"name\": \"old\",\n")
+	s.Contains(result.Unified, "\n+  \"name\": \"new\",\n")
+}
+
+func (s *AuditLogServiceTestSuite) TestGetDiff_OtherUsersLog_NotFound() {
+	// Arrange
+	ctx := context.Background()
+	s.mockAuditLog.On("GetByID", mock.Anything, "log1").Return(&domain.AuditLog{ID: "log1", UserID: "user2"}, nil)
+
+	// Act
+	result, err := s.service.GetDiff(ctx, "log1", "user1", false)
+
+	// Assert
+	s.ErrorIs(err, ErrLogNotFound)
+	s.Nil(result)
+}
+
+func (s *AuditLogServiceTestSuite) TestGetByCorrelationID_ListsChainInOrder() {
+	// Arrange
+	ctx := context.Background()
+	first := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	logs := []domain.AuditLog{
+		{ID: "log1", TenantID: "tenant1", CorrelationID: "req-1", Timestamp: first},
+		{ID: "log2", TenantID: "tenant1", CorrelationID: "req-1", Timestamp: first.Add(time.Second)},
+	}
+	s.mockAuditLog.On("ListBatch", mock.Anything, domain.AuditLogFilter{
+		TenantID:      "tenant1",
+		UserID:        "user1",
+		CorrelationID: "req-1",
+	}, (*domain.AuditLogCursor)(nil), correlationLogLimit).Return(logs, nil)
+
+	// Act
+	result, err := s.service.GetByCorrelationID(ctx, "tenant1", "req-1", "user1")
+
+	// Assert
+	s.NoError(err)
+	s.Len(result, 2)
+	s.Equal("log1", result[0].ID)
+	s.Equal("req-1", result[1].CorrelationID)
+}
+
+func (s *AuditLogServiceTestSuite) TestExport_StreamsBatchesAsJSONArray() {
+	// Arrange
+	ctx := context.Background()
+	first := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	batch := make([]domain.AuditLog, exportBatchSize)
+	for i := range batch {
+		batch[i] = domain.AuditLog{ID: fmt.Sprintf("log%d", i), TenantID: "tenant1", Action: "create", Timestamp: first.Add(time.Duration(i) * time.Second)}
+	}
+	last := batch[len(batch)-1]
+	filter := &domain.AuditLogFilter{TenantID: "tenant1", Fields: []string{"id"}}
+	s.mockAuditLog.On("ListBatch", mock.Anything, *filter, (*domain.AuditLogCursor)(nil), exportBatchSize).Return(batch, nil)
+	s.mockAuditLog.On("ListBatch", mock.Anything, *filter, &domain.AuditLogCursor{Timestamp: last.Timestamp, ID: last.ID}, exportBatchSize).
+		Return([]domain.AuditLog{{ID: "final", TenantID: "tenant1", Timestamp: last.Timestamp.Add(time.Second)}}, nil)
+	var out bytes.Buffer
+
+	// Act
+	rows, err := s.service.Export(ctx, filter, domain.ExportFormatJSON, &out)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(int64(exportBatchSize+1), rows)
+	var exported []map[string]any
+	s.NoError(json.Unmarshal(out.Bytes(), &exported))
+	s.Len(exported, exportBatchSize+1)
+	s.Equal(map[string]any{"id": "log0"}, exported[0])
+	s.Equal("final", exported[exportBatchSize]["id"])
+	s.mockAuditLog.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestExport_CSVWithSelectedFields() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{TenantID: "tenant1", Fields: []string{"id", "action"}}
+	s.mockAuditLog.On("ListBatch", mock.Anything, *filter, (*domain.AuditLogCursor)(nil), exportBatchSize).
+		Return([]domain.AuditLog{{ID: "log1", TenantID: "tenant1", Action: "create"}}, nil)
+	var out bytes.Buffer
+
+	// Act
+	rows, err := s.service.Export(ctx, filter, domain.ExportFormatCSV, &out)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(int64(1), rows)
+	s.Equal("ID,Action\nlog1,create\n", out.String())
+}
+
+func (s *AuditLogServiceTestSuite) TestExport_XLSXWorkbook() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{TenantID: "tenant1", Fields: []string{"id", "severity", "message"}}
+	s.mockAuditLog.On("ListBatch", mock.Anything, *filter, (*domain.AuditLogCursor)(nil), exportBatchSize).
+		Return([]domain.AuditLog{
+			{ID: "log1", TenantID: "tenant1", Severity: string(domain.SeverityInfo), Message: "viewed <report>"},
+			{ID: "log2", TenantID: "tenant1", Severity: string(domain.SeverityError), Message: "failed"},
+		}, nil)
+	var out bytes.Buffer
+
+	// Act
+	rows, err := s.service.Export(ctx, filter, domain.ExportFormatXLSX, &out)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(int64(2), rows)
+	workbook, err := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	s.Require().NoError(err)
+	f, err := workbook.Open("xl/worksheets/sheet1.xml")
+	s.Require().NoError(err)
+	sheet, err := io.ReadAll(f)
+	s.Require().NoError(err)
+	s.Contains(string(sheet), `state="frozen"`)
+	s.Contains(string(sheet), `<col min="3" max="3" width="60" customWidth="1"/>`)
+	s.Contains(string(sheet), `<c r="B2" t="inlineStr"><is><t xml:space="preserve">INFO</t></is></c>`)
+	s.Contains(string(sheet), `<c r="B3" t="inlineStr" s="3"><is><t xml:space="preserve">ERROR</t></is></c>`)
+	s.Contains(string(sheet), `viewed &lt;report&gt;`)
+	s.Contains(string(sheet), `<autoFilter ref="A1:C3"/>`)
+}
+
+func (s *AuditLogServiceTestSuite) TestExport_WithQuery_ScansOpenSearchOldestFirst() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{TenantID: "tenant1", Query: "failed login", Fields: []string{"id"}, SearchAfter: json.RawMessage(`[1]`)}
+	s.mockOpenSearch.On("Scan", mock.Anything, mock.MatchedBy(func(f *domain.AuditLogFilter) bool {
+		return f.Query == "failed login" && f.SearchAfter == nil && slices.Equal(f.Sort, []domain.SortField{{Field: "timestamp"}})
+	}), exportBatchSize, mock.Anything).
+		Run(func(args mock.Arguments) {
+			fn := args.Get(3).(func([]domain.AuditLog) error)
+			_ = fn([]domain.AuditLog{{ID: "log1"}, {ID: "log2"}})
+			_ = fn([]domain.AuditLog{{ID: "log3"}})
+		}).
+		Return(nil)
+	var out bytes.Buffer
+
+	// Act
+	rows, err := s.service.Export(ctx, filter, domain.ExportFormatJSON, &out)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(int64(3), rows)
+	s.JSONEq(`[{"id":"log1"},{"id":"log2"},{"id":"log3"}]`, out.String())
+	s.mockAuditLog.AssertNotCalled(s.T(), "ListBatch", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogServiceTestSuite) TestExport_ReadFailure_ReturnsError() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{TenantID: "tenant1"}
+	s.mockAuditLog.On("ListBatch", mock.Anything, *filter, (*domain.AuditLogCursor)(nil), exportBatchSize).Return(nil, errors.New("connection reset"))
+
+	// Act
+	_, err := s.service.Export(ctx, filter, domain.ExportFormatJSON, io.Discard)
+
+	// Assert
+	s.ErrorContains(err, "failed to read logs")
+}
+
+func (s *AuditLogServiceTestSuite) TestReport_VerifiesLogsAgainstStats() {
+	// Arrange
+	ctx := context.Background()
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	filter := &domain.AuditLogFilter{TenantID: "tenant1", StartTime: start, EndTime: start.Add(7 * 24 * time.Hour)}
+	logs := []domain.AuditLog{
+		{ID: "log1", TenantID: "tenant1", Action: "login", Severity: "INFO", Message: "signed in", Timestamp: start.Add(time.Hour)},
+		{ID: "log2", TenantID: "tenant1", Action: "delete", Severity: "ERROR", Message: "delete (denied)", Timestamp: start.Add(50 * time.Hour)},
+	}
+	s.mockAuditLog.On("GetStats", mock.Anything, *filter).Return(&domain.AuditLogStats{
+		TotalLogs:      2,
+		ActionCounts:   map[domain.ActionType]int64{"login": 1, "delete": 1},
+		SeverityCounts: map[domain.SeverityLevel]int64{domain.SeverityInfo: 1, domain.SeverityError: 1},
+		ResourceCounts: map[string]int64{},
+	}, nil)
+	s.mockAuditLog.On("ListBatch", mock.Anything, *filter, (*domain.AuditLogCursor)(nil), exportBatchSize).Return(logs, nil)
+	var export bytes.Buffer
+	_, err := s.service.Export(ctx, filter, domain.ExportFormatJSON, &export)
+	s.Require().NoError(err)
+	digest := sha256.Sum256(export.Bytes())
+
+	// Act
+	report, err := s.service.Report(ctx, filter)
+
+	// Assert
+	s.NoError(err)
+	s.True(bytes.HasPrefix(report, []byte("%PDF-1.4")))
+	content := pdfContent(s.T(), report)
+	s.Contains(content, "(Verified)")
+	s.Contains(content, "("+hex.EncodeToString(digest[:])+")")
+	s.Contains(content, "(Logs per 6 hours, from the start of the period.)")
+	s.Contains(content, `(delete \(denied\))`)
+	s.NotContains(content, "(signed in)")
+	s.Contains(content, "(Page 2 of 2)")
+}
+
+func (s *AuditLogServiceTestSuite) TestReport_CountMismatch() {
+	// Arrange
+	ctx := context.Background()
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	filter := &domain.AuditLogFilter{TenantID: "tenant1", StartTime: start, EndTime: start.Add(24 * time.Hour)}
+	s.mockAuditLog.On("GetStats", mock.Anything, *filter).Return(&domain.AuditLogStats{TotalLogs: 3}, nil)
+	s.mockAuditLog.On("ListBatch", mock.Anything, *filter, (*domain.AuditLogCursor)(nil), exportBatchSize).
+		Return([]domain.AuditLog{{ID: "log1", TenantID: "tenant1", Severity: "INFO", Message: "signed in", Timestamp: start}}, nil)
+
+	// Act
+	report, err := s.service.Report(ctx, filter)
+
+	// Assert
+	s.NoError(err)
+	content := pdfContent(s.T(), report)
+	s.Contains(content, "(Mismatch)")
+	s.Contains(content, "(signed in)")
+}
+
+func (s *AuditLogServiceTestSuite) TestReport_StatsFailure_ReturnsError() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{TenantID: "tenant1", StartTime: time.Now().Add(-time.Hour), EndTime: time.Now()}
+	s.mockAuditLog.On("GetStats", mock.Anything, *filter).Return(nil, errors.New("connection reset"))
+
+	// Act
+	_, err := s.service.Report(ctx, filter)
+
+	// Assert
+	s.ErrorContains(err, "failed to get audit log stats")
+	s.mockAuditLog.AssertNotCalled(s.T(), "ListBatch", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// pdfContent returns the inflated page contents of a PDF
+func pdfContent(t *testing.T, pdf []byte) string {
+	var content strings.Builder
+	for _, match := range regexp.MustCompile(`(?s)stream\n(.*?)\nendstream`).FindAllSubmatch(pdf, -1) {
+		r, err := zlib.NewReader(bytes.NewReader(match[1]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.Copy(&content, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return content.String()
+}
+
+func (s *AuditLogServiceTestSuite) TestCreateRestoreJob_EnqueuesJob() {
+	// Arrange
+	ctx := context.Background()
+	startTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	endTime := time.Date(2024, 3, 31, 23, 59, 59, 0, time.UTC)
+
+	s.mockRestoreJob.On("Create", mock.Anything, mock.MatchedBy(func(j *domain.RestoreJob) bool {
+		return j.TenantID == "tenant1" && j.Status == domain.JobPending &&
+			j.StartTime.Equal(startTime) && j.EndTime.Equal(endTime)
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.RestoreJob).ID = "job1"
+	}).Return(nil)
+	s.mockPublisher.On("SendRestoreMessage", mock.Anything, "tenant1", "job1").Return(nil)
+
+	// Act
+	result, err := s.service.CreateRestoreJob(ctx, "tenant1", startTime, endTime)
+
+	// Assert
+	s.NoError(err)
+	s.Equal("job1", result.ID)
+	s.Equal(string(domain.JobPending), result.Status)
+	s.mockRestoreJob.AssertExpectations(s.T())
+	s.mockPublisher.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestGetRestoreJob_NotFound() {
+	// Arrange
+	ctx := context.Background()
+	s.mockRestoreJob.On("GetByID", mock.Anything, "tenant1", "missing").Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	result, err := s.service.GetRestoreJob(ctx, "tenant1", "missing")
+
+	// Assert
+	s.ErrorIs(err, ErrRestoreJobNotFound)
+	s.Nil(result)
+}
+
+func (s *AuditLogServiceTestSuite) TestBatchGet_FallsBackToOpenSearchForMissing() {
+	// Arrange
+	ctx := context.Background()
+	s.mockAuditLog.On("GetByIDs", mock.Anything, []string{"log1", "log2", "log3"}).
+		Return([]domain.AuditLog{{ID: "log2", UserID: "user1"}}, nil)
+	s.mockOpenSearch.On("GetByIDs", mock.Anything, []string{"log1", "log3"}).
+		Return([]domain.AuditLog{{ID: "log1", UserID: "user1"}}, nil)
+
+	// Act
+	result, err := s.service.BatchGet(ctx, []string{"log1", "log2", "log1", "log3"}, "")
+
+	// Assert
+	s.NoError(err)
+	s.Equal([]string{"log1", "log2"}, result.Found)
+	s.Equal([]string{"log3"}, result.Missing)
+	s.Len(result.Logs, 2)
+	s.Equal("log1", result.Logs[0].ID)
+}
+
+func (s *AuditLogServiceTestSuite) TestBatchGet_OtherUsersLogs_Missing() {
+	// Arrange
+	ctx := context.Background()
+	s.mockAuditLog.On("GetByIDs", mock.Anything, []string{"log1", "log2"}).
+		Return([]domain.AuditLog{{ID: "log1", UserID: "user1"}, {ID: "log2", UserID: "user2"}}, nil)
+
+	// Act
+	result, err := s.service.BatchGet(ctx, []string{"log1", "log2"}, "user1")
+
+	// Assert
+	s.NoError(err)
+	s.Equal([]string{"log1"}, result.Found)
+	s.Equal([]string{"log2"}, result.Missing)
+	s.mockOpenSearch.AssertNotCalled(s.T(), "GetByIDs", mock.Anything, mock.Anything)
 }
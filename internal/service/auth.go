@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+)
+
+//go:generate mockery --name TokenStore --output ../mocks
+type TokenStore interface {
+	SaveRefreshToken(ctx context.Context, token *domain.RefreshToken) error
+	GetRefreshToken(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, current, next *domain.RefreshToken) (bool, error)
+	RevokeRefreshFamily(ctx context.Context, familyID string) error
+	RevokeAccessToken(ctx context.Context, tokenID string, expiresAt time.Time) error
+	SaveStreamTicket(ctx context.Context, ticket *domain.StreamTicket) error
+	ConsumeStreamTicket(ctx context.Context, ticketHash string) (*domain.StreamTicket, error)
+}
+
+// refreshTokenBytes is the amount of randomness in an opaque refresh token
+// and in a stream ticket
+const refreshTokenBytes = 32
+
+// dummyPasswordHash is compared against when the email is unknown, so the
+// response time doesn't reveal which emails have accounts
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
+// AuthService exchanges user credentials for short-lived access tokens and
+// rotating refresh tokens
+type AuthService struct {
+	repo       repository.Repository
+	tokens     TokenStore
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	ticketTTL  time.Duration
+}
+
+func NewAuthService(repo repository.Repository, tokens TokenStore, cfg *config.Config) *AuthService {
+	return &AuthService{
+		repo:       repo,
+		tokens:     tokens,
+		secret:     []byte(cfg.JWTSecretKey),
+		accessTTL:  cfg.AccessTokenTTL,
+		refreshTTL: cfg.RefreshTokenTTL,
+		ticketTTL:  cfg.StreamTicketTTL,
+	}
+}
+
+// IssueToken authenticates a user by email and password and starts a new refresh token family
+func (s *AuthService) IssueToken(ctx context.Context, req dto.TokenRequest) (_ *dto.TokenResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.IssueToken")
+	defer func() { tracing.End(span, err) }()
+
+	user, err := s.repo.User().GetByEmail(ctx, req.Email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user == nil || user.PasswordHash == "" {
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(req.Password))
+		return nil, ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	if !user.Active {
+		return nil, ErrUserInactive
+	}
+
+	span.SetAttributes(tracing.TenantAttr(user.TenantID), attribute.String("user.id", user.ID))
+
+	refreshToken, record, err := s.newRefreshToken(user, uuid.NewString())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tokens.SaveRefreshToken(ctx, record); err != nil {
+		return nil, err
+	}
+
+	return s.tokenResponse(user, refreshToken)
+}
+
+// Refresh exchanges a refresh token for a new token pair. The presented token
+// is rotated out; presenting it again revokes every token of its family.
+// Roles and active status are reloaded, so changes apply from the next refresh.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (_ *dto.TokenResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Refresh")
+	defer func() { tracing.End(span, err) }()
+
+	current, err := s.tokens.GetRefreshToken(ctx, hashToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	span.SetAttributes(tracing.TenantAttr(current.TenantID), attribute.String("user.id", current.UserID))
+
+	user, err := s.repo.User().GetByID(ctx, current.TenantID, current.UserID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil || !user.Active {
+		if err := s.tokens.RevokeRefreshFamily(ctx, current.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	nextToken, next, err := s.newRefreshToken(user, current.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	rotated, err := s.tokens.RotateRefreshToken(ctx, current, next)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return s.tokenResponse(user, nextToken)
+}
+
+// Revoke adds an access token to the revocation list and, if given, revokes
+// the family of a refresh token belonging to the same user
+func (s *AuthService) Revoke(ctx context.Context, userID, tokenID string, expiresAt time.Time, refreshToken string) (err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Revoke", trace.WithAttributes(attribute.String("user.id", userID)))
+	defer func() { tracing.End(span, err) }()
+
+	if tokenID != "" {
+		if err := s.tokens.RevokeAccessToken(ctx, tokenID, expiresAt); err != nil {
+			return err
+		}
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	record, err := s.tokens.GetRefreshToken(ctx, hashToken(refreshToken))
+	if err != nil {
+		return err
+	}
+	if record == nil || record.UserID != userID {
+		return ErrInvalidRefreshToken
+	}
+
+	return s.tokens.RevokeRefreshFamily(ctx, record.FamilyID)
+}
+
+// IssueStreamTicket issues a one-time ticket carrying the tenant, user and
+// roles of the caller's token, for opening a log stream with ?ticket= where
+// no Authorization header can be sent. A ticket never outlives the token it
+// was issued for.
+func (s *AuthService) IssueStreamTicket(ctx context.Context, claims jwt.MapClaims) (_ *dto.StreamTicketResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.IssueStreamTicket")
+	defer func() { tracing.End(span, err) }()
+
+	tenantID, _ := claims["tenant_id"].(string)
+	if tenantID == "" {
+		return nil, ErrNoTenantInToken
+	}
+	userID, _ := claims["user_id"].(string)
+	span.SetAttributes(tracing.TenantAttr(tenantID), attribute.String("user.id", userID))
+
+	expiresAt := time.Now().Add(s.ticketTTL)
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil && exp.Before(expiresAt) {
+		expiresAt = exp.Time
+	}
+
+	buf := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to generate stream ticket: %w", err)
+	}
+	ticket := base64.RawURLEncoding.EncodeToString(buf)
+
+	var roles []string
+	if values, ok := claims["roles"].([]any); ok {
+		for _, value := range values {
+			if role, ok := value.(string); ok {
+				roles = append(roles, role)
+			}
+		}
+	}
+
+	if err := s.tokens.SaveStreamTicket(ctx, &domain.StreamTicket{
+		TicketHash: hashToken(ticket),
+		TenantID:   tenantID,
+		UserID:     userID,
+		Roles:      roles,
+		ExpiresAt:  expiresAt,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &dto.StreamTicketResponse{
+		Ticket:    ticket,
+		ExpiresIn: int64(time.Until(expiresAt).Seconds()),
+	}, nil
+}
+
+// RedeemStreamTicket consumes a stream ticket and returns the claims it
+// authenticates, or nil if it is unknown, expired or was already redeemed
+func (s *AuthService) RedeemStreamTicket(ctx context.Context, ticket string) (_ jwt.MapClaims, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.RedeemStreamTicket")
+	defer func() { tracing.End(span, err) }()
+
+	record, err := s.tokens.ConsumeStreamTicket(ctx, hashToken(ticket))
+	if err != nil || record == nil {
+		return nil, err
+	}
+	span.SetAttributes(tracing.TenantAttr(record.TenantID), attribute.String("user.id", record.UserID))
+
+	roles := make([]any, len(record.Roles))
+	for i, role := range record.Roles {
+		roles[i] = role
+	}
+	claims := jwt.MapClaims{
+		"tenant_id": record.TenantID,
+		"roles":     roles,
+		"exp":       record.ExpiresAt.Unix(),
+	}
+	if record.UserID != "" {
+		claims["user_id"] = record.UserID
+	}
+	return claims, nil
+}
+
+func (s *AuthService) newRefreshToken(user *domain.User, familyID string) (string, *domain.RefreshToken, error) {
+	buf := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+
+	return token, &domain.RefreshToken{
+		TokenHash: hashToken(token),
+		FamilyID:  familyID,
+		UserID:    user.ID,
+		TenantID:  user.TenantID,
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+	}, nil
+}
+
+func (s *AuthService) tokenResponse(user *domain.User, refreshToken string) (*dto.TokenResponse, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"jti":       uuid.NewString(),
+		"user_id":   user.ID,
+		"tenant_id": user.TenantID,
+		"roles":     []string(user.Roles),
+		"exp":       now.Add(s.accessTTL).Unix(),
+		"iat":       now.Unix(),
+	}
+
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	return &dto.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.accessTTL.Seconds()),
+	}, nil
+}
+
+// hashToken derives the storage key of a refresh token or stream ticket, so a Redis dump doesn't leak usable tokens
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type AuthServiceTestSuite struct {
+	suite.Suite
+	mockRepo   *mocks.Repository
+	mockUser   *mocks.UserRepository
+	mockTokens *mocks.TokenStore
+	service    *AuthService
+	user       *domain.User
+}
+
+func (s *AuthServiceTestSuite) SetupTest() {
+	s.mockRepo = new(mocks.Repository)
+	s.mockUser = new(mocks.UserRepository)
+	s.mockTokens = new(mocks.TokenStore)
+
+	s.mockRepo.On("User").Return(s.mockUser)
+
+	s.service = NewAuthService(s.mockRepo, s.mockTokens, &config.Config{
+		JWTSecretKey:    "test-secret",
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: time.Hour,
+		StreamTicketTTL: 30 * time.Second,
+	})
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct-horse"), bcrypt.MinCost)
+	s.Require().NoError(err)
+	s.user = &domain.User{
+		ID:           "user1",
+		TenantID:     "tenant1",
+		Email:        "jane@example.com",
+		PasswordHash: string(hash),
+		Roles:        domain.StringArray{"admin"},
+		Active:       true,
+	}
+}
+
+func TestAuthService(t *testing.T) {
+	suite.Run(t, new(AuthServiceTestSuite))
+}
+
+func (s *AuthServiceTestSuite) TestIssueToken_Success() {
+	// Arrange
+	ctx := context.Background()
+	s.mockUser.On("GetByEmail", mock.Anything, s.user.Email).Return(s.user, nil)
+	s.mockTokens.On("SaveRefreshToken", mock.Anything, mock.MatchedBy(func(t *domain.RefreshToken) bool {
+		return t.UserID == "user1" && t.TenantID == "tenant1" && t.FamilyID != "" && t.TokenHash != ""
+	})).Return(nil)
+
+	// Act
+	resp, err := s.service.IssueToken(ctx, dto.TokenRequest{Email: s.user.Email, Password: "correct-horse"})
+
+	// Assert
+	s.Require().NoError(err)
+	s.Equal("Bearer", resp.TokenType)
+	s.Equal(int64(900), resp.ExpiresIn)
+	s.NotEmpty(resp.RefreshToken)
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(resp.AccessToken, &claims, func(*jwt.Token) (any, error) {
+		return []byte("test-secret"), nil
+	})
+	s.Require().NoError(err)
+	s.Equal("tenant1", claims["tenant_id"])
+	s.Equal("user1", claims["user_id"])
+	s.Equal([]any{"admin"}, claims["roles"])
+	s.NotEmpty(claims["jti"])
+	s.mockTokens.AssertExpectations(s.T())
+}
+
+func (s *AuthServiceTestSuite) TestIssueToken_WrongPassword() {
+	// Arrange
+	ctx := context.Background()
+	s.mockUser.On("GetByEmail", mock.Anything, s.user.Email).Return(s.user, nil)
+
+	// Act
+	resp, err := s.service.IssueToken(ctx, dto.TokenRequest{Email: s.user.Email, Password: "wrong"})
+
+	// Assert
+	s.ErrorIs(err, ErrInvalidCredentials)
+	s.Nil(resp)
+	s.mockTokens.AssertNotCalled(s.T(), "SaveRefreshToken", mock.Anything, mock.Anything)
+}
+
+func (s *AuthServiceTestSuite) TestIssueToken_UnknownEmail() {
+	// Arrange
+	ctx := context.Background()
+	s.mockUser.On("GetByEmail", mock.Anything, "nobody@example.com").Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	resp, err := s.service.IssueToken(ctx, dto.TokenRequest{Email: "nobody@example.com", Password: "correct-horse"})
+
+	// Assert
+	s.ErrorIs(err, ErrInvalidCredentials)
+	s.Nil(resp)
+}
+
+func (s *AuthServiceTestSuite) TestIssueToken_InactiveUser() {
+	// Arrange
+	ctx := context.Background()
+	s.user.Active = false
+	s.mockUser.On("GetByEmail", mock.Anything, s.user.Email).Return(s.user, nil)
+
+	// Act
+	resp, err := s.service.IssueToken(ctx, dto.TokenRequest{Email: s.user.Email, Password: "correct-horse"})
+
+	// Assert
+	s.ErrorIs(err, ErrUserInactive)
+	s.Nil(resp)
+}
+
+func (s *AuthServiceTestSuite) TestRefresh_RotatesToken() {
+	// Arrange
+	ctx := context.Background()
+	current := &domain.RefreshToken{
+		TokenHash: hashToken("old-token"),
+		FamilyID:  "family1",
+		UserID:    "user1",
+		TenantID:  "tenant1",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	s.mockTokens.On("GetRefreshToken", mock.Anything, hashToken("old-token")).Return(current, nil)
+	s.mockUser.On("GetByID", mock.Anything, "tenant1", "user1").Return(s.user, nil)
+	s.mockTokens.On("RotateRefreshToken", mock.Anything, current, mock.MatchedBy(func(next *domain.RefreshToken) bool {
+		return next.FamilyID == "family1" && next.TokenHash != current.TokenHash
+	})).Return(true, nil)
+
+	// Act
+	resp, err := s.service.Refresh(ctx, "old-token")
+
+	// Assert
+	s.Require().NoError(err)
+	s.NotEqual("old-token", resp.RefreshToken)
+	s.NotEmpty(resp.AccessToken)
+	s.mockTokens.AssertExpectations(s.T())
+}
+
+func (s *AuthServiceTestSuite) TestRefresh_ReusedToken() {
+	// Arrange
+	ctx := context.Background()
+	current := &domain.RefreshToken{TokenHash: hashToken("old-token"), FamilyID: "family1", UserID: "user1", TenantID: "tenant1"}
+	s.mockTokens.On("GetRefreshToken", mock.Anything, hashToken("old-token")).Return(current, nil)
+	s.mockUser.On("GetByID", mock.Anything, "tenant1", "user1").Return(s.user, nil)
+	s.mockTokens.On("RotateRefreshToken", mock.Anything, current, mock.Anything).Return(false, nil)
+
+	// Act
+	resp, err := s.service.Refresh(ctx, "old-token")
+
+	// Assert
+	s.ErrorIs(err, ErrInvalidRefreshToken)
+	s.Nil(resp)
+}
+
+func (s *AuthServiceTestSuite) TestRefresh_UnknownToken() {
+	// Arrange
+	ctx := context.Background()
+	s.mockTokens.On("GetRefreshToken", mock.Anything, hashToken("unknown")).Return(nil, nil)
+
+	// Act
+	resp, err := s.service.Refresh(ctx, "unknown")
+
+	// Assert
+	s.ErrorIs(err, ErrInvalidRefreshToken)
+	s.Nil(resp)
+}
+
+func (s *AuthServiceTestSuite) TestRefresh_DeactivatedUserRevokesFamily() {
+	// Arrange
+	ctx := context.Background()
+	s.user.Active = false
+	current := &domain.RefreshToken{TokenHash: hashToken("old-token"), FamilyID: "family1", UserID: "user1", TenantID: "tenant1"}
+	s.mockTokens.On("GetRefreshToken", mock.Anything, hashToken("old-token")).Return(current, nil)
+	s.mockUser.On("GetByID", mock.Anything, "tenant1", "user1").Return(s.user, nil)
+	s.mockTokens.On("RevokeRefreshFamily", mock.Anything, "family1").Return(nil)
+
+	// Act
+	resp, err := s.service.Refresh(ctx, "old-token")
+
+	// Assert
+	s.ErrorIs(err, ErrInvalidRefreshToken)
+	s.Nil(resp)
+	s.mockTokens.AssertExpectations(s.T())
+}
+
+func (s *AuthServiceTestSuite) TestRevoke_AccessAndRefreshToken() {
+	// Arrange
+	ctx := context.Background()
+	expiresAt := time.Now().Add(10 * time.Minute)
+	record := &domain.RefreshToken{TokenHash: hashToken("refresh"), FamilyID: "family1", UserID: "user1"}
+	s.mockTokens.On("RevokeAccessToken", mock.Anything, "jti1", expiresAt).Return(nil)
+	s.mockTokens.On("GetRefreshToken", mock.Anything, hashToken("refresh")).Return(record, nil)
+	s.mockTokens.On("RevokeRefreshFamily", mock.Anything, "family1").Return(nil)
+
+	// Act
+	err := s.service.Revoke(ctx, "user1", "jti1", expiresAt, "refresh")
+
+	// Assert
+	s.NoError(err)
+	s.mockTokens.AssertExpectations(s.T())
+}
+
+func (s *AuthServiceTestSuite) TestRevoke_OtherUsersRefreshToken() {
+	// Arrange
+	ctx := context.Background()
+	expiresAt := time.Now().Add(10 * time.Minute)
+	record := &domain.RefreshToken{TokenHash: hashToken("refresh"), FamilyID: "family1", UserID: "user2"}
+	s.mockTokens.On("RevokeAccessToken", mock.Anything, "jti1", expiresAt).Return(nil)
+	s.mockTokens.On("GetRefreshToken", mock.Anything, hashToken("refresh")).Return(record, nil)
+
+	// Act
+	err := s.service.Revoke(ctx, "user1", "jti1", expiresAt, "refresh")
+
+	// Assert
+	s.ErrorIs(err, ErrInvalidRefreshToken)
+	s.mockTokens.AssertNotCalled(s.T(), "RevokeRefreshFamily", mock.Anything, mock.Anything)
+}
+
+func (s *AuthServiceTestSuite) TestIssueStreamTicket_BoundToCaller() {
+	// Arrange
+	ctx := context.Background()
+	var saved *domain.StreamTicket
+	s.mockTokens.On("SaveStreamTicket", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { saved = args.Get(1).(*domain.StreamTicket) }).
+		Return(nil)
+	claims := jwt.MapClaims{
+		"user_id":   "user1",
+		"tenant_id": "tenant1",
+		"roles":     []any{"auditor"},
+		"exp":       float64(time.Now().Add(10 * time.Second).Unix()),
+	}
+
+	// Act
+	resp, err := s.service.IssueStreamTicket(ctx, claims)
+
+	// Assert
+	s.Require().NoError(err)
+	s.NotEmpty(resp.Ticket)
+	s.Equal(hashToken(resp.Ticket), saved.TicketHash)
+	s.Equal("tenant1", saved.TenantID)
+	s.Equal("user1", saved.UserID)
+	s.Equal([]string{"auditor"}, saved.Roles)
+	// Capped by the token's expiry rather than the 30s ticket TTL
+	s.LessOrEqual(resp.ExpiresIn, int64(10))
+}
+
+func (s *AuthServiceTestSuite) TestIssueStreamTicket_NoTenant() {
+	// Arrange
+	ctx := context.Background()
+
+	// Act
+	resp, err := s.service.IssueStreamTicket(ctx, jwt.MapClaims{"user_id": "user1"})
+
+	// Assert
+	s.ErrorIs(err, ErrNoTenantInToken)
+	s.Nil(resp)
+	s.mockTokens.AssertNotCalled(s.T(), "SaveStreamTicket", mock.Anything, mock.Anything)
+}
+
+func (s *AuthServiceTestSuite) TestRedeemStreamTicket_Success() {
+	// Arrange
+	ctx := context.Background()
+	record := &domain.StreamTicket{
+		TicketHash: hashToken("ticket"),
+		TenantID:   "tenant1",
+		UserID:     "user1",
+		Roles:      []string{"auditor"},
+		ExpiresAt:  time.Now().Add(30 * time.Second),
+	}
+	s.mockTokens.On("ConsumeStreamTicket", mock.Anything, hashToken("ticket")).Return(record, nil)
+
+	// Act
+	claims, err := s.service.RedeemStreamTicket(ctx, "ticket")
+
+	// Assert
+	s.Require().NoError(err)
+	s.Equal("tenant1", claims["tenant_id"])
+	s.Equal("user1", claims["user_id"])
+	s.Equal([]any{"auditor"}, claims["roles"])
+}
+
+func (s *AuthServiceTestSuite) TestRedeemStreamTicket_Unknown() {
+	// Arrange
+	ctx := context.Background()
+	s.mockTokens.On("ConsumeStreamTicket", mock.Anything, hashToken("used")).Return(nil, nil)
+
+	// Act
+	claims, err := s.service.RedeemStreamTicket(ctx, "used")
+
+	// Assert
+	s.NoError(err)
+	s.Nil(claims)
+}
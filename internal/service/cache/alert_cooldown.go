@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const alertCooldownPrefix = "alert_cooldown:"
+
+// AlertCooldown marks the alerts raised recently in Redis, shared by every
+// detection worker, so the same alert isn't raised again until its cooldown
+// expires
+type AlertCooldown struct {
+	client *redis.Client
+}
+
+func NewAlertCooldown(client *redis.Client) *AlertCooldown {
+	return &AlertCooldown{client: client}
+}
+
+// Acquire starts the cooldown of key, reporting false if it was already cooling down
+func (c *AlertCooldown) Acquire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	acquired, err := c.client.SetNX(ctx, alertCooldownPrefix+key, 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire alert cooldown: %w", err)
+	}
+	return acquired, nil
+}
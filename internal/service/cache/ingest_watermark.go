@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const ingestWatermarkPrefix = "ingest_watermark:"
+
+// IngestWatermark counts how often each tenant's logs were stored. Responses
+// derived from a tenant's logs are tagged and cached by its watermark, so
+// storing logs changes their ETags and invalidates their cached stats at once.
+type IngestWatermark struct {
+	client *redis.Client
+}
+
+func NewIngestWatermark(client *redis.Client) *IngestWatermark {
+	return &IngestWatermark{client: client}
+}
+
+func (w *IngestWatermark) key(tenantID string) string {
+	return ingestWatermarkPrefix + tenantID
+}
+
+// Get returns the tenant's watermark, 0 if none of its logs were stored yet
+func (w *IngestWatermark) Get(ctx context.Context, tenantID string) (int64, error) {
+	watermark, err := w.client.Get(ctx, w.key(tenantID)).Int64()
+	if err != nil && err != redis.Nil {
+		return 0, fmt.Errorf("failed to get ingest watermark: %w", err)
+	}
+	return watermark, nil
+}
+
+// Advance moves the watermarks of the tenants whose logs were stored
+func (w *IngestWatermark) Advance(ctx context.Context, tenantIDs ...string) error {
+	pipe := w.client.Pipeline()
+	for _, tenantID := range tenantIDs {
+		pipe.Incr(ctx, w.key(tenantID))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to advance ingest watermark: %w", err)
+	}
+	return nil
+}
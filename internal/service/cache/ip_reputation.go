@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const ipReputationKeyPrefix = "ip_reputation:"
+
+// IPReputationCache keeps the scores IP reputation providers returned in
+// Redis, shared by every instance, so an address is looked up at most once
+// per ttl
+type IPReputationCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewIPReputationCache(client *redis.Client, ttl time.Duration) *IPReputationCache {
+	return &IPReputationCache{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+func (c *IPReputationCache) key(provider string, ip netip.Addr) string {
+	return ipReputationKeyPrefix + provider + ":" + ip.String()
+}
+
+func (c *IPReputationCache) Get(ctx context.Context, provider string, ip netip.Addr) (int, bool, error) {
+	score, err := c.client.Get(ctx, c.key(provider, ip)).Int()
+	if err == redis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get cached IP reputation: %w", err)
+	}
+	return score, true, nil
+}
+
+func (c *IPReputationCache) Set(ctx context.Context, provider string, ip netip.Addr, score int) error {
+	if err := c.client.Set(ctx, c.key(provider, ip), score, c.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to cache IP reputation: %w", err)
+	}
+	return nil
+}
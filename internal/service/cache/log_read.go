@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+const (
+	logKeyPrefix      = "log:"
+	logStatsKeyPrefix = "log_stats:"
+)
+
+// LogReadCache keeps the logs and stats read by polling dashboards in Redis
+// for a short ttl, taking their load off the reader database. Stats are keyed
+// by the filter and the tenant's ingest watermark, so advancing the watermark
+// invalidates every cached filter of the tenant.
+type LogReadCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewLogReadCache(client *redis.Client, ttl time.Duration) *LogReadCache {
+	return &LogReadCache{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+func (c *LogReadCache) logKey(tenantID, id string) string {
+	return logKeyPrefix + tenantID + ":" + id
+}
+
+// GetLog returns the cached log, or nil if it is not cached
+func (c *LogReadCache) GetLog(ctx context.Context, tenantID, id string) (*domain.AuditLog, error) {
+	var log domain.AuditLog
+	if ok, err := c.get(ctx, c.logKey(tenantID, id), &log); !ok {
+		return nil, err
+	}
+	return &log, nil
+}
+
+func (c *LogReadCache) SetLog(ctx context.Context, log *domain.AuditLog) error {
+	return c.set(ctx, c.logKey(log.TenantID, log.ID), log)
+}
+
+// StatsKey returns the key of the filter's stats at the tenant's watermark.
+// Compute the stats only after reading the watermark, so stats of logs stored
+// meanwhile are never cached under the newer one.
+func (c *LogReadCache) StatsKey(filter *domain.AuditLogFilter, watermark int64) string {
+	data, _ := json.Marshal(filter)
+	hash := sha256.Sum256(data)
+
+	return logStatsKeyPrefix + filter.TenantID + ":" + strconv.FormatInt(watermark, 10) + ":" + hex.EncodeToString(hash[:16])
+}
+
+// GetStats returns the stats cached under key, or nil if there are none
+func (c *LogReadCache) GetStats(ctx context.Context, key string) (*domain.AuditLogStats, error) {
+	var stats domain.AuditLogStats
+	if ok, err := c.get(ctx, key, &stats); !ok {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+func (c *LogReadCache) SetStats(ctx context.Context, key string, stats *domain.AuditLogStats) error {
+	return c.set(ctx, key, stats)
+}
+
+// get decodes the value of key into value, reporting whether it was cached
+func (c *LogReadCache) get(ctx context.Context, key string, value any) (bool, error) {
+	data, err := c.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get cached value: %w", err)
+	}
+
+	if err := json.Unmarshal(data, value); err != nil {
+		return false, fmt.Errorf("failed to unmarshal cached value: %w", err)
+	}
+	return true, nil
+}
+
+func (c *LogReadCache) set(ctx context.Context, key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cached value: %w", err)
+	}
+
+	if err := c.client.Set(ctx, key, data, c.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to cache value: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+const policyKeyPrefix = "policies:tenant:"
+
+// PolicyCache keeps each tenant's policies in Redis so authorizing a request
+// doesn't hit PostgreSQL. Entries expire after ttl, which bounds how long
+// other API instances can enforce outdated policies.
+type PolicyCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewPolicyCache(client *redis.Client, ttl time.Duration) *PolicyCache {
+	return &PolicyCache{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+func (c *PolicyCache) key(tenantID string) string {
+	return policyKeyPrefix + tenantID
+}
+
+// Get returns the cached policies, or nil if the tenant is not cached. A
+// tenant without policies is cached as an empty, non-nil slice.
+func (c *PolicyCache) Get(ctx context.Context, tenantID string) ([]domain.Policy, error) {
+	data, err := c.client.Get(ctx, c.key(tenantID)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached policies: %w", err)
+	}
+
+	policies := []domain.Policy{}
+	if err := json.Unmarshal(data, &policies); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cached policies: %w", err)
+	}
+
+	return policies, nil
+}
+
+func (c *PolicyCache) Set(ctx context.Context, tenantID string, policies []domain.Policy) error {
+	if policies == nil {
+		policies = []domain.Policy{}
+	}
+
+	data, err := json.Marshal(policies)
+	if err != nil {
+		return fmt.Errorf("failed to marshal policies: %w", err)
+	}
+
+	if err := c.client.Set(ctx, c.key(tenantID), data, c.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to cache policies: %w", err)
+	}
+
+	return nil
+}
+
+func (c *PolicyCache) Invalidate(ctx context.Context, tenantID string) error {
+	if err := c.client.Del(ctx, c.key(tenantID)).Err(); err != nil {
+		return fmt.Errorf("failed to invalidate cached policies: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+const rateLimitKeyPrefix = "rate_limit:config:"
+
+// RateLimitCache keeps tenant rate limits in Redis so the rate limit middleware
+// doesn't hit PostgreSQL on every request. Entries expire after ttl, which
+// bounds how long other API instances can serve a stale limit.
+type RateLimitCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewRateLimitCache(client *redis.Client, ttl time.Duration) *RateLimitCache {
+	return &RateLimitCache{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+func (c *RateLimitCache) key(tenantID string) string {
+	return rateLimitKeyPrefix + tenantID
+}
+
+// Get returns the cached limit, or nil if the tenant is not cached
+func (c *RateLimitCache) Get(ctx context.Context, tenantID string) (*domain.TenantRateLimit, error) {
+	data, err := c.client.Get(ctx, c.key(tenantID)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached rate limit: %w", err)
+	}
+
+	var limit domain.TenantRateLimit
+	if err := json.Unmarshal(data, &limit); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cached rate limit: %w", err)
+	}
+
+	return &limit, nil
+}
+
+func (c *RateLimitCache) Set(ctx context.Context, limit *domain.TenantRateLimit) error {
+	data, err := json.Marshal(limit)
+	if err != nil {
+		return fmt.Errorf("failed to marshal rate limit: %w", err)
+	}
+
+	if err := c.client.Set(ctx, c.key(limit.TenantID), data, c.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to cache rate limit: %w", err)
+	}
+
+	return nil
+}
+
+func (c *RateLimitCache) Invalidate(ctx context.Context, tenantID string) error {
+	if err := c.client.Del(ctx, c.key(tenantID)).Err(); err != nil {
+		return fmt.Errorf("failed to invalidate cached rate limit: %w", err)
+	}
+
+	return nil
+}
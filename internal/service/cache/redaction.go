@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+const redactionRuleKeyPrefix = "redaction_rules:tenant:"
+
+// RedactionRuleCache keeps each tenant's redaction rules in Redis so ingesting
+// a log doesn't hit PostgreSQL. Entries expire after ttl, which bounds how long
+// other API instances can apply outdated rules.
+type RedactionRuleCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewRedactionRuleCache(client *redis.Client, ttl time.Duration) *RedactionRuleCache {
+	return &RedactionRuleCache{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+func (c *RedactionRuleCache) key(tenantID string) string {
+	return redactionRuleKeyPrefix + tenantID
+}
+
+// Get returns the cached rules, or nil if the tenant is not cached. A
+// tenant without rules is cached as an empty, non-nil slice.
+func (c *RedactionRuleCache) Get(ctx context.Context, tenantID string) ([]domain.RedactionRule, error) {
+	data, err := c.client.Get(ctx, c.key(tenantID)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached redaction rules: %w", err)
+	}
+
+	rules := []domain.RedactionRule{}
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cached redaction rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+func (c *RedactionRuleCache) Set(ctx context.Context, tenantID string, rules []domain.RedactionRule) error {
+	if rules == nil {
+		rules = []domain.RedactionRule{}
+	}
+
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return fmt.Errorf("failed to marshal redaction rules: %w", err)
+	}
+
+	if err := c.client.Set(ctx, c.key(tenantID), data, c.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to cache redaction rules: %w", err)
+	}
+
+	return nil
+}
+
+func (c *RedactionRuleCache) Invalidate(ctx context.Context, tenantID string) error {
+	if err := c.client.Del(ctx, c.key(tenantID)).Err(); err != nil {
+		return fmt.Errorf("failed to invalidate cached redaction rules: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+const resourceSchemaKeyPrefix = "resource_schemas:tenant:"
+
+// ResourceSchemaCache holds the schemas every ingested log is checked against, per tenant, for ttl
+type ResourceSchemaCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewResourceSchemaCache(client *redis.Client, ttl time.Duration) *ResourceSchemaCache {
+	return &ResourceSchemaCache{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+func (c *ResourceSchemaCache) key(tenantID string) string {
+	return resourceSchemaKeyPrefix + tenantID
+}
+
+// Get returns the cached schemas, or nil if the tenant is not cached. A
+// tenant without schemas is cached as an empty, non-nil slice.
+func (c *ResourceSchemaCache) Get(ctx context.Context, tenantID string) ([]domain.ResourceSchema, error) {
+	data, err := c.client.Get(ctx, c.key(tenantID)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached resource schemas: %w", err)
+	}
+
+	schemas := []domain.ResourceSchema{}
+	if err := json.Unmarshal(data, &schemas); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cached resource schemas: %w", err)
+	}
+
+	return schemas, nil
+}
+
+func (c *ResourceSchemaCache) Set(ctx context.Context, tenantID string, schemas []domain.ResourceSchema) error {
+	if schemas == nil {
+		schemas = []domain.ResourceSchema{}
+	}
+
+	data, err := json.Marshal(schemas)
+	if err != nil {
+		return fmt.Errorf("failed to marshal resource schemas: %w", err)
+	}
+
+	if err := c.client.Set(ctx, c.key(tenantID), data, c.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to cache resource schemas: %w", err)
+	}
+
+	return nil
+}
+
+func (c *ResourceSchemaCache) Invalidate(ctx context.Context, tenantID string) error {
+	if err := c.client.Del(ctx, c.key(tenantID)).Err(); err != nil {
+		return fmt.Errorf("failed to invalidate cached resource schemas: %w", err)
+	}
+
+	return nil
+}
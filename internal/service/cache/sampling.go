@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+const (
+	samplingKeyPrefix     = "sampling:tenant:"
+	samplingTotalField    = "total"
+	samplingActionField   = "action:"
+	samplingSeverityField = "severity:"
+)
+
+// SampledLogCounter counts the logs each tenant's sampling rules drop per UTC
+// day in Redis hashes, by action and by severity, kept as long as the usage
+// counters
+type SampledLogCounter struct {
+	client *redis.Client
+}
+
+func NewSampledLogCounter(client *redis.Client) *SampledLogCounter {
+	return &SampledLogCounter{client: client}
+}
+
+func (c *SampledLogCounter) key(tenantID string, day time.Time) string {
+	return samplingKeyPrefix + tenantID + ":" + day.UTC().Format(usageDayLayout)
+}
+
+// Add counts logs of the tenant dropped on day
+func (c *SampledLogCounter) Add(ctx context.Context, tenantID string, day time.Time, logs []domain.AuditLog) error {
+	key := c.key(tenantID, day)
+
+	pipe := c.client.TxPipeline()
+	pipe.HIncrBy(ctx, key, samplingTotalField, int64(len(logs)))
+	for i := range logs {
+		pipe.HIncrBy(ctx, key, samplingActionField+logs[i].Action, 1)
+		pipe.HIncrBy(ctx, key, samplingSeverityField+logs[i].Severity, 1)
+	}
+	pipe.Expire(ctx, key, usageRetention)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to count sampled logs: %w", err)
+	}
+
+	return nil
+}
+
+// Sum adds up the logs of the tenant dropped on every UTC day from from to
+// to, inclusive
+func (c *SampledLogCounter) Sum(ctx context.Context, tenantID string, from, to time.Time) (*domain.SampledLogCounts, error) {
+	from = from.UTC().Truncate(24 * time.Hour)
+	to = to.UTC().Truncate(24 * time.Hour)
+	// Older counters have expired
+	if oldest := to.Add(-usageRetention); from.Before(oldest) {
+		from = oldest
+	}
+
+	pipe := c.client.Pipeline()
+	var cmds []*redis.MapStringStringCmd
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		cmds = append(cmds, pipe.HGetAll(ctx, c.key(tenantID, day)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to get sampled logs: %w", err)
+	}
+
+	counts := &domain.SampledLogCounts{
+		ActionCounts:   make(map[string]int64),
+		SeverityCounts: make(map[string]int64),
+	}
+	for _, cmd := range cmds {
+		for field, value := range cmd.Val() {
+			count, _ := strconv.ParseInt(value, 10, 64)
+			switch {
+			case field == samplingTotalField:
+				counts.TotalLogs += count
+			case strings.HasPrefix(field, samplingActionField):
+				counts.ActionCounts[strings.TrimPrefix(field, samplingActionField)] += count
+			case strings.HasPrefix(field, samplingSeverityField):
+				counts.SeverityCounts[strings.TrimPrefix(field, samplingSeverityField)] += count
+			}
+		}
+	}
+
+	return counts, nil
+}
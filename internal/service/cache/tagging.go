@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+const taggingRuleKeyPrefix = "tagging_rules:tenant:"
+
+// TaggingRuleCache holds each tenant's tagging rules for ingest, expiring them after ttl like RedactionRuleCache
+type TaggingRuleCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewTaggingRuleCache(client *redis.Client, ttl time.Duration) *TaggingRuleCache {
+	return &TaggingRuleCache{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+func (c *TaggingRuleCache) key(tenantID string) string {
+	return taggingRuleKeyPrefix + tenantID
+}
+
+// Get returns the cached rules, or nil if the tenant is not cached. A
+// tenant without rules is cached as an empty, non-nil slice.
+func (c *TaggingRuleCache) Get(ctx context.Context, tenantID string) ([]domain.TaggingRule, error) {
+	data, err := c.client.Get(ctx, c.key(tenantID)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached tagging rules: %w", err)
+	}
+
+	rules := []domain.TaggingRule{}
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cached tagging rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+func (c *TaggingRuleCache) Set(ctx context.Context, tenantID string, rules []domain.TaggingRule) error {
+	if rules == nil {
+		rules = []domain.TaggingRule{}
+	}
+
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tagging rules: %w", err)
+	}
+
+	if err := c.client.Set(ctx, c.key(tenantID), data, c.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to cache tagging rules: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TaggingRuleCache) Invalidate(ctx context.Context, tenantID string) error {
+	if err := c.client.Del(ctx, c.key(tenantID)).Err(); err != nil {
+		return fmt.Errorf("failed to invalidate cached tagging rules: %w", err)
+	}
+
+	return nil
+}
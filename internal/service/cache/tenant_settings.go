@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+const tenantSettingsKeyPrefix = "tenant_settings:"
+
+// TenantSettingsCache keeps tenant settings in Redis so the ingest middleware
+// doesn't hit PostgreSQL on every request. Entries expire after ttl, which
+// bounds how long other API instances can apply outdated settings.
+type TenantSettingsCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewTenantSettingsCache(client *redis.Client, ttl time.Duration) *TenantSettingsCache {
+	return &TenantSettingsCache{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+func (c *TenantSettingsCache) key(tenantID string) string {
+	return tenantSettingsKeyPrefix + tenantID
+}
+
+// Get returns the cached settings, or nil if the tenant is not cached
+func (c *TenantSettingsCache) Get(ctx context.Context, tenantID string) (*domain.TenantSettings, error) {
+	data, err := c.client.Get(ctx, c.key(tenantID)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached tenant settings: %w", err)
+	}
+
+	var settings domain.TenantSettings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cached tenant settings: %w", err)
+	}
+
+	return &settings, nil
+}
+
+func (c *TenantSettingsCache) Set(ctx context.Context, tenantID string, settings *domain.TenantSettings) error {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tenant settings: %w", err)
+	}
+
+	if err := c.client.Set(ctx, c.key(tenantID), data, c.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to cache tenant settings: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TenantSettingsCache) Invalidate(ctx context.Context, tenantID string) error {
+	if err := c.client.Del(ctx, c.key(tenantID)).Err(); err != nil {
+		return fmt.Errorf("failed to invalidate cached tenant settings: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+const (
+	refreshTokenKeyPrefix  = "auth:refresh:token:"
+	refreshFamilyKeyPrefix = "auth:refresh:family:"
+	revokedTokenKeyPrefix  = "auth:revoked:"
+	streamTicketKeyPrefix  = "auth:stream_ticket:"
+)
+
+// rotateRefreshTokenScript moves a family to a new token only if the presented
+// token is still the family's current one. Otherwise the token was already
+// rotated, which means it leaked, so the family is revoked.
+var rotateRefreshTokenScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+	return 1
+end
+redis.call('DEL', KEYS[1])
+return 0
+`)
+
+// TokenStore keeps refresh tokens and the access token revocation list in Redis.
+// Keys expire with the tokens they describe, so the store never needs pruning.
+type TokenStore struct {
+	client *redis.Client
+}
+
+func NewTokenStore(client *redis.Client) *TokenStore {
+	return &TokenStore{client: client}
+}
+
+func (s *TokenStore) tokenKey(tokenHash string) string {
+	return refreshTokenKeyPrefix + tokenHash
+}
+
+func (s *TokenStore) familyKey(familyID string) string {
+	return refreshFamilyKeyPrefix + familyID
+}
+
+// SaveRefreshToken stores a token and makes it the current token of its family
+func (s *TokenStore) SaveRefreshToken(ctx context.Context, token *domain.RefreshToken) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("failed to marshal refresh token: %w", err)
+	}
+
+	ttl := time.Until(token.ExpiresAt)
+	pipe := s.client.TxPipeline()
+	pipe.Set(ctx, s.tokenKey(token.TokenHash), data, ttl)
+	pipe.Set(ctx, s.familyKey(token.FamilyID), token.TokenHash, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save refresh token: %w", err)
+	}
+
+	return nil
+}
+
+// GetRefreshToken returns the stored token, or nil if it is unknown or expired
+func (s *TokenStore) GetRefreshToken(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	data, err := s.client.Get(ctx, s.tokenKey(tokenHash)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	var token domain.RefreshToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal refresh token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// RotateRefreshToken replaces current with next as the family's valid token.
+// It returns false, revoking the family, if current was already rotated.
+func (s *TokenStore) RotateRefreshToken(ctx context.Context, current, next *domain.RefreshToken) (bool, error) {
+	rotated, err := rotateRefreshTokenScript.Run(ctx, s.client,
+		[]string{s.familyKey(current.FamilyID)},
+		current.TokenHash, next.TokenHash, time.Until(next.ExpiresAt).Milliseconds(),
+	).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if rotated == 0 {
+		return false, nil
+	}
+
+	// The rotated token is kept until it expires so that its reuse is detected
+	if err := s.SaveRefreshToken(ctx, next); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// RevokeRefreshFamily invalidates every refresh token issued from the same login
+func (s *TokenStore) RevokeRefreshFamily(ctx context.Context, familyID string) error {
+	if err := s.client.Del(ctx, s.familyKey(familyID)).Err(); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeAccessToken adds an access token ID to the revocation list until the token expires
+func (s *TokenStore) RevokeAccessToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	if err := s.client.Set(ctx, revokedTokenKeyPrefix+tokenID, 1, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+
+	return nil
+}
+
+func (s *TokenStore) IsAccessTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	n, err := s.client.Exists(ctx, revokedTokenKeyPrefix+tokenID).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check access token revocation: %w", err)
+	}
+
+	return n > 0, nil
+}
+
+// SaveStreamTicket stores a stream ticket until it expires
+func (s *TokenStore) SaveStreamTicket(ctx context.Context, ticket *domain.StreamTicket) error {
+	data, err := json.Marshal(ticket)
+	if err != nil {
+		return fmt.Errorf("failed to marshal stream ticket: %w", err)
+	}
+
+	if err := s.client.Set(ctx, streamTicketKeyPrefix+ticket.TicketHash, data, time.Until(ticket.ExpiresAt)).Err(); err != nil {
+		return fmt.Errorf("failed to save stream ticket: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeStreamTicket returns a stored ticket and deletes it in the same step,
+// so a ticket opens one stream at most. It returns nil if the ticket is
+// unknown, expired or already used.
+func (s *TokenStore) ConsumeStreamTicket(ctx context.Context, ticketHash string) (*domain.StreamTicket, error) {
+	data, err := s.client.GetDel(ctx, streamTicketKeyPrefix+ticketHash).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume stream ticket: %w", err)
+	}
+
+	var ticket domain.StreamTicket
+	if err := json.Unmarshal(data, &ticket); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal stream ticket: %w", err)
+	}
+
+	return &ticket, nil
+}
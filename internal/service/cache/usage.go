@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+const (
+	usageKeyPrefix = "usage:tenant:"
+	usageDayLayout = "2006-01-02"
+	// usageRetention keeps a little over a year of daily counters for reports
+	usageRetention = 400 * 24 * time.Hour
+
+	quotaWarningKeyPrefix = "usage:warning:"
+	// quotaWarningRetention outlives the longest quota period, a month
+	quotaWarningRetention = 32 * 24 * time.Hour
+)
+
+// UsageCounter counts the logs and bytes each tenant ingests per UTC day in
+// Redis hashes, so quota checks don't scan PostgreSQL
+type UsageCounter struct {
+	client *redis.Client
+}
+
+func NewUsageCounter(client *redis.Client) *UsageCounter {
+	return &UsageCounter{client: client}
+}
+
+func (c *UsageCounter) key(tenantID string, day time.Time) string {
+	return usageKeyPrefix + tenantID + ":" + day.UTC().Format(usageDayLayout)
+}
+
+// Add increments the tenant's counters for day
+func (c *UsageCounter) Add(ctx context.Context, tenantID string, day time.Time, logs, bytes int64) error {
+	key := c.key(tenantID, day)
+
+	pipe := c.client.TxPipeline()
+	pipe.HIncrBy(ctx, key, "logs", logs)
+	pipe.HIncrBy(ctx, key, "bytes", bytes)
+	pipe.Expire(ctx, key, usageRetention)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record usage: %w", err)
+	}
+
+	return nil
+}
+
+// Days returns the tenant's usage for every UTC day from from to to,
+// inclusive, with zero usage for days without counters
+func (c *UsageCounter) Days(ctx context.Context, tenantID string, from, to time.Time) ([]domain.TenantUsage, error) {
+	from = from.UTC().Truncate(24 * time.Hour)
+	to = to.UTC().Truncate(24 * time.Hour)
+
+	var days []time.Time
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+
+	pipe := c.client.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(days))
+	for i, day := range days {
+		cmds[i] = pipe.HGetAll(ctx, c.key(tenantID, day))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to get usage: %w", err)
+	}
+
+	usage := make([]domain.TenantUsage, len(days))
+	for i, day := range days {
+		counters := cmds[i].Val()
+		logs, _ := strconv.ParseInt(counters["logs"], 10, 64)
+		bytes, _ := strconv.ParseInt(counters["bytes"], 10, 64)
+		usage[i] = domain.TenantUsage{Day: day, Logs: logs, Bytes: bytes}
+	}
+
+	return usage, nil
+}
+
+func (c *UsageCounter) warningKey(tenantID string, quota domain.Quota, period string) string {
+	return quotaWarningKeyPrefix + tenantID + ":" + string(quota) + ":" + period
+}
+
+// MarkQuotaWarned records that the tenant was warned about the quota for
+// period, reporting false if it already was
+func (c *UsageCounter) MarkQuotaWarned(ctx context.Context, tenantID string, quota domain.Quota, period string) (bool, error) {
+	marked, err := c.client.SetNX(ctx, c.warningKey(tenantID, quota, period), 1, quotaWarningRetention).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to mark quota warning: %w", err)
+	}
+	return marked, nil
+}
+
+// UnmarkQuotaWarned forgets a warning that couldn't be delivered, so it is retried
+func (c *UsageCounter) UnmarkQuotaWarned(ctx context.Context, tenantID string, quota domain.Quota, period string) error {
+	if err := c.client.Del(ctx, c.warningKey(tenantID, quota, period)).Err(); err != nil {
+		return fmt.Errorf("failed to unmark quota warning: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"database/sql"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+// DBPoolService reports the connection pools of the instance's PostgreSQL
+// writer and readers, and resizes them under load without a restart. Resized
+// pools keep their size until the instance restarts.
+type DBPoolService struct {
+	logger *logger.Logger
+
+	mu    sync.Mutex
+	pools map[string]*dbPool
+
+	shutdownChan chan struct{}
+	waitGroup    sync.WaitGroup
+}
+
+// dbPool is a connection pool and the limits it was last set to, which
+// sql.DB doesn't report
+type dbPool struct {
+	db     *sql.DB
+	limits config.ConnectionPoolConfig
+}
+
+func NewDBPoolService(pools map[string]*sql.DB, poolConfig *config.ConnectionPoolConfig, logger *logger.Logger) *DBPoolService {
+	s := &DBPoolService{
+		logger:       logger,
+		pools:        make(map[string]*dbPool, len(pools)),
+		shutdownChan: make(chan struct{}),
+	}
+	for name, db := range pools {
+		s.pools[name] = &dbPool{db: db, limits: *poolConfig}
+	}
+	return s
+}
+
+// List returns every pool, by name
+func (s *DBPoolService) List() []dto.DBPoolResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.pools))
+	for name := range s.pools {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	pools := make([]dto.DBPoolResponse, len(names))
+	for i, name := range names {
+		pools[i] = s.pools[name].response(name)
+	}
+	return pools
+}
+
+// Update sets the limits of the named pool that req sets. Lowering them
+// closes the connections over the new limits as they are released.
+func (s *DBPoolService) Update(name string, req *dto.UpdateDBPoolRequest) (*dto.DBPoolResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pool, ok := s.pools[name]
+	if !ok {
+		return nil, ErrDBPoolNotFound
+	}
+
+	limits := pool.limits
+	if req.MaxOpenConns != nil {
+		limits.MaxOpenConns = *req.MaxOpenConns
+	}
+	if req.MaxIdleConns != nil {
+		limits.MaxIdleConns = *req.MaxIdleConns
+	}
+	if req.ConnMaxLifetime != "" {
+		lifetime, err := time.ParseDuration(req.ConnMaxLifetime)
+		if err != nil || lifetime < 0 {
+			return nil, ErrInvalidDBPoolSettings
+		}
+		limits.ConnMaxLifetime = lifetime
+	}
+	if limits.MaxIdleConns > limits.MaxOpenConns {
+		return nil, ErrInvalidDBPoolSettings
+	}
+
+	pool.db.SetMaxOpenConns(limits.MaxOpenConns)
+	pool.db.SetMaxIdleConns(limits.MaxIdleConns)
+	pool.db.SetConnMaxLifetime(limits.ConnMaxLifetime)
+	pool.limits = limits
+
+	s.logger.Info("Database pool resized",
+		zap.String("pool", name),
+		zap.Int("max_open_conns", limits.MaxOpenConns),
+		zap.Int("max_idle_conns", limits.MaxIdleConns),
+		zap.Duration("conn_max_lifetime", limits.ConnMaxLifetime),
+	)
+
+	response := pool.response(name)
+	return &response, nil
+}
+
+// Start logs the statistics of every pool each interval until Stop
+func (s *DBPoolService) Start(interval time.Duration) {
+	s.waitGroup.Add(1)
+	go func() {
+		defer s.waitGroup.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.shutdownChan:
+				return
+			case <-ticker.C:
+				s.logStats()
+			}
+		}
+	}()
+}
+
+func (s *DBPoolService) Stop() {
+	close(s.shutdownChan)
+	s.waitGroup.Wait()
+}
+
+func (s *DBPoolService) logStats() {
+	for _, pool := range s.List() {
+		s.logger.Info("Database pool stats",
+			zap.String("pool", pool.Name),
+			zap.Int("max_open_conns", pool.MaxOpenConns),
+			zap.Int("open_connections", pool.OpenConnections),
+			zap.Int("in_use", pool.InUse),
+			zap.Int("idle", pool.Idle),
+			zap.Int64("wait_count", pool.WaitCount),
+			zap.String("wait_duration", pool.WaitDuration),
+		)
+	}
+}
+
+func (p *dbPool) response(name string) dto.DBPoolResponse {
+	stats := p.db.Stats()
+	return dto.DBPoolResponse{
+		Name:              name,
+		MaxOpenConns:      stats.MaxOpenConnections,
+		MaxIdleConns:      p.limits.MaxIdleConns,
+		ConnMaxLifetime:   p.limits.ConnMaxLifetime.String(),
+		OpenConnections:   stats.OpenConnections,
+		InUse:             stats.InUse,
+		Idle:              stats.Idle,
+		WaitCount:         stats.WaitCount,
+		WaitDuration:      stats.WaitDuration.String(),
+		MaxIdleClosed:     stats.MaxIdleClosed,
+		MaxLifetimeClosed: stats.MaxLifetimeClosed,
+	}
+}
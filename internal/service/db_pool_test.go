@@ -0,0 +1,114 @@
+package service
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+type DBPoolServiceTestSuite struct {
+	suite.Suite
+	writer  *sql.DB
+	service *DBPoolService
+}
+
+func (s *DBPoolServiceTestSuite) SetupTest() {
+	// sql.Open doesn't connect, so the pool is never dialed
+	writer, err := sql.Open("pgx", "postgres://localhost:1/audit_logs")
+	s.Require().NoError(err)
+	writer.SetMaxOpenConns(25)
+	writer.SetMaxIdleConns(5)
+	s.writer = writer
+
+	poolConfig := &config.ConnectionPoolConfig{MaxOpenConns: 25, MaxIdleConns: 5, ConnMaxLifetime: time.Hour}
+	s.service = NewDBPoolService(map[string]*sql.DB{"writer": writer}, poolConfig, logger.NewLogger("test"))
+}
+
+func (s *DBPoolServiceTestSuite) TearDownTest() {
+	s.writer.Close()
+}
+
+func TestDBPoolService(t *testing.T) {
+	suite.Run(t, new(DBPoolServiceTestSuite))
+}
+
+func (s *DBPoolServiceTestSuite) TestUpdate_AppliesLimits() {
+	// Arrange
+	maxOpen, maxIdle := 50, 10
+	req := &dto.UpdateDBPoolRequest{MaxOpenConns: &maxOpen, MaxIdleConns: &maxIdle, ConnMaxLifetime: "30m"}
+
+	// Act
+	pool, err := s.service.Update("writer", req)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(50, pool.MaxOpenConns)
+	s.Equal(10, pool.MaxIdleConns)
+	s.Equal("30m0s", pool.ConnMaxLifetime)
+	s.Equal(50, s.writer.Stats().MaxOpenConnections)
+}
+
+func (s *DBPoolServiceTestSuite) TestUpdate_KeepsUnsetLimits() {
+	// Arrange
+	maxIdle := 20
+
+	// Act
+	pool, err := s.service.Update("writer", &dto.UpdateDBPoolRequest{MaxIdleConns: &maxIdle})
+
+	// Assert
+	s.NoError(err)
+	s.Equal(25, pool.MaxOpenConns)
+	s.Equal(20, pool.MaxIdleConns)
+	s.Equal("1h0m0s", pool.ConnMaxLifetime)
+}
+
+func (s *DBPoolServiceTestSuite) TestUpdate_MoreIdleThanOpen() {
+	// Arrange
+	maxIdle := 30
+
+	// Act
+	pool, err := s.service.Update("writer", &dto.UpdateDBPoolRequest{MaxIdleConns: &maxIdle})
+
+	// Assert
+	s.ErrorIs(err, ErrInvalidDBPoolSettings)
+	s.Nil(pool)
+	s.Equal(25, s.writer.Stats().MaxOpenConnections)
+}
+
+func (s *DBPoolServiceTestSuite) TestUpdate_InvalidLifetime() {
+	// Act
+	pool, err := s.service.Update("writer", &dto.UpdateDBPoolRequest{ConnMaxLifetime: "soon"})
+
+	// Assert
+	s.ErrorIs(err, ErrInvalidDBPoolSettings)
+	s.Nil(pool)
+}
+
+func (s *DBPoolServiceTestSuite) TestUpdate_UnknownPool() {
+	// Arrange
+	maxOpen := 10
+
+	// Act
+	pool, err := s.service.Update("reader_3", &dto.UpdateDBPoolRequest{MaxOpenConns: &maxOpen})
+
+	// Assert
+	s.ErrorIs(err, ErrDBPoolNotFound)
+	s.Nil(pool)
+}
+
+func (s *DBPoolServiceTestSuite) TestList_ReportsEveryPool() {
+	// Act
+	pools := s.service.List()
+
+	// Assert
+	s.Len(pools, 1)
+	s.Equal("writer", pools[0].Name)
+	s.Equal(5, pools[0].MaxIdleConns)
+}
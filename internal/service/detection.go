@@ -0,0 +1,348 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+	"github.com/kingrain94/audit-log-api/internal/utils"
+)
+
+// SecurityAlertEvent is the webhook event of a security alert
+const SecurityAlertEvent = "security.alert"
+
+// minTravelGap floors the time between two sightings, so logs of the same
+// second don't travel at an infinite speed
+const minTravelGap = time.Minute
+
+// GeoLocator locates IP addresses
+//
+//go:generate mockery --name GeoLocator --output ../mocks
+type GeoLocator interface {
+	Locate(ip netip.Addr) (domain.GeoLocation, bool)
+}
+
+// AlertCooldown keeps a rule from alerting again about the same IP address or
+// user until ttl passes, across workers
+//
+//go:generate mockery --name AlertCooldown --output ../mocks
+type AlertCooldown interface {
+	// Acquire reports whether key is not cooling down, starting its cooldown
+	Acquire(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
+
+// detectionKey identifies what a rule tracks within a tenant: an IP address
+// for brute force, a user for impossible travel
+type detectionKey struct {
+	tenantID string
+	subject  string
+}
+
+type failedAction struct {
+	userID    string
+	timestamp time.Time
+}
+
+// DetectionService evaluates the built-in detection rules on the live stream
+// of logs and raises what they find as CRITICAL audit logs in the tenant's
+// own logs and notifications to their webhook. State is kept in memory, so
+// each worker only sees the logs broadcast to it.
+type DetectionService struct {
+	repo     repository.PostgresRepository
+	settings TenantSettingsResolver
+	webhooks *WebhookSender
+	cooldown AlertCooldown
+	// locator is nil when impossible travel is disabled
+	locator GeoLocator
+	config  *config.DetectionConfig
+
+	mu        sync.Mutex
+	failures  map[detectionKey][]failedAction
+	sightings map[detectionKey]domain.Sighting
+}
+
+func NewDetectionService(repo repository.PostgresRepository, settings TenantSettingsResolver, webhooks *WebhookSender, cooldown AlertCooldown, locator GeoLocator, cfg *config.DetectionConfig) *DetectionService {
+	return &DetectionService{
+		repo:      repo,
+		settings:  settings,
+		webhooks:  webhooks,
+		cooldown:  cooldown,
+		locator:   locator,
+		config:    cfg,
+		failures:  make(map[detectionKey][]failedAction),
+		sightings: make(map[detectionKey]domain.Sighting),
+	}
+}
+
+// Evaluate runs the enabled rules on a log, returning the alerts it raises.
+// Alerts the rules emitted themselves and anomaly logs are skipped.
+func (s *DetectionService) Evaluate(log *dto.AuditLogResponse) []domain.SecurityAlert {
+	switch domain.ActionType(log.Action) {
+	case domain.ActionSecurityAlert, domain.ActionAnomaly:
+		return nil
+	}
+	ip, err := netip.ParseAddr(strings.TrimSpace(log.IPAddress))
+	if err != nil {
+		return nil
+	}
+	ip = ip.Unmap()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var alerts []domain.SecurityAlert
+	if alert, ok := s.bruteForce(log, ip); ok {
+		alerts = append(alerts, alert)
+	}
+	if alert, ok := s.impossibleTravel(log, ip); ok {
+		alerts = append(alerts, alert)
+	}
+	return alerts
+}
+
+// bruteForce counts the failed actions of the log's address within the
+// window, alerting and starting over once they reach the threshold
+func (s *DetectionService) bruteForce(log *dto.AuditLogResponse, ip netip.Addr) (domain.SecurityAlert, bool) {
+	if !s.config.BruteForceEnabled() || !isFailedAction(log, s.config.BruteForceActions) {
+		return domain.SecurityAlert{}, false
+	}
+
+	key := detectionKey{tenantID: log.TenantID, subject: ip.String()}
+	since := log.Timestamp.Add(-s.config.BruteForceWindow)
+	failures := slices.DeleteFunc(s.failures[key], func(f failedAction) bool {
+		return !f.timestamp.After(since)
+	})
+	failures = append(failures, failedAction{userID: log.UserID, timestamp: log.Timestamp})
+	if len(failures) < s.config.BruteForceThreshold {
+		s.failures[key] = failures
+		return domain.SecurityAlert{}, false
+	}
+	delete(s.failures, key)
+
+	var userIDs []string
+	for _, failure := range failures {
+		if failure.userID != "" && !slices.Contains(userIDs, failure.userID) {
+			userIDs = append(userIDs, failure.userID)
+		}
+	}
+	return domain.SecurityAlert{
+		TenantID:  log.TenantID,
+		Rule:      domain.DetectionBruteForce,
+		IPAddress: ip.String(),
+		UserIDs:   userIDs,
+		Count:     len(failures),
+		Window:    s.config.BruteForceWindow,
+		At:        log.Timestamp,
+	}, true
+}
+
+// impossibleTravel compares where the log's user is with where they were last
+// seen, alerting when they'd have travelled faster than the max speed
+func (s *DetectionService) impossibleTravel(log *dto.AuditLogResponse, ip netip.Addr) (domain.SecurityAlert, bool) {
+	if s.locator == nil || log.UserID == "" {
+		return domain.SecurityAlert{}, false
+	}
+	location, ok := s.locator.Locate(ip)
+	if !ok {
+		return domain.SecurityAlert{}, false
+	}
+
+	key := detectionKey{tenantID: log.TenantID, subject: log.UserID}
+	current := domain.Sighting{LogID: log.ID, IPAddress: ip.String(), Location: location, Timestamp: log.Timestamp}
+	last, seen := s.sightings[key]
+	if seen && log.Timestamp.Before(last.Timestamp) {
+		// Late logs don't replace where the user was seen since
+		return domain.SecurityAlert{}, false
+	}
+	s.sightings[key] = current
+
+	gap := log.Timestamp.Sub(last.Timestamp)
+	if !seen || last.IPAddress == current.IPAddress || gap > s.config.TravelMaxGap {
+		return domain.SecurityAlert{}, false
+	}
+	distance := last.Location.DistanceKm(location)
+	speed := distance / max(gap, minTravelGap).Hours()
+	if distance < s.config.TravelMinDistanceKm || speed <= s.config.TravelMaxSpeedKmh {
+		return domain.SecurityAlert{}, false
+	}
+
+	return domain.SecurityAlert{
+		TenantID:   log.TenantID,
+		Rule:       domain.DetectionImpossibleTravel,
+		UserID:     log.UserID,
+		IPAddress:  current.IPAddress,
+		From:       &last,
+		To:         &current,
+		DistanceKm: distance,
+		SpeedKmh:   speed,
+		At:         log.Timestamp,
+	}, true
+}
+
+// Prune forgets failed actions out of the brute force window and sightings
+// older than the max travel gap as of now
+func (s *DetectionService) Prune(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	since := now.Add(-s.config.BruteForceWindow)
+	for key, failures := range s.failures {
+		if !failures[len(failures)-1].timestamp.After(since) {
+			delete(s.failures, key)
+		}
+	}
+	since = now.Add(-s.config.TravelMaxGap)
+	for key, sighting := range s.sightings {
+		if sighting.Timestamp.Before(since) {
+			delete(s.sightings, key)
+		}
+	}
+}
+
+// securityAlertPayload is the webhook body of a security alert
+type securityAlertPayload struct {
+	Event     string         `json:"event"`
+	TenantID  string         `json:"tenant_id"`
+	Rule      string         `json:"rule"`
+	LogID     string         `json:"log_id"`
+	UserID    string         `json:"user_id,omitempty"`
+	IPAddress string         `json:"ip_address"`
+	Message   string         `json:"message"`
+	Details   map[string]any `json:"details"`
+	Timestamp time.Time      `json:"timestamp"`
+}
+
+// Alert stores each alert not cooling down as a CRITICAL audit log, indexed
+// and broadcast through the outbox like any other log, then posts it to the
+// tenant's webhook. Cooldowns that can't be checked don't hold alerts back,
+// and webhook failures only show in metrics, since the log was recorded.
+func (s *DetectionService) Alert(ctx context.Context, alerts []domain.SecurityAlert) (err error) {
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	ctx, span := tracing.Start(ctx, "DetectionService.Alert", trace.WithAttributes(
+		tracing.TenantAttr(alerts[0].TenantID),
+		attribute.Int("security_alert.count", len(alerts)),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	for _, alert := range alerts {
+		if s.config.AlertCooldown > 0 {
+			acquired, err := s.cooldown.Acquire(ctx, alertCooldownKey(alert), s.config.AlertCooldown)
+			if err == nil && !acquired {
+				continue
+			}
+		}
+
+		log, details, err := newSecurityAlertLog(alert)
+		if err != nil {
+			return err
+		}
+		// The repository stores logs under the tenant of the request's claims
+		// and assigns their IDs
+		tenantCtx := context.WithValue(ctx, utils.ClaimsKey, jwt.MapClaims{string(utils.TenantIDKey): alert.TenantID})
+		logs := []domain.AuditLog{*log}
+		if err := storeIndexedLogs(tenantCtx, s.repo, logs); err != nil {
+			return fmt.Errorf("failed to store security alert log: %w", err)
+		}
+		metrics.SecurityAlertsTotal.WithLabelValues(alert.TenantID, string(alert.Rule)).Inc()
+
+		if settings, err := s.settings.ResolveSettings(ctx, alert.TenantID); err == nil {
+			_ = s.webhooks.Send(ctx, settings, SecurityAlertEvent, securityAlertPayload{
+				Event:     SecurityAlertEvent,
+				TenantID:  alert.TenantID,
+				Rule:      string(alert.Rule),
+				LogID:     logs[0].ID,
+				UserID:    log.UserID,
+				IPAddress: log.IPAddress,
+				Message:   log.Message,
+				Details:   details,
+				Timestamp: alert.At,
+			})
+		}
+	}
+	return nil
+}
+
+func alertCooldownKey(alert domain.SecurityAlert) string {
+	subject := alert.IPAddress
+	if alert.Rule == domain.DetectionImpossibleTravel {
+		subject = alert.UserID
+	}
+	return alert.TenantID + ":" + string(alert.Rule) + ":" + subject
+}
+
+// newSecurityAlertLog returns the log of an alert and the details recorded in
+// its metadata
+func newSecurityAlertLog(alert domain.SecurityAlert) (*domain.AuditLog, map[string]any, error) {
+	details := map[string]any{"rule": alert.Rule}
+	log := &domain.AuditLog{
+		TenantID:     alert.TenantID,
+		UserID:       alert.UserID,
+		IPAddress:    alert.IPAddress,
+		Action:       string(domain.ActionSecurityAlert),
+		ResourceType: domain.SecurityAlertResourceType,
+		ResourceID:   string(alert.Rule),
+		Severity:     string(domain.SeverityCritical),
+		Timestamp:    alert.At,
+	}
+
+	switch alert.Rule {
+	case domain.DetectionBruteForce:
+		details["count"] = alert.Count
+		details["window"] = alert.Window.String()
+		details["user_ids"] = alert.UserIDs
+		log.Message = fmt.Sprintf("%d failed actions from %s within %s", alert.Count, alert.IPAddress, alert.Window)
+	case domain.DetectionImpossibleTravel:
+		details["from"] = alert.From
+		details["to"] = alert.To
+		details["distance_km"] = alert.DistanceKm
+		details["speed_kmh"] = alert.SpeedKmh
+		log.Message = fmt.Sprintf("User %s appeared in %s %.0f km from %s within %s (%.0f km/h)",
+			alert.UserID, describeLocation(alert.To.Location), alert.DistanceKm, describeLocation(alert.From.Location),
+			alert.To.Timestamp.Sub(alert.From.Timestamp).Round(time.Second), alert.SpeedKmh)
+	}
+
+	metadata, err := json.Marshal(details)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal security alert metadata: %w", err)
+	}
+	log.Metadata = metadata
+	return log, details, nil
+}
+
+func describeLocation(location domain.GeoLocation) string {
+	if location.City == "" {
+		return location.Country
+	}
+	return location.City + ", " + location.Country
+}
+
+// isFailedAction reports whether a log is an ERROR or CRITICAL action, among
+// actions when there are any
+func isFailedAction(log *dto.AuditLogResponse, actions []string) bool {
+	switch domain.SeverityLevel(strings.ToUpper(log.Severity)) {
+	case domain.SeverityError, domain.SeverityCritical:
+	default:
+		return false
+	}
+	return len(actions) == 0 || slices.ContainsFunc(actions, func(action string) bool {
+		return strings.EqualFold(action, log.Action)
+	})
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/mocks"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+var (
+	berlin  = domain.GeoLocation{Country: "DE", City: "Berlin", Latitude: 52.52, Longitude: 13.405}
+	sydney  = domain.GeoLocation{Country: "AU", City: "Sydney", Latitude: -33.8688, Longitude: 151.2093}
+	potsdam = domain.GeoLocation{Country: "DE", City: "Potsdam", Latitude: 52.3906, Longitude: 13.0645}
+)
+
+type DetectionServiceTestSuite struct {
+	suite.Suite
+	mockRepo     *mocks.PostgresRepository
+	mockAuditLog *mocks.AuditLogRepository
+	mockOutbox   *mocks.OutboxRepository
+	mockSettings *mocks.TenantSettingsResolver
+	mockCooldown *mocks.AlertCooldown
+	mockLocator  *mocks.GeoLocator
+	config       *config.DetectionConfig
+	start        time.Time
+}
+
+func (s *DetectionServiceTestSuite) SetupTest() {
+	s.mockRepo = new(mocks.PostgresRepository)
+	s.mockAuditLog = new(mocks.AuditLogRepository)
+	s.mockOutbox = new(mocks.OutboxRepository)
+	s.mockSettings = new(mocks.TenantSettingsResolver)
+	s.mockCooldown = new(mocks.AlertCooldown)
+	s.mockLocator = new(mocks.GeoLocator)
+
+	s.mockRepo.On("AuditLog").Return(s.mockAuditLog)
+	s.mockRepo.On("Outbox").Return(s.mockOutbox)
+	s.mockRepo.On("Transaction", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, fn func(tx repository.PostgresRepository) error) error {
+			return fn(s.mockRepo)
+		})
+
+	s.config = &config.DetectionConfig{
+		BruteForceThreshold: 3,
+		BruteForceWindow:    5 * time.Minute,
+		GeoIPDatabase:       "dbip-city-lite.csv.gz",
+		TravelMaxSpeedKmh:   1000,
+		TravelMinDistanceKm: 500,
+		TravelMaxGap:        24 * time.Hour,
+		AlertCooldown:       15 * time.Minute,
+	}
+	s.start = time.Date(2025, 7, 17, 15, 0, 0, 0, time.UTC)
+}
+
+func (s *DetectionServiceTestSuite) newService() *DetectionService {
+	return NewDetectionService(s.mockRepo, s.mockSettings, NewWebhookSender(time.Second), s.mockCooldown, s.mockLocator, s.config)
+}
+
+func (s *DetectionServiceTestSuite) failedLogin(ip string, userID string, after time.Duration) *dto.AuditLogResponse {
+	return &dto.AuditLogResponse{
+		TenantID:  "tenant1",
+		UserID:    userID,
+		IPAddress: ip,
+		Action:    "LOGIN",
+		Severity:  "ERROR",
+		Timestamp: s.start.Add(after),
+	}
+}
+
+func TestDetectionService(t *testing.T) {
+	suite.Run(t, new(DetectionServiceTestSuite))
+}
+
+func (s *DetectionServiceTestSuite) TestEvaluate_BruteForce_AlertsAtThresholdThenStartsOver() {
+	// Arrange
+	s.config.GeoIPDatabase = ""
+	detector := NewDetectionService(s.mockRepo, s.mockSettings, NewWebhookSender(time.Second), s.mockCooldown, nil, s.config)
+
+	// Act
+	first := detector.Evaluate(s.failedLogin("203.0.113.7", "alice", 0))
+	second := detector.Evaluate(s.failedLogin("203.0.113.7", "bob", time.Minute))
+	third := detector.Evaluate(s.failedLogin("203.0.113.7", "alice", 2*time.Minute))
+	fourth := detector.Evaluate(s.failedLogin("203.0.113.7", "alice", 3*time.Minute))
+
+	// Assert
+	s.Empty(first)
+	s.Empty(second)
+	s.Require().Len(third, 1)
+	s.Equal(domain.DetectionBruteForce, third[0].Rule)
+	s.Equal("203.0.113.7", third[0].IPAddress)
+	s.Equal(3, third[0].Count)
+	s.Equal([]string{"alice", "bob"}, third[0].UserIDs)
+	s.Empty(fourth)
+}
+
+func (s *DetectionServiceTestSuite) TestEvaluate_BruteForce_OnlyCountsFailuresWithinWindow() {
+	// Arrange
+	s.config.GeoIPDatabase = ""
+	s.config.BruteForceActions = []string{"login"}
+	detector := NewDetectionService(s.mockRepo, s.mockSettings, NewWebhookSender(time.Second), s.mockCooldown, nil, s.config)
+	succeeded := s.failedLogin("203.0.113.7", "alice", time.Minute)
+	succeeded.Severity = "INFO"
+	otherAction := s.failedLogin("203.0.113.7", "alice", time.Minute)
+	otherAction.Action = "DELETE"
+	alert := s.failedLogin("203.0.113.7", "", time.Minute)
+	alert.Action = string(domain.ActionSecurityAlert)
+
+	// Act
+	var alerts []domain.SecurityAlert
+	alerts = append(alerts, detector.Evaluate(s.failedLogin("203.0.113.7", "alice", 0))...)
+	alerts = append(alerts, detector.Evaluate(succeeded)...)
+	alerts = append(alerts, detector.Evaluate(otherAction)...)
+	alerts = append(alerts, detector.Evaluate(alert)...)
+	alerts = append(alerts, detector.Evaluate(s.failedLogin("198.51.100.20", "alice", time.Minute))...)
+	alerts = append(alerts, detector.Evaluate(s.failedLogin("203.0.113.7", "alice", 6*time.Minute))...)
+	alerts = append(alerts, detector.Evaluate(s.failedLogin("203.0.113.7", "alice", 7*time.Minute))...)
+
+	// Assert
+	s.Empty(alerts)
+}
+
+func (s *DetectionServiceTestSuite) TestEvaluate_ImpossibleTravel_Alerts() {
+	// Arrange
+	s.config.BruteForceThreshold = 0
+	s.mockLocator.On("Locate", netip.MustParseAddr("203.0.113.7")).Return(berlin, true)
+	s.mockLocator.On("Locate", netip.MustParseAddr("198.51.100.20")).Return(sydney, true)
+	detector := s.newService()
+	from := &dto.AuditLogResponse{ID: "log1", TenantID: "tenant1", UserID: "alice", IPAddress: "203.0.113.7", Action: "LOGIN", Severity: "INFO", Timestamp: s.start}
+	to := &dto.AuditLogResponse{ID: "log2", TenantID: "tenant1", UserID: "alice", IPAddress: "198.51.100.20", Action: "READ", Severity: "INFO", Timestamp: s.start.Add(2 * time.Hour)}
+
+	// Act
+	first := detector.Evaluate(from)
+	second := detector.Evaluate(to)
+
+	// Assert
+	s.Empty(first)
+	s.Require().Len(second, 1)
+	s.Equal(domain.DetectionImpossibleTravel, second[0].Rule)
+	s.Equal("alice", second[0].UserID)
+	s.Equal("log1", second[0].From.LogID)
+	s.Equal("log2", second[0].To.LogID)
+	s.InDelta(16000, second[0].DistanceKm, 200)
+	s.InDelta(8000, second[0].SpeedKmh, 100)
+}
+
+func (s *DetectionServiceTestSuite) TestEvaluate_ImpossibleTravel_PlausibleTravelNotAlerted() {
+	// Arrange
+	s.config.BruteForceThreshold = 0
+	s.mockLocator.On("Locate", netip.MustParseAddr("203.0.113.7")).Return(berlin, true)
+	s.mockLocator.On("Locate", netip.MustParseAddr("203.0.113.8")).Return(potsdam, true)
+	s.mockLocator.On("Locate", netip.MustParseAddr("198.51.100.20")).Return(sydney, true)
+	detector := s.newService()
+	login := func(ip string, after time.Duration) *dto.AuditLogResponse {
+		return &dto.AuditLogResponse{TenantID: "tenant1", UserID: "alice", IPAddress: ip, Action: "LOGIN", Severity: "INFO", Timestamp: s.start.Add(after)}
+	}
+
+	// Act
+	var alerts []domain.SecurityAlert
+	alerts = append(alerts, detector.Evaluate(login("203.0.113.7", 0))...)
+	// Too close to tell apart
+	alerts = append(alerts, detector.Evaluate(login("203.0.113.8", time.Minute))...)
+	// A long flight
+	alerts = append(alerts, detector.Evaluate(login("198.51.100.20", 20*time.Hour))...)
+	// A late log doesn't move the user back
+	alerts = append(alerts, detector.Evaluate(login("203.0.113.7", time.Hour))...)
+
+	// Assert
+	s.Empty(alerts)
+}
+
+func (s *DetectionServiceTestSuite) TestPrune_ForgetsStaleState() {
+	// Arrange
+	detector := s.newService()
+	s.mockLocator.On("Locate", mock.Anything).Return(berlin, true)
+	detector.Evaluate(s.failedLogin("203.0.113.7", "alice", 0))
+
+	// Act
+	detector.Prune(s.start.Add(25 * time.Hour))
+
+	// Assert
+	s.Empty(detector.failures)
+	s.Empty(detector.sightings)
+}
+
+func (s *DetectionServiceTestSuite) TestAlert_StoresLogAndPostsWebhook() {
+	// Arrange
+	ctx := context.Background()
+	var body []byte
+	var received *http.Request
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+	// The test server listens on loopback, which the default client refuses
+	detector := s.newService()
+	detector.webhooks = &WebhookSender{client: server.Client()}
+
+	s.mockCooldown.On("Acquire", mock.Anything, "tenant1:brute_force:203.0.113.7", 15*time.Minute).Return(true, nil)
+	s.mockAuditLog.On("BulkCreate", mock.MatchedBy(func(ctx context.Context) bool {
+		tenantID, err := utils.GetTenantIDFromContext(ctx)
+		return err == nil && tenantID == "tenant1"
+	}), mock.MatchedBy(func(logs []domain.AuditLog) bool {
+		return len(logs) == 1 &&
+			logs[0].Severity == string(domain.SeverityCritical) &&
+			logs[0].Action == string(domain.ActionSecurityAlert) &&
+			logs[0].ResourceID == string(domain.DetectionBruteForce) &&
+			logs[0].IPAddress == "203.0.113.7" &&
+			logs[0].Message == "10 failed actions from 203.0.113.7 within 5m0s"
+	})).Return(nil)
+	s.mockOutbox.On("Create", mock.Anything, mock.AnythingOfType("*domain.OutboxEvent")).Return(nil)
+	s.mockSettings.On("ResolveSettings", mock.Anything, "tenant1").Return(&domain.TenantSettings{WebhookURL: server.URL}, nil)
+
+	// Act
+	err := detector.Alert(ctx, []domain.SecurityAlert{{
+		TenantID:  "tenant1",
+		Rule:      domain.DetectionBruteForce,
+		IPAddress: "203.0.113.7",
+		UserIDs:   []string{"alice"},
+		Count:     10,
+		Window:    5 * time.Minute,
+		At:        s.start,
+	}})
+
+	// Assert
+	s.NoError(err)
+	s.mockAuditLog.AssertExpectations(s.T())
+	s.mockOutbox.AssertExpectations(s.T())
+	s.Require().NotNil(received)
+	s.Equal(SecurityAlertEvent, received.Header.Get(WebhookEventHeader))
+	var payload securityAlertPayload
+	s.NoError(json.Unmarshal(body, &payload))
+	s.Equal("brute_force", payload.Rule)
+	s.Equal(float64(10), payload.Details["count"])
+}
+
+func (s *DetectionServiceTestSuite) TestAlert_CoolingDown_Skipped() {
+	// Arrange
+	ctx := context.Background()
+	s.mockCooldown.On("Acquire", mock.Anything, "tenant1:impossible_travel:alice", 15*time.Minute).Return(false, nil)
+
+	// Act
+	err := s.newService().Alert(ctx, []domain.SecurityAlert{{
+		TenantID: "tenant1",
+		Rule:     domain.DetectionImpossibleTravel,
+		UserID:   "alice",
+		From:     &domain.Sighting{Location: berlin, Timestamp: s.start},
+		To:       &domain.Sighting{Location: sydney, Timestamp: s.start.Add(time.Hour)},
+		At:       s.start.Add(time.Hour),
+	}})
+
+	// Assert
+	s.NoError(err)
+	s.mockAuditLog.AssertNotCalled(s.T(), "BulkCreate", mock.Anything, mock.Anything)
+}
+
+func (s *DetectionServiceTestSuite) TestAlert_CooldownUnavailable_StillAlerts() {
+	// Arrange
+	ctx := context.Background()
+	s.mockCooldown.On("Acquire", mock.Anything, mock.Anything, mock.Anything).Return(false, errors.New("redis down"))
+	s.mockAuditLog.On("BulkCreate", mock.Anything, mock.Anything).Return(nil)
+	s.mockOutbox.On("Create", mock.Anything, mock.Anything).Return(nil)
+	s.mockSettings.On("ResolveSettings", mock.Anything, "tenant1").Return(nil, errors.New("not found"))
+
+	// Act
+	err := s.newService().Alert(ctx, []domain.SecurityAlert{{
+		TenantID:  "tenant1",
+		Rule:      domain.DetectionBruteForce,
+		IPAddress: "203.0.113.7",
+		Count:     10,
+		Window:    5 * time.Minute,
+		At:        s.start,
+	}})
+
+	// Assert
+	s.NoError(err)
+	s.mockAuditLog.AssertExpectations(s.T())
+}
@@ -1,13 +1,107 @@
 package service
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/kingrain94/audit-log-api/internal/repository"
+)
 
 var (
 	// Tenant errors
-	ErrTenantNotFound = errors.New("tenant not found")
-	ErrTenantExists   = errors.New("tenant already exists")
+	ErrTenantNotFound   = errors.New("tenant not found")
+	ErrTenantExists     = errors.New("tenant already exists")
+	ErrTenantNotDeleted = errors.New("tenant is not deleted")
+	ErrTenantPurged     = errors.New("tenant grace period has ended, its data is being purged")
+	ErrSystemTenant     = errors.New("the system tenant holding the meta-audit logs can't be deleted")
+
+	ErrInvalidExportPublicKey = errors.New("export_public_key must be a PEM-encoded RSA public key of at least 2048 bits")
+	ErrInvalidWebhookURL      = errors.New("webhook_url must be an https url of a public host")
+
+	// Audit log errors
+	ErrLogNotFound = errors.New("log not found")
+
+	// Quota errors
+	ErrDailyQuotaExceeded   = errors.New("daily log quota exceeded")
+	ErrMonthlyQuotaExceeded = errors.New("monthly log quota exceeded")
+	ErrInvalidUsageRange    = errors.New("usage range must end after it starts and span at most 400 days")
+
+	// Export errors
+	ErrExportJobNotFound     = errors.New("export job not found")
+	ErrExportNotSigned       = errors.New("export job has no signature; it isn't signed or hasn't completed")
+	ErrExportSigningDisabled = errors.New("export signing is not configured on this server")
+
+	// Restore errors
+	ErrRestoreJobNotFound = errors.New("restore job not found")
+
+	// Import errors
+	ErrImportJobNotFound      = errors.New("import job not found")
+	ErrInvalidImportSource    = errors.New("source_uri must be of the form s3://bucket/key")
+	ErrImportSourceNotAllowed = errors.New("imports from this bucket are not allowed")
+	ErrImportKeyNotAllowed    = errors.New("import files must be under the tenant's <tenant_id>/ prefix")
+	ErrImportNotGzip          = errors.New("import file must be gzip-compressed NDJSON")
+	ErrImportTooLarge         = errors.New("import file exceeds the maximum upload size")
+
+	// Archive errors
+	ErrArchiveNotFound           = errors.New("archive not found")
+	ErrInvalidArchiveSearchRange = errors.New("archive search range must end after it starts and span at most 366 days")
+
+	// Search index errors
+	ErrSearchIndexNotFound  = errors.New("search index not found")
+	ErrInvalidIndexRange    = errors.New("index range must end on or after its start day and span at most 366 days")
+	ErrInvalidReindexRange  = errors.New("reindex range must end after it starts and span at most 31 days")
+	ErrInvalidBackfillRange = errors.New("backfill range must end after it starts")
+	ErrReindexJobCompleted  = errors.New("reindex job already completed")
+
+	// Job errors
+	ErrJobNotFound = errors.New("job not found")
 
 	// User errors
 	ErrUserNotFound       = errors.New("user not found")
 	ErrEmailAlreadyExists = errors.New("email already exists")
+
+	// Policy errors
+	ErrPolicyNotFound     = errors.New("policy not found")
+	ErrPolicyExists       = errors.New("policy for this role, resource and action already exists")
+	ErrInvalidPolicyScope = errors.New("own scope only applies to reading and exporting logs")
+
+	// Redaction errors
+	ErrRedactionRuleNotFound = errors.New("redaction rule not found")
+	ErrRedactionRuleExists   = errors.New("redaction rule already exists")
+	ErrInvalidRedactionPath  = errors.New("redaction path must be dot-separated keys without empty segments")
+
+	// Tagging errors
+	ErrTaggingRuleNotFound   = errors.New("tagging rule not found")
+	ErrTaggingRuleExists     = errors.New("tagging rule already exists")
+	ErrEmptyTaggingRule      = errors.New("tagging rule needs at least one of actions, resource_types, severities and message_pattern")
+	ErrInvalidMessagePattern = errors.New("message_pattern is not a valid regular expression")
+
+	// Resource schema errors
+	ErrResourceSchemaNotFound = errors.New("resource schema not found")
+	ErrResourceSchemaExists   = errors.New("resource schema for this resource type already exists")
+	ErrInvalidResourceSchema  = errors.New("resource schema is not a valid JSON Schema")
+
+	// Saved search errors
+	ErrSavedSearchNotFound = errors.New("saved search not found")
+	ErrSavedSearchExists   = errors.New("saved search with this name already exists")
+	ErrSavedSearchNotOwner = errors.New("only the owner can change a saved search")
+	ErrInvalidSavedSearch  = errors.New("saved search needs either a positive lookback or a start_time before end_time, not both")
+
+	// Integration errors
+	ErrIntegrationNotFound       = errors.New("integration not found")
+	ErrIntegrationExists         = errors.New("integration with this name already exists")
+	ErrIntegrationTokensDisabled = errors.New("integration token encryption is not configured on this server")
+	ErrInvalidIntegration        = errors.New("integrations need a token, and splunk integrations the https url of their HTTP Event Collector, on a public host")
+
+	// Auth errors
+	ErrInvalidCredentials  = errors.New("invalid email or password")
+	ErrUserInactive        = errors.New("user is deactivated")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrNoTenantInToken     = errors.New("token has no tenant")
+
+	// Database pool errors
+	ErrDBPoolNotFound        = errors.New("database pool not found")
+	ErrInvalidDBPoolSettings = errors.New("database pool needs max_idle_conns of at most max_open_conns and a conn_max_lifetime duration of 0 or more")
+
+	// ErrQueryTimeout is returned when a query runs past the request's timeout
+	ErrQueryTimeout = repository.ErrQueryTimeout
 )
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+// ExportSigner signs export files with Ed25519, with the server's key or a
+// tenant's own key. Tenant keys are derived from the server's key, so they
+// don't have to be stored and change when it is rotated.
+type ExportSigner struct {
+	key ed25519.PrivateKey
+}
+
+func NewExportSigner(key ed25519.PrivateKey) *ExportSigner {
+	return &ExportSigner{key: key}
+}
+
+// PublicKey returns the public half of the key the tenant's exports are signed
+// with
+func (s *ExportSigner) PublicKey(tenantID string, key domain.ExportSigningKey) domain.SigningKey {
+	return signingKey(s.privateKey(tenantID, key), key)
+}
+
+// Sign signs the SHA-256 digest of an export file of the tenant
+func (s *ExportSigner) Sign(tenantID string, key domain.ExportSigningKey, digest []byte) *domain.ExportSignature {
+	private := s.privateKey(tenantID, key)
+	return &domain.ExportSignature{
+		SigningKey: signingKey(private, key),
+		SHA256:     hex.EncodeToString(digest),
+		Signature:  base64.StdEncoding.EncodeToString(ed25519.Sign(private, digest)),
+		SignedAt:   time.Now().UTC(),
+	}
+}
+
+func (s *ExportSigner) privateKey(tenantID string, key domain.ExportSigningKey) ed25519.PrivateKey {
+	if key != domain.ExportSigningTenant {
+		return s.key
+	}
+	mac := hmac.New(sha256.New, s.key.Seed())
+	mac.Write([]byte("audit-log-export-signing-key:" + tenantID))
+	return ed25519.NewKeyFromSeed(mac.Sum(nil))
+}
+
+func signingKey(private ed25519.PrivateKey, key domain.ExportSigningKey) domain.SigningKey {
+	public := private.Public().(ed25519.PublicKey)
+	// Marshaling an Ed25519 public key can't fail
+	der, _ := x509.MarshalPKIXPublicKey(public)
+	id := sha256.Sum256(public)
+	return domain.SigningKey{
+		ID:        hex.EncodeToString(id[:8]),
+		Key:       key,
+		Algorithm: domain.ExportSigningAlgorithm,
+		PublicKey: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+	}
+}
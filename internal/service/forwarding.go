@@ -0,0 +1,388 @@
+package service
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+	"github.com/kingrain94/audit-log-api/pkg/safehttp"
+)
+
+const (
+	// splunkEventPath is where the HTTP Event Collector accepts JSON events
+	splunkEventPath = "/services/collector/event"
+	// datadogLogsPath is where the Datadog intake accepts JSON logs
+	datadogLogsPath = "/api/v2/logs"
+
+	defaultForwardingSource = "audit-log-api"
+	defaultSplunkSourceType = "audit_log"
+)
+
+// DeliveryError is a batch of logs an integration's platform didn't accept.
+// Status is 0 when no response was received. The response body isn't kept,
+// since the error is shown to the tenant.
+type DeliveryError struct {
+	Type       domain.IntegrationType
+	Status     int
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *DeliveryError) Error() string {
+	if e.Status == 0 {
+		return fmt.Sprintf("failed to post to %s: %v", e.Type, e.Err)
+	}
+	return fmt.Sprintf("%s responded with status %d: %v", e.Type, e.Status, e.Err)
+}
+
+func (e *DeliveryError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable reports whether posting the same batch again may succeed: after
+// connection failures other than to addresses that aren't public, timeouts,
+// throttling and server errors
+func (e *DeliveryError) Retryable() bool {
+	if e.Status == 0 {
+		return !errors.Is(e.Err, safehttp.ErrBlockedAddress)
+	}
+	return e.Status == http.StatusRequestTimeout ||
+		e.Status == http.StatusTooManyRequests || e.Status >= http.StatusInternalServerError
+}
+
+// LogForwarder delivers logs to the platform of an integration
+//
+//go:generate mockery --name LogForwarder --output ../mocks
+type LogForwarder interface {
+	// Encode returns the log as an event of the integration's platform
+	Encode(integration *domain.Integration, log *domain.AuditLog) ([]byte, error)
+	// Send posts encoded events in one request, failing with a *DeliveryError
+	// if they aren't accepted
+	Send(ctx context.Context, integration *domain.Integration, events [][]byte) error
+}
+
+// HTTPLogForwarder posts logs to Splunk HTTP Event Collectors and the
+// Datadog Logs API, over https and only to public addresses
+type HTTPLogForwarder struct {
+	client *http.Client
+}
+
+func NewHTTPLogForwarder(timeout time.Duration) *HTTPLogForwarder {
+	return &HTTPLogForwarder{client: safehttp.NewClient(timeout)}
+}
+
+// integrationBaseURL returns the Splunk collector's URL, or the Datadog
+// intake of the integration's site unless its URL replaces it
+func integrationBaseURL(integration *domain.Integration) string {
+	if integration.Type == domain.IntegrationDatadog && integration.Settings.URL == "" {
+		return "https://http-intake.logs." + integration.Settings.Site
+	}
+	return integration.Settings.URL
+}
+
+// splunkEvent is an event of the HTTP Event Collector; time is in seconds
+type splunkEvent struct {
+	Time       float64               `json:"time"`
+	Source     string                `json:"source"`
+	SourceType string                `json:"sourcetype"`
+	Index      string                `json:"index,omitempty"`
+	Event      *dto.AuditLogResponse `json:"event"`
+}
+
+// datadogLog is a log of the Datadog Logs API, the audit log's fields being
+// its attributes. Datadog reads the date from timestamp and the status from
+// severity.
+type datadogLog struct {
+	*dto.AuditLogResponse
+	DDSource string `json:"ddsource"`
+	DDTags   string `json:"ddtags"`
+	Service  string `json:"service"`
+}
+
+// Encode returns the log as a HEC event for Splunk and a Logs API log for
+// Datadog, tagged with its tenant
+func (f *HTTPLogForwarder) Encode(integration *domain.Integration, log *domain.AuditLog) ([]byte, error) {
+	settings := integration.Settings
+	source := cmp.Or(settings.Source, defaultForwardingSource)
+
+	switch integration.Type {
+	case domain.IntegrationSplunk:
+		return json.Marshal(splunkEvent{
+			Time:       float64(log.Timestamp.UnixMilli()) / 1000,
+			Source:     source,
+			SourceType: cmp.Or(settings.SourceType, defaultSplunkSourceType),
+			Index:      settings.Index,
+			Event:      dto.FromAuditLog(log),
+		})
+	case domain.IntegrationDatadog:
+		tags := append([]string{"tenant_id:" + log.TenantID}, settings.Tags...)
+		return json.Marshal(datadogLog{
+			AuditLogResponse: dto.FromAuditLog(log),
+			DDSource:         source,
+			DDTags:           strings.Join(tags, ","),
+			Service:          cmp.Or(settings.Service, defaultForwardingSource),
+		})
+	}
+	return nil, fmt.Errorf("unknown integration type %q", integration.Type)
+}
+
+// Send posts the events to the HEC's event endpoint, newline separated, or
+// to the Datadog intake as a JSON array
+func (f *HTTPLogForwarder) Send(ctx context.Context, integration *domain.Integration, events [][]byte) error {
+	var (
+		endpoint string
+		body     []byte
+		header   = make(http.Header)
+	)
+	header.Set("Content-Type", "application/json")
+
+	base := strings.TrimRight(integrationBaseURL(integration), "/")
+	if !strings.HasPrefix(base, "https://") {
+		return fmt.Errorf("failed to post to %s: %w", integration.Type, safehttp.ErrInsecureURL)
+	}
+
+	switch integration.Type {
+	case domain.IntegrationSplunk:
+		endpoint = base + splunkEventPath
+		body = bytes.Join(events, []byte("\n"))
+		header.Set("Authorization", "Splunk "+integration.Token)
+	case domain.IntegrationDatadog:
+		endpoint = base + datadogLogsPath
+		body = append(append([]byte("["), bytes.Join(events, []byte(","))...), ']')
+		header.Set("DD-API-KEY", integration.Token)
+	default:
+		return fmt.Errorf("unknown integration type %q", integration.Type)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %w", integration.Type, err)
+	}
+	req.Header = header
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return &DeliveryError{Type: integration.Type, Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return &DeliveryError{
+		Type:       integration.Type,
+		Status:     resp.StatusCode,
+		RetryAfter: retryAfter(resp.Header.Get("Retry-After")),
+		Err:        errors.New(http.StatusText(resp.StatusCode)),
+	}
+}
+
+// retryAfter parses a Retry-After header in seconds, 0 if absent or a date
+func retryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// ForwardingService delivers the logs of tenants' integrations in the order
+// they were stored, resuming after the last log delivered. Integrations'
+// tokens are decrypted with tokens.
+type ForwardingService struct {
+	repo      repository.PostgresRepository
+	forwarder LogForwarder
+	tokens    IntegrationTokenOpener
+	cfg       *config.ForwardingConfig
+}
+
+func NewForwardingService(repo repository.PostgresRepository, forwarder LogForwarder, tokens IntegrationTokenOpener, cfg *config.ForwardingConfig) *ForwardingService {
+	return &ForwardingService{
+		repo:      repo,
+		forwarder: forwarder,
+		tokens:    tokens,
+		cfg:       cfg,
+	}
+}
+
+// Claim leases up to limit integrations due for forwarding
+func (s *ForwardingService) Claim(ctx context.Context, limit int) ([]domain.Integration, error) {
+	integrations, err := s.repo.Integration().ClaimDue(ctx, limit, s.cfg.Lease)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim integrations: %w", err)
+	}
+	return integrations, nil
+}
+
+// Forward delivers up to MaxBatches batches of the integration's logs stored
+// since its cursor and at least SettleDelay ago, then saves its progress and
+// releases it. A delivery failing with a retryable error is retried at once
+// up to MaxRetries times; once it still fails, the integration backs off
+// exponentially from PollInterval up to MaxBackoff, or for as long as the
+// platform's Retry-After asks, and the batch is delivered again then. A
+// delivery failing with an error that isn't retryable, such as a revoked
+// token, disables the integration until the tenant updates it. Logs are
+// never skipped, so a delivery interrupted after the platform accepted the
+// batch delivers it twice.
+func (s *ForwardingService) Forward(ctx context.Context, integration *domain.Integration) (err error) {
+	ctx, span := tracing.Start(ctx, "ForwardingService.Forward", trace.WithAttributes(
+		tracing.TenantAttr(integration.TenantID),
+		attribute.String("integration.id", integration.ID),
+		attribute.String("integration.type", string(integration.Type)),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	now := time.Now()
+	caughtUp, forwardErr := s.forward(ctx, integration, now.Add(-s.cfg.SettleDelay))
+
+	lag := 0.0
+	if !caughtUp {
+		lag = time.Since(integration.CursorCreatedAt).Seconds()
+	}
+	metrics.IntegrationForwardingLag.WithLabelValues(integration.TenantID, integration.ID).Set(lag)
+
+	// A worker stopping isn't a failure of the integration
+	disable := false
+	if ctx.Err() == nil {
+		disable = s.recordOutcome(integration, forwardErr, now)
+	}
+	// Record the batches delivered even when the worker is stopping
+	if err := s.repo.Integration().SaveProgress(context.WithoutCancel(ctx), integration); err != nil {
+		return errors.Join(forwardErr, fmt.Errorf("failed to save integration progress: %w", err))
+	}
+	if disable {
+		if err := s.repo.Integration().Disable(context.WithoutCancel(ctx), integration); err != nil {
+			return errors.Join(forwardErr, fmt.Errorf("failed to disable integration: %w", err))
+		}
+	}
+	return forwardErr
+}
+
+// forward delivers batches of logs stored up to until, advancing the cursor
+// past each one delivered. It reports whether no logs are left.
+func (s *ForwardingService) forward(ctx context.Context, integration *domain.Integration, until time.Time) (bool, error) {
+	token, err := s.tokens.Open(integration.SealedToken)
+	if err != nil {
+		return false, err
+	}
+	integration.Token = token
+
+	filter := integration.Filter.AuditLogFilter(integration.TenantID)
+
+	for range s.cfg.MaxBatches {
+		logs, err := s.repo.AuditLog().ListStoredAfter(ctx, filter, integration.Cursor(), until, s.cfg.BatchSize)
+		if err != nil {
+			return false, fmt.Errorf("failed to list logs to forward: %w", err)
+		}
+		if len(logs) == 0 {
+			return true, nil
+		}
+
+		events := make([][]byte, len(logs))
+		for i := range logs {
+			if events[i], err = s.forwarder.Encode(integration, &logs[i]); err != nil {
+				return false, fmt.Errorf("failed to encode log %s: %w", logs[i].ID, err)
+			}
+		}
+
+		// Split the batch into requests of at most MaxBatchBytes
+		for start := 0; start < len(events); {
+			end, size := start+1, len(events[start])
+			for end < len(events) && size+1+len(events[end]) <= s.cfg.MaxBatchBytes {
+				size += 1 + len(events[end])
+				end++
+			}
+
+			if err := s.deliver(ctx, integration, events[start:end]); err != nil {
+				return false, err
+			}
+
+			last := logs[end-1]
+			deliveredAt := time.Now()
+			integration.CursorCreatedAt = last.CreatedAt
+			integration.CursorID = last.ID
+			integration.ForwardedCount += int64(end - start)
+			integration.LastForwardedAt = &deliveredAt
+			metrics.IntegrationLogsForwardedTotal.WithLabelValues(integration.TenantID, string(integration.Type)).Add(float64(end - start))
+			start = end
+		}
+
+		if len(logs) < s.cfg.BatchSize {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// deliver sends events, retrying retryable failures RetryBackoff apart,
+// doubling the wait after each one
+func (s *ForwardingService) deliver(ctx context.Context, integration *domain.Integration, events [][]byte) error {
+	backoff := s.cfg.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		err := s.forwarder.Send(ctx, integration, events)
+		metrics.ObserveIntegrationDelivery(string(integration.Type), start, err)
+
+		var deliveryErr *DeliveryError
+		if err == nil || attempt == s.cfg.MaxRetries || !errors.As(err, &deliveryErr) || !deliveryErr.Retryable() {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// recordOutcome clears the integration's failures after a successful run, or
+// counts the failure and schedules the next attempt. After a delivery that
+// can't succeed as it is, it reports that the integration is to be disabled
+// instead.
+func (s *ForwardingService) recordOutcome(integration *domain.Integration, err error, now time.Time) bool {
+	if err == nil {
+		integration.Failures = 0
+		integration.LastError = ""
+		integration.NextAttemptAt = nil
+		return false
+	}
+
+	integration.Failures++
+	integration.LastError = err.Error()
+
+	var deliveryErr *DeliveryError
+	if errors.As(err, &deliveryErr) && !deliveryErr.Retryable() {
+		integration.Enabled = false
+		integration.NextAttemptAt = nil
+		metrics.IntegrationsDisabledTotal.WithLabelValues(integration.TenantID, string(integration.Type)).Inc()
+		return true
+	}
+
+	backoff := min(s.cfg.PollInterval<<min(integration.Failures-1, 20), s.cfg.MaxBackoff)
+	if errors.As(err, &deliveryErr) && deliveryErr.RetryAfter > backoff {
+		backoff = deliveryErr.RetryAfter
+	}
+	next := now.Add(backoff)
+	integration.NextAttemptAt = &next
+	return false
+}
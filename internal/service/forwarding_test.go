@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/mocks"
+	"github.com/kingrain94/audit-log-api/pkg/safehttp"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ForwardingServiceTestSuite struct {
+	suite.Suite
+	mockRepo        *mocks.PostgresRepository
+	mockAuditLog    *mocks.AuditLogRepository
+	mockIntegration *mocks.IntegrationRepository
+	mockForwarder   *mocks.LogForwarder
+	mockTokens      *mocks.IntegrationTokenOpener
+	service         *ForwardingService
+}
+
+func (s *ForwardingServiceTestSuite) SetupTest() {
+	s.mockRepo = new(mocks.PostgresRepository)
+	s.mockAuditLog = new(mocks.AuditLogRepository)
+	s.mockIntegration = new(mocks.IntegrationRepository)
+	s.mockForwarder = new(mocks.LogForwarder)
+	s.mockTokens = new(mocks.IntegrationTokenOpener)
+
+	s.mockRepo.On("AuditLog").Return(s.mockAuditLog)
+	s.mockRepo.On("Integration").Return(s.mockIntegration)
+	s.mockTokens.On("Open", []byte("sealed")).Return("hec-token", nil).Maybe()
+
+	s.service = NewForwardingService(s.mockRepo, s.mockForwarder, s.mockTokens, &config.ForwardingConfig{
+		PollInterval:  5 * time.Second,
+		BatchSize:     2,
+		MaxBatchBytes: 65536,
+		MaxBatches:    1,
+		MaxRetries:    1,
+		RetryBackoff:  time.Millisecond,
+		MaxBackoff:    time.Minute,
+		Lease:         time.Minute,
+	})
+}
+
+func TestForwardingService(t *testing.T) {
+	suite.Run(t, new(ForwardingServiceTestSuite))
+}
+
+func forwardingLogs() []domain.AuditLog {
+	stored := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	return []domain.AuditLog{
+		{ID: "log1", TenantID: "tenant1", Action: "LOGIN", Severity: "INFO", Timestamp: stored, CreatedAt: stored},
+		{ID: "log2", TenantID: "tenant1", Action: "DELETE", Severity: "WARNING", Timestamp: stored, CreatedAt: stored.Add(time.Second)},
+	}
+}
+
+func (s *ForwardingServiceTestSuite) TestForward_DeliversBatch_AdvancesCursor() {
+	// Arrange
+	ctx := context.Background()
+	integration := &domain.Integration{ID: "integration1", TenantID: "tenant1", Type: domain.IntegrationSplunk, SealedToken: []byte("sealed"), Failures: 2}
+	logs := forwardingLogs()
+	s.mockAuditLog.On("ListStoredAfter", mock.Anything, mock.Anything, mock.Anything, mock.Anything, 2).Return(logs, nil).Once()
+	s.mockForwarder.On("Encode", integration, mock.Anything).Return([]byte(`{}`), nil)
+	s.mockForwarder.On("Send", mock.Anything, integration, [][]byte{[]byte(`{}`), []byte(`{}`)}).Return(nil).Once()
+	s.mockIntegration.On("SaveProgress", mock.Anything, integration).Return(nil)
+
+	// Act
+	err := s.service.Forward(ctx, integration)
+
+	// Assert
+	s.Require().NoError(err)
+	s.Equal("hec-token", integration.Token)
+	s.Equal(logs[1].CreatedAt, integration.CursorCreatedAt)
+	s.Equal("log2", integration.CursorID)
+	s.Equal(int64(2), integration.ForwardedCount)
+	s.NotNil(integration.LastForwardedAt)
+	s.Zero(integration.Failures)
+	s.Nil(integration.NextAttemptAt)
+}
+
+func (s *ForwardingServiceTestSuite) TestForward_RetryableFailure_KeepsCursorAndBacksOff() {
+	// Arrange
+	ctx := context.Background()
+	integration := &domain.Integration{ID: "integration1", TenantID: "tenant1", Type: domain.IntegrationDatadog, SealedToken: []byte("sealed"), CursorID: "log0"}
+	s.mockAuditLog.On("ListStoredAfter", mock.Anything, mock.Anything, mock.Anything, mock.Anything, 2).Return(forwardingLogs(), nil).Once()
+	s.mockForwarder.On("Encode", integration, mock.Anything).Return([]byte(`{}`), nil)
+	s.mockForwarder.On("Send", mock.Anything, integration, mock.Anything).
+		Return(&DeliveryError{Type: domain.IntegrationDatadog, Status: http.StatusTooManyRequests, RetryAfter: time.Minute, Err: errors.New("slow down")}).
+		Twice()
+	s.mockIntegration.On("SaveProgress", mock.Anything, integration).Return(nil)
+
+	// Act
+	before := time.Now()
+	err := s.service.Forward(ctx, integration)
+
+	// Assert
+	var deliveryErr *DeliveryError
+	s.Require().ErrorAs(err, &deliveryErr)
+	s.mockForwarder.AssertNumberOfCalls(s.T(), "Send", 2)
+	s.Equal("log0", integration.CursorID)
+	s.Zero(integration.ForwardedCount)
+	s.Equal(1, integration.Failures)
+	s.Contains(integration.LastError, "slow down")
+	s.Require().NotNil(integration.NextAttemptAt)
+	s.False(integration.NextAttemptAt.Before(before.Add(time.Minute)))
+	s.mockIntegration.AssertNotCalled(s.T(), "Disable", mock.Anything, mock.Anything)
+}
+
+func (s *ForwardingServiceTestSuite) TestForward_ClientError_DisablesIntegration() {
+	// Arrange
+	ctx := context.Background()
+	integration := &domain.Integration{ID: "integration1", TenantID: "tenant1", Type: domain.IntegrationSplunk, Enabled: true, SealedToken: []byte("sealed")}
+	s.mockAuditLog.On("ListStoredAfter", mock.Anything, mock.Anything, mock.Anything, mock.Anything, 2).Return(forwardingLogs(), nil).Once()
+	s.mockForwarder.On("Encode", integration, mock.Anything).Return([]byte(`{}`), nil)
+	s.mockForwarder.On("Send", mock.Anything, integration, mock.Anything).
+		Return(&DeliveryError{Type: domain.IntegrationSplunk, Status: http.StatusForbidden, Err: errors.New("invalid token")}).
+		Once()
+	s.mockIntegration.On("SaveProgress", mock.Anything, integration).Return(nil)
+	s.mockIntegration.On("Disable", mock.Anything, integration).Return(nil)
+
+	// Act
+	err := s.service.Forward(ctx, integration)
+
+	// Assert
+	s.Require().Error(err)
+	s.mockForwarder.AssertNumberOfCalls(s.T(), "Send", 1)
+	s.Equal(1, integration.Failures)
+	s.Contains(integration.LastError, "status 403")
+	s.False(integration.Enabled)
+	s.Nil(integration.NextAttemptAt, "disabled integrations aren't retried")
+	s.mockIntegration.AssertCalled(s.T(), "Disable", mock.Anything, integration)
+}
+
+func (s *ForwardingServiceTestSuite) TestForward_TokenNotDecrypted_BacksOffWithoutSending() {
+	// Arrange
+	ctx := context.Background()
+	integration := &domain.Integration{ID: "integration1", TenantID: "tenant1", Type: domain.IntegrationSplunk, SealedToken: []byte("other key")}
+	s.mockTokens.On("Open", []byte("other key")).Return("", errors.New("failed to unwrap key"))
+	s.mockIntegration.On("SaveProgress", mock.Anything, integration).Return(nil)
+
+	// Act
+	err := s.service.Forward(ctx, integration)
+
+	// Assert
+	s.Require().Error(err)
+	s.mockForwarder.AssertNotCalled(s.T(), "Send", mock.Anything, mock.Anything, mock.Anything)
+	s.Equal(1, integration.Failures)
+	s.Require().NotNil(integration.NextAttemptAt)
+}
+
+func (s *ForwardingServiceTestSuite) TestHTTPLogForwarder_Splunk_PostsEventsToCollector() {
+	// Arrange
+	var path, authorization string
+	var lines []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		authorization = r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		lines = strings.Split(string(body), "\n")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	forwarder := &HTTPLogForwarder{client: server.Client()}
+	integration := &domain.Integration{
+		Type:     domain.IntegrationSplunk,
+		Token:    "hec-token",
+		Settings: domain.IntegrationSettings{URL: server.URL + "/", Index: "audit"},
+	}
+	logs := forwardingLogs()
+	events := make([][]byte, len(logs))
+	for i := range logs {
+		var err error
+		events[i], err = forwarder.Encode(integration, &logs[i])
+		s.Require().NoError(err)
+	}
+
+	// Act
+	err := forwarder.Send(context.Background(), integration, events)
+
+	// Assert
+	s.Require().NoError(err)
+	s.Equal("/services/collector/event", path)
+	s.Equal("Splunk hec-token", authorization)
+	s.Require().Len(lines, 2)
+	var event struct {
+		Time       float64 `json:"time"`
+		Index      string  `json:"index"`
+		SourceType string  `json:"sourcetype"`
+		Event      struct {
+			ID     string `json:"id"`
+			Action string `json:"action"`
+		} `json:"event"`
+	}
+	s.Require().NoError(json.Unmarshal([]byte(lines[1]), &event))
+	s.Equal(float64(logs[1].Timestamp.Unix()), event.Time)
+	s.Equal("audit", event.Index)
+	s.Equal("audit_log", event.SourceType)
+	s.Equal("log2", event.Event.ID)
+	s.Equal("DELETE", event.Event.Action)
+}
+
+func (s *ForwardingServiceTestSuite) TestHTTPLogForwarder_Datadog_PostsTaggedLogs() {
+	// Arrange
+	var path, apiKey string
+	var body []map[string]any
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		apiKey = r.Header.Get("DD-API-KEY")
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	forwarder := &HTTPLogForwarder{client: server.Client()}
+	integration := &domain.Integration{
+		Type:     domain.IntegrationDatadog,
+		Token:    "api-key",
+		Settings: domain.IntegrationSettings{URL: server.URL, Service: "billing", Tags: []string{"env:prod"}},
+	}
+	logs := forwardingLogs()
+	event, err := forwarder.Encode(integration, &logs[0])
+	s.Require().NoError(err)
+
+	// Act
+	err = forwarder.Send(context.Background(), integration, [][]byte{event})
+
+	// Assert
+	s.Require().NoError(err)
+	s.Equal("/api/v2/logs", path)
+	s.Equal("api-key", apiKey)
+	s.Require().Len(body, 1)
+	s.Equal("log1", body[0]["id"])
+	s.Equal("audit-log-api", body[0]["ddsource"])
+	s.Equal("tenant_id:tenant1,env:prod", body[0]["ddtags"])
+	s.Equal("billing", body[0]["service"])
+}
+
+func (s *ForwardingServiceTestSuite) TestHTTPLogForwarder_Throttled_ReturnsRetryableError() {
+	// Arrange
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte("rate limited"))
+	}))
+	defer server.Close()
+
+	forwarder := &HTTPLogForwarder{client: server.Client()}
+	integration := &domain.Integration{
+		Type:     domain.IntegrationDatadog,
+		Token:    "api-key",
+		Settings: domain.IntegrationSettings{URL: server.URL},
+	}
+
+	// Act
+	err := forwarder.Send(context.Background(), integration, [][]byte{[]byte(`{}`)})
+
+	// Assert
+	var deliveryErr *DeliveryError
+	s.Require().ErrorAs(err, &deliveryErr)
+	s.Equal(http.StatusTooManyRequests, deliveryErr.Status)
+	s.Equal(30*time.Second, deliveryErr.RetryAfter)
+	s.True(deliveryErr.Retryable())
+	s.Contains(err.Error(), "Too Many Requests")
+	s.NotContains(err.Error(), "rate limited", "response bodies aren't shown to tenants")
+}
+
+func (s *ForwardingServiceTestSuite) TestHTTPLogForwarder_PrivateAddress_NotPosted() {
+	// Arrange
+	posted := false
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posted = true
+	}))
+	defer server.Close()
+
+	forwarder := NewHTTPLogForwarder(time.Second)
+	integration := &domain.Integration{
+		Type:     domain.IntegrationSplunk,
+		Token:    "hec-token",
+		Settings: domain.IntegrationSettings{URL: server.URL},
+	}
+
+	// Act
+	err := forwarder.Send(context.Background(), integration, [][]byte{[]byte(`{}`)})
+
+	// Assert
+	var deliveryErr *DeliveryError
+	s.Require().ErrorAs(err, &deliveryErr)
+	s.ErrorIs(err, safehttp.ErrBlockedAddress)
+	s.False(deliveryErr.Retryable())
+	s.False(posted)
+}
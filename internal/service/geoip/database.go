@@ -0,0 +1,136 @@
+package geoip
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+// ipRange is a range of addresses located at the same place
+type ipRange struct {
+	start    netip.Addr
+	end      netip.Addr
+	location domain.GeoLocation
+}
+
+// Database locates IP addresses with an IP to city database in the CSV layout
+// of DB-IP's IP to City Lite, optionally gzipped:
+//
+//	ip_start,ip_end,continent,country,stateprov,city,latitude,longitude
+//
+// The whole database is held in memory, sorted for binary search.
+type Database struct {
+	ranges []ipRange
+}
+
+// Open loads the database at path
+func Open(path string) (*Database, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GeoIP database: %w", err)
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress GeoIP database: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	db, err := Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load GeoIP database %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// Parse reads a database, skipping a header line if there is one
+func Parse(r io.Reader) (*Database, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 8
+	reader.ReuseRecord = true
+
+	var ranges []ipRange
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, err := netip.ParseAddr(record[0])
+		if err != nil {
+			if line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		end, err := netip.ParseAddr(record[1])
+		if err != nil || end.Is4() != start.Is4() || end.Less(start) {
+			return nil, fmt.Errorf("line %d: invalid range end %q", line, record[1])
+		}
+		latitude, err := strconv.ParseFloat(record[6], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid latitude: %w", line, err)
+		}
+		longitude, err := strconv.ParseFloat(record[7], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid longitude: %w", line, err)
+		}
+
+		ranges = append(ranges, ipRange{
+			start: start.Unmap(),
+			end:   end.Unmap(),
+			location: domain.GeoLocation{
+				Country:   record[3],
+				City:      record[5],
+				Latitude:  latitude,
+				Longitude: longitude,
+			},
+		})
+	}
+	if len(ranges) == 0 {
+		return nil, errors.New("no ranges")
+	}
+
+	slices.SortFunc(ranges, func(a, b ipRange) int {
+		return a.start.Compare(b.start)
+	})
+	return &Database{ranges: ranges}, nil
+}
+
+// Len returns the number of ranges loaded
+func (d *Database) Len() int {
+	return len(d.ranges)
+}
+
+// Locate returns where ip is, reporting false for addresses out of every range
+func (d *Database) Locate(ip netip.Addr) (domain.GeoLocation, bool) {
+	ip = ip.Unmap()
+	// The last range starting at or before ip is the only one that may hold it
+	i, found := slices.BinarySearchFunc(d.ranges, ip, func(r ipRange, ip netip.Addr) int {
+		return r.start.Compare(ip)
+	})
+	if !found {
+		i--
+	}
+	if i < 0 || d.ranges[i].end.Less(ip) || d.ranges[i].end.Is4() != ip.Is4() {
+		return domain.GeoLocation{}, false
+	}
+	return d.ranges[i].location, true
+}
@@ -0,0 +1,351 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/service/storage"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+)
+
+const (
+	// importBatchSize is the number of lines an import job reads before it
+	// stores their logs and checkpoints. Their index messages are split to
+	// stay below maxIngestMessageSize.
+	importBatchSize = 100
+
+	// maxImportLineSize caps a line of an import file. Lines holding logs
+	// larger than maxIngestMessageSize, which couldn't be indexed, are invalid.
+	maxImportLineSize = 1024 * 1024
+)
+
+// ImportStore stages files uploaded for import and reads import files in S3
+//
+//go:generate mockery --name ImportStore --output ../mocks
+type ImportStore interface {
+	// Put stores size bytes of body at key in the staging bucket and returns its s3:// URI
+	Put(ctx context.Context, key string, body io.Reader, size int64) (string, error)
+	// Open streams the file at an s3:// URI and returns its size
+	Open(ctx context.Context, uri string) (io.ReadCloser, int64, error)
+	Delete(ctx context.Context, uri string) error
+}
+
+// ImportService loads historical logs from legacy systems. Files of
+// gzip-compressed NDJSON, one log per line, are uploaded through the API or
+// read from an allowed bucket, and imported by the archive worker. Logs keep
+// their IDs and timestamps, are tagged and redacted by the tenant's rules and
+// count against its quotas. Being historical, they aren't sampled or enriched.
+type ImportService struct {
+	repo      repository.PostgresRepository
+	publisher MessagePublisher
+	store     ImportStore
+	redactor  LogRedactor
+	tagger    LogTagger
+	usage     UsageTracker
+	config    *config.ImportConfig
+	validate  *validator.Validate
+}
+
+func NewImportService(
+	repo repository.PostgresRepository,
+	publisher MessagePublisher,
+	store ImportStore,
+	redactor LogRedactor,
+	tagger LogTagger,
+	usage UsageTracker,
+	cfg *config.ImportConfig,
+) *ImportService {
+	// Lines are checked against the binding rules of the ingestion DTOs
+	validate := validator.New()
+	validate.SetTagName("binding")
+	if err := dto.RegisterValidators(validate); err != nil {
+		panic(err)
+	}
+
+	return &ImportService{
+		repo:      repo,
+		publisher: publisher,
+		store:     store,
+		redactor:  redactor,
+		tagger:    tagger,
+		usage:     usage,
+		config:    cfg,
+		validate:  validate,
+	}
+}
+
+// Upload stages an uploaded import file of size bytes in S3 and enqueues its
+// import
+func (s *ImportService) Upload(ctx context.Context, tenantID string, body io.Reader, size int64) (_ *dto.ImportJobResponse, err error) {
+	ctx, span := tracing.Start(ctx, "ImportService.Upload", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	if size > s.config.MaxUploadSize {
+		return nil, ErrImportTooLarge
+	}
+
+	// Reject anything but gzip before staging it
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(body, header); err != nil || header[0] != 0x1f || header[1] != 0x8b {
+		return nil, ErrImportNotGzip
+	}
+
+	job := &domain.ImportJob{
+		ID:        uuid.New().String(),
+		TenantID:  tenantID,
+		Status:    domain.JobPending,
+		Uploaded:  true,
+		SizeBytes: size,
+	}
+	key := fmt.Sprintf("imports/%s/%s.ndjson.gz", tenantID, job.ID)
+	if job.SourceURI, err = s.store.Put(ctx, key, io.MultiReader(bytes.NewReader(header), body), size); err != nil {
+		return nil, err
+	}
+	return s.start(ctx, job)
+}
+
+// ImportFromS3 enqueues the import of a file already in S3, in one of the
+// buckets of IMPORT_ALLOWED_BUCKETS under the tenant's <tenant_id>/ prefix
+func (s *ImportService) ImportFromS3(ctx context.Context, tenantID, uri string) (_ *dto.ImportJobResponse, err error) {
+	ctx, span := tracing.Start(ctx, "ImportService.ImportFromS3", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	bucket, key, err := storage.ParseS3URI(uri)
+	if err != nil {
+		return nil, ErrInvalidImportSource
+	}
+	// The worker's role may read buckets tenants mustn't, such as the archives
+	if !slices.Contains(s.config.AllowedBuckets, bucket) {
+		return nil, ErrImportSourceNotAllowed
+	}
+	// Allowed buckets are shared, so a tenant may only import its own files
+	if !strings.HasPrefix(key, tenantID+"/") {
+		return nil, ErrImportKeyNotAllowed
+	}
+
+	return s.start(ctx, &domain.ImportJob{
+		TenantID:  tenantID,
+		Status:    domain.JobPending,
+		SourceURI: uri,
+	})
+}
+
+func (s *ImportService) start(ctx context.Context, job *domain.ImportJob) (*dto.ImportJobResponse, error) {
+	if err := s.repo.ImportJob().Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create import job: %w", err)
+	}
+
+	if err := s.publisher.SendImportMessage(ctx, job.TenantID, job.ID); err != nil {
+		// Best effort: don't leave the job pending forever when it never reached the queue
+		job.Status = domain.JobFailed
+		job.Error = "failed to enqueue import job"
+		_ = s.repo.ImportJob().Update(ctx, job)
+		return nil, fmt.Errorf("failed to enqueue import job: %w", err)
+	}
+
+	return dto.FromImportJob(job), nil
+}
+
+// Get returns the state and progress of one of the tenant's import jobs
+func (s *ImportService) Get(ctx context.Context, tenantID, jobID string) (_ *dto.ImportJobResponse, err error) {
+	ctx, span := tracing.Start(ctx, "ImportService.Get", trace.WithAttributes(tracing.TenantAttr(tenantID), attribute.String("job.id", jobID)))
+	defer func() { tracing.End(span, err) }()
+
+	job, err := s.repo.ImportJob().GetByID(ctx, tenantID, jobID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrImportJobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get import job: %w", err)
+	}
+	return dto.FromImportJob(job), nil
+}
+
+// Run imports the job's file, starting after the last line it stored. Every
+// importBatchSize lines, the valid logs are checked against the tenant's
+// quotas, tagged, redacted and stored with their IDs, skipping those that
+// already exist, and enqueued for indexing, and the job is checkpointed before
+// progress is called with it. Invalid lines are counted and the reasons of the
+// first domain.MaxImportLineErrors kept, without failing the job. Exceeding a
+// quota fails the job, keeping the logs stored up to its last checkpoint.
+func (s *ImportService) Run(ctx context.Context, job *domain.ImportJob, progress func(*domain.ImportJob)) (err error) {
+	ctx, span := tracing.Start(ctx, "ImportService.Run", trace.WithAttributes(tracing.TenantAttr(job.TenantID), attribute.String("job.id", job.ID)))
+	defer func() { tracing.End(span, err) }()
+
+	body, size, err := s.store.Open(ctx, job.SourceURI)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	job.SizeBytes = size
+
+	counter := &countingReader{r: body}
+	gz, err := gzip.NewReader(counter)
+	if err != nil {
+		return ErrImportNotGzip
+	}
+	defer gz.Close()
+
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLineSize)
+
+	var (
+		line       int64
+		logs       []domain.AuditLog
+		lineErrors []domain.ImportLineError
+		invalid    int64
+	)
+	checkpoint := func() error {
+		if len(logs) > 0 {
+			if err := s.usage.CheckQuota(ctx, job.TenantID, int64(len(logs))); err != nil {
+				return fmt.Errorf("failed to import logs up to line %d: %w", line, err)
+			}
+			if err := s.tagger.Tag(ctx, logs); err != nil {
+				return fmt.Errorf("failed to tag logs: %w", err)
+			}
+			if err := s.redactor.Redact(ctx, logs); err != nil {
+				return fmt.Errorf("failed to redact logs: %w", err)
+			}
+			chunks, size, err := IngestChunks(logs)
+			if err != nil {
+				return err
+			}
+
+			imported, err := s.repo.AuditLog().Restore(ctx, logs)
+			if err != nil {
+				return fmt.Errorf("failed to store logs up to line %d: %w", line, err)
+			}
+			// Index every log, including ones that already existed, as restores do
+			for _, chunk := range chunks {
+				if err := s.publisher.SendBulkIndexMessage(ctx, chunk); err != nil {
+					return fmt.Errorf("failed to enqueue index message: %w", err)
+				}
+			}
+			job.ImportedCount += imported
+			job.SkippedCount += int64(len(logs)) - imported
+			// Logs that already existed were metered when they were stored
+			_ = s.usage.Record(ctx, job.TenantID, imported, int64(size)*imported/int64(len(logs)))
+		}
+		job.InvalidCount += invalid
+		job.LineErrors = append(job.LineErrors, lineErrors...)
+		job.LinesRead = line
+		job.BytesRead = counter.n
+		progress(job)
+
+		logs, lineErrors, invalid = logs[:0], nil, 0
+		return nil
+	}
+
+	for scanner.Scan() {
+		line++
+		// Lines up to the checkpoint were stored before the job was interrupted
+		if line <= job.LinesRead {
+			continue
+		}
+
+		if data := bytes.TrimSpace(scanner.Bytes()); len(data) > 0 {
+			log, err := s.parseLine(job, line, data)
+			if err != nil {
+				invalid++
+				if len(job.LineErrors)+len(lineErrors) < domain.MaxImportLineErrors {
+					lineErrors = append(lineErrors, domain.ImportLineError{Line: line, Error: err.Error()})
+				}
+			} else {
+				logs = append(logs, *log)
+			}
+		}
+
+		if line-job.LinesRead >= importBatchSize {
+			if err := checkpoint(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return fmt.Errorf("line %d is longer than %d bytes", line+1, maxImportLineSize)
+		}
+		return fmt.Errorf("failed to read import file after line %d: %w", line, err)
+	}
+	return checkpoint()
+}
+
+// parseLine decodes and validates a line of the job's file. Logs without an
+// ID get one derived from the file and line, so importing the file again
+// doesn't duplicate them.
+func (s *ImportService) parseLine(job *domain.ImportJob, line int64, data []byte) (*domain.AuditLog, error) {
+	if len(data) > maxIngestMessageSize {
+		return nil, fmt.Errorf("log is larger than the %d bytes that can be indexed", maxIngestMessageSize)
+	}
+
+	var req dto.ImportAuditLogRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+	req.TenantID = job.TenantID
+
+	if err := s.validate.Struct(&req); err != nil {
+		var fieldErrs validator.ValidationErrors
+		if !errors.As(err, &fieldErrs) {
+			return nil, err
+		}
+		reasons := make([]string, len(fieldErrs))
+		for i, fieldErr := range fieldErrs {
+			rule := fieldErr.Tag()
+			if fieldErr.Param() != "" {
+				rule += "=" + fieldErr.Param()
+			}
+			reasons[i] = fmt.Sprintf("%s fails %s", fieldErr.Field(), rule)
+		}
+		return nil, errors.New(strings.Join(reasons, "; "))
+	}
+	// Logs of older schema versions are correlated by their import
+	req.Upconvert(job.ID)
+
+	log := req.ToAuditLog()
+	log.ID = req.ID
+	if log.ID == "" {
+		log.ID = uuid.NewSHA1(uuid.NameSpaceURL, []byte(job.SourceURI+"#L"+strconv.FormatInt(line, 10))).String()
+	}
+	return log, nil
+}
+
+// RemoveUpload deletes the staged file of a finished import uploaded through
+// the API; files imported from other buckets are left in place
+func (s *ImportService) RemoveUpload(ctx context.Context, job *domain.ImportJob) error {
+	if !job.Uploaded {
+		return nil
+	}
+	return s.store.Delete(ctx, job.SourceURI)
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,351 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ImportServiceTestSuite struct {
+	suite.Suite
+	mockRepo      *mocks.PostgresRepository
+	mockJobs      *mocks.ImportJobRepository
+	mockAuditLog  *mocks.AuditLogRepository
+	mockPublisher *mocks.MessagePublisher
+	mockStore     *mocks.ImportStore
+	mockRedactor  *mocks.LogRedactor
+	mockTagger    *mocks.LogTagger
+	mockUsage     *mocks.UsageTracker
+	config        *config.ImportConfig
+	service       *ImportService
+}
+
+func (s *ImportServiceTestSuite) SetupTest() {
+	s.mockRepo = new(mocks.PostgresRepository)
+	s.mockJobs = new(mocks.ImportJobRepository)
+	s.mockAuditLog = new(mocks.AuditLogRepository)
+	s.mockPublisher = new(mocks.MessagePublisher)
+	s.mockStore = new(mocks.ImportStore)
+	s.mockRedactor = new(mocks.LogRedactor)
+	s.mockTagger = new(mocks.LogTagger)
+	s.mockUsage = new(mocks.UsageTracker)
+	s.config = &config.ImportConfig{
+		AllowedBuckets: []string{"legacy-exports"},
+		MaxUploadSize:  1024,
+	}
+
+	s.mockRepo.On("ImportJob").Return(s.mockJobs)
+	s.mockRepo.On("AuditLog").Return(s.mockAuditLog)
+	s.mockUsage.On("CheckQuota", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	s.mockUsage.On("Record", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	s.service = NewImportService(s.mockRepo, s.mockPublisher, s.mockStore, s.mockRedactor, s.mockTagger, s.mockUsage, s.config)
+}
+
+func TestImportService(t *testing.T) {
+	suite.Run(t, new(ImportServiceTestSuite))
+}
+
+// gzipLines compresses lines into an NDJSON file
+func gzipLines(lines ...string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, _ = gz.Write([]byte(strings.Join(lines, "\n") + "\n"))
+	_ = gz.Close()
+	return buf.Bytes()
+}
+
+// importLine is a valid line of an import file
+func importLine(id, action string) string {
+	return `{"id":"` + id + `","tenant_id":"other-tenant","action":"` + action + `","resource_type":"document","resource_id":"doc-1","message":"Imported from the legacy system","severity":"INFO","timestamp":"2019-03-01T12:00:00Z"}`
+}
+
+// serveFile makes the store return file for uri
+func (s *ImportServiceTestSuite) serveFile(uri string, file []byte) {
+	s.mockStore.On("Open", mock.Anything, uri).Return(io.NopCloser(bytes.NewReader(file)), int64(len(file)), nil)
+}
+
+func (s *ImportServiceTestSuite) TestUpload_RejectsFilesThatAreNotGzip() {
+	// Arrange
+	ctx := context.Background()
+	body := strings.NewReader(importLine("550e8400-e29b-41d4-a716-446655440000", "CREATE"))
+
+	// Act
+	job, err := s.service.Upload(ctx, "tenant1", body, int64(body.Len()))
+
+	// Assert
+	s.ErrorIs(err, ErrImportNotGzip)
+	s.Nil(job)
+	s.mockStore.AssertNotCalled(s.T(), "Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *ImportServiceTestSuite) TestUpload_StagesFileAndEnqueuesJob() {
+	// Arrange
+	ctx := context.Background()
+	file := gzipLines(importLine("550e8400-e29b-41d4-a716-446655440000", "CREATE"))
+
+	var staged []byte
+	s.mockStore.On("Put", mock.Anything, mock.MatchedBy(func(key string) bool {
+		return strings.HasPrefix(key, "imports/tenant1/") && strings.HasSuffix(key, ".ndjson.gz")
+	}), mock.Anything, int64(len(file))).Run(func(args mock.Arguments) {
+		staged, _ = io.ReadAll(args.Get(2).(io.Reader))
+	}).Return("s3://exports/imports/tenant1/job.ndjson.gz", nil)
+	s.mockJobs.On("Create", mock.Anything, mock.MatchedBy(func(job *domain.ImportJob) bool {
+		return job.TenantID == "tenant1" && job.Uploaded && job.Status == domain.JobPending
+	})).Return(nil)
+	s.mockPublisher.On("SendImportMessage", mock.Anything, "tenant1", mock.Anything).Return(nil)
+
+	// Act
+	job, err := s.service.Upload(ctx, "tenant1", bytes.NewReader(file), int64(len(file)))
+
+	// Assert
+	s.NoError(err)
+	s.Equal(string(domain.JobPending), job.Status)
+	s.Equal("s3://exports/imports/tenant1/job.ndjson.gz", job.SourceURI)
+	s.Equal(file, staged, "the peeked gzip header must be staged too")
+}
+
+func (s *ImportServiceTestSuite) TestImportFromS3_RejectsBucketsNotAllowed() {
+	// Arrange
+	ctx := context.Background()
+
+	// Act
+	job, err := s.service.ImportFromS3(ctx, "tenant1", "s3://audit-archives/audit-logs/tenant2/part-00001.ndjson.gz")
+
+	// Assert
+	s.ErrorIs(err, ErrImportSourceNotAllowed)
+	s.Nil(job)
+	s.mockJobs.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (s *ImportServiceTestSuite) TestImportFromS3_RejectsKeysOutsideTheTenantPrefix() {
+	// Arrange
+	ctx := context.Background()
+
+	for _, uri := range []string{
+		"s3://legacy-exports/tenant2/2019.ndjson.gz",
+		"s3://legacy-exports/tenant10/2019.ndjson.gz",
+		"s3://legacy-exports/2019.ndjson.gz",
+	} {
+		// Act
+		job, err := s.service.ImportFromS3(ctx, "tenant1", uri)
+
+		// Assert
+		s.ErrorIs(err, ErrImportKeyNotAllowed, uri)
+		s.Nil(job)
+	}
+	s.mockJobs.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (s *ImportServiceTestSuite) TestImportFromS3_EnqueuesFilesUnderTheTenantPrefix() {
+	// Arrange
+	ctx := context.Background()
+	uri := "s3://legacy-exports/tenant1/2019.ndjson.gz"
+	s.mockJobs.On("Create", mock.Anything, mock.MatchedBy(func(job *domain.ImportJob) bool {
+		return job.TenantID == "tenant1" && job.SourceURI == uri && !job.Uploaded
+	})).Return(nil)
+	s.mockPublisher.On("SendImportMessage", mock.Anything, "tenant1", mock.Anything).Return(nil)
+
+	// Act
+	job, err := s.service.ImportFromS3(ctx, "tenant1", uri)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(uri, job.SourceURI)
+	s.mockJobs.AssertExpectations(s.T())
+	s.mockPublisher.AssertExpectations(s.T())
+}
+
+func (s *ImportServiceTestSuite) TestRun_ImportsValidLinesAndReportsInvalidOnes() {
+	// Arrange
+	ctx := context.Background()
+	job := &domain.ImportJob{ID: "job1", TenantID: "tenant1", Status: domain.JobRunning, SourceURI: "s3://legacy-exports/2019.ndjson.gz"}
+	s.serveFile(job.SourceURI, gzipLines(
+		importLine("550e8400-e29b-41d4-a716-446655440000", "CREATE"),
+		"not json",
+		"",
+		`{"tenant_id":"tenant1","action":"UPDATE","resource_type":"document","resource_id":"doc-1","message":"Imported from the legacy system","timestamp":"2019-03-01T12:00:00Z"}`,
+		`{"action":"DELETE","resource_type":"document","resource_id":"doc-1","message":"Imported from the legacy system","severity":"INFO","timestamp":"2019-03-02T12:00:00Z"}`,
+	))
+
+	s.mockTagger.On("Tag", mock.Anything, mock.Anything).Return(nil)
+	s.mockRedactor.On("Redact", mock.Anything, mock.Anything).Return(nil)
+	var stored []domain.AuditLog
+	// One of the logs was imported before
+	s.mockAuditLog.On("Restore", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).([]domain.AuditLog)
+	}).Return(int64(1), nil)
+	s.mockPublisher.On("SendBulkIndexMessage", mock.Anything, mock.Anything).Return(nil)
+
+	var checkpoints int
+	progress := func(*domain.ImportJob) { checkpoints++ }
+
+	// Act
+	err := s.service.Run(ctx, job, progress)
+
+	// Assert
+	s.NoError(err)
+	s.Require().Len(stored, 2)
+	s.Equal("550e8400-e29b-41d4-a716-446655440000", stored[0].ID)
+	s.Equal("tenant1", stored[0].TenantID, "lines can't import logs into other tenants")
+	s.Equal(2019, stored[0].Timestamp.Year())
+	s.NotEmpty(stored[1].ID, "logs without an ID get one derived from the file and line")
+
+	s.Equal(int64(5), job.LinesRead)
+	s.Equal(int64(1), job.ImportedCount)
+	s.Equal(int64(1), job.SkippedCount)
+	s.Equal(int64(2), job.InvalidCount)
+	s.Require().Len(job.LineErrors, 2)
+	s.Equal(int64(2), job.LineErrors[0].Line)
+	s.Contains(job.LineErrors[0].Error, "invalid JSON")
+	s.Equal(int64(4), job.LineErrors[1].Line)
+	s.Contains(job.LineErrors[1].Error, "severity fails required")
+	s.Equal(job.SizeBytes, job.BytesRead)
+	s.Equal(1, checkpoints)
+}
+
+func (s *ImportServiceTestSuite) TestRun_ResumesAfterLastCheckpoint() {
+	// Arrange
+	ctx := context.Background()
+	job := &domain.ImportJob{ID: "job1", TenantID: "tenant1", Status: domain.JobRunning, SourceURI: "s3://legacy-exports/2019.ndjson.gz", LinesRead: 1, ImportedCount: 1}
+	s.serveFile(job.SourceURI, gzipLines(
+		importLine("550e8400-e29b-41d4-a716-446655440000", "CREATE"),
+		importLine("6ba7b810-9dad-11d1-80b4-00c04fd430c8", "UPDATE"),
+	))
+
+	s.mockTagger.On("Tag", mock.Anything, mock.Anything).Return(nil)
+	s.mockRedactor.On("Redact", mock.Anything, mock.Anything).Return(nil)
+	s.mockAuditLog.On("Restore", mock.Anything, mock.MatchedBy(func(logs []domain.AuditLog) bool {
+		return len(logs) == 1 && logs[0].ID == "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	})).Return(int64(1), nil)
+	s.mockPublisher.On("SendBulkIndexMessage", mock.Anything, mock.Anything).Return(nil)
+
+	// Act
+	err := s.service.Run(ctx, job, func(*domain.ImportJob) {})
+
+	// Assert
+	s.NoError(err)
+	s.Equal(int64(2), job.LinesRead)
+	s.Equal(int64(2), job.ImportedCount)
+	s.mockAuditLog.AssertNumberOfCalls(s.T(), "Restore", 1)
+}
+
+func (s *ImportServiceTestSuite) TestRun_TagsRedactsAndMetersLogs() {
+	// Arrange
+	ctx := context.Background()
+	job := &domain.ImportJob{ID: "job1", TenantID: "tenant1", Status: domain.JobRunning, SourceURI: "s3://legacy-exports/2019.ndjson.gz"}
+	s.serveFile(job.SourceURI, gzipLines(
+		importLine("550e8400-e29b-41d4-a716-446655440000", "CREATE"),
+		importLine("6ba7b810-9dad-11d1-80b4-00c04fd430c8", "UPDATE"),
+	))
+
+	s.mockTagger.On("Tag", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		logs := args.Get(1).([]domain.AuditLog)
+		for i := range logs {
+			logs[i].Tags = domain.Tags{"legacy"}
+		}
+	}).Return(nil)
+	s.mockRedactor.On("Redact", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		logs := args.Get(1).([]domain.AuditLog)
+		for i := range logs {
+			logs[i].Message = "[REDACTED]"
+		}
+	}).Return(nil)
+	var stored []domain.AuditLog
+	// One of the logs was imported before
+	s.mockAuditLog.On("Restore", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).([]domain.AuditLog)
+	}).Return(int64(1), nil)
+	s.mockPublisher.On("SendBulkIndexMessage", mock.Anything, mock.Anything).Return(nil)
+
+	// Act
+	err := s.service.Run(ctx, job, func(*domain.ImportJob) {})
+
+	// Assert
+	s.NoError(err)
+	s.Require().Len(stored, 2)
+	for _, log := range stored {
+		s.Equal(domain.Tags{"legacy"}, log.Tags)
+		s.Equal("[REDACTED]", log.Message)
+	}
+	s.mockUsage.AssertCalled(s.T(), "CheckQuota", mock.Anything, "tenant1", int64(2))
+	s.mockUsage.AssertCalled(s.T(), "Record", mock.Anything, "tenant1", int64(1), mock.Anything)
+}
+
+func (s *ImportServiceTestSuite) TestRun_QuotaExceeded_FailsWithoutStoring() {
+	// Arrange
+	ctx := context.Background()
+	usage := new(mocks.UsageTracker)
+	usage.On("CheckQuota", mock.Anything, "tenant1", int64(1)).Return(ErrMonthlyQuotaExceeded)
+	service := NewImportService(s.mockRepo, s.mockPublisher, s.mockStore, s.mockRedactor, s.mockTagger, usage, s.config)
+	job := &domain.ImportJob{ID: "job1", TenantID: "tenant1", Status: domain.JobRunning, SourceURI: "s3://legacy-exports/2019.ndjson.gz"}
+	s.serveFile(job.SourceURI, gzipLines(importLine("550e8400-e29b-41d4-a716-446655440000", "CREATE")))
+
+	// Act
+	err := service.Run(ctx, job, func(*domain.ImportJob) {})
+
+	// Assert
+	s.ErrorIs(err, ErrMonthlyQuotaExceeded)
+	s.Equal(int64(0), job.LinesRead, "progress stops at the last checkpoint")
+	s.mockAuditLog.AssertNotCalled(s.T(), "Restore", mock.Anything, mock.Anything)
+	usage.AssertNotCalled(s.T(), "Record", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *ImportServiceTestSuite) TestRun_SplitsIndexMessagesToFitTheQueue() {
+	// Arrange
+	ctx := context.Background()
+	job := &domain.ImportJob{ID: "job1", TenantID: "tenant1", Status: domain.JobRunning, SourceURI: "s3://legacy-exports/2019.ndjson.gz"}
+	// Three logs of 100KB don't fit in one message
+	blob := `{"blob":"` + strings.Repeat("x", 50*1024) + `"}`
+	states := `"severity":"INFO","before_state":` + blob + `,"metadata":` + blob
+	lines := make([]string, 3)
+	for i := range lines {
+		lines[i] = strings.Replace(importLine(uuid.NewString(), "CREATE"), `"severity":"INFO"`, states, 1)
+	}
+	s.serveFile(job.SourceURI, gzipLines(lines...))
+
+	s.mockTagger.On("Tag", mock.Anything, mock.Anything).Return(nil)
+	s.mockRedactor.On("Redact", mock.Anything, mock.Anything).Return(nil)
+	s.mockAuditLog.On("Restore", mock.Anything, mock.Anything).Return(int64(3), nil)
+	var indexed []int
+	s.mockPublisher.On("SendBulkIndexMessage", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		indexed = append(indexed, len(args.Get(1).([]domain.AuditLog)))
+	}).Return(nil)
+
+	// Act
+	err := s.service.Run(ctx, job, func(*domain.ImportJob) {})
+
+	// Assert
+	s.NoError(err)
+	s.Equal([]int{2, 1}, indexed)
+	s.Equal(int64(3), job.ImportedCount)
+}
+
+func (s *ImportServiceTestSuite) TestRun_RejectsLogsTooLargeToIndex() {
+	// Arrange
+	ctx := context.Background()
+	job := &domain.ImportJob{ID: "job1", TenantID: "tenant1", Status: domain.JobRunning, SourceURI: "s3://legacy-exports/2019.ndjson.gz"}
+	line := strings.Replace(importLine("550e8400-e29b-41d4-a716-446655440000", "CREATE"), "doc-1", strings.Repeat("x", maxIngestMessageSize), 1)
+	s.serveFile(job.SourceURI, gzipLines(line))
+
+	// Act
+	err := s.service.Run(ctx, job, func(*domain.ImportJob) {})
+
+	// Assert
+	s.NoError(err)
+	s.Equal(int64(1), job.InvalidCount)
+	s.Require().Len(job.LineErrors, 1)
+	s.Contains(job.LineErrors[0].Error, "larger than")
+	s.mockAuditLog.AssertNotCalled(s.T(), "Restore", mock.Anything, mock.Anything)
+	s.mockPublisher.AssertNotCalled(s.T(), "SendBulkIndexMessage", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+)
+
+// indexFailureLimit caps how many failures are listed or reprocessed at once
+const indexFailureLimit = 500
+
+type IndexFailureService struct {
+	repo      repository.Repository
+	publisher MessagePublisher
+}
+
+func NewIndexFailureService(repo repository.Repository, publisher MessagePublisher) *IndexFailureService {
+	return &IndexFailureService{
+		repo:      repo,
+		publisher: publisher,
+	}
+}
+
+// List returns the tenant's most recent index failures
+func (s *IndexFailureService) List(ctx context.Context, tenantID string) (_ []dto.IndexFailureResponse, err error) {
+	ctx, span := tracing.Start(ctx, "IndexFailureService.List", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	failures, err := s.repo.IndexFailure().ListByTenant(ctx, tenantID, indexFailureLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list index failures: %w", err)
+	}
+	return dto.FromIndexFailures(failures), nil
+}
+
+// Reprocess queues the documents of the given failures, or of the tenant's
+// most recent ones when ids is empty, for indexing and removes the failures.
+// Documents rejected again are stored again by the index worker.
+func (s *IndexFailureService) Reprocess(ctx context.Context, tenantID string, ids []string) (_ *dto.ReprocessIndexFailuresResponse, err error) {
+	ctx, span := tracing.Start(ctx, "IndexFailureService.Reprocess", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	var failures []domain.IndexFailure
+	if len(ids) == 0 {
+		failures, err = s.repo.IndexFailure().ListByTenant(ctx, tenantID, indexFailureLimit)
+	} else {
+		failures, err = s.repo.IndexFailure().GetByIDs(ctx, tenantID, ids)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get index failures: %w", err)
+	}
+	if len(failures) == 0 {
+		return &dto.ReprocessIndexFailuresResponse{}, nil
+	}
+
+	logs := make([]domain.AuditLog, len(failures))
+	failureIDs := make([]string, len(failures))
+	for i := range failures {
+		logs[i] = failures[i].Document
+		failureIDs[i] = failures[i].ID
+	}
+
+	// Bulk index messages share the size limit of ingest messages
+	chunks, _, err := IngestChunks(logs)
+	if err != nil {
+		return nil, err
+	}
+
+	// The failures are deleted before the logs are queued and kept if queueing
+	// fails. Their rows stay locked until then, so a failure the index worker
+	// stores again in the meantime isn't deleted with them.
+	err = s.repo.Transaction(ctx, func(tx repository.PostgresRepository) error {
+		if err := tx.IndexFailure().Delete(ctx, tenantID, failureIDs); err != nil {
+			return fmt.Errorf("failed to delete index failures: %w", err)
+		}
+		for _, chunk := range chunks {
+			if err := s.publisher.SendBulkIndexMessage(ctx, chunk); err != nil {
+				return fmt.Errorf("failed to queue logs for indexing: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dto.ReprocessIndexFailuresResponse{Reprocessed: len(failures)}, nil
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/mocks"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type IndexFailureServiceTestSuite struct {
+	suite.Suite
+	mockRepo      *mocks.Repository
+	mockFailures  *mocks.IndexFailureRepository
+	mockPublisher *mocks.MessagePublisher
+	service       *IndexFailureService
+}
+
+func (s *IndexFailureServiceTestSuite) SetupTest() {
+	s.mockRepo = new(mocks.Repository)
+	s.mockFailures = new(mocks.IndexFailureRepository)
+	s.mockPublisher = new(mocks.MessagePublisher)
+
+	s.mockRepo.On("IndexFailure").Return(s.mockFailures)
+	s.mockRepo.On("Transaction", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, fn func(tx repository.PostgresRepository) error) error {
+			return fn(s.mockRepo)
+		})
+
+	s.service = NewIndexFailureService(s.mockRepo, s.mockPublisher)
+}
+
+func TestIndexFailureService(t *testing.T) {
+	suite.Run(t, new(IndexFailureServiceTestSuite))
+}
+
+func (s *IndexFailureServiceTestSuite) failures() []domain.IndexFailure {
+	return []domain.IndexFailure{
+		{ID: "failure1", TenantID: "tenant1", LogID: "log1", Status: 400, Document: domain.AuditLog{ID: "log1", TenantID: "tenant1"}},
+		{ID: "failure2", TenantID: "tenant1", LogID: "log2", Status: 429, Document: domain.AuditLog{ID: "log2", TenantID: "tenant1"}},
+	}
+}
+
+func (s *IndexFailureServiceTestSuite) TestList_Success() {
+	// Arrange
+	ctx := context.Background()
+	s.mockFailures.On("ListByTenant", mock.Anything, "tenant1", indexFailureLimit).Return(s.failures(), nil)
+
+	// Act
+	failures, err := s.service.List(ctx, "tenant1")
+
+	// Assert
+	s.NoError(err)
+	s.Len(failures, 2)
+	s.Equal("log1", failures[0].LogID)
+	s.Equal(429, failures[1].Status)
+}
+
+func (s *IndexFailureServiceTestSuite) TestReprocess_ByIDs() {
+	// Arrange
+	ctx := context.Background()
+	s.mockFailures.On("GetByIDs", mock.Anything, "tenant1", []string{"failure1", "failure2"}).Return(s.failures(), nil)
+	s.mockFailures.On("Delete", mock.Anything, "tenant1", []string{"failure1", "failure2"}).Return(nil)
+	s.mockPublisher.On("SendBulkIndexMessage", mock.Anything, mock.MatchedBy(func(logs []domain.AuditLog) bool {
+		return len(logs) == 2 && logs[0].ID == "log1" && logs[1].ID == "log2"
+	})).Return(nil)
+
+	// Act
+	result, err := s.service.Reprocess(ctx, "tenant1", []string{"failure1", "failure2"})
+
+	// Assert
+	s.NoError(err)
+	s.Equal(2, result.Reprocessed)
+	s.mockFailures.AssertNotCalled(s.T(), "ListByTenant", mock.Anything, mock.Anything, mock.Anything)
+	s.mockPublisher.AssertExpectations(s.T())
+	s.mockFailures.AssertExpectations(s.T())
+}
+
+func (s *IndexFailureServiceTestSuite) TestReprocess_AllRecent() {
+	// Arrange
+	ctx := context.Background()
+	s.mockFailures.On("ListByTenant", mock.Anything, "tenant1", indexFailureLimit).Return(s.failures(), nil)
+	s.mockFailures.On("Delete", mock.Anything, "tenant1", []string{"failure1", "failure2"}).Return(nil)
+	s.mockPublisher.On("SendBulkIndexMessage", mock.Anything, mock.Anything).Return(nil)
+
+	// Act
+	result, err := s.service.Reprocess(ctx, "tenant1", nil)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(2, result.Reprocessed)
+	s.mockFailures.AssertExpectations(s.T())
+}
+
+func (s *IndexFailureServiceTestSuite) TestReprocess_NothingToReprocess() {
+	// Arrange
+	ctx := context.Background()
+	s.mockFailures.On("GetByIDs", mock.Anything, "tenant1", []string{"missing"}).Return([]domain.IndexFailure{}, nil)
+
+	// Act
+	result, err := s.service.Reprocess(ctx, "tenant1", []string{"missing"})
+
+	// Assert
+	s.NoError(err)
+	s.Equal(0, result.Reprocessed)
+	s.mockPublisher.AssertNotCalled(s.T(), "SendBulkIndexMessage", mock.Anything, mock.Anything)
+	s.mockFailures.AssertNotCalled(s.T(), "Delete", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *IndexFailureServiceTestSuite) TestReprocess_QueueError() {
+	// Arrange
+	ctx := context.Background()
+	s.mockFailures.On("ListByTenant", mock.Anything, "tenant1", indexFailureLimit).Return(s.failures(), nil)
+	s.mockFailures.On("Delete", mock.Anything, "tenant1", mock.Anything).Return(nil)
+	s.mockPublisher.On("SendBulkIndexMessage", mock.Anything, mock.Anything).Return(errors.New("queue unavailable"))
+
+	// Act
+	result, err := s.service.Reprocess(ctx, "tenant1", nil)
+
+	// Assert
+	s.Error(err)
+	s.Nil(result)
+	s.Contains(err.Error(), "queue unavailable")
+}
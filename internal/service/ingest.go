@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+)
+
+// IngestService stores the logs AuditLogService accepted for asynchronous ingestion
+type IngestService struct {
+	repo      repository.PostgresRepository
+	watermark IngestWatermark
+}
+
+func NewIngestService(repo repository.PostgresRepository) *IngestService {
+	return &IngestService{repo: repo}
+}
+
+// UseWatermark makes storing logs advance their tenant's ingest watermark
+func (s *IngestService) UseWatermark(watermark IngestWatermark) {
+	s.watermark = watermark
+}
+
+// Store stores accepted logs together with a bulk outbox event, so they are
+// indexed and broadcast like logs created synchronously. Logs keep the IDs
+// assigned on acceptance and logs already stored are skipped, which makes
+// storing a redelivered message again a no-op.
+func (s *IngestService) Store(ctx context.Context, logs []domain.AuditLog) (err error) {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	ctx, span := tracing.Start(ctx, "IngestService.Store", trace.WithAttributes(
+		tracing.TenantAttr(logs[0].TenantID),
+		attribute.Int("audit_log.count", len(logs)),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	var stored int64
+	err = s.repo.Transaction(ctx, func(tx repository.PostgresRepository) error {
+		var err error
+		stored, err = tx.AuditLog().Restore(ctx, logs)
+		if err != nil {
+			return fmt.Errorf("failed to store accepted logs: %w", err)
+		}
+		if stored == 0 {
+			return nil
+		}
+
+		_, err = createBulkOutboxEvents(ctx, tx.Outbox(), logs)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	metrics.LogsIngestedTotal.WithLabelValues(logs[0].TenantID).Add(float64(stored))
+	// Best effort: ETags and cached stats expire shortly anyway
+	if s.watermark != nil && stored > 0 {
+		_ = s.watermark.Advance(ctx, logs[0].TenantID)
+	}
+	return nil
+}
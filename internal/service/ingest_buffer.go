@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+	"github.com/kingrain94/audit-log-api/internal/utils"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+const (
+	flushTriggerSize     = "size"
+	flushTriggerInterval = "interval"
+	flushTriggerDrain    = "drain"
+)
+
+// ingestBuffer is a write-behind buffer for single log creations. Logs are
+// collected per tenant and stored as one batch once a tenant's batch is full
+// or the flush interval passes; stopping stores whatever is left.
+type ingestBuffer struct {
+	store        func(ctx context.Context, logs []domain.AuditLog) error
+	config       *config.IngestBufferConfig
+	logger       *logger.Logger
+	mu           sync.Mutex
+	batches      map[string]*pendingBatch
+	stopped      bool
+	shutdownChan chan struct{}
+	waitGroup    sync.WaitGroup
+}
+
+// pendingBatch holds a tenant's buffered logs. Flushes outlive the requests
+// that buffered the logs, so only the tenant claims the repository reads are
+// kept from their context.
+type pendingBatch struct {
+	claims any
+	logs   []domain.AuditLog
+}
+
+func newIngestBuffer(store func(ctx context.Context, logs []domain.AuditLog) error, config *config.IngestBufferConfig, logger *logger.Logger) *ingestBuffer {
+	return &ingestBuffer{
+		store:        store,
+		config:       config,
+		logger:       logger,
+		batches:      make(map[string]*pendingBatch),
+		shutdownChan: make(chan struct{}),
+	}
+}
+
+func (b *ingestBuffer) start() {
+	b.waitGroup.Add(1)
+	go b.flushLoop()
+}
+
+// stop stops buffering and stores the buffered logs, giving up after the
+// drain timeout
+func (b *ingestBuffer) stop() {
+	b.mu.Lock()
+	b.stopped = true
+	b.mu.Unlock()
+
+	close(b.shutdownChan)
+	b.waitGroup.Wait()
+}
+
+// add buffers a log and reports false once the buffer is stopped. The caller
+// that fills a tenant's batch stores it, so ingestion slows down to the rate
+// batches are written at rather than growing the buffer.
+func (b *ingestBuffer) add(ctx context.Context, log domain.AuditLog) (bool, error) {
+	b.mu.Lock()
+	if b.stopped {
+		b.mu.Unlock()
+		return false, nil
+	}
+	batch, ok := b.batches[log.TenantID]
+	if !ok {
+		batch = &pendingBatch{claims: ctx.Value(utils.ClaimsKey)}
+		b.batches[log.TenantID] = batch
+	}
+	batch.logs = append(batch.logs, log)
+	var full *pendingBatch
+	if len(batch.logs) >= b.config.BatchSize {
+		full = batch
+		delete(b.batches, log.TenantID)
+	}
+	b.mu.Unlock()
+	metrics.IngestBufferPendingLogs.Inc()
+
+	if full != nil {
+		return true, b.flush(context.Background(), full, flushTriggerSize)
+	}
+	return true, nil
+}
+
+func (b *ingestBuffer) flushLoop() {
+	defer b.waitGroup.Done()
+
+	ticker := time.NewTicker(b.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.shutdownChan:
+			ctx, cancel := context.WithTimeout(context.Background(), b.config.DrainTimeout)
+			defer cancel()
+			b.flushAll(ctx, flushTriggerDrain)
+			return
+		case <-ticker.C:
+			b.flushAll(context.Background(), flushTriggerInterval)
+		}
+	}
+}
+
+func (b *ingestBuffer) flushAll(ctx context.Context, trigger string) {
+	b.mu.Lock()
+	batches := b.batches
+	b.batches = make(map[string]*pendingBatch)
+	b.mu.Unlock()
+
+	for _, batch := range batches {
+		_ = b.flush(ctx, batch, trigger)
+	}
+}
+
+// flush stores a batch; the logs of a failed flush are lost, as their
+// creation was already acknowledged
+func (b *ingestBuffer) flush(ctx context.Context, batch *pendingBatch, trigger string) (err error) {
+	tenantID := batch.logs[0].TenantID
+	ctx = context.WithValue(ctx, utils.ClaimsKey, batch.claims)
+	ctx, span := tracing.Start(ctx, "AuditLogService.flushIngestBuffer", trace.WithAttributes(
+		tracing.TenantAttr(tenantID),
+		attribute.Int("audit_log.count", len(batch.logs)),
+		attribute.String("flush.trigger", trigger),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	start := time.Now()
+	err = b.store(ctx, batch.logs)
+	metrics.IngestBufferPendingLogs.Sub(float64(len(batch.logs)))
+	metrics.IngestBufferFlushDuration.Observe(time.Since(start).Seconds())
+	metrics.IngestBufferFlushSize.Observe(float64(len(batch.logs)))
+
+	status := "success"
+	if err != nil {
+		status = "error"
+		b.logger.Errorf("Write-behind buffer failed to store %d logs of tenant %s: %v", len(batch.logs), tenantID, err)
+	}
+	metrics.IngestBufferFlushesTotal.WithLabelValues(trigger, status).Inc()
+	return err
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/mocks"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type IngestServiceTestSuite struct {
+	suite.Suite
+	mockRepo     *mocks.PostgresRepository
+	mockAuditLog *mocks.AuditLogRepository
+	mockOutbox   *mocks.OutboxRepository
+	service      *IngestService
+}
+
+func (s *IngestServiceTestSuite) SetupTest() {
+	s.mockRepo = new(mocks.PostgresRepository)
+	s.mockAuditLog = new(mocks.AuditLogRepository)
+	s.mockOutbox = new(mocks.OutboxRepository)
+
+	s.mockRepo.On("AuditLog").Return(s.mockAuditLog)
+	s.mockRepo.On("Outbox").Return(s.mockOutbox)
+	s.mockRepo.On("Transaction", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, fn func(tx repository.PostgresRepository) error) error {
+			return fn(s.mockRepo)
+		})
+
+	s.service = NewIngestService(s.mockRepo)
+}
+
+func TestIngestService(t *testing.T) {
+	suite.Run(t, new(IngestServiceTestSuite))
+}
+
+func (s *IngestServiceTestSuite) TestStore_StoresLogsWithOutboxEvent() {
+	// Arrange
+	ctx := context.Background()
+	logs := []domain.AuditLog{
+		{ID: "log1", TenantID: "tenant1", Action: "CREATE", Timestamp: time.Now()},
+		{ID: "log2", TenantID: "tenant1", Action: "UPDATE", Timestamp: time.Now()},
+	}
+	s.mockAuditLog.On("Restore", mock.Anything, logs).Return(int64(2), nil)
+	s.mockOutbox.On("Create", mock.Anything, mock.MatchedBy(func(e *domain.OutboxEvent) bool {
+		return e.EventType == domain.OutboxEventBulkIndex && e.TenantID == "tenant1"
+	})).Return(nil)
+
+	// Act
+	err := s.service.Store(ctx, logs)
+
+	// Assert
+	s.NoError(err)
+	s.mockAuditLog.AssertExpectations(s.T())
+	s.mockOutbox.AssertExpectations(s.T())
+}
+
+func (s *IngestServiceTestSuite) TestStore_AlreadyStored_SkipsOutboxEvent() {
+	// Arrange
+	ctx := context.Background()
+	logs := []domain.AuditLog{{ID: "log1", TenantID: "tenant1", Action: "CREATE", Timestamp: time.Now()}}
+	s.mockAuditLog.On("Restore", mock.Anything, logs).Return(int64(0), nil)
+
+	// Act
+	err := s.service.Store(ctx, logs)
+
+	// Assert
+	s.NoError(err)
+	s.mockOutbox.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (s *IngestServiceTestSuite) TestStore_Failure_ReturnsError() {
+	// Arrange
+	ctx := context.Background()
+	logs := []domain.AuditLog{{ID: "log1", TenantID: "tenant1", Action: "CREATE", Timestamp: time.Now()}}
+	s.mockAuditLog.On("Restore", mock.Anything, logs).Return(int64(0), errors.New("connection refused"))
+
+	// Act
+	err := s.service.Store(ctx, logs)
+
+	// Assert
+	s.Error(err)
+	s.mockOutbox.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+	"github.com/kingrain94/audit-log-api/pkg/safehttp"
+)
+
+// defaultDatadogSite is the Datadog site of integrations that don't set one
+const defaultDatadogSite = "datadoghq.com"
+
+// IntegrationService manages the tenants' log forwarding integrations, which
+// the forwarding worker delivers logs for. Tokens are stored encrypted by
+// tokens; without it, integrations can't be created or given new tokens.
+type IntegrationService struct {
+	repo   repository.Repository
+	tokens IntegrationTokenSealer
+}
+
+func NewIntegrationService(repo repository.Repository, tokens IntegrationTokenSealer) *IntegrationService {
+	return &IntegrationService{
+		repo:   repo,
+		tokens: tokens,
+	}
+}
+
+// Create adds an integration forwarding the tenant's logs stored from now on
+func (s *IntegrationService) Create(ctx context.Context, tenantID string, req dto.IntegrationRequest) (_ *dto.IntegrationResponse, err error) {
+	ctx, span := tracing.Start(ctx, "IntegrationService.Create", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	integration := &domain.Integration{
+		TenantID:        tenantID,
+		CursorCreatedAt: time.Now().UTC(),
+		CursorID:        uuid.Nil.String(),
+	}
+	if err := applyIntegrationRequest(integration, req); err != nil {
+		return nil, err
+	}
+	if err := s.sealToken(integration, req.Token); err != nil {
+		return nil, err
+	}
+	if err := s.checkDuplicateName(ctx, integration); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Integration().Create(ctx, integration); err != nil {
+		return nil, fmt.Errorf("failed to create integration: %w", err)
+	}
+
+	return dto.FromIntegration(integration), nil
+}
+
+func (s *IntegrationService) List(ctx context.Context, tenantID string) (_ []dto.IntegrationResponse, err error) {
+	ctx, span := tracing.Start(ctx, "IntegrationService.List", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	integrations, err := s.repo.Integration().ListByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromIntegrations(integrations), nil
+}
+
+func (s *IntegrationService) Get(ctx context.Context, tenantID, id string) (_ *dto.IntegrationResponse, err error) {
+	ctx, span := tracing.Start(ctx, "IntegrationService.Get", trace.WithAttributes(tracing.TenantAttr(tenantID), attribute.String("integration.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	integration, err := s.getIntegration(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromIntegration(integration), nil
+}
+
+// Update replaces an integration's settings, keeping its token when the
+// request has none. Forwarding resumes where it stopped, at once if it was
+// backing off from failed deliveries. Unless the request disables it, an
+// integration the forwarding worker disabled after failures is enabled again.
+func (s *IntegrationService) Update(ctx context.Context, tenantID, id string, req dto.IntegrationRequest) (_ *dto.IntegrationResponse, err error) {
+	ctx, span := tracing.Start(ctx, "IntegrationService.Update", trace.WithAttributes(tracing.TenantAttr(tenantID), attribute.String("integration.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	integration, err := s.getIntegration(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	keepToken := req.Token == "" && domain.IntegrationType(req.Type) == integration.Type
+	if err := applyIntegrationRequest(integration, req); err != nil {
+		return nil, err
+	}
+	if !keepToken {
+		if err := s.sealToken(integration, req.Token); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.checkDuplicateName(ctx, integration); err != nil {
+		return nil, err
+	}
+	integration.NextAttemptAt = nil
+	integration.UpdatedAt = time.Now()
+
+	if err := s.repo.Integration().Update(ctx, integration); err != nil {
+		return nil, fmt.Errorf("failed to update integration: %w", err)
+	}
+
+	return dto.FromIntegration(integration), nil
+}
+
+func (s *IntegrationService) Delete(ctx context.Context, tenantID, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "IntegrationService.Delete", trace.WithAttributes(tracing.TenantAttr(tenantID), attribute.String("integration.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	err = s.repo.Integration().Delete(ctx, tenantID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrIntegrationNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete integration: %w", err)
+	}
+	return nil
+}
+
+func (s *IntegrationService) getIntegration(ctx context.Context, tenantID, id string) (*domain.Integration, error) {
+	integration, err := s.repo.Integration().GetByID(ctx, tenantID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrIntegrationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get integration: %w", err)
+	}
+	return integration, nil
+}
+
+// checkDuplicateName returns ErrIntegrationExists if the tenant has another integration with the same name
+func (s *IntegrationService) checkDuplicateName(ctx context.Context, integration *domain.Integration) error {
+	existing, err := s.repo.Integration().ListByTenant(ctx, integration.TenantID)
+	if err != nil {
+		return fmt.Errorf("failed to list integrations: %w", err)
+	}
+	for _, e := range existing {
+		if e.ID != integration.ID && e.Name == integration.Name {
+			return ErrIntegrationExists
+		}
+	}
+	return nil
+}
+
+// sealToken stores the encrypted token on the integration, failing without
+// one or when tokens can't be encrypted
+func (s *IntegrationService) sealToken(integration *domain.Integration, token string) error {
+	if token == "" {
+		return ErrInvalidIntegration
+	}
+	if s.tokens == nil {
+		return ErrIntegrationTokensDisabled
+	}
+	sealed, err := s.tokens.Seal(token)
+	if err != nil {
+		return err
+	}
+	integration.SealedToken = sealed
+	integration.TokenHint = ""
+	if len(token) > 4 {
+		integration.TokenHint = token[len(token)-4:]
+	}
+	return nil
+}
+
+// applyIntegrationRequest sets the fields of the request but the token on
+// integration, failing for Splunk without the collector's URL, or when logs
+// would be posted other than over https to a public host
+func applyIntegrationRequest(integration *domain.Integration, req dto.IntegrationRequest) error {
+	integration.Name = strings.TrimSpace(req.Name)
+	integration.Type = domain.IntegrationType(req.Type)
+	integration.Enabled = req.Enabled == nil || *req.Enabled
+	integration.Settings = domain.IntegrationSettings(req.Settings)
+	integration.Filter = domain.IntegrationFilter(req.Filter)
+
+	switch integration.Type {
+	case domain.IntegrationSplunk:
+		if integration.Settings.URL == "" {
+			return ErrInvalidIntegration
+		}
+	case domain.IntegrationDatadog:
+		if integration.Settings.Site == "" {
+			integration.Settings.Site = defaultDatadogSite
+		}
+	}
+	if err := safehttp.ValidateURL(integrationBaseURL(integration)); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidIntegration, err)
+	}
+	return nil
+}
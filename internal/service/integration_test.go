@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type IntegrationServiceTestSuite struct {
+	suite.Suite
+	mockRepo        *mocks.Repository
+	mockIntegration *mocks.IntegrationRepository
+	mockTokens      *mocks.IntegrationTokenSealer
+	service         *IntegrationService
+}
+
+func (s *IntegrationServiceTestSuite) SetupTest() {
+	s.mockRepo = new(mocks.Repository)
+	s.mockIntegration = new(mocks.IntegrationRepository)
+	s.mockTokens = new(mocks.IntegrationTokenSealer)
+
+	s.mockRepo.On("Integration").Return(s.mockIntegration)
+	s.mockTokens.On("Seal", mock.Anything).Return([]byte("sealed"), nil).Maybe()
+
+	s.service = NewIntegrationService(s.mockRepo, s.mockTokens)
+}
+
+func TestIntegrationService(t *testing.T) {
+	suite.Run(t, new(IntegrationServiceTestSuite))
+}
+
+func (s *IntegrationServiceTestSuite) TestCreate_Datadog_StartsAtCreationWithDefaultSite() {
+	// Arrange
+	ctx := context.Background()
+	s.mockIntegration.On("ListByTenant", mock.Anything, "tenant1").Return([]domain.Integration{}, nil)
+	var created *domain.Integration
+	s.mockIntegration.On("Create", mock.Anything, mock.AnythingOfType("*domain.Integration")).
+		Run(func(args mock.Arguments) { created = args.Get(1).(*domain.Integration) }).
+		Return(nil)
+
+	// Act
+	resp, err := s.service.Create(ctx, "tenant1", dto.IntegrationRequest{
+		Name:  " datadog ",
+		Type:  "datadog",
+		Token: "0123456789abcdef",
+	})
+
+	// Assert
+	s.Require().NoError(err)
+	s.Equal("datadog", created.Name)
+	s.True(created.Enabled)
+	s.Equal("datadoghq.com", created.Settings.Site)
+	s.False(created.CursorCreatedAt.IsZero())
+	s.Equal(uuid.Nil.String(), created.CursorID)
+	s.mockTokens.AssertCalled(s.T(), "Seal", "0123456789abcdef")
+	s.Equal([]byte("sealed"), created.SealedToken)
+	s.Empty(created.Token, "tokens are only stored encrypted")
+	s.Equal("********cdef", resp.Token)
+}
+
+func (s *IntegrationServiceTestSuite) TestCreate_WithoutTokenEncryption_ReturnsErrIntegrationTokensDisabled() {
+	// Arrange
+	ctx := context.Background()
+	service := NewIntegrationService(s.mockRepo, nil)
+
+	// Act
+	_, err := service.Create(ctx, "tenant1", dto.IntegrationRequest{
+		Name:  "datadog",
+		Type:  "datadog",
+		Token: "0123456789abcdef",
+	})
+
+	// Assert
+	s.ErrorIs(err, ErrIntegrationTokensDisabled)
+	s.mockIntegration.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (s *IntegrationServiceTestSuite) TestCreate_SplunkWithoutURL_ReturnsErrInvalidIntegration() {
+	// Arrange
+	ctx := context.Background()
+
+	// Act
+	_, err := s.service.Create(ctx, "tenant1", dto.IntegrationRequest{
+		Name:  "splunk",
+		Type:  "splunk",
+		Token: "hec-token",
+	})
+
+	// Assert
+	s.ErrorIs(err, ErrInvalidIntegration)
+	s.mockIntegration.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (s *IntegrationServiceTestSuite) TestCreate_URLNotPublicHTTPS_ReturnsErrInvalidIntegration() {
+	// Arrange
+	ctx := context.Background()
+
+	for _, url := range []string{"http://splunk.example.com:8088", "https://169.254.169.254", "https://10.0.0.5:9200", "https://localhost:8088"} {
+		// Act
+		_, err := s.service.Create(ctx, "tenant1", dto.IntegrationRequest{
+			Name:     "splunk",
+			Type:     "splunk",
+			Token:    "hec-token",
+			Settings: dto.IntegrationSettings{URL: url},
+		})
+
+		// Assert
+		s.ErrorIs(err, ErrInvalidIntegration, url)
+	}
+	s.mockIntegration.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (s *IntegrationServiceTestSuite) TestCreate_DuplicateName_ReturnsErrIntegrationExists() {
+	// Arrange
+	ctx := context.Background()
+	s.mockIntegration.On("ListByTenant", mock.Anything, "tenant1").
+		Return([]domain.Integration{{ID: "integration1", Name: "splunk"}}, nil)
+
+	// Act
+	_, err := s.service.Create(ctx, "tenant1", dto.IntegrationRequest{
+		Name:     "splunk",
+		Type:     "splunk",
+		Token:    "hec-token",
+		Settings: dto.IntegrationSettings{URL: "https://splunk.example.com:8088"},
+	})
+
+	// Assert
+	s.ErrorIs(err, ErrIntegrationExists)
+}
+
+func (s *IntegrationServiceTestSuite) TestUpdate_WithoutToken_KeepsTokenAndResumesAtOnce() {
+	// Arrange
+	ctx := context.Background()
+	nextAttemptAt := time.Now().Add(time.Hour)
+	existing := &domain.Integration{
+		ID:            "integration1",
+		TenantID:      "tenant1",
+		Name:          "splunk",
+		Type:          domain.IntegrationSplunk,
+		SealedToken:   []byte("sealed-hec-token"),
+		TokenHint:     "oken",
+		Failures:      3,
+		NextAttemptAt: &nextAttemptAt,
+	}
+	s.mockIntegration.On("GetByID", mock.Anything, "tenant1", "integration1").Return(existing, nil)
+	s.mockIntegration.On("ListByTenant", mock.Anything, "tenant1").Return([]domain.Integration{*existing}, nil)
+	s.mockIntegration.On("Update", mock.Anything, existing).Return(nil)
+
+	// Act
+	_, err := s.service.Update(ctx, "tenant1", "integration1", dto.IntegrationRequest{
+		Name:     "splunk",
+		Type:     "splunk",
+		Settings: dto.IntegrationSettings{URL: "https://splunk.example.com:8088", Index: "audit"},
+	})
+
+	// Assert
+	s.Require().NoError(err)
+	s.Equal([]byte("sealed-hec-token"), existing.SealedToken)
+	s.Equal("oken", existing.TokenHint)
+	s.mockTokens.AssertNotCalled(s.T(), "Seal", mock.Anything)
+	s.Equal("audit", existing.Settings.Index)
+	s.Nil(existing.NextAttemptAt)
+}
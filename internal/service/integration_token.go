@@ -0,0 +1,73 @@
+package service
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/kingrain94/audit-log-api/pkg/envelope"
+)
+
+// IntegrationTokenSealer encrypts the tokens of integrations to be stored
+//
+//go:generate mockery --name IntegrationTokenSealer --output ../mocks
+type IntegrationTokenSealer interface {
+	Seal(token string) ([]byte, error)
+}
+
+// IntegrationTokenOpener decrypts the stored tokens of integrations
+//
+//go:generate mockery --name IntegrationTokenOpener --output ../mocks
+type IntegrationTokenOpener interface {
+	Open(sealed []byte) (string, error)
+}
+
+// IntegrationTokenCipher encrypts integration tokens with pkg/envelope, as
+// exports are, for an RSA key pair. Sealing needs only the public key, so the
+// API can store tokens it can't read back; opening needs the private key.
+type IntegrationTokenCipher struct {
+	public  *rsa.PublicKey
+	private *rsa.PrivateKey
+}
+
+// NewIntegrationTokenSealer returns a cipher that only seals tokens
+func NewIntegrationTokenSealer(public *rsa.PublicKey) *IntegrationTokenCipher {
+	return &IntegrationTokenCipher{public: public}
+}
+
+// NewIntegrationTokenOpener returns a cipher that seals and opens tokens
+func NewIntegrationTokenOpener(private *rsa.PrivateKey) *IntegrationTokenCipher {
+	return &IntegrationTokenCipher{public: &private.PublicKey, private: private}
+}
+
+func (c *IntegrationTokenCipher) Seal(token string) ([]byte, error) {
+	var sealed bytes.Buffer
+	w, err := envelope.NewWriter(&sealed, c.public)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt integration token: %w", err)
+	}
+	if _, err := io.WriteString(w, token); err != nil {
+		return nil, fmt.Errorf("failed to encrypt integration token: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encrypt integration token: %w", err)
+	}
+	return sealed.Bytes(), nil
+}
+
+func (c *IntegrationTokenCipher) Open(sealed []byte) (string, error) {
+	if c.private == nil {
+		return "", errors.New("integration tokens can't be decrypted without the private key")
+	}
+	r, err := envelope.NewReader(bytes.NewReader(sealed), c.private)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt integration token: %w", err)
+	}
+	token, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt integration token: %w", err)
+	}
+	return string(token), nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type IntegrationTokenCipherTestSuite struct {
+	suite.Suite
+	key *rsa.PrivateKey
+}
+
+func (s *IntegrationTokenCipherTestSuite) SetupSuite() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+	s.key = key
+}
+
+func TestIntegrationTokenCipher(t *testing.T) {
+	suite.Run(t, new(IntegrationTokenCipherTestSuite))
+}
+
+func (s *IntegrationTokenCipherTestSuite) TestSeal_OpensWithPrivateKeyOnly() {
+	// Arrange
+	sealer := NewIntegrationTokenSealer(&s.key.PublicKey)
+	opener := NewIntegrationTokenOpener(s.key)
+
+	// Act
+	sealed, err := sealer.Seal("5f1c2e9a-7b3d-4c8e-9a1f-2d6b8e4c0a73")
+	s.Require().NoError(err)
+	token, openErr := opener.Open(sealed)
+	_, sealerErr := sealer.Open(sealed)
+
+	// Assert
+	s.NotContains(string(sealed), "5f1c2e9a")
+	s.NoError(openErr)
+	s.Equal("5f1c2e9a-7b3d-4c8e-9a1f-2d6b8e4c0a73", token)
+	s.Error(sealerErr, "the API can't read tokens back")
+}
+
+func (s *IntegrationTokenCipherTestSuite) TestOpen_Tampered_ReturnsError() {
+	// Arrange
+	opener := NewIntegrationTokenOpener(s.key)
+	sealed, err := opener.Seal("hec-token")
+	s.Require().NoError(err)
+	sealed[len(sealed)-1] ^= 1
+
+	// Act
+	_, err = opener.Open(sealed)
+
+	// Assert
+	s.Error(err)
+}
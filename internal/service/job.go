@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+)
+
+// JobService reports the status of a tenant's background export, restore,
+// cleanup, reindex and import jobs
+type JobService struct {
+	repo repository.Repository
+}
+
+func NewJobService(repo repository.Repository) *JobService {
+	return &JobService{repo: repo}
+}
+
+// List returns a page of the tenant's jobs, newest first
+func (s *JobService) List(ctx context.Context, filter *domain.JobFilter) (_ []dto.JobResponse, err error) {
+	ctx, span := tracing.Start(ctx, "JobService.List", trace.WithAttributes(tracing.TenantAttr(filter.TenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	// Set default values for pagination
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = 10
+	}
+
+	// Convert page and page size to limit and offset
+	filter.Limit = filter.PageSize
+	filter.Offset = (filter.Page - 1) * filter.PageSize
+
+	jobs, err := s.repo.Job().List(ctx, *filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	return dto.FromJobs(jobs), nil
+}
+
+// Get returns one of the tenant's jobs
+func (s *JobService) Get(ctx context.Context, tenantID, id string) (_ *dto.JobResponse, err error) {
+	ctx, span := tracing.Start(ctx, "JobService.Get", trace.WithAttributes(tracing.TenantAttr(tenantID), attribute.String("job.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	job, err := s.repo.Job().GetByID(ctx, tenantID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return dto.FromJob(job), nil
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type JobServiceTestSuite struct {
+	suite.Suite
+	mockRepo *mocks.Repository
+	mockJob  *mocks.JobRepository
+	service  *JobService
+}
+
+func (s *JobServiceTestSuite) SetupTest() {
+	s.mockRepo = new(mocks.Repository)
+	s.mockJob = new(mocks.JobRepository)
+
+	s.mockRepo.On("Job").Return(s.mockJob)
+
+	s.service = NewJobService(s.mockRepo)
+}
+
+func TestJobService(t *testing.T) {
+	suite.Run(t, new(JobServiceTestSuite))
+}
+
+func (s *JobServiceTestSuite) TestList_AppliesPaginationDefaults() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.JobFilter{TenantID: "tenant1", Type: domain.JobTypeCleanup, Page: 3}
+
+	s.mockJob.On("List", mock.Anything, mock.MatchedBy(func(f domain.JobFilter) bool {
+		return f.Type == domain.JobTypeCleanup && f.Limit == 10 && f.Offset == 20
+	})).Return([]domain.Job{{
+		ID:     "job1",
+		Type:   domain.JobTypeCleanup,
+		Status: domain.JobCompleted,
+		Counts: map[string]int64{"archived": 5, "deleted": 5, "indexed": 5},
+	}}, nil)
+
+	// Act
+	resp, err := s.service.List(ctx, filter)
+
+	// Assert
+	s.NoError(err)
+	s.Len(resp, 1)
+	s.Equal("cleanup", resp[0].Type)
+	s.Equal(int64(5), resp[0].Counts["deleted"])
+	s.mockJob.AssertExpectations(s.T())
+}
+
+func (s *JobServiceTestSuite) TestGet_NotFound() {
+	// Arrange
+	ctx := context.Background()
+	s.mockJob.On("GetByID", mock.Anything, "tenant1", "missing").Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	resp, err := s.service.Get(ctx, "tenant1", "missing")
+
+	// Assert
+	s.ErrorIs(err, ErrJobNotFound)
+	s.Nil(resp)
+}
+
+func (s *JobServiceTestSuite) TestGet_RepositoryError() {
+	// Arrange
+	ctx := context.Background()
+	s.mockJob.On("GetByID", mock.Anything, "tenant1", "job1").Return(nil, errors.New("connection refused"))
+
+	// Act
+	resp, err := s.service.Get(ctx, "tenant1", "job1")
+
+	// Assert
+	s.Error(err)
+	s.NotErrorIs(err, ErrJobNotFound)
+	s.Nil(resp)
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+	"github.com/kingrain94/audit-log-api/pkg/xlsx"
+)
+
+// exportBatchSize is the number of logs read from PostgreSQL per query
+const exportBatchSize = 1000
+
+// Export streams every log matching filter to out in format, as WriteExport
+// does. Once rows were written, an error leaves out truncated.
+func (s *AuditLogService) Export(ctx context.Context, filter *domain.AuditLogFilter, format domain.ExportFormat, out io.Writer) (rowCount int64, err error) {
+	ctx, span := tracing.Start(ctx, "AuditLogService.Export", trace.WithAttributes(
+		tracing.TenantAttr(filter.TenantID),
+		attribute.String("export.format", string(format)),
+	))
+	defer func() {
+		span.SetAttributes(attribute.Int64("export.rows", rowCount))
+		tracing.End(span, err)
+	}()
+
+	return WriteExport(ctx, out, format, *filter, s.repo.AuditLog(), s.repo.OpenSearch())
+}
+
+// WriteExport writes every log matching filter to out in format, as a JSON
+// array or CSV with a header row, oldest first unless the filter sorts them.
+// Pagination in the filter is ignored and memory use is bounded by a single
+// batch whatever the number of logs.
+//
+// Filters with a full-text query are scanned in search, which alone evaluates
+// its syntax, over a point in time. The others are read from PostgreSQL using
+// keyset pagination on (timestamp, id), so no database connection is held
+// while out is slow to accept them.
+func WriteExport(ctx context.Context, out io.Writer, format domain.ExportFormat, filter domain.AuditLogFilter, logs repository.AuditLogRepository, search repository.OpenSearchRepository) (int64, error) {
+	w, err := newExportWriter(out, format, filter.Fields)
+	if err != nil {
+		return 0, err
+	}
+	if err := scanLogs(ctx, filter, logs, search, w.write); err != nil {
+		return w.rows, err
+	}
+	return w.rows, w.close()
+}
+
+// scanLogs calls fn with the logs matching filter in batches, in the order
+// and from the store WriteExport reads them
+func scanLogs(ctx context.Context, filter domain.AuditLogFilter, logs repository.AuditLogRepository, search repository.OpenSearchRepository, fn func([]domain.AuditLog) error) error {
+	if filter.Query != "" {
+		filter.SearchAfter = nil
+		if len(filter.Sort) == 0 {
+			filter.Sort = []domain.SortField{{Field: "timestamp"}}
+		}
+		if err := search.Scan(ctx, &filter, exportBatchSize, fn); err != nil {
+			return fmt.Errorf("failed to search logs: %w", err)
+		}
+		return nil
+	}
+
+	var cursor *domain.AuditLogCursor
+	for {
+		batch, err := logs.ListBatch(ctx, filter, cursor, exportBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to read logs: %w", err)
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+
+		if len(batch) < exportBatchSize {
+			return nil
+		}
+		last := batch[len(batch)-1]
+		cursor = &domain.AuditLogCursor{Timestamp: last.Timestamp, ID: last.ID}
+	}
+}
+
+// exportWriter writes batches of logs to out as a JSON array, CSV or an
+// Excel workbook
+type exportWriter struct {
+	out      io.Writer
+	fields   []string
+	csv      *csv.Writer
+	xlsx     *xlsx.Writer
+	severity int
+	rows     int64
+}
+
+// xlsxColumnWidths sizes the columns of Excel exports by their header
+var xlsxColumnWidths = map[string]float64{
+	"ID": 38, "TenantID": 38, "UserID": 38, "SessionID": 38, "CorrelationID": 38,
+	"Action": 16, "ResourceType": 16, "ResourceID": 38, "IPAddress": 16,
+	"UserAgent": 40, "Severity": 12, "Message": 60, "BeforeState": 40,
+	"AfterState": 40, "Metadata": 40, "Timestamp": 22,
+}
+
+// xlsxSeverityFills colors the severity cells of Excel exports from WARNING
+// up, in the order of domain.SeverityLevels
+var xlsxSeverityFills = []string{"FFEB9C", "FFC7CE", "FF7C80"}
+
+// newExportWriter starts an export in format, writing the CSV or workbook
+// header or the opening bracket of the JSON array
+func newExportWriter(out io.Writer, format domain.ExportFormat, fields []string) (*exportWriter, error) {
+	w := &exportWriter{out: out, fields: fields, severity: -1}
+
+	switch format {
+	case domain.ExportFormatCSV:
+		w.csv = csv.NewWriter(out)
+		if err := w.csv.Write(dto.AuditLogCSVHeaderOf(fields)); err != nil {
+			return nil, fmt.Errorf("failed to write CSV header: %w", err)
+		}
+	case domain.ExportFormatXLSX:
+		header := dto.AuditLogCSVHeaderOf(fields)
+		columns := make([]xlsx.Column, len(header))
+		for i, name := range header {
+			columns[i] = xlsx.Column{Header: name, Width: xlsxColumnWidths[name]}
+		}
+		w.severity = slices.Index(header, "Severity")
+
+		var err error
+		if w.xlsx, err = xlsx.NewWriter(out, "Audit Logs", columns, xlsxSeverityFills...); err != nil {
+			return nil, fmt.Errorf("failed to write workbook header: %w", err)
+		}
+	default:
+		if _, err := io.WriteString(out, "["); err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+func (w *exportWriter) write(batch []domain.AuditLog) error {
+	for i := range batch {
+		record := dto.FromAuditLog(&batch[i])
+
+		switch {
+		case w.csv != nil:
+			if err := w.csv.Write(record.CSVRecordOf(w.fields)); err != nil {
+				return fmt.Errorf("failed to write CSV record: %w", err)
+			}
+		case w.xlsx != nil:
+			values := record.CSVRecordOf(w.fields)
+			cells := make([]xlsx.Cell, len(values))
+			for i, value := range values {
+				cells[i] = xlsx.Cell{Value: value}
+			}
+			if w.severity >= 0 {
+				if level := slices.Index(domain.SeverityLevels, domain.SeverityLevel(record.Severity)); level > 0 {
+					cells[w.severity].Style = w.xlsx.Fill(level - 1)
+				}
+			}
+			if err := w.xlsx.WriteRow(cells...); err != nil {
+				return fmt.Errorf("failed to write workbook row: %w", err)
+			}
+		default:
+			var value any = record
+			if len(w.fields) > 0 {
+				value = record.SelectFields(w.fields)
+			}
+			data, err := json.Marshal(value)
+			if err != nil {
+				return fmt.Errorf("failed to marshal log: %w", err)
+			}
+			if w.rows > 0 {
+				data = append([]byte(","), data...)
+			}
+			if _, err := w.out.Write(data); err != nil {
+				return err
+			}
+		}
+		w.rows++
+	}
+
+	if w.csv != nil {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return fmt.Errorf("failed to write CSV records: %w", err)
+		}
+	}
+	return nil
+}
+
+// close ends the export, completing the workbook or writing the closing
+// bracket of the JSON array
+func (w *exportWriter) close() error {
+	switch {
+	case w.csv != nil:
+		return nil
+	case w.xlsx != nil:
+		if err := w.xlsx.Close(); err != nil {
+			return fmt.Errorf("failed to complete workbook: %w", err)
+		}
+		return nil
+	}
+	_, err := io.WriteString(w.out, "]")
+	return err
+}
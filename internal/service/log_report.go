@@ -0,0 +1,580 @@
+package service
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+	"github.com/kingrain94/audit-log-api/pkg/pdf"
+)
+
+const (
+	// reportSampleSize is the number of entries a report lists
+	reportSampleSize = 25
+	// reportTopN is the number of actions and resource types a report tables
+	reportTopN = 10
+	// reportMaxBuckets is the number of bars the activity chart has at most
+	reportMaxBuckets = 60
+)
+
+// reportBucketSizes are the spans of the activity chart's bars, the smallest
+// splitting the period into at most reportMaxBuckets being used
+var reportBucketSizes = []time.Duration{time.Hour, 6 * time.Hour, 24 * time.Hour, 7 * 24 * time.Hour, 30 * 24 * time.Hour}
+
+// reportSeverityColors are the colors of the severities in report charts
+var reportSeverityColors = map[string]string{
+	string(domain.SeverityInfo):     "60A5FA",
+	string(domain.SeverityWarning):  "FBBF24",
+	string(domain.SeverityError):    "F87171",
+	string(domain.SeverityCritical): "B91C1C",
+}
+
+// Report builds a PDF compliance report on the logs matching filter: the
+// stats of the period with charts of the severities and the activity over
+// time, the most frequent actions and resource types, a sample of entries
+// and an integrity verification. Every log is read to verify the stats and
+// to digest them as the JSON export of the same filter, so an export can be
+// checked against the report.
+func (s *AuditLogService) Report(ctx context.Context, filter *domain.AuditLogFilter) (_ []byte, err error) {
+	ctx, span := tracing.Start(ctx, "AuditLogService.Report", trace.WithAttributes(tracing.TenantAttr(filter.TenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	stats, err := s.GetStatsV2(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	report := newLogReport(*filter, stats, s.now())
+	digest := sha256.New()
+	export, err := newExportWriter(digest, domain.ExportFormatJSON, filter.Fields)
+	if err != nil {
+		return nil, err
+	}
+	err = scanLogs(ctx, *filter, s.repo.AuditLog(), s.repo.OpenSearch(), func(batch []domain.AuditLog) error {
+		report.add(batch)
+		return export.write(batch)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := export.close(); err != nil {
+		return nil, err
+	}
+	report.digest = hex.EncodeToString(digest.Sum(nil))
+	span.SetAttributes(attribute.Int64("report.logs", report.rows))
+
+	var out bytes.Buffer
+	if _, err := report.render().WriteTo(&out); err != nil {
+		return nil, fmt.Errorf("failed to write report: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// logReport accumulates the contents of a compliance report as its logs are
+// read, oldest first
+type logReport struct {
+	filter      domain.AuditLogFilter
+	stats       *dto.GetAuditLogStatsResponse
+	generatedAt time.Time
+	bucketSize  time.Duration
+	buckets     []int64
+	rows        int64
+	digest      string
+	// notable are the latest logs of WARNING severity or above and recent
+	// the latest logs, reportSampleSize of each at most
+	notable []domain.AuditLog
+	recent  []domain.AuditLog
+}
+
+func newLogReport(filter domain.AuditLogFilter, stats *dto.GetAuditLogStatsResponse, generatedAt time.Time) *logReport {
+	period := max(filter.EndTime.Sub(filter.StartTime), time.Second)
+	bucketSize := reportBucketSizes[len(reportBucketSizes)-1]
+	for _, size := range reportBucketSizes {
+		if period <= size*reportMaxBuckets {
+			bucketSize = size
+			break
+		}
+	}
+	if period > bucketSize*reportMaxBuckets {
+		bucketSize = (period/reportMaxBuckets + 24*time.Hour).Truncate(24 * time.Hour)
+	}
+
+	return &logReport{
+		filter:      filter,
+		stats:       stats,
+		generatedAt: generatedAt,
+		bucketSize:  bucketSize,
+		buckets:     make([]int64, (period+bucketSize-1)/bucketSize),
+	}
+}
+
+func (r *logReport) add(batch []domain.AuditLog) {
+	for i := range batch {
+		log := batch[i]
+		r.rows++
+
+		if bucket := int(log.Timestamp.Sub(r.filter.StartTime) / r.bucketSize); bucket >= 0 && bucket < len(r.buckets) {
+			r.buckets[bucket]++
+		}
+		r.recent = appendLatest(r.recent, log)
+		if slices.Index(domain.SeverityLevels, domain.SeverityLevel(strings.ToUpper(log.Severity))) > 0 {
+			r.notable = appendLatest(r.notable, log)
+		}
+	}
+}
+
+// appendLatest appends log to logs, dropping the oldest beyond
+// reportSampleSize
+func appendLatest(logs []domain.AuditLog, log domain.AuditLog) []domain.AuditLog {
+	if len(logs) == reportSampleSize {
+		logs = slices.Delete(logs, 0, 1)
+	}
+	return append(logs, log)
+}
+
+// verified reports whether as many logs were read as the stats count
+func (r *logReport) verified() bool {
+	return r.rows == r.stats.TotalLogs
+}
+
+// Colors of the report's text and tables
+const (
+	reportTextColor   = "1F2937"
+	reportMutedColor  = "6B7280"
+	reportRuleColor   = "D1D5DB"
+	reportHeaderColor = "E5E7EB"
+	reportStripeColor = "F9FAFB"
+	reportAccentColor = "2563EB"
+	reportPassColor   = "15803D"
+	reportFailColor   = "B91C1C"
+)
+
+// reportTimeLayout formats the times of a report
+const reportTimeLayout = "2006-01-02 15:04:05 UTC"
+
+func (r *logReport) render() *pdf.Document {
+	l := newReportLayout(pdf.New("Audit Log Compliance Report", r.generatedAt))
+
+	l.text(pdf.HelveticaBold, 20, reportTextColor, "Audit Log Compliance Report")
+	l.gap(6)
+	details := [][2]string{
+		{"Tenant", r.filter.TenantID},
+		{"Period", r.filter.StartTime.UTC().Format(reportTimeLayout) + " to " + r.filter.EndTime.UTC().Format(reportTimeLayout)},
+	}
+	if filters := describeReportFilter(r.filter); filters != "" {
+		details = append(details, [2]string{"Filters", filters})
+	}
+	details = append(details, [2]string{"Generated", r.generatedAt.UTC().Format(reportTimeLayout)})
+	l.details(details)
+
+	l.heading("Summary")
+	l.text(pdf.Helvetica, 10, reportTextColor, formatCount(r.stats.TotalLogs)+" logs were recorded in the period.")
+	l.gap(6)
+	severities := r.severities()
+	rows := make([][]string, 0, len(severities)+1)
+	bars := make([]reportBar, len(severities))
+	for i, severity := range severities {
+		count := r.stats.SeverityCounts[severity]
+		rows = append(rows, []string{severity, formatCount(count), share(count, r.stats.TotalLogs)})
+		bars[i] = reportBar{label: severity, value: count, color: cmp.Or(reportSeverityColors[severity], reportAccentColor)}
+	}
+	rows = append(rows, []string{"Total", formatCount(r.stats.TotalLogs), share(r.stats.TotalLogs, r.stats.TotalLogs)})
+	l.table(countColumns("Severity"), rows, 9)
+	l.gap(10)
+	l.barChart(bars)
+
+	l.heading("Activity")
+	l.text(pdf.Helvetica, 9, reportMutedColor, "Logs per "+formatBucketSize(r.bucketSize)+", from the start of the period.")
+	l.gap(6)
+	l.columnChart(r.buckets, r.filter.StartTime.UTC().Format("2006-01-02 15:04"), r.filter.EndTime.UTC().Format("2006-01-02 15:04"))
+
+	l.heading("Top actions")
+	l.table(countColumns("Action"), topCounts(r.stats.ActionCounts, r.stats.TotalLogs), 9)
+	l.heading("Top resource types")
+	l.table(countColumns("Resource type"), topCounts(r.stats.ResourceCounts, r.stats.TotalLogs), 9)
+
+	l.heading("Integrity verification")
+	if r.verified() {
+		l.text(pdf.HelveticaBold, 11, reportPassColor, "Verified")
+	} else {
+		l.text(pdf.HelveticaBold, 11, reportFailColor, "Mismatch")
+	}
+	l.gap(4)
+	l.details([][2]string{
+		{"Logs read", formatCount(r.rows)},
+		{"Logs in stats", formatCount(r.stats.TotalLogs)},
+		{"SHA-256 digest", r.digest},
+	})
+	l.gap(4)
+	explanation := "Every log in the period was read and counted against the stats above. The digest is the SHA-256 hash of the JSON export of the same logs (GET /api/v1/logs/export with the same filters), so an export can be checked against this report."
+	if !r.verified() {
+		explanation = "The number of logs read differs from the stats above, which happens when logs are ingested, archived or deleted while the report is generated, or when the stats store lags behind. " + explanation
+	}
+	l.paragraph(pdf.Helvetica, 9, reportMutedColor, explanation)
+
+	sample, caption := r.notable, "The latest logs of WARNING severity or above in the period, newest first."
+	if len(sample) == 0 {
+		sample, caption = r.recent, "The latest logs in the period, newest first. None has WARNING severity or above."
+	}
+	l.heading("Sample entries")
+	l.text(pdf.Helvetica, 9, reportMutedColor, caption)
+	l.gap(6)
+	rows = make([][]string, len(sample))
+	for i, log := range slices.Backward(sample) {
+		resource := log.ResourceType
+		if log.ResourceID != "" {
+			resource += "/" + log.ResourceID
+		}
+		rows[len(sample)-1-i] = []string{log.Timestamp.UTC().Format("2006-01-02 15:04:05"), log.Severity, log.Action, resource, log.UserID, log.Message}
+	}
+	l.table([]reportColumn{
+		{title: "Time (UTC)", width: 82},
+		{title: "Severity", width: 52},
+		{title: "Action", width: 62},
+		{title: "Resource", width: 86},
+		{title: "User", width: 76},
+		{title: "Message", width: reportContentWidth - 358},
+	}, rows, 7.5)
+
+	l.footers("Audit Log Compliance Report - " + r.filter.TenantID)
+	return l.doc
+}
+
+// severities returns the severities of the stats, the known ones first in
+// order of increasing severity
+func (r *logReport) severities() []string {
+	severities := make([]string, 0, len(r.stats.SeverityCounts))
+	for _, level := range domain.SeverityLevels {
+		severities = append(severities, string(level))
+	}
+	for _, severity := range slices.Sorted(maps.Keys(r.stats.SeverityCounts)) {
+		if !slices.Contains(severities, severity) {
+			severities = append(severities, severity)
+		}
+	}
+	return severities
+}
+
+// countColumns are the columns of a table counting logs by name
+func countColumns(name string) []reportColumn {
+	return []reportColumn{
+		{title: name, width: reportContentWidth - 180},
+		{title: "Logs", width: 90, right: true},
+		{title: "Share", width: 90, right: true},
+	}
+}
+
+// topCounts returns the reportTopN largest counts as rows of a count table,
+// with the rest summed in a final row
+func topCounts(counts map[string]int64, total int64) [][]string {
+	names := slices.SortedFunc(maps.Keys(counts), func(a, b string) int {
+		return cmp.Or(cmp.Compare(counts[b], counts[a]), cmp.Compare(a, b))
+	})
+
+	var rows [][]string
+	var other int64
+	for i, name := range names {
+		if i < reportTopN {
+			rows = append(rows, []string{name, formatCount(counts[name]), share(counts[name], total)})
+		} else {
+			other += counts[name]
+		}
+	}
+	if other > 0 {
+		rows = append(rows, []string{fmt.Sprintf("%d others", len(names)-reportTopN), formatCount(other), share(other, total)})
+	}
+	return rows
+}
+
+// describeReportFilter lists the criteria of filter besides the tenant and
+// the period
+func describeReportFilter(filter domain.AuditLogFilter) string {
+	var criteria []string
+	add := func(name, value string) {
+		if value != "" {
+			criteria = append(criteria, name+"="+value)
+		}
+	}
+	values := func(name string, f domain.ValueFilter) {
+		values := slices.Clone(f.In)
+		for _, value := range f.NotIn {
+			values = append(values, "!"+value)
+		}
+		add(name, strings.Join(values, ","))
+	}
+
+	add("q", filter.Query)
+	add("user_id", filter.UserID)
+	values("action", filter.Action)
+	values("resource_type", filter.ResourceType)
+	values("severity", filter.Severity)
+	values("tags", filter.Tags)
+	if filter.MinThreatScore > 0 {
+		add("min_threat_score", strconv.Itoa(filter.MinThreatScore))
+	}
+	add("session_id", filter.SessionID)
+	add("ip_address", filter.IPAddress)
+	add("user_agent", filter.UserAgent)
+	add("message", filter.Message)
+	add("correlation_id", filter.CorrelationID)
+	return strings.Join(criteria, "; ")
+}
+
+// formatCount formats a count with thousands separators
+func formatCount(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	for i := len(s) - 3; i > 0 && s[i-1] != '-'; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+// share formats n as a percentage of total
+func share(n, total int64) string {
+	if total == 0 {
+		return "-"
+	}
+	return strconv.FormatFloat(float64(n)*100/float64(total), 'f', 1, 64) + "%"
+}
+
+// formatBucketSize names the span of an activity chart's bars
+func formatBucketSize(size time.Duration) string {
+	switch {
+	case size == time.Hour:
+		return "hour"
+	case size%(24*time.Hour) != 0:
+		return fmt.Sprintf("%d hours", size/time.Hour)
+	case size == 24*time.Hour:
+		return "day"
+	case size == 7*24*time.Hour:
+		return "week"
+	default:
+		return fmt.Sprintf("%d days", size/(24*time.Hour))
+	}
+}
+
+// The margins and content width of report pages
+const (
+	reportMargin       = 48.0
+	reportContentWidth = pdf.PageWidth - 2*reportMargin
+	reportFooterHeight = 24.0
+)
+
+// reportLayout places the blocks of a report down its pages, starting a new
+// page when a block doesn't fit
+type reportLayout struct {
+	doc  *pdf.Document
+	page *pdf.Page
+	y    float64
+}
+
+func newReportLayout(doc *pdf.Document) *reportLayout {
+	l := &reportLayout{doc: doc}
+	l.newPage()
+	return l
+}
+
+func (l *reportLayout) newPage() {
+	l.page = l.doc.AddPage()
+	l.y = reportMargin
+}
+
+// space makes room for a block of the given height, returning whether a new
+// page was started for it
+func (l *reportLayout) space(height float64) bool {
+	if l.y+height <= pdf.PageHeight-reportMargin-reportFooterHeight {
+		return false
+	}
+	l.newPage()
+	return true
+}
+
+func (l *reportLayout) gap(height float64) {
+	l.y += height
+}
+
+// text draws a line of text, truncated to the content width
+func (l *reportLayout) text(font pdf.Font, size float64, color, text string) {
+	l.space(size * 1.4)
+	l.page.Text(reportMargin, l.y+size, font, size, color, pdf.Truncate(text, font, size, reportContentWidth))
+	l.y += size * 1.4
+}
+
+// paragraph draws text wrapped to the content width
+func (l *reportLayout) paragraph(font pdf.Font, size float64, color, text string) {
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && pdf.TextWidth(line+" "+word, font, size) > reportContentWidth {
+			l.text(font, size, color, line)
+			line = ""
+		}
+		line = strings.TrimPrefix(line+" "+word, " ")
+	}
+	if line != "" {
+		l.text(font, size, color, line)
+	}
+}
+
+// heading starts a section
+func (l *reportLayout) heading(title string) {
+	l.gap(14)
+	l.space(60)
+	l.page.Text(reportMargin, l.y+13, pdf.HelveticaBold, 13, reportTextColor, title)
+	l.page.Line(reportMargin, l.y+18, reportMargin+reportContentWidth, l.y+18, 0.75, reportRuleColor)
+	l.y += 26
+}
+
+// details draws labeled values
+func (l *reportLayout) details(details [][2]string) {
+	for _, detail := range details {
+		l.space(14)
+		l.page.Text(reportMargin, l.y+10, pdf.HelveticaBold, 10, reportTextColor, detail[0])
+		l.page.Text(reportMargin+100, l.y+10, pdf.Helvetica, 10, reportTextColor, pdf.Truncate(detail[1], pdf.Helvetica, 10, reportContentWidth-100))
+		l.y += 14
+	}
+}
+
+// reportColumn is a column of a report table
+type reportColumn struct {
+	title string
+	width float64
+	right bool
+}
+
+// table draws rows of cells in the columns, repeating the header on every
+// page it spans
+func (l *reportLayout) table(columns []reportColumn, rows [][]string, size float64) {
+	rowHeight := size + 7
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.title
+	}
+
+	l.space(2 * rowHeight)
+	l.tableRow(columns, header, pdf.HelveticaBold, size, reportHeaderColor)
+	if len(rows) == 0 {
+		l.tableRow(columns, []string{"None"}, pdf.Helvetica, size, "")
+	}
+	for i, row := range rows {
+		if l.space(rowHeight) {
+			l.tableRow(columns, header, pdf.HelveticaBold, size, reportHeaderColor)
+		}
+		fill := ""
+		if i%2 == 1 {
+			fill = reportStripeColor
+		}
+		l.tableRow(columns, row, pdf.Helvetica, size, fill)
+	}
+	l.page.Line(reportMargin, l.y, reportMargin+reportContentWidth, l.y, 0.5, reportRuleColor)
+}
+
+func (l *reportLayout) tableRow(columns []reportColumn, cells []string, font pdf.Font, size float64, fill string) {
+	const padding = 4
+	rowHeight := size + 7
+	if fill != "" {
+		l.page.Rect(reportMargin, l.y, reportContentWidth, rowHeight, fill)
+	}
+
+	x := reportMargin
+	for i, column := range columns {
+		if i < len(cells) && cells[i] != "" {
+			text := pdf.Truncate(cells[i], font, size, column.width-2*padding)
+			textX := x + padding
+			if column.right {
+				textX = x + column.width - padding - pdf.TextWidth(text, font, size)
+			}
+			l.page.Text(textX, l.y+size+2, font, size, reportTextColor, text)
+		}
+		x += column.width
+	}
+	l.y += rowHeight
+}
+
+// reportBar is a bar of a bar chart
+type reportBar struct {
+	label string
+	value int64
+	color string
+}
+
+// barChart draws horizontal bars scaled to the largest value
+func (l *reportLayout) barChart(bars []reportBar) {
+	const labelWidth, valueWidth, barHeight = 80.0, 60.0, 12.0
+	var largest int64
+	for _, bar := range bars {
+		largest = max(largest, bar.value)
+	}
+
+	l.space(float64(len(bars)) * (barHeight + 4))
+	for _, bar := range bars {
+		l.page.Text(reportMargin, l.y+barHeight-3, pdf.Helvetica, 9, reportTextColor, pdf.Truncate(bar.label, pdf.Helvetica, 9, labelWidth-4))
+		width := 0.0
+		if largest > 0 {
+			width = (reportContentWidth - labelWidth - valueWidth) * float64(bar.value) / float64(largest)
+		}
+		if width > 0 {
+			l.page.Rect(reportMargin+labelWidth, l.y, width, barHeight, bar.color)
+		}
+		l.page.Text(reportMargin+labelWidth+width+4, l.y+barHeight-3, pdf.Helvetica, 9, reportMutedColor, formatCount(bar.value))
+		l.y += barHeight + 4
+	}
+}
+
+// columnChart draws values as columns scaled to the largest, labeled with
+// the times the chart starts and ends at
+func (l *reportLayout) columnChart(values []int64, start, end string) {
+	const height, axisWidth = 120.0, 50.0
+	var largest int64
+	for _, value := range values {
+		largest = max(largest, value)
+	}
+
+	l.space(height + 24)
+	left, bottom := reportMargin+axisWidth, l.y+height
+	width := reportContentWidth - axisWidth
+	l.page.Text(reportMargin, l.y+8, pdf.Helvetica, 8, reportMutedColor, formatCount(largest))
+	l.page.Text(reportMargin, bottom, pdf.Helvetica, 8, reportMutedColor, "0")
+	l.page.Line(left, l.y, left+width, l.y, 0.25, reportRuleColor)
+	l.page.Line(left, bottom, left+width, bottom, 0.75, reportMutedColor)
+
+	if len(values) > 0 && largest > 0 {
+		step := width / float64(len(values))
+		for i, value := range values {
+			if value == 0 {
+				continue
+			}
+			barHeight := max(height*float64(value)/float64(largest), 0.5)
+			l.page.Rect(left+float64(i)*step+step*0.1, bottom-barHeight, step*0.8, barHeight, reportAccentColor)
+		}
+	}
+
+	l.page.Text(left, bottom+12, pdf.Helvetica, 8, reportMutedColor, start)
+	l.page.Text(left+width-pdf.TextWidth(end, pdf.Helvetica, 8), bottom+12, pdf.Helvetica, 8, reportMutedColor, end)
+	l.y = bottom + 24
+}
+
+// footers numbers the pages
+func (l *reportLayout) footers(title string) {
+	pages := l.doc.Pages()
+	for i, page := range pages {
+		y := pdf.PageHeight - reportMargin + 8
+		page.Line(reportMargin, y-12, reportMargin+reportContentWidth, y-12, 0.5, reportRuleColor)
+		page.Text(reportMargin, y, pdf.Helvetica, 8, reportMutedColor, pdf.Truncate(title, pdf.Helvetica, 8, reportContentWidth-80))
+		number := fmt.Sprintf("Page %d of %d", i+1, len(pages))
+		page.Text(reportMargin+reportContentWidth-pdf.TextWidth(number, pdf.Helvetica, 8), y, pdf.Helvetica, 8, reportMutedColor, number)
+	}
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+)
+
+//go:generate mockery --name PolicyCache --output ../mocks
+type PolicyCache interface {
+	Get(ctx context.Context, tenantID string) ([]domain.Policy, error)
+	Set(ctx context.Context, tenantID string, policies []domain.Policy) error
+	Invalidate(ctx context.Context, tenantID string) error
+}
+
+// TenantSettingsResolver resolves the settings of a tenant, such as the
+// visibility of its logs
+//
+//go:generate mockery --name TenantSettingsResolver --output ../mocks
+type TenantSettingsResolver interface {
+	ResolveSettings(ctx context.Context, tenantID string) (*domain.TenantSettings, error)
+}
+
+type PolicyService struct {
+	repo     repository.Repository
+	cache    PolicyCache
+	settings TenantSettingsResolver
+}
+
+func NewPolicyService(repo repository.Repository, cache PolicyCache, settings TenantSettingsResolver) *PolicyService {
+	return &PolicyService{
+		repo:     repo,
+		cache:    cache,
+		settings: settings,
+	}
+}
+
+// Create adds a policy to the tenant. The first policy for a role replaces
+// the built-in defaults of that role.
+func (s *PolicyService) Create(ctx context.Context, tenantID string, req dto.PolicyRequest) (_ *dto.PolicyResponse, err error) {
+	ctx, span := tracing.Start(ctx, "PolicyService.Create", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	policy := policyFromRequest(req)
+	policy.TenantID = tenantID
+	if err := validatePolicyScope(policy); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkDuplicate(ctx, policy); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Policy().Create(ctx, policy); err != nil {
+		return nil, fmt.Errorf("failed to create policy: %w", err)
+	}
+	if err := s.cache.Invalidate(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	return dto.FromPolicy(policy), nil
+}
+
+func (s *PolicyService) List(ctx context.Context, tenantID string) (_ []dto.PolicyResponse, err error) {
+	ctx, span := tracing.Start(ctx, "PolicyService.List", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	policies, err := s.repo.Policy().ListByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromPolicies(policies), nil
+}
+
+func (s *PolicyService) Update(ctx context.Context, tenantID, id string, req dto.PolicyRequest) (_ *dto.PolicyResponse, err error) {
+	ctx, span := tracing.Start(ctx, "PolicyService.Update", trace.WithAttributes(tracing.TenantAttr(tenantID), attribute.String("policy.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	policy, err := s.repo.Policy().GetByID(ctx, tenantID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPolicyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get policy: %w", err)
+	}
+
+	updated := policyFromRequest(req)
+	policy.Role = updated.Role
+	policy.Resource = updated.Resource
+	policy.Action = updated.Action
+	policy.Effect = updated.Effect
+	policy.Scope = updated.Scope
+	policy.UpdatedAt = time.Now()
+	if err := validatePolicyScope(policy); err != nil {
+		return nil, err
+	}
+	if err := s.checkDuplicate(ctx, policy); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Policy().Update(ctx, policy); err != nil {
+		return nil, fmt.Errorf("failed to update policy: %w", err)
+	}
+	if err := s.cache.Invalidate(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	return dto.FromPolicy(policy), nil
+}
+
+// Delete removes a policy. Removing the last policy of a role restores its defaults.
+func (s *PolicyService) Delete(ctx context.Context, tenantID, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "PolicyService.Delete", trace.WithAttributes(tracing.TenantAttr(tenantID), attribute.String("policy.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	err = s.repo.Policy().Delete(ctx, tenantID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrPolicyNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete policy: %w", err)
+	}
+
+	return s.cache.Invalidate(ctx, tenantID)
+}
+
+// Evaluate decides whether a caller with roles may perform action on resource
+// in the tenant. Policies are read through the cache; cache errors are not
+// fatal and fall back to the database. When the tenant limits the visibility
+// of its logs, reading and exporting them is narrowed to the caller's own
+// logs unless the caller is an admin or auditor.
+func (s *PolicyService) Evaluate(ctx context.Context, tenantID string, roles []string, resource domain.PolicyResource, action domain.PolicyAction) (domain.PolicyDecision, error) {
+	policies, err := s.cache.Get(ctx, tenantID)
+	if err != nil || policies == nil {
+		policies, err = s.repo.Policy().ListByTenant(ctx, tenantID)
+		if err != nil {
+			return domain.PolicyDecision{}, fmt.Errorf("failed to load policies: %w", err)
+		}
+		_ = s.cache.Set(ctx, tenantID, policies)
+	}
+
+	decision := domain.EvaluatePolicies(policies, roles, resource, action)
+	if decision.Allowed && decision.Scope == domain.PolicyScopeAll && resource == domain.PolicyResourceLogs &&
+		(action == domain.PolicyActionRead || action == domain.PolicyActionExport) {
+		settings, err := s.settings.ResolveSettings(ctx, tenantID)
+		if err != nil {
+			// Fail closed: the caller may be limited to its own logs
+			return domain.PolicyDecision{}, fmt.Errorf("failed to resolve tenant settings: %w", err)
+		}
+		if settings.LimitsToOwnLogs(roles) {
+			decision.Scope = domain.PolicyScopeOwn
+		}
+	}
+	return decision, nil
+}
+
+// checkDuplicate returns ErrPolicyExists if another policy of the tenant covers the same role, resource and action
+func (s *PolicyService) checkDuplicate(ctx context.Context, policy *domain.Policy) error {
+	existing, err := s.repo.Policy().ListByTenant(ctx, policy.TenantID)
+	if err != nil {
+		return fmt.Errorf("failed to list policies: %w", err)
+	}
+	for _, p := range existing {
+		if p.ID != policy.ID && p.Role == policy.Role && p.Resource == policy.Resource && p.Action == policy.Action {
+			return ErrPolicyExists
+		}
+	}
+	return nil
+}
+
+// validatePolicyScope rejects own-scoped grants on actions that can't be limited to
+// the caller's logs, such as cleanup which deletes every log before a date
+func validatePolicyScope(policy *domain.Policy) error {
+	if policy.Scope != domain.PolicyScopeOwn || policy.Effect == domain.PolicyDeny {
+		return nil
+	}
+	if policy.Resource != domain.PolicyResourceLogs ||
+		(policy.Action != domain.PolicyActionRead && policy.Action != domain.PolicyActionExport) {
+		return ErrInvalidPolicyScope
+	}
+	return nil
+}
+
+func policyFromRequest(req dto.PolicyRequest) *domain.Policy {
+	policy := &domain.Policy{
+		Role:     req.Role,
+		Resource: domain.PolicyResource(req.Resource),
+		Action:   domain.PolicyAction(req.Action),
+		Effect:   domain.PolicyEffect(req.Effect),
+		Scope:    domain.PolicyScope(req.Scope),
+	}
+	if policy.Effect == "" {
+		policy.Effect = domain.PolicyAllow
+	}
+	if policy.Scope == "" {
+		policy.Scope = domain.PolicyScopeAll
+	}
+	return policy
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type PolicyServiceTestSuite struct {
+	suite.Suite
+	mockRepo     *mocks.Repository
+	mockPolicy   *mocks.PolicyRepository
+	mockCache    *mocks.PolicyCache
+	mockSettings *mocks.TenantSettingsResolver
+	service      *PolicyService
+}
+
+func (s *PolicyServiceTestSuite) SetupTest() {
+	s.mockRepo = new(mocks.Repository)
+	s.mockPolicy = new(mocks.PolicyRepository)
+	s.mockCache = new(mocks.PolicyCache)
+	s.mockSettings = new(mocks.TenantSettingsResolver)
+
+	s.mockRepo.On("Policy").Return(s.mockPolicy)
+
+	s.service = NewPolicyService(s.mockRepo, s.mockCache, s.mockSettings)
+}
+
+func TestPolicyService(t *testing.T) {
+	suite.Run(t, new(PolicyServiceTestSuite))
+}
+
+func (s *PolicyServiceTestSuite) TestEvaluate_DefaultPolicies() {
+	// Arrange
+	ctx := context.Background()
+	s.mockCache.On("Get", mock.Anything, "tenant1").Return([]domain.Policy{}, nil)
+	s.mockSettings.On("ResolveSettings", mock.Anything, "tenant1").Return(&domain.TenantSettings{}, nil)
+
+	// Act
+	auditorRead, err := s.service.Evaluate(ctx, "tenant1", []string{"auditor"}, domain.PolicyResourceLogs, domain.PolicyActionRead)
+	s.NoError(err)
+	auditorCreate, err := s.service.Evaluate(ctx, "tenant1", []string{"auditor"}, domain.PolicyResourceLogs, domain.PolicyActionCreate)
+	s.NoError(err)
+	adminUsers, err := s.service.Evaluate(ctx, "tenant1", []string{"admin"}, domain.PolicyResourceUsers, domain.PolicyActionUpdate)
+	s.NoError(err)
+
+	// Assert
+	s.Equal(domain.PolicyDecision{Allowed: true, Scope: domain.PolicyScopeAll}, auditorRead)
+	s.False(auditorCreate.Allowed)
+	s.True(adminUsers.Allowed)
+	s.mockPolicy.AssertNotCalled(s.T(), "ListByTenant", mock.Anything, mock.Anything)
+}
+
+func (s *PolicyServiceTestSuite) TestEvaluate_TenantPoliciesReplaceRoleDefaults() {
+	// Arrange
+	ctx := context.Background()
+	policies := []domain.Policy{
+		{Role: "user", Resource: domain.PolicyResourceLogs, Action: domain.PolicyActionRead, Effect: domain.PolicyAllow, Scope: domain.PolicyScopeOwn},
+	}
+	s.mockCache.On("Get", mock.Anything, "tenant1").Return(nil, nil)
+	s.mockPolicy.On("ListByTenant", mock.Anything, "tenant1").Return(policies, nil)
+	s.mockCache.On("Set", mock.Anything, "tenant1", policies).Return(nil)
+
+	// Act
+	read, err := s.service.Evaluate(ctx, "tenant1", []string{"user"}, domain.PolicyResourceLogs, domain.PolicyActionRead)
+	s.NoError(err)
+	create, err := s.service.Evaluate(ctx, "tenant1", []string{"user"}, domain.PolicyResourceLogs, domain.PolicyActionCreate)
+	s.NoError(err)
+
+	// Assert
+	s.Equal(domain.PolicyDecision{Allowed: true, Scope: domain.PolicyScopeOwn}, read)
+	s.False(create.Allowed)
+	s.mockCache.AssertExpectations(s.T())
+}
+
+func (s *PolicyServiceTestSuite) TestEvaluate_OwnLogVisibility_LimitsUsersToOwnLogs() {
+	// Arrange
+	ctx := context.Background()
+	s.mockCache.On("Get", mock.Anything, "tenant1").Return([]domain.Policy{}, nil)
+	s.mockSettings.On("ResolveSettings", mock.Anything, "tenant1").
+		Return(&domain.TenantSettings{LogVisibility: domain.PolicyScopeOwn}, nil)
+
+	// Act
+	userRead, err := s.service.Evaluate(ctx, "tenant1", []string{"user"}, domain.PolicyResourceLogs, domain.PolicyActionRead)
+	s.NoError(err)
+	userExport, err := s.service.Evaluate(ctx, "tenant1", []string{"user"}, domain.PolicyResourceLogs, domain.PolicyActionExport)
+	s.NoError(err)
+	auditorRead, err := s.service.Evaluate(ctx, "tenant1", []string{"user", "auditor"}, domain.PolicyResourceLogs, domain.PolicyActionRead)
+	s.NoError(err)
+	adminRead, err := s.service.Evaluate(ctx, "tenant1", []string{"admin"}, domain.PolicyResourceLogs, domain.PolicyActionRead)
+	s.NoError(err)
+
+	// Assert
+	s.Equal(domain.PolicyDecision{Allowed: true, Scope: domain.PolicyScopeOwn}, userRead)
+	s.Equal(domain.PolicyDecision{Allowed: true, Scope: domain.PolicyScopeOwn}, userExport)
+	s.Equal(domain.PolicyDecision{Allowed: true, Scope: domain.PolicyScopeAll}, auditorRead)
+	s.Equal(domain.PolicyDecision{Allowed: true, Scope: domain.PolicyScopeAll}, adminRead)
+}
+
+func (s *PolicyServiceTestSuite) TestEvaluate_SettingsError_FailsClosed() {
+	// Arrange
+	ctx := context.Background()
+	s.mockCache.On("Get", mock.Anything, "tenant1").Return([]domain.Policy{}, nil)
+	s.mockSettings.On("ResolveSettings", mock.Anything, "tenant1").Return(nil, errors.New("database down"))
+
+	// Act
+	_, err := s.service.Evaluate(ctx, "tenant1", []string{"user"}, domain.PolicyResourceLogs, domain.PolicyActionRead)
+
+	// Assert
+	s.Error(err)
+}
+
+func (s *PolicyServiceTestSuite) TestEvaluate_DenyWins() {
+	// Arrange
+	ctx := context.Background()
+	s.mockCache.On("Get", mock.Anything, "tenant1").Return([]domain.Policy{
+		{Role: "auditor", Resource: domain.PolicyResourceLogs, Action: domain.PolicyActionAny, Effect: domain.PolicyDeny, Scope: domain.PolicyScopeAll},
+	}, nil)
+
+	// Act
+	decision, err := s.service.Evaluate(ctx, "tenant1", []string{"user", "auditor"}, domain.PolicyResourceLogs, domain.PolicyActionRead)
+
+	// Assert
+	s.NoError(err)
+	s.False(decision.Allowed)
+}
+
+func (s *PolicyServiceTestSuite) TestEvaluate_CacheErrorFallsBackToDatabase() {
+	// Arrange
+	ctx := context.Background()
+	s.mockCache.On("Get", mock.Anything, "tenant1").Return(nil, errors.New("redis down"))
+	s.mockPolicy.On("ListByTenant", mock.Anything, "tenant1").Return([]domain.Policy{}, nil)
+	s.mockCache.On("Set", mock.Anything, "tenant1", mock.Anything).Return(errors.New("redis down"))
+
+	// Act
+	decision, err := s.service.Evaluate(ctx, "tenant1", []string{"user"}, domain.PolicyResourceLogs, domain.PolicyActionCreate)
+
+	// Assert
+	s.NoError(err)
+	s.True(decision.Allowed)
+}
+
+func (s *PolicyServiceTestSuite) TestCreate_Success() {
+	// Arrange
+	ctx := context.Background()
+	req := dto.PolicyRequest{Role: "auditor", Resource: "logs", Action: "delete", Effect: "deny"}
+
+	s.mockPolicy.On("ListByTenant", mock.Anything, "tenant1").Return([]domain.Policy{}, nil)
+	s.mockPolicy.On("Create", mock.Anything, mock.MatchedBy(func(p *domain.Policy) bool {
+		return p.TenantID == "tenant1" && p.Effect == domain.PolicyDeny && p.Scope == domain.PolicyScopeAll
+	})).Return(nil)
+	s.mockCache.On("Invalidate", mock.Anything, "tenant1").Return(nil)
+
+	// Act
+	resp, err := s.service.Create(ctx, "tenant1", req)
+
+	// Assert
+	s.NoError(err)
+	s.Equal("deny", resp.Effect)
+	s.mockPolicy.AssertExpectations(s.T())
+	s.mockCache.AssertExpectations(s.T())
+}
+
+func (s *PolicyServiceTestSuite) TestCreate_Duplicate() {
+	// Arrange
+	ctx := context.Background()
+	req := dto.PolicyRequest{Role: "user", Resource: "logs", Action: "read"}
+
+	s.mockPolicy.On("ListByTenant", mock.Anything, "tenant1").Return([]domain.Policy{
+		{ID: "policy1", Role: "user", Resource: domain.PolicyResourceLogs, Action: domain.PolicyActionRead},
+	}, nil)
+
+	// Act
+	resp, err := s.service.Create(ctx, "tenant1", req)
+
+	// Assert
+	s.ErrorIs(err, ErrPolicyExists)
+	s.Nil(resp)
+	s.mockPolicy.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (s *PolicyServiceTestSuite) TestCreate_OwnScopeOnUnsupportedAction() {
+	// Arrange
+	ctx := context.Background()
+	req := dto.PolicyRequest{Role: "auditor", Resource: "logs", Action: "delete", Scope: "own"}
+
+	// Act
+	resp, err := s.service.Create(ctx, "tenant1", req)
+
+	// Assert
+	s.ErrorIs(err, ErrInvalidPolicyScope)
+	s.Nil(resp)
+	s.mockRepo.AssertNotCalled(s.T(), "Policy")
+}
+
+func (s *PolicyServiceTestSuite) TestDelete_NotFound() {
+	// Arrange
+	ctx := context.Background()
+	s.mockPolicy.On("Delete", mock.Anything, "tenant1", "missing").Return(gorm.ErrRecordNotFound)
+
+	// Act
+	err := s.service.Delete(ctx, "tenant1", "missing")
+
+	// Assert
+	s.ErrorIs(err, ErrPolicyNotFound)
+	s.mockCache.AssertNotCalled(s.T(), "Invalidate", mock.Anything, mock.Anything)
+}
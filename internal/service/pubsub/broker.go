@@ -0,0 +1,46 @@
+package pubsub
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+// Broker fans stored logs and tenant events out to the API instances
+// streaming them to clients
+type Broker interface {
+	// Publish publishes an audit log to its tenant's subscribers
+	Publish(ctx context.Context, log *dto.AuditLogResponse) error
+	// PublishEvent publishes an event about the tenant's data
+	PublishEvent(ctx context.Context, event Event) error
+	// Subscribe calls callback with every log of the tenant published from
+	// now on, until Unsubscribe or Close or until ctx is done
+	Subscribe(ctx context.Context, tenantID string, callback func(*dto.AuditLogResponse)) error
+	Unsubscribe(tenantID string)
+	// SubscribeAll calls callback with every log of every tenant published
+	// from now on, until UnsubscribeAll or Close or until ctx is done. It is
+	// independent of the tenant subscriptions, which keep their own callbacks.
+	SubscribeAll(ctx context.Context, callback func(*dto.AuditLogResponse)) error
+	UnsubscribeAll()
+	Close()
+}
+
+// New validates cfg and returns the broker of the backend it selects
+func New(client *redis.Client, cfg *config.PubSubConfig, logger *logger.Logger) (Broker, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch cfg.Backend {
+	case config.PubSubBackendPubSub:
+		return NewRedisPubSub(client, logger), nil
+	case config.PubSubBackendStreams:
+		return NewRedisStreams(client, cfg, logger), nil
+	}
+	return nil, fmt.Errorf("unknown pub/sub backend %q", cfg.Backend)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 
@@ -14,12 +15,28 @@ import (
 
 const (
 	channelPrefix = "audit_logs:"
+	// eventsChannelPrefix prefixes the channels of tenant data events, such as
+	// completed cleanups, kept apart from the log stream
+	eventsChannelPrefix = "audit_log_events:"
 )
 
+// EventCleanupCompleted is published once the logs of a tenant before a date
+// were deleted, with the cleanup job as data
+const EventCleanupCompleted = "cleanup.completed"
+
+// Event notifies subscribers of the tenant's events channel about its data
+type Event struct {
+	Type     string    `json:"type"`
+	TenantID string    `json:"tenant_id"`
+	Time     time.Time `json:"time"`
+	Data     any       `json:"data"`
+}
+
 type RedisPubSub struct {
 	client       *redis.Client
 	logger       *logger.Logger
 	subscribers  map[string]*redis.PubSub // Map of tenant ID to subscriber
+	all          *redis.PubSub            // Pattern subscriber of every tenant channel
 	subscriberMu sync.RWMutex
 }
 
@@ -77,28 +94,92 @@ func (ps *RedisPubSub) Subscribe(ctx context.Context, tenantID string, callback
 			ps.logger.Infof("Closing subscription for tenant channel: %s", channel)
 			pubsub.Close()
 			ps.subscriberMu.Lock()
-			delete(ps.subscribers, tenantID)
+			if ps.subscribers[tenantID] == pubsub {
+				delete(ps.subscribers, tenantID)
+			}
 			ps.subscriberMu.Unlock()
 		}()
+		ps.receive(ctx, pubsub, callback)
+	}()
+
+	ps.logger.Infof("Subscribed to tenant channel: %s", channel)
+	return nil
+}
+
+// SubscribeAll subscribes to the audit logs of every tenant with a pattern
+// subscription on the tenant channels
+func (ps *RedisPubSub) SubscribeAll(ctx context.Context, callback func(*dto.AuditLogResponse)) error {
+	ps.subscriberMu.Lock()
+	defer ps.subscriberMu.Unlock()
 
-		ch := pubsub.Channel()
-		for {
-			select {
-			case msg := <-ch:
-				var log dto.AuditLogResponse
-				if err := json.Unmarshal([]byte(msg.Payload), &log); err != nil {
-					ps.logger.Errorf("Failed to unmarshal audit log from channel %s: %v", channel, err)
-					continue
-				}
-				callback(&log)
-
-			case <-ctx.Done():
+	if ps.all != nil {
+		return nil
+	}
+
+	pubsub := ps.client.PSubscribe(ctx, channelPrefix+"*")
+	ps.all = pubsub
+
+	go func() {
+		defer func() {
+			pubsub.Close()
+			ps.subscriberMu.Lock()
+			if ps.all == pubsub {
+				ps.all = nil
+			}
+			ps.subscriberMu.Unlock()
+		}()
+		ps.receive(ctx, pubsub, callback)
+	}()
+
+	ps.logger.Infof("Subscribed to all tenant channels")
+	return nil
+}
+
+// UnsubscribeAll removes the subscription to every tenant's logs
+func (ps *RedisPubSub) UnsubscribeAll() {
+	ps.subscriberMu.Lock()
+	defer ps.subscriberMu.Unlock()
+
+	if ps.all != nil {
+		ps.all.Close()
+		ps.all = nil
+		ps.logger.Infof("Unsubscribed from all tenant channels")
+	}
+}
+
+// receive hands the logs of a subscription to callback until it is closed or ctx is done
+func (ps *RedisPubSub) receive(ctx context.Context, pubsub *redis.PubSub, callback func(*dto.AuditLogResponse)) {
+	ch := pubsub.Channel()
+	for {
+		select {
+		case msg, ok := <-ch:
+			if !ok {
 				return
 			}
+			var log dto.AuditLogResponse
+			if err := json.Unmarshal([]byte(msg.Payload), &log); err != nil {
+				ps.logger.Errorf("Failed to unmarshal audit log from channel %s: %v", msg.Channel, err)
+				continue
+			}
+			callback(&log)
+
+		case <-ctx.Done():
+			return
 		}
-	}()
+	}
+}
 
-	ps.logger.Infof("Subscribed to tenant channel: %s", channel)
+// PublishEvent publishes an event to the tenant's Redis events channel
+func (ps *RedisPubSub) PublishEvent(ctx context.Context, event Event) error {
+	message, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	channel := eventsChannelPrefix + event.TenantID
+	if err := ps.client.Publish(ctx, channel, message).Err(); err != nil {
+		return fmt.Errorf("failed to publish to Redis channel %s: %w", channel, err)
+	}
 	return nil
 }
 
@@ -123,4 +204,8 @@ func (ps *RedisPubSub) Close() {
 		delete(ps.subscribers, tenantID)
 		ps.logger.Infof("Closed subscription for tenant channel: %s", ps.getChannelName(tenantID))
 	}
+	if ps.all != nil {
+		ps.all.Close()
+		ps.all = nil
+	}
 }
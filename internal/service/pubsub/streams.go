@@ -0,0 +1,385 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+const (
+	streamPrefix       = "audit_logs:stream:"
+	eventsStreamPrefix = "audit_log_events:stream:"
+	// streamPayloadField is the entry field holding the JSON encoded log or event
+	streamPayloadField = "payload"
+
+	// streamBlockTimeout bounds how long a read waits for new logs, and so how
+	// long a new subscription waits before its stream is read
+	streamBlockTimeout = 2 * time.Second
+	streamReadCount    = 100
+	// streamRetryInterval paces reads while Redis is unreachable
+	streamRetryInterval = time.Second
+	// streamDiscoveryInterval is how often SubscribeAll looks for the streams
+	// of tenants that published their first log since
+	streamDiscoveryInterval = 10 * time.Second
+)
+
+// RedisStreams fans logs out through a Redis Stream per tenant. Each API
+// instance reads the streams of its subscribed tenants in its own consumer
+// group and acknowledges logs once delivered, so logs published while it is
+// reconnecting to Redis are delivered afterwards instead of being dropped.
+// Logs published before a tenant's first local subscriber aren't delivered;
+// clients resuming a stream replay those from the database.
+type RedisStreams struct {
+	client *redis.Client
+	logger *logger.Logger
+	group  string
+	maxLen int64
+
+	mu        sync.Mutex
+	callbacks map[string]func(*dto.AuditLogResponse) // by tenant ID
+	// recovering is set after a failed read or acknowledgement, when logs may
+	// have been read but not delivered, so pending entries are read again
+	recovering bool
+	cancel     context.CancelFunc
+	done       chan struct{}
+
+	// all reads every tenant stream for SubscribeAll, in a group of its own
+	// so the tenant subscriptions still get every log
+	all       *RedisStreams
+	cancelAll context.CancelFunc
+}
+
+func NewRedisStreams(client *redis.Client, cfg *config.PubSubConfig, logger *logger.Logger) *RedisStreams {
+	return &RedisStreams{
+		client:    client,
+		logger:    logger,
+		group:     cfg.ConsumerGroup,
+		maxLen:    cfg.StreamMaxLen,
+		callbacks: make(map[string]func(*dto.AuditLogResponse)),
+	}
+}
+
+func (s *RedisStreams) streamName(tenantID string) string {
+	return streamPrefix + tenantID
+}
+
+// Publish appends an audit log to the tenant's stream, trimming its oldest logs
+func (s *RedisStreams) Publish(ctx context.Context, log *dto.AuditLogResponse) error {
+	message, err := json.Marshal(log)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit log: %w", err)
+	}
+	return s.add(ctx, s.streamName(log.TenantID), message)
+}
+
+// PublishEvent appends an event to the tenant's events stream
+func (s *RedisStreams) PublishEvent(ctx context.Context, event Event) error {
+	message, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	return s.add(ctx, eventsStreamPrefix+event.TenantID, message)
+}
+
+func (s *RedisStreams) add(ctx context.Context, stream string, message []byte) error {
+	err := s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		MaxLen: s.maxLen,
+		Approx: true,
+		Values: map[string]any{streamPayloadField: message},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to add to Redis stream %s: %w", stream, err)
+	}
+	return nil
+}
+
+// Subscribe starts delivering the tenant's new logs to callback. The
+// instance's group is moved to the end of the stream first, so a restarted
+// instance doesn't deliver logs from before its clients connected.
+func (s *RedisStreams) Subscribe(ctx context.Context, tenantID string, callback func(*dto.AuditLogResponse)) error {
+	if err := s.join(ctx, tenantID, callback, "$"); err != nil {
+		return err
+	}
+	s.logger.Infof("Subscribed to tenant stream: %s", s.streamName(tenantID))
+	return nil
+}
+
+// join starts delivering the tenant's logs after start to callback
+func (s *RedisStreams) join(ctx context.Context, tenantID string, callback func(*dto.AuditLogResponse), start string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.callbacks[tenantID]; exists {
+		return nil
+	}
+
+	stream := s.streamName(tenantID)
+	err := s.client.XGroupCreateMkStream(ctx, stream, s.group, start).Err()
+	if err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		err = s.client.XGroupSetID(ctx, stream, s.group, start).Err()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to join Redis stream %s: %w", stream, err)
+	}
+
+	s.callbacks[tenantID] = callback
+	if s.done == nil {
+		readCtx, cancel := context.WithCancel(ctx)
+		s.cancel = cancel
+		s.done = make(chan struct{})
+		go s.read(readCtx, s.done)
+	}
+	return nil
+}
+
+// Unsubscribe stops delivering the tenant's logs. Its group is kept, holding
+// no pending logs once the current read is acknowledged.
+func (s *RedisStreams) Unsubscribe(tenantID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.callbacks[tenantID]; exists {
+		delete(s.callbacks, tenantID)
+		s.logger.Infof("Unsubscribed from tenant stream: %s", s.streamName(tenantID))
+	}
+}
+
+// SubscribeAll starts delivering the new logs of every tenant stream to
+// callback. Streams are discovered every streamDiscoveryInterval; a stream
+// found after the first discovery belongs to a tenant that published its
+// first logs since, which are delivered from its start.
+func (s *RedisStreams) SubscribeAll(ctx context.Context, callback func(*dto.AuditLogResponse)) error {
+	s.mu.Lock()
+	if s.all != nil {
+		s.mu.Unlock()
+		return nil
+	}
+	all := &RedisStreams{
+		client:    s.client,
+		logger:    s.logger,
+		group:     s.group + ":all",
+		maxLen:    s.maxLen,
+		callbacks: make(map[string]func(*dto.AuditLogResponse)),
+	}
+	discoverCtx, cancel := context.WithCancel(ctx)
+	s.all, s.cancelAll = all, cancel
+	s.mu.Unlock()
+
+	if err := all.discover(discoverCtx, callback, "$"); err != nil {
+		s.UnsubscribeAll()
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(streamDiscoveryInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-discoverCtx.Done():
+				return
+			case <-ticker.C:
+				if err := all.discover(discoverCtx, callback, "0"); err != nil && discoverCtx.Err() == nil {
+					s.logger.Errorf("Failed to discover tenant streams: %v", err)
+				}
+			}
+		}
+	}()
+
+	s.logger.Infof("Subscribed to all tenant streams")
+	return nil
+}
+
+// discover joins the tenant streams not read yet, from start
+func (s *RedisStreams) discover(ctx context.Context, callback func(*dto.AuditLogResponse), start string) error {
+	iter := s.client.ScanType(ctx, 0, streamPrefix+"*", 1000, "stream").Iterator()
+	for iter.Next(ctx) {
+		tenantID := strings.TrimPrefix(iter.Val(), streamPrefix)
+
+		s.mu.Lock()
+		_, joined := s.callbacks[tenantID]
+		s.mu.Unlock()
+		if joined {
+			continue
+		}
+
+		if err := s.join(ctx, tenantID, callback, start); err != nil {
+			return err
+		}
+	}
+	return iter.Err()
+}
+
+// UnsubscribeAll stops delivering the logs of every tenant
+func (s *RedisStreams) UnsubscribeAll() {
+	s.mu.Lock()
+	all, cancel := s.all, s.cancelAll
+	s.all, s.cancelAll = nil, nil
+	s.mu.Unlock()
+
+	if all != nil {
+		cancel()
+		all.Close()
+		s.logger.Infof("Unsubscribed from all tenant streams")
+	}
+}
+
+// Close stops reading and waits for the logs being delivered
+func (s *RedisStreams) Close() {
+	s.UnsubscribeAll()
+
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.callbacks = make(map[string]func(*dto.AuditLogResponse))
+	s.cancel, s.done = nil, nil
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// read delivers the logs of every subscribed tenant with one blocking read,
+// so the connections used don't grow with the number of tenants
+func (s *RedisStreams) read(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	for ctx.Err() == nil {
+		s.mu.Lock()
+		tenantIDs := make([]string, 0, len(s.callbacks))
+		for tenantID := range s.callbacks {
+			tenantIDs = append(tenantIDs, tenantID)
+		}
+		recovering := s.recovering
+		s.recovering = false
+		s.mu.Unlock()
+
+		if len(tenantIDs) == 0 {
+			s.wait(ctx, streamBlockTimeout)
+			continue
+		}
+
+		// Pending logs were read but not acknowledged; they are delivered before new ones
+		if recovering {
+			for _, tenantID := range tenantIDs {
+				s.readPending(ctx, tenantID)
+			}
+			continue
+		}
+
+		streams := make([]string, 0, 2*len(tenantIDs))
+		for _, tenantID := range tenantIDs {
+			streams = append(streams, s.streamName(tenantID))
+		}
+		for range tenantIDs {
+			streams = append(streams, ">")
+		}
+
+		results, err := s.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    s.group,
+			Consumer: s.group,
+			Streams:  streams,
+			Count:    streamReadCount,
+			Block:    streamBlockTimeout,
+		}).Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			s.fail(ctx, fmt.Errorf("failed to read Redis streams: %w", err))
+			continue
+		}
+
+		for _, result := range results {
+			s.deliver(ctx, result.Stream, result.Messages)
+		}
+	}
+}
+
+// readPending delivers the tenant's logs that were read but not acknowledged
+func (s *RedisStreams) readPending(ctx context.Context, tenantID string) {
+	stream := s.streamName(tenantID)
+	results, err := s.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    s.group,
+		Consumer: s.group,
+		Streams:  []string{stream, "0"},
+		Count:    streamReadCount,
+	}).Result()
+	if err != nil && err != redis.Nil {
+		s.fail(ctx, fmt.Errorf("failed to read pending logs of Redis stream %s: %w", stream, err))
+		return
+	}
+
+	for _, result := range results {
+		if len(result.Messages) == streamReadCount {
+			// More pending logs remain than were read
+			s.mu.Lock()
+			s.recovering = true
+			s.mu.Unlock()
+		}
+		s.deliver(ctx, result.Stream, result.Messages)
+	}
+}
+
+// deliver hands logs to the tenant's callback and acknowledges them.
+// Undecodable logs are acknowledged too, so they aren't read again.
+func (s *RedisStreams) deliver(ctx context.Context, stream string, messages []redis.XMessage) {
+	if len(messages) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	callback := s.callbacks[strings.TrimPrefix(stream, streamPrefix)]
+	s.mu.Unlock()
+
+	ids := make([]string, 0, len(messages))
+	for _, message := range messages {
+		ids = append(ids, message.ID)
+		if callback == nil {
+			continue
+		}
+
+		payload, _ := message.Values[streamPayloadField].(string)
+		var log dto.AuditLogResponse
+		if err := json.Unmarshal([]byte(payload), &log); err != nil {
+			s.logger.Errorf("Failed to unmarshal audit log %s from stream %s: %v", message.ID, stream, err)
+			continue
+		}
+		callback(&log)
+	}
+
+	if err := s.client.XAck(ctx, stream, s.group, ids...).Err(); err != nil {
+		s.fail(ctx, fmt.Errorf("failed to acknowledge logs of Redis stream %s: %w", stream, err))
+	}
+}
+
+// fail reports a failed read or acknowledgement and waits before retrying
+// with the pending logs
+func (s *RedisStreams) fail(ctx context.Context, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	s.logger.Errorf("%v", err)
+
+	s.mu.Lock()
+	s.recovering = true
+	s.mu.Unlock()
+	s.wait(ctx, streamRetryInterval)
+}
+
+func (s *RedisStreams) wait(ctx context.Context, d time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
@@ -0,0 +1,440 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+	"github.com/kingrain94/audit-log-api/internal/utils"
+)
+
+// kafkaBatchWait bounds how long a receive waits for more messages once one
+// has arrived, so workers get a batch without waiting the full poll time
+const kafkaBatchWait = 100 * time.Millisecond
+
+// KafkaQueue is the Queue backed by Kafka topics. Workers consume through a
+// consumer group and acknowledge messages individually like SQS: offsets are
+// only committed once every earlier message of the partition is deleted, and
+// messages left unacknowledged past the visibility timeout are re-published.
+type KafkaQueue struct {
+	writer    *kafka.Writer
+	config    *config.KafkaConfig
+	mu        sync.Mutex
+	consumers map[Name]*kafkaConsumer
+}
+
+type kafkaConsumer struct {
+	reader   *kafka.Reader
+	mu       sync.Mutex
+	inflight map[string]*inflightMessage
+}
+
+type inflightMessage struct {
+	message kafka.Message
+	// visibleAt is when the message is re-published unless deleted first
+	visibleAt time.Time
+	settled   bool
+}
+
+func NewKafkaQueue(config *config.KafkaConfig) *KafkaQueue {
+	return &KafkaQueue{
+		writer: &kafka.Writer{
+			Addr: kafka.TCP(config.Brokers...),
+			// Keying by tenant keeps each tenant's messages in order within a partition
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			BatchTimeout: 10 * time.Millisecond,
+		},
+		config:    config,
+		consumers: make(map[Name]*kafkaConsumer),
+	}
+}
+
+func (q *KafkaQueue) topic(name Name) string {
+	switch name {
+	case IndexQueue:
+		return q.config.IndexTopic
+	case ArchiveQueue:
+		return q.config.ArchiveTopic
+	case CleanupQueue:
+		return q.config.CleanupTopic
+	case ExportQueue:
+		return q.config.ExportTopic
+	case IngestQueue:
+		return q.config.IngestTopic
+	}
+	return ""
+}
+
+func (q *KafkaQueue) SendIndexMessage(ctx context.Context, log *domain.AuditLog) error {
+	return q.sendMessage(ctx, newIndexMessage(log), IndexQueue)
+}
+
+func (q *KafkaQueue) SendBulkIndexMessage(ctx context.Context, logs []domain.AuditLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	return q.sendMessage(ctx, newBulkIndexMessage(logs), IndexQueue)
+}
+
+func (q *KafkaQueue) SendArchiveMessage(ctx context.Context, tenantID, jobID string, beforeDate time.Time) error {
+	return q.sendMessage(ctx, newRetentionMessage(MessageTypeArchive, tenantID, jobID, beforeDate), ArchiveQueue)
+}
+
+func (q *KafkaQueue) SendCleanupMessage(ctx context.Context, tenantID, jobID string, beforeDate time.Time) error {
+	return q.sendMessage(ctx, newRetentionMessage(MessageTypeCleanup, tenantID, jobID, beforeDate), CleanupQueue)
+}
+
+func (q *KafkaQueue) SendExportMessage(ctx context.Context, tenantID, jobID string) error {
+	return q.sendMessage(ctx, newJobMessage(MessageTypeExport, tenantID, jobID), ExportQueue)
+}
+
+// SendRestoreMessage enqueues a restore job on the archive topic, whose worker owns the S3 archives
+func (q *KafkaQueue) SendRestoreMessage(ctx context.Context, tenantID, jobID string) error {
+	return q.sendMessage(ctx, newJobMessage(MessageTypeRestore, tenantID, jobID), ArchiveQueue)
+}
+
+// SendImportMessage enqueues an import job on the archive topic, whose worker restores logs in bulk
+func (q *KafkaQueue) SendImportMessage(ctx context.Context, tenantID, jobID string) error {
+	return q.sendMessage(ctx, newJobMessage(MessageTypeImport, tenantID, jobID), ArchiveQueue)
+}
+
+func (q *KafkaQueue) SendIngestMessage(ctx context.Context, logs []domain.AuditLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	return q.sendMessage(ctx, newIngestMessage(logs), IngestQueue)
+}
+
+// SendBulkIndexMessages writes a BULK_INDEX message per batch in one call to
+// the writer
+func (q *KafkaQueue) SendBulkIndexMessages(ctx context.Context, batches []IndexBatch) (err error) {
+	topic := q.topic(IndexQueue)
+	ctx, span := tracing.Start(ctx, "kafka.send_batch "+topic,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypePublish,
+			semconv.MessagingDestinationName(topic),
+			semconv.MessagingBatchMessageCount(len(batches)),
+			attribute.String("queue.message_type", string(MessageTypeBulkIndex)),
+		),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	failed := make(map[int]error)
+	var records []kafka.Message
+	var positions []int
+	for i, batch := range batches {
+		if len(batch.Logs) == 0 {
+			continue
+		}
+		msg := batch.message(ctx)
+		msgBody, err := json.Marshal(msg)
+		if err != nil {
+			failed[i] = fmt.Errorf("failed to marshal message: %w", err)
+			continue
+		}
+
+		record := kafka.Message{
+			Topic: topic,
+			Key:   []byte(msg.TenantID),
+			Value: msgBody,
+		}
+		if msg.RequestID != "" {
+			record.Headers = []kafka.Header{{Key: requestIDAttribute, Value: []byte(msg.RequestID)}}
+		}
+		records = append(records, record)
+		positions = append(positions, i)
+	}
+
+	if len(records) > 0 {
+		err := q.writer.WriteMessages(ctx, records...)
+		var writeErrs kafka.WriteErrors
+		switch {
+		case errors.As(err, &writeErrs):
+			for j, writeErr := range writeErrs {
+				if writeErr != nil {
+					failed[positions[j]] = fmt.Errorf("failed to send message: %w", writeErr)
+				}
+			}
+		case err != nil:
+			for _, i := range positions {
+				failed[i] = fmt.Errorf("failed to send message: %w", err)
+			}
+		}
+	}
+
+	sent := 0
+	for _, i := range positions {
+		if failed[i] == nil {
+			sent++
+		}
+	}
+	metrics.QueueMessagesSentTotal.WithLabelValues(topic, string(MessageTypeBulkIndex), "success").Add(float64(sent))
+	metrics.QueueMessagesSentTotal.WithLabelValues(topic, string(MessageTypeBulkIndex), "error").Add(float64(len(positions) - sent))
+
+	if len(failed) > 0 {
+		return &BatchSendError{Failed: failed}
+	}
+	return nil
+}
+
+func (q *KafkaQueue) sendMessage(ctx context.Context, msg Message, name Name) (err error) {
+	topic := q.topic(name)
+	ctx, span := tracing.Start(ctx, "kafka.send "+topic,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypePublish,
+			semconv.MessagingDestinationName(topic),
+			attribute.String("queue.message_type", string(msg.Type)),
+			tracing.TenantAttr(msg.TenantID),
+		),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	msg.TraceContext = tracing.Inject(ctx)
+	msg.RequestID = utils.RequestIDFromContext(ctx)
+
+	msgBody, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	record := kafka.Message{
+		Topic: topic,
+		Key:   []byte(msg.TenantID),
+		Value: msgBody,
+	}
+	if msg.RequestID != "" {
+		record.Headers = []kafka.Header{{Key: requestIDAttribute, Value: []byte(msg.RequestID)}}
+	}
+
+	err = q.writer.WriteMessages(ctx, record)
+	if err != nil {
+		metrics.QueueMessagesSentTotal.WithLabelValues(topic, string(msg.Type), "error").Inc()
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	metrics.QueueMessagesSentTotal.WithLabelValues(topic, string(msg.Type), "success").Inc()
+	return nil
+}
+
+// consumer returns the consumer group reader of a topic, creating it on first use
+func (q *KafkaQueue) consumer(name Name) *kafkaConsumer {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if c, ok := q.consumers[name]; ok {
+		return c
+	}
+	c := &kafkaConsumer{
+		reader: kafka.NewReader(kafka.ReaderConfig{
+			Brokers: q.config.Brokers,
+			GroupID: q.config.ConsumerGroup,
+			Topic:   q.topic(name),
+		}),
+		inflight: make(map[string]*inflightMessage),
+	}
+	q.consumers[name] = c
+	return c
+}
+
+func (q *KafkaQueue) ReceiveMessages(ctx context.Context, name Name, maxMessages int32, waitTimeSeconds int32) ([]ReceivedMessage, error) {
+	c := q.consumer(name)
+	if err := q.requeueExpired(ctx, c); err != nil {
+		return nil, err
+	}
+
+	wait := time.Duration(waitTimeSeconds) * time.Second
+	var messages []ReceivedMessage
+	for len(messages) < int(maxMessages) {
+		m, err := fetchMessage(ctx, c.reader, wait)
+		if err != nil {
+			// An elapsed wait time or batch wait just ends the receive, like SQS long polling
+			if len(messages) > 0 || (errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil) {
+				break
+			}
+			return nil, fmt.Errorf("failed to receive messages: %w", err)
+		}
+		wait = kafkaBatchWait
+
+		handle := strconv.Itoa(m.Partition) + ":" + strconv.FormatInt(m.Offset, 10)
+		inflight := &inflightMessage{message: m, visibleAt: time.Now().Add(q.config.VisibilityTimeout)}
+		c.mu.Lock()
+		c.inflight[handle] = inflight
+		c.mu.Unlock()
+
+		var message Message
+		if err := json.Unmarshal(m.Value, &message); err != nil {
+			// Acknowledge malformed messages so they don't block the partition's offset
+			_ = q.DeleteMessage(ctx, name, &handle)
+			return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+		}
+
+		metrics.QueueProcessingLag.WithLabelValues(m.Topic).Observe(time.Since(m.Time).Seconds())
+		messages = append(messages, ReceivedMessage{
+			Message:       message,
+			ReceiptHandle: &handle,
+			SentAt:        m.Time,
+		})
+	}
+
+	return messages, nil
+}
+
+func fetchMessage(ctx context.Context, reader *kafka.Reader, wait time.Duration) (kafka.Message, error) {
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+	return reader.FetchMessage(ctx)
+}
+
+func (q *KafkaQueue) DeleteMessage(ctx context.Context, name Name, receiptHandle *string) error {
+	return q.DeleteMessages(ctx, name, []*string{receiptHandle})
+}
+
+// DeleteMessages settles the messages and commits the offsets they unblock once
+func (q *KafkaQueue) DeleteMessages(ctx context.Context, name Name, receiptHandles []*string) error {
+	c := q.consumer(name)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Unknown handles were already re-published after their visibility timeout
+	for _, receiptHandle := range receiptHandles {
+		if m, ok := c.inflight[*receiptHandle]; ok {
+			m.settled = true
+		}
+	}
+	if err := c.commitSettled(ctx); err != nil {
+		return fmt.Errorf("failed to delete message: %w", err)
+	}
+	return nil
+}
+
+// ChangeMessageVisibility moves the re-publish deadline of an in-flight
+// message. A released message is re-published on the next receive, or
+// delivered again from its uncommitted offset once the consumer closes.
+func (q *KafkaQueue) ChangeMessageVisibility(ctx context.Context, name Name, receiptHandle *string, timeout time.Duration) error {
+	c := q.consumer(name)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if m, ok := c.inflight[*receiptHandle]; ok && !m.settled {
+		m.visibleAt = time.Now().Add(timeout)
+	}
+	return nil
+}
+
+// requeueExpired re-publishes messages whose visibility timeout expired
+// without a delete, so they are delivered again once their offset is committed
+func (q *KafkaQueue) requeueExpired(ctx context.Context, c *kafkaConsumer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, m := range c.inflight {
+		if m.settled || time.Now().Before(m.visibleAt) {
+			continue
+		}
+		err := q.writer.WriteMessages(ctx, kafka.Message{
+			Topic:   m.message.Topic,
+			Key:     m.message.Key,
+			Value:   m.message.Value,
+			Headers: m.message.Headers,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to requeue message: %w", err)
+		}
+		m.settled = true
+	}
+
+	return c.commitSettled(ctx)
+}
+
+// commitSettled commits, per partition, the offsets of the leading run of
+// settled messages. Kafka offsets are cumulative, so a message still in
+// flight holds back the commit of every later message in its partition.
+// The caller must hold c.mu.
+func (c *kafkaConsumer) commitSettled(ctx context.Context) error {
+	byPartition := make(map[int][]string)
+	for handle, m := range c.inflight {
+		byPartition[m.message.Partition] = append(byPartition[m.message.Partition], handle)
+	}
+
+	var commits []kafka.Message
+	var committed []string
+	for _, handles := range byPartition {
+		sort.Slice(handles, func(i, j int) bool {
+			return c.inflight[handles[i]].message.Offset < c.inflight[handles[j]].message.Offset
+		})
+
+		var last *kafka.Message
+		for _, handle := range handles {
+			if !c.inflight[handle].settled {
+				break
+			}
+			last = &c.inflight[handle].message
+			committed = append(committed, handle)
+		}
+		if last != nil {
+			commits = append(commits, *last)
+		}
+	}
+	if len(commits) == 0 {
+		return nil
+	}
+
+	if err := c.reader.CommitMessages(ctx, commits...); err != nil {
+		return err
+	}
+	for _, handle := range committed {
+		delete(c.inflight, handle)
+	}
+	return nil
+}
+
+func (q *KafkaQueue) StartConsumerSpan(ctx context.Context, name Name, msg Message) (context.Context, trace.Span) {
+	topic := q.topic(name)
+	ctx = tracing.Extract(ctx, msg.TraceContext)
+	return tracing.Start(ctx, "kafka.process "+topic,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypeDeliver,
+			semconv.MessagingDestinationName(topic),
+			semconv.MessagingKafkaConsumerGroup(q.config.ConsumerGroup),
+			attribute.String("queue.message_type", string(msg.Type)),
+			tracing.TenantAttr(msg.TenantID),
+		),
+	)
+}
+
+// Close stops the consumers, leaving uncommitted messages to be delivered
+// again, and flushes the writer
+func (q *KafkaQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var errs []error
+	for _, c := range q.consumers {
+		errs = append(errs, c.reader.Close())
+	}
+	errs = append(errs, q.writer.Close())
+	return errors.Join(errs...)
+}
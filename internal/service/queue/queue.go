@@ -0,0 +1,205 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+	"github.com/kingrain94/audit-log-api/internal/utils"
+)
+
+type MessageType string
+
+// requestIDAttribute names the SQS message attribute and Kafka header carrying
+// Message.RequestID, so it can be read without decoding the body
+const requestIDAttribute = "RequestId"
+
+const (
+	MessageTypeIndex     MessageType = "INDEX"
+	MessageTypeBulkIndex MessageType = "BULK_INDEX"
+	MessageTypeArchive   MessageType = "ARCHIVE"
+	MessageTypeCleanup   MessageType = "CLEANUP"
+	MessageTypeExport    MessageType = "EXPORT"
+	MessageTypeRestore   MessageType = "RESTORE"
+	MessageTypeImport    MessageType = "IMPORT"
+	MessageTypeIngest    MessageType = "INGEST"
+)
+
+type Message struct {
+	Type      MessageType       `json:"type"`
+	TenantID  string            `json:"tenant_id"`
+	Logs      []domain.AuditLog `json:"logs,omitempty"`
+	Timestamp time.Time         `json:"timestamp"`
+
+	// Fields for archive/cleanup operations
+	BeforeDate time.Time `json:"before_date,omitempty"`
+
+	// JobID references the export, restore, import or cleanup job for job-based operations
+	JobID string `json:"job_id,omitempty"`
+
+	// TraceContext carries the producer's W3C trace context to the consumer
+	TraceContext map[string]string `json:"trace_context,omitempty"`
+
+	// RequestID is the ID of the API call that sent the message, if any
+	RequestID string `json:"request_id,omitempty"`
+}
+
+type ReceivedMessage struct {
+	Message       Message
+	ReceiptHandle *string
+	SentAt        time.Time
+}
+
+func newIndexMessage(log *domain.AuditLog) Message {
+	return Message{
+		Type:      MessageTypeIndex,
+		TenantID:  log.TenantID,
+		Logs:      []domain.AuditLog{*log},
+		Timestamp: log.Timestamp,
+	}
+}
+
+func newBulkIndexMessage(logs []domain.AuditLog) Message {
+	return Message{
+		Type:      MessageTypeBulkIndex,
+		TenantID:  logs[0].TenantID,
+		Logs:      logs,
+		Timestamp: logs[0].Timestamp,
+	}
+}
+
+// IndexBatch holds the logs of one BULK_INDEX message sent with
+// SendBulkIndexMessages. RequestID and TraceContext tie the message to the
+// request that created the logs; when empty they are taken from the sender's
+// context like for single sends.
+type IndexBatch struct {
+	Logs         []domain.AuditLog
+	RequestID    string
+	TraceContext map[string]string
+}
+
+func (b IndexBatch) message(ctx context.Context) Message {
+	msg := newBulkIndexMessage(b.Logs)
+	msg.RequestID = b.RequestID
+	if msg.RequestID == "" {
+		msg.RequestID = utils.RequestIDFromContext(ctx)
+	}
+	msg.TraceContext = b.TraceContext
+	if len(msg.TraceContext) == 0 {
+		msg.TraceContext = tracing.Inject(ctx)
+	}
+	return msg
+}
+
+// BatchSendError reports the messages of a batch send that were not sent,
+// keyed by their position in the batch. The others were sent.
+type BatchSendError struct {
+	Failed map[int]error
+}
+
+func (e *BatchSendError) Error() string {
+	for _, err := range e.Failed {
+		return fmt.Sprintf("failed to send %d messages of the batch: %v", len(e.Failed), err)
+	}
+	return "failed to send messages of the batch"
+}
+
+// newIngestMessage builds a message carrying accepted logs to be stored by the ingest worker
+func newIngestMessage(logs []domain.AuditLog) Message {
+	return Message{
+		Type:      MessageTypeIngest,
+		TenantID:  logs[0].TenantID,
+		Logs:      logs,
+		Timestamp: time.Now(),
+	}
+}
+
+// newRetentionMessage builds an archive or cleanup message for logs before
+// beforeDate, for the cleanup job with jobID if it was scheduled through the API
+func newRetentionMessage(msgType MessageType, tenantID, jobID string, beforeDate time.Time) Message {
+	return Message{
+		Type:       msgType,
+		TenantID:   tenantID,
+		JobID:      jobID,
+		BeforeDate: beforeDate,
+		Timestamp:  time.Now(),
+	}
+}
+
+// newJobMessage builds an export or restore message for a job
+func newJobMessage(msgType MessageType, tenantID, jobID string) Message {
+	return Message{
+		Type:      msgType,
+		TenantID:  tenantID,
+		JobID:     jobID,
+		Timestamp: time.Now(),
+	}
+}
+
+// Name identifies one of the pipeline's queues independently of the backend
+type Name string
+
+const (
+	IndexQueue   Name = "index"
+	ArchiveQueue Name = "archive"
+	CleanupQueue Name = "cleanup"
+	ExportQueue  Name = "export"
+	IngestQueue  Name = "ingest"
+)
+
+// Queue carries pipeline messages between the API, outbox relay and workers.
+// SQSService and KafkaQueue implement it with the same Message schema.
+type Queue interface {
+	SendIndexMessage(ctx context.Context, log *domain.AuditLog) error
+	SendBulkIndexMessage(ctx context.Context, logs []domain.AuditLog) error
+	SendArchiveMessage(ctx context.Context, tenantID, jobID string, beforeDate time.Time) error
+	SendCleanupMessage(ctx context.Context, tenantID, jobID string, beforeDate time.Time) error
+	SendExportMessage(ctx context.Context, tenantID, jobID string) error
+	SendRestoreMessage(ctx context.Context, tenantID, jobID string) error
+	SendImportMessage(ctx context.Context, tenantID, jobID string) error
+	SendIngestMessage(ctx context.Context, logs []domain.AuditLog) error
+	// SendBulkIndexMessages sends a BULK_INDEX message per batch, several per
+	// call where the backend allows. Batches that were not sent are reported
+	// by a *BatchSendError.
+	SendBulkIndexMessages(ctx context.Context, batches []IndexBatch) error
+
+	// ReceiveMessages waits up to waitTimeSeconds for at most maxMessages messages
+	ReceiveMessages(ctx context.Context, name Name, maxMessages int32, waitTimeSeconds int32) ([]ReceivedMessage, error)
+	// DeleteMessage acknowledges a processed message. Messages that are never
+	// deleted are delivered again.
+	DeleteMessage(ctx context.Context, name Name, receiptHandle *string) error
+	// DeleteMessages acknowledges several processed messages at once
+	DeleteMessages(ctx context.Context, name Name, receiptHandles []*string) error
+	// ChangeMessageVisibility hides a received message for timeout from now.
+	// A zero timeout makes it available for redelivery immediately.
+	ChangeMessageVisibility(ctx context.Context, name Name, receiptHandle *string, timeout time.Duration) error
+	// StartConsumerSpan continues the producer's trace for a received message.
+	// The caller must end the returned span once the message has been handled.
+	StartConsumerSpan(ctx context.Context, name Name, msg Message) (context.Context, trace.Span)
+
+	Close() error
+}
+
+// New validates cfg and connects to the queue backend it selects
+func New(cfg *config.QueueConfig) (Queue, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch cfg.Backend {
+	case config.QueueBackendSQS:
+		client, err := cfg.SQS.GetClient()
+		if err != nil {
+			return nil, err
+		}
+		return NewSQSService(client, cfg.SQS), nil
+	case config.QueueBackendKafka:
+		return NewKafkaQueue(cfg.Kafka), nil
+	}
+	return nil, fmt.Errorf("unknown queue backend %q", cfg.Backend)
+}
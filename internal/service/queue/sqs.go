@@ -3,105 +3,114 @@ package queue
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/kingrain94/audit-log-api/internal/config"
 	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+	"github.com/kingrain94/audit-log-api/internal/utils"
+	"github.com/kingrain94/audit-log-api/pkg/breaker"
 )
 
-type MessageType string
-
 const (
-	MessageTypeIndex     MessageType = "INDEX"
-	MessageTypeBulkIndex MessageType = "BULK_INDEX"
-	MessageTypeArchive   MessageType = "ARCHIVE"
-	MessageTypeCleanup   MessageType = "CLEANUP"
+	// maxBatchEntries is the most messages SQS takes in one batch call
+	maxBatchEntries = 10
+	// maxBatchBytes caps the total body size of a SendMessageBatch call
+	maxBatchBytes = 256 * 1024
 )
 
-type Message struct {
-	Type      MessageType       `json:"type"`
-	TenantID  string            `json:"tenant_id"`
-	Logs      []domain.AuditLog `json:"logs,omitempty"`
-	Timestamp time.Time         `json:"timestamp"`
-
-	// Fields for archive/cleanup operations
-	BeforeDate time.Time `json:"before_date,omitempty"`
-}
-
-type ReceivedMessage struct {
-	Message       Message
-	ReceiptHandle *string
-}
-
+// SQSService is the Queue backed by Amazon SQS. Its SQS calls go through a
+// circuit breaker, so they fail fast while SQS is unreachable.
 type SQSService struct {
 	client          *sqs.Client
+	breaker         *breaker.Breaker
 	indexQueueURL   string
 	archiveQueueURL string
 	cleanupQueueURL string
+	exportQueueURL  string
+	ingestQueueURL  string
 }
 
 func NewSQSService(client *sqs.Client, config *config.SQSConfig) *SQSService {
 	return &SQSService{
 		client:          client,
+		breaker:         config.Breaker.NewBreaker("sqs", nil, metrics.ObserveBreakerStateChange),
 		indexQueueURL:   config.IndexQueueURL,
 		archiveQueueURL: config.ArchiveQueueURL,
 		cleanupQueueURL: config.CleanupQueueURL,
+		exportQueueURL:  config.ExportQueueURL,
+		ingestQueueURL:  config.IngestQueueURL,
 	}
 }
 
 func (s *SQSService) SendIndexMessage(ctx context.Context, log *domain.AuditLog) error {
-	msg := Message{
-		Type:      MessageTypeIndex,
-		TenantID:  log.TenantID,
-		Logs:      []domain.AuditLog{*log},
-		Timestamp: log.Timestamp,
-	}
-
-	return s.sendMessage(ctx, msg, s.indexQueueURL)
+	return s.sendMessage(ctx, newIndexMessage(log), s.indexQueueURL)
 }
 
 func (s *SQSService) SendBulkIndexMessage(ctx context.Context, logs []domain.AuditLog) error {
 	if len(logs) == 0 {
 		return nil
 	}
+	return s.sendMessage(ctx, newBulkIndexMessage(logs), s.indexQueueURL)
+}
 
-	msg := Message{
-		Type:      MessageTypeBulkIndex,
-		TenantID:  logs[0].TenantID,
-		Logs:      logs,
-		Timestamp: logs[0].Timestamp,
-	}
+func (s *SQSService) SendArchiveMessage(ctx context.Context, tenantID, jobID string, beforeDate time.Time) error {
+	return s.sendMessage(ctx, newRetentionMessage(MessageTypeArchive, tenantID, jobID, beforeDate), s.archiveQueueURL)
+}
 
-	return s.sendMessage(ctx, msg, s.indexQueueURL)
+func (s *SQSService) SendCleanupMessage(ctx context.Context, tenantID, jobID string, beforeDate time.Time) error {
+	return s.sendMessage(ctx, newRetentionMessage(MessageTypeCleanup, tenantID, jobID, beforeDate), s.cleanupQueueURL)
 }
 
-func (s *SQSService) SendArchiveMessage(ctx context.Context, tenantID string, beforeDate time.Time) error {
-	msg := Message{
-		Type:       MessageTypeArchive,
-		TenantID:   tenantID,
-		BeforeDate: beforeDate,
-		Timestamp:  time.Now(),
-	}
+func (s *SQSService) SendExportMessage(ctx context.Context, tenantID, jobID string) error {
+	return s.sendMessage(ctx, newJobMessage(MessageTypeExport, tenantID, jobID), s.exportQueueURL)
+}
 
-	return s.sendMessage(ctx, msg, s.archiveQueueURL)
+// SendRestoreMessage enqueues a restore job on the archive queue, whose worker owns the S3 archives
+func (s *SQSService) SendRestoreMessage(ctx context.Context, tenantID, jobID string) error {
+	return s.sendMessage(ctx, newJobMessage(MessageTypeRestore, tenantID, jobID), s.archiveQueueURL)
 }
 
-func (s *SQSService) SendCleanupMessage(ctx context.Context, tenantID string, beforeDate time.Time) error {
-	msg := Message{
-		Type:       MessageTypeCleanup,
-		TenantID:   tenantID,
-		BeforeDate: beforeDate,
-		Timestamp:  time.Now(),
-	}
+// SendImportMessage enqueues an import job on the archive queue, whose worker restores logs in bulk
+func (s *SQSService) SendImportMessage(ctx context.Context, tenantID, jobID string) error {
+	return s.sendMessage(ctx, newJobMessage(MessageTypeImport, tenantID, jobID), s.archiveQueueURL)
+}
 
-	return s.sendMessage(ctx, msg, s.cleanupQueueURL)
+func (s *SQSService) SendIngestMessage(ctx context.Context, logs []domain.AuditLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	return s.sendMessage(ctx, newIngestMessage(logs), s.ingestQueueURL)
 }
 
-func (s *SQSService) sendMessage(ctx context.Context, msg Message, queueURL string) error {
+func (s *SQSService) sendMessage(ctx context.Context, msg Message, queueURL string) (err error) {
+	ctx, span := tracing.Start(ctx, "sqs.send "+queueName(queueURL),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemAWSSqs,
+			semconv.MessagingOperationTypePublish,
+			semconv.MessagingDestinationName(queueName(queueURL)),
+			attribute.String("queue.message_type", string(msg.Type)),
+			tracing.TenantAttr(msg.TenantID),
+		),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	msg.TraceContext = tracing.Inject(ctx)
+	msg.RequestID = utils.RequestIDFromContext(ctx)
+
 	msgBody, err := json.Marshal(msg)
 	if err != nil {
 		return fmt.Errorf("failed to marshal message: %w", err)
@@ -111,23 +120,147 @@ func (s *SQSService) sendMessage(ctx context.Context, msg Message, queueURL stri
 		MessageBody: aws.String(string(msgBody)),
 		QueueUrl:    aws.String(queueURL),
 	}
+	if msg.RequestID != "" {
+		input.MessageAttributes = map[string]types.MessageAttributeValue{
+			requestIDAttribute: {DataType: aws.String("String"), StringValue: aws.String(msg.RequestID)},
+		}
+	}
 
-	_, err = s.client.SendMessage(ctx, input)
+	err = s.breaker.Execute(func() error {
+		_, err := s.client.SendMessage(ctx, input)
+		return err
+	})
 	if err != nil {
+		metrics.QueueMessagesSentTotal.WithLabelValues(queueName(queueURL), string(msg.Type), "error").Inc()
 		return fmt.Errorf("failed to send message: %w", err)
 	}
 
+	metrics.QueueMessagesSentTotal.WithLabelValues(queueName(queueURL), string(msg.Type), "success").Inc()
+	return nil
+}
+
+// SendBulkIndexMessages sends a BULK_INDEX message per batch with as few
+// SendMessageBatch calls as the entry and payload limits allow
+func (s *SQSService) SendBulkIndexMessages(ctx context.Context, batches []IndexBatch) (err error) {
+	queueURL := s.indexQueueURL
+	ctx, span := tracing.Start(ctx, "sqs.send_batch "+queueName(queueURL),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemAWSSqs,
+			semconv.MessagingOperationTypePublish,
+			semconv.MessagingDestinationName(queueName(queueURL)),
+			semconv.MessagingBatchMessageCount(len(batches)),
+			attribute.String("queue.message_type", string(MessageTypeBulkIndex)),
+		),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	failed := make(map[int]error)
+	var entries []types.SendMessageBatchRequestEntry
+	var positions []int
+	size := 0
+	flush := func() {
+		if len(entries) == 0 {
+			return
+		}
+		for i, err := range s.sendBatch(ctx, queueURL, entries) {
+			failed[positions[i]] = err
+		}
+		entries, positions, size = nil, nil, 0
+	}
+
+	for i, batch := range batches {
+		if len(batch.Logs) == 0 {
+			continue
+		}
+		msg := batch.message(ctx)
+		msgBody, err := json.Marshal(msg)
+		if err != nil {
+			failed[i] = fmt.Errorf("failed to marshal message: %w", err)
+			continue
+		}
+		if len(entries) == maxBatchEntries || (len(entries) > 0 && size+len(msgBody) > maxBatchBytes) {
+			flush()
+		}
+
+		entry := types.SendMessageBatchRequestEntry{
+			Id:          aws.String(strconv.Itoa(len(entries))),
+			MessageBody: aws.String(string(msgBody)),
+		}
+		if msg.RequestID != "" {
+			entry.MessageAttributes = map[string]types.MessageAttributeValue{
+				requestIDAttribute: {DataType: aws.String("String"), StringValue: aws.String(msg.RequestID)},
+			}
+		}
+		entries = append(entries, entry)
+		positions = append(positions, i)
+		size += len(msgBody)
+	}
+	flush()
+
+	if len(failed) > 0 {
+		return &BatchSendError{Failed: failed}
+	}
 	return nil
 }
 
-func (s *SQSService) ReceiveMessages(ctx context.Context, queueURL string, maxMessages int32, waitTimeSeconds int32) ([]ReceivedMessage, error) {
+// sendBatch sends entries in one SendMessageBatch call and returns the errors
+// of those that were not sent, keyed by their position in entries
+func (s *SQSService) sendBatch(ctx context.Context, queueURL string, entries []types.SendMessageBatchRequestEntry) map[int]error {
+	failed := make(map[int]error)
+	output, err := breaker.Call(s.breaker, func() (*sqs.SendMessageBatchOutput, error) {
+		return s.client.SendMessageBatch(ctx, &sqs.SendMessageBatchInput{
+			QueueUrl: aws.String(queueURL),
+			Entries:  entries,
+		})
+	})
+	if err != nil {
+		for i := range entries {
+			failed[i] = fmt.Errorf("failed to send message batch: %w", err)
+		}
+	} else {
+		for _, f := range output.Failed {
+			i, _ := strconv.Atoi(aws.ToString(f.Id))
+			failed[i] = fmt.Errorf("failed to send message: %s: %s", aws.ToString(f.Code), aws.ToString(f.Message))
+		}
+	}
+
+	metrics.QueueMessagesSentTotal.WithLabelValues(queueName(queueURL), string(MessageTypeBulkIndex), "success").Add(float64(len(entries) - len(failed)))
+	metrics.QueueMessagesSentTotal.WithLabelValues(queueName(queueURL), string(MessageTypeBulkIndex), "error").Add(float64(len(failed)))
+	return failed
+}
+
+// url returns the SQS queue URL of a pipeline queue
+func (s *SQSService) url(name Name) string {
+	switch name {
+	case IndexQueue:
+		return s.indexQueueURL
+	case ArchiveQueue:
+		return s.archiveQueueURL
+	case CleanupQueue:
+		return s.cleanupQueueURL
+	case ExportQueue:
+		return s.exportQueueURL
+	case IngestQueue:
+		return s.ingestQueueURL
+	}
+	return ""
+}
+
+func (s *SQSService) ReceiveMessages(ctx context.Context, name Name, maxMessages int32, waitTimeSeconds int32) ([]ReceivedMessage, error) {
+	queueURL := s.url(name)
 	input := &sqs.ReceiveMessageInput{
 		QueueUrl:            aws.String(queueURL),
 		MaxNumberOfMessages: maxMessages,
 		WaitTimeSeconds:     waitTimeSeconds,
+		MessageSystemAttributeNames: []types.MessageSystemAttributeName{
+			types.MessageSystemAttributeNameSentTimestamp,
+		},
 	}
 
-	output, err := s.client.ReceiveMessage(ctx, input)
+	output, err := breaker.Call(s.breaker, func() (*sqs.ReceiveMessageOutput, error) {
+		return s.client.ReceiveMessage(ctx, input)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to receive messages: %w", err)
 	}
@@ -138,25 +271,111 @@ func (s *SQSService) ReceiveMessages(ctx context.Context, queueURL string, maxMe
 		if err := json.Unmarshal([]byte(*msg.Body), &message); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal message: %w", err)
 		}
-		messages = append(messages, ReceivedMessage{
+		received := ReceivedMessage{
 			Message:       message,
 			ReceiptHandle: msg.ReceiptHandle,
-		})
+		}
+
+		// SentTimestamp is epoch milliseconds; use it to track processing lag
+		if sent, err := strconv.ParseInt(msg.Attributes[string(types.MessageSystemAttributeNameSentTimestamp)], 10, 64); err == nil {
+			received.SentAt = time.UnixMilli(sent)
+			metrics.QueueProcessingLag.WithLabelValues(queueName(queueURL)).Observe(time.Since(received.SentAt).Seconds())
+		}
+
+		messages = append(messages, received)
 	}
 
 	return messages, nil
 }
 
-func (s *SQSService) DeleteMessage(ctx context.Context, queueURL string, receiptHandle *string) error {
+func (s *SQSService) DeleteMessage(ctx context.Context, name Name, receiptHandle *string) error {
 	input := &sqs.DeleteMessageInput{
-		QueueUrl:      aws.String(queueURL),
+		QueueUrl:      aws.String(s.url(name)),
 		ReceiptHandle: receiptHandle,
 	}
 
-	_, err := s.client.DeleteMessage(ctx, input)
+	err := s.breaker.Execute(func() error {
+		_, err := s.client.DeleteMessage(ctx, input)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("failed to delete message: %w", err)
 	}
 
 	return nil
 }
+
+// DeleteMessages acknowledges messages with DeleteMessageBatch, up to
+// maxBatchEntries per call
+func (s *SQSService) DeleteMessages(ctx context.Context, name Name, receiptHandles []*string) error {
+	var errs []error
+	for start := 0; start < len(receiptHandles); start += maxBatchEntries {
+		chunk := receiptHandles[start:min(start+maxBatchEntries, len(receiptHandles))]
+		entries := make([]types.DeleteMessageBatchRequestEntry, len(chunk))
+		for i, receiptHandle := range chunk {
+			entries[i] = types.DeleteMessageBatchRequestEntry{
+				Id:            aws.String(strconv.Itoa(i)),
+				ReceiptHandle: receiptHandle,
+			}
+		}
+
+		output, err := breaker.Call(s.breaker, func() (*sqs.DeleteMessageBatchOutput, error) {
+			return s.client.DeleteMessageBatch(ctx, &sqs.DeleteMessageBatchInput{
+				QueueUrl: aws.String(s.url(name)),
+				Entries:  entries,
+			})
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete messages: %w", err))
+			continue
+		}
+		for _, f := range output.Failed {
+			errs = append(errs, fmt.Errorf("failed to delete message: %s: %s", aws.ToString(f.Code), aws.ToString(f.Message)))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *SQSService) ChangeMessageVisibility(ctx context.Context, name Name, receiptHandle *string, timeout time.Duration) error {
+	input := &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(s.url(name)),
+		ReceiptHandle:     receiptHandle,
+		VisibilityTimeout: int32(timeout.Seconds()),
+	}
+
+	err := s.breaker.Execute(func() error {
+		_, err := s.client.ChangeMessageVisibility(ctx, input)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to change message visibility: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQSService) StartConsumerSpan(ctx context.Context, name Name, msg Message) (context.Context, trace.Span) {
+	queueURL := s.url(name)
+	ctx = tracing.Extract(ctx, msg.TraceContext)
+	return tracing.Start(ctx, "sqs.process "+queueName(queueURL),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemAWSSqs,
+			semconv.MessagingOperationTypeDeliver,
+			semconv.MessagingDestinationName(queueName(queueURL)),
+			attribute.String("queue.message_type", string(msg.Type)),
+			tracing.TenantAttr(msg.TenantID),
+		),
+	)
+}
+
+// Close is a no-op; the SQS client holds no connections that need closing
+func (s *SQSService) Close() error {
+	return nil
+}
+
+// queueName extracts the queue name from its URL for use as a metric label
+func queueName(queueURL string) string {
+	return queueURL[strings.LastIndex(queueURL, "/")+1:]
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+)
+
+// QuotaWarningEvent is the webhook event of a quota warning
+const QuotaWarningEvent = "quota.warning"
+
+// QuotaWarningService warns tenants nearing a quota with a WARNING audit log
+// in their own logs and a notification to their webhook
+type QuotaWarningService struct {
+	repo     repository.PostgresRepository
+	settings TenantSettingsResolver
+	webhooks *WebhookSender
+}
+
+func NewQuotaWarningService(repo repository.PostgresRepository, settings TenantSettingsResolver, webhooks *WebhookSender) *QuotaWarningService {
+	return &QuotaWarningService{
+		repo:     repo,
+		settings: settings,
+		webhooks: webhooks,
+	}
+}
+
+// quotaWarningPayload is the webhook body of a quota warning
+type quotaWarningPayload struct {
+	Event     string    `json:"event"`
+	TenantID  string    `json:"tenant_id"`
+	Quota     string    `json:"quota"`
+	Period    string    `json:"period"`
+	Used      int64     `json:"used"`
+	Limit     int64     `json:"limit"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// NotifyQuotaWarning stores the warning as an audit log, indexed and broadcast
+// through the outbox like any other log, then posts it to the tenant's
+// webhook. Webhook failures only show in metrics, since the log was recorded.
+func (s *QuotaWarningService) NotifyQuotaWarning(ctx context.Context, warning domain.QuotaWarning) (err error) {
+	ctx, span := tracing.Start(ctx, "QuotaWarningService.NotifyQuotaWarning", trace.WithAttributes(tracing.TenantAttr(warning.TenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	log, err := newQuotaWarningLog(warning)
+	if err != nil {
+		return err
+	}
+	if err := storeIndexedLogs(ctx, s.repo, []domain.AuditLog{*log}); err != nil {
+		return fmt.Errorf("failed to store quota warning log: %w", err)
+	}
+
+	if settings, err := s.settings.ResolveSettings(ctx, warning.TenantID); err == nil {
+		_ = s.webhooks.Send(ctx, settings, QuotaWarningEvent, quotaWarningPayload{
+			Event:     QuotaWarningEvent,
+			TenantID:  warning.TenantID,
+			Quota:     string(warning.Quota),
+			Period:    warning.Period,
+			Used:      warning.Used,
+			Limit:     warning.Limit,
+			Timestamp: warning.At,
+		})
+	}
+	return nil
+}
+
+func newQuotaWarningLog(warning domain.QuotaWarning) (*domain.AuditLog, error) {
+	metadata, err := json.Marshal(map[string]any{
+		"quota":  warning.Quota,
+		"period": warning.Period,
+		"used":   warning.Used,
+		"limit":  warning.Limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal quota warning metadata: %w", err)
+	}
+
+	return &domain.AuditLog{
+		TenantID:     warning.TenantID,
+		Action:       string(domain.ActionQuotaWarning),
+		ResourceType: domain.QuotaResourceType,
+		ResourceID:   string(warning.Quota),
+		Severity:     string(domain.SeverityWarning),
+		Message: fmt.Sprintf("Usage of the %s quota for %s is at %.0f%% (%d of %d)",
+			warning.Quota, warning.Period, warning.Ratio()*100, warning.Used, warning.Limit),
+		Metadata:  metadata,
+		Timestamp: warning.At,
+	}, nil
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/mocks"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type QuotaWarningServiceTestSuite struct {
+	suite.Suite
+	mockRepo     *mocks.PostgresRepository
+	mockAuditLog *mocks.AuditLogRepository
+	mockOutbox   *mocks.OutboxRepository
+	mockSettings *mocks.TenantSettingsResolver
+	service      *QuotaWarningService
+	warning      domain.QuotaWarning
+}
+
+func (s *QuotaWarningServiceTestSuite) SetupTest() {
+	s.mockRepo = new(mocks.PostgresRepository)
+	s.mockAuditLog = new(mocks.AuditLogRepository)
+	s.mockOutbox = new(mocks.OutboxRepository)
+	s.mockSettings = new(mocks.TenantSettingsResolver)
+
+	s.mockRepo.On("AuditLog").Return(s.mockAuditLog)
+	s.mockRepo.On("Outbox").Return(s.mockOutbox)
+	s.mockRepo.On("Transaction", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, fn func(tx repository.PostgresRepository) error) error {
+			return fn(s.mockRepo)
+		})
+
+	s.service = NewQuotaWarningService(s.mockRepo, s.mockSettings, NewWebhookSender(time.Second))
+	s.warning = domain.QuotaWarning{
+		TenantID: "tenant1",
+		Quota:    domain.QuotaDailyLogs,
+		Period:   "2025-07-17",
+		Used:     80,
+		Limit:    100,
+		At:       time.Date(2025, 7, 17, 15, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestQuotaWarningService(t *testing.T) {
+	suite.Run(t, new(QuotaWarningServiceTestSuite))
+}
+
+func (s *QuotaWarningServiceTestSuite) TestNotifyQuotaWarning_StoresLogAndPostsSignedWebhook() {
+	// Arrange
+	ctx := context.Background()
+	var received *http.Request
+	var body []byte
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+	// The test server listens on loopback, which the default client refuses
+	s.service.webhooks = &WebhookSender{client: server.Client()}
+
+	s.mockAuditLog.On("BulkCreate", mock.Anything, mock.MatchedBy(func(logs []domain.AuditLog) bool {
+		return len(logs) == 1 &&
+			logs[0].Severity == string(domain.SeverityWarning) &&
+			logs[0].Action == string(domain.ActionQuotaWarning) &&
+			logs[0].ResourceID == string(domain.QuotaDailyLogs) &&
+			logs[0].Message == "Usage of the daily_logs quota for 2025-07-17 is at 80% (80 of 100)"
+	})).Return(nil)
+	s.mockOutbox.On("Create", mock.Anything, mock.AnythingOfType("*domain.OutboxEvent")).Return(nil)
+	s.mockSettings.On("ResolveSettings", mock.Anything, "tenant1").Return(&domain.TenantSettings{
+		WebhookURL:     server.URL,
+		WebhookSecrets: []string{"whsec_3f9a1c7e2b8d4f60"},
+	}, nil)
+
+	// Act
+	err := s.service.NotifyQuotaWarning(ctx, s.warning)
+
+	// Assert
+	s.NoError(err)
+	s.mockAuditLog.AssertExpectations(s.T())
+	s.Require().NotNil(received)
+	s.Equal(QuotaWarningEvent, received.Header.Get(WebhookEventHeader))
+	s.Equal(signWebhook("whsec_3f9a1c7e2b8d4f60", body), received.Header.Get(WebhookSignatureHeader))
+	var payload quotaWarningPayload
+	s.NoError(json.Unmarshal(body, &payload))
+	s.Equal("daily_logs", payload.Quota)
+	s.Equal(int64(80), payload.Used)
+}
+
+func (s *QuotaWarningServiceTestSuite) TestNotifyQuotaWarning_WebhookFails_LogStillRecorded() {
+	// Arrange
+	ctx := context.Background()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	s.service.webhooks = &WebhookSender{client: server.Client()}
+
+	s.mockAuditLog.On("BulkCreate", mock.Anything, mock.Anything).Return(nil)
+	s.mockOutbox.On("Create", mock.Anything, mock.Anything).Return(nil)
+	s.mockSettings.On("ResolveSettings", mock.Anything, "tenant1").Return(&domain.TenantSettings{WebhookURL: server.URL}, nil)
+
+	// Act
+	err := s.service.NotifyQuotaWarning(ctx, s.warning)
+
+	// Assert
+	s.NoError(err)
+	s.mockOutbox.AssertExpectations(s.T())
+}
+
+func (s *QuotaWarningServiceTestSuite) TestNotifyQuotaWarning_PrivateWebhook_NotPosted() {
+	// Arrange
+	ctx := context.Background()
+	posted := false
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posted = true
+	}))
+	defer server.Close()
+
+	s.mockAuditLog.On("BulkCreate", mock.Anything, mock.Anything).Return(nil)
+	s.mockOutbox.On("Create", mock.Anything, mock.Anything).Return(nil)
+	s.mockSettings.On("ResolveSettings", mock.Anything, "tenant1").Return(&domain.TenantSettings{WebhookURL: server.URL}, nil)
+
+	// Act
+	err := s.service.NotifyQuotaWarning(ctx, s.warning)
+
+	// Assert
+	s.NoError(err)
+	s.False(posted)
+}
+
+func (s *QuotaWarningServiceTestSuite) TestNotifyQuotaWarning_StoreFails_ReturnsError() {
+	// Arrange
+	ctx := context.Background()
+	s.mockAuditLog.On("BulkCreate", mock.Anything, mock.Anything).Return(errors.New("db down"))
+
+	// Act
+	err := s.service.NotifyQuotaWarning(ctx, s.warning)
+
+	// Assert
+	s.Error(err)
+	s.mockSettings.AssertNotCalled(s.T(), "ResolveSettings", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+)
+
+// redactedValue replaces values masked by RedactionMaskFull
+const redactedValue = "[REDACTED]"
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@([A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,})`)
+	ssnPattern   = regexp.MustCompile(`\b\d{3}-\d{2}-(\d{4})\b`)
+	cardPattern  = regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`)
+)
+
+//go:generate mockery --name RedactionRuleCache --output ../mocks
+type RedactionRuleCache interface {
+	Get(ctx context.Context, tenantID string) ([]domain.RedactionRule, error)
+	Set(ctx context.Context, tenantID string, rules []domain.RedactionRule) error
+	Invalidate(ctx context.Context, tenantID string) error
+}
+
+type RedactionService struct {
+	repo  repository.PostgresRepository
+	cache RedactionRuleCache
+}
+
+func NewRedactionService(repo repository.PostgresRepository, cache RedactionRuleCache) *RedactionService {
+	return &RedactionService{
+		repo:  repo,
+		cache: cache,
+	}
+}
+
+func (s *RedactionService) Create(ctx context.Context, tenantID string, req dto.RedactionRuleRequest) (_ *dto.RedactionRuleResponse, err error) {
+	ctx, span := tracing.Start(ctx, "RedactionService.Create", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	rule := &domain.RedactionRule{
+		TenantID: tenantID,
+		Target:   domain.RedactionTarget(req.Target),
+		Path:     strings.TrimSpace(req.Path),
+		Mask:     domain.RedactionMask(req.Mask),
+	}
+	if _, err := redactionPath(rule.Path); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.RedactionRule().ListByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list redaction rules: %w", err)
+	}
+	for _, r := range existing {
+		if r.Target == rule.Target && r.Path == rule.Path && r.Mask == rule.Mask {
+			return nil, ErrRedactionRuleExists
+		}
+	}
+
+	if err := s.repo.RedactionRule().Create(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to create redaction rule: %w", err)
+	}
+	if err := s.cache.Invalidate(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	return dto.FromRedactionRule(rule), nil
+}
+
+func (s *RedactionService) List(ctx context.Context, tenantID string) (_ []dto.RedactionRuleResponse, err error) {
+	ctx, span := tracing.Start(ctx, "RedactionService.List", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	rules, err := s.repo.RedactionRule().ListByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromRedactionRules(rules), nil
+}
+
+func (s *RedactionService) Delete(ctx context.Context, tenantID, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "RedactionService.Delete", trace.WithAttributes(tracing.TenantAttr(tenantID), attribute.String("redaction_rule.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	err = s.repo.RedactionRule().Delete(ctx, tenantID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrRedactionRuleNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete redaction rule: %w", err)
+	}
+
+	return s.cache.Invalidate(ctx, tenantID)
+}
+
+// Redact masks before_state, after_state and metadata of logs in place using
+// the rules of each log's tenant. It fails if rules can't be loaded, so logs
+// are never stored unredacted.
+func (s *RedactionService) Redact(ctx context.Context, logs []domain.AuditLog) (err error) {
+	ctx, span := tracing.Start(ctx, "RedactionService.Redact", trace.WithAttributes(attribute.Int("audit_log.count", len(logs))))
+	defer func() { tracing.End(span, err) }()
+
+	rulesByTenant := make(map[string][]domain.RedactionRule)
+	for i := range logs {
+		rules, ok := rulesByTenant[logs[i].TenantID]
+		if !ok {
+			rules, err = s.rules(ctx, logs[i].TenantID)
+			if err != nil {
+				return err
+			}
+			rulesByTenant[logs[i].TenantID] = rules
+		}
+
+		if err := redactLog(&logs[i], rules); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rules reads a tenant's rules through the cache; cache errors fall back to the database
+func (s *RedactionService) rules(ctx context.Context, tenantID string) ([]domain.RedactionRule, error) {
+	rules, err := s.cache.Get(ctx, tenantID)
+	if err == nil && rules != nil {
+		return rules, nil
+	}
+
+	rules, err = s.repo.RedactionRule().ListByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load redaction rules: %w", err)
+	}
+	_ = s.cache.Set(ctx, tenantID, rules)
+	return rules, nil
+}
+
+func redactLog(log *domain.AuditLog, rules []domain.RedactionRule) error {
+	fields := map[domain.RedactionTarget]*json.RawMessage{
+		domain.RedactionTargetBeforeState: &log.BeforeState,
+		domain.RedactionTargetAfterState:  &log.AfterState,
+		domain.RedactionTargetMetadata:    &log.Metadata,
+	}
+
+	for target, field := range fields {
+		redacted, err := redactDocument(*field, target, rules)
+		if err != nil {
+			return fmt.Errorf("failed to redact %s: %w", target, err)
+		}
+		*field = redacted
+	}
+	return nil
+}
+
+// redactDocument applies the rules for target to a JSON document. Documents
+// without matching rules are returned unchanged.
+func redactDocument(raw json.RawMessage, target domain.RedactionTarget, rules []domain.RedactionRule) (json.RawMessage, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return raw, nil
+	}
+
+	var document any
+	decoded := false
+	for _, rule := range rules {
+		if rule.Target != target {
+			continue
+		}
+		if !decoded {
+			decoder := json.NewDecoder(bytes.NewReader(raw))
+			decoder.UseNumber()
+			if err := decoder.Decode(&document); err != nil {
+				return nil, err
+			}
+			decoded = true
+		}
+
+		path, err := redactionPath(rule.Path)
+		if err != nil {
+			return nil, err
+		}
+		document = redactPath(document, path, rule.Mask)
+	}
+
+	if !decoded {
+		return raw, nil
+	}
+	return json.Marshal(document)
+}
+
+// redactionPath splits a dotted path into its segments
+func redactionPath(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	segments := strings.Split(path, ".")
+	for _, segment := range segments {
+		if segment == "" {
+			return nil, ErrInvalidRedactionPath
+		}
+	}
+	return segments, nil
+}
+
+// redactPath masks the values at path within node. Arrays are traversed
+// transparently unless the segment is "*", which matches every element.
+func redactPath(node any, path []string, mask domain.RedactionMask) any {
+	if len(path) == 0 {
+		return maskValue(node, mask)
+	}
+
+	switch v := node.(type) {
+	case map[string]any:
+		if path[0] == "*" {
+			for key, child := range v {
+				v[key] = redactPath(child, path[1:], mask)
+			}
+		} else if child, ok := v[path[0]]; ok {
+			v[path[0]] = redactPath(child, path[1:], mask)
+		}
+	case []any:
+		rest := path
+		if path[0] == "*" {
+			rest = path[1:]
+		}
+		for i, child := range v {
+			v[i] = redactPath(child, rest, mask)
+		}
+	}
+	return node
+}
+
+// maskValue replaces node entirely for RedactionMaskFull; other masks rewrite
+// the matching substrings of every string within node
+func maskValue(node any, mask domain.RedactionMask) any {
+	if mask == domain.RedactionMaskFull {
+		return redactedValue
+	}
+
+	switch v := node.(type) {
+	case string:
+		return maskString(v, mask)
+	case map[string]any:
+		for key, child := range v {
+			v[key] = maskValue(child, mask)
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = maskValue(child, mask)
+		}
+	}
+	return node
+}
+
+func maskString(value string, mask domain.RedactionMask) string {
+	switch mask {
+	case domain.RedactionMaskEmail:
+		return emailPattern.ReplaceAllString(value, "***@$1")
+	case domain.RedactionMaskSSN:
+		return ssnPattern.ReplaceAllString(value, "***-**-$1")
+	case domain.RedactionMaskCardNumber:
+		return cardPattern.ReplaceAllStringFunc(value, maskCardNumber)
+	}
+	return value
+}
+
+// maskCardNumber keeps the last four digits of digit runs that pass the Luhn
+// check, leaving other long numbers such as IDs and timestamps untouched
+func maskCardNumber(match string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, match)
+	if !luhnValid(digits) {
+		return match
+	}
+	return strings.Repeat("*", len(digits)-4) + digits[len(digits)-4:]
+}
+
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type RedactionServiceTestSuite struct {
+	suite.Suite
+	mockRepo      *mocks.Repository
+	mockRedaction *mocks.RedactionRuleRepository
+	mockCache     *mocks.RedactionRuleCache
+	service       *RedactionService
+}
+
+func (s *RedactionServiceTestSuite) SetupTest() {
+	s.mockRepo = new(mocks.Repository)
+	s.mockRedaction = new(mocks.RedactionRuleRepository)
+	s.mockCache = new(mocks.RedactionRuleCache)
+
+	s.mockRepo.On("RedactionRule").Return(s.mockRedaction)
+
+	s.service = NewRedactionService(s.mockRepo, s.mockCache)
+}
+
+func TestRedactionService(t *testing.T) {
+	suite.Run(t, new(RedactionServiceTestSuite))
+}
+
+func (s *RedactionServiceTestSuite) TestRedact_AppliesTenantRules() {
+	// Arrange
+	ctx := context.Background()
+	s.mockCache.On("Get", mock.Anything, "tenant1").Return([]domain.RedactionRule{
+		{Target: domain.RedactionTargetAfterState, Path: "customer.email", Mask: domain.RedactionMaskEmail},
+		{Target: domain.RedactionTargetAfterState, Path: "customer.ssn", Mask: domain.RedactionMaskSSN},
+		{Target: domain.RedactionTargetAfterState, Path: "payments.*.card", Mask: domain.RedactionMaskCardNumber},
+		{Target: domain.RedactionTargetMetadata, Path: "password", Mask: domain.RedactionMaskFull},
+	}, nil)
+
+	logs := []domain.AuditLog{{
+		TenantID:    "tenant1",
+		BeforeState: json.RawMessage(`{"email":"old@example.com"}`),
+		AfterState: json.RawMessage(`{"customer":{"email":"jane.doe@example.com","ssn":"123-45-6789","id":1234567890123},` +
+			`"payments":[{"card":"4111 1111 1111 1111"},{"card":"order 1234567890123"}]}`),
+		Metadata: json.RawMessage(`{"password":{"hash":"secret"},"team":"security"}`),
+	}}
+
+	// Act
+	err := s.service.Redact(ctx, logs)
+
+	// Assert
+	s.NoError(err)
+	s.JSONEq(`{"email":"old@example.com"}`, string(logs[0].BeforeState))
+	s.JSONEq(`{"customer":{"email":"***@example.com","ssn":"***-**-6789","id":1234567890123},`+
+		`"payments":[{"card":"************1111"},{"card":"order 1234567890123"}]}`, string(logs[0].AfterState))
+	s.JSONEq(`{"password":"[REDACTED]","team":"security"}`, string(logs[0].Metadata))
+}
+
+func (s *RedactionServiceTestSuite) TestRedact_EmptyPathCoversWholeField() {
+	// Arrange
+	ctx := context.Background()
+	s.mockCache.On("Get", mock.Anything, "tenant1").Return([]domain.RedactionRule{
+		{Target: domain.RedactionTargetMetadata, Mask: domain.RedactionMaskEmail},
+	}, nil)
+
+	logs := []domain.AuditLog{{
+		TenantID: "tenant1",
+		Metadata: json.RawMessage(`{"contacts":["a@example.com","b@example.org"],"note":"mail c@example.net"}`),
+	}}
+
+	// Act
+	err := s.service.Redact(ctx, logs)
+
+	// Assert
+	s.NoError(err)
+	s.JSONEq(`{"contacts":["***@example.com","***@example.org"],"note":"mail ***@example.net"}`, string(logs[0].Metadata))
+}
+
+func (s *RedactionServiceTestSuite) TestRedact_LoadsRulesOncePerTenant() {
+	// Arrange
+	ctx := context.Background()
+	s.mockCache.On("Get", mock.Anything, "tenant1").Return(nil, nil).Once()
+	s.mockRedaction.On("ListByTenant", mock.Anything, "tenant1").Return([]domain.RedactionRule{}, nil).Once()
+	s.mockCache.On("Set", mock.Anything, "tenant1", []domain.RedactionRule{}).Return(nil).Once()
+
+	logs := []domain.AuditLog{
+		{TenantID: "tenant1", Metadata: json.RawMessage(`{"email":"jane@example.com"}`)},
+		{TenantID: "tenant1"},
+	}
+
+	// Act
+	err := s.service.Redact(ctx, logs)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(`{"email":"jane@example.com"}`, string(logs[0].Metadata))
+	s.mockCache.AssertExpectations(s.T())
+	s.mockRedaction.AssertExpectations(s.T())
+}
+
+func (s *RedactionServiceTestSuite) TestRedact_RulesUnavailable() {
+	// Arrange
+	ctx := context.Background()
+	s.mockCache.On("Get", mock.Anything, "tenant1").Return(nil, errors.New("redis down"))
+	s.mockRedaction.On("ListByTenant", mock.Anything, "tenant1").Return(nil, errors.New("db down"))
+
+	// Act
+	err := s.service.Redact(ctx, []domain.AuditLog{{TenantID: "tenant1"}})
+
+	// Assert
+	s.Error(err)
+}
+
+func (s *RedactionServiceTestSuite) TestCreate_Success() {
+	// Arrange
+	ctx := context.Background()
+	req := dto.RedactionRuleRequest{Target: "metadata", Path: "user.email", Mask: "email"}
+
+	s.mockRedaction.On("ListByTenant", mock.Anything, "tenant1").Return([]domain.RedactionRule{}, nil)
+	s.mockRedaction.On("Create", mock.Anything, mock.MatchedBy(func(r *domain.RedactionRule) bool {
+		return r.TenantID == "tenant1" && r.Path == "user.email" && r.Mask == domain.RedactionMaskEmail
+	})).Return(nil)
+	s.mockCache.On("Invalidate", mock.Anything, "tenant1").Return(nil)
+
+	// Act
+	resp, err := s.service.Create(ctx, "tenant1", req)
+
+	// Assert
+	s.NoError(err)
+	s.Equal("metadata", resp.Target)
+	s.mockRedaction.AssertExpectations(s.T())
+	s.mockCache.AssertExpectations(s.T())
+}
+
+func (s *RedactionServiceTestSuite) TestCreate_InvalidPath() {
+	// Arrange
+	ctx := context.Background()
+	req := dto.RedactionRuleRequest{Target: "metadata", Path: "user..email", Mask: "email"}
+
+	// Act
+	resp, err := s.service.Create(ctx, "tenant1", req)
+
+	// Assert
+	s.ErrorIs(err, ErrInvalidRedactionPath)
+	s.Nil(resp)
+	s.mockRedaction.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (s *RedactionServiceTestSuite) TestCreate_Duplicate() {
+	// Arrange
+	ctx := context.Background()
+	req := dto.RedactionRuleRequest{Target: "metadata", Path: "user.email", Mask: "email"}
+
+	s.mockRedaction.On("ListByTenant", mock.Anything, "tenant1").Return([]domain.RedactionRule{
+		{ID: "rule1", Target: domain.RedactionTargetMetadata, Path: "user.email", Mask: domain.RedactionMaskEmail},
+	}, nil)
+
+	// Act
+	resp, err := s.service.Create(ctx, "tenant1", req)
+
+	// Assert
+	s.ErrorIs(err, ErrRedactionRuleExists)
+	s.Nil(resp)
+}
+
+func (s *RedactionServiceTestSuite) TestDelete_NotFound() {
+	// Arrange
+	ctx := context.Background()
+	s.mockRedaction.On("Delete", mock.Anything, "tenant1", "missing").Return(gorm.ErrRecordNotFound)
+
+	// Act
+	err := s.service.Delete(ctx, "tenant1", "missing")
+
+	// Assert
+	s.ErrorIs(err, ErrRedactionRuleNotFound)
+	s.mockCache.AssertNotCalled(s.T(), "Invalidate", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/repository/opensearch"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+)
+
+// reindexBatchSize is the number of logs a reindex job reads and bulk indexes
+// at a time, checkpointing after each batch
+const reindexBatchSize = 1000
+
+// CreateReindexJob records a backfill of the tenant's logs from start to end
+// into OpenSearch, counting the logs to index so RunReindexJob can report its
+// progress. The range isn't bounded: the job runs outside of a request and
+// checkpoints as it goes.
+func (s *SearchIndexService) CreateReindexJob(ctx context.Context, tenantID string, start, end time.Time) (_ *domain.ReindexJob, err error) {
+	ctx, span := tracing.Start(ctx, "SearchIndexService.CreateReindexJob", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	if !end.After(start) {
+		return nil, ErrInvalidBackfillRange
+	}
+
+	stats, err := s.repo.AuditLog().GetStats(ctx, domain.AuditLogFilter{TenantID: tenantID, StartTime: start, EndTime: end})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count logs: %w", err)
+	}
+
+	job := &domain.ReindexJob{
+		TenantID:   tenantID,
+		Status:     domain.JobPending,
+		StartTime:  start,
+		EndTime:    end,
+		TotalCount: stats.TotalLogs,
+	}
+	if err := s.repo.ReindexJob().Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create reindex job: %w", err)
+	}
+	span.SetAttributes(attribute.String("job.id", job.ID))
+	return job, nil
+}
+
+// GetReindexJob returns one of the tenant's reindex jobs, to resume it
+func (s *SearchIndexService) GetReindexJob(ctx context.Context, tenantID, id string) (_ *domain.ReindexJob, err error) {
+	ctx, span := tracing.Start(ctx, "SearchIndexService.GetReindexJob", trace.WithAttributes(tracing.TenantAttr(tenantID), attribute.String("job.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	job, err := s.repo.ReindexJob().GetByID(ctx, tenantID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reindex job: %w", err)
+	}
+	return job, nil
+}
+
+// RunReindexJob bulk indexes the job's logs from PostgreSQL into OpenSearch,
+// starting after the last log it indexed. Each batch is checkpointed on the
+// job before progress is called with it, so a job that fails or is cancelled
+// through ctx resumes from its last batch when run again. Logs OpenSearch
+// rejects are stored as index failures to be reprocessed, like those of the
+// index worker, and counted as failed.
+func (s *SearchIndexService) RunReindexJob(ctx context.Context, job *domain.ReindexJob, progress func(*domain.ReindexJob)) (err error) {
+	ctx, span := tracing.Start(ctx, "SearchIndexService.RunReindexJob", trace.WithAttributes(tracing.TenantAttr(job.TenantID), attribute.String("job.id", job.ID)))
+	defer func() { tracing.End(span, err) }()
+
+	if job.Status == domain.JobCompleted {
+		return ErrReindexJobCompleted
+	}
+
+	job.Status = domain.JobRunning
+	job.Error = ""
+	if err := s.saveReindexJob(ctx, job); err != nil {
+		return err
+	}
+
+	if err := s.reindex(ctx, job, progress); err != nil {
+		job.Status = domain.JobFailed
+		job.Error = err.Error()
+		// Record the failure even when ctx was cancelled, so the job can be resumed
+		if saveErr := s.saveReindexJob(context.WithoutCancel(ctx), job); saveErr != nil {
+			return errors.Join(err, saveErr)
+		}
+		return err
+	}
+
+	completedAt := time.Now()
+	job.Status = domain.JobCompleted
+	job.CompletedAt = &completedAt
+	span.SetAttributes(attribute.Int64("reindex.indexed", job.IndexedCount), attribute.Int64("reindex.failed", job.FailedCount))
+	return s.saveReindexJob(ctx, job)
+}
+
+// reindex indexes the job's remaining logs batch by batch
+func (s *SearchIndexService) reindex(ctx context.Context, job *domain.ReindexJob, progress func(*domain.ReindexJob)) error {
+	filter := domain.AuditLogFilter{TenantID: job.TenantID, StartTime: job.StartTime, EndTime: job.EndTime}
+	for {
+		batch, err := s.repo.AuditLog().ListBatch(ctx, filter, job.Cursor(), reindexBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to read logs: %w", err)
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		failed, err := s.bulkIndex(ctx, batch)
+		if err != nil {
+			return err
+		}
+
+		last := batch[len(batch)-1]
+		job.CursorTimestamp = &last.Timestamp
+		job.CursorID = last.ID
+		job.IndexedCount += int64(len(batch) - failed)
+		job.FailedCount += int64(failed)
+		if err := s.saveReindexJob(ctx, job); err != nil {
+			return err
+		}
+		if progress != nil {
+			progress(job)
+		}
+
+		if len(batch) < reindexBatchSize {
+			return nil
+		}
+	}
+}
+
+// bulkIndex indexes logs, storing the ones OpenSearch rejects as index
+// failures, and returns how many were rejected
+func (s *SearchIndexService) bulkIndex(ctx context.Context, logs []domain.AuditLog) (int, error) {
+	err := s.repo.OpenSearch().BulkIndex(ctx, logs)
+	var bulkErr *opensearch.BulkIndexError
+	if !errors.As(err, &bulkErr) {
+		if err != nil {
+			return 0, fmt.Errorf("failed to index logs: %w", err)
+		}
+		return 0, nil
+	}
+
+	failures := make([]domain.IndexFailure, len(bulkErr.Failures))
+	for i, f := range bulkErr.Failures {
+		failures[i] = domain.IndexFailure{
+			TenantID:  f.Log.TenantID,
+			LogID:     f.Log.ID,
+			IndexName: f.Index,
+			Status:    f.Status,
+			ErrorType: f.Type,
+			Reason:    f.Reason,
+			Document:  f.Log,
+		}
+	}
+	if err := s.repo.IndexFailure().Save(ctx, failures); err != nil {
+		return 0, fmt.Errorf("failed to save index failures: %w", err)
+	}
+	return len(failures), nil
+}
+
+func (s *SearchIndexService) saveReindexJob(ctx context.Context, job *domain.ReindexJob) error {
+	if err := s.repo.ReindexJob().Update(ctx, job); err != nil {
+		return fmt.Errorf("failed to update reindex job: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package reputation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+)
+
+// ScoreCache keeps the scores providers looked up, so an address isn't
+// looked up again with every log
+type ScoreCache interface {
+	// Get returns the cached score of ip, found false if none is cached
+	Get(ctx context.Context, provider string, ip netip.Addr) (score int, found bool, err error)
+	Set(ctx context.Context, provider string, ip netip.Addr, score int) error
+}
+
+// AbuseIPDB scores addresses with their AbuseIPDB abuse confidence score,
+// from 0 to 100. Scores are cached, clean addresses included, to stay within
+// the API's daily check limit.
+type AbuseIPDB struct {
+	url        string
+	apiKey     string
+	maxAgeDays int
+	client     *http.Client
+	cache      ScoreCache
+}
+
+func NewAbuseIPDB(cfg *config.ThreatIntelConfig, cache ScoreCache) *AbuseIPDB {
+	return &AbuseIPDB{
+		url:        cfg.AbuseIPDBURL,
+		apiKey:     cfg.AbuseIPDBAPIKey,
+		maxAgeDays: cfg.AbuseIPDBMaxAgeDays,
+		client:     &http.Client{Timeout: cfg.Timeout},
+		cache:      cache,
+	}
+}
+
+func (a *AbuseIPDB) Name() string {
+	return config.ThreatIntelProviderAbuseIPDB
+}
+
+// Score returns the cached score of ip, or checks it with the API. Cache
+// errors fall back to the API.
+func (a *AbuseIPDB) Score(ctx context.Context, ip netip.Addr) (int, error) {
+	if score, found, err := a.cache.Get(ctx, a.Name(), ip); err == nil && found {
+		return score, nil
+	}
+
+	query := url.Values{}
+	query.Set("ipAddress", ip.String())
+	query.Set("maxAgeInDays", strconv.Itoa(a.maxAgeDays))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.url+"?"+query.Encode(), nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create AbuseIPDB request: %w", err)
+	}
+	req.Header.Set("Key", a.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check address with AbuseIPDB: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("AbuseIPDB responded with status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Data struct {
+			AbuseConfidenceScore int `json:"abuseConfidenceScore"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, fmt.Errorf("failed to decode AbuseIPDB response: %w", err)
+	}
+
+	score := body.Data.AbuseConfidenceScore
+	_ = a.cache.Set(ctx, a.Name(), ip, score)
+	return score, nil
+}
@@ -0,0 +1,194 @@
+package reputation
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+// maxBlocklistSize caps the download of a blocklist
+const maxBlocklistSize = 64 << 20
+
+// blocklistEntries are the addresses and ranges of one blocklist
+type blocklistEntries struct {
+	addrs    map[netip.Addr]struct{}
+	prefixes []netip.Prefix
+}
+
+func (e *blocklistEntries) contains(ip netip.Addr) bool {
+	if _, ok := e.addrs[ip]; ok {
+		return true
+	}
+	for _, prefix := range e.prefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Blocklist scores the addresses on blocklists, such as FireHOL, Spamhaus
+// DROP or OTX exports, with the same score. Lists are downloaded again every
+// refresh interval in the background; a list that fails to download keeps
+// its previous entries.
+type Blocklist struct {
+	sources []string
+	score   int
+	client  *http.Client
+	logger  *logger.Logger
+
+	mu    sync.RWMutex
+	lists map[string]*blocklistEntries
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+func NewBlocklist(cfg *config.ThreatIntelConfig, logger *logger.Logger) *Blocklist {
+	return &Blocklist{
+		sources: cfg.BlocklistURLs,
+		score:   cfg.BlocklistScore,
+		client:  &http.Client{Timeout: cfg.Timeout},
+		logger:  logger,
+		lists:   make(map[string]*blocklistEntries),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+func (b *Blocklist) Name() string {
+	return config.ThreatIntelProviderBlocklist
+}
+
+// Score returns the blocklist score if ip is on any list, 0 otherwise
+func (b *Blocklist) Score(_ context.Context, ip netip.Addr) (int, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, list := range b.lists {
+		if list.contains(ip) {
+			return b.score, nil
+		}
+	}
+	return 0, nil
+}
+
+// Start loads the lists once, then again every interval until Stop
+func (b *Blocklist) Start(interval time.Duration) {
+	b.Refresh(context.Background())
+
+	go func() {
+		defer close(b.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-b.stop:
+				return
+			case <-ticker.C:
+				b.Refresh(context.Background())
+			}
+		}
+	}()
+}
+
+func (b *Blocklist) Stop() {
+	b.once.Do(func() {
+		close(b.stop)
+		<-b.done
+	})
+}
+
+// Refresh downloads every list, logging the lists that fail
+func (b *Blocklist) Refresh(ctx context.Context) {
+	for _, source := range b.sources {
+		entries, err := b.load(ctx, source)
+		count := 0
+		if entries != nil {
+			count = len(entries.addrs) + len(entries.prefixes)
+		}
+		metrics.ObserveBlocklistRefresh(source, count, err)
+		if err != nil {
+			b.logger.Error("Failed to refresh blocklist", err, zap.String("source", source))
+			continue
+		}
+
+		b.mu.Lock()
+		b.lists[source] = entries
+		b.mu.Unlock()
+	}
+}
+
+// load reads a list from an http(s) URL or a file path
+func (b *Blocklist) load(ctx context.Context, source string) (*blocklistEntries, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		file, err := os.Open(strings.TrimPrefix(source, "file://"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open blocklist: %w", err)
+		}
+		defer file.Close()
+		return parseBlocklist(io.LimitReader(file, maxBlocklistSize))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create blocklist request: %w", err)
+	}
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download blocklist: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("blocklist download responded with status %d", resp.StatusCode)
+	}
+	return parseBlocklist(io.LimitReader(resp.Body, maxBlocklistSize))
+}
+
+// parseBlocklist reads an IP address or CIDR range at the start of each line.
+// Blank lines, comments starting with "#" or ";" and lines that don't start
+// with an address are skipped, so lists annotating their entries, such as
+// "192.0.2.0/24 ; SBL123", are read as well.
+func parseBlocklist(r io.Reader) (*blocklistEntries, error) {
+	entries := &blocklistEntries{addrs: make(map[netip.Addr]struct{})}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.FieldsFunc(scanner.Text(), func(r rune) bool {
+			return r == ' ' || r == '\t' || r == ';' || r == ','
+		})
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		if prefix, err := netip.ParsePrefix(fields[0]); err == nil {
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()).Masked()
+			if prefix.IsSingleIP() {
+				entries.addrs[prefix.Addr()] = struct{}{}
+			} else {
+				entries.prefixes = append(entries.prefixes, prefix)
+			}
+		} else if addr, err := netip.ParseAddr(fields[0]); err == nil {
+			entries.addrs[addr.Unmap()] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read blocklist: %w", err)
+	}
+	return entries, nil
+}
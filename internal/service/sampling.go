@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+)
+
+// SampledLogCounter counts the logs dropped by the tenants' sampling rules per
+// UTC day, so stats can be extrapolated to the logs sent
+//
+//go:generate mockery --name SampledLogCounter --output ../mocks
+type SampledLogCounter interface {
+	Add(ctx context.Context, tenantID string, day time.Time, logs []domain.AuditLog) error
+	Sum(ctx context.Context, tenantID string, from, to time.Time) (*domain.SampledLogCounts, error)
+}
+
+// UseSampling makes ingestion keep only the share of logs given by the
+// sampling rules in the tenants' settings, counting the logs dropped
+func (s *AuditLogService) UseSampling(settings TenantSettingsResolver, counter SampledLogCounter) {
+	s.samplingSettings = settings
+	s.sampled = counter
+}
+
+// sample drops logs per their tenants' sampling rules and returns the logs
+// kept. It runs after validation, so whether a request is accepted doesn't
+// depend on chance. Logs of tenants whose settings can't be read are kept, as
+// are meta-audit logs, and dropped logs are counted on a best-effort basis.
+func (s *AuditLogService) sample(ctx context.Context, logs []domain.AuditLog) []domain.AuditLog {
+	if s.samplingSettings == nil {
+		return logs
+	}
+
+	settings := make(map[string]*domain.TenantSettings)
+	dropped := make(map[string][]domain.AuditLog)
+	kept := logs[:0:0]
+	for i := range logs {
+		tenantID := logs[i].TenantID
+		tenant, ok := settings[tenantID]
+		if !ok {
+			if tenantID != domain.SystemTenantID {
+				tenant, _ = s.samplingSettings.ResolveSettings(ctx, tenantID)
+			}
+			settings[tenantID] = tenant
+		}
+		if tenant != nil && s.random() >= tenant.SampleRate(logs[i].Action, logs[i].Severity) {
+			dropped[tenantID] = append(dropped[tenantID], logs[i])
+			continue
+		}
+		kept = append(kept, logs[i])
+	}
+
+	for tenantID, logs := range dropped {
+		metrics.LogsSampledOutTotal.WithLabelValues(tenantID).Add(float64(len(logs)))
+		_ = s.sampled.Add(ctx, tenantID, s.now(), logs)
+	}
+	return kept
+}
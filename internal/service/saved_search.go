@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+)
+
+type SavedSearchService struct {
+	repo repository.Repository
+}
+
+func NewSavedSearchService(repo repository.Repository) *SavedSearchService {
+	return &SavedSearchService{repo: repo}
+}
+
+func (s *SavedSearchService) Create(ctx context.Context, tenantID, ownerID string, req dto.SavedSearchRequest) (_ *dto.SavedSearchResponse, err error) {
+	ctx, span := tracing.Start(ctx, "SavedSearchService.Create", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	search := &domain.SavedSearch{
+		TenantID:    tenantID,
+		OwnerID:     ownerID,
+		Name:        req.Name,
+		Description: req.Description,
+		Filter:      req.Filter.ToSavedSearchFilter(),
+		Shared:      req.Shared,
+	}
+	if err := validateSavedSearchFilter(&search.Filter); err != nil {
+		return nil, err
+	}
+	if err := s.checkDuplicateName(ctx, search); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SavedSearch().Create(ctx, search); err != nil {
+		return nil, fmt.Errorf("failed to create saved search: %w", err)
+	}
+
+	return dto.FromSavedSearch(search), nil
+}
+
+// List returns the caller's saved searches and those shared within the tenant
+func (s *SavedSearchService) List(ctx context.Context, tenantID, userID string) (_ []dto.SavedSearchResponse, err error) {
+	ctx, span := tracing.Start(ctx, "SavedSearchService.List", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	searches, err := s.repo.SavedSearch().ListVisible(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromSavedSearches(searches), nil
+}
+
+// GetFilter returns the filter of a saved search the caller owns or that is shared
+func (s *SavedSearchService) GetFilter(ctx context.Context, tenantID, userID, id string) (_ *domain.SavedSearchFilter, err error) {
+	ctx, span := tracing.Start(ctx, "SavedSearchService.GetFilter", trace.WithAttributes(tracing.TenantAttr(tenantID), attribute.String("saved_search.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	search, err := s.getVisible(ctx, tenantID, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return &search.Filter, nil
+}
+
+func (s *SavedSearchService) Update(ctx context.Context, tenantID, userID, id string, req dto.SavedSearchRequest) (_ *dto.SavedSearchResponse, err error) {
+	ctx, span := tracing.Start(ctx, "SavedSearchService.Update", trace.WithAttributes(tracing.TenantAttr(tenantID), attribute.String("saved_search.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	search, err := s.getOwned(ctx, tenantID, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	search.Name = req.Name
+	search.Description = req.Description
+	search.Filter = req.Filter.ToSavedSearchFilter()
+	search.Shared = req.Shared
+	search.UpdatedAt = time.Now()
+	if err := validateSavedSearchFilter(&search.Filter); err != nil {
+		return nil, err
+	}
+	if err := s.checkDuplicateName(ctx, search); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SavedSearch().Update(ctx, search); err != nil {
+		return nil, fmt.Errorf("failed to update saved search: %w", err)
+	}
+
+	return dto.FromSavedSearch(search), nil
+}
+
+func (s *SavedSearchService) Delete(ctx context.Context, tenantID, userID, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "SavedSearchService.Delete", trace.WithAttributes(tracing.TenantAttr(tenantID), attribute.String("saved_search.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	if _, err := s.getOwned(ctx, tenantID, userID, id); err != nil {
+		return err
+	}
+
+	err = s.repo.SavedSearch().Delete(ctx, tenantID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrSavedSearchNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete saved search: %w", err)
+	}
+	return nil
+}
+
+// getVisible loads a saved search, hiding other users' unshared searches as not found
+func (s *SavedSearchService) getVisible(ctx context.Context, tenantID, userID, id string) (*domain.SavedSearch, error) {
+	search, err := s.repo.SavedSearch().GetByID(ctx, tenantID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSavedSearchNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved search: %w", err)
+	}
+	if search.OwnerID != userID && !search.Shared {
+		return nil, ErrSavedSearchNotFound
+	}
+	return search, nil
+}
+
+func (s *SavedSearchService) getOwned(ctx context.Context, tenantID, userID, id string) (*domain.SavedSearch, error) {
+	search, err := s.getVisible(ctx, tenantID, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if search.OwnerID != userID {
+		return nil, ErrSavedSearchNotOwner
+	}
+	return search, nil
+}
+
+// checkDuplicateName returns ErrSavedSearchExists if the owner has another search with the same name
+func (s *SavedSearchService) checkDuplicateName(ctx context.Context, search *domain.SavedSearch) error {
+	existing, err := s.repo.SavedSearch().ListVisible(ctx, search.TenantID, search.OwnerID)
+	if err != nil {
+		return fmt.Errorf("failed to list saved searches: %w", err)
+	}
+	for _, e := range existing {
+		if e.ID != search.ID && e.OwnerID == search.OwnerID && e.Name == search.Name {
+			return ErrSavedSearchExists
+		}
+	}
+	return nil
+}
+
+func validateSavedSearchFilter(filter *domain.SavedSearchFilter) error {
+	if filter.Lookback == "" {
+		if filter.StartTime != nil && filter.EndTime != nil && filter.StartTime.After(*filter.EndTime) {
+			return ErrInvalidSavedSearch
+		}
+		return nil
+	}
+	if filter.StartTime != nil || filter.EndTime != nil {
+		return ErrInvalidSavedSearch
+	}
+	lookback, err := time.ParseDuration(filter.Lookback)
+	if err != nil || lookback <= 0 {
+		return ErrInvalidSavedSearch
+	}
+	return nil
+}
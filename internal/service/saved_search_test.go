@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type SavedSearchServiceTestSuite struct {
+	suite.Suite
+	mockRepo     *mocks.Repository
+	mockSearches *mocks.SavedSearchRepository
+	service      *SavedSearchService
+}
+
+func (s *SavedSearchServiceTestSuite) SetupTest() {
+	s.mockRepo = new(mocks.Repository)
+	s.mockSearches = new(mocks.SavedSearchRepository)
+
+	s.mockRepo.On("SavedSearch").Return(s.mockSearches)
+
+	s.service = NewSavedSearchService(s.mockRepo)
+}
+
+func TestSavedSearchService(t *testing.T) {
+	suite.Run(t, new(SavedSearchServiceTestSuite))
+}
+
+func (s *SavedSearchServiceTestSuite) TestCreate_Success() {
+	// Arrange
+	ctx := context.Background()
+	req := dto.SavedSearchRequest{Name: "failed logins", Filter: dto.SavedSearchFilter{Action: "login", Lookback: "24h"}, Shared: true}
+	s.mockSearches.On("ListVisible", mock.Anything, "tenant1", "user1").Return([]domain.SavedSearch{
+		{ID: "search2", OwnerID: "user2", Name: "failed logins", Shared: true},
+	}, nil)
+	s.mockSearches.On("Create", mock.Anything, mock.MatchedBy(func(search *domain.SavedSearch) bool {
+		return search.OwnerID == "user1" && search.Filter.Action == "login" && search.Shared
+	})).Return(nil)
+
+	// Act
+	search, err := s.service.Create(ctx, "tenant1", "user1", req)
+
+	// Assert
+	s.NoError(err)
+	s.Equal("failed logins", search.Name)
+	s.Equal("24h", search.Filter.Lookback)
+	s.mockSearches.AssertExpectations(s.T())
+}
+
+func (s *SavedSearchServiceTestSuite) TestCreate_DuplicateName() {
+	// Arrange
+	ctx := context.Background()
+	req := dto.SavedSearchRequest{Name: "failed logins", Filter: dto.SavedSearchFilter{Lookback: "1h"}}
+	s.mockSearches.On("ListVisible", mock.Anything, "tenant1", "user1").Return([]domain.SavedSearch{
+		{ID: "search1", OwnerID: "user1", Name: "failed logins"},
+	}, nil)
+
+	// Act
+	search, err := s.service.Create(ctx, "tenant1", "user1", req)
+
+	// Assert
+	s.ErrorIs(err, ErrSavedSearchExists)
+	s.Nil(search)
+	s.mockSearches.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (s *SavedSearchServiceTestSuite) TestCreate_InvalidLookback() {
+	// Arrange
+	ctx := context.Background()
+	req := dto.SavedSearchRequest{Name: "bad", Filter: dto.SavedSearchFilter{Lookback: "yesterday"}}
+
+	// Act
+	search, err := s.service.Create(ctx, "tenant1", "user1", req)
+
+	// Assert
+	s.ErrorIs(err, ErrInvalidSavedSearch)
+	s.Nil(search)
+}
+
+func (s *SavedSearchServiceTestSuite) TestGetFilter_HidesUnsharedSearchOfOtherUser() {
+	// Arrange
+	ctx := context.Background()
+	s.mockSearches.On("GetByID", mock.Anything, "tenant1", "search1").
+		Return(&domain.SavedSearch{ID: "search1", OwnerID: "user2", Shared: false}, nil)
+
+	// Act
+	filter, err := s.service.GetFilter(ctx, "tenant1", "user1", "search1")
+
+	// Assert
+	s.ErrorIs(err, ErrSavedSearchNotFound)
+	s.Nil(filter)
+}
+
+func (s *SavedSearchServiceTestSuite) TestGetFilter_NotFound() {
+	// Arrange
+	ctx := context.Background()
+	s.mockSearches.On("GetByID", mock.Anything, "tenant1", "missing").Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	filter, err := s.service.GetFilter(ctx, "tenant1", "user1", "missing")
+
+	// Assert
+	s.ErrorIs(err, ErrSavedSearchNotFound)
+	s.Nil(filter)
+}
+
+func (s *SavedSearchServiceTestSuite) TestUpdate_SharedSearchOfOtherUser() {
+	// Arrange
+	ctx := context.Background()
+	req := dto.SavedSearchRequest{Name: "renamed", Filter: dto.SavedSearchFilter{Lookback: "1h"}}
+	s.mockSearches.On("GetByID", mock.Anything, "tenant1", "search1").
+		Return(&domain.SavedSearch{ID: "search1", OwnerID: "user2", Shared: true}, nil)
+
+	// Act
+	search, err := s.service.Update(ctx, "tenant1", "user1", "search1", req)
+
+	// Assert
+	s.ErrorIs(err, ErrSavedSearchNotOwner)
+	s.Nil(search)
+	s.mockSearches.AssertNotCalled(s.T(), "Update", mock.Anything, mock.Anything)
+}
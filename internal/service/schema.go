@@ -0,0 +1,404 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+)
+
+//go:generate mockery --name ResourceSchemaCache --output ../mocks
+type ResourceSchemaCache interface {
+	Get(ctx context.Context, tenantID string) ([]domain.ResourceSchema, error)
+	Set(ctx context.Context, tenantID string, schemas []domain.ResourceSchema) error
+	Invalidate(ctx context.Context, tenantID string) error
+}
+
+// SchemaViolation is a value of a log's JSON field that doesn't conform to
+// the schema of its resource type. Field is the dotted path of the value,
+// e.g. metadata.region.
+type SchemaViolation struct {
+	Log     int
+	Field   string
+	Message string
+}
+
+// SchemaViolationError rejects logs that don't conform to the reject-mode
+// schema of their resource type. Batch is set for bulk ingests, whose
+// violations name the index of their log.
+type SchemaViolationError struct {
+	Batch      bool
+	Violations []SchemaViolation
+}
+
+func (e *SchemaViolationError) Error() string {
+	return fmt.Sprintf("log does not conform to the schema of its resource type: %d violations", len(e.Violations))
+}
+
+// compiledSchemas are the compiled schemas of a tenant, keyed by resource
+// type. version identifies the schema rows they were compiled from.
+type compiledSchemas struct {
+	version string
+	byType  map[string]*compiledSchema
+}
+
+type compiledSchema struct {
+	mode     domain.SchemaMode
+	metadata *jsonschema.Schema
+	state    *jsonschema.Schema
+}
+
+type SchemaService struct {
+	repo  repository.Repository
+	cache ResourceSchemaCache
+
+	mu       sync.Mutex
+	compiled map[string]*compiledSchemas
+}
+
+func NewSchemaService(repo repository.Repository, cache ResourceSchemaCache) *SchemaService {
+	return &SchemaService{
+		repo:     repo,
+		cache:    cache,
+		compiled: make(map[string]*compiledSchemas),
+	}
+}
+
+func (s *SchemaService) Create(ctx context.Context, tenantID string, req dto.ResourceSchemaRequest) (_ *dto.ResourceSchemaResponse, err error) {
+	ctx, span := tracing.Start(ctx, "SchemaService.Create", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	schema := &domain.ResourceSchema{TenantID: tenantID}
+	if err := applySchemaRequest(schema, req); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.ResourceSchema().ListByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resource schemas: %w", err)
+	}
+	for _, r := range existing {
+		if r.ResourceType == schema.ResourceType {
+			return nil, ErrResourceSchemaExists
+		}
+	}
+
+	if err := s.repo.ResourceSchema().Create(ctx, schema); err != nil {
+		return nil, fmt.Errorf("failed to create resource schema: %w", err)
+	}
+	if err := s.cache.Invalidate(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	return dto.FromResourceSchema(schema), nil
+}
+
+func (s *SchemaService) List(ctx context.Context, tenantID string) (_ []dto.ResourceSchemaResponse, err error) {
+	ctx, span := tracing.Start(ctx, "SchemaService.List", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	schemas, err := s.repo.ResourceSchema().ListByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromResourceSchemas(schemas), nil
+}
+
+func (s *SchemaService) Get(ctx context.Context, tenantID, id string) (_ *dto.ResourceSchemaResponse, err error) {
+	ctx, span := tracing.Start(ctx, "SchemaService.Get", trace.WithAttributes(tracing.TenantAttr(tenantID), attribute.String("resource_schema.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	schema, err := s.getSchema(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromResourceSchema(schema), nil
+}
+
+// Update replaces a schema. Logs already stored are not checked again.
+func (s *SchemaService) Update(ctx context.Context, tenantID, id string, req dto.ResourceSchemaRequest) (_ *dto.ResourceSchemaResponse, err error) {
+	ctx, span := tracing.Start(ctx, "SchemaService.Update", trace.WithAttributes(tracing.TenantAttr(tenantID), attribute.String("resource_schema.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	schema, err := s.getSchema(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	previousType := schema.ResourceType
+	if err := applySchemaRequest(schema, req); err != nil {
+		return nil, err
+	}
+
+	if schema.ResourceType != previousType {
+		existing, err := s.repo.ResourceSchema().ListByTenant(ctx, tenantID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list resource schemas: %w", err)
+		}
+		for _, r := range existing {
+			if r.ID != schema.ID && r.ResourceType == schema.ResourceType {
+				return nil, ErrResourceSchemaExists
+			}
+		}
+	}
+
+	if err := s.repo.ResourceSchema().Update(ctx, schema); err != nil {
+		return nil, fmt.Errorf("failed to update resource schema: %w", err)
+	}
+	if err := s.cache.Invalidate(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	return dto.FromResourceSchema(schema), nil
+}
+
+func (s *SchemaService) Delete(ctx context.Context, tenantID, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "SchemaService.Delete", trace.WithAttributes(tracing.TenantAttr(tenantID), attribute.String("resource_schema.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	err = s.repo.ResourceSchema().Delete(ctx, tenantID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrResourceSchemaNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete resource schema: %w", err)
+	}
+
+	return s.cache.Invalidate(ctx, tenantID)
+}
+
+func (s *SchemaService) getSchema(ctx context.Context, tenantID, id string) (*domain.ResourceSchema, error) {
+	schema, err := s.repo.ResourceSchema().GetByID(ctx, tenantID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrResourceSchemaNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resource schema: %w", err)
+	}
+	return schema, nil
+}
+
+// applySchemaRequest sets the fields of the request on schema, failing if
+// either JSON Schema doesn't compile
+func applySchemaRequest(schema *domain.ResourceSchema, req dto.ResourceSchemaRequest) error {
+	schema.ResourceType = strings.TrimSpace(req.ResourceType)
+	schema.MetadataSchema = req.MetadataSchema
+	schema.StateSchema = req.StateSchema
+	schema.Mode = domain.SchemaMode(req.Mode)
+
+	if _, err := compileSchema(schema); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Validate checks the JSON fields of logs against the schemas of their
+// tenant and resource type. Violations of flag-mode schemas are recorded in
+// the logs' SchemaErrors, while those of reject-mode schemas fail with a
+// *SchemaViolationError. It fails if schemas can't be loaded, so logs are
+// never stored unchecked.
+func (s *SchemaService) Validate(ctx context.Context, logs []domain.AuditLog) (err error) {
+	ctx, span := tracing.Start(ctx, "SchemaService.Validate", trace.WithAttributes(attribute.Int("audit_log.count", len(logs))))
+	defer func() { tracing.End(span, err) }()
+
+	var rejected []SchemaViolation
+	schemasByTenant := make(map[string]map[string]*compiledSchema)
+	for i := range logs {
+		schemas, ok := schemasByTenant[logs[i].TenantID]
+		if !ok {
+			schemas, err = s.schemas(ctx, logs[i].TenantID)
+			if err != nil {
+				return err
+			}
+			schemasByTenant[logs[i].TenantID] = schemas
+		}
+
+		schema, ok := schemas[logs[i].ResourceType]
+		if !ok {
+			continue
+		}
+		violations, err := validateLog(&logs[i], schema)
+		if err != nil {
+			return err
+		}
+		if len(violations) == 0 {
+			continue
+		}
+
+		if schema.mode == domain.SchemaModeReject {
+			for _, violation := range violations {
+				violation.Log = i
+				rejected = append(rejected, violation)
+			}
+			continue
+		}
+		logs[i].SchemaErrors = make([]string, len(violations))
+		for j, violation := range violations {
+			logs[i].SchemaErrors[j] = violation.Field + ": " + violation.Message
+		}
+	}
+
+	if len(rejected) > 0 {
+		return &SchemaViolationError{Violations: rejected}
+	}
+	return nil
+}
+
+// schemas returns the compiled schemas of a tenant keyed by resource type,
+// reading them through the cache and compiling them again only once they
+// change. Cache errors fall back to the database.
+func (s *SchemaService) schemas(ctx context.Context, tenantID string) (map[string]*compiledSchema, error) {
+	schemas, err := s.cache.Get(ctx, tenantID)
+	if err != nil || schemas == nil {
+		schemas, err = s.repo.ResourceSchema().ListByTenant(ctx, tenantID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load resource schemas: %w", err)
+		}
+		_ = s.cache.Set(ctx, tenantID, schemas)
+	}
+
+	var version strings.Builder
+	for _, schema := range schemas {
+		fmt.Fprintf(&version, "%s@%d;", schema.ID, schema.UpdatedAt.UnixNano())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if compiled, ok := s.compiled[tenantID]; ok && compiled.version == version.String() {
+		return compiled.byType, nil
+	}
+
+	compiled := &compiledSchemas{version: version.String(), byType: make(map[string]*compiledSchema, len(schemas))}
+	for i := range schemas {
+		schema, err := compileSchema(&schemas[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile schema of resource type %s: %w", schemas[i].ResourceType, err)
+		}
+		compiled.byType[schemas[i].ResourceType] = schema
+	}
+	s.compiled[tenantID] = compiled
+	return compiled.byType, nil
+}
+
+// noRemoteLoader refuses to load $ref targets, so tenant schemas can only
+// refer to their own definitions and never to files or URLs
+type noRemoteLoader struct{}
+
+func (noRemoteLoader) Load(url string) (any, error) {
+	return nil, fmt.Errorf("loading %s is not allowed", url)
+}
+
+// compileSchema compiles the JSON Schemas of a resource schema, defaulting to
+// draft 2020-12 with format assertions enabled
+func compileSchema(schema *domain.ResourceSchema) (*compiledSchema, error) {
+	metadata, err := compileJSONSchema(schema.MetadataSchema)
+	if err != nil {
+		return nil, err
+	}
+	state, err := compileJSONSchema(schema.StateSchema)
+	if err != nil {
+		return nil, err
+	}
+	return &compiledSchema{mode: schema.Mode, metadata: metadata, state: state}, nil
+}
+
+func compileJSONSchema(raw json.RawMessage) (*jsonschema.Schema, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	document, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidResourceSchema, err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	compiler.AssertFormat()
+	compiler.UseLoader(noRemoteLoader{})
+	if err := compiler.AddResource("schema.json", document); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidResourceSchema, err)
+	}
+	compiled, err := compiler.Compile("schema.json")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidResourceSchema, err)
+	}
+	return compiled, nil
+}
+
+// validateLog checks the JSON fields of a log against its compiled schema.
+// Missing fields are checked as null, so schemas decide whether they're required.
+func validateLog(log *domain.AuditLog, schema *compiledSchema) ([]SchemaViolation, error) {
+	fields := []struct {
+		name   string
+		value  json.RawMessage
+		schema *jsonschema.Schema
+	}{
+		{"metadata", log.Metadata, schema.metadata},
+		{"before_state", log.BeforeState, schema.state},
+		{"after_state", log.AfterState, schema.state},
+	}
+
+	var violations []SchemaViolation
+	for _, field := range fields {
+		if field.schema == nil {
+			continue
+		}
+		// States are optional on creates and deletes, unlike metadata
+		if len(field.value) == 0 && field.name != "metadata" {
+			continue
+		}
+
+		raw := field.value
+		if len(raw) == 0 {
+			raw = json.RawMessage("null")
+		}
+		document, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", field.name, err)
+		}
+
+		var validationErr *jsonschema.ValidationError
+		if err := field.schema.Validate(document); errors.As(err, &validationErr) {
+			for _, unit := range validationErr.BasicOutput().Errors {
+				if unit.Error == nil {
+					continue
+				}
+				violations = append(violations, SchemaViolation{
+					Field:   field.name + jsonPointerPath(unit.InstanceLocation),
+					Message: unit.Error.String(),
+				})
+			}
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to validate %s: %w", field.name, err)
+		}
+	}
+	return violations, nil
+}
+
+// jsonPointerPath converts a JSON pointer such as /tags/1 to the dotted
+// suffix .tags.1
+func jsonPointerPath(pointer string) string {
+	if pointer == "" {
+		return ""
+	}
+	replacer := strings.NewReplacer("~1", "/", "~0", "~")
+	var path strings.Builder
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		path.WriteByte('.')
+		path.WriteString(replacer.Replace(token))
+	}
+	return path.String()
+}
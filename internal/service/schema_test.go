@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type SchemaServiceTestSuite struct {
+	suite.Suite
+	mockRepo   *mocks.Repository
+	mockSchema *mocks.ResourceSchemaRepository
+	mockCache  *mocks.ResourceSchemaCache
+	service    *SchemaService
+}
+
+func (s *SchemaServiceTestSuite) SetupTest() {
+	s.mockRepo = new(mocks.Repository)
+	s.mockSchema = new(mocks.ResourceSchemaRepository)
+	s.mockCache = new(mocks.ResourceSchemaCache)
+
+	s.mockRepo.On("ResourceSchema").Return(s.mockSchema)
+
+	s.service = NewSchemaService(s.mockRepo, s.mockCache)
+}
+
+func TestSchemaService(t *testing.T) {
+	suite.Run(t, new(SchemaServiceTestSuite))
+}
+
+const regionSchema = `{"type":"object","required":["region"],"properties":{"region":{"enum":["eu","us"]}}}`
+
+func (s *SchemaServiceTestSuite) TestValidate_RejectMode_ReturnsViolations() {
+	// Arrange
+	ctx := context.Background()
+	s.mockCache.On("Get", mock.Anything, "tenant1").Return([]domain.ResourceSchema{{
+		ID:             "schema1",
+		ResourceType:   "user",
+		MetadataSchema: json.RawMessage(regionSchema),
+		Mode:           domain.SchemaModeReject,
+	}}, nil)
+	logs := []domain.AuditLog{
+		{TenantID: "tenant1", ResourceType: "user", Metadata: json.RawMessage(`{"region":"eu"}`)},
+		{TenantID: "tenant1", ResourceType: "user", Metadata: json.RawMessage(`{"region":"mars"}`)},
+		{TenantID: "tenant1", ResourceType: "order", Metadata: json.RawMessage(`{"region":"mars"}`)},
+	}
+
+	// Act
+	err := s.service.Validate(ctx, logs)
+
+	// Assert
+	var violations *SchemaViolationError
+	s.Require().ErrorAs(err, &violations)
+	s.Require().Len(violations.Violations, 1)
+	s.Equal(1, violations.Violations[0].Log)
+	s.Equal("metadata.region", violations.Violations[0].Field)
+}
+
+func (s *SchemaServiceTestSuite) TestValidate_FlagMode_RecordsSchemaErrors() {
+	// Arrange
+	ctx := context.Background()
+	s.mockCache.On("Get", mock.Anything, "tenant1").Return([]domain.ResourceSchema{{
+		ID:           "schema1",
+		ResourceType: "user",
+		StateSchema:  json.RawMessage(`{"type":"object","properties":{"email":{"type":"string","format":"email"}}}`),
+		Mode:         domain.SchemaModeFlag,
+	}}, nil)
+	logs := []domain.AuditLog{{
+		TenantID:     "tenant1",
+		ResourceType: "user",
+		BeforeState:  json.RawMessage(`{"email":"jane@example.com"}`),
+		AfterState:   json.RawMessage(`{"email":42}`),
+	}}
+
+	// Act
+	err := s.service.Validate(ctx, logs)
+
+	// Assert
+	s.NoError(err)
+	s.Require().Len(logs[0].SchemaErrors, 1)
+	s.Contains(logs[0].SchemaErrors[0], "after_state.email: ")
+}
+
+func (s *SchemaServiceTestSuite) TestValidate_CacheMiss_LoadsAndCaches() {
+	// Arrange
+	ctx := context.Background()
+	schemas := []domain.ResourceSchema{{ID: "schema1", ResourceType: "user", MetadataSchema: json.RawMessage(regionSchema), Mode: domain.SchemaModeReject}}
+	s.mockCache.On("Get", mock.Anything, "tenant1").Return(nil, nil)
+	s.mockSchema.On("ListByTenant", mock.Anything, "tenant1").Return(schemas, nil)
+	s.mockCache.On("Set", mock.Anything, "tenant1", schemas).Return(nil)
+	logs := []domain.AuditLog{{TenantID: "tenant1", ResourceType: "user"}}
+
+	// Act
+	err := s.service.Validate(ctx, logs)
+
+	// Assert
+	var violations *SchemaViolationError
+	s.Require().ErrorAs(err, &violations)
+	s.Equal("metadata", violations.Violations[0].Field)
+	s.mockCache.AssertExpectations(s.T())
+}
+
+func (s *SchemaServiceTestSuite) TestCreate_InvalidSchema() {
+	// Arrange
+	ctx := context.Background()
+	req := dto.ResourceSchemaRequest{ResourceType: "user", MetadataSchema: json.RawMessage(`{"type":"nope"}`), Mode: "reject"}
+
+	// Act
+	resp, err := s.service.Create(ctx, "tenant1", req)
+
+	// Assert
+	s.ErrorIs(err, ErrInvalidResourceSchema)
+	s.Nil(resp)
+	s.mockSchema.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (s *SchemaServiceTestSuite) TestCreate_RemoteRefRejected() {
+	// Arrange
+	ctx := context.Background()
+	req := dto.ResourceSchemaRequest{ResourceType: "user", MetadataSchema: json.RawMessage(`{"$ref":"file:///etc/passwd"}`), Mode: "reject"}
+
+	// Act
+	_, err := s.service.Create(ctx, "tenant1", req)
+
+	// Assert
+	s.ErrorIs(err, ErrInvalidResourceSchema)
+}
+
+func (s *SchemaServiceTestSuite) TestCreate_DuplicateResourceType() {
+	// Arrange
+	ctx := context.Background()
+	s.mockSchema.On("ListByTenant", mock.Anything, "tenant1").Return([]domain.ResourceSchema{{ID: "schema1", ResourceType: "user"}}, nil)
+	req := dto.ResourceSchemaRequest{ResourceType: "user", MetadataSchema: json.RawMessage(regionSchema), Mode: "flag"}
+
+	// Act
+	_, err := s.service.Create(ctx, "tenant1", req)
+
+	// Assert
+	s.ErrorIs(err, ErrResourceSchemaExists)
+}
+
+func (s *SchemaServiceTestSuite) TestCreate_Success() {
+	// Arrange
+	ctx := context.Background()
+	s.mockSchema.On("ListByTenant", mock.Anything, "tenant1").Return([]domain.ResourceSchema{}, nil)
+	s.mockSchema.On("Create", mock.Anything, mock.MatchedBy(func(schema *domain.ResourceSchema) bool {
+		return schema.TenantID == "tenant1" && schema.ResourceType == "user" && schema.Mode == domain.SchemaModeFlag
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.ResourceSchema).ID = "schema1"
+		args.Get(1).(*domain.ResourceSchema).CreatedAt = time.Now()
+	}).Return(nil)
+	s.mockCache.On("Invalidate", mock.Anything, "tenant1").Return(nil)
+	req := dto.ResourceSchemaRequest{ResourceType: " user ", MetadataSchema: json.RawMessage(regionSchema), Mode: "flag"}
+
+	// Act
+	resp, err := s.service.Create(ctx, "tenant1", req)
+
+	// Assert
+	s.NoError(err)
+	s.Equal("schema1", resp.ID)
+	s.mockCache.AssertExpectations(s.T())
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+)
+
+const (
+	// maxEnsureIndexDays bounds the days one request creates indices for
+	maxEnsureIndexDays = 366
+	// maxReindexRange bounds the period one reindex request queues, so the
+	// request stays short; longer periods are reindexed in several requests
+	maxReindexRange = 31 * 24 * time.Hour
+)
+
+// SearchIndexService manages a tenant's daily OpenSearch indices
+type SearchIndexService struct {
+	repo      repository.Repository
+	publisher MessagePublisher
+}
+
+func NewSearchIndexService(repo repository.Repository, publisher MessagePublisher) *SearchIndexService {
+	return &SearchIndexService{
+		repo:      repo,
+		publisher: publisher,
+	}
+}
+
+// List returns the tenant's daily indices, oldest first
+func (s *SearchIndexService) List(ctx context.Context, tenantID string) (_ []dto.SearchIndexResponse, err error) {
+	ctx, span := tracing.Start(ctx, "SearchIndexService.List", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	indices, err := s.repo.OpenSearch().ListIndices(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indices: %w", err)
+	}
+	return dto.FromSearchIndices(indices), nil
+}
+
+// Ensure creates the tenant's missing indices for the UTC days from start to
+// end, with the audit log mapping
+func (s *SearchIndexService) Ensure(ctx context.Context, tenantID string, start, end time.Time) (_ *dto.EnsureIndicesResponse, err error) {
+	ctx, span := tracing.Start(ctx, "SearchIndexService.Ensure", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	first, last := start.UTC().Truncate(24*time.Hour), end.UTC().Truncate(24*time.Hour)
+	if last.Before(first) || last.Sub(first) >= maxEnsureIndexDays*24*time.Hour {
+		return nil, ErrInvalidIndexRange
+	}
+
+	indices, err := s.repo.OpenSearch().ListIndices(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indices: %w", err)
+	}
+	existing := make(map[time.Time]bool, len(indices))
+	for _, index := range indices {
+		existing[index.Day] = true
+	}
+
+	resp := &dto.EnsureIndicesResponse{Created: []string{}}
+	for day := first; !day.After(last); day = day.Add(24 * time.Hour) {
+		if existing[day] {
+			resp.Existing++
+			continue
+		}
+		if err := s.repo.OpenSearch().CreateIndex(ctx, tenantID, day); err != nil {
+			return nil, fmt.Errorf("failed to create index for %s: %w", day.Format(time.DateOnly), err)
+		}
+		resp.Created = append(resp.Created, day.Format(time.DateOnly))
+	}
+	return resp, nil
+}
+
+// Reindex queues the tenant's logs from start to end in PostgreSQL for the
+// index worker to index into OpenSearch, overwriting the documents already
+// indexed. Logs are read in keyset-paged batches, so memory stays bounded.
+func (s *SearchIndexService) Reindex(ctx context.Context, tenantID string, start, end time.Time) (_ *dto.ReindexResponse, err error) {
+	ctx, span := tracing.Start(ctx, "SearchIndexService.Reindex", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	if !end.After(start) || end.Sub(start) > maxReindexRange {
+		return nil, ErrInvalidReindexRange
+	}
+
+	var queued int64
+	filter := domain.AuditLogFilter{TenantID: tenantID, StartTime: start, EndTime: end}
+	err = scanLogs(ctx, filter, s.repo.AuditLog(), s.repo.OpenSearch(), func(batch []domain.AuditLog) error {
+		// Bulk index messages share the size limit of ingest messages
+		chunks, _, err := IngestChunks(batch)
+		if err != nil {
+			return err
+		}
+		for _, chunk := range chunks {
+			if err := s.publisher.SendBulkIndexMessage(ctx, chunk); err != nil {
+				return fmt.Errorf("failed to queue logs for indexing: %w", err)
+			}
+		}
+		queued += int64(len(batch))
+		return nil
+	})
+	span.SetAttributes(attribute.Int64("reindex.queued", queued))
+	if err != nil {
+		return nil, err
+	}
+	return &dto.ReindexResponse{Queued: queued}, nil
+}
+
+// Delete deletes the tenant's index for the UTC day of day with the logs
+// indexed in it. The logs stay in PostgreSQL and can be reindexed.
+func (s *SearchIndexService) Delete(ctx context.Context, tenantID string, day time.Time) (err error) {
+	ctx, span := tracing.Start(ctx, "SearchIndexService.Delete", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	indices, err := s.repo.OpenSearch().ListIndices(ctx, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to list indices: %w", err)
+	}
+	day = day.UTC().Truncate(24 * time.Hour)
+	for _, index := range indices {
+		if index.Day.Equal(day) {
+			if err := s.repo.OpenSearch().DeleteDailyIndex(ctx, tenantID, day); err != nil {
+				return fmt.Errorf("failed to delete index: %w", err)
+			}
+			return nil
+		}
+	}
+	return ErrSearchIndexNotFound
+}
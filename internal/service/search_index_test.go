@@ -0,0 +1,307 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/mocks"
+	"github.com/kingrain94/audit-log-api/internal/repository/opensearch"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type SearchIndexServiceTestSuite struct {
+	suite.Suite
+	mockRepo       *mocks.Repository
+	mockAuditLog   *mocks.AuditLogRepository
+	mockOpenSearch *mocks.OpenSearchRepository
+	mockPublisher  *mocks.MessagePublisher
+	mockJobs       *mocks.ReindexJobRepository
+	mockFailures   *mocks.IndexFailureRepository
+	service        *SearchIndexService
+}
+
+func (s *SearchIndexServiceTestSuite) SetupTest() {
+	s.mockRepo = new(mocks.Repository)
+	s.mockAuditLog = new(mocks.AuditLogRepository)
+	s.mockOpenSearch = new(mocks.OpenSearchRepository)
+	s.mockPublisher = new(mocks.MessagePublisher)
+	s.mockJobs = new(mocks.ReindexJobRepository)
+	s.mockFailures = new(mocks.IndexFailureRepository)
+
+	s.mockRepo.On("AuditLog").Return(s.mockAuditLog)
+	s.mockRepo.On("OpenSearch").Return(s.mockOpenSearch)
+	s.mockRepo.On("ReindexJob").Return(s.mockJobs)
+	s.mockRepo.On("IndexFailure").Return(s.mockFailures)
+
+	s.service = NewSearchIndexService(s.mockRepo, s.mockPublisher)
+}
+
+func TestSearchIndexService(t *testing.T) {
+	suite.Run(t, new(SearchIndexServiceTestSuite))
+}
+
+func day(s string) time.Time {
+	t, _ := time.Parse(time.DateOnly, s)
+	return t
+}
+
+func (s *SearchIndexServiceTestSuite) TestList_Success() {
+	// Arrange
+	ctx := context.Background()
+	s.mockOpenSearch.On("ListIndices", mock.Anything, "tenant1").Return([]domain.SearchIndex{
+		{Name: "audit_logs_tenant1_2025_07_17", Day: day("2025-07-17"), Docs: 10, SizeBytes: 2048, Health: "green"},
+	}, nil)
+
+	// Act
+	indices, err := s.service.List(ctx, "tenant1")
+
+	// Assert
+	s.NoError(err)
+	s.Len(indices, 1)
+	s.Equal("2025-07-17", indices[0].Day)
+	s.Equal(int64(2048), indices[0].SizeBytes)
+}
+
+func (s *SearchIndexServiceTestSuite) TestEnsure_CreatesMissingDays() {
+	// Arrange
+	ctx := context.Background()
+	s.mockOpenSearch.On("ListIndices", mock.Anything, "tenant1").Return([]domain.SearchIndex{
+		{Name: "audit_logs_tenant1_2025_07_18", Day: day("2025-07-18")},
+	}, nil)
+	s.mockOpenSearch.On("CreateIndex", mock.Anything, "tenant1", day("2025-07-17")).Return(nil)
+	s.mockOpenSearch.On("CreateIndex", mock.Anything, "tenant1", day("2025-07-19")).Return(nil)
+
+	// Act
+	result, err := s.service.Ensure(ctx, "tenant1", day("2025-07-17").Add(time.Hour), day("2025-07-19").Add(23*time.Hour))
+
+	// Assert
+	s.NoError(err)
+	s.Equal([]string{"2025-07-17", "2025-07-19"}, result.Created)
+	s.Equal(1, result.Existing)
+	s.mockOpenSearch.AssertNumberOfCalls(s.T(), "CreateIndex", 2)
+}
+
+func (s *SearchIndexServiceTestSuite) TestEnsure_RangeTooLong() {
+	// Act
+	_, err := s.service.Ensure(context.Background(), "tenant1", day("2024-01-01"), day("2025-01-02"))
+
+	// Assert
+	s.ErrorIs(err, ErrInvalidIndexRange)
+	s.mockOpenSearch.AssertNotCalled(s.T(), "ListIndices", mock.Anything, mock.Anything)
+}
+
+func (s *SearchIndexServiceTestSuite) TestReindex_QueuesLogsInBatches() {
+	// Arrange
+	ctx := context.Background()
+	start, end := day("2025-07-01"), day("2025-07-02")
+	filter := domain.AuditLogFilter{TenantID: "tenant1", StartTime: start, EndTime: end}
+	batch := make([]domain.AuditLog, exportBatchSize)
+	for i := range batch {
+		batch[i] = domain.AuditLog{ID: "log", TenantID: "tenant1", Timestamp: start.Add(time.Duration(i) * time.Second)}
+	}
+	last := batch[len(batch)-1]
+	s.mockAuditLog.On("ListBatch", mock.Anything, filter, (*domain.AuditLogCursor)(nil), exportBatchSize).Return(batch, nil)
+	s.mockAuditLog.On("ListBatch", mock.Anything, filter, &domain.AuditLogCursor{Timestamp: last.Timestamp, ID: last.ID}, exportBatchSize).
+		Return([]domain.AuditLog{{ID: "final", TenantID: "tenant1"}}, nil)
+	var published int
+	s.mockPublisher.On("SendBulkIndexMessage", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { published += len(args.Get(1).([]domain.AuditLog)) }).
+		Return(nil)
+
+	// Act
+	result, err := s.service.Reindex(ctx, "tenant1", start, end)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(int64(exportBatchSize+1), result.Queued)
+	s.Equal(exportBatchSize+1, published)
+}
+
+func (s *SearchIndexServiceTestSuite) TestReindex_QueueFailure_ReturnsError() {
+	// Arrange
+	ctx := context.Background()
+	start, end := day("2025-07-01"), day("2025-07-02")
+	s.mockAuditLog.On("ListBatch", mock.Anything, mock.Anything, (*domain.AuditLogCursor)(nil), exportBatchSize).
+		Return([]domain.AuditLog{{ID: "log1", TenantID: "tenant1"}}, nil)
+	s.mockPublisher.On("SendBulkIndexMessage", mock.Anything, mock.Anything).Return(errors.New("queue unavailable"))
+
+	// Act
+	_, err := s.service.Reindex(ctx, "tenant1", start, end)
+
+	// Assert
+	s.ErrorContains(err, "failed to queue logs for indexing")
+}
+
+func (s *SearchIndexServiceTestSuite) TestReindex_RangeTooLong() {
+	// Act
+	_, err := s.service.Reindex(context.Background(), "tenant1", day("2025-01-01"), day("2025-03-01"))
+
+	// Assert
+	s.ErrorIs(err, ErrInvalidReindexRange)
+	s.mockAuditLog.AssertNotCalled(s.T(), "ListBatch", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *SearchIndexServiceTestSuite) TestDelete_Success() {
+	// Arrange
+	ctx := context.Background()
+	s.mockOpenSearch.On("ListIndices", mock.Anything, "tenant1").Return([]domain.SearchIndex{
+		{Name: "audit_logs_tenant1_2025_07_17", Day: day("2025-07-17")},
+	}, nil)
+	s.mockOpenSearch.On("DeleteDailyIndex", mock.Anything, "tenant1", day("2025-07-17")).Return(nil)
+
+	// Act
+	err := s.service.Delete(ctx, "tenant1", day("2025-07-17"))
+
+	// Assert
+	s.NoError(err)
+	s.mockOpenSearch.AssertExpectations(s.T())
+}
+
+func (s *SearchIndexServiceTestSuite) TestDelete_NotFound() {
+	// Arrange
+	ctx := context.Background()
+	s.mockOpenSearch.On("ListIndices", mock.Anything, "tenant1").Return([]domain.SearchIndex{}, nil)
+
+	// Act
+	err := s.service.Delete(ctx, "tenant1", day("2025-07-17"))
+
+	// Assert
+	s.ErrorIs(err, ErrSearchIndexNotFound)
+	s.mockOpenSearch.AssertNotCalled(s.T(), "DeleteDailyIndex", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *SearchIndexServiceTestSuite) TestCreateReindexJob_CountsLogs() {
+	// Arrange
+	ctx := context.Background()
+	start, end := day("2025-01-01"), day("2025-07-01")
+	filter := domain.AuditLogFilter{TenantID: "tenant1", StartTime: start, EndTime: end}
+	s.mockAuditLog.On("GetStats", mock.Anything, filter).Return(&domain.AuditLogStats{TotalLogs: 2500}, nil)
+	s.mockJobs.On("Create", mock.Anything, mock.MatchedBy(func(job *domain.ReindexJob) bool {
+		return job.TenantID == "tenant1" && job.Status == domain.JobPending && job.TotalCount == 2500 && job.Cursor() == nil
+	})).Return(nil)
+
+	// Act
+	job, err := s.service.CreateReindexJob(ctx, "tenant1", start, end)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(start, job.StartTime)
+	s.Equal(end, job.EndTime)
+	s.mockJobs.AssertExpectations(s.T())
+}
+
+func (s *SearchIndexServiceTestSuite) TestCreateReindexJob_InvalidRange() {
+	// Act
+	_, err := s.service.CreateReindexJob(context.Background(), "tenant1", day("2025-07-01"), day("2025-01-01"))
+
+	// Assert
+	s.ErrorIs(err, ErrInvalidBackfillRange)
+	s.mockJobs.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (s *SearchIndexServiceTestSuite) TestRunReindexJob_ResumesFromCheckpoint() {
+	// Arrange
+	ctx := context.Background()
+	checkpoint := day("2025-07-01").Add(time.Hour)
+	job := &domain.ReindexJob{
+		ID:              "job1",
+		TenantID:        "tenant1",
+		Status:          domain.JobFailed,
+		StartTime:       day("2025-07-01"),
+		EndTime:         day("2025-07-02"),
+		TotalCount:      3,
+		IndexedCount:    2,
+		CursorTimestamp: &checkpoint,
+		CursorID:        "log2",
+		Error:           "context canceled",
+	}
+	logs := []domain.AuditLog{{ID: "log3", TenantID: "tenant1", Timestamp: checkpoint.Add(time.Minute)}}
+	s.mockAuditLog.On("ListBatch", mock.Anything, mock.Anything, &domain.AuditLogCursor{Timestamp: checkpoint, ID: "log2"}, reindexBatchSize).Return(logs, nil)
+	s.mockOpenSearch.On("BulkIndex", mock.Anything, logs).Return(nil)
+	s.mockJobs.On("Update", mock.Anything, job).Return(nil)
+	var reported []int64
+
+	// Act
+	err := s.service.RunReindexJob(ctx, job, func(job *domain.ReindexJob) {
+		reported = append(reported, job.IndexedCount)
+	})
+
+	// Assert
+	s.NoError(err)
+	s.Equal(domain.JobCompleted, job.Status)
+	s.Empty(job.Error)
+	s.NotNil(job.CompletedAt)
+	s.Equal(int64(3), job.IndexedCount)
+	s.Equal("log3", job.CursorID)
+	s.Equal([]int64{3}, reported)
+	// Running, the checkpoint after the batch and completion
+	s.mockJobs.AssertNumberOfCalls(s.T(), "Update", 3)
+}
+
+func (s *SearchIndexServiceTestSuite) TestRunReindexJob_SavesRejectedLogs() {
+	// Arrange
+	ctx := context.Background()
+	job := &domain.ReindexJob{ID: "job1", TenantID: "tenant1", Status: domain.JobPending, StartTime: day("2025-07-01"), EndTime: day("2025-07-02")}
+	logs := []domain.AuditLog{{ID: "log1", TenantID: "tenant1"}, {ID: "log2", TenantID: "tenant1"}}
+	s.mockAuditLog.On("ListBatch", mock.Anything, mock.Anything, (*domain.AuditLogCursor)(nil), reindexBatchSize).Return(logs, nil)
+	s.mockOpenSearch.On("BulkIndex", mock.Anything, logs).Return(&opensearch.BulkIndexError{Failures: []opensearch.BulkItemFailure{
+		{Log: logs[1], Index: "audit_logs_tenant1_2025_07_01", Status: 400, Type: "mapper_parsing_exception", Reason: "failed to parse field [metadata]"},
+	}})
+	s.mockFailures.On("Save", mock.Anything, mock.MatchedBy(func(failures []domain.IndexFailure) bool {
+		return len(failures) == 1 && failures[0].LogID == "log2" && failures[0].ErrorType == "mapper_parsing_exception"
+	})).Return(nil)
+	s.mockJobs.On("Update", mock.Anything, job).Return(nil)
+
+	// Act
+	err := s.service.RunReindexJob(ctx, job, nil)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(domain.JobCompleted, job.Status)
+	s.Equal(int64(1), job.IndexedCount)
+	s.Equal(int64(1), job.FailedCount)
+	s.mockFailures.AssertExpectations(s.T())
+}
+
+func (s *SearchIndexServiceTestSuite) TestRunReindexJob_IndexFailure_KeepsCheckpoint() {
+	// Arrange
+	ctx := context.Background()
+	job := &domain.ReindexJob{ID: "job1", TenantID: "tenant1", Status: domain.JobPending, StartTime: day("2025-07-01"), EndTime: day("2025-07-02")}
+	batch := make([]domain.AuditLog, reindexBatchSize)
+	for i := range batch {
+		batch[i] = domain.AuditLog{ID: "log", TenantID: "tenant1", Timestamp: day("2025-07-01").Add(time.Duration(i) * time.Second)}
+	}
+	last := batch[len(batch)-1]
+	next := []domain.AuditLog{{ID: "final", TenantID: "tenant1"}}
+	s.mockAuditLog.On("ListBatch", mock.Anything, mock.Anything, (*domain.AuditLogCursor)(nil), reindexBatchSize).Return(batch, nil)
+	s.mockAuditLog.On("ListBatch", mock.Anything, mock.Anything, &domain.AuditLogCursor{Timestamp: last.Timestamp, ID: last.ID}, reindexBatchSize).Return(next, nil)
+	s.mockOpenSearch.On("BulkIndex", mock.Anything, batch).Return(nil)
+	s.mockOpenSearch.On("BulkIndex", mock.Anything, next).Return(errors.New("cluster unavailable"))
+	s.mockJobs.On("Update", mock.Anything, job).Return(nil)
+
+	// Act
+	err := s.service.RunReindexJob(ctx, job, nil)
+
+	// Assert
+	s.ErrorContains(err, "failed to index logs")
+	s.Equal(domain.JobFailed, job.Status)
+	s.Contains(job.Error, "cluster unavailable")
+	s.Equal(int64(reindexBatchSize), job.IndexedCount)
+	s.Equal(&domain.AuditLogCursor{Timestamp: last.Timestamp, ID: last.ID}, job.Cursor())
+}
+
+func (s *SearchIndexServiceTestSuite) TestRunReindexJob_AlreadyCompleted() {
+	// Arrange
+	job := &domain.ReindexJob{ID: "job1", TenantID: "tenant1", Status: domain.JobCompleted}
+
+	// Act
+	err := s.service.RunReindexJob(context.Background(), job, nil)
+
+	// Assert
+	s.ErrorIs(err, ErrReindexJobCompleted)
+	s.mockAuditLog.AssertNotCalled(s.T(), "ListBatch", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+var (
+	// ErrObjectNotFound is returned for archive objects that don't exist
+	ErrObjectNotFound = errors.New("archive object not found")
+	// ErrObjectInGlacier is returned for archive objects in Glacier, which
+	// have to be restored before they can be read
+	ErrObjectInGlacier = errors.New("archive object is in Glacier")
+)
+
+// S3ArchiveReader reads and queries objects in the archive bucket
+type S3ArchiveReader struct {
+	client *s3.Client
+	bucket string
+}
+
+func NewS3ArchiveReader(client *s3.Client, cfg *config.S3Config) *S3ArchiveReader {
+	return &S3ArchiveReader{
+		client: client,
+		bucket: cfg.BucketName,
+	}
+}
+
+// Open streams the archive object at key; the caller closes it
+func (r *S3ArchiveReader) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := r.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(r.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, objectError(key, "failed to download archive object", err)
+	}
+	return out.Body, nil
+}
+
+// Select runs an S3 Select SQL expression over the gzip-compressed NDJSON
+// archive part at key and returns the logs it selects. The expression bounds
+// the logs returned, which are held in memory.
+func (r *S3ArchiveReader) Select(ctx context.Context, key, expression string) ([]domain.AuditLog, error) {
+	out, err := r.client.SelectObjectContent(ctx, &s3.SelectObjectContentInput{
+		Bucket:         aws.String(r.bucket),
+		Key:            aws.String(key),
+		Expression:     aws.String(expression),
+		ExpressionType: types.ExpressionTypeSql,
+		InputSerialization: &types.InputSerialization{
+			CompressionType: types.CompressionTypeGzip,
+			JSON:            &types.JSONInput{Type: types.JSONTypeLines},
+		},
+		OutputSerialization: &types.OutputSerialization{
+			JSON: &types.JSONOutput{RecordDelimiter: aws.String("\n")},
+		},
+	})
+	if err != nil {
+		return nil, objectError(key, "failed to query archive object", err)
+	}
+
+	stream := out.GetStream()
+	defer stream.Close()
+
+	// Records may be split across events, so they are decoded once the
+	// stream has ended
+	var records bytes.Buffer
+	ended := false
+	for event := range stream.Events() {
+		switch e := event.(type) {
+		case *types.SelectObjectContentEventStreamMemberRecords:
+			records.Write(e.Value.Payload)
+		case *types.SelectObjectContentEventStreamMemberEnd:
+			ended = true
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read query results of archive object %s: %w", key, err)
+	}
+	if !ended {
+		return nil, fmt.Errorf("query results of archive object %s ended early", key)
+	}
+
+	var logs []domain.AuditLog
+	dec := json.NewDecoder(&records)
+	for {
+		var log domain.AuditLog
+		if err := dec.Decode(&log); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to decode log selected from %s: %w", key, err)
+		}
+		logs = append(logs, log)
+	}
+	return logs, nil
+}
+
+// objectError maps the errors of missing objects and objects in Glacier to
+// ErrObjectNotFound and ErrObjectInGlacier
+func objectError(key, message string, err error) error {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "NoSuchKey":
+			return fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+		case "InvalidObjectState":
+			return fmt.Errorf("%w: %s", ErrObjectInGlacier, key)
+		}
+	}
+	return fmt.Errorf("%s %s: %w", message, key, err)
+}
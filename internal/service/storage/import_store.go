@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+)
+
+// ErrInvalidS3URI is returned for URIs that aren't of the form s3://bucket/key
+var ErrInvalidS3URI = errors.New("invalid S3 URI, expected s3://bucket/key")
+
+// S3ImportStore stages files uploaded for import in the export bucket and
+// reads import files from any bucket the role can read
+type S3ImportStore struct {
+	client   *s3.Client
+	bucket   string
+	kmsKeyID string
+}
+
+func NewS3ImportStore(client *s3.Client, cfg *config.S3Config) *S3ImportStore {
+	return &S3ImportStore{
+		client:   client,
+		bucket:   cfg.ExportBucket,
+		kmsKeyID: cfg.KMSKeyID,
+	}
+}
+
+// Put stores size bytes of body at key in the export bucket and returns its URI
+func (s *S3ImportStore) Put(ctx context.Context, key string, body io.Reader, size int64) (string, error) {
+	input := &s3.PutObjectInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(key),
+		Body:          body,
+		ContentLength: aws.Int64(size),
+		ContentType:   aws.String("application/gzip"),
+	}
+	if s.kmsKeyID != "" {
+		input.ServerSideEncryption = types.ServerSideEncryptionAwsKms
+		input.SSEKMSKeyId = aws.String(s.kmsKeyID)
+	}
+	if _, err := s.client.PutObject(ctx, input); err != nil {
+		return "", fmt.Errorf("failed to upload import file: %w", err)
+	}
+	return "s3://" + s.bucket + "/" + key, nil
+}
+
+// Open streams the object at uri and returns its size; the caller closes it
+func (s *S3ImportStore) Open(ctx context.Context, uri string) (io.ReadCloser, int64, error) {
+	bucket, key, err := ParseS3URI(uri)
+	if err != nil {
+		return nil, 0, err
+	}
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to download import file %s: %w", uri, err)
+	}
+	return out.Body, aws.ToInt64(out.ContentLength), nil
+}
+
+// Delete removes the object at uri
+func (s *S3ImportStore) Delete(ctx context.Context, uri string) error {
+	bucket, key, err := ParseS3URI(uri)
+	if err != nil {
+		return err
+	}
+	if _, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}); err != nil {
+		return fmt.Errorf("failed to delete import file %s: %w", uri, err)
+	}
+	return nil
+}
+
+// ParseS3URI splits an s3://bucket/key URI into its bucket and key
+func ParseS3URI(uri string) (bucket, key string, err error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "s3" || u.Host == "" {
+		return "", "", ErrInvalidS3URI
+	}
+	key = strings.TrimPrefix(u.Path, "/")
+	if key == "" || strings.HasSuffix(key, "/") {
+		return "", "", ErrInvalidS3URI
+	}
+	return u.Host, key, nil
+}
@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+)
+
+// S3Presigner issues time-limited download URLs for objects in the export bucket
+type S3Presigner struct {
+	client *s3.PresignClient
+	bucket string
+	expiry time.Duration
+}
+
+func NewS3Presigner(client *s3.Client, cfg *config.S3Config) *S3Presigner {
+	return &S3Presigner{
+		client: s3.NewPresignClient(client),
+		bucket: cfg.ExportBucket,
+		expiry: cfg.ExportURLExpiry,
+	}
+}
+
+func (p *S3Presigner) PresignGet(ctx context.Context, key string) (string, error) {
+	req, err := p.client.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(p.bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(p.expiry))
+	if err != nil {
+		return "", fmt.Errorf("failed to presign export download: %w", err)
+	}
+
+	return req.URL, nil
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+)
+
+//go:generate mockery --name TaggingRuleCache --output ../mocks
+type TaggingRuleCache interface {
+	Get(ctx context.Context, tenantID string) ([]domain.TaggingRule, error)
+	Set(ctx context.Context, tenantID string, rules []domain.TaggingRule) error
+	Invalidate(ctx context.Context, tenantID string) error
+}
+
+// TaggingService manages the tenants' tagging rules and classifies logs with
+// them at ingest, such as "authentication" or "data-export", so security
+// events can be listed and counted by tag
+type TaggingService struct {
+	repo  repository.PostgresRepository
+	cache TaggingRuleCache
+}
+
+func NewTaggingService(repo repository.PostgresRepository, cache TaggingRuleCache) *TaggingService {
+	return &TaggingService{
+		repo:  repo,
+		cache: cache,
+	}
+}
+
+// taggingRule is a rule with its message pattern compiled
+type taggingRule struct {
+	domain.TaggingRule
+	pattern *regexp.Regexp
+}
+
+func (s *TaggingService) Create(ctx context.Context, tenantID string, req dto.TaggingRuleRequest) (_ *dto.TaggingRuleResponse, err error) {
+	ctx, span := tracing.Start(ctx, "TaggingService.Create", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	rule := &domain.TaggingRule{
+		TenantID:       tenantID,
+		Tag:            req.Tag,
+		Actions:        req.Actions,
+		ResourceTypes:  req.ResourceTypes,
+		Severities:     req.Severities,
+		MessagePattern: strings.TrimSpace(req.MessagePattern),
+	}
+	if len(rule.Actions) == 0 && len(rule.ResourceTypes) == 0 && len(rule.Severities) == 0 && rule.MessagePattern == "" {
+		return nil, ErrEmptyTaggingRule
+	}
+	if _, err := compileTaggingRule(*rule); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.TaggingRule().ListByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tagging rules: %w", err)
+	}
+	for _, r := range existing {
+		if r.Tag == rule.Tag && r.MessagePattern == rule.MessagePattern &&
+			sameValues(r.Actions, rule.Actions) && sameValues(r.ResourceTypes, rule.ResourceTypes) &&
+			sameValues(r.Severities, rule.Severities) {
+			return nil, ErrTaggingRuleExists
+		}
+	}
+
+	if err := s.repo.TaggingRule().Create(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to create tagging rule: %w", err)
+	}
+	if err := s.cache.Invalidate(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	return dto.FromTaggingRule(rule), nil
+}
+
+func (s *TaggingService) List(ctx context.Context, tenantID string) (_ []dto.TaggingRuleResponse, err error) {
+	ctx, span := tracing.Start(ctx, "TaggingService.List", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	rules, err := s.repo.TaggingRule().ListByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromTaggingRules(rules), nil
+}
+
+func (s *TaggingService) Delete(ctx context.Context, tenantID, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "TaggingService.Delete", trace.WithAttributes(tracing.TenantAttr(tenantID), attribute.String("tagging_rule.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	err = s.repo.TaggingRule().Delete(ctx, tenantID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrTaggingRuleNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete tagging rule: %w", err)
+	}
+
+	return s.cache.Invalidate(ctx, tenantID)
+}
+
+// Tag adds to logs in place the tags of the rules of each log's tenant that
+// they match. It fails if rules can't be loaded, so logs are never stored
+// without the tags their rules give them.
+func (s *TaggingService) Tag(ctx context.Context, logs []domain.AuditLog) (err error) {
+	ctx, span := tracing.Start(ctx, "TaggingService.Tag", trace.WithAttributes(attribute.Int("audit_log.count", len(logs))))
+	defer func() { tracing.End(span, err) }()
+
+	rulesByTenant := make(map[string][]taggingRule)
+	for i := range logs {
+		rules, ok := rulesByTenant[logs[i].TenantID]
+		if !ok {
+			rules, err = s.rules(ctx, logs[i].TenantID)
+			if err != nil {
+				return err
+			}
+			rulesByTenant[logs[i].TenantID] = rules
+		}
+
+		for _, rule := range rules {
+			if rule.MatchesFields(&logs[i]) && (rule.pattern == nil || rule.pattern.MatchString(logs[i].Message)) {
+				logs[i].Tags.Add(rule.Tag)
+			}
+		}
+	}
+
+	return nil
+}
+
+// rules reads a tenant's rules through the cache; cache errors fall back to
+// the database. Rules whose pattern no longer compiles are skipped.
+func (s *TaggingService) rules(ctx context.Context, tenantID string) ([]taggingRule, error) {
+	rules, err := s.cache.Get(ctx, tenantID)
+	if err != nil || rules == nil {
+		rules, err = s.repo.TaggingRule().ListByTenant(ctx, tenantID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tagging rules: %w", err)
+		}
+		_ = s.cache.Set(ctx, tenantID, rules)
+	}
+
+	compiled := make([]taggingRule, 0, len(rules))
+	for _, rule := range rules {
+		if c, err := compileTaggingRule(rule); err == nil {
+			compiled = append(compiled, c)
+		}
+	}
+	return compiled, nil
+}
+
+func compileTaggingRule(rule domain.TaggingRule) (taggingRule, error) {
+	compiled := taggingRule{TaggingRule: rule}
+	if rule.MessagePattern != "" {
+		pattern, err := regexp.Compile(rule.MessagePattern)
+		if err != nil {
+			return compiled, ErrInvalidMessagePattern
+		}
+		compiled.pattern = pattern
+	}
+	return compiled, nil
+}
+
+// sameValues reports whether a and b hold the same values, in any order
+func sameValues(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
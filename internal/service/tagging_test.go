@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type TaggingServiceTestSuite struct {
+	suite.Suite
+	mockRepo    *mocks.Repository
+	mockTagging *mocks.TaggingRuleRepository
+	mockCache   *mocks.TaggingRuleCache
+	service     *TaggingService
+}
+
+func (s *TaggingServiceTestSuite) SetupTest() {
+	s.mockRepo = new(mocks.Repository)
+	s.mockTagging = new(mocks.TaggingRuleRepository)
+	s.mockCache = new(mocks.TaggingRuleCache)
+
+	s.mockRepo.On("TaggingRule").Return(s.mockTagging)
+
+	s.service = NewTaggingService(s.mockRepo, s.mockCache)
+}
+
+func TestTaggingService(t *testing.T) {
+	suite.Run(t, new(TaggingServiceTestSuite))
+}
+
+func (s *TaggingServiceTestSuite) TestTag_AppliesMatchingRules() {
+	// Arrange
+	ctx := context.Background()
+	s.mockCache.On("Get", mock.Anything, "tenant1").Return([]domain.TaggingRule{
+		{Tag: "authentication", Actions: []string{"LOGIN", "LOGOUT"}},
+		{Tag: "privilege-change", ResourceTypes: []string{"role"}, Severities: []string{"WARNING"}},
+		{Tag: "data-export", MessagePattern: `(?i)\bexport(ed)?\b`},
+		{Tag: "security", Actions: []string{"LOGIN"}, Severities: []string{"ERROR", "CRITICAL"}},
+	}, nil)
+
+	logs := []domain.AuditLog{
+		{TenantID: "tenant1", Action: "LOGIN", Severity: "error", Message: "Login failed"},
+		{TenantID: "tenant1", Action: "UPDATE", ResourceType: "role", Severity: "WARNING", Message: "Role granted"},
+		{TenantID: "tenant1", Action: "VIEW", ResourceType: "report", Severity: "INFO", Message: "Report Exported to CSV"},
+		{TenantID: "tenant1", Action: "UPDATE", ResourceType: "role", Severity: "INFO", Message: "Role renamed"},
+	}
+
+	// Act
+	err := s.service.Tag(ctx, logs)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(domain.Tags{"authentication", "security"}, logs[0].Tags)
+	s.Equal(domain.Tags{"privilege-change"}, logs[1].Tags)
+	s.Equal(domain.Tags{"data-export"}, logs[2].Tags)
+	s.Empty(logs[3].Tags)
+}
+
+func (s *TaggingServiceTestSuite) TestTag_LoadsRulesOncePerTenant() {
+	// Arrange
+	ctx := context.Background()
+	rules := []domain.TaggingRule{{Tag: "authentication", Actions: []string{"LOGIN"}}}
+	s.mockCache.On("Get", mock.Anything, "tenant1").Return(nil, nil).Once()
+	s.mockTagging.On("ListByTenant", mock.Anything, "tenant1").Return(rules, nil).Once()
+	s.mockCache.On("Set", mock.Anything, "tenant1", rules).Return(nil).Once()
+
+	logs := []domain.AuditLog{
+		{TenantID: "tenant1", Action: "LOGIN"},
+		{TenantID: "tenant1", Action: "LOGIN", Tags: domain.Tags{"authentication"}},
+	}
+
+	// Act
+	err := s.service.Tag(ctx, logs)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(domain.Tags{"authentication"}, logs[0].Tags)
+	s.Equal(domain.Tags{"authentication"}, logs[1].Tags)
+	s.mockCache.AssertExpectations(s.T())
+	s.mockTagging.AssertExpectations(s.T())
+}
+
+func (s *TaggingServiceTestSuite) TestTag_RulesUnavailable() {
+	// Arrange
+	ctx := context.Background()
+	s.mockCache.On("Get", mock.Anything, "tenant1").Return(nil, errors.New("redis down"))
+	s.mockTagging.On("ListByTenant", mock.Anything, "tenant1").Return(nil, errors.New("db down"))
+
+	// Act
+	err := s.service.Tag(ctx, []domain.AuditLog{{TenantID: "tenant1"}})
+
+	// Assert
+	s.Error(err)
+}
+
+func (s *TaggingServiceTestSuite) TestCreate_Success() {
+	// Arrange
+	ctx := context.Background()
+	req := dto.TaggingRuleRequest{Tag: "privilege-change", ResourceTypes: []string{"role"}, MessagePattern: " (?i)granted "}
+
+	s.mockTagging.On("ListByTenant", mock.Anything, "tenant1").Return([]domain.TaggingRule{}, nil)
+	s.mockTagging.On("Create", mock.Anything, mock.MatchedBy(func(r *domain.TaggingRule) bool {
+		return r.TenantID == "tenant1" && r.Tag == "privilege-change" && r.MessagePattern == "(?i)granted"
+	})).Return(nil)
+	s.mockCache.On("Invalidate", mock.Anything, "tenant1").Return(nil)
+
+	// Act
+	resp, err := s.service.Create(ctx, "tenant1", req)
+
+	// Assert
+	s.NoError(err)
+	s.Equal("privilege-change", resp.Tag)
+	s.Equal([]string{"role"}, resp.ResourceTypes)
+	s.mockTagging.AssertExpectations(s.T())
+	s.mockCache.AssertExpectations(s.T())
+}
+
+func (s *TaggingServiceTestSuite) TestCreate_NoCriteria() {
+	// Arrange
+	ctx := context.Background()
+
+	// Act
+	resp, err := s.service.Create(ctx, "tenant1", dto.TaggingRuleRequest{Tag: "everything"})
+
+	// Assert
+	s.ErrorIs(err, ErrEmptyTaggingRule)
+	s.Nil(resp)
+	s.mockTagging.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (s *TaggingServiceTestSuite) TestCreate_InvalidPattern() {
+	// Arrange
+	ctx := context.Background()
+
+	// Act
+	resp, err := s.service.Create(ctx, "tenant1", dto.TaggingRuleRequest{Tag: "data-export", MessagePattern: "export("})
+
+	// Assert
+	s.ErrorIs(err, ErrInvalidMessagePattern)
+	s.Nil(resp)
+	s.mockTagging.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (s *TaggingServiceTestSuite) TestCreate_Duplicate() {
+	// Arrange
+	ctx := context.Background()
+	req := dto.TaggingRuleRequest{Tag: "authentication", Actions: []string{"LOGOUT", "LOGIN"}}
+
+	s.mockTagging.On("ListByTenant", mock.Anything, "tenant1").Return([]domain.TaggingRule{
+		{ID: "rule1", Tag: "authentication", Actions: []string{"LOGIN", "LOGOUT"}},
+	}, nil)
+
+	// Act
+	resp, err := s.service.Create(ctx, "tenant1", req)
+
+	// Assert
+	s.ErrorIs(err, ErrTaggingRuleExists)
+	s.Nil(resp)
+}
+
+func (s *TaggingServiceTestSuite) TestDelete_NotFound() {
+	// Arrange
+	ctx := context.Background()
+	s.mockTagging.On("Delete", mock.Anything, "tenant1", "missing").Return(gorm.ErrRecordNotFound)
+
+	// Act
+	err := s.service.Delete(ctx, "tenant1", "missing")
+
+	// Assert
+	s.ErrorIs(err, ErrTaggingRuleNotFound)
+	s.mockCache.AssertNotCalled(s.T(), "Invalidate", mock.Anything, mock.Anything)
+}
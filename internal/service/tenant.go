@@ -2,19 +2,48 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
+	"gorm.io/gorm"
+
 	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/config"
 	"github.com/kingrain94/audit-log-api/internal/domain"
 	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/pkg/envelope"
+	"github.com/kingrain94/audit-log-api/pkg/safehttp"
 )
 
+//go:generate mockery --name RateLimitCache --output ../mocks
+type RateLimitCache interface {
+	Get(ctx context.Context, tenantID string) (*domain.TenantRateLimit, error)
+	Set(ctx context.Context, limit *domain.TenantRateLimit) error
+	Invalidate(ctx context.Context, tenantID string) error
+}
+
+//go:generate mockery --name TenantSettingsCache --output ../mocks
+type TenantSettingsCache interface {
+	Get(ctx context.Context, tenantID string) (*domain.TenantSettings, error)
+	Set(ctx context.Context, tenantID string, settings *domain.TenantSettings) error
+	Invalidate(ctx context.Context, tenantID string) error
+}
+
 type TenantService struct {
-	repo repository.Repository
+	repo          repository.Repository
+	limitCache    RateLimitCache
+	settingsCache TenantSettingsCache
+	deletion      *config.TenantDeletionConfig
 }
 
-func NewTenantService(repo repository.Repository) *TenantService {
-	return &TenantService{repo: repo}
+func NewTenantService(repo repository.Repository, limitCache RateLimitCache, settingsCache TenantSettingsCache, deletion *config.TenantDeletionConfig) *TenantService {
+	return &TenantService{
+		repo:          repo,
+		limitCache:    limitCache,
+		settingsCache: settingsCache,
+		deletion:      deletion,
+	}
 }
 
 func (s *TenantService) Create(ctx context.Context, req dto.CreateTenantRequest) (dto.CreateTenantResponse, error) {
@@ -44,8 +73,63 @@ func (s *TenantService) Update(ctx context.Context, tenant *domain.Tenant) error
 	return s.repo.Tenant().Update(ctx, tenant)
 }
 
-func (s *TenantService) Delete(ctx context.Context, id string) error {
-	return s.repo.Tenant().Delete(ctx, id)
+// Delete soft deletes the tenant. Its logs are kept for the grace period,
+// during which the tenant can be restored; the purge worker then archives and
+// removes them.
+func (s *TenantService) Delete(ctx context.Context, id string) (*domain.Tenant, error) {
+	if id == domain.SystemTenantID {
+		return nil, ErrSystemTenant
+	}
+
+	purgeAfter := time.Now().Add(s.deletion.GracePeriod)
+	err := s.repo.Tenant().Delete(ctx, id, purgeAfter)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTenantNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete tenant: %w", err)
+	}
+
+	// The rate limiter and ingest middleware must stop seeing the tenant
+	_ = s.limitCache.Invalidate(ctx, id)
+	_ = s.settingsCache.Invalidate(ctx, id)
+
+	tenant, err := s.repo.Tenant().GetDeleted(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted tenant: %w", err)
+	}
+	return tenant, nil
+}
+
+// Restore undoes the deletion of a tenant within its grace period
+func (s *TenantService) Restore(ctx context.Context, id string) (*domain.Tenant, error) {
+	tenant, err := s.repo.Tenant().GetDeleted(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Tell a tenant that was never deleted apart from one that doesn't exist
+		if _, err := s.getTenant(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrTenantNotDeleted
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted tenant: %w", err)
+	}
+	if !tenant.Restorable(time.Now()) {
+		return nil, ErrTenantPurged
+	}
+
+	err = s.repo.Tenant().Restore(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// The purge worker got to it first
+		return nil, ErrTenantPurged
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore tenant: %w", err)
+	}
+
+	tenant.DeletedAt = nil
+	tenant.PurgeAfter = nil
+	return tenant, nil
 }
 
 func (s *TenantService) List(ctx context.Context) ([]dto.CreateTenantResponse, error) {
@@ -65,3 +149,238 @@ func (s *TenantService) List(ctx context.Context) ([]dto.CreateTenantResponse, e
 	}
 	return tenantResponses, nil
 }
+
+// GetRateLimit returns the tenant's rate limit, reading through the cache.
+// Cache errors are not fatal: the limit is then loaded from the database.
+func (s *TenantService) GetRateLimit(ctx context.Context, tenantID string) (*domain.TenantRateLimit, error) {
+	if limit, err := s.limitCache.Get(ctx, tenantID); err == nil && limit != nil {
+		return limit, nil
+	}
+
+	tenant, err := s.getTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := &domain.TenantRateLimit{
+		TenantID: tenant.ID,
+		Limit:    tenant.RateLimit,
+		Burst:    tenant.RateLimitBurst,
+	}
+	_ = s.limitCache.Set(ctx, limit)
+
+	return limit, nil
+}
+
+// UpdateRateLimit persists a new rate limit and drops the cached copy so this
+// instance applies it immediately; other instances pick it up on cache expiry
+func (s *TenantService) UpdateRateLimit(ctx context.Context, tenantID string, req dto.UpdateTenantRateLimitRequest) (*domain.TenantRateLimit, error) {
+	tenant, err := s.getTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	tenant.RateLimit = req.RateLimit
+	tenant.RateLimitBurst = req.Burst
+	if err := s.Update(ctx, tenant); err != nil {
+		return nil, fmt.Errorf("failed to update tenant rate limit: %w", err)
+	}
+
+	if err := s.limitCache.Invalidate(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	return &domain.TenantRateLimit{
+		TenantID: tenant.ID,
+		Limit:    tenant.RateLimit,
+		Burst:    tenant.RateLimitBurst,
+	}, nil
+}
+
+// GetSettings returns the tenant with its settings
+func (s *TenantService) GetSettings(ctx context.Context, tenantID string) (*domain.Tenant, error) {
+	return s.getTenant(ctx, tenantID)
+}
+
+// UpdateSettings applies the given settings, leaving the others unchanged,
+// and drops the cached settings and rate limit so this instance applies them
+// immediately; other instances pick them up on cache expiry
+func (s *TenantService) UpdateSettings(ctx context.Context, tenantID string, req dto.UpdateTenantSettingsRequest) (*domain.Tenant, error) {
+	tenant, err := s.getTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &tenant.Settings
+	if req.RetentionDays != nil {
+		settings.RetentionDays = *req.RetentionDays
+	}
+	if req.AllowedActions != nil {
+		settings.AllowedActions = req.AllowedActions
+	}
+	if req.CustomActions != nil {
+		settings.CustomActions = req.CustomActions
+	}
+	if req.WebhookURL != nil {
+		if *req.WebhookURL != "" {
+			if err := safehttp.ValidateURL(*req.WebhookURL); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidWebhookURL, err)
+			}
+		}
+		settings.WebhookURL = *req.WebhookURL
+	}
+	if req.WebhookSecrets != nil {
+		settings.WebhookSecrets = req.WebhookSecrets
+	}
+	if req.DataResidencyRegion != nil {
+		settings.DataResidencyRegion = *req.DataResidencyRegion
+	}
+	if req.LogVisibility != nil {
+		settings.LogVisibility = domain.PolicyScope(*req.LogVisibility)
+	}
+	if req.SamplingRules != nil {
+		settings.SamplingRules = make([]domain.SamplingRule, len(req.SamplingRules))
+		for i, rule := range req.SamplingRules {
+			settings.SamplingRules[i] = domain.SamplingRule{
+				Actions:    rule.Actions,
+				Severities: rule.Severities,
+				Rate:       *rule.Rate,
+			}
+		}
+	}
+	if req.UserRateLimits != nil {
+		settings.UserRateLimits = make(map[string]int, len(req.UserRateLimits))
+		for route, limit := range req.UserRateLimits {
+			if limit > 0 {
+				settings.UserRateLimits[route] = limit
+			}
+		}
+	}
+	if req.IndexedMetadataKeys != nil {
+		settings.IndexedMetadataKeys = req.IndexedMetadataKeys
+	}
+	if storage := req.ArchiveStorage; storage != nil {
+		if storage.StorageClass != nil {
+			settings.ArchiveStorage.StorageClass = *storage.StorageClass
+		}
+		if storage.GlacierAfterDays != nil {
+			settings.ArchiveStorage.GlacierAfterDays = *storage.GlacierAfterDays
+		}
+		if storage.ObjectLockMode != nil {
+			settings.ArchiveStorage.ObjectLockMode = *storage.ObjectLockMode
+		}
+		if storage.ObjectLockDays != nil {
+			settings.ArchiveStorage.ObjectLockDays = *storage.ObjectLockDays
+		}
+	}
+	if encryption := req.Encryption; encryption != nil {
+		if encryption.KMSKeyARN != nil {
+			settings.Encryption.KMSKeyARN = *encryption.KMSKeyARN
+		}
+		if encryption.ExportPublicKey != nil {
+			if *encryption.ExportPublicKey != "" {
+				if _, err := envelope.ParsePublicKey(*encryption.ExportPublicKey); err != nil {
+					return nil, fmt.Errorf("%w: %v", ErrInvalidExportPublicKey, err)
+				}
+			}
+			settings.Encryption.ExportPublicKey = *encryption.ExportPublicKey
+		}
+	}
+	if req.RateLimit != nil {
+		tenant.RateLimit = *req.RateLimit
+	}
+	if req.RateLimitBurst != nil {
+		tenant.RateLimitBurst = *req.RateLimitBurst
+	}
+
+	if err := s.Update(ctx, tenant); err != nil {
+		return nil, fmt.Errorf("failed to update tenant settings: %w", err)
+	}
+
+	if err := s.settingsCache.Invalidate(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	if err := s.limitCache.Invalidate(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	return tenant, nil
+}
+
+// GetArchiveSchedule returns the tenant's archive schedule, or the default
+// schedule when the tenant doesn't override it
+func (s *TenantService) GetArchiveSchedule(ctx context.Context, tenantID string) (*domain.ArchiveSchedule, error) {
+	if _, err := s.getTenant(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	return s.getArchiveSchedule(ctx, tenantID)
+}
+
+// UpdateArchiveSchedule applies the given overrides, leaving the others and
+// the record of the last run unchanged. The archive scheduler picks them up on
+// its next check.
+func (s *TenantService) UpdateArchiveSchedule(ctx context.Context, tenantID string, req dto.UpdateArchiveScheduleRequest) (*domain.ArchiveSchedule, error) {
+	if _, err := s.getTenant(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	schedule, err := s.getArchiveSchedule(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Enabled != nil {
+		schedule.Enabled = *req.Enabled
+	}
+	if req.IntervalHours != nil {
+		schedule.IntervalHours = *req.IntervalHours
+	}
+	if req.RetentionDays != nil {
+		schedule.RetentionDays = *req.RetentionDays
+	}
+	schedule.UpdatedAt = time.Now()
+
+	if err := s.repo.ArchiveSchedule().Save(ctx, schedule); err != nil {
+		return nil, fmt.Errorf("failed to update archive schedule: %w", err)
+	}
+	return schedule, nil
+}
+
+// ResolveSettings returns the tenant's settings, reading through the cache.
+// Cache errors are not fatal: the settings are then loaded from the database.
+func (s *TenantService) ResolveSettings(ctx context.Context, tenantID string) (*domain.TenantSettings, error) {
+	if settings, err := s.settingsCache.Get(ctx, tenantID); err == nil && settings != nil {
+		return settings, nil
+	}
+
+	tenant, err := s.getTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.settingsCache.Set(ctx, tenantID, &tenant.Settings)
+	return &tenant.Settings, nil
+}
+
+// getArchiveSchedule loads a tenant's archive schedule, falling back to the default
+func (s *TenantService) getArchiveSchedule(ctx context.Context, tenantID string) (*domain.ArchiveSchedule, error) {
+	schedule, err := s.repo.ArchiveSchedule().GetByTenant(ctx, tenantID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.DefaultArchiveSchedule(tenantID), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get archive schedule: %w", err)
+	}
+	return schedule, nil
+}
+
+// getTenant loads a tenant, mapping a missing row to ErrTenantNotFound
+func (s *TenantService) getTenant(ctx context.Context, tenantID string) (*domain.Tenant, error) {
+	tenant, err := s.repo.Tenant().GetByID(ctx, tenantID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTenantNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	return tenant, nil
+}
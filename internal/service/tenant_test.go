@@ -2,30 +2,44 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/config"
 	"github.com/kingrain94/audit-log-api/internal/domain"
 	"github.com/kingrain94/audit-log-api/internal/mocks"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
 )
 
 type TenantServiceTestSuite struct {
 	suite.Suite
-	mockRepo   *mocks.Repository
-	mockTenant *mocks.TenantRepository
-	service    *TenantService
+	mockRepo          *mocks.Repository
+	mockTenant        *mocks.TenantRepository
+	mockCache         *mocks.RateLimitCache
+	mockSettingsCache *mocks.TenantSettingsCache
+	service           *TenantService
 }
 
 func (s *TenantServiceTestSuite) SetupTest() {
 	s.mockRepo = new(mocks.Repository)
 	s.mockTenant = new(mocks.TenantRepository)
+	s.mockCache = new(mocks.RateLimitCache)
+	s.mockSettingsCache = new(mocks.TenantSettingsCache)
 
 	s.mockRepo.On("Tenant").Return(s.mockTenant)
 
-	s.service = NewTenantService(s.mockRepo)
+	s.service = NewTenantService(s.mockRepo, s.mockCache, s.mockSettingsCache, &config.TenantDeletionConfig{
+		GracePeriod:   30 * 24 * time.Hour,
+		PurgeInterval: time.Hour,
+	})
 }
 
 func TestTenantService(t *testing.T) {
@@ -108,14 +122,94 @@ func (s *TenantServiceTestSuite) TestDelete_Success() {
 	ctx := context.Background()
 	tenantID := "tenant1"
 
-	s.mockTenant.On("Delete", ctx, tenantID).Return(nil)
+	deletedAt := time.Now()
+	purgeAfter := deletedAt.Add(30 * 24 * time.Hour)
+	deleted := &domain.Tenant{ID: tenantID, DeletedAt: &deletedAt, PurgeAfter: &purgeAfter}
+
+	s.mockTenant.On("Delete", ctx, tenantID, mock.MatchedBy(func(after time.Time) bool {
+		return after.Sub(deletedAt) > 29*24*time.Hour
+	})).Return(nil)
+	s.mockTenant.On("GetDeleted", ctx, tenantID).Return(deleted, nil)
+	s.mockCache.On("Invalidate", ctx, tenantID).Return(nil)
+	s.mockSettingsCache.On("Invalidate", ctx, tenantID).Return(nil)
 
 	// Act
-	err := s.service.Delete(ctx, tenantID)
+	tenant, err := s.service.Delete(ctx, tenantID)
 
 	// Assert
 	s.NoError(err)
+	s.Equal(deleted, tenant)
 	s.mockTenant.AssertExpectations(s.T())
+	s.mockCache.AssertExpectations(s.T())
+}
+
+func (s *TenantServiceTestSuite) TestDelete_NotFound() {
+	// Arrange
+	ctx := context.Background()
+	s.mockTenant.On("Delete", ctx, "missing", mock.Anything).Return(gorm.ErrRecordNotFound)
+
+	// Act
+	tenant, err := s.service.Delete(ctx, "missing")
+
+	// Assert
+	s.ErrorIs(err, ErrTenantNotFound)
+	s.Nil(tenant)
+}
+
+func (s *TenantServiceTestSuite) TestDelete_SystemTenant() {
+	// Act
+	tenant, err := s.service.Delete(context.Background(), domain.SystemTenantID)
+
+	// Assert
+	s.ErrorIs(err, ErrSystemTenant)
+	s.Nil(tenant)
+	s.mockTenant.AssertNotCalled(s.T(), "Delete", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *TenantServiceTestSuite) TestRestore_WithinGracePeriod() {
+	// Arrange
+	ctx := context.Background()
+	deletedAt := time.Now().Add(-time.Hour)
+	purgeAfter := time.Now().Add(time.Hour)
+	s.mockTenant.On("GetDeleted", ctx, "tenant1").Return(&domain.Tenant{ID: "tenant1", DeletedAt: &deletedAt, PurgeAfter: &purgeAfter}, nil)
+	s.mockTenant.On("Restore", ctx, "tenant1").Return(nil)
+
+	// Act
+	tenant, err := s.service.Restore(ctx, "tenant1")
+
+	// Assert
+	s.NoError(err)
+	s.Nil(tenant.DeletedAt)
+	s.Nil(tenant.PurgeAfter)
+	s.mockTenant.AssertExpectations(s.T())
+}
+
+func (s *TenantServiceTestSuite) TestRestore_AfterGracePeriod() {
+	// Arrange
+	ctx := context.Background()
+	deletedAt := time.Now().Add(-48 * time.Hour)
+	purgeAfter := time.Now().Add(-time.Hour)
+	s.mockTenant.On("GetDeleted", ctx, "tenant1").Return(&domain.Tenant{ID: "tenant1", DeletedAt: &deletedAt, PurgeAfter: &purgeAfter}, nil)
+
+	// Act
+	_, err := s.service.Restore(ctx, "tenant1")
+
+	// Assert
+	s.ErrorIs(err, ErrTenantPurged)
+	s.mockTenant.AssertNotCalled(s.T(), "Restore", mock.Anything, mock.Anything)
+}
+
+func (s *TenantServiceTestSuite) TestRestore_NotDeleted() {
+	// Arrange
+	ctx := context.Background()
+	s.mockTenant.On("GetDeleted", ctx, "tenant1").Return(nil, gorm.ErrRecordNotFound)
+	s.mockTenant.On("GetByID", ctx, "tenant1").Return(&domain.Tenant{ID: "tenant1"}, nil)
+
+	// Act
+	_, err := s.service.Restore(ctx, "tenant1")
+
+	// Assert
+	s.ErrorIs(err, ErrTenantNotDeleted)
 }
 
 func (s *TenantServiceTestSuite) TestList_Success() {
@@ -150,3 +244,377 @@ func (s *TenantServiceTestSuite) TestList_Success() {
 	s.Equal(expectedTenants[1].Name, tenants[1].Name)
 	s.mockTenant.AssertExpectations(s.T())
 }
+
+func (s *TenantServiceTestSuite) TestGetRateLimit_CacheHit() {
+	// Arrange
+	ctx := context.Background()
+	cached := &domain.TenantRateLimit{TenantID: "tenant1", Limit: 500, Burst: 50}
+
+	s.mockCache.On("Get", ctx, "tenant1").Return(cached, nil)
+
+	// Act
+	limit, err := s.service.GetRateLimit(ctx, "tenant1")
+
+	// Assert
+	s.NoError(err)
+	s.Equal(cached, limit)
+	s.mockTenant.AssertNotCalled(s.T(), "GetByID", mock.Anything, mock.Anything)
+}
+
+func (s *TenantServiceTestSuite) TestGetRateLimit_CacheMiss_LoadsFromDatabase() {
+	// Arrange
+	ctx := context.Background()
+	tenant := &domain.Tenant{ID: "tenant1", RateLimit: 2000, RateLimitBurst: 100}
+	expected := &domain.TenantRateLimit{TenantID: "tenant1", Limit: 2000, Burst: 100}
+
+	s.mockCache.On("Get", ctx, "tenant1").Return(nil, nil)
+	s.mockTenant.On("GetByID", ctx, "tenant1").Return(tenant, nil)
+	s.mockCache.On("Set", ctx, expected).Return(nil)
+
+	// Act
+	limit, err := s.service.GetRateLimit(ctx, "tenant1")
+
+	// Assert
+	s.NoError(err)
+	s.Equal(expected, limit)
+	s.mockCache.AssertExpectations(s.T())
+	s.mockTenant.AssertExpectations(s.T())
+}
+
+func (s *TenantServiceTestSuite) TestUpdateRateLimit_InvalidatesCache() {
+	// Arrange
+	ctx := context.Background()
+	tenant := &domain.Tenant{ID: "tenant1", RateLimit: 1000}
+	req := dto.UpdateTenantRateLimitRequest{RateLimit: 5000, Burst: 500}
+
+	s.mockTenant.On("GetByID", ctx, "tenant1").Return(tenant, nil)
+	s.mockTenant.On("Update", ctx, mock.MatchedBy(func(t *domain.Tenant) bool {
+		return t.RateLimit == 5000 && t.RateLimitBurst == 500
+	})).Return(nil)
+	s.mockCache.On("Invalidate", ctx, "tenant1").Return(nil)
+
+	// Act
+	limit, err := s.service.UpdateRateLimit(ctx, "tenant1", req)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(5500, limit.Allowance())
+	s.mockTenant.AssertExpectations(s.T())
+	s.mockCache.AssertExpectations(s.T())
+}
+
+func (s *TenantServiceTestSuite) TestUpdateRateLimit_TenantNotFound() {
+	// Arrange
+	ctx := context.Background()
+
+	s.mockTenant.On("GetByID", ctx, "missing").Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	_, err := s.service.UpdateRateLimit(ctx, "missing", dto.UpdateTenantRateLimitRequest{RateLimit: 10})
+
+	// Assert
+	s.True(errors.Is(err, ErrTenantNotFound))
+	s.mockCache.AssertNotCalled(s.T(), "Invalidate", mock.Anything, mock.Anything)
+}
+
+func (s *TenantServiceTestSuite) TestUpdateSettings_KeepsUnsetFieldsAndInvalidatesCaches() {
+	// Arrange
+	ctx := context.Background()
+	tenant := &domain.Tenant{
+		ID:        "tenant1",
+		RateLimit: 1000,
+		Settings: domain.TenantSettings{
+			RetentionDays:       30,
+			DataResidencyRegion: "eu-west-1",
+		},
+	}
+	retentionDays := 90
+	req := dto.UpdateTenantSettingsRequest{
+		RetentionDays:  &retentionDays,
+		AllowedActions: []string{"CREATE", "DELETE"},
+	}
+
+	s.mockTenant.On("GetByID", ctx, "tenant1").Return(tenant, nil)
+	s.mockTenant.On("Update", ctx, mock.MatchedBy(func(t *domain.Tenant) bool {
+		return t.Settings.RetentionDays == 90 && t.Settings.DataResidencyRegion == "eu-west-1" && t.RateLimit == 1000
+	})).Return(nil)
+	s.mockSettingsCache.On("Invalidate", ctx, "tenant1").Return(nil)
+	s.mockCache.On("Invalidate", ctx, "tenant1").Return(nil)
+
+	// Act
+	updated, err := s.service.UpdateSettings(ctx, "tenant1", req)
+
+	// Assert
+	s.NoError(err)
+	s.Equal([]string{"CREATE", "DELETE"}, updated.Settings.AllowedActions)
+	s.True(updated.Settings.AllowsAction("DELETE"))
+	s.False(updated.Settings.AllowsAction("VIEW"))
+	s.mockTenant.AssertExpectations(s.T())
+	s.mockSettingsCache.AssertExpectations(s.T())
+	s.mockCache.AssertExpectations(s.T())
+}
+
+func (s *TenantServiceTestSuite) TestUpdateSettings_WebhookURLMustBePublicHTTPS() {
+	// Arrange
+	ctx := context.Background()
+	s.mockTenant.On("GetByID", ctx, "tenant1").Return(&domain.Tenant{ID: "tenant1"}, nil)
+
+	for _, webhookURL := range []string{
+		"http://hooks.example.com/audit",
+		"https://169.254.169.254/latest/meta-data",
+		"https://10.0.0.5:9200",
+		"https://[::1]/hook",
+		"https://localhost/hook",
+	} {
+		// Act
+		_, err := s.service.UpdateSettings(ctx, "tenant1", dto.UpdateTenantSettingsRequest{WebhookURL: &webhookURL})
+
+		// Assert
+		s.ErrorIs(err, ErrInvalidWebhookURL, webhookURL)
+	}
+	s.mockTenant.AssertNotCalled(s.T(), "Update", mock.Anything, mock.Anything)
+}
+
+func (s *TenantServiceTestSuite) TestGetArchiveSchedule_DefaultsWithoutOverrides() {
+	// Arrange
+	ctx := context.Background()
+	mockSchedule := new(mocks.ArchiveScheduleRepository)
+	s.mockRepo.On("ArchiveSchedule").Return(mockSchedule)
+	s.mockTenant.On("GetByID", ctx, "tenant1").Return(&domain.Tenant{ID: "tenant1"}, nil)
+	mockSchedule.On("GetByTenant", ctx, "tenant1").Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	schedule, err := s.service.GetArchiveSchedule(ctx, "tenant1")
+
+	// Assert
+	s.NoError(err)
+	s.Equal("tenant1", schedule.TenantID)
+	s.True(schedule.Enabled)
+	s.Zero(schedule.IntervalHours)
+	s.Zero(schedule.RetentionDays)
+	mockSchedule.AssertExpectations(s.T())
+}
+
+func (s *TenantServiceTestSuite) TestUpdateArchiveSchedule_KeepsUnsetOverridesAndLastRun() {
+	// Arrange
+	ctx := context.Background()
+	mockSchedule := new(mocks.ArchiveScheduleRepository)
+	s.mockRepo.On("ArchiveSchedule").Return(mockSchedule)
+	lastRunAt := time.Now().Add(-time.Hour)
+	existing := &domain.ArchiveSchedule{TenantID: "tenant1", Enabled: true, IntervalHours: 12, LastRunAt: &lastRunAt}
+	enabled := false
+	req := dto.UpdateArchiveScheduleRequest{Enabled: &enabled}
+
+	s.mockTenant.On("GetByID", ctx, "tenant1").Return(&domain.Tenant{ID: "tenant1"}, nil)
+	mockSchedule.On("GetByTenant", ctx, "tenant1").Return(existing, nil)
+	mockSchedule.On("Save", ctx, mock.MatchedBy(func(schedule *domain.ArchiveSchedule) bool {
+		return !schedule.Enabled && schedule.IntervalHours == 12
+	})).Return(nil)
+
+	// Act
+	schedule, err := s.service.UpdateArchiveSchedule(ctx, "tenant1", req)
+
+	// Assert
+	s.NoError(err)
+	s.False(schedule.Enabled)
+	s.Equal(&lastRunAt, schedule.LastRunAt)
+	mockSchedule.AssertExpectations(s.T())
+}
+
+func (s *TenantServiceTestSuite) TestUpdateArchiveSchedule_TenantNotFound() {
+	// Arrange
+	ctx := context.Background()
+	enabled := false
+	s.mockTenant.On("GetByID", ctx, "missing").Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	schedule, err := s.service.UpdateArchiveSchedule(ctx, "missing", dto.UpdateArchiveScheduleRequest{Enabled: &enabled})
+
+	// Assert
+	s.Nil(schedule)
+	s.True(errors.Is(err, ErrTenantNotFound))
+	s.mockRepo.AssertNotCalled(s.T(), "ArchiveSchedule")
+}
+
+func (s *TenantServiceTestSuite) TestUpdateSettings_CustomActionsExtendBuiltIns() {
+	// Arrange
+	ctx := context.Background()
+	tenant := &domain.Tenant{ID: "tenant1"}
+	req := dto.UpdateTenantSettingsRequest{CustomActions: []string{"LOGIN"}}
+
+	s.mockTenant.On("GetByID", ctx, "tenant1").Return(tenant, nil)
+	s.mockTenant.On("Update", ctx, mock.AnythingOfType("*domain.Tenant")).Return(nil)
+	s.mockSettingsCache.On("Invalidate", ctx, "tenant1").Return(nil)
+	s.mockCache.On("Invalidate", ctx, "tenant1").Return(nil)
+
+	// Act
+	updated, err := s.service.UpdateSettings(ctx, "tenant1", req)
+
+	// Assert
+	s.NoError(err)
+	s.True(updated.Settings.KnowsAction("LOGIN"))
+	s.True(updated.Settings.KnowsAction("update"))
+	s.False(updated.Settings.KnowsAction("LOGOUT"))
+}
+
+func (s *TenantServiceTestSuite) TestUpdateSettings_SamplingRulesFirstMatchApplies() {
+	// Arrange
+	ctx := context.Background()
+	tenant := &domain.Tenant{ID: "tenant1"}
+	keepErrors, sampleViews := 1.0, 0.01
+	req := dto.UpdateTenantSettingsRequest{SamplingRules: []dto.SamplingRuleRequest{
+		{Severities: []string{"ERROR", "CRITICAL"}, Rate: &keepErrors},
+		{Actions: []string{"VIEW"}, Rate: &sampleViews},
+	}}
+
+	s.mockTenant.On("GetByID", ctx, "tenant1").Return(tenant, nil)
+	s.mockTenant.On("Update", ctx, mock.AnythingOfType("*domain.Tenant")).Return(nil)
+	s.mockSettingsCache.On("Invalidate", ctx, "tenant1").Return(nil)
+	s.mockCache.On("Invalidate", ctx, "tenant1").Return(nil)
+
+	// Act
+	updated, err := s.service.UpdateSettings(ctx, "tenant1", req)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(1.0, updated.Settings.SampleRate("VIEW", "ERROR"))
+	s.Equal(0.01, updated.Settings.SampleRate("VIEW", "INFO"))
+	s.Equal(1.0, updated.Settings.SampleRate("CREATE", "INFO"))
+}
+
+func (s *TenantServiceTestSuite) TestUpdateSettings_UserRateLimitsReplaceCaps() {
+	// Arrange
+	ctx := context.Background()
+	tenant := &domain.Tenant{ID: "tenant1", Settings: domain.TenantSettings{UserRateLimits: map[string]int{"query": 60}}}
+	req := dto.UpdateTenantSettingsRequest{UserRateLimits: map[string]int{"ingest": 100, "query": 0}}
+
+	s.mockTenant.On("GetByID", ctx, "tenant1").Return(tenant, nil)
+	s.mockTenant.On("Update", ctx, mock.AnythingOfType("*domain.Tenant")).Return(nil)
+	s.mockSettingsCache.On("Invalidate", ctx, "tenant1").Return(nil)
+	s.mockCache.On("Invalidate", ctx, "tenant1").Return(nil)
+
+	// Act
+	updated, err := s.service.UpdateSettings(ctx, "tenant1", req)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(100, updated.Settings.UserRateLimit("ingest"))
+	s.Equal(0, updated.Settings.UserRateLimit("query"))
+	s.Equal(map[string]int{"ingest": 100}, updated.Settings.UserRateLimits)
+}
+
+func (s *TenantServiceTestSuite) TestUpdateSettings_IndexedMetadataKeys() {
+	// Arrange
+	ctx := context.Background()
+	tenant := &domain.Tenant{ID: "tenant1", Settings: domain.TenantSettings{IndexedMetadataKeys: []string{"region"}, RetentionDays: 30}}
+	req := dto.UpdateTenantSettingsRequest{IndexedMetadataKeys: []string{"order_id", "customer_id"}}
+
+	s.mockTenant.On("GetByID", ctx, "tenant1").Return(tenant, nil)
+	s.mockTenant.On("Update", ctx, mock.AnythingOfType("*domain.Tenant")).Return(nil)
+	s.mockSettingsCache.On("Invalidate", ctx, "tenant1").Return(nil)
+	s.mockCache.On("Invalidate", ctx, "tenant1").Return(nil)
+
+	// Act
+	updated, err := s.service.UpdateSettings(ctx, "tenant1", req)
+
+	// Assert
+	s.NoError(err)
+	s.Equal([]string{"order_id", "customer_id"}, updated.Settings.IndexedMetadataKeys)
+	s.Equal(30, updated.Settings.RetentionDays)
+}
+
+func (s *TenantServiceTestSuite) TestUpdateSettings_ArchiveStorageKeepsUnsetFields() {
+	// Arrange
+	ctx := context.Background()
+	tenant := &domain.Tenant{ID: "tenant1", Settings: domain.TenantSettings{
+		ArchiveStorage: domain.ArchiveStorage{StorageClass: "STANDARD_IA", GlacierAfterDays: 30},
+	}}
+	mode := "COMPLIANCE"
+	lockDays := 2555
+	glacierAfterDays := 0
+	req := dto.UpdateTenantSettingsRequest{ArchiveStorage: &dto.ArchiveStorageRequest{
+		GlacierAfterDays: &glacierAfterDays,
+		ObjectLockMode:   &mode,
+		ObjectLockDays:   &lockDays,
+	}}
+
+	s.mockTenant.On("GetByID", ctx, "tenant1").Return(tenant, nil)
+	s.mockTenant.On("Update", ctx, mock.AnythingOfType("*domain.Tenant")).Return(nil)
+	s.mockSettingsCache.On("Invalidate", ctx, "tenant1").Return(nil)
+	s.mockCache.On("Invalidate", ctx, "tenant1").Return(nil)
+
+	// Act
+	updated, err := s.service.UpdateSettings(ctx, "tenant1", req)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(domain.ArchiveStorage{
+		StorageClass:   "STANDARD_IA",
+		ObjectLockMode: "COMPLIANCE",
+		ObjectLockDays: 2555,
+	}, updated.Settings.ArchiveStorage)
+}
+
+func (s *TenantServiceTestSuite) TestUpdateSettings_Encryption() {
+	// Arrange
+	ctx := context.Background()
+	tenant := &domain.Tenant{ID: "tenant1"}
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	s.Require().NoError(err)
+	publicKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	keyARN := "arn:aws:kms:eu-west-1:111122223333:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+	req := dto.UpdateTenantSettingsRequest{Encryption: &dto.EncryptionRequest{
+		KMSKeyARN:       &keyARN,
+		ExportPublicKey: &publicKey,
+	}}
+
+	s.mockTenant.On("GetByID", ctx, "tenant1").Return(tenant, nil)
+	s.mockTenant.On("Update", ctx, mock.AnythingOfType("*domain.Tenant")).Return(nil)
+	s.mockSettingsCache.On("Invalidate", ctx, "tenant1").Return(nil)
+	s.mockCache.On("Invalidate", ctx, "tenant1").Return(nil)
+
+	// Act
+	updated, err := s.service.UpdateSettings(ctx, "tenant1", req)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(domain.Encryption{KMSKeyARN: keyARN, ExportPublicKey: publicKey}, updated.Settings.Encryption)
+}
+
+func (s *TenantServiceTestSuite) TestUpdateSettings_InvalidExportPublicKey() {
+	// Arrange
+	ctx := context.Background()
+	tenant := &domain.Tenant{ID: "tenant1"}
+	publicKey := "-----BEGIN PUBLIC KEY-----\nnot a key\n-----END PUBLIC KEY-----\n"
+	req := dto.UpdateTenantSettingsRequest{Encryption: &dto.EncryptionRequest{ExportPublicKey: &publicKey}}
+
+	s.mockTenant.On("GetByID", ctx, "tenant1").Return(tenant, nil)
+
+	// Act
+	updated, err := s.service.UpdateSettings(ctx, "tenant1", req)
+
+	// Assert
+	s.ErrorIs(err, ErrInvalidExportPublicKey)
+	s.Nil(updated)
+	s.mockTenant.AssertNotCalled(s.T(), "Update", mock.Anything, mock.Anything)
+}
+
+func (s *TenantServiceTestSuite) TestResolveSettings_CacheMiss_LoadsAndCaches() {
+	// Arrange
+	ctx := context.Background()
+	tenant := &domain.Tenant{ID: "tenant1", Settings: domain.TenantSettings{AllowedActions: []string{"CREATE"}}}
+
+	s.mockSettingsCache.On("Get", ctx, "tenant1").Return(nil, nil)
+	s.mockTenant.On("GetByID", ctx, "tenant1").Return(tenant, nil)
+	s.mockSettingsCache.On("Set", ctx, "tenant1", &tenant.Settings).Return(nil)
+
+	// Act
+	settings, err := s.service.ResolveSettings(ctx, "tenant1")
+
+	// Assert
+	s.NoError(err)
+	s.Equal([]string{"CREATE"}, settings.AllowedActions)
+	s.mockSettingsCache.AssertExpectations(s.T())
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"net/netip"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+)
+
+// maxReputationLookups bounds the lookups of a batch's addresses in flight
+const maxReputationLookups = 8
+
+// IPReputationProvider scores the threat an IP address poses
+//
+//go:generate mockery --name IPReputationProvider --output ../mocks
+type IPReputationProvider interface {
+	Name() string
+	// Score returns the threat score of ip, from 0 when nothing is known
+	// against it to 100
+	Score(ctx context.Context, ip netip.Addr) (int, error)
+}
+
+// ThreatIntelService enriches ingested logs with the threat score of their
+// IP address, the highest score of its providers, and raises the severity of
+// logs from the worst addresses
+type ThreatIntelService struct {
+	providers          []IPReputationProvider
+	minScore           int
+	raiseSeverityScore int
+	raisedSeverity     domain.SeverityLevel
+}
+
+func NewThreatIntelService(providers []IPReputationProvider, cfg *config.ThreatIntelConfig) *ThreatIntelService {
+	return &ThreatIntelService{
+		providers:          providers,
+		minScore:           cfg.MinScore,
+		raiseSeverityScore: cfg.RaiseSeverityScore,
+		raisedSeverity:     domain.SeverityLevel(cfg.RaisedSeverity),
+	}
+}
+
+// Enrich records in place the threat score of the logs whose address scores
+// at least the minimum score under domain.ThreatScoreKey in their metadata,
+// raising the severity of those scoring at least the raise severity score.
+// Failed lookups leave logs unscored rather than hold back their ingestion.
+func (s *ThreatIntelService) Enrich(ctx context.Context, logs []domain.AuditLog) {
+	ctx, span := tracing.Start(ctx, "ThreatIntelService.Enrich", trace.WithAttributes(attribute.Int("audit_log.count", len(logs))))
+	defer span.End()
+
+	var ips []netip.Addr
+	seen := make(map[netip.Addr]bool)
+	for i := range logs {
+		if ip, ok := lookupAddr(logs[i].IPAddress); ok && !seen[ip] {
+			seen[ip] = true
+			ips = append(ips, ip)
+		}
+	}
+	if len(ips) == 0 {
+		return
+	}
+
+	scores := s.scores(ctx, ips)
+	for i := range logs {
+		ip, ok := lookupAddr(logs[i].IPAddress)
+		if !ok || scores[ip] < s.minScore || !logs[i].SetThreatScore(scores[ip]) {
+			continue
+		}
+		metrics.ThreatScoredLogsTotal.WithLabelValues(logs[i].TenantID).Inc()
+		if s.raiseSeverityScore > 0 && scores[ip] >= s.raiseSeverityScore {
+			logs[i].RaiseSeverity(s.raisedSeverity)
+		}
+	}
+}
+
+// scores looks every address up with every provider, keeping the highest
+// score of each address
+func (s *ThreatIntelService) scores(ctx context.Context, ips []netip.Addr) map[netip.Addr]int {
+	var (
+		mu        sync.Mutex
+		waitGroup sync.WaitGroup
+	)
+	scores := make(map[netip.Addr]int, len(ips))
+	inFlight := make(chan struct{}, maxReputationLookups)
+	for _, ip := range ips {
+		for _, provider := range s.providers {
+			waitGroup.Add(1)
+			inFlight <- struct{}{}
+			go func() {
+				defer func() {
+					<-inFlight
+					waitGroup.Done()
+				}()
+
+				score, err := provider.Score(ctx, ip)
+				metrics.IPReputationLookupsTotal.WithLabelValues(provider.Name(), lookupResult(score, err)).Inc()
+				if err != nil {
+					return
+				}
+				mu.Lock()
+				scores[ip] = max(scores[ip], score)
+				mu.Unlock()
+			}()
+		}
+	}
+	waitGroup.Wait()
+	return scores
+}
+
+// lookupAddr parses the IP address of a log, reporting false for addresses
+// no provider knows of, such as private and loopback ones
+func lookupAddr(value string) (netip.Addr, bool) {
+	ip, err := netip.ParseAddr(strings.TrimSpace(value))
+	if err != nil {
+		return ip, false
+	}
+	ip = ip.Unmap()
+	return ip, ip.IsGlobalUnicast() && !ip.IsPrivate()
+}
+
+func lookupResult(score int, err error) string {
+	switch {
+	case err != nil:
+		return "error"
+	case score > 0:
+		return "listed"
+	default:
+		return "clean"
+	}
+}
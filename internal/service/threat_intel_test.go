@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"testing"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ThreatIntelServiceTestSuite struct {
+	suite.Suite
+	mockBlocklist *mocks.IPReputationProvider
+	mockAbuseIPDB *mocks.IPReputationProvider
+	config        *config.ThreatIntelConfig
+}
+
+func (s *ThreatIntelServiceTestSuite) SetupTest() {
+	s.mockBlocklist = new(mocks.IPReputationProvider)
+	s.mockAbuseIPDB = new(mocks.IPReputationProvider)
+	s.mockBlocklist.On("Name").Return("blocklist")
+	s.mockAbuseIPDB.On("Name").Return("abuseipdb")
+
+	s.config = &config.ThreatIntelConfig{MinScore: 25, RaisedSeverity: "WARNING"}
+}
+
+func (s *ThreatIntelServiceTestSuite) newService() *ThreatIntelService {
+	return NewThreatIntelService([]IPReputationProvider{s.mockBlocklist, s.mockAbuseIPDB}, s.config)
+}
+
+func TestThreatIntelService(t *testing.T) {
+	suite.Run(t, new(ThreatIntelServiceTestSuite))
+}
+
+func (s *ThreatIntelServiceTestSuite) TestEnrich_RecordsHighestScore() {
+	// Arrange
+	ctx := context.Background()
+	bad := netip.MustParseAddr("203.0.113.7")
+	s.mockBlocklist.On("Score", mock.Anything, bad).Return(100, nil).Once()
+	s.mockAbuseIPDB.On("Score", mock.Anything, bad).Return(40, nil).Once()
+
+	logs := []domain.AuditLog{
+		{TenantID: "tenant1", IPAddress: "203.0.113.7", Severity: "INFO", Metadata: json.RawMessage(`{"browser":"firefox"}`)},
+		{TenantID: "tenant1", IPAddress: "203.0.113.7", Severity: "INFO"},
+	}
+
+	// Act
+	s.newService().Enrich(ctx, logs)
+
+	// Assert
+	s.JSONEq(`{"browser":"firefox","threat_score":100}`, string(logs[0].Metadata))
+	s.JSONEq(`{"threat_score":100}`, string(logs[1].Metadata))
+	s.Equal("INFO", logs[0].Severity)
+	s.mockBlocklist.AssertExpectations(s.T())
+	s.mockAbuseIPDB.AssertExpectations(s.T())
+}
+
+func (s *ThreatIntelServiceTestSuite) TestEnrich_BelowMinScore_LeavesLogUnscored() {
+	// Arrange
+	ctx := context.Background()
+	ip := netip.MustParseAddr("198.51.100.20")
+	s.mockBlocklist.On("Score", mock.Anything, ip).Return(0, nil)
+	s.mockAbuseIPDB.On("Score", mock.Anything, ip).Return(10, nil)
+
+	logs := []domain.AuditLog{{TenantID: "tenant1", IPAddress: "198.51.100.20", Severity: "INFO"}}
+
+	// Act
+	s.newService().Enrich(ctx, logs)
+
+	// Assert
+	s.Empty(logs[0].Metadata)
+	s.Zero(logs[0].ThreatScore())
+}
+
+func (s *ThreatIntelServiceTestSuite) TestEnrich_RaisesSeverity() {
+	// Arrange
+	ctx := context.Background()
+	s.config.RaiseSeverityScore = 75
+	s.config.RaisedSeverity = "ERROR"
+	bad := netip.MustParseAddr("203.0.113.7")
+	s.mockBlocklist.On("Score", mock.Anything, bad).Return(100, nil)
+	s.mockAbuseIPDB.On("Score", mock.Anything, bad).Return(0, nil)
+	suspicious := netip.MustParseAddr("198.51.100.20")
+	s.mockBlocklist.On("Score", mock.Anything, suspicious).Return(0, nil)
+	s.mockAbuseIPDB.On("Score", mock.Anything, suspicious).Return(50, nil)
+
+	logs := []domain.AuditLog{
+		{TenantID: "tenant1", IPAddress: "203.0.113.7", Severity: "info"},
+		{TenantID: "tenant1", IPAddress: "203.0.113.7", Severity: "CRITICAL"},
+		{TenantID: "tenant1", IPAddress: "198.51.100.20", Severity: "INFO"},
+	}
+
+	// Act
+	s.newService().Enrich(ctx, logs)
+
+	// Assert
+	s.Equal("ERROR", logs[0].Severity)
+	s.Equal("CRITICAL", logs[1].Severity)
+	s.Equal("INFO", logs[2].Severity)
+	s.Equal(50, logs[2].ThreatScore())
+}
+
+func (s *ThreatIntelServiceTestSuite) TestEnrich_ProviderFails_KeepsOtherScores() {
+	// Arrange
+	ctx := context.Background()
+	bad := netip.MustParseAddr("203.0.113.7")
+	s.mockBlocklist.On("Score", mock.Anything, bad).Return(100, nil)
+	s.mockAbuseIPDB.On("Score", mock.Anything, bad).Return(0, errors.New("rate limited"))
+
+	logs := []domain.AuditLog{{TenantID: "tenant1", IPAddress: "::ffff:203.0.113.7", Severity: "INFO"}}
+
+	// Act
+	s.newService().Enrich(ctx, logs)
+
+	// Assert
+	s.Equal(100, logs[0].ThreatScore())
+}
+
+func (s *ThreatIntelServiceTestSuite) TestEnrich_SkipsPrivateAndInvalidAddresses() {
+	// Arrange
+	ctx := context.Background()
+	logs := []domain.AuditLog{
+		{TenantID: "tenant1", IPAddress: "10.0.0.1"},
+		{TenantID: "tenant1", IPAddress: "127.0.0.1"},
+		{TenantID: "tenant1", IPAddress: "not-an-ip"},
+		{TenantID: "tenant1"},
+	}
+
+	// Act
+	s.newService().Enrich(ctx, logs)
+
+	// Assert
+	s.mockBlocklist.AssertNotCalled(s.T(), "Score", mock.Anything, mock.Anything)
+	s.mockAbuseIPDB.AssertNotCalled(s.T(), "Score", mock.Anything, mock.Anything)
+	for _, log := range logs {
+		s.Empty(log.Metadata)
+	}
+}
+
+func (s *ThreatIntelServiceTestSuite) TestEnrich_MetadataNotAnObject_LeftAsIs() {
+	// Arrange
+	ctx := context.Background()
+	bad := netip.MustParseAddr("203.0.113.7")
+	s.mockBlocklist.On("Score", mock.Anything, bad).Return(100, nil)
+	s.mockAbuseIPDB.On("Score", mock.Anything, bad).Return(0, nil)
+	s.config.RaiseSeverityScore = 50
+
+	logs := []domain.AuditLog{{TenantID: "tenant1", IPAddress: "203.0.113.7", Severity: "INFO", Metadata: json.RawMessage(`["a","b"]`)}}
+
+	// Act
+	s.newService().Enrich(ctx, logs)
+
+	// Assert
+	s.Equal(`["a","b"]`, string(logs[0].Metadata))
+	s.Equal("INFO", logs[0].Severity)
+}
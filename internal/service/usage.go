@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+)
+
+// maxUsageRange bounds the days read for one usage report
+const maxUsageRange = 400 * 24 * time.Hour
+
+//go:generate mockery --name UsageStore --output ../mocks
+type UsageStore interface {
+	Add(ctx context.Context, tenantID string, day time.Time, logs, bytes int64) error
+	Days(ctx context.Context, tenantID string, from, to time.Time) ([]domain.TenantUsage, error)
+	MarkQuotaWarned(ctx context.Context, tenantID string, quota domain.Quota, period string) (bool, error)
+	UnmarkQuotaWarned(ctx context.Context, tenantID string, quota domain.Quota, period string) error
+}
+
+// QuotaNotifier tells a tenant its usage is nearing a quota
+//
+//go:generate mockery --name QuotaNotifier --output ../mocks
+type QuotaNotifier interface {
+	NotifyQuotaWarning(ctx context.Context, warning domain.QuotaWarning) error
+}
+
+// UsageService meters the logs and bytes tenants ingest, for billing and to
+// enforce the configured quotas
+type UsageService struct {
+	store    UsageStore
+	config   *config.QuotaConfig
+	notifier QuotaNotifier
+	now      func() time.Time
+}
+
+func NewUsageService(store UsageStore, config *config.QuotaConfig) *UsageService {
+	return &UsageService{
+		store:  store,
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// UseQuotaWarnings makes CheckQuota warn tenants through notifier once their
+// usage reaches the configured share of a quota
+func (s *UsageService) UseQuotaWarnings(notifier QuotaNotifier) {
+	s.notifier = notifier
+}
+
+// CheckQuota returns ErrDailyQuotaExceeded or ErrMonthlyQuotaExceeded if
+// ingesting that many more logs would exceed a quota of the tenant. Usage that
+// can't be read doesn't block ingestion. The system tenant is never capped, so
+// meta-audit logs aren't lost to a quota. Within its quotas, the tenant is
+// warned once per quota period when its usage reaches the warning share.
+func (s *UsageService) CheckQuota(ctx context.Context, tenantID string, logs int64) error {
+	if !s.config.Enabled() || tenantID == domain.SystemTenantID {
+		return nil
+	}
+
+	now := s.now().UTC()
+	days, err := s.store.Days(ctx, tenantID, startOfMonth(now), now)
+	if err != nil || len(days) == 0 {
+		return nil
+	}
+
+	today := days[len(days)-1]
+	var month domain.TenantUsage
+	for _, day := range days {
+		month.Logs += day.Logs
+		month.Bytes += day.Bytes
+	}
+
+	switch {
+	case s.config.MonthlyLogs > 0 && month.Logs+logs > s.config.MonthlyLogs:
+		metrics.QuotaRejectionsTotal.WithLabelValues(tenantID, "monthly_logs").Inc()
+		return ErrMonthlyQuotaExceeded
+	case s.config.MonthlyBytes > 0 && month.Bytes >= s.config.MonthlyBytes:
+		metrics.QuotaRejectionsTotal.WithLabelValues(tenantID, "monthly_bytes").Inc()
+		return ErrMonthlyQuotaExceeded
+	case s.config.DailyLogs > 0 && today.Logs+logs > s.config.DailyLogs:
+		metrics.QuotaRejectionsTotal.WithLabelValues(tenantID, "daily_logs").Inc()
+		return ErrDailyQuotaExceeded
+	}
+
+	s.warn(ctx, tenantID, now, today.Logs+logs, month.Logs+logs, month.Bytes)
+	return nil
+}
+
+// warn notifies the tenant of each quota its usage reached the warning share
+// of, unless it was already warned this period. Warnings that can't be
+// recorded are retried on the next check.
+func (s *UsageService) warn(ctx context.Context, tenantID string, now time.Time, dailyLogs, monthlyLogs, monthlyBytes int64) {
+	if s.notifier == nil || s.config.WarningRatio <= 0 {
+		return
+	}
+
+	day, month := now.Format(time.DateOnly), now.Format("2006-01")
+	warnings := []domain.QuotaWarning{
+		{Quota: domain.QuotaDailyLogs, Period: day, Used: dailyLogs, Limit: s.config.DailyLogs},
+		{Quota: domain.QuotaMonthlyLogs, Period: month, Used: monthlyLogs, Limit: s.config.MonthlyLogs},
+		{Quota: domain.QuotaMonthlyBytes, Period: month, Used: monthlyBytes, Limit: s.config.MonthlyBytes},
+	}
+	for _, warning := range warnings {
+		if warning.Limit <= 0 || warning.Ratio() < s.config.WarningRatio {
+			continue
+		}
+		warned, err := s.store.MarkQuotaWarned(ctx, tenantID, warning.Quota, warning.Period)
+		if err != nil || !warned {
+			continue
+		}
+
+		warning.TenantID, warning.At = tenantID, now
+		if err := s.notifier.NotifyQuotaWarning(ctx, warning); err != nil {
+			metrics.QuotaWarningsTotal.WithLabelValues(tenantID, string(warning.Quota), "error").Inc()
+			_ = s.store.UnmarkQuotaWarned(ctx, tenantID, warning.Quota, warning.Period)
+			continue
+		}
+		metrics.QuotaWarningsTotal.WithLabelValues(tenantID, string(warning.Quota), "success").Inc()
+	}
+}
+
+// Record adds ingested logs to the tenant's usage for today
+func (s *UsageService) Record(ctx context.Context, tenantID string, logs, bytes int64) error {
+	return s.store.Add(ctx, tenantID, s.now(), logs, bytes)
+}
+
+// GetUsage returns the tenant's usage for every UTC day from from to to,
+// inclusive, and its totals per calendar month
+func (s *UsageService) GetUsage(ctx context.Context, tenantID string, from, to time.Time) (*dto.TenantUsageResponse, error) {
+	from, to = from.UTC(), to.UTC()
+	if to.Before(from) || to.Sub(from) > maxUsageRange {
+		return nil, ErrInvalidUsageRange
+	}
+
+	days, err := s.store.Days(ctx, tenantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.TenantUsageResponse{
+		TenantID: tenantID,
+		From:     from.Format(time.DateOnly),
+		To:       to.Format(time.DateOnly),
+		Daily:    make([]dto.UsagePeriodResponse, len(days)),
+		Monthly:  []dto.UsagePeriodResponse{},
+		Quota: dto.TenantQuotaResponse{
+			DailyLogs:    s.config.DailyLogs,
+			MonthlyLogs:  s.config.MonthlyLogs,
+			MonthlyBytes: s.config.MonthlyBytes,
+		},
+	}
+
+	for i, day := range days {
+		resp.Daily[i] = dto.UsagePeriodResponse{Period: day.Day.Format(time.DateOnly), Logs: day.Logs, Bytes: day.Bytes}
+
+		period := day.Day.Format("2006-01")
+		if n := len(resp.Monthly); n == 0 || resp.Monthly[n-1].Period != period {
+			resp.Monthly = append(resp.Monthly, dto.UsagePeriodResponse{Period: period})
+		}
+		month := &resp.Monthly[len(resp.Monthly)-1]
+		month.Logs += day.Logs
+		month.Bytes += day.Bytes
+	}
+
+	return resp, nil
+}
+
+// startOfMonth returns midnight of the first day of t's month
+func startOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type UsageServiceTestSuite struct {
+	suite.Suite
+	mockStore *mocks.UsageStore
+	config    *config.QuotaConfig
+	service   *UsageService
+	now       time.Time
+}
+
+func (s *UsageServiceTestSuite) SetupTest() {
+	s.mockStore = new(mocks.UsageStore)
+	s.config = &config.QuotaConfig{}
+	s.now = time.Date(2025, 7, 17, 15, 0, 0, 0, time.UTC)
+
+	s.service = NewUsageService(s.mockStore, s.config)
+	s.service.now = func() time.Time { return s.now }
+}
+
+func TestUsageService(t *testing.T) {
+	suite.Run(t, new(UsageServiceTestSuite))
+}
+
+func (s *UsageServiceTestSuite) monthSoFar(todayLogs int64) []domain.TenantUsage {
+	return []domain.TenantUsage{
+		{Day: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), Logs: 500, Bytes: 50_000},
+		{Day: time.Date(2025, 7, 17, 0, 0, 0, 0, time.UTC), Logs: todayLogs, Bytes: todayLogs * 100},
+	}
+}
+
+func (s *UsageServiceTestSuite) TestCheckQuota_NoQuotas_SkipsStore() {
+	// Act
+	err := s.service.CheckQuota(context.Background(), "tenant1", 10)
+
+	// Assert
+	s.NoError(err)
+	s.mockStore.AssertNotCalled(s.T(), "Days", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *UsageServiceTestSuite) TestCheckQuota_SystemTenant_NotCapped() {
+	// Arrange
+	s.config.DailyLogs = 100
+
+	// Act
+	err := s.service.CheckQuota(context.Background(), domain.SystemTenantID, 1000)
+
+	// Assert
+	s.NoError(err)
+	s.mockStore.AssertNotCalled(s.T(), "Days", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *UsageServiceTestSuite) TestCheckQuota_DailyExceeded() {
+	// Arrange
+	s.config.DailyLogs = 100
+	s.mockStore.On("Days", mock.Anything, "tenant1", time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), s.now).
+		Return(s.monthSoFar(95), nil)
+
+	// Act
+	withinQuota := s.service.CheckQuota(context.Background(), "tenant1", 5)
+	overQuota := s.service.CheckQuota(context.Background(), "tenant1", 6)
+
+	// Assert
+	s.NoError(withinQuota)
+	s.ErrorIs(overQuota, ErrDailyQuotaExceeded)
+}
+
+func (s *UsageServiceTestSuite) TestCheckQuota_MonthlyExceeded() {
+	// Arrange
+	s.config.DailyLogs = 1000
+	s.config.MonthlyLogs = 600
+	s.mockStore.On("Days", mock.Anything, "tenant1", mock.Anything, mock.Anything).Return(s.monthSoFar(100), nil)
+
+	// Act
+	err := s.service.CheckQuota(context.Background(), "tenant1", 1)
+
+	// Assert
+	s.ErrorIs(err, ErrMonthlyQuotaExceeded)
+}
+
+func (s *UsageServiceTestSuite) TestGetUsage_GroupsDaysByMonth() {
+	// Arrange
+	from := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	s.mockStore.On("Days", mock.Anything, "tenant1", from, to).Return([]domain.TenantUsage{
+		{Day: from, Logs: 10, Bytes: 1000},
+		{Day: to, Logs: 20, Bytes: 2000},
+	}, nil)
+
+	// Act
+	usage, err := s.service.GetUsage(context.Background(), "tenant1", from, to)
+
+	// Assert
+	s.NoError(err)
+	s.Len(usage.Daily, 2)
+	s.Equal("2025-06-30", usage.Daily[0].Period)
+	s.Len(usage.Monthly, 2)
+	s.Equal("2025-07", usage.Monthly[1].Period)
+	s.Equal(int64(20), usage.Monthly[1].Logs)
+}
+
+func (s *UsageServiceTestSuite) TestGetUsage_InvalidRange() {
+	// Act
+	_, err := s.service.GetUsage(context.Background(), "tenant1", s.now, s.now.AddDate(0, 0, -1))
+
+	// Assert
+	s.ErrorIs(err, ErrInvalidUsageRange)
+}
+
+func (s *UsageServiceTestSuite) TestCheckQuota_NearingQuota_WarnsOncePerPeriod() {
+	// Arrange
+	notifier := new(mocks.QuotaNotifier)
+	s.service.UseQuotaWarnings(notifier)
+	s.config.DailyLogs = 100
+	s.config.WarningRatio = 0.8
+	s.mockStore.On("Days", mock.Anything, "tenant1", time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), s.now).
+		Return(s.monthSoFar(75), nil)
+	s.mockStore.On("MarkQuotaWarned", mock.Anything, "tenant1", domain.QuotaDailyLogs, "2025-07-17").Return(true, nil).Once()
+	s.mockStore.On("MarkQuotaWarned", mock.Anything, "tenant1", domain.QuotaDailyLogs, "2025-07-17").Return(false, nil)
+	notifier.On("NotifyQuotaWarning", mock.Anything, domain.QuotaWarning{
+		TenantID: "tenant1",
+		Quota:    domain.QuotaDailyLogs,
+		Period:   "2025-07-17",
+		Used:     80,
+		Limit:    100,
+		At:       s.now,
+	}).Return(nil).Once()
+
+	// Act
+	belowWarning := s.service.CheckQuota(context.Background(), "tenant1", 4)
+	first := s.service.CheckQuota(context.Background(), "tenant1", 5)
+	second := s.service.CheckQuota(context.Background(), "tenant1", 5)
+
+	// Assert
+	s.NoError(belowWarning)
+	s.NoError(first)
+	s.NoError(second)
+	notifier.AssertExpectations(s.T())
+	s.mockStore.AssertNumberOfCalls(s.T(), "MarkQuotaWarned", 2)
+}
+
+func (s *UsageServiceTestSuite) TestCheckQuota_WarningNotRecorded_RetriedLater() {
+	// Arrange
+	notifier := new(mocks.QuotaNotifier)
+	s.service.UseQuotaWarnings(notifier)
+	s.config.MonthlyLogs = 1000
+	s.config.WarningRatio = 0.5
+	s.mockStore.On("Days", mock.Anything, "tenant1", time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), s.now).
+		Return(s.monthSoFar(10), nil)
+	s.mockStore.On("MarkQuotaWarned", mock.Anything, "tenant1", domain.QuotaMonthlyLogs, "2025-07").Return(true, nil)
+	s.mockStore.On("UnmarkQuotaWarned", mock.Anything, "tenant1", domain.QuotaMonthlyLogs, "2025-07").Return(nil)
+	notifier.On("NotifyQuotaWarning", mock.Anything, mock.Anything).Return(errors.New("db down"))
+
+	// Act
+	err := s.service.CheckQuota(context.Background(), "tenant1", 1)
+
+	// Assert
+	s.NoError(err)
+	s.mockStore.AssertCalled(s.T(), "UnmarkQuotaWarned", mock.Anything, "tenant1", domain.QuotaMonthlyLogs, "2025-07")
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+)
+
+type UserService struct {
+	repo repository.Repository
+}
+
+func NewUserService(repo repository.Repository) *UserService {
+	return &UserService{repo: repo}
+}
+
+// Create adds a user to the tenant. Users without roles get the user role.
+func (s *UserService) Create(ctx context.Context, tenantID string, req dto.CreateUserRequest) (_ *dto.UserResponse, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.Create", trace.WithAttributes(tracing.TenantAttr(tenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	roles := req.Roles
+	if len(roles) == 0 {
+		roles = []string{string(domain.RoleUser)}
+	}
+
+	// Emails are unique across tenants
+	_, err = s.repo.User().GetByEmail(ctx, req.Email)
+	if err == nil {
+		return nil, ErrEmailAlreadyExists
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to check email: %w", err)
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user := &domain.User{
+		TenantID:     tenantID,
+		Email:        req.Email,
+		Name:         req.Name,
+		PasswordHash: string(passwordHash),
+		Roles:        roles,
+		Active:       true,
+		Metadata:     req.Metadata,
+	}
+	if err := s.repo.User().Create(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return dto.FromUser(user), nil
+}
+
+func (s *UserService) List(ctx context.Context, filter *domain.UserFilter) (_ []dto.UserResponse, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.List", trace.WithAttributes(tracing.TenantAttr(filter.TenantID)))
+	defer func() { tracing.End(span, err) }()
+
+	// Set default values for pagination
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = 10
+	}
+
+	// Convert page and page size to limit and offset
+	filter.Limit = filter.PageSize
+	filter.Offset = (filter.Page - 1) * filter.PageSize
+
+	users, err := s.repo.User().List(ctx, *filter)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromUsers(users), nil
+}
+
+// UpdateRoles replaces the roles of a user
+func (s *UserService) UpdateRoles(ctx context.Context, tenantID, id string, roles []string) (_ *dto.UserResponse, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.UpdateRoles", trace.WithAttributes(tracing.TenantAttr(tenantID), attribute.String("user.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	user, err := s.getUser(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Roles = roles
+	user.UpdatedAt = time.Now()
+	if err := s.repo.User().Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user roles: %w", err)
+	}
+
+	return dto.FromUser(user), nil
+}
+
+// Deactivate marks a user inactive. The user is kept so past audit logs still resolve.
+func (s *UserService) Deactivate(ctx context.Context, tenantID, id string) (_ *dto.UserResponse, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.Deactivate", trace.WithAttributes(tracing.TenantAttr(tenantID), attribute.String("user.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	user, err := s.getUser(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.Active {
+		return dto.FromUser(user), nil
+	}
+
+	user.Active = false
+	user.UpdatedAt = time.Now()
+	if err := s.repo.User().Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to deactivate user: %w", err)
+	}
+
+	return dto.FromUser(user), nil
+}
+
+// getUser loads a tenant's user, mapping a missing row to ErrUserNotFound
+func (s *UserService) getUser(ctx context.Context, tenantID, id string) (*domain.User, error) {
+	user, err := s.repo.User().GetByID(ctx, tenantID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type UserServiceTestSuite struct {
+	suite.Suite
+	mockRepo *mocks.Repository
+	mockUser *mocks.UserRepository
+	service  *UserService
+}
+
+func (s *UserServiceTestSuite) SetupTest() {
+	s.mockRepo = new(mocks.Repository)
+	s.mockUser = new(mocks.UserRepository)
+
+	s.mockRepo.On("User").Return(s.mockUser)
+
+	s.service = NewUserService(s.mockRepo)
+}
+
+func TestUserService(t *testing.T) {
+	suite.Run(t, new(UserServiceTestSuite))
+}
+
+func (s *UserServiceTestSuite) TestCreate_DefaultsToUserRole() {
+	// Arrange
+	ctx := context.Background()
+	req := dto.CreateUserRequest{Email: "jane@example.com", Name: "Jane", Password: "correct-horse"}
+
+	s.mockUser.On("GetByEmail", mock.Anything, req.Email).Return(nil, gorm.ErrRecordNotFound)
+	s.mockUser.On("Create", mock.Anything, mock.MatchedBy(func(u *domain.User) bool {
+		return u.TenantID == "tenant1" && u.Active && len(u.Roles) == 1 && u.Roles[0] == "user" &&
+			bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(req.Password)) == nil
+	})).Return(nil)
+
+	// Act
+	resp, err := s.service.Create(ctx, "tenant1", req)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(req.Email, resp.Email)
+	s.Equal([]string{"user"}, resp.Roles)
+	s.mockUser.AssertExpectations(s.T())
+}
+
+func (s *UserServiceTestSuite) TestCreate_EmailExists() {
+	// Arrange
+	ctx := context.Background()
+	req := dto.CreateUserRequest{Email: "jane@example.com", Name: "Jane", Password: "correct-horse"}
+
+	s.mockUser.On("GetByEmail", mock.Anything, req.Email).Return(&domain.User{ID: "user1"}, nil)
+
+	// Act
+	resp, err := s.service.Create(ctx, "tenant1", req)
+
+	// Assert
+	s.ErrorIs(err, ErrEmailAlreadyExists)
+	s.Nil(resp)
+	s.mockUser.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (s *UserServiceTestSuite) TestList_AppliesPaginationDefaults() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.UserFilter{TenantID: "tenant1", Page: 3}
+
+	s.mockUser.On("List", mock.Anything, mock.MatchedBy(func(f domain.UserFilter) bool {
+		return f.Limit == 10 && f.Offset == 20
+	})).Return([]domain.User{{ID: "user1"}}, nil)
+
+	// Act
+	users, err := s.service.List(ctx, filter)
+
+	// Assert
+	s.NoError(err)
+	s.Len(users, 1)
+	s.mockUser.AssertExpectations(s.T())
+}
+
+func (s *UserServiceTestSuite) TestUpdateRoles_Success() {
+	// Arrange
+	ctx := context.Background()
+	user := &domain.User{ID: "user1", TenantID: "tenant1", Roles: domain.StringArray{"user"}, Active: true}
+
+	s.mockUser.On("GetByID", mock.Anything, "tenant1", "user1").Return(user, nil)
+	s.mockUser.On("Update", mock.Anything, user).Return(nil)
+
+	// Act
+	resp, err := s.service.UpdateRoles(ctx, "tenant1", "user1", []string{"admin", "auditor"})
+
+	// Assert
+	s.NoError(err)
+	s.Equal([]string{"admin", "auditor"}, resp.Roles)
+	s.mockUser.AssertExpectations(s.T())
+}
+
+func (s *UserServiceTestSuite) TestUpdateRoles_NotFound() {
+	// Arrange
+	ctx := context.Background()
+	s.mockUser.On("GetByID", mock.Anything, "tenant1", "missing").Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	resp, err := s.service.UpdateRoles(ctx, "tenant1", "missing", []string{"admin"})
+
+	// Assert
+	s.ErrorIs(err, ErrUserNotFound)
+	s.Nil(resp)
+}
+
+func (s *UserServiceTestSuite) TestDeactivate_Success() {
+	// Arrange
+	ctx := context.Background()
+	user := &domain.User{ID: "user1", TenantID: "tenant1", Active: true}
+
+	s.mockUser.On("GetByID", mock.Anything, "tenant1", "user1").Return(user, nil)
+	s.mockUser.On("Update", mock.Anything, mock.MatchedBy(func(u *domain.User) bool { return !u.Active })).Return(nil)
+
+	// Act
+	resp, err := s.service.Deactivate(ctx, "tenant1", "user1")
+
+	// Assert
+	s.NoError(err)
+	s.False(resp.Active)
+	s.mockUser.AssertExpectations(s.T())
+}
+
+func (s *UserServiceTestSuite) TestDeactivate_UpdateError() {
+	// Arrange
+	ctx := context.Background()
+	user := &domain.User{ID: "user1", TenantID: "tenant1", Active: true}
+
+	s.mockUser.On("GetByID", mock.Anything, "tenant1", "user1").Return(user, nil)
+	s.mockUser.On("Update", mock.Anything, mock.Anything).Return(errors.New("db error"))
+
+	// Act
+	resp, err := s.service.Deactivate(ctx, "tenant1", "user1")
+
+	// Assert
+	s.Error(err)
+	s.Nil(resp)
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/pkg/safehttp"
+)
+
+const (
+	// WebhookEventHeader names the event a webhook notification reports
+	WebhookEventHeader = "X-Webhook-Event"
+	// WebhookSignatureHeader carries sha256=<hex HMAC-SHA256 of the body>,
+	// keyed with the tenant's first webhook secret
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// WebhookSender posts notifications to the webhook URL of a tenant's settings.
+// Webhooks must be https and are only posted to public addresses, so tenants
+// can't reach the server's own network.
+type WebhookSender struct {
+	client *http.Client
+}
+
+func NewWebhookSender(timeout time.Duration) *WebhookSender {
+	return &WebhookSender{client: safehttp.NewClient(timeout)}
+}
+
+// Send posts payload as JSON to the tenant's webhook, signing it when the
+// tenant has a webhook secret. Tenants without a webhook are skipped, and
+// webhooks set before they had to be https fail.
+func (w *WebhookSender) Send(ctx context.Context, settings *domain.TenantSettings, event string, payload any) (err error) {
+	if settings.WebhookURL == "" {
+		return nil
+	}
+	defer func() {
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.WebhookDeliveriesTotal.WithLabelValues(event, status).Inc()
+	}()
+
+	if !strings.HasPrefix(settings.WebhookURL, "https://") {
+		return fmt.Errorf("failed to post webhook: %w", safehttp.ErrInsecureURL)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, settings.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, event)
+	if len(settings.WebhookSecrets) > 0 {
+		req.Header.Set(WebhookSignatureHeader, signWebhook(settings.WebhookSecrets[0], body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// signWebhook returns the signature header value of body
+func signWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
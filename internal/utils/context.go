@@ -12,6 +12,13 @@ type ContextKey string
 const (
 	ClaimsKey   ContextKey = "claims"
 	TenantIDKey ContextKey = "tenant_id"
+	UserIDKey   ContextKey = "user_id"
+	// CorrelationIDKey holds the request chain ID set by the correlation ID middleware
+	CorrelationIDKey ContextKey = "correlation_id"
+	// RequestIDKey holds the ID of the API call set by the request ID middleware
+	RequestIDKey ContextKey = "request_id"
+	// PolicyScopeKey holds the domain.PolicyScope granted to the request by the policy middleware
+	PolicyScopeKey ContextKey = "policy_scope"
 )
 
 var (
@@ -21,6 +28,13 @@ var (
 	ErrInvalidTenantIDType = errors.New("tenant_id must be a string")
 )
 
+// RequestIDFromContext returns the ID of the API call ctx belongs to, or ""
+// outside of one
+func RequestIDFromContext(c context.Context) string {
+	id, _ := c.Value(RequestIDKey).(string)
+	return id
+}
+
 func GetTenantIDFromContext(c context.Context) (string, error) {
 	claims, exists := c.Value(ClaimsKey).(jwt.MapClaims)
 	if !exists {
@@ -0,0 +1,98 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+// AnomalyWorker checks every tenant's activity once per window and records
+// deviations from the baseline as CRITICAL audit logs
+type AnomalyWorker struct {
+	detector     *service.AnomalyService
+	repository   repository.PostgresRepository
+	logger       *logger.Logger
+	window       time.Duration
+	shutdownChan chan struct{}
+	waitGroup    sync.WaitGroup
+}
+
+func NewAnomalyWorker(
+	detector *service.AnomalyService,
+	repository repository.PostgresRepository,
+	logger *logger.Logger,
+	window time.Duration,
+) *AnomalyWorker {
+	return &AnomalyWorker{
+		detector:     detector,
+		repository:   repository,
+		logger:       logger,
+		window:       window,
+		shutdownChan: make(chan struct{}),
+	}
+}
+
+func (w *AnomalyWorker) Start() {
+	w.logger.Info("Starting Anomaly worker...")
+
+	w.waitGroup.Add(1)
+	go w.run()
+}
+
+func (w *AnomalyWorker) Stop() {
+	w.logger.Info("Stopping Anomaly worker...")
+	close(w.shutdownChan)
+	w.waitGroup.Wait()
+	w.logger.Info("Anomaly worker stopped")
+}
+
+func (w *AnomalyWorker) run() {
+	defer w.waitGroup.Done()
+
+	// Ticking once per window checks consecutive, non-overlapping windows,
+	// so an anomaly is reported once
+	ticker := time.NewTicker(w.window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.shutdownChan:
+			w.logger.Info("Anomaly worker shutting down")
+			return
+		case now := <-ticker.C:
+			if err := w.checkTenants(context.Background(), now); err != nil {
+				w.logger.Errorf("Anomaly worker failed to check tenants: %v", err)
+			}
+		}
+	}
+}
+
+func (w *AnomalyWorker) checkTenants(ctx context.Context, now time.Time) error {
+	tenants, err := w.repository.Tenant().List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list tenants: %w", err)
+	}
+
+	for _, tenant := range tenants {
+		anomalies, err := w.detector.Detect(ctx, tenant.ID, now)
+		if err != nil {
+			w.logger.Errorf("Failed to detect anomalies for tenant %s: %v", tenant.ID, err)
+			continue
+		}
+
+		for _, anomaly := range anomalies {
+			w.logger.Warnf("Anomaly %s for tenant %s: observed %.2f, threshold %.2f",
+				anomaly.Kind, tenant.ID, anomaly.Observed, anomaly.Threshold)
+		}
+		if err := w.detector.Report(ctx, anomalies); err != nil {
+			w.logger.Errorf("Failed to report anomalies for tenant %s: %v", tenant.ID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,226 @@
+package worker
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+// Archives are written as a directory of gzip-compressed NDJSON parts plus a manifest:
+//
+//	audit-logs/<tenant>/before_<date>/part-00001.ndjson.gz
+//	audit-logs/<tenant>/before_<date>/manifest.json
+//
+// The manifest is written last, so an archive without one is incomplete.
+// Archives written before this layout are single JSON files named
+// audit-logs/<tenant>/audit_logs_<tenant>_before_<date>.json and are still
+// readable for restores.
+const (
+	// archiveDateLayout formats the before date embedded in archive keys
+	archiveDateLayout = "2006-01-02_15-04-05"
+
+	archiveManifestName = "manifest.json"
+	archiveFormatNDJSON = "ndjson+gzip"
+
+	// archiveBatchSize is the number of logs read from PostgreSQL per query
+	archiveBatchSize = 1000
+
+	// archivePartMaxLogs caps the logs per part so a restore can skip parts outside its range
+	archivePartMaxLogs = 250000
+)
+
+// archiveManifest describes the parts of an archive
+type archiveManifest struct {
+	TenantID   string        `json:"tenant_id"`
+	BeforeDate time.Time     `json:"before_date"`
+	ArchivedAt time.Time     `json:"archived_at"`
+	Format     string        `json:"format"`
+	LogCount   int64         `json:"log_count"`
+	Parts      []archivePart `json:"parts"`
+	// RetainUntil is the date S3 Object Lock protects the archive until
+	RetainUntil *time.Time `json:"retain_until,omitempty"`
+	// KMSKeyID is the KMS key the archive's objects are encrypted with
+	KMSKeyID string `json:"kms_key_id,omitempty"`
+}
+
+// archivePart describes one compressed NDJSON object of an archive
+type archivePart struct {
+	Key            string    `json:"key"`
+	LogCount       int64     `json:"log_count"`
+	FirstTimestamp time.Time `json:"first_timestamp"`
+	LastTimestamp  time.Time `json:"last_timestamp"`
+	Size           int64     `json:"size"`
+	// SHA256 is the hex digest of the compressed object, absent from archives
+	// written before parts were checksummed
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// newArchiveRecord records a written archive with the checksums of its
+// manifest, whose content is manifestData, and parts
+func newArchiveRecord(manifest *archiveManifest, manifestKey string, manifestData []byte, storage archiveStorage) *domain.Archive {
+	digest := sha256.Sum256(manifestData)
+	archive := &domain.Archive{
+		TenantID:       manifest.TenantID,
+		BeforeDate:     manifest.BeforeDate,
+		ManifestKey:    manifestKey,
+		ManifestSHA256: hex.EncodeToString(digest[:]),
+		LogCount:       manifest.LogCount,
+		StorageClass:   string(storage.storageClass),
+		RetainUntil:    manifest.RetainUntil,
+		KMSKeyID:       manifest.KMSKeyID,
+		ArchivedAt:     manifest.ArchivedAt,
+	}
+	for _, part := range manifest.Parts {
+		archive.Parts = append(archive.Parts, domain.ArchivePart{
+			Key:            part.Key,
+			LogCount:       part.LogCount,
+			FirstTimestamp: part.FirstTimestamp,
+			LastTimestamp:  part.LastTimestamp,
+			Size:           part.Size,
+			SHA256:         part.SHA256,
+		})
+		archive.Size += part.Size
+	}
+	return archive
+}
+
+// overlaps reports whether the part may hold logs in [start, end]
+func (p archivePart) overlaps(start, end time.Time) bool {
+	return !p.LastTimestamp.Before(start) && !p.FirstTimestamp.After(end)
+}
+
+func archivePrefix(tenantID string) string {
+	return fmt.Sprintf("audit-logs/%s/", tenantID)
+}
+
+func archiveDir(tenantID string, beforeDate time.Time) string {
+	return fmt.Sprintf("%sbefore_%s/", archivePrefix(tenantID), beforeDate.UTC().Format(archiveDateLayout))
+}
+
+func archivePartKey(dir string, number int) string {
+	return fmt.Sprintf("%spart-%05d.ndjson.gz", dir, number)
+}
+
+// archiveBeforeDate parses the before date from an archive manifest key or a legacy single-file archive key
+func archiveBeforeDate(key string) (time.Time, bool) {
+	var date string
+	switch {
+	case path.Base(key) == archiveManifestName:
+		dir := path.Base(path.Dir(key))
+		if !strings.HasPrefix(dir, "before_") {
+			return time.Time{}, false
+		}
+		date = strings.TrimPrefix(dir, "before_")
+	case strings.HasSuffix(key, ".json") && strings.Contains(key, "_before_"):
+		date = strings.TrimSuffix(key[strings.LastIndex(key, "_before_")+len("_before_"):], ".json")
+	default:
+		return time.Time{}, false
+	}
+
+	t, err := time.Parse(archiveDateLayout, date)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// archivePartWriter streams logs into a single gzip-compressed NDJSON part via multipart upload
+type archivePartWriter struct {
+	upload *multipartUpload
+	gz     *gzip.Writer
+	enc    *json.Encoder
+	info   archivePart
+}
+
+func newArchivePartWriter(ctx context.Context, client *s3.Client, bucket, key string, options ...func(*s3.CreateMultipartUploadInput)) (*archivePartWriter, error) {
+	upload, err := newMultipartUpload(ctx, client, bucket, key, "application/gzip", options...)
+	if err != nil {
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(upload)
+	return &archivePartWriter{
+		upload: upload,
+		gz:     gz,
+		enc:    json.NewEncoder(gz),
+		info:   archivePart{Key: key},
+	}, nil
+}
+
+func (p *archivePartWriter) Write(log *domain.AuditLog) error {
+	if err := p.enc.Encode(log); err != nil {
+		return fmt.Errorf("failed to write log %s to %s: %w", log.ID, p.info.Key, err)
+	}
+
+	if p.info.LogCount == 0 || log.Timestamp.Before(p.info.FirstTimestamp) {
+		p.info.FirstTimestamp = log.Timestamp
+	}
+	if log.Timestamp.After(p.info.LastTimestamp) {
+		p.info.LastTimestamp = log.Timestamp
+	}
+	p.info.LogCount++
+	return nil
+}
+
+// Close flushes the compressed stream and completes the upload
+func (p *archivePartWriter) Close(ctx context.Context) (archivePart, error) {
+	if err := p.gz.Close(); err != nil {
+		return archivePart{}, fmt.Errorf("failed to compress %s: %w", p.info.Key, err)
+	}
+	if err := p.upload.Complete(ctx); err != nil {
+		return archivePart{}, err
+	}
+
+	p.info.Size = p.upload.Size()
+	p.info.SHA256 = p.upload.SHA256()
+	return p.info, nil
+}
+
+func (p *archivePartWriter) Abort(ctx context.Context) {
+	p.upload.Abort(ctx)
+}
+
+// decodeArchivePart streams logs from a compressed NDJSON part, calling fn with batches of up to batchSize logs
+func decodeArchivePart(r io.Reader, batchSize int, fn func([]domain.AuditLog) error) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("failed to open gzip stream: %w", err)
+	}
+	defer gz.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(gz))
+	batch := make([]domain.AuditLog, 0, batchSize)
+	for {
+		var log domain.AuditLog
+		if err := dec.Decode(&log); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("failed to decode log: %w", err)
+		}
+
+		batch = append(batch, log)
+		if len(batch) == batchSize {
+			if err := fn(batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	if len(batch) > 0 {
+		return fn(batch)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/service/queue"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+// ArchiveScheduler periodically archives each tenant's logs older than its
+// retention, the way DELETE /logs/cleanup does on request: it records a
+// cleanup job and enqueues an archive message, whose archival is followed by
+// the cleanup. A tenant is archived once per interval, and not while its
+// previous scheduled run is still in progress. Runs are claimed in the
+// tenant's schedule, so several schedulers can run side by side.
+type ArchiveScheduler struct {
+	repository   repository.PostgresRepository
+	messageQueue queue.Queue
+	config       *config.ArchiveScheduleConfig
+	logger       *logger.Logger
+	shutdownChan chan struct{}
+	waitGroup    sync.WaitGroup
+}
+
+func NewArchiveScheduler(
+	repository repository.PostgresRepository,
+	messageQueue queue.Queue,
+	config *config.ArchiveScheduleConfig,
+	logger *logger.Logger,
+) *ArchiveScheduler {
+	return &ArchiveScheduler{
+		repository:   repository,
+		messageQueue: messageQueue,
+		config:       config,
+		logger:       logger,
+		shutdownChan: make(chan struct{}),
+	}
+}
+
+func (w *ArchiveScheduler) Start() {
+	w.logger.Info("Starting Archive scheduler...")
+
+	w.waitGroup.Add(1)
+	go w.run()
+}
+
+func (w *ArchiveScheduler) Stop() {
+	w.logger.Info("Stopping Archive scheduler...")
+	close(w.shutdownChan)
+	w.waitGroup.Wait()
+	w.logger.Info("Archive scheduler stopped")
+}
+
+func (w *ArchiveScheduler) run() {
+	defer w.waitGroup.Done()
+
+	if err := w.schedule(context.Background(), time.Now()); err != nil {
+		w.logger.Errorf("Archive scheduler failed to schedule archives: %v", err)
+	}
+
+	ticker := time.NewTicker(w.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.shutdownChan:
+			w.logger.Info("Archive scheduler shutting down")
+			return
+		case now := <-ticker.C:
+			if err := w.schedule(context.Background(), now); err != nil {
+				w.logger.Errorf("Archive scheduler failed to schedule archives: %v", err)
+			}
+		}
+	}
+}
+
+func (w *ArchiveScheduler) schedule(ctx context.Context, now time.Time) error {
+	tenants, err := w.repository.Tenant().List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list tenants: %w", err)
+	}
+	schedules, err := w.repository.ArchiveSchedule().List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list archive schedules: %w", err)
+	}
+
+	byTenant := make(map[string]*domain.ArchiveSchedule, len(schedules))
+	for i := range schedules {
+		byTenant[schedules[i].TenantID] = &schedules[i]
+	}
+
+	for i := range tenants {
+		tenant := &tenants[i]
+		schedule, ok := byTenant[tenant.ID]
+		if !ok {
+			schedule = domain.DefaultArchiveSchedule(tenant.ID)
+		}
+
+		started, err := w.scheduleTenant(ctx, tenant, schedule, now)
+		if started || err != nil {
+			metrics.ObserveScheduledArchive(err)
+		}
+		if err != nil {
+			w.logger.Errorf("Failed to schedule archive for tenant %s: %v", tenant.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// scheduleTenant starts a run of the tenant's schedule if one is due,
+// reporting whether it did
+func (w *ArchiveScheduler) scheduleTenant(ctx context.Context, tenant *domain.Tenant, schedule *domain.ArchiveSchedule, now time.Time) (bool, error) {
+	retention := w.retentionFor(tenant, schedule)
+	if retention == 0 || !schedule.Due(now, schedule.Interval(w.config.Interval)) {
+		return false, nil
+	}
+
+	// Don't pile up runs behind one the archive or cleanup worker hasn't finished
+	if schedule.LastJobID != nil {
+		job, err := w.repository.CleanupJob().GetByID(ctx, tenant.ID, *schedule.LastJobID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, fmt.Errorf("failed to get last cleanup job: %w", err)
+		}
+		if err == nil && !job.Status.Done() {
+			return false, nil
+		}
+	}
+
+	// Archive whole days, matching the tenant's daily indices
+	beforeDate := now.UTC().Add(-retention).Truncate(24 * time.Hour)
+	job := &domain.CleanupJob{
+		ID:         uuid.New().String(),
+		TenantID:   tenant.ID,
+		Status:     domain.JobPending,
+		BeforeDate: beforeDate,
+	}
+
+	claimed, err := w.repository.ArchiveSchedule().Claim(ctx, tenant.ID, schedule.LastRunAt, now, job.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim archive run: %w", err)
+	}
+	if !claimed {
+		return false, nil
+	}
+
+	if err := w.repository.CleanupJob().Create(ctx, job); err != nil {
+		return false, fmt.Errorf("failed to create cleanup job: %w", err)
+	}
+	if err := w.messageQueue.SendArchiveMessage(ctx, tenant.ID, job.ID, beforeDate); err != nil {
+		// Best effort: don't leave the job pending forever when it never reached the queue
+		job.Status = domain.JobFailed
+		job.Error = "failed to enqueue archive message"
+		_ = w.repository.CleanupJob().Update(ctx, job)
+		return false, fmt.Errorf("failed to enqueue archive message: %w", err)
+	}
+
+	w.logger.Infof("Scheduled archive of tenant %s logs before %s (job %s)", tenant.ID, beforeDate.Format(time.DateOnly), job.ID)
+	return true, nil
+}
+
+// retentionFor returns how old a tenant's logs are when they are archived,
+// or 0 to leave them in place. The schedule's override comes first, then the
+// tenant's own setting, then the default.
+func (w *ArchiveScheduler) retentionFor(tenant *domain.Tenant, schedule *domain.ArchiveSchedule) time.Duration {
+	if retention := schedule.Retention(); retention > 0 {
+		return retention
+	}
+	if retention := tenant.Settings.Retention(); retention > 0 {
+		return retention
+	}
+	return w.config.Retention
+}
@@ -0,0 +1,240 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+const (
+	// archiveObjectTag marks archive parts, which the tenants' lifecycle
+	// rules move to Glacier; manifests stay readable for listing and restores
+	archiveObjectTag  = "audit-log-archive"
+	archivePartTag    = "part"
+	archiveRulePrefix = "audit-log-archive-"
+
+	// glacierRestoreDays is how long a copy restored from Glacier stays readable
+	glacierRestoreDays = 7
+)
+
+// archiveStorage is how an archive is stored, the tenant's settings over the
+// global defaults
+type archiveStorage struct {
+	storageClass     types.StorageClass
+	glacierAfterDays int
+	glacierClass     types.TransitionStorageClass
+	lockMode         types.ObjectLockMode
+	lockDays         int
+	kmsKeyID         string
+}
+
+func newArchiveStorage(cfg *config.S3Config, tenantSettings domain.TenantSettings) archiveStorage {
+	settings := tenantSettings.ArchiveStorage
+	storage := archiveStorage{
+		storageClass:     types.StorageClass(cfg.ArchiveStorageClass),
+		glacierAfterDays: cfg.ArchiveGlacierAfterDays,
+		glacierClass:     types.TransitionStorageClass(cfg.ArchiveGlacierClass),
+		lockMode:         types.ObjectLockMode(cfg.ArchiveObjectLockMode),
+		lockDays:         cfg.ArchiveObjectLockDays,
+		kmsKeyID:         kmsKeyID(cfg, tenantSettings.Encryption),
+	}
+	if settings.StorageClass != "" {
+		storage.storageClass = types.StorageClass(settings.StorageClass)
+	}
+	if settings.GlacierAfterDays > 0 {
+		storage.glacierAfterDays = settings.GlacierAfterDays
+	}
+	if settings.ObjectLockMode != "" {
+		storage.lockMode = types.ObjectLockMode(settings.ObjectLockMode)
+	}
+	if settings.ObjectLockDays > 0 {
+		storage.lockDays = settings.ObjectLockDays
+	}
+	return storage
+}
+
+// retainUntil returns the date an archive written at archivedAt is locked
+// until, or nil when archives aren't locked
+func (s archiveStorage) retainUntil(archivedAt time.Time) *time.Time {
+	if s.lockMode == "" || s.lockDays == 0 {
+		return nil
+	}
+	until := archivedAt.AddDate(0, 0, s.lockDays).UTC()
+	return &until
+}
+
+// partOptions applies the storage to an archive part's upload
+func (s archiveStorage) partOptions(retainUntil *time.Time) func(*s3.CreateMultipartUploadInput) {
+	return func(input *s3.CreateMultipartUploadInput) {
+		input.StorageClass = s.storageClass
+		input.Tagging = aws.String(url.Values{archiveObjectTag: {archivePartTag}}.Encode())
+		encryptUpload(s.kmsKeyID)(input)
+		if retainUntil != nil {
+			input.ObjectLockMode = s.lockMode
+			input.ObjectLockRetainUntilDate = retainUntil
+			input.ChecksumAlgorithm = types.ChecksumAlgorithmCrc32
+		}
+	}
+}
+
+// manifestOptions encrypts and locks a manifest upload with its parts
+func (s archiveStorage) manifestOptions(input *s3.PutObjectInput, retainUntil *time.Time) {
+	encryptPut(input, s.kmsKeyID)
+	if retainUntil != nil {
+		input.ObjectLockMode = s.lockMode
+		input.ObjectLockRetainUntilDate = retainUntil
+		input.ChecksumAlgorithm = types.ChecksumAlgorithmCrc32
+	}
+}
+
+// resolveArchiveStorage returns how the tenant's archives are stored. Deleted
+// tenants being purged still have their settings applied.
+func (w *ArchiveWorker) resolveArchiveStorage(ctx context.Context, tenantID string) (archiveStorage, error) {
+	tenant, err := w.repository.Tenant().GetByID(ctx, tenantID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		tenant, err = w.repository.Tenant().GetDeleted(ctx, tenantID)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return newArchiveStorage(w.s3Config, domain.TenantSettings{}), nil
+	}
+	if err != nil {
+		return archiveStorage{}, fmt.Errorf("failed to load tenant %s: %w", tenantID, err)
+	}
+	return newArchiveStorage(w.s3Config, tenant.Settings), nil
+}
+
+// ensureLifecycleRule keeps the bucket lifecycle rule moving the tenant's
+// archive parts to Glacier in line with its storage, adding, changing or
+// removing it. Rules known to be applied by this worker aren't checked again.
+// Workers writing the lifecycle configuration at once may drop each other's
+// change, which the next archive of the affected tenant puts back.
+func (w *ArchiveWorker) ensureLifecycleRule(ctx context.Context, tenantID string, storage archiveStorage) error {
+	want := lifecycleRuleState{days: storage.glacierAfterDays, class: storage.glacierClass}
+
+	w.lifecycleMu.Lock()
+	defer w.lifecycleMu.Unlock()
+	if applied, ok := w.lifecycleRules[tenantID]; ok && applied == want {
+		return nil
+	}
+
+	bucket := aws.String(w.s3Config.BucketName)
+	var rules []types.LifecycleRule
+	out, err := w.s3Client.GetBucketLifecycleConfiguration(ctx, &s3.GetBucketLifecycleConfigurationInput{Bucket: bucket})
+	var apiErr smithy.APIError
+	switch {
+	case err == nil:
+		rules = out.Rules
+	case errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchLifecycleConfiguration":
+	default:
+		return fmt.Errorf("failed to get bucket lifecycle configuration: %w", err)
+	}
+
+	id := archiveRulePrefix + tenantID
+	i := slices.IndexFunc(rules, func(rule types.LifecycleRule) bool {
+		return aws.ToString(rule.ID) == id
+	})
+	switch {
+	case want.days == 0 && i < 0:
+		w.lifecycleRules[tenantID] = want
+		return nil
+	case want.days == 0:
+		rules = slices.Delete(rules, i, i+1)
+	case i < 0:
+		rules = append(rules, archiveLifecycleRule(id, tenantID, want))
+	default:
+		rules[i] = archiveLifecycleRule(id, tenantID, want)
+	}
+
+	if len(rules) == 0 {
+		_, err = w.s3Client.DeleteBucketLifecycle(ctx, &s3.DeleteBucketLifecycleInput{Bucket: bucket})
+	} else {
+		_, err = w.s3Client.PutBucketLifecycleConfiguration(ctx, &s3.PutBucketLifecycleConfigurationInput{
+			Bucket:                 bucket,
+			LifecycleConfiguration: &types.BucketLifecycleConfiguration{Rules: rules},
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update bucket lifecycle rule %s: %w", id, err)
+	}
+
+	w.lifecycleRules[tenantID] = want
+	w.logger.Infof("Archive parts of tenant %s move to %s after %d days", tenantID, want.class, want.days)
+	return nil
+}
+
+// lifecycleRuleState is the Glacier transition of a tenant's lifecycle rule
+type lifecycleRuleState struct {
+	days  int
+	class types.TransitionStorageClass
+}
+
+func archiveLifecycleRule(id, tenantID string, state lifecycleRuleState) types.LifecycleRule {
+	return types.LifecycleRule{
+		ID:     aws.String(id),
+		Status: types.ExpirationStatusEnabled,
+		Filter: &types.LifecycleRuleFilter{
+			And: &types.LifecycleRuleAndOperator{
+				Prefix: aws.String(archivePrefix(tenantID)),
+				Tags:   []types.Tag{{Key: aws.String(archiveObjectTag), Value: aws.String(archivePartTag)}},
+			},
+		},
+		Transitions: []types.Transition{{
+			Days:         aws.Int32(int32(state.days)),
+			StorageClass: state.class,
+		}},
+	}
+}
+
+// VerifyObjectLock checks that the archive bucket has S3 Object Lock enabled
+// when archives are locked by default, since S3 rejects locked uploads to
+// other buckets
+func (w *ArchiveWorker) VerifyObjectLock(ctx context.Context) error {
+	if w.s3Config.ArchiveObjectLockMode == "" {
+		return nil
+	}
+	out, err := w.s3Client.GetObjectLockConfiguration(ctx, &s3.GetObjectLockConfigurationInput{
+		Bucket: aws.String(w.s3Config.BucketName),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get object lock configuration of bucket %s: %w", w.s3Config.BucketName, err)
+	}
+	if out.ObjectLockConfiguration == nil || out.ObjectLockConfiguration.ObjectLockEnabled != types.ObjectLockEnabledEnabled {
+		return fmt.Errorf("bucket %s doesn't have object lock enabled", w.s3Config.BucketName)
+	}
+	return nil
+}
+
+// errArchiveInGlacier is returned for archive objects in Glacier, whose
+// restore was requested
+var errArchiveInGlacier = errors.New("archive object is in Glacier")
+
+// requestGlacierRestore asks S3 for a readable copy of an archive object in
+// Glacier, returning errArchiveInGlacier once requested. A restore already in
+// progress isn't requested again.
+func (w *ArchiveWorker) requestGlacierRestore(ctx context.Context, key string) error {
+	_, err := w.s3Client.RestoreObject(ctx, &s3.RestoreObjectInput{
+		Bucket: aws.String(w.s3Config.BucketName),
+		Key:    aws.String(key),
+		RestoreRequest: &types.RestoreRequest{
+			Days:                 aws.Int32(glacierRestoreDays),
+			GlacierJobParameters: &types.GlacierJobParameters{Tier: types.TierStandard},
+		},
+	})
+	var apiErr smithy.APIError
+	if err != nil && !(errors.As(err, &apiErr) && apiErr.ErrorCode() == "RestoreAlreadyInProgress") {
+		return fmt.Errorf("failed to request restore of archive object %s from Glacier: %w", key, err)
+	}
+	return fmt.Errorf("%w: %s", errArchiveInGlacier, key)
+}
@@ -4,53 +4,74 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 
 	"github.com/kingrain94/audit-log-api/internal/config"
 	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
 	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/service"
 	"github.com/kingrain94/audit-log-api/internal/service/queue"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
 	"github.com/kingrain94/audit-log-api/pkg/logger"
 )
 
+// restoreBatchSize bounds both the insert batch and the size of each index message
+const restoreBatchSize = 100
+
 type ArchiveWorker struct {
-	sqsService   *queue.SQSService
+	messageQueue queue.Queue
 	repository   repository.PostgresRepository
 	logger       *logger.Logger
 	workerCount  int
 	pollInterval time.Duration
 	maxMessages  int32
 	waitTime     int32
-	shutdownChan chan struct{}
+	drain        *drain
 	waitGroup    sync.WaitGroup
 	s3Client     *s3.Client
 	s3Config     *config.S3Config
+	importer     *service.ImportService
+
+	// lifecycleRules are the Glacier transitions this worker applied per tenant
+	lifecycleMu    sync.Mutex
+	lifecycleRules map[string]lifecycleRuleState
 }
 
 func NewArchiveWorker(
-	sqsService *queue.SQSService,
+	messageQueue queue.Queue,
 	repository repository.PostgresRepository,
 	logger *logger.Logger,
-	workerCount int,
-	pollInterval time.Duration,
+	workerConfig *config.WorkerConfig,
 	s3Client *s3.Client,
 	s3Config *config.S3Config,
+	importer *service.ImportService,
 ) *ArchiveWorker {
 	return &ArchiveWorker{
-		sqsService:   sqsService,
+		messageQueue: messageQueue,
 		repository:   repository,
 		logger:       logger,
-		workerCount:  workerCount,
-		pollInterval: pollInterval,
-		maxMessages:  10,
+		workerCount:  workerConfig.Count,
+		pollInterval: workerConfig.PollInterval,
+		maxMessages:  int32(workerConfig.MaxMessages),
 		waitTime:     20,
-		shutdownChan: make(chan struct{}),
+		drain:        newDrain(messageQueue, queue.ArchiveQueue, workerConfig, logger),
 		s3Client:     s3Client,
 		s3Config:     s3Config,
+		importer:     importer,
+
+		lifecycleRules: make(map[string]lifecycleRuleState),
 	}
 }
 
@@ -66,8 +87,7 @@ func (w *ArchiveWorker) Start() {
 
 func (w *ArchiveWorker) Stop() {
 	w.logger.Info("Stopping Archive workers...")
-	close(w.shutdownChan)
-	w.waitGroup.Wait()
+	w.drain.stop(&w.waitGroup)
 	w.logger.Info("All Archive workers stopped")
 }
 
@@ -79,128 +99,549 @@ func (w *ArchiveWorker) runWorker(workerID int) {
 	ticker := time.NewTicker(w.pollInterval)
 	defer ticker.Stop()
 
+	throttle := newThrottle("archive", w.drain.config, w.logger)
+
 	for {
 		select {
-		case <-w.shutdownChan:
+		case <-w.drain.stopping():
 			w.logger.Infof("Archive Worker %d shutting down", workerID)
 			return
 		case <-ticker.C:
-			if err := w.processMessages(context.Background()); err != nil {
+			// Wait out a backoff before receiving more messages
+			if !throttle.wait(w.drain.receiveCtx) {
+				continue
+			}
+			if err := w.processMessages(w.drain.receiveCtx, throttle); err != nil {
 				w.logger.Errorf("Archive Worker %d failed to process messages: %v", workerID, err)
 			}
 		}
 	}
 }
 
-func (w *ArchiveWorker) processMessages(ctx context.Context) error {
-	// Get archive queue URL from config
-	config := config.DefaultSQSConfig()
-	archiveQueueURL := config.ArchiveQueueURL
-
-	messages, err := w.sqsService.ReceiveMessages(ctx, archiveQueueURL, w.maxMessages, w.waitTime)
+func (w *ArchiveWorker) processMessages(ctx context.Context, throttle *throttle) error {
+	messages, err := w.messageQueue.ReceiveMessages(ctx, queue.ArchiveQueue, w.maxMessages, w.waitTime)
 	if err != nil {
+		// Stop cancels the long poll
+		if ctx.Err() != nil {
+			return nil
+		}
 		return fmt.Errorf("failed to receive messages: %w", err)
 	}
 
-	for _, msg := range messages {
-		if msg.Message.Type == queue.MessageTypeArchive {
-			if err := w.processArchiveMessage(ctx, msg.Message); err != nil {
-				w.logger.Errorf("Failed to process archive message: %v", err)
-				continue
-			}
+	for i, msg := range messages {
+		// Pace the batch, and hand back what is left of it once the drain timeout passed
+		if !throttle.wait(w.drain.processCtx) {
+			w.drain.release(messages[i:], 0)
+			break
+		}
+		var process func(context.Context, queue.Message) error
+		switch msg.Message.Type {
+		case queue.MessageTypeArchive:
+			process = w.processArchiveMessage
+		case queue.MessageTypeRestore:
+			process = w.processRestoreMessage
+		case queue.MessageTypeImport:
+			process = w.processImportMessage
+		default:
+			continue
+		}
 
-			// Only delete the message if processing was successful
-			if err := w.sqsService.DeleteMessage(ctx, archiveQueueURL, msg.ReceiptHandle); err != nil {
-				w.logger.Errorf("Failed to delete message: %v", err)
-			}
+		start := time.Now()
+		msgCtx, span := w.messageQueue.StartConsumerSpan(w.drain.processCtx, queue.ArchiveQueue, msg.Message)
+		done := w.drain.hold(msg.ReceiptHandle)
+		err := process(msgCtx, msg.Message)
+		done(err)
+		tracing.End(span, err)
+		metrics.ObserveWorkerMessage(strings.ToLower(string(msg.Message.Type)), start, err)
+		// Hand the rest of the batch back until the backoff passes
+		if backoff := throttle.done(start, err); backoff > 0 {
+			w.drain.release(messages[i+1:], backoff)
+			break
+		}
+		if err != nil {
+			w.logger.Errorf("Failed to process %s message: %v", strings.ToLower(string(msg.Message.Type)), err)
+			continue
+		}
+
+		// Only delete the message if processing was successful
+		if err := w.messageQueue.DeleteMessage(context.Background(), queue.ArchiveQueue, msg.ReceiptHandle); err != nil {
+			w.logger.Errorf("Failed to delete message: %v", err)
 		}
 	}
 
 	return nil
 }
 
+// processArchiveMessage archives the tenant's logs before the message's date
+// and hands over to the cleanup worker. The cleanup job of cleanups scheduled
+// through the API is marked running and gets the archived count; archival
+// errors are recorded on it while the message is retried.
 func (w *ArchiveWorker) processArchiveMessage(ctx context.Context, msg queue.Message) error {
 	w.logger.Infof("Processing archive message for tenant %s (before: %s)",
 		msg.TenantID, msg.BeforeDate.Format(time.RFC3339))
 
-	filter := domain.AuditLogFilter{
-		TenantID: msg.TenantID,
-		EndTime:  msg.BeforeDate,
+	var job *domain.CleanupJob
+	if msg.JobID != "" {
+		var err error
+		job, err = w.repository.CleanupJob().GetByID(ctx, msg.TenantID, msg.JobID)
+		if err != nil {
+			return fmt.Errorf("failed to load cleanup job %s: %w", msg.JobID, err)
+		}
+
+		// A redelivered message for a finished job is a no-op
+		if job.Status.Done() {
+			return nil
+		}
+
+		job.Status = domain.JobRunning
+		job.Error = ""
+		if err := w.repository.CleanupJob().Update(ctx, job); err != nil {
+			return fmt.Errorf("failed to mark cleanup job %s running: %w", job.ID, err)
+		}
 	}
 
-	logs, err := w.repository.AuditLog().List(ctx, filter)
+	// Archive the logs to S3
+	manifest, err := w.archiveLogsToS3(ctx, msg.TenantID, msg.BeforeDate)
 	if err != nil {
-		return fmt.Errorf("failed to fetch logs for archival for tenant %s: %w", msg.TenantID, err)
+		err = fmt.Errorf("failed to archive logs for tenant %s: %w", msg.TenantID, err)
+		if job != nil {
+			job.Error = err.Error()
+			if err := w.repository.CleanupJob().Update(ctx, job); err != nil {
+				w.logger.Errorf("Failed to record error of cleanup job %s: %v", job.ID, err)
+			}
+		}
+		return err
 	}
 
-	if len(logs) == 0 {
+	if manifest == nil {
 		w.logger.Infof("No logs found for archival for tenant %s before %s", msg.TenantID, msg.BeforeDate.Format(time.RFC3339))
-		// Still enqueue cleanup message even if no logs found
-		return w.enqueueCleanupMessage(ctx, msg.TenantID, msg.BeforeDate)
+	} else {
+		w.logger.Infof("Successfully archived %d logs in %d parts for tenant %s to S3", manifest.LogCount, len(manifest.Parts), msg.TenantID)
+		if job != nil {
+			job.ArchivedCount = manifest.LogCount
+			if err := w.repository.CleanupJob().Update(ctx, job); err != nil {
+				return fmt.Errorf("failed to record archived count of cleanup job %s: %w", job.ID, err)
+			}
+		}
 	}
 
-	w.logger.Infof("Found %d logs to archive for tenant %s before %s", len(logs), msg.TenantID, msg.BeforeDate.Format(time.RFC3339))
+	// Enqueue cleanup message after successful archival, even if no logs were found
+	return w.enqueueCleanupMessage(ctx, msg.TenantID, msg.JobID, msg.BeforeDate)
+}
 
-	// Archive the logs to S3
-	if err := w.archiveLogsToS3(ctx, msg.TenantID, logs, msg.BeforeDate); err != nil {
-		return fmt.Errorf("failed to archive logs for tenant %s: %w", msg.TenantID, err)
+// archiveLogsToS3 streams the tenant's logs up to beforeDate from PostgreSQL in
+// batches into gzip-compressed NDJSON parts, then writes the archive manifest.
+// Memory use is bounded by one batch plus one multipart chunk regardless of
+// tenant size. Parts are stored in the tenant's storage class, the whole
+// archive is encrypted with the tenant's KMS key when one is set and locked
+// until its retention date when object lock applies. The
+// archive is recorded with the SHA-256 checksums of its manifest and parts for
+// later verification. It returns nil when there is nothing to archive.
+func (w *ArchiveWorker) archiveLogsToS3(ctx context.Context, tenantID string, beforeDate time.Time) (_ *archiveManifest, err error) {
+	storage, err := w.resolveArchiveStorage(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.ensureLifecycleRule(ctx, tenantID, storage); err != nil {
+		return nil, err
 	}
 
-	w.logger.Infof("Successfully archived %d logs for tenant %s to S3", len(logs), msg.TenantID)
+	dir := archiveDir(tenantID, beforeDate)
+	manifest := &archiveManifest{
+		TenantID:    tenantID,
+		BeforeDate:  beforeDate,
+		Format:      archiveFormatNDJSON,
+		RetainUntil: storage.retainUntil(time.Now()),
+		KMSKeyID:    storage.kmsKeyID,
+	}
 
-	// Enqueue cleanup message after successful archival
-	return w.enqueueCleanupMessage(ctx, msg.TenantID, msg.BeforeDate)
-}
+	var part *archivePartWriter
+	defer func() {
+		if err != nil && part != nil {
+			part.Abort(ctx)
+		}
+	}()
+
+	closePart := func() error {
+		info, err := part.Close(ctx)
+		if err != nil {
+			return err
+		}
+		part = nil
+		manifest.Parts = append(manifest.Parts, info)
+		manifest.LogCount += info.LogCount
+		return nil
+	}
+
+	filter := domain.AuditLogFilter{
+		TenantID: tenantID,
+		EndTime:  beforeDate,
+	}
+	var cursor *domain.AuditLogCursor
+	for {
+		batch, err := w.repository.AuditLog().ListBatch(ctx, filter, cursor, archiveBatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch logs for archival: %w", err)
+		}
+
+		for i := range batch {
+			if part == nil {
+				key := archivePartKey(dir, len(manifest.Parts)+1)
+				if part, err = newArchivePartWriter(ctx, w.s3Client, w.s3Config.BucketName, key, storage.partOptions(manifest.RetainUntil)); err != nil {
+					return nil, err
+				}
+			}
+			if err := part.Write(&batch[i]); err != nil {
+				return nil, err
+			}
+			if part.info.LogCount >= archivePartMaxLogs {
+				if err := closePart(); err != nil {
+					return nil, err
+				}
+			}
+		}
 
-func (w *ArchiveWorker) archiveLogsToS3(ctx context.Context, tenantID string, logs []domain.AuditLog, beforeDate time.Time) error {
-	// Create S3 key with timestamp and tenant
-	s3Key := fmt.Sprintf("audit-logs/%s/audit_logs_%s_before_%s.json",
-		tenantID,
-		tenantID,
-		beforeDate.Format("2006-01-02_15-04-05"))
+		if len(batch) < archiveBatchSize {
+			break
+		}
+		last := batch[len(batch)-1]
+		cursor = &domain.AuditLogCursor{Timestamp: last.Timestamp, ID: last.ID}
+	}
+
+	if part != nil {
+		if err := closePart(); err != nil {
+			return nil, err
+		}
+	}
 
-	// Prepare archive data
-	archiveData := map[string]interface{}{
-		"tenant_id":   tenantID,
-		"before_date": beforeDate,
-		"archived_at": time.Now(),
-		"log_count":   len(logs),
-		"logs":        logs,
+	if manifest.LogCount == 0 {
+		return nil, nil
 	}
 
-	// Convert to JSON
-	jsonData, err := json.MarshalIndent(archiveData, "", "  ")
+	// Write the manifest last; restores ignore archives without one
+	manifest.ArchivedAt = time.Now()
+	data, err := json.MarshalIndent(manifest, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal logs to JSON: %w", err)
+		return nil, fmt.Errorf("failed to marshal archive manifest: %w", err)
 	}
 
-	// Upload to S3
-	_, err = w.s3Client.PutObject(ctx, &s3.PutObjectInput{
-		Bucket:      &w.s3Config.BucketName,
-		Key:         &s3Key,
-		Body:        bytes.NewReader(jsonData),
-		ContentType: &[]string{"application/json"}[0],
+	manifestKey := dir + archiveManifestName
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(w.s3Config.BucketName),
+		Key:         aws.String(manifestKey),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/json"),
 		Metadata: map[string]string{
 			"tenant-id":   tenantID,
-			"archived-at": time.Now().Format(time.RFC3339),
-			"log-count":   fmt.Sprintf("%d", len(logs)),
+			"archived-at": manifest.ArchivedAt.Format(time.RFC3339),
+			"log-count":   fmt.Sprintf("%d", manifest.LogCount),
 			"before-date": beforeDate.Format(time.RFC3339),
 		},
+	}
+	if storage.kmsKeyID != "" {
+		input.Metadata["kms-key-id"] = storage.kmsKeyID
+	}
+	storage.manifestOptions(input, manifest.RetainUntil)
+	if _, err := w.s3Client.PutObject(ctx, input); err != nil {
+		return nil, fmt.Errorf("failed to upload archive manifest to S3: %w", err)
+	}
+
+	w.logger.Infof("Successfully uploaded archive to S3: s3://%s/%s", w.s3Config.BucketName, manifestKey)
+
+	if err := w.repository.Archive().Save(ctx, newArchiveRecord(manifest, manifestKey, data, storage)); err != nil {
+		return nil, fmt.Errorf("failed to record archive %s: %w", manifestKey, err)
+	}
+	return manifest, nil
+}
+
+func (w *ArchiveWorker) enqueueCleanupMessage(ctx context.Context, tenantID, jobID string, beforeDate time.Time) error {
+	if err := w.messageQueue.SendCleanupMessage(ctx, tenantID, jobID, beforeDate); err != nil {
+		return fmt.Errorf("failed to enqueue cleanup message: %w", err)
+	}
+
+	w.logger.Infof("Successfully enqueued cleanup message for tenant %s", tenantID)
+	return nil
+}
+
+// processRestoreMessage runs a restore job and records its outcome. Restore
+// failures are stored on the job and are not returned, so the message is not
+// redelivered; only failures to load or update the job itself are retried.
+func (w *ArchiveWorker) processRestoreMessage(ctx context.Context, msg queue.Message) error {
+	jobs := w.repository.RestoreJob()
+
+	job, err := jobs.GetByID(ctx, msg.TenantID, msg.JobID)
+	if err != nil {
+		return fmt.Errorf("failed to load restore job %s: %w", msg.JobID, err)
+	}
+
+	// A redelivered message for a finished job is a no-op
+	if job.Status.Done() {
+		return nil
+	}
+
+	job.Status = domain.JobRunning
+	if err := jobs.Update(ctx, job); err != nil {
+		return fmt.Errorf("failed to mark restore job %s running: %w", job.ID, err)
+	}
+
+	w.logger.Infof("Processing restore job %s for tenant %s (%s - %s)",
+		job.ID, job.TenantID, job.StartTime.Format(time.RFC3339), job.EndTime.Format(time.RFC3339))
+
+	restoreErr := w.restoreFromS3(ctx, job)
+
+	now := time.Now()
+	job.CompletedAt = &now
+	if restoreErr != nil {
+		w.logger.Errorf("Restore job %s failed: %v", job.ID, restoreErr)
+		job.Status = domain.JobFailed
+		job.Error = restoreErr.Error()
+	} else {
+		job.Status = domain.JobCompleted
+	}
+
+	if err := jobs.Update(ctx, job); err != nil {
+		return fmt.Errorf("failed to record restore job %s result: %w", job.ID, err)
+	}
+
+	if restoreErr == nil {
+		w.logger.Infof("Restored %d logs from %d archives for job %s", job.RestoredCount, job.ObjectsProcessed, job.ID)
+	}
+	return nil
+}
+
+// processImportMessage runs an import job and records its outcome, like
+// processRestoreMessage. Progress is saved on the job after every batch, so a
+// job interrupted with its worker resumes from its last checkpoint when the
+// message is redelivered. Files uploaded for the job are deleted once it is done.
+func (w *ArchiveWorker) processImportMessage(ctx context.Context, msg queue.Message) error {
+	jobs := w.repository.ImportJob()
+
+	job, err := jobs.GetByID(ctx, msg.TenantID, msg.JobID)
+	if err != nil {
+		return fmt.Errorf("failed to load import job %s: %w", msg.JobID, err)
+	}
+
+	// A redelivered message for a finished job is a no-op
+	if job.Status.Done() {
+		return nil
+	}
+
+	job.Status = domain.JobRunning
+	if err := jobs.Update(ctx, job); err != nil {
+		return fmt.Errorf("failed to mark import job %s running: %w", job.ID, err)
+	}
+
+	w.logger.Infof("Processing import job %s for tenant %s from %s after line %d", job.ID, job.TenantID, job.SourceURI, job.LinesRead)
+
+	importErr := w.importer.Run(ctx, job, func(job *domain.ImportJob) {
+		if err := jobs.Update(ctx, job); err != nil {
+			w.logger.Errorf("Failed to save import job %s progress: %v", job.ID, err)
+		}
 	})
 
+	now := time.Now()
+	job.CompletedAt = &now
+	if importErr != nil {
+		w.logger.Errorf("Import job %s failed: %v", job.ID, importErr)
+		job.Status = domain.JobFailed
+		job.Error = importErr.Error()
+	} else {
+		job.Status = domain.JobCompleted
+	}
+
+	if err := jobs.Update(ctx, job); err != nil {
+		return fmt.Errorf("failed to record import job %s result: %w", job.ID, err)
+	}
+
+	if err := w.importer.RemoveUpload(ctx, job); err != nil {
+		w.logger.Errorf("Failed to delete upload of import job %s: %v", job.ID, err)
+	}
+
+	if importErr == nil {
+		w.logger.Infof("Imported %d logs from %d lines for job %s (%d already existed, %d invalid)",
+			job.ImportedCount, job.LinesRead, job.ID, job.SkippedCount, job.InvalidCount)
+	}
+	return nil
+}
+
+// restoreFromS3 re-imports logs in the job's time range from every archive that
+// may contain them, and re-indexes them through the index queue. Progress is
+// saved on the job after each archive. Archives with parts in Glacier are
+// requested for restore and skipped, failing the job so it can be retried.
+func (w *ArchiveWorker) restoreFromS3(ctx context.Context, job *domain.RestoreJob) error {
+	keys, err := w.listArchives(ctx, job.TenantID, job.StartTime, job.EndTime)
 	if err != nil {
-		return fmt.Errorf("failed to upload archive to S3: %w", err)
+		return err
+	}
+
+	var inGlacier []error
+	for _, key := range keys {
+		err := w.readArchive(ctx, key, job.StartTime, job.EndTime, func(logs []domain.AuditLog) error {
+			var batch []domain.AuditLog
+			for _, log := range logs {
+				if log.TenantID == job.TenantID && !log.Timestamp.Before(job.StartTime) && !log.Timestamp.After(job.EndTime) {
+					batch = append(batch, log)
+				}
+			}
+			if len(batch) == 0 {
+				return nil
+			}
+
+			restored, err := w.repository.AuditLog().Restore(ctx, batch)
+			if err != nil {
+				return fmt.Errorf("failed to restore logs from %s: %w", key, err)
+			}
+			job.RestoredCount += restored
+
+			// Re-index every log in range, including ones already in PostgreSQL, in case they were dropped from OpenSearch
+			if err := w.messageQueue.SendBulkIndexMessage(ctx, batch); err != nil {
+				return fmt.Errorf("failed to enqueue index message: %w", err)
+			}
+			return nil
+		})
+		if errors.Is(err, errArchiveInGlacier) {
+			inGlacier = append(inGlacier, err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		job.ObjectsProcessed++
+		if err := w.repository.RestoreJob().Update(ctx, job); err != nil {
+			w.logger.Errorf("Failed to save restore job %s progress: %v", job.ID, err)
+		}
 	}
 
-	w.logger.Infof("Successfully uploaded archive to S3: s3://%s/%s", w.s3Config.BucketName, s3Key)
+	if len(inGlacier) > 0 {
+		return fmt.Errorf("restores from Glacier were requested, retry once they complete, usually within 12 hours: %w", errors.Join(inGlacier...))
+	}
 	return nil
 }
 
-func (w *ArchiveWorker) enqueueCleanupMessage(ctx context.Context, tenantID string, beforeDate time.Time) error {
-	if err := w.sqsService.SendCleanupMessage(ctx, tenantID, beforeDate); err != nil {
-		return fmt.Errorf("failed to enqueue cleanup message: %w", err)
+// listArchives returns the keys of the tenant's archives that may hold logs in
+// [startTime, endTime], oldest first. Each archive holds the logs older than its
+// before date that were still in PostgreSQL, i.e. newer than the previous archive's.
+func (w *ArchiveWorker) listArchives(ctx context.Context, tenantID string, startTime, endTime time.Time) ([]string, error) {
+	type archive struct {
+		key        string
+		beforeDate time.Time
 	}
 
-	w.logger.Infof("Successfully enqueued cleanup message for tenant %s", tenantID)
+	var archives []archive
+	paginator := s3.NewListObjectsV2Paginator(w.s3Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(w.s3Config.BucketName),
+		Prefix: aws.String(archivePrefix(tenantID)),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list archives: %w", err)
+		}
+		for _, obj := range page.Contents {
+			beforeDate, ok := archiveBeforeDate(aws.ToString(obj.Key))
+			if !ok {
+				continue
+			}
+			archives = append(archives, archive{key: aws.ToString(obj.Key), beforeDate: beforeDate})
+		}
+	}
+
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].beforeDate.Before(archives[j].beforeDate)
+	})
+
+	var keys []string
+	for i, a := range archives {
+		if a.beforeDate.Before(startTime) {
+			continue
+		}
+		if i > 0 && archives[i-1].beforeDate.After(endTime) {
+			break
+		}
+		keys = append(keys, a.key)
+	}
+
+	return keys, nil
+}
+
+// readArchive streams the logs of an archive to fn in batches of up to
+// restoreBatchSize. For manifest-based archives only parts overlapping
+// [startTime, endTime] are downloaded.
+func (w *ArchiveWorker) readArchive(ctx context.Context, key string, startTime, endTime time.Time, fn func([]domain.AuditLog) error) error {
+	body, err := w.getObject(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if path.Base(key) != archiveManifestName {
+		return readLegacyArchive(key, body, fn)
+	}
+
+	var manifest archiveManifest
+	if err := json.NewDecoder(body).Decode(&manifest); err != nil {
+		return fmt.Errorf("failed to decode archive manifest %s: %w", key, err)
+	}
+
+	// Parts in Glacier are all requested for restore before the job fails,
+	// so a single retry once they're restored reads the whole archive
+	inGlacier := 0
+	for _, part := range manifest.Parts {
+		if !part.overlaps(startTime, endTime) {
+			continue
+		}
+
+		partBody, err := w.getObject(ctx, part.Key)
+		if errors.Is(err, errArchiveInGlacier) {
+			inGlacier++
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		err = decodeArchivePart(partBody, restoreBatchSize, fn)
+		partBody.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read archive part %s: %w", part.Key, err)
+		}
+	}
+
+	if inGlacier > 0 {
+		return fmt.Errorf("%w: %d parts of %s", errArchiveInGlacier, inGlacier, key)
+	}
+	return nil
+}
+
+func (w *ArchiveWorker) getObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := w.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(w.s3Config.BucketName),
+		Key:    aws.String(key),
+	})
+	// Objects moved to Glacier have to be restored before they can be read
+	var invalidState *types.InvalidObjectState
+	if errors.As(err, &invalidState) {
+		return nil, w.requestGlacierRestore(ctx, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to download archive object %s: %w", key, err)
+	}
+	return out.Body, nil
+}
+
+// readLegacyArchive reads a single-file JSON archive written before archives were split into parts
+func readLegacyArchive(key string, r io.Reader, fn func([]domain.AuditLog) error) error {
+	var archive struct {
+		Logs []domain.AuditLog `json:"logs"`
+	}
+	if err := json.NewDecoder(r).Decode(&archive); err != nil {
+		return fmt.Errorf("failed to decode archive %s: %w", key, err)
+	}
+
+	for start := 0; start < len(archive.Logs); start += restoreBatchSize {
+		if err := fn(archive.Logs[start:min(start+restoreBatchSize, len(archive.Logs))]); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -7,39 +7,49 @@ import (
 	"time"
 
 	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
 	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/repository/opensearch"
+	"github.com/kingrain94/audit-log-api/internal/service/pubsub"
 	"github.com/kingrain94/audit-log-api/internal/service/queue"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
 	"github.com/kingrain94/audit-log-api/pkg/logger"
 )
 
 type CleanupWorker struct {
-	sqsService   *queue.SQSService
+	messageQueue queue.Queue
 	repository   repository.PostgresRepository
+	osRepository opensearch.Repository
+	pubsub       pubsub.Broker
 	logger       *logger.Logger
 	workerCount  int
 	pollInterval time.Duration
 	maxMessages  int32
 	waitTime     int32
-	shutdownChan chan struct{}
+	drain        *drain
 	waitGroup    sync.WaitGroup
 }
 
 func NewCleanupWorker(
-	sqsService *queue.SQSService,
+	messageQueue queue.Queue,
 	repository repository.PostgresRepository,
+	osRepository opensearch.Repository,
+	pubsub pubsub.Broker,
 	logger *logger.Logger,
-	workerCount int,
-	pollInterval time.Duration,
+	workerConfig *config.WorkerConfig,
 ) *CleanupWorker {
 	return &CleanupWorker{
-		sqsService:   sqsService,
+		messageQueue: messageQueue,
 		repository:   repository,
+		osRepository: osRepository,
+		pubsub:       pubsub,
 		logger:       logger,
-		workerCount:  workerCount,
-		pollInterval: pollInterval,
-		maxMessages:  10,
+		workerCount:  workerConfig.Count,
+		pollInterval: workerConfig.PollInterval,
+		maxMessages:  int32(workerConfig.MaxMessages),
 		waitTime:     20,
-		shutdownChan: make(chan struct{}),
+		drain:        newDrain(messageQueue, queue.CleanupQueue, workerConfig, logger),
 	}
 }
 
@@ -55,8 +65,7 @@ func (w *CleanupWorker) Start() {
 
 func (w *CleanupWorker) Stop() {
 	w.logger.Info("Stopping Cleanup workers...")
-	close(w.shutdownChan)
-	w.waitGroup.Wait()
+	w.drain.stop(&w.waitGroup)
 	w.logger.Info("All Cleanup workers stopped")
 }
 
@@ -68,58 +77,157 @@ func (w *CleanupWorker) runWorker(workerID int) {
 	ticker := time.NewTicker(w.pollInterval)
 	defer ticker.Stop()
 
+	throttle := newThrottle("cleanup", w.drain.config, w.logger)
+
 	for {
 		select {
-		case <-w.shutdownChan:
+		case <-w.drain.stopping():
 			w.logger.Infof("Cleanup Worker %d shutting down", workerID)
 			return
 		case <-ticker.C:
-			if err := w.processMessages(context.Background()); err != nil {
+			// Wait out a backoff before receiving more messages
+			if !throttle.wait(w.drain.receiveCtx) {
+				continue
+			}
+			if err := w.processMessages(w.drain.receiveCtx, throttle); err != nil {
 				w.logger.Errorf("Cleanup Worker %d failed to process messages: %v", workerID, err)
 			}
 		}
 	}
 }
 
-func (w *CleanupWorker) processMessages(ctx context.Context) error {
-	// Get cleanup queue URL from config
-	config := config.DefaultSQSConfig()
-	cleanupQueueURL := config.CleanupQueueURL
-
-	messages, err := w.sqsService.ReceiveMessages(ctx, cleanupQueueURL, w.maxMessages, w.waitTime)
+func (w *CleanupWorker) processMessages(ctx context.Context, throttle *throttle) error {
+	messages, err := w.messageQueue.ReceiveMessages(ctx, queue.CleanupQueue, w.maxMessages, w.waitTime)
 	if err != nil {
+		// Stop cancels the long poll
+		if ctx.Err() != nil {
+			return nil
+		}
 		return fmt.Errorf("failed to receive messages: %w", err)
 	}
 
-	for _, msg := range messages {
-		if msg.Message.Type == queue.MessageTypeCleanup {
-			if err := w.processCleanupMessage(ctx, msg.Message); err != nil {
-				w.logger.Errorf("Failed to process cleanup message: %v", err)
-				continue
-			}
+	for i, msg := range messages {
+		// Pace the batch, and hand back what is left of it once the drain timeout passed
+		if !throttle.wait(w.drain.processCtx) {
+			w.drain.release(messages[i:], 0)
+			break
+		}
+		if msg.Message.Type != queue.MessageTypeCleanup {
+			continue
+		}
 
-			// Only delete the message if processing was successful
-			if err := w.sqsService.DeleteMessage(ctx, cleanupQueueURL, msg.ReceiptHandle); err != nil {
-				w.logger.Errorf("Failed to delete message: %v", err)
-			}
+		start := time.Now()
+		msgCtx, span := w.messageQueue.StartConsumerSpan(w.drain.processCtx, queue.CleanupQueue, msg.Message)
+		done := w.drain.hold(msg.ReceiptHandle)
+		err := w.processCleanupMessage(msgCtx, msg.Message)
+		done(err)
+		tracing.End(span, err)
+		metrics.ObserveWorkerMessage("cleanup", start, err)
+		// Hand the rest of the batch back until the backoff passes
+		if backoff := throttle.done(start, err); backoff > 0 {
+			w.drain.release(messages[i+1:], backoff)
+			break
+		}
+		if err != nil {
+			w.logger.Errorf("Failed to process cleanup message: %v", err)
+			continue
+		}
+
+		// Only delete the message if processing was successful
+		if err := w.messageQueue.DeleteMessage(context.Background(), queue.CleanupQueue, msg.ReceiptHandle); err != nil {
+			w.logger.Errorf("Failed to delete message: %v", err)
 		}
 	}
 
 	return nil
 }
 
+// processCleanupMessage deletes the tenant's logs before the message's date
+// from PostgreSQL and OpenSearch, completing the cleanup job the message
+// references or recording the run as a new one
 func (w *CleanupWorker) processCleanupMessage(ctx context.Context, msg queue.Message) error {
 	w.logger.Infof("Processing cleanup message for tenant %s (before: %s)",
 		msg.TenantID, msg.BeforeDate.Format(time.RFC3339))
 
-	// Delete logs before the specified date for the tenant
-	deletedCount, err := w.repository.AuditLog().DeleteBeforeDate(ctx, msg.TenantID, msg.BeforeDate)
+	job, err := w.startJob(ctx, msg)
 	if err != nil {
-		return fmt.Errorf("failed to delete logs for tenant %s: %w", msg.TenantID, err)
+		return err
+	}
+	// A redelivered message for a finished job is a no-op
+	if job.Status.Done() {
+		return nil
 	}
 
-	w.logger.Infof("Successfully deleted %d logs for tenant %s (before: %s)",
-		deletedCount, msg.TenantID, msg.BeforeDate.Format(time.RFC3339))
+	if err := w.cleanup(ctx, job); err != nil {
+		job.Status = domain.JobFailed
+		job.Error = err.Error()
+		w.finish(job)
+		return err
+	}
 
+	job.Status = domain.JobCompleted
+	w.finish(job)
+
+	w.logger.Infof("Successfully deleted %d logs and %d indexed logs for tenant %s (before: %s)",
+		job.DeletedCount, job.IndexedCount, msg.TenantID, msg.BeforeDate.Format(time.RFC3339))
+
+	event := pubsub.Event{
+		Type:     pubsub.EventCleanupCompleted,
+		TenantID: job.TenantID,
+		Time:     *job.CompletedAt,
+		Data:     job,
+	}
+	if err := w.pubsub.PublishEvent(ctx, event); err != nil {
+		w.logger.Errorf("Failed to publish cleanup completion for tenant %s: %v", job.TenantID, err)
+	}
 	return nil
 }
+
+// startJob loads the job of a cleanup scheduled through the API, or creates one
+func (w *CleanupWorker) startJob(ctx context.Context, msg queue.Message) (*domain.CleanupJob, error) {
+	if msg.JobID != "" {
+		job, err := w.repository.CleanupJob().GetByID(ctx, msg.TenantID, msg.JobID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load cleanup job %s: %w", msg.JobID, err)
+		}
+		return job, nil
+	}
+
+	job := &domain.CleanupJob{
+		TenantID:   msg.TenantID,
+		Status:     domain.JobRunning,
+		BeforeDate: msg.BeforeDate,
+	}
+	if err := w.repository.CleanupJob().Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create cleanup job: %w", err)
+	}
+	return job, nil
+}
+
+// cleanup deletes the job's logs, counting them on the job
+func (w *CleanupWorker) cleanup(ctx context.Context, job *domain.CleanupJob) error {
+	deletedCount, err := w.repository.AuditLog().DeleteBeforeDate(ctx, job.TenantID, job.BeforeDate)
+	if err != nil {
+		return fmt.Errorf("failed to delete logs for tenant %s: %w", job.TenantID, err)
+	}
+	job.DeletedCount = deletedCount
+
+	// Purge the search index in sync, so searches don't return logs that are
+	// gone. A failure redelivers the message, and deleting again is harmless.
+	indexedCount, err := w.osRepository.DeleteRange(ctx, job.TenantID, time.Time{}, job.BeforeDate)
+	if err != nil {
+		return fmt.Errorf("failed to delete indexed logs for tenant %s: %w", job.TenantID, err)
+	}
+	job.IndexedCount = indexedCount
+	return nil
+}
+
+// finish records the final state of the job. It is written even when the
+// message context was cancelled, and a failure to write it is only logged.
+func (w *CleanupWorker) finish(job *domain.CleanupJob) {
+	completedAt := time.Now()
+	job.CompletedAt = &completedAt
+	if err := w.repository.CleanupJob().Update(context.Background(), job); err != nil {
+		w.logger.Errorf("Failed to update cleanup job %s: %v", job.ID, err)
+	}
+}
@@ -0,0 +1,99 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	"github.com/kingrain94/audit-log-api/internal/service/pubsub"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+// detectionPruneInterval is how often the detection state is pruned
+const detectionPruneInterval = time.Minute
+
+// DetectionWorker evaluates the built-in detection rules on every log
+// broadcast, alerting on brute force and impossible travel as they happen.
+// Logs are queued off the subscription, so a slow alert never holds back the
+// broker; when the queue is full, logs are dropped unevaluated.
+type DetectionWorker struct {
+	detector     *service.DetectionService
+	broker       pubsub.Broker
+	logger       *logger.Logger
+	queue        chan *dto.AuditLogResponse
+	shutdownChan chan struct{}
+	waitGroup    sync.WaitGroup
+}
+
+func NewDetectionWorker(
+	detector *service.DetectionService,
+	broker pubsub.Broker,
+	logger *logger.Logger,
+	queueSize int,
+) *DetectionWorker {
+	return &DetectionWorker{
+		detector:     detector,
+		broker:       broker,
+		logger:       logger,
+		queue:        make(chan *dto.AuditLogResponse, queueSize),
+		shutdownChan: make(chan struct{}),
+	}
+}
+
+func (w *DetectionWorker) Start() error {
+	w.logger.Info("Starting Detection worker...")
+
+	if err := w.broker.SubscribeAll(context.Background(), w.enqueue); err != nil {
+		return fmt.Errorf("failed to subscribe to logs: %w", err)
+	}
+
+	w.waitGroup.Add(1)
+	go w.run()
+	return nil
+}
+
+func (w *DetectionWorker) Stop() {
+	w.logger.Info("Stopping Detection worker...")
+	w.broker.UnsubscribeAll()
+	close(w.shutdownChan)
+	w.waitGroup.Wait()
+	w.logger.Info("Detection worker stopped")
+}
+
+func (w *DetectionWorker) enqueue(log *dto.AuditLogResponse) {
+	select {
+	case w.queue <- log:
+	default:
+		metrics.DetectionLogsDroppedTotal.Inc()
+	}
+}
+
+func (w *DetectionWorker) run() {
+	defer w.waitGroup.Done()
+
+	ticker := time.NewTicker(detectionPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.shutdownChan:
+			w.logger.Info("Detection worker shutting down")
+			return
+		case now := <-ticker.C:
+			w.detector.Prune(now)
+		case log := <-w.queue:
+			alerts := w.detector.Evaluate(log)
+			for _, alert := range alerts {
+				w.logger.Warnf("Security alert %s for tenant %s: ip %s, user %s",
+					alert.Rule, alert.TenantID, alert.IPAddress, alert.UserID)
+			}
+			if err := w.detector.Alert(context.Background(), alerts); err != nil {
+				w.logger.Errorf("Failed to raise security alerts for tenant %s: %v", log.TenantID, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/service/queue"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+// drain coordinates the shutdown of a queue worker. Stopping cancels the
+// receive context at once so long polls return, lets the messages already
+// received finish until the drain timeout, and only then cancels the process
+// context and hands the remaining messages back to the queue.
+type drain struct {
+	messageQueue    queue.Queue
+	queueName       queue.Name
+	config          *config.WorkerConfig
+	logger          *logger.Logger
+	receiveCtx      context.Context
+	stopReceiving   context.CancelFunc
+	processCtx      context.Context
+	abortProcessing context.CancelFunc
+}
+
+func newDrain(messageQueue queue.Queue, queueName queue.Name, cfg *config.WorkerConfig, logger *logger.Logger) *drain {
+	receiveCtx, stopReceiving := context.WithCancel(context.Background())
+	processCtx, abortProcessing := context.WithCancel(context.Background())
+	return &drain{
+		messageQueue:    messageQueue,
+		queueName:       queueName,
+		config:          cfg,
+		logger:          logger,
+		receiveCtx:      receiveCtx,
+		stopReceiving:   stopReceiving,
+		processCtx:      processCtx,
+		abortProcessing: abortProcessing,
+	}
+}
+
+// stopping is closed once stop is called
+func (d *drain) stopping() <-chan struct{} {
+	return d.receiveCtx.Done()
+}
+
+// aborted reports whether the drain timeout passed and processing was cancelled
+func (d *drain) aborted() bool {
+	return d.processCtx.Err() != nil
+}
+
+// stop stops receiving and waits for the workers to finish their current
+// batch, cancelling processing if that takes longer than the drain timeout
+func (d *drain) stop(waitGroup *sync.WaitGroup) {
+	d.stopReceiving()
+
+	done := make(chan struct{})
+	go func() {
+		waitGroup.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(d.config.DrainTimeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		d.logger.Warnf("%s workers did not drain within %s, releasing unprocessed messages", d.queueName, d.config.DrainTimeout)
+		d.abortProcessing()
+		<-done
+	}
+	d.abortProcessing()
+}
+
+// hold keeps a message hidden from other consumers while it is processed by
+// extending its visibility every half VisibilityExtension. The returned func
+// must be called with the processing result; a message whose processing was
+// cut short by the drain timeout is released for immediate redelivery.
+func (d *drain) hold(receiptHandle *string) func(err error) {
+	stopHeartbeat := make(chan struct{})
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		ticker := time.NewTicker(d.config.VisibilityExtension / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopHeartbeat:
+				return
+			case <-ticker.C:
+				if err := d.messageQueue.ChangeMessageVisibility(context.Background(), d.queueName, receiptHandle, d.config.VisibilityExtension); err != nil {
+					d.logger.Warnf("Failed to extend message visibility: %v", err)
+				}
+			}
+		}
+	}()
+
+	return func(err error) {
+		close(stopHeartbeat)
+		<-heartbeatDone
+		if err != nil && d.aborted() {
+			d.release([]queue.ReceivedMessage{{ReceiptHandle: receiptHandle}}, 0)
+		}
+	}
+}
+
+// release makes messages that will not be processed visible again after delay,
+// right away for a zero delay, instead of after their visibility timeout
+func (d *drain) release(messages []queue.ReceivedMessage, delay time.Duration) {
+	for _, msg := range messages {
+		if err := d.messageQueue.ChangeMessageVisibility(context.Background(), d.queueName, msg.ReceiptHandle, delay); err != nil {
+			d.logger.Warnf("Failed to release message: %v", err)
+		}
+	}
+	if len(messages) > 0 {
+		d.logger.Infof("Released %d unprocessed %s messages", len(messages), d.queueName)
+	}
+}
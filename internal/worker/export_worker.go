@@ -0,0 +1,299 @@
+package worker
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	"github.com/kingrain94/audit-log-api/internal/service/queue"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+	"github.com/kingrain94/audit-log-api/pkg/envelope"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+const (
+	// exportBatchSize is the number of logs read from PostgreSQL per query
+	exportBatchSize = 1000
+
+	// encryptedExportSuffix is appended to the keys of exports encrypted for
+	// the tenant's export public key
+	encryptedExportSuffix = ".enc"
+)
+
+type ExportWorker struct {
+	messageQueue queue.Queue
+	repository   repository.PostgresRepository
+	search       repository.OpenSearchRepository
+	logger       *logger.Logger
+	workerCount  int
+	pollInterval time.Duration
+	maxMessages  int32
+	waitTime     int32
+	drain        *drain
+	waitGroup    sync.WaitGroup
+	s3Client     *s3.Client
+	s3Config     *config.S3Config
+	signer       *service.ExportSigner
+}
+
+func NewExportWorker(
+	messageQueue queue.Queue,
+	repository repository.PostgresRepository,
+	search repository.OpenSearchRepository,
+	logger *logger.Logger,
+	workerConfig *config.WorkerConfig,
+	s3Client *s3.Client,
+	s3Config *config.S3Config,
+	signer *service.ExportSigner,
+) *ExportWorker {
+	return &ExportWorker{
+		messageQueue: messageQueue,
+		repository:   repository,
+		search:       search,
+		logger:       logger,
+		workerCount:  workerConfig.Count,
+		pollInterval: workerConfig.PollInterval,
+		maxMessages:  int32(workerConfig.MaxMessages),
+		waitTime:     20,
+		drain:        newDrain(messageQueue, queue.ExportQueue, workerConfig, logger),
+		s3Client:     s3Client,
+		s3Config:     s3Config,
+		signer:       signer,
+	}
+}
+
+func (w *ExportWorker) Start() {
+	w.logger.Info("Starting Export workers...")
+
+	for i := 0; i < w.workerCount; i++ {
+		w.waitGroup.Add(1)
+		go w.runWorker(i)
+	}
+}
+
+func (w *ExportWorker) Stop() {
+	w.logger.Info("Stopping Export workers...")
+	w.drain.stop(&w.waitGroup)
+	w.logger.Info("All Export workers stopped")
+}
+
+func (w *ExportWorker) runWorker(workerID int) {
+	defer w.waitGroup.Done()
+
+	w.logger.Infof("Export Worker %d started", workerID)
+
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	throttle := newThrottle("export", w.drain.config, w.logger)
+
+	for {
+		select {
+		case <-w.drain.stopping():
+			w.logger.Infof("Export Worker %d shutting down", workerID)
+			return
+		case <-ticker.C:
+			// Wait out a backoff before receiving more messages
+			if !throttle.wait(w.drain.receiveCtx) {
+				continue
+			}
+			if err := w.processMessages(w.drain.receiveCtx, throttle); err != nil {
+				w.logger.Errorf("Export Worker %d failed to process messages: %v", workerID, err)
+			}
+		}
+	}
+}
+
+func (w *ExportWorker) processMessages(ctx context.Context, throttle *throttle) error {
+	messages, err := w.messageQueue.ReceiveMessages(ctx, queue.ExportQueue, w.maxMessages, w.waitTime)
+	if err != nil {
+		// Stop cancels the long poll
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("failed to receive messages: %w", err)
+	}
+
+	for i, msg := range messages {
+		// Pace the batch, and hand back what is left of it once the drain timeout passed
+		if !throttle.wait(w.drain.processCtx) {
+			w.drain.release(messages[i:], 0)
+			break
+		}
+		if msg.Message.Type != queue.MessageTypeExport {
+			continue
+		}
+
+		start := time.Now()
+		msgCtx, span := w.messageQueue.StartConsumerSpan(w.drain.processCtx, queue.ExportQueue, msg.Message)
+		done := w.drain.hold(msg.ReceiptHandle)
+		err := w.processExportMessage(msgCtx, msg.Message)
+		done(err)
+		tracing.End(span, err)
+		metrics.ObserveWorkerMessage("export", start, err)
+		// Hand the rest of the batch back until the backoff passes
+		if backoff := throttle.done(start, err); backoff > 0 {
+			w.drain.release(messages[i+1:], backoff)
+			break
+		}
+		if err != nil {
+			w.logger.Errorf("Failed to process export message: %v", err)
+			continue
+		}
+
+		// Only delete the message once the job has reached a final state
+		if err := w.messageQueue.DeleteMessage(context.Background(), queue.ExportQueue, msg.ReceiptHandle); err != nil {
+			w.logger.Errorf("Failed to delete message: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// processExportMessage runs the export job and records its outcome. Export
+// failures are stored on the job and are not returned, so the message is not
+// redelivered; only failures to load or update the job itself are retried.
+func (w *ExportWorker) processExportMessage(ctx context.Context, msg queue.Message) error {
+	jobs := w.repository.ExportJob()
+
+	job, err := jobs.GetByID(ctx, msg.TenantID, msg.JobID)
+	if err != nil {
+		return fmt.Errorf("failed to load export job %s: %w", msg.JobID, err)
+	}
+
+	// A redelivered message for a finished job is a no-op
+	if job.Status.Done() {
+		return nil
+	}
+
+	job.Status = domain.JobRunning
+	if err := jobs.Update(ctx, job); err != nil {
+		return fmt.Errorf("failed to mark export job %s running: %w", job.ID, err)
+	}
+
+	w.logger.Infof("Processing export job %s for tenant %s", job.ID, job.TenantID)
+
+	var (
+		s3Key     string
+		rowCount  int64
+		exportErr error
+	)
+	if job.Scope == domain.ExportScopeTenant {
+		rowCount, s3Key, exportErr = w.dumpTenant(ctx, job)
+	} else {
+		rowCount, s3Key, exportErr = w.exportToS3(ctx, job)
+	}
+
+	// An export cut short by the drain timeout is left running for redelivery
+	if exportErr != nil && ctx.Err() != nil {
+		return fmt.Errorf("export job %s interrupted: %w", job.ID, exportErr)
+	}
+
+	now := time.Now()
+	job.CompletedAt = &now
+	job.RowCount = rowCount
+	if exportErr != nil {
+		w.logger.Errorf("Export job %s failed: %v", job.ID, exportErr)
+		job.Status = domain.JobFailed
+		job.Error = exportErr.Error()
+	} else {
+		job.Status = domain.JobCompleted
+		job.S3Key = s3Key
+	}
+
+	if err := jobs.Update(ctx, job); err != nil {
+		return fmt.Errorf("failed to record export job %s result: %w", job.ID, err)
+	}
+
+	if exportErr == nil {
+		w.logger.Infof("Exported %d logs for job %s to s3://%s/%s", rowCount, job.ID, w.s3Config.ExportBucket, s3Key)
+	}
+	return nil
+}
+
+// exportToS3 pages through the matching logs and streams them to S3 as a
+// multipart upload, so memory use is bounded by a single part, and returns the
+// number of logs exported and the object's key. The object is encrypted with
+// the tenant's KMS key, and for its export public key when it has one, in
+// which case the job is marked encrypted. Jobs asking for a signature are
+// signed with the SHA-256 digest of the object as uploaded.
+func (w *ExportWorker) exportToS3(ctx context.Context, job *domain.ExportJob) (int64, string, error) {
+	if job.SignWith != "" && w.signer == nil {
+		return 0, "", errors.New("export signing is not configured on the export worker")
+	}
+
+	tenant, err := w.tenant(ctx, job.TenantID)
+	if err != nil {
+		return 0, "", err
+	}
+	encryption := tenant.Settings.Encryption
+
+	key := fmt.Sprintf("exports/%s/%s.%s", job.TenantID, job.ID, job.Format)
+	contentType := job.Format.ContentType()
+	options := []func(*s3.CreateMultipartUploadInput){encryptUpload(kmsKeyID(w.s3Config, encryption))}
+	var recipient *rsa.PublicKey
+	if encryption.ExportPublicKey != "" {
+		if recipient, err = envelope.ParsePublicKey(encryption.ExportPublicKey); err != nil {
+			return 0, "", fmt.Errorf("failed to read export public key: %w", err)
+		}
+		key += encryptedExportSuffix
+		contentType = "application/octet-stream"
+		options = append(options, func(input *s3.CreateMultipartUploadInput) {
+			input.Metadata = map[string]string{"encryption": envelope.Scheme}
+		})
+	}
+
+	upload, err := newMultipartUpload(ctx, w.s3Client, w.s3Config.ExportBucket, key, contentType, options...)
+	if err != nil {
+		return 0, "", err
+	}
+
+	var out io.Writer = upload
+	var sealed *envelope.Writer
+	if recipient != nil {
+		if sealed, err = envelope.NewWriter(upload, recipient); err != nil {
+			upload.Abort(ctx)
+			return 0, "", fmt.Errorf("failed to encrypt export: %w", err)
+		}
+		out = sealed
+	}
+
+	rowCount, err := service.WriteExport(ctx, out, job.Format, job.Filter, w.repository.AuditLog(), w.search)
+	if err != nil {
+		upload.Abort(ctx)
+		return rowCount, "", err
+	}
+
+	if sealed != nil {
+		if err := sealed.Close(); err != nil {
+			upload.Abort(ctx)
+			return rowCount, "", fmt.Errorf("failed to encrypt export: %w", err)
+		}
+	}
+	if err := upload.Complete(ctx); err != nil {
+		upload.Abort(ctx)
+		return rowCount, "", err
+	}
+
+	job.Encrypted = recipient != nil
+	if job.SignWith != "" {
+		digest, err := hex.DecodeString(upload.SHA256())
+		if err != nil {
+			return rowCount, "", fmt.Errorf("failed to sign export: %w", err)
+		}
+		job.Signature = w.signer.Sign(job.TenantID, job.SignWith, digest)
+	}
+	return rowCount, key, nil
+}
@@ -0,0 +1,113 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+// ForwardingWorker forwards the logs of tenants' Splunk and Datadog
+// integrations, up to Concurrency integrations at a time. Integrations are
+// leased, so workers can run side by side; on stop, the deliveries in flight
+// are cancelled and the progress made so far is saved.
+type ForwardingWorker struct {
+	forwarder    *service.ForwardingService
+	config       *config.ForwardingConfig
+	logger       *logger.Logger
+	slots        chan struct{}
+	cancel       context.CancelFunc
+	shutdownChan chan struct{}
+	waitGroup    sync.WaitGroup
+}
+
+func NewForwardingWorker(
+	forwarder *service.ForwardingService,
+	config *config.ForwardingConfig,
+	logger *logger.Logger,
+) *ForwardingWorker {
+	return &ForwardingWorker{
+		forwarder:    forwarder,
+		config:       config,
+		logger:       logger,
+		slots:        make(chan struct{}, config.Concurrency),
+		shutdownChan: make(chan struct{}),
+	}
+}
+
+func (w *ForwardingWorker) Start() {
+	w.logger.Info("Starting Forwarding worker...")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+
+	w.waitGroup.Add(1)
+	go w.run(ctx)
+}
+
+func (w *ForwardingWorker) Stop() {
+	w.logger.Info("Stopping Forwarding worker...")
+	close(w.shutdownChan)
+	w.cancel()
+	w.waitGroup.Wait()
+	w.logger.Info("Forwarding worker stopped")
+}
+
+func (w *ForwardingWorker) run(ctx context.Context) {
+	defer w.waitGroup.Done()
+
+	ticker := time.NewTicker(w.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		w.claim(ctx)
+
+		select {
+		case <-w.shutdownChan:
+			w.logger.Info("Forwarding worker shutting down")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// claim leases as many due integrations as there are free slots and
+// forwards each in its own goroutine
+func (w *ForwardingWorker) claim(ctx context.Context) {
+	free := cap(w.slots) - len(w.slots)
+	if free == 0 {
+		return
+	}
+
+	integrations, err := w.forwarder.Claim(ctx, free)
+	if err != nil {
+		w.logger.Errorf("Forwarding worker failed to claim integrations: %v", err)
+		return
+	}
+
+	for i := range integrations {
+		w.slots <- struct{}{}
+		w.waitGroup.Add(1)
+		go w.forward(ctx, &integrations[i])
+	}
+}
+
+func (w *ForwardingWorker) forward(ctx context.Context, integration *domain.Integration) {
+	defer func() {
+		<-w.slots
+		w.waitGroup.Done()
+	}()
+
+	if err := w.forwarder.Forward(ctx, integration); err != nil && ctx.Err() == nil {
+		w.logger.Errorf("Failed to forward logs of %s integration %s for tenant %s: %v",
+			integration.Type, integration.ID, integration.TenantID, err)
+	}
+	if !integration.Enabled {
+		w.logger.Warnf("Disabled %s integration %s for tenant %s until it is updated",
+			integration.Type, integration.ID, integration.TenantID)
+	}
+}
@@ -0,0 +1,206 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/repository/opensearch"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+// IndexLifecycleWorker periodically moves the daily per-tenant OpenSearch
+// indices through their lifecycle: the write alias rolls over to the new day,
+// older indices are warmed, indices past retention are deleted and indices
+// created with an older mapping version, or another metadata mapping strategy,
+// are migrated to the current one
+type IndexLifecycleWorker struct {
+	manager      opensearch.LifecycleManager
+	tenants      repository.TenantRepository
+	config       *config.IndexLifecycleConfig
+	logger       *logger.Logger
+	shutdownChan chan struct{}
+	waitGroup    sync.WaitGroup
+}
+
+func NewIndexLifecycleWorker(
+	manager opensearch.LifecycleManager,
+	tenants repository.TenantRepository,
+	config *config.IndexLifecycleConfig,
+	logger *logger.Logger,
+) *IndexLifecycleWorker {
+	return &IndexLifecycleWorker{
+		manager:      manager,
+		tenants:      tenants,
+		config:       config,
+		logger:       logger,
+		shutdownChan: make(chan struct{}),
+	}
+}
+
+func (w *IndexLifecycleWorker) Start() {
+	w.logger.Info("Starting Index Lifecycle worker...")
+
+	w.waitGroup.Add(1)
+	go w.run()
+}
+
+func (w *IndexLifecycleWorker) Stop() {
+	w.logger.Info("Stopping Index Lifecycle worker...")
+	close(w.shutdownChan)
+	w.waitGroup.Wait()
+	w.logger.Info("Index Lifecycle worker stopped")
+}
+
+func (w *IndexLifecycleWorker) run() {
+	defer w.waitGroup.Done()
+
+	// Apply right away so a restart doesn't postpone a day's rollover
+	if err := w.apply(context.Background(), time.Now()); err != nil {
+		w.logger.Errorf("Index Lifecycle worker failed to apply lifecycle: %v", err)
+	}
+
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.shutdownChan:
+			w.logger.Info("Index Lifecycle worker shutting down")
+			return
+		case now := <-ticker.C:
+			if err := w.apply(context.Background(), now); err != nil {
+				w.logger.Errorf("Index Lifecycle worker failed to apply lifecycle: %v", err)
+			}
+		}
+	}
+}
+
+func (w *IndexLifecycleWorker) apply(ctx context.Context, now time.Time) error {
+	if err := w.manager.PutIndexTemplate(ctx); err != nil {
+		return fmt.Errorf("failed to put index template: %w", err)
+	}
+
+	indices, err := w.manager.ListIndices(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list indices: %w", err)
+	}
+
+	tenantRetention := w.tenantRetention(ctx)
+
+	now = now.UTC()
+	today := now.Truncate(24 * time.Hour)
+
+	// Interrupted migrations are completed whatever MaxMigrations, as their
+	// index may be missing logs until they are
+	migrations, err := w.manager.InterruptedMigrations(ctx)
+	if err != nil {
+		w.logger.Errorf("Failed to list interrupted index migrations: %v", err)
+	}
+	migrated := 0
+
+	var expired []string
+	active := make(map[string]bool)
+	for _, index := range indices {
+		// An index holds logs up to the end of its day
+		age := now.Sub(index.Day.Add(24 * time.Hour))
+
+		if retention := w.retentionFor(index.TenantID, tenantRetention); retention > 0 && age > retention {
+			expired = append(expired, index.Name)
+			continue
+		}
+
+		// Today's index is still written to, so it's migrated once its day is
+		// over. A migrated index is recreated hot, so it's warmed in a later run.
+		migrate := index.Outdated && index.Day.Before(today) && migrated < w.config.MaxMigrations
+		if migrate && !slices.Contains(migrations, index.Name) {
+			migrations = append(migrations, index.Name)
+			migrated++
+		}
+
+		if !migrate && w.config.WarmAfter > 0 && age > w.config.WarmAfter && index.Phase != opensearch.LifecyclePhaseWarm {
+			err := w.manager.Warm(ctx, index.Name, w.config.WarmReplicas)
+			metrics.ObserveIndexLifecycleAction("warm", err)
+			if err != nil {
+				w.logger.Errorf("Failed to warm index %s: %v", index.Name, err)
+			} else {
+				w.logger.Infof("Warmed index %s", index.Name)
+			}
+		}
+
+		// Only tenants that wrote recently get today's index created for them
+		if !index.Day.Before(today.Add(-24 * time.Hour)) {
+			active[index.TenantID] = true
+		}
+	}
+
+	if len(expired) > 0 {
+		err := w.manager.DeleteIndices(ctx, expired)
+		metrics.ObserveIndexLifecycleAction("delete", err)
+		if err != nil {
+			w.logger.Errorf("Failed to delete %d expired indices: %v", len(expired), err)
+		} else {
+			w.logger.Infof("Deleted %d expired indices: %v", len(expired), expired)
+		}
+	}
+
+	for _, index := range migrations {
+		err := w.manager.MigrateIndex(ctx, index)
+		metrics.ObserveIndexLifecycleAction("migrate", err)
+		if err != nil {
+			w.logger.Errorf("Failed to migrate index %s to mapping version %d: %v", index, opensearch.MappingVersion, err)
+		} else {
+			w.logger.Infof("Migrated index %s to mapping version %d", index, opensearch.MappingVersion)
+		}
+	}
+
+	for tenantID := range active {
+		rolled, err := w.manager.RolloverWriteAlias(ctx, tenantID, now)
+		if err != nil {
+			metrics.ObserveIndexLifecycleAction("rollover", err)
+			w.logger.Errorf("Failed to roll over write alias for tenant %s: %v", tenantID, err)
+			continue
+		}
+		if rolled {
+			metrics.ObserveIndexLifecycleAction("rollover", nil)
+			w.logger.Infof("Rolled over write alias for tenant %s", tenantID)
+		}
+	}
+
+	return nil
+}
+
+// tenantRetention returns the retention tenants set for themselves. If the
+// tenants can't be read, the configured retention applies for this run.
+func (w *IndexLifecycleWorker) tenantRetention(ctx context.Context) map[string]time.Duration {
+	tenants, err := w.tenants.List(ctx)
+	if err != nil {
+		w.logger.Errorf("Failed to load tenant retention settings: %v", err)
+		return nil
+	}
+
+	retention := make(map[string]time.Duration)
+	for _, tenant := range tenants {
+		if kept := tenant.Settings.Retention(); kept > 0 {
+			retention[tenant.ID] = kept
+		}
+	}
+	return retention
+}
+
+// retentionFor returns how long a tenant's indices are kept. Overrides set by
+// operators come first, then the tenant's own setting, then the default.
+func (w *IndexLifecycleWorker) retentionFor(tenantID string, tenantRetention map[string]time.Duration) time.Duration {
+	if _, ok := w.config.RetentionOverrides[tenantID]; ok {
+		return w.config.RetentionFor(tenantID)
+	}
+	if retention, ok := tenantRetention[tenantID]; ok {
+		return retention
+	}
+	return w.config.Retention
+}
@@ -0,0 +1,149 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	"github.com/kingrain94/audit-log-api/internal/service/queue"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+	"github.com/kingrain94/audit-log-api/internal/utils"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+// IngestWorker stores the logs the API accepted with ?async=true. Messages are
+// only deleted once their logs are stored, so a failed write is retried.
+type IngestWorker struct {
+	messageQueue queue.Queue
+	store        *service.IngestService
+	logger       *logger.Logger
+	workerCount  int
+	pollInterval time.Duration
+	maxMessages  int32
+	waitTime     int32
+	drain        *drain
+	waitGroup    sync.WaitGroup
+}
+
+func NewIngestWorker(
+	messageQueue queue.Queue,
+	store *service.IngestService,
+	logger *logger.Logger,
+	workerConfig *config.WorkerConfig,
+) *IngestWorker {
+	return &IngestWorker{
+		messageQueue: messageQueue,
+		store:        store,
+		logger:       logger,
+		workerCount:  workerConfig.Count,
+		pollInterval: workerConfig.PollInterval,
+		maxMessages:  int32(workerConfig.MaxMessages),
+		waitTime:     20,
+		drain:        newDrain(messageQueue, queue.IngestQueue, workerConfig, logger),
+	}
+}
+
+func (w *IngestWorker) Start() {
+	w.logger.Info("Starting Ingest workers...")
+
+	for i := 0; i < w.workerCount; i++ {
+		w.waitGroup.Add(1)
+		go w.runWorker(i)
+	}
+}
+
+func (w *IngestWorker) Stop() {
+	w.logger.Info("Stopping Ingest workers...")
+	w.drain.stop(&w.waitGroup)
+	w.logger.Info("All Ingest workers stopped")
+}
+
+func (w *IngestWorker) runWorker(workerID int) {
+	defer w.waitGroup.Done()
+
+	w.logger.Infof("Ingest Worker %d started", workerID)
+
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	throttle := newThrottle("ingest", w.drain.config, w.logger)
+
+	for {
+		select {
+		case <-w.drain.stopping():
+			w.logger.Infof("Ingest Worker %d shutting down", workerID)
+			return
+		case <-ticker.C:
+			// Wait out a backoff before receiving more messages
+			if !throttle.wait(w.drain.receiveCtx) {
+				continue
+			}
+			if err := w.processMessages(w.drain.receiveCtx, throttle); err != nil {
+				w.logger.Errorf("Ingest Worker %d failed to process messages: %v", workerID, err)
+			}
+		}
+	}
+}
+
+func (w *IngestWorker) processMessages(ctx context.Context, throttle *throttle) error {
+	messages, err := w.messageQueue.ReceiveMessages(ctx, queue.IngestQueue, w.maxMessages, w.waitTime)
+	if err != nil {
+		// Stop cancels the long poll
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("failed to receive messages: %w", err)
+	}
+
+	for i, msg := range messages {
+		// Pace the batch, and hand back what is left of it once the drain timeout passed
+		if !throttle.wait(w.drain.processCtx) {
+			w.drain.release(messages[i:], 0)
+			break
+		}
+		start := time.Now()
+		msgCtx, span := w.messageQueue.StartConsumerSpan(w.drain.processCtx, queue.IngestQueue, msg.Message)
+		done := w.drain.hold(msg.ReceiptHandle)
+		err := w.processMessage(msgCtx, msg.Message)
+		done(err)
+		tracing.End(span, err)
+		metrics.ObserveWorkerMessage("ingest", start, err)
+		// Hand the rest of the batch back until the backoff passes
+		if backoff := throttle.done(start, err); backoff > 0 {
+			w.drain.release(messages[i+1:], backoff)
+			break
+		}
+		if err != nil {
+			w.logger.With(zap.String("request_id", msg.Message.RequestID)).
+				Errorf("Failed to store %d logs of tenant %s: %v", len(msg.Message.Logs), msg.Message.TenantID, err)
+			continue
+		}
+
+		if err := w.messageQueue.DeleteMessage(context.Background(), queue.IngestQueue, msg.ReceiptHandle); err != nil {
+			w.logger.Errorf("Failed to delete message: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (w *IngestWorker) processMessage(ctx context.Context, msg queue.Message) error {
+	if msg.Type != queue.MessageTypeIngest {
+		return fmt.Errorf("unknown message type: %s", msg.Type)
+	}
+	if len(msg.Logs) == 0 {
+		return fmt.Errorf("empty logs array for INGEST message")
+	}
+
+	// The outbox event keeps the request ID, so the index message is tagged with it too
+	if msg.RequestID != "" {
+		ctx = context.WithValue(ctx, utils.RequestIDKey, msg.RequestID)
+	}
+	return w.store.Store(ctx, msg.Logs)
+}
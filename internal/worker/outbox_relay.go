@@ -0,0 +1,279 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/service"
+	"github.com/kingrain94/audit-log-api/internal/service/pubsub"
+	"github.com/kingrain94/audit-log-api/internal/service/queue"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+// maxAggregatedMessageSize keeps combined index messages below the 256KB SQS
+// message limit
+const maxAggregatedMessageSize = 240 * 1024
+
+// OutboxRelay publishes pending outbox events to the index queue and Redis
+// (real-time broadcast). Events are marked processed once their index message
+// is sent, giving at-least-once indexing; the broadcast is best effort, so a
+// Redis outage doesn't send the index messages again. Events failing
+// maxAttempts times are dead-lettered. Each claimed batch goes to the queue in
+// as few calls as possible, with the single-log events of a tenant combined
+// into bulk index messages.
+type OutboxRelay struct {
+	messageQueue queue.Queue
+	pubsub       pubsub.Broker
+	repository   repository.PostgresRepository
+	logger       *logger.Logger
+	pollInterval time.Duration
+	batchSize    int
+	// maxAggregatedLogs caps the single-log events combined into one message
+	maxAggregatedLogs int
+	lease             time.Duration
+	maxAttempts       int // Claims of an event before it's dead-lettered
+	retention         time.Duration
+	purgeInterval     time.Duration
+	shutdownChan      chan struct{}
+	waitGroup         sync.WaitGroup
+}
+
+func NewOutboxRelay(
+	messageQueue queue.Queue,
+	pubsub pubsub.Broker,
+	repository repository.PostgresRepository,
+	logger *logger.Logger,
+	pollInterval time.Duration,
+	batchSize int,
+) *OutboxRelay {
+	return &OutboxRelay{
+		messageQueue:      messageQueue,
+		pubsub:            pubsub,
+		repository:        repository,
+		logger:            logger,
+		pollInterval:      pollInterval,
+		batchSize:         batchSize,
+		maxAggregatedLogs: 50,
+		lease:             30 * time.Second, // Claimed events are retried after this delay if the relay dies
+		retention:         24 * time.Hour,   // Keep processed events for a day for troubleshooting
+		purgeInterval:     time.Hour,
+		maxAttempts:       10,
+		shutdownChan:      make(chan struct{}),
+	}
+}
+
+func (w *OutboxRelay) Start() {
+	w.logger.Info("Starting Outbox relay...")
+
+	w.waitGroup.Add(1)
+	go w.run()
+}
+
+func (w *OutboxRelay) Stop() {
+	w.logger.Info("Stopping Outbox relay...")
+	close(w.shutdownChan)
+	w.waitGroup.Wait()
+	w.logger.Info("Outbox relay stopped")
+}
+
+func (w *OutboxRelay) run() {
+	defer w.waitGroup.Done()
+
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	purgeTicker := time.NewTicker(w.purgeInterval)
+	defer purgeTicker.Stop()
+
+	for {
+		select {
+		case <-w.shutdownChan:
+			w.logger.Info("Outbox relay shutting down")
+			return
+		case <-ticker.C:
+			if err := w.processEvents(context.Background()); err != nil {
+				w.logger.Errorf("Outbox relay failed to process events: %v", err)
+			}
+		case <-purgeTicker.C:
+			deleted, err := w.repository.Outbox().DeleteProcessedBefore(context.Background(), time.Now().Add(-w.retention))
+			if err != nil {
+				w.logger.Errorf("Outbox relay failed to purge processed events: %v", err)
+				continue
+			}
+			if deleted > 0 {
+				w.logger.Infof("Purged %d processed outbox events", deleted)
+			}
+		}
+	}
+}
+
+func (w *OutboxRelay) processEvents(ctx context.Context) (err error) {
+	events, err := w.repository.Outbox().ClaimPending(ctx, w.batchSize, w.lease)
+	if err != nil {
+		return fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	links := make([]trace.Link, len(events))
+	for i, event := range events {
+		links[i] = trace.LinkFromContext(tracing.Extract(ctx, event.TraceContext))
+	}
+	ctx, span := tracing.Start(ctx, "outbox.publish",
+		trace.WithLinks(links...),
+		trace.WithAttributes(attribute.Int("outbox.events", len(events))),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	failed := make(map[string]error)
+	logs := make(map[string][]domain.AuditLog, len(events))
+	for _, event := range events {
+		var eventLogs []domain.AuditLog
+		if err := json.Unmarshal(event.Payload, &eventLogs); err != nil {
+			failed[event.ID] = fmt.Errorf("failed to unmarshal outbox payload: %w", err)
+			continue
+		}
+		if len(eventLogs) == 0 {
+			failed[event.ID] = fmt.Errorf("empty payload for outbox event")
+			continue
+		}
+		logs[event.ID] = eventLogs
+	}
+
+	// Send message to SQS for asynchronous indexing
+	batches, members := w.batchEvents(events, logs, failed)
+	err = w.messageQueue.SendBulkIndexMessages(ctx, batches)
+	var sendErr *queue.BatchSendError
+	switch {
+	case errors.As(err, &sendErr):
+		for i, err := range sendErr.Failed {
+			for _, id := range members[i] {
+				failed[id] = fmt.Errorf("failed to send index message: %w", err)
+			}
+		}
+	case err != nil:
+		for _, ids := range members {
+			for _, id := range ids {
+				failed[id] = fmt.Errorf("failed to send index message: %w", err)
+			}
+		}
+	}
+
+	processed := make([]string, 0, len(events))
+	for _, event := range events {
+		err := failed[event.ID]
+		metrics.ObserveWorkerMessage("outbox_relay", start, err)
+		if err != nil {
+			w.fail(ctx, &event, err)
+			continue
+		}
+		processed = append(processed, event.ID)
+
+		if err := w.broadcast(ctx, logs[event.ID]); err != nil {
+			metrics.OutboxBroadcastFailuresTotal.Inc()
+			w.logger.With(zap.String("request_id", event.RequestID)).
+				Warnf("Failed to broadcast outbox event %s: %v", event.ID, err)
+		}
+	}
+
+	if err := w.repository.Outbox().MarkProcessed(ctx, processed); err != nil {
+		return fmt.Errorf("failed to mark outbox events processed: %w", err)
+	}
+
+	return nil
+}
+
+// fail records why an event couldn't be published, dead-lettering it on its
+// last attempt. Attempts was counted before the claim.
+func (w *OutboxRelay) fail(ctx context.Context, event *domain.OutboxEvent, err error) {
+	attempt := event.Attempts + 1
+	log := w.logger.With(zap.String("request_id", event.RequestID))
+
+	if attempt >= w.maxAttempts {
+		log.Errorf("Dead-lettering outbox event %s after %d attempts: %v", event.ID, attempt, err)
+		metrics.OutboxEventsDeadLetteredTotal.Inc()
+		if markErr := w.repository.Outbox().MarkDeadLettered(ctx, event.ID, err.Error()); markErr != nil {
+			w.logger.Errorf("Failed to dead-letter outbox event %s: %v", event.ID, markErr)
+		}
+		return
+	}
+
+	log.Errorf("Failed to publish outbox event %s (attempt %d): %v", event.ID, attempt, err)
+	if markErr := w.repository.Outbox().MarkFailed(ctx, event.ID, err.Error()); markErr != nil {
+		w.logger.Errorf("Failed to record outbox event failure %s: %v", event.ID, markErr)
+	}
+}
+
+// batchEvents groups the logs of events into BULK_INDEX messages. A bulk
+// event is sent as its own messages, split to fit the SQS message limit,
+// while single-log events of a tenant are combined into messages of up to
+// maxAggregatedLogs logs and maxAggregatedMessageSize bytes, which carry the
+// request and trace of their first event. members lists the events each
+// batch carries; events of an unknown type are added to failed.
+func (w *OutboxRelay) batchEvents(events []domain.OutboxEvent, logs map[string][]domain.AuditLog, failed map[string]error) (batches []queue.IndexBatch, members [][]string) {
+	// Position of the batch still taking single logs, by tenant, and the
+	// payload size of each batch
+	open := make(map[string]int)
+	var sizes []int
+	for _, event := range events {
+		eventLogs, ok := logs[event.ID]
+		if !ok {
+			continue
+		}
+
+		switch event.EventType {
+		case domain.OutboxEventIndex:
+			i, ok := open[event.TenantID]
+			if !ok || len(batches[i].Logs) >= w.maxAggregatedLogs || sizes[i]+len(event.Payload) > maxAggregatedMessageSize {
+				i = len(batches)
+				open[event.TenantID] = i
+				batches = append(batches, queue.IndexBatch{RequestID: event.RequestID, TraceContext: event.TraceContext})
+				members = append(members, nil)
+				sizes = append(sizes, 0)
+			}
+			batches[i].Logs = append(batches[i].Logs, eventLogs[0])
+			members[i] = append(members[i], event.ID)
+			sizes[i] += len(event.Payload)
+		case domain.OutboxEventBulkIndex:
+			// The event is published only if all of its messages are sent
+			chunks, _, err := service.IngestChunks(eventLogs)
+			if err != nil {
+				failed[event.ID] = err
+				continue
+			}
+			for _, chunk := range chunks {
+				batches = append(batches, queue.IndexBatch{Logs: chunk, RequestID: event.RequestID, TraceContext: event.TraceContext})
+				members = append(members, []string{event.ID})
+				sizes = append(sizes, 0)
+			}
+		default:
+			failed[event.ID] = fmt.Errorf("unknown outbox event type: %s", event.EventType)
+		}
+	}
+	return batches, members
+}
+
+// broadcast sends an event's logs to WebSocket clients through Redis pub/sub
+func (w *OutboxRelay) broadcast(ctx context.Context, logs []domain.AuditLog) error {
+	for i := range logs {
+		if err := w.pubsub.Publish(ctx, dto.FromAuditLog(&logs[i])); err != nil {
+			return fmt.Errorf("failed to publish log to Redis: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+	"github.com/stretchr/testify/suite"
+)
+
+type OutboxRelayTestSuite struct {
+	suite.Suite
+	relay *OutboxRelay
+}
+
+func (s *OutboxRelayTestSuite) SetupTest() {
+	s.relay = NewOutboxRelay(nil, nil, nil, logger.NewLogger("test"), time.Second, 100)
+}
+
+func TestOutboxRelay(t *testing.T) {
+	suite.Run(t, new(OutboxRelayTestSuite))
+}
+
+// bulkEvent builds a BULK_INDEX event of count logs of about size bytes each
+func bulkEvent(id string, count, size int) (domain.OutboxEvent, []domain.AuditLog) {
+	logs := make([]domain.AuditLog, count)
+	for i := range logs {
+		logs[i] = domain.AuditLog{
+			ID:         fmt.Sprintf("%s-%d", id, i),
+			TenantID:   "tenant1",
+			Action:     string(domain.ActionCreate),
+			ResourceID: strings.Repeat("x", size),
+		}
+	}
+	payload, _ := json.Marshal(logs)
+	return domain.OutboxEvent{ID: id, TenantID: "tenant1", EventType: domain.OutboxEventBulkIndex, Payload: payload}, logs
+}
+
+func (s *OutboxRelayTestSuite) TestBatchEvents_SplitsLargeBulkEvents() {
+	// Arrange
+	event, eventLogs := bulkEvent("event1", 6, 100*1024)
+	s.Greater(len(event.Payload), 256*1024)
+	failed := make(map[string]error)
+
+	// Act
+	batches, members := s.relay.batchEvents([]domain.OutboxEvent{event}, map[string][]domain.AuditLog{event.ID: eventLogs}, failed)
+
+	// Assert
+	s.Empty(failed)
+	s.Len(batches, 3)
+	var sent []domain.AuditLog
+	for i, batch := range batches {
+		body, err := json.Marshal(batch.Logs)
+		s.NoError(err)
+		s.Less(len(body), 256*1024)
+		s.Equal([]string{event.ID}, members[i])
+		sent = append(sent, batch.Logs...)
+	}
+	s.Equal(eventLogs, sent)
+}
+
+func (s *OutboxRelayTestSuite) TestBatchEvents_CombinesSingleLogEvents() {
+	// Arrange
+	events := make([]domain.OutboxEvent, 3)
+	logs := make(map[string][]domain.AuditLog)
+	for i := range events {
+		event, eventLogs := bulkEvent(fmt.Sprintf("event%d", i), 1, 10)
+		event.EventType = domain.OutboxEventIndex
+		events[i] = event
+		logs[event.ID] = eventLogs
+	}
+	failed := make(map[string]error)
+
+	// Act
+	batches, members := s.relay.batchEvents(events, logs, failed)
+
+	// Assert
+	s.Empty(failed)
+	s.Len(batches, 1)
+	s.Len(batches[0].Logs, 3)
+	s.Equal([]string{"event0", "event1", "event2"}, members[0])
+}
@@ -0,0 +1,124 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/repository/postgres"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+// PartitionWorker periodically maintains the monthly partitions of the
+// audit_logs table: the partitions of the coming months are created ahead of
+// time and those past retention are detached and dropped
+type PartitionWorker struct {
+	manager      postgres.PartitionManager
+	config       *config.PartitionConfig
+	logger       *logger.Logger
+	shutdownChan chan struct{}
+	waitGroup    sync.WaitGroup
+}
+
+func NewPartitionWorker(
+	manager postgres.PartitionManager,
+	config *config.PartitionConfig,
+	logger *logger.Logger,
+) *PartitionWorker {
+	return &PartitionWorker{
+		manager:      manager,
+		config:       config,
+		logger:       logger,
+		shutdownChan: make(chan struct{}),
+	}
+}
+
+func (w *PartitionWorker) Start() {
+	w.logger.Info("Starting Partition worker...")
+
+	w.waitGroup.Add(1)
+	go w.run()
+}
+
+func (w *PartitionWorker) Stop() {
+	w.logger.Info("Stopping Partition worker...")
+	close(w.shutdownChan)
+	w.waitGroup.Wait()
+	w.logger.Info("Partition worker stopped")
+}
+
+func (w *PartitionWorker) run() {
+	defer w.waitGroup.Done()
+
+	// Apply right away so a restart doesn't leave the next month without a partition
+	if err := w.maintain(context.Background(), time.Now()); err != nil {
+		w.logger.Errorf("Partition worker failed to maintain partitions: %v", err)
+	}
+
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.shutdownChan:
+			w.logger.Info("Partition worker shutting down")
+			return
+		case now := <-ticker.C:
+			if err := w.maintain(context.Background(), now); err != nil {
+				w.logger.Errorf("Partition worker failed to maintain partitions: %v", err)
+			}
+		}
+	}
+}
+
+func (w *PartitionWorker) maintain(ctx context.Context, now time.Time) error {
+	partitions, err := w.manager.ListPartitions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list partitions: %w", err)
+	}
+
+	now = now.UTC()
+	current := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	// Create missing partitions before dropping any, so logs keep a partition
+	// to land in even if a drop fails
+	existing := make(map[time.Time]bool, len(partitions))
+	for _, partition := range partitions {
+		existing[partition.Month] = true
+	}
+	for i := 0; i <= w.config.MonthsAhead; i++ {
+		month := current.AddDate(0, i, 0)
+		if existing[month] {
+			continue
+		}
+		created, err := w.manager.CreatePartition(ctx, month)
+		metrics.ObservePartitionAction("create", err)
+		if err != nil {
+			w.logger.Errorf("Failed to create partition for %s: %v", month.Format("2006-01"), err)
+		} else if created {
+			w.logger.Infof("Created partition for %s", month.Format("2006-01"))
+		}
+	}
+
+	if w.config.Retention == 0 {
+		return nil
+	}
+	for _, partition := range partitions {
+		// A partition holds logs up to the end of its month
+		if now.Sub(partition.End()) <= w.config.Retention {
+			continue
+		}
+		err := w.manager.DropPartition(ctx, partition)
+		metrics.ObservePartitionAction("drop", err)
+		if err != nil {
+			w.logger.Errorf("Failed to drop expired partition %s: %v", partition.Name, err)
+		} else {
+			w.logger.Infof("Dropped expired partition %s", partition.Name)
+		}
+	}
+
+	return nil
+}
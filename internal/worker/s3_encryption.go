@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+// kmsKeyID returns the KMS key a tenant's objects are encrypted with, its own
+// over the global default, or "" to leave the bucket's default encryption
+func kmsKeyID(cfg *config.S3Config, settings domain.Encryption) string {
+	if settings.KMSKeyARN != "" {
+		return settings.KMSKeyARN
+	}
+	return cfg.KMSKeyID
+}
+
+// encryptUpload encrypts a multipart upload with the KMS key (SSE-KMS); an
+// empty key leaves it unchanged
+func encryptUpload(keyID string) func(*s3.CreateMultipartUploadInput) {
+	return func(input *s3.CreateMultipartUploadInput) {
+		if keyID != "" {
+			input.ServerSideEncryption = types.ServerSideEncryptionAwsKms
+			input.SSEKMSKeyId = aws.String(keyID)
+		}
+	}
+}
+
+// encryptPut encrypts an upload with the KMS key (SSE-KMS); an empty key
+// leaves it unchanged
+func encryptPut(input *s3.PutObjectInput, keyID string) {
+	if keyID != "" {
+		input.ServerSideEncryption = types.ServerSideEncryptionAwsKms
+		input.SSEKMSKeyId = aws.String(keyID)
+	}
+}
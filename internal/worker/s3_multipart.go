@@ -0,0 +1,134 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// multipartPartSize is the multipart upload part size; S3 requires at least 5 MiB for all but the last part
+const multipartPartSize = 8 << 20
+
+// multipartUpload buffers writes and uploads them to S3 one part at a time,
+// hashing the object's content with SHA-256 on the way
+type multipartUpload struct {
+	ctx      context.Context
+	client   *s3.Client
+	bucket   string
+	key      string
+	uploadID *string
+	checksum types.ChecksumAlgorithm
+	buf      bytes.Buffer
+	parts    []types.CompletedPart
+	size     int64
+	hash     hash.Hash
+}
+
+// newMultipartUpload starts an upload; options set the storage class, tags
+// or object lock of the object. Parts are checksummed with CRC32 when the
+// options choose it, which object lock requires.
+func newMultipartUpload(ctx context.Context, client *s3.Client, bucket, key, contentType string, options ...func(*s3.CreateMultipartUploadInput)) (*multipartUpload, error) {
+	input := &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+	}
+	for _, option := range options {
+		option(input)
+	}
+	out, err := client.CreateMultipartUpload(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start multipart upload: %w", err)
+	}
+
+	return &multipartUpload{
+		ctx:      ctx,
+		client:   client,
+		bucket:   bucket,
+		key:      key,
+		uploadID: out.UploadId,
+		checksum: input.ChecksumAlgorithm,
+		hash:     sha256.New(),
+	}, nil
+}
+
+func (u *multipartUpload) Write(p []byte) (int, error) {
+	n, _ := u.buf.Write(p)
+	u.hash.Write(p)
+	u.size += int64(n)
+	if u.buf.Len() >= multipartPartSize {
+		if err := u.flushPart(u.ctx); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+func (u *multipartUpload) flushPart(ctx context.Context) error {
+	partNumber := int32(len(u.parts) + 1)
+	out, err := u.client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:            aws.String(u.bucket),
+		Key:               aws.String(u.key),
+		UploadId:          u.uploadID,
+		PartNumber:        aws.Int32(partNumber),
+		Body:              bytes.NewReader(u.buf.Bytes()),
+		ChecksumAlgorithm: u.checksum,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload part %d: %w", partNumber, err)
+	}
+
+	part := types.CompletedPart{ETag: out.ETag, PartNumber: aws.Int32(partNumber)}
+	if u.checksum == types.ChecksumAlgorithmCrc32 {
+		part.ChecksumCRC32 = out.ChecksumCRC32
+	}
+	u.parts = append(u.parts, part)
+	u.buf.Reset()
+	return nil
+}
+
+// Complete uploads the remaining buffered data as the final part and assembles the object
+func (u *multipartUpload) Complete(ctx context.Context) error {
+	if u.buf.Len() > 0 || len(u.parts) == 0 {
+		if err := u.flushPart(ctx); err != nil {
+			return err
+		}
+	}
+
+	_, err := u.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(u.bucket),
+		Key:             aws.String(u.key),
+		UploadId:        u.uploadID,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: u.parts},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+	return nil
+}
+
+// Size returns the number of bytes written so far
+func (u *multipartUpload) Size() int64 {
+	return u.size
+}
+
+// SHA256 returns the hex SHA-256 digest of the bytes written so far
+func (u *multipartUpload) SHA256() string {
+	return hex.EncodeToString(u.hash.Sum(nil))
+}
+
+// Abort discards uploaded parts so failed uploads don't accrue storage
+func (u *multipartUpload) Abort(ctx context.Context) {
+	_, _ = u.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(u.bucket),
+		Key:      aws.String(u.key),
+		UploadId: u.uploadID,
+	})
+}
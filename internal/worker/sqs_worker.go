@@ -2,44 +2,58 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
+	"go.uber.org/zap"
+
 	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/repository"
 	"github.com/kingrain94/audit-log-api/internal/repository/opensearch"
 	"github.com/kingrain94/audit-log-api/internal/service/queue"
+	"github.com/kingrain94/audit-log-api/internal/tracing"
 	"github.com/kingrain94/audit-log-api/pkg/logger"
 )
 
 type SQSWorker struct {
-	sqsService   *queue.SQSService
+	messageQueue queue.Queue
 	osRepository opensearch.Repository
+	// failures keeps the logs OpenSearch rejected for good
+	failures repository.IndexFailureRepository
+	// analytics receives the indexed logs too; nil when no analytics store is configured
+	analytics    repository.AnalyticsRepository
 	logger       *logger.Logger
 	workerCount  int
 	pollInterval time.Duration
 	maxMessages  int32
 	waitTime     int32
-	shutdownChan chan struct{}
+	drain        *drain
 	waitGroup    sync.WaitGroup
 }
 
 func NewSQSWorker(
-	sqsService *queue.SQSService,
+	messageQueue queue.Queue,
 	osRepository opensearch.Repository,
+	failures repository.IndexFailureRepository,
+	analytics repository.AnalyticsRepository,
 	logger *logger.Logger,
-	workerCount int,
-	pollInterval time.Duration,
+	workerConfig *config.WorkerConfig,
 ) *SQSWorker {
 	return &SQSWorker{
-		sqsService:   sqsService,
+		messageQueue: messageQueue,
 		osRepository: osRepository,
+		failures:     failures,
+		analytics:    analytics,
 		logger:       logger,
-		workerCount:  workerCount,
-		pollInterval: pollInterval,
-		maxMessages:  10, // Process up to 10 messages at a time
+		workerCount:  workerConfig.Count,
+		pollInterval: workerConfig.PollInterval,
+		maxMessages:  int32(workerConfig.MaxMessages),
 		waitTime:     20, // Long polling: wait up to 20 seconds for messages
-		shutdownChan: make(chan struct{}),
+		drain:        newDrain(messageQueue, queue.IndexQueue, workerConfig, logger),
 	}
 }
 
@@ -55,8 +69,7 @@ func (w *SQSWorker) Start() {
 
 func (w *SQSWorker) Stop() {
 	w.logger.Info("Stopping SQS workers...")
-	close(w.shutdownChan)
-	w.waitGroup.Wait()
+	w.drain.stop(&w.waitGroup)
 	w.logger.Info("All SQS workers stopped")
 }
 
@@ -68,38 +81,66 @@ func (w *SQSWorker) runWorker(workerID int) {
 	ticker := time.NewTicker(w.pollInterval)
 	defer ticker.Stop()
 
+	throttle := newThrottle("index", w.drain.config, w.logger)
+
 	for {
 		select {
-		case <-w.shutdownChan:
+		case <-w.drain.stopping():
 			w.logger.Infof("Worker %d shutting down", workerID)
 			return
 		case <-ticker.C:
-			if err := w.processMessages(context.Background()); err != nil {
+			// Wait out a backoff before receiving more messages
+			if !throttle.wait(w.drain.receiveCtx) {
+				continue
+			}
+			if err := w.processMessages(w.drain.receiveCtx, throttle); err != nil {
 				w.logger.Errorf("Worker %d failed to process messages: %v", workerID, err)
 			}
 		}
 	}
 }
 
-func (w *SQSWorker) processMessages(ctx context.Context) error {
-	// Get index queue URL from config
-	config := config.DefaultSQSConfig()
-	indexQueueURL := config.IndexQueueURL
-
-	messages, err := w.sqsService.ReceiveMessages(ctx, indexQueueURL, w.maxMessages, w.waitTime)
+func (w *SQSWorker) processMessages(ctx context.Context, throttle *throttle) error {
+	messages, err := w.messageQueue.ReceiveMessages(ctx, queue.IndexQueue, w.maxMessages, w.waitTime)
 	if err != nil {
+		// Stop cancels the long poll
+		if ctx.Err() != nil {
+			return nil
+		}
 		return fmt.Errorf("failed to receive messages: %w", err)
 	}
 
-	for _, msg := range messages {
-		if err := w.processMessage(ctx, msg.Message); err != nil {
+	// Only delete the messages that were processed successfully, in one call
+	// once the batch is done
+	processed := make([]*string, 0, len(messages))
+	for i, msg := range messages {
+		// Pace the batch, and hand back what is left of it once the drain timeout passed
+		if !throttle.wait(w.drain.processCtx) {
+			w.drain.release(messages[i:], 0)
+			break
+		}
+		start := time.Now()
+		msgCtx, span := w.messageQueue.StartConsumerSpan(w.drain.processCtx, queue.IndexQueue, msg.Message)
+		done := w.drain.hold(msg.ReceiptHandle)
+		err := w.processMessage(msgCtx, msg.Message)
+		done(err)
+		tracing.End(span, err)
+		metrics.ObserveWorkerMessage("index", start, err)
+		// Hand the rest of the batch back until the backoff passes
+		if backoff := throttle.done(start, err); backoff > 0 {
+			w.drain.release(messages[i+1:], backoff)
+			break
+		}
+		if err != nil {
 			w.logger.Errorf("Failed to process message: %v", err)
 			continue
 		}
+		processed = append(processed, msg.ReceiptHandle)
+	}
 
-		// Only delete the message if processing was successful
-		if err := w.sqsService.DeleteMessage(ctx, indexQueueURL, msg.ReceiptHandle); err != nil {
-			w.logger.Errorf("Failed to delete message: %v", err)
+	if len(processed) > 0 {
+		if err := w.messageQueue.DeleteMessages(context.Background(), queue.IndexQueue, processed); err != nil {
+			w.logger.Errorf("Failed to delete messages: %v", err)
 		}
 	}
 
@@ -107,21 +148,88 @@ func (w *SQSWorker) processMessages(ctx context.Context) error {
 }
 
 func (w *SQSWorker) processMessage(ctx context.Context, msg queue.Message) error {
-	w.logger.Infof("Processing message of type %s for tenant %s", msg.Type, msg.TenantID)
+	w.logger.With(zap.String("request_id", msg.RequestID)).
+		Infof("Processing message of type %s for tenant %s", msg.Type, msg.TenantID)
 
 	switch msg.Type {
 	case queue.MessageTypeIndex:
 		if len(msg.Logs) != 1 {
 			return fmt.Errorf("invalid number of logs for INDEX message: %d", len(msg.Logs))
 		}
-		return w.osRepository.Index(ctx, &msg.Logs[0])
+		if err := w.osRepository.Index(ctx, &msg.Logs[0]); err != nil {
+			metrics.OpenSearchIndexFailuresTotal.WithLabelValues("index").Inc()
+			return err
+		}
+		return w.insertAnalytics(ctx, msg.Logs)
 
 	case queue.MessageTypeBulkIndex:
 		if len(msg.Logs) == 0 {
 			return fmt.Errorf("empty logs array for BULK_INDEX message")
 		}
-		return w.osRepository.BulkIndex(ctx, msg.Logs)
+		err := w.osRepository.BulkIndex(ctx, msg.Logs)
+		var bulkErr *opensearch.BulkIndexError
+		if errors.As(err, &bulkErr) {
+			indexed, err := w.saveFailures(ctx, msg.Logs, bulkErr.Failures)
+			if err != nil {
+				return err
+			}
+			return w.insertAnalytics(ctx, indexed)
+		}
+		if err != nil {
+			metrics.OpenSearchIndexFailuresTotal.WithLabelValues("bulk_index").Inc()
+			return err
+		}
+		return w.insertAnalytics(ctx, msg.Logs)
 	default:
 		return fmt.Errorf("unknown message type: %s", msg.Type)
 	}
 }
+
+// saveFailures stores the logs of a batch that OpenSearch rejected, so the
+// message can be deleted rather than redelivered to fail again, and returns
+// the logs that were indexed
+func (w *SQSWorker) saveFailures(ctx context.Context, logs []domain.AuditLog, failures []opensearch.BulkItemFailure) ([]domain.AuditLog, error) {
+	records := make([]domain.IndexFailure, len(failures))
+	failed := make(map[string]bool, len(failures))
+	for i, f := range failures {
+		records[i] = domain.IndexFailure{
+			TenantID:  f.Log.TenantID,
+			LogID:     f.Log.ID,
+			IndexName: f.Index,
+			Status:    f.Status,
+			ErrorType: f.Type,
+			Reason:    f.Reason,
+			Document:  f.Log,
+		}
+		failed[f.Log.ID] = true
+		w.logger.Errorf("Failed to index log %s into %s with status %d: %s: %s", f.Log.ID, f.Index, f.Status, f.Type, f.Reason)
+	}
+
+	if err := w.failures.Save(ctx, records); err != nil {
+		metrics.OpenSearchIndexFailuresTotal.WithLabelValues("bulk_index").Inc()
+		return nil, fmt.Errorf("failed to save index failures: %w", err)
+	}
+	metrics.IndexFailuresSavedTotal.Add(float64(len(records)))
+
+	var indexed []domain.AuditLog
+	for _, log := range logs {
+		if !failed[log.ID] {
+			indexed = append(indexed, log)
+		}
+	}
+	return indexed, nil
+}
+
+// insertAnalytics copies indexed logs to the analytics store. A failure fails
+// the message, whose redelivery indexes the logs again under the same IDs and
+// inserts them again, which the store counts once.
+func (w *SQSWorker) insertAnalytics(ctx context.Context, logs []domain.AuditLog) error {
+	if w.analytics == nil {
+		return nil
+	}
+	if err := w.analytics.Insert(ctx, logs); err != nil {
+		metrics.AnalyticsInsertFailuresTotal.Inc()
+		return fmt.Errorf("failed to insert logs into analytics store: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/repository/postgres"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+// backfillStep is the span of log time one backfill transaction recomputes
+const backfillStep = 24 * time.Hour
+
+// StatsWorker periodically rolls the logs created since its last run up into
+// the hourly stats, recomputing each tenant hour they fall in so logs
+// arriving late are counted in their own hour
+type StatsWorker struct {
+	rollup       postgres.StatsRollup
+	config       *config.StatsRollupConfig
+	logger       *logger.Logger
+	shutdownChan chan struct{}
+	waitGroup    sync.WaitGroup
+}
+
+func NewStatsWorker(
+	rollup postgres.StatsRollup,
+	config *config.StatsRollupConfig,
+	logger *logger.Logger,
+) *StatsWorker {
+	return &StatsWorker{
+		rollup:       rollup,
+		config:       config,
+		logger:       logger,
+		shutdownChan: make(chan struct{}),
+	}
+}
+
+func (w *StatsWorker) Start() {
+	w.logger.Info("Starting Stats worker...")
+
+	w.waitGroup.Add(1)
+	go w.run()
+}
+
+func (w *StatsWorker) Stop() {
+	w.logger.Info("Stopping Stats worker...")
+	close(w.shutdownChan)
+	w.waitGroup.Wait()
+	w.logger.Info("Stats worker stopped")
+}
+
+func (w *StatsWorker) run() {
+	defer w.waitGroup.Done()
+
+	// Catch up right away on the logs written while the worker was down
+	if err := w.catchUp(context.Background(), time.Now()); err != nil {
+		w.logger.Errorf("Stats worker failed to roll up hourly stats: %v", err)
+	}
+
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.shutdownChan:
+			w.logger.Info("Stats worker shutting down")
+			return
+		case now := <-ticker.C:
+			if err := w.catchUp(context.Background(), now); err != nil {
+				w.logger.Errorf("Stats worker failed to roll up hourly stats: %v", err)
+			}
+		}
+	}
+}
+
+// catchUp rolls up the logs created up to the rollup delay before now, at
+// most MaxWindow of creation times at a time
+func (w *StatsWorker) catchUp(ctx context.Context, now time.Time) error {
+	target := now.Add(-w.config.Delay)
+
+	watermark, err := w.rollup.Watermark(ctx)
+	if err != nil {
+		return err
+	}
+
+	for watermark.Before(target) {
+		select {
+		case <-w.shutdownChan:
+			return nil
+		default:
+		}
+
+		until := watermark.Add(w.config.MaxWindow)
+		if until.After(target) {
+			until = target
+		}
+
+		start := time.Now()
+		result, err := w.rollup.Rollup(ctx, until)
+		if err != nil {
+			metrics.ObserveStatsRollup(0, watermark, err)
+			return err
+		}
+		metrics.ObserveStatsRollup(result.Buckets, result.Watermark, nil)
+		if result.Buckets > 0 {
+			w.logger.Info("Rolled up hourly stats",
+				zap.Int64("buckets", result.Buckets),
+				zap.Time("watermark", result.Watermark),
+				zap.Duration("duration", time.Since(start)))
+		}
+		watermark = result.Watermark
+	}
+
+	return nil
+}
+
+// Backfill recomputes the hourly stats from start to end, of the tenant or of
+// every tenant when tenantID is empty, a day of logs at a time. It returns the
+// number of tenant hours holding logs.
+func (w *StatsWorker) Backfill(ctx context.Context, tenantID string, start, end time.Time) (int64, error) {
+	var total int64
+	for from := start.Truncate(time.Hour); !from.After(end); from = from.Add(backfillStep) {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		// Each step covers the hours from the one holding from through the
+		// one holding to, up to the hour the next step starts at
+		to := from.Add(backfillStep - time.Nanosecond)
+		if to.After(end) {
+			to = end
+		}
+		buckets, err := w.rollup.Backfill(ctx, tenantID, from, to)
+		if err != nil {
+			return total, fmt.Errorf("failed to backfill hourly stats from %s: %w", from.Format(time.RFC3339), err)
+		}
+		total += buckets
+
+		w.logger.Info("Backfilled hourly stats",
+			zap.String("tenant_id", tenantID),
+			zap.Time("from", from),
+			zap.Time("to", to),
+			zap.Int64("buckets", buckets))
+	}
+	return total, nil
+}
@@ -0,0 +1,216 @@
+package worker
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"gorm.io/gorm"
+
+	"github.com/kingrain94/audit-log-api/internal/api/dto"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+)
+
+// Tenant dumps are written as a directory of gzip-compressed NDJSON files plus
+// a manifest, which is written last and is what the job's download URL points
+// at. Every file is encrypted with the tenant's KMS key when one is set:
+//
+//	exports/<tenant>/<job>/tenant.json
+//	exports/<tenant>/<job>/logs.ndjson.gz
+//	exports/<tenant>/<job>/users.ndjson.gz
+//	exports/<tenant>/<job>/retention_policies.ndjson.gz
+//	exports/<tenant>/<job>/manifest.json
+const tenantExportFormat = "ndjson+gzip"
+
+// tenantExportManifest describes the files of a tenant dump
+type tenantExportManifest struct {
+	TenantID   string             `json:"tenant_id"`
+	JobID      string             `json:"job_id"`
+	ExportedAt time.Time          `json:"exported_at"`
+	Format     string             `json:"format"`
+	Files      []tenantExportFile `json:"files"`
+}
+
+// tenantExportFile describes one object of a tenant dump
+type tenantExportFile struct {
+	Name    string `json:"name"`
+	Key     string `json:"key"`
+	Records int64  `json:"records"`
+	Size    int64  `json:"size"`
+}
+
+// tenantExportRecord is the tenant itself, with its settings. Webhook secrets
+// are masked as they are by the settings API.
+type tenantExportRecord struct {
+	*domain.Tenant
+	Settings *dto.TenantSettingsResponse `json:"settings"`
+}
+
+// dumpTenant writes all of the job's tenant data to S3 and returns the number
+// of logs exported and the key of the manifest
+func (w *ExportWorker) dumpTenant(ctx context.Context, job *domain.ExportJob) (int64, string, error) {
+	dir := fmt.Sprintf("exports/%s/%s/", job.TenantID, job.ID)
+	manifest := &tenantExportManifest{
+		TenantID: job.TenantID,
+		JobID:    job.ID,
+		Format:   tenantExportFormat,
+	}
+
+	tenant, err := w.tenant(ctx, job.TenantID)
+	if err != nil {
+		return 0, "", err
+	}
+	keyID := kmsKeyID(w.s3Config, tenant.Settings.Encryption)
+
+	data, err := json.Marshal(tenantExportRecord{Tenant: tenant, Settings: dto.FromTenantSettings(tenant)})
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to marshal tenant: %w", err)
+	}
+	file, err := w.putExportFile(ctx, dir+"tenant.json", "application/json", keyID, data)
+	if err != nil {
+		return 0, "", err
+	}
+	file.Name, file.Records = "tenant", 1
+	manifest.Files = append(manifest.Files, file)
+
+	logs, err := w.dumpLogs(ctx, job, dir+"logs.ndjson.gz", keyID)
+	if err != nil {
+		return logs.Records, "", err
+	}
+	manifest.Files = append(manifest.Files, logs)
+
+	users, err := w.repository.User().List(ctx, domain.UserFilter{TenantID: job.TenantID})
+	if err != nil {
+		return logs.Records, "", fmt.Errorf("failed to read users: %w", err)
+	}
+	file, err = putNDJSON(ctx, w, "users", dir+"users.ndjson.gz", keyID, users)
+	if err != nil {
+		return logs.Records, "", err
+	}
+	manifest.Files = append(manifest.Files, file)
+
+	policies, err := w.repository.RetentionPolicy().ListByTenant(ctx, job.TenantID)
+	if err != nil {
+		return logs.Records, "", fmt.Errorf("failed to read retention policies: %w", err)
+	}
+	file, err = putNDJSON(ctx, w, "retention_policies", dir+"retention_policies.ndjson.gz", keyID, policies)
+	if err != nil {
+		return logs.Records, "", err
+	}
+	manifest.Files = append(manifest.Files, file)
+
+	manifest.ExportedAt = time.Now()
+	data, err = json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return logs.Records, "", fmt.Errorf("failed to marshal export manifest: %w", err)
+	}
+	manifestKey := dir + archiveManifestName
+	if _, err := w.putExportFile(ctx, manifestKey, "application/json", keyID, data); err != nil {
+		return logs.Records, "", err
+	}
+
+	return logs.Records, manifestKey, nil
+}
+
+// tenant loads the tenant, including one deleted but not purged yet so it can
+// be off-boarded during its grace period
+func (w *ExportWorker) tenant(ctx context.Context, tenantID string) (*domain.Tenant, error) {
+	tenant, err := w.repository.Tenant().GetByID(ctx, tenantID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		tenant, err = w.repository.Tenant().GetDeleted(ctx, tenantID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tenant: %w", err)
+	}
+	return tenant, nil
+}
+
+// dumpLogs streams every log of the tenant into one compressed NDJSON object
+func (w *ExportWorker) dumpLogs(ctx context.Context, job *domain.ExportJob, key, keyID string) (tenantExportFile, error) {
+	file := tenantExportFile{Name: "logs", Key: key}
+
+	upload, err := newMultipartUpload(ctx, w.s3Client, w.s3Config.ExportBucket, key, "application/gzip", encryptUpload(keyID))
+	if err != nil {
+		return file, err
+	}
+
+	gz := gzip.NewWriter(upload)
+	enc := json.NewEncoder(gz)
+	filter := domain.AuditLogFilter{TenantID: job.TenantID}
+	var cursor *domain.AuditLogCursor
+	for {
+		batch, err := w.repository.AuditLog().ListBatch(ctx, filter, cursor, exportBatchSize)
+		if err != nil {
+			upload.Abort(ctx)
+			return file, fmt.Errorf("failed to read logs: %w", err)
+		}
+
+		for i := range batch {
+			if err := enc.Encode(dto.FromAuditLog(&batch[i])); err != nil {
+				upload.Abort(ctx)
+				return file, fmt.Errorf("failed to write log %s: %w", batch[i].ID, err)
+			}
+			file.Records++
+		}
+
+		if len(batch) < exportBatchSize {
+			break
+		}
+		last := batch[len(batch)-1]
+		cursor = &domain.AuditLogCursor{Timestamp: last.Timestamp, ID: last.ID}
+	}
+
+	if err := gz.Close(); err != nil {
+		upload.Abort(ctx)
+		return file, fmt.Errorf("failed to compress %s: %w", key, err)
+	}
+	if err := upload.Complete(ctx); err != nil {
+		upload.Abort(ctx)
+		return file, err
+	}
+
+	file.Size = upload.Size()
+	return file, nil
+}
+
+// putNDJSON writes records, which fit in memory, as one compressed NDJSON object
+func putNDJSON[T any](ctx context.Context, w *ExportWorker, name, key, keyID string, records []T) (tenantExportFile, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	enc := json.NewEncoder(gz)
+	for i := range records {
+		if err := enc.Encode(&records[i]); err != nil {
+			return tenantExportFile{}, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return tenantExportFile{}, fmt.Errorf("failed to compress %s: %w", key, err)
+	}
+
+	file, err := w.putExportFile(ctx, key, "application/gzip", keyID, buf.Bytes())
+	if err != nil {
+		return tenantExportFile{}, err
+	}
+	file.Name, file.Records = name, int64(len(records))
+	return file, nil
+}
+
+func (w *ExportWorker) putExportFile(ctx context.Context, key, contentType, keyID string, data []byte) (tenantExportFile, error) {
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(w.s3Config.ExportBucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String(contentType),
+	}
+	encryptPut(input, keyID)
+	if _, err := w.s3Client.PutObject(ctx, input); err != nil {
+		return tenantExportFile{}, fmt.Errorf("failed to upload %s: %w", key, err)
+	}
+	return tenantExportFile{Key: key, Size: int64(len(data))}, nil
+}
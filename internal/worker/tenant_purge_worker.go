@@ -0,0 +1,182 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/domain"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/repository"
+	"github.com/kingrain94/audit-log-api/internal/repository/opensearch"
+	"github.com/kingrain94/audit-log-api/internal/service/queue"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+// TenantPurgeWorker periodically purges the tenants whose deletion grace period
+// ended. A purge runs in two passes: the first deletes the tenant's OpenSearch
+// indices and hands its logs to the archive worker, which archives them to S3
+// and then enqueues their cleanup. Once no log from before the purge started is
+// left, a later pass drops the tenant row, cascading to its remaining rows.
+type TenantPurgeWorker struct {
+	repository   repository.PostgresRepository
+	messageQueue queue.Queue
+	managers     map[string]opensearch.LifecycleManager
+	config       *config.TenantDeletionConfig
+	logger       *logger.Logger
+	shutdownChan chan struct{}
+	waitGroup    sync.WaitGroup
+}
+
+func NewTenantPurgeWorker(
+	repository repository.PostgresRepository,
+	messageQueue queue.Queue,
+	managers map[string]opensearch.LifecycleManager,
+	config *config.TenantDeletionConfig,
+	logger *logger.Logger,
+) *TenantPurgeWorker {
+	return &TenantPurgeWorker{
+		repository:   repository,
+		messageQueue: messageQueue,
+		managers:     managers,
+		config:       config,
+		logger:       logger,
+		shutdownChan: make(chan struct{}),
+	}
+}
+
+func (w *TenantPurgeWorker) Start() {
+	w.logger.Info("Starting Tenant Purge worker...")
+
+	w.waitGroup.Add(1)
+	go w.run()
+}
+
+func (w *TenantPurgeWorker) Stop() {
+	w.logger.Info("Stopping Tenant Purge worker...")
+	close(w.shutdownChan)
+	w.waitGroup.Wait()
+	w.logger.Info("Tenant Purge worker stopped")
+}
+
+func (w *TenantPurgeWorker) run() {
+	defer w.waitGroup.Done()
+
+	if err := w.purge(context.Background(), time.Now()); err != nil {
+		w.logger.Errorf("Tenant Purge worker failed to purge tenants: %v", err)
+	}
+
+	ticker := time.NewTicker(w.config.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.shutdownChan:
+			w.logger.Info("Tenant Purge worker shutting down")
+			return
+		case now := <-ticker.C:
+			if err := w.purge(context.Background(), now); err != nil {
+				w.logger.Errorf("Tenant Purge worker failed to purge tenants: %v", err)
+			}
+		}
+	}
+}
+
+func (w *TenantPurgeWorker) purge(ctx context.Context, now time.Time) error {
+	tenants, err := w.repository.Tenant().ListPurgeable(ctx, now)
+	if err != nil {
+		return fmt.Errorf("failed to list purgeable tenants: %w", err)
+	}
+
+	for i := range tenants {
+		tenant := &tenants[i]
+		if tenant.PurgeStartedAt == nil {
+			err = w.startPurge(ctx, tenant, now)
+			metrics.ObserveTenantPurgeAction("start", err)
+		} else {
+			err = w.finishPurge(ctx, tenant)
+			metrics.ObserveTenantPurgeAction("finish", err)
+		}
+		if err != nil {
+			w.logger.Errorf("Failed to purge tenant %s: %v", tenant.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// startPurge deletes the tenant's indices and enqueues the archival of all its
+// logs. Marking the purge started last makes a failed attempt retry in full.
+func (w *TenantPurgeWorker) startPurge(ctx context.Context, tenant *domain.Tenant, now time.Time) error {
+	if err := w.deleteIndices(ctx, tenant.ID); err != nil {
+		return err
+	}
+
+	if err := w.messageQueue.SendArchiveMessage(ctx, tenant.ID, "", now); err != nil {
+		return fmt.Errorf("failed to enqueue archival: %w", err)
+	}
+
+	if err := w.repository.Tenant().MarkPurgeStarted(ctx, tenant.ID, now); err != nil {
+		return fmt.Errorf("failed to mark purge started: %w", err)
+	}
+
+	w.logger.Infof("Started purge of tenant %s, deleted on %s", tenant.ID, tenant.DeletedAt.Format(time.RFC3339))
+	return nil
+}
+
+// finishPurge drops the tenant once the archive and cleanup workers removed
+// the logs enqueued when the purge started
+func (w *TenantPurgeWorker) finishPurge(ctx context.Context, tenant *domain.Tenant) error {
+	filter := domain.AuditLogFilter{
+		TenantID: tenant.ID,
+		EndTime:  *tenant.PurgeStartedAt,
+	}
+	remaining, err := w.repository.AuditLog().ListBatch(ctx, filter, nil, 1)
+	if err != nil {
+		return fmt.Errorf("failed to check remaining logs: %w", err)
+	}
+	if len(remaining) > 0 {
+		w.logger.Infof("Tenant %s still has logs waiting for archival", tenant.ID)
+		return nil
+	}
+
+	// Logs indexed after the purge started may have recreated indices
+	if err := w.deleteIndices(ctx, tenant.ID); err != nil {
+		return err
+	}
+
+	if err := w.repository.Tenant().Purge(ctx, tenant.ID); err != nil {
+		return fmt.Errorf("failed to delete tenant: %w", err)
+	}
+
+	w.logger.Infof("Purged tenant %s", tenant.ID)
+	return nil
+}
+
+// deleteIndices deletes the tenant's indices from every OpenSearch cluster,
+// including any the tenant wrote to before moving to another region
+func (w *TenantPurgeWorker) deleteIndices(ctx context.Context, tenantID string) error {
+	for cluster, manager := range w.managers {
+		indices, err := manager.ListIndices(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list indices of cluster %s: %w", cluster, err)
+		}
+
+		var names []string
+		for _, index := range indices {
+			if index.TenantID == tenantID {
+				names = append(names, index.Name)
+			}
+		}
+		if len(names) == 0 {
+			continue
+		}
+
+		if err := manager.DeleteIndices(ctx, names); err != nil {
+			return fmt.Errorf("failed to delete indices of cluster %s: %w", cluster, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/kingrain94/audit-log-api/internal/config"
+	"github.com/kingrain94/audit-log-api/internal/metrics"
+	"github.com/kingrain94/audit-log-api/internal/repository/opensearch"
+	"github.com/kingrain94/audit-log-api/pkg/logger"
+)
+
+// throttle paces the messages of one worker goroutine: no more than the
+// configured rate, and with an exponential backoff, from BackoffMin up to
+// BackoffMax, while OpenSearch or PostgreSQL report pressure
+type throttle struct {
+	worker     string
+	logger     *logger.Logger
+	interval   time.Duration
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	// backoff is the current backoff, zero while the stores keep up
+	backoff time.Duration
+	// next is the earliest time the next message may start
+	next time.Time
+}
+
+func newThrottle(worker string, cfg *config.WorkerConfig, logger *logger.Logger) *throttle {
+	t := &throttle{
+		worker:     worker,
+		logger:     logger,
+		minBackoff: cfg.BackoffMin,
+		maxBackoff: cfg.BackoffMax,
+	}
+	if cfg.RateLimit > 0 {
+		t.interval = time.Duration(float64(time.Second) / cfg.RateLimit)
+	}
+	return t
+}
+
+// wait blocks until the next message may start and reports whether it may,
+// which it may not once ctx is done
+func (t *throttle) wait(ctx context.Context) bool {
+	delay := time.Until(t.next)
+	if delay <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// done records the outcome of a message started at start. It returns the
+// backoff to apply when err reports pressure, and zero otherwise.
+func (t *throttle) done(start time.Time, err error) time.Duration {
+	if !isPressure(err) {
+		t.backoff = 0
+		t.next = start.Add(t.interval)
+		return 0
+	}
+
+	t.backoff = min(max(t.backoff*2, t.minBackoff), t.maxBackoff)
+	t.next = time.Now().Add(t.backoff)
+	metrics.WorkerBackoffsTotal.WithLabelValues(t.worker).Inc()
+	t.logger.Warnf("%s worker backing off for %s: %v", t.worker, t.backoff, err)
+	return t.backoff
+}
+
+// isPressure reports whether err means a store is overloaded rather than the
+// message being bad: OpenSearch 429s, PostgreSQL running out of resources or
+// cancelling a statement on timeout, and timeouts
+func isPressure(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, opensearch.ErrTooManyRequests) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// Class 53 is insufficient resources, 57014 a statement timeout
+		return strings.HasPrefix(pgErr.Code, "53") || pgErr.Code == "57014"
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,152 @@
+// Package auditclient is a Go client for sending audit logs to the audit log API.
+package auditclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// Log is an audit log entry as accepted by POST /api/v1/logs
+type Log struct {
+	TenantID      string          `json:"tenant_id"`
+	UserID        string          `json:"user_id,omitempty"`
+	SessionID     string          `json:"session_id,omitempty"`
+	CorrelationID string          `json:"correlation_id,omitempty"`
+	IPAddress     string          `json:"ip_address,omitempty"`
+	UserAgent     string          `json:"user_agent,omitempty"`
+	Action        string          `json:"action"`
+	ResourceType  string          `json:"resource_type"`
+	ResourceID    string          `json:"resource_id"`
+	Severity      string          `json:"severity"`
+	Message       string          `json:"message"`
+	BeforeState   json.RawMessage `json:"before_state,omitempty"`
+	AfterState    json.RawMessage `json:"after_state,omitempty"`
+	Metadata      json.RawMessage `json:"metadata,omitempty"`
+	Timestamp     time.Time       `json:"timestamp"`
+}
+
+type Config struct {
+	// BaseURL is the API address, e.g. http://localhost:10000
+	BaseURL string
+	// Token is a bearer token issued by /auth/token. TokenSource, when set,
+	// is called per request instead, so callers can refresh expiring tokens.
+	Token       string
+	TokenSource func(ctx context.Context) (string, error)
+	// TenantID fills logs sent without one
+	TenantID string
+	// HTTPClient defaults to a client with a 10s timeout
+	HTTPClient *http.Client
+}
+
+// APIError is returned for non-2xx responses. Code is the API's error code,
+// such as VALIDATION_FAILED or TENANT_QUOTA_EXCEEDED, when it returned one.
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	RequestID  string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("audit log API returned %d: %s", e.StatusCode, e.Message)
+}
+
+type Client struct {
+	config     Config
+	httpClient *http.Client
+}
+
+func New(config Config) *Client {
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultTimeout}
+	}
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+	return &Client{config: config, httpClient: httpClient}
+}
+
+// CreateLog sends a single log
+func (c *Client) CreateLog(ctx context.Context, log Log) error {
+	return c.post(ctx, "/api/v1/logs", c.withDefaults(log))
+}
+
+// BulkCreateLogs sends logs in one request
+func (c *Client) BulkCreateLogs(ctx context.Context, logs []Log) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	batch := make([]Log, len(logs))
+	for i, log := range logs {
+		batch[i] = c.withDefaults(log)
+	}
+	return c.post(ctx, "/api/v1/logs/bulk", batch)
+}
+
+func (c *Client) withDefaults(log Log) Log {
+	if log.TenantID == "" {
+		log.TenantID = c.config.TenantID
+	}
+	if log.Severity == "" {
+		log.Severity = "INFO"
+	}
+	if log.Timestamp.IsZero() {
+		log.Timestamp = time.Now().UTC()
+	}
+	return log
+}
+
+func (c *Client) post(ctx context.Context, path string, body any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal logs: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.BaseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	token := c.config.Token
+	if c.config.TokenSource != nil {
+		if token, err = c.config.TokenSource(ctx); err != nil {
+			return fmt.Errorf("failed to get token: %w", err)
+		}
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send logs: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+	var errBody struct {
+		Code      string `json:"code"`
+		Message   string `json:"message"`
+		RequestID string `json:"request_id"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if json.Unmarshal(data, &errBody) == nil && errBody.Message != "" {
+		apiErr.Code, apiErr.Message, apiErr.RequestID = errBody.Code, errBody.Message, errBody.RequestID
+	} else {
+		apiErr.Message = strings.TrimSpace(string(data))
+	}
+	return apiErr
+}
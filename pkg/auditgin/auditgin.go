@@ -0,0 +1,254 @@
+// Package auditgin is Gin middleware that records mutating HTTP requests as
+// audit logs through the audit log API.
+//
+//	client := auditclient.New(auditclient.Config{BaseURL: "http://audit:10000", Token: token, TenantID: tenantID})
+//	router.Use(auditgin.New(auditgin.Config{Client: client, RedactFields: []string{"password"}}))
+//
+//	func updateUser(c *gin.Context) {
+//		auditgin.SetBefore(c, oldUser)
+//		...
+//		auditgin.SetAfter(c, newUser)
+//	}
+package auditgin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kingrain94/audit-log-api/pkg/auditclient"
+)
+
+const (
+	beforeKey       = "auditgin.before"
+	afterKey        = "auditgin.after"
+	resourceIDKey   = "auditgin.resource_id"
+	actionKey       = "auditgin.action"
+	messageKey      = "auditgin.message"
+	defaultTimeout  = 5 * time.Second
+	redactedValue   = "[REDACTED]"
+	requestIDHeader = "X-Request-ID"
+)
+
+var defaultMethods = []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// Sender delivers audit logs, e.g. an *auditclient.Client
+type Sender interface {
+	CreateLog(ctx context.Context, log auditclient.Log) error
+}
+
+type Config struct {
+	Client Sender
+	// Methods lists the audited HTTP methods (default: POST, PUT, PATCH, DELETE)
+	Methods []string
+	// SampleRate is the fraction of matching requests audited; 0 audits all
+	SampleRate float64
+	// RedactFields names JSON fields, matched case-insensitively at any
+	// depth, whose values are masked in before and after state
+	RedactFields []string
+	// TenantID and UserID read the caller from the request; by default the
+	// client's tenant and the "user_id" context key are used
+	TenantID func(c *gin.Context) string
+	UserID   func(c *gin.Context) string
+	// ResourceType defaults to the last static segment of the matched route
+	ResourceType func(c *gin.Context) string
+	// Skip excludes requests from auditing
+	Skip func(c *gin.Context) bool
+	// Timeout bounds each send (default: 5s)
+	Timeout time.Duration
+	// OnError receives failed sends; logs are sent in the background, so
+	// errors are otherwise dropped
+	OnError func(err error)
+}
+
+// SetBefore records the state of the resource before the handler changes it
+func SetBefore(c *gin.Context, state any) { c.Set(beforeKey, state) }
+
+// SetAfter records the state of the resource after the handler changed it
+func SetAfter(c *gin.Context, state any) { c.Set(afterKey, state) }
+
+// SetResourceID overrides the resource ID, by default the route's :id parameter
+func SetResourceID(c *gin.Context, id string) { c.Set(resourceIDKey, id) }
+
+// SetAction overrides the action derived from the HTTP method
+func SetAction(c *gin.Context, action string) { c.Set(actionKey, action) }
+
+// SetMessage overrides the default "METHOD path -> status" message
+func SetMessage(c *gin.Context, message string) { c.Set(messageKey, message) }
+
+// New returns middleware that sends an audit log for each sampled request
+// with an audited method once its handler has run
+func New(config Config) gin.HandlerFunc {
+	methods := config.Methods
+	if len(methods) == 0 {
+		methods = defaultMethods
+	}
+	audited := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		audited[strings.ToUpper(method)] = true
+	}
+	redact := make(map[string]bool, len(config.RedactFields))
+	for _, field := range config.RedactFields {
+		redact[strings.ToLower(field)] = true
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultTimeout
+	}
+
+	return func(c *gin.Context) {
+		if !audited[c.Request.Method] || (config.Skip != nil && config.Skip(c)) {
+			c.Next()
+			return
+		}
+		if config.SampleRate > 0 && config.SampleRate < 1 && rand.Float64() >= config.SampleRate {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+
+		log := buildLog(c, &config, redact, time.Since(start))
+		ctx := context.WithoutCancel(c.Request.Context())
+		go func() {
+			ctx, cancel := context.WithTimeout(ctx, config.Timeout)
+			defer cancel()
+			if err := config.Client.CreateLog(ctx, log); err != nil && config.OnError != nil {
+				config.OnError(fmt.Errorf("failed to send audit log: %w", err))
+			}
+		}()
+	}
+}
+
+func buildLog(c *gin.Context, config *Config, redact map[string]bool, latency time.Duration) auditclient.Log {
+	status := c.Writer.Status()
+	log := auditclient.Log{
+		IPAddress:     c.ClientIP(),
+		UserAgent:     c.Request.UserAgent(),
+		CorrelationID: c.GetHeader("X-Correlation-ID"),
+		Action:        c.GetString(actionKey),
+		ResourceType:  routeResourceType(c.FullPath()),
+		ResourceID:    c.GetString(resourceIDKey),
+		Severity:      severity(status),
+		Message:       c.GetString(messageKey),
+		Timestamp:     time.Now().UTC(),
+		UserID:        c.GetString("user_id"),
+	}
+	if config.TenantID != nil {
+		log.TenantID = config.TenantID(c)
+	}
+	if config.UserID != nil {
+		log.UserID = config.UserID(c)
+	}
+	if config.ResourceType != nil {
+		log.ResourceType = config.ResourceType(c)
+	}
+	if log.Action == "" {
+		log.Action = methodAction(c.Request.Method)
+	}
+	if log.ResourceID == "" {
+		log.ResourceID = c.Param("id")
+	}
+	if log.ResourceID == "" {
+		log.ResourceID = c.Request.URL.Path
+	}
+	if log.Message == "" {
+		log.Message = fmt.Sprintf("%s %s -> %d", c.Request.Method, c.Request.URL.Path, status)
+	}
+
+	if state, ok := c.Get(beforeKey); ok {
+		log.BeforeState = marshalState(state, redact)
+	}
+	if state, ok := c.Get(afterKey); ok {
+		log.AfterState = marshalState(state, redact)
+	}
+	log.Metadata, _ = json.Marshal(map[string]any{
+		"method":     c.Request.Method,
+		"path":       c.Request.URL.Path,
+		"route":      c.FullPath(),
+		"status":     status,
+		"latency_ms": latency.Milliseconds(),
+		"request_id": c.GetHeader(requestIDHeader),
+	})
+	return log
+}
+
+func methodAction(method string) string {
+	switch method {
+	case http.MethodPost:
+		return "CREATE"
+	case http.MethodPut, http.MethodPatch:
+		return "UPDATE"
+	case http.MethodDelete:
+		return "DELETE"
+	}
+	return method
+}
+
+func severity(status int) string {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return "ERROR"
+	case status >= http.StatusBadRequest:
+		return "WARNING"
+	}
+	return "INFO"
+}
+
+// routeResourceType returns the last segment of route that is not a
+// parameter, e.g. "users" for /api/users/:id
+func routeResourceType(route string) string {
+	segments := strings.Split(strings.Trim(route, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if s := segments[i]; s != "" && !strings.HasPrefix(s, ":") && !strings.HasPrefix(s, "*") {
+			return s
+		}
+	}
+	return "http"
+}
+
+// marshalState encodes state as JSON, masking redacted fields. States that
+// cannot be encoded are dropped.
+func marshalState(state any, redact map[string]bool) json.RawMessage {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil
+	}
+	if len(redact) == 0 {
+		return data
+	}
+
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil
+	}
+	data, err = json.Marshal(redactValue(value, redact))
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func redactValue(value any, redact map[string]bool) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if redact[strings.ToLower(key)] {
+				v[key] = redactedValue
+			} else {
+				v[key] = redactValue(item, redact)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(item, redact)
+		}
+	}
+	return value
+}
@@ -0,0 +1,214 @@
+// Package breaker implements circuit breakers, which stop calls to a failing
+// dependency so they fail at once instead of each waiting on a timeout.
+//
+// A breaker starts closed and lets calls through. After FailureThreshold
+// consecutive failures it opens and rejects calls with an *OpenError for
+// OpenTimeout. It then turns half-open and lets HalfOpenRequests probe calls
+// through: it closes if they all succeed and opens again on the first
+// failure.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// State is the state of a Breaker
+type State int
+
+const (
+	StateClosed State = iota
+	StateHalfOpen
+	StateOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half-open"
+	case StateOpen:
+		return "open"
+	}
+	return fmt.Sprintf("unknown state %d", int(s))
+}
+
+// ErrOpen matches the errors of calls a breaker rejected
+var ErrOpen = errors.New("circuit breaker is open")
+
+// OpenError is returned for a call rejected because the breaker of the
+// dependency is open or already probing it
+type OpenError struct {
+	Name string
+	// RetryAfter is the time left until the breaker lets a probe through
+	RetryAfter time.Duration
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("%s is unavailable: %v", e.Name, ErrOpen)
+}
+
+func (e *OpenError) Is(target error) bool {
+	return target == ErrOpen
+}
+
+// Settings configures a Breaker
+type Settings struct {
+	// Name identifies the dependency in errors and state changes
+	Name string
+	// FailureThreshold is the number of consecutive failures that opens the breaker
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before probing the dependency
+	OpenTimeout time.Duration
+	// HalfOpenRequests is the number of probe calls let through when half-open
+	HalfOpenRequests int
+	// IsFailure reports whether an error of a call is a failure of the
+	// dependency. By default every error but a cancellation is.
+	IsFailure func(err error) bool
+	// OnStateChange, if set, is called on every change of state, with the
+	// breaker locked, so it must not call the breaker
+	OnStateChange func(name string, from, to State)
+}
+
+// Breaker is a circuit breaker. A nil *Breaker lets every call through.
+type Breaker struct {
+	settings Settings
+	now      func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	// probes counts the calls let through while half-open
+	probes int
+	// successes counts the probes that succeeded
+	successes int
+	openedAt  time.Time
+	// generation changes with the state, so the result of a call started in
+	// an earlier state is ignored
+	generation uint64
+}
+
+// New returns a closed breaker. FailureThreshold and HalfOpenRequests are at
+// least 1.
+func New(settings Settings) *Breaker {
+	settings.FailureThreshold = max(settings.FailureThreshold, 1)
+	settings.HalfOpenRequests = max(settings.HalfOpenRequests, 1)
+	if settings.IsFailure == nil {
+		settings.IsFailure = func(err error) bool {
+			return !errors.Is(err, context.Canceled)
+		}
+	}
+	return &Breaker{settings: settings, now: time.Now}
+}
+
+// State returns the current state of the breaker
+func (b *Breaker) State() State {
+	if b == nil {
+		return StateClosed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance()
+	return b.state
+}
+
+// Execute calls fn unless the breaker rejects the call, in which case it
+// returns an *OpenError, and records the outcome of fn
+func (b *Breaker) Execute(fn func() error) error {
+	if b == nil {
+		return fn()
+	}
+
+	generation, err := b.before()
+	if err != nil {
+		return err
+	}
+	err = fn()
+	b.after(generation, err == nil || !b.settings.IsFailure(err))
+	return err
+}
+
+// Call is Execute for a function returning a value
+func Call[T any](b *Breaker, fn func() (T, error)) (T, error) {
+	var value T
+	err := b.Execute(func() error {
+		var err error
+		value, err = fn()
+		return err
+	})
+	return value, err
+}
+
+// before admits a call, returning the generation it runs in
+func (b *Breaker) before() (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance()
+	switch b.state {
+	case StateOpen:
+		return 0, &OpenError{Name: b.settings.Name, RetryAfter: b.openedAt.Add(b.settings.OpenTimeout).Sub(b.now())}
+	case StateHalfOpen:
+		if b.probes >= b.settings.HalfOpenRequests {
+			return 0, &OpenError{Name: b.settings.Name}
+		}
+		b.probes++
+	}
+	return b.generation, nil
+}
+
+// after records the outcome of a call admitted in generation
+func (b *Breaker) after(generation uint64, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance()
+	if generation != b.generation {
+		return
+	}
+
+	switch b.state {
+	case StateClosed:
+		if success {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.settings.FailureThreshold {
+			b.setState(StateOpen)
+		}
+	case StateHalfOpen:
+		if !success {
+			b.setState(StateOpen)
+			return
+		}
+		b.successes++
+		if b.successes >= b.settings.HalfOpenRequests {
+			b.setState(StateClosed)
+		}
+	}
+}
+
+// advance turns an open breaker half-open once OpenTimeout has passed
+func (b *Breaker) advance() {
+	if b.state == StateOpen && !b.now().Before(b.openedAt.Add(b.settings.OpenTimeout)) {
+		b.setState(StateHalfOpen)
+	}
+}
+
+func (b *Breaker) setState(state State) {
+	from := b.state
+	b.state = state
+	b.generation++
+	b.failures, b.probes, b.successes = 0, 0, 0
+	if state == StateOpen {
+		b.openedAt = b.now()
+	}
+	if b.settings.OnStateChange != nil {
+		b.settings.OnStateChange(b.settings.Name, from, state)
+	}
+}
@@ -0,0 +1,301 @@
+// Package envelope encrypts streams for a recipient's RSA public key, so only
+// the holder of the private key can read them.
+//
+// Each stream is encrypted with a random AES-256 key, which is wrapped with
+// RSA-OAEP (SHA-256) for the recipient. The format is:
+//
+//	magic "AUDITENC" | version (1 byte) | wrapped key length (uint16, big endian) | wrapped key | chunks
+//
+// The plaintext is split into chunks of ChunkSize bytes, each sealed with
+// AES-GCM. The 12-byte nonce is the chunk's index as a big endian integer in
+// the first 11 bytes followed by 1 for the last chunk and 0 for the others,
+// so chunks can't be reordered, dropped or truncated unnoticed. Every chunk
+// but the last holds exactly ChunkSize bytes; the last is shorter, possibly
+// empty.
+package envelope
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// ChunkSize is the plaintext size of every chunk but the last
+	ChunkSize = 64 * 1024
+
+	// Scheme names the encryption, for object metadata and documentation
+	Scheme = "rsa-oaep-sha256+aes-256-gcm"
+
+	// MinKeyBits is the smallest RSA key accepted
+	MinKeyBits = 2048
+
+	magic   = "AUDITENC"
+	version = 1
+	keySize = 32
+)
+
+var (
+	// ErrInvalidPublicKey is returned for keys that aren't PEM-encoded RSA
+	// public keys of at least MinKeyBits
+	ErrInvalidPublicKey = errors.New("invalid RSA public key")
+	// ErrInvalidPrivateKey is returned for keys that aren't PEM-encoded RSA
+	// private keys of at least MinKeyBits
+	ErrInvalidPrivateKey = errors.New("invalid RSA private key")
+	// ErrCorrupted is returned for streams that aren't in the format, were
+	// altered or were truncated
+	ErrCorrupted = errors.New("encrypted stream is corrupted")
+)
+
+// ParsePublicKey parses a PEM-encoded RSA public key, either PKIX ("PUBLIC
+// KEY") or PKCS #1 ("RSA PUBLIC KEY")
+func ParsePublicKey(data string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, fmt.Errorf("%w: no PEM block found", ErrInvalidPublicKey)
+	}
+
+	var key any
+	var err error
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%w: unexpected PEM block %q", ErrInvalidPublicKey, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+	}
+
+	pub, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: not an RSA key", ErrInvalidPublicKey)
+	}
+	if pub.N.BitLen() < MinKeyBits {
+		return nil, fmt.Errorf("%w: key has %d bits, at least %d are required", ErrInvalidPublicKey, pub.N.BitLen(), MinKeyBits)
+	}
+	return pub, nil
+}
+
+// ParsePrivateKey parses a PEM-encoded RSA private key, either PKCS #8
+// ("PRIVATE KEY") or PKCS #1 ("RSA PRIVATE KEY")
+func ParsePrivateKey(data string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, fmt.Errorf("%w: no PEM block found", ErrInvalidPrivateKey)
+	}
+
+	var key any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%w: unexpected PEM block %q", ErrInvalidPrivateKey, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPrivateKey, err)
+	}
+
+	priv, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: not an RSA key", ErrInvalidPrivateKey)
+	}
+	if priv.N.BitLen() < MinKeyBits {
+		return nil, fmt.Errorf("%w: key has %d bits, at least %d are required", ErrInvalidPrivateKey, priv.N.BitLen(), MinKeyBits)
+	}
+	return priv, nil
+}
+
+// Writer encrypts what is written to it. Close must be called to write the
+// last chunk; it doesn't close the underlying writer.
+type Writer struct {
+	w     io.Writer
+	aead  cipher.AEAD
+	buf   []byte
+	index uint64
+	err   error
+}
+
+// NewWriter writes the header of a stream encrypted for pub to w and returns
+// a Writer encrypting what follows
+func NewWriter(w io.Writer, pub *rsa.PublicKey) (*Writer, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	wrapped, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, key, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap key: %w", err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, len(magic)+3+len(wrapped))
+	header = append(header, magic...)
+	header = append(header, version)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(wrapped)))
+	header = append(header, wrapped...)
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &Writer{w: w, aead: aead, buf: make([]byte, 0, ChunkSize+aead.Overhead())}, nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	written := 0
+	for len(p) > 0 {
+		// A full chunk is only sealed once more data follows, since the last
+		// chunk must be shorter
+		if len(w.buf) == ChunkSize {
+			if w.err = w.seal(false); w.err != nil {
+				return written, w.err
+			}
+		}
+		n := copy(w.buf[len(w.buf):ChunkSize], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close seals and writes the last chunk
+func (w *Writer) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	if len(w.buf) == ChunkSize {
+		if w.err = w.seal(false); w.err != nil {
+			return w.err
+		}
+	}
+	if w.err = w.seal(true); w.err != nil {
+		return w.err
+	}
+	w.err = errors.New("envelope: write to closed writer")
+	return nil
+}
+
+func (w *Writer) seal(last bool) error {
+	sealed := w.aead.Seal(w.buf[:0], nonce(w.index, last), w.buf, nil)
+	w.index++
+	w.buf = w.buf[:0]
+	_, err := w.w.Write(sealed)
+	return err
+}
+
+// Reader decrypts a stream written by a Writer
+type Reader struct {
+	r     io.Reader
+	aead  cipher.AEAD
+	chunk []byte
+	plain []byte
+	index uint64
+	done  bool
+}
+
+// NewReader reads the header of a stream encrypted for priv from r and
+// returns a Reader decrypting it. Reads return ErrCorrupted for streams that
+// were altered or truncated.
+func NewReader(r io.Reader, priv *rsa.PrivateKey) (*Reader, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(magic)+3)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+	if !bytes.Equal(header[:len(magic)], []byte(magic)) || header[len(magic)] != version {
+		return nil, fmt.Errorf("%w: unknown header", ErrCorrupted)
+	}
+
+	wrapped := make([]byte, binary.BigEndian.Uint16(header[len(magic)+1:]))
+	if _, err := io.ReadFull(br, wrapped); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+	key, err := rsa.DecryptOAEP(sha256.New(), nil, priv, wrapped, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap key: %w", err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reader{r: br, aead: aead, chunk: make([]byte, ChunkSize+aead.Overhead())}, nil
+}
+
+func (r *Reader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+// open reads and decrypts the next chunk; a short one is the last
+func (r *Reader) open() error {
+	n, err := io.ReadFull(r.r, r.chunk)
+	last := false
+	switch {
+	case err == io.ErrUnexpectedEOF:
+		last = true
+	case err == io.EOF:
+		return fmt.Errorf("%w: last chunk missing", ErrCorrupted)
+	case err != nil:
+		return err
+	}
+
+	plain, err := r.aead.Open(r.chunk[:0], nonce(r.index, last), r.chunk[:n], nil)
+	if err != nil {
+		return fmt.Errorf("%w: chunk %d failed authentication", ErrCorrupted, r.index)
+	}
+	r.index++
+	r.plain = plain
+	r.done = last
+	return nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// nonce is the chunk's index followed by the last chunk flag
+func nonce(index uint64, last bool) []byte {
+	n := make([]byte, 12)
+	binary.BigEndian.PutUint64(n[3:11], index)
+	if last {
+		n[11] = 1
+	}
+	return n
+}
@@ -0,0 +1,291 @@
+// Package jsondiff compares two JSON documents, either structurally, as the
+// list of paths that were added, removed or changed, or as a unified text
+// diff of their indented forms.
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Op is the kind of a change
+type Op string
+
+const (
+	OpAdded   Op = "added"
+	OpRemoved Op = "removed"
+	OpChanged Op = "changed"
+)
+
+// Change is a difference at a path such as "customer.addresses[0].city"; the
+// empty path is the document itself. Added and removed objects and arrays are
+// reported as one change rather than one per nested value.
+type Change struct {
+	Path   string `json:"path"`
+	Op     Op     `json:"op"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+// Diff returns the changes from before to after, ordered by path. An empty
+// document counts as an empty object when compared with an object.
+func Diff(before, after json.RawMessage) ([]Change, error) {
+	a, err := decode(before)
+	if err != nil {
+		return nil, fmt.Errorf("invalid before document: %w", err)
+	}
+	b, err := decode(after)
+	if err != nil {
+		return nil, fmt.Errorf("invalid after document: %w", err)
+	}
+
+	// Comparing an object with nothing lists its keys instead of replacing it whole
+	if _, ok := b.(map[string]any); ok && a == nil {
+		a = map[string]any{}
+	}
+	if _, ok := a.(map[string]any); ok && b == nil {
+		b = map[string]any{}
+	}
+
+	changes := make([]Change, 0)
+	compare("", a, b, &changes)
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+func decode(data json.RawMessage) (any, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func compare(path string, a, b any, changes *[]Change) {
+	switch a := a.(type) {
+	case map[string]any:
+		if b, ok := b.(map[string]any); ok {
+			compareObjects(path, a, b, changes)
+			return
+		}
+	case []any:
+		if b, ok := b.([]any); ok {
+			compareArrays(path, a, b, changes)
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		*changes = append(*changes, Change{Path: path, Op: OpChanged, Before: a, After: b})
+	}
+}
+
+func compareObjects(path string, a, b map[string]any, changes *[]Change) {
+	for key, before := range a {
+		after, ok := b[key]
+		if !ok {
+			*changes = append(*changes, Change{Path: join(path, key), Op: OpRemoved, Before: before})
+			continue
+		}
+		compare(join(path, key), before, after, changes)
+	}
+	for key, after := range b {
+		if _, ok := a[key]; !ok {
+			*changes = append(*changes, Change{Path: join(path, key), Op: OpAdded, After: after})
+		}
+	}
+}
+
+// compareArrays compares elements by position, so an insertion shows up as
+// changes to every later element
+func compareArrays(path string, a, b []any, changes *[]Change) {
+	for i := 0; i < max(len(a), len(b)); i++ {
+		elementPath := path + "[" + strconv.Itoa(i) + "]"
+		switch {
+		case i >= len(b):
+			*changes = append(*changes, Change{Path: elementPath, Op: OpRemoved, Before: a[i]})
+		case i >= len(a):
+			*changes = append(*changes, Change{Path: elementPath, Op: OpAdded, After: b[i]})
+		default:
+			compare(elementPath, a[i], b[i], changes)
+		}
+	}
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// contextLines is the number of unchanged lines shown around each change
+const contextLines = 3
+
+// maxUnifiedCells bounds the work of matching lines; larger documents are
+// shown as entirely replaced
+const maxUnifiedCells = 4_000_000
+
+// Unified returns a unified diff of the indented documents, with object keys
+// sorted so that only real changes show. It is empty if they are equal.
+func Unified(before, after json.RawMessage, beforeName, afterName string) (string, error) {
+	a, err := indentedLines(before)
+	if err != nil {
+		return "", fmt.Errorf("invalid before document: %w", err)
+	}
+	b, err := indentedLines(after)
+	if err != nil {
+		return "", fmt.Errorf("invalid after document: %w", err)
+	}
+
+	edits := diffLines(a, b)
+	if len(edits) == 0 {
+		return "", nil
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", beforeName, afterName)
+	for _, h := range hunks(edits) {
+		writeHunk(&out, h)
+	}
+	return out.String(), nil
+}
+
+func indentedLines(data json.RawMessage) ([]string, error) {
+	v, err := decode(data)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	// Re-encoding the decoded value sorts object keys
+	indented, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(string(indented), "\n"), nil
+}
+
+// edit is a line kept (' '), removed ('-') or added ('+'), with its line
+// numbers in either document
+type edit struct {
+	kind         byte
+	line         string
+	aLine, bLine int
+}
+
+// diffLines matches lines by their longest common subsequence. It returns no
+// edits if the documents are equal.
+func diffLines(a, b []string) []edit {
+	if slices.Equal(a, b) {
+		return nil
+	}
+
+	n, m := len(a), len(b)
+	if n*m > maxUnifiedCells {
+		edits := make([]edit, 0, n+m)
+		for i, line := range a {
+			edits = append(edits, edit{kind: '-', line: line, aLine: i, bLine: 0})
+		}
+		for j, line := range b {
+			edits = append(edits, edit{kind: '+', line: line, aLine: n, bLine: j})
+		}
+		return edits
+	}
+
+	// lcs[i][j] is the length of the common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	edits := make([]edit, 0, n+m)
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && a[i] == b[j]:
+			edits = append(edits, edit{kind: ' ', line: a[i], aLine: i, bLine: j})
+			i++
+			j++
+		case i < n && (j == m || lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, edit{kind: '-', line: a[i], aLine: i, bLine: j})
+			i++
+		default:
+			edits = append(edits, edit{kind: '+', line: b[j], aLine: i, bLine: j})
+			j++
+		}
+	}
+	return edits
+}
+
+// hunks groups changed lines with their context, merging groups whose
+// context overlaps
+func hunks(edits []edit) [][]edit {
+	var groups [][]edit
+	start, end := -1, -1
+	for i, e := range edits {
+		if e.kind == ' ' {
+			continue
+		}
+		from, to := max(i-contextLines, 0), min(i+contextLines+1, len(edits))
+		if start >= 0 && from <= end {
+			end = to
+			continue
+		}
+		if start >= 0 {
+			groups = append(groups, edits[start:end])
+		}
+		start, end = from, to
+	}
+	if start >= 0 {
+		groups = append(groups, edits[start:end])
+	}
+	return groups
+}
+
+func writeHunk(out *strings.Builder, h []edit) {
+	var aCount, bCount int
+	for _, e := range h {
+		if e.kind != '+' {
+			aCount++
+		}
+		if e.kind != '-' {
+			bCount++
+		}
+	}
+
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(h[0].aLine, aCount), hunkRange(h[0].bLine, bCount))
+	for _, e := range h {
+		out.WriteByte(e.kind)
+		out.WriteString(e.line)
+		out.WriteByte('\n')
+	}
+}
+
+// hunkRange formats a hunk's first line, counted from one, and length; an
+// empty range names the line before it
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
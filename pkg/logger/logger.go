@@ -31,6 +31,11 @@ func NewLogger(env string) *Logger {
 	}
 }
 
+// With returns a logger adding the fields to every line it writes
+func (l *Logger) With(fields ...zap.Field) *Logger {
+	return &Logger{Logger: l.Logger.With(fields...)}
+}
+
 func (l *Logger) Info(msg string, fields ...zap.Field) {
 	l.Logger.Info(msg, fields...)
 }
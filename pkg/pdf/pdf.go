@@ -0,0 +1,221 @@
+// Package pdf writes simple PDF documents: A4 pages of text in the standard
+// Helvetica fonts, lines and filled rectangles. Coordinates are in points
+// from the top-left corner of the page, y growing downwards, and text is
+// positioned by its baseline. Text is encoded as WinAnsi, so characters
+// outside Latin-1 are written as '?'.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The size of an A4 page in points
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// Font is one of the standard fonts every PDF reader has
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+)
+
+// fontNames are the base fonts of Font, in order
+var fontNames = []string{"Helvetica", "Helvetica-Bold"}
+
+// Document is a PDF document built in memory. Add pages with AddPage and
+// write the document with WriteTo.
+type Document struct {
+	title   string
+	created time.Time
+	pages   []*Page
+}
+
+// New starts a document with the given title, created at created
+func New(title string, created time.Time) *Document {
+	return &Document{title: title, created: created}
+}
+
+// AddPage adds a blank page to the end of the document
+func (d *Document) AddPage() *Page {
+	p := &Page{}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// Pages returns the pages of the document in order
+func (d *Document) Pages() []*Page {
+	return d.pages
+}
+
+// Page is a page of a Document. Its drawing methods append to the page's
+// content, so later ones paint over earlier ones.
+type Page struct {
+	content bytes.Buffer
+}
+
+// Text draws text with its baseline starting at (x, y) in color, an RGB hex
+// value such as "1F2937"
+func (p *Page) Text(x, y float64, font Font, size float64, color, text string) {
+	fmt.Fprintf(&p.content, "BT %s rg /F%d %s Tf %s %s Td (%s) Tj ET\n",
+		rgb(color), font+1, num(size), num(x), num(PageHeight-y), encode(text))
+}
+
+// Rect fills the rectangle with its top-left corner at (x, y) in color
+func (p *Page) Rect(x, y, width, height float64, color string) {
+	fmt.Fprintf(&p.content, "%s rg %s %s %s %s re f\n",
+		rgb(color), num(x), num(PageHeight-y-height), num(width), num(height))
+}
+
+// Line strokes a line from (x1, y1) to (x2, y2) in color
+func (p *Page) Line(x1, y1, x2, y2, width float64, color string) {
+	fmt.Fprintf(&p.content, "%s RG %s w %s %s m %s %s l S\n",
+		rgb(color), num(width), num(x1), num(PageHeight-y1), num(x2), num(PageHeight-y2))
+}
+
+// WriteTo writes the document to w
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var b bytes.Buffer
+	var offsets []int
+	object := func(format string, args ...any) {
+		offsets = append(offsets, b.Len())
+		fmt.Fprintf(&b, "%d 0 obj\n", len(offsets))
+		fmt.Fprintf(&b, format, args...)
+		b.WriteString("\nendobj\n")
+	}
+
+	// Objects 1 to 4 are the catalog, the page tree and the fonts, followed
+	// by each page and its content, then the document information
+	b.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages))
+	for _, name := range fontNames {
+		object("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name)
+	}
+
+	for i, page := range d.pages {
+		object("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			num(PageWidth), num(PageHeight), 6+2*i)
+
+		var content bytes.Buffer
+		zw := zlib.NewWriter(&content)
+		if _, err := zw.Write(page.content.Bytes()); err != nil {
+			return 0, err
+		}
+		if err := zw.Close(); err != nil {
+			return 0, err
+		}
+		object("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", content.Len(), content.Bytes())
+	}
+
+	object("<< /Title (%s) /Producer (audit-log-api) /CreationDate (D:%s) >>",
+		encode(d.title), d.created.UTC().Format("20060102150405Z"))
+	info := len(offsets)
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, info, xref)
+
+	n, err := w.Write(b.Bytes())
+	return int64(n), err
+}
+
+// TextWidth returns the width of text drawn in font at size
+func TextWidth(text string, font Font, size float64) float64 {
+	widths := helveticaWidths
+	if font == HelveticaBold {
+		widths = helveticaBoldWidths
+	}
+
+	var units int
+	for _, c := range winAnsi(text) {
+		if c >= ' ' && int(c-' ') < len(widths) {
+			units += widths[c-' ']
+		} else {
+			units += latin1Width
+		}
+	}
+	return float64(units) * size / 1000
+}
+
+// Truncate shortens text to fit width when drawn in font at size, ending it
+// with an ellipsis
+func Truncate(text string, font Font, size, width float64) string {
+	if TextWidth(text, font, size) <= width {
+		return text
+	}
+	limit := width - TextWidth("...", font, size)
+	if limit < 0 {
+		return ""
+	}
+	var used float64
+	for i, r := range text {
+		if used += TextWidth(string(r), font, size); used > limit {
+			return text[:i] + "..."
+		}
+	}
+	return text
+}
+
+// winAnsi encodes text as WinAnsi, replacing control characters with spaces
+// and characters it lacks with '?'
+func winAnsi(text string) []byte {
+	b := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r < ' ':
+			b = append(b, ' ')
+		case r < 0x7f || (r >= 0xa0 && r <= 0xff):
+			b = append(b, byte(r))
+		default:
+			b = append(b, '?')
+		}
+	}
+	return b
+}
+
+// encode encodes text for a PDF string literal
+func encode(text string) string {
+	var b strings.Builder
+	for _, c := range winAnsi(text) {
+		if c == '(' || c == ')' || c == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// rgb returns the PDF color operands of an RGB hex value, black if invalid
+func rgb(color string) string {
+	v, err := strconv.ParseUint(strings.TrimPrefix(color, "#"), 16, 32)
+	if err != nil || len(strings.TrimPrefix(color, "#")) != 6 {
+		v = 0
+	}
+	component := func(c uint64) string {
+		return strconv.FormatFloat(float64(c&0xff)/255, 'f', 3, 64)
+	}
+	return component(v>>16) + " " + component(v>>8) + " " + component(v)
+}
+
+// num formats a number for the content of a page, to a hundredth of a point
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
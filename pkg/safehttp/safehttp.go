@@ -0,0 +1,126 @@
+// Package safehttp posts to URLs that tenants configure, such as webhooks,
+// without letting them reach the server's own network.
+//
+// URLs must be https and may not name a host that isn't publicly routable.
+// Since a public name can resolve to a private address, or be rebound to one
+// after validation, Client also checks every address it connects to, so a
+// request never reaches loopback, private, link-local (including the cloud
+// metadata endpoints), shared or otherwise reserved addresses.
+package safehttp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	// ErrInsecureURL is returned for URLs that aren't https
+	ErrInsecureURL = errors.New("url must be https")
+	// ErrBlockedAddress is returned for hosts and addresses that aren't publicly routable
+	ErrBlockedAddress = errors.New("address is not publicly routable")
+)
+
+// reservedPrefixes are the ranges netip doesn't classify that still aren't
+// publicly routable
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "this" network
+	netip.MustParsePrefix("100.64.0.0/10"),   // shared address space, e.g. Alibaba Cloud metadata
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved, and broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64, mapping IPv4 addresses
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // local-use NAT64
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+	netip.MustParsePrefix("2002::/16"),       // 6to4, mapping IPv4 addresses
+}
+
+// IsPublic reports whether addr is a publicly routable unicast address
+func IsPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateURL checks that raw is an https URL whose host may be public: a
+// public IP address or a name other than localhost. Where the name resolves
+// is checked when connecting.
+func ValidateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid url %q", raw)
+	}
+	if u.Scheme != "https" {
+		return ErrInsecureURL
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !IsPublic(addr) {
+			return ErrBlockedAddress
+		}
+		return nil
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrBlockedAddress
+	}
+	return nil
+}
+
+// NewClient returns a client with the timeout that only connects to public
+// addresses and doesn't use a proxy, which would connect on its behalf.
+// Redirects are followed only to https URLs, and connect through the same
+// checks.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   control,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			if req.URL.Scheme != "https" {
+				return ErrInsecureURL
+			}
+			return nil
+		},
+	}
+}
+
+// control refuses connections to addresses that aren't public. It runs after
+// the name is resolved, for every address tried.
+func control(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid address %q: %w", address, err)
+	}
+	if !IsPublic(addrPort.Addr()) {
+		return fmt.Errorf("connection to %s refused: %w", addrPort.Addr(), ErrBlockedAddress)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+// Package xlsx writes single-sheet Excel workbooks as a stream. Rows are
+// compressed into the output as they are written, so a workbook of any size
+// is written with bounded memory. The sheet's header row is bold and frozen,
+// columns have fixed widths and cells can be colored with fills.
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxRows is the number of rows a sheet holds, the header included
+const MaxRows = 1048576
+
+// maxCellLength is the number of characters a cell holds
+const maxCellLength = 32767
+
+// ErrTooManyRows is returned when a row is written to a full sheet
+var ErrTooManyRows = errors.New("xlsx: sheet is limited to 1048576 rows")
+
+// Column is a column of the sheet, named in the header row
+type Column struct {
+	Header string
+	// Width in characters; zero uses the default width
+	Width float64
+}
+
+// Style is the style of a cell: StyleDefault, StyleHeader or one returned by
+// Writer.Fill
+type Style int
+
+const (
+	StyleDefault Style = iota
+	StyleHeader
+	firstFillStyle
+)
+
+// Cell is a text cell of a row
+type Cell struct {
+	Value string
+	Style Style
+}
+
+// Writer writes a workbook to an io.Writer. Write the rows with WriteRow and
+// complete the workbook with Close.
+type Writer struct {
+	zip     *zip.Writer
+	sheet   *bufio.Writer
+	columns int
+	rows    int
+	fills   int
+}
+
+// NewWriter starts a workbook with a sheet of the given name and columns,
+// writing its header row. Each of fills, an RGB color such as "FFC7CE",
+// defines a style that Fill returns.
+func NewWriter(out io.Writer, sheetName string, columns []Column, fills ...string) (*Writer, error) {
+	w := &Writer{zip: zip.NewWriter(out), columns: len(columns), fills: len(fills)}
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", contentTypes},
+		{"_rels/.rels", rootRels},
+		{"xl/workbook.xml", fmt.Sprintf(workbook, escape(sheetName))},
+		{"xl/_rels/workbook.xml.rels", workbookRels},
+		{"xl/styles.xml", styles(fills)},
+	}
+	for _, part := range parts {
+		f, err := w.zip.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := w.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	w.sheet = bufio.NewWriter(f)
+
+	w.sheet.WriteString(xml.Header)
+	w.sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	w.sheet.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	w.sheet.WriteString(`<sheetFormatPr defaultRowHeight="15"/>`)
+	if len(columns) > 0 {
+		w.sheet.WriteString("<cols>")
+		for i, column := range columns {
+			if column.Width > 0 {
+				fmt.Fprintf(w.sheet, `<col min="%d" max="%d" width="%s" customWidth="1"/>`, i+1, i+1, strconv.FormatFloat(column.Width, 'f', -1, 64))
+			}
+		}
+		w.sheet.WriteString("</cols>")
+	}
+	w.sheet.WriteString("<sheetData>")
+
+	header := make([]Cell, len(columns))
+	for i, column := range columns {
+		header[i] = Cell{Value: column.Header, Style: StyleHeader}
+	}
+	if err := w.WriteRow(header...); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Fill returns the style of the i-th fill given to NewWriter
+func (w *Writer) Fill(i int) Style {
+	if i < 0 || i >= w.fills {
+		return StyleDefault
+	}
+	return firstFillStyle + Style(i)
+}
+
+// WriteRow writes the next row of the sheet
+func (w *Writer) WriteRow(cells ...Cell) error {
+	if w.rows >= MaxRows {
+		return ErrTooManyRows
+	}
+	w.rows++
+
+	fmt.Fprintf(w.sheet, `<row r="%d">`, w.rows)
+	for i, cell := range cells {
+		ref := columnName(i) + strconv.Itoa(w.rows)
+		if cell.Value == "" {
+			if cell.Style != StyleDefault {
+				fmt.Fprintf(w.sheet, `<c r="%s" s="%d"/>`, ref, cell.Style)
+			}
+			continue
+		}
+		fmt.Fprintf(w.sheet, `<c r="%s" t="inlineStr"`, ref)
+		if cell.Style != StyleDefault {
+			fmt.Fprintf(w.sheet, ` s="%d"`, cell.Style)
+		}
+		w.sheet.WriteString(`><is><t xml:space="preserve">`)
+		w.sheet.WriteString(escape(truncate(cell.Value)))
+		w.sheet.WriteString("</t></is></c>")
+	}
+	_, err := w.sheet.WriteString("</row>")
+	return err
+}
+
+// Close completes the workbook, adding a filter to the header row. It
+// doesn't close the underlying writer.
+func (w *Writer) Close() error {
+	w.sheet.WriteString("</sheetData>")
+	if w.columns > 0 {
+		fmt.Fprintf(w.sheet, `<autoFilter ref="A1:%s%d"/>`, columnName(w.columns-1), w.rows)
+	}
+	w.sheet.WriteString("</worksheet>")
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+	return w.zip.Close()
+}
+
+// columnName returns the letters of the i-th column, counting from 0: A to Z,
+// then AA and so on
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// truncate cuts a value to the characters a cell holds
+func truncate(s string) string {
+	if len(s) <= maxCellLength || utf8.RuneCountInString(s) <= maxCellLength {
+		return s
+	}
+	return string([]rune(s)[:maxCellLength])
+}
+
+// escape escapes a value for XML text and attributes, replacing characters
+// XML can't hold
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,24 @@
+-- Analytics copy of the audit logs, filled by the index worker when
+-- CLICKHOUSE_ADDR is set. Only the columns stats filter or group by are kept.
+CREATE DATABASE IF NOT EXISTS audit_log;
+
+-- Redelivered index messages insert logs again; ReplacingMergeTree merges rows
+-- with the same sorting key away and queries read it with FINAL meanwhile
+CREATE TABLE IF NOT EXISTS audit_log.audit_logs (
+    id UUID,
+    tenant_id String,
+    user_id String,
+    session_id String,
+    correlation_id String,
+    ip_address String,
+    user_agent String,
+    action LowCardinality(String),
+    resource_type LowCardinality(String),
+    resource_id String,
+    severity LowCardinality(String),
+    message String,
+    timestamp DateTime64(3, 'UTC')
+)
+ENGINE = ReplacingMergeTree
+PARTITION BY toYYYYMM(timestamp)
+ORDER BY (tenant_id, timestamp, id);
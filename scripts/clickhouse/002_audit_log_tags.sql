@@ -0,0 +1,3 @@
+-- Tags given to logs at ingest by their tenant's tagging rules, which stats
+-- filter by
+ALTER TABLE audit_log.audit_logs ADD COLUMN IF NOT EXISTS tags Array(LowCardinality(String)) AFTER message;
@@ -0,0 +1,3 @@
+-- Threat score of the log's IP address, copied from its metadata, which stats
+-- filter by
+ALTER TABLE audit_log.audit_logs ADD COLUMN IF NOT EXISTS threat_score UInt8 DEFAULT 0 AFTER tags;
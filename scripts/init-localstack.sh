@@ -44,6 +44,28 @@ aws --endpoint-url=http://localhost:4566 sqs create-queue \
         "ReceiveMessageWaitTimeSeconds": "20"
     }'
 
+# Create export queue (for asynchronous export jobs; exports can run for minutes)
+echo "Creating audit-log-export-queue..."
+aws --endpoint-url=http://localhost:4566 sqs create-queue \
+    --queue-name audit-log-export-queue \
+    --attributes '{
+        "VisibilityTimeout": "900",
+        "MessageRetentionPeriod": "86400",
+        "DelaySeconds": "0",
+        "ReceiveMessageWaitTimeSeconds": "20"
+    }'
+
+# Create ingest queue (for logs accepted with ?async=true, stored by the ingest worker)
+echo "Creating audit-log-ingest-queue..."
+aws --endpoint-url=http://localhost:4566 sqs create-queue \
+    --queue-name audit-log-ingest-queue \
+    --attributes '{
+        "VisibilityTimeout": "30",
+        "MessageRetentionPeriod": "345600",
+        "DelaySeconds": "0",
+        "ReceiveMessageWaitTimeSeconds": "20"
+    }'
+
 # Create S3 buckets
 echo "Creating S3 buckets..."
 
@@ -51,3 +73,6 @@ echo "Creating S3 buckets..."
 echo "Creating audit-log-archives bucket..."
 aws --endpoint-url=http://localhost:4566 s3 mb s3://audit-log-archives
 
+# Create export bucket
+echo "Creating audit-log-exports bucket..."
+aws --endpoint-url=http://localhost:4566 s3 mb s3://audit-log-exports
@@ -63,16 +63,16 @@ check_dependencies() {
 generate_token() {
     print_status "Generating JWT token..."
     
-    # Use the generate_token script from the project
-    if [ -f "./scripts/generate_token.go" ]; then
-        JWT_TOKEN=$(go run ./scripts/generate_token.go -user=test-user -roles=admin,user,auditor -tenant=$TENANT_ID 2>/dev/null | tail -1)
+    # Use the auditctl CLI from the project
+    if [ -d "./cmd/auditctl" ]; then
+        JWT_TOKEN=$(go run ./cmd/auditctl token -user=test-user -roles=admin,user,auditor -tenant=$TENANT_ID 2>/dev/null | tail -1)
         if [ -z "$JWT_TOKEN" ]; then
             print_error "Failed to generate JWT token"
             exit 1
         fi
         print_success "JWT token generated"
     else
-        print_error "generate_token.go script not found"
+        print_error "cmd/auditctl not found"
         exit 1
     fi
 }
@@ -0,0 +1,25 @@
+-- +migrate Up
+-- Create outbox_events table for transactional side effects (indexing, broadcasting)
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    processed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Pending events are claimed in creation order
+CREATE INDEX idx_outbox_events_pending ON outbox_events(created_at) WHERE processed_at IS NULL;
+
+-- Processed events are purged by age
+CREATE INDEX idx_outbox_events_processed_at ON outbox_events(processed_at) WHERE processed_at IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_outbox_events_processed_at;
+DROP INDEX IF EXISTS idx_outbox_events_pending;
+
+DROP TABLE IF EXISTS outbox_events;
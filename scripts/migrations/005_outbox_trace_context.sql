@@ -0,0 +1,6 @@
+-- +migrate Up
+-- W3C trace context captured at ingest, so the outbox relay continues the request trace
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS trace_context JSONB;
+
+-- +migrate Down
+ALTER TABLE outbox_events DROP COLUMN IF EXISTS trace_context;
@@ -0,0 +1,6 @@
+-- +migrate Up
+-- Extra requests a tenant may burst above its per-minute rate_limit
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS rate_limit_burst INTEGER NOT NULL DEFAULT 0;
+
+-- +migrate Down
+ALTER TABLE tenants DROP COLUMN IF EXISTS rate_limit_burst;
@@ -0,0 +1,22 @@
+-- +migrate Up
+-- Create export_jobs table for asynchronous exports delivered to S3
+CREATE TABLE IF NOT EXISTS export_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    status TEXT NOT NULL,
+    format TEXT NOT NULL,
+    filter JSONB NOT NULL,
+    s3_key TEXT,
+    row_count BIGINT NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_export_jobs_tenant_created_at ON export_jobs(tenant_id, created_at DESC);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_export_jobs_tenant_created_at;
+
+DROP TABLE IF EXISTS export_jobs;
@@ -0,0 +1,22 @@
+-- +migrate Up
+-- Create restore_jobs table for re-importing S3 archives
+CREATE TABLE IF NOT EXISTS restore_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    status TEXT NOT NULL,
+    start_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    objects_processed INTEGER NOT NULL DEFAULT 0,
+    restored_count BIGINT NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_restore_jobs_tenant_created_at ON restore_jobs(tenant_id, created_at DESC);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_restore_jobs_tenant_created_at;
+
+DROP TABLE IF EXISTS restore_jobs;
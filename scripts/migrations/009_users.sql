@@ -0,0 +1,20 @@
+-- +migrate Up
+-- Create users table for tenant user management
+CREATE TABLE IF NOT EXISTS users (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    email TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    roles TEXT[] NOT NULL DEFAULT '{user}',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    metadata JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_users_tenant_id ON users(tenant_id);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_users_tenant_id;
+
+DROP TABLE IF EXISTS users;
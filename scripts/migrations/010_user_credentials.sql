@@ -0,0 +1,6 @@
+-- +migrate Up
+-- Store bcrypt password hashes so users can exchange credentials for tokens
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_hash TEXT;
+
+-- +migrate Down
+ALTER TABLE users DROP COLUMN IF EXISTS password_hash;
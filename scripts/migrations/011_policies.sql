@@ -0,0 +1,21 @@
+-- +migrate Up
+-- Create policies table for per-tenant role permissions
+CREATE TABLE IF NOT EXISTS policies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    role TEXT NOT NULL,
+    resource TEXT NOT NULL,
+    action TEXT NOT NULL,
+    effect TEXT NOT NULL DEFAULT 'allow' CHECK (effect IN ('allow', 'deny')),
+    scope TEXT NOT NULL DEFAULT 'all' CHECK (scope IN ('all', 'own')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tenant_id, role, resource, action)
+);
+
+CREATE INDEX idx_policies_tenant_id ON policies(tenant_id);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_policies_tenant_id;
+
+DROP TABLE IF EXISTS policies;
@@ -0,0 +1,19 @@
+-- +migrate Up
+-- Create redaction_rules table for per-tenant PII masking applied at ingest
+CREATE TABLE IF NOT EXISTS redaction_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    target TEXT NOT NULL CHECK (target IN ('before_state', 'after_state', 'metadata')),
+    path TEXT NOT NULL DEFAULT '',
+    mask TEXT NOT NULL CHECK (mask IN ('full', 'email', 'ssn', 'card_number')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tenant_id, target, path, mask)
+);
+
+CREATE INDEX idx_redaction_rules_tenant_id ON redaction_rules(tenant_id);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_redaction_rules_tenant_id;
+
+DROP TABLE IF EXISTS redaction_rules;
@@ -0,0 +1,23 @@
+-- +migrate Up
+-- Create saved_searches table for named, optionally shared log filters
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    owner_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT,
+    filter JSONB NOT NULL DEFAULT '{}',
+    shared BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tenant_id, owner_id, name)
+);
+
+CREATE INDEX idx_saved_searches_tenant_owner ON saved_searches(tenant_id, owner_id);
+CREATE INDEX idx_saved_searches_tenant_shared ON saved_searches(tenant_id) WHERE shared;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_saved_searches_tenant_shared;
+DROP INDEX IF EXISTS idx_saved_searches_tenant_owner;
+
+DROP TABLE IF EXISTS saved_searches;
@@ -0,0 +1,8 @@
+-- +migrate Up
+-- Ties together the logs written while serving one request chain
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS correlation_id TEXT;
+CREATE INDEX IF NOT EXISTS idx_audit_logs_correlation_id ON audit_logs(tenant_id, correlation_id, timestamp) WHERE correlation_id IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_audit_logs_correlation_id;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS correlation_id;
@@ -0,0 +1,6 @@
+-- +migrate Up
+-- Self-service tenant configuration: retention, allowed actions, webhook secrets and data residency
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS settings JSONB NOT NULL DEFAULT '{}';
+
+-- +migrate Down
+ALTER TABLE tenants DROP COLUMN IF EXISTS settings;
@@ -0,0 +1,14 @@
+-- +migrate Up
+-- Deleted tenants are kept until purge_after so they can be restored; the purge
+-- worker then archives and removes their logs before dropping the row
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS purge_after TIMESTAMP WITH TIME ZONE;
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS purge_started_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_tenants_purge_after ON tenants(purge_after) WHERE deleted_at IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_tenants_purge_after;
+ALTER TABLE tenants DROP COLUMN IF EXISTS purge_started_at;
+ALTER TABLE tenants DROP COLUMN IF EXISTS purge_after;
+ALTER TABLE tenants DROP COLUMN IF EXISTS deleted_at;
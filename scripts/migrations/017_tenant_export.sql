@@ -0,0 +1,6 @@
+-- +migrate Up
+-- Export jobs either export matching logs or dump all of a tenant's data
+ALTER TABLE export_jobs ADD COLUMN IF NOT EXISTS scope TEXT NOT NULL DEFAULT 'logs';
+
+-- +migrate Down
+ALTER TABLE export_jobs DROP COLUMN IF EXISTS scope;
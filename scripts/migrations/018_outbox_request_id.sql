@@ -0,0 +1,6 @@
+-- +migrate Up
+-- ID of the API call that stored the event, forwarded on the queue messages it produces
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS request_id TEXT;
+
+-- +migrate Down
+ALTER TABLE outbox_events DROP COLUMN IF EXISTS request_id;
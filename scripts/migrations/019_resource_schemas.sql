@@ -0,0 +1,21 @@
+-- +migrate Up
+-- Create resource_schemas table for per-tenant JSON Schemas of log payloads, checked at ingest
+CREATE TABLE IF NOT EXISTS resource_schemas (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    resource_type TEXT NOT NULL,
+    metadata_schema JSONB,
+    state_schema JSONB,
+    mode TEXT NOT NULL CHECK (mode IN ('reject', 'flag')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tenant_id, resource_type)
+);
+
+-- Violations of logs stored in flag mode
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS schema_errors JSONB;
+
+-- +migrate Down
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS schema_errors;
+
+DROP TABLE IF EXISTS resource_schemas;
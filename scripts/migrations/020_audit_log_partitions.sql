@@ -0,0 +1,117 @@
+-- +migrate Up
+-- Replace the TimescaleDB hypertable with native range partitioning by month, so
+-- expired months are dropped as a whole instead of deleted row by row. The
+-- partition worker creates the partitions of later months ahead of time; rows
+-- outside every partition land in audit_logs_default.
+
+-- The continuous aggregate and compression depend on the hypertable
+SELECT remove_continuous_aggregate_policy('audit_logs_hourly_stats', if_exists => TRUE);
+DROP MATERIALIZED VIEW IF EXISTS audit_logs_hourly_stats;
+SELECT remove_compression_policy('audit_logs', if_exists => TRUE);
+
+CREATE TABLE audit_logs_partitioned (
+    LIKE audit_logs INCLUDING DEFAULTS
+) PARTITION BY RANGE (timestamp);
+
+-- Monthly partitions from the oldest stored log up to three months ahead
+-- +migrate StatementBegin
+DO $$
+DECLARE
+    month TIMESTAMP := date_trunc('month', COALESCE((SELECT MIN(timestamp) FROM audit_logs), now()) AT TIME ZONE 'UTC');
+    last_month TIMESTAMP := date_trunc('month', now() AT TIME ZONE 'UTC') + INTERVAL '3 months';
+BEGIN
+    WHILE month <= last_month LOOP
+        EXECUTE format(
+            'CREATE TABLE %I PARTITION OF audit_logs_partitioned FOR VALUES FROM (%L) TO (%L)',
+            'audit_logs_' || to_char(month, '"y"YYYY"m"MM'),
+            month AT TIME ZONE 'UTC',
+            (month + INTERVAL '1 month') AT TIME ZONE 'UTC');
+        month := month + INTERVAL '1 month';
+    END LOOP;
+END $$;
+-- +migrate StatementEnd
+
+CREATE TABLE audit_logs_default PARTITION OF audit_logs_partitioned DEFAULT;
+
+INSERT INTO audit_logs_partitioned SELECT * FROM audit_logs;
+
+DROP TABLE audit_logs;
+ALTER TABLE audit_logs_partitioned RENAME TO audit_logs;
+
+-- Unique constraints of a partitioned table must cover every partition key,
+-- including tenant_id for months sub-partitioned by tenant hash
+ALTER TABLE audit_logs ADD CONSTRAINT pk_audit_logs PRIMARY KEY (id, timestamp, tenant_id);
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_tenant_id_fkey
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_audit_logs_brin_timestamp ON audit_logs USING BRIN (timestamp);
+CREATE INDEX idx_audit_logs_stats_action ON audit_logs(tenant_id, timestamp, action) INCLUDE (id);
+CREATE INDEX idx_audit_logs_stats_severity ON audit_logs(tenant_id, timestamp, severity) INCLUDE (id);
+CREATE INDEX idx_audit_logs_stats_resource ON audit_logs(tenant_id, timestamp, resource_type) INCLUDE (id) WHERE resource_type IS NOT NULL;
+CREATE INDEX idx_audit_logs_correlation_id ON audit_logs(tenant_id, correlation_id, timestamp) WHERE correlation_id IS NOT NULL;
+
+-- Hourly stats are aggregated from the logs table on read
+CREATE VIEW audit_logs_hourly_stats AS
+SELECT
+    date_trunc('hour', timestamp) AS bucket,
+    tenant_id,
+    action,
+    severity,
+    resource_type,
+    COUNT(*) as count
+FROM audit_logs
+GROUP BY bucket, tenant_id, action, severity, resource_type;
+
+-- +migrate Down
+DROP VIEW IF EXISTS audit_logs_hourly_stats;
+
+ALTER TABLE audit_logs RENAME TO audit_logs_partitioned;
+ALTER TABLE audit_logs_partitioned DROP CONSTRAINT IF EXISTS audit_logs_tenant_id_fkey;
+ALTER TABLE audit_logs_partitioned DROP CONSTRAINT IF EXISTS pk_audit_logs;
+DROP INDEX IF EXISTS idx_audit_logs_brin_timestamp;
+DROP INDEX IF EXISTS idx_audit_logs_stats_action;
+DROP INDEX IF EXISTS idx_audit_logs_stats_severity;
+DROP INDEX IF EXISTS idx_audit_logs_stats_resource;
+DROP INDEX IF EXISTS idx_audit_logs_correlation_id;
+
+CREATE TABLE audit_logs (
+    LIKE audit_logs_partitioned INCLUDING DEFAULTS,
+    CONSTRAINT pk_audit_logs PRIMARY KEY (id, timestamp),
+    CONSTRAINT audit_logs_tenant_id_fkey FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE
+);
+
+SELECT create_hypertable('audit_logs', 'timestamp', chunk_time_interval => INTERVAL '1 day');
+
+INSERT INTO audit_logs SELECT * FROM audit_logs_partitioned;
+DROP TABLE audit_logs_partitioned;
+
+CREATE INDEX idx_audit_logs_brin_timestamp ON audit_logs USING BRIN (timestamp);
+CREATE INDEX idx_audit_logs_stats_action ON audit_logs(tenant_id, timestamp, action) INCLUDE (id);
+CREATE INDEX idx_audit_logs_stats_severity ON audit_logs(tenant_id, timestamp, severity) INCLUDE (id);
+CREATE INDEX idx_audit_logs_stats_resource ON audit_logs(tenant_id, timestamp, resource_type) INCLUDE (id) WHERE resource_type IS NOT NULL;
+CREATE INDEX idx_audit_logs_correlation_id ON audit_logs(tenant_id, correlation_id, timestamp) WHERE correlation_id IS NOT NULL;
+
+ALTER TABLE audit_logs SET (
+    timescaledb.compress,
+    timescaledb.compress_segmentby = 'tenant_id,action,severity,resource_type'
+);
+
+SELECT add_compression_policy('audit_logs', INTERVAL '7 days');
+
+CREATE MATERIALIZED VIEW audit_logs_hourly_stats
+WITH (timescaledb.continuous) AS
+SELECT
+    time_bucket('1 hour', timestamp) AS bucket,
+    tenant_id,
+    action,
+    severity,
+    resource_type,
+    COUNT(*) as count
+FROM audit_logs
+GROUP BY bucket, tenant_id, action, severity, resource_type
+WITH NO DATA;
+
+SELECT add_continuous_aggregate_policy('audit_logs_hourly_stats',
+    start_offset => INTERVAL '1 month',
+    end_offset => INTERVAL '1 hour',
+    schedule_interval => INTERVAL '1 hour');
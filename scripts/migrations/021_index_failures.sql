@@ -0,0 +1,21 @@
+-- +migrate Up
+-- Create index_failures table for logs OpenSearch rejected, kept for reindexing
+CREATE TABLE IF NOT EXISTS index_failures (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    log_id UUID NOT NULL,
+    index_name TEXT NOT NULL,
+    status INTEGER NOT NULL,
+    error_type TEXT,
+    reason TEXT,
+    document JSONB NOT NULL,
+    failed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tenant_id, log_id)
+);
+
+CREATE INDEX idx_index_failures_tenant_failed_at ON index_failures(tenant_id, failed_at DESC);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_index_failures_tenant_failed_at;
+
+DROP TABLE IF EXISTS index_failures;
@@ -0,0 +1,20 @@
+-- +migrate Up
+-- Create cleanup_jobs table recording each deletion of a tenant's old logs
+CREATE TABLE IF NOT EXISTS cleanup_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    status TEXT NOT NULL,
+    before_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    deleted_count BIGINT NOT NULL DEFAULT 0,
+    indexed_count BIGINT NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_cleanup_jobs_tenant_created_at ON cleanup_jobs(tenant_id, created_at DESC);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_cleanup_jobs_tenant_created_at;
+
+DROP TABLE IF EXISTS cleanup_jobs;
@@ -0,0 +1,29 @@
+-- +migrate Up
+-- Cleanup jobs are created when the cleanup is scheduled and count the logs archived before deletion
+ALTER TABLE cleanup_jobs ADD COLUMN IF NOT EXISTS archived_count BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE cleanup_jobs ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+
+-- Create the jobs view, one row per background operation scheduled through the API
+CREATE OR REPLACE VIEW jobs AS
+SELECT id, tenant_id,
+       CASE scope WHEN 'tenant' THEN 'tenant_export' ELSE 'export' END AS type,
+       status,
+       jsonb_build_object('rows', row_count) AS counts,
+       error, created_at, updated_at, completed_at
+FROM export_jobs
+UNION ALL
+SELECT id, tenant_id, 'restore' AS type, status,
+       jsonb_build_object('objects', objects_processed, 'restored', restored_count) AS counts,
+       error, created_at, updated_at, completed_at
+FROM restore_jobs
+UNION ALL
+SELECT id, tenant_id, 'cleanup' AS type, status,
+       jsonb_build_object('archived', archived_count, 'deleted', deleted_count, 'indexed', indexed_count) AS counts,
+       error, created_at, updated_at, completed_at
+FROM cleanup_jobs;
+
+-- +migrate Down
+DROP VIEW IF EXISTS jobs;
+
+ALTER TABLE cleanup_jobs DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE cleanup_jobs DROP COLUMN IF EXISTS archived_count;
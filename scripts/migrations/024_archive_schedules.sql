@@ -0,0 +1,16 @@
+-- +migrate Up
+-- Create archive_schedules table holding each tenant's scheduled archival
+-- overrides and its last run; tenants without a row use the defaults
+CREATE TABLE IF NOT EXISTS archive_schedules (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    interval_hours INTEGER NOT NULL DEFAULT 0,
+    retention_days INTEGER NOT NULL DEFAULT 0,
+    last_run_at TIMESTAMP WITH TIME ZONE,
+    last_job_id UUID,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS archive_schedules;
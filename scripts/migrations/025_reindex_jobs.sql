@@ -0,0 +1,67 @@
+-- +migrate Up
+-- Create reindex_jobs table recording each backfill of OpenSearch from PostgreSQL, with the last log indexed to resume from
+CREATE TABLE IF NOT EXISTS reindex_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    status TEXT NOT NULL,
+    start_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    total_count BIGINT NOT NULL DEFAULT 0,
+    indexed_count BIGINT NOT NULL DEFAULT 0,
+    failed_count BIGINT NOT NULL DEFAULT 0,
+    cursor_timestamp TIMESTAMP WITH TIME ZONE,
+    cursor_id TEXT,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_reindex_jobs_tenant_created_at ON reindex_jobs(tenant_id, created_at DESC);
+
+-- Add reindex jobs to the jobs view
+CREATE OR REPLACE VIEW jobs AS
+SELECT id, tenant_id,
+       CASE scope WHEN 'tenant' THEN 'tenant_export' ELSE 'export' END AS type,
+       status,
+       jsonb_build_object('rows', row_count) AS counts,
+       error, created_at, updated_at, completed_at
+FROM export_jobs
+UNION ALL
+SELECT id, tenant_id, 'restore' AS type, status,
+       jsonb_build_object('objects', objects_processed, 'restored', restored_count) AS counts,
+       error, created_at, updated_at, completed_at
+FROM restore_jobs
+UNION ALL
+SELECT id, tenant_id, 'cleanup' AS type, status,
+       jsonb_build_object('archived', archived_count, 'deleted', deleted_count, 'indexed', indexed_count) AS counts,
+       error, created_at, updated_at, completed_at
+FROM cleanup_jobs
+UNION ALL
+SELECT id, tenant_id, 'reindex' AS type, status,
+       jsonb_build_object('total', total_count, 'indexed', indexed_count, 'failed', failed_count) AS counts,
+       error, created_at, updated_at, completed_at
+FROM reindex_jobs;
+
+-- +migrate Down
+CREATE OR REPLACE VIEW jobs AS
+SELECT id, tenant_id,
+       CASE scope WHEN 'tenant' THEN 'tenant_export' ELSE 'export' END AS type,
+       status,
+       jsonb_build_object('rows', row_count) AS counts,
+       error, created_at, updated_at, completed_at
+FROM export_jobs
+UNION ALL
+SELECT id, tenant_id, 'restore' AS type, status,
+       jsonb_build_object('objects', objects_processed, 'restored', restored_count) AS counts,
+       error, created_at, updated_at, completed_at
+FROM restore_jobs
+UNION ALL
+SELECT id, tenant_id, 'cleanup' AS type, status,
+       jsonb_build_object('archived', archived_count, 'deleted', deleted_count, 'indexed', indexed_count) AS counts,
+       error, created_at, updated_at, completed_at
+FROM cleanup_jobs;
+
+DROP INDEX IF EXISTS idx_reindex_jobs_tenant_created_at;
+
+DROP TABLE IF EXISTS reindex_jobs;
@@ -0,0 +1,9 @@
+-- +migrate Up
+-- Reserve the system tenant holding the meta-audit logs, the queries and
+-- administrative actions made against the API itself
+INSERT INTO tenants (id, name)
+VALUES ('00000000-0000-0000-0000-000000000000', 'system')
+ON CONFLICT (id) DO NOTHING;
+
+-- +migrate Down
+DELETE FROM tenants WHERE id = '00000000-0000-0000-0000-000000000000';
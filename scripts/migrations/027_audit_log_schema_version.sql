@@ -0,0 +1,7 @@
+-- +migrate Up
+-- Records the version of the log schema each log conforms to; logs stored
+-- before versioning are version 1
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS schema_version SMALLINT NOT NULL DEFAULT 1;
+
+-- +migrate Down
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS schema_version;
@@ -0,0 +1,29 @@
+-- +migrate Up
+-- Tags given to logs at ingest by their tenant's tagging rules
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_tags ON audit_logs USING GIN (tags);
+
+-- Create tagging_rules table for per-tenant classification of logs at ingest
+CREATE TABLE IF NOT EXISTS tagging_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    actions JSONB,
+    resource_types JSONB,
+    severities JSONB,
+    message_pattern TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_tagging_rules_tenant_id ON tagging_rules(tenant_id);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_tagging_rules_tenant_id;
+
+DROP TABLE IF EXISTS tagging_rules;
+
+DROP INDEX IF EXISTS idx_audit_logs_tags;
+
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS tags;
@@ -0,0 +1,8 @@
+-- +migrate Up
+-- Logs from IP addresses with a bad reputation record a threat_score in their
+-- metadata at ingest, which logs are filtered by
+CREATE INDEX IF NOT EXISTS idx_audit_logs_threat_score ON audit_logs (tenant_id, (CASE WHEN jsonb_typeof(metadata->'threat_score') = 'number' THEN (metadata->>'threat_score')::numeric END), timestamp)
+    WHERE jsonb_typeof(metadata->'threat_score') = 'number';
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_audit_logs_threat_score;
@@ -0,0 +1,27 @@
+-- +migrate Up
+-- Create archives table recording every archive written to S3 with the
+-- SHA-256 checksums of its manifest and parts, and the outcome of its last
+-- integrity verification
+CREATE TABLE IF NOT EXISTS archives (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    before_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    manifest_key TEXT NOT NULL,
+    manifest_sha256 TEXT NOT NULL,
+    log_count BIGINT NOT NULL,
+    size BIGINT NOT NULL,
+    parts JSONB NOT NULL,
+    storage_class TEXT,
+    retain_until TIMESTAMP WITH TIME ZONE,
+    archived_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    verification_status TEXT,
+    last_verified_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tenant_id, manifest_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_archives_tenant_before_date ON archives(tenant_id, before_date DESC);
+
+-- +migrate Down
+DROP TABLE IF EXISTS archives;
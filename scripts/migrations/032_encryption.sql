@@ -0,0 +1,11 @@
+-- +migrate Up
+-- KMS key the objects of an archive are encrypted with (SSE-KMS)
+ALTER TABLE archives ADD COLUMN IF NOT EXISTS kms_key_id TEXT;
+
+-- Whether an export was encrypted for the tenant's export public key
+ALTER TABLE export_jobs ADD COLUMN IF NOT EXISTS encrypted BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +migrate Down
+ALTER TABLE export_jobs DROP COLUMN IF EXISTS encrypted;
+
+ALTER TABLE archives DROP COLUMN IF EXISTS kms_key_id;
@@ -0,0 +1,8 @@
+-- +migrate Up
+-- Key export jobs are signed with, and the detached signature of their file
+ALTER TABLE export_jobs ADD COLUMN IF NOT EXISTS sign_with TEXT;
+ALTER TABLE export_jobs ADD COLUMN IF NOT EXISTS signature JSONB;
+
+-- +migrate Down
+ALTER TABLE export_jobs DROP COLUMN IF EXISTS signature;
+ALTER TABLE export_jobs DROP COLUMN IF EXISTS sign_with;
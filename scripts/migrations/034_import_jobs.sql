@@ -0,0 +1,79 @@
+-- +migrate Up
+-- Create import_jobs table recording each import of historical logs, with the lines stored to resume from
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    status TEXT NOT NULL,
+    source_uri TEXT NOT NULL,
+    uploaded BOOLEAN NOT NULL DEFAULT FALSE,
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    bytes_read BIGINT NOT NULL DEFAULT 0,
+    lines_read BIGINT NOT NULL DEFAULT 0,
+    imported_count BIGINT NOT NULL DEFAULT 0,
+    skipped_count BIGINT NOT NULL DEFAULT 0,
+    invalid_count BIGINT NOT NULL DEFAULT 0,
+    line_errors JSONB,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_import_jobs_tenant_created_at ON import_jobs(tenant_id, created_at DESC);
+
+-- Add import jobs to the jobs view
+CREATE OR REPLACE VIEW jobs AS
+SELECT id, tenant_id,
+       CASE scope WHEN 'tenant' THEN 'tenant_export' ELSE 'export' END AS type,
+       status,
+       jsonb_build_object('rows', row_count) AS counts,
+       error, created_at, updated_at, completed_at
+FROM export_jobs
+UNION ALL
+SELECT id, tenant_id, 'restore' AS type, status,
+       jsonb_build_object('objects', objects_processed, 'restored', restored_count) AS counts,
+       error, created_at, updated_at, completed_at
+FROM restore_jobs
+UNION ALL
+SELECT id, tenant_id, 'cleanup' AS type, status,
+       jsonb_build_object('archived', archived_count, 'deleted', deleted_count, 'indexed', indexed_count) AS counts,
+       error, created_at, updated_at, completed_at
+FROM cleanup_jobs
+UNION ALL
+SELECT id, tenant_id, 'reindex' AS type, status,
+       jsonb_build_object('total', total_count, 'indexed', indexed_count, 'failed', failed_count) AS counts,
+       error, created_at, updated_at, completed_at
+FROM reindex_jobs
+UNION ALL
+SELECT id, tenant_id, 'import' AS type, status,
+       jsonb_build_object('lines', lines_read, 'imported', imported_count, 'skipped', skipped_count, 'invalid', invalid_count) AS counts,
+       error, created_at, updated_at, completed_at
+FROM import_jobs;
+
+-- +migrate Down
+CREATE OR REPLACE VIEW jobs AS
+SELECT id, tenant_id,
+       CASE scope WHEN 'tenant' THEN 'tenant_export' ELSE 'export' END AS type,
+       status,
+       jsonb_build_object('rows', row_count) AS counts,
+       error, created_at, updated_at, completed_at
+FROM export_jobs
+UNION ALL
+SELECT id, tenant_id, 'restore' AS type, status,
+       jsonb_build_object('objects', objects_processed, 'restored', restored_count) AS counts,
+       error, created_at, updated_at, completed_at
+FROM restore_jobs
+UNION ALL
+SELECT id, tenant_id, 'cleanup' AS type, status,
+       jsonb_build_object('archived', archived_count, 'deleted', deleted_count, 'indexed', indexed_count) AS counts,
+       error, created_at, updated_at, completed_at
+FROM cleanup_jobs
+UNION ALL
+SELECT id, tenant_id, 'reindex' AS type, status,
+       jsonb_build_object('total', total_count, 'indexed', indexed_count, 'failed', failed_count) AS counts,
+       error, created_at, updated_at, completed_at
+FROM reindex_jobs;
+
+DROP INDEX IF EXISTS idx_import_jobs_tenant_created_at;
+
+DROP TABLE IF EXISTS import_jobs;
@@ -0,0 +1,38 @@
+-- +migrate Up
+-- Create integrations table of the tenants' log forwarding to Splunk and Datadog, with the last log delivered to resume after.
+-- Tokens are stored encrypted for the forwarding worker's key, with their last four characters to tell them apart
+CREATE TABLE IF NOT EXISTS integrations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    settings JSONB NOT NULL DEFAULT '{}',
+    sealed_token BYTEA NOT NULL,
+    token_hint TEXT NOT NULL DEFAULT '',
+    filter JSONB NOT NULL DEFAULT '{}',
+    cursor_created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    cursor_id UUID NOT NULL,
+    forwarded_count BIGINT NOT NULL DEFAULT 0,
+    last_forwarded_at TIMESTAMP WITH TIME ZONE,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP WITH TIME ZONE,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tenant_id, name)
+);
+
+-- The forwarding worker claims enabled integrations whose lease has ended
+CREATE INDEX idx_integrations_due ON integrations(locked_until NULLS FIRST) WHERE enabled;
+
+-- The forwarding worker reads each tenant's logs in the order they were stored
+CREATE INDEX IF NOT EXISTS idx_audit_logs_tenant_created_at ON audit_logs(tenant_id, created_at, id);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_audit_logs_tenant_created_at;
+
+DROP INDEX IF EXISTS idx_integrations_due;
+
+DROP TABLE IF EXISTS integrations;
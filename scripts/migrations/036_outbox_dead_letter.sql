@@ -0,0 +1,17 @@
+-- +migrate Up
+-- Dead-letter outbox events that failed every attempt, so the relay stops retrying them
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS dead_lettered_at TIMESTAMP WITH TIME ZONE;
+
+-- Pending events exclude dead-lettered ones
+DROP INDEX IF EXISTS idx_outbox_events_pending;
+CREATE INDEX idx_outbox_events_pending ON outbox_events(created_at) WHERE processed_at IS NULL AND dead_lettered_at IS NULL;
+
+CREATE INDEX idx_outbox_events_dead_lettered_at ON outbox_events(dead_lettered_at) WHERE dead_lettered_at IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_outbox_events_dead_lettered_at;
+
+DROP INDEX IF EXISTS idx_outbox_events_pending;
+CREATE INDEX idx_outbox_events_pending ON outbox_events(created_at) WHERE processed_at IS NULL;
+
+ALTER TABLE outbox_events DROP COLUMN IF EXISTS dead_lettered_at;
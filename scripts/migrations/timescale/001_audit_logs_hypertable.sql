@@ -0,0 +1,120 @@
+-- +migrate Up
+-- TimescaleDB storage mode (POSTGRES_STORAGE_MODE=timescale): audit_logs becomes a
+-- hypertable with compressed chunks, and the hourly stats table the stats
+-- worker maintains is replaced by a continuous aggregate. Apply after the
+-- migrations in scripts/migrations.
+CREATE EXTENSION IF NOT EXISTS timescaledb;
+
+DROP TABLE IF EXISTS audit_logs_hourly_stats;
+
+ALTER TABLE audit_logs RENAME TO audit_logs_partitioned;
+ALTER TABLE audit_logs_partitioned DROP CONSTRAINT IF EXISTS audit_logs_tenant_id_fkey;
+ALTER TABLE audit_logs_partitioned DROP CONSTRAINT IF EXISTS pk_audit_logs;
+DROP INDEX IF EXISTS idx_audit_logs_brin_timestamp;
+DROP INDEX IF EXISTS idx_audit_logs_stats_action;
+DROP INDEX IF EXISTS idx_audit_logs_stats_severity;
+DROP INDEX IF EXISTS idx_audit_logs_stats_resource;
+DROP INDEX IF EXISTS idx_audit_logs_correlation_id;
+DROP INDEX IF EXISTS idx_audit_logs_brin_created_at;
+DROP INDEX IF EXISTS idx_audit_logs_tags;
+DROP INDEX IF EXISTS idx_audit_logs_threat_score;
+
+CREATE TABLE audit_logs (
+    LIKE audit_logs_partitioned INCLUDING DEFAULTS,
+    CONSTRAINT pk_audit_logs PRIMARY KEY (id, timestamp),
+    CONSTRAINT audit_logs_tenant_id_fkey FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE
+);
+
+SELECT create_hypertable('audit_logs', 'timestamp', chunk_time_interval => INTERVAL '1 day');
+
+INSERT INTO audit_logs SELECT * FROM audit_logs_partitioned;
+DROP TABLE audit_logs_partitioned;
+
+CREATE INDEX idx_audit_logs_brin_timestamp ON audit_logs USING BRIN (timestamp);
+CREATE INDEX idx_audit_logs_stats_action ON audit_logs(tenant_id, timestamp, action) INCLUDE (id);
+CREATE INDEX idx_audit_logs_stats_severity ON audit_logs(tenant_id, timestamp, severity) INCLUDE (id);
+CREATE INDEX idx_audit_logs_stats_resource ON audit_logs(tenant_id, timestamp, resource_type) INCLUDE (id) WHERE resource_type IS NOT NULL;
+CREATE INDEX idx_audit_logs_correlation_id ON audit_logs(tenant_id, correlation_id, timestamp) WHERE correlation_id IS NOT NULL;
+CREATE INDEX idx_audit_logs_brin_created_at ON audit_logs USING BRIN (created_at);
+CREATE INDEX idx_audit_logs_tags ON audit_logs USING GIN (tags);
+CREATE INDEX idx_audit_logs_threat_score ON audit_logs (tenant_id, (CASE WHEN jsonb_typeof(metadata->'threat_score') = 'number' THEN (metadata->>'threat_score')::numeric END), timestamp)
+    WHERE jsonb_typeof(metadata->'threat_score') = 'number';
+
+-- Compress chunks older than 7 days
+ALTER TABLE audit_logs SET (
+    timescaledb.compress,
+    timescaledb.compress_segmentby = 'tenant_id,action,severity,resource_type'
+);
+
+SELECT add_compression_policy('audit_logs', INTERVAL '7 days');
+
+-- Stats of any range are read from the aggregate; buckets not materialized yet
+-- are aggregated from the logs on read
+CREATE MATERIALIZED VIEW audit_logs_hourly_stats
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT
+    time_bucket('1 hour', timestamp) AS bucket,
+    tenant_id,
+    action,
+    severity,
+    resource_type,
+    COUNT(*) as count
+FROM audit_logs
+GROUP BY bucket, tenant_id, action, severity, resource_type
+WITH NO DATA;
+
+SELECT add_continuous_aggregate_policy('audit_logs_hourly_stats',
+    start_offset => INTERVAL '1 month',
+    end_offset => INTERVAL '1 hour',
+    schedule_interval => INTERVAL '1 hour');
+
+-- +migrate Down
+SELECT remove_continuous_aggregate_policy('audit_logs_hourly_stats', if_exists => TRUE);
+DROP MATERIALIZED VIEW IF EXISTS audit_logs_hourly_stats;
+SELECT remove_compression_policy('audit_logs', if_exists => TRUE);
+
+CREATE TABLE audit_logs_partitioned (
+    LIKE audit_logs INCLUDING DEFAULTS
+) PARTITION BY RANGE (timestamp);
+
+-- The partition worker creates the monthly partitions; until then logs land in
+-- the default partition and are moved out as each month's partition is created
+CREATE TABLE audit_logs_default PARTITION OF audit_logs_partitioned DEFAULT;
+
+INSERT INTO audit_logs_partitioned SELECT * FROM audit_logs;
+
+DROP TABLE audit_logs;
+ALTER TABLE audit_logs_partitioned RENAME TO audit_logs;
+
+ALTER TABLE audit_logs ADD CONSTRAINT pk_audit_logs PRIMARY KEY (id, timestamp, tenant_id);
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_tenant_id_fkey
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_audit_logs_brin_timestamp ON audit_logs USING BRIN (timestamp);
+CREATE INDEX idx_audit_logs_stats_action ON audit_logs(tenant_id, timestamp, action) INCLUDE (id);
+CREATE INDEX idx_audit_logs_stats_severity ON audit_logs(tenant_id, timestamp, severity) INCLUDE (id);
+CREATE INDEX idx_audit_logs_stats_resource ON audit_logs(tenant_id, timestamp, resource_type) INCLUDE (id) WHERE resource_type IS NOT NULL;
+CREATE INDEX idx_audit_logs_correlation_id ON audit_logs(tenant_id, correlation_id, timestamp) WHERE correlation_id IS NOT NULL;
+CREATE INDEX idx_audit_logs_brin_created_at ON audit_logs USING BRIN (created_at);
+CREATE INDEX idx_audit_logs_tags ON audit_logs USING GIN (tags);
+CREATE INDEX idx_audit_logs_threat_score ON audit_logs (tenant_id, (CASE WHEN jsonb_typeof(metadata->'threat_score') = 'number' THEN (metadata->>'threat_score')::numeric END), timestamp)
+    WHERE jsonb_typeof(metadata->'threat_score') = 'number';
+
+CREATE TABLE audit_logs_hourly_stats (
+    bucket TIMESTAMP WITH TIME ZONE NOT NULL,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    action TEXT NOT NULL,
+    severity TEXT NOT NULL,
+    resource_type TEXT NOT NULL DEFAULT '',
+    count BIGINT NOT NULL,
+    PRIMARY KEY (tenant_id, bucket, action, severity, resource_type)
+);
+
+INSERT INTO audit_logs_hourly_stats (bucket, tenant_id, action, severity, resource_type, count)
+SELECT date_trunc('hour', timestamp), tenant_id, action, severity, COALESCE(resource_type, ''), COUNT(*)
+FROM audit_logs
+GROUP BY 1, 2, 3, 4, 5;
+
+-- The stats worker picks up from here
+UPDATE stats_rollup_state SET watermark = NOW() - INTERVAL '5 minutes', updated_at = NOW()
+WHERE name = 'audit_logs_hourly_stats';
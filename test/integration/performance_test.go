@@ -24,7 +24,7 @@ func BenchmarkCreateAuditLog(b *testing.B) {
 	// Setup
 	gin.SetMode(gin.TestMode)
 	mockService := new(mocks.AuditLogService)
-	handler := api.NewAuditLogHandler(mockService)
+	handler := api.NewAuditLogHandler(mockService, new(mocks.SavedSearchLookup))
 	logger.NewLogger("test")
 
 	// Mock auth middleware that sets tenant context
@@ -81,7 +81,7 @@ func BenchmarkListAuditLogs(b *testing.B) {
 	// Setup
 	gin.SetMode(gin.TestMode)
 	mockService := new(mocks.AuditLogService)
-	handler := api.NewAuditLogHandler(mockService)
+	handler := api.NewAuditLogHandler(mockService, new(mocks.SavedSearchLookup))
 
 	router := gin.New()
 	router.Use(func(c *gin.Context) {
@@ -112,7 +112,8 @@ func BenchmarkListAuditLogs(b *testing.B) {
 		}
 	}
 
-	mockService.On("List", mock.Anything, mock.AnythingOfType("*domain.AuditLogFilter"), true).Return(mockLogs, nil)
+	mockService.On("ETag", mock.Anything, "logs", mock.Anything).Return("")
+	mockService.On("List", mock.Anything, mock.AnythingOfType("*domain.AuditLogFilter"), true).Return(mockLogs, "", false, nil)
 
 	b.ResetTimer()
 	b.ReportAllocs()
@@ -137,7 +138,7 @@ func TestHighConcurrencyCreateLogs(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
 	mockService := new(mocks.AuditLogService)
-	handler := api.NewAuditLogHandler(mockService)
+	handler := api.NewAuditLogHandler(mockService, new(mocks.SavedSearchLookup))
 
 	router := gin.New()
 	router.Use(func(c *gin.Context) {
@@ -254,7 +255,7 @@ func TestMemoryUsageUnderLoad(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
 	mockService := new(mocks.AuditLogService)
-	handler := api.NewAuditLogHandler(mockService)
+	handler := api.NewAuditLogHandler(mockService, new(mocks.SavedSearchLookup))
 
 	router := gin.New()
 	router.Use(func(c *gin.Context) {
@@ -271,7 +272,8 @@ func TestMemoryUsageUnderLoad(t *testing.T) {
 	router.GET("/logs", handler.ListLogs)
 
 	mockService.On("Create", mock.Anything, mock.AnythingOfType("dto.CreateAuditLogRequest")).Return(nil)
-	mockService.On("List", mock.Anything, mock.AnythingOfType("*domain.AuditLogFilter"), true).Return([]dto.AuditLogResponse{}, nil)
+	mockService.On("ETag", mock.Anything, "logs", mock.Anything).Return("")
+	mockService.On("List", mock.Anything, mock.AnythingOfType("*domain.AuditLogFilter"), true).Return([]dto.AuditLogResponse{}, "", false, nil)
 
 	// Run sustained load for 10 seconds
 	duration := 10 * time.Second